@@ -18,14 +18,17 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/go-chi/httprate"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/config"
 	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/database"
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/envelope"
 	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/handlers"
 	appMiddleware "github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
 	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/worker"
 )
 
 func main() {
@@ -50,6 +53,29 @@ func main() {
 	}
 	defer db.Close()
 
+	if err := db.Migrate(context.Background()); err != nil {
+		log.Fatal().Err(err).Msg("Failed to apply database migrations")
+	}
+
+	// Contacts, signatures and recipient IDs are sealed at rest
+	switch cfg.EncryptionProvider {
+	case "vault":
+		db.SetSealer(envelope.NewSealer(envelope.NewVaultTransit(cfg.VaultAddr, cfg.VaultToken, cfg.VaultTransitKey)))
+	case "keyring":
+		keyring, err := envelope.ParseKeyring(cfg.EncryptionKeys)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid ENCRYPTION_KEYS")
+		}
+		db.SetSealer(envelope.NewSealer(keyring))
+	case "":
+		log.Warn().Msg("ENCRYPTION_PROVIDER not set - sensitive delivery data will be stored in the clear")
+	default:
+		log.Fatal().Str("provider", cfg.EncryptionProvider).Msg("Unknown ENCRYPTION_PROVIDER, want vault or keyring")
+	}
+
+	// Contact names are found through keyed hashes of their words
+	db.SetSearchKey([]byte(cfg.SearchIndexKey))
+
 	// Initialize Redis
 	rdb, err := redis.New(cfg.RedisURL)
 	if err != nil {
@@ -57,9 +83,37 @@ func main() {
 	}
 	defer rdb.Close()
 
+	// Inbound webhooks are signed per source
+	webhookSecrets, err := appMiddleware.ParseWebhookSecrets(cfg.WebhookSecrets)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid WEBHOOK_SECRETS")
+	}
+
 	// Initialize handlers
 	h := handlers.New(db, rdb, cfg)
 
+	// Background jobs, run by whichever replica holds the leader lock
+	replica := replicaName()
+	var elector worker.Elector
+	switch cfg.WorkerLock {
+	case "redis":
+		elector = worker.NewRedisElector(rdb.Client(), "workers:leader:delivery-service", replica, 30*time.Second)
+	case "postgres":
+		elector = worker.NewPostgresElector(db.Pool, "delivery-service:workers")
+	default:
+		log.Fatal().Str("lock", cfg.WorkerLock).Msg("Unknown WORKER_LEADER_LOCK, want redis or postgres")
+	}
+	workers := worker.New("delivery-service", replica, elector)
+	h.RegisterJobs(workers)
+
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	workersDone := make(chan struct{})
+	go func() {
+		defer close(workersDone)
+		workers.Start(jobCtx)
+	}()
+
 	// Create router
 	r := chi.NewRouter()
 
@@ -97,10 +151,29 @@ func main() {
 			r.Post("/", h.CreateDelivery)
 			r.Get("/", h.ListDeliveries)
 			r.Get("/active", h.GetActiveDeliveries)
-			r.Get("/{id}", h.GetDelivery)
-			r.Get("/{id}/track", h.TrackDelivery)
-			r.Post("/{id}/cancel", h.CancelDelivery)
-			r.Post("/{id}/tip", h.AddTip)
+			r.Get("/search", h.SearchDeliveries)
+			r.Get("/notification-preferences", h.GetNotificationPreferences)
+			r.Put("/notification-preferences", h.UpdateNotificationPreferences)
+			r.Route("/{id}", func(r chi.Router) {
+				r.Use(h.PartnerTenancyGuard)
+				r.Get("/", h.GetDelivery)
+				r.Get("/track", h.TrackDelivery)
+				r.Post("/cancel", h.CancelDelivery)
+				r.Post("/tip", h.AddTip)
+				r.Get("/messages", h.GetDeliveryMessages)
+				r.Post("/messages", h.SendCustomerMessage)
+			})
+		})
+
+		// Merchant routes
+		r.Route("/merchant", func(r chi.Router) {
+			r.Use(appMiddleware.Auth(rdb, cfg.JWTSecret))
+			r.Use(appMiddleware.MerchantOnly)
+			r.Get("/pickup-windows", h.GetPickupWindows)
+			r.Put("/pickup-windows", h.ReplacePickupWindows)
+			r.Post("/pickup-blackouts", h.CreatePickupBlackout)
+			r.Delete("/pickup-blackouts/{blackoutId}", h.DeletePickupBlackout)
+			r.Get("/pickups/upcoming", h.GetUpcomingPickups)
 		})
 
 		// Driver routes
@@ -108,10 +181,33 @@ func main() {
 			r.Use(appMiddleware.Auth(rdb, cfg.JWTSecret))
 			r.Use(appMiddleware.DriverOnly)
 			r.Get("/deliveries/available", h.GetAvailableDeliveries)
+			r.Get("/route-sheet", h.GetRouteSheet)
 			r.Post("/deliveries/{id}/accept", h.AcceptDelivery)
 			r.Post("/deliveries/{id}/pickup", h.ConfirmPickup)
 			r.Post("/deliveries/{id}/deliver", h.ConfirmDelivery)
+			r.Post("/deliveries/{id}/fail", h.FailDelivery)
+			r.Post("/deliveries/{id}/temperature", h.RecordTemperatureCheck)
+			r.Get("/deliveries/{id}/messages", h.GetDeliveryMessages)
+			r.Post("/deliveries/{id}/messages", h.SendCourierMessage)
+			r.Get("/quick-replies", h.GetQuickReplies)
 			r.Post("/location", h.UpdateDriverLocation)
+			r.Get("/shifts/slots", h.GetOpenShiftSlots)
+			r.Post("/shifts/slots/{slotId}/book", h.BookShiftSlot)
+			r.Get("/shifts/bookings", h.GetMyShiftBookings)
+			r.Post("/shifts/bookings/{bookingId}/cancel", h.CancelShiftBooking)
+		})
+
+		// Partner data exports; download links are signed instead of
+		// authenticated
+		r.Route("/exports", func(r chi.Router) {
+			r.Get("/{exportId}/download", h.DownloadExport)
+			r.Group(func(r chi.Router) {
+				r.Use(appMiddleware.Auth(rdb, cfg.JWTSecret))
+				r.Use(appMiddleware.PartnerOnly)
+				r.Post("/", h.CreateExport)
+				r.Get("/", h.ListExports)
+				r.Get("/{exportId}", h.GetExport)
+			})
 		})
 
 		// Quotes
@@ -125,11 +221,82 @@ func main() {
 			r.Get("/check", h.CheckZone)
 		})
 
+		// Support tooling (internal)
+		r.Route("/internal/support", func(r chi.Router) {
+			r.Use(appMiddleware.Auth(rdb, cfg.JWTSecret))
+			r.Use(appMiddleware.SupportOnly)
+			r.Get("/deliveries/{id}/context", h.GetDeliveryCaseContext)
+			r.Get("/deliveries/{id}/refunds", h.ListDeliveryRefunds)
+			r.Post("/deliveries/{id}/refunds", h.CreateDeliveryRefund)
+			r.Post("/refunds/{refundId}/approve", h.ApproveDeliveryRefund)
+			r.Post("/refunds/{refundId}/reject", h.RejectDeliveryRefund)
+		})
+
+		// Compliance (internal): price control report, and regulated
+		// delivery records for compliance officers
+		r.Route("/internal/compliance", func(r chi.Router) {
+			r.Use(appMiddleware.Auth(rdb, cfg.JWTSecret))
+			r.With(appMiddleware.AdminOnly).Get("/price-controls", h.GetPriceControlReport)
+			r.With(appMiddleware.ComplianceOnly).Get("/deliveries/{id}/regulated", h.GetDeliveryComplianceRecord)
+		})
+
+		// Background job leadership and run stats (internal)
+		r.Route("/internal/workers", func(r chi.Router) {
+			r.Use(appMiddleware.Auth(rdb, cfg.JWTSecret))
+			r.Use(appMiddleware.AdminOnly)
+			r.Get("/", h.GetWorkerStatus)
+		})
+
+		// Hourly delivery metrics (internal)
+		r.Route("/internal/metrics", func(r chi.Router) {
+			r.Use(appMiddleware.Auth(rdb, cfg.JWTSecret))
+			r.Use(appMiddleware.AdminOnly)
+			r.Get("/deliveries", h.GetDeliveryMetricsReport)
+			r.Get("/webhooks", h.GetWebhookFailures)
+		})
+
+		// Courier shift slots (internal)
+		r.Route("/internal/shift-slots", func(r chi.Router) {
+			r.Use(appMiddleware.Auth(rdb, cfg.JWTSecret))
+			r.Use(appMiddleware.AdminOnly)
+			r.Post("/", h.PublishShiftSlot)
+			r.Get("/", h.ListShiftSlots)
+			r.Get("/{slotId}", h.GetShiftSlot)
+			r.Post("/{slotId}/cancel", h.CancelShiftSlot)
+		})
+
+		// Courier cold-chain certification and regulated goods vetting (internal)
+		r.Route("/internal/couriers", func(r chi.Router) {
+			r.Use(appMiddleware.Auth(rdb, cfg.JWTSecret))
+			r.Use(appMiddleware.AdminOnly)
+			r.Put("/{driverId}/cold-chain", h.SetCourierColdChain)
+			r.Put("/{driverId}/regulated-goods", h.SetCourierRegulatedGoods)
+		})
+
+		// Ops manual dispatch override (internal)
+		r.Route("/internal/dispatch", func(r chi.Router) {
+			r.Use(appMiddleware.Auth(rdb, cfg.JWTSecret))
+			r.Use(appMiddleware.AdminOnly)
+			r.Post("/deliveries/{id}", h.ManualDispatchDelivery)
+		})
+
+		// Driver earnings for the cross-service summary (internal)
+		r.Route("/internal/drivers", func(r chi.Router) {
+			r.Use(appMiddleware.ServiceAuth(cfg.InternalServiceKey))
+			r.Get("/{driverId}/earnings", h.GetDriverEarnings)
+		})
+
+		// Deliveries along a driver's return leg (internal)
+		r.Route("/internal/deliveries", func(r chi.Router) {
+			r.Use(appMiddleware.ServiceAuth(cfg.InternalServiceKey))
+			r.Get("/corridor", h.GetCorridorDeliveries)
+		})
+
 		// Webhooks (internal)
 		r.Route("/webhooks", func(r chi.Router) {
 			r.Use(appMiddleware.ServiceAuth(cfg.InternalServiceKey))
-			r.Post("/payment", h.PaymentWebhook)
-			r.Post("/order", h.OrderWebhook)
+			r.With(appMiddleware.WebhookSignature(rdb, "payment", webhookSecrets, cfg.WebhookTolerance)).Post("/payment", h.PaymentWebhook)
+			r.With(appMiddleware.WebhookSignature(rdb, "order", webhookSecrets, cfg.WebhookTolerance)).Post("/order", h.OrderWebhook)
 		})
 	})
 
@@ -156,6 +323,7 @@ func main() {
 	<-quit
 
 	log.Info().Msg("Shutting down server...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -164,5 +332,22 @@ func main() {
 		log.Error().Err(err).Msg("Server forced to shutdown")
 	}
 
+	// Let job runs in progress finish and hand leadership to another replica
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		log.Warn().Msg("Background jobs still running at shutdown")
+	}
+
 	log.Info().Msg("Server exited")
 }
+
+// replicaName names this instance for worker leader election, unique even
+// when pods share a hostname
+func replicaName() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "delivery-service"
+	}
+	return host + "-" + uuid.NewString()[:8]
+}
@@ -5,7 +5,11 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
 // Config holds all configuration values
@@ -25,10 +29,42 @@ type Config struct {
 	MinimumFare        float64
 	ServiceFeePercent  float64
 	
+	// Regulatory price controls by currency
+	PriceControls      map[string]PriceControls
+	
 	// Service URLs
 	PaymentServiceURL  string
 	UserServiceURL     string
 	NotificationURL    string
+	ValhallaURL        string // road-network isochrones for zone checks; empty disables
+	RideServiceURL     string // live courier ETAs, falling back to straight-line estimates, and courier training checks
+	
+	// Leader election for background jobs: redis or postgres
+	WorkerLock         string
+	
+	// Inbound webhook signing: per-source secrets as SOURCE=SECRET entries,
+	// and how far a delivery's timestamp may be from our clock
+	WebhookSecrets     string
+	WebhookTolerance   time.Duration
+	
+	// Encryption of contacts, signatures and recipient IDs at rest: vault
+	// (a transit key), keyring (ENCRYPTION_KEYS, for development) or empty
+	// to store them in the clear
+	EncryptionProvider string
+	EncryptionKeys     string
+	VaultAddr          string
+	VaultToken         string
+	VaultTransitKey    string
+	
+	// Partner data exports: the key download links are signed with and
+	// how long a link stays valid
+	ExportSigningKey   string
+	ExportLinkTTL      time.Duration
+	
+	// Delivery search: the key contact names are hashed with so deliveries
+	// can be found by name while the contacts stay sealed. Changing it
+	// stops existing deliveries matching until their index is rebuilt.
+	SearchIndexKey     string
 }
 
 // Load loads configuration from environment
@@ -48,12 +84,48 @@ func Load() *Config {
 		PerMinuteRate:     15.0,
 		MinimumFare:       800.0,
 		ServiceFeePercent: 0.05,
+		PriceControls:     loadPriceControls(getEnv("PRICE_CONTROLS", "")),
 		
 		// Service URLs
 		PaymentServiceURL:  getEnv("PAYMENT_SERVICE_URL", "http://localhost:4003"),
 		UserServiceURL:     getEnv("USER_SERVICE_URL", "http://localhost:4001"),
 		NotificationURL:    getEnv("NOTIFICATION_SERVICE_URL", "http://localhost:4006"),
+		ValhallaURL:        getEnv("VALHALLA_URL", ""),
+		RideServiceURL:     getEnv("RIDE_SERVICE_URL", "http://localhost:4002"),
+		WorkerLock:         getEnv("WORKER_LEADER_LOCK", "redis"),
+		WebhookSecrets:     getEnv("WEBHOOK_SECRETS", ""),
+		WebhookTolerance:   getEnvDuration("WEBHOOK_TIMESTAMP_TOLERANCE", 5*time.Minute),
+		EncryptionProvider: getEnv("ENCRYPTION_PROVIDER", ""),
+		EncryptionKeys:     getEnv("ENCRYPTION_KEYS", ""),
+		VaultAddr:          getEnv("VAULT_ADDR", "http://localhost:8200"),
+		VaultToken:         getEnv("VAULT_TOKEN", ""),
+		VaultTransitKey:    getEnv("VAULT_TRANSIT_KEY", "delivery-service"),
+		ExportSigningKey:   getEnv("EXPORT_SIGNING_KEY", "export-signing-key"),
+		ExportLinkTTL:      getEnvDuration("EXPORT_LINK_TTL", 15*time.Minute),
+		SearchIndexKey:     getEnv("SEARCH_INDEX_KEY", "search-index-key"),
+	}
+}
+
+// PriceControls are a regulator's limits on delivery fares in one market, in
+// major units. Zero means no limit.
+type PriceControls struct {
+	MaxSurgeMultiplier float64 `json:"maxSurgeMultiplier"`
+	MaxPerKmRate       float64 `json:"maxPerKmRate"`
+	MinPerKmRate       float64 `json:"minPerKmRate"`
+	MinFare            float64 `json:"minFare"`
+}
+
+// loadPriceControls parses PRICE_CONTROLS, a JSON object keyed by currency,
+// e.g. {"KES": {"maxSurgeMultiplier": 2, "minFare": 150}}
+func loadPriceControls(raw string) map[string]PriceControls {
+	controls := map[string]PriceControls{}
+	if raw == "" {
+		return controls
 	}
+	if err := json.Unmarshal([]byte(raw), &controls); err != nil {
+		log.Fatal().Err(err).Msg("Invalid PRICE_CONTROLS")
+	}
+	return controls
 }
 
 func getEnv(key, defaultValue string) string {
@@ -62,3 +134,14 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatal().Err(err).Str("key", key).Msg("Invalid duration")
+		}
+		return d
+	}
+	return defaultValue
+}
@@ -10,11 +10,15 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/envelope"
 )
 
 // DB wraps the database pool
 type DB struct {
-	Pool *pgxpool.Pool
+	Pool      *pgxpool.Pool
+	sealer    *envelope.Sealer // seals sensitive columns; nil stores them in the clear
+	searchKey []byte           // keys the blind index on contact names
 }
 
 // New creates a new database connection pool
@@ -31,6 +35,9 @@ func New(databaseURL string) (*DB, error) {
 	config.MaxConnIdleTime = 30 * time.Minute
 	config.HealthCheckPeriod = 30 * time.Second
 
+	// Scope partners' queries to their own deliveries
+	(&partnerScope{}).install(config)
+
 	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		return nil, err
@@ -46,6 +53,8 @@ func New(databaseURL string) (*DB, error) {
 
 	log.Info().Msg("Connected to database")
 
+	checkTenancyRole(ctx, pool)
+
 	return &DB{Pool: pool}, nil
 }
 
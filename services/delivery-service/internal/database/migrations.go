@@ -0,0 +1,307 @@
+/*
+ * Schema Migrations
+ */
+
+package database
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// migrations are idempotent DDL statements for tables owned by this service,
+// applied in order on startup
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS delivery_refunds (
+		id UUID PRIMARY KEY,
+		delivery_id UUID NOT NULL,
+		customer_id UUID NOT NULL,
+		type VARCHAR(30) NOT NULL,
+		reason VARCHAR(50) NOT NULL,
+		note TEXT,
+		amount DECIMAL(12, 2) NOT NULL CHECK (amount > 0),
+		currency VARCHAR(3) NOT NULL,
+		status VARCHAR(30) NOT NULL,
+		requested_by UUID NOT NULL,
+		approved_by UUID,
+		provider_ref VARCHAR(100),
+		failure_reason TEXT,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_delivery_refunds_delivery_id ON delivery_refunds(delivery_id)`,
+	`CREATE TABLE IF NOT EXISTS delivery_ledger_entries (
+		id UUID PRIMARY KEY,
+		delivery_id UUID NOT NULL,
+		refund_id UUID REFERENCES delivery_refunds(id),
+		account VARCHAR(20) NOT NULL,
+		account_id UUID,
+		amount DECIMAL(12, 2) NOT NULL,
+		currency VARCHAR(3) NOT NULL,
+		description TEXT,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_delivery_ledger_delivery_id ON delivery_ledger_entries(delivery_id)`,
+	`CREATE TABLE IF NOT EXISTS delivery_price_control_violations (
+		id UUID PRIMARY KEY,
+		currency VARCHAR(3) NOT NULL,
+		control VARCHAR(30) NOT NULL,
+		limit_value DECIMAL(12, 2) NOT NULL,
+		computed DECIMAL(12, 2) NOT NULL,
+		applied DECIMAL(12, 2) NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_delivery_price_control_violations_created_at ON delivery_price_control_violations(created_at)`,
+	`CREATE TABLE IF NOT EXISTS courier_shift_slots (
+		id UUID PRIMARY KEY,
+		zone_id VARCHAR(100) NOT NULL,
+		city VARCHAR(100) NOT NULL,
+		starts_at TIMESTAMPTZ NOT NULL,
+		ends_at TIMESTAMPTZ NOT NULL,
+		capacity INT NOT NULL CHECK (capacity > 0),
+		guaranteed_min DECIMAL(12, 2) NOT NULL CHECK (guaranteed_min >= 0),
+		currency VARCHAR(3) NOT NULL,
+		center_latitude DOUBLE PRECISION NOT NULL,
+		center_longitude DOUBLE PRECISION NOT NULL,
+		radius_km DECIMAL(6, 2) NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		created_by UUID NOT NULL,
+		settled_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_courier_shift_slots_starts_at ON courier_shift_slots(starts_at)`,
+	`CREATE TABLE IF NOT EXISTS courier_shift_bookings (
+		id UUID PRIMARY KEY,
+		slot_id UUID NOT NULL REFERENCES courier_shift_slots(id),
+		driver_id UUID NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		online_minutes INT NOT NULL DEFAULT 0,
+		in_zone_minutes INT NOT NULL DEFAULT 0,
+		earnings DECIMAL(12, 2) NOT NULL DEFAULT 0,
+		top_up DECIMAL(12, 2) NOT NULL DEFAULT 0,
+		settled_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_courier_shift_bookings_slot_driver ON courier_shift_bookings(slot_id, driver_id) WHERE status != 'CANCELLED'`,
+	`CREATE INDEX IF NOT EXISTS idx_courier_shift_bookings_driver_id ON courier_shift_bookings(driver_id)`,
+	`CREATE TABLE IF NOT EXISTS courier_shift_attendance (
+		booking_id UUID NOT NULL REFERENCES courier_shift_bookings(id),
+		minute TIMESTAMPTZ NOT NULL,
+		in_zone BOOLEAN NOT NULL,
+		PRIMARY KEY (booking_id, minute)
+	)`,
+	`ALTER TABLE delivery_ledger_entries ALTER COLUMN delivery_id DROP NOT NULL`,
+	`ALTER TABLE delivery_ledger_entries ADD COLUMN IF NOT EXISTS shift_booking_id UUID REFERENCES courier_shift_bookings(id)`,
+	`CREATE TABLE IF NOT EXISTS delivery_hourly_metrics (
+		hour TIMESTAMPTZ NOT NULL,
+		city VARCHAR(100) NOT NULL,
+		currency VARCHAR(3) NOT NULL,
+		created BIGINT NOT NULL DEFAULT 0,
+		delivered BIGINT NOT NULL DEFAULT 0,
+		cancelled BIGINT NOT NULL DEFAULT 0,
+		revenue DECIMAL(14, 2) NOT NULL DEFAULT 0,
+		fare_count BIGINT NOT NULL DEFAULT 0,
+		duration_sum_seconds BIGINT NOT NULL DEFAULT 0,
+		duration_count BIGINT NOT NULL DEFAULT 0,
+		PRIMARY KEY (hour, city, currency)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_deliveries_created_at ON deliveries(created_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_deliveries_delivered_at ON deliveries(delivered_at) WHERE status = 'DELIVERED'`,
+	`CREATE INDEX IF NOT EXISTS idx_deliveries_cancelled_at ON deliveries(cancelled_at) WHERE status = 'CANCELLED'`,
+	// Partner tenancy: new rows take the partner of the connection that
+	// inserts them, and while a partner is set only its rows are visible
+	// or writable. Roles with BYPASSRLS, and superusers, are not bound.
+	`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS partner_id VARCHAR(64) DEFAULT NULLIF(current_setting('app.partner_id', true), '')`,
+	`CREATE INDEX IF NOT EXISTS idx_deliveries_partner_id ON deliveries(partner_id, created_at) WHERE partner_id IS NOT NULL`,
+	`DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'deliveries' AND policyname = 'deliveries_partner_tenancy') THEN
+			CREATE POLICY deliveries_partner_tenancy ON deliveries
+				USING (COALESCE(current_setting('app.partner_id', true), '') = '' OR partner_id = current_setting('app.partner_id', true))
+				WITH CHECK (COALESCE(current_setting('app.partner_id', true), '') = '' OR partner_id = current_setting('app.partner_id', true));
+		END IF;
+	END $$`,
+	`ALTER TABLE deliveries ENABLE ROW LEVEL SECURITY`,
+	`ALTER TABLE deliveries FORCE ROW LEVEL SECURITY`,
+	`CREATE TABLE IF NOT EXISTS partner_access_audit (
+		id UUID PRIMARY KEY,
+		partner_id VARCHAR(64) NOT NULL,
+		delivery_id VARCHAR(50) NOT NULL,
+		owner_partner_id VARCHAR(64),
+		method VARCHAR(10) NOT NULL,
+		path TEXT NOT NULL,
+		request_id VARCHAR(100),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_partner_access_audit_partner ON partner_access_audit(partner_id, created_at)`,
+	// Cold-chain deliveries: certified couriers, each delivery's transit
+	// limit and temperature range, and the temperatures couriers check in
+	`CREATE TABLE IF NOT EXISTS courier_capabilities (
+		driver_id UUID PRIMARY KEY,
+		cold_chain BOOLEAN NOT NULL DEFAULT FALSE,
+		certified_by UUID,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS max_transit_minutes INT`,
+	`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS temperature_min_c DECIMAL(5, 2)`,
+	`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS temperature_max_c DECIMAL(5, 2)`,
+	`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS transit_escalated_at TIMESTAMPTZ`,
+	`CREATE INDEX IF NOT EXISTS idx_deliveries_perishable_in_transit ON deliveries(picked_up_at) WHERE type = 'PERISHABLE' AND status IN ('PICKED_UP', 'IN_TRANSIT') AND transit_escalated_at IS NULL`,
+	`CREATE TABLE IF NOT EXISTS delivery_temperature_checks (
+		id UUID PRIMARY KEY,
+		delivery_id VARCHAR(50) NOT NULL,
+		driver_id UUID NOT NULL,
+		stage VARCHAR(20) NOT NULL,
+		temperature_c DECIMAL(5, 2) NOT NULL,
+		in_range BOOLEAN NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_delivery_temperature_checks_delivery_id ON delivery_temperature_checks(delivery_id, created_at)`,
+	// Regulated deliveries: vetted couriers, and prescriptions and recipient
+	// IDs kept apart from deliveries, readable only through the compliance
+	// endpoint, which logs every read
+	`ALTER TABLE courier_capabilities ADD COLUMN IF NOT EXISTS regulated_goods BOOLEAN NOT NULL DEFAULT FALSE`,
+	`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS regulated BOOLEAN NOT NULL DEFAULT FALSE`,
+	`CREATE TABLE IF NOT EXISTS regulated_delivery_compliance (
+		delivery_id VARCHAR(50) PRIMARY KEY,
+		customer_id UUID NOT NULL,
+		prescription_reference VARCHAR(100),
+		prescriber VARCHAR(200),
+		prescription_issued_at TIMESTAMPTZ,
+		prescription_document_url TEXT,
+		recipient_name VARCHAR(200),
+		recipient_id_type VARCHAR(30),
+		recipient_id_number VARCHAR(50),
+		recipient_id_country VARCHAR(2),
+		verified_by UUID,
+		verified_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`REVOKE ALL ON regulated_delivery_compliance FROM PUBLIC`,
+	`CREATE TABLE IF NOT EXISTS regulated_compliance_access_log (
+		id UUID PRIMARY KEY,
+		delivery_id VARCHAR(50) NOT NULL,
+		accessed_by UUID NOT NULL,
+		role VARCHAR(30) NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_regulated_compliance_access_log_delivery ON regulated_compliance_access_log(delivery_id, created_at)`,
+	`CREATE TABLE IF NOT EXISTS delivery_messages (
+		id VARCHAR(50) PRIMARY KEY,
+		delivery_id VARCHAR(50) NOT NULL,
+		sender_id UUID NOT NULL,
+		sender_role VARCHAR(20) NOT NULL,
+		quick_reply VARCHAR(30),
+		body TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_delivery_messages_delivery_id ON delivery_messages(delivery_id, created_at)`,
+	// Failed delivery attempts and next-day retries
+	`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS failed_attempts INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS failure_reason VARCHAR(40)`,
+	`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ`,
+	`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS failed_at TIMESTAMPTZ`,
+	`CREATE INDEX IF NOT EXISTS idx_deliveries_next_attempt_at ON deliveries(next_attempt_at) WHERE status = 'RETRY_SCHEDULED'`,
+	// Where couriers found addresses whose geocode was wrong, keyed by the
+	// original geocode
+	`CREATE TABLE IF NOT EXISTS address_corrections (
+		key TEXT PRIMARY KEY,
+		address TEXT NOT NULL,
+		original_latitude DOUBLE PRECISION NOT NULL,
+		original_longitude DOUBLE PRECISION NOT NULL,
+		corrected_latitude DOUBLE PRECISION NOT NULL,
+		corrected_longitude DOUBLE PRECISION NOT NULL,
+		reports INT NOT NULL,
+		conflicts INT NOT NULL DEFAULT 0,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE TABLE IF NOT EXISTS address_correction_reports (
+		id UUID PRIMARY KEY,
+		key TEXT NOT NULL,
+		delivery_id VARCHAR(50) NOT NULL,
+		driver_id UUID NOT NULL,
+		latitude DOUBLE PRECISION NOT NULL,
+		longitude DOUBLE PRECISION NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_address_correction_reports_key ON address_correction_reports(key, created_at)`,
+	// How customers want to hear that their courier is nearly there
+	`CREATE TABLE IF NOT EXISTS customer_notification_preferences (
+		customer_id UUID PRIMARY KEY,
+		eta_channel VARCHAR(10) NOT NULL DEFAULT 'PUSH',
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	// Merchants' weekly pickup-ready windows, in minutes after local
+	// midnight, and the times they cannot hand over at all
+	`CREATE TABLE IF NOT EXISTS merchant_pickup_windows (
+		id UUID PRIMARY KEY,
+		merchant_id UUID NOT NULL,
+		day_of_week SMALLINT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6),
+		opens_minute SMALLINT NOT NULL,
+		closes_minute SMALLINT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		CHECK (opens_minute < closes_minute)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_merchant_pickup_windows_merchant ON merchant_pickup_windows(merchant_id)`,
+	`CREATE TABLE IF NOT EXISTS merchant_pickup_blackouts (
+		id UUID PRIMARY KEY,
+		merchant_id UUID NOT NULL,
+		starts_at TIMESTAMPTZ NOT NULL,
+		ends_at TIMESTAMPTZ NOT NULL,
+		reason TEXT,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_merchant_pickup_blackouts_merchant ON merchant_pickup_blackouts(merchant_id, ends_at)`,
+	`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS pickup_window_start TIMESTAMPTZ`,
+	`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS pickup_window_end TIMESTAMPTZ`,
+	`CREATE INDEX IF NOT EXISTS idx_deliveries_pickup_window ON deliveries(customer_id, pickup_window_start) WHERE pickup_window_end IS NOT NULL`,
+	// Sealed values outgrow the original column sizes
+	`ALTER TABLE regulated_delivery_compliance ALTER COLUMN recipient_name TYPE TEXT`,
+	`ALTER TABLE regulated_delivery_compliance ALTER COLUMN recipient_id_number TYPE TEXT`,
+	`ALTER TABLE deliveries ALTER COLUMN recipient_signature TYPE TEXT`,
+	`CREATE TABLE IF NOT EXISTS partner_exports (
+		id UUID PRIMARY KEY,
+		partner_id VARCHAR(64) NOT NULL,
+		requested_by UUID NOT NULL,
+		dataset VARCHAR(20) NOT NULL,
+		format VARCHAR(10) NOT NULL,
+		range_start TIMESTAMPTZ NOT NULL,
+		range_end TIMESTAMPTZ NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		row_count INTEGER,
+		error TEXT,
+		content BYTEA,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		started_at TIMESTAMPTZ,
+		completed_at TIMESTAMPTZ,
+		expires_at TIMESTAMPTZ
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_partner_exports_partner ON partner_exports(partner_id, created_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_partner_exports_queue ON partner_exports(created_at) WHERE status IN ('PENDING', 'RUNNING')`,
+	// Delivery search: keyed hashes of contact name words, since the names
+	// themselves are sealed, and indexes covering the search listing for
+	// customers' and couriers' own deliveries
+	`ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS contact_name_tokens TEXT[]`,
+	`CREATE INDEX IF NOT EXISTS idx_deliveries_contact_name_tokens ON deliveries USING GIN (contact_name_tokens)`,
+	`CREATE INDEX IF NOT EXISTS idx_deliveries_contact_name_unindexed ON deliveries(created_at) WHERE contact_name_tokens IS NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_deliveries_search_customer ON deliveries(customer_id, created_at, id)
+		INCLUDE (tracking_number, type, status, total_fare, currency, payment_status, driver_id, delivered_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_deliveries_search_driver ON deliveries(driver_id, created_at, id)
+		INCLUDE (tracking_number, type, status, total_fare, currency, payment_status, customer_id, delivered_at)
+		WHERE driver_id IS NOT NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_deliveries_search_fare ON deliveries(customer_id, total_fare, id)`,
+	`CREATE INDEX IF NOT EXISTS idx_deliveries_dropoff_city ON deliveries(LOWER(dropoff_location->>'city'), created_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_deliveries_tracking_number ON deliveries(tracking_number)`,
+}
+
+// Migrate applies all migrations
+func (db *DB) Migrate(ctx context.Context) error {
+	for _, stmt := range migrations {
+		if _, err := db.Pool.Exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	log.Info().Int("statements", len(migrations)).Msg("Database migrations applied")
+	return nil
+}
@@ -0,0 +1,179 @@
+/*
+ * Sealed Columns
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/envelope"
+)
+
+// sealedColumn is a column whose values are stored sealed
+type sealedColumn struct {
+	table string
+	key   string // primary key column
+	name  string
+	json  bool // JSONB, sealed as an object; otherwise text
+}
+
+// sealedColumns are the columns holding personal data: delivery contacts,
+// proof-of-delivery signatures, and the IDs recipients show for regulated
+// deliveries
+var sealedColumns = []sealedColumn{
+	{table: "deliveries", key: "id", name: "pickup_contact", json: true},
+	{table: "deliveries", key: "id", name: "dropoff_contact", json: true},
+	{table: "deliveries", key: "id", name: "recipient_signature"},
+	{table: "regulated_delivery_compliance", key: "delivery_id", name: "recipient_name"},
+	{table: "regulated_delivery_compliance", key: "delivery_id", name: "recipient_id_number"},
+}
+
+// isStale reports whether a value is in the clear or sealed under a master
+// key other than keyID, as the re-seal query selects it
+func (col sealedColumn) isStale(value, keyID string) bool {
+	if col.json {
+		return envelope.SealedJSONKeyID([]byte(value)) != keyID
+	}
+	return value != "" && envelope.SealedTextKeyID(value) != keyID
+}
+
+// SetSealer seals sensitive columns with sealer. Without one they are
+// stored in the clear.
+func (db *DB) SetSealer(sealer *envelope.Sealer) {
+	db.sealer = sealer
+}
+
+// SealJSON seals a JSON value for a sensitive JSONB column
+func (db *DB) SealJSON(ctx context.Context, raw []byte) ([]byte, error) {
+	if db.sealer == nil {
+		return raw, nil
+	}
+	return db.sealer.SealJSON(ctx, raw)
+}
+
+// OpenJSON opens a value read from a sensitive JSONB column
+func (db *DB) OpenJSON(ctx context.Context, raw []byte) ([]byte, error) {
+	if db.sealer == nil || len(raw) == 0 {
+		return raw, nil
+	}
+	return db.sealer.OpenJSON(ctx, raw)
+}
+
+// SealText seals a string for a sensitive text column. Empty strings are
+// left empty.
+func (db *DB) SealText(ctx context.Context, text string) (string, error) {
+	if db.sealer == nil || text == "" {
+		return text, nil
+	}
+	return db.sealer.SealText(ctx, text)
+}
+
+// OpenText opens a string read from a sensitive text column
+func (db *DB) OpenText(ctx context.Context, text string) (string, error) {
+	if db.sealer == nil {
+		return text, nil
+	}
+	return db.sealer.OpenText(ctx, text)
+}
+
+// OpenNullText opens a nullable string read from a sensitive text column
+func (db *DB) OpenNullText(ctx context.Context, text *sql.NullString) error {
+	if !text.Valid {
+		return nil
+	}
+	plain, err := db.OpenText(ctx, text.String)
+	if err != nil {
+		return err
+	}
+	text.String = plain
+	return nil
+}
+
+// ResealStale re-seals up to limit values per sensitive column that are
+// in the clear or sealed under a retired master key, so that after a
+// rotation the old key can be disabled. It returns how many it re-sealed.
+func (db *DB) ResealStale(ctx context.Context, limit int) (int, error) {
+	if db.sealer == nil {
+		return 0, nil
+	}
+	keyID, err := db.sealer.Refresh(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, col := range sealedColumns {
+		n, err := db.resealColumn(ctx, col, keyID, limit)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("%s.%s: %w", col.table, col.name, err)
+		}
+	}
+	return total, nil
+}
+
+func (db *DB) resealColumn(ctx context.Context, col sealedColumn, keyID string, limit int) (int, error) {
+	stale := fmt.Sprintf(
+		"%[1]s IS NOT NULL AND %[1]s <> '' AND (%[1]s NOT LIKE 'enc:v1:%%' OR split_part(%[1]s, ':', 3) <> $1)",
+		col.name,
+	)
+	if col.json {
+		stale = fmt.Sprintf("%[1]s IS NOT NULL AND %[1]s->>'kid' IS DISTINCT FROM $1", col.name)
+	}
+	rows, err := db.Pool.Query(ctx, fmt.Sprintf(
+		"SELECT %s, %s::text FROM %s WHERE %s LIMIT $2",
+		col.key, col.name, col.table, stale,
+	), keyID, limit)
+	if err != nil {
+		return 0, err
+	}
+	type staleValue struct{ key, value string }
+	var values []staleValue
+	for rows.Next() {
+		var v staleValue
+		if err := rows.Scan(&v.key, &v.value); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		values = append(values, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// Values changed since they were read are left for the next run
+	update := fmt.Sprintf("UPDATE %[1]s SET %[2]s = $2 WHERE %[3]s = $1 AND %[2]s::text = $3", col.table, col.name, col.key)
+	n := 0
+	for _, v := range values {
+		if !col.isStale(v.value, keyID) {
+			continue
+		}
+		var resealed interface{}
+		if col.json {
+			plain, err := db.sealer.OpenJSON(ctx, []byte(v.value))
+			if err != nil {
+				return n, err
+			}
+			if resealed, err = db.sealer.SealJSON(ctx, plain); err != nil {
+				return n, err
+			}
+		} else {
+			plain, err := db.sealer.OpenText(ctx, v.value)
+			if err != nil {
+				return n, err
+			}
+			if resealed, err = db.sealer.SealText(ctx, plain); err != nil {
+				return n, err
+			}
+		}
+		if _, err := db.Pool.Exec(ctx, update, v.key, resealed, v.value); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/envelope"
+)
+
+func newTestSealer(t *testing.T, spec string) *envelope.Sealer {
+	t.Helper()
+	keyring, err := envelope.ParseKeyring(spec)
+	if err != nil {
+		t.Fatalf("ParseKeyring() error = %v", err)
+	}
+	return envelope.NewSealer(keyring)
+}
+
+func newTestKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func TestSealedColumnIsStaleAfterRotation(t *testing.T) {
+	ctx := context.Background()
+	k1, k2 := newTestKey(t), newTestKey(t)
+	text := sealedColumn{table: "regulated_delivery_compliance", key: "delivery_id", name: "recipient_id_number"}
+	jsonb := sealedColumn{table: "deliveries", key: "id", name: "pickup_contact", json: true}
+
+	before := newTestSealer(t, "k1="+k1)
+	sealedText, err := before.SealText(ctx, "A1234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealedJSON, err := before.SealJSON(ctx, []byte(`{"name":"Amina"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text.isStale(sealedText, "k1") || jsonb.isStale(string(sealedJSON), "k1") {
+		t.Error("values sealed under the current key reported stale")
+	}
+
+	// After k2 becomes current, values sealed under k1 are picked up for
+	// re-sealing and still open
+	after := newTestSealer(t, "k2="+k2+",k1="+k1)
+	keyID, err := after.Refresh(ctx)
+	if err != nil || keyID != "k2" {
+		t.Fatalf("Refresh() = %q, %v, want k2", keyID, err)
+	}
+	if !text.isStale(sealedText, keyID) {
+		t.Error("text sealed under k1 not stale after rotation")
+	}
+	if !jsonb.isStale(string(sealedJSON), keyID) {
+		t.Error("JSON sealed under k1 not stale after rotation")
+	}
+
+	plain, err := after.OpenText(ctx, sealedText)
+	if err != nil || plain != "A1234567" {
+		t.Fatalf("OpenText() after rotation = %q, %v", plain, err)
+	}
+	resealed, err := after.SealText(ctx, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text.isStale(resealed, keyID) {
+		t.Error("re-sealed text still stale")
+	}
+}
+
+func TestSealedColumnIsStalePlaintext(t *testing.T) {
+	text := sealedColumn{name: "recipient_name"}
+	jsonb := sealedColumn{name: "dropoff_contact", json: true}
+
+	if !text.isStale("Amina", "k1") {
+		t.Error("text in the clear not stale")
+	}
+	if text.isStale("", "k1") {
+		t.Error("empty text reported stale")
+	}
+	if !jsonb.isStale(`{"name":"Amina"}`, "k1") {
+		t.Error("JSON in the clear not stale")
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ * Contact Name Index
+ */
+
+package database
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// nameTokenLength is how many hex characters of each word's hash are kept:
+// enough that unrelated names rarely collide, few enough that a token says
+// little about the word on its own
+const nameTokenLength = 16
+
+// SetSearchKey keys the blind index deliveries are found by contact name
+// through
+func (db *DB) SetSearchKey(key []byte) {
+	db.searchKey = key
+}
+
+// NameTokens are the blind index tokens for contact names: a keyed hash of
+// each distinct word, case-folded. Deliveries store the tokens of their
+// contacts' names so that searches match whole words while the names stay
+// sealed.
+func (db *DB) NameTokens(names ...string) []string {
+	seen := make(map[string]bool)
+	tokens := make([]string, 0)
+	for _, name := range names {
+		words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, word := range words {
+			if seen[word] {
+				continue
+			}
+			seen[word] = true
+
+			mac := hmac.New(sha256.New, db.searchKey)
+			mac.Write([]byte(word))
+			tokens = append(tokens, hex.EncodeToString(mac.Sum(nil))[:nameTokenLength])
+		}
+	}
+	return tokens
+}
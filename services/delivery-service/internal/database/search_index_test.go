@@ -0,0 +1,51 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNameTokens(t *testing.T) {
+	db := &DB{}
+	db.SetSearchKey([]byte("search-key"))
+
+	tokens := db.NameTokens("Amina Bello")
+	if len(tokens) != 2 {
+		t.Fatalf("NameTokens() = %v, want one token per word", tokens)
+	}
+	for _, token := range tokens {
+		if len(token) != nameTokenLength {
+			t.Errorf("token %q is %d characters, want %d", token, len(token), nameTokenLength)
+		}
+	}
+
+	// Searches match whole words whatever their case or punctuation
+	if got := db.NameTokens("  BELLO, amina! "); !reflect.DeepEqual(got, []string{tokens[1], tokens[0]}) {
+		t.Errorf("NameTokens() = %v, want the same words' tokens %v", got, tokens)
+	}
+	if got := db.NameTokens("Bello"); !reflect.DeepEqual(got, tokens[1:]) {
+		t.Errorf("NameTokens(Bello) = %v, want %v", got, tokens[1:])
+	}
+	if got := db.NameTokens("Belo"); reflect.DeepEqual(got, tokens[1:]) {
+		t.Error("a different word got the same token")
+	}
+
+	// Words shared between names are indexed once
+	if got := db.NameTokens("Amina Bello", "Musa Bello"); len(got) != 3 {
+		t.Errorf("NameTokens() = %v, want 3 distinct words", got)
+	}
+	if got := db.NameTokens("Adébáyọ O'Neil-Ọlá"); len(got) != 4 {
+		t.Errorf("NameTokens() = %v, want 4 words", got)
+	}
+
+	// Nothing to search by is an empty list, not a NULL array
+	if got := db.NameTokens("", " - "); got == nil || len(got) != 0 {
+		t.Errorf("NameTokens() = %#v, want an empty list", got)
+	}
+
+	other := &DB{}
+	other.SetSearchKey([]byte("other-key"))
+	if got := other.NameTokens("Amina Bello"); reflect.DeepEqual(got, tokens) {
+		t.Error("tokens are the same under another key")
+	}
+}
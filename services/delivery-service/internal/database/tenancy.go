@@ -0,0 +1,148 @@
+/*
+ * Partner Tenancy
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// partnerSetting is the session setting the deliveries row-level security
+// policy reads the current partner from
+const partnerSetting = "app.partner_id"
+
+type partnerKey struct{}
+
+// WithPartner scopes every query made with the returned context to a
+// partner's deliveries. An empty partnerID lifts the scope.
+func WithPartner(ctx context.Context, partnerID string) context.Context {
+	return context.WithValue(ctx, partnerKey{}, partnerID)
+}
+
+// PartnerFrom gets the partner a context is scoped to, or "" when none
+func PartnerFrom(ctx context.Context) string {
+	if partnerID, ok := ctx.Value(partnerKey{}).(string); ok {
+		return partnerID
+	}
+	return ""
+}
+
+// partnerScope sets the partner on each connection as it is acquired for
+// a partner's request and clears it on release, so Postgres enforces
+// tenancy on every query without handlers filtering by partner
+type partnerScope struct {
+	mu     sync.Mutex
+	scoped map[*pgx.Conn]bool
+}
+
+func (s *partnerScope) install(config *pgxpool.Config) {
+	s.scoped = make(map[*pgx.Conn]bool)
+	config.BeforeAcquire = s.beforeAcquire
+	config.AfterRelease = s.afterRelease
+}
+
+func (s *partnerScope) beforeAcquire(ctx context.Context, conn *pgx.Conn) bool {
+	partnerID := PartnerFrom(ctx)
+	if partnerID == "" {
+		return true
+	}
+	if _, err := conn.Exec(ctx, "SELECT set_config($1, $2, false)", partnerSetting, partnerID); err != nil {
+		// Refusing the connection is safer than handing out an unscoped one
+		log.Error().Err(err).Msg("Failed to scope connection to partner")
+		return false
+	}
+
+	s.mu.Lock()
+	s.scoped[conn] = true
+	s.mu.Unlock()
+	return true
+}
+
+func (s *partnerScope) afterRelease(conn *pgx.Conn) bool {
+	s.mu.Lock()
+	scoped := s.scoped[conn]
+	delete(s.scoped, conn)
+	s.mu.Unlock()
+	if !scoped {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := conn.Exec(ctx, "SELECT set_config($1, '', false)", partnerSetting); err != nil {
+		// Drop the connection rather than let the scope leak to the next caller
+		log.Error().Err(err).Msg("Failed to clear partner scope")
+		return false
+	}
+	return true
+}
+
+// checkTenancyRole logs an error when the service connects as a role that
+// row-level security does not apply to. Superusers and roles with
+// BYPASSRLS see every partner's deliveries whatever the scope, so the
+// service must run as a plain role.
+func checkTenancyRole(ctx context.Context, pool *pgxpool.Pool) {
+	var role string
+	var super, bypassRLS bool
+	err := pool.QueryRow(ctx,
+		"SELECT rolname, rolsuper, rolbypassrls FROM pg_roles WHERE rolname = current_user",
+	).Scan(&role, &super, &bypassRLS)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check database role for partner tenancy")
+		return
+	}
+	if super || bypassRLS {
+		log.Error().
+			Str("role", role).
+			Bool("rolsuper", super).
+			Bool("rolbypassrls", bypassRLS).
+			Msg("Database role bypasses row-level security; partner tenancy is not enforced")
+	}
+}
+
+// PartnerAccess is a partner's attempt to reach a delivery it does not own
+type PartnerAccess struct {
+	PartnerID      string
+	DeliveryID     string
+	OwnerPartnerID *string // nil for a first-party delivery
+	Method         string
+	Path           string
+	RequestID      string
+}
+
+// DeliveryPartner gets the partner that owns a delivery, nil for a
+// first-party delivery. It reads outside any partner scope so deliveries
+// hidden from the caller are still found.
+func (db *DB) DeliveryPartner(ctx context.Context, deliveryID string) (*string, bool, error) {
+	var owner *string
+	err := db.Pool.QueryRow(WithPartner(ctx, ""),
+		"SELECT partner_id FROM deliveries WHERE id = $1", deliveryID,
+	).Scan(&owner)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return owner, true, nil
+}
+
+// RecordPartnerAccess stores a cross-tenant access attempt for audit
+func (db *DB) RecordPartnerAccess(ctx context.Context, access *PartnerAccess) error {
+	_, err := db.Pool.Exec(WithPartner(ctx, ""), `
+		INSERT INTO partner_access_audit (id, partner_id, delivery_id, owner_partner_id, method, path, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())`,
+		uuid.New(), access.PartnerID, access.DeliveryID, access.OwnerPartnerID,
+		access.Method, access.Path, access.RequestID,
+	)
+	return err
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestWithPartner(t *testing.T) {
+	ctx := context.Background()
+	if got := PartnerFrom(ctx); got != "" {
+		t.Errorf("PartnerFrom(unscoped) = %q, want empty", got)
+	}
+
+	scoped := WithPartner(ctx, "acme")
+	if got := PartnerFrom(scoped); got != "acme" {
+		t.Errorf("PartnerFrom(scoped) = %q, want acme", got)
+	}
+
+	// An empty partner lifts the scope for lookups that must see every
+	// tenant's deliveries
+	if got := PartnerFrom(WithPartner(scoped, "")); got != "" {
+		t.Errorf("PartnerFrom(lifted) = %q, want empty", got)
+	}
+}
+
+func TestPartnerScopeInstall(t *testing.T) {
+	config := &pgxpool.Config{}
+	scope := &partnerScope{}
+	scope.install(config)
+
+	if config.BeforeAcquire == nil || config.AfterRelease == nil {
+		t.Fatal("install() did not set the pool's acquire and release hooks")
+	}
+	if scope.scoped == nil {
+		t.Fatal("install() did not initialise the scoped connections")
+	}
+}
+
+func TestPartnerScopeUnscoped(t *testing.T) {
+	scope := &partnerScope{}
+	scope.install(&pgxpool.Config{})
+
+	// Connections acquired without a partner are handed out untouched, and
+	// released without clearing anything
+	if !scope.beforeAcquire(context.Background(), nil) {
+		t.Error("beforeAcquire() refused a connection for a first-party caller")
+	}
+	if !scope.afterRelease(nil) {
+		t.Error("afterRelease() dropped a connection that was never scoped")
+	}
+	if len(scope.scoped) != 0 {
+		t.Errorf("scoped connections = %d, want none", len(scope.scoped))
+	}
+}
@@ -0,0 +1,299 @@
+/*
+ * Envelope Encryption
+ */
+
+// Package envelope encrypts sensitive values at rest. Each value is sealed
+// with AES-256-GCM under a data key, and the data key is stored beside it
+// wrapped by a master key held in a KMS. Rotating the master key only
+// changes the key new data keys are wrapped under; values sealed before
+// stay readable and are re-sealed in the background.
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sealing settings
+const (
+	version         = "v1"
+	textPrefix      = "enc:" + version + ":"
+	dataKeyBytes    = 32
+	dataKeyLifetime = time.Hour // how long one data key seals new values
+	maxOpenKeys     = 1024      // unwrapped data keys kept for reading
+)
+
+// ErrMalformed is returned for sealed values that cannot be parsed
+var ErrMalformed = errors.New("malformed sealed value")
+
+// KeyProvider wraps and unwraps data keys with master keys it never
+// releases
+type KeyProvider interface {
+	// CurrentKeyID names the master key new data keys are wrapped under
+	CurrentKeyID(ctx context.Context) (string, error)
+	// WrapKey wraps a data key under the current master key and names it
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	// UnwrapKey unwraps a data key wrapped under the named master key
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Sealed is an encrypted value with the data key it was sealed under
+type Sealed struct {
+	Version    string `json:"enc"`
+	KeyID      string `json:"kid"` // master key the data key is wrapped under
+	DataKey    string `json:"dek"` // wrapped data key, base64
+	Ciphertext string `json:"ct"`  // nonce and ciphertext, base64
+}
+
+// dataKey is a data key in the clear with its wrapped form
+type dataKey struct {
+	keyID   string
+	wrapped string
+	aead    cipher.AEAD
+	expires time.Time
+}
+
+// Sealer seals and opens values. One data key seals all new values for an
+// hour, so the KMS is called once an hour rather than once a value, and
+// data keys already unwrapped are kept for reading.
+type Sealer struct {
+	provider KeyProvider
+
+	mu      sync.Mutex
+	current *dataKey
+	open    map[string]cipher.AEAD // by wrapped data key
+}
+
+// NewSealer creates a sealer over a key provider
+func NewSealer(provider KeyProvider) *Sealer {
+	return &Sealer{
+		provider: provider,
+		open:     make(map[string]cipher.AEAD),
+	}
+}
+
+// Seal encrypts a value under the current data key
+func (s *Sealer) Seal(ctx context.Context, plaintext []byte) (*Sealed, error) {
+	key, err := s.currentKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, key.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &Sealed{
+		Version:    version,
+		KeyID:      key.keyID,
+		DataKey:    key.wrapped,
+		Ciphertext: base64.StdEncoding.EncodeToString(key.aead.Seal(nonce, nonce, plaintext, nil)),
+	}, nil
+}
+
+// Open decrypts a sealed value
+func (s *Sealer) Open(ctx context.Context, sealed *Sealed) ([]byte, error) {
+	if sealed.Version != version {
+		return nil, fmt.Errorf("%w: version %q", ErrMalformed, sealed.Version)
+	}
+	aead, err := s.openKey(ctx, sealed.KeyID, sealed.DataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(sealed.Ciphertext)
+	if err != nil || len(data) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+// Refresh retires the current data key if the master key was rotated, so
+// new values are sealed under the new master key
+func (s *Sealer) Refresh(ctx context.Context) (string, error) {
+	keyID, err := s.provider.CurrentKeyID(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	if s.current != nil && s.current.keyID != keyID {
+		s.current = nil
+	}
+	s.mu.Unlock()
+	return keyID, nil
+}
+
+// currentKey gets the data key new values are sealed under, making a new
+// one when it has expired
+func (s *Sealer) currentKey(ctx context.Context) (*dataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current != nil && time.Now().Before(s.current.expires) {
+		return s.current, nil
+	}
+
+	plain := make([]byte, dataKeyBytes)
+	if _, err := rand.Read(plain); err != nil {
+		return nil, err
+	}
+	keyID, wrapped, err := s.provider.WrapKey(ctx, plain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	if strings.Contains(keyID, ":") {
+		return nil, fmt.Errorf("master key ID %q may not contain ':'", keyID)
+	}
+	aead, err := newAEAD(plain)
+	if err != nil {
+		return nil, err
+	}
+
+	s.current = &dataKey{
+		keyID:   keyID,
+		wrapped: base64.StdEncoding.EncodeToString(wrapped),
+		aead:    aead,
+		expires: time.Now().Add(dataKeyLifetime),
+	}
+	s.remember(s.current.wrapped, aead)
+	return s.current, nil
+}
+
+// openKey gets a data key for reading, unwrapping it the first time
+func (s *Sealer) openKey(ctx context.Context, keyID, wrapped string) (cipher.AEAD, error) {
+	s.mu.Lock()
+	aead, ok := s.open[wrapped]
+	s.mu.Unlock()
+	if ok {
+		return aead, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	plain, err := s.provider.UnwrapKey(ctx, keyID, raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	if aead, err = newAEAD(plain); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.remember(wrapped, aead)
+	s.mu.Unlock()
+	return aead, nil
+}
+
+// remember keeps an unwrapped data key, starting over when too many are
+// kept. Callers hold mu.
+func (s *Sealer) remember(wrapped string, aead cipher.AEAD) {
+	if len(s.open) >= maxOpenKeys {
+		s.open = make(map[string]cipher.AEAD)
+	}
+	s.open[wrapped] = aead
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SealJSON seals a JSON value into a JSON object, for JSONB columns
+func (s *Sealer) SealJSON(ctx context.Context, raw []byte) ([]byte, error) {
+	sealed, err := s.Seal(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(sealed)
+}
+
+// OpenJSON opens a value sealed by SealJSON. Values stored before sealing
+// are returned as they are.
+func (s *Sealer) OpenJSON(ctx context.Context, raw []byte) ([]byte, error) {
+	sealed, ok := parseJSON(raw)
+	if !ok {
+		return raw, nil
+	}
+	return s.Open(ctx, sealed)
+}
+
+// SealText seals a string into enc:v1:<key ID>:<data key>:<ciphertext>,
+// for text columns
+func (s *Sealer) SealText(ctx context.Context, plaintext string) (string, error) {
+	sealed, err := s.Seal(ctx, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return textPrefix + sealed.KeyID + ":" + sealed.DataKey + ":" + sealed.Ciphertext, nil
+}
+
+// OpenText opens a string sealed by SealText. Strings stored before sealing
+// are returned as they are.
+func (s *Sealer) OpenText(ctx context.Context, text string) (string, error) {
+	if !strings.HasPrefix(text, textPrefix) {
+		return text, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(text, textPrefix), ":")
+	if len(parts) != 3 {
+		return "", ErrMalformed
+	}
+	plain, err := s.Open(ctx, &Sealed{Version: version, KeyID: parts[0], DataKey: parts[1], Ciphertext: parts[2]})
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// IsSealedJSON reports whether a JSON value was sealed by SealJSON
+func IsSealedJSON(raw []byte) bool {
+	_, ok := parseJSON(raw)
+	return ok
+}
+
+// IsSealedText reports whether a string was sealed by SealText
+func IsSealedText(text string) bool {
+	return strings.HasPrefix(text, textPrefix)
+}
+
+// SealedJSONKeyID names the master key a value sealed by SealJSON is
+// wrapped under, "" for a value in the clear
+func SealedJSONKeyID(raw []byte) string {
+	sealed, ok := parseJSON(raw)
+	if !ok {
+		return ""
+	}
+	return sealed.KeyID
+}
+
+// SealedTextKeyID names the master key a string sealed by SealText is
+// wrapped under, "" for a string in the clear
+func SealedTextKeyID(text string) string {
+	if !IsSealedText(text) {
+		return ""
+	}
+	keyID, _, _ := strings.Cut(strings.TrimPrefix(text, textPrefix), ":")
+	return keyID
+}
+
+func parseJSON(raw []byte) (*Sealed, bool) {
+	var sealed Sealed
+	if json.Unmarshal(raw, &sealed) != nil || sealed.Version == "" || sealed.Ciphertext == "" {
+		return nil, false
+	}
+	return &sealed, true
+}
@@ -0,0 +1,192 @@
+package envelope
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func newTestKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, dataKeyBytes)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func newTestSealer(t *testing.T, spec string) *Sealer {
+	t.Helper()
+	keyring, err := ParseKeyring(spec)
+	if err != nil {
+		t.Fatalf("ParseKeyring() error = %v", err)
+	}
+	return NewSealer(keyring)
+}
+
+func TestSealJSONRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	sealer := newTestSealer(t, "k1="+newTestKey(t))
+	plain := []byte(`{"name":"Amina","phone":"+254700000000"}`)
+
+	sealed, err := sealer.SealJSON(ctx, plain)
+	if err != nil {
+		t.Fatalf("SealJSON() error = %v", err)
+	}
+	if strings.Contains(string(sealed), "Amina") {
+		t.Fatalf("sealed value %s contains the plaintext", sealed)
+	}
+	if !IsSealedJSON(sealed) {
+		t.Error("IsSealedJSON() = false for a sealed value")
+	}
+	if got := SealedJSONKeyID(sealed); got != "k1" {
+		t.Errorf("SealedJSONKeyID() = %q, want k1", got)
+	}
+
+	opened, err := sealer.OpenJSON(ctx, sealed)
+	if err != nil {
+		t.Fatalf("OpenJSON() error = %v", err)
+	}
+	if string(opened) != string(plain) {
+		t.Errorf("OpenJSON() = %s, want %s", opened, plain)
+	}
+}
+
+func TestSealTextRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	sealer := newTestSealer(t, "k1="+newTestKey(t))
+
+	sealed, err := sealer.SealText(ctx, "A1234567")
+	if err != nil {
+		t.Fatalf("SealText() error = %v", err)
+	}
+	if !strings.HasPrefix(sealed, "enc:v1:k1:") || strings.Contains(sealed, "A1234567") {
+		t.Fatalf("SealText() = %q, want enc:v1:k1:... without the plaintext", sealed)
+	}
+	if got := SealedTextKeyID(sealed); got != "k1" {
+		t.Errorf("SealedTextKeyID() = %q, want k1", got)
+	}
+
+	opened, err := sealer.OpenText(ctx, sealed)
+	if err != nil {
+		t.Fatalf("OpenText() error = %v", err)
+	}
+	if opened != "A1234567" {
+		t.Errorf("OpenText() = %q, want A1234567", opened)
+	}
+}
+
+func TestOpenLegacyPlaintext(t *testing.T) {
+	ctx := context.Background()
+	sealer := newTestSealer(t, "k1="+newTestKey(t))
+
+	raw := []byte(`{"name":"Amina","phone":"+254700000000"}`)
+	if IsSealedJSON(raw) || SealedJSONKeyID(raw) != "" {
+		t.Error("plain JSON reported as sealed")
+	}
+	opened, err := sealer.OpenJSON(ctx, raw)
+	if err != nil || string(opened) != string(raw) {
+		t.Errorf("OpenJSON(plain) = %s, %v, want it unchanged", opened, err)
+	}
+
+	if IsSealedText("A1234567") || SealedTextKeyID("A1234567") != "" {
+		t.Error("plain text reported as sealed")
+	}
+	text, err := sealer.OpenText(ctx, "A1234567")
+	if err != nil || text != "A1234567" {
+		t.Errorf("OpenText(plain) = %q, %v, want it unchanged", text, err)
+	}
+}
+
+func TestOpenTampered(t *testing.T) {
+	ctx := context.Background()
+	sealer := newTestSealer(t, "k1="+newTestKey(t))
+
+	sealed, err := sealer.Seal(ctx, []byte("Amina"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	data, _ := base64.StdEncoding.DecodeString(sealed.Ciphertext)
+	data[len(data)-1] ^= 0xff
+	sealed.Ciphertext = base64.StdEncoding.EncodeToString(data)
+
+	if _, err := sealer.Open(ctx, sealed); err == nil {
+		t.Error("Open() of a tampered ciphertext succeeded")
+	}
+
+	raw, _ := json.Marshal(sealed)
+	if _, err := sealer.OpenJSON(ctx, raw); err == nil {
+		t.Error("OpenJSON() of a tampered ciphertext succeeded")
+	}
+
+	if _, err := sealer.OpenText(ctx, "enc:v1:k1:only-two"); err != ErrMalformed {
+		t.Errorf("OpenText() of a truncated value error = %v, want ErrMalformed", err)
+	}
+}
+
+func TestOpenAfterRotation(t *testing.T) {
+	ctx := context.Background()
+	k1, k2 := newTestKey(t), newTestKey(t)
+
+	sealed, err := newTestSealer(t, "k1="+k1).SealText(ctx, "A1234567")
+	if err != nil {
+		t.Fatalf("SealText() error = %v", err)
+	}
+
+	// k2 becomes current; k1 only unwraps
+	rotated := newTestSealer(t, "k2="+k2+",k1="+k1)
+	opened, err := rotated.OpenText(ctx, sealed)
+	if err != nil {
+		t.Fatalf("OpenText() after rotation error = %v", err)
+	}
+	if opened != "A1234567" {
+		t.Errorf("OpenText() after rotation = %q, want A1234567", opened)
+	}
+
+	resealed, err := rotated.SealText(ctx, opened)
+	if err != nil {
+		t.Fatalf("SealText() after rotation error = %v", err)
+	}
+	if got := SealedTextKeyID(resealed); got != "k2" {
+		t.Errorf("resealed under %q, want k2", got)
+	}
+
+	// Once k1 is retired, values still sealed under it cannot be read
+	if _, err := newTestSealer(t, "k2="+k2).OpenText(ctx, sealed); err == nil {
+		t.Error("OpenText() under a retired key succeeded")
+	}
+}
+
+func TestRefreshRetiresDataKey(t *testing.T) {
+	ctx := context.Background()
+	k1, k2 := newTestKey(t), newTestKey(t)
+	keyring, err := ParseKeyring("k1=" + k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealer := NewSealer(keyring)
+
+	if _, err := sealer.Seal(ctx, []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := ParseKeyring("k2=" + k2 + ",k1=" + k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	*keyring = *rotated
+
+	keyID, err := sealer.Refresh(ctx)
+	if err != nil || keyID != "k2" {
+		t.Fatalf("Refresh() = %q, %v, want k2", keyID, err)
+	}
+	sealed, err := sealer.Seal(ctx, []byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sealed.KeyID != "k2" {
+		t.Errorf("sealed after Refresh() under %q, want k2", sealed.KeyID)
+	}
+}
@@ -0,0 +1,74 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestParseKeyring(t *testing.T) {
+	key := newTestKey(t)
+
+	tests := []struct {
+		name        string
+		spec        string
+		wantCurrent string
+		wantErr     bool
+	}{
+		{"single", "k1=" + key, "k1", false},
+		{"newest first", "k2=" + key + ", k1=" + key, "k2", false},
+		{"empty", "", "", true},
+		{"missing ID", "=" + key, "", true},
+		{"ID with colon", "k:1=" + key, "", true},
+		{"short key", "k1=c2hvcnQ=", "", true},
+		{"not base64", "k1=not-base64!", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring, err := ParseKeyring(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKeyring() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got, _ := keyring.CurrentKeyID(context.Background()); got != tt.wantCurrent {
+				t.Errorf("CurrentKeyID() = %q, want %q", got, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestKeyringWrapUnwrap(t *testing.T) {
+	ctx := context.Background()
+	keyring, err := ParseKeyring("k2=" + newTestKey(t) + ",k1=" + newTestKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataKey := bytes.Repeat([]byte{7}, dataKeyBytes)
+
+	keyID, wrapped, err := keyring.WrapKey(ctx, dataKey)
+	if err != nil {
+		t.Fatalf("WrapKey() error = %v", err)
+	}
+	if keyID != "k2" {
+		t.Errorf("WrapKey() key ID = %q, want k2", keyID)
+	}
+
+	unwrapped, err := keyring.UnwrapKey(ctx, keyID, wrapped)
+	if err != nil || !bytes.Equal(unwrapped, dataKey) {
+		t.Errorf("UnwrapKey() = %x, %v, want the data key", unwrapped, err)
+	}
+
+	// The key ID is bound to the wrapped key
+	if _, err := keyring.UnwrapKey(ctx, "k1", wrapped); err == nil {
+		t.Error("UnwrapKey() under the wrong key ID succeeded")
+	}
+	if _, err := keyring.UnwrapKey(ctx, "k3", wrapped); err == nil {
+		t.Error("UnwrapKey() under an unknown key ID succeeded")
+	}
+	if _, err := keyring.UnwrapKey(ctx, keyID, wrapped[:4]); err != ErrMalformed {
+		t.Errorf("UnwrapKey() of a truncated key error = %v, want ErrMalformed", err)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+ * Vault Transit Key Provider
+ */
+
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var vaultClient = &http.Client{Timeout: 5 * time.Second}
+
+// VaultTransit wraps data keys with a Vault transit key, which never leaves
+// Vault. Rotating the transit key in Vault adds a version; data keys are
+// then wrapped under the new version and older versions still unwrap.
+type VaultTransit struct {
+	addr  string
+	token string
+	key   string
+}
+
+// NewVaultTransit creates a provider for a transit key at a Vault address
+func NewVaultTransit(addr, token, key string) *VaultTransit {
+	return &VaultTransit{addr: strings.TrimRight(addr, "/"), token: token, key: key}
+}
+
+// CurrentKeyID names the transit key's latest version
+func (v *VaultTransit) CurrentKeyID(ctx context.Context) (string, error) {
+	var resp struct {
+		Data struct {
+			LatestVersion int `json:"latest_version"`
+		} `json:"data"`
+	}
+	if err := v.call(ctx, http.MethodGet, "/v1/transit/keys/"+v.key, nil, &resp); err != nil {
+		return "", err
+	}
+	return v.keyID(resp.Data.LatestVersion), nil
+}
+
+// WrapKey encrypts a data key with the transit key's latest version
+func (v *VaultTransit) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(dataKey)}
+	if err := v.call(ctx, http.MethodPost, "/v1/transit/encrypt/"+v.key, body, &resp); err != nil {
+		return "", nil, err
+	}
+
+	// Ciphertexts are vault:v<version>:<data>
+	parts := strings.SplitN(resp.Data.Ciphertext, ":", 3)
+	if len(parts) != 3 || !strings.HasPrefix(parts[1], "v") {
+		return "", nil, fmt.Errorf("unexpected transit ciphertext")
+	}
+	version, err := strconv.Atoi(strings.TrimPrefix(parts[1], "v"))
+	if err != nil {
+		return "", nil, fmt.Errorf("unexpected transit ciphertext version %q", parts[1])
+	}
+	return v.keyID(version), []byte(resp.Data.Ciphertext), nil
+}
+
+// UnwrapKey decrypts a data key with the transit key version it names
+func (v *VaultTransit) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"ciphertext": string(wrapped)}
+	if err := v.call(ctx, http.MethodPost, "/v1/transit/decrypt/"+v.key, body, &resp); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+}
+
+// keyID names a version of the transit key, e.g. deliveries-v3
+func (v *VaultTransit) keyID(version int) string {
+	return v.key + "-v" + strconv.Itoa(version)
+}
+
+func (v *VaultTransit) call(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, v.addr+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := vaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault %s %s returned %d", method, path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,198 @@
+/*
+ * Address Corrections
+ */
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+const (
+	// addressCorrectionAgreeKm is how close two reported dropoffs must be
+	// to count as the same correction
+	addressCorrectionAgreeKm = 0.075
+
+	// addressCorrectionMinKm is how far a dropoff must be from the pin
+	// before it is worth learning from
+	addressCorrectionMinKm = 0.03
+)
+
+// Confidence in a learned correction, by how many reports agree on it
+const (
+	correctionConfidenceLow    = "LOW"
+	correctionConfidenceMedium = "MEDIUM"
+	correctionConfidenceHigh   = "HIGH"
+)
+
+func correctionConfidence(reports int) string {
+	switch {
+	case reports >= 3:
+		return correctionConfidenceHigh
+	case reports == 2:
+		return correctionConfidenceMedium
+	default:
+		return correctionConfidenceLow
+	}
+}
+
+// addressCorrectionKey keys a location by its original geocode: the
+// address text as typed, folded, with the coordinates rounded to about
+// 10m. A location that was already corrected is keyed by the coordinates
+// it was corrected from.
+func addressCorrectionKey(loc models.Location) string {
+	lat, lng := loc.Latitude, loc.Longitude
+	if loc.Correction != nil {
+		lat, lng = loc.Correction.OriginalLatitude, loc.Correction.OriginalLongitude
+	}
+	address := strings.Join(strings.Fields(strings.ToLower(loc.Address)), " ")
+	return fmt.Sprintf("%.4f,%.4f|%s", lat, lng, address)
+}
+
+// learnedCorrection is where couriers say an address really is, with how
+// many reports agree and how many have disagreed since
+type learnedCorrection struct {
+	lat, lng  float64
+	reports   int
+	conflicts int
+}
+
+// withReport folds in a new courier's report. Agreeing reports pull the
+// correction towards their average; a disagreeing one replaces a
+// correction only one courier has vouched for, and otherwise counts
+// against it until it is outvoted.
+func (c learnedCorrection) withReport(lat, lng float64) learnedCorrection {
+	switch {
+	case haversineDistance(c.lat, c.lng, lat, lng) <= addressCorrectionAgreeKm:
+		c.lat = (c.lat*float64(c.reports) + lat) / float64(c.reports+1)
+		c.lng = (c.lng*float64(c.reports) + lng) / float64(c.reports+1)
+		c.reports++
+	case c.reports <= 1 || c.conflicts+1 >= c.reports:
+		return learnedCorrection{lat: lat, lng: lng, reports: 1}
+	default:
+		c.conflicts++
+	}
+	return c
+}
+
+// applyAddressCorrection moves a location to where couriers have found it
+// really is, when they have reported it, and flags how sure that is
+func (h *Handler) applyAddressCorrection(ctx context.Context, loc *models.Location) {
+	if loc.Correction != nil || (loc.Latitude == 0 && loc.Longitude == 0) {
+		return
+	}
+
+	var lat, lng float64
+	var reports int
+	err := h.db.Pool.QueryRow(ctx,
+		"SELECT corrected_latitude, corrected_longitude, reports FROM address_corrections WHERE key = $1",
+		addressCorrectionKey(*loc),
+	).Scan(&lat, &lng, &reports)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to look up address correction")
+		return
+	}
+
+	loc.Correction = &models.LocationCorrection{
+		OriginalLatitude:  loc.Latitude,
+		OriginalLongitude: loc.Longitude,
+		Confidence:        correctionConfidence(reports),
+		Reports:           reports,
+	}
+	loc.Latitude, loc.Longitude = lat, lng
+}
+
+// recordAddressCorrection learns from a courier finding a delivery's
+// dropoff somewhere other than its pin. Each courier's first report there
+// is folded into the stored correction.
+func (h *Handler) recordAddressCorrection(ctx context.Context, deliveryID, driverID string, lat, lng float64) error {
+	if lat == 0 && lng == 0 {
+		return nil
+	}
+
+	var dropoff models.Location
+	if err := h.db.Pool.QueryRow(ctx,
+		"SELECT dropoff_location FROM deliveries WHERE id = $1", deliveryID,
+	).Scan(&dropoff); err != nil {
+		return err
+	}
+	origLat, origLng := dropoff.Latitude, dropoff.Longitude
+	if dropoff.Correction != nil {
+		origLat, origLng = dropoff.Correction.OriginalLatitude, dropoff.Correction.OriginalLongitude
+	}
+	if haversineDistance(origLat, origLng, lat, lng) < addressCorrectionMinKm {
+		return nil
+	}
+	key := addressCorrectionKey(dropoff)
+
+	tx, err := h.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// A courier vouches for a correction once, however often they go there
+	var reportedBefore bool
+	if err := tx.QueryRow(ctx,
+		"SELECT EXISTS (SELECT 1 FROM address_correction_reports WHERE key = $1 AND driver_id = $2)",
+		key, driverID,
+	).Scan(&reportedBefore); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO address_correction_reports (id, key, delivery_id, driver_id, latitude, longitude, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())`,
+		uuid.New(), key, deliveryID, driverID, lat, lng,
+	)
+	if err != nil {
+		return err
+	}
+
+	var c learnedCorrection
+	err = tx.QueryRow(ctx, `
+		SELECT corrected_latitude, corrected_longitude, reports, conflicts
+		FROM address_corrections WHERE key = $1 FOR UPDATE`,
+		key,
+	).Scan(&c.lat, &c.lng, &c.reports, &c.conflicts)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c = learnedCorrection{lat: lat, lng: lng, reports: 1}
+	case err != nil:
+		return err
+	case reportedBefore:
+		return tx.Commit(ctx)
+	default:
+		c = c.withReport(lat, lng)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO address_corrections (
+			key, address, original_latitude, original_longitude,
+			corrected_latitude, corrected_longitude, reports, conflicts, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			corrected_latitude = EXCLUDED.corrected_latitude,
+			corrected_longitude = EXCLUDED.corrected_longitude,
+			reports = EXCLUDED.reports,
+			conflicts = EXCLUDED.conflicts,
+			updated_at = NOW()`,
+		key, dropoff.Address, origLat, origLng, c.lat, c.lng, c.reports, c.conflicts,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+func TestAddressCorrectionKey(t *testing.T) {
+	loc := models.Location{Latitude: 6.524379, Longitude: 3.379206, Address: "12  Marina Road, Lagos "}
+	want := "6.5244,3.3792|12 marina road, lagos"
+	if got := addressCorrectionKey(loc); got != want {
+		t.Errorf("addressCorrectionKey() = %q, want %q", got, want)
+	}
+
+	// Once corrected, the location keeps the key of where it was geocoded
+	corrected := loc
+	corrected.Latitude, corrected.Longitude = 6.5301, 3.3850
+	corrected.Correction = &models.LocationCorrection{OriginalLatitude: loc.Latitude, OriginalLongitude: loc.Longitude}
+	if got := addressCorrectionKey(corrected); got != want {
+		t.Errorf("addressCorrectionKey(corrected) = %q, want %q", got, want)
+	}
+}
+
+func TestCorrectionConfidence(t *testing.T) {
+	for reports, want := range map[int]string{
+		1: correctionConfidenceLow,
+		2: correctionConfidenceMedium,
+		3: correctionConfidenceHigh,
+		7: correctionConfidenceHigh,
+	} {
+		if got := correctionConfidence(reports); got != want {
+			t.Errorf("correctionConfidence(%d) = %s, want %s", reports, got, want)
+		}
+	}
+}
+
+func TestLearnedCorrectionWithReport(t *testing.T) {
+	// About 33m and 220m north of the stored correction
+	const near, far = 0.0003, 0.002
+
+	tests := []struct {
+		name   string
+		stored learnedCorrection
+		lat    float64 // reported, on the stored correction's longitude
+		want   learnedCorrection
+	}{
+		{
+			name:   "agreeing report averages in",
+			stored: learnedCorrection{lat: 6.5, lng: 3.4, reports: 2},
+			lat:    6.5 + near,
+			want:   learnedCorrection{lat: 6.5 + near/3, lng: 3.4, reports: 3},
+		},
+		{
+			name:   "disagreeing report replaces a single report",
+			stored: learnedCorrection{lat: 6.5, lng: 3.4, reports: 1},
+			lat:    6.5 + far,
+			want:   learnedCorrection{lat: 6.5 + far, lng: 3.4, reports: 1},
+		},
+		{
+			name:   "disagreeing report counts against a vouched correction",
+			stored: learnedCorrection{lat: 6.5, lng: 3.4, reports: 3},
+			lat:    6.5 + far,
+			want:   learnedCorrection{lat: 6.5, lng: 3.4, reports: 3, conflicts: 1},
+		},
+		{
+			name:   "correction outvoted",
+			stored: learnedCorrection{lat: 6.5, lng: 3.4, reports: 3, conflicts: 2},
+			lat:    6.5 + far,
+			want:   learnedCorrection{lat: 6.5 + far, lng: 3.4, reports: 1},
+		},
+		{
+			name:   "agreement keeps earlier conflicts",
+			stored: learnedCorrection{lat: 6.5, lng: 3.4, reports: 3, conflicts: 1},
+			lat:    6.5,
+			want:   learnedCorrection{lat: 6.5, lng: 3.4, reports: 4, conflicts: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.stored.withReport(tt.lat, 3.4)
+			if math.Abs(got.lat-tt.want.lat) > 1e-9 || math.Abs(got.lng-tt.want.lng) > 1e-9 ||
+				got.reports != tt.want.reports || got.conflicts != tt.want.conflicts {
+				t.Errorf("withReport() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+/*
+ * Cold-Chain Deliveries
+ */
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+const (
+	// defaultMaxTransitMinutes is how long a perishable package may spend
+	// between pickup and dropoff when the customer does not say
+	defaultMaxTransitMinutes = 90
+	maxMaxTransitMinutes     = 240
+
+	// Default cold-chain range, for chilled goods
+	defaultTemperatureMinC = 2.0
+	defaultTemperatureMaxC = 8.0
+
+	// transitEscalationShare is the share of the transit limit after which
+	// a perishable delivery still on the road is escalated
+	transitEscalationShare = 0.8
+	transitEscalationBatch = 100
+)
+
+// Stages a courier checks in the package temperature at
+const (
+	temperatureStagePickup  = "PICKUP"
+	temperatureStageTransit = "IN_TRANSIT"
+	temperatureStageDropoff = "DROPOFF"
+)
+
+// applyPerishableTerms fills in a perishable delivery's transit limit and
+// temperature range and checks the trip fits inside the limit. It returns
+// a message for the customer when it does not.
+func applyPerishableTerms(req *CreateDeliveryRequest, estimatedMinutes int) string {
+	if req.Type != models.DeliveryTypePerishable {
+		req.MaxTransitMinutes, req.TemperatureMinC, req.TemperatureMaxC = 0, nil, nil
+		return ""
+	}
+	req.Package.Perishable = true
+
+	if req.MaxTransitMinutes == 0 {
+		req.MaxTransitMinutes = defaultMaxTransitMinutes
+	}
+	if req.MaxTransitMinutes < 0 || req.MaxTransitMinutes > maxMaxTransitMinutes {
+		return fmt.Sprintf("Transit limit must be between 1 and %d minutes", maxMaxTransitMinutes)
+	}
+	if estimatedMinutes > req.MaxTransitMinutes {
+		return fmt.Sprintf("Trip takes about %d minutes, over the %d minute transit limit", estimatedMinutes, req.MaxTransitMinutes)
+	}
+
+	if req.TemperatureMinC == nil && req.TemperatureMaxC == nil {
+		lo, hi := defaultTemperatureMinC, defaultTemperatureMaxC
+		req.TemperatureMinC, req.TemperatureMaxC = &lo, &hi
+	}
+	if req.TemperatureMinC == nil || req.TemperatureMaxC == nil || *req.TemperatureMinC > *req.TemperatureMaxC {
+		return "Temperature range needs a minimum no higher than its maximum"
+	}
+	return ""
+}
+
+// checkInTemperature records the package temperature a courier read at a
+// stage of a perishable delivery. A reading outside the delivery's range
+// is logged as an excursion and the customer is told.
+func (h *Handler) checkInTemperature(ctx context.Context, deliveryID, driverID, customerID, stage string, temperatureC float64, location interface{}) (bool, error) {
+	var minC, maxC *float64
+	err := h.db.Pool.QueryRow(ctx,
+		"SELECT temperature_min_c, temperature_max_c FROM deliveries WHERE id = $1",
+		deliveryID,
+	).Scan(&minC, &maxC)
+	if err != nil {
+		return false, err
+	}
+	inRange := (minC == nil || temperatureC >= *minC) && (maxC == nil || temperatureC <= *maxC)
+
+	_, err = h.db.Pool.Exec(ctx, `
+		INSERT INTO delivery_temperature_checks (id, delivery_id, driver_id, stage, temperature_c, in_range, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())`,
+		uuid.New(), deliveryID, driverID, stage, temperatureC, inRange,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	if !inRange {
+		note := fmt.Sprintf("%.1f°C at %s", temperatureC, stage)
+		h.createDeliveryEvent(ctx, deliveryID, "temperature_excursion", stage, location, &note)
+		h.rdb.Publish(ctx, "delivery:temperature_excursion", map[string]interface{}{
+			"deliveryId":   deliveryID,
+			"driverId":     driverID,
+			"customerId":   customerID,
+			"stage":        stage,
+			"temperatureC": temperatureC,
+			"minC":         minC,
+			"maxC":         maxC,
+		})
+	}
+	return inRange, nil
+}
+
+// RecordTemperatureCheck takes a courier's temperature reading for a
+// perishable package on the road
+func (h *Handler) RecordTemperatureCheck(w http.ResponseWriter, r *http.Request) {
+	driverID := middleware.GetUserID(r.Context())
+	deliveryID := chi.URLParam(r, "id")
+
+	var req struct {
+		TemperatureC *float64 `json:"temperatureC"`
+		Lat          float64  `json:"latitude"`
+		Lon          float64  `json:"longitude"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TemperatureC == nil {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "temperatureC is required")
+		return
+	}
+
+	var status, deliveryType, customerID string
+	err := h.db.Pool.QueryRow(r.Context(),
+		"SELECT status, type, customer_id FROM deliveries WHERE id = $1 AND driver_id = $2",
+		deliveryID, driverID,
+	).Scan(&status, &deliveryType, &customerID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Delivery not found")
+		return
+	}
+	if deliveryType != string(models.DeliveryTypePerishable) {
+		respondError(w, http.StatusBadRequest, "NOT_PERISHABLE", "Temperature checks are only taken for perishable deliveries")
+		return
+	}
+	if status != "PICKED_UP" && status != "IN_TRANSIT" {
+		respondError(w, http.StatusBadRequest, "INVALID_STATUS", "Package is not in transit")
+		return
+	}
+
+	location := map[string]float64{"latitude": req.Lat, "longitude": req.Lon}
+	inRange, err := h.checkInTemperature(r.Context(), deliveryID, driverID, customerID, temperatureStageTransit, *req.TemperatureC, location)
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to record temperature check")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to record temperature")
+		return
+	}
+
+	respond(w, http.StatusOK, map[string]interface{}{
+		"inRange": inRange,
+	})
+}
+
+// escalatePerishableTransit marks perishable deliveries that have used up
+// most of their transit limit, once each, and raises them with the courier,
+// the customer and ops
+func (h *Handler) escalatePerishableTransit(ctx context.Context) error {
+	rows, err := h.db.Pool.Query(ctx, `
+		UPDATE deliveries SET transit_escalated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM deliveries
+			WHERE type = 'PERISHABLE'
+				AND status IN ('PICKED_UP', 'IN_TRANSIT')
+				AND transit_escalated_at IS NULL
+				AND picked_up_at + COALESCE(max_transit_minutes, $1) * $2 * INTERVAL '1 minute' <= NOW()
+			ORDER BY picked_up_at
+			LIMIT $3
+		)
+		RETURNING id, driver_id, customer_id, picked_up_at, COALESCE(max_transit_minutes, $1)`,
+		defaultMaxTransitMinutes, transitEscalationShare, transitEscalationBatch,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type escalation struct {
+		deliveryID, driverID, customerID string
+		pickedUpAt                       time.Time
+		maxTransitMinutes                int
+	}
+	var escalations []escalation
+	for rows.Next() {
+		var e escalation
+		if err := rows.Scan(&e.deliveryID, &e.driverID, &e.customerID, &e.pickedUpAt, &e.maxTransitMinutes); err != nil {
+			return err
+		}
+		escalations = append(escalations, e)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, e := range escalations {
+		deadline := e.pickedUpAt.Add(time.Duration(e.maxTransitMinutes) * time.Minute)
+		remaining := int(time.Until(deadline).Minutes())
+		note := fmt.Sprintf("%d minutes left of a %d minute transit limit", remaining, e.maxTransitMinutes)
+		h.createDeliveryEvent(ctx, e.deliveryID, "transit_escalated", "IN_TRANSIT", nil, &note)
+		h.rdb.Publish(ctx, "delivery:transit_escalated", map[string]interface{}{
+			"deliveryId":       e.deliveryID,
+			"driverId":         e.driverID,
+			"customerId":       e.customerID,
+			"deadline":         deadline,
+			"minutesRemaining": remaining,
+		})
+		log.Warn().
+			Str("delivery_id", e.deliveryID).
+			Str("driver_id", e.driverID).
+			Int("minutes_remaining", remaining).
+			Msg("Perishable delivery near its transit limit")
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+func TestApplyPerishableTerms(t *testing.T) {
+	temp := func(c float64) *float64 { return &c }
+
+	tests := []struct {
+		name      string
+		req       CreateDeliveryRequest
+		estimated int
+		wantErr   bool
+		wantLimit int
+		wantRange [2]float64
+	}{
+		{
+			name:      "defaults",
+			req:       CreateDeliveryRequest{Type: models.DeliveryTypePerishable},
+			estimated: 40,
+			wantLimit: defaultMaxTransitMinutes,
+			wantRange: [2]float64{defaultTemperatureMinC, defaultTemperatureMaxC},
+		},
+		{
+			name:      "frozen goods within a short limit",
+			req:       CreateDeliveryRequest{Type: models.DeliveryTypePerishable, MaxTransitMinutes: 45, TemperatureMinC: temp(-25), TemperatureMaxC: temp(-18)},
+			estimated: 45,
+			wantLimit: 45,
+			wantRange: [2]float64{-25, -18},
+		},
+		{
+			name:      "trip longer than the limit",
+			req:       CreateDeliveryRequest{Type: models.DeliveryTypePerishable, MaxTransitMinutes: 30},
+			estimated: 31,
+			wantErr:   true,
+		},
+		{
+			name:      "trip longer than the default limit",
+			req:       CreateDeliveryRequest{Type: models.DeliveryTypePerishable},
+			estimated: defaultMaxTransitMinutes + 1,
+			wantErr:   true,
+		},
+		{
+			name:    "limit too long",
+			req:     CreateDeliveryRequest{Type: models.DeliveryTypePerishable, MaxTransitMinutes: maxMaxTransitMinutes + 1},
+			wantErr: true,
+		},
+		{
+			name:    "negative limit",
+			req:     CreateDeliveryRequest{Type: models.DeliveryTypePerishable, MaxTransitMinutes: -1},
+			wantErr: true,
+		},
+		{
+			name:    "only one end of the range",
+			req:     CreateDeliveryRequest{Type: models.DeliveryTypePerishable, TemperatureMaxC: temp(4)},
+			wantErr: true,
+		},
+		{
+			name:    "inverted range",
+			req:     CreateDeliveryRequest{Type: models.DeliveryTypePerishable, TemperatureMinC: temp(8), TemperatureMaxC: temp(2)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			msg := applyPerishableTerms(&req, tt.estimated)
+			if (msg != "") != tt.wantErr {
+				t.Fatalf("applyPerishableTerms() = %q, want error %v", msg, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !req.Package.Perishable {
+				t.Error("package not marked perishable")
+			}
+			if req.MaxTransitMinutes != tt.wantLimit {
+				t.Errorf("MaxTransitMinutes = %d, want %d", req.MaxTransitMinutes, tt.wantLimit)
+			}
+			if *req.TemperatureMinC != tt.wantRange[0] || *req.TemperatureMaxC != tt.wantRange[1] {
+				t.Errorf("range = %v..%v, want %v", *req.TemperatureMinC, *req.TemperatureMaxC, tt.wantRange)
+			}
+		})
+	}
+}
+
+func TestApplyPerishableTermsClearsOtherTypes(t *testing.T) {
+	lo, hi := 2.0, 8.0
+	req := CreateDeliveryRequest{
+		Type:              models.DeliveryTypeStandard,
+		MaxTransitMinutes: 30,
+		TemperatureMinC:   &lo,
+		TemperatureMaxC:   &hi,
+	}
+
+	// A long trip is fine when nothing perishes
+	if msg := applyPerishableTerms(&req, 500); msg != "" {
+		t.Fatalf("applyPerishableTerms() = %q, want none", msg)
+	}
+	if req.MaxTransitMinutes != 0 || req.TemperatureMinC != nil || req.TemperatureMaxC != nil {
+		t.Errorf("request = %+v, want cold-chain terms cleared", req)
+	}
+	if req.Package.Perishable {
+		t.Error("package marked perishable")
+	}
+}
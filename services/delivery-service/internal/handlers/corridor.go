@@ -0,0 +1,117 @@
+/*
+ * Return-Leg Corridor Handlers
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+// Corridor search limits
+const (
+	defaultCorridorWidthM = 1500.0
+	maxCorridorWidthM     = 5000.0
+	corridorDeliveryLimit = 20
+)
+
+// corridorDelivery is an unassigned delivery along a corridor, in major
+// units
+type corridorDelivery struct {
+	ID               string          `json:"id"`
+	TrackingNumber   string          `json:"trackingNumber"`
+	Type             string          `json:"type"`
+	PickupLocation   models.Location `json:"pickupLocation"`
+	DropoffLocation  models.Location `json:"dropoffLocation"`
+	DistanceKm       float64         `json:"distanceKm"`
+	EstimatedMinutes int             `json:"estimatedMinutes"`
+	TotalFare        float64         `json:"totalFare"`
+	Currency         string          `json:"currency"`
+	CreatedAt        time.Time       `json:"createdAt"`
+}
+
+// GetCorridorDeliveries returns confirmed deliveries waiting for a driver
+// whose pickup and dropoff both lie along the corridor from one point to
+// another, and which end closer to the corridor's end than they start, for
+// offering drivers a return leg. Nearest pickups come first.
+// GET /api/v1/internal/deliveries/corridor?from_lat=&from_lng=&to_lat=&to_lng=&width_m=
+func (h *Handler) GetCorridorDeliveries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	coords := make([]float64, 0, 4)
+	for _, param := range []string{"from_lat", "from_lng", "to_lat", "to_lng"} {
+		value, err := strconv.ParseFloat(query.Get(param), 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid "+param)
+			return
+		}
+		coords = append(coords, value)
+	}
+
+	width := defaultCorridorWidthM
+	if v := query.Get("width_m"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed <= 0 || parsed > maxCorridorWidthM {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "width_m must be between 0 and 5000")
+			return
+		}
+		width = parsed
+	}
+
+	rows, err := h.db.Pool.Query(r.Context(), `
+		WITH candidates AS (
+			SELECT
+				id, tracking_number, type, pickup_location, dropoff_location,
+				distance_km, estimated_minutes, total_fare, currency, created_at,
+				ST_MakePoint((pickup_location->>'longitude')::float, (pickup_location->>'latitude')::float)::geography AS pickup_point,
+				ST_MakePoint((dropoff_location->>'longitude')::float, (dropoff_location->>'latitude')::float)::geography AS dropoff_point
+			FROM deliveries
+			WHERE status = 'CONFIRMED'
+		), corridor AS (
+			SELECT
+				ST_MakeLine(ST_MakePoint($2, $1), ST_MakePoint($4, $3))::geography AS line,
+				ST_MakePoint($2, $1)::geography AS origin,
+				ST_MakePoint($4, $3)::geography AS destination
+		)
+		SELECT
+			c.id, c.tracking_number, c.type, c.pickup_location, c.dropoff_location,
+			c.distance_km, c.estimated_minutes, c.total_fare, c.currency, c.created_at
+		FROM candidates c, corridor k
+		WHERE ST_DWithin(c.pickup_point, k.line, $5)
+			AND ST_DWithin(c.dropoff_point, k.line, $5)
+			AND ST_Distance(c.dropoff_point, k.destination) < ST_Distance(c.pickup_point, k.destination)
+		ORDER BY ST_Distance(c.pickup_point, k.origin)
+		LIMIT $6`,
+		coords[0], coords[1], coords[2], coords[3], width, corridorDeliveryLimit,
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch corridor deliveries")
+		return
+	}
+	defer rows.Close()
+
+	deliveries := make([]*corridorDelivery, 0)
+	for rows.Next() {
+		var d corridorDelivery
+		var pickup, dropoff json.RawMessage
+		if err := rows.Scan(
+			&d.ID, &d.TrackingNumber, &d.Type, &pickup, &dropoff,
+			&d.DistanceKm, &d.EstimatedMinutes, &d.TotalFare, &d.Currency, &d.CreatedAt,
+		); err != nil {
+			respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch corridor deliveries")
+			return
+		}
+		json.Unmarshal(pickup, &d.PickupLocation)
+		json.Unmarshal(dropoff, &d.DropoffLocation)
+		deliveries = append(deliveries, &d)
+	}
+
+	respond(w, http.StatusOK, map[string]interface{}{
+		"deliveries": deliveries,
+	})
+}
@@ -0,0 +1,134 @@
+/*
+ * Courier Capabilities
+ */
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+// courierLoad is what a courier is certified for and already carrying
+type courierLoad struct {
+	coldChain         bool
+	regulatedGoods    bool
+	regulatedTraining bool
+	active            int
+	activePerishable  int
+}
+
+func (h *Handler) getCourierLoad(ctx context.Context, driverID string) (courierLoad, error) {
+	var l courierLoad
+	err := h.db.Pool.QueryRow(ctx, `
+		SELECT
+			COALESCE((SELECT cold_chain FROM courier_capabilities WHERE driver_id = $1), false),
+			COALESCE((SELECT regulated_goods FROM courier_capabilities WHERE driver_id = $1), false),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE type = 'PERISHABLE')
+		FROM deliveries
+		WHERE driver_id = $1 AND status IN ('DRIVER_ASSIGNED', 'PICKED_UP', 'IN_TRANSIT')`,
+		driverID,
+	).Scan(&l.coldChain, &l.regulatedGoods, &l.active, &l.activePerishable)
+	if err != nil || !l.regulatedGoods {
+		return l, err
+	}
+
+	// Vetted couriers also need the regulated delivery training. When the
+	// ride service cannot say, they are offered no regulated deliveries.
+	l.regulatedTraining, err = fetchTrainingCleared(ctx, h.cfg.RideServiceURL, h.cfg.InternalServiceKey, driverID, regulatedDeliveryTraining)
+	if err != nil {
+		log.Warn().Err(err).Str("driver_id", driverID).Msg("Failed to check courier regulated delivery training")
+	}
+	return l, nil
+}
+
+// regulatedCleared reports whether the courier may carry regulated goods
+func (l courierLoad) regulatedCleared() bool {
+	return l.regulatedGoods && l.regulatedTraining
+}
+
+// dispatchBlock says why the courier may not take a delivery, as an error
+// code and message, or "" when they may. Perishable deliveries go only to
+// certified cold-chain couriers with nothing else on board, since another
+// stop would eat into the transit limit; for the same reason a courier
+// carrying a perishable package takes nothing else. Regulated deliveries
+// go only to vetted couriers who have completed the training.
+func (l courierLoad) dispatchBlock(deliveryType models.DeliveryType, regulated bool) (string, string) {
+	if regulated && !l.regulatedGoods {
+		return "VETTING_REQUIRED", "Regulated deliveries need a vetted courier"
+	}
+	if regulated && !l.regulatedTraining {
+		return "TRAINING_REQUIRED", "Regulated deliveries need a courier who has completed the training"
+	}
+	if deliveryType == models.DeliveryTypePerishable {
+		if !l.coldChain {
+			return "COLD_CHAIN_REQUIRED", "Perishable deliveries need a cold-chain certified courier"
+		}
+		if l.active > 0 {
+			return "TRANSIT_LIMIT", "Finish your current deliveries before taking a perishable one"
+		}
+		return "", ""
+	}
+	if l.activePerishable > 0 {
+		return "TRANSIT_LIMIT", "Finish your perishable delivery before taking another"
+	}
+	return "", ""
+}
+
+// SetCourierColdChain certifies a courier for cold-chain deliveries, or
+// withdraws the certification
+func (h *Handler) SetCourierColdChain(w http.ResponseWriter, r *http.Request) {
+	h.setCourierCapability(w, r, "cold_chain", "coldChain")
+}
+
+// SetCourierRegulatedGoods marks a courier as vetted to carry regulated
+// goods such as prescription medicine, or withdraws the vetting
+func (h *Handler) SetCourierRegulatedGoods(w http.ResponseWriter, r *http.Request) {
+	h.setCourierCapability(w, r, "regulated_goods", "regulatedGoods")
+}
+
+// setCourierCapability sets one courier_capabilities column; column is
+// always one of the constants above, never client input
+func (h *Handler) setCourierCapability(w http.ResponseWriter, r *http.Request, column, field string) {
+	adminID := middleware.GetUserID(r.Context())
+	driverID := chi.URLParam(r, "driverId")
+	if _, err := uuid.Parse(driverID); err != nil {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid driver ID")
+		return
+	}
+
+	var req struct {
+		Certified bool `json:"certified"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return
+	}
+
+	_, err := h.db.Pool.Exec(r.Context(), `
+		INSERT INTO courier_capabilities (driver_id, `+column+`, certified_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (driver_id) DO UPDATE
+		SET `+column+` = EXCLUDED.`+column+`, certified_by = EXCLUDED.certified_by, updated_at = NOW()`,
+		driverID, req.Certified, adminID,
+	)
+	if err != nil {
+		log.Error().Err(err).Str("driver_id", driverID).Str("capability", column).Msg("Failed to set courier capability")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to update courier")
+		return
+	}
+
+	respond(w, http.StatusOK, map[string]interface{}{
+		"driverId": driverID,
+		field:      req.Certified,
+	})
+}
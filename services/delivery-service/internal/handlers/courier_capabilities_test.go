@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+func TestCourierLoadDispatchBlock(t *testing.T) {
+	vetted := courierLoad{regulatedGoods: true, regulatedTraining: true}
+
+	tests := []struct {
+		name         string
+		load         courierLoad
+		deliveryType models.DeliveryType
+		regulated    bool
+		want         string
+	}{
+		{name: "standard delivery", load: courierLoad{active: 2}, deliveryType: models.DeliveryTypeStandard},
+		{name: "perishable to a free cold-chain courier", load: courierLoad{coldChain: true}, deliveryType: models.DeliveryTypePerishable},
+		{name: "perishable without certification", load: courierLoad{}, deliveryType: models.DeliveryTypePerishable, want: "COLD_CHAIN_REQUIRED"},
+		{name: "perishable to a busy courier", load: courierLoad{coldChain: true, active: 1}, deliveryType: models.DeliveryTypePerishable, want: "TRANSIT_LIMIT"},
+		{name: "anything while carrying a perishable", load: courierLoad{coldChain: true, active: 1, activePerishable: 1}, deliveryType: models.DeliveryTypeStandard, want: "TRANSIT_LIMIT"},
+		{name: "regulated to a vetted, trained courier", load: vetted, deliveryType: models.DeliveryTypeStandard, regulated: true},
+		{name: "regulated without vetting", load: courierLoad{regulatedTraining: true}, deliveryType: models.DeliveryTypeStandard, regulated: true, want: "VETTING_REQUIRED"},
+		{name: "regulated without training", load: courierLoad{regulatedGoods: true}, deliveryType: models.DeliveryTypeStandard, regulated: true, want: "TRAINING_REQUIRED"},
+		{name: "regulated perishable needs both", load: vetted, deliveryType: models.DeliveryTypePerishable, regulated: true, want: "COLD_CHAIN_REQUIRED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, msg := tt.load.dispatchBlock(tt.deliveryType, tt.regulated)
+			if code != tt.want {
+				t.Fatalf("dispatchBlock() = %q, want %q", code, tt.want)
+			}
+			if (msg == "") != (code == "") {
+				t.Errorf("dispatchBlock() message = %q for code %q", msg, code)
+			}
+		})
+	}
+
+	if !vetted.regulatedCleared() || (courierLoad{regulatedGoods: true}).regulatedCleared() {
+		t.Error("regulatedCleared() should need both vetting and training")
+	}
+}
@@ -0,0 +1,58 @@
+/*
+ * Courier Training
+ */
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The ride service keeps the registry of training modules drivers and
+// couriers have completed
+const regulatedDeliveryTraining = "REGULATED_DELIVERY"
+
+var trainingClient = &http.Client{Timeout: 3 * time.Second}
+
+// fetchTrainingCleared asks the ride service whether a courier has
+// completed the training a product requires
+func fetchTrainingCleared(ctx context.Context, baseURL, serviceKey, driverID, product string) (bool, error) {
+	if baseURL == "" {
+		return false, errors.New("ride service not configured")
+	}
+
+	endpoint := fmt.Sprintf("%s/internal/drivers/%s/training/%s",
+		strings.TrimRight(baseURL, "/"), url.PathEscape(driverID), url.PathEscape(product))
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("X-Service-Key", serviceKey)
+
+	resp, err := trainingClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("ride service returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data struct {
+			Cleared bool `json:"cleared"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, err
+	}
+	return result.Data.Cleared, nil
+}
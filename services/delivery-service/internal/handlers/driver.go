@@ -11,6 +11,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 
 	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
 	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
@@ -28,7 +29,16 @@ func (h *Handler) GetAvailableDeliveries(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Find nearby deliveries (within 10km radius)
+	load, err := h.getCourierLoad(r.Context(), driverID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch deliveries")
+		return
+	}
+	perishableCode, _ := load.dispatchBlock(models.DeliveryTypePerishable, false)
+	otherCode, _ := load.dispatchBlock(models.DeliveryTypeStandard, false)
+
+	// Find nearby deliveries (within 10km radius) that can be picked up
+	// when the courier gets there
 	query := `
 		SELECT 
 			id, tracking_number, type, pickup_location, dropoff_location,
@@ -39,6 +49,10 @@ func (h *Handler) GetAvailableDeliveries(w http.ResponseWriter, r *http.Request)
 			) / 1000 as pickup_distance_km
 		FROM deliveries
 		WHERE status = 'CONFIRMED'
+		AND (type != 'PERISHABLE' OR $3)
+		AND (type = 'PERISHABLE' OR $4)
+		AND (NOT regulated OR $5)
+		AND ` + pickupWindowOpenSQL + `
 		AND ST_DWithin(
 			ST_MakePoint((pickup_location->>'longitude')::float, (pickup_location->>'latitude')::float)::geography,
 			ST_MakePoint($1, $2)::geography,
@@ -48,7 +62,7 @@ func (h *Handler) GetAvailableDeliveries(w http.ResponseWriter, r *http.Request)
 		LIMIT 20
 	`
 
-	rows, err := h.db.Pool.Query(r.Context(), query, driverLoc.Longitude, driverLoc.Latitude)
+	rows, err := h.db.Pool.Query(r.Context(), query, driverLoc.Longitude, driverLoc.Latitude, perishableCode == "", otherCode == "", load.regulatedCleared())
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch deliveries")
 		return
@@ -124,10 +138,12 @@ func (h *Handler) AcceptDelivery(w http.ResponseWriter, r *http.Request) {
 	// Check delivery status
 	var status string
 	var customerID string
+	var deliveryType models.DeliveryType
+	var regulated bool
 	err = h.db.Pool.QueryRow(r.Context(),
-		"SELECT status, customer_id FROM deliveries WHERE id = $1",
+		"SELECT status, customer_id, type, regulated FROM deliveries WHERE id = $1",
 		deliveryID,
-	).Scan(&status, &customerID)
+	).Scan(&status, &customerID, &deliveryType, &regulated)
 
 	if err != nil {
 		h.rdb.Delete(r.Context(), lockKey)
@@ -141,6 +157,32 @@ func (h *Handler) AcceptDelivery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Cold-chain, vetting and transit limits
+	load, err := h.getCourierLoad(r.Context(), driverID)
+	if err != nil {
+		h.rdb.Delete(r.Context(), lockKey)
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to accept delivery")
+		return
+	}
+	if code, msg := load.dispatchBlock(deliveryType, regulated); code != "" {
+		h.rdb.Delete(r.Context(), lockKey)
+		respondError(w, http.StatusConflict, code, msg)
+		return
+	}
+
+	// Merchants hand over only in their pickup windows
+	open, err := h.pickupWindowOpen(r.Context(), deliveryID)
+	if err != nil {
+		h.rdb.Delete(r.Context(), lockKey)
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to accept delivery")
+		return
+	}
+	if !open {
+		h.rdb.Delete(r.Context(), lockKey)
+		respondError(w, http.StatusConflict, "PICKUP_NOT_READY", "The merchant is not handing over this order yet")
+		return
+	}
+
 	// Assign driver
 	_, err = h.db.Pool.Exec(r.Context(),
 		`UPDATE deliveries SET 
@@ -168,10 +210,15 @@ func (h *Handler) AcceptDelivery(w http.ResponseWriter, r *http.Request) {
 		"customerId": customerID,
 	})
 
-	respond(w, http.StatusOK, map[string]interface{}{
+	resp := map[string]interface{}{
 		"message":    "Delivery accepted",
 		"deliveryId": deliveryID,
-	})
+	}
+	if deliveryType == models.DeliveryTypePerishable {
+		// Prompt the courier for the temperature at each of these stages
+		resp["temperatureChecks"] = []string{temperatureStagePickup, temperatureStageTransit, temperatureStageDropoff}
+	}
+	respond(w, http.StatusOK, resp)
 }
 
 // ConfirmPickup confirms package pickup
@@ -180,20 +227,22 @@ func (h *Handler) ConfirmPickup(w http.ResponseWriter, r *http.Request) {
 	deliveryID := chi.URLParam(r, "id")
 
 	var req struct {
-		Photo string  `json:"photo,omitempty"`
-		Note  string  `json:"note,omitempty"`
-		Lat   float64 `json:"latitude"`
-		Lon   float64 `json:"longitude"`
+		Photo        string   `json:"photo,omitempty"`
+		Note         string   `json:"note,omitempty"`
+		Lat          float64  `json:"latitude"`
+		Lon          float64  `json:"longitude"`
+		TemperatureC *float64 `json:"temperatureC,omitempty"` // perishable deliveries
 	}
 	json.NewDecoder(r.Body).Decode(&req)
 
 	// Verify driver assignment
 	var status string
 	var customerID string
+	var deliveryType models.DeliveryType
 	err := h.db.Pool.QueryRow(r.Context(),
-		"SELECT status, customer_id FROM deliveries WHERE id = $1 AND driver_id = $2",
+		"SELECT status, customer_id, type FROM deliveries WHERE id = $1 AND driver_id = $2",
 		deliveryID, driverID,
-	).Scan(&status, &customerID)
+	).Scan(&status, &customerID, &deliveryType)
 
 	if err != nil {
 		respondError(w, http.StatusNotFound, "NOT_FOUND", "Delivery not found")
@@ -205,6 +254,19 @@ func (h *Handler) ConfirmPickup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Perishable packages are checked in at pickup
+	location := map[string]float64{"latitude": req.Lat, "longitude": req.Lon}
+	if deliveryType == models.DeliveryTypePerishable {
+		if req.TemperatureC == nil {
+			respondError(w, http.StatusBadRequest, "TEMPERATURE_CHECK_REQUIRED", "Record the package temperature before confirming pickup")
+			return
+		}
+		if _, err := h.checkInTemperature(r.Context(), deliveryID, driverID, customerID, temperatureStagePickup, *req.TemperatureC, location); err != nil {
+			respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to record temperature")
+			return
+		}
+	}
+
 	// Update status
 	_, err = h.db.Pool.Exec(r.Context(),
 		`UPDATE deliveries SET 
@@ -221,7 +283,6 @@ func (h *Handler) ConfirmPickup(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create event
-	location := map[string]float64{"latitude": req.Lat, "longitude": req.Lon}
 	h.createDeliveryEvent(r.Context(), deliveryID, "picked_up", "PICKED_UP", location, &req.Note)
 
 	// Notify customer
@@ -240,22 +301,26 @@ func (h *Handler) ConfirmDelivery(w http.ResponseWriter, r *http.Request) {
 	deliveryID := chi.URLParam(r, "id")
 
 	var req struct {
-		Signature string  `json:"signature,omitempty"` // Base64 image
-		Photo     string  `json:"photo,omitempty"`     // Delivery photo
-		Note      string  `json:"note,omitempty"`
-		Lat       float64 `json:"latitude"`
-		Lon       float64 `json:"longitude"`
+		Signature    string       `json:"signature,omitempty"`    // Base64 image
+		Photo        string       `json:"photo,omitempty"`        // Delivery photo
+		Note         string       `json:"note,omitempty"`
+		Lat          float64      `json:"latitude"`
+		Lon          float64      `json:"longitude"`
+		TemperatureC *float64     `json:"temperatureC,omitempty"` // perishable deliveries
+		RecipientID  *RecipientID `json:"recipientId,omitempty"`  // regulated deliveries
+		WrongAddress bool         `json:"wrongAddress,omitempty"` // the pin was wrong; handed over at latitude/longitude instead
 	}
 	json.NewDecoder(r.Body).Decode(&req)
 
 	// Verify driver assignment and status
 	var status, customerID string
 	var requiresPOD bool
+	var deliveryType models.DeliveryType
 	err := h.db.Pool.QueryRow(r.Context(),
-		`SELECT status, customer_id, (package->>'requiresPod')::boolean 
+		`SELECT status, customer_id, (package->>'requiresPod')::boolean, type 
 		FROM deliveries WHERE id = $1 AND driver_id = $2`,
 		deliveryID, driverID,
-	).Scan(&status, &customerID, &requiresPOD)
+	).Scan(&status, &customerID, &requiresPOD, &deliveryType)
 
 	if err != nil {
 		respondError(w, http.StatusNotFound, "NOT_FOUND", "Delivery not found")
@@ -273,6 +338,46 @@ func (h *Handler) ConfirmDelivery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Regulated deliveries are handed over against the recipient's ID
+	regulated, country, err := h.regulatedDropoff(r.Context(), deliveryID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to confirm delivery")
+		return
+	}
+	if regulated {
+		id, err := validateRecipientID(req.RecipientID, country)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "RECIPIENT_ID_REQUIRED", "Check the recipient's ID: "+err.Error())
+			return
+		}
+		if err := h.recordRecipientID(r.Context(), deliveryID, driverID, country, id); err != nil {
+			log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to record recipient ID")
+			respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to record recipient ID")
+			return
+		}
+	}
+
+	// Perishable packages are checked in again at dropoff
+	location := map[string]float64{"latitude": req.Lat, "longitude": req.Lon}
+	if deliveryType == models.DeliveryTypePerishable {
+		if req.TemperatureC == nil {
+			respondError(w, http.StatusBadRequest, "TEMPERATURE_CHECK_REQUIRED", "Record the package temperature before confirming delivery")
+			return
+		}
+		if _, err := h.checkInTemperature(r.Context(), deliveryID, driverID, customerID, temperatureStageDropoff, *req.TemperatureC, location); err != nil {
+			respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to record temperature")
+			return
+		}
+	}
+
+	// Signatures are personal data, sealed at rest
+	signature, err := h.db.SealText(r.Context(), req.Signature)
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to seal recipient signature")
+		respondError(w, http.StatusInternalServerError, "ENCRYPTION_ERROR", "Failed to confirm delivery")
+		return
+	}
+
 	// Update status
 	_, err = h.db.Pool.Exec(r.Context(),
 		`UPDATE deliveries SET 
@@ -282,7 +387,7 @@ func (h *Handler) ConfirmDelivery(w http.ResponseWriter, r *http.Request) {
 			delivery_photo = $2,
 			updated_at = NOW()
 		WHERE id = $3`,
-		req.Signature, req.Photo, deliveryID,
+		signature, req.Photo, deliveryID,
 	)
 
 	if err != nil {
@@ -290,8 +395,14 @@ func (h *Handler) ConfirmDelivery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Learn where the address really is for the next delivery there
+	if req.WrongAddress {
+		if err := h.recordAddressCorrection(r.Context(), deliveryID, driverID, req.Lat, req.Lon); err != nil {
+			log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to record address correction")
+		}
+	}
+
 	// Create event
-	location := map[string]float64{"latitude": req.Lat, "longitude": req.Lon}
 	h.createDeliveryEvent(r.Context(), deliveryID, "delivered", "DELIVERED", location, &req.Note)
 
 	// Notify and trigger payout
@@ -357,6 +468,9 @@ func (h *Handler) UpdateDriverLocation(w http.ResponseWriter, r *http.Request) {
 		h.rdb.Publish(r.Context(), "delivery:location:"+activeDeliveryID, location)
 	}
 
+	// Count the minute towards any shift slot the driver is working
+	h.recordShiftAttendance(r.Context(), driverID, req.Latitude, req.Longitude, location.UpdatedAt)
+
 	respond(w, http.StatusOK, map[string]interface{}{
 		"updated":  true,
 		"location": location,
@@ -0,0 +1,122 @@
+/*
+ * Driver Earnings Handlers
+ */
+
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// driverEarningsLine is a driver's delivery earnings in one currency, in
+// major units
+type driverEarningsLine struct {
+	Currency    string  `json:"currency"`
+	Deliveries  int     `json:"deliveries"`
+	Fares       float64 `json:"fares"`
+	Tips        float64 `json:"tips"`
+	Adjustments float64 `json:"adjustments"`
+	Net         float64 `json:"net"`
+}
+
+// GetDriverEarnings returns a driver's delivery earnings for a period, per
+// currency, for the cross-service earnings summary. Fares are the delivery
+// fare less platform service and insurance fees; adjustments are ledger
+// entries such as refund reversals booked in the period.
+// GET /api/v1/internal/drivers/{driverId}/earnings?from=&to=
+func (h *Handler) GetDriverEarnings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	driverID, err := uuid.Parse(chi.URLParam(r, "driverId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid driver ID")
+		return
+	}
+	from, err := time.Parse(time.RFC3339, r.URL.Query().Get("from"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "from must be an RFC3339 timestamp")
+		return
+	}
+	to, err := time.Parse(time.RFC3339, r.URL.Query().Get("to"))
+	if err != nil || !to.After(from) {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "to must be an RFC3339 timestamp after from")
+		return
+	}
+
+	lines := map[string]*driverEarningsLine{}
+	line := func(currency string) *driverEarningsLine {
+		if l, ok := lines[currency]; ok {
+			return l
+		}
+		l := &driverEarningsLine{Currency: currency}
+		lines[currency] = l
+		return l
+	}
+
+	rows, err := h.db.Pool.Query(ctx, `
+		SELECT currency, COUNT(*),
+			COALESCE(SUM(total_fare - tip - service_fee - insurance_fee), 0),
+			COALESCE(SUM(tip), 0)
+		FROM deliveries
+		WHERE driver_id = $1 AND status = 'DELIVERED'
+			AND delivered_at >= $2 AND delivered_at < $3
+		GROUP BY currency`,
+		driverID, from, to,
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load earnings")
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var currency string
+		var count int
+		var fares, tips float64
+		if err := rows.Scan(&currency, &count, &fares, &tips); err != nil {
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load earnings")
+			return
+		}
+		l := line(currency)
+		l.Deliveries, l.Fares, l.Tips = count, fares, tips
+	}
+
+	adjRows, err := h.db.Pool.Query(ctx, `
+		SELECT currency, COALESCE(SUM(amount), 0)
+		FROM delivery_ledger_entries
+		WHERE account = 'DRIVER' AND account_id = $1
+			AND created_at >= $2 AND created_at < $3
+		GROUP BY currency`,
+		driverID, from, to,
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load earnings")
+		return
+	}
+	defer adjRows.Close()
+	for adjRows.Next() {
+		var currency string
+		var amount float64
+		if err := adjRows.Scan(&currency, &amount); err != nil {
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load earnings")
+			return
+		}
+		line(currency).Adjustments = amount
+	}
+
+	currencies := make([]*driverEarningsLine, 0, len(lines))
+	for _, l := range lines {
+		l.Net = l.Fares + l.Tips + l.Adjustments
+		currencies = append(currencies, l)
+	}
+
+	respond(w, http.StatusOK, map[string]interface{}{
+		"driverId":   driverID,
+		"from":       from,
+		"to":         to,
+		"currencies": currencies,
+	})
+}
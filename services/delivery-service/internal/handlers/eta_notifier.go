@@ -0,0 +1,280 @@
+/*
+ * Courier ETA Notifications
+ */
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+// Channels a customer can hear about their courier's ETA on
+const (
+	etaChannelSMS  = "SMS"
+	etaChannelPush = "PUSH"
+	etaChannelNone = "NONE"
+)
+
+var etaChannels = map[string]bool{
+	etaChannelSMS:  true,
+	etaChannelPush: true,
+	etaChannelNone: true,
+}
+
+const (
+	// etaNotifyMinutes is how close the courier must be to the dropoff
+	// for the customer to be told they are nearly there
+	etaNotifyMinutes = 5
+
+	// etaNotifyPrefilterKm skips asking for a routed ETA when the courier
+	// is too far away in a straight line to arrive within etaNotifyMinutes
+	etaNotifyPrefilterKm = 5.0
+
+	// etaCustomerCooldown is the least time between two ETA notifications
+	// to one customer, however many deliveries they have arriving
+	etaCustomerCooldown = 10 * time.Minute
+
+	// etaStaleLocation is how old a courier's last location can be before
+	// it says too little about where they are now
+	etaStaleLocation = 2 * time.Minute
+
+	etaNotifierBatch = 500
+)
+
+var liveETAClient = &http.Client{Timeout: 3 * time.Second}
+
+// GetNotificationPreferences returns how the customer hears that their
+// courier is nearly there. Customers who have not chosen get push.
+func (h *Handler) GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	channel, err := h.etaChannel(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch notification preferences")
+		return
+	}
+	respond(w, http.StatusOK, map[string]interface{}{
+		"etaChannel": channel,
+	})
+}
+
+// UpdateNotificationPreferences sets how the customer hears that their
+// courier is nearly there: SMS, PUSH or NONE
+func (h *Handler) UpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	var req struct {
+		ETAChannel string `json:"etaChannel"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return
+	}
+	channel := strings.ToUpper(strings.TrimSpace(req.ETAChannel))
+	if !etaChannels[channel] {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "etaChannel must be SMS, PUSH or NONE")
+		return
+	}
+
+	_, err := h.db.Pool.Exec(r.Context(), `
+		INSERT INTO customer_notification_preferences (customer_id, eta_channel, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (customer_id) DO UPDATE SET
+			eta_channel = EXCLUDED.eta_channel,
+			updated_at = NOW()`,
+		userID, channel,
+	)
+	if err != nil {
+		log.Error().Err(err).Str("customer_id", userID).Msg("Failed to update notification preferences")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to update notification preferences")
+		return
+	}
+
+	respond(w, http.StatusOK, map[string]interface{}{
+		"etaChannel": channel,
+	})
+}
+
+func (h *Handler) etaChannel(ctx context.Context, customerID string) (string, error) {
+	var channel string
+	err := h.db.Pool.QueryRow(ctx,
+		"SELECT eta_channel FROM customer_notification_preferences WHERE customer_id = $1",
+		customerID,
+	).Scan(&channel)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return etaChannelPush, nil
+	}
+	return channel, err
+}
+
+// notifyCouriersNearby checks the live ETA of every courier carrying a
+// delivery and tells the customer once the courier is etaNotifyMinutes
+// from the dropoff. Each delivery attempt is announced once, and a
+// customer hears at most once per etaCustomerCooldown.
+func (h *Handler) notifyCouriersNearby(ctx context.Context) error {
+	rows, err := h.db.Pool.Query(ctx, `
+		SELECT id, tracking_number, status, customer_id, driver_id, dropoff_location, failed_attempts
+		FROM deliveries
+		WHERE status IN ('PICKED_UP', 'IN_TRANSIT') AND driver_id IS NOT NULL
+		ORDER BY picked_up_at
+		LIMIT $1`,
+		etaNotifierBatch,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type enRoute struct {
+		deliveryID, trackingNumber, status, customerID, driverID string
+		dropoff                                                  models.Location
+		failedAttempts                                           int
+	}
+	var deliveries []enRoute
+	for rows.Next() {
+		var d enRoute
+		if err := rows.Scan(&d.deliveryID, &d.trackingNumber, &d.status, &d.customerID, &d.driverID, &d.dropoff, &d.failedAttempts); err != nil {
+			return err
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, d := range deliveries {
+		// Retried deliveries are announced again on the new attempt
+		notifiedKey := fmt.Sprintf("delivery:eta_notified:%s:%d", d.deliveryID, d.failedAttempts)
+		if notified, err := h.rdb.Exists(ctx, notifiedKey); err != nil || notified {
+			continue
+		}
+
+		var loc models.DriverLocation
+		if err := h.rdb.GetJSON(ctx, "driver:location:"+d.driverID, &loc); err != nil {
+			continue
+		}
+		if time.Since(loc.UpdatedAt) > etaStaleLocation {
+			continue
+		}
+		if haversineDistance(loc.Latitude, loc.Longitude, d.dropoff.Latitude, d.dropoff.Longitude) > etaNotifyPrefilterKm {
+			continue
+		}
+
+		etaMinutes, source := h.liveETAMinutes(ctx, loc.Latitude, loc.Longitude, d.dropoff.Latitude, d.dropoff.Longitude)
+		if etaMinutes > etaNotifyMinutes {
+			continue
+		}
+
+		channel, err := h.etaChannel(ctx, d.customerID)
+		if err != nil {
+			log.Warn().Err(err).Str("customer_id", d.customerID).Msg("Failed to fetch notification preferences")
+			continue
+		}
+
+		// Claim the notification before sending it so overlapping runs
+		// cannot both send it
+		if ok, err := h.rdb.SetNX(ctx, notifiedKey, etaMinutes, 6*time.Hour); err != nil || !ok {
+			continue
+		}
+		if channel == etaChannelNone {
+			continue
+		}
+		if ok, err := h.rdb.SetNX(ctx, "customer:eta_notified:"+d.customerID, d.deliveryID, etaCustomerCooldown); err != nil || !ok {
+			continue
+		}
+
+		message := courierNearbyMessage(etaMinutes, d.trackingNumber)
+		h.rdb.Publish(ctx, "delivery:eta_notification", map[string]interface{}{
+			"deliveryId":     d.deliveryID,
+			"trackingNumber": d.trackingNumber,
+			"customerId":     d.customerID,
+			"driverId":       d.driverID,
+			"channel":        channel,
+			"etaMinutes":     etaMinutes,
+			"etaSource":      source,
+			"message":        message,
+		})
+
+		note := fmt.Sprintf("%s: courier %d min away (%s)", channel, etaMinutes, source)
+		location := map[string]float64{"latitude": loc.Latitude, "longitude": loc.Longitude}
+		h.createDeliveryEvent(ctx, d.deliveryID, "courier_nearby", d.status, location, &note)
+	}
+	return nil
+}
+
+// courierNearbyMessage is what the customer is told when their courier is
+// etaMinutes from the dropoff
+func courierNearbyMessage(etaMinutes int, trackingNumber string) string {
+	if etaMinutes <= 1 {
+		return fmt.Sprintf("Your courier is about to arrive with delivery %s", trackingNumber)
+	}
+	return fmt.Sprintf("Your courier is about %d minutes away with delivery %s", etaMinutes, trackingNumber)
+}
+
+// liveETAMinutes is how long a courier will take to reach a point, routed
+// with live traffic by the ride service's ETA service when it answers and
+// estimated from the straight-line distance when it does not. It reports
+// which of the two it used.
+func (h *Handler) liveETAMinutes(ctx context.Context, lat, lng, destLat, destLng float64) (int, string) {
+	seconds, err := fetchLiveETA(ctx, h.cfg.RideServiceURL, h.cfg.InternalServiceKey, lat, lng, destLat, destLng)
+	if err == nil {
+		return int(math.Ceil(float64(seconds) / 60)), "live"
+	}
+	log.Debug().Err(err).Msg("Live ETA unavailable, estimating")
+	km := haversineDistance(lat, lng, destLat, destLng)
+	return int(math.Ceil(km / routeSpeedKmh * 60)), "estimate"
+}
+
+// fetchLiveETA asks the ride service for a courier's traffic-adjusted
+// travel time in seconds
+func fetchLiveETA(ctx context.Context, baseURL, serviceKey string, lat, lng, destLat, destLng float64) (int64, error) {
+	if baseURL == "" {
+		return 0, errors.New("ride service not configured")
+	}
+	query := url.Values{}
+	query.Set("origin_lat", fmt.Sprintf("%f", lat))
+	query.Set("origin_lng", fmt.Sprintf("%f", lng))
+	query.Set("dest_lat", fmt.Sprintf("%f", destLat))
+	query.Set("dest_lng", fmt.Sprintf("%f", destLng))
+	query.Set("profile", "motorcycle")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimRight(baseURL, "/")+"/eta/live?"+query.Encode(), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("X-Service-Key", serviceKey)
+
+	resp, err := liveETAClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("ride service returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data struct {
+			DurationSeconds int64 `json:"duration_seconds"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	return result.Data.DurationSeconds, nil
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/config"
+)
+
+// liveETAServer stands in for the ride service's live ETA endpoint
+func liveETAServer(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/eta/live" || q.Get("profile") != "motorcycle" || q.Get("origin_lat") != "6.500000" || q.Get("dest_lng") != "3.410000" {
+			t.Errorf("request = %s, want a motorcycle ETA from 6.5,3.4 to 6.51,3.41", r.URL)
+		}
+		if r.Header.Get("X-Service-Key") != "svc-key" {
+			t.Errorf("X-Service-Key = %q, want svc-key", r.Header.Get("X-Service-Key"))
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLiveETAMinutes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("live", func(t *testing.T) {
+		srv := liveETAServer(t, http.StatusOK, `{"success":true,"data":{"duration_seconds":241}}`)
+		h := &Handler{cfg: &config.Config{RideServiceURL: srv.URL + "/", InternalServiceKey: "svc-key"}}
+
+		minutes, source := h.liveETAMinutes(ctx, 6.5, 3.4, 6.51, 3.41)
+		if minutes != 5 || source != "live" {
+			t.Errorf("liveETAMinutes() = %d %s, want 5 live", minutes, source)
+		}
+	})
+
+	t.Run("ride service error falls back to an estimate", func(t *testing.T) {
+		srv := liveETAServer(t, http.StatusServiceUnavailable, `{"success":false}`)
+		h := &Handler{cfg: &config.Config{RideServiceURL: srv.URL, InternalServiceKey: "svc-key"}}
+
+		// About 1.56km at 20km/h
+		minutes, source := h.liveETAMinutes(ctx, 6.5, 3.4, 6.51, 3.41)
+		if minutes != 5 || source != "estimate" {
+			t.Errorf("liveETAMinutes() = %d %s, want 5 estimate", minutes, source)
+		}
+	})
+
+	t.Run("ride service not configured", func(t *testing.T) {
+		h := &Handler{cfg: &config.Config{}}
+
+		if _, source := h.liveETAMinutes(ctx, 6.5, 3.4, 6.51, 3.41); source != "estimate" {
+			t.Errorf("source = %s, want estimate", source)
+		}
+	})
+}
+
+func TestFetchLiveETABadResponse(t *testing.T) {
+	srv := liveETAServer(t, http.StatusOK, `not json`)
+	if _, err := fetchLiveETA(context.Background(), srv.URL, "svc-key", 6.5, 3.4, 6.51, 3.41); err == nil {
+		t.Error("fetchLiveETA() error = nil, want a decode error")
+	}
+}
+
+func TestCourierNearbyMessage(t *testing.T) {
+	tests := []struct {
+		minutes int
+		want    string
+	}{
+		{5, "Your courier is about 5 minutes away with delivery UBI-123"},
+		{2, "Your courier is about 2 minutes away with delivery UBI-123"},
+		{1, "Your courier is about to arrive with delivery UBI-123"},
+		{0, "Your courier is about to arrive with delivery UBI-123"},
+	}
+	for _, tt := range tests {
+		if got := courierNearbyMessage(tt.minutes, "UBI-123"); got != tt.want {
+			t.Errorf("courierNearbyMessage(%d) = %q, want %q", tt.minutes, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateNotificationPreferencesValidation(t *testing.T) {
+	for _, body := range []string{`{`, `{"etaChannel":"EMAIL"}`, `{}`} {
+		rec := httptest.NewRecorder()
+		(&Handler{}).UpdateNotificationPreferences(rec, httptest.NewRequest(http.MethodPut, "/notification-preferences", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, want 400", body, rec.Code)
+		}
+	}
+}
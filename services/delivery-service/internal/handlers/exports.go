@@ -0,0 +1,502 @@
+/*
+ * Partner Data Exports
+ */
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/database"
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+)
+
+const (
+	// maxExportRows is the most rows one export may hold. Larger exports
+	// fail and are asked for over a shorter range.
+	maxExportRows = 50000
+
+	// maxExportRange is the longest date range one export may cover
+	maxExportRange = 93 * 24 * time.Hour
+
+	// maxOpenExports is how many exports a partner may have waiting or
+	// running at once
+	maxOpenExports = 3
+
+	// exportRetention is how long a finished export can be downloaded
+	exportRetention = 7 * 24 * time.Hour
+
+	// exportBatchSize is the most exports built per job run
+	exportBatchSize = 5
+
+	// exportStaleAfter is how long a running export may go unfinished
+	// before another run picks it up again
+	exportStaleAfter = 15 * time.Minute
+)
+
+// exportFormats are the content types of the formats exports are built in
+var exportFormats = map[string]string{
+	"csv":   "text/csv",
+	"jsonl": "application/x-ndjson",
+}
+
+// exportDataset is a kind of data partners can export. Queries run in the
+// partner's scope, so row-level security keeps other partners' deliveries
+// out, and take the range start, range end and row limit.
+type exportDataset struct {
+	columns []string
+	query   string
+}
+
+var exportDatasets = map[string]exportDataset{
+	"deliveries": {
+		columns: []string{
+			"id", "tracking_number", "type", "status", "pickup_address", "dropoff_address",
+			"distance_km", "total_fare", "currency", "payment_status",
+			"created_at", "picked_up_at", "delivered_at", "cancelled_at", "cancellation_reason",
+		},
+		query: `
+			SELECT d.id::text, d.tracking_number, d.type::text, d.status::text,
+				d.pickup_location->>'address', d.dropoff_location->>'address',
+				d.distance_km::float8, d.total_fare::float8, d.currency::text, d.payment_status::text,
+				d.created_at, d.picked_up_at, d.delivered_at, d.cancelled_at, d.cancellation_reason
+			FROM deliveries d
+			WHERE d.created_at >= $1 AND d.created_at < $2
+			ORDER BY d.created_at, d.id
+			LIMIT $3`,
+	},
+	// Tracking events without the courier's position
+	"events": {
+		columns: []string{"id", "delivery_id", "tracking_number", "type", "status", "note", "created_at"},
+		query: `
+			SELECT e.id::text, e.delivery_id::text, d.tracking_number, e.type::text, e.status::text, e.note, e.created_at
+			FROM delivery_events e
+			JOIN deliveries d ON d.id = e.delivery_id
+			WHERE e.created_at >= $1 AND e.created_at < $2
+			ORDER BY e.created_at, e.id
+			LIMIT $3`,
+	},
+	// One invoice line per completed delivery, net of completed refunds
+	"invoices": {
+		columns: []string{
+			"delivery_id", "tracking_number", "delivered_at", "currency",
+			"base_fare", "distance_fare", "time_fare", "surge_fare", "service_fee", "insurance_fee", "tip",
+			"total_fare", "refunded", "net_amount", "payment_status",
+		},
+		query: `
+			SELECT d.id::text, d.tracking_number, d.delivered_at, d.currency::text,
+				d.base_fare::float8, d.distance_fare::float8, d.time_fare::float8, d.surge_fare::float8,
+				d.service_fee::float8, d.insurance_fee::float8, d.tip::float8,
+				d.total_fare::float8, COALESCE(rf.refunded, 0)::float8,
+				(d.total_fare - COALESCE(rf.refunded, 0))::float8, d.payment_status::text
+			FROM deliveries d
+			LEFT JOIN LATERAL (
+				SELECT SUM(r.amount) AS refunded FROM delivery_refunds r
+				WHERE r.delivery_id = d.id AND r.status = 'COMPLETED'
+			) rf ON true
+			WHERE d.status = 'DELIVERED' AND d.delivered_at >= $1 AND d.delivered_at < $2
+			ORDER BY d.delivered_at, d.id
+			LIMIT $3`,
+	},
+}
+
+// errExportTooLarge fails an export with more rows than maxExportRows
+var errExportTooLarge = fmt.Errorf("more than %d rows, export a shorter date range", maxExportRows)
+
+// partnerExport is an export job and, once built, its file
+type partnerExport struct {
+	ID          string     `json:"id"`
+	Dataset     string     `json:"dataset"`
+	Format      string     `json:"format"`
+	From        time.Time  `json:"from"`
+	To          time.Time  `json:"to"`
+	Status      string     `json:"status"` // PENDING, RUNNING, COMPLETED, FAILED or EXPIRED
+	RowCount    *int       `json:"rowCount,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+	DownloadURL string     `json:"downloadUrl,omitempty"`
+	LinkExpires *time.Time `json:"downloadUrlExpiresAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+
+	partnerID string
+}
+
+const partnerExportColumns = `
+	id, partner_id, dataset, format, range_start, range_end,
+	CASE WHEN status = 'COMPLETED' AND expires_at <= NOW() THEN 'EXPIRED' ELSE status END,
+	row_count, error, created_at, completed_at, expires_at`
+
+func scanPartnerExport(row pgx.Row) (*partnerExport, error) {
+	var e partnerExport
+	err := row.Scan(
+		&e.ID, &e.partnerID, &e.Dataset, &e.Format, &e.From, &e.To,
+		&e.Status, &e.RowCount, &e.Error, &e.CreatedAt, &e.CompletedAt, &e.ExpiresAt,
+	)
+	return &e, err
+}
+
+// CreateExportRequest asks for a dataset over [from, to)
+type CreateExportRequest struct {
+	Dataset string    `json:"dataset"`
+	Format  string    `json:"format"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+}
+
+// CreateExport queues an export of the partner's data. It is built in the
+// background; the caller polls GetExport for its download link.
+// POST /api/v1/exports
+func (h *Handler) CreateExport(w http.ResponseWriter, r *http.Request) {
+	partnerID := middleware.GetPartnerID(r.Context())
+
+	var req CreateExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+	if req.Format == "" {
+		req.Format = "csv"
+	}
+	if _, ok := exportDatasets[req.Dataset]; !ok {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "dataset must be one of deliveries, events, invoices")
+		return
+	}
+	if _, ok := exportFormats[req.Format]; !ok {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "format must be csv or jsonl")
+		return
+	}
+	if req.From.IsZero() || !req.To.After(req.From) {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "to must be an RFC3339 timestamp after from")
+		return
+	}
+	if req.To.Sub(req.From) > maxExportRange {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Exports may cover at most 93 days")
+		return
+	}
+
+	var open int
+	err := h.db.Pool.QueryRow(r.Context(),
+		"SELECT COUNT(*) FROM partner_exports WHERE partner_id = $1 AND status IN ('PENDING', 'RUNNING')",
+		partnerID,
+	).Scan(&open)
+	if err != nil {
+		log.Error().Err(err).Str("partner_id", partnerID).Msg("Failed to count open exports")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to create export")
+		return
+	}
+	if open >= maxOpenExports {
+		respondError(w, http.StatusTooManyRequests, "TOO_MANY_EXPORTS", "Wait for your other exports to finish")
+		return
+	}
+
+	export, err := scanPartnerExport(h.db.Pool.QueryRow(r.Context(), `
+		INSERT INTO partner_exports (id, partner_id, requested_by, dataset, format, range_start, range_end, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'PENDING', NOW())
+		RETURNING `+partnerExportColumns,
+		uuid.New(), partnerID, middleware.GetUserID(r.Context()), req.Dataset, req.Format, req.From.UTC(), req.To.UTC(),
+	))
+	if err != nil {
+		log.Error().Err(err).Str("partner_id", partnerID).Msg("Failed to create export")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to create export")
+		return
+	}
+
+	respond(w, http.StatusAccepted, export)
+}
+
+// ListExports returns the partner's recent exports
+// GET /api/v1/exports
+func (h *Handler) ListExports(w http.ResponseWriter, r *http.Request) {
+	partnerID := middleware.GetPartnerID(r.Context())
+
+	rows, err := h.db.Pool.Query(r.Context(), `
+		SELECT `+partnerExportColumns+` FROM partner_exports
+		WHERE partner_id = $1
+		ORDER BY created_at DESC
+		LIMIT 50`,
+		partnerID,
+	)
+	if err != nil {
+		log.Error().Err(err).Str("partner_id", partnerID).Msg("Failed to list exports")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to list exports")
+		return
+	}
+	defer rows.Close()
+
+	exports := []*partnerExport{}
+	for rows.Next() {
+		export, err := scanPartnerExport(rows)
+		if err != nil {
+			log.Error().Err(err).Str("partner_id", partnerID).Msg("Failed to list exports")
+			respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to list exports")
+			return
+		}
+		h.signExport(export)
+		exports = append(exports, export)
+	}
+
+	respond(w, http.StatusOK, map[string]interface{}{"exports": exports})
+}
+
+// GetExport returns an export's status, with a signed download link once
+// it is built
+// GET /api/v1/exports/{exportId}
+func (h *Handler) GetExport(w http.ResponseWriter, r *http.Request) {
+	partnerID := middleware.GetPartnerID(r.Context())
+
+	export, err := scanPartnerExport(h.db.Pool.QueryRow(r.Context(),
+		"SELECT "+partnerExportColumns+" FROM partner_exports WHERE id = $1 AND partner_id = $2",
+		chi.URLParam(r, "exportId"), partnerID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Export not found")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("partner_id", partnerID).Msg("Failed to fetch export")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch export")
+		return
+	}
+
+	h.signExport(export)
+	respond(w, http.StatusOK, export)
+}
+
+// DownloadExport serves a built export to whoever holds its signed link.
+// The link carries no token, so it can be handed to a browser or a
+// partner's batch job.
+// GET /api/v1/exports/{exportId}/download?expires=&signature=
+func (h *Handler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	exportID := chi.URLParam(r, "exportId")
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err != nil || !h.validExportSignature(exportID, expires, r.URL.Query().Get("signature")) {
+		respondError(w, http.StatusForbidden, "INVALID_SIGNATURE", "Invalid download link")
+		return
+	}
+	if time.Now().Unix() > expires {
+		respondError(w, http.StatusGone, "LINK_EXPIRED", "Download link has expired, fetch the export for a new one")
+		return
+	}
+
+	var dataset, format string
+	var from time.Time
+	var content []byte
+	err = h.db.Pool.QueryRow(r.Context(), `
+		SELECT dataset, format, range_start, content FROM partner_exports
+		WHERE id = $1 AND status = 'COMPLETED' AND expires_at > NOW()`,
+		exportID,
+	).Scan(&dataset, &format, &from, &content)
+	if errors.Is(err, pgx.ErrNoRows) {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Export not found")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("export_id", exportID).Msg("Failed to fetch export content")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch export")
+		return
+	}
+
+	w.Header().Set("Content-Type", exportFormats[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.%s"`, dataset, from.Format("20060102"), format))
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
+
+// signExport sets a completed export's download link
+func (h *Handler) signExport(export *partnerExport) {
+	if export.Status != "COMPLETED" {
+		return
+	}
+	expires := time.Now().Add(h.cfg.ExportLinkTTL).Truncate(time.Second)
+	if export.ExpiresAt != nil && export.ExpiresAt.Before(expires) {
+		expires = *export.ExpiresAt
+	}
+	export.DownloadURL = fmt.Sprintf("/api/v1/exports/%s/download?expires=%d&signature=%s",
+		export.ID, expires.Unix(), h.exportSignature(export.ID, expires.Unix()))
+	export.LinkExpires = &expires
+}
+
+func (h *Handler) exportSignature(exportID string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(h.cfg.ExportSigningKey))
+	fmt.Fprintf(mac, "%s:%d", exportID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (h *Handler) validExportSignature(exportID string, expires int64, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(h.exportSignature(exportID, expires)))
+}
+
+// buildPartnerExports builds waiting exports, and exports a replica gave
+// up on part way, then drops the files of expired ones
+func (h *Handler) buildPartnerExports(ctx context.Context) error {
+	rows, err := h.db.Pool.Query(ctx, `
+		UPDATE partner_exports SET status = 'RUNNING', started_at = NOW()
+		WHERE id IN (
+			SELECT id FROM partner_exports
+			WHERE status = 'PENDING' OR (status = 'RUNNING' AND started_at < $1)
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+partnerExportColumns,
+		time.Now().Add(-exportStaleAfter), exportBatchSize,
+	)
+	if err != nil {
+		return err
+	}
+	var exports []*partnerExport
+	for rows.Next() {
+		export, err := scanPartnerExport(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		exports = append(exports, export)
+	}
+	rows.Close()
+
+	for _, export := range exports {
+		h.buildPartnerExport(ctx, export)
+	}
+
+	_, err = h.db.Pool.Exec(ctx,
+		"UPDATE partner_exports SET content = NULL WHERE status = 'COMPLETED' AND expires_at <= NOW() AND content IS NOT NULL",
+	)
+	return err
+}
+
+// buildPartnerExport runs one export in its partner's scope and stores the
+// file, or why it failed
+func (h *Handler) buildPartnerExport(ctx context.Context, export *partnerExport) {
+	content, count, err := h.writeExport(database.WithPartner(ctx, export.partnerID), export)
+	if err != nil {
+		message := "Export failed, please try again"
+		if errors.Is(err, errExportTooLarge) {
+			message = err.Error()
+		}
+		log.Error().Err(err).Str("export_id", export.ID).Str("partner_id", export.partnerID).Msg("Failed to build export")
+		if _, err := h.db.Pool.Exec(ctx,
+			"UPDATE partner_exports SET status = 'FAILED', error = $2, completed_at = NOW() WHERE id = $1",
+			export.ID, message,
+		); err != nil {
+			log.Error().Err(err).Str("export_id", export.ID).Msg("Failed to mark export failed")
+		}
+		return
+	}
+
+	_, err = h.db.Pool.Exec(ctx, `
+		UPDATE partner_exports SET
+			status = 'COMPLETED',
+			row_count = $2,
+			content = $3,
+			completed_at = NOW(),
+			expires_at = NOW() + $4 * INTERVAL '1 second'
+		WHERE id = $1`,
+		export.ID, count, content, int(exportRetention.Seconds()),
+	)
+	if err != nil {
+		log.Error().Err(err).Str("export_id", export.ID).Msg("Failed to store export")
+		return
+	}
+
+	log.Info().
+		Str("export_id", export.ID).
+		Str("partner_id", export.partnerID).
+		Str("dataset", export.Dataset).
+		Int("rows", count).
+		Msg("Partner export built")
+}
+
+// writeExport queries an export's rows and writes them in its format
+func (h *Handler) writeExport(ctx context.Context, export *partnerExport) ([]byte, int, error) {
+	dataset, ok := exportDatasets[export.Dataset]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown dataset %q", export.Dataset)
+	}
+
+	rows, err := h.db.Pool.Query(ctx, dataset.query, export.From, export.To, maxExportRows+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var buf bytes.Buffer
+	var csvWriter *csv.Writer
+	jsonEncoder := json.NewEncoder(&buf)
+	if export.Format == "csv" {
+		csvWriter = csv.NewWriter(&buf)
+		csvWriter.Write(dataset.columns)
+	}
+
+	count := 0
+	for rows.Next() {
+		count++
+		if count > maxExportRows {
+			return nil, 0, errExportTooLarge
+		}
+		values, err := rows.Values()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if csvWriter != nil {
+			record := make([]string, len(values))
+			for i, v := range values {
+				record[i] = exportCell(v)
+			}
+			csvWriter.Write(record)
+			continue
+		}
+		line := make(map[string]interface{}, len(values))
+		for i, v := range values {
+			line[dataset.columns[i]] = v
+		}
+		if err := jsonEncoder.Encode(line); err != nil {
+			return nil, 0, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	if csvWriter != nil {
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return buf.Bytes(), count, nil
+}
+
+// exportCell formats a value for a CSV cell
+func exportCell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
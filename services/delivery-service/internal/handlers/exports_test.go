@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/config"
+)
+
+func newExportHandler() *Handler {
+	return &Handler{cfg: &config.Config{ExportSigningKey: "export-key", ExportLinkTTL: 15 * time.Minute}}
+}
+
+func TestCreateExportValidation(t *testing.T) {
+	from := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	export := func(dataset, format string, from, to time.Time) string {
+		b, _ := json.Marshal(CreateExportRequest{Dataset: dataset, Format: format, From: from, To: to})
+		return string(b)
+	}
+
+	tests := map[string]struct {
+		body     string
+		wantCode string
+	}{
+		"bad JSON":          {`{`, "INVALID_REQUEST"},
+		"unknown dataset":   {export("drivers", "csv", from, from.AddDate(0, 1, 0)), "VALIDATION_ERROR"},
+		"unknown format":    {export("deliveries", "xlsx", from, from.AddDate(0, 1, 0)), "VALIDATION_ERROR"},
+		"no start":          {export("deliveries", "csv", time.Time{}, from), "VALIDATION_ERROR"},
+		"ends before start": {export("events", "jsonl", from, from.Add(-time.Hour)), "VALIDATION_ERROR"},
+		"empty range":       {export("events", "", from, from), "VALIDATION_ERROR"},
+		"over 93 days":      {export("invoices", "csv", from, from.Add(maxExportRange+time.Second)), "VALIDATION_ERROR"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newExportHandler().CreateExport(rec, httptest.NewRequest(http.MethodPost, "/api/v1/exports", strings.NewReader(tt.body)))
+
+			var resp response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != http.StatusBadRequest || resp.Error == nil || resp.Error.Code != tt.wantCode {
+				t.Errorf("status = %d, error = %+v, want 400 %s", rec.Code, resp.Error, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSignExport(t *testing.T) {
+	h := newExportHandler()
+
+	t.Run("completed", func(t *testing.T) {
+		export := &partnerExport{ID: "exp-1", Status: "COMPLETED"}
+		h.signExport(export)
+		if export.LinkExpires == nil {
+			t.Fatal("LinkExpires = nil, want the link's expiry")
+		}
+		if ttl := time.Until(*export.LinkExpires); ttl <= 14*time.Minute || ttl > 15*time.Minute {
+			t.Errorf("link valid for %s, want ExportLinkTTL", ttl)
+		}
+
+		link, err := url.Parse(export.DownloadURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if link.Path != "/api/v1/exports/exp-1/download" {
+			t.Errorf("path = %s", link.Path)
+		}
+		if link.Query().Get("expires") == "" || link.Query().Get("signature") == "" {
+			t.Errorf("DownloadURL = %s, want expires and signature", export.DownloadURL)
+		}
+	})
+
+	t.Run("never outlives the file", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+		export := &partnerExport{ID: "exp-1", Status: "COMPLETED", ExpiresAt: &expiresAt}
+		h.signExport(export)
+		if export.LinkExpires == nil || !export.LinkExpires.Equal(expiresAt) {
+			t.Errorf("LinkExpires = %v, want %s", export.LinkExpires, expiresAt)
+		}
+	})
+
+	for _, status := range []string{"PENDING", "RUNNING", "FAILED", "EXPIRED"} {
+		export := &partnerExport{ID: "exp-1", Status: status}
+		h.signExport(export)
+		if export.DownloadURL != "" || export.LinkExpires != nil {
+			t.Errorf("%s export got a download link %q", status, export.DownloadURL)
+		}
+	}
+}
+
+func TestValidExportSignature(t *testing.T) {
+	h := newExportHandler()
+	expires := time.Now().Add(time.Hour).Unix()
+	signature := h.exportSignature("exp-1", expires)
+
+	if !h.validExportSignature("exp-1", expires, signature) {
+		t.Error("own signature rejected")
+	}
+	if h.validExportSignature("exp-2", expires, signature) {
+		t.Error("signature accepted for another export")
+	}
+	if h.validExportSignature("exp-1", expires+3600, signature) {
+		t.Error("signature accepted with a later expiry")
+	}
+	other := &Handler{cfg: &config.Config{ExportSigningKey: "other-key"}}
+	if other.validExportSignature("exp-1", expires, signature) {
+		t.Error("signature accepted under another key")
+	}
+}
+
+// Bad and expired links are refused before the database is reached
+func TestDownloadExportRefusesBadLinks(t *testing.T) {
+	h := newExportHandler()
+	live := time.Now().Add(time.Hour).Unix()
+	expired := time.Now().Add(-time.Minute).Unix()
+
+	tests := []struct {
+		name       string
+		query      url.Values
+		wantStatus int
+		wantCode   string
+	}{
+		{"no signature", url.Values{"expires": {strconv.FormatInt(live, 10)}}, http.StatusForbidden, "INVALID_SIGNATURE"},
+		{"no expiry", url.Values{"signature": {h.exportSignature("exp-1", live)}}, http.StatusForbidden, "INVALID_SIGNATURE"},
+		{"tampered expiry", url.Values{"expires": {strconv.FormatInt(live+60, 10)}, "signature": {h.exportSignature("exp-1", live)}}, http.StatusForbidden, "INVALID_SIGNATURE"},
+		{"another export's link", url.Values{"expires": {strconv.FormatInt(live, 10)}, "signature": {h.exportSignature("exp-2", live)}}, http.StatusForbidden, "INVALID_SIGNATURE"},
+		{"expired", url.Values{"expires": {strconv.FormatInt(expired, 10)}, "signature": {h.exportSignature("exp-1", expired)}}, http.StatusGone, "LINK_EXPIRED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("exportId", "exp-1")
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/exports/exp-1/download?"+tt.query.Encode(), nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rec := httptest.NewRecorder()
+			h.DownloadExport(rec, req)
+
+			var resp response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tt.wantStatus || resp.Error == nil || resp.Error.Code != tt.wantCode {
+				t.Errorf("status = %d, error = %+v, want %d %s", rec.Code, resp.Error, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestExportCell(t *testing.T) {
+	at := time.Date(2026, 10, 18, 21, 30, 0, 0, time.FixedZone("WAT", 3600))
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, ""},
+		{"DELIVERED", "DELIVERED"},
+		{at, "2026-10-18T20:30:00Z"},
+		{1500.0, "1500"},
+		{12.75, "12.75"},
+		{int64(42), "42"},
+		{true, "true"},
+	}
+	for _, tt := range tests {
+		if got := exportCell(tt.value); got != tt.want {
+			t.Errorf("exportCell(%#v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+/*
+ * Failed Deliveries
+ */
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+// failurePolicy is how a failed delivery attempt is handled
+type failurePolicy struct {
+	Retry bool   // another attempt is scheduled, up to maxDeliveryAttempts
+	Fault string // CUSTOMER, COURIER or NONE; decides the refund once the delivery finally fails
+}
+
+// failureReasons are the reasons a courier can give for not delivering
+var failureReasons = map[string]failurePolicy{
+	"RECIPIENT_UNREACHABLE": {Retry: true, Fault: "CUSTOMER"},
+	"RECIPIENT_UNAVAILABLE": {Retry: true, Fault: "CUSTOMER"},
+	"ACCESS_DENIED":         {Retry: true, Fault: "CUSTOMER"},
+	"WRONG_ADDRESS":         {Retry: false, Fault: "CUSTOMER"},
+	"REFUSED":               {Retry: false, Fault: "CUSTOMER"},
+	"UNSAFE_LOCATION":       {Retry: true, Fault: "NONE"},
+	"VEHICLE_BREAKDOWN":     {Retry: true, Fault: "COURIER"},
+	"PACKAGE_DAMAGED":       {Retry: false, Fault: "COURIER"},
+}
+
+const (
+	// maxDeliveryAttempts is how many times a delivery is tried before it
+	// fails for good
+	maxDeliveryAttempts = 3
+
+	// retryAttemptHour is the local hour next-day attempts are offered from
+	retryAttemptHour = 10
+
+	// failedDeliveryFeeShare is the share of the fare kept when a delivery
+	// fails through the customer's fault, for the courier's trip
+	failedDeliveryFeeShare = 0.5
+
+	deliveryRetryBatch = 200
+)
+
+// deliveryTimeZones place next-day attempts in the local day of the
+// delivery's market. None of these observe daylight saving.
+var deliveryTimeZones = map[models.Currency]*time.Location{
+	models.CurrencyNGN: time.FixedZone("WAT", 1*60*60),
+	models.CurrencyKES: time.FixedZone("EAT", 3*60*60),
+	models.CurrencyGHS: time.FixedZone("GMT", 0),
+	models.CurrencyUGX: time.FixedZone("EAT", 3*60*60),
+	models.CurrencyTZS: time.FixedZone("EAT", 3*60*60),
+	models.CurrencyZAR: time.FixedZone("SAST", 2*60*60),
+	models.CurrencyXOF: time.FixedZone("GMT", 0),
+}
+
+// retries reports whether a failed attempt, counting from 1, is followed
+// by another. Perishable goods will not keep until tomorrow.
+func (p failurePolicy) retries(attempt int, deliveryType models.DeliveryType) bool {
+	return p.Retry && attempt < maxDeliveryAttempts && deliveryType != models.DeliveryTypePerishable
+}
+
+// refund is the refund owed on a fare once a delivery has failed for good:
+// all of it, less the courier's trip when the customer was at fault
+func (p failurePolicy) refund(totalFare float64) (refundType, reason string, amount float64) {
+	if p.Fault == "CUSTOMER" {
+		return "PARTIAL", "FAILED_DELIVERY", math.Round(totalFare*(1-failedDeliveryFeeShare)*100) / 100
+	}
+	return "FULL", "NOT_DELIVERED", totalFare
+}
+
+// nextAttemptAt is the next day's attempt time in the delivery's market
+func nextAttemptAt(now time.Time, currency models.Currency) time.Time {
+	loc, ok := deliveryTimeZones[currency]
+	if !ok {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, retryAttemptHour, 0, 0, 0, loc)
+}
+
+// FailDelivery records a failed delivery attempt. Depending on the reason
+// and how many attempts have been made, the delivery is scheduled for a
+// next-day attempt or fails for good and its fare is refunded.
+func (h *Handler) FailDelivery(w http.ResponseWriter, r *http.Request) {
+	driverID := middleware.GetUserID(r.Context())
+	deliveryID := chi.URLParam(r, "id")
+
+	var req struct {
+		Reason string  `json:"reason"`
+		Note   string  `json:"note,omitempty"`
+		Photo  string  `json:"photo,omitempty"`
+		Lat    float64 `json:"latitude"`
+		Lon    float64 `json:"longitude"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return
+	}
+	reason := strings.ToUpper(strings.TrimSpace(req.Reason))
+	policy, ok := failureReasons[reason]
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_FAILURE_REASON", "Unknown failure reason")
+		return
+	}
+
+	var status, customerID string
+	var deliveryType models.DeliveryType
+	var currency models.Currency
+	var failedAttempts int
+	err := h.db.Pool.QueryRow(r.Context(), `
+		SELECT status, customer_id, type, currency, failed_attempts
+		FROM deliveries WHERE id = $1 AND driver_id = $2`,
+		deliveryID, driverID,
+	).Scan(&status, &customerID, &deliveryType, &currency, &failedAttempts)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Delivery not found")
+		return
+	}
+	if status != "PICKED_UP" && status != "IN_TRANSIT" {
+		respondError(w, http.StatusBadRequest, "INVALID_STATUS", "Cannot fail delivery at this stage")
+		return
+	}
+
+	attempt := failedAttempts + 1
+	retry := policy.retries(attempt, deliveryType)
+
+	newStatus := models.DeliveryStatusFailed
+	var nextAttempt *time.Time
+	if retry {
+		newStatus = models.DeliveryStatusRetryScheduled
+		at := nextAttemptAt(time.Now(), currency)
+		nextAttempt = &at
+	}
+
+	result, err := h.db.Pool.Exec(r.Context(), `
+		UPDATE deliveries SET
+			status = $3,
+			failed_attempts = failed_attempts + 1,
+			failure_reason = $4,
+			next_attempt_at = $5,
+			failed_at = CASE WHEN $3 = 'FAILED' THEN NOW() ELSE failed_at END,
+			updated_at = NOW()
+		WHERE id = $1 AND driver_id = $2 AND status IN ('PICKED_UP', 'IN_TRANSIT')`,
+		deliveryID, driverID, newStatus, reason, nextAttempt,
+	)
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to record failed delivery")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to record failed delivery")
+		return
+	}
+	if result.RowsAffected() == 0 {
+		respondError(w, http.StatusConflict, "INVALID_STATUS", "Delivery changed, try again")
+		return
+	}
+
+	note := reason
+	if req.Note != "" {
+		note += ": " + req.Note
+	}
+	location := map[string]float64{"latitude": req.Lat, "longitude": req.Lon}
+	h.createDeliveryEvent(r.Context(), deliveryID, "delivery_failed", string(newStatus), location, &note)
+
+	resp := map[string]interface{}{
+		"status":        newStatus,
+		"reason":        reason,
+		"attempt":       attempt,
+		"maxAttempts":   maxDeliveryAttempts,
+		"nextAttemptAt": nextAttempt,
+	}
+	if !retry {
+		refund, err := h.refundFailedDelivery(r.Context(), deliveryID, driverID, reason, policy)
+		if err != nil {
+			log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to refund failed delivery")
+		}
+		if refund != nil {
+			resp["refund"] = refund
+		}
+	}
+
+	h.rdb.Publish(r.Context(), "delivery:failed", map[string]interface{}{
+		"deliveryId":    deliveryID,
+		"driverId":      driverID,
+		"customerId":    customerID,
+		"reason":        reason,
+		"attempt":       attempt,
+		"retry":         retry,
+		"nextAttemptAt": nextAttempt,
+	})
+
+	respond(w, http.StatusOK, resp)
+}
+
+// refundFailedDelivery refunds a delivery that failed for good, through
+// the usual refund workflow. The whole fare comes back unless the customer
+// was at fault, in which case the courier's trip is paid for out of it.
+// Unpaid deliveries have nothing to refund.
+func (h *Handler) refundFailedDelivery(ctx context.Context, deliveryID, driverID, reason string, policy failurePolicy) (*deliveryRefund, error) {
+	var customerID, paymentStatus, currency string
+	var totalFare float64
+	err := h.db.Pool.QueryRow(ctx, `
+		SELECT customer_id, payment_status, total_fare, currency
+		FROM deliveries WHERE id = $1`,
+		deliveryID,
+	).Scan(&customerID, &paymentStatus, &totalFare, &currency)
+	if err != nil || paymentStatus != "PAID" {
+		return nil, err
+	}
+
+	refundType, refundReason, amount := policy.refund(totalFare)
+	if amount <= 0 {
+		return nil, nil
+	}
+
+	refundStatus := "PROCESSING"
+	threshold, ok := refundApprovalThresholds[currency]
+	if !ok {
+		threshold = refundApprovalThresholds["USD"]
+	}
+	if amount >= threshold {
+		refundStatus = "PENDING_APPROVAL"
+	}
+
+	refundID := uuid.New().String()
+	_, err = h.db.Pool.Exec(ctx, `
+		INSERT INTO delivery_refunds (id, delivery_id, customer_id, type, reason, note, amount, currency, status, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		refundID, deliveryID, customerID, refundType, refundReason, "Delivery failed: "+reason, amount, currency, refundStatus, driverID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if refundStatus == "PROCESSING" {
+		h.executeRefund(ctx, refundID)
+	}
+	return h.getRefund(ctx, refundID)
+}
+
+// reopenDueRetries puts deliveries whose next attempt is due back up for
+// couriers to accept
+func (h *Handler) reopenDueRetries(ctx context.Context) error {
+	rows, err := h.db.Pool.Query(ctx, `
+		UPDATE deliveries SET
+			status = 'CONFIRMED',
+			driver_id = NULL,
+			next_attempt_at = NULL,
+			updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM deliveries
+			WHERE status = 'RETRY_SCHEDULED' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+		)
+		RETURNING id, customer_id, failed_attempts`,
+		deliveryRetryBatch,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type reopened struct {
+		deliveryID, customerID string
+		failedAttempts         int
+	}
+	var due []reopened
+	for rows.Next() {
+		var d reopened
+		if err := rows.Scan(&d.deliveryID, &d.customerID, &d.failedAttempts); err != nil {
+			return err
+		}
+		due = append(due, d)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, d := range due {
+		h.createDeliveryEvent(ctx, d.deliveryID, "retry_opened", "CONFIRMED", nil, nil)
+		h.rdb.Publish(ctx, "delivery:retry_opened", map[string]interface{}{
+			"deliveryId": d.deliveryID,
+			"customerId": d.customerID,
+			"attempt":    d.failedAttempts + 1,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+func TestFailurePolicyRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		reason       string
+		attempt      int
+		deliveryType models.DeliveryType
+		want         bool
+	}{
+		{"recipient unreachable, first attempt", "RECIPIENT_UNREACHABLE", 1, models.DeliveryTypeStandard, true},
+		{"recipient unreachable, second attempt", "RECIPIENT_UNREACHABLE", 2, models.DeliveryTypeStandard, true},
+		{"recipient unreachable, last attempt", "RECIPIENT_UNREACHABLE", maxDeliveryAttempts, models.DeliveryTypeStandard, false},
+		{"vehicle breakdown", "VEHICLE_BREAKDOWN", 1, models.DeliveryTypeExpress, true},
+		{"refused", "REFUSED", 1, models.DeliveryTypeStandard, false},
+		{"wrong address", "WRONG_ADDRESS", 1, models.DeliveryTypeStandard, false},
+		{"damaged", "PACKAGE_DAMAGED", 1, models.DeliveryTypeStandard, false},
+		{"perishable never waits a day", "RECIPIENT_UNAVAILABLE", 1, models.DeliveryTypePerishable, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, ok := failureReasons[tt.reason]
+			if !ok {
+				t.Fatalf("no policy for %s", tt.reason)
+			}
+			if got := policy.retries(tt.attempt, tt.deliveryType); got != tt.want {
+				t.Errorf("retries(%d, %s) = %v, want %v", tt.attempt, tt.deliveryType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailurePolicyRefund(t *testing.T) {
+	tests := []struct {
+		reason     string
+		fare       float64
+		wantType   string
+		wantReason string
+		wantAmount float64
+	}{
+		{"REFUSED", 2500, "PARTIAL", "FAILED_DELIVERY", 1250},
+		{"WRONG_ADDRESS", 1234.57, "PARTIAL", "FAILED_DELIVERY", 617.29},
+		{"RECIPIENT_UNREACHABLE", 0, "PARTIAL", "FAILED_DELIVERY", 0},
+		{"PACKAGE_DAMAGED", 2500, "FULL", "NOT_DELIVERED", 2500},
+		{"UNSAFE_LOCATION", 1800, "FULL", "NOT_DELIVERED", 1800},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			refundType, reason, amount := failureReasons[tt.reason].refund(tt.fare)
+			if refundType != tt.wantType || reason != tt.wantReason || amount != tt.wantAmount {
+				t.Errorf("refund(%v) = %s %s %v, want %s %s %v", tt.fare, refundType, reason, amount, tt.wantType, tt.wantReason, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestNextAttemptAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		now      time.Time
+		currency models.Currency
+		want     time.Time
+	}{
+		{
+			name:     "Lagos afternoon",
+			now:      time.Date(2026, 10, 18, 14, 0, 0, 0, time.UTC),
+			currency: models.CurrencyNGN,
+			want:     time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), // 10:00 WAT
+		},
+		{
+			name:     "Nairobi just after midnight is already the next day",
+			now:      time.Date(2026, 10, 18, 22, 30, 0, 0, time.UTC),
+			currency: models.CurrencyKES,
+			want:     time.Date(2026, 10, 20, 7, 0, 0, 0, time.UTC), // 10:00 EAT on the 20th
+		},
+		{
+			name:     "end of the month",
+			now:      time.Date(2026, 10, 31, 12, 0, 0, 0, time.UTC),
+			currency: models.CurrencyZAR,
+			want:     time.Date(2026, 11, 1, 8, 0, 0, 0, time.UTC), // 10:00 SAST
+		},
+		{
+			name:     "unknown currency uses UTC",
+			now:      time.Date(2026, 10, 18, 14, 0, 0, 0, time.UTC),
+			currency: models.Currency("USD"),
+			want:     time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextAttemptAt(tt.now, tt.currency); !got.Equal(tt.want) {
+				t.Errorf("nextAttemptAt() = %s, want %s", got.UTC(), tt.want)
+			}
+		})
+	}
+}
@@ -7,8 +7,10 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -20,21 +22,25 @@ import (
 	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
 	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
 	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/worker"
 )
 
 // Handler holds all HTTP handlers
 type Handler struct {
-	db  *database.DB
-	rdb *redis.Client
-	cfg *config.Config
+	db      *database.DB
+	rdb     *redis.Client
+	cfg     *config.Config
+	workers *worker.Scheduler
+	tenancy tenancyStore
 }
 
 // New creates a new Handler
 func New(db *database.DB, rdb *redis.Client, cfg *config.Config) *Handler {
 	return &Handler{
-		db:  db,
-		rdb: rdb,
-		cfg: cfg,
+		db:      db,
+		rdb:     rdb,
+		cfg:     cfg,
+		tenancy: db,
 	}
 }
 
@@ -154,6 +160,15 @@ type CreateDeliveryRequest struct {
 	PickupInstructions   string              `json:"pickupInstructions,omitempty"`
 	DeliveryInstructions string              `json:"deliveryInstructions,omitempty"`
 	Currency             models.Currency     `json:"currency"`
+
+	// Perishable deliveries only
+	MaxTransitMinutes int      `json:"maxTransitMinutes,omitempty"`
+	TemperatureMinC   *float64 `json:"temperatureMinC,omitempty"`
+	TemperatureMaxC   *float64 `json:"temperatureMaxC,omitempty"`
+
+	// Regulated goods, e.g. prescription medicine
+	Regulated    bool          `json:"regulated,omitempty"`
+	Prescription *Prescription `json:"prescription,omitempty"`
 }
 
 func (h *Handler) CreateDelivery(w http.ResponseWriter, r *http.Request) {
@@ -165,20 +180,87 @@ func (h *Handler) CreateDelivery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Plus codes may stand in for coordinates, e.g. for dropoffs with no
+	// street address
+	if err := resolvePlusCode(&req.PickupLocation, &req.DropoffLocation); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_PLUS_CODE", "Pickup: "+err.Error())
+		return
+	}
+	if err := resolvePlusCode(&req.DropoffLocation, &req.PickupLocation); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_PLUS_CODE", "Dropoff: "+err.Error())
+		return
+	}
+
+	// Use where couriers actually found these addresses
+	h.applyAddressCorrection(r.Context(), &req.PickupLocation)
+	h.applyAddressCorrection(r.Context(), &req.DropoffLocation)
+
 	// Validate
 	if req.PickupLocation.Latitude == 0 || req.DropoffLocation.Latitude == 0 {
 		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Pickup and dropoff locations required")
 		return
 	}
 
+	// Ops may have switched new deliveries off in the pickup city
+	if sw := h.deliveriesSwitchedOff(r.Context(), req.PickupLocation.City); sw != nil {
+		respondFeatureDisabled(w, sw)
+		return
+	}
+
+	if violations := checkRestrictedItems(&req.Package, req.DropoffLocation, req.Currency); len(violations) > 0 {
+		respondRestrictedItems(w, violations)
+		return
+	}
+	if msg := applyRegulatedTerms(&req); msg != "" {
+		respondError(w, http.StatusUnprocessableEntity, "REGULATED_TERMS", msg)
+		return
+	}
+
+	// Merchants hand over only in their pickup windows
+	var windowStart, windowEnd *time.Time
+	slot, err := h.applyPickupWindows(r.Context(), userID, &req)
+	var windowErr *pickupWindowError
+	if errors.As(err, &windowErr) {
+		respondPickupWindow(w, windowErr)
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check pickup windows")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to create delivery")
+		return
+	}
+	if slot != nil {
+		windowStart, windowEnd = &slot.WindowStart, &slot.WindowEnd
+	}
+
+	// Instructions double as driver-facing directions unless the client
+	// sent directions of its own
+	if req.PickupLocation.Directions == "" {
+		req.PickupLocation.Directions = req.PickupInstructions
+	}
+	if req.DropoffLocation.Directions == "" {
+		req.DropoffLocation.Directions = req.DeliveryInstructions
+	}
+
 	// Calculate distance
 	distance := haversineDistance(
 		req.PickupLocation.Latitude, req.PickupLocation.Longitude,
 		req.DropoffLocation.Latitude, req.DropoffLocation.Longitude,
 	)
 
+	// Estimate time (avg 20 km/h in city)
+	estimatedMinutes := int(math.Ceil((distance / 20.0) * 60))
+	if estimatedMinutes < 15 {
+		estimatedMinutes = 15
+	}
+
+	if msg := applyPerishableTerms(&req, estimatedMinutes); msg != "" {
+		respondError(w, http.StatusUnprocessableEntity, "PERISHABLE_TERMS", msg)
+		return
+	}
+
 	// Calculate fare
-	fare := h.calculateFare(distance, req.Package.Size, req.Type)
+	fare := h.calculateFare(r.Context(), distance, req.Package.Size, req.Type, req.Currency)
 
 	// Generate IDs
 	deliveryID := "del_" + uuid.New().String()[:12]
@@ -191,10 +273,23 @@ func (h *Handler) CreateDelivery(w http.ResponseWriter, r *http.Request) {
 	dropoffContact, _ := json.Marshal(req.DropoffContact)
 	pkg, _ := json.Marshal(req.Package)
 
-	// Estimate time (avg 20 km/h in city)
-	estimatedMinutes := int(math.Ceil((distance / 20.0) * 60))
-	if estimatedMinutes < 15 {
-		estimatedMinutes = 15
+	// Contacts are personal data, sealed at rest
+	if pickupContact, err = h.db.SealJSON(r.Context(), pickupContact); err == nil {
+		dropoffContact, err = h.db.SealJSON(r.Context(), dropoffContact)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to seal delivery contacts")
+		respondError(w, http.StatusInternalServerError, "ENCRYPTION_ERROR", "Failed to create delivery")
+		return
+	}
+
+	// Regulated deliveries keep their prescription apart from the delivery
+	if req.Regulated {
+		if err := h.createComplianceRecord(r.Context(), deliveryID, userID, req.Prescription); err != nil {
+			log.Error().Err(err).Msg("Failed to create compliance record")
+			respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to create delivery")
+			return
+		}
 	}
 
 	// Insert delivery
@@ -206,6 +301,8 @@ func (h *Handler) CreateDelivery(w http.ResponseWriter, r *http.Request) {
 			base_fare, distance_fare, time_fare, surge_fare, service_fee, insurance_fee, total_fare,
 			currency, payment_status,
 			scheduled_pickup_time, pickup_instructions, delivery_instructions,
+			max_transit_minutes, temperature_min_c, temperature_max_c, regulated,
+			pickup_window_start, pickup_window_end, contact_name_tokens,
 			created_at, updated_at
 		) VALUES (
 			$1, $2, $3, $4, $5,
@@ -214,6 +311,8 @@ func (h *Handler) CreateDelivery(w http.ResponseWriter, r *http.Request) {
 			$13, $14, $15, $16, $17, $18, $19,
 			$20, $21,
 			$22, $23, $24,
+			NULLIF($25, 0), $26, $27, $28,
+			$29, $30, $31,
 			NOW(), NOW()
 		)
 		RETURNING id, tracking_number, status, total_fare, currency, estimated_minutes, created_at
@@ -229,13 +328,15 @@ func (h *Handler) CreateDelivery(w http.ResponseWriter, r *http.Request) {
 		CreatedAt        time.Time `json:"createdAt"`
 	}
 
-	err := h.db.Pool.QueryRow(r.Context(), query,
+	err = h.db.Pool.QueryRow(r.Context(), query,
 		deliveryID, trackingNumber, userID, req.Type, models.DeliveryStatusPending,
 		pickupLoc, dropoffLoc, pickupContact, dropoffContact,
 		pkg, distance, estimatedMinutes,
 		fare.BaseFare, fare.DistanceFare, fare.TimeFare, fare.SurgeFare, fare.ServiceFee, fare.InsuranceFee, fare.Total,
 		req.Currency, "PENDING",
 		req.ScheduledPickupTime, req.PickupInstructions, req.DeliveryInstructions,
+		req.MaxTransitMinutes, req.TemperatureMinC, req.TemperatureMaxC, req.Regulated,
+		windowStart, windowEnd, h.db.NameTokens(req.PickupContact.Name, req.DropoffContact.Name),
 	).Scan(&delivery.ID, &delivery.TrackingNumber, &delivery.Status, &delivery.TotalFare, &delivery.Currency, &delivery.EstimatedMinutes, &delivery.CreatedAt)
 
 	if err != nil {
@@ -299,6 +400,14 @@ func (h *Handler) GetDelivery(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusNotFound, "NOT_FOUND", "Delivery not found")
 		return
 	}
+	if err := h.openDeliveryContacts(r.Context(), &d.PickupContact, &d.DropoffContact); err == nil {
+		err = h.db.OpenNullText(r.Context(), &d.RecipientSignature)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to open sealed delivery fields")
+		respondError(w, http.StatusInternalServerError, "ENCRYPTION_ERROR", "Failed to fetch delivery")
+		return
+	}
 
 	respond(w, http.StatusOK, d)
 }
@@ -480,10 +589,18 @@ func (h *Handler) TrackDelivery(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	// Courier and customer messages
+	messages, err := h.listDeliveryMessages(r.Context(), deliveryID)
+	if err != nil {
+		log.Warn().Err(err).Str("delivery_id", deliveryID).Msg("Failed to fetch delivery messages")
+		messages = []deliveryMessage{}
+	}
+
 	respond(w, http.StatusOK, map[string]interface{}{
 		"delivery":       d,
 		"driverLocation": driverLocation,
 		"events":         events,
+		"messages":       messages,
 	})
 }
 
@@ -579,6 +696,7 @@ type QuoteRequest struct {
 	PackageSize     models.PackageSize `json:"packageSize"`
 	Type            models.DeliveryType `json:"type"`
 	Currency        models.Currency   `json:"currency"`
+	Package         *models.Package   `json:"package,omitempty"` // checked against restricted items when sent
 }
 
 func (h *Handler) GetQuote(w http.ResponseWriter, r *http.Request) {
@@ -588,6 +706,9 @@ func (h *Handler) GetQuote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.applyAddressCorrection(r.Context(), &req.PickupLocation)
+	h.applyAddressCorrection(r.Context(), &req.DropoffLocation)
+
 	distance := haversineDistance(
 		req.PickupLocation.Latitude, req.PickupLocation.Longitude,
 		req.DropoffLocation.Latitude, req.DropoffLocation.Longitude,
@@ -597,7 +718,18 @@ func (h *Handler) GetQuote(w http.ResponseWriter, r *http.Request) {
 		req.Type = models.DeliveryTypeStandard
 	}
 
-	fare := h.calculateFare(distance, req.PackageSize, req.Type)
+	// Refuse restricted contents before the customer books, not after
+	if req.Package != nil {
+		if violations := checkRestrictedItems(req.Package, req.DropoffLocation, req.Currency); len(violations) > 0 {
+			respondRestrictedItems(w, violations)
+			return
+		}
+		if req.PackageSize == "" {
+			req.PackageSize = req.Package.Size
+		}
+	}
+
+	fare := h.calculateFare(r.Context(), distance, req.PackageSize, req.Type, req.Currency)
 	estimatedMinutes := int(math.Ceil((distance / 20.0) * 60))
 	if estimatedMinutes < 15 {
 		estimatedMinutes = 15
@@ -608,6 +740,8 @@ func (h *Handler) GetQuote(w http.ResponseWriter, r *http.Request) {
 		"estimatedMinutes": estimatedMinutes,
 		"fare":             fare,
 		"currency":         req.Currency,
+		"pickupLocation":   req.PickupLocation,
+		"dropoffLocation":  req.DropoffLocation,
 	})
 }
 
@@ -657,12 +791,65 @@ func (h *Handler) GetZones(w http.ResponseWriter, r *http.Request) {
 	respond(w, http.StatusOK, zones)
 }
 
+// CheckZone reports whether a location is served. Given an origin, such as
+// a merchant, it also checks the location is reachable by road within
+// maxMinutes (default 45) for the courier's costing model.
 func (h *Handler) CheckZone(w http.ResponseWriter, r *http.Request) {
-	// Simplified zone check
-	respond(w, http.StatusOK, map[string]interface{}{
+	result := map[string]interface{}{
 		"supported":       true,
 		"surgeMultiplier": 1.0,
-	})
+	}
+
+	query := r.URL.Query()
+	if query.Get("originLat") == "" || h.cfg.ValhallaURL == "" {
+		respond(w, http.StatusOK, result)
+		return
+	}
+
+	var coords [4]float64
+	for i, param := range []string{"lat", "lng", "originLat", "originLng"} {
+		value, err := strconv.ParseFloat(query.Get(param), 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid "+param)
+			return
+		}
+		coords[i] = value
+	}
+
+	maxMinutes := defaultReachMinutes
+	if v := query.Get("maxMinutes"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > maxReachMinutes {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "maxMinutes must be between 1 and 90")
+			return
+		}
+		maxMinutes = parsed
+	}
+
+	costing := query.Get("costing")
+	if costing == "" {
+		costing = defaultReachCosting
+	}
+	if !reachCostings[costing] {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "costing must be motorcycle, bicycle, auto or pedestrian")
+		return
+	}
+
+	polygon, err := reachablePolygon(r.Context(), h.cfg.ValhallaURL, coords[2], coords[3], maxMinutes, costing)
+	if err != nil {
+		// Don't turn deliveries away because the router is down
+		log.Warn().Err(err).Msg("Failed to compute delivery reach isochrone")
+		result["reachChecked"] = false
+		respond(w, http.StatusOK, result)
+		return
+	}
+
+	reachable := pointInPolygon(polygon, coords[0], coords[1])
+	result["supported"] = reachable
+	result["reachChecked"] = true
+	result["reachable"] = reachable
+	result["maxMinutes"] = maxMinutes
+	respond(w, http.StatusOK, result)
 }
 
 // ============================================
@@ -719,7 +906,7 @@ type FareBreakdown struct {
 	Total        float64 `json:"total"`
 }
 
-func (h *Handler) calculateFare(distanceKm float64, size models.PackageSize, deliveryType models.DeliveryType) FareBreakdown {
+func (h *Handler) calculateFare(ctx context.Context, distanceKm float64, size models.PackageSize, deliveryType models.DeliveryType, currency models.Currency) FareBreakdown {
 	// Size multipliers
 	sizeMultiplier := 1.0
 	switch size {
@@ -738,6 +925,8 @@ func (h *Handler) calculateFare(distanceKm float64, size models.PackageSize, del
 		typeMultiplier = 1.5
 	case models.DeliveryTypeSameDay:
 		typeMultiplier = 1.2
+	case models.DeliveryTypePerishable:
+		typeMultiplier = 1.4
 	}
 
 	baseFare := h.cfg.BaseFare * sizeMultiplier * typeMultiplier
@@ -745,23 +934,37 @@ func (h *Handler) calculateFare(distanceKm float64, size models.PackageSize, del
 	estimatedMinutes := (distanceKm / 20.0) * 60
 	timeFare := estimatedMinutes * h.cfg.PerMinuteRate
 
-	subtotal := baseFare + distanceFare + timeFare
-	serviceFee := subtotal * h.cfg.ServiceFeePercent
+	fare := FareBreakdown{
+		BaseFare:     baseFare,
+		DistanceFare: distanceFare,
+		TimeFare:     timeFare,
+		SurgeFare:    0,
+		InsuranceFee: 0,
+	}
+
+	// Regulatory price controls are the final clamp on every fare
+	controls := h.cfg.PriceControls[string(currency)]
+	h.applyPriceControls(ctx, &fare, controls, distanceKm, string(currency))
 
-	total := subtotal + serviceFee
+	subtotal := fare.BaseFare + fare.DistanceFare + fare.TimeFare + fare.SurgeFare
+	fare.ServiceFee = subtotal * h.cfg.ServiceFeePercent
+
+	total := subtotal + fare.ServiceFee
 	if total < h.cfg.MinimumFare {
 		total = h.cfg.MinimumFare
 	}
-
-	return FareBreakdown{
-		BaseFare:     math.Round(baseFare),
-		DistanceFare: math.Round(distanceFare),
-		TimeFare:     math.Round(timeFare),
-		SurgeFare:    0,
-		ServiceFee:   math.Round(serviceFee),
-		InsuranceFee: 0,
-		Total:        math.Round(total),
+	if controls.MinFare > 0 && total < controls.MinFare {
+		h.recordPriceControlViolation(ctx, string(currency), "MIN_FARE", controls.MinFare, total, controls.MinFare)
+		total = controls.MinFare
 	}
+
+	fare.BaseFare = math.Round(fare.BaseFare)
+	fare.DistanceFare = math.Round(fare.DistanceFare)
+	fare.TimeFare = math.Round(fare.TimeFare)
+	fare.SurgeFare = math.Round(fare.SurgeFare)
+	fare.ServiceFee = math.Round(fare.ServiceFee)
+	fare.Total = math.Round(total)
+	return fare
 }
 
 func haversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
@@ -0,0 +1,105 @@
+/*
+ * Road-Network Reachability (Valhalla Isochrones)
+ */
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Reach check defaults
+const (
+	defaultReachMinutes = 45
+	maxReachMinutes     = 90
+	defaultReachCosting = "motorcycle"
+)
+
+// reachCostings are the Valhalla costing models couriers travel by
+var reachCostings = map[string]bool{
+	"motorcycle": true,
+	"bicycle":    true,
+	"auto":       true,
+	"pedestrian": true,
+}
+
+var isochroneClient = &http.Client{Timeout: 5 * time.Second}
+
+// reachablePolygon returns the [lat, lng] outer ring of the area reachable
+// from a point within minutes by road for a Valhalla costing model
+func reachablePolygon(ctx context.Context, baseURL string, lat, lng float64, minutes int, costing string) ([][2]float64, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"locations": []map[string]float64{{"lat": lat, "lon": lng}},
+		"costing":   costing,
+		"contours":  []map[string]int{{"time": minutes}},
+		"polygons":  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", strings.TrimRight(baseURL, "/")+"/isochrone", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := isochroneClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("valhalla returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Features []struct {
+			Geometry struct {
+				Type        string         `json:"type"`
+				Coordinates [][][2]float64 `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	for _, feature := range result.Features {
+		if feature.Geometry.Type != "Polygon" || len(feature.Geometry.Coordinates) == 0 {
+			continue
+		}
+		// GeoJSON rings are [lng, lat]
+		ring := feature.Geometry.Coordinates[0]
+		polygon := make([][2]float64, len(ring))
+		for i, point := range ring {
+			polygon[i] = [2]float64{point[1], point[0]}
+		}
+		return polygon, nil
+	}
+	return nil, errors.New("valhalla returned no isochrone")
+}
+
+// pointInPolygon uses ray casting over [lat, lng] vertices
+func pointInPolygon(polygon [][2]float64, lat, lng float64) bool {
+	inside := false
+	j := len(polygon) - 1
+	for i := range polygon {
+		latI, lngI := polygon[i][0], polygon[i][1]
+		latJ, lngJ := polygon[j][0], polygon[j][1]
+		if (latI > lat) != (latJ > lat) &&
+			lng < (lngJ-lngI)*(lat-latI)/(latJ-latI)+lngI {
+			inside = !inside
+		}
+		j = i
+	}
+	return inside
+}
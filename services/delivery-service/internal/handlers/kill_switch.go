@@ -0,0 +1,87 @@
+/*
+ * City Kill Switch Handlers
+ */
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// killSwitchesKey is the Redis hash the ride service's admin API stores
+// city kill switches in, one field per city and feature, e.g. "LAG:DELIVERIES"
+const killSwitchesKey = "killswitch:active"
+
+// killSwitch is a feature switched off in a city, as the ride service
+// stores it
+type killSwitch struct {
+	City       string     `json:"city"`
+	CityName   string     `json:"city_name"`
+	Feature    string     `json:"feature"`
+	Reason     string     `json:"reason"`
+	ReenableAt *time.Time `json:"reenable_at,omitempty"`
+}
+
+// deliveriesSwitchedOff gets the switch stopping new deliveries in a city,
+// matched on the pickup address's city name or code, or nil when
+// deliveries are on. A Redis failure is logged and treated as on so an
+// outage does not stop deliveries on its own.
+func (h *Handler) deliveriesSwitchedOff(ctx context.Context, city string) *killSwitch {
+	city = strings.TrimSpace(city)
+	if city == "" {
+		return nil
+	}
+
+	entries, err := h.rdb.Client().HGetAll(ctx, killSwitchesKey).Result()
+	if err != nil {
+		log.Warn().Err(err).Str("city", city).Msg("Failed to check delivery kill switch")
+		return nil
+	}
+
+	now := time.Now()
+	for _, data := range entries {
+		var sw killSwitch
+		if err := json.Unmarshal([]byte(data), &sw); err != nil || sw.Feature != "DELIVERIES" {
+			continue
+		}
+		if sw.ReenableAt != nil && !now.Before(*sw.ReenableAt) {
+			continue
+		}
+		if strings.EqualFold(sw.CityName, city) || strings.EqualFold(sw.City, city) {
+			return &sw
+		}
+	}
+	return nil
+}
+
+// respondFeatureDisabled tells the client deliveries are switched off in
+// its city, why, and when they are due back if ops set a time
+func respondFeatureDisabled(w http.ResponseWriter, sw *killSwitch) {
+	if sw.ReenableAt != nil {
+		if wait := time.Until(*sw.ReenableAt); wait > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(response{
+		Success: false,
+		Error: &errorInfo{
+			Code:    "FEATURE_DISABLED",
+			Message: sw.Reason,
+			Details: map[string]interface{}{
+				"city":        sw.City,
+				"feature":     sw.Feature,
+				"reason":      sw.Reason,
+				"reenable_at": sw.ReenableAt,
+			},
+		},
+	})
+}
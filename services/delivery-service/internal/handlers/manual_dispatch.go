@@ -0,0 +1,141 @@
+/*
+ * Manual Dispatch
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+// ManualDispatchDelivery lets ops hand a confirmed delivery to a courier
+// they choose, for VIP orders or deliveries no courier has accepted. It
+// skips the nearby-courier search but takes the same delivery lock and
+// applies the same courier, pickup window and status checks as a courier
+// accepting it, and records a manual_dispatch event.
+func (h *Handler) ManualDispatchDelivery(w http.ResponseWriter, r *http.Request) {
+	adminID := middleware.GetUserID(r.Context())
+	deliveryID := chi.URLParam(r, "id")
+
+	var req struct {
+		DriverID string `json:"driverId"`
+		Reason   string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return
+	}
+	if _, err := uuid.Parse(req.DriverID); err != nil {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid driver ID")
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "A reason is required")
+		return
+	}
+
+	// Couriers are online while their location is fresh
+	var driverLoc models.DriverLocation
+	if err := h.rdb.GetJSON(r.Context(), "driver:location:"+req.DriverID, &driverLoc); err != nil {
+		respondError(w, http.StatusConflict, "DRIVER_OFFLINE", "The courier is not online")
+		return
+	}
+
+	// Take the lock a courier accepting the delivery would
+	lockKey := "delivery:lock:" + deliveryID
+	acquired, err := h.rdb.SetNX(r.Context(), lockKey, req.DriverID, 30*time.Second)
+	if err != nil || !acquired {
+		respondError(w, http.StatusConflict, "ALREADY_TAKEN", "Delivery is being processed by another driver")
+		return
+	}
+	defer h.rdb.Delete(r.Context(), lockKey)
+
+	var status string
+	var customerID string
+	var deliveryType models.DeliveryType
+	var regulated bool
+	err = h.db.Pool.QueryRow(r.Context(),
+		"SELECT status, customer_id, type, regulated FROM deliveries WHERE id = $1",
+		deliveryID,
+	).Scan(&status, &customerID, &deliveryType, &regulated)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Delivery not found")
+		return
+	}
+	if status != "CONFIRMED" {
+		respondError(w, http.StatusConflict, "INVALID_STATUS", "Only confirmed deliveries awaiting a courier can be dispatched")
+		return
+	}
+
+	load, err := h.getCourierLoad(r.Context(), req.DriverID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to dispatch delivery")
+		return
+	}
+	if code, msg := load.dispatchBlock(deliveryType, regulated); code != "" {
+		respondError(w, http.StatusConflict, code, msg)
+		return
+	}
+	open, err := h.pickupWindowOpen(r.Context(), deliveryID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to dispatch delivery")
+		return
+	}
+	if !open {
+		respondError(w, http.StatusConflict, "PICKUP_NOT_READY", "The merchant is not handing over this order yet")
+		return
+	}
+
+	result, err := h.db.Pool.Exec(r.Context(),
+		`UPDATE deliveries SET
+			driver_id = $1,
+			status = 'DRIVER_ASSIGNED',
+			driver_assigned_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $2 AND status = 'CONFIRMED' AND driver_id IS NULL`,
+		req.DriverID, deliveryID,
+	)
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to dispatch delivery")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to dispatch delivery")
+		return
+	}
+	if result.RowsAffected() == 0 {
+		respondError(w, http.StatusConflict, "ALREADY_TAKEN", "Delivery was assigned or changed meanwhile")
+		return
+	}
+
+	note := fmt.Sprintf("Dispatched to courier %s by %s: %s", req.DriverID, adminID, req.Reason)
+	h.createDeliveryEvent(r.Context(), deliveryID, "manual_dispatch", "DRIVER_ASSIGNED", nil, &note)
+
+	h.rdb.Publish(r.Context(), "delivery:driver_assigned", map[string]interface{}{
+		"deliveryId": deliveryID,
+		"driverId":   req.DriverID,
+		"customerId": customerID,
+		"manual":     true,
+	})
+
+	log.Info().
+		Str("delivery_id", deliveryID).
+		Str("driver_id", req.DriverID).
+		Str("admin_id", adminID).
+		Msg("Delivery dispatched manually")
+
+	respond(w, http.StatusOK, map[string]interface{}{
+		"message":    "Delivery dispatched",
+		"deliveryId": deliveryID,
+		"driverId":   req.DriverID,
+	})
+}
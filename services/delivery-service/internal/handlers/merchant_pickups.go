@@ -0,0 +1,604 @@
+/*
+ * Merchant Pickup Windows
+ */
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+const (
+	// pickupDispatchLead is how long before a pickup couriers are offered
+	// it, about the time it takes them to get there
+	pickupDispatchLead = 20 * time.Minute
+
+	// pickupWindowHorizonDays is how far ahead a pickup is looked for when
+	// the requested time is outside the merchant's windows
+	pickupWindowHorizonDays = 14
+
+	maxPickupWindowsPerDay  = 6
+	maxBlackoutDuration     = 31 * 24 * time.Hour
+	defaultUpcomingHours    = 24
+	maxUpcomingHours        = 7 * 24
+	pickupWindowRollerBatch = 200
+)
+
+// pickupWindowOpenSQL holds for deliveries a courier sent now would reach
+// while the merchant can hand them over. Deliveries from customers without
+// pickup windows always hold.
+var pickupWindowOpenSQL = fmt.Sprintf(`(pickup_window_end IS NULL OR (
+	COALESCE(scheduled_pickup_time, pickup_window_start) <= NOW() + INTERVAL '%[1]d minutes'
+	AND NOW() + INTERVAL '%[1]d minutes' < pickup_window_end
+	AND NOT EXISTS (
+		SELECT 1 FROM merchant_pickup_blackouts b
+		WHERE b.merchant_id = deliveries.customer_id
+		AND NOW() + INTERVAL '%[1]d minutes' >= b.starts_at
+		AND NOW() + INTERVAL '%[1]d minutes' < b.ends_at
+	)))`, int(pickupDispatchLead.Minutes()))
+
+// pickupWindow is a weekly time a merchant has orders ready for pickup, in
+// the local time of the delivery's market
+type pickupWindow struct {
+	ID        string `json:"id"`
+	DayOfWeek int    `json:"dayOfWeek"` // 0 is Sunday
+	OpensAt   string `json:"opensAt"`   // HH:MM
+	ClosesAt  string `json:"closesAt"`  // HH:MM
+
+	opens, closes int // minutes after midnight
+}
+
+// pickupBlackout is a time a merchant cannot hand over orders, such as a
+// holiday or stock-take
+type pickupBlackout struct {
+	ID       string    `json:"id"`
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// pickupSlot is when a delivery will be picked up and the window that
+// falls in
+type pickupSlot struct {
+	At          time.Time
+	WindowStart time.Time
+	WindowEnd   time.Time
+}
+
+// pickupWindowError rejects a pickup time outside the merchant's windows
+type pickupWindowError struct {
+	Next *time.Time // the earliest time the merchant can hand over instead
+}
+
+func (e *pickupWindowError) Error() string {
+	return "pickup time is outside the merchant's pickup windows"
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func marketTimeZone(currency models.Currency) *time.Location {
+	if loc, ok := deliveryTimeZones[currency]; ok {
+		return loc
+	}
+	return time.UTC
+}
+
+func inBlackout(blackouts []pickupBlackout, at time.Time) (pickupBlackout, bool) {
+	for _, b := range blackouts {
+		if !at.Before(b.StartsAt) && at.Before(b.EndsAt) {
+			return b, true
+		}
+	}
+	return pickupBlackout{}, false
+}
+
+// windowsOn returns the merchant's windows on a local day as times,
+// earliest first
+func windowsOn(windows []pickupWindow, day time.Time) [][2]time.Time {
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	var spans [][2]time.Time
+	for _, pw := range windows {
+		if pw.DayOfWeek != int(day.Weekday()) {
+			continue
+		}
+		spans = append(spans, [2]time.Time{
+			midnight.Add(time.Duration(pw.opens) * time.Minute),
+			midnight.Add(time.Duration(pw.closes) * time.Minute),
+		})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0].Before(spans[j][0]) })
+	return spans
+}
+
+// fitPickupWindow finds the window a pickup time falls in, when it falls
+// in one and no blackout
+func fitPickupWindow(windows []pickupWindow, blackouts []pickupBlackout, at time.Time, loc *time.Location) (pickupSlot, bool) {
+	if _, blocked := inBlackout(blackouts, at); blocked {
+		return pickupSlot{}, false
+	}
+	for _, span := range windowsOn(windows, at.In(loc)) {
+		if !at.Before(span[0]) && at.Before(span[1]) {
+			return pickupSlot{At: at, WindowStart: span[0], WindowEnd: span[1]}, true
+		}
+	}
+	return pickupSlot{}, false
+}
+
+// nextPickupSlot finds the earliest time from a given time that falls in a
+// window and no blackout
+func nextPickupSlot(windows []pickupWindow, blackouts []pickupBlackout, from time.Time, loc *time.Location) (pickupSlot, bool) {
+	local := from.In(loc)
+	for offset := 0; offset <= pickupWindowHorizonDays; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 12, 0, 0, 0, loc)
+		for _, span := range windowsOn(windows, day) {
+			at := span[0]
+			if from.After(at) {
+				at = from
+			}
+			// Step past blackouts, which may run into one another
+			for i := 0; i <= len(blackouts); i++ {
+				b, blocked := inBlackout(blackouts, at)
+				if !blocked {
+					break
+				}
+				at = b.EndsAt
+			}
+			if _, blocked := inBlackout(blackouts, at); !blocked && at.Before(span[1]) {
+				return pickupSlot{At: at, WindowStart: span[0], WindowEnd: span[1]}, true
+			}
+		}
+	}
+	return pickupSlot{}, false
+}
+
+// merchantPickupSchedule loads a merchant's pickup windows and the
+// blackouts that have not ended yet
+func (h *Handler) merchantPickupSchedule(ctx context.Context, merchantID string) ([]pickupWindow, []pickupBlackout, error) {
+	rows, err := h.db.Pool.Query(ctx, `
+		SELECT id::text, day_of_week, opens_minute, closes_minute
+		FROM merchant_pickup_windows
+		WHERE merchant_id = $1
+		ORDER BY day_of_week, opens_minute`,
+		merchantID,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	windows := []pickupWindow{}
+	for rows.Next() {
+		var pw pickupWindow
+		if err := rows.Scan(&pw.ID, &pw.DayOfWeek, &pw.opens, &pw.closes); err != nil {
+			return nil, nil, err
+		}
+		pw.OpensAt, pw.ClosesAt = formatClock(pw.opens), formatClock(pw.closes)
+		windows = append(windows, pw)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err = h.db.Pool.Query(ctx, `
+		SELECT id::text, starts_at, ends_at, COALESCE(reason, '')
+		FROM merchant_pickup_blackouts
+		WHERE merchant_id = $1 AND ends_at > NOW()
+		ORDER BY starts_at`,
+		merchantID,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	blackouts := []pickupBlackout{}
+	for rows.Next() {
+		var b pickupBlackout
+		if err := rows.Scan(&b.ID, &b.StartsAt, &b.EndsAt, &b.Reason); err != nil {
+			return nil, nil, err
+		}
+		blackouts = append(blackouts, b)
+	}
+	return windows, blackouts, rows.Err()
+}
+
+// applyPickupWindows fits a new delivery's pickup to the windows of the
+// merchant creating it. A delivery for as soon as possible is scheduled
+// for the next window when the merchant is not open now; a scheduled
+// pickup outside the windows is rejected with a *pickupWindowError. It
+// returns nil for customers without pickup windows.
+func (h *Handler) applyPickupWindows(ctx context.Context, merchantID string, req *CreateDeliveryRequest) (*pickupSlot, error) {
+	windows, blackouts, err := h.merchantPickupSchedule(ctx, merchantID)
+	if err != nil || len(windows) == 0 {
+		return nil, err
+	}
+	loc := marketTimeZone(req.Currency)
+	now := time.Now()
+
+	if req.ScheduledPickupTime == nil {
+		if slot, ok := fitPickupWindow(windows, blackouts, now, loc); ok {
+			return &slot, nil
+		}
+		slot, ok := nextPickupSlot(windows, blackouts, now, loc)
+		if !ok {
+			return nil, &pickupWindowError{}
+		}
+		req.ScheduledPickupTime = &slot.At
+		return &slot, nil
+	}
+
+	requested := *req.ScheduledPickupTime
+	if slot, ok := fitPickupWindow(windows, blackouts, requested, loc); ok && requested.After(now) {
+		return &slot, nil
+	}
+	if requested.Before(now) {
+		requested = now
+	}
+	windowErr := &pickupWindowError{}
+	if slot, ok := nextPickupSlot(windows, blackouts, requested, loc); ok {
+		windowErr.Next = &slot.At
+	}
+	return nil, windowErr
+}
+
+func respondPickupWindow(w http.ResponseWriter, err *pickupWindowError) {
+	message := "The merchant has no pickup windows open in the next two weeks"
+	if err.Next != nil {
+		message = "Pickup time is outside the merchant's pickup windows"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(response{
+		Success: false,
+		Error: &errorInfo{
+			Code:    "OUTSIDE_PICKUP_WINDOW",
+			Message: message,
+			Details: map[string]interface{}{
+				"nextPickupTime": err.Next,
+			},
+		},
+	})
+}
+
+// GetPickupWindows returns the merchant's weekly pickup windows and
+// upcoming blackouts
+func (h *Handler) GetPickupWindows(w http.ResponseWriter, r *http.Request) {
+	merchantID := middleware.GetUserID(r.Context())
+
+	windows, blackouts, err := h.merchantPickupSchedule(r.Context(), merchantID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch pickup windows")
+		return
+	}
+	respond(w, http.StatusOK, map[string]interface{}{
+		"windows":   windows,
+		"blackouts": blackouts,
+	})
+}
+
+// ReplacePickupWindows sets the merchant's weekly pickup windows. An empty
+// list takes pickups at any time again. Deliveries already scheduled keep
+// their windows.
+func (h *Handler) ReplacePickupWindows(w http.ResponseWriter, r *http.Request) {
+	merchantID := middleware.GetUserID(r.Context())
+
+	var req struct {
+		Windows []pickupWindow `json:"windows"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return
+	}
+
+	perDay := map[int][]pickupWindow{}
+	for i := range req.Windows {
+		pw := &req.Windows[i]
+		if pw.DayOfWeek < 0 || pw.DayOfWeek > 6 {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "dayOfWeek must be 0 (Sunday) to 6 (Saturday)")
+			return
+		}
+		var err error
+		if pw.opens, err = parseClock(pw.OpensAt); err == nil {
+			pw.closes, err = parseClock(pw.ClosesAt)
+		}
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+		if pw.opens >= pw.closes {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Windows must close after they open, on the same day")
+			return
+		}
+		perDay[pw.DayOfWeek] = append(perDay[pw.DayOfWeek], *pw)
+	}
+	for _, day := range perDay {
+		if len(day) > maxPickupWindowsPerDay {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "At most "+strconv.Itoa(maxPickupWindowsPerDay)+" windows a day")
+			return
+		}
+		sort.Slice(day, func(i, j int) bool { return day[i].opens < day[j].opens })
+		for i := 1; i < len(day); i++ {
+			if day[i].opens < day[i-1].closes {
+				respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Windows on the same day must not overlap")
+				return
+			}
+		}
+	}
+
+	tx, err := h.db.Pool.Begin(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to update pickup windows")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	if _, err := tx.Exec(r.Context(), "DELETE FROM merchant_pickup_windows WHERE merchant_id = $1", merchantID); err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to update pickup windows")
+		return
+	}
+	for _, pw := range req.Windows {
+		_, err := tx.Exec(r.Context(), `
+			INSERT INTO merchant_pickup_windows (id, merchant_id, day_of_week, opens_minute, closes_minute, created_at)
+			VALUES ($1, $2, $3, $4, $5, NOW())`,
+			uuid.New(), merchantID, pw.DayOfWeek, pw.opens, pw.closes,
+		)
+		if err != nil {
+			log.Error().Err(err).Str("merchant_id", merchantID).Msg("Failed to store pickup window")
+			respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to update pickup windows")
+			return
+		}
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to update pickup windows")
+		return
+	}
+
+	h.GetPickupWindows(w, r)
+}
+
+// CreatePickupBlackout blocks pickups from the merchant for a time
+func (h *Handler) CreatePickupBlackout(w http.ResponseWriter, r *http.Request) {
+	merchantID := middleware.GetUserID(r.Context())
+
+	var req pickupBlackout
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return
+	}
+	if !req.EndsAt.After(req.StartsAt) || !req.EndsAt.After(time.Now()) {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Blackouts must end after they start, in the future")
+		return
+	}
+	if req.EndsAt.Sub(req.StartsAt) > maxBlackoutDuration {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Blackouts can last at most 31 days")
+		return
+	}
+
+	req.ID = uuid.New().String()
+	req.Reason = strings.TrimSpace(req.Reason)
+	_, err := h.db.Pool.Exec(r.Context(), `
+		INSERT INTO merchant_pickup_blackouts (id, merchant_id, starts_at, ends_at, reason, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NOW())`,
+		req.ID, merchantID, req.StartsAt, req.EndsAt, req.Reason,
+	)
+	if err != nil {
+		log.Error().Err(err).Str("merchant_id", merchantID).Msg("Failed to create pickup blackout")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to create blackout")
+		return
+	}
+	respond(w, http.StatusCreated, req)
+}
+
+// DeletePickupBlackout lifts one of the merchant's blackouts
+func (h *Handler) DeletePickupBlackout(w http.ResponseWriter, r *http.Request) {
+	merchantID := middleware.GetUserID(r.Context())
+	blackoutID := chi.URLParam(r, "blackoutId")
+	if _, err := uuid.Parse(blackoutID); err != nil {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Blackout not found")
+		return
+	}
+
+	result, err := h.db.Pool.Exec(r.Context(),
+		"DELETE FROM merchant_pickup_blackouts WHERE id = $1 AND merchant_id = $2",
+		blackoutID, merchantID,
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to delete blackout")
+		return
+	}
+	if result.RowsAffected() == 0 {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Blackout not found")
+		return
+	}
+	respond(w, http.StatusOK, map[string]string{"message": "Blackout removed"})
+}
+
+type stagedPickup struct {
+	DeliveryID          string             `json:"deliveryId"`
+	TrackingNumber      string             `json:"trackingNumber"`
+	Status              string             `json:"status"`
+	ScheduledPickupTime *time.Time         `json:"scheduledPickupTime,omitempty"`
+	DropoffContact      models.ContactInfo `json:"dropoffContact"`
+	Package             models.Package     `json:"package"`
+	CourierAssigned     bool               `json:"courierAssigned"`
+}
+
+type pickupWindowGroup struct {
+	WindowStart time.Time      `json:"windowStart"`
+	WindowEnd   time.Time      `json:"windowEnd"`
+	Count       int            `json:"count"`
+	Pickups     []stagedPickup `json:"pickups"`
+}
+
+// GetUpcomingPickups lists the merchant's pickups over the next hours
+// (default 24), grouped by pickup window, so orders can be staged for
+// each window
+func (h *Handler) GetUpcomingPickups(w http.ResponseWriter, r *http.Request) {
+	merchantID := middleware.GetUserID(r.Context())
+
+	hours := defaultUpcomingHours
+	if v := r.URL.Query().Get("hours"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > maxUpcomingHours {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "hours must be between 1 and 168")
+			return
+		}
+		hours = parsed
+	}
+
+	rows, err := h.db.Pool.Query(r.Context(), `
+		SELECT pickup_window_start, pickup_window_end,
+			id, tracking_number, status, scheduled_pickup_time, dropoff_contact, package, driver_id IS NOT NULL
+		FROM deliveries
+		WHERE customer_id = $1
+		AND status IN ('PENDING', 'CONFIRMED', 'DRIVER_ASSIGNED')
+		AND pickup_window_end > NOW()
+		AND pickup_window_start < NOW() + make_interval(hours => $2)
+		ORDER BY pickup_window_start, COALESCE(scheduled_pickup_time, pickup_window_start), created_at`,
+		merchantID, hours,
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch upcoming pickups")
+		return
+	}
+	defer rows.Close()
+
+	groups := []*pickupWindowGroup{}
+	for rows.Next() {
+		var start, end time.Time
+		var p stagedPickup
+		var dropoffContact []byte
+		if err := rows.Scan(
+			&start, &end,
+			&p.DeliveryID, &p.TrackingNumber, &p.Status, &p.ScheduledPickupTime, &dropoffContact, &p.Package, &p.CourierAssigned,
+		); err != nil {
+			respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch upcoming pickups")
+			return
+		}
+		if p.DropoffContact, err = h.openContact(r.Context(), dropoffContact); err != nil {
+			log.Error().Err(err).Str("delivery_id", p.DeliveryID).Msg("Failed to open dropoff contact")
+			respondError(w, http.StatusInternalServerError, "ENCRYPTION_ERROR", "Failed to fetch upcoming pickups")
+			return
+		}
+		if n := len(groups); n == 0 || !groups[n-1].WindowStart.Equal(start) || !groups[n-1].WindowEnd.Equal(end) {
+			groups = append(groups, &pickupWindowGroup{WindowStart: start, WindowEnd: end})
+		}
+		g := groups[len(groups)-1]
+		g.Pickups = append(g.Pickups, p)
+		g.Count++
+	}
+	if err := rows.Err(); err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch upcoming pickups")
+		return
+	}
+
+	respond(w, http.StatusOK, groups)
+}
+
+func (h *Handler) rollMissedPickupWindows(ctx context.Context) error {
+	rows, err := h.db.Pool.Query(ctx, `
+		SELECT id, status, customer_id, currency
+		FROM deliveries
+		WHERE status IN ('PENDING', 'CONFIRMED') AND driver_id IS NULL
+		AND pickup_window_end <= NOW() + make_interval(mins => $1)
+		ORDER BY pickup_window_end
+		LIMIT $2`,
+		int(pickupDispatchLead.Minutes()), pickupWindowRollerBatch,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type missed struct {
+		deliveryID, status, merchantID string
+		currency                       models.Currency
+	}
+	var due []missed
+	for rows.Next() {
+		var m missed
+		if err := rows.Scan(&m.deliveryID, &m.status, &m.merchantID, &m.currency); err != nil {
+			return err
+		}
+		due = append(due, m)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, m := range due {
+		windows, blackouts, err := h.merchantPickupSchedule(ctx, m.merchantID)
+		if err != nil {
+			return err
+		}
+		slot, ok := nextPickupSlot(windows, blackouts, time.Now().Add(pickupDispatchLead), marketTimeZone(m.currency))
+		if !ok {
+			// The merchant has dropped its windows; dispatch as usual
+			if _, err := h.db.Pool.Exec(ctx, `
+				UPDATE deliveries SET pickup_window_start = NULL, pickup_window_end = NULL, updated_at = NOW()
+				WHERE id = $1`,
+				m.deliveryID,
+			); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := h.db.Pool.Exec(ctx, `
+			UPDATE deliveries SET
+				scheduled_pickup_time = $2,
+				pickup_window_start = $3,
+				pickup_window_end = $4,
+				updated_at = NOW()
+			WHERE id = $1 AND driver_id IS NULL`,
+			m.deliveryID, slot.At, slot.WindowStart, slot.WindowEnd,
+		); err != nil {
+			return err
+		}
+
+		note := "Pickup window missed, moved to " + slot.At.Format(time.RFC3339)
+		h.createDeliveryEvent(ctx, m.deliveryID, "pickup_rescheduled", m.status, nil, &note)
+		h.rdb.Publish(ctx, "delivery:pickup_rescheduled", map[string]interface{}{
+			"deliveryId":          m.deliveryID,
+			"merchantId":          m.merchantID,
+			"scheduledPickupTime": slot.At,
+			"windowStart":         slot.WindowStart,
+			"windowEnd":           slot.WindowEnd,
+		})
+	}
+	return nil
+}
+
+// pickupWindowOpen reports whether a courier sent now would reach a
+// delivery's pickup while the merchant can hand it over
+func (h *Handler) pickupWindowOpen(ctx context.Context, deliveryID string) (bool, error) {
+	var open bool
+	err := h.db.Pool.QueryRow(ctx,
+		"SELECT "+pickupWindowOpenSQL+" FROM deliveries WHERE id = $1", deliveryID,
+	).Scan(&open)
+	return open, err
+}
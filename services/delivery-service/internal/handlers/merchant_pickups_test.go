@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+var lagosTime = marketTimeZone(models.CurrencyNGN)
+
+// onMonday is a time on Monday 19 October 2026 in Lagos
+func onMonday(hour, minute int) time.Time {
+	return time.Date(2026, 10, 19, hour, minute, 0, 0, lagosTime)
+}
+
+// testPickupWindows opens Mondays 09:00-12:00 and 14:00-17:00 and Tuesdays
+// 09:00-12:00
+func testPickupWindows() []pickupWindow {
+	return []pickupWindow{
+		{DayOfWeek: 1, opens: 14 * 60, closes: 17 * 60},
+		{DayOfWeek: 1, opens: 9 * 60, closes: 12 * 60},
+		{DayOfWeek: 2, opens: 9 * 60, closes: 12 * 60},
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	for value, want := range map[string]int{"00:00": 0, "09:30": 570, " 23:59 ": 1439} {
+		got, err := parseClock(value)
+		if err != nil || got != want {
+			t.Errorf("parseClock(%q) = %d, %v, want %d", value, got, err, want)
+		}
+		if formatClock(want) != strings.TrimSpace(value) {
+			t.Errorf("formatClock(%d) = %s, want %s", want, formatClock(want), strings.TrimSpace(value))
+		}
+	}
+	for _, value := range []string{"9am", "24:00", "12:60", ""} {
+		if _, err := parseClock(value); err == nil {
+			t.Errorf("parseClock(%q) error = nil, want an error", value)
+		}
+	}
+}
+
+func TestFitPickupWindow(t *testing.T) {
+	stockTake := []pickupBlackout{{StartsAt: onMonday(9, 30), EndsAt: onMonday(11, 0)}}
+
+	tests := []struct {
+		name      string
+		at        time.Time
+		blackouts []pickupBlackout
+		wantStart time.Time
+		wantOK    bool
+	}{
+		{name: "inside the morning window", at: onMonday(10, 0), wantStart: onMonday(9, 0), wantOK: true},
+		{name: "at opening", at: onMonday(14, 0), wantStart: onMonday(14, 0), wantOK: true},
+		{name: "given in UTC", at: onMonday(9, 30).UTC(), wantStart: onMonday(9, 0), wantOK: true},
+		{name: "at closing", at: onMonday(12, 0)},
+		{name: "between windows", at: onMonday(13, 0)},
+		{name: "on a closed day", at: onMonday(10, 0).AddDate(0, 0, -1)},
+		{name: "in a blackout", at: onMonday(10, 0), blackouts: stockTake},
+		{name: "after a blackout", at: onMonday(11, 0), blackouts: stockTake, wantStart: onMonday(9, 0), wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slot, ok := fitPickupWindow(testPickupWindows(), tt.blackouts, tt.at, lagosTime)
+			if ok != tt.wantOK {
+				t.Fatalf("fitPickupWindow() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && (!slot.At.Equal(tt.at) || !slot.WindowStart.Equal(tt.wantStart)) {
+				t.Errorf("slot = %+v, want at %s in the window from %s", slot, tt.at, tt.wantStart)
+			}
+		})
+	}
+}
+
+func TestNextPickupSlot(t *testing.T) {
+	tests := []struct {
+		name      string
+		windows   []pickupWindow
+		blackouts []pickupBlackout
+		from      time.Time
+		want      time.Time
+		wantEnd   time.Time
+		wantOK    bool
+	}{
+		{name: "already open", from: onMonday(10, 0), want: onMonday(10, 0), wantEnd: onMonday(12, 0), wantOK: true},
+		{name: "before opening", from: onMonday(7, 0), want: onMonday(9, 0), wantEnd: onMonday(12, 0), wantOK: true},
+		{name: "over lunch", from: onMonday(12, 30), want: onMonday(14, 0), wantEnd: onMonday(17, 0), wantOK: true},
+		{name: "after closing", from: onMonday(18, 0), want: onMonday(9, 0).AddDate(0, 0, 1), wantEnd: onMonday(12, 0).AddDate(0, 0, 1), wantOK: true},
+		{
+			name:      "past a blackout inside the window",
+			blackouts: []pickupBlackout{{StartsAt: onMonday(10, 0), EndsAt: onMonday(11, 0)}},
+			from:      onMonday(10, 30),
+			want:      onMonday(11, 0), wantEnd: onMonday(12, 0), wantOK: true,
+		},
+		{
+			name: "past back-to-back blackouts that outlast the window",
+			blackouts: []pickupBlackout{
+				{StartsAt: onMonday(10, 0), EndsAt: onMonday(12, 30)},
+				{StartsAt: onMonday(9, 0), EndsAt: onMonday(10, 0)},
+			},
+			from: onMonday(8, 0),
+			want: onMonday(14, 0), wantEnd: onMonday(17, 0), wantOK: true,
+		},
+		{
+			name:    "sunday windows a week on",
+			windows: []pickupWindow{{DayOfWeek: 0, opens: 8 * 60, closes: 9 * 60}},
+			from:    onMonday(10, 0),
+			want:    time.Date(2026, 10, 25, 8, 0, 0, 0, lagosTime), wantEnd: time.Date(2026, 10, 25, 9, 0, 0, 0, lagosTime), wantOK: true,
+		},
+		{name: "no windows", windows: []pickupWindow{}, from: onMonday(10, 0)},
+		{
+			name:      "blacked out past the horizon",
+			blackouts: []pickupBlackout{{StartsAt: onMonday(0, 0), EndsAt: onMonday(0, 0).AddDate(0, 0, pickupWindowHorizonDays+2)}},
+			from:      onMonday(8, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			windows := tt.windows
+			if windows == nil {
+				windows = testPickupWindows()
+			}
+			slot, ok := nextPickupSlot(windows, tt.blackouts, tt.from, lagosTime)
+			if ok != tt.wantOK {
+				t.Fatalf("nextPickupSlot() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && (!slot.At.Equal(tt.want) || !slot.WindowEnd.Equal(tt.wantEnd)) {
+				t.Errorf("slot = at %s until %s, want at %s until %s", slot.At, slot.WindowEnd, tt.want, tt.wantEnd)
+			}
+		})
+	}
+}
+
+// The validation tests below are refused before the database is reached
+func TestReplacePickupWindowsValidation(t *testing.T) {
+	window := func(day int, opens, closes string) string {
+		return fmt.Sprintf(`{"dayOfWeek":%d,"opensAt":%q,"closesAt":%q}`, day, opens, closes)
+	}
+	sevenMornings := make([]string, 7)
+	for i := range sevenMornings {
+		sevenMornings[i] = window(1, formatClock(i*60), formatClock(i*60+30))
+	}
+
+	tests := map[string]string{
+		"bad JSON":           `{`,
+		"day out of range":   `{"windows":[` + window(7, "09:00", "12:00") + `]}`,
+		"not a clock time":   `{"windows":[` + window(1, "9am", "12:00") + `]}`,
+		"closes before open": `{"windows":[` + window(1, "12:00", "09:00") + `]}`,
+		"overlapping":        `{"windows":[` + window(1, "09:00", "12:00") + `,` + window(1, "11:00", "13:00") + `]}`,
+		"too many in a day":  `{"windows":[` + strings.Join(sevenMornings, ",") + `]}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			(&Handler{}).ReplacePickupWindows(rec, httptest.NewRequest(http.MethodPut, "/merchant/pickup-windows", strings.NewReader(body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+		})
+	}
+}
+
+func TestCreatePickupBlackoutValidation(t *testing.T) {
+	now := time.Now()
+	blackout := func(start, end time.Time) string {
+		b, _ := json.Marshal(pickupBlackout{StartsAt: start, EndsAt: end})
+		return string(b)
+	}
+
+	tests := map[string]string{
+		"ends before it starts": blackout(now.Add(2*time.Hour), now.Add(time.Hour)),
+		"already over":          blackout(now.Add(-2*time.Hour), now.Add(-time.Hour)),
+		"longer than a month":   blackout(now, now.Add(maxBlackoutDuration+time.Hour)),
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			(&Handler{}).CreatePickupBlackout(rec, httptest.NewRequest(http.MethodPost, "/merchant/pickup-blackouts", strings.NewReader(body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+		})
+	}
+}
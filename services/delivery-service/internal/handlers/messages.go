@@ -0,0 +1,237 @@
+/*
+ * Delivery Messages
+ */
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+)
+
+// quickReplies are the messages couriers can send, by code. Couriers pick
+// from these rather than typing, so they can reply while riding.
+var quickReplies = map[string]string{
+	"ON_MY_WAY":        "I'm on my way",
+	"ARRIVED_PICKUP":   "I've arrived at the pickup",
+	"ARRIVED_DROPOFF":  "I've arrived",
+	"WAITING_OUTSIDE":  "I'm waiting outside",
+	"CANT_FIND_GATE":   "I can't find the gate",
+	"CANT_FIND_PLACE":  "I can't find the address, please share directions",
+	"RUNNING_LATE":     "Running a few minutes late",
+	"STUCK_IN_TRAFFIC": "Stuck in traffic, I'll be there as soon as I can",
+	"PLEASE_CALL":      "Please call me",
+}
+
+// maxCustomerMessageLength caps a customer's reply, in characters
+const maxCustomerMessageLength = 500
+
+// Statuses a delivery can be messaged in
+var messageableStatuses = map[string]bool{
+	"DRIVER_ASSIGNED": true,
+	"PICKED_UP":       true,
+	"IN_TRANSIT":      true,
+}
+
+type deliveryMessage struct {
+	ID         string    `json:"id"`
+	DeliveryID string    `json:"deliveryId"`
+	SenderID   string    `json:"senderId"`
+	SenderRole string    `json:"senderRole"` // COURIER or CUSTOMER
+	QuickReply *string   `json:"quickReply,omitempty"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// GetQuickReplies lists the quick replies couriers can send
+func (h *Handler) GetQuickReplies(w http.ResponseWriter, r *http.Request) {
+	replies := make([]map[string]string, 0, len(quickReplies))
+	for code, body := range quickReplies {
+		replies = append(replies, map[string]string{"code": code, "body": body})
+	}
+	sort.Slice(replies, func(i, j int) bool { return replies[i]["code"] < replies[j]["code"] })
+	respond(w, http.StatusOK, replies)
+}
+
+// SendCourierMessage sends one of the quick replies to the customer
+func (h *Handler) SendCourierMessage(w http.ResponseWriter, r *http.Request) {
+	driverID := middleware.GetUserID(r.Context())
+	deliveryID := chi.URLParam(r, "id")
+
+	var req struct {
+		QuickReply string `json:"quickReply"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return
+	}
+	code := strings.ToUpper(strings.TrimSpace(req.QuickReply))
+	body, ok := quickReplies[code]
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_QUICK_REPLY", "Unknown quick reply")
+		return
+	}
+
+	var status, customerID string
+	err := h.db.Pool.QueryRow(r.Context(),
+		"SELECT status, customer_id FROM deliveries WHERE id = $1 AND driver_id = $2",
+		deliveryID, driverID,
+	).Scan(&status, &customerID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Delivery not found")
+		return
+	}
+	if !messageableStatuses[status] {
+		respondError(w, http.StatusBadRequest, "INVALID_STATUS", "Delivery is not active")
+		return
+	}
+
+	msg, err := h.storeDeliveryMessage(r.Context(), deliveryID, driverID, "COURIER", &code, body)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to send message")
+		return
+	}
+	h.publishDeliveryMessage(r.Context(), msg, customerID)
+
+	respond(w, http.StatusCreated, msg)
+}
+
+// SendCustomerMessage sends the courier a customer's reply, usually
+// delivery instructions
+func (h *Handler) SendCustomerMessage(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	deliveryID := chi.URLParam(r, "id")
+
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+		return
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" || utf8.RuneCountInString(body) > maxCustomerMessageLength {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Message must be 1 to 500 characters")
+		return
+	}
+
+	var status string
+	var driverID *string
+	err := h.db.Pool.QueryRow(r.Context(),
+		"SELECT status, driver_id FROM deliveries WHERE id = $1 AND customer_id = $2",
+		deliveryID, userID,
+	).Scan(&status, &driverID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Delivery not found")
+		return
+	}
+	if !messageableStatuses[status] || driverID == nil {
+		respondError(w, http.StatusBadRequest, "INVALID_STATUS", "Delivery has no courier to message")
+		return
+	}
+
+	msg, err := h.storeDeliveryMessage(r.Context(), deliveryID, userID, "CUSTOMER", nil, body)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to send message")
+		return
+	}
+	h.publishDeliveryMessage(r.Context(), msg, *driverID)
+
+	respond(w, http.StatusCreated, msg)
+}
+
+// GetDeliveryMessages lists a delivery's messages to its customer or
+// courier, oldest first
+func (h *Handler) GetDeliveryMessages(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	deliveryID := chi.URLParam(r, "id")
+
+	var exists bool
+	err := h.db.Pool.QueryRow(r.Context(),
+		"SELECT EXISTS (SELECT 1 FROM deliveries WHERE id = $1 AND (customer_id = $2 OR driver_id = $2))",
+		deliveryID, userID,
+	).Scan(&exists)
+	if err != nil || !exists {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Delivery not found")
+		return
+	}
+
+	messages, err := h.listDeliveryMessages(r.Context(), deliveryID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch messages")
+		return
+	}
+	respond(w, http.StatusOK, messages)
+}
+
+func (h *Handler) storeDeliveryMessage(ctx context.Context, deliveryID, senderID, senderRole string, quickReply *string, body string) (*deliveryMessage, error) {
+	msg := &deliveryMessage{
+		ID:         "msg_" + uuid.New().String()[:12],
+		DeliveryID: deliveryID,
+		SenderID:   senderID,
+		SenderRole: senderRole,
+		QuickReply: quickReply,
+		Body:       body,
+	}
+	err := h.db.Pool.QueryRow(ctx, `
+		INSERT INTO delivery_messages (id, delivery_id, sender_id, sender_role, quick_reply, body, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING created_at`,
+		msg.ID, deliveryID, senderID, senderRole, quickReply, body,
+	).Scan(&msg.CreatedAt)
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to store delivery message")
+		return nil, err
+	}
+	return msg, nil
+}
+
+// publishDeliveryMessage pushes a message to the notification channel
+// for the other party
+func (h *Handler) publishDeliveryMessage(ctx context.Context, msg *deliveryMessage, recipientID string) {
+	h.rdb.Publish(ctx, "delivery:message", map[string]interface{}{
+		"deliveryId":  msg.DeliveryID,
+		"messageId":   msg.ID,
+		"senderId":    msg.SenderID,
+		"senderRole":  msg.SenderRole,
+		"recipientId": recipientID,
+		"quickReply":  msg.QuickReply,
+		"body":        msg.Body,
+		"createdAt":   msg.CreatedAt,
+	})
+}
+
+func (h *Handler) listDeliveryMessages(ctx context.Context, deliveryID string) ([]deliveryMessage, error) {
+	rows, err := h.db.Pool.Query(ctx, `
+		SELECT id, delivery_id, sender_id, sender_role, quick_reply, body, created_at
+		FROM delivery_messages
+		WHERE delivery_id = $1
+		ORDER BY created_at ASC`,
+		deliveryID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []deliveryMessage{}
+	for rows.Next() {
+		var m deliveryMessage
+		if err := rows.Scan(&m.ID, &m.DeliveryID, &m.SenderID, &m.SenderRole, &m.QuickReply, &m.Body, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetQuickReplies(t *testing.T) {
+	rec := httptest.NewRecorder()
+	(&Handler{}).GetQuickReplies(rec, httptest.NewRequest(http.MethodGet, "/quick-replies", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var resp struct {
+		Data []map[string]string `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != len(quickReplies) {
+		t.Fatalf("replies = %d, want %d", len(resp.Data), len(quickReplies))
+	}
+	for i, reply := range resp.Data {
+		if quickReplies[reply["code"]] != reply["body"] {
+			t.Errorf("reply %s = %q, want %q", reply["code"], reply["body"], quickReplies[reply["code"]])
+		}
+		if i > 0 && resp.Data[i-1]["code"] >= reply["code"] {
+			t.Errorf("replies not sorted: %s before %s", resp.Data[i-1]["code"], reply["code"])
+		}
+	}
+}
+
+// The tests below stop at request validation, before the delivery is
+// looked up, so they need no database
+func TestSendMessageValidation(t *testing.T) {
+	h := &Handler{}
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		body     string
+		wantCode string
+	}{
+		{"courier sends bad JSON", h.SendCourierMessage, `{`, "INVALID_JSON"},
+		{"courier types their own text", h.SendCourierMessage, `{"quickReply":"see you soon"}`, "INVALID_QUICK_REPLY"},
+		{"courier sends no reply", h.SendCourierMessage, `{}`, "INVALID_QUICK_REPLY"},
+		{"customer sends bad JSON", h.SendCustomerMessage, `{`, "INVALID_JSON"},
+		{"customer sends a blank message", h.SendCustomerMessage, `{"body":"   "}`, "VALIDATION_ERROR"},
+		{"customer message too long", h.SendCustomerMessage, `{"body":"` + strings.Repeat("é", maxCustomerMessageLength+1) + `"}`, "VALIDATION_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler(rec, httptest.NewRequest(http.MethodPost, "/deliveries/d-1/messages", strings.NewReader(tt.body)))
+
+			var resp response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != http.StatusBadRequest || resp.Error == nil || resp.Error.Code != tt.wantCode {
+				t.Errorf("status = %d, error = %+v, want 400 %s", rec.Code, resp.Error, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+/*
+ * Delivery Metrics Rollup Handlers
+ */
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+)
+
+// Metrics rollup settings
+const (
+	metricsReroll   = 3 * time.Hour      // trailing hours re-rolled each run to pick up late deliveries
+	metricsBackfill = 7 * 24 * time.Hour // history rolled up on the first run
+)
+
+// deliveryHourlyMetrics is the delivery summary for one city, currency and
+// hour. Deliveries count as created in the hour they were requested and as
+// delivered or cancelled in the hour that happened.
+type deliveryHourlyMetrics struct {
+	Hour               time.Time `json:"hour"`
+	City               string    `json:"city"`
+	Currency           string    `json:"currency"`
+	Created            int64     `json:"created"`
+	Delivered          int64     `json:"delivered"`
+	Cancelled          int64     `json:"cancelled"`
+	Revenue            float64   `json:"revenue"`
+	FareCount          int64     `json:"fareCount"`
+	DurationSumSeconds int64     `json:"durationSumSeconds"` // picked up to delivered
+	DurationCount      int64     `json:"durationCount"`
+}
+
+// deliveryMetricsSummary is a city's delivery summary over a period
+type deliveryMetricsSummary struct {
+	City               string  `json:"city"`
+	Currency           string  `json:"currency"`
+	Created            int64   `json:"created"`
+	Delivered          int64   `json:"delivered"`
+	Cancelled          int64   `json:"cancelled"`
+	Revenue            float64 `json:"revenue"`
+	AvgFare            float64 `json:"avgFare"`
+	AvgDurationSeconds float64 `json:"avgDurationSeconds"`
+	CompletionRate     float64 `json:"completionRate"` // delivered per 100 created
+}
+
+// rollUpDeliveryMetrics rolls up every hour from the re-roll window (or the
+// last rolled-up hour, if the job has fallen further behind) through the
+// current hour
+func (h *Handler) rollUpDeliveryMetrics(ctx context.Context) error {
+	current := time.Now().UTC().Truncate(time.Hour)
+
+	from := current.Add(-metricsReroll)
+	var watermark *time.Time
+	if err := h.db.Pool.QueryRow(ctx, `SELECT MAX(hour) FROM delivery_hourly_metrics`).Scan(&watermark); err != nil {
+		return err
+	}
+	switch {
+	case watermark == nil:
+		from = current.Add(-metricsBackfill)
+	case watermark.Before(from):
+		from = watermark.UTC()
+	}
+
+	// One hour per statement keeps each scan on the deliveries table small
+	for hour := from; !hour.After(current); hour = hour.Add(time.Hour) {
+		if err := h.rollUpDeliveryHour(ctx, hour); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollUpDeliveryHour recomputes one hour's per-city summaries
+func (h *Handler) rollUpDeliveryHour(ctx context.Context, hour time.Time) error {
+	end := hour.Add(time.Hour)
+
+	tx, err := h.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM delivery_hourly_metrics WHERE hour >= $1 AND hour < $2`,
+		hour, end,
+	); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO delivery_hourly_metrics (
+			hour, city, currency, created, delivered, cancelled,
+			revenue, fare_count, duration_sum_seconds, duration_count
+		)
+		SELECT
+			$1,
+			COALESCE(NULLIF(d.pickup_location->>'city', ''), 'UNKNOWN') AS city,
+			d.currency,
+			COUNT(*) FILTER (WHERE e.kind = 'created'),
+			COUNT(*) FILTER (WHERE e.kind = 'delivered'),
+			COUNT(*) FILTER (WHERE e.kind = 'cancelled'),
+			COALESCE(SUM(d.total_fare) FILTER (WHERE e.kind = 'delivered'), 0),
+			COUNT(*) FILTER (WHERE e.kind = 'delivered'),
+			COALESCE(SUM(extract(epoch FROM d.delivered_at - d.picked_up_at)) FILTER (WHERE e.kind = 'delivered' AND d.picked_up_at IS NOT NULL), 0)::BIGINT,
+			COUNT(*) FILTER (WHERE e.kind = 'delivered' AND d.picked_up_at IS NOT NULL)
+		FROM deliveries d
+		CROSS JOIN LATERAL (VALUES
+			('created', d.created_at),
+			('delivered', CASE WHEN d.status = 'DELIVERED' THEN d.delivered_at END),
+			('cancelled', CASE WHEN d.status = 'CANCELLED' THEN d.cancelled_at END)
+		) AS e(kind, at)
+		WHERE (d.created_at >= $1 AND d.created_at < $2
+				OR d.status = 'DELIVERED' AND d.delivered_at >= $1 AND d.delivered_at < $2
+				OR d.status = 'CANCELLED' AND d.cancelled_at >= $1 AND d.cancelled_at < $2)
+			AND e.at >= $1 AND e.at < $2
+		GROUP BY city, d.currency`,
+		hour, end,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetDeliveryMetricsReport summarises deliveries by city and currency from
+// the hourly rollups, for the last 7 days unless from/to are given, with
+// the hourly breakdown when hourly=true.
+// GET /api/v1/internal/metrics/deliveries?from=&to=&city=&hourly=
+func (h *Handler) GetDeliveryMetricsReport(w http.ResponseWriter, r *http.Request) {
+	to := time.Now().UTC()
+	if v := r.URL.Query().Get("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "to must be an RFC3339 timestamp")
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-7 * 24 * time.Hour)
+	if v := r.URL.Query().Get("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil || !parsed.Before(to) {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "from must be an RFC3339 timestamp before to")
+			return
+		}
+		from = parsed
+	}
+	from = from.UTC().Truncate(time.Hour)
+
+	rows, err := h.db.Pool.Query(r.Context(), `
+		SELECT
+			hour, city, currency, created, delivered, cancelled,
+			revenue, fare_count, duration_sum_seconds, duration_count
+		FROM delivery_hourly_metrics
+		WHERE hour >= $1 AND hour < $2
+			AND ($3 = '' OR city = $3)
+		ORDER BY hour, city, currency`,
+		from, to, r.URL.Query().Get("city"),
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to build metrics report")
+		return
+	}
+	defer rows.Close()
+
+	hours := []deliveryHourlyMetrics{}
+	for rows.Next() {
+		var m deliveryHourlyMetrics
+		if err := rows.Scan(
+			&m.Hour, &m.City, &m.Currency, &m.Created, &m.Delivered, &m.Cancelled,
+			&m.Revenue, &m.FareCount, &m.DurationSumSeconds, &m.DurationCount,
+		); err != nil {
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to build metrics report")
+			return
+		}
+		hours = append(hours, m)
+	}
+
+	response := map[string]interface{}{
+		"from":   from,
+		"to":     to,
+		"cities": summarizeDeliveryMetrics(hours),
+	}
+	if r.URL.Query().Get("hourly") == "true" {
+		response["hours"] = hours
+	}
+	respond(w, http.StatusOK, response)
+}
+
+// summarizeDeliveryMetrics totals hourly metrics by city and currency
+func summarizeDeliveryMetrics(hours []deliveryHourlyMetrics) []*deliveryMetricsSummary {
+	type totals struct {
+		summary                     *deliveryMetricsSummary
+		fareCount, durSum, durCount int64
+	}
+	byKey := map[string]*totals{}
+	summaries := []*deliveryMetricsSummary{}
+	for _, m := range hours {
+		key := m.City + "|" + m.Currency
+		t, ok := byKey[key]
+		if !ok {
+			t = &totals{summary: &deliveryMetricsSummary{City: m.City, Currency: m.Currency}}
+			byKey[key] = t
+			summaries = append(summaries, t.summary)
+		}
+		t.summary.Created += m.Created
+		t.summary.Delivered += m.Delivered
+		t.summary.Cancelled += m.Cancelled
+		t.summary.Revenue += m.Revenue
+		t.fareCount += m.FareCount
+		t.durSum += m.DurationSumSeconds
+		t.durCount += m.DurationCount
+	}
+
+	for _, t := range byKey {
+		s := t.summary
+		if t.fareCount > 0 {
+			s.AvgFare = s.Revenue / float64(t.fareCount)
+		}
+		if t.durCount > 0 {
+			s.AvgDurationSeconds = float64(t.durSum) / float64(t.durCount)
+		}
+		if s.Created > 0 {
+			s.CompletionRate = float64(s.Delivered) / float64(s.Created) * 100
+		}
+	}
+	return summaries
+}
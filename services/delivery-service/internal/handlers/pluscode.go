@@ -0,0 +1,123 @@
+/*
+ * Plus Code (Open Location Code) Helpers
+ */
+
+package handlers
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+const (
+	plusCodeAlphabet = "23456789CFGHJMPQRVWX"
+	plusCodeSepIndex = 8
+	plusCodeGridRows = 5
+	plusCodeGridCols = 4
+)
+
+var errInvalidPlusCode = errors.New("invalid plus code")
+
+// resolvePlusCode fills in a location's coordinates from its plus code when
+// it came without them. Short codes are read near ref, which may be nil.
+func resolvePlusCode(loc *models.Location, ref *models.Location) error {
+	if loc.PlusCode == "" || loc.Latitude != 0 || loc.Longitude != 0 {
+		return nil
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(loc.PlusCode))
+	sep := strings.Index(code, "+")
+	if sep < 2 || sep > plusCodeSepIndex || sep%2 == 1 || strings.ContainsRune(code, '0') {
+		return errInvalidPlusCode
+	}
+
+	// Short codes borrow their missing leading digits from the reference
+	if sep < plusCodeSepIndex {
+		if ref == nil || (ref.Latitude == 0 && ref.Longitude == 0) {
+			return errors.New("short plus code needs the other location's coordinates")
+		}
+		missing := plusCodeSepIndex - sep
+		prefix := encodePlusCodePrefix(ref.Latitude, ref.Longitude, missing)
+		lat, lng, err := decodePlusCode(prefix + code)
+		if err != nil {
+			return err
+		}
+
+		// Move a whole cell if that lands further than half a cell away
+		resolution := math.Pow(20, float64(2-missing/2))
+		switch {
+		case ref.Latitude+resolution/2 < lat:
+			lat -= resolution
+		case ref.Latitude-resolution/2 > lat:
+			lat += resolution
+		}
+		switch {
+		case ref.Longitude+resolution/2 < lng:
+			lng -= resolution
+		case ref.Longitude-resolution/2 > lng:
+			lng += resolution
+		}
+		loc.Latitude, loc.Longitude = lat, lng
+		return nil
+	}
+
+	lat, lng, err := decodePlusCode(code)
+	if err != nil {
+		return err
+	}
+	loc.Latitude, loc.Longitude = lat, lng
+	return nil
+}
+
+// decodePlusCode gives the centre of a full plus code's area
+func decodePlusCode(code string) (float64, float64, error) {
+	digits := strings.Replace(code, "+", "", 1)
+	if len(digits) < plusCodeSepIndex || len(digits) == plusCodeSepIndex+1 {
+		return 0, 0, errInvalidPlusCode
+	}
+
+	lat, lng := -90.0, -180.0
+	latRes, lngRes := 400.0, 400.0
+	for i := 0; i < len(digits); i++ {
+		value := strings.IndexByte(plusCodeAlphabet, digits[i])
+		if value < 0 {
+			return 0, 0, errInvalidPlusCode
+		}
+		switch {
+		case i < 10 && i%2 == 0:
+			latRes /= 20
+			lat += float64(value) * latRes
+		case i < 10:
+			lngRes /= 20
+			lng += float64(value) * lngRes
+		default:
+			latRes /= plusCodeGridRows
+			lngRes /= plusCodeGridCols
+			lat += float64(value/plusCodeGridCols) * latRes
+			lng += float64(value%plusCodeGridCols) * lngRes
+		}
+	}
+	if lat >= 90 || lng >= 180 {
+		return 0, 0, errInvalidPlusCode
+	}
+
+	return math.Min(lat+latRes/2, 90), lng + lngRes/2, nil
+}
+
+// encodePlusCodePrefix gives the first n digits of a location's plus code
+func encodePlusCodePrefix(lat, lng float64, n int) string {
+	latVal := int64(math.Floor((math.Min(lat, 89.99999) + 90) * 8000))
+	lngVal := int64(math.Floor((lng + 180) * 8000))
+
+	digits := make([]byte, 10)
+	for i := 4; i >= 0; i-- {
+		digits[2*i] = plusCodeAlphabet[latVal%20]
+		digits[2*i+1] = plusCodeAlphabet[lngVal%20]
+		latVal /= 20
+		lngVal /= 20
+	}
+	return string(digits[:n])
+}
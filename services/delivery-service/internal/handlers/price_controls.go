@@ -0,0 +1,129 @@
+/*
+ * Regulatory Price Control Handlers
+ */
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/config"
+)
+
+// applyPriceControls clamps a fare's surge and distance components to a
+// market's limits. The minimum fare is applied to the total by the caller.
+func (h *Handler) applyPriceControls(ctx context.Context, fare *FareBreakdown, controls config.PriceControls, distanceKm float64, currency string) {
+	subtotal := fare.BaseFare + fare.DistanceFare + fare.TimeFare
+	if controls.MaxSurgeMultiplier > 0 && subtotal > 0 {
+		maxSurgeFare := subtotal * (controls.MaxSurgeMultiplier - 1)
+		if fare.SurgeFare > maxSurgeFare {
+			h.recordPriceControlViolation(ctx, currency, "MAX_SURGE", controls.MaxSurgeMultiplier, 1+fare.SurgeFare/subtotal, controls.MaxSurgeMultiplier)
+			fare.SurgeFare = maxSurgeFare
+		}
+	}
+
+	if distanceKm <= 0 {
+		return
+	}
+
+	if controls.MaxPerKmRate > 0 && fare.DistanceFare > controls.MaxPerKmRate*distanceKm {
+		h.recordPriceControlViolation(ctx, currency, "MAX_PER_KM_RATE", controls.MaxPerKmRate, fare.DistanceFare/distanceKm, controls.MaxPerKmRate)
+		fare.DistanceFare = controls.MaxPerKmRate * distanceKm
+	}
+	if controls.MinPerKmRate > 0 && fare.DistanceFare < controls.MinPerKmRate*distanceKm {
+		h.recordPriceControlViolation(ctx, currency, "MIN_PER_KM_RATE", controls.MinPerKmRate, fare.DistanceFare/distanceKm, controls.MinPerKmRate)
+		fare.DistanceFare = controls.MinPerKmRate * distanceKm
+	}
+}
+
+// recordPriceControlViolation logs a fare clamped to a price control and
+// stores it for the compliance report
+func (h *Handler) recordPriceControlViolation(ctx context.Context, currency, control string, limit, computed, applied float64) {
+	log.Warn().
+		Str("currency", currency).
+		Str("control", control).
+		Float64("limit", limit).
+		Float64("computed", computed).
+		Msg("Delivery fare clamped to price control")
+
+	_, err := h.db.Pool.Exec(ctx, `
+		INSERT INTO delivery_price_control_violations (id, currency, control, limit_value, computed, applied)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		uuid.New().String(), currency, control, limit, computed, applied,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to store price control violation")
+	}
+}
+
+// GetPriceControlReport summarises fares clamped to price controls by
+// currency and control, for the last 7 days unless from/to are given.
+// GET /api/v1/internal/compliance/price-controls?from=&to=
+func (h *Handler) GetPriceControlReport(w http.ResponseWriter, r *http.Request) {
+	to := time.Now().UTC()
+	if v := r.URL.Query().Get("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "to must be an RFC3339 timestamp")
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-7 * 24 * time.Hour)
+	if v := r.URL.Query().Get("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil || !parsed.Before(to) {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "from must be an RFC3339 timestamp before to")
+			return
+		}
+		from = parsed
+	}
+
+	rows, err := h.db.Pool.Query(r.Context(), `
+		SELECT currency, control, limit_value, COUNT(*),
+			CASE WHEN control LIKE 'MAX_%' THEN MAX(computed) ELSE MIN(computed) END,
+			MIN(created_at), MAX(created_at)
+		FROM delivery_price_control_violations
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY currency, control, limit_value
+		ORDER BY COUNT(*) DESC, currency, control`,
+		from, to,
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to build compliance report")
+		return
+	}
+	defer rows.Close()
+
+	report := []map[string]interface{}{}
+	for rows.Next() {
+		var currency, control string
+		var limit, worstCase float64
+		var violations int64
+		var firstSeen, lastSeen time.Time
+		if err := rows.Scan(&currency, &control, &limit, &violations, &worstCase, &firstSeen, &lastSeen); err != nil {
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to build compliance report")
+			return
+		}
+		report = append(report, map[string]interface{}{
+			"currency":    currency,
+			"control":     control,
+			"limit":       limit,
+			"violations":  violations,
+			"worstCase":   worstCase,
+			"firstSeenAt": firstSeen,
+			"lastSeenAt":  lastSeen,
+		})
+	}
+
+	respond(w, http.StatusOK, map[string]interface{}{
+		"from": from,
+		"to":   to,
+		"rows": report,
+	})
+}
@@ -0,0 +1,444 @@
+/*
+ * Refund Handlers
+ */
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+)
+
+// refundApprovalThresholds are the amounts (major units) at or above which
+// a refund needs a second approver
+var refundApprovalThresholds = map[string]float64{
+	"NGN": 10000,
+	"KES": 2000,
+	"GHS": 200,
+	"UGX": 50000,
+	"TZS": 30000,
+	"RWF": 20000,
+	"ZAR": 300,
+	"USD": 20,
+}
+
+// refundReasons maps reason codes to whether the driver shares the cost
+var refundReasons = map[string]bool{
+	"OVERCHARGED":       true,
+	"DAMAGED_PACKAGE":   true,
+	"LATE_DELIVERY":     false,
+	"NOT_DELIVERED":     true,
+	"DRIVER_MISCONDUCT": true,
+	"SERVICE_FAILURE":   false,
+	"DUPLICATE_CHARGE":  true,
+	"GOODWILL":          false,
+	"FAILED_DELIVERY":   true,
+}
+
+type deliveryRefund struct {
+	ID            string    `json:"id"`
+	DeliveryID    string    `json:"deliveryId"`
+	CustomerID    string    `json:"customerId"`
+	Type          string    `json:"type"`
+	Reason        string    `json:"reason"`
+	Note          *string   `json:"note,omitempty"`
+	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency"`
+	Status        string    `json:"status"`
+	RequestedBy   string    `json:"requestedBy"`
+	ApprovedBy    *string   `json:"approvedBy,omitempty"`
+	ProviderRef   *string   `json:"providerRef,omitempty"`
+	FailureReason *string   `json:"failureReason,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+const refundColumns = `id, delivery_id, customer_id, type, reason, note, amount, currency, status,
+	requested_by, approved_by, provider_ref, failure_reason, created_at, updated_at`
+
+// ============================================
+// Refund Workflow
+// ============================================
+
+// CreateDeliveryRefund issues a full/partial refund or goodwill credit against
+// a delivered order. Amounts at or above the approval threshold are held for a
+// SUPPORT_LEAD or ADMIN to approve.
+func (h *Handler) CreateDeliveryRefund(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deliveryID := chi.URLParam(r, "id")
+	agentID := middleware.GetUserID(ctx)
+
+	var req struct {
+		Type   string  `json:"type"`
+		Reason string  `json:"reason"`
+		Amount float64 `json:"amount"`
+		Note   string  `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+	if _, ok := refundReasons[req.Reason]; !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid refund reason code")
+		return
+	}
+
+	var customerID, status, currency string
+	var totalFare float64
+	err := h.db.Pool.QueryRow(ctx, `
+		SELECT customer_id, status, total_fare, currency
+		FROM deliveries WHERE id = $1`,
+		deliveryID,
+	).Scan(&customerID, &status, &totalFare, &currency)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Delivery not found")
+		return
+	}
+	if status != "DELIVERED" {
+		respondError(w, http.StatusConflict, "DELIVERY_NOT_REFUNDABLE", "Only delivered orders can be refunded")
+		return
+	}
+
+	var refunded float64
+	h.db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount), 0) FROM delivery_refunds
+		WHERE delivery_id = $1 AND type != 'GOODWILL_CREDIT'
+			AND status IN ('PENDING_APPROVAL', 'PROCESSING', 'COMPLETED')`,
+		deliveryID,
+	).Scan(&refunded)
+	refundable := math.Round((totalFare-refunded)*100) / 100
+
+	amount := req.Amount
+	switch req.Type {
+	case "FULL":
+		amount = refundable
+	case "PARTIAL":
+		if amount > refundable {
+			respondError(w, http.StatusUnprocessableEntity, "REFUND_EXCEEDS_FARE", "Refund exceeds refundable fare")
+			return
+		}
+	case "GOODWILL_CREDIT":
+	default:
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid refund type")
+		return
+	}
+	if amount <= 0 {
+		respondError(w, http.StatusUnprocessableEntity, "REFUND_EXCEEDS_FARE", "Nothing left to refund")
+		return
+	}
+
+	refundStatus := "PROCESSING"
+	threshold, ok := refundApprovalThresholds[currency]
+	if !ok {
+		threshold = refundApprovalThresholds["USD"]
+	}
+	if amount >= threshold {
+		refundStatus = "PENDING_APPROVAL"
+	}
+
+	refundID := uuid.New().String()
+	_, err = h.db.Pool.Exec(ctx, `
+		INSERT INTO delivery_refunds (id, delivery_id, customer_id, type, reason, note, amount, currency, status, requested_by)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10)`,
+		refundID, deliveryID, customerID, req.Type, req.Reason, req.Note, amount, currency, refundStatus, agentID,
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create refund")
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create refund")
+		return
+	}
+
+	if refundStatus == "PROCESSING" {
+		h.executeRefund(ctx, refundID)
+	}
+
+	refund, err := h.getRefund(ctx, refundID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load refund")
+		return
+	}
+
+	httpStatus := http.StatusCreated
+	if refund.Status == "PENDING_APPROVAL" {
+		httpStatus = http.StatusAccepted
+	}
+	respond(w, httpStatus, refund)
+}
+
+// ListDeliveryRefunds lists refunds and ledger entries for a delivery
+func (h *Handler) ListDeliveryRefunds(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deliveryID := chi.URLParam(r, "id")
+
+	refunds := []*deliveryRefund{}
+	rows, err := h.db.Pool.Query(ctx,
+		`SELECT `+refundColumns+` FROM delivery_refunds WHERE delivery_id = $1 ORDER BY created_at DESC`,
+		deliveryID,
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list refunds")
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rf deliveryRefund
+		if err := rows.Scan(
+			&rf.ID, &rf.DeliveryID, &rf.CustomerID, &rf.Type, &rf.Reason, &rf.Note, &rf.Amount, &rf.Currency, &rf.Status,
+			&rf.RequestedBy, &rf.ApprovedBy, &rf.ProviderRef, &rf.FailureReason, &rf.CreatedAt, &rf.UpdatedAt,
+		); err == nil {
+			refunds = append(refunds, &rf)
+		}
+	}
+
+	ledger := []map[string]interface{}{}
+	ledgerRows, err := h.db.Pool.Query(ctx, `
+		SELECT id, refund_id, account, account_id, amount, currency, description, created_at
+		FROM delivery_ledger_entries
+		WHERE delivery_id = $1
+		ORDER BY created_at ASC`,
+		deliveryID,
+	)
+	if err == nil {
+		defer ledgerRows.Close()
+		for ledgerRows.Next() {
+			var id, account, currency string
+			var refundID, accountID, description *string
+			var amount float64
+			var createdAt time.Time
+			ledgerRows.Scan(&id, &refundID, &account, &accountID, &amount, &currency, &description, &createdAt)
+			ledger = append(ledger, map[string]interface{}{
+				"id":          id,
+				"refundId":    refundID,
+				"account":     account,
+				"accountId":   accountID,
+				"amount":      amount,
+				"currency":    currency,
+				"description": description,
+				"createdAt":   createdAt,
+			})
+		}
+	}
+
+	respond(w, http.StatusOK, map[string]interface{}{
+		"refunds": refunds,
+		"ledger":  ledger,
+	})
+}
+
+// ApproveDeliveryRefund approves a pending refund and executes it
+func (h *Handler) ApproveDeliveryRefund(w http.ResponseWriter, r *http.Request) {
+	h.decideRefund(w, r, true)
+}
+
+// RejectDeliveryRefund rejects a pending refund
+func (h *Handler) RejectDeliveryRefund(w http.ResponseWriter, r *http.Request) {
+	h.decideRefund(w, r, false)
+}
+
+func (h *Handler) decideRefund(w http.ResponseWriter, r *http.Request, approve bool) {
+	ctx := r.Context()
+	refundID := chi.URLParam(r, "refundId")
+	approverID := middleware.GetUserID(ctx)
+
+	role := middleware.GetUserRole(ctx)
+	if role != "SUPPORT_LEAD" && role != "ADMIN" {
+		respondError(w, http.StatusForbidden, "FORBIDDEN", "Refund approval requires a support lead")
+		return
+	}
+
+	refund, err := h.getRefund(ctx, refundID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Refund not found")
+		return
+	}
+	if refund.Status != "PENDING_APPROVAL" {
+		respondError(w, http.StatusConflict, "REFUND_NOT_PENDING", "Refund is not awaiting approval")
+		return
+	}
+	if approve && refund.RequestedBy == approverID {
+		respondError(w, http.StatusForbidden, "REFUND_SELF_APPROVAL", "Refund must be approved by a different agent")
+		return
+	}
+
+	newStatus := "REJECTED"
+	var note *string
+	if approve {
+		newStatus = "PROCESSING"
+	} else {
+		var req struct {
+			Reason string `json:"reason"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		note = &req.Reason
+	}
+
+	result, err := h.db.Pool.Exec(ctx, `
+		UPDATE delivery_refunds SET status = $2, approved_by = $3, failure_reason = $4, updated_at = NOW()
+		WHERE id = $1 AND status = 'PENDING_APPROVAL'`,
+		refundID, newStatus, approverID, note,
+	)
+	if err != nil || result.RowsAffected() == 0 {
+		respondError(w, http.StatusConflict, "REFUND_NOT_PENDING", "Refund is not awaiting approval")
+		return
+	}
+
+	if approve {
+		h.executeRefund(ctx, refundID)
+	}
+
+	refund, err = h.getRefund(ctx, refundID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load refund")
+		return
+	}
+	respond(w, http.StatusOK, refund)
+}
+
+// executeRefund sends a PROCESSING refund to the payment service and, on
+// success, writes ledger entries reversing the driver/platform split
+func (h *Handler) executeRefund(ctx context.Context, refundID string) {
+	refund, err := h.getRefund(ctx, refundID)
+	if err != nil {
+		return
+	}
+
+	var paymentID, driverID *string
+	var totalFare, serviceFee, insuranceFee float64
+	deliveryStatus := "DELIVERED"
+	h.db.Pool.QueryRow(ctx, `
+		SELECT payment_id, driver_id, total_fare, service_fee, insurance_fee, status
+		FROM deliveries WHERE id = $1`,
+		refund.DeliveryID,
+	).Scan(&paymentID, &driverID, &totalFare, &serviceFee, &insuranceFee, &deliveryStatus)
+
+	var providerRef string
+	if refund.Type == "GOODWILL_CREDIT" {
+		providerRef, err = h.callPaymentService(ctx, "/wallets/credits", map[string]interface{}{
+			"userId":      refund.CustomerID,
+			"amount":      refund.Amount,
+			"currency":    refund.Currency,
+			"reference":   refund.ID,
+			"description": refund.Reason,
+		})
+		if err == nil && providerRef == "" {
+			providerRef = refund.ID
+		}
+	} else if paymentID == nil || *paymentID == "" {
+		err = fmt.Errorf("delivery has no payment to refund")
+	} else {
+		providerRef, err = h.callPaymentService(ctx, "/payments/"+*paymentID+"/refund", map[string]interface{}{
+			"amount": refund.Amount,
+			"reason": refund.Reason,
+		})
+	}
+
+	if err != nil {
+		log.Error().Err(err).Str("refundId", refundID).Msg("Refund execution failed")
+		h.db.Pool.Exec(ctx, `
+			UPDATE delivery_refunds SET status = 'FAILED', failure_reason = $2, updated_at = NOW()
+			WHERE id = $1`,
+			refundID, err.Error(),
+		)
+		return
+	}
+
+	// Driver earnings are the fare less platform service and insurance fees
+	driverShare := 0.0
+	if refund.Type != "GOODWILL_CREDIT" && refundReasons[refund.Reason] && driverID != nil && totalFare > 0 {
+		driverShare = math.Round(refund.Amount*(totalFare-serviceFee-insuranceFee)/totalFare*100) / 100
+	}
+
+	tx, err := h.db.Pool.Begin(ctx)
+	if err != nil {
+		log.Error().Err(err).Str("refundId", refundID).Str("providerRef", providerRef).Msg("Refund executed but ledger write failed")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	tx.Exec(ctx, `
+		UPDATE delivery_refunds SET status = 'COMPLETED', provider_ref = $2, updated_at = NOW()
+		WHERE id = $1`,
+		refundID, providerRef,
+	)
+
+	insertEntry := `
+		INSERT INTO delivery_ledger_entries (id, delivery_id, refund_id, account, account_id, amount, currency, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	tx.Exec(ctx, insertEntry, uuid.New().String(), refund.DeliveryID, refundID,
+		"CUSTOMER", refund.CustomerID, refund.Amount, refund.Currency, refund.Type+" refund: "+refund.Reason)
+	if driverShare > 0 {
+		tx.Exec(ctx, insertEntry, uuid.New().String(), refund.DeliveryID, refundID,
+			"DRIVER", *driverID, -driverShare, refund.Currency, "Driver earnings reversal")
+	}
+	tx.Exec(ctx, insertEntry, uuid.New().String(), refund.DeliveryID, refundID,
+		"PLATFORM", nil, -(refund.Amount - driverShare), refund.Currency, "Platform fee reversal")
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error().Err(err).Str("refundId", refundID).Str("providerRef", providerRef).Msg("Refund executed but ledger write failed")
+		return
+	}
+
+	h.createDeliveryEvent(ctx, refund.DeliveryID, "FARE_ADJUSTED", deliveryStatus, nil, &refundID)
+}
+
+func (h *Handler) getRefund(ctx context.Context, refundID string) (*deliveryRefund, error) {
+	var rf deliveryRefund
+	err := h.db.Pool.QueryRow(ctx,
+		`SELECT `+refundColumns+` FROM delivery_refunds WHERE id = $1`,
+		refundID,
+	).Scan(
+		&rf.ID, &rf.DeliveryID, &rf.CustomerID, &rf.Type, &rf.Reason, &rf.Note, &rf.Amount, &rf.Currency, &rf.Status,
+		&rf.RequestedBy, &rf.ApprovedBy, &rf.ProviderRef, &rf.FailureReason, &rf.CreatedAt, &rf.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rf, nil
+}
+
+// callPaymentService posts to the payment service and returns the refund ID
+func (h *Handler) callPaymentService(ctx context.Context, path string, body interface{}) (string, error) {
+	payload, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.cfg.PaymentServiceURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Service-Key", h.cfg.InternalServiceKey)
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success bool `json:"success"`
+		Data    struct {
+			RefundID string `json:"refundId"`
+		} `json:"data"`
+		Error *errorInfo `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if resp.StatusCode >= 300 || !result.Success {
+		if result.Error != nil {
+			return "", fmt.Errorf("payment service error %s: %s", result.Error.Code, result.Error.Message)
+		}
+		return "", fmt.Errorf("payment service returned status %d", resp.StatusCode)
+	}
+	return result.Data.RefundID, nil
+}
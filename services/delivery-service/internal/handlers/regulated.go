@@ -0,0 +1,262 @@
+/*
+ * Regulated Deliveries
+ */
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+// recipientIDRules are the IDs a recipient may show for a regulated
+// delivery, by ISO country code, with the shape of each ID's number.
+// Regulated deliveries are not offered in countries not listed here.
+var recipientIDRules = map[string]map[string]*regexp.Regexp{
+	"NG": {
+		"NIN":             regexp.MustCompile(`^\d{11}$`),
+		"VOTERS_CARD":     regexp.MustCompile(`^[A-Z0-9]{19}$`),
+		"DRIVERS_LICENSE": regexp.MustCompile(`^[A-Z]{3}[A-Z0-9]{9}$`),
+		"PASSPORT":        regexp.MustCompile(`^[A-Z]\d{8}$`),
+	},
+	"KE": {
+		"NATIONAL_ID": regexp.MustCompile(`^\d{7,8}$`),
+		"ALIEN_ID":    regexp.MustCompile(`^\d{6,9}$`),
+		"PASSPORT":    regexp.MustCompile(`^[A-Z]{1,2}\d{6,7}$`),
+	},
+	"GH": {
+		"GHANA_CARD": regexp.MustCompile(`^GHA-\d{9}-\d$`),
+		"PASSPORT":   regexp.MustCompile(`^G\d{7}$`),
+	},
+	"UG": {
+		"NATIONAL_ID": regexp.MustCompile(`^C[MF][A-Z0-9]{12}$`),
+		"PASSPORT":    regexp.MustCompile(`^[A-Z]\d{7}$`),
+	},
+	"TZ": {
+		"NIDA":     regexp.MustCompile(`^\d{20}$`),
+		"PASSPORT": regexp.MustCompile(`^[A-Z]{2}\d{6,7}$`),
+	},
+	"ZA": {
+		"SA_ID":    regexp.MustCompile(`^\d{13}$`),
+		"PASSPORT": regexp.MustCompile(`^[A-Z]?\d{8,9}$`),
+	},
+}
+
+// Prescription is a prescription attached to a regulated delivery
+type Prescription struct {
+	Reference   string     `json:"reference"`
+	Prescriber  string     `json:"prescriber"`
+	IssuedAt    *time.Time `json:"issuedAt,omitempty"`
+	DocumentURL string     `json:"documentUrl"`
+}
+
+// RecipientID is the ID a courier checked when handing over a regulated
+// delivery
+type RecipientID struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Number string `json:"number"`
+}
+
+// applyRegulatedTerms marks a delivery regulated when it carries
+// prescription-only items or a prescription, and checks it can be made.
+// It returns a message for the customer when it cannot.
+func applyRegulatedTerms(req *CreateDeliveryRequest) string {
+	prescriptionOnly := false
+	for _, item := range req.Package.Contents {
+		prescriptionOnly = prescriptionOnly || item.PrescriptionOnly
+	}
+	if prescriptionOnly || req.Prescription != nil {
+		req.Regulated = true
+	}
+	if !req.Regulated {
+		return ""
+	}
+
+	if prescriptionOnly && (req.Prescription == nil || req.Prescription.Reference == "" || req.Prescription.DocumentURL == "") {
+		return "Prescription-only items need a prescription reference and document"
+	}
+	if _, ok := recipientIDRules[countryCode(req.DropoffLocation.Country, req.Currency)]; !ok {
+		return "Regulated deliveries are not available in this country"
+	}
+	// Handover is against the recipient's ID, so it is always proven
+	req.Package.RequiresPOD = true
+	return ""
+}
+
+// createComplianceRecord stores a regulated delivery's prescription apart
+// from the delivery itself, which most staff and partners can read
+func (h *Handler) createComplianceRecord(ctx context.Context, deliveryID, customerID string, p *Prescription) error {
+	if p == nil {
+		p = &Prescription{}
+	}
+	_, err := h.db.Pool.Exec(ctx, `
+		INSERT INTO regulated_delivery_compliance (
+			delivery_id, customer_id,
+			prescription_reference, prescriber, prescription_issued_at, prescription_document_url,
+			created_at
+		) VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, NULLIF($6, ''), NOW())`,
+		deliveryID, customerID,
+		p.Reference, p.Prescriber, p.IssuedAt, p.DocumentURL,
+	)
+	return err
+}
+
+// validateRecipientID checks an ID against the rules for the dropoff's
+// country and returns it normalized
+func validateRecipientID(id *RecipientID, country string) (RecipientID, error) {
+	if id == nil || strings.TrimSpace(id.Name) == "" {
+		return RecipientID{}, errors.New("recipient name and ID are required")
+	}
+	rules, ok := recipientIDRules[country]
+	if !ok {
+		return RecipientID{}, fmt.Errorf("no recipient ID rules for %s", country)
+	}
+
+	idType := strings.ToUpper(strings.TrimSpace(id.Type))
+	pattern, ok := rules[idType]
+	if !ok {
+		accepted := make([]string, 0, len(rules))
+		for t := range rules {
+			accepted = append(accepted, t)
+		}
+		sort.Strings(accepted)
+		return RecipientID{}, fmt.Errorf("%s is not accepted in %s, use one of %s", idType, country, strings.Join(accepted, ", "))
+	}
+	number := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(id.Number), " ", ""))
+	if !pattern.MatchString(number) {
+		return RecipientID{}, fmt.Errorf("%s number is not valid", idType)
+	}
+	return RecipientID{Name: strings.TrimSpace(id.Name), Type: idType, Number: number}, nil
+}
+
+// recordRecipientID stores the checked ID on the delivery's compliance
+// record, with the recipient's name and ID number sealed
+func (h *Handler) recordRecipientID(ctx context.Context, deliveryID, driverID, country string, id RecipientID) error {
+	name, err := h.db.SealText(ctx, id.Name)
+	if err != nil {
+		return err
+	}
+	number, err := h.db.SealText(ctx, id.Number)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.db.Pool.Exec(ctx, `
+		UPDATE regulated_delivery_compliance SET
+			recipient_name = $2,
+			recipient_id_type = $3,
+			recipient_id_number = $4,
+			recipient_id_country = $5,
+			verified_by = $6,
+			verified_at = NOW()
+		WHERE delivery_id = $1`,
+		deliveryID, name, id.Type, number, country, driverID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("no compliance record for delivery %s", deliveryID)
+	}
+	return nil
+}
+
+// GetDeliveryComplianceRecord returns a regulated delivery's prescription
+// and recipient ID to compliance staff. Every read is logged.
+func (h *Handler) GetDeliveryComplianceRecord(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	deliveryID := chi.URLParam(r, "id")
+
+	var rec struct {
+		DeliveryID              string     `json:"deliveryId"`
+		CustomerID              string     `json:"customerId"`
+		PrescriptionReference   *string    `json:"prescriptionReference,omitempty"`
+		Prescriber              *string    `json:"prescriber,omitempty"`
+		PrescriptionIssuedAt    *time.Time `json:"prescriptionIssuedAt,omitempty"`
+		PrescriptionDocumentURL *string    `json:"prescriptionDocumentUrl,omitempty"`
+		RecipientName           *string    `json:"recipientName,omitempty"`
+		RecipientIDType         *string    `json:"recipientIdType,omitempty"`
+		RecipientIDNumber       *string    `json:"recipientIdNumber,omitempty"`
+		RecipientIDCountry      *string    `json:"recipientIdCountry,omitempty"`
+		VerifiedBy              *string    `json:"verifiedBy,omitempty"`
+		VerifiedAt              *time.Time `json:"verifiedAt,omitempty"`
+		CreatedAt               time.Time  `json:"createdAt"`
+	}
+	err := h.db.Pool.QueryRow(r.Context(), `
+		SELECT delivery_id, customer_id,
+			prescription_reference, prescriber, prescription_issued_at, prescription_document_url,
+			recipient_name, recipient_id_type, recipient_id_number, recipient_id_country,
+			verified_by, verified_at, created_at
+		FROM regulated_delivery_compliance WHERE delivery_id = $1`,
+		deliveryID,
+	).Scan(
+		&rec.DeliveryID, &rec.CustomerID,
+		&rec.PrescriptionReference, &rec.Prescriber, &rec.PrescriptionIssuedAt, &rec.PrescriptionDocumentURL,
+		&rec.RecipientName, &rec.RecipientIDType, &rec.RecipientIDNumber, &rec.RecipientIDCountry,
+		&rec.VerifiedBy, &rec.VerifiedAt, &rec.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "No compliance record for this delivery")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to fetch compliance record")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch compliance record")
+		return
+	}
+	for _, field := range []*string{rec.RecipientName, rec.RecipientIDNumber} {
+		if field == nil {
+			continue
+		}
+		if *field, err = h.db.OpenText(r.Context(), *field); err != nil {
+			log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to open compliance record")
+			respondError(w, http.StatusInternalServerError, "ENCRYPTION_ERROR", "Failed to fetch compliance record")
+			return
+		}
+	}
+
+	_, err = h.db.Pool.Exec(r.Context(), `
+		INSERT INTO regulated_compliance_access_log (id, delivery_id, accessed_by, role, created_at)
+		VALUES ($1, $2, $3, $4, NOW())`,
+		uuid.New(), deliveryID, userID, middleware.GetUserRole(r.Context()),
+	)
+	if err != nil {
+		// An unlogged read is not allowed
+		log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to log compliance record access")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch compliance record")
+		return
+	}
+
+	respond(w, http.StatusOK, rec)
+}
+
+// regulatedDropoff gets what ConfirmDelivery needs to check a recipient's
+// ID: whether the delivery is regulated and the dropoff's country
+func (h *Handler) regulatedDropoff(ctx context.Context, deliveryID string) (bool, string, error) {
+	var regulated bool
+	var country string
+	var currency models.Currency
+	err := h.db.Pool.QueryRow(ctx,
+		"SELECT regulated, COALESCE(dropoff_location->>'country', ''), currency FROM deliveries WHERE id = $1",
+		deliveryID,
+	).Scan(&regulated, &country, &currency)
+	if err != nil {
+		return false, "", err
+	}
+	return regulated, countryCode(country, currency), nil
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+func TestApplyRegulatedTerms(t *testing.T) {
+	lagos := models.Location{City: "Lagos", Country: "NG"}
+	rx := &Prescription{Reference: "RX-1042", Prescriber: "Dr. Okafor", DocumentURL: "https://files.example/rx-1042.pdf"}
+	medicine := models.Package{Contents: []models.PackageItem{
+		{Category: models.PackageCategoryMedicine, PrescriptionOnly: true},
+	}}
+
+	tests := []struct {
+		name          string
+		req           CreateDeliveryRequest
+		wantErr       bool
+		wantRegulated bool
+	}{
+		{
+			name: "ordinary delivery",
+			req:  CreateDeliveryRequest{DropoffLocation: lagos, Package: models.Package{Category: models.PackageCategoryFood}},
+		},
+		{
+			name:          "prescription-only items with a prescription",
+			req:           CreateDeliveryRequest{DropoffLocation: lagos, Package: medicine, Prescription: rx},
+			wantRegulated: true,
+		},
+		{
+			name:          "prescription alone makes it regulated",
+			req:           CreateDeliveryRequest{DropoffLocation: lagos, Prescription: rx},
+			wantRegulated: true,
+		},
+		{
+			name:          "asked for as regulated",
+			req:           CreateDeliveryRequest{DropoffLocation: models.Location{City: "Nairobi"}, Currency: models.CurrencyKES, Regulated: true},
+			wantRegulated: true,
+		},
+		{
+			name:    "prescription-only items without a prescription",
+			req:     CreateDeliveryRequest{DropoffLocation: lagos, Package: medicine},
+			wantErr: true,
+		},
+		{
+			name:    "prescription without its document",
+			req:     CreateDeliveryRequest{DropoffLocation: lagos, Package: medicine, Prescription: &Prescription{Reference: "RX-1042"}},
+			wantErr: true,
+		},
+		{
+			name:    "country without recipient ID rules",
+			req:     CreateDeliveryRequest{DropoffLocation: models.Location{Country: "SN"}, Currency: models.CurrencyXOF, Prescription: rx},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			msg := applyRegulatedTerms(&req)
+			if (msg != "") != tt.wantErr {
+				t.Fatalf("applyRegulatedTerms() = %q, want error %v", msg, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if req.Regulated != tt.wantRegulated {
+				t.Errorf("Regulated = %v, want %v", req.Regulated, tt.wantRegulated)
+			}
+			if req.Package.RequiresPOD != tt.wantRegulated {
+				t.Errorf("RequiresPOD = %v, want %v", req.Package.RequiresPOD, tt.wantRegulated)
+			}
+		})
+	}
+}
+
+func TestValidateRecipientID(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      *RecipientID
+		country string
+		want    RecipientID
+		wantErr bool
+	}{
+		{
+			name:    "Nigerian NIN",
+			id:      &RecipientID{Name: " Chioma Eze ", Type: "nin", Number: "123 4567 8901"},
+			country: "NG",
+			want:    RecipientID{Name: "Chioma Eze", Type: "NIN", Number: "12345678901"},
+		},
+		{
+			name:    "Ghana card",
+			id:      &RecipientID{Name: "Kwame Mensah", Type: "GHANA_CARD", Number: "gha-123456789-0"},
+			country: "GH",
+			want:    RecipientID{Name: "Kwame Mensah", Type: "GHANA_CARD", Number: "GHA-123456789-0"},
+		},
+		{name: "no ID", country: "NG", wantErr: true},
+		{name: "no name", id: &RecipientID{Type: "NIN", Number: "12345678901"}, country: "NG", wantErr: true},
+		{name: "short number", id: &RecipientID{Name: "Chioma Eze", Type: "NIN", Number: "1234567890"}, country: "NG", wantErr: true},
+		{name: "ID from another country", id: &RecipientID{Name: "Chioma Eze", Type: "SA_ID", Number: "8001015009087"}, country: "NG", wantErr: true},
+		{name: "country without rules", id: &RecipientID{Name: "Awa Diop", Type: "PASSPORT", Number: "A1234567"}, country: "SN", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateRecipientID(tt.id, tt.country)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateRecipientID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("validateRecipientID() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+/*
+ * Restricted Items
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+// prohibitedCategories are never carried, whatever the country
+var prohibitedCategories = map[models.PackageCategory]bool{
+	models.PackageCategoryWeapons:   true,
+	models.PackageCategoryHazardous: true,
+}
+
+// cashLimits are the most cash (major units) a single delivery may carry.
+// Cash in a currency not listed here is refused.
+var cashLimits = map[models.Currency]float64{
+	models.CurrencyNGN: 100000,
+	models.CurrencyKES: 20000,
+	models.CurrencyGHS: 2000,
+	models.CurrencyUGX: 500000,
+	models.CurrencyTZS: 300000,
+	models.CurrencyZAR: 3000,
+	models.CurrencyXOF: 100000,
+}
+
+// alcoholRule is how a country treats alcohol deliveries
+type alcoholRule struct {
+	MinAge           int      // recipient's ID is checked at handover
+	ProhibitedStates []string // states or regions where delivery is banned outright
+}
+
+// alcoholRules are keyed by ISO country code. Alcohol to a country not
+// listed here is refused.
+var alcoholRules = map[string]alcoholRule{
+	"NG": {MinAge: 18, ProhibitedStates: []string{
+		"Bauchi", "Borno", "Gombe", "Jigawa", "Kaduna", "Kano",
+		"Katsina", "Kebbi", "Niger", "Sokoto", "Yobe", "Zamfara",
+	}},
+	"KE": {MinAge: 18},
+	"GH": {MinAge: 18},
+	"UG": {MinAge: 18},
+	"TZ": {MinAge: 18},
+	"ZA": {MinAge: 18},
+	"SN": {MinAge: 18},
+	"CI": {MinAge: 18},
+}
+
+// countryCodes maps the country names clients send to ISO codes
+var countryCodes = map[string]string{
+	"NIGERIA":       "NG",
+	"KENYA":         "KE",
+	"GHANA":         "GH",
+	"UGANDA":        "UG",
+	"TANZANIA":      "TZ",
+	"SOUTH AFRICA":  "ZA",
+	"SENEGAL":       "SN",
+	"COTE D'IVOIRE": "CI",
+	"CÔTE D'IVOIRE": "CI",
+	"IVORY COAST":   "CI",
+}
+
+// currencyCountries fills in the country when a location has none; XOF is
+// shared by several countries so it is left out
+var currencyCountries = map[models.Currency]string{
+	models.CurrencyNGN: "NG",
+	models.CurrencyKES: "KE",
+	models.CurrencyGHS: "GH",
+	models.CurrencyUGX: "UG",
+	models.CurrencyTZS: "TZ",
+	models.CurrencyZAR: "ZA",
+}
+
+// Rejection reasons clients can act on
+const (
+	restrictionUnknownCategory     = "UNKNOWN_CATEGORY"
+	restrictionInvalidValue        = "INVALID_DECLARED_VALUE"
+	restrictionProhibitedItem      = "PROHIBITED_ITEM"
+	restrictionCashOverLimit       = "CASH_OVER_LIMIT"
+	restrictionCashNotPermitted    = "CASH_NOT_PERMITTED"
+	restrictionAlcoholNotPermitted = "ALCOHOL_NOT_PERMITTED"
+)
+
+var knownCategories = map[models.PackageCategory]bool{
+	models.PackageCategoryDocuments:   true,
+	models.PackageCategoryElectronics: true,
+	models.PackageCategoryClothing:    true,
+	models.PackageCategoryFood:        true,
+	models.PackageCategoryMedicine:    true,
+	models.PackageCategoryCosmetics:   true,
+	models.PackageCategoryAlcohol:     true,
+	models.PackageCategoryCash:        true,
+	models.PackageCategoryWeapons:     true,
+	models.PackageCategoryHazardous:   true,
+	models.PackageCategoryOther:       true,
+}
+
+// restrictionViolation is one reason a package was refused. Item is the
+// index into the package's contents, or -1 for the package as a whole.
+type restrictionViolation struct {
+	Code     string                 `json:"code"`
+	Item     int                    `json:"item"`
+	Category models.PackageCategory `json:"category,omitempty"`
+	Limit    float64                `json:"limit,omitempty"`
+	Message  string                 `json:"message"`
+}
+
+// normalizePackage rolls itemised contents up into the package's declared
+// value and flags, so older readers of the package JSON see the totals
+func normalizePackage(pkg *models.Package) {
+	for i := range pkg.Contents {
+		item := &pkg.Contents[i]
+		item.Category = models.PackageCategory(strings.ToUpper(strings.TrimSpace(string(item.Category))))
+		if item.Quantity == 0 {
+			item.Quantity = 1
+		}
+	}
+	pkg.Category = models.PackageCategory(strings.ToUpper(strings.TrimSpace(string(pkg.Category))))
+	if len(pkg.Contents) == 0 {
+		return
+	}
+
+	var total float64
+	for _, item := range pkg.Contents {
+		total += item.DeclaredValue
+		pkg.Fragile = pkg.Fragile || item.Fragile
+		pkg.Perishable = pkg.Perishable || item.Perishable
+	}
+	if pkg.Value == 0 {
+		pkg.Value = total
+	}
+}
+
+// checkRestrictedItems runs a package against the restricted items rules
+// for a delivery to dropoff paid in currency. A package with no itemised
+// contents is checked as a single item of its own category. Alcohol that
+// passes sets the package's minimum recipient age and requires proof of
+// delivery, so the driver checks ID at handover.
+func checkRestrictedItems(pkg *models.Package, dropoff models.Location, currency models.Currency) []restrictionViolation {
+	normalizePackage(pkg)
+
+	items := pkg.Contents
+	whole := false
+	if len(items) == 0 {
+		if pkg.Category == "" {
+			return nil
+		}
+		items = []models.PackageItem{{Category: pkg.Category, DeclaredValue: pkg.Value}}
+		whole = true
+	}
+
+	var violations []restrictionViolation
+	add := func(i int, v restrictionViolation) {
+		v.Item = i
+		if whole {
+			v.Item = -1
+		}
+		violations = append(violations, v)
+	}
+
+	if pkg.Value < 0 {
+		violations = append(violations, restrictionViolation{
+			Code: restrictionInvalidValue, Item: -1, Message: "Declared value cannot be negative",
+		})
+	}
+
+	var cash float64
+	cashItem := -1
+	hasAlcohol := false
+	for i, item := range items {
+		switch {
+		case item.Category == "":
+			continue
+		case !knownCategories[item.Category]:
+			add(i, restrictionViolation{
+				Code: restrictionUnknownCategory, Category: item.Category,
+				Message: fmt.Sprintf("Unknown package category %s", item.Category),
+			})
+			continue
+		case prohibitedCategories[item.Category]:
+			add(i, restrictionViolation{
+				Code: restrictionProhibitedItem, Category: item.Category,
+				Message: fmt.Sprintf("%s cannot be sent", strings.ToLower(string(item.Category))),
+			})
+			continue
+		}
+
+		if item.DeclaredValue < 0 {
+			add(i, restrictionViolation{
+				Code: restrictionInvalidValue, Category: item.Category,
+				Message: "Declared value cannot be negative",
+			})
+		}
+		switch item.Category {
+		case models.PackageCategoryCash:
+			cash += item.DeclaredValue
+			if cashItem < 0 {
+				cashItem = i
+			}
+		case models.PackageCategoryAlcohol:
+			hasAlcohol = true
+		}
+	}
+
+	if cashItem >= 0 {
+		limit, ok := cashLimits[currency]
+		switch {
+		case !ok:
+			add(cashItem, restrictionViolation{
+				Code: restrictionCashNotPermitted, Category: models.PackageCategoryCash,
+				Message: fmt.Sprintf("Cash cannot be sent in %s deliveries", currency),
+			})
+		case cash > limit:
+			add(cashItem, restrictionViolation{
+				Code: restrictionCashOverLimit, Category: models.PackageCategoryCash, Limit: limit,
+				Message: fmt.Sprintf("Cash is limited to %.0f %s per delivery", limit, currency),
+			})
+		}
+	}
+
+	if hasAlcohol {
+		country := countryCode(dropoff.Country, currency)
+		rule, ok := alcoholRules[country]
+		if ok && stateProhibitsAlcohol(rule, dropoff.State) {
+			ok = false
+		}
+		if !ok {
+			for i, item := range items {
+				if item.Category == models.PackageCategoryAlcohol {
+					add(i, restrictionViolation{
+						Code: restrictionAlcoholNotPermitted, Category: item.Category,
+						Message: "Alcohol cannot be delivered to this address",
+					})
+				}
+			}
+		} else if len(violations) == 0 {
+			if rule.MinAge > pkg.MinRecipientAge {
+				pkg.MinRecipientAge = rule.MinAge
+			}
+			pkg.RequiresPOD = true
+		}
+	}
+
+	return violations
+}
+
+// countryCode gets the ISO code for a location's country, falling back to
+// the country of the delivery's currency
+func countryCode(country string, currency models.Currency) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if code, ok := countryCodes[country]; ok {
+		return code
+	}
+	if len(country) == 2 {
+		return country
+	}
+	if country == "" {
+		return currencyCountries[currency]
+	}
+	return country
+}
+
+func stateProhibitsAlcohol(rule alcoholRule, state string) bool {
+	state = strings.TrimSuffix(strings.TrimSpace(state), " State")
+	for _, s := range rule.ProhibitedStates {
+		if strings.EqualFold(s, state) {
+			return true
+		}
+	}
+	return false
+}
+
+// respondRestrictedItems refuses a package, listing every rule it broke
+func respondRestrictedItems(w http.ResponseWriter, violations []restrictionViolation) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(response{
+		Success: false,
+		Error: &errorInfo{
+			Code:    "RESTRICTED_ITEMS",
+			Message: violations[0].Message,
+			Details: map[string]interface{}{
+				"violations": violations,
+			},
+		},
+	})
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+func TestCheckRestrictedItems(t *testing.T) {
+	lagos := models.Location{City: "Lagos", State: "Lagos", Country: "Nigeria"}
+	kano := models.Location{City: "Kano", State: "Kano State", Country: "NG"}
+	item := func(category models.PackageCategory, value float64) models.PackageItem {
+		return models.PackageItem{Category: category, DeclaredValue: value}
+	}
+
+	tests := []struct {
+		name      string
+		pkg       models.Package
+		dropoff   models.Location
+		currency  models.Currency
+		want      []string // violation codes
+		wantItems []int
+		wantAge   int
+	}{
+		{
+			name:     "uncategorised package",
+			pkg:      models.Package{Description: "box"},
+			dropoff:  lagos,
+			currency: models.CurrencyNGN,
+		},
+		{
+			name:     "ordinary contents",
+			pkg:      models.Package{Contents: []models.PackageItem{item("clothing", 5000), item(models.PackageCategoryDocuments, 0)}},
+			dropoff:  lagos,
+			currency: models.CurrencyNGN,
+		},
+		{
+			name:      "weapons on the package itself",
+			pkg:       models.Package{Category: models.PackageCategoryWeapons},
+			dropoff:   lagos,
+			currency:  models.CurrencyNGN,
+			want:      []string{restrictionProhibitedItem},
+			wantItems: []int{-1},
+		},
+		{
+			name:      "hazardous item among others",
+			pkg:       models.Package{Contents: []models.PackageItem{item(models.PackageCategoryFood, 100), item(models.PackageCategoryHazardous, 10)}},
+			dropoff:   lagos,
+			currency:  models.CurrencyNGN,
+			want:      []string{restrictionProhibitedItem},
+			wantItems: []int{1},
+		},
+		{
+			name:      "unknown category",
+			pkg:       models.Package{Contents: []models.PackageItem{item("PETS", 0)}},
+			dropoff:   lagos,
+			currency:  models.CurrencyNGN,
+			want:      []string{restrictionUnknownCategory},
+			wantItems: []int{0},
+		},
+		{
+			name:      "negative item value",
+			pkg:       models.Package{Value: 100, Contents: []models.PackageItem{item(models.PackageCategoryFood, -5)}},
+			dropoff:   lagos,
+			currency:  models.CurrencyNGN,
+			want:      []string{restrictionInvalidValue},
+			wantItems: []int{0},
+		},
+		{
+			name:     "cash at the limit",
+			pkg:      models.Package{Contents: []models.PackageItem{item(models.PackageCategoryCash, 60000), item(models.PackageCategoryCash, 40000)}},
+			dropoff:  lagos,
+			currency: models.CurrencyNGN,
+		},
+		{
+			name:      "cash over the limit across items",
+			pkg:       models.Package{Contents: []models.PackageItem{item(models.PackageCategoryFood, 10), item(models.PackageCategoryCash, 60000), item(models.PackageCategoryCash, 40001)}},
+			dropoff:   lagos,
+			currency:  models.CurrencyNGN,
+			want:      []string{restrictionCashOverLimit},
+			wantItems: []int{1},
+		},
+		{
+			name:      "cash in an unlisted currency",
+			pkg:       models.Package{Category: models.PackageCategoryCash, Value: 10},
+			dropoff:   models.Location{Country: "RW"},
+			currency:  models.Currency("RWF"),
+			want:      []string{restrictionCashNotPermitted},
+			wantItems: []int{-1},
+		},
+		{
+			name:     "alcohol in Lagos",
+			pkg:      models.Package{Contents: []models.PackageItem{item(models.PackageCategoryAlcohol, 15000)}},
+			dropoff:  lagos,
+			currency: models.CurrencyNGN,
+			wantAge:  18,
+		},
+		{
+			name:      "alcohol in a state that bans it",
+			pkg:       models.Package{Contents: []models.PackageItem{item(models.PackageCategoryFood, 10), item(models.PackageCategoryAlcohol, 15000)}},
+			dropoff:   kano,
+			currency:  models.CurrencyNGN,
+			want:      []string{restrictionAlcoholNotPermitted},
+			wantItems: []int{1},
+		},
+		{
+			name:     "alcohol with the country taken from the currency",
+			pkg:      models.Package{Category: models.PackageCategoryAlcohol},
+			dropoff:  models.Location{City: "Nairobi"},
+			currency: models.CurrencyKES,
+			wantAge:  18,
+		},
+		{
+			name:      "alcohol to an unlisted country",
+			pkg:       models.Package{Category: models.PackageCategoryAlcohol},
+			dropoff:   models.Location{Country: "Sudan"},
+			currency:  models.Currency("SDG"),
+			want:      []string{restrictionAlcoholNotPermitted},
+			wantItems: []int{-1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := tt.pkg
+			violations := checkRestrictedItems(&pkg, tt.dropoff, tt.currency)
+
+			var codes []string
+			var items []int
+			for _, v := range violations {
+				codes = append(codes, v.Code)
+				items = append(items, v.Item)
+			}
+			if !reflect.DeepEqual(codes, tt.want) || !reflect.DeepEqual(items, tt.wantItems) {
+				t.Fatalf("violations = %v on items %v, want %v on %v", codes, items, tt.want, tt.wantItems)
+			}
+			if pkg.MinRecipientAge != tt.wantAge {
+				t.Errorf("MinRecipientAge = %d, want %d", pkg.MinRecipientAge, tt.wantAge)
+			}
+			if pkg.RequiresPOD != (tt.wantAge > 0) {
+				t.Errorf("RequiresPOD = %v, want %v", pkg.RequiresPOD, tt.wantAge > 0)
+			}
+		})
+	}
+}
+
+func TestCheckRestrictedItemsKeepsHigherAge(t *testing.T) {
+	pkg := models.Package{Category: models.PackageCategoryAlcohol, MinRecipientAge: 21}
+	if v := checkRestrictedItems(&pkg, models.Location{Country: "GH"}, models.CurrencyGHS); v != nil {
+		t.Fatalf("violations = %+v, want none", v)
+	}
+	if pkg.MinRecipientAge != 21 {
+		t.Errorf("MinRecipientAge = %d, want the sender's 21 kept", pkg.MinRecipientAge)
+	}
+}
+
+func TestNormalizePackage(t *testing.T) {
+	pkg := models.Package{
+		Category: " food ",
+		Contents: []models.PackageItem{
+			{Category: "electronics ", DeclaredValue: 300, Fragile: true},
+			{Category: "Food", DeclaredValue: 20, Quantity: 3, Perishable: true},
+		},
+	}
+	normalizePackage(&pkg)
+
+	if pkg.Category != models.PackageCategoryFood {
+		t.Errorf("Category = %q, want FOOD", pkg.Category)
+	}
+	if pkg.Contents[0].Category != models.PackageCategoryElectronics || pkg.Contents[0].Quantity != 1 {
+		t.Errorf("first item = %+v, want ELECTRONICS with quantity 1", pkg.Contents[0])
+	}
+	if pkg.Contents[1].Quantity != 3 {
+		t.Errorf("second item quantity = %d, want 3", pkg.Contents[1].Quantity)
+	}
+	if pkg.Value != 320 || !pkg.Fragile || !pkg.Perishable {
+		t.Errorf("package = value %v fragile %v perishable %v, want 320 rolled up from the items", pkg.Value, pkg.Fragile, pkg.Perishable)
+	}
+
+	// A declared total is kept rather than replaced by the items' sum
+	declared := models.Package{Value: 500, Contents: []models.PackageItem{{Category: "FOOD", DeclaredValue: 20}}}
+	normalizePackage(&declared)
+	if declared.Value != 500 {
+		t.Errorf("Value = %v, want the declared 500", declared.Value)
+	}
+}
+
+func TestCountryCode(t *testing.T) {
+	tests := []struct {
+		country  string
+		currency models.Currency
+		want     string
+	}{
+		{"Nigeria", models.CurrencyNGN, "NG"},
+		{" côte d'ivoire ", models.CurrencyXOF, "CI"},
+		{"ke", models.CurrencyKES, "KE"},
+		{"", models.CurrencyZAR, "ZA"},
+		{"", models.CurrencyXOF, ""},
+		{"Sudan", models.CurrencyNGN, "SUDAN"},
+	}
+	for _, tt := range tests {
+		if got := countryCode(tt.country, tt.currency); got != tt.want {
+			t.Errorf("countryCode(%q, %s) = %q, want %q", tt.country, tt.currency, got, tt.want)
+		}
+	}
+}
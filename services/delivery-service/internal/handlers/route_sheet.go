@@ -0,0 +1,248 @@
+/*
+ * Courier Route Sheet
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+// routeSpeedKmh is the average city speed stop ETAs are estimated at, as
+// for delivery estimates
+const routeSpeedKmh = 20.0
+
+type routeStop struct {
+	Sequence       int                `json:"sequence"`
+	Kind           string             `json:"kind"`   // PICKUP or DROPOFF
+	Status         string             `json:"status"` // NEXT or PENDING
+	DeliveryID     string             `json:"deliveryId"`
+	TrackingNumber string             `json:"trackingNumber"`
+	DeliveryType   string             `json:"deliveryType"`
+	Location       models.Location    `json:"location"`
+	Contact        models.ContactInfo `json:"contact"`
+	Instructions   *string            `json:"instructions,omitempty"`
+	Package        models.Package     `json:"package"`
+	NotBefore      *time.Time         `json:"notBefore,omitempty"`
+	DistanceKm     float64            `json:"distanceKm"` // from the previous stop
+	EtaMinutes     int                `json:"etaMinutes"` // from now
+	Navigation     map[string]string  `json:"navigation"`
+	Actions        []routeStopAction  `json:"actions"`
+}
+
+// routeStopAction is a status change the courier can make at a stop with
+// one tap, and what the request must carry
+type routeStopAction struct {
+	Action   string   `json:"action"`
+	Method   string   `json:"method"`
+	Path     string   `json:"path"`
+	Requires []string `json:"requires,omitempty"`
+}
+
+// GetRouteSheet returns the courier's remaining stops across all their
+// deliveries, in the order to visit them. The sheet is built from the
+// deliveries' current state on every call, so a stop that fails or is
+// rescheduled drops out and the rest are re-sequenced.
+func (h *Handler) GetRouteSheet(w http.ResponseWriter, r *http.Request) {
+	driverID := middleware.GetUserID(r.Context())
+
+	rows, err := h.db.Pool.Query(r.Context(), `
+		SELECT id, tracking_number, type, status, regulated,
+			pickup_location, dropoff_location, pickup_contact, dropoff_contact,
+			package, scheduled_pickup_time, pickup_instructions, delivery_instructions
+		FROM deliveries
+		WHERE driver_id = $1 AND status IN ('DRIVER_ASSIGNED', 'PICKED_UP', 'IN_TRANSIT')
+		ORDER BY driver_assigned_at ASC`,
+		driverID,
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch route sheet")
+		return
+	}
+	defer rows.Close()
+
+	var stops []*routeStop
+	for rows.Next() {
+		var id, trackingNumber, deliveryType, status string
+		var regulated bool
+		var pickup, dropoff models.Location
+		var sealedPickupContact, sealedDropoffContact []byte
+		var pkg models.Package
+		var scheduledPickup *time.Time
+		var pickupInstructions, deliveryInstructions *string
+		if err := rows.Scan(
+			&id, &trackingNumber, &deliveryType, &status, &regulated,
+			&pickup, &dropoff, &sealedPickupContact, &sealedDropoffContact,
+			&pkg, &scheduledPickup, &pickupInstructions, &deliveryInstructions,
+		); err != nil {
+			respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch route sheet")
+			return
+		}
+		pickupContact, err := h.openContact(r.Context(), sealedPickupContact)
+		if err != nil {
+			log.Error().Err(err).Str("delivery_id", id).Msg("Failed to open pickup contact")
+			respondError(w, http.StatusInternalServerError, "ENCRYPTION_ERROR", "Failed to fetch route sheet")
+			return
+		}
+		dropoffContact, err := h.openContact(r.Context(), sealedDropoffContact)
+		if err != nil {
+			log.Error().Err(err).Str("delivery_id", id).Msg("Failed to open dropoff contact")
+			respondError(w, http.StatusInternalServerError, "ENCRYPTION_ERROR", "Failed to fetch route sheet")
+			return
+		}
+
+		perishable := deliveryType == string(models.DeliveryTypePerishable)
+		if status == "DRIVER_ASSIGNED" {
+			stop := &routeStop{
+				Kind: "PICKUP", DeliveryID: id, TrackingNumber: trackingNumber, DeliveryType: deliveryType,
+				Location: pickup, Contact: pickupContact, Instructions: pickupInstructions, Package: pkg,
+				Actions: pickupActions(id, perishable),
+			}
+			if scheduledPickup != nil && scheduledPickup.After(time.Now()) {
+				stop.NotBefore = scheduledPickup
+			}
+			stops = append(stops, stop)
+		}
+		stops = append(stops, &routeStop{
+			Kind: "DROPOFF", DeliveryID: id, TrackingNumber: trackingNumber, DeliveryType: deliveryType,
+			Location: dropoff, Contact: dropoffContact, Instructions: deliveryInstructions, Package: pkg,
+			Actions: dropoffActions(id, perishable, regulated, pkg.RequiresPOD),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch route sheet")
+		return
+	}
+
+	// Start from where the courier is, or the first stop if we don't know
+	var startLat, startLng float64
+	var driverLoc models.DriverLocation
+	if err := h.rdb.GetJSON(r.Context(), "driver:location:"+driverID, &driverLoc); err == nil {
+		startLat, startLng = driverLoc.Latitude, driverLoc.Longitude
+	} else if len(stops) > 0 {
+		startLat, startLng = stops[0].Location.Latitude, stops[0].Location.Longitude
+	}
+
+	ordered := sequenceStops(startLat, startLng, stops)
+	for _, stop := range ordered {
+		stop.Navigation = navigationLinks(stop.Location)
+	}
+
+	respond(w, http.StatusOK, map[string]interface{}{
+		"stops":       ordered,
+		"generatedAt": time.Now(),
+	})
+}
+
+// sequenceStops orders stops nearest-first from the start, never visiting
+// a delivery's dropoff before its pickup and leaving stops that may not be
+// visited yet until the others are done. It sets each stop's sequence,
+// leg distance, ETA and status.
+func sequenceStops(lat, lng float64, stops []*routeStop) []*routeStop {
+	ordered := make([]*routeStop, 0, len(stops))
+	visited := make([]bool, len(stops))
+	pickedUp := map[string]bool{}
+	for _, s := range stops {
+		if s.Kind == "PICKUP" {
+			pickedUp[s.DeliveryID] = false
+		}
+	}
+
+	now := time.Now()
+	elapsed := 0.0 // minutes
+	for len(ordered) < len(stops) {
+		best, bestDist, bestWaiting := -1, math.MaxFloat64, true
+		for i, s := range stops {
+			if visited[i] {
+				continue
+			}
+			if done, ok := pickedUp[s.DeliveryID]; s.Kind == "DROPOFF" && ok && !done {
+				continue
+			}
+			dist := haversineDistance(lat, lng, s.Location.Latitude, s.Location.Longitude)
+			arrival := now.Add(time.Duration((elapsed + dist/routeSpeedKmh*60) * float64(time.Minute)))
+			waiting := s.NotBefore != nil && arrival.Before(*s.NotBefore)
+			// Stops ready on arrival beat stops that would have to wait
+			if best < 0 || (bestWaiting && !waiting) || (waiting == bestWaiting && dist < bestDist) {
+				best, bestDist, bestWaiting = i, dist, waiting
+			}
+		}
+
+		s := stops[best]
+		visited[best] = true
+		if s.Kind == "PICKUP" {
+			pickedUp[s.DeliveryID] = true
+		}
+
+		elapsed += bestDist / routeSpeedKmh * 60
+		if s.NotBefore != nil {
+			if wait := s.NotBefore.Sub(now).Minutes(); wait > elapsed {
+				elapsed = wait
+			}
+		}
+		s.Sequence = len(ordered) + 1
+		s.DistanceKm = math.Round(bestDist*10) / 10
+		s.EtaMinutes = int(math.Ceil(elapsed))
+		s.Status = "PENDING"
+		if s.Sequence == 1 {
+			s.Status = "NEXT"
+		}
+		ordered = append(ordered, s)
+		lat, lng = s.Location.Latitude, s.Location.Longitude
+	}
+	return ordered
+}
+
+func pickupActions(deliveryID string, perishable bool) []routeStopAction {
+	confirm := routeStopAction{
+		Action: "CONFIRM_PICKUP", Method: http.MethodPost,
+		Path: "/api/v1/driver/deliveries/" + deliveryID + "/pickup",
+	}
+	if perishable {
+		confirm.Requires = []string{"temperatureC"}
+	}
+	return []routeStopAction{confirm}
+}
+
+func dropoffActions(deliveryID string, perishable, regulated, requiresPOD bool) []routeStopAction {
+	base := "/api/v1/driver/deliveries/" + deliveryID
+	confirm := routeStopAction{Action: "CONFIRM_DELIVERY", Method: http.MethodPost, Path: base + "/deliver"}
+	if requiresPOD {
+		confirm.Requires = append(confirm.Requires, "signature|photo")
+	}
+	if perishable {
+		confirm.Requires = append(confirm.Requires, "temperatureC")
+	}
+	if regulated {
+		confirm.Requires = append(confirm.Requires, "recipientId")
+	}
+	return []routeStopAction{
+		confirm,
+		{Action: "FAIL_DELIVERY", Method: http.MethodPost, Path: base + "/fail", Requires: []string{"reason"}},
+		{Action: "MESSAGE_CUSTOMER", Method: http.MethodPost, Path: base + "/messages", Requires: []string{"quickReply"}},
+	}
+}
+
+// navigationLinks are deep links that open turn-by-turn directions to a
+// stop in the courier's navigation app
+func navigationLinks(loc models.Location) map[string]string {
+	ll := fmt.Sprintf("%.6f,%.6f", loc.Latitude, loc.Longitude)
+	label := loc.Address
+	if label == "" {
+		label = "Stop"
+	}
+	return map[string]string{
+		"googleMaps": "https://www.google.com/maps/dir/?api=1&travelmode=two-wheeler&destination=" + url.QueryEscape(ll),
+		"waze":       "https://waze.com/ul?navigate=yes&ll=" + url.QueryEscape(ll),
+		"geo":        "geo:" + ll + "?q=" + url.QueryEscape(ll+"("+label+")"),
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+// stopAt is a point north of the start; 0.01 degrees is about 1.1km
+func stopAt(lat float64) models.Location {
+	return models.Location{Latitude: lat, Longitude: 3.4}
+}
+
+func stopOrder(stops []*routeStop) []string {
+	order := make([]string, len(stops))
+	for i, s := range stops {
+		order[i] = s.DeliveryID + " " + s.Kind
+	}
+	return order
+}
+
+func TestSequenceStops(t *testing.T) {
+	stops := []*routeStop{
+		{Kind: "PICKUP", DeliveryID: "a", Location: stopAt(6.53)},
+		{Kind: "DROPOFF", DeliveryID: "a", Location: stopAt(6.51)},
+		{Kind: "DROPOFF", DeliveryID: "b", Location: stopAt(6.52)}, // already picked up
+	}
+
+	ordered := sequenceStops(6.50, 3.4, stops)
+
+	// a's dropoff is nearest but has to wait for its pickup
+	want := []string{"b DROPOFF", "a PICKUP", "a DROPOFF"}
+	if got := stopOrder(ordered); !reflect.DeepEqual(got, want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+	for i, s := range ordered {
+		if s.Sequence != i+1 {
+			t.Errorf("stop %d sequence = %d", i, s.Sequence)
+		}
+		wantStatus := "PENDING"
+		if i == 0 {
+			wantStatus = "NEXT"
+		}
+		if s.Status != wantStatus {
+			t.Errorf("stop %d status = %s, want %s", i, s.Status, wantStatus)
+		}
+		if i > 0 && s.EtaMinutes < ordered[i-1].EtaMinutes {
+			t.Errorf("stop %d ETA %d before the previous stop's %d", i, s.EtaMinutes, ordered[i-1].EtaMinutes)
+		}
+	}
+	if got := []float64{ordered[0].DistanceKm, ordered[1].DistanceKm, ordered[2].DistanceKm}; !reflect.DeepEqual(got, []float64{2.2, 1.1, 2.2}) {
+		t.Errorf("leg distances = %v, want [2.2 1.1 2.2]", got)
+	}
+	// 2.2km at 20km/h
+	if ordered[0].EtaMinutes != 7 {
+		t.Errorf("first ETA = %d, want 7", ordered[0].EtaMinutes)
+	}
+}
+
+func TestSequenceStopsLeavesEarlyStopsForLater(t *testing.T) {
+	later := time.Now().Add(2 * time.Hour)
+	stops := []*routeStop{
+		{Kind: "PICKUP", DeliveryID: "scheduled", Location: stopAt(6.51), NotBefore: &later},
+		{Kind: "PICKUP", DeliveryID: "ready", Location: stopAt(6.54)},
+	}
+
+	ordered := sequenceStops(6.50, 3.4, stops)
+
+	want := []string{"ready PICKUP", "scheduled PICKUP"}
+	if got := stopOrder(ordered); !reflect.DeepEqual(got, want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+	if ordered[1].EtaMinutes < 119 {
+		t.Errorf("scheduled pickup ETA = %d minutes, want no earlier than it opens", ordered[1].EtaMinutes)
+	}
+}
+
+func TestSequenceStopsEmpty(t *testing.T) {
+	if got := sequenceStops(6.5, 3.4, nil); len(got) != 0 {
+		t.Errorf("sequenceStops(nil) = %v, want none", got)
+	}
+}
+
+func TestDropoffActions(t *testing.T) {
+	tests := []struct {
+		name                               string
+		perishable, regulated, requiresPOD bool
+		want                               []string
+	}{
+		{name: "plain"},
+		{name: "proof of delivery", requiresPOD: true, want: []string{"signature|photo"}},
+		{name: "perishable", perishable: true, want: []string{"temperatureC"}},
+		{name: "regulated", regulated: true, requiresPOD: true, want: []string{"signature|photo", "recipientId"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := dropoffActions("d-1", tt.perishable, tt.regulated, tt.requiresPOD)
+			if len(actions) != 3 || actions[0].Action != "CONFIRM_DELIVERY" {
+				t.Fatalf("actions = %+v, want confirm, fail and message", actions)
+			}
+			if actions[0].Path != "/api/v1/driver/deliveries/d-1/deliver" {
+				t.Errorf("confirm path = %s", actions[0].Path)
+			}
+			if !reflect.DeepEqual(actions[0].Requires, tt.want) {
+				t.Errorf("confirm requires %v, want %v", actions[0].Requires, tt.want)
+			}
+		})
+	}
+
+	if got := pickupActions("d-1", true)[0].Requires; !reflect.DeepEqual(got, []string{"temperatureC"}) {
+		t.Errorf("perishable pickup requires %v, want [temperatureC]", got)
+	}
+}
+
+func TestNavigationLinks(t *testing.T) {
+	links := navigationLinks(models.Location{Latitude: 6.4281, Longitude: 3.4219, Address: "Eko Hotel & Suites"})
+
+	if want := "https://waze.com/ul?navigate=yes&ll=6.428100%2C3.421900"; links["waze"] != want {
+		t.Errorf("waze = %s, want %s", links["waze"], want)
+	}
+	if !strings.HasSuffix(links["googleMaps"], "&destination=6.428100%2C3.421900") {
+		t.Errorf("googleMaps = %s", links["googleMaps"])
+	}
+	// The address is escaped so it cannot break out of the query
+	if want := "geo:6.428100,3.421900?q=6.428100%2C3.421900%28Eko+Hotel+%26+Suites%29"; links["geo"] != want {
+		t.Errorf("geo = %s, want %s", links["geo"], want)
+	}
+}
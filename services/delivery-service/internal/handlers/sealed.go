@@ -0,0 +1,50 @@
+/*
+ * Sealed Delivery Data
+ */
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+// resealBatchSize is the most values per column re-sealed per run
+const resealBatchSize = 500
+
+// openDeliveryContacts opens sealed contact JSON in place
+func (h *Handler) openDeliveryContacts(ctx context.Context, contacts ...*json.RawMessage) error {
+	for _, contact := range contacts {
+		plain, err := h.db.OpenJSON(ctx, *contact)
+		if err != nil {
+			return err
+		}
+		*contact = plain
+	}
+	return nil
+}
+
+// openContact opens and decodes a sealed contact
+func (h *Handler) openContact(ctx context.Context, raw []byte) (models.ContactInfo, error) {
+	var contact models.ContactInfo
+	plain, err := h.db.OpenJSON(ctx, raw)
+	if err != nil || len(plain) == 0 {
+		return contact, err
+	}
+	err = json.Unmarshal(plain, &contact)
+	return contact, err
+}
+
+// resealSensitiveData re-seals delivery data left in the clear or sealed
+// under a master key that has since been rotated
+func (h *Handler) resealSensitiveData(ctx context.Context) error {
+	n, err := h.db.ResealStale(ctx, resealBatchSize)
+	if n > 0 {
+		log.Info().Int("values", n).Msg("Re-sealed sensitive delivery data")
+	}
+	return err
+}
@@ -0,0 +1,326 @@
+/*
+ * Delivery Search
+ */
+
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+)
+
+const (
+	// defaultSearchLimit and maxSearchLimit bound a page of search results
+	defaultSearchLimit = 50
+	maxSearchLimit     = 100
+
+	// contactIndexBatchSize is the most deliveries whose contact names are
+	// indexed per job run
+	contactIndexBatchSize = 500
+)
+
+// searchSort is an order search results can be listed in. Each orders by a
+// column and then by ID, which the cursor resumes from.
+type searchSort struct {
+	column string // cast to text for the cursor
+	desc   bool
+}
+
+var searchSorts = map[string]searchSort{
+	"newest":    {column: "d.created_at", desc: true},
+	"oldest":    {column: "d.created_at"},
+	"fare_high": {column: "d.total_fare", desc: true},
+	"fare_low":  {column: "d.total_fare"},
+}
+
+// searchCursor is where the next page of results starts: the sort value
+// and ID of the last delivery on the previous page
+type searchCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func (c searchCursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeSearchCursor(s string) (searchCursor, error) {
+	var c searchCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(raw, &c)
+	}
+	if err == nil && (c.Value == "" || c.ID == "") {
+		err = fmt.Errorf("incomplete cursor")
+	}
+	return c, err
+}
+
+// deliverySearchResult is a delivery as search results list it
+type deliverySearchResult struct {
+	ID             string     `json:"id"`
+	TrackingNumber string     `json:"trackingNumber"`
+	Type           string     `json:"type"`
+	Status         string     `json:"status"`
+	PaymentStatus  string     `json:"paymentStatus"`
+	TotalFare      float64    `json:"totalFare"`
+	Currency       string     `json:"currency"`
+	CustomerID     string     `json:"customerId"`
+	DriverID       *string    `json:"driverId,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// SearchDeliveries finds deliveries by date range, tracking number, dropoff
+// city or zone, courier, status, payment status and contact name, a page at
+// a time. Customers search their own deliveries and couriers those they
+// carried; support, and partners within their own deliveries, search all.
+// Names match whole words, since contacts are sealed and only keyed hashes
+// of their words are indexed.
+// GET /api/v1/deliveries/search?from=&to=&trackingNumber=&dropoffCity=&zoneId=
+// &driverId=&status=&paymentStatus=&q=&sort=&cursor=&limit=
+func (h *Handler) SearchDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, values ...interface{}) {
+		for _, v := range values {
+			args = append(args, v)
+			condition = strings.Replace(condition, "?", "$"+strconv.Itoa(len(args)), 1)
+		}
+		conditions = append(conditions, condition)
+	}
+
+	userID := middleware.GetUserID(ctx)
+	switch role := middleware.GetUserRole(ctx); {
+	case middleware.GetPartnerID(ctx) != "", role == "SUPPORT", role == "SUPPORT_LEAD", role == "ADMIN":
+		// Row-level security keeps partners to their own deliveries
+	case role == "DRIVER":
+		where("d.driver_id = ?", userID)
+	default:
+		where("d.customer_id = ?", userID)
+	}
+
+	for _, param := range []string{"from", "to"} {
+		v := query.Get(param)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", param+" must be an RFC3339 timestamp")
+			return
+		}
+		if param == "from" {
+			where("d.created_at >= ?", t)
+		} else {
+			where("d.created_at < ?", t)
+		}
+	}
+
+	if v := strings.TrimSpace(query.Get("trackingNumber")); v != "" {
+		where("d.tracking_number = ?", strings.ToUpper(v))
+	}
+	if v := query.Get("driverId"); v != "" {
+		where("d.driver_id = ?", v)
+	}
+	if v := query.Get("status"); v != "" {
+		where("d.status = ?", strings.ToUpper(v))
+	}
+	if v := query.Get("paymentStatus"); v != "" {
+		where("d.payment_status = ?", strings.ToUpper(v))
+	}
+
+	// Zones carry no boundaries of their own, so a zone matches deliveries
+	// dropped off anywhere in its city
+	city := strings.TrimSpace(query.Get("dropoffCity"))
+	if zoneID := query.Get("zoneId"); zoneID != "" {
+		var zoneCity string
+		err := h.db.Pool.QueryRow(ctx, `SELECT city FROM delivery_zones WHERE id = $1`, zoneID).Scan(&zoneCity)
+		if err != nil {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "Zone not found")
+			return
+		}
+		if city != "" && !strings.EqualFold(city, zoneCity) {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "zoneId is not in dropoffCity")
+			return
+		}
+		city = zoneCity
+	}
+	if city != "" {
+		where("LOWER(d.dropoff_location->>'city') = ?", strings.ToLower(city))
+	}
+
+	if q := query.Get("q"); q != "" {
+		tokens := h.db.NameTokens(q)
+		if len(tokens) == 0 {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "q must contain a name")
+			return
+		}
+		where("d.contact_name_tokens @> ?", tokens)
+	}
+
+	sortName := query.Get("sort")
+	if sortName == "" {
+		sortName = "newest"
+	}
+	sort, ok := searchSorts[sortName]
+	if !ok {
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "sort must be newest, oldest, fare_high or fare_low")
+		return
+	}
+	direction, after := "ASC", ">"
+	if sort.desc {
+		direction, after = "DESC", "<"
+	}
+
+	limit := defaultSearchLimit
+	if v := query.Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "limit must be between 1 and 100")
+			return
+		}
+		limit = parsed
+	}
+
+	if v := query.Get("cursor"); v != "" {
+		cursor, err := decodeSearchCursor(v)
+		if err != nil || cursor.Sort != sortName {
+			respondError(w, http.StatusBadRequest, "INVALID_CURSOR", "cursor is invalid or from another sort")
+			return
+		}
+		cast := "timestamptz"
+		if sort.column == "d.total_fare" {
+			cast = "numeric"
+		}
+		where(fmt.Sprintf("(%s, d.id) %s (?::%s, ?)", sort.column, after, cast), cursor.Value, cursor.ID)
+	}
+
+	sql := `
+		SELECT d.id, d.tracking_number, d.type::text, d.status::text, COALESCE(d.payment_status::text, ''),
+			d.total_fare::float8, d.currency::text, d.customer_id::text, d.driver_id::text,
+			d.created_at, d.delivered_at, ` + sort.column + `::text
+		FROM deliveries d`
+	if len(conditions) > 0 {
+		sql += "\n\t\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	sql += fmt.Sprintf("\n\t\tORDER BY %[1]s %[2]s, d.id %[2]s\n\t\tLIMIT %[3]d", sort.column, direction, limit+1)
+
+	rows, err := h.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to search deliveries")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to search deliveries")
+		return
+	}
+	defer rows.Close()
+
+	// One row past the page tells there is another page
+	results := make([]deliverySearchResult, 0, limit)
+	var next searchCursor
+	hasMore := false
+	for rows.Next() {
+		var d deliverySearchResult
+		var sortValue string
+		err := rows.Scan(
+			&d.ID, &d.TrackingNumber, &d.Type, &d.Status, &d.PaymentStatus,
+			&d.TotalFare, &d.Currency, &d.CustomerID, &d.DriverID,
+			&d.CreatedAt, &d.DeliveredAt, &sortValue,
+		)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to read delivery search results")
+			respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to search deliveries")
+			return
+		}
+		if len(results) == limit {
+			hasMore = true
+			break
+		}
+		results = append(results, d)
+		next = searchCursor{Sort: sortName, Value: sortValue, ID: d.ID}
+	}
+	if err := rows.Err(); err != nil {
+		log.Error().Err(err).Msg("Failed to read delivery search results")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to search deliveries")
+		return
+	}
+
+	meta := map[string]interface{}{
+		"limit":   limit,
+		"sort":    sortName,
+		"hasMore": hasMore,
+	}
+	if hasMore {
+		meta["nextCursor"] = next.encode()
+	}
+
+	respondWithMeta(w, http.StatusOK, results, meta)
+}
+
+// indexContactNames indexes the contact names of deliveries created before
+// the index existed, so older deliveries can be found by name too
+func (h *Handler) indexContactNames(ctx context.Context) error {
+	rows, err := h.db.Pool.Query(ctx, `
+		SELECT id, pickup_contact, dropoff_contact FROM deliveries
+		WHERE contact_name_tokens IS NULL
+		ORDER BY created_at
+		LIMIT $1`,
+		contactIndexBatchSize,
+	)
+	if err != nil {
+		return err
+	}
+	type unindexed struct {
+		id              string
+		pickup, dropoff []byte
+	}
+	var deliveries []unindexed
+	for rows.Next() {
+		var d unindexed
+		if err := rows.Scan(&d.id, &d.pickup, &d.dropoff); err != nil {
+			rows.Close()
+			return err
+		}
+		deliveries = append(deliveries, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, d := range deliveries {
+		pickup, err := h.openContact(ctx, d.pickup)
+		if err != nil {
+			return fmt.Errorf("delivery %s: %w", d.id, err)
+		}
+		dropoff, err := h.openContact(ctx, d.dropoff)
+		if err != nil {
+			return fmt.Errorf("delivery %s: %w", d.id, err)
+		}
+		if _, err := h.db.Pool.Exec(ctx,
+			"UPDATE deliveries SET contact_name_tokens = $2 WHERE id = $1 AND contact_name_tokens IS NULL",
+			d.id, h.db.NameTokens(pickup.Name, dropoff.Name),
+		); err != nil {
+			return err
+		}
+	}
+
+	if len(deliveries) > 0 {
+		log.Info().Int("deliveries", len(deliveries)).Msg("Indexed delivery contact names")
+	}
+	return nil
+}
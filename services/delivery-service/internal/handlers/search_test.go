@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/database"
+)
+
+func TestSearchCursor(t *testing.T) {
+	cursor := searchCursor{Sort: "fare_high", Value: "2500.00", ID: "d-1"}
+	got, err := decodeSearchCursor(cursor.encode())
+	if err != nil || got != cursor {
+		t.Errorf("decodeSearchCursor(encode()) = %+v, %v, want %+v", got, err, cursor)
+	}
+
+	for name, s := range map[string]string{
+		"not base64":   "not a cursor!",
+		"not JSON":     searchCursorFrom(`{`),
+		"no value":     searchCursorFrom(`{"s":"newest","id":"d-1"}`),
+		"no ID":        searchCursorFrom(`{"s":"newest","v":"2026-10-18 09:00:00+00"}`),
+		"empty string": "",
+	} {
+		if _, err := decodeSearchCursor(s); err == nil {
+			t.Errorf("%s: decodeSearchCursor() error = nil, want an error", name)
+		}
+	}
+}
+
+// searchCursorFrom encodes raw as a cursor is encoded
+func searchCursorFrom(raw string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// These searches are refused before the database is reached
+func TestSearchDeliveriesValidation(t *testing.T) {
+	db := &database.DB{}
+	db.SetSearchKey([]byte("search-key"))
+	h := &Handler{db: db}
+	newestCursor := searchCursor{Sort: "newest", Value: "2026-10-18 09:00:00+00", ID: "d-1"}.encode()
+
+	tests := []struct {
+		name     string
+		query    url.Values
+		wantCode string
+	}{
+		{"bad from", url.Values{"from": {"2026-10-18"}}, "VALIDATION_ERROR"},
+		{"bad to", url.Values{"to": {"yesterday"}}, "VALIDATION_ERROR"},
+		{"name without a word", url.Values{"q": {" -- "}}, "VALIDATION_ERROR"},
+		{"unknown sort", url.Values{"sort": {"distance"}}, "VALIDATION_ERROR"},
+		{"limit too low", url.Values{"limit": {"0"}}, "VALIDATION_ERROR"},
+		{"limit too high", url.Values{"limit": {"101"}}, "VALIDATION_ERROR"},
+		{"limit not a number", url.Values{"limit": {"ten"}}, "VALIDATION_ERROR"},
+		{"garbled cursor", url.Values{"cursor": {"abc"}}, "INVALID_CURSOR"},
+		{"cursor from another sort", url.Values{"sort": {"fare_low"}, "cursor": {newestCursor}}, "INVALID_CURSOR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.SearchDeliveries(rec, httptest.NewRequest(http.MethodGet, "/api/v1/deliveries/search?"+tt.query.Encode(), nil))
+
+			var resp response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != http.StatusBadRequest || resp.Error == nil || resp.Error.Code != tt.wantCode {
+				t.Errorf("status = %d, error = %+v, want 400 %s", rec.Code, resp.Error, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,730 @@
+/*
+ * Courier Shift Slot Handlers
+ */
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+)
+
+// Shift slot rules
+const (
+	shiftMinLength         = 30 * time.Minute
+	shiftMaxLength         = 6 * time.Hour
+	shiftDefaultRadiusKm   = 3.0
+	shiftAttendanceMinPct  = 0.8              // share of the slot a courier must be online in the zone
+	shiftSettlementGrace   = 10 * time.Minute // wait after a slot ends for late location updates
+	shiftSettlementBatches = 50
+)
+
+type shiftSlot struct {
+	ID              string     `json:"id"`
+	ZoneID          string     `json:"zoneId"`
+	City            string     `json:"city"`
+	StartsAt        time.Time  `json:"startsAt"`
+	EndsAt          time.Time  `json:"endsAt"`
+	Capacity        int        `json:"capacity"`
+	Booked          int        `json:"booked"`
+	GuaranteedMin   float64    `json:"guaranteedMin"`
+	Currency        string     `json:"currency"`
+	CenterLatitude  float64    `json:"centerLatitude"`
+	CenterLongitude float64    `json:"centerLongitude"`
+	RadiusKm        float64    `json:"radiusKm"`
+	Status          string     `json:"status"`
+	CreatedBy       string     `json:"createdBy"`
+	SettledAt       *time.Time `json:"settledAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+type shiftBooking struct {
+	ID            string     `json:"id"`
+	SlotID        string     `json:"slotId"`
+	DriverID      string     `json:"driverId"`
+	Status        string     `json:"status"`
+	OnlineMinutes int        `json:"onlineMinutes"`
+	InZoneMinutes int        `json:"inZoneMinutes"`
+	Earnings      float64    `json:"earnings"`
+	TopUp         float64    `json:"topUp"`
+	SettledAt     *time.Time `json:"settledAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	Slot          *shiftSlot `json:"slot,omitempty"`
+}
+
+const shiftSlotColumns = `s.id, s.zone_id, s.city, s.starts_at, s.ends_at, s.capacity,
+	(SELECT COUNT(*) FROM courier_shift_bookings b WHERE b.slot_id = s.id AND b.status != 'CANCELLED'),
+	s.guaranteed_min, s.currency, s.center_latitude, s.center_longitude, s.radius_km,
+	s.status, s.created_by, s.settled_at, s.created_at`
+
+const shiftBookingColumns = `b.id, b.slot_id, b.driver_id, b.status, b.online_minutes, b.in_zone_minutes,
+	b.earnings, b.top_up, b.settled_at, b.created_at`
+
+func scanShiftSlot(row pgx.Row) (*shiftSlot, error) {
+	var s shiftSlot
+	err := row.Scan(
+		&s.ID, &s.ZoneID, &s.City, &s.StartsAt, &s.EndsAt, &s.Capacity,
+		&s.Booked, &s.GuaranteedMin, &s.Currency, &s.CenterLatitude, &s.CenterLongitude, &s.RadiusKm,
+		&s.Status, &s.CreatedBy, &s.SettledAt, &s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func scanShiftBooking(row pgx.Row) (*shiftBooking, error) {
+	var b shiftBooking
+	err := row.Scan(
+		&b.ID, &b.SlotID, &b.DriverID, &b.Status, &b.OnlineMinutes, &b.InZoneMinutes,
+		&b.Earnings, &b.TopUp, &b.SettledAt, &b.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// shiftOutcome decides whether a courier attended a slot and the top-up owed
+// to bring their earnings in it up to the guarantee
+func shiftOutcome(slotMinutes, inZoneMinutes int, guaranteedMin, earnings float64) (bool, float64) {
+	if slotMinutes <= 0 || float64(inZoneMinutes) < shiftAttendanceMinPct*float64(slotMinutes) {
+		return false, 0
+	}
+	topUp := math.Round((guaranteedMin-earnings)*100) / 100
+	if topUp < 0 {
+		topUp = 0
+	}
+	return true, topUp
+}
+
+// ============================================
+// Ops Slot Management
+// ============================================
+
+// PublishShiftSlot publishes a peak-hour slot in a zone with a guaranteed
+// minimum courier earning
+// POST /api/v1/internal/shift-slots
+func (h *Handler) PublishShiftSlot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req struct {
+		ZoneID          string    `json:"zoneId"`
+		StartsAt        time.Time `json:"startsAt"`
+		EndsAt          time.Time `json:"endsAt"`
+		Capacity        int       `json:"capacity"`
+		GuaranteedMin   float64   `json:"guaranteedMin"`
+		Currency        string    `json:"currency"`
+		CenterLatitude  float64   `json:"centerLatitude"`
+		CenterLongitude float64   `json:"centerLongitude"`
+		RadiusKm        float64   `json:"radiusKm"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	length := req.EndsAt.Sub(req.StartsAt)
+	switch {
+	case !req.StartsAt.After(time.Now()):
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "startsAt must be in the future")
+		return
+	case length < shiftMinLength || length > shiftMaxLength:
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Slots must be between 30 minutes and 6 hours long")
+		return
+	case req.Capacity <= 0 || req.GuaranteedMin < 0 || len(req.Currency) != 3:
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "capacity, guaranteedMin and currency are required")
+		return
+	case req.CenterLatitude < -90 || req.CenterLatitude > 90 || req.CenterLongitude < -180 || req.CenterLongitude > 180:
+		respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid zone centre")
+		return
+	}
+	if req.RadiusKm <= 0 {
+		req.RadiusKm = shiftDefaultRadiusKm
+	}
+
+	var city string
+	err := h.db.Pool.QueryRow(ctx,
+		`SELECT city FROM delivery_zones WHERE id = $1 AND is_active = true`,
+		req.ZoneID,
+	).Scan(&city)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Zone not found")
+		return
+	}
+
+	slotID := uuid.New().String()
+	_, err = h.db.Pool.Exec(ctx, `
+		INSERT INTO courier_shift_slots (
+			id, zone_id, city, starts_at, ends_at, capacity, guaranteed_min, currency,
+			center_latitude, center_longitude, radius_km, status, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, 'OPEN', $12)`,
+		slotID, req.ZoneID, city, req.StartsAt, req.EndsAt, req.Capacity, req.GuaranteedMin, req.Currency,
+		req.CenterLatitude, req.CenterLongitude, req.RadiusKm, middleware.GetUserID(ctx),
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to publish shift slot")
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to publish slot")
+		return
+	}
+
+	slot, err := h.getShiftSlot(ctx, slotID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load slot")
+		return
+	}
+
+	log.Info().
+		Str("slotId", slotID).
+		Str("zoneId", req.ZoneID).
+		Time("startsAt", req.StartsAt).
+		Float64("guaranteedMin", req.GuaranteedMin).
+		Msg("Shift slot published")
+
+	respond(w, http.StatusCreated, slot)
+}
+
+// ListShiftSlots lists slots starting in a period, optionally in one zone
+// GET /api/v1/internal/shift-slots?zoneId=&from=&to=
+func (h *Handler) ListShiftSlots(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseShiftPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	slots, err := h.queryShiftSlots(r.Context(), `
+		SELECT `+shiftSlotColumns+` FROM courier_shift_slots s
+		WHERE s.starts_at >= $1 AND s.starts_at < $2
+			AND ($3 = '' OR s.zone_id = $3)
+		ORDER BY s.starts_at`,
+		from, to, r.URL.Query().Get("zoneId"),
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list slots")
+		return
+	}
+
+	respond(w, http.StatusOK, slots)
+}
+
+// GetShiftSlot returns a slot with its bookings and their attendance
+// GET /api/v1/internal/shift-slots/{slotId}
+func (h *Handler) GetShiftSlot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	slot, err := h.getShiftSlot(ctx, chi.URLParam(r, "slotId"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Slot not found")
+		return
+	}
+
+	bookings, err := h.queryShiftBookings(ctx, `
+		SELECT `+shiftBookingColumns+` FROM courier_shift_bookings b
+		WHERE b.slot_id = $1
+		ORDER BY b.created_at`,
+		slot.ID,
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load bookings")
+		return
+	}
+
+	respond(w, http.StatusOK, map[string]interface{}{
+		"slot":     slot,
+		"bookings": bookings,
+	})
+}
+
+// CancelShiftSlot withdraws a slot that has not started, cancelling its
+// bookings
+// POST /api/v1/internal/shift-slots/{slotId}/cancel
+func (h *Handler) CancelShiftSlot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	slotID := chi.URLParam(r, "slotId")
+
+	tx, err := h.db.Pool.Begin(ctx)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to cancel slot")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE courier_shift_slots SET status = 'CANCELLED'
+		WHERE id = $1 AND status = 'OPEN' AND starts_at > NOW()`,
+		slotID,
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to cancel slot")
+		return
+	}
+	if result.RowsAffected() == 0 {
+		respondError(w, http.StatusConflict, "SLOT_NOT_CANCELLABLE", "Only open slots that have not started can be cancelled")
+		return
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE courier_shift_bookings SET status = 'CANCELLED' WHERE slot_id = $1 AND status = 'BOOKED'`,
+		slotID,
+	); err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to cancel slot")
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to cancel slot")
+		return
+	}
+
+	slot, err := h.getShiftSlot(ctx, slotID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load slot")
+		return
+	}
+	respond(w, http.StatusOK, slot)
+}
+
+// ============================================
+// Courier Booking
+// ============================================
+
+// GetOpenShiftSlots lists upcoming slots with spots left, optionally in one
+// zone, flagging those the courier has booked
+// GET /api/v1/driver/shifts/slots?zoneId=
+func (h *Handler) GetOpenShiftSlots(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	driverID := middleware.GetUserID(ctx)
+
+	slots, err := h.queryShiftSlots(ctx, `
+		SELECT `+shiftSlotColumns+` FROM courier_shift_slots s
+		WHERE s.status = 'OPEN' AND s.starts_at > NOW()
+			AND ($1 = '' OR s.zone_id = $1)
+		ORDER BY s.starts_at
+		LIMIT 100`,
+		r.URL.Query().Get("zoneId"),
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list slots")
+		return
+	}
+
+	booked := map[string]bool{}
+	rows, err := h.db.Pool.Query(ctx,
+		`SELECT slot_id FROM courier_shift_bookings WHERE driver_id = $1 AND status = 'BOOKED'`,
+		driverID,
+	)
+	if err == nil {
+		defer rows.Close()
+		for rows.Next() {
+			var slotID string
+			rows.Scan(&slotID)
+			booked[slotID] = true
+		}
+	}
+
+	open := make([]map[string]interface{}, 0, len(slots))
+	for _, s := range slots {
+		if s.Booked >= s.Capacity && !booked[s.ID] {
+			continue
+		}
+		open = append(open, map[string]interface{}{
+			"slot":      s,
+			"spotsLeft": s.Capacity - s.Booked,
+			"booked":    booked[s.ID],
+		})
+	}
+
+	respond(w, http.StatusOK, open)
+}
+
+// BookShiftSlot books the courier onto an upcoming slot with spots left that
+// doesn't overlap their other bookings
+// POST /api/v1/driver/shifts/slots/{slotId}/book
+func (h *Handler) BookShiftSlot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	driverID := middleware.GetUserID(ctx)
+	slotID := chi.URLParam(r, "slotId")
+
+	tx, err := h.db.Pool.Begin(ctx)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to book slot")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the slot so concurrent bookings can't exceed its capacity
+	var status string
+	var startsAt, endsAt time.Time
+	var capacity int
+	err = tx.QueryRow(ctx, `
+		SELECT status, starts_at, ends_at, capacity FROM courier_shift_slots
+		WHERE id = $1 FOR UPDATE`,
+		slotID,
+	).Scan(&status, &startsAt, &endsAt, &capacity)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Slot not found")
+		return
+	}
+	if status != "OPEN" || !startsAt.After(time.Now()) {
+		respondError(w, http.StatusConflict, "SLOT_NOT_OPEN", "Slot is no longer open for booking")
+		return
+	}
+
+	var booked, overlapping int
+	tx.QueryRow(ctx,
+		`SELECT COUNT(*) FROM courier_shift_bookings WHERE slot_id = $1 AND status != 'CANCELLED'`,
+		slotID,
+	).Scan(&booked)
+	if booked >= capacity {
+		respondError(w, http.StatusConflict, "SLOT_FULL", "Slot is fully booked")
+		return
+	}
+	tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM courier_shift_bookings b
+		JOIN courier_shift_slots s ON s.id = b.slot_id
+		WHERE b.driver_id = $1 AND b.status = 'BOOKED'
+			AND s.starts_at < $3 AND s.ends_at > $2`,
+		driverID, startsAt, endsAt,
+	).Scan(&overlapping)
+	if overlapping > 0 {
+		respondError(w, http.StatusConflict, "SLOT_OVERLAP", "You already have a slot booked at this time")
+		return
+	}
+
+	bookingID := uuid.New().String()
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO courier_shift_bookings (id, slot_id, driver_id, status)
+		VALUES ($1, $2, $3, 'BOOKED')`,
+		bookingID, slotID, driverID,
+	); err != nil {
+		log.Error().Err(err).Msg("Failed to book shift slot")
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to book slot")
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to book slot")
+		return
+	}
+
+	booking, err := h.getShiftBooking(ctx, bookingID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load booking")
+		return
+	}
+	respond(w, http.StatusCreated, booking)
+}
+
+// CancelShiftBooking cancels one of the courier's bookings before its slot
+// starts
+// POST /api/v1/driver/shifts/bookings/{bookingId}/cancel
+func (h *Handler) CancelShiftBooking(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bookingID := chi.URLParam(r, "bookingId")
+
+	result, err := h.db.Pool.Exec(ctx, `
+		UPDATE courier_shift_bookings b SET status = 'CANCELLED'
+		FROM courier_shift_slots s
+		WHERE b.id = $1 AND b.driver_id = $2 AND b.status = 'BOOKED'
+			AND s.id = b.slot_id AND s.starts_at > NOW()`,
+		bookingID, middleware.GetUserID(ctx),
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to cancel booking")
+		return
+	}
+	if result.RowsAffected() == 0 {
+		respondError(w, http.StatusConflict, "BOOKING_NOT_CANCELLABLE", "Only bookings for slots that have not started can be cancelled")
+		return
+	}
+
+	booking, err := h.getShiftBooking(ctx, bookingID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load booking")
+		return
+	}
+	respond(w, http.StatusOK, booking)
+}
+
+// GetMyShiftBookings lists the courier's bookings, latest slot first, with
+// their attendance and guarantee top-ups
+// GET /api/v1/driver/shifts/bookings
+func (h *Handler) GetMyShiftBookings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bookings, err := h.queryShiftBookings(ctx, `
+		SELECT `+shiftBookingColumns+` FROM courier_shift_bookings b
+		JOIN courier_shift_slots s ON s.id = b.slot_id
+		WHERE b.driver_id = $1
+		ORDER BY s.starts_at DESC
+		LIMIT 50`,
+		middleware.GetUserID(ctx),
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list bookings")
+		return
+	}
+	for _, b := range bookings {
+		b.Slot, _ = h.getShiftSlot(ctx, b.SlotID)
+	}
+
+	respond(w, http.StatusOK, bookings)
+}
+
+// ============================================
+// Attendance and Settlement
+// ============================================
+
+// recordShiftAttendance marks the current minute as online for any slot the
+// courier is booked on and running now, noting whether they are in its zone
+func (h *Handler) recordShiftAttendance(ctx context.Context, driverID string, lat, lng float64, at time.Time) {
+	_, err := h.db.Pool.Exec(ctx, `
+		INSERT INTO courier_shift_attendance (booking_id, minute, in_zone)
+		SELECT b.id, date_trunc('minute', $2::timestamptz),
+			ST_DWithin(
+				ST_MakePoint(s.center_longitude, s.center_latitude)::geography,
+				ST_MakePoint($3, $4)::geography,
+				s.radius_km * 1000
+			)
+		FROM courier_shift_bookings b
+		JOIN courier_shift_slots s ON s.id = b.slot_id
+		WHERE b.driver_id = $1 AND b.status = 'BOOKED'
+			AND s.starts_at <= $2 AND s.ends_at > $2
+		ON CONFLICT (booking_id, minute) DO UPDATE
+			SET in_zone = courier_shift_attendance.in_zone OR EXCLUDED.in_zone`,
+		driverID, at, lng, lat,
+	)
+	if err != nil {
+		log.Error().Err(err).Str("driverId", driverID).Msg("Failed to record shift attendance")
+	}
+}
+
+// settleEndedShiftSlots settles open slots that ended more than the grace
+// period ago
+func (h *Handler) settleEndedShiftSlots(ctx context.Context) error {
+	slots, err := h.queryShiftSlots(ctx, `
+		SELECT `+shiftSlotColumns+` FROM courier_shift_slots s
+		WHERE s.status = 'OPEN' AND s.ends_at <= $1
+		ORDER BY s.ends_at
+		LIMIT $2`,
+		time.Now().Add(-shiftSettlementGrace), shiftSettlementBatches,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, slot := range slots {
+		if err := h.settleShiftSlot(ctx, slot); err != nil {
+			log.Error().Err(err).Str("slotId", slot.ID).Msg("Failed to settle shift slot")
+		}
+	}
+	return nil
+}
+
+// settleShiftSlot verifies each booked courier's attendance and books a
+// ledger top-up for attended couriers who earned less than the guarantee
+func (h *Handler) settleShiftSlot(ctx context.Context, slot *shiftSlot) error {
+	rows, err := h.db.Pool.Query(ctx, `
+		SELECT b.id, b.driver_id,
+			COUNT(a.minute),
+			COUNT(a.minute) FILTER (WHERE a.in_zone)
+		FROM courier_shift_bookings b
+		LEFT JOIN courier_shift_attendance a ON a.booking_id = b.id
+		WHERE b.slot_id = $1 AND b.status = 'BOOKED'
+		GROUP BY b.id, b.driver_id`,
+		slot.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	type attendance struct {
+		bookingID, driverID string
+		online, inZone      int
+	}
+	var bookings []attendance
+	for rows.Next() {
+		var a attendance
+		if err := rows.Scan(&a.bookingID, &a.driverID, &a.online, &a.inZone); err != nil {
+			rows.Close()
+			return err
+		}
+		bookings = append(bookings, a)
+	}
+	rows.Close()
+
+	slotMinutes := int(slot.EndsAt.Sub(slot.StartsAt).Minutes())
+	for _, a := range bookings {
+		// Delivery earnings in the slot, on the same basis as the earnings summary
+		var earnings float64
+		err := h.db.Pool.QueryRow(ctx, `
+			SELECT COALESCE(SUM(total_fare - tip - service_fee - insurance_fee), 0)
+			FROM deliveries
+			WHERE driver_id = $1 AND status = 'DELIVERED' AND currency = $2
+				AND delivered_at >= $3 AND delivered_at < $4`,
+			a.driverID, slot.Currency, slot.StartsAt, slot.EndsAt,
+		).Scan(&earnings)
+		if err != nil {
+			return err
+		}
+
+		attended, topUp := shiftOutcome(slotMinutes, a.inZone, slot.GuaranteedMin, earnings)
+		if err := h.settleShiftBooking(ctx, slot, a.bookingID, a.driverID, attended, a.online, a.inZone, earnings, topUp); err != nil {
+			return err
+		}
+	}
+
+	_, err = h.db.Pool.Exec(ctx,
+		`UPDATE courier_shift_slots SET status = 'SETTLED', settled_at = NOW() WHERE id = $1 AND status = 'OPEN'`,
+		slot.ID,
+	)
+	return err
+}
+
+func (h *Handler) settleShiftBooking(
+	ctx context.Context,
+	slot *shiftSlot,
+	bookingID, driverID string,
+	attended bool,
+	online, inZone int,
+	earnings, topUp float64,
+) error {
+	status := "NO_SHOW"
+	if attended {
+		status = "ATTENDED"
+	}
+
+	tx, err := h.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE courier_shift_bookings SET
+			status = $2, online_minutes = $3, in_zone_minutes = $4,
+			earnings = $5, top_up = $6, settled_at = NOW()
+		WHERE id = $1 AND status = 'BOOKED'`,
+		bookingID, status, online, inZone, earnings, topUp,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return nil
+	}
+
+	if topUp > 0 {
+		insertEntry := `
+			INSERT INTO delivery_ledger_entries (id, shift_booking_id, account, account_id, amount, currency, description)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`
+		if _, err := tx.Exec(ctx, insertEntry, uuid.New().String(), bookingID,
+			"DRIVER", driverID, topUp, slot.Currency, "Shift guarantee top-up"); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, insertEntry, uuid.New().String(), bookingID,
+			"PLATFORM", nil, -topUp, slot.Currency, "Shift guarantee top-up"); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("slotId", slot.ID).
+		Str("bookingId", bookingID).
+		Str("driverId", driverID).
+		Str("status", status).
+		Int("inZoneMinutes", inZone).
+		Float64("earnings", earnings).
+		Float64("topUp", topUp).
+		Msg("Shift booking settled")
+	return nil
+}
+
+// ============================================
+// Helpers
+// ============================================
+
+func (h *Handler) getShiftSlot(ctx context.Context, slotID string) (*shiftSlot, error) {
+	return scanShiftSlot(h.db.Pool.QueryRow(ctx,
+		`SELECT `+shiftSlotColumns+` FROM courier_shift_slots s WHERE s.id = $1`,
+		slotID,
+	))
+}
+
+func (h *Handler) getShiftBooking(ctx context.Context, bookingID string) (*shiftBooking, error) {
+	return scanShiftBooking(h.db.Pool.QueryRow(ctx,
+		`SELECT `+shiftBookingColumns+` FROM courier_shift_bookings b WHERE b.id = $1`,
+		bookingID,
+	))
+}
+
+func (h *Handler) queryShiftSlots(ctx context.Context, query string, args ...interface{}) ([]*shiftSlot, error) {
+	rows, err := h.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slots := []*shiftSlot{}
+	for rows.Next() {
+		s, err := scanShiftSlot(rows)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, s)
+	}
+	return slots, rows.Err()
+}
+
+func (h *Handler) queryShiftBookings(ctx context.Context, query string, args ...interface{}) ([]*shiftBooking, error) {
+	rows, err := h.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := []*shiftBooking{}
+	for rows.Next() {
+		b, err := scanShiftBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, rows.Err()
+}
+
+// parseShiftPeriod reads the from/to query window, defaulting to the next
+// seven days
+func parseShiftPeriod(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	from := time.Now().Truncate(24 * time.Hour)
+	to := from.Add(7 * 24 * time.Hour)
+
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "from must be an RFC3339 timestamp")
+			return from, to, false
+		}
+		from = t
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil || !t.After(from) {
+			respondError(w, http.StatusBadRequest, "VALIDATION_ERROR", "to must be an RFC3339 timestamp after from")
+			return from, to, false
+		}
+		to = t
+	}
+	return from, to, true
+}
@@ -0,0 +1,263 @@
+/*
+ * Support Case Handlers
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/models"
+)
+
+// ============================================
+// Support Case Context
+// ============================================
+
+// GetDeliveryCaseContext assembles everything support needs for a delivery
+// ticket in one call. Front-line SUPPORT agents get masked contact details
+// and coarse coordinates; SUPPORT_LEAD and ADMIN see the full record.
+func (h *Handler) GetDeliveryCaseContext(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deliveryID := chi.URLParam(r, "id")
+	role := middleware.GetUserRole(ctx)
+
+	var d struct {
+		ID                 string          `json:"id"`
+		TrackingNumber     string          `json:"trackingNumber"`
+		CustomerID         string          `json:"customerId"`
+		DriverID           *string         `json:"driverId"`
+		Type               string          `json:"type"`
+		Status             string          `json:"status"`
+		PickupLocation     json.RawMessage `json:"pickupLocation"`
+		DropoffLocation    json.RawMessage `json:"dropoffLocation"`
+		PickupContact      json.RawMessage `json:"pickupContact"`
+		DropoffContact     json.RawMessage `json:"dropoffContact"`
+		Package            json.RawMessage `json:"package"`
+		PaymentStatus      string          `json:"paymentStatus"`
+		PaymentMethod      *string         `json:"paymentMethod"`
+		CancellationReason *string         `json:"cancellationReason"`
+		CreatedAt          time.Time       `json:"createdAt"`
+	}
+	var fare FareBreakdown
+	var distanceKm float64
+	var estimatedMinutes int
+	var tip float64
+	var currency string
+	var pickedUpAt, deliveredAt *time.Time
+
+	err := h.db.Pool.QueryRow(ctx, `
+		SELECT id, tracking_number, customer_id, driver_id, type, status,
+			pickup_location, dropoff_location, pickup_contact, dropoff_contact, package,
+			payment_status, payment_method, cancellation_reason, created_at,
+			base_fare, distance_fare, time_fare, surge_fare, service_fee, insurance_fee, total_fare, currency,
+			tip, distance_km, estimated_minutes, picked_up_at, delivered_at
+		FROM deliveries WHERE id = $1`,
+		deliveryID,
+	).Scan(
+		&d.ID, &d.TrackingNumber, &d.CustomerID, &d.DriverID, &d.Type, &d.Status,
+		&d.PickupLocation, &d.DropoffLocation, &d.PickupContact, &d.DropoffContact, &d.Package,
+		&d.PaymentStatus, &d.PaymentMethod, &d.CancellationReason, &d.CreatedAt,
+		&fare.BaseFare, &fare.DistanceFare, &fare.TimeFare, &fare.SurgeFare, &fare.ServiceFee, &fare.InsuranceFee, &fare.Total, &currency,
+		&tip, &distanceKm, &estimatedMinutes, &pickedUpAt, &deliveredAt,
+	)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "NOT_FOUND", "Delivery not found")
+		return
+	}
+	if err := h.openDeliveryContacts(ctx, &d.PickupContact, &d.DropoffContact); err != nil {
+		log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to open delivery contacts")
+		respondError(w, http.StatusInternalServerError, "ENCRYPTION_ERROR", "Failed to fetch delivery context")
+		return
+	}
+
+	// Agents below support lead see contacts masked and locations coarsened
+	redacted := role != "SUPPORT_LEAD" && role != "ADMIN"
+
+	// Timeline
+	events := []map[string]interface{}{}
+	eventRows, err := h.db.Pool.Query(ctx, `
+		SELECT type, status, location, note, created_at
+		FROM delivery_events
+		WHERE delivery_id = $1
+		ORDER BY created_at ASC`,
+		deliveryID,
+	)
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to fetch delivery timeline")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch delivery context")
+		return
+	}
+	defer eventRows.Close()
+	for eventRows.Next() {
+		var evt struct {
+			Type      string
+			Status    string
+			Location  json.RawMessage
+			Note      *string
+			CreatedAt time.Time
+		}
+		if err := eventRows.Scan(&evt.Type, &evt.Status, &evt.Location, &evt.Note, &evt.CreatedAt); err != nil {
+			log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to scan delivery event")
+			respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch delivery context")
+			return
+		}
+		if redacted {
+			evt.Location = redactLocation(evt.Location)
+		}
+		events = append(events, map[string]interface{}{
+			"type":      evt.Type,
+			"status":    evt.Status,
+			"location":  evt.Location,
+			"note":      evt.Note,
+			"createdAt": evt.CreatedAt,
+		})
+	}
+	if err := eventRows.Err(); err != nil {
+		log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to read delivery timeline")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch delivery context")
+		return
+	}
+
+	// Customer profile (delivery-service view)
+	var customerDeliveries, customerCancelled int
+	err = h.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE status = 'CANCELLED')
+		FROM deliveries WHERE customer_id = $1`,
+		d.CustomerID,
+	).Scan(&customerDeliveries, &customerCancelled)
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to fetch customer delivery stats")
+		respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch delivery context")
+		return
+	}
+
+	// Driver profile and last known location
+	var driver map[string]interface{}
+	var lastLocation *models.DriverLocation
+	if d.DriverID != nil {
+		var driverDeliveries int
+		var avgRating *float64
+		err := h.db.Pool.QueryRow(ctx, `
+			SELECT COUNT(*), AVG(driver_rating)
+			FROM deliveries WHERE driver_id = $1 AND status = 'DELIVERED'`,
+			*d.DriverID,
+		).Scan(&driverDeliveries, &avgRating)
+		if err != nil {
+			log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to fetch driver delivery stats")
+			respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch delivery context")
+			return
+		}
+
+		driver = map[string]interface{}{
+			"id":                  *d.DriverID,
+			"completedDeliveries": driverDeliveries,
+			"averageRating":       avgRating,
+		}
+
+		var loc models.DriverLocation
+		if err := h.rdb.GetJSON(ctx, "driver:location:"+*d.DriverID, &loc); err == nil {
+			lastLocation = &loc
+		}
+	}
+
+	// Location trail summary
+	trail := map[string]interface{}{
+		"pickup":           d.PickupLocation,
+		"dropoff":          d.DropoffLocation,
+		"distanceKm":       distanceKm,
+		"estimatedMinutes": estimatedMinutes,
+	}
+	if pickedUpAt != nil && deliveredAt != nil {
+		trail["actualMinutes"] = int(deliveredAt.Sub(*pickedUpAt).Minutes())
+	}
+
+	if redacted {
+		d.PickupContact = redactContact(d.PickupContact)
+		d.DropoffContact = redactContact(d.DropoffContact)
+		d.PickupLocation = redactLocation(d.PickupLocation)
+		d.DropoffLocation = redactLocation(d.DropoffLocation)
+		trail["pickup"] = d.PickupLocation
+		trail["dropoff"] = d.DropoffLocation
+		if lastLocation != nil {
+			coarse := *lastLocation
+			coarse.Latitude = roundCoord(coarse.Latitude)
+			coarse.Longitude = roundCoord(coarse.Longitude)
+			lastLocation = &coarse
+		}
+	}
+	trail["driverLastLocation"] = lastLocation
+
+	respond(w, http.StatusOK, map[string]interface{}{
+		"delivery":      d,
+		"timeline":      events,
+		"fareBreakdown": fare,
+		"tip":           tip,
+		"currency":      currency,
+		"customer": map[string]interface{}{
+			"id":                  d.CustomerID,
+			"totalDeliveries":     customerDeliveries,
+			"cancelledDeliveries": customerCancelled,
+		},
+		"driver":        driver,
+		"locationTrail": trail,
+		"chatTranscript": map[string]string{
+			"service":        "messaging",
+			"conversationId": "delivery:" + d.ID,
+		},
+		"redacted": redacted,
+	})
+}
+
+// redactContact masks phone and email in a contact JSON blob
+func redactContact(raw json.RawMessage) json.RawMessage {
+	var contact models.ContactInfo
+	if len(raw) == 0 || json.Unmarshal(raw, &contact) != nil {
+		return raw
+	}
+
+	contact.Phone = maskPhone(contact.Phone)
+	if at := strings.Index(contact.Email, "@"); at > 0 {
+		contact.Email = contact.Email[:1] + "***" + contact.Email[at:]
+	}
+
+	out, _ := json.Marshal(contact)
+	return out
+}
+
+// redactLocation drops street-level address detail and rounds coordinates (~100m)
+func redactLocation(raw json.RawMessage) json.RawMessage {
+	var loc *models.Location
+	if len(raw) == 0 || json.Unmarshal(raw, &loc) != nil || loc == nil {
+		return raw
+	}
+
+	out, _ := json.Marshal(models.Location{
+		Latitude:  roundCoord(loc.Latitude),
+		Longitude: roundCoord(loc.Longitude),
+		City:      loc.City,
+		State:     loc.State,
+		Country:   loc.Country,
+	})
+	return out
+}
+
+// maskPhone keeps the country prefix and last two digits of a phone number
+func maskPhone(phone string) string {
+	if len(phone) <= 6 {
+		return strings.Repeat("*", len(phone))
+	}
+	return phone[:4] + strings.Repeat("*", len(phone)-6) + phone[len(phone)-2:]
+}
+
+func roundCoord(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRedactLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			"delivery address",
+			`{"latitude":6.524379,"longitude":3.379206,"address":"12 Marina Road","city":"Lagos","country":"NG","directions":"blue gate"}`,
+			`{"latitude":6.524,"longitude":3.379,"address":"","city":"Lagos","country":"NG"}`,
+		},
+		{
+			"timeline event position",
+			`{"latitude":6.5243791,"longitude":3.3792057}`,
+			`{"latitude":6.524,"longitude":3.379,"address":"","city":"","country":""}`,
+		},
+		{"event without a position", `null`, `null`},
+		{"not a location", `"somewhere"`, `"somewhere"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactLocation(json.RawMessage(tt.raw))
+			if string(got) != tt.want {
+				t.Errorf("redactLocation() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
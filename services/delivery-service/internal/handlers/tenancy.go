@@ -0,0 +1,78 @@
+/*
+ * Partner Tenancy Guard
+ */
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	chimw "github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/database"
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+)
+
+// tenancyStore looks up who owns deliveries and stores cross-tenant access
+// audits
+type tenancyStore interface {
+	DeliveryPartner(ctx context.Context, deliveryID string) (*string, bool, error)
+	RecordPartnerAccess(ctx context.Context, access *database.PartnerAccess) error
+}
+
+// PartnerTenancyGuard audits partners reaching for another tenant's
+// delivery by ID. Row-level security already hides the delivery from
+// them; the guard records the attempt and answers 404 before the handler
+// runs, so it reads the same as a delivery that does not exist.
+func (h *Handler) PartnerTenancyGuard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		partnerID := middleware.GetPartnerID(r.Context())
+		deliveryID := chi.URLParam(r, "id")
+		if partnerID == "" || deliveryID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		owner, found, err := h.tenancy.DeliveryPartner(r.Context(), deliveryID)
+		if err != nil {
+			log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to check delivery tenancy")
+			respondError(w, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to fetch delivery")
+			return
+		}
+		if found && (owner == nil || *owner != partnerID) {
+			h.auditCrossTenantAccess(r, partnerID, deliveryID, owner)
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "Delivery not found")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// auditCrossTenantAccess logs and stores a partner's attempt to reach a
+// delivery it does not own
+func (h *Handler) auditCrossTenantAccess(r *http.Request, partnerID, deliveryID string, owner *string) {
+	requestID := chimw.GetReqID(r.Context())
+	log.Warn().
+		Str("partner_id", partnerID).
+		Str("delivery_id", deliveryID).
+		Str("method", r.Method).
+		Str("path", r.URL.Path).
+		Str("request_id", requestID).
+		Msg("Cross-tenant delivery access blocked")
+
+	err := h.tenancy.RecordPartnerAccess(r.Context(), &database.PartnerAccess{
+		PartnerID:      partnerID,
+		DeliveryID:     deliveryID,
+		OwnerPartnerID: owner,
+		Method:         r.Method,
+		Path:           r.URL.Path,
+		RequestID:      requestID,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("partner_id", partnerID).Msg("Failed to store cross-tenant access audit")
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/database"
+)
+
+type fakeTenancy struct {
+	owners map[string]*string // delivery ID to owning partner, nil for first-party
+	audits []*database.PartnerAccess
+}
+
+func (f *fakeTenancy) DeliveryPartner(ctx context.Context, deliveryID string) (*string, bool, error) {
+	owner, ok := f.owners[deliveryID]
+	return owner, ok, nil
+}
+
+func (f *fakeTenancy) RecordPartnerAccess(ctx context.Context, access *database.PartnerAccess) error {
+	f.audits = append(f.audits, access)
+	return nil
+}
+
+// serveGuarded requests a delivery through the tenancy guard as partnerID,
+// reporting whether the handler behind it ran
+func serveGuarded(t *testing.T, store *fakeTenancy, partnerID, deliveryID string) (*httptest.ResponseRecorder, bool) {
+	t.Helper()
+	h := &Handler{tenancy: store}
+	reached := false
+
+	r := chi.NewRouter()
+	r.Route("/deliveries/{id}", func(r chi.Router) {
+		r.Use(h.PartnerTenancyGuard)
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			reached = true
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/deliveries/"+deliveryID+"/", nil)
+	req = req.WithContext(database.WithPartner(req.Context(), partnerID))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec, reached
+}
+
+func TestPartnerTenancyGuard(t *testing.T) {
+	acme, globex := "acme", "globex"
+	store := &fakeTenancy{owners: map[string]*string{
+		"d-acme":   &acme,
+		"d-globex": &globex,
+		"d-first":  nil,
+	}}
+
+	t.Run("own delivery", func(t *testing.T) {
+		rec, reached := serveGuarded(t, store, acme, "d-acme")
+		if rec.Code != http.StatusOK || !reached {
+			t.Errorf("status = %d, reached = %v, want 200 from the handler", rec.Code, reached)
+		}
+	})
+
+	t.Run("another partner's delivery", func(t *testing.T) {
+		store.audits = nil
+		rec, reached := serveGuarded(t, store, acme, "d-globex")
+		if rec.Code != http.StatusNotFound || reached {
+			t.Errorf("status = %d, reached = %v, want 404 before the handler", rec.Code, reached)
+		}
+		if len(store.audits) != 1 {
+			t.Fatalf("audits = %d, want 1", len(store.audits))
+		}
+		audit := store.audits[0]
+		if audit.PartnerID != acme || audit.DeliveryID != "d-globex" || audit.OwnerPartnerID == nil || *audit.OwnerPartnerID != globex {
+			t.Errorf("audit = %+v, want acme reaching for globex's d-globex", audit)
+		}
+	})
+
+	t.Run("first-party delivery", func(t *testing.T) {
+		store.audits = nil
+		rec, reached := serveGuarded(t, store, acme, "d-first")
+		if rec.Code != http.StatusNotFound || reached {
+			t.Errorf("status = %d, reached = %v, want 404 before the handler", rec.Code, reached)
+		}
+		if len(store.audits) != 1 || store.audits[0].OwnerPartnerID != nil {
+			t.Errorf("audits = %+v, want one with no owning partner", store.audits)
+		}
+	})
+
+	t.Run("unknown delivery", func(t *testing.T) {
+		store.audits = nil
+		_, reached := serveGuarded(t, store, acme, "d-missing")
+		if !reached || len(store.audits) != 0 {
+			t.Errorf("reached = %v, audits = %d, want the handler to answer without an audit", reached, len(store.audits))
+		}
+	})
+
+	t.Run("first-party caller", func(t *testing.T) {
+		store.audits = nil
+		rec, reached := serveGuarded(t, store, "", "d-globex")
+		if rec.Code != http.StatusOK || !reached || len(store.audits) != 0 {
+			t.Errorf("status = %d, reached = %v, audits = %d, want passed through unaudited", rec.Code, reached, len(store.audits))
+		}
+	})
+}
@@ -0,0 +1,47 @@
+/*
+ * Webhook Verification Failure Handlers
+ */
+
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/middleware"
+)
+
+// webhookFailureCount is how many deliveries from a webhook source have
+// been refused for one reason
+type webhookFailureCount struct {
+	Source string `json:"source"`
+	Reason string `json:"reason"`
+	Count  int64  `json:"count"`
+}
+
+// GetWebhookFailures returns refused webhook deliveries across replicas,
+// by source and reason
+func (h *Handler) GetWebhookFailures(w http.ResponseWriter, r *http.Request) {
+	fields, err := h.rdb.Client().HGetAll(r.Context(), middleware.WebhookFailuresKey).Result()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to load webhook failures")
+		return
+	}
+
+	failures := make([]webhookFailureCount, 0, len(fields))
+	for field, value := range fields {
+		source, reason, _ := strings.Cut(field, ":")
+		count, _ := strconv.ParseInt(value, 10, 64)
+		failures = append(failures, webhookFailureCount{Source: source, Reason: reason, Count: count})
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		if failures[i].Source != failures[j].Source {
+			return failures[i].Source < failures[j].Source
+		}
+		return failures[i].Reason < failures[j].Reason
+	})
+
+	respond(w, http.StatusOK, map[string]interface{}{"failures": failures})
+}
@@ -0,0 +1,38 @@
+/*
+ * Background Jobs
+ */
+
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/worker"
+)
+
+// RegisterJobs schedules the delivery service's periodic jobs, which run
+// on the leader replica only
+func (h *Handler) RegisterJobs(workers *worker.Scheduler) {
+	h.workers = workers
+
+	workers.Register(worker.Job{Name: "shift-settlement", Schedule: worker.Every(5 * time.Minute), Run: h.settleEndedShiftSlots})
+	workers.Register(worker.Job{Name: "metrics-rollup", Schedule: worker.Every(10 * time.Minute), Run: h.rollUpDeliveryMetrics})
+	workers.Register(worker.Job{Name: "perishable-transit", Schedule: worker.Every(time.Minute), Run: h.escalatePerishableTransit})
+	workers.Register(worker.Job{Name: "delivery-retries", Schedule: worker.Every(5 * time.Minute), Run: h.reopenDueRetries})
+	workers.Register(worker.Job{Name: "eta-notifier", Schedule: worker.Every(30 * time.Second), Run: h.notifyCouriersNearby})
+	workers.Register(worker.Job{Name: "pickup-windows", Schedule: worker.Every(5 * time.Minute), Run: h.rollMissedPickupWindows})
+	workers.Register(worker.Job{Name: "reseal-sensitive-data", Schedule: worker.Every(time.Hour), Run: h.resealSensitiveData})
+	workers.Register(worker.Job{Name: "partner-exports", Schedule: worker.Every(30 * time.Second), Run: h.buildPartnerExports})
+	workers.Register(worker.Job{Name: "contact-name-index", Schedule: worker.Every(10 * time.Minute), Run: h.indexContactNames})
+}
+
+// GetWorkerStatus returns this replica's job leadership and run stats.
+// Followers count runs they skipped rather than ran.
+func (h *Handler) GetWorkerStatus(w http.ResponseWriter, r *http.Request) {
+	if h.workers == nil {
+		respondError(w, http.StatusServiceUnavailable, "WORKERS_UNAVAILABLE", "Background jobs are not running")
+		return
+	}
+	respond(w, http.StatusOK, h.workers.Status())
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog/log"
 
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/database"
 	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/redis"
 )
 
@@ -26,9 +27,10 @@ const (
 
 // Claims represents JWT claims
 type Claims struct {
-	UserID   string `json:"userId"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
+	UserID    string `json:"userId"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	PartnerID string `json:"partnerId,omitempty"` // set on tokens issued for partner API keys
 	jwt.RegisteredClaims
 }
 
@@ -84,6 +86,11 @@ func Auth(rdb *redis.Client, jwtSecret string) func(http.Handler) http.Handler {
 			ctx = context.WithValue(ctx, UserRoleKey, claims.Role)
 			ctx = context.WithValue(ctx, UserEmailKey, claims.Email)
 
+			// Partner callers only ever see their own deliveries
+			if claims.PartnerID != "" {
+				ctx = database.WithPartner(ctx, claims.PartnerID)
+			}
+
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -117,6 +124,60 @@ func AdminOnly(next http.Handler) http.Handler {
 	})
 }
 
+// SupportOnly middleware ensures user is a support agent or admin
+func SupportOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role := r.Context().Value(UserRoleKey).(string)
+
+		if role != "SUPPORT" && role != "SUPPORT_LEAD" && role != "ADMIN" {
+			respondError(w, http.StatusForbidden, "FORBIDDEN", "Support access required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ComplianceOnly middleware ensures user is a compliance officer or admin
+func ComplianceOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role := r.Context().Value(UserRoleKey).(string)
+
+		if role != "COMPLIANCE" && role != "ADMIN" {
+			respondError(w, http.StatusForbidden, "FORBIDDEN", "Compliance access required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MerchantOnly middleware ensures user is a merchant or admin
+func MerchantOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role := r.Context().Value(UserRoleKey).(string)
+
+		if role != "MERCHANT" && role != "ADMIN" {
+			respondError(w, http.StatusForbidden, "FORBIDDEN", "Merchant access required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// PartnerOnly middleware ensures the token was issued for a partner API key
+func PartnerOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetPartnerID(r.Context()) == "" {
+			respondError(w, http.StatusForbidden, "FORBIDDEN", "Partner access required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 // ServiceAuth middleware for service-to-service auth
 func ServiceAuth(serviceKey string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -141,6 +202,12 @@ func GetUserID(ctx context.Context) string {
 	return ""
 }
 
+// GetPartnerID extracts the partner a request was made for, or "" for
+// first-party requests
+func GetPartnerID(ctx context.Context) string {
+	return database.PartnerFrom(ctx)
+}
+
 // GetUserRole extracts user role from context
 func GetUserRole(ctx context.Context) string {
 	if role, ok := ctx.Value(UserRoleKey).(string); ok {
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/database"
+)
+
+func TestPartnerOnly(t *testing.T) {
+	handler := PartnerOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for partnerID, want := range map[string]int{"partner-1": http.StatusNoContent, "": http.StatusForbidden} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/exports", nil)
+		req = req.WithContext(database.WithPartner(req.Context(), partnerID))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("partner %q: status = %d, want %d", partnerID, rec.Code, want)
+		}
+	}
+}
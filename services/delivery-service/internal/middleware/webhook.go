@@ -0,0 +1,180 @@
+/*
+ * Webhook Signature Middleware
+ */
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/ubi-africa/ubi-monorepo/services/delivery-service/internal/redis"
+)
+
+// Headers a signed webhook delivery carries. The signature is the hex
+// HMAC-SHA256 of "<timestamp>.<nonce>.<body>", optionally prefixed
+// "sha256=", with the timestamp in Unix seconds.
+const (
+	HeaderWebhookSignature = "X-Webhook-Signature"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookNonce     = "X-Webhook-Nonce"
+)
+
+// WebhookFailuresKey is the Redis hash counting refused deliveries across
+// replicas, one field per source and reason, e.g. "payment:bad_signature"
+const WebhookFailuresKey = "metrics:webhook_failures"
+
+// Webhook verification settings
+const (
+	maxWebhookBodyBytes   = 1 << 20
+	webhookAlertThreshold = 10 // failures from one source within a minute that raise an alert
+)
+
+// ParseWebhookSecrets reads WEBHOOK_SECRETS, SOURCE=SECRET entries such as
+// "payment=abc,order=def"
+func ParseWebhookSecrets(spec string) (map[string]string, error) {
+	secrets := map[string]string{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		source, secret, ok := strings.Cut(entry, "=")
+		source = strings.ToLower(strings.TrimSpace(source))
+		secret = strings.TrimSpace(secret)
+		if !ok || source == "" || secret == "" {
+			return nil, fmt.Errorf("invalid webhook secret entry for %q, want SOURCE=SECRET", source)
+		}
+		secrets[source] = secret
+	}
+	return secrets, nil
+}
+
+// WebhookSignature refuses webhook deliveries from source that are not
+// signed with its secret, were signed more than tolerance from now, or
+// reuse a nonce already seen. A source with no secret has every delivery
+// refused. Nonces are kept in Redis so a delivery cannot be replayed
+// against another replica.
+func WebhookSignature(rdb *redis.Client, source string, secrets map[string]string, tolerance time.Duration) func(http.Handler) http.Handler {
+	secret := secrets[source]
+	if secret == "" {
+		log.Warn().Str("source", source).Msg("No webhook secret configured - all deliveries will be refused")
+	}
+
+	return webhookSignature(rdb, source, secret, tolerance, func(r *http.Request, reason string) {
+		recordWebhookFailure(r, rdb, source, reason)
+	})
+}
+
+// webhookNonces remembers the nonces of accepted deliveries
+type webhookNonces interface {
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+}
+
+// webhookSignature verifies deliveries from source, calling onFailure with
+// the reason each refused one was refused
+func webhookSignature(nonces webhookNonces, source, secret string, tolerance time.Duration, onFailure func(r *http.Request, reason string)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fail := func(status int, code, reason, message string) {
+				onFailure(r, reason)
+				respondError(w, status, code, message)
+			}
+
+			if secret == "" {
+				fail(http.StatusUnauthorized, "INVALID_SIGNATURE", "unknown_source", "Webhook source not configured")
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes))
+			if err != nil {
+				respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+				return
+			}
+
+			signature := strings.TrimPrefix(r.Header.Get(HeaderWebhookSignature), "sha256=")
+			nonce := r.Header.Get(HeaderWebhookNonce)
+			timestamp, err := strconv.ParseInt(r.Header.Get(HeaderWebhookTimestamp), 10, 64)
+			if signature == "" || nonce == "" || err != nil {
+				fail(http.StatusUnauthorized, "INVALID_SIGNATURE", "missing_header", "Missing webhook signature headers")
+				return
+			}
+
+			skew := time.Since(time.Unix(timestamp, 0))
+			if skew > tolerance || skew < -tolerance {
+				fail(http.StatusUnauthorized, "INVALID_SIGNATURE", "stale_timestamp", "Webhook timestamp outside tolerance")
+				return
+			}
+
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "." + nonce + "."))
+			mac.Write(body)
+			if !hmac.Equal([]byte(signature), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+				fail(http.StatusUnauthorized, "INVALID_SIGNATURE", "bad_signature", "Invalid webhook signature")
+				return
+			}
+
+			// A nonce only needs remembering while its timestamp is still accepted
+			fresh, err := nonces.SetNX(r.Context(), "webhook:nonce:"+source+":"+nonce, 1, 2*tolerance)
+			if err != nil {
+				log.Error().Err(err).Str("source", source).Msg("Failed to check webhook nonce")
+				respondError(w, http.StatusServiceUnavailable, "INTERNAL_ERROR", "Unable to verify webhook")
+				return
+			}
+			if !fresh {
+				fail(http.StatusConflict, "WEBHOOK_REPLAYED", "replayed_nonce", "Webhook delivery already received")
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// recordWebhookFailure counts a refused delivery and alerts once a
+// source's failures across replicas in the current minute reach the
+// threshold
+func recordWebhookFailure(r *http.Request, rdb *redis.Client, source, reason string) {
+	ctx := r.Context()
+	client := rdb.Client()
+
+	log.Warn().
+		Str("tag", "webhook_verification_failed").
+		Str("source", source).
+		Str("reason", reason).
+		Str("remote_addr", r.RemoteAddr).
+		Msg("Webhook delivery refused")
+
+	if err := client.HIncrBy(ctx, WebhookFailuresKey, source+":"+reason, 1).Err(); err != nil {
+		log.Error().Err(err).Msg("Failed to count webhook failure")
+		return
+	}
+
+	minuteKey := fmt.Sprintf("webhook:failures:%s:%d", source, time.Now().Unix()/60)
+	count, err := client.Incr(ctx, minuteKey).Result()
+	if err != nil {
+		return
+	}
+	if count == 1 {
+		client.Expire(ctx, minuteKey, 2*time.Minute)
+	}
+	if count == webhookAlertThreshold {
+		log.Error().
+			Bool("alert", true).
+			Str("source", source).
+			Int64("failures", count).
+			Msg("Webhook verification failures spiking")
+	}
+}
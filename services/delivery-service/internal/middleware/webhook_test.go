@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type memoryNonces map[string]bool
+
+func (m memoryNonces) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if m[key] {
+		return false, nil
+	}
+	m[key] = true
+	return true, nil
+}
+
+func signWebhook(secret string, at time.Time, nonce, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(at.Unix(), 10) + "." + nonce + "." + body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhookRequest(secret string, at time.Time, nonce, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/payment", strings.NewReader(body))
+	req.Header.Set(HeaderWebhookTimestamp, strconv.FormatInt(at.Unix(), 10))
+	req.Header.Set(HeaderWebhookNonce, nonce)
+	req.Header.Set(HeaderWebhookSignature, signWebhook(secret, at, nonce, body))
+	return req
+}
+
+func TestWebhookSignature(t *testing.T) {
+	const secret = "payment-secret"
+	body := `{"event":"payment.completed"}`
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+		wantReason string
+	}{
+		{"signed", newWebhookRequest(secret, now, "n1", body), http.StatusOK, ""},
+		{"sha256 prefix optional", func() *http.Request {
+			req := newWebhookRequest(secret, now, "n2", body)
+			req.Header.Set(HeaderWebhookSignature, strings.TrimPrefix(req.Header.Get(HeaderWebhookSignature), "sha256="))
+			return req
+		}(), http.StatusOK, ""},
+		{"wrong secret", newWebhookRequest("other-secret", now, "n3", body), http.StatusUnauthorized, "bad_signature"},
+		{"body changed", func() *http.Request {
+			req := newWebhookRequest(secret, now, "n4", body)
+			req.Body = io.NopCloser(strings.NewReader(`{"event":"payment.refunded"}`))
+			return req
+		}(), http.StatusUnauthorized, "bad_signature"},
+		{"signed too long ago", newWebhookRequest(secret, now.Add(-6*time.Minute), "n5", body), http.StatusUnauthorized, "stale_timestamp"},
+		{"signed in the future", newWebhookRequest(secret, now.Add(6*time.Minute), "n6", body), http.StatusUnauthorized, "stale_timestamp"},
+		{"no nonce", newWebhookRequest(secret, now, "", body), http.StatusUnauthorized, "missing_header"},
+		{"replayed", newWebhookRequest(secret, now, "n1", body), http.StatusConflict, "replayed_nonce"},
+	}
+
+	var reasons []string
+	var received []string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The verified body is restored for the handler to read
+		raw, _ := io.ReadAll(r.Body)
+		received = append(received, string(raw))
+		w.WriteHeader(http.StatusOK)
+	})
+	guard := webhookSignature(memoryNonces{}, "payment", secret, 5*time.Minute, func(r *http.Request, reason string) {
+		reasons = append(reasons, reason)
+	})(next)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasons, received = nil, nil
+			rec := httptest.NewRecorder()
+			guard.ServeHTTP(rec, tt.req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantReason == "" {
+				if len(received) != 1 || received[0] != body {
+					t.Errorf("handler received %q, want the signed body", received)
+				}
+				if len(reasons) != 0 {
+					t.Errorf("failures = %v, want none", reasons)
+				}
+				return
+			}
+			if len(received) != 0 {
+				t.Error("refused delivery reached the handler")
+			}
+			if len(reasons) != 1 || reasons[0] != tt.wantReason {
+				t.Errorf("failures = %v, want %s", reasons, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestWebhookSignatureUnknownSource(t *testing.T) {
+	var reasons []string
+	guard := webhookSignature(memoryNonces{}, "payment", "", 5*time.Minute, func(r *http.Request, reason string) {
+		reasons = append(reasons, reason)
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("delivery from an unconfigured source reached the handler")
+	}))
+
+	rec := httptest.NewRecorder()
+	guard.ServeHTTP(rec, newWebhookRequest("", time.Now(), "n1", "{}"))
+	if rec.Code != http.StatusUnauthorized || len(reasons) != 1 || reasons[0] != "unknown_source" {
+		t.Errorf("status = %d, failures = %v, want 401 unknown_source", rec.Code, reasons)
+	}
+}
+
+func TestParseWebhookSecrets(t *testing.T) {
+	secrets, err := ParseWebhookSecrets(" Payment=abc, order=d=ef ")
+	if err != nil {
+		t.Fatalf("ParseWebhookSecrets() error = %v", err)
+	}
+	if secrets["payment"] != "abc" || secrets["order"] != "d=ef" {
+		t.Errorf("ParseWebhookSecrets() = %v", secrets)
+	}
+	if _, err := ParseWebhookSecrets("payment"); err == nil {
+		t.Error("ParseWebhookSecrets(\"payment\") succeeded, want error")
+	}
+}
@@ -22,6 +22,7 @@ const (
 	DeliveryStatusDelivered    DeliveryStatus = "DELIVERED"
 	DeliveryStatusCancelled    DeliveryStatus = "CANCELLED"
 	DeliveryStatusFailed       DeliveryStatus = "FAILED"
+	DeliveryStatusRetryScheduled DeliveryStatus = "RETRY_SCHEDULED" // failed attempt, offered again at next_attempt_at
 )
 
 // DeliveryType represents the type of delivery
@@ -32,6 +33,7 @@ const (
 	DeliveryTypeExpress  DeliveryType = "EXPRESS"
 	DeliveryTypeSameDay  DeliveryType = "SAME_DAY"
 	DeliveryTypeScheduled DeliveryType = "SCHEDULED"
+	DeliveryTypePerishable DeliveryType = "PERISHABLE" // cold chain, with a transit time limit
 )
 
 // PackageSize represents package size category
@@ -67,6 +69,26 @@ type Location struct {
 	Country   string  `json:"country"`
 	PostalCode string `json:"postalCode,omitempty"`
 	PlaceID   string  `json:"placeId,omitempty"`
+	Landmarks []Landmark `json:"landmarks,omitempty"`
+	Directions string    `json:"directions,omitempty"` // free text for the driver, e.g. "blue gate, second floor"
+	PlusCode   string    `json:"plusCode,omitempty"`   // in place of coordinates; short codes are read near the other end
+	Correction *LocationCorrection `json:"correction,omitempty"` // set when the coordinates were moved to where couriers found the address
+}
+
+// LocationCorrection records that a location's coordinates were replaced
+// with ones couriers reported, and how far to trust them
+type LocationCorrection struct {
+	OriginalLatitude  float64 `json:"originalLatitude"`
+	OriginalLongitude float64 `json:"originalLongitude"`
+	Confidence        string  `json:"confidence"` // LOW, MEDIUM or HIGH
+	Reports           int     `json:"reports"`
+}
+
+// Landmark is a well-known place an address is given relative to, as in
+// "opposite GTBank"
+type Landmark struct {
+	Relation string `json:"relation"` // OPPOSITE, BESIDE, BEHIND, IN_FRONT_OF, NEAR, AFTER, BEFORE, INSIDE or OFF
+	Name     string `json:"name"`
 }
 
 // ContactInfo represents contact information
@@ -78,13 +100,45 @@ type ContactInfo struct {
 
 // Package represents package details
 type Package struct {
-	Description string      `json:"description"`
-	Size        PackageSize `json:"size"`
-	Weight      float64     `json:"weight"` // kg
-	Dimensions  *Dimensions `json:"dimensions,omitempty"`
-	Value       float64     `json:"value,omitempty"` // Declared value
-	Fragile     bool        `json:"fragile"`
-	RequiresPOD bool        `json:"requiresPod"` // Proof of delivery
+	Description     string          `json:"description"`
+	Size            PackageSize     `json:"size"`
+	Weight          float64         `json:"weight"`                    // kg
+	Dimensions      *Dimensions     `json:"dimensions,omitempty"`
+	Value           float64         `json:"value,omitempty"`           // Declared value
+	Fragile         bool            `json:"fragile"`
+	RequiresPOD     bool            `json:"requiresPod"`               // Proof of delivery
+	Category        PackageCategory `json:"category,omitempty"`
+	Perishable      bool            `json:"perishable"`
+	Contents        []PackageItem   `json:"contents,omitempty"`
+	MinRecipientAge int             `json:"minRecipientAge,omitempty"` // ID checked at handover, e.g. for alcohol
+}
+
+// PackageCategory is what a package or item holds
+type PackageCategory string
+
+const (
+	PackageCategoryDocuments   PackageCategory = "DOCUMENTS"
+	PackageCategoryElectronics PackageCategory = "ELECTRONICS"
+	PackageCategoryClothing    PackageCategory = "CLOTHING"
+	PackageCategoryFood        PackageCategory = "FOOD"
+	PackageCategoryMedicine    PackageCategory = "MEDICINE"
+	PackageCategoryCosmetics   PackageCategory = "COSMETICS"
+	PackageCategoryAlcohol     PackageCategory = "ALCOHOL"
+	PackageCategoryCash        PackageCategory = "CASH"
+	PackageCategoryWeapons     PackageCategory = "WEAPONS"
+	PackageCategoryHazardous   PackageCategory = "HAZARDOUS"
+	PackageCategoryOther       PackageCategory = "OTHER"
+)
+
+// PackageItem is one line of a package's declared contents
+type PackageItem struct {
+	Category         PackageCategory `json:"category"`
+	Description      string          `json:"description,omitempty"`
+	Quantity         int             `json:"quantity,omitempty"`
+	DeclaredValue    float64         `json:"declaredValue"` // total for the line
+	Fragile          bool            `json:"fragile"`
+	Perishable       bool            `json:"perishable"`
+	PrescriptionOnly bool            `json:"prescriptionOnly,omitempty"` // needs a prescription and a regulated delivery
 }
 
 // Dimensions represents package dimensions
@@ -0,0 +1,308 @@
+/*
+ * Background Workers
+ */
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// How often the leader renews its lease and followers try to take it
+	electionInterval = 10 * time.Second
+
+	// Longest a leader waits to hand over its lease on shutdown
+	resignTimeout = 5 * time.Second
+)
+
+// Job is a unit of background work
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+
+	// EveryReplica runs the job on every replica rather than the leader
+	// only, for work on state each replica keeps for itself
+	EveryReplica bool
+
+	// RunOnStart also runs the job as soon as the scheduler starts, for
+	// jobs whose results are needed before their first scheduled run
+	RunOnStart bool
+
+	// Timeout bounds a single run; zero leaves it unbounded
+	Timeout time.Duration
+}
+
+// JobStats are a job's run counts and latest outcome on this replica
+type JobStats struct {
+	Name            string     `json:"name"`
+	Schedule        string     `json:"schedule"`
+	EveryReplica    bool       `json:"everyReplica"`
+	Running         bool       `json:"running"`
+	Runs            int64      `json:"runs"`
+	Failures        int64      `json:"failures"`
+	Panics          int64      `json:"panics"`
+	Skipped         int64      `json:"skipped"` // runs due while another replica led
+	LastStartedAt   *time.Time `json:"lastStartedAt,omitempty"`
+	LastDurationMs  int64      `json:"lastDurationMs"`
+	LastError       string     `json:"lastError,omitempty"`
+	LastSucceededAt *time.Time `json:"lastSucceededAt,omitempty"`
+	NextRunAt       time.Time  `json:"nextRunAt"`
+}
+
+// Status is the scheduler's view of this replica
+type Status struct {
+	Service string      `json:"service"`
+	Replica string      `json:"replica"`
+	Leader  bool        `json:"leader"`
+	Jobs    []*JobStats `json:"jobs"`
+}
+
+type job struct {
+	Job
+	mu    sync.Mutex
+	stats JobStats
+}
+
+// Scheduler runs registered jobs on their schedules. Singleton jobs run
+// only while this replica leads, and a run in progress is cancelled when
+// leadership is lost. A job that panics is recovered and counted without
+// affecting the others.
+type Scheduler struct {
+	service string
+	replica string
+	elector Elector
+
+	mu      sync.Mutex
+	jobs    []*job
+	leader  bool
+	term    context.Context
+	endTerm context.CancelFunc
+	baseCtx context.Context
+	wg      sync.WaitGroup
+}
+
+// New creates a scheduler for a service. replica names this instance in
+// logs and status.
+func New(service, replica string, elector Elector) *Scheduler {
+	return &Scheduler{
+		service: service,
+		replica: replica,
+		elector: elector,
+	}
+}
+
+// Register adds a job. Jobs must be registered before Start.
+func (s *Scheduler) Register(j Job) {
+	if j.Name == "" || j.Schedule == nil || j.Run == nil {
+		panic("worker: job needs a name, schedule and run function")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, &job{
+		Job: j,
+		stats: JobStats{
+			Name:         j.Name,
+			Schedule:     j.Schedule.String(),
+			EveryReplica: j.EveryReplica,
+		},
+	})
+}
+
+// Start elects a leader and runs the jobs until ctx is cancelled, then
+// waits for runs in progress and hands over leadership
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.baseCtx = ctx
+	jobs := append([]*job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	// Know whether this replica leads before jobs that run on start do
+	s.elect(ctx)
+
+	for _, j := range jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+	log.Info().Str("replica", s.replica).Int("jobs", len(jobs)).Msg("Background workers started")
+
+	ticker := time.NewTicker(electionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			s.setLeader(false)
+
+			resignCtx, cancel := context.WithTimeout(context.Background(), resignTimeout)
+			if err := s.elector.Resign(resignCtx); err != nil {
+				log.Warn().Err(err).Msg("Failed to hand over worker leadership")
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			s.elect(ctx)
+		}
+	}
+}
+
+// IsLeader reports whether this replica runs the singleton jobs
+func (s *Scheduler) IsLeader() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.leader
+}
+
+// Status returns this replica's leadership and job stats
+func (s *Scheduler) Status() *Status {
+	s.mu.Lock()
+	status := &Status{
+		Service: s.service,
+		Replica: s.replica,
+		Leader:  s.leader,
+		Jobs:    make([]*JobStats, 0, len(s.jobs)),
+	}
+	jobs := append([]*job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	for _, j := range jobs {
+		j.mu.Lock()
+		stats := j.stats
+		j.mu.Unlock()
+		status.Jobs = append(status.Jobs, &stats)
+	}
+	sort.Slice(status.Jobs, func(a, b int) bool { return status.Jobs[a].Name < status.Jobs[b].Name })
+	return status
+}
+
+// elect takes or renews leadership. An election error steps down: the
+// lease may lapse before it can be renewed, and a missed run is safer
+// than two replicas running the same job.
+func (s *Scheduler) elect(ctx context.Context) {
+	electCtx, cancel := context.WithTimeout(ctx, electionInterval)
+	defer cancel()
+
+	leading, err := s.elector.TryLead(electCtx)
+	if err != nil && ctx.Err() == nil {
+		log.Warn().Err(err).Msg("Worker leader election failed")
+	}
+	s.setLeader(leading && err == nil)
+}
+
+func (s *Scheduler) setLeader(leading bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if leading == s.leader {
+		return
+	}
+
+	s.leader = leading
+	if leading {
+		s.term, s.endTerm = context.WithCancel(s.baseCtx)
+		log.Info().Str("service", s.service).Str("replica", s.replica).Msg("Became worker leader")
+		return
+	}
+	s.endTerm()
+	s.term, s.endTerm = nil, nil
+	log.Info().Str("service", s.service).Str("replica", s.replica).Msg("No longer worker leader")
+}
+
+// currentTerm returns a context cancelled when leadership ends, and false
+// when this replica does not lead
+func (s *Scheduler) currentTerm() (context.Context, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.term, s.leader
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	if j.RunOnStart {
+		s.execute(ctx, j)
+	}
+	for {
+		next := j.Schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Error().Str("job", j.Name).Msg("Job schedule never runs")
+			return
+		}
+		j.mu.Lock()
+		j.stats.NextRunAt = next
+		j.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.execute(ctx, j)
+	}
+}
+
+func (s *Scheduler) execute(ctx context.Context, j *job) {
+	runCtx := ctx
+	if !j.EveryReplica {
+		term, leading := s.currentTerm()
+		if !leading {
+			j.mu.Lock()
+			j.stats.Skipped++
+			j.mu.Unlock()
+			return
+		}
+		runCtx = term
+	}
+	if j.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, j.Timeout)
+		defer cancel()
+	}
+
+	started := time.Now().UTC()
+	j.mu.Lock()
+	j.stats.Running = true
+	j.stats.LastStartedAt = &started
+	j.mu.Unlock()
+
+	panicked, err := run(runCtx, j)
+	elapsed := time.Since(started)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.stats.Running = false
+	j.stats.Runs++
+	j.stats.LastDurationMs = elapsed.Milliseconds()
+	if panicked {
+		j.stats.Panics++
+	}
+	if err != nil {
+		j.stats.Failures++
+		j.stats.LastError = err.Error()
+		log.Error().Err(err).Str("job", j.Name).Dur("duration", elapsed).Msg("Background job failed")
+		return
+	}
+	j.stats.LastError = ""
+	j.stats.LastSucceededAt = &started
+}
+
+// run calls the job, turning a panic into an error
+func run(ctx context.Context, j *job) (panicked bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Str("job", j.Name).Bytes("stack", debug.Stack()).Msgf("Background job panicked: %v", r)
+			panicked, err = true, fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return false, j.Run(ctx)
+}
@@ -58,6 +58,7 @@ import {
 } from "./services/driver/earnings.service";
 import { FleetOwnerService } from "./services/driver/fleet.service";
 import { IncentiveService } from "./services/driver/incentives.service";
+import { DriverStatementsService } from "./services/driver/statements.service";
 
 const app = new Hono();
 
@@ -153,6 +154,7 @@ const earningsService = new DriverEarningsService(
 
 const driverServices = {
   earningsService,
+  statementsService: new DriverStatementsService(prisma),
   goalsService: new DriverGoalsService(
     prisma,
     earningsService,
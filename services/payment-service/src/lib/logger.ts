@@ -110,6 +110,7 @@ export const loanLogger = logger.child({ component: "loans" });
 export const savingsLogger = logger.child({ component: "savings" });
 export const billsLogger = logger.child({ component: "bills" });
 export const cardsLogger = logger.child({ component: "cards" });
+export const invoicingLogger = logger.child({ component: "invoicing" });
 export const subscriptionLogger = logger.child({ component: "subscription" });
 export const remittanceLogger = logger.child({ component: "remittance" });
 export const splitFareLogger = logger.child({ component: "split-fare" });
@@ -703,6 +703,7 @@ class NotificationClient {
     tripId: string,
     reason: string,
     checkId: string,
+    timeoutSeconds = 60,
   ): Promise<SendResult> {
     return this.send({
       userId,
@@ -717,7 +718,7 @@ class NotificationClient {
         reason,
         requiresResponse: true,
         responseOptions: ["I'm fine", "Need help"],
-        timeoutSeconds: 60,
+        timeoutSeconds,
       },
     });
   }
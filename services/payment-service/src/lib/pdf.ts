@@ -0,0 +1,167 @@
+/**
+ * Minimal PDF Writer
+ * UBI Payment System
+ *
+ * Just enough PDF to lay out text documents such as invoices and
+ * statements: A4 pages, Helvetica and Helvetica-Bold, text and rules.
+ * Coordinates are in points measured from the top-left of the page.
+ */
+
+// A4 in points
+export const PAGE_WIDTH = 595.28;
+export const PAGE_HEIGHT = 841.89;
+
+export interface PdfTextOptions {
+  size?: number;
+  bold?: boolean;
+  align?: "left" | "right";
+}
+
+// Helvetica advance widths (per 1000 em) for the characters that matter
+// when right-aligning amounts; everything else is approximated
+const NARROW = new Set([
+  " ",
+  ".",
+  ",",
+  ":",
+  ";",
+  "!",
+  "|",
+  "'",
+  "i",
+  "l",
+  "j",
+  "t",
+  "f",
+  "I",
+]);
+const WIDE = new Set(["m", "w", "M", "W"]);
+
+/**
+ * Approximate width of a string set in Helvetica at the given size
+ */
+export function textWidth(text: string, size: number): number {
+  let units = 0;
+  for (const ch of text) {
+    if (ch >= "0" && ch <= "9") {
+      units += 556;
+    } else if (NARROW.has(ch)) {
+      units += 278;
+    } else if (WIDE.has(ch)) {
+      units += 833;
+    } else if (ch >= "A" && ch <= "Z") {
+      units += 667;
+    } else {
+      units += 500;
+    }
+  }
+  return (units * size) / 1000;
+}
+
+/**
+ * Escape a string for a PDF literal. Standard fonts only cover Latin-1,
+ * so anything outside it is replaced.
+ */
+function escapeText(text: string): string {
+  let out = "";
+  for (const ch of text) {
+    const code = ch.codePointAt(0)!;
+    if (ch === "\\" || ch === "(" || ch === ")") {
+      out += `\\${ch}`;
+    } else if (code < 0x20 || (code >= 0x7f && code < 0xa0) || code > 0xff) {
+      out += "?";
+    } else {
+      out += ch;
+    }
+  }
+  return out;
+}
+
+function num(n: number): string {
+  return Number(n.toFixed(2)).toString();
+}
+
+export class PdfDocument {
+  private readonly pages: string[][] = [];
+
+  constructor() {
+    this.addPage();
+  }
+
+  get pageCount(): number {
+    return this.pages.length;
+  }
+
+  addPage(): void {
+    this.pages.push([]);
+  }
+
+  text(
+    x: number,
+    y: number,
+    text: string,
+    options: PdfTextOptions = {},
+  ): void {
+    const size = options.size ?? 10;
+    const font = options.bold ? "F2" : "F1";
+    const left = options.align === "right" ? x - textWidth(text, size) : x;
+    this.current().push(
+      `BT /${font} ${num(size)} Tf ${num(left)} ${num(PAGE_HEIGHT - y)} Td (${escapeText(text)}) Tj ET`,
+    );
+  }
+
+  line(x1: number, y1: number, x2: number, y2: number, width = 0.5): void {
+    this.current().push(
+      `${num(width)} w ${num(x1)} ${num(PAGE_HEIGHT - y1)} m ${num(x2)} ${num(PAGE_HEIGHT - y2)} l S`,
+    );
+  }
+
+  toBuffer(): Buffer {
+    // Objects 1-4 are the catalog, page tree and fonts; each page then
+    // takes a page object and a content stream
+    const objects: string[] = [];
+    const pageIds = this.pages.map((_, i) => 5 + i * 2);
+
+    objects.push("<< /Type /Catalog /Pages 2 0 R >>");
+    objects.push(
+      `<< /Type /Pages /Kids [${pageIds.map((id) => `${id} 0 R`).join(" ")}] /Count ${pageIds.length} >>`,
+    );
+    objects.push(
+      "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+    );
+    objects.push(
+      "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+    );
+
+    for (const [i, ops] of this.pages.entries()) {
+      const content = ops.join("\n");
+      objects.push(
+        `<< /Type /Page /Parent 2 0 R /MediaBox [0 0 ${num(PAGE_WIDTH)} ${num(PAGE_HEIGHT)}] ` +
+          `/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents ${pageIds[i]! + 1} 0 R >>`,
+      );
+      objects.push(
+        `<< /Length ${Buffer.byteLength(content, "latin1")} >>\nstream\n${content}\nendstream`,
+      );
+    }
+
+    let out = "%PDF-1.4\n";
+    const offsets: number[] = [];
+    for (const [i, body] of objects.entries()) {
+      offsets.push(Buffer.byteLength(out, "latin1"));
+      out += `${i + 1} 0 obj\n${body}\nendobj\n`;
+    }
+
+    const xref = Buffer.byteLength(out, "latin1");
+    out += `xref\n0 ${objects.length + 1}\n0000000000 65535 f \n`;
+    for (const offset of offsets) {
+      out += `${String(offset).padStart(10, "0")} 00000 n \n`;
+    }
+    out += `trailer\n<< /Size ${objects.length + 1} /Root 1 0 R >>\nstartxref\n${xref}\n%%EOF\n`;
+
+    return Buffer.from(out, "latin1");
+  }
+
+  private current(): string[] {
+    return this.pages[this.pages.length - 1]!;
+  }
+}
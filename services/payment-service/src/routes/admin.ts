@@ -22,12 +22,16 @@ import { PaystackService } from "../providers/paystack.service";
 import { FraudDetectionService } from "../services/fraud-detection.service";
 import { PayoutService } from "../services/payout.service";
 import { ReconciliationService } from "../services/reconciliation.service";
+import { SettlementReconciliationService } from "../services/settlement-reconciliation.service";
 import { SettlementService } from "../services/settlement.service";
 
 const adminRoutes = new Hono();
 
 // Initialize services
 const reconciliationService = new ReconciliationService(prisma);
+const settlementReconciliationService = new SettlementReconciliationService(
+  prisma,
+);
 const settlementService = new SettlementService(prisma);
 const payoutService = new PayoutService(prisma);
 const fraudService = new FraudDetectionService(prisma);
@@ -607,6 +611,79 @@ adminRoutes.post("/reconciliations/discrepancies/:id/resolve", async (c) => {
   });
 });
 
+/**
+ * POST /admin/reconciliations/settlement-reports
+ * Reconcile an uploaded provider settlement report
+ */
+adminRoutes.post("/reconciliations/settlement-reports", async (c) => {
+  const body = await c.req.json();
+
+  const schema = z.object({
+    provider: z.enum(["PAYSTACK", "FLUTTERWAVE", "MPESA"]),
+    currency: z.enum(["NGN", "KES", "GHS", "ZAR"]),
+    date: z.string(),
+    fileName: z.string().optional(),
+    content: z.string().min(1),
+  });
+
+  const parsed = schema.safeParse(body);
+  if (!parsed.success) {
+    return c.json(
+      {
+        success: false,
+        error: { code: "VALIDATION_ERROR", details: parsed.error.errors },
+      },
+      400,
+    );
+  }
+
+  const result = await settlementReconciliationService.reconcileReport(
+    parsed.data.provider as any,
+    parsed.data.currency as any,
+    new Date(parsed.data.date),
+    parsed.data.content,
+    parsed.data.fileName,
+  );
+
+  return c.json({
+    success: true,
+    data: result,
+  });
+});
+
+/**
+ * GET /admin/reconciliations/settlement-status
+ * Settlement report reconciliation status per provider, currency and day
+ */
+adminRoutes.get("/reconciliations/settlement-status", async (c) => {
+  const query = c.req.query();
+
+  const endDate = query.endDate ? new Date(query.endDate) : new Date();
+  const startDate = query.startDate
+    ? new Date(query.startDate)
+    : new Date(endDate.getTime() - 6 * 24 * 60 * 60 * 1000);
+
+  const statuses = await settlementReconciliationService.getSettlementStatus(
+    startDate,
+    endDate,
+    query.provider as any,
+  );
+
+  return c.json({
+    success: true,
+    data: {
+      statuses,
+      summary: {
+        matched: statuses.filter((s) => s.status === "MATCHED").length,
+        mismatched: statuses.filter((s) => s.status === "MISMATCHED").length,
+        resolved: statuses.filter((s) => s.status === "RESOLVED").length,
+        missingReports: statuses.filter((s) => s.status === "MISSING_REPORT")
+          .length,
+      },
+    },
+  });
+});
+
 // ============================================
 // Settlement Management
 // ============================================
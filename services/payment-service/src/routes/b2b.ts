@@ -14,6 +14,7 @@ import { Hono } from "hono";
 import { apiInfrastructureService } from "../services/api-infrastructure.service";
 import { billingService } from "../services/billing.service";
 import { corporateAccountsService } from "../services/corporate-accounts.service";
+import { corporateInvoicingService } from "../services/corporate-invoicing.service";
 import { deliveryApiService } from "../services/delivery-api.service";
 import { healthcareTransportService } from "../services/healthcare-transport.service";
 import { schoolTransportService } from "../services/school-transport.service";
@@ -791,6 +792,27 @@ b2bRoutes.get(
   }
 );
 
+b2bRoutes.get(
+  "/billing/invoices/:invoiceId/pdf",
+  authenticateApiKey("billing:read"),
+  async (c) => {
+    const orgId = c.get("organizationId");
+    const invoiceId = c.req.param("invoiceId");
+    const rendered = await corporateInvoicingService.renderInvoicePdf(
+      invoiceId,
+      orgId
+    );
+    if (!rendered) {
+      return c.json({ error: "not_found", message: "Invoice not found" }, 404);
+    }
+    return c.body(new Uint8Array(rendered.pdf), 200, {
+      "Content-Type": "application/pdf",
+      "Content-Disposition": `attachment; filename="${rendered.invoiceNumber}.pdf"`,
+      "Cache-Control": "private, no-store",
+    });
+  }
+);
+
 // Credits
 b2bRoutes.get(
   "/billing/credits",
@@ -14,6 +14,11 @@
 import { zValidator } from "@hono/zod-validator";
 import { Hono } from "hono";
 import { z } from "zod";
+import {
+  renderStatementPdf,
+  statementTitle,
+} from "../services/driver/statements.service";
+import { StatementPeriodType } from "../types/driver.types";
 
 // =============================================================================
 // VALIDATION SCHEMAS
@@ -25,6 +30,24 @@ import { z } from "zod";
 //   endDate: z.string().datetime().optional(),
 // });
 
+const StatementQuerySchema = z
+  .object({
+    period: z.enum(["annual", "quarterly"]).default("annual"),
+    year: z.coerce.number().int().min(2000).max(2100).optional(),
+    quarter: z.coerce.number().int().min(1).max(4).optional(),
+    format: z.enum(["json", "pdf"]).default("json"),
+  })
+  .refine((q) => q.quarter === undefined || q.period === "quarterly", {
+    message: "quarter is only valid for quarterly statements",
+  })
+  .refine(
+    (q) =>
+      q.period !== "quarterly" ||
+      q.year === undefined ||
+      q.quarter !== undefined,
+    { message: "quarter is required when year is given" }
+  );
+
 const GoalSchema = z.object({
   goalType: z.enum([
     "DAILY_EARNINGS",
@@ -196,6 +219,7 @@ type Variables = {
 
 export function createDriverRoutes(services: {
   earningsService: any;
+  statementsService: any;
   goalsService: any;
   incentiveService: any;
   benefitsService: any;
@@ -246,6 +270,44 @@ export function createDriverRoutes(services: {
     return c.json({ success: true, data: projection });
   });
 
+  // =============================================================================
+  // STATEMENTS ROUTES
+  // =============================================================================
+
+  app.get(
+    "/statements",
+    zValidator("query", StatementQuerySchema),
+    async (c) => {
+      const driverId = c.get("driver").id;
+      const query = c.req.valid("query");
+      const statement = await services.statementsService.getStatement(
+        driverId,
+        query.period === "quarterly"
+          ? StatementPeriodType.QUARTERLY
+          : StatementPeriodType.ANNUAL,
+        { year: query.year, quarter: query.quarter }
+      );
+      if (!statement) {
+        return c.json(
+          { success: false, error: "Statement period has not started" },
+          400
+        );
+      }
+
+      if (query.format === "pdf") {
+        const name = statementTitle(statement)
+          .toLowerCase()
+          .replace(/[^a-z0-9]+/g, "-");
+        return c.body(new Uint8Array(renderStatementPdf(statement)), 200, {
+          "Content-Type": "application/pdf",
+          "Content-Disposition": `attachment; filename="ubi-earnings-${name}.pdf"`,
+          "Cache-Control": "private, no-store",
+        });
+      }
+      return c.json({ success: true, data: statement });
+    }
+  );
+
   // =============================================================================
   // GOALS ROUTES
   // =============================================================================
@@ -373,6 +373,39 @@ paymentRoutes.post(
   },
 );
 
+/**
+ * GET /payments/by-reference/:referenceId - Get the captured payment for a
+ * ride, order, etc. (internal)
+ */
+paymentRoutes.get("/by-reference/:referenceId", async (c) => {
+  const referenceId = c.req.param("referenceId");
+
+  const payment = await prisma.payment.findFirst({
+    where: {
+      referenceId,
+      status: {
+        in: [PaymentStatus.COMPLETED, PaymentStatus.PARTIALLY_REFUNDED],
+      },
+    },
+    orderBy: { createdAt: "desc" },
+  });
+
+  if (!payment) {
+    return c.json(
+      {
+        success: false,
+        error: { code: "NOT_FOUND", message: "Payment not found" },
+      },
+      404,
+    );
+  }
+
+  return c.json({
+    success: true,
+    data: payment,
+  });
+});
+
 /**
  * GET /payments/:paymentId - Get payment details
  */
@@ -423,7 +456,10 @@ paymentRoutes.post("/:paymentId/refund", async (c) => {
     );
   }
 
-  if (payment.status !== PaymentStatus.COMPLETED) {
+  if (
+    payment.status !== PaymentStatus.COMPLETED &&
+    payment.status !== PaymentStatus.PARTIALLY_REFUNDED
+  ) {
     return c.json(
       {
         success: false,
@@ -436,8 +472,10 @@ paymentRoutes.post("/:paymentId/refund", async (c) => {
     );
   }
 
-  const refundAmount = amount || payment.amount;
-  if (refundAmount > payment.amount) {
+  // Partial refunds may follow each other up to what is left of the payment
+  const refundable = payment.amount - (payment.refundedAmount || 0);
+  const refundAmount = amount || refundable;
+  if (refundAmount > refundable) {
     return c.json(
       {
         success: false,
@@ -484,7 +522,7 @@ paymentRoutes.post("/:paymentId/refund", async (c) => {
           where: { id: payment.id },
           data: {
             status:
-              refundAmount === payment.amount
+              refundAmount === refundable
                 ? PaymentStatus.REFUNDED
                 : PaymentStatus.PARTIALLY_REFUNDED,
             refundedAmount: { increment: refundAmount },
@@ -499,6 +537,17 @@ paymentRoutes.post("/:paymentId/refund", async (c) => {
       payment.providerReference || payment.id,
       refundAmount * 100,
     );
+
+    await prisma.payment.update({
+      where: { id: payment.id },
+      data: {
+        status:
+          refundAmount === refundable
+            ? PaymentStatus.REFUNDED
+            : PaymentStatus.PARTIALLY_REFUNDED,
+        refundedAmount: { increment: refundAmount },
+      },
+    });
   }
 
   return c.json({
@@ -340,7 +340,8 @@ safetyRoutes.get("/verification/status/:userId", async (c) => {
  */
 safetyRoutes.post("/trip/monitor/start", async (c) => {
   try {
-    const { tripId, riderId, driverId } = await c.req.json();
+    const { tripId, riderId, driverId, expectedDuration, country } =
+      await c.req.json();
 
     if (!tripId || !riderId || !driverId) {
       return c.json(
@@ -357,7 +358,8 @@ safetyRoutes.post("/trip/monitor/start", async (c) => {
       riderId,
       driverId,
       expectedRoute: [],
-      expectedDuration: 0,
+      expectedDuration: Number(expectedDuration) || 0,
+      country,
     });
 
     return c.json({
@@ -646,6 +648,81 @@ safetyRoutes.get("/trip/:tripId/status", async (c) => {
   }
 });
 
+/**
+ * Get open trip safety flags for the monitoring queue
+ * GET /trip/flags
+ */
+safetyRoutes.get("/trip/flags", async (c) => {
+  try {
+    const flags = await tripMonitorService.getSafetyFlagQueue();
+
+    return c.json({
+      success: true,
+      data: flags,
+    });
+  } catch (error: any) {
+    safetyLogger.error({ err: error }, "Safety flag queue error");
+    return c.json(
+      {
+        success: false,
+        error: error.message || "Failed to get safety flags",
+      },
+      500,
+    );
+  }
+});
+
+/**
+ * Acknowledge or close a trip safety flag
+ * POST /trip/flags/:flagId/review
+ */
+safetyRoutes.post("/trip/flags/:flagId/review", async (c) => {
+  try {
+    const flagId = c.req.param("flagId");
+    const { agentId, action } = await c.req.json();
+
+    if (!agentId || (action !== "acknowledge" && action !== "close")) {
+      return c.json(
+        {
+          success: false,
+          error: "Missing required fields: agentId, action (acknowledge|close)",
+        },
+        400,
+      );
+    }
+
+    const flag = await tripMonitorService.reviewSafetyFlag(
+      flagId,
+      agentId,
+      action,
+    );
+
+    if (!flag) {
+      return c.json(
+        {
+          success: false,
+          error: "Flag not found or already closed",
+        },
+        404,
+      );
+    }
+
+    return c.json({
+      success: true,
+      data: flag,
+    });
+  } catch (error: any) {
+    safetyLogger.error({ err: error }, "Safety flag review error");
+    return c.json(
+      {
+        success: false,
+        error: error.message || "Failed to review safety flag",
+      },
+      500,
+    );
+  }
+});
+
 // =============================================================================
 // SOS EMERGENCY ROUTES
 // =============================================================================
@@ -838,6 +915,56 @@ safetyRoutes.get("/sos/:sosId", async (c) => {
   }
 });
 
+/**
+ * Acknowledge a dispatch partner escalation (called by the partner)
+ * POST /sos/:sosId/partner-ack
+ */
+safetyRoutes.post("/sos/:sosId/partner-ack", async (c) => {
+  try {
+    const sosId = c.req.param("sosId");
+    const { reference, partnerIncidentId } = await c.req.json();
+
+    if (!reference) {
+      return c.json(
+        {
+          success: false,
+          error: "Missing required field: reference",
+        },
+        400,
+      );
+    }
+
+    const acknowledged = await sosEmergencyService.acknowledgePartnerEscalation(
+      sosId,
+      reference,
+      partnerIncidentId,
+    );
+
+    if (!acknowledged) {
+      return c.json(
+        {
+          success: false,
+          error: "No matching escalation for this SOS",
+        },
+        404,
+      );
+    }
+
+    return c.json({
+      success: true,
+    });
+  } catch (error: any) {
+    safetyLogger.error({ err: error }, "SOS partner ack error");
+    return c.json(
+      {
+        success: false,
+        error: error.message || "Failed to acknowledge escalation",
+      },
+      500,
+    );
+  }
+});
+
 /**
  * Add emergency contact
  * POST /emergency-contacts
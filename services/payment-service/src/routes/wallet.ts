@@ -36,6 +36,14 @@ const transferSchema = z.object({
   description: z.string().max(200).optional(),
 });
 
+const creditSchema = z.object({
+  userId: z.string(),
+  amount: z.number().positive(),
+  currency: z.nativeEnum(Currency).default(Currency.NGN),
+  reference: z.string().min(1), // Refund ID in the calling service
+  description: z.string().max(200).optional(),
+});
+
 const paginationSchema = z.object({
   page: z.string().transform(Number).default("1"),
   limit: z.string().transform(Number).default("20"),
@@ -472,4 +480,64 @@ walletRoutes.post("/unlock", async (c) => {
   });
 });
 
+/**
+ * POST /wallets/credits - Credit a user's wallet with a platform-funded
+ * goodwill amount (internal). Idempotent on reference.
+ */
+walletRoutes.post("/credits", zValidator("json", creditSchema), async (c) => {
+  const { userId, amount, currency, reference, description } =
+    c.req.valid("json");
+
+  const wallet = await getOrCreateWallet(userId, currency);
+
+  const existing = await prisma.walletTransaction.findFirst({
+    where: { walletId: wallet.id, type: TransactionType.CREDIT, reference },
+  });
+  if (existing) {
+    return c.json({
+      success: true,
+      data: {
+        refundId: existing.id,
+        reference,
+        amount: existing.amount,
+        status: "completed",
+      },
+    });
+  }
+
+  const transaction = await prisma.$transaction(async (tx) => {
+    await tx.wallet.update({
+      where: { id: wallet.id },
+      data: { balance: { increment: amount } },
+    });
+
+    return tx.walletTransaction.create({
+      data: {
+        id: generateId("txn"),
+        walletId: wallet.id,
+        type: TransactionType.CREDIT,
+        amount,
+        currency,
+        status: TransactionStatus.COMPLETED,
+        reference,
+        description: description || "Goodwill credit",
+        metadata: { goodwill: true },
+      },
+    });
+  });
+
+  return c.json(
+    {
+      success: true,
+      data: {
+        refundId: transaction.id,
+        reference,
+        amount,
+        status: "completed",
+      },
+    },
+    201
+  );
+});
+
 export { walletRoutes };
@@ -567,9 +567,15 @@ export class BillingService extends EventEmitter {
         quantity: number;
         unitPrice: number;
         usageType?: UsageType;
+        amount?: number;
+        details?: Record<string, any>;
       }[];
       dueDate: Date;
       notes?: string;
+      periodStart?: Date;
+      periodEnd?: Date;
+      taxRate?: number;
+      currency?: string;
     },
   ): Promise<Invoice> {
     const lineItems: InvoiceLineItem[] = invoiceData.lineItems.map((item) => ({
@@ -577,13 +583,14 @@ export class BillingService extends EventEmitter {
       description: item.description,
       quantity: item.quantity,
       unitPrice: item.unitPrice,
-      amount: item.quantity * item.unitPrice,
+      amount: item.amount ?? item.quantity * item.unitPrice,
       usageType: item.usageType,
+      details: item.details,
     }));
 
     const subtotal = lineItems.reduce((sum, item) => sum + item.amount, 0);
-    const taxRate = 0.075; // 7.5% VAT
-    const taxAmount = Math.round(subtotal * taxRate);
+    const taxRate = invoiceData.taxRate ?? 0.075; // 7.5% Nigerian VAT
+    const taxAmount = Math.round(subtotal * taxRate * 100) / 100;
     const total = subtotal + taxAmount;
 
     const invoice: Invoice = {
@@ -591,8 +598,8 @@ export class BillingService extends EventEmitter {
       invoiceNumber: this.generateInvoiceNumber(),
       organizationId,
       status: "DRAFT",
-      periodStart: new Date(),
-      periodEnd: new Date(),
+      periodStart: invoiceData.periodStart ?? new Date(),
+      periodEnd: invoiceData.periodEnd ?? new Date(),
       subtotal,
       taxAmount,
       taxRate,
@@ -601,7 +608,7 @@ export class BillingService extends EventEmitter {
       total,
       amountPaid: 0,
       amountDue: total,
-      currency: "NGN",
+      currency: invoiceData.currency ?? "NGN",
       lineItems,
       dueDate: invoiceData.dueDate,
       notes: invoiceData.notes,
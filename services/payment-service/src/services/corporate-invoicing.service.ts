@@ -0,0 +1,599 @@
+/**
+ * UBI Corporate Invoicing Service
+ *
+ * Monthly invoices for corporate accounts:
+ * - Aggregates completed business rides per billing period
+ * - Computes VAT at the organization's country rate
+ * - Renders invoice PDFs with per-cost-center line items and a trip log
+ * - Publishes invoice-issued events for the billing email flow
+ */
+
+import { EventEmitter } from "node:events";
+import { invoicingLogger } from "../lib/logger";
+import { PAGE_WIDTH, PdfDocument } from "../lib/pdf";
+import { redis } from "../lib/redis";
+import type { CorporateTrip, Invoice, Organization } from "../types/b2b.types";
+import { billingService, type BillingService } from "./billing.service";
+import {
+  corporateAccountsService,
+  type CorporateAccountsService,
+} from "./corporate-accounts.service";
+
+// =============================================================================
+// CONSTANTS
+// =============================================================================
+
+/**
+ * Standard VAT rates by organization country. Rides are invoiced at the
+ * standard rate; an organization in a country missing here is not invoiced
+ * until its rate is added.
+ */
+export const VAT_RATES: Record<string, number> = {
+  NG: 0.075,
+  GH: 0.15,
+  KE: 0.16,
+  ZA: 0.15,
+  RW: 0.18,
+  UG: 0.18,
+  TZ: 0.18,
+  ET: 0.15,
+  EG: 0.14,
+  CI: 0.18,
+  SN: 0.18,
+  CM: 0.1925,
+  MA: 0.2,
+  ZM: 0.16,
+  GB: 0.2,
+  US: 0,
+};
+
+/** Channel the billing email flow subscribes to */
+export const INVOICE_ISSUED_CHANNEL = "invoice:issued";
+
+const TRIP_PAGE_SIZE = 500;
+const UNASSIGNED_COST_CENTER = "Unassigned";
+
+// =============================================================================
+// TYPES
+// =============================================================================
+
+export interface InvoiceIssuedEvent {
+  type: "invoice.issued";
+  invoiceId: string;
+  invoiceNumber: string;
+  organizationId: string;
+  organizationName: string;
+  billingEmail: string;
+  periodStart: string;
+  periodEnd: string;
+  currency: string;
+  subtotal: number;
+  taxAmount: number;
+  total: number;
+  dueDate: string;
+  pdfUrl: string;
+  issuedAt: string;
+}
+
+export interface InvoiceRunResult {
+  periodStart: Date;
+  periodEnd: Date;
+  issued: Invoice[];
+  skipped: { organizationId: string; reason: string }[];
+  failed: { organizationId: string; error: string }[];
+}
+
+interface EventPublisher {
+  publish(channel: string, message: string): Promise<unknown>;
+}
+
+interface InvoicedTrip {
+  id: string;
+  date: string;
+  member: string;
+  route: string;
+  projectCode?: string;
+  amount: number;
+}
+
+// =============================================================================
+// HELPERS
+// =============================================================================
+
+function roundMoney(amount: number): number {
+  return Math.round(amount * 100) / 100;
+}
+
+function isoDate(date: Date): string {
+  return date.toISOString().split("T")[0]!;
+}
+
+// Periods are half-open, so the last day billed is the day before the end
+function periodLabel(start: Date, end: Date): string {
+  return `${isoDate(start)} to ${isoDate(new Date(end.getTime() - 1))}`;
+}
+
+/**
+ * The calendar month (UTC) before the one containing `now`, as a
+ * half-open [start, end) range
+ */
+export function previousMonth(now: Date): { start: Date; end: Date } {
+  const end = new Date(Date.UTC(now.getUTCFullYear(), now.getUTCMonth(), 1));
+  const start = new Date(
+    Date.UTC(end.getUTCFullYear(), end.getUTCMonth() - 1, 1),
+  );
+  return { start, end };
+}
+
+export function formatAmount(amount: number, currency: string): string {
+  const formatted = amount.toLocaleString("en-US", {
+    minimumFractionDigits: 2,
+    maximumFractionDigits: 2,
+  });
+  return `${currency} ${formatted}`;
+}
+
+// =============================================================================
+// CORPORATE INVOICING SERVICE
+// =============================================================================
+
+export class CorporateInvoicingService extends EventEmitter {
+  // Organization + period start -> invoice, so a rerun of the job does not
+  // issue a second invoice for the same month
+  private readonly periodInvoices: Map<string, string> = new Map();
+  private readonly invoicedTrips: Set<string> = new Set();
+
+  constructor(
+    private readonly accounts: CorporateAccountsService,
+    private readonly billing: BillingService,
+    private readonly publisher: EventPublisher,
+  ) {
+    super();
+  }
+
+  /**
+   * Issue last month's invoices for every active monthly-billed
+   * organization. Safe to run more than once for the same month.
+   */
+  async runMonthlyInvoicing(now: Date = new Date()): Promise<InvoiceRunResult> {
+    const { start, end } = previousMonth(now);
+    const result: InvoiceRunResult = {
+      periodStart: start,
+      periodEnd: end,
+      issued: [],
+      skipped: [],
+      failed: [],
+    };
+
+    for (const organization of await this.listActiveOrganizations()) {
+      if (organization.billingCycle !== "MONTHLY") {
+        continue;
+      }
+      try {
+        const invoice = await this.issueInvoice(organization.id, start, end);
+        if (invoice) {
+          result.issued.push(invoice);
+        } else {
+          result.skipped.push({
+            organizationId: organization.id,
+            reason: "already invoiced or no rides",
+          });
+        }
+      } catch (error) {
+        const message =
+          error instanceof Error ? error.message : "Unknown error";
+        invoicingLogger.error(
+          { err: error, organizationId: organization.id },
+          "Failed to issue corporate invoice",
+        );
+        result.failed.push({
+          organizationId: organization.id,
+          error: message,
+        });
+      }
+    }
+
+    invoicingLogger.info(
+      {
+        periodStart: isoDate(start),
+        issued: result.issued.length,
+        skipped: result.skipped.length,
+        failed: result.failed.length,
+      },
+      "Monthly corporate invoicing complete",
+    );
+
+    return result;
+  }
+
+  /**
+   * Issue an invoice for an organization's business rides completed in
+   * [periodStart, periodEnd). Returns null when the period is already
+   * invoiced or had no billable rides.
+   */
+  async issueInvoice(
+    organizationId: string,
+    periodStart: Date,
+    periodEnd: Date,
+  ): Promise<Invoice | null> {
+    const periodKey = `${organizationId}:${periodStart.toISOString()}`;
+    if (this.periodInvoices.has(periodKey)) {
+      return null;
+    }
+
+    const organization = await this.accounts.getOrganization(organizationId);
+    if (!organization) {
+      throw new Error("Organization not found");
+    }
+
+    const taxRate = VAT_RATES[organization.country];
+    if (taxRate === undefined) {
+      throw new Error(`No VAT rate configured for ${organization.country}`);
+    }
+
+    const currency = organization.settings.currency;
+    const trips = await this.billableTrips(
+      organizationId,
+      periodStart,
+      periodEnd,
+    );
+    const inCurrency = trips.filter((t) => t.currency === currency);
+    if (inCurrency.length < trips.length) {
+      invoicingLogger.warn(
+        {
+          organizationId,
+          currency,
+          excluded: trips.length - inCurrency.length,
+        },
+        "Excluded rides not priced in the organization's billing currency",
+      );
+    }
+    if (inCurrency.length === 0) {
+      return null;
+    }
+
+    const issuedAt = new Date();
+    const dueDate = new Date(issuedAt);
+    dueDate.setUTCDate(dueDate.getUTCDate() + organization.paymentTermDays);
+
+    const draft = await this.billing.createInvoice(organizationId, {
+      lineItems: this.buildLineItems(inCurrency),
+      dueDate,
+      periodStart,
+      periodEnd,
+      taxRate,
+      currency,
+      notes: `Business rides from ${periodLabel(periodStart, periodEnd)}`,
+    });
+    draft.pdfUrl = `/b2b/billing/invoices/${draft.id}/pdf`;
+    const invoice = await this.billing.finalizeInvoice(draft.id);
+
+    this.periodInvoices.set(periodKey, invoice.id);
+    for (const trip of inCurrency) {
+      this.invoicedTrips.add(trip.id);
+    }
+
+    await this.publishIssued(invoice, organization);
+
+    invoicingLogger.info(
+      {
+        organizationId,
+        invoiceId: invoice.id,
+        invoiceNumber: invoice.invoiceNumber,
+        trips: inCurrency.length,
+        total: invoice.total,
+        currency,
+      },
+      "Corporate invoice issued",
+    );
+
+    return invoice;
+  }
+
+  /**
+   * Render an organization's invoice as a PDF. Returns null when the
+   * invoice does not exist or belongs to another organization.
+   */
+  async renderInvoicePdf(
+    invoiceId: string,
+    organizationId: string,
+  ): Promise<{ invoiceNumber: string; pdf: Buffer } | null> {
+    const invoice = await this.billing.getInvoice(invoiceId);
+    if (invoice?.organizationId !== organizationId) {
+      return null;
+    }
+    const organization = await this.accounts.getOrganization(organizationId);
+    if (!organization) {
+      return null;
+    }
+    return {
+      invoiceNumber: invoice.invoiceNumber,
+      pdf: renderInvoice(invoice, organization),
+    };
+  }
+
+  // ===========================================================================
+  // PRIVATE HELPERS
+  // ===========================================================================
+
+  private async listActiveOrganizations(): Promise<Organization[]> {
+    const organizations: Organization[] = [];
+    for (let page = 1; ; page++) {
+      const result = await this.accounts.listOrganizations(
+        { status: "ACTIVE" },
+        { page, limit: 100 },
+      );
+      organizations.push(...result.data);
+      if (!result.pagination.hasNext) {
+        return organizations;
+      }
+    }
+  }
+
+  private async billableTrips(
+    organizationId: string,
+    periodStart: Date,
+    periodEnd: Date,
+  ): Promise<CorporateTrip[]> {
+    const trips: CorporateTrip[] = [];
+    for (let page = 1; ; page++) {
+      const result = await this.accounts.listCorporateTrips(
+        organizationId,
+        { status: "completed" },
+        { page, limit: TRIP_PAGE_SIZE },
+      );
+      for (const trip of result.data) {
+        if (
+          trip.completedAt &&
+          trip.completedAt >= periodStart &&
+          trip.completedAt < periodEnd &&
+          trip.actualCost !== undefined &&
+          !this.invoicedTrips.has(trip.id)
+        ) {
+          trips.push(trip);
+        }
+      }
+      if (!result.pagination.hasNext) {
+        break;
+      }
+    }
+    return trips.sort(
+      (a, b) => a.completedAt!.getTime() - b.completedAt!.getTime(),
+    );
+  }
+
+  /**
+   * One line per cost center, each carrying its rides for the PDF's
+   * trip log
+   */
+  private buildLineItems(trips: CorporateTrip[]): {
+    description: string;
+    quantity: number;
+    unitPrice: number;
+    amount: number;
+    usageType: "ride";
+    details: { costCenterId?: string; trips: InvoicedTrip[] };
+  }[] {
+    const groups = new Map<
+      string,
+      { costCenterId?: string; name: string; trips: CorporateTrip[] }
+    >();
+    for (const trip of trips) {
+      const key = trip.costCenterId ?? "";
+      const group = groups.get(key) ?? {
+        costCenterId: trip.costCenterId,
+        name: trip.costCenterName ?? UNASSIGNED_COST_CENTER,
+        trips: [],
+      };
+      group.trips.push(trip);
+      groups.set(key, group);
+    }
+
+    return Array.from(groups.values())
+      .sort((a, b) => a.name.localeCompare(b.name))
+      .map((group) => {
+        const amount = roundMoney(
+          group.trips.reduce((sum, t) => sum + t.actualCost!, 0),
+        );
+        return {
+          description: `Business rides - ${group.name}`,
+          quantity: group.trips.length,
+          unitPrice: roundMoney(amount / group.trips.length),
+          amount,
+          usageType: "ride" as const,
+          details: {
+            costCenterId: group.costCenterId,
+            trips: group.trips.map((t) => ({
+              id: t.id,
+              date: isoDate(t.completedAt!),
+              member: t.memberName,
+              route: `${t.pickupAddress} -> ${t.dropoffAddress}`,
+              projectCode: t.projectCode,
+              amount: roundMoney(t.actualCost!),
+            })),
+          },
+        };
+      });
+  }
+
+  private async publishIssued(
+    invoice: Invoice,
+    organization: Organization,
+  ): Promise<void> {
+    const event: InvoiceIssuedEvent = {
+      type: "invoice.issued",
+      invoiceId: invoice.id,
+      invoiceNumber: invoice.invoiceNumber,
+      organizationId: organization.id,
+      organizationName: organization.legalName ?? organization.name,
+      billingEmail: organization.billingEmail ?? organization.email,
+      periodStart: invoice.periodStart.toISOString(),
+      periodEnd: invoice.periodEnd.toISOString(),
+      currency: invoice.currency,
+      subtotal: invoice.subtotal,
+      taxAmount: invoice.taxAmount,
+      total: invoice.total,
+      dueDate: invoice.dueDate!.toISOString(),
+      pdfUrl: invoice.pdfUrl!,
+      issuedAt: (invoice.issuedAt ?? new Date()).toISOString(),
+    };
+
+    this.emit("invoice:issued", event);
+
+    try {
+      await this.publisher.publish(
+        INVOICE_ISSUED_CHANNEL,
+        JSON.stringify(event),
+      );
+    } catch (error) {
+      // The invoice stands; the email can be resent from the invoice
+      invoicingLogger.error(
+        { err: error, invoiceId: invoice.id },
+        "Failed to publish invoice issued event",
+      );
+    }
+  }
+}
+
+// =============================================================================
+// PDF RENDERING
+// =============================================================================
+
+const MARGIN = 50;
+const RIGHT = PAGE_WIDTH - MARGIN;
+const PAGE_BOTTOM = 790;
+
+/**
+ * Lay out an invoice: header, bill-to, line items, totals and then the
+ * log of rides behind each line
+ */
+export function renderInvoice(
+  invoice: Invoice,
+  organization: Organization,
+): Buffer {
+  const pdf = new PdfDocument();
+  const money = (amount: number) => formatAmount(amount, invoice.currency);
+  let y = MARGIN;
+
+  const ensureSpace = (height: number) => {
+    if (y + height > PAGE_BOTTOM) {
+      pdf.addPage();
+      y = MARGIN;
+    }
+  };
+
+  pdf.text(MARGIN, y + 10, "UBI", { size: 22, bold: true });
+  pdf.text(RIGHT, y + 10, "TAX INVOICE", {
+    size: 16,
+    bold: true,
+    align: "right",
+  });
+  y += 40;
+
+  const meta: [string, string][] = [
+    ["Invoice number", invoice.invoiceNumber],
+    ["Issued", invoice.issuedAt ? isoDate(invoice.issuedAt) : "-"],
+    ["Due", invoice.dueDate ? isoDate(invoice.dueDate) : "-"],
+    ["Period", periodLabel(invoice.periodStart, invoice.periodEnd)],
+  ];
+  for (const [label, value] of meta) {
+    pdf.text(RIGHT - 200, y, label, { size: 9 });
+    pdf.text(RIGHT, y, value, { size: 9, bold: true, align: "right" });
+    y += 14;
+  }
+
+  y = MARGIN + 40;
+  pdf.text(MARGIN, y, "Bill to", { size: 9, bold: true });
+  y += 14;
+  const billTo = [
+    organization.legalName ?? organization.name,
+    organization.address?.line1,
+    organization.address?.city,
+    organization.country,
+    organization.vatNumber ? `VAT: ${organization.vatNumber}` : undefined,
+    organization.taxId ? `Tax ID: ${organization.taxId}` : undefined,
+  ];
+  for (const line of billTo) {
+    if (line) {
+      pdf.text(MARGIN, y, line, { size: 9 });
+      y += 12;
+    }
+  }
+
+  y = Math.max(y, MARGIN + 40 + 14 * (meta.length + 1)) + 24;
+
+  // Line items
+  const columns = { qty: 340, unit: 430, amount: RIGHT };
+  pdf.text(MARGIN, y, "Description", { size: 9, bold: true });
+  const heading = { size: 9, bold: true, align: "right" as const };
+  pdf.text(columns.qty, y, "Rides", heading);
+  pdf.text(columns.unit, y, "Avg. fare", heading);
+  pdf.text(columns.amount, y, "Amount", heading);
+  y += 6;
+  pdf.line(MARGIN, y, RIGHT, y);
+  y += 14;
+
+  for (const item of invoice.lineItems) {
+    ensureSpace(16);
+    pdf.text(MARGIN, y, item.description, { size: 9 });
+    const cell = { size: 9, align: "right" as const };
+    pdf.text(columns.qty, y, String(item.quantity), cell);
+    pdf.text(columns.unit, y, money(item.unitPrice), cell);
+    pdf.text(columns.amount, y, money(item.amount), cell);
+    y += 16;
+  }
+
+  ensureSpace(80);
+  pdf.line(columns.qty - 40, y - 6, RIGHT, y - 6);
+  y += 6;
+  const totals: [string, string, boolean][] = [
+    ["Subtotal", money(invoice.subtotal), false],
+    [
+      `VAT (${roundMoney(invoice.taxRate * 100)}%)`,
+      money(invoice.taxAmount),
+      false,
+    ],
+    ["Total due", money(invoice.amountDue), true],
+  ];
+  for (const [label, value, bold] of totals) {
+    pdf.text(columns.unit, y, label, { size: 10, bold, align: "right" });
+    pdf.text(columns.amount, y, value, { size: 10, bold, align: "right" });
+    y += 16;
+  }
+
+  // Ride log
+  for (const item of invoice.lineItems) {
+    const trips = (item.details?.trips ?? []) as InvoicedTrip[];
+    if (trips.length === 0) {
+      continue;
+    }
+    y += 20;
+    ensureSpace(40);
+    pdf.text(MARGIN, y, item.description, { size: 10, bold: true });
+    y += 16;
+    for (const trip of trips) {
+      ensureSpace(14);
+      pdf.text(MARGIN, y, trip.date, { size: 8 });
+      pdf.text(MARGIN + 60, y, truncate(trip.member, 24), { size: 8 });
+      pdf.text(MARGIN + 170, y, truncate(trip.route, 58), { size: 8 });
+      pdf.text(RIGHT, y, money(trip.amount), { size: 8, align: "right" });
+      y += 12;
+    }
+  }
+
+  return pdf.toBuffer();
+}
+
+function truncate(text: string, max: number): string {
+  return text.length > max ? `${text.slice(0, max - 3)}...` : text;
+}
+
+// =============================================================================
+// SINGLETON EXPORT
+// =============================================================================
+
+export const corporateInvoicingService = new CorporateInvoicingService(
+  corporateAccountsService,
+  billingService,
+  redis,
+);
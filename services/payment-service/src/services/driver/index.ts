@@ -13,6 +13,7 @@ export { CommunityService } from "./community.service";
 export { DriverEarningsService, DriverGoalsService } from "./earnings.service";
 export { FleetOwnerService } from "./fleet.service";
 export { IncentiveService } from "./incentives.service";
+export { DriverStatementsService } from "./statements.service";
 
 // -----------------------------------------
 // TYPE RE-EXPORTS
@@ -31,6 +32,7 @@ import { CommunityService } from "./community.service";
 import { DriverEarningsService, DriverGoalsService } from "./earnings.service";
 import { FleetOwnerService } from "./fleet.service";
 import { IncentiveService } from "./incentives.service";
+import { DriverStatementsService } from "./statements.service";
 
 export interface DriverExperienceConfig {
   database: any;
@@ -43,6 +45,7 @@ export interface DriverExperienceConfig {
 export class DriverExperiencePlatform {
   // Core Services
   public earnings: DriverEarningsService;
+  public statements: DriverStatementsService;
   public goals: DriverGoalsService;
   public incentives: IncentiveService;
   public benefits: DriverBenefitsService;
@@ -64,6 +67,8 @@ export class DriverExperiencePlatform {
       config.analyticsService,
     );
 
+    this.statements = new DriverStatementsService(config.database);
+
     this.goals = new DriverGoalsService(
       config.database,
       this.earnings,
@@ -0,0 +1,406 @@
+// ===========================================
+// UBI Driver Experience Platform
+// Tax Statements Service
+// ===========================================
+
+import { PAGE_WIDTH, PdfDocument } from "../../lib/pdf";
+import {
+  DriverStatementMonth,
+  DriverStatementTotals,
+  DriverTaxStatement,
+  StatementPeriodType,
+} from "../../types/driver.types";
+
+// -----------------------------------------
+// TAX YEARS
+// -----------------------------------------
+
+interface TaxYearStart {
+  month: number; // 0-based
+  day: number;
+  utcOffset: number; // hours, so periods turn over at local midnight
+}
+
+/**
+ * When each market's personal income tax year starts. Markets not listed
+ * use the calendar year in West Africa Time.
+ */
+export const TAX_YEARS: Record<string, TaxYearStart> = {
+  NG: { month: 0, day: 1, utcOffset: 1 },
+  GH: { month: 0, day: 1, utcOffset: 0 },
+  KE: { month: 0, day: 1, utcOffset: 3 },
+  RW: { month: 0, day: 1, utcOffset: 2 },
+  TZ: { month: 0, day: 1, utcOffset: 3 },
+  EG: { month: 0, day: 1, utcOffset: 2 },
+  CI: { month: 0, day: 1, utcOffset: 0 },
+  SN: { month: 0, day: 1, utcOffset: 0 },
+  ZA: { month: 2, day: 1, utcOffset: 2 }, // 1 March - end of February
+  UG: { month: 6, day: 1, utcOffset: 3 }, // 1 July - 30 June
+  ET: { month: 6, day: 8, utcOffset: 3 }, // 1 Hamle - 30 Sene
+};
+
+const DEFAULT_TAX_YEAR: TaxYearStart = { month: 0, day: 1, utcOffset: 1 };
+const DEFAULT_MARKET = "NG";
+
+const HOUR_MS = 60 * 60 * 1000;
+
+export interface StatementPeriod {
+  taxYear: string;
+  quarter?: number;
+  start: Date;
+  end: Date;
+}
+
+/**
+ * Start of the tax year that begins in calendar year `year`, plus
+ * `months` further months
+ */
+function taxYearOffset(rule: TaxYearStart, year: number, months = 0): Date {
+  return new Date(
+    Date.UTC(year, rule.month + months, rule.day) - rule.utcOffset * HOUR_MS,
+  );
+}
+
+function taxYearLabel(rule: TaxYearStart, year: number): string {
+  if (rule.month === 0 && rule.day === 1) {
+    return String(year);
+  }
+  return `${year}/${String((year + 1) % 100).padStart(2, "0")}`;
+}
+
+/**
+ * The calendar year in which the tax year containing `date` began
+ */
+export function taxYearOf(country: string, date: Date): number {
+  const rule = TAX_YEARS[country] ?? DEFAULT_TAX_YEAR;
+  const local = new Date(date.getTime() + rule.utcOffset * HOUR_MS);
+  const year = local.getUTCFullYear();
+  return date < taxYearOffset(rule, year) ? year - 1 : year;
+}
+
+/**
+ * Resolve a statement period in a market. Without a year this is the last
+ * tax year (or quarter) to have closed before `now`.
+ */
+export function resolveStatementPeriod(
+  country: string,
+  periodType: StatementPeriodType,
+  now: Date,
+  year?: number,
+  quarter?: number,
+): StatementPeriod {
+  const rule = TAX_YEARS[country] ?? DEFAULT_TAX_YEAR;
+  const current = taxYearOf(country, now);
+
+  if (periodType === StatementPeriodType.ANNUAL) {
+    const y = year ?? current - 1;
+    return {
+      taxYear: taxYearLabel(rule, y),
+      start: taxYearOffset(rule, y),
+      end: taxYearOffset(rule, y + 1),
+    };
+  }
+
+  let y = year ?? current;
+  let q = quarter ?? 1;
+  if (year === undefined && quarter === undefined) {
+    // Last closed quarter, which may be in the previous tax year
+    q = 0;
+    while (q < 4 && taxYearOffset(rule, y, (q + 1) * 3) <= now) {
+      q++;
+    }
+    if (q === 0) {
+      y -= 1;
+      q = 4;
+    }
+  }
+
+  return {
+    taxYear: taxYearLabel(rule, y),
+    quarter: q,
+    start: taxYearOffset(rule, y, (q - 1) * 3),
+    end: taxYearOffset(rule, y, q * 3),
+  };
+}
+
+// -----------------------------------------
+// STATEMENTS SERVICE
+// -----------------------------------------
+
+type Totals = Omit<DriverStatementMonth, "month">;
+
+function emptyTotals(): Totals {
+  return {
+    tripCount: 0,
+    grossFares: 0,
+    commissions: 0,
+    tips: 0,
+    incentives: 0,
+    netEarnings: 0,
+  };
+}
+
+// Ledger amounts are Decimal(10, 2); summing in minor units keeps the
+// statement to the cent of what was credited for payout
+function toMinor(value: unknown): number {
+  return Math.round(Number(value ?? 0) * 100);
+}
+
+function addTrip(totals: Totals, trip: any): void {
+  totals.tripCount += 1;
+  totals.grossFares += toMinor(trip.grossFare);
+  totals.commissions += toMinor(trip.commission);
+  totals.tips += toMinor(trip.tip);
+  totals.incentives += toMinor(trip.incentiveBonus);
+  totals.netEarnings += toMinor(trip.netEarnings);
+}
+
+function toMajor(totals: Totals): Totals {
+  return {
+    tripCount: totals.tripCount,
+    grossFares: totals.grossFares / 100,
+    commissions: totals.commissions / 100,
+    tips: totals.tips / 100,
+    incentives: totals.incentives / 100,
+    netEarnings: totals.netEarnings / 100,
+  };
+}
+
+export class DriverStatementsService {
+  constructor(private readonly db: any) {}
+
+  /**
+   * Build a driver's earnings statement for a tax period in their market.
+   * Figures are summed from the per-trip earnings ledger, with commission
+   * and net exactly as recorded when each trip paid out, rather than from
+   * the dashboard summaries. Returns null for a period that has not started.
+   */
+  async getStatement(
+    driverId: string,
+    periodType: StatementPeriodType,
+    options: { year?: number; quarter?: number } = {},
+    now: Date = new Date(),
+  ): Promise<DriverTaxStatement | null> {
+    const country = await this.getDriverCountry(driverId);
+    const period = resolveStatementPeriod(
+      country,
+      periodType,
+      now,
+      options.year,
+      options.quarter,
+    );
+    if (period.start > now) {
+      return null;
+    }
+
+    const trips = await this.db.tripEarning.findMany({
+      where: {
+        driverId,
+        completedAt: {
+          gte: period.start,
+          lt: period.end,
+        },
+      },
+      select: {
+        grossFare: true,
+        commission: true,
+        tip: true,
+        incentiveBonus: true,
+        netEarnings: true,
+        currency: true,
+        completedAt: true,
+      },
+      orderBy: {
+        completedAt: "asc",
+      },
+    });
+
+    const utcOffset = (TAX_YEARS[country] ?? DEFAULT_TAX_YEAR).utcOffset;
+    const byCurrency = new Map<
+      string,
+      { totals: Totals; months: Map<string, Totals> }
+    >();
+    for (const trip of trips) {
+      const entry = byCurrency.get(trip.currency) ?? {
+        totals: emptyTotals(),
+        months: new Map<string, Totals>(),
+      };
+      const month = new Date(trip.completedAt.getTime() + utcOffset * HOUR_MS)
+        .toISOString()
+        .slice(0, 7);
+      const monthTotals = entry.months.get(month) ?? emptyTotals();
+      addTrip(entry.totals, trip);
+      addTrip(monthTotals, trip);
+      entry.months.set(month, monthTotals);
+      byCurrency.set(trip.currency, entry);
+    }
+
+    const totals: DriverStatementTotals[] = Array.from(byCurrency.entries())
+      .sort(([a], [b]) => a.localeCompare(b))
+      .map(([currency, entry]) => ({
+        currency,
+        ...toMajor(entry.totals),
+        months: Array.from(entry.months.entries()).map(([month, t]) => ({
+          month,
+          ...toMajor(t),
+        })),
+      }));
+
+    return {
+      driverId,
+      country,
+      periodType,
+      taxYear: period.taxYear,
+      quarter: period.quarter,
+      periodStart: period.start,
+      periodEnd: period.end,
+      final: period.end <= now,
+      totals,
+      generatedAt: now,
+    };
+  }
+
+  private async getDriverCountry(driverId: string): Promise<string> {
+    const driver = await this.db.driver.findUnique({
+      where: { id: driverId },
+      select: { user: { select: { country: true } } },
+    });
+    return driver?.user?.country ?? DEFAULT_MARKET;
+  }
+}
+
+// -----------------------------------------
+// PDF RENDERING
+// -----------------------------------------
+
+const MARGIN = 50;
+const RIGHT = PAGE_WIDTH - MARGIN;
+const PAGE_BOTTOM = 790;
+
+function isoDate(date: Date): string {
+  return date.toISOString().slice(0, 10);
+}
+
+function formatAmount(amount: number, currency: string): string {
+  return `${currency} ${amount.toLocaleString("en-US", {
+    minimumFractionDigits: 2,
+    maximumFractionDigits: 2,
+  })}`;
+}
+
+export function statementTitle(statement: DriverTaxStatement): string {
+  return statement.quarter
+    ? `Q${statement.quarter} ${statement.taxYear}`
+    : `Tax year ${statement.taxYear}`;
+}
+
+/**
+ * Lay out a statement: the period, then per currency the headline totals
+ * and a month-by-month breakdown
+ */
+export function renderStatementPdf(statement: DriverTaxStatement): Buffer {
+  const pdf = new PdfDocument();
+  const lastDay = new Date(statement.periodEnd.getTime() - 1);
+  let y = MARGIN + 10;
+
+  pdf.text(MARGIN, y, "UBI", { size: 22, bold: true });
+  pdf.text(RIGHT, y, "DRIVER EARNINGS STATEMENT", {
+    size: 14,
+    bold: true,
+    align: "right",
+  });
+  y += 30;
+
+  const meta: [string, string][] = [
+    ["Period", statementTitle(statement)],
+    ["Driver", statement.driverId],
+    ["Market", statement.country],
+    ["Dates", `${isoDate(statement.periodStart)} to ${isoDate(lastDay)}`],
+    ["Generated", isoDate(statement.generatedAt)],
+  ];
+  for (const [label, value] of meta) {
+    pdf.text(MARGIN, y, label, { size: 9 });
+    pdf.text(MARGIN + 90, y, value, { size: 9, bold: true });
+    y += 14;
+  }
+  if (!statement.final) {
+    y += 4;
+    pdf.text(MARGIN, y, "Provisional: this period has not yet closed.", {
+      size: 9,
+      bold: true,
+    });
+    y += 14;
+  }
+
+  if (statement.totals.length === 0) {
+    y += 20;
+    pdf.text(MARGIN, y, "No earnings in this period.", { size: 10 });
+    return pdf.toBuffer();
+  }
+
+  const columns = [250, 310, 375, 430, 480, RIGHT];
+  const headings = [
+    "Trips",
+    "Gross",
+    "Commission",
+    "Tips",
+    "Incentives",
+    "Net",
+  ];
+
+  for (const totals of statement.totals) {
+    if (y + 160 + totals.months.length * 12 > PAGE_BOTTOM) {
+      pdf.addPage();
+      y = MARGIN;
+    }
+    y += 24;
+    const money = (amount: number) => formatAmount(amount, totals.currency);
+    pdf.text(MARGIN, y, `Earnings in ${totals.currency}`, {
+      size: 12,
+      bold: true,
+    });
+    y += 20;
+
+    const summary: [string, string][] = [
+      ["Gross fares", money(totals.grossFares)],
+      ["Platform commission", `- ${money(totals.commissions)}`],
+      ["Tips", money(totals.tips)],
+      ["Incentives", money(totals.incentives)],
+      ["Net earnings", money(totals.netEarnings)],
+      ["Trips", String(totals.tripCount)],
+    ];
+    for (const [label, value] of summary) {
+      const bold = label === "Net earnings";
+      pdf.text(MARGIN, y, label, { size: 10, bold });
+      pdf.text(300, y, value, { size: 10, bold, align: "right" });
+      y += 14;
+    }
+
+    y += 14;
+    pdf.text(MARGIN, y, "Month", { size: 8, bold: true });
+    const heading = { size: 8, bold: true, align: "right" as const };
+    headings.forEach((label, i) => {
+      pdf.text(columns[i]!, y, label, heading);
+    });
+    y += 5;
+    pdf.line(MARGIN, y, RIGHT, y);
+    y += 12;
+    for (const month of totals.months) {
+      const cells = [
+        String(month.tripCount),
+        month.grossFares.toFixed(2),
+        month.commissions.toFixed(2),
+        month.tips.toFixed(2),
+        month.incentives.toFixed(2),
+        month.netEarnings.toFixed(2),
+      ];
+      pdf.text(MARGIN, y, month.month, { size: 8 });
+      cells.forEach((cell, i) => {
+        pdf.text(columns[i]!, y, cell, { size: 8, align: "right" });
+      });
+      y += 12;
+    }
+  }
+
+  return pdf.toBuffer();
+}
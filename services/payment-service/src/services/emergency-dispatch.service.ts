@@ -0,0 +1,396 @@
+/**
+ * UBI Emergency Dispatch Partner Service
+ *
+ * Hands unresolved SOS incidents to local emergency dispatch partners:
+ * - Per-country partner registry with escalation delays
+ * - API adapter for partners with a dispatch endpoint
+ * - SMS bridge adapter for partners staffed by a dispatch desk
+ * - Dispatch payload with trip location and vehicle details
+ */
+
+import crypto from "node:crypto";
+import { sosLogger } from "../lib/logger";
+import { prisma } from "../lib/prisma";
+import {
+  EmergencyDispatchPayload,
+  EmergencyEscalation,
+  EmergencyPartnerChannel,
+  EmergencyPartnerConfig,
+  SOSIncident,
+} from "../types/safety.types";
+
+// Minutes an SOS may stay unresolved before escalating, where no partner rule applies
+const DEFAULT_ESCALATE_AFTER_MINUTES = 5;
+
+// Delivery attempts per escalation
+const MAX_DISPATCH_ATTEMPTS = 3;
+
+// =============================================================================
+// PARTNER REGISTRY
+// =============================================================================
+
+/**
+ * Emergency dispatch partners by country. Endpoints and desk numbers come
+ * from the environment; a partner without them is treated as unavailable.
+ */
+export const EMERGENCY_PARTNERS: Record<string, EmergencyPartnerConfig> = {
+  NG: {
+    country: "NG",
+    partnerName: "LASEMA Emergency Response",
+    channel: "API",
+    apiUrl: process.env.EMERGENCY_PARTNER_NG_URL,
+    apiKey: process.env.EMERGENCY_PARTNER_NG_KEY,
+    escalateAfterMinutes: 5,
+  },
+  KE: {
+    country: "KE",
+    partnerName: "Kenya Red Cross E-Plus",
+    channel: "SMS",
+    smsNumber: process.env.EMERGENCY_PARTNER_KE_SMS,
+    escalateAfterMinutes: 5,
+  },
+  GH: {
+    country: "GH",
+    partnerName: "National Ambulance Service Ghana",
+    channel: "SMS",
+    smsNumber: process.env.EMERGENCY_PARTNER_GH_SMS,
+    escalateAfterMinutes: 5,
+  },
+  ZA: {
+    country: "ZA",
+    partnerName: "ER24 Emergency Dispatch",
+    channel: "API",
+    apiUrl: process.env.EMERGENCY_PARTNER_ZA_URL,
+    apiKey: process.env.EMERGENCY_PARTNER_ZA_KEY,
+    escalateAfterMinutes: 4,
+  },
+  RW: {
+    country: "RW",
+    partnerName: "Rwanda National Police Dispatch",
+    channel: "SMS",
+    smsNumber: process.env.EMERGENCY_PARTNER_RW_SMS,
+    escalateAfterMinutes: 5,
+  },
+};
+
+// =============================================================================
+// PARTNER ADAPTERS
+// =============================================================================
+
+export interface EmergencyPartnerAdapter {
+  readonly channel: EmergencyPartnerChannel;
+  isConfigured(partner: EmergencyPartnerConfig): boolean;
+  dispatch(
+    partner: EmergencyPartnerConfig,
+    payload: EmergencyDispatchPayload,
+  ): Promise<{ partnerIncidentId?: string }>;
+}
+
+/**
+ * Posts a structured dispatch request to the partner's API
+ */
+export class ApiPartnerAdapter implements EmergencyPartnerAdapter {
+  readonly channel = "API" as const;
+
+  isConfigured(partner: EmergencyPartnerConfig): boolean {
+    return Boolean(partner.apiUrl);
+  }
+
+  async dispatch(
+    partner: EmergencyPartnerConfig,
+    payload: EmergencyDispatchPayload,
+  ): Promise<{ partnerIncidentId?: string }> {
+    const response = await fetch(partner.apiUrl as string, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        "Idempotency-Key": payload.reference,
+        ...(partner.apiKey && { Authorization: `Bearer ${partner.apiKey}` }),
+      },
+      body: JSON.stringify(payload),
+    });
+
+    if (!response.ok) {
+      throw new Error(`Partner API returned ${response.status}`);
+    }
+
+    const result = (await response.json().catch(() => ({}))) as any;
+    return { partnerIncidentId: result.incidentId || result.id };
+  }
+}
+
+/**
+ * Texts a compact incident summary to the partner's dispatch desk through
+ * the notification service
+ */
+export class SmsBridgeAdapter implements EmergencyPartnerAdapter {
+  readonly channel = "SMS" as const;
+
+  isConfigured(partner: EmergencyPartnerConfig): boolean {
+    return Boolean(partner.smsNumber);
+  }
+
+  async dispatch(
+    partner: EmergencyPartnerConfig,
+    payload: EmergencyDispatchPayload,
+  ): Promise<{ partnerIncidentId?: string }> {
+    const NOTIFICATION_SERVICE_URL =
+      process.env.NOTIFICATION_SERVICE_URL ||
+      "http://notification-service:4006";
+
+    const response = await fetch(
+      `${NOTIFICATION_SERVICE_URL}/api/v1/sms/send`,
+      {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/json",
+        },
+        body: JSON.stringify({
+          to: partner.smsNumber,
+          message: formatDispatchSMS(payload),
+          priority: "high",
+        }),
+      },
+    );
+
+    if (!response.ok) {
+      throw new Error(`SMS bridge returned ${response.status}`);
+    }
+
+    return {};
+  }
+}
+
+export function formatDispatchSMS(payload: EmergencyDispatchPayload): string {
+  const { lat, lng } = payload.location;
+  const parts = [
+    `UBI SOS ${payload.reference}`,
+    `Loc: ${lat.toFixed(5)},${lng.toFixed(5)}`,
+    `Track: ${payload.locationLink}`,
+    `Rider: ${payload.rider.name}${payload.rider.phone ? ` ${payload.rider.phone}` : ""}`,
+  ];
+
+  if (payload.vehicle) {
+    const v = payload.vehicle;
+    parts.push(`Vehicle: ${v.color} ${v.make} ${v.model} ${v.plateNumber}`);
+  }
+  if (payload.driver) {
+    parts.push(`Driver: ${payload.driver.name}`);
+  }
+
+  return parts.join(" | ");
+}
+
+// =============================================================================
+// EMERGENCY DISPATCH SERVICE
+// =============================================================================
+
+export class EmergencyDispatchService {
+  private readonly adapters: Map<
+    EmergencyPartnerChannel,
+    EmergencyPartnerAdapter
+  >;
+
+  constructor(
+    private readonly partners: Record<
+      string,
+      EmergencyPartnerConfig
+    > = EMERGENCY_PARTNERS,
+    adapters: EmergencyPartnerAdapter[] = [
+      new ApiPartnerAdapter(),
+      new SmsBridgeAdapter(),
+    ],
+  ) {
+    this.adapters = new Map(adapters.map((a) => [a.channel, a]));
+  }
+
+  getPartner(country: string): EmergencyPartnerConfig | null {
+    return this.partners[country] || null;
+  }
+
+  getEscalationDelayMs(country: string): number {
+    const minutes =
+      this.getPartner(country)?.escalateAfterMinutes ??
+      DEFAULT_ESCALATE_AFTER_MINUTES;
+    return minutes * 60 * 1000;
+  }
+
+  /**
+   * Create the initial escalation state for a new incident
+   */
+  schedule(country: string, triggeredAt: Date): EmergencyEscalation {
+    const partner = this.getPartner(country);
+
+    return {
+      status: "SCHEDULED",
+      country,
+      partnerName: partner?.partnerName,
+      channel: partner?.channel,
+      scheduledFor: new Date(
+        triggeredAt.getTime() + this.getEscalationDelayMs(country),
+      ),
+      attempts: 0,
+    };
+  }
+
+  /**
+   * Send the incident to the country's partner, retrying transient failures.
+   * Updates and returns the incident's escalation state.
+   */
+  async dispatch(
+    incident: SOSIncident,
+    locationLink: string,
+  ): Promise<EmergencyEscalation> {
+    const escalation = incident.emergencyEscalation;
+    if (!escalation) {
+      throw new Error(`Incident ${incident.id} has no escalation scheduled`);
+    }
+    const partner = this.getPartner(escalation.country);
+    const adapter = partner ? this.adapters.get(partner.channel) : undefined;
+
+    if (!partner || !adapter?.isConfigured(partner)) {
+      escalation.status = "UNAVAILABLE";
+      escalation.lastError = `No dispatch partner configured for ${escalation.country}`;
+      return escalation;
+    }
+
+    escalation.status = "IN_PROGRESS";
+    escalation.reference =
+      escalation.reference ||
+      `ESC-${crypto.randomBytes(6).toString("hex").toUpperCase()}`;
+
+    const payload = await this.buildPayload(
+      incident,
+      escalation,
+      locationLink,
+    );
+
+    while (escalation.attempts < MAX_DISPATCH_ATTEMPTS) {
+      escalation.attempts++;
+      try {
+        const result = await adapter.dispatch(partner, payload);
+        escalation.status = "SENT";
+        escalation.escalatedAt = new Date();
+        escalation.partnerIncidentId = result.partnerIncidentId;
+        escalation.lastError = undefined;
+
+        sosLogger.info(
+          {
+            incidentId: incident.id,
+            partner: partner.partnerName,
+            reference: escalation.reference,
+          },
+          "[EmergencyDispatch] Incident escalated to partner",
+        );
+        return escalation;
+      } catch (error) {
+        escalation.lastError =
+          error instanceof Error ? error.message : String(error);
+        sosLogger.warn(
+          {
+            incidentId: incident.id,
+            attempt: escalation.attempts,
+            err: error,
+          },
+          "[EmergencyDispatch] Partner dispatch attempt failed",
+        );
+      }
+    }
+
+    escalation.status = "FAILED";
+    sosLogger.error(
+      { incidentId: incident.id, partner: partner.partnerName },
+      "[EmergencyDispatch] Partner escalation failed",
+    );
+    return escalation;
+  }
+
+  /**
+   * Record a partner's acknowledgement. The partner must echo the reference
+   * sent with the dispatch.
+   */
+  acknowledge(
+    escalation: EmergencyEscalation,
+    reference: string,
+    partnerIncidentId?: string,
+  ): boolean {
+    if (!escalation.reference || escalation.reference !== reference) {
+      return false;
+    }
+
+    escalation.status = "ACKNOWLEDGED";
+    escalation.acknowledgedAt = new Date();
+    if (partnerIncidentId) {
+      escalation.partnerIncidentId = partnerIncidentId;
+    }
+    return true;
+  }
+
+  private async buildPayload(
+    incident: SOSIncident,
+    escalation: EmergencyEscalation,
+    locationLink: string,
+  ): Promise<EmergencyDispatchPayload> {
+    const payload: EmergencyDispatchPayload = {
+      reference: escalation.reference as string,
+      incidentId: incident.id,
+      country: escalation.country,
+      triggerMethod: incident.triggerMethod,
+      triggeredAt: incident.triggeredAt,
+      location: incident.currentLocation || incident.triggerLocation,
+      locationLink,
+      tripId: incident.tripId,
+      rider: { name: "UBI rider" },
+    };
+
+    if (!incident.tripId) {
+      return payload;
+    }
+
+    try {
+      const ride = await prisma.ride.findUnique({
+        where: { id: incident.tripId },
+        include: {
+          rider: { include: { user: true } },
+          driver: { include: { user: true, vehicle: true } },
+        },
+      });
+
+      if (ride?.rider?.user) {
+        payload.rider = {
+          name: `${ride.rider.user.firstName} ${ride.rider.user.lastName}`,
+          phone: ride.rider.user.phone,
+        };
+      }
+      if (ride?.driver?.user) {
+        payload.driver = {
+          name: `${ride.driver.user.firstName} ${ride.driver.user.lastName}`,
+          phone: ride.driver.user.phone,
+        };
+      }
+      if (ride?.driver?.vehicle) {
+        const v = ride.driver.vehicle;
+        payload.vehicle = {
+          make: v.make,
+          model: v.model,
+          color: v.color,
+          plateNumber: v.plateNumber,
+        };
+      }
+    } catch (error) {
+      // Escalate with location alone rather than not at all
+      sosLogger.error(
+        { incidentId: incident.id, err: error },
+        "[EmergencyDispatch] Failed to load trip details",
+      );
+    }
+
+    return payload;
+  }
+}
+
+// =============================================================================
+// EXPORT SINGLETON
+// =============================================================================
+
+export const emergencyDispatchService = new EmergencyDispatchService();
@@ -43,6 +43,11 @@ export {
   createReconciliationService,
   getReconciliationService,
 } from "./reconciliation.service";
+export {
+  SettlementReconciliationService,
+  createSettlementReconciliationService,
+  getSettlementReconciliationService,
+} from "./settlement-reconciliation.service";
 export {
   ScheduledJobsService,
   createScheduledJobsService,
@@ -165,6 +170,14 @@ export type {
   ReconciliationResult,
 } from "./reconciliation.service";
 
+export type {
+  // Settlement report reconciliation types
+  SettlementLine,
+  SettlementMismatch,
+  SettlementReconciliationResult,
+  SettlementReconciliationStatus,
+} from "./settlement-reconciliation.service";
+
 // ===========================================
 // ML SERVICES (AI/ML Platform)
 // ===========================================
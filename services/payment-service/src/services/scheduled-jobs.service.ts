@@ -4,7 +4,9 @@
  *
  * Manages cron jobs for automated tasks:
  * - Daily reconciliation
+ * - Daily settlement report reconciliation
  * - Daily settlements
+ * - Monthly corporate invoicing
  * - Weekly payouts
  * - Health checks
  */
@@ -12,8 +14,10 @@
 import { Currency, PaymentProvider, PrismaClient } from "@prisma/client";
 import { Redis } from "ioredis";
 import { jobsLogger } from "../lib/logger";
+import { corporateInvoicingService } from "./corporate-invoicing.service";
 import { PayoutService } from "./payout.service";
 import { ReconciliationService } from "./reconciliation.service";
+import { SettlementReconciliationService } from "./settlement-reconciliation.service";
 import { SettlementService } from "./settlement.service";
 
 // ===========================================
@@ -54,6 +58,7 @@ export class ScheduledJobsService {
   private readonly redis: Redis;
   private readonly reconciliationService: ReconciliationService;
   private readonly settlementService: SettlementService;
+  private readonly settlementReconciliationService: SettlementReconciliationService;
 
   private readonly jobs: Map<string, ScheduledJob> = new Map();
   private isRunning: boolean = false;
@@ -85,6 +90,9 @@ export class ScheduledJobsService {
     this.redis = redis;
     this.reconciliationService = reconciliationService;
     this.settlementService = settlementService;
+    this.settlementReconciliationService = new SettlementReconciliationService(
+      prisma,
+    );
 
     this.initializeJobs();
   }
@@ -114,6 +122,17 @@ export class ScheduledJobsService {
       handler: this.runDailyBalanceReconciliation.bind(this),
     });
 
+    // Daily Settlement Report Reconciliation - 2:45 AM, once providers
+    // have dropped the previous day's settlement files
+    this.registerJob({
+      config: {
+        name: "daily-settlement-report-reconciliation",
+        schedule: "45 2 * * *",
+        enabled: true,
+      },
+      handler: this.runDailySettlementReportReconciliation.bind(this),
+    });
+
     // Daily Restaurant Settlements - 3:00 AM
     this.registerJob({
       config: {
@@ -134,6 +153,17 @@ export class ScheduledJobsService {
       handler: this.runDailyMerchantSettlements.bind(this),
     });
 
+    // Corporate Invoicing - 4:00 AM daily; invoices last month once per
+    // organization, so a missed run on the 1st is picked up the next day
+    this.registerJob({
+      config: {
+        name: "monthly-corporate-invoicing",
+        schedule: "0 4 * * *",
+        enabled: true,
+      },
+      handler: this.runMonthlyCorporateInvoicing.bind(this),
+    });
+
     // Weekly Driver Payouts - Monday 3:00 AM
     this.registerJob({
       config: {
@@ -400,6 +430,38 @@ export class ScheduledJobsService {
     }
   }
 
+  private async runDailySettlementReportReconciliation(): Promise<void> {
+    const yesterday = new Date();
+    yesterday.setUTCDate(yesterday.getUTCDate() - 1);
+    yesterday.setUTCHours(0, 0, 0, 0);
+
+    const results =
+      await this.settlementReconciliationService.runDailySettlementReconciliation(
+        yesterday,
+      );
+
+    for (const result of results) {
+      jobsLogger.info(
+        `Settlement report for ${result.provider} / ${result.currency}: ${result.matched}/${result.settledLines} matched, ${result.mismatches.length} mismatches`,
+      );
+    }
+  }
+
+  private async runMonthlyCorporateInvoicing(): Promise<void> {
+    const result = await corporateInvoicingService.runMonthlyInvoicing();
+
+    if (result.issued.length > 0 || result.failed.length > 0) {
+      jobsLogger.info(
+        `Corporate invoicing for ${result.periodStart.toISOString().slice(0, 7)}: ${result.issued.length} issued, ${result.failed.length} failed`,
+      );
+    }
+    if (result.failed.length > 0) {
+      throw new Error(
+        `Corporate invoicing failed for ${result.failed.length} organization(s)`,
+      );
+    }
+  }
+
   private async runDailyRestaurantSettlements(): Promise<void> {
     const yesterday = new Date();
     yesterday.setDate(yesterday.getDate() - 1);
@@ -0,0 +1,747 @@
+/**
+ * Settlement Report Reconciliation Service
+ *
+ * Reconciles provider settlement files against UBI's own records:
+ * - Parse Paystack, Flutterwave and M-Pesa daily settlement reports
+ * - Match each settled line to its payment transaction and ledger posting
+ * - Flag missing captures, unsettled captures and amount drift
+ * - Report reconciliation status per provider, currency and day
+ *
+ * Settlement files are dropped into SETTLEMENT_REPORTS_DIR by the provider
+ * SFTP sync, named <provider>_<CURRENCY>_<YYYY-MM-DD>.csv
+ * (e.g. paystack_NGN_2024-01-15.csv), or uploaded through the admin API.
+ */
+
+import { readFile } from "node:fs/promises";
+import path from "node:path";
+import {
+  Currency,
+  PaymentProvider,
+  PaymentStatus,
+  PrismaClient,
+} from "@prisma/client";
+import { reconciliationLogger } from "../lib/logger";
+
+// ===========================================
+// TYPES
+// ===========================================
+
+/** A line of a provider settlement report, amounts in major units */
+export interface SettlementLine {
+  reference: string;
+  amount: number;
+  fee: number;
+  currency: string;
+  status: "success" | "failed" | "reversed";
+  settledAt: Date;
+}
+
+export type SettlementMismatchType =
+  | "MISSING_CAPTURE" // provider settled a payment we never captured
+  | "NOT_SETTLED" // we captured a payment the provider did not settle
+  | "AMOUNT_DRIFT" // settled amount differs from the captured amount
+  | "LEDGER_DRIFT"; // captured payment not posted to the ledger as captured
+
+export type SettlementMismatchSeverity = "LOW" | "MEDIUM" | "HIGH" | "CRITICAL";
+
+export interface SettlementMismatch {
+  type: SettlementMismatchType;
+  severity: SettlementMismatchSeverity;
+  transactionId?: string;
+  providerReference?: string;
+  ubiAmount?: number;
+  providerAmount?: number;
+  difference?: number;
+  description: string;
+}
+
+export interface SettlementReconciliationResult {
+  reportId: string;
+  provider: PaymentProvider;
+  currency: Currency;
+  date: Date;
+  lines: number;
+  settledLines: number;
+  matched: number;
+  settledAmount: number;
+  capturedAmount: number;
+  mismatches: SettlementMismatch[];
+}
+
+export type SettlementReconciliationState =
+  | "MATCHED"
+  | "MISMATCHED"
+  | "RESOLVED"
+  | "MISSING_REPORT";
+
+export interface SettlementReconciliationStatus {
+  date: string; // YYYY-MM-DD
+  provider: PaymentProvider;
+  currency: Currency;
+  status: SettlementReconciliationState;
+  matched: number;
+  mismatches: number;
+  pendingMismatches: number;
+  amountDifference: number;
+}
+
+// ===========================================
+// CONFIGURATION
+// ===========================================
+
+/** Provider and currency pairs that deliver a daily settlement file */
+export const SETTLEMENT_REPORT_SOURCES: Array<{
+  provider: PaymentProvider;
+  currency: Currency;
+}> = [
+  { provider: PaymentProvider.PAYSTACK, currency: Currency.NGN },
+  { provider: PaymentProvider.PAYSTACK, currency: Currency.GHS },
+  { provider: PaymentProvider.FLUTTERWAVE, currency: Currency.NGN },
+  { provider: PaymentProvider.FLUTTERWAVE, currency: Currency.GHS },
+  { provider: PaymentProvider.FLUTTERWAVE, currency: Currency.KES },
+  { provider: PaymentProvider.MPESA, currency: Currency.KES },
+];
+
+// Amounts within the larger of these are treated as equal
+const AMOUNT_TOLERANCE_PERCENT = 0.1;
+const AMOUNT_TOLERANCE_FIXED = 1;
+
+// Alert when a day's unexplained difference exceeds this
+const CRITICAL_DIFFERENCE_THRESHOLD = 50000;
+
+/**
+ * Report columns by provider. Headers are matched case-insensitively with
+ * punctuation folded to underscores, so "Receipt No." matches receipt_no.
+ */
+const REPORT_COLUMNS: Partial<
+  Record<
+    PaymentProvider,
+    {
+      reference: string[];
+      amount: string[];
+      fee: string[];
+      currency: string[];
+      status: string[];
+      date: string[];
+    }
+  >
+> = {
+  [PaymentProvider.PAYSTACK]: {
+    reference: ["reference", "transaction_reference"],
+    amount: ["amount", "transaction_amount"],
+    fee: ["fees", "fee"],
+    currency: ["currency"],
+    status: ["status"],
+    date: ["paid_at", "settlement_date", "date"],
+  },
+  [PaymentProvider.FLUTTERWAVE]: {
+    reference: ["tx_ref", "transaction_reference", "reference"],
+    amount: ["amount", "amount_charged"],
+    fee: ["app_fee", "fee"],
+    currency: ["currency"],
+    status: ["status"],
+    date: ["created_at", "settlement_date", "date"],
+  },
+  // M-Pesa organisation statement: money in is under "Paid In"; charges
+  // and withdrawals are lines of their own with no Paid In amount
+  [PaymentProvider.MPESA]: {
+    reference: ["receipt_no", "receipt_number", "receipt"],
+    amount: ["paid_in"],
+    fee: [],
+    currency: [],
+    status: ["transaction_status", "status"],
+    date: ["completion_time", "initiation_time"],
+  },
+};
+
+// ===========================================
+// REPORT PARSING
+// ===========================================
+
+/**
+ * Parse CSV into rows keyed by normalized header. Handles quoted fields
+ * with embedded commas, quotes and newlines.
+ */
+export function parseCsv(content: string): Record<string, string>[] {
+  const records: string[][] = [];
+  let record: string[] = [];
+  let field = "";
+  let quoted = false;
+
+  for (let i = 0; i < content.length; i++) {
+    const ch = content[i];
+    if (quoted) {
+      if (ch === '"' && content[i + 1] === '"') {
+        field += '"';
+        i++;
+      } else if (ch === '"') {
+        quoted = false;
+      } else {
+        field += ch;
+      }
+    } else if (ch === '"') {
+      quoted = true;
+    } else if (ch === ",") {
+      record.push(field);
+      field = "";
+    } else if (ch === "\n" || ch === "\r") {
+      if (ch === "\r" && content[i + 1] === "\n") i++;
+      record.push(field);
+      records.push(record);
+      record = [];
+      field = "";
+    } else {
+      field += ch;
+    }
+  }
+  if (field !== "" || record.length > 0) {
+    record.push(field);
+    records.push(record);
+  }
+
+  const nonEmpty = records.filter((r) => r.some((f) => f.trim() !== ""));
+  if (nonEmpty.length === 0) return [];
+
+  const headers = nonEmpty[0].map(normalizeHeader);
+  return nonEmpty.slice(1).map((fields) => {
+    const row: Record<string, string> = {};
+    headers.forEach((header, i) => {
+      row[header] = (fields[i] ?? "").trim();
+    });
+    return row;
+  });
+}
+
+function normalizeHeader(header: string): string {
+  return header
+    .trim()
+    .toLowerCase()
+    .replace(/[^a-z0-9]+/g, "_")
+    .replace(/^_+|_+$/g, "");
+}
+
+function pick(row: Record<string, string>, columns: string[]): string {
+  for (const column of columns) {
+    if (row[column]) return row[column];
+  }
+  return "";
+}
+
+function parseAmount(value: string): number {
+  const amount = Number.parseFloat(value.replace(/[^0-9.-]/g, ""));
+  return Number.isFinite(amount) ? amount : 0;
+}
+
+function parseLineStatus(value: string): SettlementLine["status"] {
+  const status = value.toLowerCase();
+  if (status.includes("revers") || status.includes("refund")) return "reversed";
+  if (["success", "successful", "completed", "settled"].includes(status)) {
+    return "success";
+  }
+  return "failed";
+}
+
+/**
+ * Parse a provider settlement report. Lines without a reference or amount
+ * (M-Pesa charges and withdrawals, totals rows) are skipped; a report with
+ * no currency column takes the currency it was filed under.
+ */
+export function parseSettlementReport(
+  provider: PaymentProvider,
+  content: string,
+  currency: Currency,
+): SettlementLine[] {
+  const columns = REPORT_COLUMNS[provider];
+  if (!columns) {
+    throw new Error(`No settlement report format for ${provider}`);
+  }
+
+  const lines: SettlementLine[] = [];
+  for (const row of parseCsv(content)) {
+    const reference = pick(row, columns.reference);
+    const amount = parseAmount(pick(row, columns.amount));
+    if (!reference || amount <= 0) continue;
+
+    const settledAt = new Date(pick(row, columns.date));
+    lines.push({
+      reference,
+      amount,
+      fee: parseAmount(pick(row, columns.fee)),
+      currency: (pick(row, columns.currency) || currency).toUpperCase(),
+      status: parseLineStatus(pick(row, columns.status)),
+      settledAt: Number.isNaN(settledAt.getTime()) ? new Date(0) : settledAt,
+    });
+  }
+  return lines;
+}
+
+/** The file name a provider's settlement report for a day is dropped under */
+export function settlementReportFileName(
+  provider: PaymentProvider,
+  currency: Currency,
+  date: Date,
+): string {
+  return `${provider.toLowerCase()}_${currency}_${dayKey(date)}.csv`;
+}
+
+function dayKey(date: Date): string {
+  return date.toISOString().slice(0, 10);
+}
+
+function startOfUtcDay(date: Date): Date {
+  const day = new Date(date);
+  day.setUTCHours(0, 0, 0, 0);
+  return day;
+}
+
+// ===========================================
+// SETTLEMENT RECONCILIATION SERVICE
+// ===========================================
+
+export class SettlementReconciliationService {
+  constructor(
+    private readonly prisma: PrismaClient,
+    private readonly reportsDir: string = process.env
+      .SETTLEMENT_REPORTS_DIR || "",
+  ) {}
+
+  /**
+   * Reconcile a provider's settlement report for a day. Re-running for the
+   * same day replaces the day's pending mismatches; resolved ones are kept.
+   */
+  async reconcileReport(
+    provider: PaymentProvider,
+    currency: Currency,
+    date: Date,
+    content: string,
+    fileName?: string,
+  ): Promise<SettlementReconciliationResult> {
+    const day = startOfUtcDay(date);
+    const lines = parseSettlementReport(provider, content, currency).filter(
+      (line) => line.currency === currency,
+    );
+
+    const payments = await this.getPayments(provider, currency, day, lines);
+    const result = this.matchLines(provider, currency, day, lines, payments);
+
+    const difference = result.mismatches.reduce(
+      (sum, m) => sum + (m.difference ?? 0),
+      0,
+    );
+    const reportFields = {
+      totalInternal: payments.filter(
+        (p) => p.status === PaymentStatus.COMPLETED,
+      ).length,
+      totalProvider: result.settledLines,
+      matched: result.matched,
+      unmatchedInternal: result.mismatches.filter(
+        (m) => m.type === "NOT_SETTLED",
+      ).length,
+      unmatchedProvider: result.mismatches.filter(
+        (m) => m.type === "MISSING_CAPTURE",
+      ).length,
+      discrepancies: result.mismatches.length,
+      internalAmount: result.capturedAmount,
+      providerAmount: result.settledAmount,
+      amountDifference: difference,
+      status: result.mismatches.length > 0 ? "pending" : "resolved",
+      reportData: {
+        source: "settlement_file",
+        fileName: fileName ?? settlementReportFileName(provider, currency, day),
+        lines: result.lines,
+      },
+    };
+
+    const report = await this.prisma.reconciliationReport.upsert({
+      where: {
+        date_provider_currency: { date: day, provider, currency },
+      },
+      create: { date: day, provider, currency, ...reportFields },
+      update: reportFields,
+    });
+
+    await this.prisma.reconciliationDiscrepancy.deleteMany({
+      where: { reconciliationReportId: report.id, status: "pending" },
+    });
+    if (result.mismatches.length > 0) {
+      await this.prisma.reconciliationDiscrepancy.createMany({
+        data: result.mismatches.map((m) => ({
+          reconciliationReportId: report.id,
+          type: m.type,
+          severity: m.severity,
+          transactionId: m.transactionId,
+          providerReference: m.providerReference,
+          ubiAmount: m.ubiAmount,
+          providerAmount: m.providerAmount,
+          difference: m.difference,
+          currency,
+          description: m.description,
+        })),
+      });
+    }
+
+    if (difference > CRITICAL_DIFFERENCE_THRESHOLD) {
+      reconciliationLogger.error(
+        {
+          alert: true,
+          provider,
+          currency,
+          date: dayKey(day),
+          difference,
+          mismatches: result.mismatches.length,
+        },
+        "[Reconciliation] Settlement report difference above threshold",
+      );
+    }
+
+    reconciliationLogger.info(
+      {
+        provider,
+        currency,
+        date: dayKey(day),
+        matched: result.matched,
+        mismatches: result.mismatches.length,
+      },
+      "[Reconciliation] Settlement report reconciled",
+    );
+
+    return { ...result, reportId: report.id };
+  }
+
+  /**
+   * Reconcile every expected settlement file for a day from the reports
+   * directory. Missing files are logged and show up as MISSING_REPORT in
+   * the status report.
+   */
+  async runDailySettlementReconciliation(
+    date: Date,
+  ): Promise<SettlementReconciliationResult[]> {
+    if (!this.reportsDir) {
+      reconciliationLogger.warn(
+        "SETTLEMENT_REPORTS_DIR not configured - skipping settlement report reconciliation",
+      );
+      return [];
+    }
+
+    const results: SettlementReconciliationResult[] = [];
+    for (const { provider, currency } of SETTLEMENT_REPORT_SOURCES) {
+      const fileName = settlementReportFileName(provider, currency, date);
+
+      let content: string;
+      try {
+        content = await readFile(path.join(this.reportsDir, fileName), "utf8");
+      } catch {
+        reconciliationLogger.warn(
+          { provider, currency, fileName },
+          "[Reconciliation] Settlement report not received",
+        );
+        continue;
+      }
+
+      try {
+        results.push(
+          await this.reconcileReport(provider, currency, date, content, fileName),
+        );
+      } catch (error) {
+        reconciliationLogger.error(
+          { err: error, provider, currency, fileName },
+          "[Reconciliation] Settlement report reconciliation failed",
+        );
+      }
+    }
+    return results;
+  }
+
+  /**
+   * Reconciliation status for each expected provider, currency and day in
+   * the range, newest first
+   */
+  async getSettlementStatus(
+    startDate: Date,
+    endDate: Date,
+    provider?: PaymentProvider,
+  ): Promise<SettlementReconciliationStatus[]> {
+    const start = startOfUtcDay(startDate);
+    const end = startOfUtcDay(endDate);
+
+    const reports = await this.prisma.reconciliationReport.findMany({
+      where: {
+        date: { gte: start, lte: end },
+        ...(provider ? { provider } : {}),
+      },
+      include: { discrepancyRecords: true },
+    });
+
+    const byKey = new Map<string, any>();
+    for (const report of reports) {
+      if (report.reportData?.source !== "settlement_file") continue;
+      byKey.set(
+        `${dayKey(new Date(report.date))}:${report.provider}:${report.currency}`,
+        report,
+      );
+    }
+
+    const sources = SETTLEMENT_REPORT_SOURCES.filter(
+      (s) => !provider || s.provider === provider,
+    );
+    const statuses: SettlementReconciliationStatus[] = [];
+    for (let day = end; day >= start; day = new Date(day.getTime() - 86400000)) {
+      for (const source of sources) {
+        const report = byKey.get(
+          `${dayKey(day)}:${source.provider}:${source.currency}`,
+        );
+        if (!report) {
+          statuses.push({
+            date: dayKey(day),
+            ...source,
+            status: "MISSING_REPORT",
+            matched: 0,
+            mismatches: 0,
+            pendingMismatches: 0,
+            amountDifference: 0,
+          });
+          continue;
+        }
+
+        const records: any[] = report.discrepancyRecords ?? [];
+        const pending = records.filter((d) => d.status === "pending").length;
+        let status: SettlementReconciliationState = "MATCHED";
+        if (pending > 0) {
+          status = "MISMATCHED";
+        } else if (records.length > 0) {
+          status = "RESOLVED";
+        }
+
+        statuses.push({
+          date: dayKey(day),
+          ...source,
+          status,
+          matched: report.matched,
+          mismatches: records.length,
+          pendingMismatches: pending,
+          amountDifference: Number(report.amountDifference),
+        });
+      }
+    }
+    return statuses;
+  }
+
+  /**
+   * Payments the report's lines refer to, plus those captured on the day,
+   * with their ledger transactions
+   */
+  private async getPayments(
+    provider: PaymentProvider,
+    currency: Currency,
+    day: Date,
+    lines: SettlementLine[],
+  ): Promise<any[]> {
+    const dayEnd = new Date(day.getTime() + 86400000 - 1);
+    return await this.prisma.paymentTransaction.findMany({
+      where: {
+        provider,
+        currency,
+        OR: [
+          { providerReference: { in: lines.map((l) => l.reference) } },
+          {
+            status: PaymentStatus.COMPLETED,
+            confirmedAt: { gte: day, lte: dayEnd },
+          },
+        ],
+      },
+      include: {
+        transaction: { include: { ledgerEntries: true } },
+      },
+    });
+  }
+
+  /**
+   * Match settled lines to payments. Failed and reversed lines are counted
+   * but not matched: nothing was settled for them.
+   */
+  private matchLines(
+    provider: PaymentProvider,
+    currency: Currency,
+    day: Date,
+    lines: SettlementLine[],
+    payments: any[],
+  ): Omit<SettlementReconciliationResult, "reportId"> {
+    const byReference = new Map<string, any>();
+    for (const payment of payments) {
+      if (payment.providerReference) {
+        byReference.set(payment.providerReference, payment);
+      }
+    }
+
+    const mismatches: SettlementMismatch[] = [];
+    const settledRefs = new Set<string>();
+    let matched = 0;
+    let settledAmount = 0;
+
+    const settled = lines.filter((line) => line.status === "success");
+    for (const line of settled) {
+      settledRefs.add(line.reference);
+      settledAmount += line.amount;
+
+      const payment = byReference.get(line.reference);
+      if (!payment || payment.status !== PaymentStatus.COMPLETED) {
+        mismatches.push({
+          type: "MISSING_CAPTURE",
+          severity: mismatchSeverity(line.amount),
+          transactionId: payment?.id,
+          providerReference: line.reference,
+          ubiAmount: payment ? Number(payment.amount) : undefined,
+          providerAmount: line.amount,
+          difference: line.amount,
+          description: payment
+            ? `Settled by provider but payment is ${payment.status}`
+            : "Settled by provider but no payment recorded",
+        });
+        continue;
+      }
+
+      const captured = Number(payment.amount);
+      const drift = Math.abs(captured - line.amount);
+      if (drift > amountTolerance(captured)) {
+        mismatches.push({
+          type: "AMOUNT_DRIFT",
+          severity: mismatchSeverity(drift),
+          transactionId: payment.id,
+          providerReference: line.reference,
+          ubiAmount: captured,
+          providerAmount: line.amount,
+          difference: drift,
+          description: `Settled ${line.amount} ${currency} against ${captured} captured`,
+        });
+        continue;
+      }
+
+      const ledgerMismatch = ledgerDrift(payment, line.reference);
+      if (ledgerMismatch) {
+        mismatches.push(ledgerMismatch);
+        continue;
+      }
+
+      matched++;
+    }
+
+    // Captures on the day the provider has not settled
+    const dayEnd = day.getTime() + 86400000;
+    let capturedAmount = 0;
+    for (const payment of payments) {
+      if (payment.status !== PaymentStatus.COMPLETED) continue;
+      const confirmedAt = payment.confirmedAt
+        ? new Date(payment.confirmedAt).getTime()
+        : 0;
+      const capturedOnDay = confirmedAt >= day.getTime() && confirmedAt < dayEnd;
+      if (capturedOnDay) capturedAmount += Number(payment.amount);
+
+      if (
+        capturedOnDay &&
+        payment.providerReference &&
+        !settledRefs.has(payment.providerReference)
+      ) {
+        mismatches.push({
+          type: "NOT_SETTLED",
+          severity: mismatchSeverity(Number(payment.amount)),
+          transactionId: payment.id,
+          providerReference: payment.providerReference,
+          ubiAmount: Number(payment.amount),
+          difference: Number(payment.amount),
+          description: `Captured but missing from the ${provider} settlement report`,
+        });
+      }
+    }
+
+    return {
+      provider,
+      currency,
+      date: day,
+      lines: lines.length,
+      settledLines: settled.length,
+      matched,
+      settledAmount,
+      capturedAmount,
+      mismatches,
+    };
+  }
+}
+
+function amountTolerance(amount: number): number {
+  return Math.max(
+    amount * (AMOUNT_TOLERANCE_PERCENT / 100),
+    AMOUNT_TOLERANCE_FIXED,
+  );
+}
+
+function mismatchSeverity(amount: number): SettlementMismatchSeverity {
+  if (amount >= 50000) return "CRITICAL";
+  if (amount >= 10000) return "HIGH";
+  if (amount >= 1000) return "MEDIUM";
+  return "LOW";
+}
+
+/**
+ * A captured payment must have a completed ledger transaction for the
+ * captured amount with entries posted
+ */
+function ledgerDrift(
+  payment: any,
+  reference: string,
+): SettlementMismatch | null {
+  const captured = Number(payment.amount);
+  const ledger = payment.transaction;
+
+  if (
+    !ledger ||
+    ledger.status !== "COMPLETED" ||
+    (ledger.ledgerEntries ?? []).length === 0
+  ) {
+    return {
+      type: "LEDGER_DRIFT",
+      severity: mismatchSeverity(captured),
+      transactionId: payment.id,
+      providerReference: reference,
+      ubiAmount: captured,
+      difference: captured,
+      description: ledger
+        ? `Ledger transaction is ${ledger.status} with ${(ledger.ledgerEntries ?? []).length} entries`
+        : "Captured payment has no ledger transaction",
+    };
+  }
+
+  const posted = Number(ledger.amount);
+  const drift = Math.abs(posted - captured);
+  if (drift > amountTolerance(captured)) {
+    return {
+      type: "LEDGER_DRIFT",
+      severity: mismatchSeverity(drift),
+      transactionId: payment.id,
+      providerReference: reference,
+      ubiAmount: captured,
+      difference: drift,
+      description: `Ledger posted ${posted} against ${captured} captured`,
+    };
+  }
+  return null;
+}
+
+// Singleton instance
+let settlementReconciliationServiceInstance: SettlementReconciliationService | null =
+  null;
+
+// Create new instance
+export function createSettlementReconciliationService(
+  prisma: PrismaClient,
+): SettlementReconciliationService {
+  return new SettlementReconciliationService(prisma);
+}
+
+// Get singleton instance
+export function getSettlementReconciliationService(
+  prisma: PrismaClient,
+): SettlementReconciliationService {
+  settlementReconciliationServiceInstance ??=
+    createSettlementReconciliationService(prisma);
+  return settlementReconciliationServiceInstance;
+}
@@ -7,6 +7,7 @@
  * - Audio/video recording during emergency
  * - Emergency contact notification
  * - Law enforcement integration
+ * - Escalation to local emergency dispatch partners
  * - Safety team dashboard integration
  */
 
@@ -14,6 +15,7 @@ import crypto from "node:crypto";
 import { EventEmitter } from "node:events";
 import { sosLogger } from "../lib/logger";
 import { notificationClient } from "../lib/notification-client";
+import { prisma } from "../lib/prisma";
 import {
   COUNTRY_CONFIGS,
   EmergencyContact,
@@ -25,6 +27,10 @@ import {
   SOSResponse,
   UserSafetyContext,
 } from "../types/safety.types";
+import {
+  EmergencyDispatchService,
+  emergencyDispatchService,
+} from "./emergency-dispatch.service";
 
 // =============================================================================
 // SOS EMERGENCY SERVICE
@@ -44,7 +50,10 @@ export class SOSEmergencyService extends EventEmitter {
   private readonly LEVEL_2_TIMEOUT = 120; // 2 minutes
   private readonly LEVEL_3_TIMEOUT = 180; // 3 minutes
 
-  constructor() {
+  constructor(
+    private readonly dispatchPartners: EmergencyDispatchService =
+      emergencyDispatchService,
+  ) {
     super();
     this.initializeMockAgents();
   }
@@ -116,8 +125,9 @@ export class SOSEmergencyService extends EventEmitter {
     // Notify emergency contacts
     await this.notifyEmergencyContacts(incident);
 
-    // Start escalation timer
+    // Start escalation timers
     this.startEscalationTimer(incident);
+    await this.schedulePartnerEscalation(incident);
 
     // Emit event
     this.emit("sos_triggered", incident);
@@ -664,6 +674,7 @@ export class SOSEmergencyService extends EventEmitter {
     });
 
     await this.contactLawEnforcement(incident);
+    await this.escalateToPartner(incident.id, "Emergency services dispatched");
 
     sosLogger.info(
       { incidentId: incident.id },
@@ -683,6 +694,105 @@ export class SOSEmergencyService extends EventEmitter {
     }
   }
 
+  // ---------------------------------------------------------------------------
+  // DISPATCH PARTNER ESCALATION
+  // ---------------------------------------------------------------------------
+
+  private async schedulePartnerEscalation(
+    incident: SOSIncident,
+  ): Promise<void> {
+    const country = await this.getUserCountry(incident.userId);
+    incident.emergencyEscalation = this.dispatchPartners.schedule(
+      country,
+      incident.triggeredAt,
+    );
+
+    const delayMs =
+      incident.emergencyEscalation.scheduledFor.getTime() - Date.now();
+    setTimeout(
+      () =>
+        this.escalateToPartner(
+          incident.id,
+          `Auto-escalation: SOS unresolved after ${Math.round(delayMs / 60000)} minutes`,
+        ),
+      Math.max(delayMs, 0),
+    );
+  }
+
+  /**
+   * Hand an unresolved incident to the local dispatch partner. Runs at most
+   * once per incident; later calls are no-ops.
+   */
+  async escalateToPartner(incidentId: string, reason: string): Promise<void> {
+    const incident = this.activeIncidents.get(incidentId);
+    const escalation = incident?.emergencyEscalation;
+
+    if (!incident || !escalation || escalation.status !== "SCHEDULED") {
+      return;
+    }
+
+    if (!UNRESOLVED_STATUSES.includes(incident.status)) {
+      return;
+    }
+
+    const locationLink = await this.generateLiveLocationLink(incident.id);
+    const result = await this.dispatchPartners.dispatch(incident, locationLink);
+
+    this.addTimelineEntry(
+      incident.id,
+      result.status === "SENT"
+        ? "partner_escalated"
+        : "partner_escalation_failed",
+      {
+        reason,
+        partner: result.partnerName,
+        channel: result.channel,
+        status: result.status,
+        reference: result.reference,
+        attempts: result.attempts,
+        error: result.lastError,
+      },
+    );
+
+    if (result.status !== "SENT") {
+      // Nobody outside UBI is responding; make sure every agent knows
+      await this.triggerAllHandsAlert(incident);
+    }
+
+    this.emit("sos_partner_escalated", { incident, escalation: result });
+  }
+
+  /**
+   * Record a dispatch partner's acknowledgement of an escalation
+   */
+  async acknowledgePartnerEscalation(
+    incidentId: string,
+    reference: string,
+    partnerIncidentId?: string,
+  ): Promise<boolean> {
+    const incident = this.activeIncidents.get(incidentId);
+    const escalation = incident?.emergencyEscalation;
+
+    if (
+      !incident ||
+      !escalation ||
+      !this.dispatchPartners.acknowledge(
+        escalation,
+        reference,
+        partnerIncidentId,
+      )
+    ) {
+      return false;
+    }
+
+    this.addTimelineEntry(incident.id, "partner_acknowledged", {
+      partner: escalation.partnerName,
+      partnerIncidentId: escalation.partnerIncidentId,
+    });
+
+    return true;
+  }
+
   // ---------------------------------------------------------------------------
   // AUDIO/VIDEO RECORDING
   // ---------------------------------------------------------------------------
@@ -1103,9 +1213,17 @@ export class SOSEmergencyService extends EventEmitter {
     return `https://ubi.app/sos/track/${token}`;
   }
 
-  private async getUserCountry(_userId: string): Promise<string> {
-    // In production, get from user profile
-    return "NG";
+  private async getUserCountry(userId: string): Promise<string> {
+    try {
+      const user = await prisma.user.findUnique({
+        where: { id: userId },
+        select: { country: true },
+      });
+      return user?.country || "NG";
+    } catch (error) {
+      sosLogger.warn({ userId, err: error }, "Failed to load user country");
+      return "NG";
+    }
   }
 
   private async verifyUserPin(_userId: string, pin: string): Promise<boolean> {
@@ -1200,6 +1318,13 @@ export class SOSEmergencyService extends EventEmitter {
 // TYPES
 // =============================================================================
 
+// Incidents still awaiting resolution, eligible for partner escalation
+const UNRESOLVED_STATUSES: SOSIncident["status"][] = [
+  "ACTIVE",
+  "RESPONDED",
+  "ESCALATED",
+];
+
 interface TriggerSOSParams {
   userId: string;
   tripId?: string;
@@ -7,7 +7,8 @@
  * - Speed anomaly detection
  * - Crash detection (accelerometer + GPS)
  * - Safety check-ins
- * - Automatic escalation
+ * - Night-time check-ins on long trips
+ * - Automatic escalation and safety flag queue
  */
 
 import crypto from "node:crypto";
@@ -17,6 +18,7 @@ import { notificationClient } from "../lib/notification-client";
 import {
   AccelData,
   AnomalyDetails,
+  COUNTRY_CONFIGS,
   CrashDetection,
   IncidentSeverity,
   Location,
@@ -24,6 +26,7 @@ import {
   TripAnomaly,
   TripAnomalyType,
   TripSafetyCheck,
+  TripSafetyFlag,
   TripSafetySession,
   TripShare,
 } from "../types/safety.types";
@@ -38,6 +41,7 @@ export class TripMonitorService extends EventEmitter {
   private readonly anomalyStore: Map<string, TripAnomaly[]> = new Map();
   private readonly safetyChecks: Map<string, TripSafetyCheck> = new Map();
   private readonly crashBuffer: Map<string, AccelData[]> = new Map();
+  private readonly safetyFlags: Map<string, TripSafetyFlag> = new Map();
 
   // Thresholds
   private readonly ROUTE_DEVIATION_THRESHOLD_METERS = 500;
@@ -48,6 +52,14 @@ export class TripMonitorService extends EventEmitter {
   private readonly SAFETY_CHECK_TIMEOUT_SECONDS = 60;
   private readonly LOCATION_JUMP_THRESHOLD_METERS = 1000;
 
+  // Night-time check-ins (local hours, seconds)
+  private readonly NIGHT_START_HOUR = 19;
+  private readonly NIGHT_END_HOUR = 6;
+  private readonly NIGHT_CHECKIN_MIN_TRIP_SECONDS = 1800; // 30 minutes
+  private readonly NIGHT_CHECKIN_INTERVAL_SECONDS = 1200; // 20 minutes
+  private readonly NIGHT_CHECKIN_TIMEOUT_SECONDS = 120;
+  private readonly NIGHT_DEVIATION_WINDOW_SECONDS = 900; // 15 minutes
+
   constructor() {
     super();
     this.startBackgroundProcessing();
@@ -68,6 +80,7 @@ export class TripMonitorService extends EventEmitter {
       expectedDuration,
       womenSafetyMode = false,
       emergencyContacts = [],
+      country,
     } = params;
 
     // Calculate initial risk score
@@ -89,6 +102,8 @@ export class TripMonitorService extends EventEmitter {
       anomalyCount: 0,
       womenSafetyMode,
       sharedWithContacts: emergencyContacts.length > 0,
+      country,
+      nightCheckInCount: 0,
       monitoringStartedAt: new Date(),
     };
 
@@ -455,8 +470,13 @@ export class TripMonitorService extends EventEmitter {
     tripId: string,
     userId: string,
     reason: string,
+    kind: "standard" | "night" = "standard",
   ): Promise<TripSafetyCheck> {
     const checkId = this.generateId();
+    const timeoutSeconds =
+      kind === "night"
+        ? this.NIGHT_CHECKIN_TIMEOUT_SECONDS
+        : this.SAFETY_CHECK_TIMEOUT_SECONDS;
 
     const check: TripSafetyCheck = {
       id: checkId,
@@ -465,18 +485,25 @@ export class TripMonitorService extends EventEmitter {
       reason,
       status: "SENT",
       sentAt: new Date(),
-      timeoutSeconds: this.SAFETY_CHECK_TIMEOUT_SECONDS,
+      timeoutSeconds,
+      kind,
     };
 
     this.safetyChecks.set(checkId, check);
 
     // Send push notification to user
-    await this.sendSafetyCheckNotification(userId, checkId, reason);
+    await this.sendSafetyCheckNotification(
+      userId,
+      tripId,
+      checkId,
+      reason,
+      timeoutSeconds,
+    );
 
     // Schedule timeout
     setTimeout(
       () => this.handleSafetyCheckTimeout(checkId),
-      this.SAFETY_CHECK_TIMEOUT_SECONDS * 1000,
+      timeoutSeconds * 1000,
     );
 
     this.emitSafetyEvent("safety_check_sent", userId, {
@@ -540,6 +567,18 @@ export class TripMonitorService extends EventEmitter {
 
     tripMonitorLogger.info({ checkId }, "[TripMonitor] Safety check timeout");
 
+    if (check.kind === "night") {
+      // A missed night check-in alone is common (the rider may be asleep);
+      // it only raises a flag together with a route deviation
+      await this.evaluateNightCheckIn(check.tripId);
+
+      this.emitSafetyEvent("safety_check_timeout", check.userId, {
+        checkId,
+        tripId: check.tripId,
+      });
+      return;
+    }
+
     // Escalate due to no response
     await this.escalateToSafetyTeam(
       check.tripId,
@@ -553,6 +592,202 @@ export class TripMonitorService extends EventEmitter {
     });
   }
 
+  // ---------------------------------------------------------------------------
+  // NIGHT-TIME CHECK-INS
+  // ---------------------------------------------------------------------------
+
+  private async checkNightCheckIns(): Promise<void> {
+    const now = new Date();
+
+    for (const session of this.activeSessions.values()) {
+      if (
+        session.status !== "monitoring" ||
+        !this.isAfterDark(now, session.country)
+      ) {
+        continue;
+      }
+
+      // Trips expected to be long get check-ins from the start; others once
+      // they actually run long
+      const elapsedSeconds =
+        (now.getTime() - session.monitoringStartedAt.getTime()) / 1000;
+      if (
+        session.expectedDuration < this.NIGHT_CHECKIN_MIN_TRIP_SECONDS &&
+        elapsedSeconds < this.NIGHT_CHECKIN_MIN_TRIP_SECONDS
+      ) {
+        continue;
+      }
+
+      const lastCheckIn = (
+        session.lastNightCheckInAt ?? session.monitoringStartedAt
+      ).getTime();
+      if (
+        now.getTime() - lastCheckIn <
+        this.NIGHT_CHECKIN_INTERVAL_SECONDS * 1000
+      ) {
+        continue;
+      }
+
+      if (this.getLatestNightCheck(session.tripId)?.status === "SENT") {
+        continue;
+      }
+
+      session.lastNightCheckInAt = now;
+      session.nightCheckInCount = (session.nightCheckInCount ?? 0) + 1;
+
+      await this.triggerSafetyCheck(
+        session.tripId,
+        session.riderId,
+        "Night-time check-in: Is everything OK?",
+        "night",
+      );
+    }
+  }
+
+  /**
+   * Raise a safety flag when the latest night check-in went unanswered and
+   * the trip deviated from its route around the same time
+   */
+  private async evaluateNightCheckIn(tripId: string): Promise<void> {
+    const session = this.activeSessions.get(tripId);
+    const missed = this.getLatestNightCheck(tripId);
+    if (!session || missed?.status !== "NO_RESPONSE") return;
+
+    const windowStart =
+      missed.sentAt.getTime() - this.NIGHT_DEVIATION_WINDOW_SECONDS * 1000;
+    const deviation = (this.anomalyStore.get(tripId) || [])
+      .filter(
+        (a) =>
+          a.anomalyType === "ROUTE_DEVIATION" &&
+          a.detectedAt.getTime() >= windowStart,
+      )
+      .at(-1);
+
+    if (!deviation) return;
+
+    await this.raiseSafetyFlag(
+      session,
+      "No response to night-time check-in during route deviation",
+      ["night_checkin_no_response", "route_deviation"],
+      "HIGH",
+      deviation.location,
+    );
+  }
+
+  private getLatestNightCheck(tripId: string): TripSafetyCheck | null {
+    let latest: TripSafetyCheck | null = null;
+
+    for (const check of this.safetyChecks.values()) {
+      if (
+        check.tripId === tripId &&
+        check.kind === "night" &&
+        (!latest || check.sentAt > latest.sentAt)
+      ) {
+        latest = check;
+      }
+    }
+
+    return latest;
+  }
+
+  private isAfterDark(date: Date, country?: string): boolean {
+    const timeZone = country ? COUNTRY_CONFIGS[country]?.timezone : undefined;
+    const hour = timeZone
+      ? Number(
+          new Intl.DateTimeFormat("en-GB", {
+            hour: "numeric",
+            hourCycle: "h23",
+            timeZone,
+          }).format(date),
+        )
+      : date.getHours();
+
+    return hour >= this.NIGHT_START_HOUR || hour < this.NIGHT_END_HOUR;
+  }
+
+  // ---------------------------------------------------------------------------
+  // SAFETY FLAG QUEUE
+  // ---------------------------------------------------------------------------
+
+  private async raiseSafetyFlag(
+    session: TripSafetySession,
+    reason: string,
+    triggers: string[],
+    severity: IncidentSeverity,
+    location?: Location,
+  ): Promise<TripSafetyFlag> {
+    // One open flag per trip; repeated signals attach to it
+    for (const existing of this.safetyFlags.values()) {
+      if (existing.tripId === session.tripId && existing.status !== "CLOSED") {
+        return existing;
+      }
+    }
+
+    const flag: TripSafetyFlag = {
+      id: this.generateId(),
+      tripId: session.tripId,
+      riderId: session.riderId,
+      driverId: session.driverId,
+      reason,
+      triggers,
+      severity,
+      status: "OPEN",
+      location,
+      raisedAt: new Date(),
+    };
+
+    this.safetyFlags.set(flag.id, flag);
+
+    this.emitSafetyEvent("safety_flag_raised", session.riderId, {
+      tripId: session.tripId,
+      flagId: flag.id,
+      triggers,
+    });
+    this.emit("safety_flag", flag);
+
+    tripMonitorLogger.warn(
+      { tripId: session.tripId, flagId: flag.id, reason },
+      "[TripMonitor] Safety flag raised",
+    );
+
+    return flag;
+  }
+
+  /**
+   * Open and acknowledged flags, most severe and oldest first
+   */
+  async getSafetyFlagQueue(): Promise<TripSafetyFlag[]> {
+    const rank: Record<IncidentSeverity, number> = {
+      CRITICAL: 0,
+      HIGH: 1,
+      MEDIUM: 2,
+      LOW: 3,
+    };
+
+    return Array.from(this.safetyFlags.values())
+      .filter((f) => f.status !== "CLOSED")
+      .sort(
+        (a, b) =>
+          rank[a.severity] - rank[b.severity] ||
+          a.raisedAt.getTime() - b.raisedAt.getTime(),
+      );
+  }
+
+  async reviewSafetyFlag(
+    flagId: string,
+    agentId: string,
+    action: "acknowledge" | "close",
+  ): Promise<TripSafetyFlag | null> {
+    const flag = this.safetyFlags.get(flagId);
+    if (!flag || flag.status === "CLOSED") return null;
+
+    flag.status = action === "close" ? "CLOSED" : "ACKNOWLEDGED";
+    flag.acknowledgedBy = flag.acknowledgedBy ?? agentId;
+    flag.acknowledgedAt = flag.acknowledgedAt ?? new Date();
+
+    return flag;
+  }
+
   // ---------------------------------------------------------------------------
   // TRIP SHARING
   // ---------------------------------------------------------------------------
@@ -742,6 +977,10 @@ export class TripMonitorService extends EventEmitter {
       anomaly,
     });
 
+    if (anomaly.anomalyType === "ROUTE_DEVIATION") {
+      await this.evaluateNightCheckIn(session.tripId);
+    }
+
     // Auto-escalation rules
     if (anomaly.severity === "CRITICAL") {
       await this.escalateToSafetyTeam(
@@ -1013,14 +1252,30 @@ export class TripMonitorService extends EventEmitter {
 
   private async sendSafetyCheckNotification(
     userId: string,
-    _checkId: string,
-    _reason: string,
+    tripId: string,
+    checkId: string,
+    reason: string,
+    timeoutSeconds: number,
   ): Promise<void> {
-    // In production, send push notification
-    tripMonitorLogger.info(
-      { userId },
-      "[TripMonitor] Sent safety check notification",
+    const result = await notificationClient.sendSafetyCheck(
+      userId,
+      tripId,
+      reason,
+      checkId,
+      timeoutSeconds,
     );
+
+    if (result.success) {
+      tripMonitorLogger.info(
+        { userId, checkId },
+        "[TripMonitor] Sent safety check notification",
+      );
+    } else {
+      tripMonitorLogger.error(
+        { userId, checkId, error: result.error },
+        "[TripMonitor] Safety check notification failed",
+      );
+    }
   }
 
   private generateId(): string {
@@ -1044,6 +1299,12 @@ export class TripMonitorService extends EventEmitter {
     // Monitor active sessions periodically
     setInterval(() => {
       this.checkStaleLocations();
+      this.checkNightCheckIns().catch((error) =>
+        tripMonitorLogger.error(
+          { err: error },
+          "[TripMonitor] Night check-in sweep failed",
+        ),
+      );
     }, 30000); // Every 30 seconds
   }
 
@@ -1118,9 +1379,10 @@ interface StartMonitoringParams {
   riderId: string;
   driverId: string;
   expectedRoute: Location[];
-  expectedDuration: number;
+  expectedDuration: number; // seconds
   womenSafetyMode?: boolean;
   emergencyContacts?: string[];
+  country?: string;
 }
 
 interface LocationProcessResult {
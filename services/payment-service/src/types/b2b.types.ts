@@ -115,6 +115,7 @@ export type WebhookEvent =
   | "trip.completed"
   | "trip.cancelled"
   | "invoice.created"
+  | "invoice.issued"
   | "invoice.paid"
   | "payment.failed";
 
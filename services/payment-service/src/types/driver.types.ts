@@ -140,6 +140,49 @@ export enum SuggestionType {
   GO_ONLINE = "go_online",
 }
 
+export enum StatementPeriodType {
+  ANNUAL = "annual",
+  QUARTERLY = "quarterly",
+}
+
+/**
+ * Earnings for a tax period, summed from the trip earnings ledger. One
+ * set of totals per currency the driver earned in.
+ */
+export interface DriverTaxStatement {
+  driverId: string;
+  country: string;
+  periodType: StatementPeriodType;
+  taxYear: string; // "2024", or "2024/25" where the tax year spans two
+  quarter?: number;
+  periodStart: Date;
+  periodEnd: Date; // exclusive
+  final: boolean; // false while the period is still running
+  totals: DriverStatementTotals[];
+  generatedAt: Date;
+}
+
+export interface DriverStatementTotals {
+  currency: string;
+  tripCount: number;
+  grossFares: number;
+  commissions: number;
+  tips: number;
+  incentives: number;
+  netEarnings: number;
+  months: DriverStatementMonth[];
+}
+
+export interface DriverStatementMonth {
+  month: string; // YYYY-MM
+  tripCount: number;
+  grossFares: number;
+  commissions: number;
+  tips: number;
+  incentives: number;
+  netEarnings: number;
+}
+
 // -----------------------------------------
 // GOALS TYPES
 // -----------------------------------------
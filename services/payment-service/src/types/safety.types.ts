@@ -296,6 +296,7 @@ export interface SOSIncident {
   responseTimeSeconds?: number;
   resolvedAt?: Date;
   resolutionType?: string;
+  emergencyEscalation?: EmergencyEscalation;
   triggeredAt: Date;
 }
 
@@ -308,6 +309,65 @@ export interface SOSResponse {
   escalationReason?: string;
 }
 
+// =============================================================================
+// EMERGENCY DISPATCH PARTNER TYPES
+// =============================================================================
+
+export type EmergencyPartnerChannel = "API" | "SMS";
+
+export type EmergencyEscalationStatus =
+  | "SCHEDULED"
+  | "IN_PROGRESS"
+  | "SENT"
+  | "ACKNOWLEDGED"
+  | "FAILED"
+  | "UNAVAILABLE";
+
+export interface EmergencyPartnerConfig {
+  country: string;
+  partnerName: string;
+  channel: EmergencyPartnerChannel;
+  apiUrl?: string;
+  apiKey?: string;
+  smsNumber?: string;
+  escalateAfterMinutes: number;
+}
+
+export interface EmergencyDispatchVehicle {
+  make: string;
+  model: string;
+  color: string;
+  plateNumber: string;
+}
+
+export interface EmergencyDispatchPayload {
+  reference: string;
+  incidentId: string;
+  country: string;
+  triggerMethod: SOSTrigger;
+  triggeredAt: Date;
+  location: Location;
+  locationLink: string;
+  tripId?: string;
+  rider: { name: string; phone?: string };
+  driver?: { name: string; phone?: string };
+  vehicle?: EmergencyDispatchVehicle;
+}
+
+export interface EmergencyEscalation {
+  status: EmergencyEscalationStatus;
+  country: string;
+  partnerName?: string;
+  channel?: EmergencyPartnerChannel;
+  scheduledFor: Date;
+  attempts: number;
+  reference?: string;
+  partnerIncidentId?: string;
+  escalatedAt?: Date;
+  acknowledgedAt?: Date;
+  lastError?: string;
+}
+
 export interface EmergencyContact {
   id: string;
   userId: string;
@@ -371,6 +431,9 @@ export interface TripSafetySession {
   womenSafetyMode: boolean;
   pinVerified?: boolean;
   sharedWithContacts: boolean;
+  country?: string;
+  nightCheckInCount?: number;
+  lastNightCheckInAt?: Date;
   monitoringStartedAt: Date;
 }
 
@@ -442,6 +505,24 @@ export interface TripSafetyCheck {
   respondedAt?: Date;
   responseType?: "safe" | "need_help" | "no_response";
   timeoutSeconds: number;
+  kind?: "standard" | "night";
+}
+
+export type TripSafetyFlagStatus = "OPEN" | "ACKNOWLEDGED" | "CLOSED";
+
+export interface TripSafetyFlag {
+  id: string;
+  tripId: string;
+  riderId: string;
+  driverId: string;
+  reason: string;
+  triggers: string[];
+  severity: IncidentSeverity;
+  status: TripSafetyFlagStatus;
+  location?: Location;
+  raisedAt: Date;
+  acknowledgedBy?: string;
+  acknowledgedAt?: Date;
 }
 
 export interface TripShare {
@@ -81,12 +81,15 @@ export const mockPrismaClient = {
     findMany: vi.fn(),
     create: vi.fn(),
     update: vi.fn(),
+    upsert: vi.fn(),
   },
   reconciliationDiscrepancy: {
     findFirst: vi.fn(),
     findMany: vi.fn(),
     create: vi.fn(),
+    createMany: vi.fn(),
     update: vi.fn(),
+    deleteMany: vi.fn(),
     count: vi.fn(),
   },
   settlement: {
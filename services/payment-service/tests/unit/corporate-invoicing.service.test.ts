@@ -0,0 +1,233 @@
+/**
+ * Corporate Invoicing Service Unit Tests
+ * UBI Payment Service
+ */
+
+import { beforeEach, describe, expect, it, vi } from "vitest";
+import { mockRedisClient, resetMocks } from "../setup";
+
+vi.mock("../../src/lib/redis", () => ({
+  redis: mockRedisClient,
+}));
+
+import { BillingService } from "../../src/services/billing.service";
+import type { CorporateAccountsService } from "../../src/services/corporate-accounts.service";
+import {
+  CorporateInvoicingService,
+  INVOICE_ISSUED_CHANNEL,
+  previousMonth,
+} from "../../src/services/corporate-invoicing.service";
+import type { CorporateTrip, Organization } from "../../src/types/b2b.types";
+
+const organization = {
+  id: "org-1",
+  name: "Acme",
+  legalName: "Acme Nigeria Ltd",
+  email: "ops@acme.ng",
+  billingEmail: "billing@acme.ng",
+  country: "NG",
+  status: "ACTIVE",
+  billingCycle: "MONTHLY",
+  paymentTermDays: 30,
+  settings: { currency: "NGN" },
+} as unknown as Organization;
+
+function trip(
+  id: string,
+  completedAt: string,
+  actualCost: number,
+  overrides: Partial<CorporateTrip> = {},
+): CorporateTrip {
+  return {
+    id,
+    organizationId: "org-1",
+    memberId: "member-1",
+    memberName: "Ada Obi",
+    pickupAddress: "Victoria Island",
+    dropoffAddress: "Ikeja",
+    vehicleType: "standard",
+    currency: "NGN",
+    requiresApproval: false,
+    approvalStatus: "APPROVED",
+    status: "completed",
+    completedAt: new Date(completedAt),
+    actualCost,
+    createdAt: new Date(completedAt),
+    ...overrides,
+  } as CorporateTrip;
+}
+
+function page<T>(data: T[]) {
+  return {
+    data,
+    pagination: {
+      total: data.length,
+      page: 1,
+      limit: data.length,
+      totalPages: 1,
+      hasNext: false,
+      hasPrev: false,
+    },
+  };
+}
+
+describe("CorporateInvoicingService", () => {
+  let trips: CorporateTrip[];
+  let billing: BillingService;
+  let publisher: { publish: ReturnType<typeof vi.fn> };
+  let service: CorporateInvoicingService;
+
+  const periodStart = new Date("2024-01-01T00:00:00Z");
+  const periodEnd = new Date("2024-02-01T00:00:00Z");
+
+  beforeEach(() => {
+    resetMocks();
+    trips = [
+      trip("t-1", "2024-01-05T09:00:00Z", 4000, {
+        costCenterId: "cc-sales",
+        costCenterName: "Sales",
+      }),
+      trip("t-2", "2024-01-20T18:30:00Z", 6000, {
+        costCenterId: "cc-sales",
+        costCenterName: "Sales",
+      }),
+      trip("t-3", "2024-01-31T23:59:00Z", 2500),
+      trip("t-4", "2024-02-01T00:00:00Z", 9999),
+      trip("t-5", "2024-01-10T12:00:00Z", 50, { currency: "USD" }),
+    ];
+    const accounts = {
+      getOrganization: vi.fn(async (id: string) =>
+        id === organization.id ? organization : null,
+      ),
+      listOrganizations: vi.fn(async () => page([organization])),
+      listCorporateTrips: vi.fn(async () => page(trips)),
+    } as unknown as CorporateAccountsService;
+
+    billing = new BillingService();
+    publisher = { publish: vi.fn().mockResolvedValue(1) };
+    service = new CorporateInvoicingService(accounts, billing, publisher);
+  });
+
+  describe("previousMonth", () => {
+    it("should return last calendar month as a half-open range", () => {
+      const { start, end } = previousMonth(new Date("2024-03-01T02:00:00Z"));
+      expect(start.toISOString()).toBe("2024-02-01T00:00:00.000Z");
+      expect(end.toISOString()).toBe("2024-03-01T00:00:00.000Z");
+    });
+
+    it("should roll back over the year boundary", () => {
+      const { start } = previousMonth(new Date("2024-01-15T00:00:00Z"));
+      expect(start.toISOString()).toBe("2023-12-01T00:00:00.000Z");
+    });
+  });
+
+  describe("issueInvoice", () => {
+    it("should bill the period's rides per cost center with VAT", async () => {
+      const invoice = await service.issueInvoice(
+        "org-1",
+        periodStart,
+        periodEnd,
+      );
+
+      expect(invoice).not.toBeNull();
+      expect(invoice!.status).toBe("SENT");
+      expect(invoice!.currency).toBe("NGN");
+      expect(invoice!.lineItems.map((i) => i.description)).toEqual([
+        "Business rides - Sales",
+        "Business rides - Unassigned",
+      ]);
+      expect(invoice!.lineItems[0]!.quantity).toBe(2);
+      expect(invoice!.lineItems[0]!.amount).toBe(10000);
+      expect(invoice!.subtotal).toBe(12500);
+      expect(invoice!.taxRate).toBe(0.075);
+      expect(invoice!.taxAmount).toBe(937.5);
+      expect(invoice!.total).toBe(13437.5);
+      expect(invoice!.pdfUrl).toBe(`/b2b/billing/invoices/${invoice!.id}/pdf`);
+    });
+
+    it("should publish an invoice issued event for billing email", async () => {
+      const invoice = await service.issueInvoice(
+        "org-1",
+        periodStart,
+        periodEnd,
+      );
+
+      expect(publisher.publish).toHaveBeenCalledTimes(1);
+      const [channel, message] = publisher.publish.mock.calls[0]!;
+      expect(channel).toBe(INVOICE_ISSUED_CHANNEL);
+      expect(JSON.parse(message)).toMatchObject({
+        type: "invoice.issued",
+        invoiceId: invoice!.id,
+        billingEmail: "billing@acme.ng",
+        total: 13437.5,
+      });
+    });
+
+    it("should not invoice the same period twice", async () => {
+      await service.issueInvoice("org-1", periodStart, periodEnd);
+      const again = await service.issueInvoice(
+        "org-1",
+        periodStart,
+        periodEnd,
+      );
+
+      expect(again).toBeNull();
+      expect(publisher.publish).toHaveBeenCalledTimes(1);
+    });
+
+    it("should still issue the invoice when publishing fails", async () => {
+      publisher.publish.mockRejectedValue(new Error("redis down"));
+
+      const invoice = await service.issueInvoice(
+        "org-1",
+        periodStart,
+        periodEnd,
+      );
+
+      expect(invoice?.status).toBe("SENT");
+    });
+
+    it("should refuse a country without a VAT rate", async () => {
+      (organization as { country: string }).country = "XX";
+      try {
+        await expect(
+          service.issueInvoice("org-1", periodStart, periodEnd),
+        ).rejects.toThrow("No VAT rate configured for XX");
+      } finally {
+        (organization as { country: string }).country = "NG";
+      }
+    });
+  });
+
+  describe("runMonthlyInvoicing", () => {
+    it("should invoice last month for monthly organizations", async () => {
+      const result = await service.runMonthlyInvoicing(
+        new Date("2024-02-01T04:00:00Z"),
+      );
+
+      expect(result.issued).toHaveLength(1);
+      expect(result.failed).toHaveLength(0);
+      expect(result.periodStart.toISOString()).toBe("2024-01-01T00:00:00.000Z");
+    });
+  });
+
+  describe("renderInvoicePdf", () => {
+    it("should render a PDF only for the owning organization", async () => {
+      const invoice = await service.issueInvoice(
+        "org-1",
+        periodStart,
+        periodEnd,
+      );
+
+      const rendered = await service.renderInvoicePdf(invoice!.id, "org-1");
+      expect(rendered!.invoiceNumber).toBe(invoice!.invoiceNumber);
+      const text = rendered!.pdf.toString("latin1");
+      expect(text.startsWith("%PDF-1.4")).toBe(true);
+      expect(text.trimEnd().endsWith("%%EOF")).toBe(true);
+      expect(text).toContain(invoice!.invoiceNumber);
+      expect(text).toContain("NGN 13,437.50");
+
+      expect(await service.renderInvoicePdf(invoice!.id, "org-2")).toBeNull();
+    });
+  });
+});
@@ -0,0 +1,207 @@
+/**
+ * Driver Tax Statements Service Unit Tests
+ * UBI Payment Service
+ */
+
+import { beforeEach, describe, expect, it, vi } from "vitest";
+import {
+  DriverStatementsService,
+  renderStatementPdf,
+  resolveStatementPeriod,
+  taxYearOf,
+} from "../../src/services/driver/statements.service";
+import { StatementPeriodType } from "../../src/types/driver.types";
+
+function ledgerRow(
+  completedAt: string,
+  amounts: {
+    grossFare: string;
+    commission: string;
+    tip?: string;
+    incentiveBonus?: string;
+    netEarnings: string;
+  },
+  currency = "NGN",
+) {
+  return {
+    tip: "0.00",
+    incentiveBonus: "0.00",
+    ...amounts,
+    currency,
+    completedAt: new Date(completedAt),
+  };
+}
+
+describe("DriverStatementsService", () => {
+  // ===========================================
+  // TAX PERIOD TESTS
+  // ===========================================
+
+  describe("resolveStatementPeriod", () => {
+    it("should default to the last closed calendar tax year", () => {
+      const period = resolveStatementPeriod(
+        "NG",
+        StatementPeriodType.ANNUAL,
+        new Date("2025-03-10T00:00:00Z"),
+      );
+
+      expect(period.taxYear).toBe("2024");
+      // Midnight in Lagos
+      expect(period.start.toISOString()).toBe("2023-12-31T23:00:00.000Z");
+      expect(period.end.toISOString()).toBe("2024-12-31T23:00:00.000Z");
+    });
+
+    it("should use the March tax year in South Africa", () => {
+      const period = resolveStatementPeriod(
+        "ZA",
+        StatementPeriodType.ANNUAL,
+        new Date("2025-06-01T00:00:00Z"),
+        2024,
+      );
+
+      expect(period.taxYear).toBe("2024/25");
+      expect(period.start.toISOString()).toBe("2024-02-29T22:00:00.000Z");
+      expect(period.end.toISOString()).toBe("2025-02-28T22:00:00.000Z");
+    });
+
+    it("should default to the last closed quarter of a July tax year", () => {
+      const period = resolveStatementPeriod(
+        "UG",
+        StatementPeriodType.QUARTERLY,
+        new Date("2025-01-15T00:00:00Z"),
+      );
+
+      expect(period.taxYear).toBe("2024/25");
+      expect(period.quarter).toBe(2);
+      expect(period.start.toISOString()).toBe("2024-09-30T21:00:00.000Z");
+      expect(period.end.toISOString()).toBe("2024-12-31T21:00:00.000Z");
+    });
+
+    it("should fall back to the previous tax year's last quarter", () => {
+      const period = resolveStatementPeriod(
+        "NG",
+        StatementPeriodType.QUARTERLY,
+        new Date("2025-02-10T00:00:00Z"),
+      );
+
+      expect(period.taxYear).toBe("2024");
+      expect(period.quarter).toBe(4);
+    });
+
+    it("should place trips after local midnight in the new tax year", () => {
+      expect(taxYearOf("NG", new Date("2024-12-31T23:30:00Z"))).toBe(2025);
+      expect(taxYearOf("NG", new Date("2024-12-31T22:30:00Z"))).toBe(2024);
+    });
+  });
+
+  // ===========================================
+  // STATEMENT TESTS
+  // ===========================================
+
+  describe("getStatement", () => {
+    let db: any;
+    let service: DriverStatementsService;
+    const now = new Date("2025-03-10T00:00:00Z");
+
+    beforeEach(() => {
+      db = {
+        driver: {
+          findUnique: vi.fn().mockResolvedValue({ user: { country: "NG" } }),
+        },
+        tripEarning: {
+          findMany: vi.fn().mockResolvedValue([
+            ledgerRow("2024-01-31T23:30:00Z", {
+              grossFare: "1000.10",
+              commission: "250.03",
+              tip: "100.00",
+              netEarnings: "850.07",
+            }),
+            ledgerRow("2024-02-15T12:00:00Z", {
+              grossFare: "2500.25",
+              commission: "625.06",
+              incentiveBonus: "200.00",
+              netEarnings: "2075.19",
+            }),
+            ledgerRow(
+              "2024-03-01T09:00:00Z",
+              { grossFare: "12.00", commission: "3.00", netEarnings: "9.00" },
+              "USD",
+            ),
+          ]),
+        },
+      };
+      service = new DriverStatementsService(db);
+    });
+
+    it("should sum the earnings ledger per currency to the cent", async () => {
+      const statement = await service.getStatement(
+        "driver-1",
+        StatementPeriodType.ANNUAL,
+        { year: 2024 },
+        now,
+      );
+
+      expect(statement!.final).toBe(true);
+      expect(statement!.totals.map((t) => t.currency)).toEqual(["NGN", "USD"]);
+
+      const ngn = statement!.totals[0]!;
+      expect(ngn.tripCount).toBe(2);
+      expect(ngn.grossFares).toBe(3500.35);
+      expect(ngn.commissions).toBe(875.09);
+      expect(ngn.tips).toBe(100);
+      expect(ngn.incentives).toBe(200);
+      expect(ngn.netEarnings).toBe(2925.26);
+      // The first trip was just after midnight on 1 February in Lagos
+      expect(ngn.months.map((m) => m.month)).toEqual(["2024-02"]);
+
+      expect(db.tripEarning.findMany).toHaveBeenCalledWith(
+        expect.objectContaining({
+          where: {
+            driverId: "driver-1",
+            completedAt: {
+              gte: new Date("2023-12-31T23:00:00Z"),
+              lt: new Date("2024-12-31T23:00:00Z"),
+            },
+          },
+        }),
+      );
+    });
+
+    it("should mark a running period as provisional", async () => {
+      const statement = await service.getStatement(
+        "driver-1",
+        StatementPeriodType.ANNUAL,
+        { year: 2025 },
+        now,
+      );
+
+      expect(statement!.final).toBe(false);
+    });
+
+    it("should return null for a period that has not started", async () => {
+      const statement = await service.getStatement(
+        "driver-1",
+        StatementPeriodType.ANNUAL,
+        { year: 2030 },
+        now,
+      );
+
+      expect(statement).toBeNull();
+      expect(db.tripEarning.findMany).not.toHaveBeenCalled();
+    });
+
+    it("should render the statement as a PDF", async () => {
+      const statement = await service.getStatement(
+        "driver-1",
+        StatementPeriodType.ANNUAL,
+        { year: 2024 },
+        now,
+      );
+
+      const text = renderStatementPdf(statement!).toString("latin1");
+      expect(text.startsWith("%PDF-1.4")).toBe(true);
+      expect(text).toContain("Tax year 2024");
+      expect(text).toContain("NGN 2,925.26");
+    });
+  });
+});
@@ -0,0 +1,268 @@
+/**
+ * Emergency Dispatch Partner Service Unit Tests
+ */
+
+import { beforeEach, describe, expect, it, vi } from "vitest";
+
+import {
+  EmergencyDispatchService,
+  EmergencyPartnerAdapter,
+  formatDispatchSMS,
+} from "../../src/services/emergency-dispatch.service";
+import {
+  EmergencyDispatchPayload,
+  EmergencyPartnerConfig,
+  SOSIncident,
+} from "../../src/types/safety.types";
+
+vi.mock("../../src/lib/prisma", () => ({
+  prisma: {
+    ride: {
+      findUnique: vi.fn().mockResolvedValue({
+        rider: {
+          user: { firstName: "Ada", lastName: "Obi", phone: "+234800000001" },
+        },
+        driver: {
+          user: {
+            firstName: "Musa",
+            lastName: "Bello",
+            phone: "+234800000002",
+          },
+          vehicle: {
+            make: "Toyota",
+            model: "Corolla",
+            color: "Silver",
+            plateNumber: "LND-123-AB",
+          },
+        },
+      }),
+    },
+    $disconnect: vi.fn(),
+  },
+}));
+
+const PARTNERS: Record<string, EmergencyPartnerConfig> = {
+  NG: {
+    country: "NG",
+    partnerName: "Lagos Dispatch",
+    channel: "API",
+    apiUrl: "https://dispatch.example/ng",
+    escalateAfterMinutes: 5,
+  },
+  KE: {
+    country: "KE",
+    partnerName: "Nairobi Desk",
+    channel: "SMS",
+    escalateAfterMinutes: 3,
+  },
+};
+
+function fakeAdapter(
+  dispatch: EmergencyPartnerAdapter["dispatch"],
+): EmergencyPartnerAdapter {
+  return {
+    channel: "API",
+    isConfigured: (partner) => Boolean(partner.apiUrl),
+    dispatch: vi.fn(dispatch),
+  };
+}
+
+function newIncident(
+  service: EmergencyDispatchService,
+  country: string,
+  tripId?: string,
+): SOSIncident {
+  const triggeredAt = new Date("2026-10-18T20:00:00Z");
+  return {
+    id: "sos_1",
+    userId: "user_1",
+    tripId,
+    status: "ACTIVE",
+    escalationLevel: "LEVEL_1",
+    triggerMethod: "button",
+    triggerLocation: { lat: 6.5244, lng: 3.3792 },
+    emergencyEscalation: service.schedule(country, triggeredAt),
+    triggeredAt,
+  };
+}
+
+describe("EmergencyDispatchService", () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+  });
+
+  describe("schedule", () => {
+    it("should schedule after the country's partner delay", () => {
+      const service = new EmergencyDispatchService(PARTNERS, []);
+      const triggeredAt = new Date("2026-10-18T20:00:00Z");
+
+      const escalation = service.schedule("KE", triggeredAt);
+
+      expect(escalation.status).toBe("SCHEDULED");
+      expect(escalation.partnerName).toBe("Nairobi Desk");
+      expect(escalation.channel).toBe("SMS");
+      expect(escalation.attempts).toBe(0);
+      expect(escalation.scheduledFor.getTime() - triggeredAt.getTime()).toBe(
+        3 * 60 * 1000,
+      );
+    });
+
+    it("should fall back to the default delay without a partner", () => {
+      const service = new EmergencyDispatchService(PARTNERS, []);
+      const triggeredAt = new Date("2026-10-18T20:00:00Z");
+
+      const escalation = service.schedule("UG", triggeredAt);
+
+      expect(escalation.partnerName).toBeUndefined();
+      expect(escalation.scheduledFor.getTime() - triggeredAt.getTime()).toBe(
+        5 * 60 * 1000,
+      );
+    });
+  });
+
+  describe("dispatch", () => {
+    it("should send the trip's location, people and vehicle to the partner", async () => {
+      const adapter = fakeAdapter(async () => ({ partnerIncidentId: "P-42" }));
+      const service = new EmergencyDispatchService(PARTNERS, [adapter]);
+      const incident = newIncident(service, "NG", "trip_1");
+
+      const escalation = await service.dispatch(
+        incident,
+        "https://ubi.app/sos/track/t",
+      );
+
+      expect(escalation.status).toBe("SENT");
+      expect(escalation.attempts).toBe(1);
+      expect(escalation.partnerIncidentId).toBe("P-42");
+      expect(escalation.reference).toMatch(/^ESC-[0-9A-F]{12}$/);
+
+      const payload = vi.mocked(adapter.dispatch).mock.calls[0][1];
+      expect(payload.reference).toBe(escalation.reference);
+      expect(payload.location).toEqual(incident.triggerLocation);
+      expect(payload.locationLink).toBe("https://ubi.app/sos/track/t");
+      expect(payload.rider).toEqual({
+        name: "Ada Obi",
+        phone: "+234800000001",
+      });
+      expect(payload.driver?.name).toBe("Musa Bello");
+      expect(payload.vehicle?.plateNumber).toBe("LND-123-AB");
+    });
+
+    it("should retry transient failures with the same reference", async () => {
+      let calls = 0;
+      const adapter = fakeAdapter(async () => {
+        calls++;
+        if (calls < 3) {
+          throw new Error("Partner API returned 503");
+        }
+        return {};
+      });
+      const service = new EmergencyDispatchService(PARTNERS, [adapter]);
+      const incident = newIncident(service, "NG");
+
+      const escalation = await service.dispatch(incident, "link");
+
+      expect(escalation.status).toBe("SENT");
+      expect(escalation.attempts).toBe(3);
+      expect(escalation.lastError).toBeUndefined();
+      const references = vi
+        .mocked(adapter.dispatch)
+        .mock.calls.map(([, payload]) => payload.reference);
+      expect(new Set(references).size).toBe(1);
+    });
+
+    it("should fail after the last attempt", async () => {
+      const adapter = fakeAdapter(async () => {
+        throw new Error("Partner API returned 500");
+      });
+      const service = new EmergencyDispatchService(PARTNERS, [adapter]);
+      const incident = newIncident(service, "NG");
+
+      const escalation = await service.dispatch(incident, "link");
+
+      expect(escalation.status).toBe("FAILED");
+      expect(escalation.attempts).toBe(3);
+      expect(escalation.lastError).toBe("Partner API returned 500");
+    });
+
+    it("should be unavailable where no partner is configured", async () => {
+      const adapter = fakeAdapter(async () => ({}));
+      const service = new EmergencyDispatchService(PARTNERS, [adapter]);
+
+      // KE's partner is an SMS desk with no number and no SMS adapter here
+      const escalation = await service.dispatch(
+        newIncident(service, "KE"),
+        "link",
+      );
+
+      expect(escalation.status).toBe("UNAVAILABLE");
+      expect(escalation.attempts).toBe(0);
+      expect(adapter.dispatch).not.toHaveBeenCalled();
+    });
+  });
+
+  describe("acknowledge", () => {
+    it("should only accept the reference sent with the dispatch", async () => {
+      const service = new EmergencyDispatchService(PARTNERS, [
+        fakeAdapter(async () => ({ partnerIncidentId: "P-1" })),
+      ]);
+      const escalation = await service.dispatch(
+        newIncident(service, "NG"),
+        "link",
+      );
+
+      expect(service.acknowledge(escalation, "ESC-WRONG")).toBe(false);
+      expect(escalation.status).toBe("SENT");
+
+      expect(
+        service.acknowledge(escalation, escalation.reference as string, "P-2"),
+      ).toBe(true);
+      expect(escalation.status).toBe("ACKNOWLEDGED");
+      expect(escalation.acknowledgedAt).toBeInstanceOf(Date);
+      expect(escalation.partnerIncidentId).toBe("P-2");
+    });
+
+    it("should refuse an escalation that was never sent", () => {
+      const service = new EmergencyDispatchService(PARTNERS, []);
+      const escalation = service.schedule("NG", new Date());
+
+      expect(service.acknowledge(escalation, "")).toBe(false);
+      expect(escalation.status).toBe("SCHEDULED");
+    });
+  });
+});
+
+describe("formatDispatchSMS", () => {
+  const payload: EmergencyDispatchPayload = {
+    reference: "ESC-ABC",
+    incidentId: "sos_1",
+    country: "KE",
+    triggerMethod: "button",
+    triggeredAt: new Date("2026-10-18T20:00:00Z"),
+    location: { lat: -1.2921, lng: 36.8219 },
+    locationLink: "https://ubi.app/sos/track/t",
+    rider: { name: "Wanjiru K", phone: "+254700000001" },
+  };
+
+  it("should include the reference, location and rider", () => {
+    expect(formatDispatchSMS(payload)).toBe(
+      "UBI SOS ESC-ABC | Loc: -1.29210,36.82190 | Track: https://ubi.app/sos/track/t | Rider: Wanjiru K +254700000001",
+    );
+  });
+
+  it("should add the vehicle and driver when known", () => {
+    const sms = formatDispatchSMS({
+      ...payload,
+      driver: { name: "Otieno J" },
+      vehicle: {
+        make: "Toyota",
+        model: "Axio",
+        color: "White",
+        plateNumber: "KDA 123A",
+      },
+    });
+
+    expect(sms).toContain("Vehicle: White Toyota Axio KDA 123A");
+    expect(sms.endsWith("Driver: Otieno J")).toBe(true);
+  });
+});
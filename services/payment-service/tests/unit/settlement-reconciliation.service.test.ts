@@ -0,0 +1,241 @@
+/**
+ * Settlement Report Reconciliation Service Unit Tests
+ * UBI Payment Service
+ */
+
+import { Currency, PaymentProvider, PaymentStatus } from "@prisma/client";
+import { beforeEach, describe, expect, it, vi } from "vitest";
+import { mockPrismaClient, resetMocks } from "../setup";
+
+vi.mock("../../src/lib/prisma", () => ({
+  prisma: mockPrismaClient,
+}));
+
+import {
+  parseCsv,
+  parseSettlementReport,
+  SettlementReconciliationService,
+} from "../../src/services/settlement-reconciliation.service";
+
+const day = new Date("2024-01-15T00:00:00Z");
+
+function payment(
+  id: string,
+  reference: string,
+  amount: number,
+  overrides: Record<string, unknown> = {},
+) {
+  return {
+    id,
+    providerReference: reference,
+    amount: amount.toFixed(4),
+    status: PaymentStatus.COMPLETED,
+    confirmedAt: new Date("2024-01-15T10:00:00Z"),
+    transaction: {
+      status: "COMPLETED",
+      amount: amount.toFixed(4),
+      ledgerEntries: [{ id: "le-1" }, { id: "le-2" }],
+    },
+    ...overrides,
+  };
+}
+
+describe("SettlementReconciliationService", () => {
+  let service: SettlementReconciliationService;
+
+  beforeEach(() => {
+    resetMocks();
+    service = new SettlementReconciliationService(mockPrismaClient, "");
+    (
+      mockPrismaClient.reconciliationReport.upsert as ReturnType<typeof vi.fn>
+    ).mockResolvedValue({ id: "report-1" });
+  });
+
+  // ===========================================
+  // REPORT PARSING TESTS
+  // ===========================================
+
+  describe("parseCsv", () => {
+    it("should handle quoted fields and normalize headers", () => {
+      const rows = parseCsv(
+        'Receipt No.,Details,Paid In\r\nQAB1,"Payment from ""UBI"", ride","1,500.00"\n',
+      );
+
+      expect(rows).toEqual([
+        {
+          receipt_no: "QAB1",
+          details: 'Payment from "UBI", ride',
+          paid_in: "1,500.00",
+        },
+      ]);
+    });
+  });
+
+  describe("parseSettlementReport", () => {
+    it("should parse a Paystack report", () => {
+      const lines = parseSettlementReport(
+        PaymentProvider.PAYSTACK,
+        "Reference,Amount,Fees,Currency,Status,Paid At\nPSK-1,2500.00,37.50,NGN,success,2024-01-15T09:00:00Z\nPSK-2,900,13.5,NGN,reversed,2024-01-15T11:00:00Z\n",
+        Currency.NGN,
+      );
+
+      expect(lines).toHaveLength(2);
+      expect(lines[0]).toMatchObject({
+        reference: "PSK-1",
+        amount: 2500,
+        fee: 37.5,
+        status: "success",
+      });
+      expect(lines[1].status).toBe("reversed");
+    });
+
+    it("should skip M-Pesa charge lines and take the filed currency", () => {
+      const lines = parseSettlementReport(
+        PaymentProvider.MPESA,
+        'Receipt No.,Completion Time,Details,Transaction Status,Paid In,Withdrawn\nQAB1,2024-01-15 08:00:00,Payment,Completed,"1,200.00",\nQAB2,2024-01-15 08:00:01,Charge,Completed,,12.00\n',
+        Currency.KES,
+      );
+
+      expect(lines).toHaveLength(1);
+      expect(lines[0]).toMatchObject({
+        reference: "QAB1",
+        amount: 1200,
+        currency: "KES",
+        status: "success",
+      });
+    });
+  });
+
+  // ===========================================
+  // RECONCILIATION TESTS
+  // ===========================================
+
+  describe("reconcileReport", () => {
+    const report = [
+      "tx_ref,amount,app_fee,currency,status,created_at",
+      "FLW-1,1000,14,NGN,successful,2024-01-15T09:00:00Z",
+      "FLW-2,2000,28,NGN,successful,2024-01-15T09:10:00Z",
+      "FLW-3,3000,42,NGN,successful,2024-01-15T09:20:00Z",
+      "FLW-4,4000,56,NGN,successful,2024-01-15T09:30:00Z",
+    ].join("\n");
+
+    it("should flag missing captures, amount drift, ledger drift and unsettled captures", async () => {
+      (
+        mockPrismaClient.paymentTransaction.findMany as ReturnType<typeof vi.fn>
+      ).mockResolvedValue([
+        payment("pay-1", "FLW-1", 1000),
+        payment("pay-2", "FLW-2", 2500),
+        payment("pay-3", "FLW-3", 3000, { status: PaymentStatus.PENDING }),
+        payment("pay-4", "FLW-4", 4000, { transaction: null }),
+        payment("pay-5", "FLW-5", 5000),
+      ]);
+
+      const result = await service.reconcileReport(
+        PaymentProvider.FLUTTERWAVE,
+        Currency.NGN,
+        day,
+        report,
+      );
+
+      expect(result.matched).toBe(1);
+      expect(result.mismatches.map((m) => [m.type, m.transactionId])).toEqual([
+        ["AMOUNT_DRIFT", "pay-2"],
+        ["MISSING_CAPTURE", "pay-3"],
+        ["LEDGER_DRIFT", "pay-4"],
+        ["NOT_SETTLED", "pay-5"],
+      ]);
+      expect(
+        mockPrismaClient.reconciliationDiscrepancy.deleteMany,
+      ).toHaveBeenCalledWith({
+        where: { reconciliationReportId: "report-1", status: "pending" },
+      });
+      expect(
+        (
+          mockPrismaClient.reconciliationDiscrepancy.createMany as ReturnType<
+            typeof vi.fn
+          >
+        ).mock.calls[0][0].data,
+      ).toHaveLength(4);
+    });
+
+    it("should resolve the report when everything matches", async () => {
+      (
+        mockPrismaClient.paymentTransaction.findMany as ReturnType<typeof vi.fn>
+      ).mockResolvedValue([
+        payment("pay-1", "FLW-1", 1000),
+        payment("pay-2", "FLW-2", 2000),
+        payment("pay-3", "FLW-3", 3000),
+        payment("pay-4", "FLW-4", 4000.5),
+      ]);
+
+      const result = await service.reconcileReport(
+        PaymentProvider.FLUTTERWAVE,
+        Currency.NGN,
+        day,
+        report,
+      );
+
+      expect(result.matched).toBe(4);
+      expect(result.mismatches).toHaveLength(0);
+      expect(
+        (
+          mockPrismaClient.reconciliationReport.upsert as ReturnType<
+            typeof vi.fn
+          >
+        ).mock.calls[0][0].update.status,
+      ).toBe("resolved");
+      expect(
+        mockPrismaClient.reconciliationDiscrepancy.createMany,
+      ).not.toHaveBeenCalled();
+    });
+  });
+
+  describe("getSettlementStatus", () => {
+    it("should report missing, mismatched and matched days", async () => {
+      (
+        mockPrismaClient.reconciliationReport.findMany as ReturnType<
+          typeof vi.fn
+        >
+      ).mockResolvedValue([
+        {
+          date: day,
+          provider: PaymentProvider.MPESA,
+          currency: Currency.KES,
+          matched: 10,
+          amountDifference: "1200.0000",
+          reportData: { source: "settlement_file" },
+          discrepancyRecords: [{ status: "pending" }, { status: "resolved" }],
+        },
+      ]);
+
+      const statuses = await service.getSettlementStatus(
+        day,
+        day,
+        PaymentProvider.MPESA,
+      );
+
+      expect(statuses).toEqual([
+        {
+          date: "2024-01-15",
+          provider: PaymentProvider.MPESA,
+          currency: Currency.KES,
+          status: "MISMATCHED",
+          matched: 10,
+          mismatches: 2,
+          pendingMismatches: 1,
+          amountDifference: 1200,
+        },
+      ]);
+
+      const paystack = await service.getSettlementStatus(
+        day,
+        day,
+        PaymentProvider.PAYSTACK,
+      );
+      expect(paystack.map((s) => s.status)).toEqual([
+        "MISSING_REPORT",
+        "MISSING_REPORT",
+      ]);
+    });
+  });
+});
@@ -4,6 +4,7 @@
 
 import { afterEach, beforeEach, describe, expect, it, vi } from "vitest";
 
+import { notificationClient } from "../../src/lib/notification-client";
 import { TripMonitorService } from "../../src/services/trip-monitor.service";
 
 // Mock the notification client
@@ -273,4 +274,183 @@ describe("TripMonitorService", () => {
       expect(session).toBeNull();
     });
   });
+
+  describe("nightCheckIns", () => {
+    const expectedRoute = [
+      { lat: 6.5244, lng: 3.3792, accuracy: 10, timestamp: new Date() },
+      { lat: 6.53, lng: 3.38, accuracy: 10, timestamp: new Date() },
+    ];
+
+    const startTrip = (tripId: string, expectedDuration: number) =>
+      tripMonitor.startMonitoring({
+        tripId,
+        riderId: `rider_${tripId}`,
+        driverId: `driver_${tripId}`,
+        expectedRoute,
+        expectedDuration,
+        country: "NG",
+      });
+
+    const nightCheckIns = () =>
+      vi
+        .mocked(notificationClient.sendSafetyCheck)
+        .mock.calls.filter(([, , reason]) => reason.startsWith("Night-time"));
+
+    beforeEach(() => {
+      vi.useFakeTimers();
+      // 21:00 in Lagos
+      vi.setSystemTime(new Date("2026-10-18T20:00:00Z"));
+      tripMonitor = new TripMonitorService();
+    });
+
+    afterEach(() => {
+      vi.useRealTimers();
+    });
+
+    it("should check in on a long trip after dark", async () => {
+      const session = await startTrip("trip_night", 45 * 60);
+
+      await vi.advanceTimersByTimeAsync(19 * 60 * 1000);
+      expect(nightCheckIns()).toHaveLength(0);
+
+      await vi.advanceTimersByTimeAsync(60 * 1000);
+      expect(nightCheckIns()).toHaveLength(1);
+      expect(nightCheckIns()[0]).toEqual([
+        "rider_trip_night",
+        "trip_night",
+        "Night-time check-in: Is everything OK?",
+        expect.any(String),
+        120,
+      ]);
+      expect(session.nightCheckInCount).toBe(1);
+    });
+
+    it("should not check in during the day", async () => {
+      // 11:00 in Lagos
+      vi.setSystemTime(new Date("2026-10-18T10:00:00Z"));
+      await startTrip("trip_day", 45 * 60);
+
+      await vi.advanceTimersByTimeAsync(45 * 60 * 1000);
+
+      expect(nightCheckIns()).toHaveLength(0);
+    });
+
+    it("should wait for a short trip to run long", async () => {
+      await startTrip("trip_short", 15 * 60);
+
+      await vi.advanceTimersByTimeAsync(29 * 60 * 1000);
+      expect(nightCheckIns()).toHaveLength(0);
+
+      await vi.advanceTimersByTimeAsync(60 * 1000);
+      expect(nightCheckIns()).toHaveLength(1);
+    });
+
+    it("should not raise a flag for a missed check-in alone", async () => {
+      await startTrip("trip_asleep", 45 * 60);
+
+      const check = await tripMonitor.triggerSafetyCheck(
+        "trip_asleep",
+        "rider_trip_asleep",
+        "Night-time check-in: Is everything OK?",
+        "night",
+      );
+      await vi.advanceTimersByTimeAsync(120 * 1000);
+
+      expect(check.status).toBe("NO_RESPONSE");
+      expect(await tripMonitor.getSafetyFlagQueue()).toHaveLength(0);
+    });
+
+    it("should raise one flag for a missed check-in during a route deviation", async () => {
+      await startTrip("trip_flag", 45 * 60);
+
+      await tripMonitor.triggerSafetyCheck(
+        "trip_flag",
+        "rider_trip_flag",
+        "Night-time check-in: Is everything OK?",
+        "night",
+      );
+      await vi.advanceTimersByTimeAsync(120 * 1000);
+
+      // Far off the expected route, twice
+      for (let i = 0; i < 2; i++) {
+        await tripMonitor.processLocationUpdate("trip_flag", {
+          lat: 6.6,
+          lng: 3.5,
+          speed: 40,
+          accuracy: 10,
+          timestamp: new Date(),
+        });
+      }
+
+      const queue = await tripMonitor.getSafetyFlagQueue();
+      expect(queue).toHaveLength(1);
+      expect(queue[0].tripId).toBe("trip_flag");
+      expect(queue[0].severity).toBe("HIGH");
+      expect(queue[0].triggers).toEqual([
+        "night_checkin_no_response",
+        "route_deviation",
+      ]);
+      expect(queue[0].status).toBe("OPEN");
+    });
+
+    it("should not flag a deviation when the rider answered", async () => {
+      await startTrip("trip_answered", 45 * 60);
+
+      const check = await tripMonitor.triggerSafetyCheck(
+        "trip_answered",
+        "rider_trip_answered",
+        "Night-time check-in: Is everything OK?",
+        "night",
+      );
+      await tripMonitor.respondToSafetyCheck(check.id, "safe");
+      await tripMonitor.processLocationUpdate("trip_answered", {
+        lat: 6.6,
+        lng: 3.5,
+        speed: 40,
+        accuracy: 10,
+        timestamp: new Date(),
+      });
+      await vi.advanceTimersByTimeAsync(120 * 1000);
+
+      expect(await tripMonitor.getSafetyFlagQueue()).toHaveLength(0);
+    });
+
+    it("should drop closed flags from the queue", async () => {
+      await startTrip("trip_review", 45 * 60);
+      await tripMonitor.triggerSafetyCheck(
+        "trip_review",
+        "rider_trip_review",
+        "Night-time check-in: Is everything OK?",
+        "night",
+      );
+      await vi.advanceTimersByTimeAsync(120 * 1000);
+      await tripMonitor.processLocationUpdate("trip_review", {
+        lat: 6.6,
+        lng: 3.5,
+        accuracy: 10,
+        timestamp: new Date(),
+      });
+      const [flag] = await tripMonitor.getSafetyFlagQueue();
+
+      const acknowledged = await tripMonitor.reviewSafetyFlag(
+        flag.id,
+        "agent_1",
+        "acknowledge",
+      );
+      expect(acknowledged?.status).toBe("ACKNOWLEDGED");
+      expect(await tripMonitor.getSafetyFlagQueue()).toHaveLength(1);
+
+      const closed = await tripMonitor.reviewSafetyFlag(
+        flag.id,
+        "agent_2",
+        "close",
+      );
+      expect(closed?.status).toBe("CLOSED");
+      expect(closed?.acknowledgedBy).toBe("agent_1");
+      expect(await tripMonitor.getSafetyFlagQueue()).toHaveLength(0);
+      expect(
+        await tripMonitor.reviewSafetyFlag(flag.id, "agent_2", "close"),
+      ).toBeNull();
+    });
+  });
 });
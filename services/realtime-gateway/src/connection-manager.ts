@@ -7,6 +7,8 @@ import { Redis } from "ioredis";
 import { nanoid } from "nanoid";
 import { WebSocket } from "ws";
 import { connectionLogger as logger } from "./lib/logger.js";
+import { OfferStream } from "./lib/offer-stream.js";
+import { REDIS_CHANNELS } from "./types/index.js";
 import type {
   ConnectionEvent,
   UserType,
@@ -20,11 +22,13 @@ export class ConnectionManager {
   private websockets = new Map<string, WebSocket>();
   private redis: Redis;
   private redisSub: Redis;
+  private offers: OfferStream;
   private serverId: string;
 
   constructor(redisUrl: string) {
     this.redis = new Redis(redisUrl);
     this.redisSub = new Redis(redisUrl);
+    this.offers = new OfferStream(this.redis);
     this.serverId = nanoid();
 
     // Subscribe to global events
@@ -48,6 +52,12 @@ export class ConnectionManager {
   }
 
   private handleRedisMessage(channel: string, message: string) {
+    // New offer on a driver's stream (format: offers:{driverId})
+    if (channel.startsWith("offers:")) {
+      void this.deliverOffers(channel.slice("offers:".length), ">");
+      return;
+    }
+
     try {
       // Extract userId from channel (format: user:{userId})
       const userId = channel.split(":")[1];
@@ -109,6 +119,14 @@ export class ConnectionManager {
     // Subscribe to user's channel
     await this.subscribeToChannel(`user:${userId}`);
 
+    // Drivers also receive offers from their stream, including any sent
+    // while they were reconnecting and not yet acknowledged
+    if (userType === "driver") {
+      await this.redisSub.subscribe(REDIS_CHANNELS.driverOffers(userId));
+      await this.deliverOffers(userId, "0");
+      await this.deliverOffers(userId, ">");
+    }
+
     // Setup WebSocket handlers
     this.setupWebSocketHandlers(ws, connectionId);
 
@@ -199,6 +217,10 @@ export class ConnectionManager {
         await this.handleDispatchResponse(connection, message.payload);
         break;
 
+      case "offer_ack":
+        await this.handleOfferAck(connection, message.payload.offerId);
+        break;
+
       default:
         logger.warn(
           { connectionId, messageType: message.type },
@@ -262,6 +284,60 @@ export class ConnectionManager {
       `dispatch:${payload.dispatchId}:response`,
       JSON.stringify(payload),
     );
+
+    // A response is as good as an acknowledgment
+    if (payload.offerId) {
+      await this.offers.ack(connection.userId, [payload.offerId]);
+    }
+  }
+
+  private async handleOfferAck(
+    connection: WebSocketConnection,
+    offerId: string,
+  ) {
+    if (connection.userType !== "driver") {
+      this.sendError(
+        connection.id,
+        "INVALID_USER_TYPE",
+        "Only drivers can acknowledge offers",
+      );
+      return;
+    }
+    if (!offerId) {
+      this.sendError(connection.id, "INVALID_MESSAGE", "offerId is required");
+      return;
+    }
+
+    await this.offers.ack(connection.userId, [offerId]);
+  }
+
+  /**
+   * Read a driver's offer stream and send the offers to every device the
+   * driver has connected, on this server or another. Offers stay pending
+   * until the driver app acknowledges them; expired ones are acknowledged
+   * here so they are never replayed.
+   */
+  private async deliverOffers(driverId: string, from: "0" | ">") {
+    try {
+      const { deliverable, discarded } = await this.offers.read(
+        driverId,
+        from,
+      );
+
+      await this.offers.ack(driverId, discarded);
+      for (const { message } of deliverable) {
+        await this.redis.publish(`user:${driverId}`, JSON.stringify(message));
+      }
+
+      if (deliverable.length > 0) {
+        logger.debug(
+          { driverId, offers: deliverable.length, replay: from === "0" },
+          "Delivered driver offers",
+        );
+      }
+    } catch (error) {
+      logger.error({ error, driverId }, "Error delivering driver offers");
+    }
   }
 
   async handleDisconnection(connectionId: string) {
@@ -303,6 +379,11 @@ export class ConnectionManager {
     // Unsubscribe if no more connections for this user on this server
     if (!userConns || userConns.size === 0) {
       await this.unsubscribeFromChannel(`user:${connection.userId}`);
+      if (connection.userType === "driver") {
+        await this.unsubscribeFromChannel(
+          REDIS_CHANNELS.driverOffers(connection.userId),
+        );
+      }
     }
 
     // Emit disconnection event
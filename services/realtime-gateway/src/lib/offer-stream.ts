@@ -0,0 +1,126 @@
+/**
+ * Driver Offer Stream
+ *
+ * Dispatch offers reach drivers through a Redis stream per driver rather
+ * than plain pub/sub, so an offer sent while a driver's socket is
+ * reconnecting is replayed instead of lost. The gateway reads each stream
+ * as a consumer group and acknowledges an entry once the driver app
+ * confirms the offer; entries past their expiry are acknowledged unsent.
+ */
+
+import type { Redis } from "ioredis";
+import type { WebSocketMessage } from "../types/index.js";
+import { REDIS_STREAMS } from "../types/index.js";
+
+export const OFFER_STREAM_GROUP = "gateway";
+
+// Entries read per XREADGROUP call
+const READ_COUNT = 20;
+
+// A stream entry as returned by XREADGROUP. Fields are null when the entry
+// was trimmed from the stream while still pending.
+export type StreamEntry = [id: string, fields: string[] | null];
+
+export interface OfferBatch {
+  // Messages to send, with the entry ID added to the payload as offerId
+  deliverable: { id: string; message: WebSocketMessage }[];
+  // Entries to acknowledge without sending: expired, trimmed or unreadable
+  discarded: string[];
+}
+
+/**
+ * Split stream entries into offers still worth sending and those to drop
+ */
+export function parseOfferEntries(
+  entries: StreamEntry[],
+  now: number,
+): OfferBatch {
+  const batch: OfferBatch = { deliverable: [], discarded: [] };
+
+  for (const [id, fields] of entries) {
+    const values = new Map<string, string>();
+    for (let i = 0; fields && i + 1 < fields.length; i += 2) {
+      values.set(fields[i]!, fields[i + 1]!);
+    }
+
+    const raw = values.get("message");
+    const expiresAt = Number(values.get("expires_at"));
+    if (!raw || !Number.isFinite(expiresAt) || expiresAt <= now) {
+      batch.discarded.push(id);
+      continue;
+    }
+
+    try {
+      const { type, payload } = JSON.parse(raw) as {
+        type: string;
+        payload?: Record<string, unknown>;
+      };
+      const message = {
+        type,
+        payload: { ...payload, offerId: id },
+      } as WebSocketMessage;
+      batch.deliverable.push({ id, message });
+    } catch {
+      batch.discarded.push(id);
+    }
+  }
+
+  return batch;
+}
+
+export class OfferStream {
+  constructor(private redis: Redis) {}
+
+  /**
+   * Read a driver's offers. "0" replays offers already read but never
+   * acknowledged; ">" reads offers not yet read by any gateway.
+   */
+  async read(driverId: string, from: "0" | ">"): Promise<OfferBatch> {
+    const key = REDIS_STREAMS.driverOffers(driverId);
+    const entries: StreamEntry[] = [];
+    let cursor: string = from;
+
+    for (;;) {
+      let result: [string, StreamEntry[]][] | null;
+      try {
+        result = (await this.redis.xreadgroup(
+          "GROUP",
+          OFFER_STREAM_GROUP,
+          driverId,
+          "COUNT",
+          READ_COUNT,
+          "STREAMS",
+          key,
+          cursor,
+        )) as [string, StreamEntry[]][] | null;
+      } catch (error) {
+        // No offer has been sent since the stream last expired
+        if (error instanceof Error && error.message.startsWith("NOGROUP")) {
+          break;
+        }
+        throw error;
+      }
+
+      const batch = result?.[0]?.[1] ?? [];
+      entries.push(...batch);
+      if (batch.length < READ_COUNT) break;
+
+      // Pending entries are paged by ID; new entries by reading again
+      if (from === "0") cursor = batch[batch.length - 1]![0];
+    }
+
+    return parseOfferEntries(entries, Date.now());
+  }
+
+  /**
+   * Acknowledge offers so they are not replayed
+   */
+  async ack(driverId: string, ids: string[]): Promise<number> {
+    if (ids.length === 0) return 0;
+    return this.redis.xack(
+      REDIS_STREAMS.driverOffers(driverId),
+      OFFER_STREAM_GROUP,
+      ...ids,
+    );
+  }
+}
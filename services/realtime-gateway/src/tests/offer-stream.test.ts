@@ -0,0 +1,71 @@
+/**
+ * Tests for the Driver Offer Stream
+ */
+
+import { describe, expect, it } from "vitest";
+import { parseOfferEntries, type StreamEntry } from "../lib/offer-stream.js";
+
+const NOW = 1_700_000_000_000;
+
+function entry(id: string, message: unknown, expiresAt: number): StreamEntry {
+  return [
+    id,
+    ["message", JSON.stringify(message), "expires_at", String(expiresAt)],
+  ];
+}
+
+const offer = {
+  type: "dispatch_request",
+  payload: { dispatch_id: "d-1", request_id: "r-1" },
+};
+
+describe("parseOfferEntries", () => {
+  it("adds the entry ID to unexpired offers", () => {
+    const batch = parseOfferEntries([entry("1-0", offer, NOW + 15000)], NOW);
+
+    expect(batch.discarded).toEqual([]);
+    expect(batch.deliverable).toHaveLength(1);
+    expect(batch.deliverable[0]!.message).toEqual({
+      type: "dispatch_request",
+      payload: { dispatch_id: "d-1", request_id: "r-1", offerId: "1-0" },
+    });
+  });
+
+  it("discards expired offers", () => {
+    const batch = parseOfferEntries(
+      [entry("1-0", offer, NOW - 1), entry("2-0", offer, NOW)],
+      NOW,
+    );
+
+    expect(batch.deliverable).toEqual([]);
+    expect(batch.discarded).toEqual(["1-0", "2-0"]);
+  });
+
+  it("discards trimmed and unreadable entries", () => {
+    const batch = parseOfferEntries(
+      [
+        ["1-0", null],
+        ["2-0", ["message", "{not json", "expires_at", String(NOW + 1000)]],
+        ["3-0", ["message", JSON.stringify(offer)]],
+      ],
+      NOW,
+    );
+
+    expect(batch.deliverable).toEqual([]);
+    expect(batch.discarded).toEqual(["1-0", "2-0", "3-0"]);
+  });
+
+  it("keeps stream order", () => {
+    const batch = parseOfferEntries(
+      [
+        entry("1-0", offer, NOW + 1000),
+        entry("2-0", offer, NOW - 1000),
+        entry("3-0", offer, NOW + 1000),
+      ],
+      NOW,
+    );
+
+    expect(batch.deliverable.map((o) => o.id)).toEqual(["1-0", "3-0"]);
+    expect(batch.discarded).toEqual(["2-0"]);
+  });
+});
@@ -28,6 +28,7 @@ export type WebSocketMessage =
   | { type: 'order_status'; payload: OrderStatusPayload }
   | { type: 'dispatch_request'; payload: DispatchRequestPayload }
   | { type: 'dispatch_response'; payload: DispatchResponsePayload }
+  | { type: 'offer_ack'; payload: OfferAckPayload }
   | { type: 'error'; payload: ErrorPayload };
 
 export interface LocationUpdate {
@@ -110,6 +111,7 @@ export interface DispatchRequestPayload {
   fareEstimate: number;
   currency: string;
   expiresIn: number; // seconds
+  offerId?: string; // acknowledge with offer_ack
 }
 
 export interface DispatchResponsePayload {
@@ -117,6 +119,11 @@ export interface DispatchResponsePayload {
   requestId: string;
   accepted: boolean;
   reason?: string;
+  offerId?: string; // also acknowledges the offer
+}
+
+export interface OfferAckPayload {
+  offerId: string;
 }
 
 export interface ErrorPayload {
@@ -154,6 +161,12 @@ export const REDIS_CHANNELS = {
   orderUpdates: (orderId: string) => `order:${orderId}:updates`,
   zoneEvents: (h3Index: string) => `zone:${h3Index}:events`,
   globalEvents: 'events:global',
+  driverOffers: (driverId: string) => `offers:${driverId}`,
+} as const;
+
+// Redis stream names
+export const REDIS_STREAMS = {
+  driverOffers: (driverId: string) => `driver:${driverId}:offers`,
 } as const;
 
 // Kafka topics
@@ -16,9 +16,12 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -27,18 +30,41 @@ import (
 	"github.com/go-chi/cors"
 	"github.com/go-chi/httprate"
 	goredis "github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/delivery"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/eta"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/guard"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/handler"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/ingest"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/locale"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/logging"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/matching"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/money"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/notification"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/payment"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/pricing"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/receipt"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/safety"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/service"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/verification"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/weather"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/webhook"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/worker"
 )
 
+// How long a worker leader's Redis lease lasts without renewal
+const workerLeaseTTL = 30 * time.Second
+
 // HTTP header and content type constants
 const (
 	headerContentType    = "Content-Type"
@@ -56,6 +82,45 @@ type Config struct {
 	DatabaseURL     string
 	RedisURL        string
 	GoogleMapsKey   string
+	PaymentURL      string
+	DeliveryURL     string
+	NotificationURL string
+	ServiceKey      string
+	CheckURL        string
+	CheckAPIKey     string
+	CheckSecret     string
+	CheckSecrets    string // per-provider webhook secrets as PROVIDER=SECRET entries; CheckSecret covers the rest
+	FaceMatchURL    string
+	FaceMatchKey    string
+	FaceMatchVendor string
+	CityConfigDir   string
+	W3WEnabled      bool     // feature flag for what3words pickup and dropoff addresses
+	W3WKey          string
+	W3WCountries    []string // ISO codes what3words is offered in; empty means everywhere
+	OSRMRegions     string   // per-country OSRM datasets as COUNTRY=URL pairs
+	ValhallaURL     string   // Valhalla instance for routing and isochrones
+	WeatherKey      string   // OpenWeather API key; weather adjustments are off without it
+	FXAppID         string   // Open Exchange Rates app ID; display conversions use reference rates without it
+	FXRatesURL      string   // Open Exchange Rates compatible API, for a self-hosted mirror
+	KafkaBrokers    []string // brokers for the driver location stream and safety alerts and ride receipts; history ingestion is off without them
+	LocationBatch   int      // location history points written per COPY
+	LocationFlush   time.Duration // longest a location history point waits to be written
+	VarianceAlert   float64 // median quoted-vs-final fare variance (%) that alerts
+	GlutFloor       float64 // lowest zone discount multiplier in supply gluts; 1 disables
+	DocumentGrace   string  // grace period overrides as COUNTRY:TYPE=DAYS entries
+	RideSweep       string  // stuck ride timeout overrides as STATUS=DURATION entries
+	TrainingPrereqs string  // training modules gating each product as PRODUCT=MODULE|MODULE entries
+	MatchingTTL     time.Duration // longest a ride searches for a driver before it expires
+	LogLevel        string  // default log level
+	LogLevels       string  // per-component log levels as COMPONENT=LEVEL entries
+	LogSampling     string  // per-component sampling as COMPONENT=N entries, keeping 1 in N debug and info events
+	BodyLimits      string  // request body limit overrides as GROUP=BYTES entries
+	LatencyBudgets  string  // latency budget overrides as GROUP=DURATION entries
+	SLOObjective    float64 // share of requests per endpoint group that must succeed within budget
+	WebhookSkew     time.Duration // how far a signed webhook's timestamp may be from our clock
+	ShareSecret     string        // signs trip share links; sharing is off without it
+	ShareTTL        time.Duration // how long a trip share link works
+	WorkerLock      string  // leader election for background jobs: redis, postgres or none
 	ShutdownTimeout time.Duration
 }
 
@@ -67,23 +132,161 @@ type App struct {
 	driverPool      *redis.DriverPool
 	rideRepo        *repository.RideRepository
 	driverRepo      *repository.DriverRepository
+	refundRepo      *repository.RefundRepository
+	disputeRepo     *repository.DisputeRepository
+	safetyRepo      *repository.SafetyIncidentRepository
+	insuranceRepo   *repository.InsuranceRepository
+	sanctionRepo    *repository.SanctionRepository
+	qualityRepo     *repository.DriverQualityRepository
+	reportRepo      *repository.RiderReportRepository
+	placeRepo       *repository.SavedPlaceRepository
+	prefRepo        *repository.DriverPreferenceRepository
+	consentRepo     *repository.ConsentRepository
+	receiptRepo     *repository.ReceiptRepository
+	lostItemRepo    *repository.LostItemRepository
+	messageRepo     *repository.RideMessageRepository
+	checkRepo       *repository.BackgroundCheckRepository
+	identityRepo    *repository.IdentityCheckRepository
+	documentRepo    *repository.DriverDocumentRepository
+	cityRepo        *repository.CityConfigRepository
+	controlsRepo    *repository.PriceControlRepository
+	promoRepo       *repository.PromoRepository
+	businessRepo    *repository.BusinessRepository
+	trainingRepo    *repository.TrainingRepository
+	loyaltyRepo     *repository.LoyaltyRepository
+	emissionsRepo   *repository.EmissionsRepository
+	cities          *cityconfig.Registry
 	pricingEngine   *pricing.Engine
 	rideService     *service.RideService
 	driverService   *service.DriverService
+	supportService  *service.SupportService
+	refundService   *service.RefundService
+	disputeService  *service.DisputeService
+	safetyService   *service.SafetyService
+	safetyAlerts    *safety.Publisher
+	receiptEvents   *receipt.Publisher
+	insurance       *service.InsuranceService
+	standingService *service.DriverStandingService
+	qualityService  *service.DriverQualityService
+	reliability     *service.RiderReliabilityService
+	prefService     *service.DriverPreferenceService
+	trainingService *service.TrainingService
+	loyaltyService  *service.LoyaltyService
+	emissions       *service.EmissionsService
+	consentService  *service.ConsentService
+	receiptService  *service.ReceiptService
+	lostItems       *service.LostItemService
+	rideChats       *service.RideChatService
+	checkService    *service.BackgroundCheckService
+	identityService *service.IdentityCheckService
+	documentService *service.DriverDocumentService
+	cityService     *service.CityConfigService
+	earningsService *service.EarningsService
+	controlsService *service.PriceControlService
+	varianceService *service.FareVarianceService
+	zoneKPIService  *service.ZoneKPIService
+	metricsService  *service.RideMetricsService
+	historyService  *service.LocationHistoryService
+	placeService    *service.SavedPlaceService
+	popularService  *service.PopularLocationService
+	promoService    *service.PromoService
+	business        *service.BusinessService
+	driverAdmin     *service.DriverAdminService
+	scheduleService *service.ScheduledRideService
+	dispatchService *service.ManualDispatchService
+	nudgeService    *service.RetentionService
+	etaService      *service.PickupETAService
+	returnService   *service.ReturnLegService
+	rideSweeper     *service.RideSweeper
+	expiryService   *service.RideExpiryService
 	rideHandler     *handler.RideHandler
 	locationHandler *handler.LocationHandler
+	supportHandler  *handler.SupportHandler
+	refundHandler   *handler.RefundHandler
+	disputeHandler  *handler.DisputeHandler
+	safetyHandler   *handler.SafetyHandler
+	insureHandler   *handler.InsuranceHandler
+	standingHandler *handler.DriverStandingHandler
+	qualityHandler  *handler.DriverQualityHandler
+	reportHandler   *handler.RiderReliabilityHandler
+	prefHandler     *handler.DriverPreferenceHandler
+	consentHandler  *handler.ConsentHandler
+	receiptHandler  *handler.ReceiptHandler
+	lostHandler     *handler.LostItemHandler
+	chatHandler     *handler.RideChatHandler
+	checkHandler    *handler.BackgroundCheckHandler
+	identityHandler *handler.IdentityCheckHandler
+	documentHandler *handler.DriverDocumentHandler
+	cityHandler     *handler.CityConfigHandler
+	earningsHandler *handler.EarningsHandler
+	fareHandler     *handler.FareHandler
+	varianceHandler *handler.FareVarianceHandler
+	zoneKPIHandler  *handler.ZoneKPIHandler
+	metricsHandler  *handler.RideMetricsHandler
+	predictHandler  *handler.PredictionHandler
+	placeHandler    *handler.SavedPlaceHandler
+	controlsHandler *handler.PriceControlHandler
+	promoHandler    *handler.PromoHandler
+	businessHandler *handler.BusinessHandler
+	adminDrvHandler *handler.DriverAdminHandler
+	claimHandler    *handler.ScheduledRideHandler
+	dispatchHandler *handler.ManualDispatchHandler
+	trainingHandler *handler.TrainingHandler
+	loyaltyHandler  *handler.LoyaltyHandler
+	emissionHandler *handler.EmissionsHandler
+	stopHandler     *handler.RideStopHandler
+	shareHandler    *handler.TripShareHandler
+	nudgeHandler    *handler.RetentionHandler
+	etaHandler      *handler.PickupETAHandler
+	returnHandler   *handler.ReturnLegHandler
 	mapsClient      *geo.MapsClient
+	travelMatrix    *eta.TravelMatrix
+	matcher         *matching.Engine
+	matrixHandler   *handler.TravelMatrixHandler
+	router          *eta.FallbackRoutingClient
+	routingHandler  *handler.RoutingHealthHandler
+	isoHandler      *handler.IsochroneHandler
+	liveETAHandler  *handler.LiveETAHandler
+	routeHandler    *handler.RouteOptionHandler
+	traffic         *eta.H3TrafficService
+	incidentService *service.IncidentService
+	incidentHandler *handler.IncidentHandler
+	roadReports     *service.RoadReportService
+	roadHandler     *handler.RoadReportHandler
+	weatherService  *service.WeatherService
+	fxRates         *money.RateCache
+	killSwitches    *service.KillSwitchService
+	killHandler     *handler.KillSwitchHandler
+	logSettings     *service.LogSettingsService
+	logHandler      *handler.LogSettingsHandler
+	locationFlusher *service.LocationFlushService
+	locationIngest  *ingest.LocationConsumer
+	workers         *worker.Scheduler
+	workersDone     chan struct{}
+	workerHandler   *handler.WorkerHandler
+	guard           *guard.Guard
+	sloHandler      *handler.SLOHandler
+	webhooks        *webhook.Verifier
+	webhookHandler  *handler.WebhookHandler
 }
 
 func main() {
+	// Load configuration
+	config := loadConfig()
+
 	// Initialize logger
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+	var logOutput io.Writer = os.Stderr
 	if os.Getenv("NODE_ENV") == "development" {
-		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+		logOutput = zerolog.ConsoleWriter{Out: os.Stderr}
+	}
+	logDefaults, err := logSettings(config)
+	if err == nil {
+		err = logging.Setup(logOutput, logDefaults)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid log settings")
 	}
-
-	// Load configuration
-	config := loadConfig()
 
 	// Initialize application
 	app, err := initializeApp(config)
@@ -92,13 +295,17 @@ func main() {
 	}
 	defer app.cleanup()
 
+	// Start background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	app.startBackgroundJobs(jobsCtx)
+
 	// Create router
 	r := chi.NewRouter()
 
 	// Global middleware
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(30 * time.Second))
 	r.Use(middleware.Compress(5))
@@ -107,7 +314,7 @@ func main() {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://app.ubi.africa", "https://admin.ubi.africa", "http://localhost:*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{headerAccept, headerAuthorization, headerContentType, headerRequestID, headerUserID},
+		AllowedHeaders:   []string{headerAccept, headerAuthorization, headerContentType, headerRequestID, headerUserID, locale.HeaderUserLocation},
 		ExposedHeaders:   []string{headerRequestID},
 		AllowCredentials: true,
 		MaxAge:           300,
@@ -118,46 +325,486 @@ func main() {
 	
 	// Service auth middleware - extracts user from gateway headers
 	r.Use(serviceAuthMiddleware)
+	
+	// Resolve the request's country, city and language
+	r.Use(locale.NewResolver(app.cities).Middleware)
+
+	// Log requests once the locale is known, so request logs carry the city.
+	// Location pings are sampled under their own component.
+	r.Use(logging.RequestLogger(map[string]string{
+		"/drivers/location": "location",
+	}))
+
+	// Refuse oversized bodies and time requests against their latency budgets
+	r.Use(app.guard.Middleware)
 
 	// Health check routes
 	r.Get("/health/live", app.healthLive)
 	r.Get("/health/ready", app.healthReady)
 	r.Get("/health", app.healthDetailed)
 
+	// Public view of a shared trip; the signed token is the permission
+	if app.shareHandler != nil {
+		r.Get("/track/{token}", app.shareHandler.TrackSharedTrip)
+	}
+
 	// API routes - Rider endpoints
 	r.Route("/rides", func(r chi.Router) {
 		r.Post("/", app.rideHandler.RequestRide)
+		r.Get("/history", app.rideHandler.GetRideHistory)
+		r.Get("/history/export", app.rideHandler.ExportRideHistory)
+		
+		// Route options at confirmation (requires Google Maps and Redis)
+		if app.routeHandler != nil {
+			r.Post("/route-options", app.routeHandler.GetRouteOptions)
+		}
+		
 		r.Get("/{rideId}", app.rideHandler.GetRide)
 		r.Post("/{rideId}/cancel", app.rideHandler.CancelRide)
 		r.Get("/{rideId}/track", app.rideHandler.TrackRide)
 		r.Post("/{rideId}/rate", app.rideHandler.RateRide)
+		r.Get("/{rideId}/events", app.rideHandler.GetRideEvents)
+		
+		// Trip sharing (requires TRIP_SHARE_SECRET)
+		if app.shareHandler != nil {
+			r.Post("/{rideId}/share", app.shareHandler.ShareRide)
+		}
+		
+		// Ride receipts (requires database)
+		if app.receiptHandler != nil {
+			r.Get("/{rideId}/receipt", app.receiptHandler.GetReceipt)
+		}
+		
+		// Lost items (requires database)
+		if app.lostHandler != nil {
+			r.Post("/{rideId}/lost-item", app.lostHandler.ReportLostItem)
+			r.Get("/{rideId}/lost-item", app.lostHandler.GetRideLostItem)
+		}
+		
+		// Rider and driver chat (requires database and Redis)
+		if app.chatHandler != nil {
+			r.Post("/{rideId}/messages", app.chatHandler.SendMessage)
+			r.Get("/{rideId}/messages", app.chatHandler.GetMessages)
+			r.Get("/{rideId}/messages/stream", app.chatHandler.StreamMessages)
+		}
+		
+		// Fare explanation (requires database)
+		if app.fareHandler != nil {
+			r.Get("/{rideId}/fare/explain", app.fareHandler.ExplainFare)
+		}
+		
+		// Fare disputes (requires database)
+		if app.disputeHandler != nil {
+			r.Post("/{rideId}/dispute", app.disputeHandler.OpenDispute)
+			r.Get("/{rideId}/dispute", app.disputeHandler.GetDispute)
+		}
+		
+		// In-ride SOS (requires database)
+		if app.safetyHandler != nil {
+			r.Post("/{rideId}/sos", app.safetyHandler.RaiseSOS)
+		}
+		
+		// Ride insurance and claims (requires database)
+		if app.insureHandler != nil {
+			r.Get("/{rideId}/insurance", app.insureHandler.GetPolicy)
+			r.Post("/{rideId}/insurance/claims", app.insureHandler.FileClaim)
+			r.Get("/{rideId}/insurance/claims", app.insureHandler.ListRideClaims)
+		}
+		
+		// Drivers' reports about riders (requires database)
+		if app.reportHandler != nil {
+			r.Post("/{rideId}/rider-reports", app.reportHandler.ReportRider)
+		}
+		
+		// Favorite and blocked drivers (requires database)
+		if app.prefHandler != nil {
+			r.Post("/{rideId}/favorite-driver", app.prefHandler.FavoriteDriver)
+			r.Post("/{rideId}/block-driver", app.prefHandler.BlockDriver)
+		}
 	})
 
+	// Rider retention nudge opt-out (requires database)
+	if app.nudgeHandler != nil {
+		r.Get("/riders/me/nudge-preferences", app.nudgeHandler.GetMyNudgePreferences)
+		r.Put("/riders/me/nudge-preferences", app.nudgeHandler.UpdateMyNudgePreferences)
+	}
+
+	// Rider loyalty points balance (requires database)
+	if app.loyaltyHandler != nil {
+		r.Get("/riders/me/loyalty", app.loyaltyHandler.GetMyLoyalty)
+	}
+
+	// Rider and business account ride emissions (requires database)
+	if app.emissionHandler != nil {
+		r.Get("/riders/me/emissions", app.emissionHandler.GetMyEmissions)
+		r.Get("/business-accounts/{accountId}/emissions", app.emissionHandler.GetBusinessEmissions)
+	}
+
+	// Rider saved places, which also seed home and work for predictions
+	// (requires database)
+	if app.placeHandler != nil {
+		r.Route("/riders/places", func(r chi.Router) {
+			r.Get("/", app.placeHandler.ListPlaces)
+			r.Post("/", app.placeHandler.CreatePlace)
+			r.Put("/{placeId}", app.placeHandler.UpdatePlace)
+			r.Delete("/{placeId}", app.placeHandler.DeletePlace)
+		})
+	}
+
+	// Rider reliability score and its factors (requires database)
+	if app.reportHandler != nil {
+		r.Get("/riders/me/reliability", app.reportHandler.GetMyReliability)
+	}
+	if app.prefHandler != nil {
+		r.Get("/riders/me/drivers", app.prefHandler.ListMyDrivers)
+		r.Delete("/riders/me/drivers/{driverId}", app.prefHandler.RemoveMyDriver)
+	}
+
 	// Driver endpoints
 	r.Route("/drivers", func(r chi.Router) {
 		r.Put("/location", app.rideHandler.UpdateDriverLocation)
+		r.Put("/status", app.rideHandler.UpdateDriverStatus)
 		r.Get("/nearby", app.rideHandler.GetNearbyDrivers)
+		
+		// Driver standing and appeals (requires database)
+		if app.standingHandler != nil {
+			r.Get("/me/standing", app.standingHandler.GetMyStanding)
+			r.Post("/me/appeals", app.standingHandler.SubmitAppeal)
+		}
+		
+		// Driver quality tiers and tips (requires database)
+		if app.qualityHandler != nil {
+			r.Get("/me/quality", app.qualityHandler.GetMyQuality)
+			r.Get("/quality-tiers", app.qualityHandler.ListTiers)
+		}
+		
+		// Combined ride and delivery earnings (requires database)
+		if app.earningsHandler != nil {
+			r.Get("/me/earnings", app.earningsHandler.GetMyEarnings)
+		}
+		
+		// Shift-start selfie checks (requires face-match provider)
+		if app.identityHandler != nil {
+			r.Post("/me/identity-checks", app.identityHandler.SubmitSelfie)
+		}
+		
+		// Return legs toward demand after a dropoff (requires database)
+		if app.returnHandler != nil {
+			r.Get("/me/return-legs", app.returnHandler.GetMyReturnLegs)
+		}
+		
+		// Training progress for gated products (requires database)
+		if app.trainingHandler != nil {
+			r.Get("/me/training", app.trainingHandler.GetMyTraining)
+		}
 	})
 	
 	// Driver ride management
 	r.Route("/driver/rides", func(r chi.Router) {
 		r.Post("/{rideId}/accept", app.rideHandler.AcceptRide)
 		r.Post("/{rideId}/decline", app.rideHandler.DeclineRide)
+		r.Post("/{rideId}/stops/{index}/arrive", app.stopHandler.ArriveAtStop)
+		r.Post("/{rideId}/stops/{index}/depart", app.stopHandler.DepartStop)
 	})
+	
+	// Crowdsourced road issue reports (requires database and Redis)
+	if app.roadHandler != nil {
+		r.Route("/driver/road-reports", func(r chi.Router) {
+			r.Get("/", app.roadHandler.ListMyRoadReports)
+			r.Post("/", app.roadHandler.SubmitRoadReport)
+			r.Post("/{reportId}/confirm", app.roadHandler.ConfirmRoadReport)
+		})
+	}
+	
+	// Scheduled ride marketplace (requires database)
+	if app.claimHandler != nil {
+		r.Route("/driver/scheduled-rides", func(r chi.Router) {
+			r.Get("/", app.claimHandler.ListScheduledRides)
+			r.Post("/{rideId}/claim", app.claimHandler.ClaimScheduledRide)
+			r.Post("/{rideId}/release", app.claimHandler.ReleaseScheduledRide)
+		})
+	}
 
 	// Pricing endpoints
 	r.Route("/pricing", func(r chi.Router) {
 		r.Post("/estimate", app.rideHandler.GetPriceEstimate)
 		r.Get("/surge", app.rideHandler.GetSurgeMultiplier)
+		
+		// Pickup ETA previews (requires database and Redis)
+		if app.etaHandler != nil {
+			r.Get("/eta-preview", app.etaHandler.GetETAPreview)
+		}
 	})
 
+	// Predictions learned from past trips (requires database)
+	if app.predictHandler != nil {
+		r.Get("/predictions/pickup", app.predictHandler.SuggestPickupSpots)
+		r.Get("/predictions/popular", app.predictHandler.GetPopularLocations)
+		
+		r.Route("/users/me/prediction-data", func(r chi.Router) {
+			r.Get("/", app.predictHandler.ExportMyPredictionData)
+			r.Delete("/", app.predictHandler.DeleteMyPredictionData)
+			r.Get("/preferences", app.predictHandler.GetMyPredictionPreferences)
+			r.Put("/preferences", app.predictHandler.UpdateMyPredictionPreferences)
+		})
+	}
+	
+	// What users' location and contact data may be used for (requires database)
+	if app.consentHandler != nil {
+		r.Get("/users/me/consents", app.consentHandler.GetMyConsents)
+		r.Put("/users/me/consents", app.consentHandler.UpdateMyConsents)
+	}
+	
+	// Business profiles riders can ride on, and their accounts' monthly
+	// statements (requires database)
+	if app.businessHandler != nil {
+		r.Get("/users/me/business-profiles", app.businessHandler.ListMyProfiles)
+		r.Get("/business-accounts/{accountId}/statement", app.businessHandler.GetStatement)
+	}
+	
+	// Lost item cases for riders, drivers and support (requires database)
+	if app.lostHandler != nil {
+		r.Route("/lost-items", func(r chi.Router) {
+			r.Get("/", app.lostHandler.ListLostItems)
+			r.Get("/{caseId}", app.lostHandler.GetLostItem)
+			r.Post("/{caseId}/status", app.lostHandler.UpdateLostItem)
+		})
+	}
+
 	r.Route("/locations", func(r chi.Router) {
 		r.Get("/autocomplete", app.locationHandler.AutocompleteLocation)
 		r.Get("/geocode", app.locationHandler.GeocodeAddress)
+		r.Post("/geocode/batch", app.locationHandler.BatchGeocodeAddresses)
 		r.Get("/reverse", app.locationHandler.ReverseGeocode)
 		r.Get("/place", app.locationHandler.GetPlaceDetails)
 	})
 
+	// Internal support tooling endpoints
+	r.Route("/internal/support", func(r chi.Router) {
+		r.Get("/rides/{rideId}/context", app.supportHandler.GetRideCaseContext)
+		
+		// Refunds and goodwill credits (requires database)
+		if app.refundHandler != nil {
+			r.Post("/rides/{rideId}/refunds", app.refundHandler.CreateRefund)
+			r.Get("/rides/{rideId}/refunds", app.refundHandler.ListRefunds)
+			r.Post("/refunds/{refundId}/approve", app.refundHandler.ApproveRefund)
+			r.Post("/refunds/{refundId}/reject", app.refundHandler.RejectRefund)
+		}
+		
+		// Fare dispute queue (requires database)
+		if app.disputeHandler != nil {
+			r.Get("/disputes", app.disputeHandler.ListDisputeQueue)
+			r.Post("/disputes/{disputeId}/resolve", app.disputeHandler.ResolveDispute)
+		}
+		
+		// SOS incident queue (requires database)
+		if app.safetyHandler != nil {
+			r.Get("/safety-incidents", app.safetyHandler.ListIncidents)
+			r.Get("/safety-incidents/{incidentId}", app.safetyHandler.GetIncident)
+			r.Post("/safety-incidents/{incidentId}/acknowledge", app.safetyHandler.AcknowledgeIncident)
+			r.Post("/safety-incidents/{incidentId}/resolve", app.safetyHandler.ResolveIncident)
+		}
+		
+		// Insurance claims queue (requires database)
+		if app.insureHandler != nil {
+			r.Get("/insurance-claims", app.insureHandler.ListClaims)
+			r.Get("/insurance-claims/{claimId}", app.insureHandler.GetClaim)
+			r.Post("/insurance-claims/{claimId}/status", app.insureHandler.UpdateClaim)
+			r.Get("/safety-incidents/{incidentId}/insurance-claims", app.insureHandler.ListIncidentClaims)
+		}
+		
+		// Driver sanctions and appeals (requires database)
+		if app.standingHandler != nil {
+			r.Get("/drivers/{driverId}/sanctions", app.standingHandler.ListDriverSanctions)
+			r.Post("/drivers/{driverId}/sanctions", app.standingHandler.SanctionDriver)
+			r.Post("/drivers/{driverId}/reinstate", app.standingHandler.ReinstateDriver)
+			r.Get("/appeals", app.standingHandler.ListAppeals)
+			r.Post("/appeals/{appealId}/review", app.standingHandler.ReviewAppeal)
+		}
+		if app.qualityHandler != nil {
+			r.Get("/drivers/{driverId}/quality", app.qualityHandler.GetDriverQuality)
+		}
+		
+		// Rider reliability and drivers' reports (requires database)
+		if app.reportHandler != nil {
+			r.Get("/riders/{riderId}/reliability", app.reportHandler.GetRiderReliability)
+			r.Get("/riders/{riderId}/rider-reports", app.reportHandler.ListRiderReports)
+			r.Post("/rider-reports/{reportId}/void", app.reportHandler.VoidReport)
+		}
+		
+		// Driver background checks (requires database)
+		if app.checkHandler != nil {
+			r.Get("/drivers/{driverId}/background-checks", app.checkHandler.ListDriverChecks)
+		}
+		
+		// Driver selfie checks and lockout review (requires face-match provider)
+		if app.identityHandler != nil {
+			r.Get("/drivers/{driverId}/identity-checks", app.identityHandler.ListDriverChecks)
+			r.Post("/drivers/{driverId}/identity-override", app.identityHandler.OverrideLockout)
+		}
+		
+		// Driver licences and permits (requires database)
+		if app.documentHandler != nil {
+			r.Get("/drivers/{driverId}/documents", app.documentHandler.ListDriverDocuments)
+			r.Put("/drivers/{driverId}/documents/{type}", app.documentHandler.RecordDocument)
+		}
+	})
+
+	// City launch configuration (requires database)
+	if app.cityHandler != nil {
+		r.Route("/internal/admin/cities", func(r chi.Router) {
+			r.Get("/", app.cityHandler.ListCities)
+			r.Get("/{code}", app.cityHandler.GetCity)
+			r.Put("/{code}", app.cityHandler.SaveCity)
+		})
+	}
+
+	// Price control compliance report (requires database)
+	if app.controlsHandler != nil {
+		r.Get("/internal/admin/compliance/price-controls", app.controlsHandler.GetComplianceReport)
+	}
+	
+	// Driver documents expiring by market (requires database)
+	if app.documentHandler != nil {
+		r.Get("/internal/admin/compliance/expiring-documents", app.documentHandler.GetExpiringReport)
+	}
+
+	// Promo campaign management (requires database)
+	if app.promoHandler != nil {
+		r.Route("/internal/admin/promo-campaigns", func(r chi.Router) {
+			r.Get("/", app.promoHandler.ListCampaigns)
+			r.Post("/", app.promoHandler.CreateCampaign)
+			r.Get("/{campaignId}", app.promoHandler.GetCampaign)
+			r.Put("/{campaignId}", app.promoHandler.UpdateCampaign)
+			r.Post("/{campaignId}/codes", app.promoHandler.AddCodes)
+			r.Delete("/{campaignId}/codes/{code}", app.promoHandler.RemoveCode)
+			r.Get("/{campaignId}/stats", app.promoHandler.GetCampaignStats)
+			r.Post("/{campaignId}/pause", app.promoHandler.PauseCampaign)
+			r.Post("/{campaignId}/resume", app.promoHandler.ResumeCampaign)
+		})
+	}
+	
+	// Business account management (requires database)
+	if app.businessHandler != nil {
+		r.Route("/internal/admin/business-accounts", func(r chi.Router) {
+			r.Get("/", app.businessHandler.ListAccounts)
+			r.Post("/", app.businessHandler.CreateAccount)
+			r.Get("/{accountId}", app.businessHandler.GetAccount)
+			r.Put("/{accountId}", app.businessHandler.UpdateAccount)
+			r.Get("/{accountId}/members", app.businessHandler.ListMembers)
+			r.Put("/{accountId}/members/{riderId}", app.businessHandler.SaveMember)
+			r.Delete("/{accountId}/members/{riderId}", app.businessHandler.RemoveMember)
+		})
+	}
+	
+	// Driver search, profiles and record changes (requires database)
+	if app.adminDrvHandler != nil {
+		r.Route("/internal/admin/drivers", func(r chi.Router) {
+			r.Get("/", app.adminDrvHandler.SearchDrivers)
+			r.Get("/{driverId}/profile", app.adminDrvHandler.GetProfile)
+			r.Patch("/{driverId}/vehicle", app.adminDrvHandler.UpdateVehicle)
+			r.Post("/{driverId}/reset-status", app.adminDrvHandler.ResetStatus)
+			r.Post("/{driverId}/force-offline", app.adminDrvHandler.ForceOffline)
+		})
+	}
+	
+	// Manual dispatch override for ops (requires database)
+	if app.dispatchHandler != nil {
+		r.Post("/internal/admin/rides/{rideId}/dispatch", app.dispatchHandler.DispatchRide)
+	}
+	
+	// Quoted vs final fare variance report (requires database)
+	if app.varianceHandler != nil {
+		r.Get("/internal/admin/fare-variance", app.varianceHandler.GetReport)
+	}
+
+	// Zone operational KPIs for the ops dashboard (requires database)
+	if app.zoneKPIHandler != nil {
+		r.Get("/internal/admin/zones/kpis", app.zoneKPIHandler.GetReport)
+		r.Get("/internal/admin/zones/{zone}/kpis", app.zoneKPIHandler.GetZoneSeries)
+	}
+
+	// Hourly per-market ride metrics (requires database)
+	if app.metricsHandler != nil {
+		r.Get("/internal/admin/metrics", app.metricsHandler.GetReport)
+	}
+
+	// Road closures, floods and protests reported by ops (requires database and Redis)
+	if app.incidentHandler != nil {
+		r.Route("/internal/admin/incidents", func(r chi.Router) {
+			r.Get("/", app.incidentHandler.ListIncidents)
+			r.Post("/", app.incidentHandler.ReportIncident)
+			r.Delete("/{incidentId}", app.incidentHandler.ResolveIncident)
+		})
+	}
+
+	// Switch rides, deliveries or surge off city by city (requires Redis)
+	if app.killHandler != nil {
+		r.Route("/internal/admin/kill-switches", func(r chi.Router) {
+			r.Get("/", app.killHandler.ListKillSwitches)
+			r.Get("/audit", app.killHandler.GetKillSwitchAudit)
+			r.Put("/{city}/{feature}", app.killHandler.DisableFeature)
+			r.Delete("/{city}/{feature}", app.killHandler.EnableFeature)
+		})
+	}
+	
+	// Runtime log levels and sampling rates (requires Redis)
+	if app.logHandler != nil {
+		r.Route("/internal/admin/logging", func(r chi.Router) {
+			r.Get("/", app.logHandler.GetLogSettings)
+			r.Put("/", app.logHandler.UpdateLogSettings)
+			r.Delete("/", app.logHandler.ResetLogSettings)
+		})
+	}
+	
+	// Background job leadership and run stats for this replica
+	r.Get("/internal/admin/workers", app.workerHandler.GetStatus)
+	
+	// Routing provider failover counts and OSRM dataset health
+	r.Get("/internal/admin/routing/health", app.routingHandler.GetHealth)
+
+	// SLO burn rates per endpoint group for this replica
+	r.Get("/internal/admin/slo", app.sloHandler.GetBurnRates)
+	
+	// Webhook deliveries refused by signature or replay checks on this replica
+	r.Get("/internal/admin/webhooks/failures", app.webhookHandler.GetFailures)
+
+	// Retention nudge measurement against holdout (requires database)
+	if app.nudgeHandler != nil {
+		r.Get("/internal/admin/retention-nudges/stats", app.nudgeHandler.GetReport)
+	}
+
+	// Background-check provider webhooks (requires database)
+	if app.checkHandler != nil {
+		r.Post("/webhooks/background-checks/{provider}", app.checkHandler.ReceiveWebhook)
+	}
+
+	// Learning provider webhooks and training gates for other services
+	// (requires database)
+	if app.trainingHandler != nil {
+		r.Post("/webhooks/training/{provider}", app.trainingHandler.ReceiveWebhook)
+		r.Get("/internal/drivers/{driverId}/training/{product}", app.trainingHandler.GetDriverTraining)
+	}
+
+	// Travel time matrix endpoints (requires Redis)
+	if app.matrixHandler != nil {
+		r.Route("/eta/matrix", func(r chi.Router) {
+			r.Get("/", app.matrixHandler.GetTravelTime)
+			r.Get("/cities/{city}", app.matrixHandler.GetCityStats)
+		})
+	}
+
+	// Live traffic-adjusted ETAs for vehicles on the move (requires Redis
+	// and a routing provider)
+	if app.liveETAHandler != nil {
+		r.Get("/eta/live", app.liveETAHandler.GetLiveETA)
+	}
+
+	// Road-network reachability polygons (requires Valhalla)
+	if app.isoHandler != nil {
+		r.Get("/eta/isochrone", app.isoHandler.GetIsochrones)
+	}
+
 	// Create server
 	server := &http.Server{
 		Addr:         ":" + config.Port,
@@ -192,6 +839,14 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatal().Err(err).Msg("Server forced to shutdown")
 	}
+	
+	// Let runs in progress finish and hand leadership to another replica
+	stopJobs()
+	select {
+	case <-app.workersDone:
+	case <-ctx.Done():
+		log.Warn().Msg("Background jobs still running at shutdown")
+	}
 
 	log.Info().Msg("Server exited properly")
 }
@@ -200,6 +855,17 @@ func main() {
 func initializeApp(config *Config) (*App, error) {
 	app := &App{config: config}
 	
+	// Request guard: body limits, latency budgets and SLO burn rates.
+	// Database, Redis and outbound HTTP calls are timed as dependency spans
+	// so slow requests name what held them up.
+	routes, err := guard.ParseRoutes(config.BodyLimits, config.LatencyBudgets)
+	if err != nil {
+		return nil, err
+	}
+	app.guard = guard.New(routes, config.SLOObjective)
+	app.sloHandler = handler.NewSLOHandler(app.guard)
+	http.DefaultTransport = guard.Transport(http.DefaultTransport)
+	
 	// Initialize database connection
 	if config.DatabaseURL != "" {
 		poolConfig, err := pgxpool.ParseConfig(config.DatabaseURL)
@@ -211,6 +877,15 @@ func initializeApp(config *Config) (*App, error) {
 		poolConfig.MinConns = 5
 		poolConfig.MaxConnLifetime = 30 * time.Minute
 		poolConfig.MaxConnIdleTime = 5 * time.Minute
+		poolConfig.ConnConfig.Tracer = guard.QueryTracer{}
+		
+		// Hot queries are prepared once per connection. Prepared statements
+		// live in the server session, so they are left off when the URL sets
+		// default_query_exec_mode for a transaction pooler such as PgBouncer;
+		// statement_cache_capacity in the URL sizes the cache for the rest.
+		if poolConfig.ConnConfig.DefaultQueryExecMode == pgx.QueryExecModeCacheStatement {
+			poolConfig.AfterConnect = repository.PrepareHotStatements
+		}
 		
 		pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 		if err != nil {
@@ -225,6 +900,29 @@ func initializeApp(config *Config) (*App, error) {
 		app.db = pool
 		app.rideRepo = repository.NewRideRepository(pool)
 		app.driverRepo = repository.NewDriverRepository(pool)
+		app.refundRepo = repository.NewRefundRepository(pool)
+		app.disputeRepo = repository.NewDisputeRepository(pool)
+		app.safetyRepo = repository.NewSafetyIncidentRepository(pool)
+		app.insuranceRepo = repository.NewInsuranceRepository(pool)
+		app.sanctionRepo = repository.NewSanctionRepository(pool)
+		app.qualityRepo = repository.NewDriverQualityRepository(pool)
+		app.reportRepo = repository.NewRiderReportRepository(pool)
+		app.placeRepo = repository.NewSavedPlaceRepository(pool)
+		app.prefRepo = repository.NewDriverPreferenceRepository(pool)
+		app.consentRepo = repository.NewConsentRepository(pool)
+		app.receiptRepo = repository.NewReceiptRepository(pool)
+		app.lostItemRepo = repository.NewLostItemRepository(pool)
+		app.messageRepo = repository.NewRideMessageRepository(pool)
+		app.checkRepo = repository.NewBackgroundCheckRepository(pool)
+		app.identityRepo = repository.NewIdentityCheckRepository(pool)
+		app.documentRepo = repository.NewDriverDocumentRepository(pool)
+		app.cityRepo = repository.NewCityConfigRepository(pool)
+		app.controlsRepo = repository.NewPriceControlRepository(pool)
+		app.promoRepo = repository.NewPromoRepository(pool)
+		app.businessRepo = repository.NewBusinessRepository(pool)
+		app.trainingRepo = repository.NewTrainingRepository(pool)
+		app.loyaltyRepo = repository.NewLoyaltyRepository(pool)
+		app.emissionsRepo = repository.NewEmissionsRepository(pool)
 		
 		log.Info().Msg("Database connection established")
 	}
@@ -237,6 +935,7 @@ func initializeApp(config *Config) (*App, error) {
 		}
 		
 		client := goredis.NewClient(opts)
+		client.AddHook(guard.RedisHook{})
 		
 		// Test connection
 		if err := client.Ping(context.Background()).Err(); err != nil {
@@ -245,16 +944,423 @@ func initializeApp(config *Config) (*App, error) {
 		
 		app.redisClient = client
 		app.driverPool = redis.NewDriverPool(client)
+		app.travelMatrix = eta.NewTravelMatrix(client, serviceAreaCity)
+		app.matrixHandler = handler.NewTravelMatrixHandler(app.travelMatrix)
 		
 		log.Info().Msg("Redis connection established")
 	}
 	
-	// Initialize pricing engine
+	// Inbound webhooks are signed per source; nonces are shared through
+	// Redis so a delivery cannot be replayed against another replica
+	checkSecrets, err := webhook.ParseSecrets(config.CheckSecrets)
+	if err != nil {
+		return nil, err
+	}
+	var nonces webhook.NonceStore = webhook.NewMemoryNonces()
+	if app.redisClient != nil {
+		nonces = webhook.RedisNonces{Client: app.redisClient}
+	}
+	app.webhooks = webhook.NewVerifier(checkSecrets, config.CheckSecret, config.WebhookSkew, nonces)
+	app.webhookHandler = handler.NewWebhookHandler(app.webhooks)
+	
+	// Initialize pricing engine, reporting fares clamped to price controls
 	app.pricingEngine = pricing.NewEngine()
+	glutConfig := pricing.DefaultGlutDiscountConfig()
+	glutConfig.FloorMultiplier = config.GlutFloor
+	app.pricingEngine.SetGlutDiscountConfig(glutConfig)
+	if app.controlsRepo != nil {
+		app.controlsService = service.NewPriceControlService(app.controlsRepo)
+		app.controlsHandler = handler.NewPriceControlHandler(app.controlsService)
+		app.pricingEngine.SetViolationRecorder(app.controlsService)
+	}
+	
+	// Load city launch bundles
+	app.cities = cityconfig.NewRegistry()
+	app.cityService = service.NewCityConfigService(app.cityRepo, app.cities, app.pricingEngine)
+	if err := app.cityService.Load(context.Background(), config.CityConfigDir); err != nil {
+		return nil, fmt.Errorf("failed to load city configs: %w", err)
+	}
+	if app.cityRepo != nil {
+		app.cityHandler = handler.NewCityConfigHandler(app.cityService)
+	}
 	
 	// Initialize services
-	app.rideService = service.NewRideService(app.rideRepo, app.driverPool, app.pricingEngine)
-	app.driverService = service.NewDriverService(app.driverRepo, app.driverPool)
+	if app.promoRepo != nil {
+		app.promoService = service.NewPromoService(app.promoRepo)
+		app.promoHandler = handler.NewPromoHandler(app.promoService)
+	}
+	app.rideService = service.NewRideService(app.rideRepo, app.driverPool, app.pricingEngine, app.cities, app.promoService)
+	app.stopHandler = handler.NewRideStopHandler(app.rideService)
+	
+	// Business profiles, with rides checked against the account's policy
+	if app.businessRepo != nil {
+		app.business = service.NewBusinessService(app.businessRepo)
+		app.rideService.SetBusiness(app.business)
+		app.businessHandler = handler.NewBusinessHandler(app.business)
+	}
+	
+	// Riders earn loyalty points on completed rides and redeem them on fares
+	if app.loyaltyRepo != nil {
+		app.loyaltyService = service.NewLoyaltyService(app.loyaltyRepo)
+		app.rideService.SetLoyalty(app.loyaltyService)
+		app.loyaltyHandler = handler.NewLoyaltyHandler(app.loyaltyService)
+	}
+	
+	// Completed rides carry their estimated CO2, summed for riders and
+	// business accounts
+	if app.emissionsRepo != nil {
+		app.emissions = service.NewEmissionsService(app.emissionsRepo, app.driverRepo, app.businessRepo)
+		app.rideService.SetEmissions(app.emissions)
+		app.emissionHandler = handler.NewEmissionsHandler(app.emissions)
+	}
+	
+	// Riders can send family a link to follow the trip
+	if config.ShareSecret != "" {
+		app.shareHandler = handler.NewTripShareHandler(service.NewTripShareService(
+			app.rideService, app.driverRepo, app.driverPool, config.ShareSecret, config.ShareTTL,
+		))
+	}
+	
+	// Fare distances follow the ride type's roads when a routing provider
+	// (Google, Mapbox or OSRM) is configured
+	app.router = eta.NewFallbackRoutingClient()
+	if osrm := app.router.OSRM(); osrm != nil && config.OSRMRegions != "" {
+		regions, err := osrmRegions(config.OSRMRegions, app.cities)
+		if err != nil {
+			return nil, err
+		}
+		osrm.SetRegions(regions, func(lat, lng float64) (string, bool) {
+			city, ok := app.cities.FindByLocation(lat, lng)
+			if !ok {
+				return "", false
+			}
+			return city.Country, true
+		})
+	}
+	if app.router.HasProviders() {
+		app.rideService.SetRouter(app.router)
+	}
+	app.routingHandler = handler.NewRoutingHealthHandler(app.router)
+	if app.redisClient != nil && app.router.HasProviders() {
+		app.liveETAHandler = handler.NewLiveETAHandler(eta.NewETAService(app.router, app.redisClient))
+	}
+	var valhalla *eta.ValhallaClient
+	if config.ValhallaURL != "" {
+		valhalla = eta.NewValhallaClient(config.ValhallaURL)
+		app.isoHandler = handler.NewIsochroneHandler(valhalla)
+	}
+	app.supportService = service.NewSupportService(app.rideService, app.rideRepo, app.driverRepo)
+	if app.refundRepo != nil {
+		paymentClient := payment.NewClient(payment.ClientConfig{
+			BaseURL:    config.PaymentURL,
+			ServiceKey: config.ServiceKey,
+		})
+		app.refundService = service.NewRefundService(app.rideService, app.refundRepo, paymentClient)
+		app.refundHandler = handler.NewRefundHandler(app.refundService)
+		
+		app.disputeService = service.NewDisputeService(
+			app.rideService, app.rideRepo, app.disputeRepo, app.refundService, app.pricingEngine,
+		)
+		app.disputeHandler = handler.NewDisputeHandler(app.disputeService)
+	}
+	
+	// SOS alerts reach ops through Kafka and Redis, whichever are configured
+	if app.safetyRepo != nil {
+		app.safetyAlerts = safety.NewPublisher(config.KafkaBrokers, app.redisClient)
+		app.safetyService = service.NewSafetyService(
+			app.rideService, app.safetyRepo, app.driverRepo, app.driverPool, app.safetyAlerts,
+		)
+		app.safetyHandler = handler.NewSafetyHandler(app.safetyService)
+	}
+	
+	// Passenger insurance in markets whose bundle enables it
+	if app.insuranceRepo != nil {
+		app.insurance = service.NewInsuranceService(
+			app.rideService, app.insuranceRepo, app.safetyRepo, app.cities,
+		)
+		app.rideService.SetInsurer(app.insurance)
+		app.insureHandler = handler.NewInsuranceHandler(app.insurance)
+	}
+	
+	// Receipts for completed rides, emailed by the notification service
+	// from the receipt topic
+	if app.receiptRepo != nil {
+		var publisher service.ReceiptPublisher
+		if len(config.KafkaBrokers) > 0 {
+			app.receiptEvents = receipt.NewPublisher(config.KafkaBrokers)
+			publisher = app.receiptEvents
+		} else {
+			log.Warn().Msg("Kafka not configured - ride receipts will not be emailed")
+		}
+		app.receiptService = service.NewReceiptService(app.rideService, app.receiptRepo, publisher)
+		app.rideService.SetReceipts(app.receiptService)
+		app.receiptHandler = handler.NewReceiptHandler(app.receiptService)
+	}
+	
+	// Lost items, relayed between rider and driver over the case's masked
+	// channel
+	if app.lostItemRepo != nil {
+		notificationClient := app.notifier(notification.NewClient(notification.ClientConfig{
+			BaseURL:    config.NotificationURL,
+			ServiceKey: config.ServiceKey,
+		}))
+		app.lostItems = service.NewLostItemService(app.rideService, app.lostItemRepo, notificationClient)
+		app.lostHandler = handler.NewLostItemHandler(app.lostItems)
+	}
+	
+	// Chat between a ride's rider and driver, live in Redis and archived
+	// as the ride ends
+	if app.messageRepo != nil && app.driverPool != nil {
+		notificationClient := app.notifier(notification.NewClient(notification.ClientConfig{
+			BaseURL:    config.NotificationURL,
+			ServiceKey: config.ServiceKey,
+		}))
+		app.rideChats = service.NewRideChatService(app.rideService, app.driverPool, app.messageRepo, notificationClient)
+		app.rideService.SetChats(app.rideChats)
+		app.chatHandler = handler.NewRideChatHandler(app.rideChats)
+	}
+	
+	if app.sanctionRepo != nil {
+		app.standingService = service.NewDriverStandingService(app.driverRepo, app.sanctionRepo, app.driverPool)
+		app.standingHandler = handler.NewDriverStandingHandler(app.standingService)
+	}
+	if app.qualityRepo != nil {
+		app.qualityService = service.NewDriverQualityService(app.qualityRepo)
+		app.qualityHandler = handler.NewDriverQualityHandler(app.qualityService)
+	}
+	
+	// Rider reliability: poor records prepay and wait in high demand
+	if app.reportRepo != nil {
+		app.reliability = service.NewRiderReliabilityService(app.rideService, app.reportRepo)
+		app.rideService.SetRiderReliability(app.reliability)
+		app.reportHandler = handler.NewRiderReliabilityHandler(app.reliability)
+	}
+	
+	// Favorite drivers rank ahead in matching; blocked and reported drivers
+	// are kept from the rider's rides
+	if app.prefRepo != nil {
+		app.prefService = service.NewDriverPreferenceService(app.rideService, app.prefRepo)
+		app.prefHandler = handler.NewDriverPreferenceHandler(app.prefService)
+		if app.disputeService != nil {
+			app.disputeService.SetDriverPreferences(app.prefService)
+		}
+	}
+	
+	// Consents gate location storage, personalization and marketing pushes
+	if app.consentRepo != nil {
+		app.consentService = service.NewConsentService(app.consentRepo)
+		app.consentHandler = handler.NewConsentHandler(app.consentService)
+	}
+	if app.checkRepo != nil {
+		var provider service.CheckProvider
+		if config.CheckURL != "" {
+			provider = verification.NewClient(verification.ClientConfig{
+				BaseURL: config.CheckURL,
+				APIKey:  config.CheckAPIKey,
+			})
+		} else {
+			log.Warn().Msg("Background check provider not configured - re-checks will not be requested")
+		}
+		app.checkService = service.NewBackgroundCheckService(
+			app.checkRepo, app.driverRepo, app.driverPool, app.standingService, provider,
+		)
+		app.checkHandler = handler.NewBackgroundCheckHandler(app.checkService, app.webhooks)
+	}
+	// Pool, intercity and regulated delivery work needs completed training
+	if app.trainingRepo != nil {
+		prereqs, err := domain.ParseTrainingPrerequisites(config.TrainingPrereqs)
+		if err != nil {
+			return nil, err
+		}
+		app.trainingService = service.NewTrainingService(app.trainingRepo, prereqs, app.cities)
+		app.trainingHandler = handler.NewTrainingHandler(app.trainingService, app.webhooks)
+	}
+	if app.identityRepo != nil {
+		if config.FaceMatchURL != "" {
+			matcher := verification.NewFaceMatchClient(verification.ClientConfig{
+				BaseURL: config.FaceMatchURL,
+				APIKey:  config.FaceMatchKey,
+			}, config.FaceMatchVendor)
+			app.identityService = service.NewIdentityCheckService(app.identityRepo, app.driverRepo, matcher)
+			app.identityHandler = handler.NewIdentityCheckHandler(app.identityService)
+		} else {
+			log.Warn().Msg("Face match provider not configured - shift-start selfie checks disabled")
+		}
+	}
+	if app.documentRepo != nil {
+		grace, err := domain.ParseDocumentGraceDays(config.DocumentGrace)
+		if err != nil {
+			return nil, err
+		}
+		notificationClient := app.notifier(notification.NewClient(notification.ClientConfig{
+			BaseURL:    config.NotificationURL,
+			ServiceKey: config.ServiceKey,
+		}))
+		app.documentService = service.NewDriverDocumentService(
+			app.documentRepo, app.driverRepo, app.driverPool, notificationClient, grace,
+		)
+		app.documentHandler = handler.NewDriverDocumentHandler(app.documentService)
+	}
+	
+	// Driver search, profiles and audited record changes for ops
+	if app.driverRepo != nil {
+		app.driverAdmin = service.NewDriverAdminService(
+			app.driverRepo, app.rideRepo, app.safetyRepo, app.driverPool,
+			app.standingService, app.qualityService, app.documentService,
+		)
+		app.adminDrvHandler = handler.NewDriverAdminHandler(app.driverAdmin)
+	}
+	if app.rideRepo != nil {
+		app.fareHandler = handler.NewFareHandler(app.rideService)
+		
+		app.varianceService = service.NewFareVarianceService(
+			app.rideRepo, app.pricingEngine, app.cities, config.VarianceAlert,
+		)
+		app.varianceHandler = handler.NewFareVarianceHandler(app.varianceService)
+		
+		app.zoneKPIService = service.NewZoneKPIService(app.rideRepo, app.driverPool, app.cities)
+		app.zoneKPIHandler = handler.NewZoneKPIHandler(app.zoneKPIService)
+		
+		app.metricsService = service.NewRideMetricsService(app.rideRepo, app.cities)
+		app.metricsHandler = handler.NewRideMetricsHandler(app.metricsService)
+		
+		app.historyService = service.NewLocationHistoryService(app.rideRepo, app.driverPool, app.cities)
+		app.historyService.SetSavedPlaces(app.placeRepo)
+		if app.consentService != nil {
+			app.historyService.SetConsents(app.consentService)
+			app.consentService.SetLocationHistory(app.historyService)
+		}
+		app.placeService = service.NewSavedPlaceService(app.placeRepo, app.historyService)
+		app.placeHandler = handler.NewSavedPlaceHandler(app.placeService)
+		
+		deliveryClient := delivery.NewClient(delivery.ClientConfig{
+			BaseURL:    config.DeliveryURL,
+			ServiceKey: config.ServiceKey,
+		})
+		app.earningsService = service.NewEarningsService(app.rideRepo, deliveryClient)
+		app.earningsHandler = handler.NewEarningsHandler(app.earningsService)
+		
+		app.returnService = service.NewReturnLegService(app.rideRepo, app.driverPool, deliveryClient, app.cities)
+		app.returnHandler = handler.NewReturnLegHandler(app.returnService)
+		
+		notificationClient := app.notifier(notification.NewClient(notification.ClientConfig{
+			BaseURL:    config.NotificationURL,
+			ServiceKey: config.ServiceKey,
+		}))
+		app.scheduleService = service.NewScheduledRideService(
+			app.rideService, app.rideRepo, app.driverRepo, app.pricingEngine, app.cities, app.promoService, notificationClient,
+		)
+		if app.qualityService != nil {
+			app.scheduleService.SetQualityProgram(app.qualityService)
+		}
+		if app.prefService != nil {
+			app.scheduleService.SetDriverPreferences(app.prefService)
+		}
+		app.claimHandler = handler.NewScheduledRideHandler(app.scheduleService)
+		
+		// Ops hand rides matching cannot place to drivers they choose
+		if app.driverRepo != nil {
+			app.dispatchService = service.NewManualDispatchService(app.rideRepo, app.driverRepo, app.driverPool, notificationClient)
+			if app.qualityService != nil {
+				app.dispatchService.SetQualityProgram(app.qualityService)
+			}
+			if app.prefService != nil {
+				app.dispatchService.SetDriverPreferences(app.prefService)
+			}
+			if app.trainingService != nil {
+				app.dispatchService.SetTraining(app.trainingService)
+			}
+			app.dispatchHandler = handler.NewManualDispatchHandler(app.dispatchService)
+		}
+		
+		app.nudgeService = service.NewRetentionService(app.rideService, app.rideRepo, app.promoService, notificationClient)
+		app.nudgeHandler = handler.NewRetentionHandler(app.nudgeService)
+		
+		sweepTimeouts, err := domain.ParseRideSweepTimeouts(config.RideSweep)
+		if err != nil {
+			return nil, err
+		}
+		paymentClient := payment.NewClient(payment.ClientConfig{
+			BaseURL:    config.PaymentURL,
+			ServiceKey: config.ServiceKey,
+		})
+		app.rideSweeper = service.NewRideSweeper(app.rideService, app.rideRepo, app.driverRepo, paymentClient, sweepTimeouts)
+		app.expiryService = service.NewRideExpiryService(app.rideService, app.rideRepo, paymentClient, notificationClient, config.MatchingTTL)
+	}
+	if app.driverRepo != nil && app.redisClient != nil {
+		app.traffic = eta.NewH3TrafficService(app.redisClient)
+		app.etaService = service.NewPickupETAService(
+			app.driverPool, app.driverRepo, app.travelMatrix, app.traffic, app.cities,
+		)
+		app.etaHandler = handler.NewPickupETAHandler(app.etaService)
+	}
+	app.driverService = service.NewDriverService(app.driverRepo, app.driverPool, app.checkService, app.identityService)
+	if app.documentService != nil {
+		app.driverService.SetDocumentService(app.documentService)
+	}
+	if app.qualityService != nil {
+		app.driverService.SetQualityProgram(app.qualityService)
+	}
+	if app.prefService != nil {
+		app.driverService.SetDriverPreferences(app.prefService)
+	}
+	if app.trainingService != nil {
+		app.driverService.SetTraining(app.trainingService)
+	}
+	if app.rideRepo != nil {
+		app.driverService.SetEventRecorder(app.rideRepo)
+		app.driverService.SetRideRepository(app.rideRepo)
+	}
+	// Offer requested rides to nearby drivers, ranked on learned pickup
+	// ETAs (requires Redis and the database)
+	if app.driverPool != nil && app.driverRepo != nil {
+		app.matcher = matching.NewEngine(
+			matching.DefaultConfig(),
+			service.NewMatchingPool(app.driverPool, app.driverRepo),
+			matching.NewOfferStream(app.redisClient),
+		)
+		app.matcher.SetTravelTimeEstimator(app.travelMatrix)
+		app.matcher.SetFareEstimator(app.pricingEngine)
+		app.matcher.SetCityResolver(app.cities.FindByLocation)
+		if app.qualityService != nil {
+			app.matcher.SetQualityBoosts(app.qualityService)
+		}
+		if app.prefService != nil {
+			app.matcher.SetDriverPreferences(app.prefService)
+		}
+		// Riders who asked for a green ride are offered electric and
+		// hybrid vehicles first
+		app.matcher.SetVehicleLookup(app.driverRepo)
+		if app.trainingService != nil {
+			app.matcher.SetTrainingGate(app.trainingService)
+		}
+		// Candidates with no road to the pickup in time are passed over
+		if valhalla != nil {
+			app.matcher.SetReachabilityProvider(valhalla)
+		}
+		if app.rideRepo != nil {
+			app.matcher.SetEventRecorder(app.rideRepo)
+		}
+		app.rideService.SetMatcher(app.matcher)
+		app.driverService.SetMatcher(app.matcher)
+	}
+	// Coalesce driver GPS pings into batched database writes (requires Redis)
+	if app.driverRepo != nil && app.driverPool != nil {
+		app.locationFlusher = service.NewLocationFlushService(app.driverPool, app.driverRepo)
+		app.driverService.SetLocationFlusher(app.locationFlusher)
+	}
+	// Driver location history from the location service's stream
+	if app.db != nil && len(config.KafkaBrokers) > 0 {
+		app.locationIngest = ingest.NewLocationConsumer(ingest.LocationConsumerConfig{
+			Brokers:       config.KafkaBrokers,
+			GroupID:       "ride-service-location-history",
+			BatchSize:     config.LocationBatch,
+			FlushInterval: config.LocationFlush,
+		}, repository.NewLocationPointRepository(app.db))
+		if app.consentService != nil {
+			app.locationIngest.SetConsents(app.consentService)
+		}
+	}
 	
 	// Initialize handlers
 	app.rideHandler = handler.NewRideHandler(
@@ -262,13 +1368,126 @@ func initializeApp(config *Config) (*App, error) {
 		app.driverService,
 		nil, // matching service injected later
 		app.pricingEngine,
+		app.cities,
 	)
+	if app.router.HasProviders() {
+		app.rideHandler.SetRouter(app.router)
+	}
 
 	// Initialize Google Maps client and location handler
 	app.mapsClient = geo.NewMapsClient(geo.MapsClientConfig{
 		APIKey: config.GoogleMapsKey,
 	})
-	app.locationHandler = handler.NewLocationHandler(app.mapsClient)
+
+	// Location predictions need ride history and the Maps client for place types
+	var ranker handler.SuggestionRanker
+	if app.rideRepo != nil {
+		app.popularService = service.NewPopularLocationService(app.rideRepo, app.driverPool, app.cities, app.mapsClient)
+		app.predictHandler = handler.NewPredictionHandler(app.historyService, app.popularService)
+		ranker = service.NewLocationSuggestionService(app.historyService, app.popularService)
+	}
+
+	// Route choices at ride confirmation are held in Redis until the rider confirms
+	if config.GoogleMapsKey != "" && app.driverPool != nil {
+		app.routeHandler = handler.NewRouteOptionHandler(
+			service.NewRouteOptionService(app.mapsClient, app.pricingEngine, app.cities, app.driverPool),
+		)
+	}
+
+	// Road incidents slow ETAs through them and reroute rides around them
+	if app.rideRepo != nil && app.driverPool != nil {
+		var mapsClient *geo.MapsClient
+		if config.GoogleMapsKey != "" {
+			mapsClient = app.mapsClient
+		}
+		notificationClient := app.notifier(notification.NewClient(notification.ClientConfig{
+			BaseURL:    config.NotificationURL,
+			ServiceKey: config.ServiceKey,
+		}))
+		app.incidentService = service.NewIncidentService(
+			app.driverPool, app.rideRepo, app.driverRepo, mapsClient, notificationClient,
+		)
+		app.incidentHandler = handler.NewIncidentHandler(app.incidentService)
+		
+		// Drivers' road reports become incidents once enough agree
+		app.roadReports = service.NewRoadReportService(app.driverPool, app.incidentService, notificationClient)
+		app.roadHandler = handler.NewRoadReportHandler(app.roadReports)
+		if app.traffic != nil {
+			app.traffic.SetIncidents(app.incidentService)
+		}
+	}
+
+	app.locationHandler = handler.NewLocationHandler(
+		app.mapsClient, app.cities, ranker, service.NewBatchGeocodeService(app.mapsClient, app.driverPool),
+	)
+	
+	// what3words addresses for pickups and dropoffs (feature flagged)
+	if config.W3WEnabled && config.W3WKey != "" {
+		what3words := geo.NewWhat3WordsClient(geo.What3WordsConfig{
+			APIKey:    config.W3WKey,
+			Countries: config.W3WCountries,
+		})
+		app.rideHandler.SetWhat3Words(what3words)
+		app.locationHandler.SetWhat3Words(what3words)
+	}
+	
+	// City weather slows traffic, adds to surge and pads severe-weather ETAs
+	if config.WeatherKey != "" {
+		app.weatherService = service.NewWeatherService(
+			weather.NewClient(weather.ClientConfig{APIKey: config.WeatherKey}), app.cities, app.pricingEngine,
+		)
+		app.rideService.SetWeather(app.weatherService)
+		app.rideHandler.SetWeather(app.weatherService)
+		if app.traffic != nil {
+			app.traffic.SetWeather(app.weatherService)
+		}
+	}
+	
+	// Fares can be shown in a rider's home currency; they still settle in
+	// the city's currency
+	var rateProvider money.RateProvider
+	if config.FXAppID != "" {
+		rateProvider = money.NewHTTPProvider(money.ProviderConfig{BaseURL: config.FXRatesURL, AppID: config.FXAppID})
+	}
+	app.fxRates = money.NewRateCache(rateProvider, app.redisClient)
+	app.rideHandler.SetRates(app.fxRates)
+	if app.fareHandler != nil {
+		app.fareHandler.SetRates(app.fxRates)
+	}
+	
+	// Riders see what their promo code takes off each estimate
+	if app.promoService != nil {
+		app.rideHandler.SetPromos(app.rideService)
+	}
+	
+	// Per-city kill switches for ride requests, new deliveries and surge
+	if app.driverPool != nil {
+		var auditRepo *repository.KillSwitchRepository
+		if app.db != nil {
+			auditRepo = repository.NewKillSwitchRepository(app.db)
+		}
+		app.killSwitches = service.NewKillSwitchService(app.driverPool, auditRepo, app.cities, app.pricingEngine)
+		app.killHandler = handler.NewKillSwitchHandler(app.killSwitches)
+		app.rideHandler.SetKillSwitches(app.killSwitches)
+		
+		// Log levels changed at runtime apply to every replica; the
+		// settings were validated at startup
+		logDefaults, _ := logSettings(config)
+		app.logSettings = service.NewLogSettingsService(app.driverPool, logDefaults)
+		app.logHandler = handler.NewLogSettingsHandler(app.logSettings)
+	}
+	
+	app.supportHandler = handler.NewSupportHandler(app.supportService)
+	
+	// Background jobs run on whichever replica holds the leader lock
+	replica := replicaName()
+	elector, err := workerElector(config, app, replica)
+	if err != nil {
+		return nil, err
+	}
+	app.workers = worker.New("ride-service", replica, elector)
+	app.registerJobs()
+	app.workerHandler = handler.NewWorkerHandler(app.workers)
 
 	if config.GoogleMapsKey != "" {
 		log.Info().Msg("Google Maps API configured")
@@ -279,8 +1498,241 @@ func initializeApp(config *Config) (*App, error) {
 	return app, nil
 }
 
+// notifier sends pushes through the notification client, checking consent
+// first when consent management is enabled
+func (a *App) notifier(client *notification.Client) service.Notifier {
+	if a.consentService == nil {
+		return client
+	}
+	return a.consentService.Notifier(client)
+}
+
+// registerJobs schedules the periodic jobs for whichever backends are
+// configured. Jobs run on the leader replica unless they work on state each
+// replica keeps for itself.
+func (a *App) registerJobs() {
+	if a.standingService != nil {
+		a.workers.Register(worker.Job{
+			Name:     "driver-reinstatement",
+			Schedule: worker.Every(5 * time.Minute),
+			Run: func(ctx context.Context) error {
+				n, err := a.standingService.ReinstateExpired(ctx)
+				if n > 0 {
+					log.Info().Int("reinstated", n).Msg("Reinstated drivers with expired suspensions")
+				}
+				return err
+			},
+		})
+	}
+	if a.checkService != nil {
+		a.workers.Register(worker.Job{
+			Name:     "background-rechecks",
+			Schedule: worker.Every(time.Hour),
+			Run: func(ctx context.Context) error {
+				n, err := a.checkService.ScheduleRechecks(ctx)
+				if n > 0 {
+					log.Info().Int("requested", n).Msg("Requested background re-checks")
+				}
+				return err
+			},
+		})
+	}
+	if a.qualityService != nil {
+		// Early on the 1st, once the month's last trips have closed
+		a.workers.Register(worker.Job{Name: "driver-quality-evaluation", Schedule: worker.MustParse("0 2 1 * *"), Run: a.qualityService.Run})
+	}
+	if a.documentService != nil {
+		a.workers.Register(worker.Job{Name: "driver-document-expiry", Schedule: worker.Every(time.Hour), Run: a.documentService.RunExpiry})
+	}
+	if a.rideSweeper != nil {
+		a.workers.Register(worker.Job{Name: "stuck-ride-sweep", Schedule: worker.Every(time.Minute), Run: a.rideSweeper.Run})
+	}
+	if a.expiryService != nil {
+		a.workers.Register(worker.Job{Name: "ride-request-expiry", Schedule: worker.Every(15 * time.Second), Run: a.expiryService.Run})
+	}
+	if a.varianceService != nil {
+		a.workers.Register(worker.Job{Name: "fare-variance", Schedule: worker.Every(15 * time.Minute), Run: a.varianceService.Run})
+	}
+	if a.zoneKPIService != nil {
+		a.workers.Register(worker.Job{Name: "zone-kpi-rollup", Schedule: worker.Every(5 * time.Minute), Run: a.zoneKPIService.Run})
+	}
+	if a.metricsService != nil {
+		a.workers.Register(worker.Job{Name: "ride-metrics-rollup", Schedule: worker.Every(10 * time.Minute), Run: a.metricsService.Run})
+	}
+	if a.historyService != nil {
+		a.workers.Register(worker.Job{Name: "location-history", Schedule: worker.Every(time.Minute), Run: a.historyService.Run})
+	}
+	if a.receiptService != nil {
+		a.workers.Register(worker.Job{Name: "ride-receipts", Schedule: worker.Every(time.Minute), Run: a.receiptService.Run})
+	}
+	if a.popularService != nil {
+		// Overnight in every market, when the fewest rides are being booked
+		a.workers.Register(worker.Job{Name: "popular-locations", Schedule: worker.MustParse("0 1 * * *"), Run: a.popularService.Run})
+	}
+	if a.scheduleService != nil {
+		a.workers.Register(worker.Job{Name: "scheduled-rides", Schedule: worker.Every(time.Minute), Run: a.scheduleService.Run})
+	}
+	if a.nudgeService != nil {
+		a.workers.Register(worker.Job{Name: "retention-nudges", Schedule: worker.Every(30 * time.Second), Run: a.nudgeService.Run})
+	}
+	if a.incidentService != nil {
+		a.workers.Register(worker.Job{Name: "incident-sweep", Schedule: worker.Every(time.Minute), Run: a.incidentService.Sweep})
+	}
+	if a.roadReports != nil {
+		a.workers.Register(worker.Job{Name: "road-report-prompts", Schedule: worker.Every(time.Minute), Run: a.roadReports.SendPrompts})
+	}
+	if a.rideRepo != nil && a.travelMatrix != nil {
+		job := eta.NewTravelMatrixJob(a.travelMatrix, a.rideRepo)
+		a.workers.Register(worker.Job{Name: "travel-matrix", Schedule: worker.Every(6 * time.Hour), Run: job.Run})
+	}
+	
+	// Each replica keeps its own surge switches and weather in memory
+	if a.killSwitches != nil {
+		a.workers.Register(worker.Job{
+			Name:         "kill-switch-sweep",
+			Schedule:     worker.Every(15 * time.Second),
+			Run:          a.killSwitches.Sweep,
+			EveryReplica: true,
+			RunOnStart:   true,
+		})
+	}
+	a.workers.Register(worker.Job{
+		Name:         "slo-burn-report",
+		Schedule:     worker.Every(time.Minute),
+		Run:          a.guard.Report,
+		EveryReplica: true,
+	})
+	if a.logSettings != nil {
+		a.workers.Register(worker.Job{
+			Name:         "log-settings-sync",
+			Schedule:     worker.Every(15 * time.Second),
+			Run:          a.logSettings.Sync,
+			EveryReplica: true,
+			RunOnStart:   true,
+		})
+	}
+	if a.qualityService != nil {
+		a.workers.Register(worker.Job{
+			Name:         "driver-quality-tiers",
+			Schedule:     worker.Every(15 * time.Minute),
+			Run:          a.qualityService.Refresh,
+			EveryReplica: true,
+			RunOnStart:   true,
+		})
+	}
+	if a.weatherService != nil {
+		a.workers.Register(worker.Job{
+			Name:         "weather-poll",
+			Schedule:     worker.Every(10 * time.Minute),
+			Run:          a.weatherService.Poll,
+			EveryReplica: true,
+			RunOnStart:   true,
+		})
+	}
+	// Each replica holds the day's rates; Redis spares the provider repeat fetches
+	a.workers.Register(worker.Job{
+		Name:         "fx-rates-refresh",
+		Schedule:     worker.Every(time.Hour),
+		Run:          a.fxRates.Refresh,
+		EveryReplica: true,
+		RunOnStart:   true,
+	})
+	// The flush queue lives in Redis, so replicas share the writes
+	if a.locationFlusher != nil {
+		a.workers.Register(worker.Job{
+			Name:         "location-flush",
+			Schedule:     worker.Every(2 * time.Second),
+			Run:          a.locationFlusher.Flush,
+			EveryReplica: true,
+		})
+	}
+}
+
+// startBackgroundJobs starts the job scheduler and the consumers that run
+// continuously on every replica
+func (a *App) startBackgroundJobs(ctx context.Context) {
+	a.workersDone = make(chan struct{})
+	go func() {
+		defer close(a.workersDone)
+		a.workers.Start(ctx)
+	}()
+	
+	if a.controlsService != nil {
+		go a.controlsService.StartFlushJob(ctx, 30*time.Second)
+		log.Info().Msg("Price control violation flush job started")
+	}
+	if a.incidentService != nil {
+		go a.incidentService.StartConsumer(ctx)
+		log.Info().Msg("Traffic incident feed consumer started")
+	}
+	if a.locationIngest != nil {
+		go a.locationIngest.Start(ctx)
+		log.Info().Msg("Driver location history ingestion started")
+	}
+	
+	if osrm := a.router.OSRM(); osrm != nil && len(osrm.RegionHealth()) > 0 {
+		go osrm.StartHealthChecks(ctx, 5*time.Minute)
+		log.Info().Msg("OSRM dataset health checks started")
+	}
+}
+
+// workerElector picks how replicas elect the one that runs singleton jobs,
+// falling back to the other backend when the configured one is missing
+func workerElector(config *Config, app *App, replica string) (worker.Elector, error) {
+	redisElector := func() worker.Elector {
+		return worker.NewRedisElector(app.redisClient, "workers:leader:ride-service", replica, workerLeaseTTL)
+	}
+	postgresElector := func() worker.Elector {
+		return worker.NewPostgresElector(app.db, "ride-service:workers")
+	}
+	
+	switch config.WorkerLock {
+	case "redis":
+		if app.redisClient != nil {
+			return redisElector(), nil
+		}
+		if app.db != nil {
+			log.Warn().Msg("Redis not configured - electing the worker leader with a Postgres advisory lock")
+			return postgresElector(), nil
+		}
+	case "postgres":
+		if app.db != nil {
+			return postgresElector(), nil
+		}
+		if app.redisClient != nil {
+			log.Warn().Msg("Database not configured - electing the worker leader with a Redis lease")
+			return redisElector(), nil
+		}
+	case "none":
+	default:
+		return nil, fmt.Errorf("unknown WORKER_LEADER_LOCK %q, want redis, postgres or none", config.WorkerLock)
+	}
+	
+	log.Warn().Msg("No worker leader election - every replica runs every background job")
+	return worker.Standalone(), nil
+}
+
+// replicaName names this instance for worker leader election, unique even
+// when pods share a hostname
+func replicaName() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "ride-service"
+	}
+	return host + "-" + uuid.NewString()[:8]
+}
+
 // cleanup releases all resources
 func (a *App) cleanup() {
+	if a.locationIngest != nil {
+		a.locationIngest.Close()
+	}
+	if a.safetyAlerts != nil {
+		a.safetyAlerts.Close()
+	}
+	if a.receiptEvents != nil {
+		a.receiptEvents.Close()
+	}
 	if a.db != nil {
 		a.db.Close()
 		log.Info().Msg("Database connection closed")
@@ -319,10 +1771,101 @@ func loadConfig() *Config {
 		DatabaseURL:     getEnv("DATABASE_URL", ""),
 		RedisURL:        getEnv("REDIS_URL", ""),
 		GoogleMapsKey:   getEnv("GOOGLE_MAPS_API_KEY", ""),
+		PaymentURL:      getEnv("PAYMENT_SERVICE_URL", "http://localhost:4003"),
+		DeliveryURL:     getEnv("DELIVERY_SERVICE_URL", "http://localhost:4005"),
+		NotificationURL: getEnv("NOTIFICATION_SERVICE_URL", "http://localhost:4006"),
+		ServiceKey:      getEnv("INTERNAL_SERVICE_KEY", ""),
+		CheckURL:        getEnv("BACKGROUND_CHECK_URL", ""),
+		CheckAPIKey:     getEnv("BACKGROUND_CHECK_API_KEY", ""),
+		CheckSecret:     getEnv("BACKGROUND_CHECK_WEBHOOK_SECRET", ""),
+		CheckSecrets:    getEnv("BACKGROUND_CHECK_WEBHOOK_SECRETS", ""),
+		FaceMatchURL:    getEnv("FACE_MATCH_URL", ""),
+		FaceMatchKey:    getEnv("FACE_MATCH_API_KEY", ""),
+		FaceMatchVendor: getEnv("FACE_MATCH_PROVIDER", "smile_identity"),
+		CityConfigDir:   getEnv("CITY_CONFIG_DIR", ""),
+		W3WEnabled:      getEnvBool("WHAT3WORDS_ENABLED", false),
+		W3WKey:          getEnv("WHAT3WORDS_API_KEY", ""),
+		W3WCountries:    getEnvList("WHAT3WORDS_COUNTRIES"),
+		OSRMRegions:     getEnv("OSRM_REGIONS", ""),
+		ValhallaURL:     getEnv("VALHALLA_URL", ""),
+		WeatherKey:      getEnv("OPENWEATHER_API_KEY", ""),
+		FXAppID:         getEnv("FX_RATES_APP_ID", ""),
+		FXRatesURL:      getEnv("FX_RATES_URL", ""),
+		KafkaBrokers:    getEnvList("KAFKA_BROKERS"),
+		LocationBatch:   getEnvInt("LOCATION_INGEST_BATCH_SIZE", domain.DefaultLocationIngestBatchSize),
+		LocationFlush:   getEnvDuration("LOCATION_INGEST_FLUSH_INTERVAL", domain.DefaultLocationIngestFlushInterval),
+		VarianceAlert:   getEnvFloat("FARE_VARIANCE_ALERT_PCT", 0),
+		GlutFloor:       getEnvFloat("GLUT_DISCOUNT_FLOOR", 0.85),
+		DocumentGrace:   getEnv("DOCUMENT_GRACE_DAYS", ""),
+		RideSweep:       getEnv("RIDE_SWEEP_TIMEOUTS", ""),
+		TrainingPrereqs: getEnv("TRAINING_PREREQUISITES", ""),
+		MatchingTTL:     getEnvDuration("RIDE_MATCHING_TTL", domain.DefaultMatchingTTL),
+		LogLevel:        getEnv("LOG_LEVEL", "info"),
+		LogLevels:       getEnv("LOG_LEVELS", ""),
+		LogSampling:     getEnv("LOG_SAMPLING", ""),
+		BodyLimits:      getEnv("REQUEST_BODY_LIMITS", ""),
+		LatencyBudgets:  getEnv("LATENCY_BUDGETS", ""),
+		SLOObjective:    getEnvFloat("SLO_OBJECTIVE", guard.DefaultObjective),
+		WebhookSkew:     getEnvDuration("WEBHOOK_TIMESTAMP_TOLERANCE", webhook.DefaultTolerance),
+		ShareSecret:     getEnv("TRIP_SHARE_SECRET", ""),
+		ShareTTL:        getEnvDuration("TRIP_SHARE_TTL", domain.DefaultTripShareTTL),
+		WorkerLock:      getEnv("WORKER_LEADER_LOCK", "redis"),
 		ShutdownTimeout: 30 * time.Second,
 	}
 }
 
+// logSettings builds the startup log settings from LOG_LEVEL, LOG_LEVELS
+// and LOG_SAMPLING
+func logSettings(config *Config) (logging.Settings, error) {
+	levels, err := logging.ParseLevels(config.LogLevels)
+	if err != nil {
+		return logging.Settings{}, err
+	}
+	rates, err := logging.ParseSampleRates(config.LogSampling)
+	if err != nil {
+		return logging.Settings{}, err
+	}
+	return logging.Settings{Level: config.LogLevel, Levels: levels, SampleRates: rates}, nil
+}
+
+// serviceAreaCity names the service area containing a location, which the
+// travel matrix is built and looked up per
+func serviceAreaCity(lat, lng float64) (string, bool) {
+	ok, area := geo.IsInServiceArea(lat, lng)
+	if !ok {
+		return "", false
+	}
+	return area.Name, true
+}
+
+// osrmRegions builds the OSRM regional datasets from COUNTRY=URL pairs,
+// probing each at the center of the country's first enabled city
+func osrmRegions(spec string, cities *cityconfig.Registry) ([]eta.OSRMRegion, error) {
+	urls, err := eta.ParseOSRMRegions(spec)
+	if err != nil {
+		return nil, err
+	}
+	
+	var regions []eta.OSRMRegion
+	for country, baseURL := range urls {
+		region := eta.OSRMRegion{Country: country, BaseURL: baseURL}
+		found := false
+		for _, city := range cities.List() {
+			if city.Enabled && city.Country == country {
+				region.ProbeLat = city.ServiceArea.CenterLat
+				region.ProbeLng = city.ServiceArea.CenterLng
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("OSRM region %s has no enabled city to probe", country)
+		}
+		regions = append(regions, region)
+	}
+	return regions, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -330,6 +1873,44 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // Health check handlers
 
 func (a *App) healthLive(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,172 @@
+// Package cityconfig loads city launch bundles and answers per-city lookups
+// for pricing, service areas and geocoding.
+package cityconfig
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	// City bundles name IANA timezones; embed the database for slim images
+	_ "time/tzdata"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+)
+
+//go:embed bundles/*.json
+var defaultBundles embed.FS
+
+// DefaultBundles returns the city bundles compiled into the service
+func DefaultBundles() ([]*domain.CityConfig, error) {
+	sub, err := fs.Sub(defaultBundles, "bundles")
+	if err != nil {
+		return nil, err
+	}
+	return LoadBundles(sub)
+}
+
+// LoadBundles reads and validates every *.json city bundle at the root of fsys
+func LoadBundles(fsys fs.FS) ([]*domain.CityConfig, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	cities := make([]*domain.CityConfig, 0, len(files))
+	for _, name := range files {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var city domain.CityConfig
+		if err := json.Unmarshal(data, &city); err != nil {
+			return nil, fmt.Errorf("%s: %w", path.Base(name), err)
+		}
+		if err := city.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path.Base(name), err)
+		}
+		cities = append(cities, &city)
+	}
+
+	return cities, nil
+}
+
+// Registry holds the active city bundles. Bundles are replaced whole and must
+// not be modified after Put.
+type Registry struct {
+	mu     sync.RWMutex
+	cities map[string]*domain.CityConfig
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{cities: make(map[string]*domain.CityConfig)}
+}
+
+// Put adds or replaces a city bundle
+func (r *Registry) Put(city *domain.CityConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cities[city.Code] = city
+}
+
+// Get returns a city bundle by code
+func (r *Registry) Get(code string) (*domain.CityConfig, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	city, ok := r.cities[strings.ToLower(code)]
+	return city, ok
+}
+
+// List returns all bundles, enabled or not, ordered by code
+func (r *Registry) List() []*domain.CityConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cities := make([]*domain.CityConfig, 0, len(r.cities))
+	for _, city := range r.cities {
+		cities = append(cities, city)
+	}
+	sort.Slice(cities, func(i, j int) bool { return cities[i].Code < cities[j].Code })
+	return cities
+}
+
+// FindByLocation returns the enabled city whose service area contains the point
+func (r *Registry) FindByLocation(lat, lng float64) (*domain.CityConfig, bool) {
+	for _, city := range r.List() {
+		if city.Enabled && Contains(city, lat, lng) {
+			return city, true
+		}
+	}
+	return nil, false
+}
+
+// FindByName returns the enabled city with a name or code, ignoring case,
+// such as the locality written after a short plus code
+func (r *Registry) FindByName(name string) (*domain.CityConfig, bool) {
+	name = strings.TrimSpace(name)
+	for _, city := range r.List() {
+		if city.Enabled && (strings.EqualFold(city.Name, name) || strings.EqualFold(city.Code, name)) {
+			return city, true
+		}
+	}
+	return nil, false
+}
+
+// H3Resolution returns the indexing resolution for the city at a point,
+// falling back to the service default outside configured cities
+func (r *Registry) H3Resolution(lat, lng float64) int {
+	if city, ok := r.FindByLocation(lat, lng); ok && city.H3Resolution > 0 {
+		return city.H3Resolution
+	}
+	return geo.H3Resolution
+}
+
+// CountryComponents returns a Maps API component filter restricting results to
+// the countries of enabled cities, e.g. "country:ng|country:ke". It is empty
+// when no cities are enabled.
+func (r *Registry) CountryComponents() string {
+	seen := make(map[string]bool)
+	components := make([]string, 0)
+	for _, city := range r.List() {
+		country := strings.ToLower(city.Country)
+		if city.Enabled && !seen[country] {
+			seen[country] = true
+			components = append(components, "country:"+country)
+		}
+	}
+	return strings.Join(components, "|")
+}
+
+// Contains reports whether a point lies in a city's service area
+func Contains(city *domain.CityConfig, lat, lng float64) bool {
+	area := city.ServiceArea
+	if len(area.Polygon) >= 3 {
+		return pointInPolygon(area.Polygon, lat, lng)
+	}
+	return geo.HaversineDistance(area.CenterLat, area.CenterLng, lat, lng) <= area.RadiusM
+}
+
+// pointInPolygon uses ray casting over [lat, lng] vertices
+func pointInPolygon(polygon [][2]float64, lat, lng float64) bool {
+	inside := false
+	j := len(polygon) - 1
+	for i := range polygon {
+		latI, lngI := polygon[i][0], polygon[i][1]
+		latJ, lngJ := polygon[j][0], polygon[j][1]
+		if (latI > lat) != (latJ > lat) &&
+			lng < (lngJ-lngI)*(lat-latI)/(latJ-latI)+lngI {
+			inside = !inside
+		}
+		j = i
+	}
+	return inside
+}
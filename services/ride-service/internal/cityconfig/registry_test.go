@@ -0,0 +1,69 @@
+package cityconfig
+
+import (
+	"testing"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+func TestDefaultBundlesAreValid(t *testing.T) {
+	cities, err := DefaultBundles()
+	if err != nil {
+		t.Fatalf("DefaultBundles() error = %v", err)
+	}
+	if len(cities) == 0 {
+		t.Fatal("expected compiled-in city bundles")
+	}
+
+	registry := NewRegistry()
+	for _, city := range cities {
+		registry.Put(city)
+	}
+
+	city, ok := registry.FindByLocation(6.4550, 3.3941) // Lagos Island
+	if !ok || city.Code != "lagos" {
+		t.Errorf("FindByLocation(Lagos Island) = %v, %v; want lagos", city, ok)
+	}
+	if _, ok := registry.FindByLocation(51.5074, -0.1278); ok {
+		t.Error("expected London to be outside every service area")
+	}
+}
+
+func TestContainsPolygon(t *testing.T) {
+	city := &domain.CityConfig{
+		ServiceArea: domain.CityServiceArea{
+			Polygon: [][2]float64{{0, 0}, {0, 10}, {10, 10}, {10, 0}},
+		},
+	}
+
+	if !Contains(city, 5, 5) {
+		t.Error("expected point inside polygon")
+	}
+	if Contains(city, 5, 15) {
+		t.Error("expected point outside polygon")
+	}
+}
+
+func TestCountryComponentsSkipsDisabledCities(t *testing.T) {
+	registry := NewRegistry()
+	registry.Put(&domain.CityConfig{Code: "lagos", Country: "NG", Enabled: true})
+	registry.Put(&domain.CityConfig{Code: "abuja", Country: "NG", Enabled: true})
+	registry.Put(&domain.CityConfig{Code: "kigali", Country: "RW", Enabled: false})
+
+	if got := registry.CountryComponents(); got != "country:ng" {
+		t.Errorf("CountryComponents() = %q, want %q", got, "country:ng")
+	}
+}
+
+func TestFindByName(t *testing.T) {
+	registry := NewRegistry()
+	registry.Put(&domain.CityConfig{Code: "dar-es-salaam", Name: "Dar es Salaam", Enabled: true})
+	registry.Put(&domain.CityConfig{Code: "kigali", Name: "Kigali", Enabled: false})
+
+	if city, ok := registry.FindByName(" dar ES salaam"); !ok || city.Code != "dar-es-salaam" {
+		t.Errorf("FindByName by name = %v, %v", city, ok)
+	}
+	if _, ok := registry.FindByName("kigali"); ok {
+		t.Error("disabled cities should not be found")
+	}
+}
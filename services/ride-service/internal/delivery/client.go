@@ -0,0 +1,173 @@
+// Package delivery provides a client for the delivery service.
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// Client calls the delivery service's internal API
+type Client struct {
+	baseURL    string
+	serviceKey string
+	httpClient *http.Client
+}
+
+// ClientConfig holds configuration for the delivery client
+type ClientConfig struct {
+	BaseURL    string
+	ServiceKey string
+	Timeout    time.Duration
+}
+
+// NewClient creates a new delivery service client
+func NewClient(config ClientConfig) *Client {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		serviceKey: config.ServiceKey,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+type apiResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// earningsLine is one currency of the delivery service's earnings response.
+// Amounts are major units.
+type earningsLine struct {
+	Currency    string  `json:"currency"`
+	Deliveries  int     `json:"deliveries"`
+	Fares       float64 `json:"fares"`
+	Tips        float64 `json:"tips"`
+	Adjustments float64 `json:"adjustments"`
+}
+
+// GetDriverEarnings fetches a driver's delivery earnings in [from, to), one
+// line per currency. Amounts are converted to minor units.
+func (c *Client) GetDriverEarnings(ctx context.Context, driverID uuid.UUID, from, to time.Time) ([]*domain.EarningsLine, error) {
+	query := url.Values{}
+	query.Set("from", from.UTC().Format(time.RFC3339))
+	query.Set("to", to.UTC().Format(time.RFC3339))
+
+	var result struct {
+		Currencies []earningsLine `json:"currencies"`
+	}
+	path := fmt.Sprintf("/api/v1/internal/drivers/%s/earnings?%s", driverID, query.Encode())
+	if err := c.get(ctx, path, &result); err != nil {
+		return nil, err
+	}
+
+	lines := make([]*domain.EarningsLine, 0, len(result.Currencies))
+	for _, cur := range result.Currencies {
+		lines = append(lines, &domain.EarningsLine{
+			Source:      domain.EarningsSourceDeliveries,
+			Currency:    domain.Currency(cur.Currency),
+			Trips:       cur.Deliveries,
+			Fares:       toMinorUnits(cur.Fares),
+			Tips:        toMinorUnits(cur.Tips),
+			Adjustments: toMinorUnits(cur.Adjustments),
+		})
+	}
+	return lines, nil
+}
+
+// corridorDelivery is a delivery from the corridor search. Fares are major
+// units.
+type corridorDelivery struct {
+	ID              string          `json:"id"`
+	PickupLocation  domain.Location `json:"pickupLocation"`
+	DropoffLocation domain.Location `json:"dropoffLocation"`
+	TotalFare       float64         `json:"totalFare"`
+	Currency        string          `json:"currency"`
+}
+
+// GetCorridorDeliveries fetches deliveries waiting for a driver whose pickup
+// and dropoff lie within widthM of the way from one point to another and
+// which end closer to it, as return legs. Fares are converted to minor
+// units.
+func (c *Client) GetCorridorDeliveries(ctx context.Context, fromLat, fromLng, toLat, toLng, widthM float64) ([]*domain.ReturnLeg, error) {
+	query := url.Values{}
+	query.Set("from_lat", strconv.FormatFloat(fromLat, 'f', -1, 64))
+	query.Set("from_lng", strconv.FormatFloat(fromLng, 'f', -1, 64))
+	query.Set("to_lat", strconv.FormatFloat(toLat, 'f', -1, 64))
+	query.Set("to_lng", strconv.FormatFloat(toLng, 'f', -1, 64))
+	query.Set("width_m", strconv.FormatFloat(widthM, 'f', -1, 64))
+
+	var result struct {
+		Deliveries []corridorDelivery `json:"deliveries"`
+	}
+	if err := c.get(ctx, "/api/v1/internal/deliveries/corridor?"+query.Encode(), &result); err != nil {
+		return nil, err
+	}
+
+	legs := make([]*domain.ReturnLeg, 0, len(result.Deliveries))
+	for _, d := range result.Deliveries {
+		domain.NormalizeLocation(&d.PickupLocation)
+		domain.NormalizeLocation(&d.DropoffLocation)
+		legs = append(legs, &domain.ReturnLeg{
+			Source:          domain.ReturnLegSourceDelivery,
+			ID:              d.ID,
+			PickupLocation:  d.PickupLocation,
+			DropoffLocation: d.DropoffLocation,
+			Fare:            toMinorUnits(d.TotalFare),
+			Currency:        domain.Currency(d.Currency),
+		})
+	}
+	return legs, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Service-Key", c.serviceKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("delivery service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("failed to decode delivery service response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 || !apiResp.Success {
+		if apiResp.Error != nil {
+			return fmt.Errorf("delivery service error %s: %s", apiResp.Error.Code, apiResp.Error.Message)
+		}
+		return fmt.Errorf("delivery service returned status %d", resp.StatusCode)
+	}
+
+	if out != nil && len(apiResp.Data) > 0 {
+		return json.Unmarshal(apiResp.Data, out)
+	}
+	return nil
+}
+
+func toMinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+)
+
+// LandmarkRelation is where a place is relative to a landmark
+type LandmarkRelation string
+
+const (
+	LandmarkOpposite LandmarkRelation = "OPPOSITE"
+	LandmarkBeside   LandmarkRelation = "BESIDE"
+	LandmarkBehind   LandmarkRelation = "BEHIND"
+	LandmarkInFront  LandmarkRelation = "IN_FRONT_OF"
+	LandmarkNear     LandmarkRelation = "NEAR"
+	LandmarkAfter    LandmarkRelation = "AFTER"
+	LandmarkBefore   LandmarkRelation = "BEFORE"
+	LandmarkInside   LandmarkRelation = "INSIDE"
+	LandmarkOff      LandmarkRelation = "OFF"
+)
+
+// Landmark is a well-known place an address is given relative to, as in
+// "opposite GTBank"
+type Landmark struct {
+	Relation LandmarkRelation `json:"relation"`
+	Name     string           `json:"name"`
+}
+
+// String reads the landmark out for a driver
+func (l Landmark) String() string {
+	relation := strings.ReplaceAll(strings.ToLower(string(l.Relation)), "_", " ")
+	return strings.ToUpper(relation[:1]) + relation[1:] + " " + l.Name
+}
+
+// landmarkPhrase is a way of writing a landmark relation. Phrases that are
+// ordinary words mid-sentence only count at the start of a comma-separated
+// part.
+type landmarkPhrase struct {
+	pattern     *regexp.Regexp
+	relation    LandmarkRelation
+	leadingOnly bool
+}
+
+// landmarkPhrases are tried longest first so "directly opposite" wins over
+// "opposite"
+var landmarkPhrases = []landmarkPhrase{
+	{regexp.MustCompile(`(?i)\b(directly opposite|across the road from|across from)\s+`), LandmarkOpposite, false},
+	{regexp.MustCompile(`(?i)\b(opposite|opp\.?)\s+`), LandmarkOpposite, false},
+	{regexp.MustCompile(`(?i)\b(next to|adjacent to|beside)\s+`), LandmarkBeside, false},
+	{regexp.MustCompile(`(?i)\bin front of\s+`), LandmarkInFront, false},
+	{regexp.MustCompile(`(?i)\bbehind\s+`), LandmarkBehind, false},
+	{regexp.MustCompile(`(?i)\b(close to|near)\s+`), LandmarkNear, false},
+	{regexp.MustCompile(`(?i)\b(inside|within)\s+`), LandmarkInside, false},
+	{regexp.MustCompile(`(?i)^(after)\s+`), LandmarkAfter, true},
+	{regexp.MustCompile(`(?i)^(before)\s+`), LandmarkBefore, true},
+	{regexp.MustCompile(`(?i)^(off)\s+`), LandmarkOff, true},
+	{regexp.MustCompile(`(?i)^(by)\s+`), LandmarkBeside, true},
+}
+
+// addressAbbreviations expands the street-type and landmark shorthand
+// common in written addresses
+var addressAbbreviations = []struct {
+	pattern *regexp.Regexp
+	full    string
+}{
+	{regexp.MustCompile(`(?i)\bb/\s?stop\b|\bb/s\b`), "Bus Stop"},
+	{regexp.MustCompile(`(?i)\bave?\b\.?`), "Avenue"},
+	{regexp.MustCompile(`(?i)\brd\b\.?`), "Road"},
+	{regexp.MustCompile(`(?i)\bstr\b\.?`), "Street"},
+	{regexp.MustCompile(`(?i)\bcres\b\.?`), "Crescent"},
+	{regexp.MustCompile(`(?i)\bblvd\b\.?`), "Boulevard"},
+	{regexp.MustCompile(`(?i)\bhwy\b\.?`), "Highway"},
+}
+
+var streetNumber = regexp.MustCompile(`^(\d+[A-Za-z]?)\s+(.+)$`)
+
+// ParsedAddress is a free-text address split into its street address,
+// the landmarks it was given by and its best-guess components
+type ParsedAddress struct {
+	Address    string             `json:"address"`
+	Landmarks  []Landmark         `json:"landmarks,omitempty"`
+	Components *AddressComponents `json:"components,omitempty"`
+}
+
+// ParseAddress pulls landmark phrases out of a written address, such as
+// "12 Allen Ave, opposite GTBank, Ikeja", leaving the street address.
+// Components are a best guess from the order of the parts; geocoded
+// components should be preferred where there are any.
+func ParseAddress(address string) *ParsedAddress {
+	parsed := &ParsedAddress{}
+
+	var plain []string
+	for _, part := range strings.FieldsFunc(address, func(r rune) bool { return r == ',' || r == ';' }) {
+		part = expandAbbreviations(strings.Join(strings.Fields(part), " "))
+		if part == "" {
+			continue
+		}
+
+		rest, landmark := splitLandmark(part)
+		if rest != "" {
+			plain = append(plain, rest)
+		}
+		if landmark != nil {
+			parsed.Landmarks = append(parsed.Landmarks, *landmark)
+		}
+	}
+
+	parsed.Address = strings.Join(plain, ", ")
+	if len(plain) > 0 {
+		parsed.Components = guessComponents(plain)
+	}
+	return parsed
+}
+
+// splitLandmark splits a part of an address at its first landmark phrase
+// into what comes before it and the landmark
+func splitLandmark(part string) (string, *Landmark) {
+	for _, phrase := range landmarkPhrases {
+		loc := phrase.pattern.FindStringIndex(part)
+		if loc == nil || (phrase.leadingOnly && loc[0] != 0) {
+			continue
+		}
+		name := strings.TrimSpace(strings.TrimRight(part[loc[1]:], "."))
+		if name == "" {
+			continue
+		}
+		return strings.TrimSpace(part[:loc[0]]), &Landmark{Relation: phrase.relation, Name: name}
+	}
+	return part, nil
+}
+
+func expandAbbreviations(part string) string {
+	for _, abbr := range addressAbbreviations {
+		part = abbr.pattern.ReplaceAllString(part, abbr.full)
+	}
+	return part
+}
+
+// guessComponents reads the parts of a street address as street, then
+// neighbourhood, then city
+func guessComponents(parts []string) *AddressComponents {
+	c := &AddressComponents{Street: parts[0]}
+	if m := streetNumber.FindStringSubmatch(parts[0]); m != nil {
+		c.StreetNumber, c.Street = m[1], m[2]
+	}
+	switch rest := parts[1:]; len(rest) {
+	case 0:
+	case 1:
+		c.City = rest[0]
+	default:
+		c.Neighborhood = rest[0]
+		c.City = rest[len(rest)-1]
+	}
+	return c
+}
+
+// NormalizeLocation fills a location's landmarks and components from its
+// written address, keeping any the client already sent
+func NormalizeLocation(loc *Location) {
+	if loc.Address == "" || (len(loc.Landmarks) > 0 && loc.Components != nil) {
+		return
+	}
+	parsed := ParseAddress(loc.Address)
+	if len(loc.Landmarks) == 0 {
+		loc.Landmarks = parsed.Landmarks
+	}
+	if loc.Components == nil {
+		loc.Components = parsed.Components
+	}
+}
+
+// DriverDirections is what a driver is told to find a location by: its
+// landmarks, then the free-text directions left for them
+func (l *Location) DriverDirections() string {
+	parts := make([]string, 0, len(l.Landmarks)+1)
+	for _, landmark := range l.Landmarks {
+		parts = append(parts, landmark.String())
+	}
+	if directions := strings.TrimSpace(l.Directions); directions != "" {
+		parts = append(parts, directions)
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "testing"
+
+func TestParseAddress(t *testing.T) {
+	parsed := ParseAddress("12 Allen Ave, opp. GTBank, beside Mr Biggs, Ikeja, Lagos")
+
+	if parsed.Address != "12 Allen Avenue, Ikeja, Lagos" {
+		t.Errorf("address = %q", parsed.Address)
+	}
+	want := []Landmark{{LandmarkOpposite, "GTBank"}, {LandmarkBeside, "Mr Biggs"}}
+	if len(parsed.Landmarks) != len(want) {
+		t.Fatalf("landmarks = %v, want %v", parsed.Landmarks, want)
+	}
+	for i := range want {
+		if parsed.Landmarks[i] != want[i] {
+			t.Errorf("landmark %d = %v, want %v", i, parsed.Landmarks[i], want[i])
+		}
+	}
+
+	c := parsed.Components
+	if c.StreetNumber != "12" || c.Street != "Allen Avenue" || c.Neighborhood != "Ikeja" || c.City != "Lagos" {
+		t.Errorf("components = %+v", c)
+	}
+}
+
+func TestParseAddressLandmarkMidPart(t *testing.T) {
+	parsed := ParseAddress("Plot 5 Ngong Rd directly opposite Prestige Plaza")
+
+	if parsed.Address != "Plot 5 Ngong Road" {
+		t.Errorf("address = %q", parsed.Address)
+	}
+	if len(parsed.Landmarks) != 1 || parsed.Landmarks[0] != (Landmark{LandmarkOpposite, "Prestige Plaza"}) {
+		t.Errorf("landmarks = %v", parsed.Landmarks)
+	}
+}
+
+func TestParseAddressLeadingOnlyPhrases(t *testing.T) {
+	// "by" only marks a landmark at the start of a part
+	parsed := ParseAddress("House built by Julius Berger, by the roundabout")
+
+	if parsed.Address != "House built by Julius Berger" {
+		t.Errorf("address = %q", parsed.Address)
+	}
+	if len(parsed.Landmarks) != 1 || parsed.Landmarks[0].Name != "the roundabout" {
+		t.Errorf("landmarks = %v", parsed.Landmarks)
+	}
+}
+
+func TestDriverDirections(t *testing.T) {
+	loc := &Location{Address: "4 Oxford Rd, in front of Total filling station", Directions: "Blue gate"}
+	NormalizeLocation(loc)
+
+	if got := loc.DriverDirections(); got != "In front of Total filling station; Blue gate" {
+		t.Errorf("DriverDirections = %q", got)
+	}
+}
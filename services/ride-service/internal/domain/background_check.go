@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BackgroundCheckStatus is the normalized outcome of a third-party background check
+type BackgroundCheckStatus string
+
+const (
+	BackgroundCheckPending  BackgroundCheckStatus = "PENDING"
+	BackgroundCheckClear    BackgroundCheckStatus = "CLEAR"
+	BackgroundCheckConsider BackgroundCheckStatus = "CONSIDER" // provider flagged records for manual review
+	BackgroundCheckFailed   BackgroundCheckStatus = "FAILED"
+	BackgroundCheckExpired  BackgroundCheckStatus = "EXPIRED" // cleared, but the re-check deadline has passed
+)
+
+// ParseBackgroundCheckStatus maps a provider's status string onto our statuses.
+// Providers disagree on vocabulary, so common synonyms are accepted.
+func ParseBackgroundCheckStatus(s string) (BackgroundCheckStatus, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "pending", "in_progress", "processing", "submitted":
+		return BackgroundCheckPending, true
+	case "clear", "passed", "approved", "complete_clear":
+		return BackgroundCheckClear, true
+	case "consider", "review", "needs_review":
+		return BackgroundCheckConsider, true
+	case "failed", "rejected", "suspended", "disqualified":
+		return BackgroundCheckFailed, true
+	}
+	return "", false
+}
+
+// IsFinal reports whether the provider has finished the check
+func (s BackgroundCheckStatus) IsFinal() bool {
+	return s != BackgroundCheckPending
+}
+
+// DefaultRecheckInterval applies in markets without a specific rule
+const DefaultRecheckInterval = 365 * 24 * time.Hour
+
+// RecheckLeadTime is how far ahead of expiry a re-check is requested, so a
+// driver in good standing is not blocked while the provider works
+const RecheckLeadTime = 14 * 24 * time.Hour
+
+// RecheckIntervals is how long a clear check remains valid, by market (ISO
+// country code), following each regulator's renewal requirement
+var RecheckIntervals = map[string]time.Duration{
+	"NG": 365 * 24 * time.Hour,
+	"KE": 365 * 24 * time.Hour,
+	"GH": 365 * 24 * time.Hour,
+	"ZA": 365 * 24 * time.Hour,
+	"UG": 2 * 365 * 24 * time.Hour,
+	"TZ": 2 * 365 * 24 * time.Hour,
+	"RW": 2 * 365 * 24 * time.Hour,
+}
+
+// RecheckInterval returns the validity period of a clear check in a market
+func RecheckInterval(country string) time.Duration {
+	if interval, ok := RecheckIntervals[strings.ToUpper(country)]; ok {
+		return interval
+	}
+	return DefaultRecheckInterval
+}
+
+// BackgroundCheck is a driver's background check with an external provider
+type BackgroundCheck struct {
+	ID          uuid.UUID             `json:"id"`
+	DriverID    uuid.UUID             `json:"driver_id"`
+	Provider    string                `json:"provider"`
+	ExternalID  string                `json:"external_id"`
+	Country     string                `json:"country"`
+	Status      BackgroundCheckStatus `json:"status"`
+	Detail      string                `json:"detail,omitempty"`
+	CompletedAt *time.Time            `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time            `json:"expires_at,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+// ApplyResult records a provider result, setting the expiry of a clear check
+// from the market's re-check interval
+func (c *BackgroundCheck) ApplyResult(status BackgroundCheckStatus, detail string, completedAt time.Time) {
+	c.Status = status
+	c.Detail = detail
+	c.UpdatedAt = time.Now().UTC()
+	c.ExpiresAt = nil
+	c.CompletedAt = nil
+
+	if status.IsFinal() {
+		c.CompletedAt = &completedAt
+	}
+	if status == BackgroundCheckClear {
+		expiresAt := completedAt.Add(RecheckInterval(c.Country))
+		c.ExpiresAt = &expiresAt
+	}
+}
+
+// IsCleared reports whether the check permits the driver to work at the given time
+func (c *BackgroundCheck) IsCleared(now time.Time) bool {
+	if c.Status != BackgroundCheckClear {
+		return false
+	}
+	return c.ExpiresAt == nil || now.Before(*c.ExpiresAt)
+}
+
+// BackgroundCheckWebhook is the normalized payload accepted from providers
+type BackgroundCheckWebhook struct {
+	CheckID     string    `json:"check_id"`
+	DriverID    uuid.UUID `json:"driver_id"`
+	Country     string    `json:"country"`
+	Status      string    `json:"status"`
+	Detail      string    `json:"detail,omitempty"`
+	CompletedAt time.Time `json:"completed_at"`
+}
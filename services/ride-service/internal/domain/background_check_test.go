@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackgroundCheckApplyResult(t *testing.T) {
+	completedAt := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		country     string
+		status      BackgroundCheckStatus
+		at          time.Time
+		wantCleared bool
+	}{
+		{"clear check is valid within interval", "NG", BackgroundCheckClear, completedAt.AddDate(0, 11, 0), true},
+		{"clear check lapses after market interval", "NG", BackgroundCheckClear, completedAt.AddDate(1, 0, 1), false},
+		{"longer interval market stays valid", "RW", BackgroundCheckClear, completedAt.AddDate(1, 6, 0), true},
+		{"flagged check never clears", "KE", BackgroundCheckConsider, completedAt, false},
+		{"failed check never clears", "KE", BackgroundCheckFailed, completedAt, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := &BackgroundCheck{Country: tt.country, Status: BackgroundCheckPending}
+			check.ApplyResult(tt.status, "", completedAt)
+
+			if got := check.IsCleared(tt.at); got != tt.wantCleared {
+				t.Errorf("IsCleared() = %v, want %v", got, tt.wantCleared)
+			}
+			if (check.ExpiresAt != nil) != (tt.status == BackgroundCheckClear) {
+				t.Errorf("ExpiresAt set = %v for status %s", check.ExpiresAt != nil, tt.status)
+			}
+		})
+	}
+}
+
+func TestParseBackgroundCheckStatus(t *testing.T) {
+	tests := map[string]BackgroundCheckStatus{
+		"clear":       BackgroundCheckClear,
+		"APPROVED":    BackgroundCheckClear,
+		"in_progress": BackgroundCheckPending,
+		"consider":    BackgroundCheckConsider,
+		"rejected":    BackgroundCheckFailed,
+	}
+
+	for input, want := range tests {
+		if got, ok := ParseBackgroundCheckStatus(input); !ok || got != want {
+			t.Errorf("ParseBackgroundCheckStatus(%q) = %s, %v; want %s", input, got, ok, want)
+		}
+	}
+
+	if _, ok := ParseBackgroundCheckStatus("unknown"); ok {
+		t.Error("expected unknown provider status to be rejected")
+	}
+}
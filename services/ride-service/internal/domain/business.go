@@ -0,0 +1,271 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ride metadata keys set on rides taken on a business profile
+const (
+	BusinessAccountMetadataKey = "business_account_id"
+	CostCenterMetadataKey      = "cost_center"
+)
+
+// BusinessMemberRole is what a rider may do on a business account
+type BusinessMemberRole string
+
+const (
+	BusinessRoleAdmin  BusinessMemberRole = "ADMIN" // may also read the account's statements
+	BusinessRoleMember BusinessMemberRole = "MEMBER"
+)
+
+// BusinessRideWindow is a span of local pickup time business rides are
+// allowed in. A window ending before it starts runs past midnight.
+type BusinessRideWindow struct {
+	Days        []time.Weekday `json:"days,omitempty"` // local pickup days, 0 for Sunday; every day when empty
+	StartMinute int            `json:"start_minute"`   // minutes after local midnight, inclusive
+	EndMinute   int            `json:"end_minute"`     // minutes after local midnight, exclusive
+}
+
+// Contains reports whether a local pickup time falls in the window. Past
+// midnight, an overnight window still belongs to the day it started on.
+func (w BusinessRideWindow) Contains(local time.Time) bool {
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+	switch {
+	case w.StartMinute < w.EndMinute:
+		if minute < w.StartMinute || minute >= w.EndMinute {
+			return false
+		}
+	case minute >= w.StartMinute:
+	case minute < w.EndMinute:
+		day = (day + 6) % 7
+	default:
+		return false
+	}
+
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// BusinessRidePolicy limits the rides members can take on an account.
+// Empty fields do not restrict.
+type BusinessRidePolicy struct {
+	RideTypes []RideType           `json:"ride_types,omitempty"`
+	Windows   []BusinessRideWindow `json:"windows,omitempty"`
+}
+
+// BusinessAccount is an organization its members can ride on. Rides are
+// charged to one of its cost centers and, with monthly invoicing, billed to
+// the organization at the end of the month instead of paid by the rider.
+type BusinessAccount struct {
+	ID               uuid.UUID          `json:"id"`
+	Name             string             `json:"name"`
+	CostCenters      []string           `json:"cost_centers"`
+	MonthlyInvoicing bool               `json:"monthly_invoicing"`
+	Policy           BusinessRidePolicy `json:"policy"`
+	Active           bool               `json:"active"`
+	CreatedBy        uuid.UUID          `json:"created_by"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+// Validate checks an account is well formed, trimming its cost centers
+func (a *BusinessAccount) Validate() error {
+	a.Name = strings.TrimSpace(a.Name)
+	if a.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidBusinessAccount)
+	}
+	seen := make(map[string]bool, len(a.CostCenters))
+	for i, center := range a.CostCenters {
+		center = strings.TrimSpace(center)
+		if center == "" || seen[center] {
+			return fmt.Errorf("%w: cost centers must be non-empty and unique", ErrInvalidBusinessAccount)
+		}
+		seen[center] = true
+		a.CostCenters[i] = center
+	}
+	for _, rideType := range a.Policy.RideTypes {
+		switch rideType {
+		case RideTypeStandard, RideTypePremium, RideTypeXL, RideTypeBoda, RideTypeTricycle, RideTypePool:
+		default:
+			return fmt.Errorf("%w: unknown ride type %q", ErrInvalidBusinessAccount, rideType)
+		}
+	}
+	for _, w := range a.Policy.Windows {
+		if w.StartMinute < 0 || w.StartMinute >= 24*60 || w.EndMinute < 0 || w.EndMinute > 24*60 || w.StartMinute == w.EndMinute {
+			return fmt.Errorf("%w: windows must start and end on different minutes of the day", ErrInvalidBusinessAccount)
+		}
+		for _, d := range w.Days {
+			if d < time.Sunday || d > time.Saturday {
+				return fmt.Errorf("%w: window days must be between 0 and 6", ErrInvalidBusinessAccount)
+			}
+		}
+	}
+	return nil
+}
+
+// HasCostCenter reports whether rides can be charged to a cost center
+func (a *BusinessAccount) HasCostCenter(center string) bool {
+	for _, c := range a.CostCenters {
+		if c == center {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRide reports why a ride cannot be taken on the account, or nil if
+// it can. local is the pickup time in the pickup city's timezone.
+func (a *BusinessAccount) CheckRide(rideType RideType, costCenter string, local time.Time) error {
+	if !a.Active {
+		return fmt.Errorf("%w: the account is suspended", ErrBusinessRideNotAllowed)
+	}
+	if costCenter == "" && len(a.CostCenters) > 0 {
+		return fmt.Errorf("%w: a cost center is required", ErrInvalidCostCenter)
+	}
+	if costCenter != "" && !a.HasCostCenter(costCenter) {
+		return fmt.Errorf("%w: %q is not one of the account's cost centers", ErrInvalidCostCenter, costCenter)
+	}
+
+	if len(a.Policy.RideTypes) > 0 {
+		allowed := false
+		for _, t := range a.Policy.RideTypes {
+			allowed = allowed || t == rideType
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %s rides are not allowed", ErrBusinessRideNotAllowed, rideType)
+		}
+	}
+	if len(a.Policy.Windows) > 0 {
+		allowed := false
+		for _, w := range a.Policy.Windows {
+			allowed = allowed || w.Contains(local)
+		}
+		if !allowed {
+			return fmt.Errorf("%w: rides are not allowed at this time", ErrBusinessRideNotAllowed)
+		}
+	}
+	return nil
+}
+
+// BusinessMember is a rider who can ride on a business account
+type BusinessMember struct {
+	AccountID         uuid.UUID          `json:"account_id"`
+	RiderID           uuid.UUID          `json:"rider_id"`
+	Role              BusinessMemberRole `json:"role"`
+	DefaultCostCenter string             `json:"default_cost_center,omitempty"` // used when a request names none
+	CreatedAt         time.Time          `json:"created_at"`
+}
+
+// Validate checks a membership fits its account
+func (m *BusinessMember) Validate(account *BusinessAccount) error {
+	if m.RiderID == uuid.Nil {
+		return fmt.Errorf("%w: rider_id is required", ErrInvalidBusinessAccount)
+	}
+	if m.Role != BusinessRoleAdmin && m.Role != BusinessRoleMember {
+		return fmt.Errorf("%w: role must be ADMIN or MEMBER", ErrInvalidBusinessAccount)
+	}
+	m.DefaultCostCenter = strings.TrimSpace(m.DefaultCostCenter)
+	if m.DefaultCostCenter != "" && !account.HasCostCenter(m.DefaultCostCenter) {
+		return fmt.Errorf("%w: %q is not one of the account's cost centers", ErrInvalidCostCenter, m.DefaultCostCenter)
+	}
+	return nil
+}
+
+// BusinessProfile is a business account as one of its members sees it
+type BusinessProfile struct {
+	Account           *BusinessAccount   `json:"account"`
+	Role              BusinessMemberRole `json:"role"`
+	DefaultCostCenter string             `json:"default_cost_center,omitempty"`
+}
+
+// BusinessRide records a ride taken on a business account
+type BusinessRide struct {
+	RideID           uuid.UUID `json:"ride_id"`
+	AccountID        uuid.UUID `json:"account_id"`
+	RiderID          uuid.UUID `json:"rider_id"`
+	CostCenter       string    `json:"cost_center,omitempty"`
+	MonthlyInvoicing bool      `json:"monthly_invoicing"` // billed to the account rather than paid by the rider
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// BusinessStatementLine is a completed ride on a monthly statement
+type BusinessStatementLine struct {
+	RideID      uuid.UUID `json:"ride_id"`
+	RiderID     uuid.UUID `json:"rider_id"`
+	CostCenter  string    `json:"cost_center,omitempty"`
+	Type        RideType  `json:"type"`
+	CompletedAt time.Time `json:"completed_at"`
+	Total       int64     `json:"total"`
+	Currency    Currency  `json:"currency"`
+	Invoiced    bool      `json:"invoiced"` // on the account's monthly invoice
+}
+
+// BusinessStatementTotal sums a cost center's rides in one currency
+type BusinessStatementTotal struct {
+	CostCenter string   `json:"cost_center,omitempty"`
+	Currency   Currency `json:"currency"`
+	Rides      int      `json:"rides"`
+	Amount     int64    `json:"amount"`
+	Invoiced   int64    `json:"invoiced"` // the part billed on the monthly invoice
+}
+
+// BusinessStatement is an account's completed rides for a calendar month
+// (UTC), totalled by cost center
+type BusinessStatement struct {
+	AccountID uuid.UUID                `json:"account_id"`
+	Month     string                   `json:"month"` // YYYY-MM
+	Lines     []BusinessStatementLine  `json:"lines"`
+	Totals    []BusinessStatementTotal `json:"totals"`
+}
+
+// NewBusinessStatement totals a month's lines by cost center and currency,
+// in the order they first appear
+func NewBusinessStatement(accountID uuid.UUID, month string, lines []BusinessStatementLine) *BusinessStatement {
+	statement := &BusinessStatement{
+		AccountID: accountID,
+		Month:     month,
+		Lines:     lines,
+		Totals:    make([]BusinessStatementTotal, 0),
+	}
+	if statement.Lines == nil {
+		statement.Lines = make([]BusinessStatementLine, 0)
+	}
+
+	index := make(map[[2]string]int)
+	for _, line := range lines {
+		key := [2]string{line.CostCenter, string(line.Currency)}
+		i, ok := index[key]
+		if !ok {
+			i = len(statement.Totals)
+			index[key] = i
+			statement.Totals = append(statement.Totals, BusinessStatementTotal{CostCenter: line.CostCenter, Currency: line.Currency})
+		}
+		statement.Totals[i].Rides++
+		statement.Totals[i].Amount += line.Total
+		if line.Invoiced {
+			statement.Totals[i].Invoiced += line.Total
+		}
+	}
+	return statement
+}
+
+// ParseStatementMonth parses a YYYY-MM month into the UTC span it covers
+func ParseStatementMonth(month string) (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidStatementMonth
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBusinessRideWindowContains(t *testing.T) {
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	office := BusinessRideWindow{Days: weekdays, StartMinute: 7 * 60, EndMinute: 19 * 60}
+	lateShift := BusinessRideWindow{Days: []time.Weekday{time.Friday}, StartMinute: 22 * 60, EndMinute: 2 * 60}
+
+	// 2026-10-16 is a Friday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		window BusinessRideWindow
+		local  time.Time
+		want   bool
+	}{
+		{"office start", office, at(16, 7, 0), true},
+		{"office end is exclusive", office, at(16, 19, 0), false},
+		{"office weekend", office, at(17, 9, 0), false},
+		{"any day", BusinessRideWindow{StartMinute: 0, EndMinute: 24 * 60}, at(18, 23, 59), true},
+		{"late shift evening", lateShift, at(16, 23, 30), true},
+		{"late shift after midnight", lateShift, at(17, 1, 30), true},
+		{"late shift ends", lateShift, at(17, 2, 0), false},
+		{"late shift wrong night", lateShift, at(17, 23, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.Contains(tt.local); got != tt.want {
+				t.Errorf("Contains(%s) = %v, want %v", tt.local, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBusinessAccountCheckRide(t *testing.T) {
+	account := &BusinessAccount{
+		Name:        "Acme",
+		CostCenters: []string{"SALES", "OPS"},
+		Policy: BusinessRidePolicy{
+			RideTypes: []RideType{RideTypeStandard, RideTypeXL},
+			Windows:   []BusinessRideWindow{{StartMinute: 6 * 60, EndMinute: 22 * 60}},
+		},
+		Active: true,
+	}
+	morning := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		active     bool
+		rideType   RideType
+		costCenter string
+		local      time.Time
+		want       error
+	}{
+		{"allowed", true, RideTypeStandard, "SALES", morning, nil},
+		{"suspended", false, RideTypeStandard, "SALES", morning, ErrBusinessRideNotAllowed},
+		{"no cost center", true, RideTypeStandard, "", morning, ErrInvalidCostCenter},
+		{"unknown cost center", true, RideTypeStandard, "LEGAL", morning, ErrInvalidCostCenter},
+		{"ride type not allowed", true, RideTypePremium, "OPS", morning, ErrBusinessRideNotAllowed},
+		{"outside hours", true, RideTypeXL, "OPS", morning.Add(15 * time.Hour), ErrBusinessRideNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account.Active = tt.active
+			err := account.CheckRide(tt.rideType, tt.costCenter, tt.local)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Errorf("CheckRide() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBusinessAccountValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		account BusinessAccount
+		wantErr bool
+	}{
+		{"valid", BusinessAccount{Name: "Acme", CostCenters: []string{" SALES "}}, false},
+		{"no name", BusinessAccount{Name: " "}, true},
+		{"duplicate cost center", BusinessAccount{Name: "Acme", CostCenters: []string{"OPS", "OPS "}}, true},
+		{"unknown ride type", BusinessAccount{Name: "Acme", Policy: BusinessRidePolicy{RideTypes: []RideType{"JET"}}}, true},
+		{"empty window", BusinessAccount{Name: "Acme", Policy: BusinessRidePolicy{Windows: []BusinessRideWindow{{StartMinute: 60, EndMinute: 60}}}}, true},
+		{"bad day", BusinessAccount{Name: "Acme", Policy: BusinessRidePolicy{Windows: []BusinessRideWindow{{Days: []time.Weekday{7}, StartMinute: 0, EndMinute: 60}}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.account.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidBusinessAccount) {
+				t.Errorf("Validate() error = %v, want ErrInvalidBusinessAccount", err)
+			}
+		})
+	}
+}
+
+func TestNewBusinessStatement(t *testing.T) {
+	lines := []BusinessStatementLine{
+		{RideID: uuid.New(), CostCenter: "SALES", Total: 50000, Currency: CurrencyKES, Invoiced: true},
+		{RideID: uuid.New(), CostCenter: "OPS", Total: 30000, Currency: CurrencyKES, Invoiced: true},
+		{RideID: uuid.New(), CostCenter: "SALES", Total: 20000, Currency: CurrencyKES},
+		{RideID: uuid.New(), CostCenter: "SALES", Total: 4000, Currency: CurrencyNGN, Invoiced: true},
+	}
+
+	statement := NewBusinessStatement(uuid.New(), "2026-10", lines)
+
+	want := []BusinessStatementTotal{
+		{CostCenter: "SALES", Currency: CurrencyKES, Rides: 2, Amount: 70000, Invoiced: 50000},
+		{CostCenter: "OPS", Currency: CurrencyKES, Rides: 1, Amount: 30000, Invoiced: 30000},
+		{CostCenter: "SALES", Currency: CurrencyNGN, Rides: 1, Amount: 4000, Invoiced: 4000},
+	}
+	if len(statement.Totals) != len(want) {
+		t.Fatalf("got %d totals, want %d", len(statement.Totals), len(want))
+	}
+	for i, total := range statement.Totals {
+		if total != want[i] {
+			t.Errorf("Totals[%d] = %+v, want %+v", i, total, want[i])
+		}
+	}
+}
+
+func TestParseStatementMonth(t *testing.T) {
+	from, to, err := ParseStatementMonth("2026-12")
+	if err != nil {
+		t.Fatalf("ParseStatementMonth() error = %v", err)
+	}
+	if !from.Equal(time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseStatementMonth() = %s, %s", from, to)
+	}
+	if _, _, err := ParseStatementMonth("2026-13"); err != ErrInvalidStatementMonth {
+		t.Errorf("ParseStatementMonth(2026-13) error = %v, want %v", err, ErrInvalidStatementMonth)
+	}
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Valid city codes are lowercase slugs, e.g. "lagos" or "dar-es-salaam"
+var cityCodePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{1,49}$`)
+
+// CityConfig is the launch bundle for one market city. Everything that varies
+// by city lives here, so opening a new market is a data change.
+type CityConfig struct {
+	Code         string          `json:"code"`
+	Name         string          `json:"name"`
+	Country      string          `json:"country"` // ISO 3166-1 alpha-2
+	Currency     Currency        `json:"currency"`
+	Timezone     string          `json:"timezone"`
+	Enabled      bool            `json:"enabled"`
+	H3Resolution int             `json:"h3_resolution"`
+	ServiceArea  CityServiceArea `json:"service_area"`
+	RideTypes    []RideType      `json:"ride_types"`
+	Pricing      CityPricing     `json:"pricing"`
+	Regulatory   CityRegulatory  `json:"regulatory"`
+	Offers       OfferDisclosure `json:"offer_disclosure"`
+	Weather      CityWeather     `json:"weather"`
+	Insurance    CityInsurance   `json:"insurance"`
+	UpdatedAt    time.Time       `json:"updated_at,omitempty"`
+}
+
+// CityServiceArea bounds where rides may start. A polygon, given as
+// [lat, lng] vertices, takes precedence over the center and radius.
+type CityServiceArea struct {
+	CenterLat float64      `json:"center_lat"`
+	CenterLng float64      `json:"center_lng"`
+	RadiusM   float64      `json:"radius_m,omitempty"`
+	Polygon   [][2]float64 `json:"polygon,omitempty"`
+}
+
+// CityPricing holds fares in the smallest currency unit
+type CityPricing struct {
+	RideTypes         map[RideType]RideTypeFares `json:"ride_types"`
+	BookingFee        int64                      `json:"booking_fee"`
+	StopFee           int64                      `json:"stop_fee,omitempty"` // per stop on the way to the dropoff
+	CommissionPercent float64                    `json:"commission_percent"`
+}
+
+// RideTypeFares are the fare components for one ride type. Waiting at
+// pickup is billed per minute past the free minutes; a zero wait rate
+// leaves it unbilled.
+type RideTypeFares struct {
+	BaseFare          int64 `json:"base_fare"`
+	PerKmRate         int64 `json:"per_km_rate"`
+	PerMinuteRate     int64 `json:"per_minute_rate"`
+	MinFare           int64 `json:"min_fare"`
+	WaitPerMinuteRate int64 `json:"wait_per_minute_rate,omitempty"`
+	FreeWaitMinutes   int   `json:"free_wait_minutes,omitempty"`
+}
+
+// CityRegulatory holds rules set by the city's regulator. Price controls are
+// enforced as a final clamp on every fare; a zero max surge means the
+// platform default.
+type CityRegulatory struct {
+	PriceControls
+	CashPaymentsAllowed bool `json:"cash_payments_allowed"`
+}
+
+// SupportsRideType reports whether the ride type is offered in the city
+func (c *CityConfig) SupportsRideType(rideType RideType) bool {
+	for _, t := range c.RideTypes {
+		if t == rideType {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the bundle is complete enough to serve rides
+func (c *CityConfig) Validate() error {
+	c.Code = strings.ToLower(strings.TrimSpace(c.Code))
+	c.Country = strings.ToUpper(strings.TrimSpace(c.Country))
+
+	switch {
+	case !cityCodePattern.MatchString(c.Code):
+		return fmt.Errorf("invalid city code %q", c.Code)
+	case c.Name == "":
+		return fmt.Errorf("city %s: name is required", c.Code)
+	case len(c.Country) != 2:
+		return fmt.Errorf("city %s: country must be an ISO 3166-1 alpha-2 code", c.Code)
+	case len(c.Currency) != 3:
+		return fmt.Errorf("city %s: currency must be an ISO 4217 code", c.Code)
+	case c.Timezone == "":
+		return fmt.Errorf("city %s: timezone is required", c.Code)
+	case c.H3Resolution < 0 || c.H3Resolution > 15:
+		return fmt.Errorf("city %s: h3_resolution must be between 0 and 15", c.Code)
+	case len(c.RideTypes) == 0:
+		return fmt.Errorf("city %s: at least one ride type is required", c.Code)
+	}
+
+	if err := c.Regulatory.PriceControls.Validate(); err != nil {
+		return fmt.Errorf("city %s: %w", c.Code, err)
+	}
+
+	if err := c.Offers.Validate(); err != nil {
+		return fmt.Errorf("city %s: %w", c.Code, err)
+	}
+
+	if err := c.Weather.Validate(); err != nil {
+		return fmt.Errorf("city %s: %w", c.Code, err)
+	}
+
+	if err := c.Insurance.Validate(); err != nil {
+		return fmt.Errorf("city %s: %w", c.Code, err)
+	}
+
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("city %s: unknown timezone %q", c.Code, c.Timezone)
+	}
+
+	area := c.ServiceArea
+	if len(area.Polygon) == 0 && area.RadiusM <= 0 {
+		return fmt.Errorf("city %s: service area needs a polygon or a radius", c.Code)
+	}
+	if len(area.Polygon) > 0 && len(area.Polygon) < 3 {
+		return fmt.Errorf("city %s: service area polygon needs at least 3 vertices", c.Code)
+	}
+
+	for _, rideType := range c.RideTypes {
+		fares, ok := c.Pricing.RideTypes[rideType]
+		if !ok {
+			return fmt.Errorf("city %s: no pricing for ride type %s", c.Code, rideType)
+		}
+		if fares.BaseFare < 0 || fares.PerKmRate < 0 || fares.PerMinuteRate < 0 || fares.MinFare < 0 ||
+			fares.WaitPerMinuteRate < 0 || fares.FreeWaitMinutes < 0 {
+			return fmt.Errorf("city %s: fares for %s must not be negative", c.Code, rideType)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConsentPurpose is something a user's data is used for only with their
+// consent
+type ConsentPurpose string
+
+const (
+	ConsentPreciseLocation ConsentPurpose = "PRECISE_LOCATION"           // storing exact trip endpoints and GPS trails
+	ConsentPersonalization ConsentPurpose = "PREDICTION_PERSONALIZATION" // learning trip patterns and predicting from them
+	ConsentMarketing       ConsentPurpose = "MARKETING"                  // promotional messages
+)
+
+// ConsentPurposes lists every consent purpose
+var ConsentPurposes = []ConsentPurpose{ConsentPreciseLocation, ConsentPersonalization, ConsentMarketing}
+
+// consentPolicy is the current policy text version of a purpose, and
+// whether it is allowed before the user has chosen under that version.
+// Location storage and personalization keep the opt-out model location
+// history always had; marketing needs an explicit grant.
+type consentPolicy struct {
+	Version int
+	Default bool
+}
+
+var consentPolicies = map[ConsentPurpose]consentPolicy{
+	ConsentPreciseLocation: {Version: 1, Default: true},
+	ConsentPersonalization: {Version: 1, Default: true},
+	ConsentMarketing:       {Version: 1, Default: false},
+}
+
+// IsValid reports whether the purpose is known
+func (p ConsentPurpose) IsValid() bool {
+	_, ok := consentPolicies[p]
+	return ok
+}
+
+// CurrentVersion is the version of the purpose's policy text users consent to
+func (p ConsentPurpose) CurrentVersion() int {
+	return consentPolicies[p].Version
+}
+
+// Consent is a user's recorded choice for one purpose
+type Consent struct {
+	UserID    uuid.UUID      `json:"user_id"`
+	Purpose   ConsentPurpose `json:"purpose"`
+	Granted   bool           `json:"granted"`
+	Version   int            `json:"version"` // the policy version the user was shown
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// ConsentChoice is a user granting or withdrawing consent for a purpose
+// under the policy version they were shown
+type ConsentChoice struct {
+	Purpose ConsentPurpose `json:"purpose"`
+	Granted bool           `json:"granted"`
+	Version int            `json:"version"`
+}
+
+// NewConsent records a user's choice. The version must be one the purpose
+// has had; grants under an older version stop counting once it changes.
+func NewConsent(userID uuid.UUID, choice ConsentChoice, now time.Time) (*Consent, error) {
+	if !choice.Purpose.IsValid() || choice.Version < 1 || choice.Version > choice.Purpose.CurrentVersion() {
+		return nil, ErrInvalidConsent
+	}
+	return &Consent{
+		UserID:    userID,
+		Purpose:   choice.Purpose,
+		Granted:   choice.Granted,
+		Version:   choice.Version,
+		UpdatedAt: now,
+	}, nil
+}
+
+// ConsentStatus is whether a purpose is allowed for a user, with their
+// recorded choice if they made one
+type ConsentStatus struct {
+	Purpose        ConsentPurpose `json:"purpose"`
+	Allowed        bool           `json:"allowed"`
+	CurrentVersion int            `json:"current_version"`
+	Consent        *Consent       `json:"consent,omitempty"`
+}
+
+// ConsentSet is a user's recorded consents by purpose. A nil set has none,
+// so every purpose takes its default.
+type ConsentSet struct {
+	UserID   uuid.UUID
+	Consents map[ConsentPurpose]*Consent
+}
+
+// NewConsentSet indexes a user's consents by purpose
+func NewConsentSet(userID uuid.UUID, consents []*Consent) *ConsentSet {
+	set := &ConsentSet{UserID: userID, Consents: make(map[ConsentPurpose]*Consent, len(consents))}
+	for _, c := range consents {
+		set.Consents[c.Purpose] = c
+	}
+	return set
+}
+
+// Allows reports whether the user's data may be used for the purpose. A
+// withdrawal always holds; a grant holds only under the current policy
+// version, otherwise the purpose's default applies until they choose again.
+func (s *ConsentSet) Allows(purpose ConsentPurpose) bool {
+	var c *Consent
+	if s != nil {
+		c = s.Consents[purpose]
+	}
+	switch {
+	case c == nil:
+		return consentPolicies[purpose].Default
+	case !c.Granted:
+		return false
+	case c.Version < purpose.CurrentVersion():
+		return consentPolicies[purpose].Default
+	}
+	return true
+}
+
+// Statuses lists the user's consent status for every purpose
+func (s *ConsentSet) Statuses() []*ConsentStatus {
+	statuses := make([]*ConsentStatus, 0, len(ConsentPurposes))
+	for _, p := range ConsentPurposes {
+		status := &ConsentStatus{
+			Purpose:        p,
+			Allowed:        s.Allows(p),
+			CurrentVersion: p.CurrentVersion(),
+		}
+		if s != nil {
+			status.Consent = s.Consents[p]
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// NotificationCategoryMarketing marks a push as promotional in its data
+// payload's "category"; it is sent only to users who consented to marketing
+const NotificationCategoryMarketing = "MARKETING"
+
+// NotificationConsent is the consent a push needs, from its data payload
+func NotificationConsent(data map[string]string) (ConsentPurpose, bool) {
+	if data["category"] == NotificationCategoryMarketing {
+		return ConsentMarketing, true
+	}
+	return "", false
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewConsent(t *testing.T) {
+	tests := []struct {
+		name   string
+		choice ConsentChoice
+		want   error
+	}{
+		{"current version", ConsentChoice{Purpose: ConsentMarketing, Granted: true, Version: 1}, nil},
+		{"unknown purpose", ConsentChoice{Purpose: "PROFILING", Granted: true, Version: 1}, ErrInvalidConsent},
+		{"missing version", ConsentChoice{Purpose: ConsentMarketing, Granted: true}, ErrInvalidConsent},
+		{"future version", ConsentChoice{Purpose: ConsentMarketing, Granted: true, Version: 2}, ErrInvalidConsent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewConsent(uuid.New(), tt.choice, time.Now()); err != tt.want {
+				t.Errorf("NewConsent() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsentSetAllows(t *testing.T) {
+	userID := uuid.New()
+
+	var none *ConsentSet
+	if !none.Allows(ConsentPreciseLocation) || !none.Allows(ConsentPersonalization) {
+		t.Error("location purposes should default to allowed")
+	}
+	if none.Allows(ConsentMarketing) {
+		t.Error("marketing should need an explicit grant")
+	}
+
+	set := NewConsentSet(userID, []*Consent{
+		{UserID: userID, Purpose: ConsentPreciseLocation, Granted: false, Version: 1},
+		{UserID: userID, Purpose: ConsentMarketing, Granted: true, Version: 1},
+	})
+	if set.Allows(ConsentPreciseLocation) {
+		t.Error("withdrawn consent still allowed")
+	}
+	if !set.Allows(ConsentMarketing) {
+		t.Error("granted consent not allowed")
+	}
+
+	// A grant under an outdated policy falls back to the default
+	set.Consents[ConsentMarketing].Version = 0
+	if set.Allows(ConsentMarketing) {
+		t.Error("outdated marketing grant still allowed")
+	}
+
+	statuses := set.Statuses()
+	if len(statuses) != len(ConsentPurposes) {
+		t.Fatalf("got %d statuses, want one per purpose", len(statuses))
+	}
+	if statuses[1].Consent != nil || !statuses[1].Allowed {
+		t.Errorf("personalization status = %+v, want the default with no recorded choice", statuses[1])
+	}
+}
+
+func TestNotificationConsent(t *testing.T) {
+	if p, ok := NotificationConsent(map[string]string{"type": "RETENTION_NUDGE", "category": NotificationCategoryMarketing}); !ok || p != ConsentMarketing {
+		t.Errorf("marketing push needs %q, %v", p, ok)
+	}
+	if _, ok := NotificationConsent(map[string]string{"type": "SCHEDULED_RIDE_REMINDER"}); ok {
+		t.Error("transactional push should not need consent")
+	}
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DisputeStatus represents the lifecycle of a fare dispute
+type DisputeStatus string
+
+const (
+	DisputeStatusOpen         DisputeStatus = "OPEN"
+	DisputeStatusAutoResolved DisputeStatus = "AUTO_RESOLVED"
+	DisputeStatusResolved     DisputeStatus = "RESOLVED"
+	DisputeStatusRejected     DisputeStatus = "REJECTED"
+)
+
+// DisputeReason is the rider's reason for contesting a fare
+type DisputeReason string
+
+const (
+	DisputeReasonOvercharged  DisputeReason = "OVERCHARGED"
+	DisputeReasonLongRoute    DisputeReason = "LONG_ROUTE"
+	DisputeReasonWrongDropoff DisputeReason = "WRONG_DROPOFF"
+	DisputeReasonSurge        DisputeReason = "SURGE_PRICING"
+	DisputeReasonTripNotTaken DisputeReason = "TRIP_NOT_TAKEN"
+	DisputeReasonOther        DisputeReason = "OTHER"
+)
+
+// IsValid reports whether the reason is known
+func (r DisputeReason) IsValid() bool {
+	switch r {
+	case DisputeReasonOvercharged, DisputeReasonLongRoute, DisputeReasonWrongDropoff,
+		DisputeReasonSurge, DisputeReasonTripNotTaken, DisputeReasonOther:
+		return true
+	}
+	return false
+}
+
+// ReportsDriver reports whether the reason is a complaint about how the
+// driver handled the trip rather than about its price
+func (r DisputeReason) ReportsDriver() bool {
+	switch r {
+	case DisputeReasonLongRoute, DisputeReasonWrongDropoff, DisputeReasonTripNotTaken:
+		return true
+	}
+	return false
+}
+
+const (
+	// DisputeWindow is how long after completion a rider may dispute a fare
+	DisputeWindow = 7 * 24 * time.Hour
+
+	// DisputeSLA is the time support has to resolve a queued dispute
+	DisputeSLA = 48 * time.Hour
+
+	// DisputeAutoResolveOverbilling is the fraction by which billed distance
+	// must exceed the GPS-measured distance for a dispute to resolve automatically
+	DisputeAutoResolveOverbilling = 0.20
+
+	// DisputeMinTrailPoints is the fewest GPS points needed to trust the trail
+	DisputeMinTrailPoints = 10
+)
+
+// TrailPoint is a single GPS fix recorded during a ride
+type TrailPoint struct {
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	Speed      float64   `json:"speed"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// DisputeEvidence is the system-gathered evidence attached to a dispute
+type DisputeEvidence struct {
+	Trail           []TrailPoint `json:"trail"`
+	BilledDistanceM float64      `json:"billed_distance_meters"`
+	ActualDistanceM float64      `json:"actual_distance_meters"`
+	StraightLineM   float64      `json:"straight_line_meters"`
+	DistanceOverPct float64      `json:"distance_over_percent"`
+	BilledDurationS int64        `json:"billed_duration_seconds"`
+	ActualDurationS int64        `json:"actual_duration_seconds"`
+	BilledFare      int64        `json:"billed_fare"`
+	RefundedFare    int64        `json:"refunded_fare"` // refunded before the dispute was opened
+	RecomputedFare  int64        `json:"recomputed_fare"`
+	FareDifference  int64        `json:"fare_difference"`
+	Currency        Currency     `json:"currency"`
+}
+
+// IsClearOverbilling reports whether the evidence alone justifies a refund:
+// a trustworthy GPS trail and billed distance well above what was driven
+func (e *DisputeEvidence) IsClearOverbilling() bool {
+	if len(e.Trail) < DisputeMinTrailPoints || e.ActualDistanceM <= 0 {
+		return false
+	}
+	return e.BilledDistanceM > e.ActualDistanceM*(1+DisputeAutoResolveOverbilling) && e.FareDifference > 0
+}
+
+// FareDispute is a rider's challenge to the fare charged for a ride
+type FareDispute struct {
+	ID         uuid.UUID        `json:"id"`
+	RideID     uuid.UUID        `json:"ride_id"`
+	RiderID    uuid.UUID        `json:"rider_id"`
+	Reason     DisputeReason    `json:"reason"`
+	Comment    string           `json:"comment,omitempty"`
+	Status     DisputeStatus    `json:"status"`
+	Evidence   *DisputeEvidence `json:"evidence,omitempty"`
+	Resolution string           `json:"resolution,omitempty"`
+	RefundID   *uuid.UUID       `json:"refund_id,omitempty"`
+	SLADueAt   time.Time        `json:"sla_due_at"`
+	ResolvedBy *uuid.UUID       `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time       `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+// IsOverdue reports whether an open dispute has breached its SLA
+func (d *FareDispute) IsOverdue(now time.Time) bool {
+	return d.Status == DisputeStatusOpen && now.After(d.SLADueAt)
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "testing"
+
+func TestDisputeEvidenceIsClearOverbilling(t *testing.T) {
+	trail := make([]TrailPoint, DisputeMinTrailPoints)
+
+	tests := []struct {
+		name     string
+		evidence DisputeEvidence
+		want     bool
+	}{
+		{"billed well over the trail", DisputeEvidence{Trail: trail, BilledDistanceM: 13000, ActualDistanceM: 10000, FareDifference: 30000}, true},
+		{"billed within the margin", DisputeEvidence{Trail: trail, BilledDistanceM: 11900, ActualDistanceM: 10000, FareDifference: 15000}, false},
+		{"trail too sparse", DisputeEvidence{Trail: trail[:DisputeMinTrailPoints-1], BilledDistanceM: 13000, ActualDistanceM: 10000, FareDifference: 30000}, false},
+		{"no distance on the trail", DisputeEvidence{Trail: trail, BilledDistanceM: 13000, FareDifference: 30000}, false},
+		{"difference already refunded", DisputeEvidence{Trail: trail, BilledDistanceM: 13000, ActualDistanceM: 10000}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.evidence.IsClearOverbilling(); got != tt.want {
+				t.Errorf("IsClearOverbilling() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
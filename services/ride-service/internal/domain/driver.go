@@ -15,8 +15,18 @@ const (
 	DriverStatusOnline    DriverStatus = "ONLINE"
 	DriverStatusBusy      DriverStatus = "BUSY"
 	DriverStatusOnRide    DriverStatus = "ON_RIDE"
+	
+	// Restricted states set by trust & safety; see DriverSanction
+	DriverStatusSuspended   DriverStatus = "SUSPENDED"
+	DriverStatusDeactivated DriverStatus = "DEACTIVATED"
+	DriverStatusUnderReview DriverStatus = "UNDER_REVIEW"
 )
 
+// IsRestricted returns true if the status bars the driver from taking rides
+func (s DriverStatus) IsRestricted() bool {
+	return s == DriverStatusSuspended || s == DriverStatusDeactivated || s == DriverStatusUnderReview
+}
+
 // VehicleType represents the type of vehicle
 type VehicleType string
 
@@ -76,6 +86,7 @@ type Vehicle struct {
 	Color           string      `json:"color"`
 	LicensePlate    string      `json:"license_plate"`
 	Capacity        int         `json:"capacity"`
+	Powertrain      Powertrain  `json:"powertrain"`
 	
 	// Supported ride types
 	SupportedTypes  []RideType  `json:"supported_types"`
@@ -186,9 +197,9 @@ func GetVehicleTypes(vehicleType VehicleType) []RideType {
 	case VehicleTypeTricycle:
 		return []RideType{RideTypeTricycle}
 	case VehicleTypeCar:
-		return []RideType{RideTypeStandard, RideTypePremium}
+		return []RideType{RideTypeStandard, RideTypePremium, RideTypePool}
 	case VehicleTypeSUV, VehicleTypeVan:
-		return []RideType{RideTypeStandard, RideTypePremium, RideTypeXL}
+		return []RideType{RideTypeStandard, RideTypePremium, RideTypeXL, RideTypePool}
 	default:
 		return []RideType{RideTypeStandard}
 	}
@@ -0,0 +1,308 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultDriverSearchLimit and MaxDriverSearchLimit bound a page of
+	// driver search results
+	DefaultDriverSearchLimit = 20
+	MaxDriverSearchLimit     = 100
+
+	// minPhoneSearchDigits is the shortest phone fragment drivers are
+	// searched by, so a search cannot list every driver by one digit
+	minPhoneSearchDigits = 4
+)
+
+// DriverSearch finds drivers for ops by phone, licence plate, name, status
+// or the city they are licensed in. Criteria combine; a search with none
+// lists every driver.
+type DriverSearch struct {
+	Phone  string       `json:"phone,omitempty"`
+	Plate  string       `json:"plate,omitempty"`
+	Name   string       `json:"name,omitempty"`
+	Status DriverStatus `json:"status,omitempty"`
+	City   string       `json:"city,omitempty"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
+
+// Normalize puts the criteria in the form drivers are matched in and
+// checks them. Phones match on their trailing digits, so local numbers
+// drop their trunk zero to match numbers stored in international form.
+func (s *DriverSearch) Normalize() error {
+	if s.Phone != "" {
+		s.Phone = strings.TrimLeft(digitsOnly(s.Phone), "0")
+		if len(s.Phone) < minPhoneSearchDigits {
+			return fmt.Errorf("%w: phone needs at least %d digits", ErrInvalidDriverSearch, minPhoneSearchDigits)
+		}
+	}
+	s.Plate = NormalizePlate(s.Plate)
+	s.Name = strings.Join(strings.Fields(s.Name), " ")
+	s.City = strings.TrimSpace(s.City)
+	s.Status = DriverStatus(strings.ToUpper(string(s.Status)))
+	if s.Status != "" && !s.Status.IsValid() {
+		return fmt.Errorf("%w: unknown status %s", ErrInvalidDriverSearch, s.Status)
+	}
+
+	if s.Limit == 0 {
+		s.Limit = DefaultDriverSearchLimit
+	}
+	if s.Limit < 1 || s.Limit > MaxDriverSearchLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidDriverSearch, MaxDriverSearchLimit)
+	}
+	if s.Offset < 0 {
+		return fmt.Errorf("%w: offset cannot be negative", ErrInvalidDriverSearch)
+	}
+	return nil
+}
+
+// IsValid reports whether the status is known
+func (s DriverStatus) IsValid() bool {
+	switch s {
+	case DriverStatusOffline, DriverStatusOnline, DriverStatusBusy, DriverStatusOnRide,
+		DriverStatusSuspended, DriverStatusDeactivated, DriverStatusUnderReview:
+		return true
+	}
+	return false
+}
+
+// NormalizePlate reduces a licence plate to its upper-case letters and
+// digits, so "kcb 123a" and "KCB-123A" are the same plate
+func NormalizePlate(plate string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(plate) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// DriverAdminAction is a change ops make to a driver's record
+type DriverAdminAction string
+
+const (
+	// DriverAdminUpdateVehicle corrects the driver's active vehicle
+	DriverAdminUpdateVehicle DriverAdminAction = "UPDATE_VEHICLE"
+	// DriverAdminResetStatus frees a driver left on a ride that has ended
+	DriverAdminResetStatus DriverAdminAction = "RESET_STATUS"
+	// DriverAdminForceOffline takes an online driver out of matching
+	DriverAdminForceOffline DriverAdminAction = "FORCE_OFFLINE"
+)
+
+// DriverFieldChange is one field an admin action changed
+type DriverFieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// DriverAdminAudit records who changed a driver's record, how and why
+type DriverAdminAudit struct {
+	ID        uuid.UUID           `json:"id"`
+	DriverID  uuid.UUID           `json:"driver_id"`
+	Action    DriverAdminAction   `json:"action"`
+	Reason    string              `json:"reason"`
+	Changes   []DriverFieldChange `json:"changes"`
+	AdminID   uuid.UUID           `json:"admin_id"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+// NewDriverAdminAudit starts the audit entry for an action. Every action
+// needs a reason.
+func NewDriverAdminAudit(driverID, adminID uuid.UUID, action DriverAdminAction, reason string) (*DriverAdminAudit, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrDriverAdminReasonRequired
+	}
+	return &DriverAdminAudit{
+		ID:        uuid.New(),
+		DriverID:  driverID,
+		Action:    action,
+		Reason:    reason,
+		Changes:   []DriverFieldChange{},
+		AdminID:   adminID,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// VehicleUpdate corrects details of a driver's active vehicle. Fields left
+// out are kept.
+type VehicleUpdate struct {
+	Type         *VehicleType `json:"type,omitempty"`
+	Make         *string      `json:"make,omitempty"`
+	Model        *string      `json:"model,omitempty"`
+	Year         *int         `json:"year,omitempty"`
+	Color        *string      `json:"color,omitempty"`
+	LicensePlate *string      `json:"license_plate,omitempty"`
+	Capacity     *int         `json:"capacity,omitempty"`
+	Powertrain   *Powertrain  `json:"powertrain,omitempty"`
+}
+
+// Apply checks the update and applies it to the vehicle, returning the
+// fields it changed. A new vehicle type brings the ride types that type
+// supports.
+func (u *VehicleUpdate) Apply(v *Vehicle, now time.Time) ([]DriverFieldChange, error) {
+	var changes []DriverFieldChange
+	set := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, DriverFieldChange{Field: field, From: from, To: to})
+		}
+	}
+	text := func(field string, value *string, target *string) error {
+		if value == nil {
+			return nil
+		}
+		trimmed := strings.TrimSpace(*value)
+		if trimmed == "" {
+			return fmt.Errorf("%w: %s cannot be empty", ErrInvalidVehicleUpdate, field)
+		}
+		set(field, *target, trimmed)
+		*target = trimmed
+		return nil
+	}
+
+	if u.Type != nil {
+		vehicleType := VehicleType(strings.ToUpper(string(*u.Type)))
+		if !vehicleType.IsValid() {
+			return nil, fmt.Errorf("%w: unknown vehicle type %s", ErrInvalidVehicleUpdate, *u.Type)
+		}
+		if vehicleType != v.Type {
+			set("type", string(v.Type), string(vehicleType))
+			v.Type = vehicleType
+			v.SupportedTypes = GetVehicleTypes(vehicleType)
+		}
+	}
+	if err := text("make", u.Make, &v.Make); err != nil {
+		return nil, err
+	}
+	if err := text("model", u.Model, &v.Model); err != nil {
+		return nil, err
+	}
+	if err := text("color", u.Color, &v.Color); err != nil {
+		return nil, err
+	}
+	if u.Year != nil {
+		if *u.Year < 1980 || *u.Year > now.Year()+1 {
+			return nil, fmt.Errorf("%w: year must be between 1980 and %d", ErrInvalidVehicleUpdate, now.Year()+1)
+		}
+		set("year", strconv.Itoa(v.Year), strconv.Itoa(*u.Year))
+		v.Year = *u.Year
+	}
+	if u.LicensePlate != nil {
+		plate := NormalizePlate(*u.LicensePlate)
+		if len(plate) < 2 || len(plate) > 12 {
+			return nil, fmt.Errorf("%w: licence plate must be 2 to 12 letters and digits", ErrInvalidVehicleUpdate)
+		}
+		if plate != NormalizePlate(v.LicensePlate) {
+			set("license_plate", v.LicensePlate, plate)
+			v.LicensePlate = plate
+		}
+	}
+	if u.Capacity != nil {
+		if *u.Capacity < 1 || *u.Capacity > 20 {
+			return nil, fmt.Errorf("%w: capacity must be between 1 and 20", ErrInvalidVehicleUpdate)
+		}
+		set("capacity", strconv.Itoa(v.Capacity), strconv.Itoa(*u.Capacity))
+		v.Capacity = *u.Capacity
+	}
+	if u.Powertrain != nil {
+		powertrain := Powertrain(strings.ToUpper(string(*u.Powertrain)))
+		if !powertrain.IsValid() {
+			return nil, fmt.Errorf("%w: unknown powertrain %s", ErrInvalidVehicleUpdate, *u.Powertrain)
+		}
+		set("powertrain", string(v.Powertrain), string(powertrain))
+		v.Powertrain = powertrain
+	}
+
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("%w: nothing to change", ErrInvalidVehicleUpdate)
+	}
+	v.UpdatedAt = now
+	return changes, nil
+}
+
+// IsValid reports whether the vehicle type is known
+func (t VehicleType) IsValid() bool {
+	switch t {
+	case VehicleTypeCar, VehicleTypeSUV, VehicleTypeBike, VehicleTypeTricycle, VehicleTypeVan, VehicleTypeTruck:
+		return true
+	}
+	return false
+}
+
+// CheckStatusReset checks the driver's status can be reset: they are
+// recorded on a ride, or still pointing at one, that is no longer active
+func (d *Driver) CheckStatusReset(hasActiveRide bool) error {
+	switch {
+	case d.Status.IsRestricted():
+		return ErrDriverRestricted
+	case hasActiveRide:
+		return ErrDriverOnActiveRide
+	case d.Status != DriverStatusOnRide && d.CurrentRideID == nil:
+		return ErrDriverStatusNotStuck
+	}
+	return nil
+}
+
+// CheckForceOffline checks the driver can be forced offline: they are
+// online and not carrying out a ride
+func (d *Driver) CheckForceOffline(hasActiveRide bool) error {
+	switch {
+	case hasActiveRide:
+		return ErrDriverOnActiveRide
+	case d.Status != DriverStatusOnline && d.Status != DriverStatusBusy:
+		return ErrDriverNotOnline
+	}
+	return nil
+}
+
+// OfflineChanges lists what taking the driver offline changes on their
+// record, for the audit trail
+func (d *Driver) OfflineChanges() []DriverFieldChange {
+	changes := []DriverFieldChange{{Field: "status", From: string(d.Status), To: string(DriverStatusOffline)}}
+	if d.CurrentRideID != nil {
+		changes = append(changes, DriverFieldChange{Field: "current_ride_id", From: d.CurrentRideID.String()})
+	}
+	return changes
+}
+
+// DriverTripStats summarises a driver's recent trips
+type DriverTripStats struct {
+	Since           time.Time `json:"since"`
+	Assigned        int       `json:"assigned"`
+	Completed       int       `json:"completed"`
+	DriverCancelled int       `json:"driver_cancelled"`
+	CompletionRate  float64   `json:"completion_rate"`
+}
+
+// DriverProfile is everything ops see about a driver in one place. Parts
+// whose feature is not enabled are left out.
+type DriverProfile struct {
+	Driver       *Driver             `json:"driver"`
+	Standing     *DriverStanding     `json:"standing,omitempty"`
+	Quality      *QualityStatus      `json:"quality,omitempty"`
+	Documents    []*ExpiringDocument `json:"documents,omitempty"`
+	Stats        *DriverTripStats    `json:"stats"`
+	RecentTrips  []*Ride             `json:"recent_trips"`
+	Incidents    []*SafetyIncident   `json:"incidents"`
+	AdminActions []*DriverAdminAudit `json:"admin_actions"`
+}
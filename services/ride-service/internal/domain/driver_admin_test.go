@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDriverSearchNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		search  DriverSearch
+		want    DriverSearch
+		wantErr bool
+	}{
+		{
+			name:   "local phone drops trunk zero",
+			search: DriverSearch{Phone: "0712 345-678"},
+			want:   DriverSearch{Phone: "712345678", Limit: DefaultDriverSearchLimit},
+		},
+		{
+			name:   "plate, name and status",
+			search: DriverSearch{Plate: "kcb 123a", Name: "  Jane   Wanjiru ", Status: "online", City: " Nairobi ", Limit: 50},
+			want:   DriverSearch{Plate: "KCB123A", Name: "Jane Wanjiru", Status: DriverStatusOnline, City: "Nairobi", Limit: 50},
+		},
+		{name: "phone too short", search: DriverSearch{Phone: "+0 12"}, wantErr: true},
+		{name: "unknown status", search: DriverSearch{Status: "NAPPING"}, wantErr: true},
+		{name: "limit too high", search: DriverSearch{Limit: MaxDriverSearchLimit + 1}, wantErr: true},
+		{name: "negative offset", search: DriverSearch{Offset: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.search.Normalize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidDriverSearch) {
+					t.Errorf("Normalize() error = %v, want ErrInvalidDriverSearch", err)
+				}
+				return
+			}
+			if tt.search != tt.want {
+				t.Errorf("Normalize() = %+v, want %+v", tt.search, tt.want)
+			}
+		})
+	}
+}
+
+func TestVehicleUpdateApply(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+	suv := VehicleTypeSUV
+	diesel := Powertrain("DIESEL")
+
+	vehicle := func() *Vehicle {
+		return &Vehicle{
+			Type: VehicleTypeCar, Make: "Toyota", Model: "Axio", Year: 2014, Color: "Silver",
+			LicensePlate: "KCB123A", Capacity: 4, SupportedTypes: GetVehicleTypes(VehicleTypeCar),
+		}
+	}
+
+	t.Run("changes only what differs", func(t *testing.T) {
+		v := vehicle()
+		update := VehicleUpdate{Make: str(" Toyota "), Color: str("White"), LicensePlate: str("kcb-123a"), Year: num(2015)}
+		changes, err := update.Apply(v, now)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		want := []DriverFieldChange{
+			{Field: "color", From: "Silver", To: "White"},
+			{Field: "year", From: "2014", To: "2015"},
+		}
+		if len(changes) != len(want) {
+			t.Fatalf("Apply() changes = %+v, want %+v", changes, want)
+		}
+		for i := range want {
+			if changes[i] != want[i] {
+				t.Errorf("changes[%d] = %+v, want %+v", i, changes[i], want[i])
+			}
+		}
+		if !v.UpdatedAt.Equal(now) {
+			t.Errorf("UpdatedAt = %s, want %s", v.UpdatedAt, now)
+		}
+	})
+
+	t.Run("new type brings its ride types", func(t *testing.T) {
+		v := vehicle()
+		if _, err := (&VehicleUpdate{Type: &suv}).Apply(v, now); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if len(v.SupportedTypes) != len(GetVehicleTypes(VehicleTypeSUV)) {
+			t.Errorf("SupportedTypes = %v, want those of an SUV", v.SupportedTypes)
+		}
+	})
+
+	invalid := []struct {
+		name   string
+		update VehicleUpdate
+	}{
+		{"nothing changes", VehicleUpdate{Model: str("Axio")}},
+		{"empty make", VehicleUpdate{Make: str(" ")}},
+		{"future year", VehicleUpdate{Year: num(2028)}},
+		{"bad plate", VehicleUpdate{LicensePlate: str("-")}},
+		{"no capacity", VehicleUpdate{Capacity: num(0)}},
+		{"unknown powertrain", VehicleUpdate{Powertrain: &diesel}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.update.Apply(vehicle(), now); !errors.Is(err, ErrInvalidVehicleUpdate) {
+				t.Errorf("Apply() error = %v, want ErrInvalidVehicleUpdate", err)
+			}
+		})
+	}
+}
+
+func TestDriverAdminStatusChecks(t *testing.T) {
+	rideID := uuid.New()
+
+	tests := []struct {
+		name          string
+		driver        Driver
+		hasActiveRide bool
+		wantReset     error
+		wantOffline   error
+	}{
+		{"stuck on ended ride", Driver{Status: DriverStatusOnRide, CurrentRideID: &rideID}, false, nil, ErrDriverNotOnline},
+		{"online with stale ride", Driver{Status: DriverStatusOnline, CurrentRideID: &rideID}, false, nil, nil},
+		{"on active ride", Driver{Status: DriverStatusOnRide, CurrentRideID: &rideID}, true, ErrDriverOnActiveRide, ErrDriverOnActiveRide},
+		{"online and free", Driver{Status: DriverStatusOnline}, false, ErrDriverStatusNotStuck, nil},
+		{"busy", Driver{Status: DriverStatusBusy}, false, ErrDriverStatusNotStuck, nil},
+		{"offline", Driver{Status: DriverStatusOffline}, false, ErrDriverStatusNotStuck, ErrDriverNotOnline},
+		{"suspended", Driver{Status: DriverStatusSuspended}, false, ErrDriverRestricted, ErrDriverNotOnline},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.driver.CheckStatusReset(tt.hasActiveRide); err != tt.wantReset {
+				t.Errorf("CheckStatusReset() error = %v, want %v", err, tt.wantReset)
+			}
+			if err := tt.driver.CheckForceOffline(tt.hasActiveRide); err != tt.wantOffline {
+				t.Errorf("CheckForceOffline() error = %v, want %v", err, tt.wantOffline)
+			}
+		})
+	}
+}
+
+func TestNewDriverAdminAuditNeedsReason(t *testing.T) {
+	if _, err := NewDriverAdminAudit(uuid.New(), uuid.New(), DriverAdminForceOffline, "  "); err != ErrDriverAdminReasonRequired {
+		t.Errorf("NewDriverAdminAudit() error = %v, want %v", err, ErrDriverAdminReasonRequired)
+	}
+}
@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DocumentType is a licence, permit or certificate a driver must hold
+type DocumentType string
+
+const (
+	DocumentDriversLicense      DocumentType = "DRIVERS_LICENSE"
+	DocumentVehicleInsurance    DocumentType = "VEHICLE_INSURANCE"
+	DocumentVehicleRegistration DocumentType = "VEHICLE_REGISTRATION"
+	DocumentRoadworthiness      DocumentType = "ROADWORTHINESS"
+	DocumentOperatorPermit      DocumentType = "OPERATOR_PERMIT" // PSV badge, hackney permit or local equivalent
+)
+
+// IsValid reports whether the document type is known
+func (t DocumentType) IsValid() bool {
+	switch t {
+	case DocumentDriversLicense, DocumentVehicleInsurance, DocumentVehicleRegistration,
+		DocumentRoadworthiness, DocumentOperatorPermit:
+		return true
+	}
+	return false
+}
+
+// DocumentStatus is where a document stands against its expiry date
+type DocumentStatus string
+
+const (
+	DocumentValid    DocumentStatus = "VALID"
+	DocumentExpiring DocumentStatus = "EXPIRING" // within the first reminder of expiry
+	DocumentGrace    DocumentStatus = "GRACE"    // expired, but the driver may still work
+	DocumentExpired  DocumentStatus = "EXPIRED"
+)
+
+// DocumentExpiryReminders are the days before expiry a driver is reminded
+// to renew, earliest first
+var DocumentExpiryReminders = []int{30, 7, 1}
+
+// DocumentGracePeriods is how long a driver may keep working on an expired
+// document while the renewal is processed, by market (ISO country code)
+// and document type. Unlisted documents have no grace.
+type DocumentGracePeriods map[string]map[DocumentType]time.Duration
+
+// DefaultDocumentGracePeriods follow how long each regulator tolerates a
+// renewal in progress. Insurance never has grace: an uninsured trip is
+// not allowed anywhere.
+var DefaultDocumentGracePeriods = DocumentGracePeriods{
+	"NG": {DocumentDriversLicense: 30 * 24 * time.Hour, DocumentRoadworthiness: 14 * 24 * time.Hour},
+	"KE": {DocumentDriversLicense: 14 * 24 * time.Hour, DocumentOperatorPermit: 7 * 24 * time.Hour},
+	"GH": {DocumentDriversLicense: 30 * 24 * time.Hour, DocumentRoadworthiness: 7 * 24 * time.Hour},
+	"ZA": {DocumentOperatorPermit: 14 * 24 * time.Hour},
+	"UG": {DocumentDriversLicense: 14 * 24 * time.Hour},
+	"TZ": {DocumentDriversLicense: 14 * 24 * time.Hour},
+	"RW": {DocumentDriversLicense: 7 * 24 * time.Hour},
+}
+
+// GracePeriod returns the grace period for a document type in a market
+func (g DocumentGracePeriods) GracePeriod(country string, docType DocumentType) time.Duration {
+	return g[strings.ToUpper(country)][docType]
+}
+
+// ParseDocumentGraceDays overrides the default grace periods from
+// DOCUMENT_GRACE_DAYS, a comma separated list of COUNTRY:TYPE=DAYS entries
+// such as "NG:DRIVERS_LICENSE=14,KE:ROADWORTHINESS=0"
+func ParseDocumentGraceDays(spec string) (DocumentGracePeriods, error) {
+	periods := make(DocumentGracePeriods, len(DefaultDocumentGracePeriods))
+	for country, byType := range DefaultDocumentGracePeriods {
+		periods[country] = make(map[DocumentType]time.Duration, len(byType))
+		for docType, grace := range byType {
+			periods[country][docType] = grace
+		}
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(entry, "=")
+		country, docType, okKey := strings.Cut(key, ":")
+		country = strings.ToUpper(strings.TrimSpace(country))
+		t := DocumentType(strings.ToUpper(strings.TrimSpace(docType)))
+		days, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || !okKey || len(country) != 2 || !t.IsValid() || err != nil || days < 0 {
+			return nil, fmt.Errorf("invalid document grace period %q, want COUNTRY:TYPE=DAYS", entry)
+		}
+
+		if periods[country] == nil {
+			periods[country] = make(map[DocumentType]time.Duration)
+		}
+		periods[country][t] = time.Duration(days) * 24 * time.Hour
+	}
+	return periods, nil
+}
+
+// DriverDocument is a driver's current document of one type
+type DriverDocument struct {
+	ID        uuid.UUID    `json:"id"`
+	DriverID  uuid.UUID    `json:"driver_id"`
+	Type      DocumentType `json:"type"`
+	Country   string       `json:"country"`
+	City      string       `json:"city,omitempty"`
+	Number    string       `json:"number,omitempty"`
+	ExpiresAt time.Time    `json:"expires_at"`
+
+	// LastReminderDays is the last reminder sent for this expiry date, in
+	// days before expiry; zero when none has been sent
+	LastReminderDays int `json:"last_reminder_days,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Status returns where the document stands at the given time
+func (d *DriverDocument) Status(now time.Time, grace time.Duration) DocumentStatus {
+	switch {
+	case now.Before(d.ExpiresAt.AddDate(0, 0, -DocumentExpiryReminders[0])):
+		return DocumentValid
+	case now.Before(d.ExpiresAt):
+		return DocumentExpiring
+	case now.Before(d.ExpiresAt.Add(grace)):
+		return DocumentGrace
+	default:
+		return DocumentExpired
+	}
+}
+
+// PermitsWork reports whether the driver may work on the document at the
+// given time
+func (d *DriverDocument) PermitsWork(now time.Time, grace time.Duration) bool {
+	return d.Status(now, grace) != DocumentExpired
+}
+
+// DueReminder returns the reminder due at the given time, in days before
+// expiry. Only the nearest reminder is sent, so a driver who uploads a
+// document expiring in five days is reminded once, not twice.
+func (d *DriverDocument) DueReminder(now time.Time) (int, bool) {
+	if !now.Before(d.ExpiresAt) {
+		return 0, false
+	}
+	due := 0
+	for _, days := range DocumentExpiryReminders {
+		if !now.Before(d.ExpiresAt.AddDate(0, 0, -days)) {
+			due = days
+		}
+	}
+	if due == 0 || (d.LastReminderDays != 0 && due >= d.LastReminderDays) {
+		return 0, false
+	}
+	return due, true
+}
+
+// ExpiringDocument is a row of the expiring-documents report
+type ExpiringDocument struct {
+	DriverDocument
+	Status      DocumentStatus `json:"status"`
+	GraceEndsAt time.Time      `json:"grace_ends_at"`
+	DaysLeft    int            `json:"days_left"` // until the driver must stop working
+}
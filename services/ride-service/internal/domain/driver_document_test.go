@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDriverDocumentStatus(t *testing.T) {
+	expiresAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	grace := 14 * 24 * time.Hour
+
+	tests := []struct {
+		name  string
+		at    time.Time
+		grace time.Duration
+		want  DocumentStatus
+	}{
+		{"well before expiry", expiresAt.AddDate(0, -2, 0), grace, DocumentValid},
+		{"within first reminder", expiresAt.AddDate(0, 0, -10), grace, DocumentExpiring},
+		{"expired within grace", expiresAt.AddDate(0, 0, 3), grace, DocumentGrace},
+		{"expired past grace", expiresAt.AddDate(0, 0, 15), grace, DocumentExpired},
+		{"no grace expires at once", expiresAt, 0, DocumentExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &DriverDocument{ExpiresAt: expiresAt}
+			if got := doc.Status(tt.at, tt.grace); got != tt.want {
+				t.Errorf("Status() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDriverDocumentDueReminder(t *testing.T) {
+	expiresAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		last     int
+		wantDays int
+		wantDue  bool
+	}{
+		{"too early", expiresAt.AddDate(0, 0, -31), 0, 0, false},
+		{"thirty days out", expiresAt.AddDate(0, 0, -30), 0, 30, true},
+		{"thirty day reminder already sent", expiresAt.AddDate(0, 0, -20), 30, 0, false},
+		{"seven days out", expiresAt.AddDate(0, 0, -7), 30, 7, true},
+		{"uploaded late skips to nearest", expiresAt.AddDate(0, 0, -5), 0, 7, true},
+		{"final day", expiresAt.Add(-12 * time.Hour), 7, 1, true},
+		{"final reminder already sent", expiresAt.Add(-6 * time.Hour), 1, 0, false},
+		{"already expired", expiresAt.Add(time.Hour), 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &DriverDocument{ExpiresAt: expiresAt, LastReminderDays: tt.last}
+			days, due := doc.DueReminder(tt.at)
+			if days != tt.wantDays || due != tt.wantDue {
+				t.Errorf("DueReminder() = (%d, %v), want (%d, %v)", days, due, tt.wantDays, tt.wantDue)
+			}
+		})
+	}
+}
+
+func TestParseDocumentGraceDays(t *testing.T) {
+	periods, err := ParseDocumentGraceDays("ng:drivers_license=7, KE:ROADWORTHINESS=3")
+	if err != nil {
+		t.Fatalf("ParseDocumentGraceDays() error = %v", err)
+	}
+	if got := periods.GracePeriod("NG", DocumentDriversLicense); got != 7*24*time.Hour {
+		t.Errorf("NG licence grace = %v, want 7 days", got)
+	}
+	if got := periods.GracePeriod("KE", DocumentRoadworthiness); got != 3*24*time.Hour {
+		t.Errorf("KE roadworthiness grace = %v, want 3 days", got)
+	}
+	if got := periods.GracePeriod("KE", DocumentDriversLicense); got != DefaultDocumentGracePeriods.GracePeriod("KE", DocumentDriversLicense) {
+		t.Errorf("KE licence grace = %v, want default", got)
+	}
+	if DefaultDocumentGracePeriods.GracePeriod("NG", DocumentDriversLicense) != 30*24*time.Hour {
+		t.Error("override changed the defaults")
+	}
+
+	for _, spec := range []string{"NG=7", "NGA:DRIVERS_LICENSE=7", "NG:PASSPORT=7", "NG:DRIVERS_LICENSE=-1"} {
+		if _, err := ParseDocumentGraceDays(spec); err == nil {
+			t.Errorf("ParseDocumentGraceDays(%q) succeeded, want error", spec)
+		}
+	}
+}
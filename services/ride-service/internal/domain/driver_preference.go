@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DriverPreferenceKind is how a rider marked a driver they rode with
+type DriverPreferenceKind string
+
+const (
+	DriverPreferenceFavorite DriverPreferenceKind = "FAVORITE" // ranked ahead in matching
+	DriverPreferenceBlocked  DriverPreferenceKind = "BLOCKED"  // never matched with the rider
+)
+
+// DriverBlockReasonLimit is the most characters kept of a block reason
+const DriverBlockReasonLimit = 500
+
+// DriverPreference is a rider's favorite or block of a driver, set from a
+// ride they shared. A rider has at most one per driver; blocking replaces a
+// favorite, and a blocked driver must be unblocked before being favorited.
+type DriverPreference struct {
+	RiderID   uuid.UUID            `json:"rider_id"`
+	DriverID  uuid.UUID            `json:"driver_id"`
+	Kind      DriverPreferenceKind `json:"kind"`
+	RideID    uuid.UUID            `json:"ride_id"`
+	Reason    string               `json:"reason,omitempty"` // why a driver was blocked
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// NewDriverPreference marks a ride's driver for its rider. Drivers are
+// favorited after a completed ride and blocked from any ride they were
+// assigned.
+func NewDriverPreference(ride *Ride, kind DriverPreferenceKind, reason string, now time.Time) (*DriverPreference, error) {
+	if ride.DriverID == nil {
+		return nil, ErrInvalidDriverPreference
+	}
+	switch kind {
+	case DriverPreferenceFavorite:
+		if ride.Status != RideStatusCompleted {
+			return nil, ErrInvalidDriverPreference
+		}
+		reason = ""
+	case DriverPreferenceBlocked:
+		reason = strings.TrimSpace(reason)
+		if len(reason) > DriverBlockReasonLimit {
+			reason = reason[:DriverBlockReasonLimit]
+		}
+	default:
+		return nil, ErrInvalidDriverPreference
+	}
+
+	return &DriverPreference{
+		RiderID:   ride.RiderID,
+		DriverID:  *ride.DriverID,
+		Kind:      kind,
+		RideID:    ride.ID,
+		Reason:    reason,
+		CreatedAt: now,
+	}, nil
+}
+
+// DriverPreferenceSet is the drivers a rider favorited and blocked, for
+// matching. A nil set has neither.
+type DriverPreferenceSet struct {
+	Favorites map[uuid.UUID]bool
+	Blocked   map[uuid.UUID]bool
+}
+
+// NewDriverPreferenceSet indexes a rider's preferences by driver
+func NewDriverPreferenceSet(prefs []*DriverPreference) *DriverPreferenceSet {
+	set := &DriverPreferenceSet{
+		Favorites: make(map[uuid.UUID]bool),
+		Blocked:   make(map[uuid.UUID]bool),
+	}
+	for _, p := range prefs {
+		switch p.Kind {
+		case DriverPreferenceFavorite:
+			set.Favorites[p.DriverID] = true
+		case DriverPreferenceBlocked:
+			set.Blocked[p.DriverID] = true
+		}
+	}
+	return set
+}
+
+// IsFavorite reports whether the rider favorited the driver
+func (s *DriverPreferenceSet) IsFavorite(driverID uuid.UUID) bool {
+	return s != nil && s.Favorites[driverID]
+}
+
+// IsBlocked reports whether the rider blocked the driver
+func (s *DriverPreferenceSet) IsBlocked(driverID uuid.UUID) bool {
+	return s != nil && s.Blocked[driverID]
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewDriverPreference(t *testing.T) {
+	now := time.Now()
+	driverID := uuid.New()
+	completed := &Ride{ID: uuid.New(), RiderID: uuid.New(), DriverID: &driverID, Status: RideStatusCompleted}
+	cancelled := &Ride{ID: uuid.New(), RiderID: uuid.New(), DriverID: &driverID, Status: RideStatusCancelled}
+	unassigned := &Ride{ID: uuid.New(), RiderID: uuid.New(), Status: RideStatusCancelled}
+
+	tests := []struct {
+		name string
+		ride *Ride
+		kind DriverPreferenceKind
+		want error
+	}{
+		{"favorite after completed ride", completed, DriverPreferenceFavorite, nil},
+		{"favorite after cancelled ride", cancelled, DriverPreferenceFavorite, ErrInvalidDriverPreference},
+		{"block after cancelled ride", cancelled, DriverPreferenceBlocked, nil},
+		{"block without a driver", unassigned, DriverPreferenceBlocked, ErrInvalidDriverPreference},
+		{"unknown kind", completed, "MUTED", ErrInvalidDriverPreference},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pref, err := NewDriverPreference(tt.ride, tt.kind, "", now)
+			if err != tt.want {
+				t.Fatalf("NewDriverPreference() error = %v, want %v", err, tt.want)
+			}
+			if err == nil && (pref.DriverID != driverID || pref.RiderID != tt.ride.RiderID) {
+				t.Errorf("preference = %+v, want the ride's rider and driver", pref)
+			}
+		})
+	}
+
+	pref, _ := NewDriverPreference(cancelled, DriverPreferenceBlocked, "  "+strings.Repeat("x", 600), now)
+	if len(pref.Reason) != DriverBlockReasonLimit {
+		t.Errorf("reason kept %d characters, want %d", len(pref.Reason), DriverBlockReasonLimit)
+	}
+}
+
+func TestDriverPreferenceSet(t *testing.T) {
+	favorite, blocked := uuid.New(), uuid.New()
+	set := NewDriverPreferenceSet([]*DriverPreference{
+		{DriverID: favorite, Kind: DriverPreferenceFavorite},
+		{DriverID: blocked, Kind: DriverPreferenceBlocked},
+	})
+
+	if !set.IsFavorite(favorite) || set.IsBlocked(favorite) {
+		t.Error("favorite driver not recognised")
+	}
+	if !set.IsBlocked(blocked) || set.IsFavorite(blocked) {
+		t.Error("blocked driver not recognised")
+	}
+
+	var none *DriverPreferenceSet
+	if none.IsFavorite(favorite) || none.IsBlocked(blocked) {
+		t.Error("nil set reported a preference")
+	}
+}
@@ -0,0 +1,301 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QualityTier is a driver's tier in the quality program, earned each month
+// from the previous month's quality score
+type QualityTier string
+
+const (
+	QualityTierStandard QualityTier = "STANDARD"
+	QualityTierSilver   QualityTier = "SILVER"
+	QualityTierGold     QualityTier = "GOLD"
+	QualityTierPlatinum QualityTier = "PLATINUM"
+)
+
+// QualityPeriodLayout formats an evaluation period, one calendar month
+const QualityPeriodLayout = "2006-01"
+
+// Composite score weights
+const (
+	QualityWeightRating       = 0.40
+	QualityWeightCancellation = 0.25
+	QualityWeightComplaints   = 0.20
+	QualityWeightCompliance   = 0.15
+)
+
+// Scoring bounds. Ratings score from 0 at 4.0 stars to 100 at 5.0, and
+// cancellation and complaint rates from 100 at none to 0 at the cap. Each
+// compliance issue costs half the compliance score.
+const (
+	qualityRatingFloor       = 4.0
+	qualityUnratedRating     = 4.5 // drivers nobody rated this month are scored at mid-range
+	qualityCancellationCap   = 0.20
+	qualityComplaintsCap     = 5.0 // per 100 trips
+	qualityCompliancePenalty = 50.0
+)
+
+// Thresholds below which a component earns an improvement tip
+const (
+	qualityTipRating       = 4.8
+	qualityTipCancellation = 0.03
+)
+
+// QualityBenefits are what a tier earns its drivers
+type QualityBenefits struct {
+	CommissionDiscount float64 `json:"commission_discount"` // taken off the platform commission, 0.02 for two points
+	MatchingBoost      float64 `json:"matching_boost"`      // added to the driver's matching score out of 100
+}
+
+// QualityTierRule is what a tier takes to earn and what it earns
+type QualityTierRule struct {
+	Tier     QualityTier     `json:"tier"`
+	MinScore float64         `json:"min_score"`
+	MinTrips int             `json:"min_trips"` // completed in the month, so a handful of rides cannot earn a tier
+	Benefits QualityBenefits `json:"benefits"`
+}
+
+// QualityTiers are the program's tiers from the top down
+var QualityTiers = []QualityTierRule{
+	{Tier: QualityTierPlatinum, MinScore: 90, MinTrips: 120, Benefits: QualityBenefits{CommissionDiscount: 0.03, MatchingBoost: 6}},
+	{Tier: QualityTierGold, MinScore: 80, MinTrips: 60, Benefits: QualityBenefits{CommissionDiscount: 0.02, MatchingBoost: 4}},
+	{Tier: QualityTierSilver, MinScore: 70, MinTrips: 25, Benefits: QualityBenefits{CommissionDiscount: 0.01, MatchingBoost: 2}},
+	{Tier: QualityTierStandard},
+}
+
+// Rule returns the tier's rule, Standard's for an unknown tier
+func (t QualityTier) Rule() QualityTierRule {
+	for _, rule := range QualityTiers {
+		if rule.Tier == t {
+			return rule
+		}
+	}
+	return QualityTiers[len(QualityTiers)-1]
+}
+
+// Benefits returns what the tier earns
+func (t QualityTier) Benefits() QualityBenefits {
+	return t.Rule().Benefits
+}
+
+// Next returns the tier above, and false at the top
+func (t QualityTier) Next() (QualityTier, bool) {
+	for i, rule := range QualityTiers {
+		if rule.Tier == t && i > 0 {
+			return QualityTiers[i-1].Tier, true
+		}
+	}
+	return "", false
+}
+
+// QualityMetrics are a driver's record over an evaluation period
+type QualityMetrics struct {
+	CompletedTrips   int     `json:"completed_trips"`
+	Cancellations    int     `json:"cancellations"` // accepted rides the driver cancelled
+	RatedTrips       int     `json:"rated_trips"`
+	AverageRating    float64 `json:"average_rating"`
+	Complaints       int     `json:"complaints"`        // upheld driving disputes and complaint sanctions
+	ComplianceIssues int     `json:"compliance_issues"` // expired documents and other sanctions
+}
+
+// CancellationRate is the share of accepted rides the driver cancelled
+func (m QualityMetrics) CancellationRate() float64 {
+	accepted := m.CompletedTrips + m.Cancellations
+	if accepted == 0 {
+		return 0
+	}
+	return float64(m.Cancellations) / float64(accepted)
+}
+
+// ComplaintsPer100 is complaints per 100 completed trips
+func (m QualityMetrics) ComplaintsPer100() float64 {
+	trips := m.CompletedTrips
+	if trips == 0 {
+		trips = 1
+	}
+	return float64(m.Complaints) * 100 / float64(trips)
+}
+
+// QualityScores are a driver's component scores out of 100 and their
+// weighted composite
+type QualityScores struct {
+	Rating       float64 `json:"rating"`
+	Cancellation float64 `json:"cancellation"`
+	Complaints   float64 `json:"complaints"`
+	Compliance   float64 `json:"compliance"`
+	Composite    float64 `json:"composite"`
+}
+
+// ScoreQuality scores a driver's record
+func ScoreQuality(m QualityMetrics) QualityScores {
+	rating := m.AverageRating
+	if m.RatedTrips == 0 {
+		rating = qualityUnratedRating
+	}
+
+	scores := QualityScores{
+		Rating:       clampScore((rating - qualityRatingFloor) * 100 / (5 - qualityRatingFloor)),
+		Cancellation: clampScore(100 * (1 - m.CancellationRate()/qualityCancellationCap)),
+		Complaints:   clampScore(100 * (1 - m.ComplaintsPer100()/qualityComplaintsCap)),
+		Compliance:   clampScore(100 - qualityCompliancePenalty*float64(m.ComplianceIssues)),
+	}
+	scores.Composite = roundScore(scores.Rating*QualityWeightRating +
+		scores.Cancellation*QualityWeightCancellation +
+		scores.Complaints*QualityWeightComplaints +
+		scores.Compliance*QualityWeightCompliance)
+	return scores
+}
+
+// QualityTierFor returns the highest tier a record earns. A driver with any
+// compliance issue in the month earns no tier above Standard, whatever
+// their score.
+func QualityTierFor(m QualityMetrics, scores QualityScores) QualityTier {
+	if m.ComplianceIssues > 0 {
+		return QualityTierStandard
+	}
+	for _, rule := range QualityTiers {
+		if scores.Composite >= rule.MinScore && m.CompletedTrips >= rule.MinTrips {
+			return rule.Tier
+		}
+	}
+	return QualityTierStandard
+}
+
+// DriverQuality is a driver's quality evaluation for a month
+type DriverQuality struct {
+	DriverID     uuid.UUID       `json:"driver_id"`
+	Period       string          `json:"period"` // YYYY-MM
+	Metrics      QualityMetrics  `json:"metrics"`
+	Scores       QualityScores   `json:"scores"`
+	Tier         QualityTier     `json:"tier"`
+	PreviousTier QualityTier     `json:"previous_tier,omitempty"`
+	Benefits     QualityBenefits `json:"benefits"`
+	EvaluatedAt  time.Time       `json:"evaluated_at"`
+}
+
+// EvaluateQuality scores a driver's record for a month and places them in
+// a tier
+func EvaluateQuality(driverID uuid.UUID, period time.Time, m QualityMetrics, previous QualityTier, now time.Time) *DriverQuality {
+	scores := ScoreQuality(m)
+	tier := QualityTierFor(m, scores)
+	return &DriverQuality{
+		DriverID:     driverID,
+		Period:       period.Format(QualityPeriodLayout),
+		Metrics:      m,
+		Scores:       scores,
+		Tier:         tier,
+		PreviousTier: previous,
+		Benefits:     tier.Benefits(),
+		EvaluatedAt:  now,
+	}
+}
+
+// QualityPeriod returns the start of the month containing t, and the start
+// of the month after
+func QualityPeriod(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// QualityTip is advice on improving one part of a driver's score
+type QualityTip struct {
+	Component string `json:"component"` // rating, cancellation, complaints, compliance, score or trips
+	Message   string `json:"message"`
+}
+
+// Tips suggests what would most improve the driver's standing. Compliance
+// comes first, since it caps the tier whatever the score.
+func (q *DriverQuality) Tips() []QualityTip {
+	m := q.Metrics
+	tips := make([]QualityTip, 0)
+
+	if m.ComplianceIssues > 0 {
+		tips = append(tips, QualityTip{
+			Component: "compliance",
+			Message: fmt.Sprintf("%d compliance issue(s) this month hold you at Standard. Renew your documents before they expire to keep your tier.",
+				m.ComplianceIssues),
+		})
+	}
+	if rate := m.CancellationRate(); rate > qualityTipCancellation {
+		tips = append(tips, QualityTip{
+			Component: "cancellation",
+			Message: fmt.Sprintf("You cancelled %.0f%% of the rides you accepted. Keep it under %.0f%% by only accepting rides you can complete.",
+				rate*100, qualityTipCancellation*100),
+		})
+	}
+	if m.Complaints > 0 {
+		tips = append(tips, QualityTip{
+			Component: "complaints",
+			Message: fmt.Sprintf("Riders had %d upheld complaint(s) about your trips. Follow the app's route and drop riders where they asked.",
+				m.Complaints),
+		})
+	}
+	if m.RatedTrips > 0 && m.AverageRating < qualityTipRating {
+		tips = append(tips, QualityTip{
+			Component: "rating",
+			Message: fmt.Sprintf("Your average rating is %.2f. Riders rate a clean car, a smooth drive and a friendly greeting highest.",
+				m.AverageRating),
+		})
+	}
+
+	if next, ok := q.Tier.Next(); ok {
+		rule := next.Rule()
+		switch {
+		case q.Scores.Composite < rule.MinScore:
+			tips = append(tips, QualityTip{
+				Component: "score",
+				Message:   fmt.Sprintf("%.1f more points to reach %s.", rule.MinScore-q.Scores.Composite, next),
+			})
+		case m.CompletedTrips < rule.MinTrips:
+			tips = append(tips, QualityTip{
+				Component: "trips",
+				Message:   fmt.Sprintf("%d more completed trips this month to reach %s.", rule.MinTrips-m.CompletedTrips, next),
+			})
+		}
+	}
+
+	return tips
+}
+
+// QualityStatus is what a driver sees of the program: the tier they hold
+// and its benefits, and how this month is shaping up for the next one
+type QualityStatus struct {
+	Tier        QualityTier     `json:"tier"`
+	Benefits    QualityBenefits `json:"benefits"`
+	Evaluation  *DriverQuality  `json:"evaluation,omitempty"` // the evaluation that placed them in their tier
+	MonthToDate *DriverQuality  `json:"month_to_date"`
+	Tips        []QualityTip    `json:"tips"`
+}
+
+// WithCommissionDiscount returns the fare split with discount taken off the
+// platform's commission in place of any discount it already has. The
+// rider's total is unchanged; the driver earns the difference.
+func (p *PriceBreakdown) WithCommissionDiscount(discount float64) *PriceBreakdown {
+	if discount == p.CommissionDiscount {
+		return p
+	}
+
+	split := *p
+	split.PlatformFee += int64(float64(split.Total)*split.CommissionDiscount) - int64(float64(split.Total)*discount)
+	if split.PlatformFee < 0 {
+		split.PlatformFee = 0
+	}
+	split.CommissionDiscount = discount
+	split.DriverEarnings = split.Total - split.PlatformFee
+	return &split
+}
+
+func clampScore(score float64) float64 {
+	return roundScore(math.Max(0, math.Min(100, score)))
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*10) / 10
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestScoreQuality(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics QualityMetrics
+		want    QualityScores
+	}{
+		{
+			"spotless",
+			QualityMetrics{CompletedTrips: 100, RatedTrips: 80, AverageRating: 5},
+			QualityScores{Rating: 100, Cancellation: 100, Complaints: 100, Compliance: 100, Composite: 100},
+		},
+		{
+			"unrated scored mid-range",
+			QualityMetrics{CompletedTrips: 10},
+			QualityScores{Rating: 50, Cancellation: 100, Complaints: 100, Compliance: 100, Composite: 80},
+		},
+		{
+			"cancellations and complaints",
+			QualityMetrics{CompletedTrips: 90, Cancellations: 10, RatedTrips: 90, AverageRating: 4.8, Complaints: 2},
+			QualityScores{Rating: 80, Cancellation: 50, Complaints: 55.6, Compliance: 100, Composite: 70.6},
+		},
+		{
+			"below the floors",
+			QualityMetrics{CompletedTrips: 10, Cancellations: 10, RatedTrips: 10, AverageRating: 3.2, Complaints: 3, ComplianceIssues: 3},
+			QualityScores{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScoreQuality(tt.metrics); got != tt.want {
+				t.Errorf("ScoreQuality() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQualityTierFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		trips     int
+		issues    int
+		composite float64
+		want      QualityTier
+	}{
+		{"platinum", 150, 0, 95, QualityTierPlatinum},
+		{"too few trips for platinum", 100, 0, 95, QualityTierGold},
+		{"gold", 60, 0, 80, QualityTierGold},
+		{"silver", 30, 0, 75, QualityTierSilver},
+		{"too few trips for any tier", 10, 0, 99, QualityTierStandard},
+		{"low score", 200, 0, 60, QualityTierStandard},
+		{"compliance issue caps the tier", 200, 1, 95, QualityTierStandard},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := QualityMetrics{CompletedTrips: tt.trips, ComplianceIssues: tt.issues}
+			if got := QualityTierFor(m, QualityScores{Composite: tt.composite}); got != tt.want {
+				t.Errorf("QualityTierFor() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQualityTierNext(t *testing.T) {
+	tests := []struct {
+		tier   QualityTier
+		want   QualityTier
+		wantOK bool
+	}{
+		{QualityTierStandard, QualityTierSilver, true},
+		{QualityTierSilver, QualityTierGold, true},
+		{QualityTierGold, QualityTierPlatinum, true},
+		{QualityTierPlatinum, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.tier.Next()
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s.Next() = %s, %v, want %s, %v", tt.tier, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestDriverQualityTips(t *testing.T) {
+	period := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	components := func(q *DriverQuality) []string {
+		var got []string
+		for _, tip := range q.Tips() {
+			got = append(got, tip.Component)
+		}
+		return got
+	}
+
+	q := EvaluateQuality(uuid.New(), period, QualityMetrics{
+		CompletedTrips: 90, Cancellations: 10, RatedTrips: 90, AverageRating: 4.6, Complaints: 1, ComplianceIssues: 1,
+	}, QualityTierStandard, period)
+	want := []string{"compliance", "cancellation", "complaints", "rating", "score"}
+	if got := components(q); len(got) != len(want) {
+		t.Fatalf("Tips() components = %v, want %v", got, want)
+	} else {
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("Tips() components = %v, want %v", got, want)
+				break
+			}
+		}
+	}
+
+	q = EvaluateQuality(uuid.New(), period, QualityMetrics{CompletedTrips: 10, RatedTrips: 10, AverageRating: 5}, QualityTierStandard, period)
+	if got := components(q); len(got) != 1 || got[0] != "trips" {
+		t.Errorf("Tips() components = %v, want [trips]", got)
+	}
+
+	q = EvaluateQuality(uuid.New(), period, QualityMetrics{CompletedTrips: 200, RatedTrips: 200, AverageRating: 5}, QualityTierGold, period)
+	if q.Tier != QualityTierPlatinum || len(q.Tips()) != 0 {
+		t.Errorf("top tier driver got tier %s, tips %v", q.Tier, q.Tips())
+	}
+}
+
+func TestPriceBreakdownWithCommissionDiscount(t *testing.T) {
+	price := &PriceBreakdown{Total: 10000, PlatformFee: 2000, DriverEarnings: 8000}
+
+	discounted := price.WithCommissionDiscount(0.03)
+	if discounted.PlatformFee != 1700 || discounted.DriverEarnings != 8300 || discounted.Total != 10000 {
+		t.Errorf("discounted split = %d/%d of %d, want 1700/8300 of 10000", discounted.PlatformFee, discounted.DriverEarnings, discounted.Total)
+	}
+	if price.PlatformFee != 2000 {
+		t.Errorf("original breakdown was modified")
+	}
+
+	// A reassigned ride takes the new driver's discount in place of the old
+	replaced := discounted.WithCommissionDiscount(0.01)
+	if replaced.PlatformFee != 1900 || replaced.CommissionDiscount != 0.01 {
+		t.Errorf("replaced split fee = %d discount %v, want 1900 and 0.01", replaced.PlatformFee, replaced.CommissionDiscount)
+	}
+	if removed := replaced.WithCommissionDiscount(0); removed.PlatformFee != 2000 || removed.DriverEarnings != 8000 {
+		t.Errorf("removed split = %d/%d, want 2000/8000", removed.PlatformFee, removed.DriverEarnings)
+	}
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SanctionReason is a trust & safety reason code for restricting a driver
+type SanctionReason string
+
+const (
+	SanctionReasonSafetyIncident   SanctionReason = "SAFETY_INCIDENT"
+	SanctionReasonFraud            SanctionReason = "FRAUD"
+	SanctionReasonLowRating        SanctionReason = "LOW_RATING"
+	SanctionReasonDocumentsExpired SanctionReason = "DOCUMENTS_EXPIRED"
+	SanctionReasonPolicyViolation  SanctionReason = "POLICY_VIOLATION"
+	SanctionReasonRiderComplaints  SanctionReason = "RIDER_COMPLAINTS"
+	SanctionReasonBackgroundCheck  SanctionReason = "BACKGROUND_CHECK"
+	SanctionReasonOther            SanctionReason = "OTHER"
+)
+
+// IsValid reports whether the reason code is known
+func (r SanctionReason) IsValid() bool {
+	switch r {
+	case SanctionReasonSafetyIncident, SanctionReasonFraud, SanctionReasonLowRating,
+		SanctionReasonDocumentsExpired, SanctionReasonPolicyViolation,
+		SanctionReasonRiderComplaints, SanctionReasonBackgroundCheck, SanctionReasonOther:
+		return true
+	}
+	return false
+}
+
+// DriverSanction restricts a driver from taking rides. Suspensions may be
+// time-boxed and lift automatically; deactivations and reviews are lifted
+// by an agent or a successful appeal.
+type DriverSanction struct {
+	ID        uuid.UUID      `json:"id"`
+	DriverID  uuid.UUID      `json:"driver_id"`
+	Status    DriverStatus   `json:"status"`
+	Reason    SanctionReason `json:"reason"`
+	Note      string         `json:"note,omitempty"`
+	IssuedBy  uuid.UUID      `json:"issued_by"`
+	StartsAt  time.Time      `json:"starts_at"`
+	EndsAt    *time.Time     `json:"ends_at,omitempty"`
+	LiftedAt  *time.Time     `json:"lifted_at,omitempty"`
+	LiftedBy  *uuid.UUID     `json:"lifted_by,omitempty"`
+	LiftNote  string         `json:"lift_note,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// NewDriverSanction creates a sanction. A zero duration means indefinite;
+// durations only apply to suspensions.
+func NewDriverSanction(driverID, issuedBy uuid.UUID, status DriverStatus, reason SanctionReason, note string, duration time.Duration) (*DriverSanction, error) {
+	if !status.IsRestricted() || !reason.IsValid() || duration < 0 {
+		return nil, ErrInvalidSanction
+	}
+
+	now := time.Now().UTC()
+	sanction := &DriverSanction{
+		ID:        uuid.New(),
+		DriverID:  driverID,
+		Status:    status,
+		Reason:    reason,
+		Note:      note,
+		IssuedBy:  issuedBy,
+		StartsAt:  now,
+		CreatedAt: now,
+	}
+
+	if duration > 0 {
+		if status != DriverStatusSuspended {
+			return nil, ErrInvalidSanction
+		}
+		endsAt := now.Add(duration)
+		sanction.EndsAt = &endsAt
+	}
+
+	return sanction, nil
+}
+
+// IsActive reports whether the sanction is still in force at the given time
+func (s *DriverSanction) IsActive(now time.Time) bool {
+	if s.LiftedAt != nil {
+		return false
+	}
+	return s.EndsAt == nil || now.Before(*s.EndsAt)
+}
+
+// CanAppeal reports whether the driver may appeal this sanction. Reviews are
+// already pending a decision, so only suspensions and deactivations qualify.
+func (s *DriverSanction) CanAppeal() bool {
+	return s.Status == DriverStatusSuspended || s.Status == DriverStatusDeactivated
+}
+
+// AppealStatus represents the state of a driver's appeal
+type AppealStatus string
+
+const (
+	AppealStatusPending AppealStatus = "PENDING"
+	AppealStatusUpheld  AppealStatus = "UPHELD"
+	AppealStatusDenied  AppealStatus = "DENIED"
+)
+
+// DriverAppeal is a driver's request to overturn a sanction
+type DriverAppeal struct {
+	ID         uuid.UUID    `json:"id"`
+	SanctionID uuid.UUID    `json:"sanction_id"`
+	DriverID   uuid.UUID    `json:"driver_id"`
+	Statement  string       `json:"statement"`
+	Status     AppealStatus `json:"status"`
+	ReviewedBy *uuid.UUID   `json:"reviewed_by,omitempty"`
+	ReviewNote string       `json:"review_note,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	ReviewedAt *time.Time   `json:"reviewed_at,omitempty"`
+}
+
+// DriverStanding summarizes whether a driver may work, for the driver app
+// to check at sign-in
+type DriverStanding struct {
+	DriverID       uuid.UUID       `json:"driver_id"`
+	Status         DriverStatus    `json:"status"`
+	CanGoOnline    bool            `json:"can_go_online"`
+	ActiveSanction *DriverSanction `json:"active_sanction,omitempty"`
+	PendingAppeal  *DriverAppeal   `json:"pending_appeal,omitempty"`
+}
+
+// CanSanctionDrivers reports whether a role may sanction or reinstate drivers
+// and decide appeals
+func CanSanctionDrivers(role string) bool {
+	return role == RoleSupportLead || role == RoleAdmin
+}
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewDriverSanction(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   DriverStatus
+		reason   SanctionReason
+		duration time.Duration
+		wantEnds bool
+		wantErr  bool
+	}{
+		{name: "indefinite suspension", status: DriverStatusSuspended, reason: SanctionReasonFraud},
+		{name: "time-boxed suspension", status: DriverStatusSuspended, reason: SanctionReasonLowRating, duration: 72 * time.Hour, wantEnds: true},
+		{name: "deactivation", status: DriverStatusDeactivated, reason: SanctionReasonSafetyIncident},
+		{name: "review", status: DriverStatusUnderReview, reason: SanctionReasonRiderComplaints},
+		{name: "time-boxed deactivation", status: DriverStatusDeactivated, reason: SanctionReasonFraud, duration: time.Hour, wantErr: true},
+		{name: "time-boxed review", status: DriverStatusUnderReview, reason: SanctionReasonOther, duration: time.Hour, wantErr: true},
+		{name: "unrestricted status", status: DriverStatusOnline, reason: SanctionReasonFraud, wantErr: true},
+		{name: "unknown reason", status: DriverStatusSuspended, reason: "BAD_VIBES", wantErr: true},
+		{name: "negative duration", status: DriverStatusSuspended, reason: SanctionReasonFraud, duration: -time.Hour, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driverID, agentID := uuid.New(), uuid.New()
+			sanction, err := NewDriverSanction(driverID, agentID, tt.status, tt.reason, "note", tt.duration)
+			if tt.wantErr {
+				if err != ErrInvalidSanction {
+					t.Fatalf("NewDriverSanction() error = %v, want ErrInvalidSanction", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewDriverSanction() error = %v", err)
+			}
+			if sanction.DriverID != driverID || sanction.IssuedBy != agentID || sanction.Status != tt.status {
+				t.Errorf("sanction = %+v, want driver, agent and status kept", sanction)
+			}
+			if (sanction.EndsAt != nil) != tt.wantEnds {
+				t.Fatalf("EndsAt = %v, want set %v", sanction.EndsAt, tt.wantEnds)
+			}
+			if tt.wantEnds && !sanction.EndsAt.Equal(sanction.StartsAt.Add(tt.duration)) {
+				t.Errorf("EndsAt = %s, want %s after %s", sanction.EndsAt, tt.duration, sanction.StartsAt)
+			}
+			if !sanction.IsActive(sanction.StartsAt) {
+				t.Error("new sanction is not active")
+			}
+		})
+	}
+}
+
+func TestDriverSanctionIsActive(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	lifted := start.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		sanction DriverSanction
+		at       time.Time
+		want     bool
+	}{
+		{name: "indefinite", sanction: DriverSanction{StartsAt: start}, at: start.Add(365 * 24 * time.Hour), want: true},
+		{name: "before its end", sanction: DriverSanction{StartsAt: start, EndsAt: &end}, at: end.Add(-time.Second), want: true},
+		{name: "at its end", sanction: DriverSanction{StartsAt: start, EndsAt: &end}, at: end, want: false},
+		{name: "lifted early", sanction: DriverSanction{StartsAt: start, EndsAt: &end, LiftedAt: &lifted}, at: start.Add(2 * time.Hour), want: false},
+		{name: "indefinite but lifted", sanction: DriverSanction{StartsAt: start, LiftedAt: &lifted}, at: start.Add(2 * time.Hour), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sanction.IsActive(tt.at); got != tt.want {
+				t.Errorf("IsActive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDriverSanctionCanAppeal(t *testing.T) {
+	tests := []struct {
+		status DriverStatus
+		want   bool
+	}{
+		{DriverStatusSuspended, true},
+		{DriverStatusDeactivated, true},
+		{DriverStatusUnderReview, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			s := DriverSanction{Status: tt.status}
+			if got := s.CanAppeal(); got != tt.want {
+				t.Errorf("CanAppeal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanSanctionDrivers(t *testing.T) {
+	for role, want := range map[string]bool{
+		RoleSupportLead: true,
+		RoleAdmin:       true,
+		RoleSupport:     false,
+		"DRIVER":        false,
+		"":              false,
+	} {
+		if got := CanSanctionDrivers(role); got != want {
+			t.Errorf("CanSanctionDrivers(%q) = %v, want %v", role, got, want)
+		}
+	}
+}
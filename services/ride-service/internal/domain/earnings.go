@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EarningsPeriod is the window a driver's earnings summary covers
+type EarningsPeriod string
+
+const (
+	EarningsPeriodDay   EarningsPeriod = "day"
+	EarningsPeriodWeek  EarningsPeriod = "week"
+	EarningsPeriodMonth EarningsPeriod = "month"
+)
+
+// Bounds returns the start of the period containing now, in loc, and now.
+// Weeks start on Monday.
+func (p EarningsPeriod) Bounds(now time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	local := now.In(loc)
+	year, month, day := local.Date()
+
+	var from time.Time
+	switch p {
+	case EarningsPeriodDay:
+		from = time.Date(year, month, day, 0, 0, 0, 0, loc)
+	case EarningsPeriodWeek:
+		sinceMonday := (int(local.Weekday()) + 6) % 7
+		from = time.Date(year, month, day-sinceMonday, 0, 0, 0, 0, loc)
+	case EarningsPeriodMonth:
+		from = time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Time{}, time.Time{}, ErrInvalidEarningsPeriod
+	}
+
+	return from, now, nil
+}
+
+// EarningsSource is the service a driver's earnings came from
+type EarningsSource string
+
+const (
+	EarningsSourceRides      EarningsSource = "RIDES"
+	EarningsSourceDeliveries EarningsSource = "DELIVERIES"
+)
+
+// EarningsLine is a driver's earnings from one source in one currency.
+// Amounts are minor units.
+type EarningsLine struct {
+	Source      EarningsSource `json:"source"`
+	Currency    Currency       `json:"currency"`
+	Trips       int            `json:"trips"`
+	Fares       int64          `json:"fares"`
+	Tips        int64          `json:"tips"`
+	Adjustments int64          `json:"adjustments"` // refund reversals and other ledger corrections
+	Net         int64          `json:"net"`
+}
+
+// EarningsSummary is a driver's combined ride and delivery earnings for a
+// period. Totals are in Currency; Lines keep each source's own currency.
+type EarningsSummary struct {
+	DriverID              uuid.UUID       `json:"driver_id"`
+	Period                EarningsPeriod  `json:"period"`
+	From                  time.Time       `json:"from"`
+	To                    time.Time       `json:"to"`
+	Currency              Currency        `json:"currency"`
+	Total                 int64           `json:"total"`
+	Rides                 int64           `json:"rides"`
+	Deliveries            int64           `json:"deliveries"`
+	Trips                 int             `json:"trips"`
+	Approximate           bool            `json:"approximate"` // some lines were converted at reference rates
+	DeliveriesUnavailable bool            `json:"deliveries_unavailable,omitempty"`
+	Lines                 []*EarningsLine `json:"lines"`
+}
+
+// ReferenceRatesPerUSD are indicative units of each currency per US dollar.
+// They only let the home screen show one figure when a driver earned in
+// several currencies; payouts settle per currency and never use them.
+var ReferenceRatesPerUSD = map[Currency]float64{
+	CurrencyUSD: 1,
+	CurrencyNGN: 1550,
+	CurrencyKES: 129,
+	CurrencyGHS: 15.5,
+	CurrencyUGX: 3700,
+	CurrencyTZS: 2650,
+	CurrencyRWF: 1400,
+	CurrencyZAR: 18.5,
+}
+
+// ConvertAmount converts minor units between currencies at the reference
+// rates. It reports false if either currency has no reference rate.
+func ConvertAmount(amount int64, from, to Currency) (int64, bool) {
+	if from == to {
+		return amount, true
+	}
+	fromRate, ok := ReferenceRatesPerUSD[from]
+	if !ok {
+		return 0, false
+	}
+	toRate, ok := ReferenceRatesPerUSD[to]
+	if !ok {
+		return 0, false
+	}
+	return int64(math.Round(float64(amount) / fromRate * toRate)), true
+}
+
+// PrimaryEarningsCurrency returns the currency holding the largest share of
+// the lines' net earnings, or "" if there are no lines
+func PrimaryEarningsCurrency(lines []*EarningsLine) Currency {
+	shares := make(map[Currency]int64)
+	for _, line := range lines {
+		usd, ok := ConvertAmount(line.Net, line.Currency, CurrencyUSD)
+		if !ok {
+			continue
+		}
+		shares[line.Currency] += usd
+	}
+
+	var primary Currency
+	var best int64
+	for currency, share := range shares {
+		if primary == "" || share > best || (share == best && currency < primary) {
+			primary, best = currency, share
+		}
+	}
+	return primary
+}
+
+// Summarize sorts the summary's lines and totals them in s.Currency. Lines in
+// a currency without a reference rate are left out of the totals and the
+// summary is marked approximate.
+func (s *EarningsSummary) Summarize() {
+	sort.Slice(s.Lines, func(i, j int) bool {
+		if s.Lines[i].Source != s.Lines[j].Source {
+			return s.Lines[i].Source > s.Lines[j].Source // rides first
+		}
+		return s.Lines[i].Currency < s.Lines[j].Currency
+	})
+
+	s.Total, s.Rides, s.Deliveries, s.Trips = 0, 0, 0, 0
+	for _, line := range s.Lines {
+		line.Net = line.Fares + line.Tips + line.Adjustments
+		s.Trips += line.Trips
+
+		amount, ok := ConvertAmount(line.Net, line.Currency, s.Currency)
+		if !ok || line.Currency != s.Currency {
+			s.Approximate = true
+		}
+		if !ok {
+			continue
+		}
+
+		s.Total += amount
+		switch line.Source {
+		case EarningsSourceRides:
+			s.Rides += amount
+		case EarningsSourceDeliveries:
+			s.Deliveries += amount
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEarningsPeriodBounds(t *testing.T) {
+	now := time.Date(2026, 3, 12, 15, 30, 0, 0, time.UTC) // Thursday
+
+	tests := []struct {
+		period   EarningsPeriod
+		wantFrom time.Time
+	}{
+		{EarningsPeriodDay, time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)},
+		{EarningsPeriodWeek, time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{EarningsPeriodMonth, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.period), func(t *testing.T) {
+			from, to, err := tt.period.Bounds(now, time.UTC)
+			if err != nil {
+				t.Fatalf("Bounds() error = %v", err)
+			}
+			if !from.Equal(tt.wantFrom) || !to.Equal(now) {
+				t.Errorf("Bounds() = %v, %v; want %v, %v", from, to, tt.wantFrom, now)
+			}
+		})
+	}
+
+	if _, _, err := EarningsPeriod("year").Bounds(now, time.UTC); err != ErrInvalidEarningsPeriod {
+		t.Errorf("Bounds(year) error = %v, want %v", err, ErrInvalidEarningsPeriod)
+	}
+}
+
+func TestEarningsSummarize(t *testing.T) {
+	summary := &EarningsSummary{
+		Currency: CurrencyKES,
+		Lines: []*EarningsLine{
+			{Source: EarningsSourceDeliveries, Currency: CurrencyKES, Trips: 3, Fares: 90000, Tips: 10000},
+			{Source: EarningsSourceRides, Currency: CurrencyKES, Trips: 5, Fares: 250000, Adjustments: -20000},
+			{Source: EarningsSourceRides, Currency: CurrencyUGX, Trips: 1, Fares: 3700000},
+		},
+	}
+
+	summary.Summarize()
+
+	if summary.Lines[0].Source != EarningsSourceRides || summary.Lines[2].Source != EarningsSourceDeliveries {
+		t.Errorf("expected ride lines before delivery lines, got %+v", summary.Lines)
+	}
+	// 37,000 UGX is ten reference dollars, or 1,290 KES
+	if summary.Rides != 230000+129000 {
+		t.Errorf("Rides = %d, want %d", summary.Rides, 230000+129000)
+	}
+	if summary.Deliveries != 100000 {
+		t.Errorf("Deliveries = %d, want 100000", summary.Deliveries)
+	}
+	if summary.Total != summary.Rides+summary.Deliveries || summary.Trips != 9 {
+		t.Errorf("Total = %d, Trips = %d", summary.Total, summary.Trips)
+	}
+	if !summary.Approximate {
+		t.Error("expected summary with converted lines to be approximate")
+	}
+}
+
+func TestPrimaryEarningsCurrency(t *testing.T) {
+	lines := []*EarningsLine{
+		{Currency: CurrencyNGN, Net: 1550000}, // 10 USD
+		{Currency: CurrencyKES, Net: 258000},  // 20 USD
+	}
+
+	if got := PrimaryEarningsCurrency(lines); got != CurrencyKES {
+		t.Errorf("PrimaryEarningsCurrency() = %s, want %s", got, CurrencyKES)
+	}
+	if got := PrimaryEarningsCurrency(nil); got != "" {
+		t.Errorf("PrimaryEarningsCurrency(nil) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Powertrain is what drives a vehicle, which decides how much CO2 it emits
+type Powertrain string
+
+const (
+	PowertrainICE      Powertrain = "ICE" // petrol or diesel; vehicles not flagged otherwise
+	PowertrainHybrid   Powertrain = "HYBRID"
+	PowertrainElectric Powertrain = "ELECTRIC"
+)
+
+// IsValid checks if the powertrain is known
+func (p Powertrain) IsValid() bool {
+	switch p {
+	case PowertrainICE, PowertrainHybrid, PowertrainElectric:
+		return true
+	}
+	return false
+}
+
+// LowEmission reports whether the powertrain is electric or hybrid, the
+// vehicles green rides are matched with first
+func (p Powertrain) LowEmission() bool {
+	return p == PowertrainHybrid || p == PowertrainElectric
+}
+
+// RidePreference is how a rider asked for their ride to be matched
+type RidePreference string
+
+// RidePreferenceGreen matches the ride with electric and hybrid vehicles
+// ahead of others nearby. It is a preference, not a guarantee.
+const RidePreferenceGreen RidePreference = "GREEN"
+
+// Ride metadata keys for the rider's matching preference and the ride's
+// estimated emissions
+const (
+	RidePreferenceMetadataKey = "ride_preference"
+	EmissionsMetadataKey      = "emissions"
+)
+
+// vehicleCO2GramsPerKm is the CO2 a petrol or diesel vehicle of each type
+// emits per km, for a typical vehicle on the road in our markets
+var vehicleCO2GramsPerKm = map[VehicleType]int64{
+	VehicleTypeBike:     75,
+	VehicleTypeTricycle: 110,
+	VehicleTypeCar:      170,
+	VehicleTypeSUV:      220,
+	VehicleTypeVan:      250,
+	VehicleTypeTruck:    450,
+}
+
+// powertrainCO2Percent is what a powertrain emits as a share of a petrol or
+// diesel vehicle of the same type. Electric vehicles are charged from
+// grids still partly run on fossil fuels, so emit some.
+var powertrainCO2Percent = map[Powertrain]int64{
+	PowertrainICE:      100,
+	PowertrainHybrid:   65,
+	PowertrainElectric: 25,
+}
+
+// RideTypeVehicle is the vehicle type a ride type is usually driven in,
+// for estimating its emissions before a driver is matched
+func RideTypeVehicle(rideType RideType) VehicleType {
+	switch rideType {
+	case RideTypeBoda:
+		return VehicleTypeBike
+	case RideTypeTricycle:
+		return VehicleTypeTricycle
+	case RideTypeXL:
+		return VehicleTypeSUV
+	default:
+		return VehicleTypeCar
+	}
+}
+
+// CO2Grams estimates the CO2 emitted driving a distance in a vehicle type
+// and powertrain. Unknown types count as cars and unknown powertrains as
+// petrol or diesel.
+func CO2Grams(distanceM int64, vehicleType VehicleType, powertrain Powertrain) int64 {
+	if distanceM <= 0 {
+		return 0
+	}
+	perKm, ok := vehicleCO2GramsPerKm[vehicleType]
+	if !ok {
+		perKm = vehicleCO2GramsPerKm[VehicleTypeCar]
+	}
+	percent, ok := powertrainCO2Percent[powertrain]
+	if !ok {
+		percent = 100
+	}
+	return (distanceM*perKm*percent + 50000) / 100000
+}
+
+// EstimateRideCO2Grams estimates a ride type's emissions over a distance
+// in the vehicle it is usually driven in, as quoted on fare estimates
+func EstimateRideCO2Grams(rideType RideType, distanceM int64) int64 {
+	return CO2Grams(distanceM, RideTypeVehicle(rideType), PowertrainICE)
+}
+
+// RideEmissions is the CO2 a completed ride is estimated to have emitted
+// in the vehicle it was driven in
+type RideEmissions struct {
+	VehicleType    VehicleType `json:"vehicle_type"`
+	Powertrain     Powertrain  `json:"powertrain"`
+	DistanceMeters int64       `json:"distance_meters"`
+	CO2Grams       int64       `json:"co2_grams"`
+	SavedGrams     int64       `json:"saved_grams"` // against the same trip in a petrol or diesel vehicle
+}
+
+// NewRideEmissions estimates a ride's emissions over its route in the
+// vehicle it was driven in. Without a vehicle, the ride type's usual one is
+// assumed.
+func NewRideEmissions(ride *Ride, vehicle *Vehicle) *RideEmissions {
+	e := &RideEmissions{
+		VehicleType: RideTypeVehicle(ride.Type),
+		Powertrain:  PowertrainICE,
+	}
+	if vehicle != nil {
+		e.VehicleType = vehicle.Type
+		if vehicle.Powertrain.IsValid() {
+			e.Powertrain = vehicle.Powertrain
+		}
+	}
+	if ride.Route != nil {
+		e.DistanceMeters = ride.Route.DistanceMeters
+	}
+	e.CO2Grams = CO2Grams(e.DistanceMeters, e.VehicleType, e.Powertrain)
+	e.SavedGrams = CO2Grams(e.DistanceMeters, e.VehicleType, PowertrainICE) - e.CO2Grams
+	return e
+}
+
+// PrefersGreen reports whether the rider asked for a green ride
+func (r *Ride) PrefersGreen() bool {
+	preference, _ := r.Metadata[RidePreferenceMetadataKey].(string)
+	return RidePreference(preference) == RidePreferenceGreen
+}
+
+// SetEmissions records the ride's estimated emissions
+func (r *Ride) SetEmissions(e *RideEmissions) {
+	if r.Metadata == nil {
+		r.Metadata = make(map[string]any)
+	}
+	r.Metadata[EmissionsMetadataKey] = e
+}
+
+// Emissions gets the ride's estimated emissions, nil when it has none. Read
+// back from storage they are a plain map, so they are decoded again.
+func (r *Ride) Emissions() *RideEmissions {
+	switch v := r.Metadata[EmissionsMetadataKey].(type) {
+	case nil:
+		return nil
+	case *RideEmissions:
+		return v
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var e RideEmissions
+		if json.Unmarshal(raw, &e) != nil {
+			return nil
+		}
+		return &e
+	}
+}
+
+// EmissionsStats sums the estimated emissions of a rider's or business
+// account's completed rides. Rides completed before emissions were
+// estimated are left out.
+type EmissionsStats struct {
+	From             *time.Time `json:"from,omitempty"`
+	To               *time.Time `json:"to,omitempty"`
+	Rides            int64      `json:"rides"`
+	DistanceMeters   int64      `json:"distance_meters"`
+	CO2Grams         int64      `json:"co2_grams"`
+	SavedGrams       int64      `json:"saved_grams"`
+	LowEmissionRides int64      `json:"low_emission_rides"` // in electric or hybrid vehicles
+	GreenRequested   int64      `json:"green_requested"`    // rides requested as green
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCO2Grams(t *testing.T) {
+	tests := []struct {
+		name        string
+		distanceM   int64
+		vehicleType VehicleType
+		powertrain  Powertrain
+		want        int64
+	}{
+		{"car", 10000, VehicleTypeCar, PowertrainICE, 1700},
+		{"hybrid car", 10000, VehicleTypeCar, PowertrainHybrid, 1105},
+		{"electric car", 10000, VehicleTypeCar, PowertrainElectric, 425},
+		{"boda", 5000, VehicleTypeBike, PowertrainICE, 375},
+		{"rounded", 1234, VehicleTypeCar, PowertrainICE, 210},
+		{"unknown type counts as a car", 10000, VehicleType("BOAT"), PowertrainICE, 1700},
+		{"unflagged powertrain", 10000, VehicleTypeSUV, Powertrain(""), 2200},
+		{"no distance", 0, VehicleTypeCar, PowertrainICE, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CO2Grams(tt.distanceM, tt.vehicleType, tt.powertrain); got != tt.want {
+				t.Errorf("CO2Grams() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateRideCO2Grams(t *testing.T) {
+	if got, want := EstimateRideCO2Grams(RideTypeXL, 10000), CO2Grams(10000, VehicleTypeSUV, PowertrainICE); got != want {
+		t.Errorf("EstimateRideCO2Grams(XL) = %d, want %d", got, want)
+	}
+	if got, want := EstimateRideCO2Grams(RideTypeBoda, 10000), CO2Grams(10000, VehicleTypeBike, PowertrainICE); got != want {
+		t.Errorf("EstimateRideCO2Grams(BODA) = %d, want %d", got, want)
+	}
+}
+
+func TestNewRideEmissions(t *testing.T) {
+	ride := &Ride{Type: RideTypeStandard, Route: &RouteInfo{DistanceMeters: 10000}}
+
+	electric := NewRideEmissions(ride, &Vehicle{Type: VehicleTypeCar, Powertrain: PowertrainElectric})
+	if electric.CO2Grams != 425 || electric.SavedGrams != 1275 {
+		t.Errorf("electric car = %+v, want 425 g emitted and 1275 g saved", electric)
+	}
+
+	unknown := NewRideEmissions(ride, nil)
+	if unknown.VehicleType != VehicleTypeCar || unknown.Powertrain != PowertrainICE || unknown.SavedGrams != 0 {
+		t.Errorf("without a vehicle = %+v, want a petrol or diesel car", unknown)
+	}
+}
+
+func TestRideEmissionsMetadata(t *testing.T) {
+	ride := &Ride{Type: RideTypeStandard, Route: &RouteInfo{DistanceMeters: 8000}}
+	if ride.Emissions() != nil {
+		t.Fatal("Emissions() on a ride without any should be nil")
+	}
+
+	want := NewRideEmissions(ride, &Vehicle{Type: VehicleTypeCar, Powertrain: PowertrainHybrid})
+	ride.SetEmissions(want)
+
+	// As read back from storage
+	raw, _ := json.Marshal(ride.Metadata)
+	stored := &Ride{}
+	if err := json.Unmarshal(raw, &stored.Metadata); err != nil {
+		t.Fatal(err)
+	}
+	if got := stored.Emissions(); got == nil || *got != *want {
+		t.Errorf("Emissions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRidePrefersGreen(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]any
+		want     bool
+	}{
+		{"green", map[string]any{RidePreferenceMetadataKey: string(RidePreferenceGreen)}, true},
+		{"no preference", map[string]any{}, false},
+		{"no metadata", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ride := &Ride{Metadata: tt.metadata}
+			if got := ride.PrefersGreen(); got != tt.want {
+				t.Errorf("PrefersGreen() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -11,6 +11,12 @@ var (
 	ErrRideAlreadyAssigned    = errors.New("ride already assigned to a driver")
 	ErrRideNotActive          = errors.New("ride is not active")
 	ErrCannotCancelRide       = errors.New("ride cannot be cancelled in current state")
+	ErrRideRequestInProgress  = errors.New("another ride request from this rider is still being processed")
+	ErrRideConflict           = errors.New("ride was changed by another request")
+	ErrStopNotFound           = errors.New("ride has no such stop")
+	ErrInvalidStopTransition  = errors.New("invalid stop transition")
+	ErrInvalidShareToken      = errors.New("invalid trip share link")
+	ErrShareLinkExpired       = errors.New("trip share link has expired")
 	
 	// Driver errors
 	ErrDriverNotFound         = errors.New("driver not found")
@@ -18,26 +24,197 @@ var (
 	ErrDriverBusy             = errors.New("driver is busy with another ride")
 	ErrDriverNotOnline        = errors.New("driver is not online")
 	ErrDriverAlreadyAssigned  = errors.New("driver already assigned to this ride")
+	ErrDriverRestricted       = errors.New("driver account is suspended, deactivated or under review")
+	ErrDriverNotRestricted    = errors.New("driver has no active sanction")
+	ErrInvalidSanction        = errors.New("invalid sanction")
+	ErrAppealNotAllowed       = errors.New("sanction cannot be appealed")
+	ErrAppealAlreadyPending   = errors.New("an appeal is already pending")
+	ErrAppealNotFound         = errors.New("appeal not found")
+	ErrAppealNotPending       = errors.New("appeal has already been reviewed")
+	ErrBackgroundCheckRequired = errors.New("driver background check has not cleared")
+	ErrBackgroundCheckNotFound = errors.New("background check not found")
+	ErrDriverDocumentExpired  = errors.New("a required driver document has expired")
+	ErrInvalidDriverDocument  = errors.New("invalid driver document")
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	ErrWebhookReplayed        = errors.New("webhook delivery already received")
+	ErrIdentityCheckRequired  = errors.New("driver must pass a selfie check before going online")
+	ErrIdentityCheckLocked    = errors.New("too many failed selfie checks; awaiting manual review")
+	ErrIdentityCheckNotLocked = errors.New("driver is not locked out of selfie checks")
+	ErrProfilePhotoMissing    = errors.New("driver has no profile photo to match against")
+	ErrFaceMatchUnavailable   = errors.New("face match provider unavailable")
 	ErrNoDriversAvailable     = errors.New("no drivers available in the area")
+	ErrPoolTripFull           = errors.New("pool trip has no seat or route for another rider")
 	
 	// Location errors
 	ErrInvalidLocation        = errors.New("invalid location coordinates")
 	ErrLocationOutOfService   = errors.New("location is outside service area")
+	ErrRideTypeUnavailable    = errors.New("ride type is not offered in this city")
+	ErrInvalidScheduleTime    = errors.New("scheduled rides must be booked between 30 minutes and 30 days ahead")
 	ErrRouteNotFound          = errors.New("could not find route between locations")
+	ErrRouteOptionNotFound    = errors.New("route option not found or quote expired")
 	
 	// Pricing errors
 	ErrPricingFailed          = errors.New("failed to calculate price")
 	ErrInvalidPromoCode       = errors.New("invalid or expired promo code")
 	ErrPromoCodeAlreadyUsed   = errors.New("promo code already used")
+	ErrPromoNotEligible       = errors.New("promo code does not apply to this trip")
+	
+	// Promo campaign errors
+	ErrPromoCampaignNotFound  = errors.New("promo campaign not found")
+	ErrInvalidPromoCampaign   = errors.New("invalid promo campaign")
+	ErrPromoCodeTaken         = errors.New("promo code belongs to another campaign")
+	ErrPromoCampaignStatus    = errors.New("promo campaign cannot move to that status")
+	ErrPromoCodeNotFound      = errors.New("promo code is not one of the campaign's codes")
+	
+	// Ride history errors
+	ErrInvalidRideHistoryFilter = errors.New("invalid ride history filter")
+	
+	// Loyalty points errors
+	ErrInsufficientLoyaltyPoints = errors.New("not enough loyalty points")
+	ErrLoyaltyRedemptionTooSmall = errors.New("too few loyalty points to redeem")
+	ErrLoyaltyNotRedeemable   = errors.New("loyalty points cannot be redeemed on this ride")
+	
+	// Scheduled ride claim errors
+	ErrScheduledRideNotClaimable = errors.New("ride is not a scheduled ride open for claiming")
+	ErrScheduledRideClaimed   = errors.New("scheduled ride is already claimed by a driver")
+	ErrScheduledClaimNotFound = errors.New("no active claim on this ride")
+	ErrScheduledClaimLimit    = errors.New("too many scheduled rides claimed")
+	ErrClaimReliabilityLow    = errors.New("reliability score is too low to claim scheduled rides")
 	
 	// Payment errors
 	ErrInsufficientBalance    = errors.New("insufficient wallet balance")
 	ErrPaymentFailed          = errors.New("payment processing failed")
+	ErrSettlementCurrency     = errors.New("rides settle only in their own local currency")
+	ErrUnsupportedCurrency    = errors.New("no exchange rate for currency")
 	
 	// Matching errors
 	ErrMatchingFailed         = errors.New("failed to match driver")
 	ErrMatchingTimeout        = errors.New("matching timeout - no driver accepted")
 	
+	// Refund errors
+	ErrRefundNotFound         = errors.New("refund not found")
+	ErrRefundExceedsFare      = errors.New("refund exceeds refundable fare")
+	ErrRefundNotPending       = errors.New("refund is not awaiting approval")
+	ErrRefundSelfApproval     = errors.New("refund must be approved by a different agent")
+	ErrRefundInvalidReason    = errors.New("invalid refund reason code")
+	ErrRefundInvalidAmount    = errors.New("invalid refund amount")
+	ErrRideNotRefundable      = errors.New("only completed rides can be refunded")
+	ErrRidePaymentNotFound    = errors.New("ride has no payment to refund")
+	
+	// Dispute errors
+	ErrDisputeNotFound        = errors.New("dispute not found")
+	ErrDisputeAlreadyOpen     = errors.New("ride already has an open dispute")
+	ErrDisputeAlreadyResolved = errors.New("ride's fare dispute has already been resolved")
+	ErrDisputeNotOpen         = errors.New("dispute is not open")
+	ErrDisputeWindowClosed    = errors.New("dispute window has closed")
+	ErrDisputeInvalidReason   = errors.New("invalid dispute reason")
+	ErrRideNotDisputable      = errors.New("only completed rides can be disputed")
+	
+	// Safety incident errors
+	ErrSafetyIncidentNotFound = errors.New("safety incident not found")
+	ErrInvalidSafetyTransition = errors.New("safety incident cannot move to that status")
+	ErrSOSNotAllowed          = errors.New("SOS can only be raised by the ride's rider or driver during the ride")
+	ErrSafetyIncidentOpen     = errors.New("ride already has an unresolved safety incident")
+	
+	// Insurance errors
+	ErrRideNotInsured         = errors.New("ride has no insurance cover")
+	ErrInsuranceClaimNotFound = errors.New("insurance claim not found")
+	ErrInsuranceClaimOpen     = errors.New("claimant already has an open claim on this ride")
+	ErrInsuranceClaimWindowClosed = errors.New("insurance claim window has closed")
+	ErrInvalidInsuranceClaim  = errors.New("invalid insurance claim")
+	ErrInvalidClaimTransition = errors.New("insurance claim cannot move to that status")
+	
+	// Rider reliability errors
+	ErrPrepaymentRequired     = errors.New("rider must pay by card, wallet or mobile money")
+	ErrInvalidRiderReport     = errors.New("report type does not apply to this ride")
+	ErrRiderReportExists      = errors.New("this ride already has a report of that type")
+	ErrRiderReportNotFound    = errors.New("rider report not found")
+	ErrRiderReportVoided      = errors.New("rider report is already voided")
+	ErrRiderReportWindowClosed = errors.New("rider report window has closed")
+	
+	// Driver quality errors
+	ErrDriverQualityNotFound  = errors.New("driver has no quality evaluation")
+	
+	// Saved place errors
+	ErrSavedPlaceNotFound     = errors.New("saved place not found")
+	ErrInvalidSavedPlace      = errors.New("saved place needs a kind of HOME, WORK or CUSTOM, a location and, for custom places, a label")
+	ErrSavedPlaceExists       = errors.New("rider already has a saved place of that kind")
+	ErrSavedPlaceLimit        = errors.New("rider has reached the saved place limit")
+	
+	// Favorite and blocked driver errors
+	ErrInvalidDriverPreference = errors.New("drivers can be favorited after a completed ride and blocked after any ride they were assigned")
+	ErrDriverPreferenceNotFound = errors.New("driver is not favorited or blocked")
+	ErrDriverBlocked          = errors.New("driver is blocked; unblock them before favoriting")
+	ErrBlockedByRider         = errors.New("rider has blocked this driver")
+	
+	// Receipt errors
+	ErrReceiptNotFound        = errors.New("receipt not found")
+	ErrReceiptNotAvailable    = errors.New("receipts are issued once a ride is completed")
+	
+	// Lost item errors
+	ErrLostItemNotFound       = errors.New("lost item case not found")
+	ErrLostItemAlreadyOpen    = errors.New("ride already has an open lost item case")
+	ErrLostItemNotReportable  = errors.New("lost items can be reported for completed rides with a driver")
+	ErrLostItemWindowClosed   = errors.New("lost item reporting window has closed")
+	ErrInvalidLostItem        = errors.New("invalid lost item report")
+	ErrInvalidLostItemUpdate  = errors.New("lost item case cannot move to that status")
+	
+	// Ride chat errors
+	ErrRideChatClosed         = errors.New("ride chat is open only while a driver is on the ride")
+	ErrInvalidRideMessage     = errors.New("message must be 1 to 1000 characters")
+	
+	// Business account errors
+	ErrBusinessAccountNotFound = errors.New("business account not found")
+	ErrNotBusinessMember      = errors.New("rider is not a member of the business account")
+	ErrInvalidBusinessAccount = errors.New("invalid business account")
+	ErrInvalidCostCenter      = errors.New("invalid cost center")
+	ErrBusinessRideNotAllowed = errors.New("ride is not allowed by the business account's policy")
+	ErrInvalidStatementMonth  = errors.New("month must be YYYY-MM")
+	
+	// Driver admin errors
+	ErrInvalidDriverSearch    = errors.New("invalid driver search")
+	ErrInvalidVehicleUpdate   = errors.New("invalid vehicle update")
+	ErrVehicleNotFound        = errors.New("driver has no active vehicle")
+	ErrDriverAdminReasonRequired = errors.New("a reason is required to change a driver's record")
+	ErrDriverOnActiveRide     = errors.New("driver is on an active ride")
+	ErrDriverStatusNotStuck   = errors.New("driver is not held on a ride, so there is no status to reset")
+	ErrDriverStatusChanged    = errors.New("driver status changed; reload the driver and try again")
+	
+	// Manual dispatch errors
+	ErrRideNotDispatchable    = errors.New("ride is not waiting for a driver")
+	ErrDispatchReasonRequired = errors.New("a reason is required to dispatch a ride by hand")
+	ErrRideTypeNotSupported   = errors.New("driver's vehicle does not serve this ride type")
+	
+	// Training errors
+	ErrTrainingRequired       = errors.New("driver has not completed the training this work requires")
+	ErrInvalidTrainingCompletion = errors.New("invalid training completion")
+	
+	// Consent errors
+	ErrInvalidConsent         = errors.New("consent needs a known purpose and a policy version it has had")
+	
+	// City configuration errors
+	ErrCityNotFound           = errors.New("city not found")
+	ErrInvalidCityConfig      = errors.New("invalid city configuration")
+	
+	// Earnings errors
+	ErrInvalidEarningsPeriod  = errors.New("period must be day, week or month")
+	
+	// Traffic incident errors
+	ErrIncidentNotFound       = errors.New("incident not found")
+	ErrInvalidIncidentType    = errors.New("incident type must be ROAD_CLOSURE, FLOOD, PROTEST, ACCIDENT, ROADWORKS or CHECKPOINT")
+	ErrInvalidIncidentRadius  = errors.New("incident radius must be at most 5000 meters")
+	ErrInvalidIncidentExpiry  = errors.New("incident must expire within 72 hours")
+	ErrInvalidIncidentDelay   = errors.New("incident delay multiplier must be between 1 and 3")
+	ErrRoadReportNotFound     = errors.New("road report not found")
+	ErrInvalidRoadReport      = errors.New("category must be BLOCKED_ROAD, POLICE_CHECKPOINT or FLOODING")
+	ErrRoadReportClosed       = errors.New("road report has expired or been cleared")
+	
+	// Kill switch errors
+	ErrInvalidKillSwitchFeature = errors.New("feature must be RIDES, DELIVERIES or SURGE")
+	ErrKillSwitchReasonRequired = errors.New("a reason is required to switch a feature off")
+	ErrInvalidKillSwitchExpiry  = errors.New("re-enable time must be in the future and within 7 days")
+	ErrKillSwitchNotFound       = errors.New("feature is not switched off in this city")
+	
 	// General errors
 	ErrInvalidRequest         = errors.New("invalid request")
 	ErrUnauthorized           = errors.New("unauthorized")
@@ -52,27 +229,170 @@ const (
 	ErrCodeInvalidStatusTransition = "INVALID_STATUS_TRANSITION"
 	ErrCodeRideAlreadyAssigned    = "RIDE_ALREADY_ASSIGNED"
 	ErrCodeRideNotActive          = "RIDE_NOT_ACTIVE"
+	ErrCodeRideRequestInProgress  = "RIDE_REQUEST_IN_PROGRESS"
+	ErrCodeRideConflict           = "RIDE_CONFLICT"
 	ErrCodeCannotCancelRide       = "CANNOT_CANCEL_RIDE"
+	ErrCodeStopNotFound           = "STOP_NOT_FOUND"
+	ErrCodeInvalidStopTransition  = "INVALID_STOP_TRANSITION"
+	ErrCodeInvalidShareToken      = "INVALID_SHARE_TOKEN"
+	ErrCodeShareLinkExpired       = "SHARE_LINK_EXPIRED"
 	
 	ErrCodeDriverNotFound         = "DRIVER_NOT_FOUND"
 	ErrCodeDriverNotAvailable     = "DRIVER_NOT_AVAILABLE"
 	ErrCodeDriverBusy             = "DRIVER_BUSY"
 	ErrCodeNoDriversAvailable     = "NO_DRIVERS_AVAILABLE"
+	ErrCodeDriverRestricted       = "DRIVER_RESTRICTED"
+	ErrCodeDriverNotRestricted    = "DRIVER_NOT_RESTRICTED"
+	ErrCodeAppealNotAllowed       = "APPEAL_NOT_ALLOWED"
+	ErrCodeAppealAlreadyPending   = "APPEAL_ALREADY_PENDING"
+	ErrCodeAppealNotFound         = "APPEAL_NOT_FOUND"
+	ErrCodeAppealNotPending       = "APPEAL_NOT_PENDING"
+	ErrCodeBackgroundCheckRequired = "BACKGROUND_CHECK_REQUIRED"
+	ErrCodeBackgroundCheckNotFound = "BACKGROUND_CHECK_NOT_FOUND"
+	ErrCodeDriverDocumentExpired  = "DRIVER_DOCUMENT_EXPIRED"
+	ErrCodeInvalidSignature       = "INVALID_SIGNATURE"
+	ErrCodeWebhookReplayed        = "WEBHOOK_REPLAYED"
+	ErrCodeIdentityCheckRequired  = "IDENTITY_CHECK_REQUIRED"
+	ErrCodeIdentityCheckLocked    = "IDENTITY_CHECK_LOCKED"
+	ErrCodeIdentityCheckNotLocked = "IDENTITY_CHECK_NOT_LOCKED"
+	ErrCodeProfilePhotoMissing    = "PROFILE_PHOTO_MISSING"
+	ErrCodeFaceMatchUnavailable   = "FACE_MATCH_UNAVAILABLE"
 	
 	ErrCodeInvalidLocation        = "INVALID_LOCATION"
 	ErrCodeOutOfService           = "OUT_OF_SERVICE_AREA"
+	ErrCodeRideTypeUnavailable    = "RIDE_TYPE_UNAVAILABLE"
+	ErrCodeInvalidScheduleTime    = "INVALID_SCHEDULE_TIME"
 	ErrCodeRouteNotFound          = "ROUTE_NOT_FOUND"
+	ErrCodeRouteOptionNotFound    = "ROUTE_OPTION_NOT_FOUND"
 	
 	ErrCodePricingFailed          = "PRICING_FAILED"
 	ErrCodeInvalidPromoCode       = "INVALID_PROMO_CODE"
+	ErrCodePromoCodeAlreadyUsed   = "PROMO_CODE_ALREADY_USED"
+	ErrCodePromoNotEligible       = "PROMO_NOT_ELIGIBLE"
+	
+	ErrCodePromoCampaignNotFound  = "PROMO_CAMPAIGN_NOT_FOUND"
+	ErrCodeInvalidPromoCampaign   = "INVALID_PROMO_CAMPAIGN"
+	ErrCodePromoCodeTaken         = "PROMO_CODE_TAKEN"
+	ErrCodePromoCampaignStatus    = "INVALID_PROMO_CAMPAIGN_STATUS"
+	ErrCodePromoCodeNotFound      = "PROMO_CODE_NOT_FOUND"
+	
+	ErrCodeInvalidRideHistoryFilter = "INVALID_RIDE_HISTORY_FILTER"
+	
+	ErrCodeInsufficientLoyaltyPoints = "INSUFFICIENT_LOYALTY_POINTS"
+	ErrCodeLoyaltyRedemptionTooSmall = "LOYALTY_REDEMPTION_TOO_SMALL"
+	ErrCodeLoyaltyNotRedeemable   = "LOYALTY_NOT_REDEEMABLE"
+	
+	ErrCodeScheduledRideNotClaimable = "SCHEDULED_RIDE_NOT_CLAIMABLE"
+	ErrCodeScheduledRideClaimed   = "SCHEDULED_RIDE_CLAIMED"
+	ErrCodeScheduledClaimNotFound = "SCHEDULED_CLAIM_NOT_FOUND"
+	ErrCodeScheduledClaimLimit    = "SCHEDULED_CLAIM_LIMIT"
+	ErrCodeClaimReliabilityLow    = "CLAIM_RELIABILITY_TOO_LOW"
 	
 	ErrCodeInsufficientBalance    = "INSUFFICIENT_BALANCE"
 	ErrCodePaymentFailed          = "PAYMENT_FAILED"
+	ErrCodeSettlementCurrency     = "SETTLEMENT_CURRENCY_MISMATCH"
+	ErrCodeUnsupportedCurrency    = "UNSUPPORTED_CURRENCY"
 	
 	ErrCodeMatchingFailed         = "MATCHING_FAILED"
 	ErrCodeMatchingTimeout        = "MATCHING_TIMEOUT"
 	
+	ErrCodeRefundNotFound         = "REFUND_NOT_FOUND"
+	ErrCodeRefundExceedsFare      = "REFUND_EXCEEDS_FARE"
+	ErrCodeRefundNotPending       = "REFUND_NOT_PENDING"
+	ErrCodeRefundSelfApproval     = "REFUND_SELF_APPROVAL"
+	ErrCodeRideNotRefundable      = "RIDE_NOT_REFUNDABLE"
+	ErrCodeRidePaymentNotFound    = "RIDE_PAYMENT_NOT_FOUND"
+	
+	ErrCodeDisputeNotFound        = "DISPUTE_NOT_FOUND"
+	ErrCodeDisputeAlreadyOpen     = "DISPUTE_ALREADY_OPEN"
+	ErrCodeDisputeAlreadyResolved = "DISPUTE_ALREADY_RESOLVED"
+	ErrCodeDisputeNotOpen         = "DISPUTE_NOT_OPEN"
+	ErrCodeDisputeWindowClosed    = "DISPUTE_WINDOW_CLOSED"
+	ErrCodeRideNotDisputable      = "RIDE_NOT_DISPUTABLE"
+	
+	ErrCodeSafetyIncidentNotFound = "SAFETY_INCIDENT_NOT_FOUND"
+	ErrCodeInvalidSafetyTransition = "INVALID_SAFETY_TRANSITION"
+	ErrCodeSOSNotAllowed          = "SOS_NOT_ALLOWED"
+	
+	ErrCodeRideNotInsured         = "RIDE_NOT_INSURED"
+	ErrCodeInsuranceClaimNotFound = "INSURANCE_CLAIM_NOT_FOUND"
+	ErrCodeInsuranceClaimOpen     = "INSURANCE_CLAIM_OPEN"
+	ErrCodeClaimWindowClosed      = "CLAIM_WINDOW_CLOSED"
+	ErrCodeInvalidInsuranceClaim  = "INVALID_INSURANCE_CLAIM"
+	ErrCodeInvalidClaimTransition = "INVALID_CLAIM_TRANSITION"
+	
+	ErrCodePrepaymentRequired     = "PREPAYMENT_REQUIRED"
+	ErrCodeInvalidRiderReport     = "INVALID_RIDER_REPORT"
+	ErrCodeRiderReportExists      = "RIDER_REPORT_EXISTS"
+	ErrCodeRiderReportNotFound    = "RIDER_REPORT_NOT_FOUND"
+	ErrCodeRiderReportVoided      = "RIDER_REPORT_VOIDED"
+	ErrCodeReportWindowClosed     = "REPORT_WINDOW_CLOSED"
+	
+	ErrCodeSavedPlaceNotFound     = "SAVED_PLACE_NOT_FOUND"
+	ErrCodeInvalidSavedPlace      = "INVALID_SAVED_PLACE"
+	ErrCodeSavedPlaceExists       = "SAVED_PLACE_EXISTS"
+	ErrCodeSavedPlaceLimit        = "SAVED_PLACE_LIMIT"
+	
+	ErrCodeInvalidDriverPreference = "INVALID_DRIVER_PREFERENCE"
+	ErrCodeDriverPreferenceNotFound = "DRIVER_PREFERENCE_NOT_FOUND"
+	ErrCodeDriverBlocked          = "DRIVER_BLOCKED"
+	ErrCodeRideUnavailable        = "RIDE_UNAVAILABLE"
+	
+	ErrCodeInvalidConsent         = "INVALID_CONSENT"
+	
+	ErrCodeReceiptNotAvailable    = "RECEIPT_NOT_AVAILABLE"
+	
+	ErrCodeLostItemNotFound       = "LOST_ITEM_NOT_FOUND"
+	ErrCodeLostItemAlreadyOpen    = "LOST_ITEM_ALREADY_OPEN"
+	ErrCodeLostItemNotReportable  = "LOST_ITEM_NOT_REPORTABLE"
+	ErrCodeLostItemWindowClosed   = "LOST_ITEM_WINDOW_CLOSED"
+	ErrCodeInvalidLostItem        = "INVALID_LOST_ITEM"
+	ErrCodeInvalidLostItemUpdate  = "INVALID_LOST_ITEM_UPDATE"
+	
+	ErrCodeRideChatClosed         = "RIDE_CHAT_CLOSED"
+	ErrCodeInvalidRideMessage     = "INVALID_RIDE_MESSAGE"
+	
+	ErrCodeBusinessAccountNotFound = "BUSINESS_ACCOUNT_NOT_FOUND"
+	ErrCodeNotBusinessMember      = "NOT_BUSINESS_MEMBER"
+	ErrCodeInvalidBusinessAccount = "INVALID_BUSINESS_ACCOUNT"
+	ErrCodeInvalidCostCenter      = "INVALID_COST_CENTER"
+	ErrCodeBusinessRideNotAllowed = "BUSINESS_RIDE_NOT_ALLOWED"
+	ErrCodeInvalidStatementMonth  = "INVALID_STATEMENT_MONTH"
+	
+	ErrCodeInvalidDriverSearch    = "INVALID_DRIVER_SEARCH"
+	ErrCodeInvalidVehicleUpdate   = "INVALID_VEHICLE_UPDATE"
+	ErrCodeVehicleNotFound        = "VEHICLE_NOT_FOUND"
+	ErrCodeDriverAdminReasonRequired = "DRIVER_ADMIN_REASON_REQUIRED"
+	ErrCodeDriverOnActiveRide     = "DRIVER_ON_ACTIVE_RIDE"
+	ErrCodeDriverStatusNotStuck   = "DRIVER_STATUS_NOT_STUCK"
+	ErrCodeDriverStatusChanged    = "DRIVER_STATUS_CHANGED"
+	ErrCodeDriverNotOnline        = "DRIVER_NOT_ONLINE"
+	
+	ErrCodeRideNotDispatchable    = "RIDE_NOT_DISPATCHABLE"
+	ErrCodeDispatchReasonRequired = "DISPATCH_REASON_REQUIRED"
+	ErrCodeRideTypeNotSupported   = "RIDE_TYPE_NOT_SUPPORTED"
+	
+	ErrCodeTrainingRequired       = "TRAINING_REQUIRED"
+	ErrCodeInvalidTrainingCompletion = "INVALID_TRAINING_COMPLETION"
+	
+	ErrCodeCityNotFound           = "CITY_NOT_FOUND"
+	ErrCodeInvalidCityConfig      = "INVALID_CITY_CONFIG"
+	
+	ErrCodeInvalidEarningsPeriod  = "INVALID_EARNINGS_PERIOD"
+	
+	ErrCodeIncidentNotFound       = "INCIDENT_NOT_FOUND"
+	ErrCodeInvalidIncident        = "INVALID_INCIDENT"
+	ErrCodeRoadReportNotFound     = "ROAD_REPORT_NOT_FOUND"
+	ErrCodeInvalidRoadReport      = "INVALID_ROAD_REPORT"
+	ErrCodeRoadReportClosed       = "ROAD_REPORT_CLOSED"
+	
+	ErrCodeFeatureDisabled        = "FEATURE_DISABLED"
+	ErrCodeInvalidKillSwitch      = "INVALID_KILL_SWITCH"
+	ErrCodeKillSwitchNotFound     = "KILL_SWITCH_NOT_FOUND"
+	
 	ErrCodeInvalidRequest         = "INVALID_REQUEST"
+	ErrCodePayloadTooLarge        = "PAYLOAD_TOO_LARGE"
+	ErrCodeNotFound               = "NOT_FOUND"
 	ErrCodeUnauthorized           = "UNAUTHORIZED"
 	ErrCodeForbidden              = "FORBIDDEN"
 	ErrCodeInternal               = "INTERNAL_ERROR"
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FareSnapshotReason is the pricing event a snapshot was taken at
+type FareSnapshotReason string
+
+const (
+	FareSnapshotRideRequest       FareSnapshotReason = "RIDE_REQUEST"
+	FareSnapshotScheduledDispatch FareSnapshotReason = "SCHEDULED_DISPATCH"
+)
+
+// Where a fare's pricing config came from
+const (
+	FareConfigCityBundle      = "CITY_BUNDLE"
+	FareConfigCurrencyDefault = "CURRENCY_DEFAULT"
+)
+
+// How a fare's route distance and duration were obtained
+const (
+	RouteDistanceHaversine = "HAVERSINE"              // straight-line legs through each stop
+	RouteDurationEstimate  = "SPEED_ESTIMATE_TRAFFIC" // average speed for the ride type, scaled by hour of day
+	RouteDistanceRouted    = "ROUTED"                 // a routing provider's road distance
+	RouteDurationRouted    = "ROUTED"                 // a routing provider's travel time
+)
+
+// FareInputs are the pricing engine's inputs to a fare
+type FareInputs struct {
+	ConfigSource         string         `json:"config_source"`
+	ConfigVersion        string         `json:"config_version"` // hash of the fares and controls in force
+	City                 string         `json:"city,omitempty"`
+	Currency             Currency       `json:"currency"`
+	RideType             RideType       `json:"ride_type"`
+	Rates                RideTypeFares  `json:"rates"`
+	BookingFee           int64          `json:"booking_fee"`
+	StopFee              int64          `json:"stop_fee,omitempty"`
+	Commission           float64        `json:"commission_percent"`
+	PriceControls        PriceControls  `json:"price_controls"`
+	SurgeCell            string         `json:"surge_cell,omitempty"`
+	SurgeObserved        float64        `json:"surge_observed"`             // multiplier in the cell at pricing time
+	SurgeUpdatedAt       *time.Time     `json:"surge_updated_at,omitempty"` // when the cell's surge was last computed
+	WeatherSurge         float64        `json:"weather_surge,omitempty"`    // added to the surge for the city's weather
+	SurgeDisabled        bool           `json:"surge_disabled,omitempty"`   // surge was switched off in the city
+	ZoneDiscountObserved float64        `json:"zone_discount_observed"`     // glut discount multiplier in the cell at pricing time
+	ClampedControls      []PriceControl `json:"clamped_controls,omitempty"`
+}
+
+// FareRouteInputs are the route measurements a fare was priced on
+type FareRouteInputs struct {
+	DistanceMeters  int64  `json:"distance_meters"`
+	DurationSeconds int64  `json:"duration_seconds"`
+	DistanceSource  string `json:"distance_source"`
+	DurationSource  string `json:"duration_source"`
+	Stops           int    `json:"stops"`
+}
+
+// FarePromoInputs records how a promo code was evaluated and the loyalty
+// points redeemed against the fare
+type FarePromoInputs struct {
+	Code           string `json:"code,omitempty"`
+	Evaluated      bool   `json:"evaluated"`
+	Discount       int64  `json:"discount"`
+	Points         int64  `json:"points,omitempty"`          // loyalty points redeemed
+	PointsDiscount int64  `json:"points_discount,omitempty"` // what the points took off
+	Note           string `json:"note,omitempty"`
+}
+
+// FareTaxInputs records the tax rules applied to a fare
+type FareTaxInputs struct {
+	Rules  []string `json:"rules"`
+	Amount int64    `json:"amount"`
+	Note   string   `json:"note,omitempty"`
+}
+
+// FareSnapshot is everything used to compute a ride's price, stored when the
+// price is set so it can be reconstructed later
+type FareSnapshot struct {
+	ID        uuid.UUID          `json:"id"`
+	RideID    uuid.UUID          `json:"ride_id"`
+	Reason    FareSnapshotReason `json:"reason"`
+	Inputs    FareInputs         `json:"inputs"`
+	Route     FareRouteInputs    `json:"route"`
+	Promo     FarePromoInputs    `json:"promo"`
+	Tax       FareTaxInputs      `json:"tax"`
+	Price     *PriceBreakdown    `json:"price"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// FareExplanation is a ride's current price and the snapshots behind it,
+// oldest first
+type FareExplanation struct {
+	RideID    uuid.UUID       `json:"ride_id"`
+	Price     *PriceBreakdown `json:"price,omitempty"`
+	Display   *DisplayAmount  `json:"display,omitempty"` // the total in the rider's display currency
+	Snapshots []*FareSnapshot `json:"snapshots"`
+}
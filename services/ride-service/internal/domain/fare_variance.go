@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Fare variance monitoring defaults
+const (
+	DefaultFareVarianceAlertPct = 15.0 // median variance, either direction, that raises an alert
+	FareVarianceMinSampleSize   = 20   // rides a city and ride type needs before it can alert
+	FareVarianceMinTrailPoints  = DisputeMinTrailPoints
+)
+
+// FareVariance compares a ride's quoted fare with the fare metered on the
+// distance and time actually driven
+type FareVariance struct {
+	RideID          uuid.UUID `json:"ride_id"`
+	Market          string    `json:"market"` // city code, or currency for rides outside a city bundle
+	RideType        RideType  `json:"ride_type"`
+	Currency        Currency  `json:"currency"`
+	Measured        bool      `json:"measured"` // false when the GPS trail was too sparse to meter
+	QuotedFare      int64     `json:"quoted_fare"`
+	FinalFare       int64     `json:"final_fare"`
+	VariancePct     float64   `json:"variance_pct"`
+	QuotedDistanceM float64   `json:"quoted_distance_meters"`
+	ActualDistanceM float64   `json:"actual_distance_meters"`
+	CompletedAt     time.Time `json:"completed_at"`
+}
+
+// FareVariancePct is how far the final fare is from the quote, as a
+// percentage of the quote. Positive means the trip cost more than quoted.
+func FareVariancePct(quoted, final int64) float64 {
+	if quoted <= 0 {
+		return 0
+	}
+	return float64(final-quoted) / float64(quoted) * 100
+}
+
+// FareVarianceStat summarises variance for one market and ride type
+type FareVarianceStat struct {
+	Market     string   `json:"market"`
+	RideType   RideType `json:"ride_type"`
+	Rides      int64    `json:"rides"`
+	MedianPct  float64  `json:"median_pct"`
+	P90AbsPct  float64  `json:"p90_abs_pct"`
+	MeanQuoted float64  `json:"mean_quoted"`
+	MeanFinal  float64  `json:"mean_final"`
+	Alerting   bool     `json:"alerting"`
+}
+
+// ShouldAlert reports whether the stat's median variance breaches the
+// threshold on a large enough sample
+func (s *FareVarianceStat) ShouldAlert(thresholdPct float64) bool {
+	return s.Rides >= FareVarianceMinSampleSize && math.Abs(s.MedianPct) > thresholdPct
+}
+
+// FareVarianceReport is the quoted-vs-final fare report for a period
+type FareVarianceReport struct {
+	From         time.Time           `json:"from"`
+	To           time.Time           `json:"to"`
+	ThresholdPct float64             `json:"threshold_pct"`
+	Stats        []*FareVarianceStat `json:"stats"`
+}
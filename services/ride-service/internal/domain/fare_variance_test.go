@@ -0,0 +1,31 @@
+package domain
+
+import "testing"
+
+func TestFareVarianceAlerting(t *testing.T) {
+	if got := FareVariancePct(10000, 12500); got != 25 {
+		t.Errorf("FareVariancePct(10000, 12500) = %v, want 25", got)
+	}
+	if got := FareVariancePct(0, 500); got != 0 {
+		t.Errorf("FareVariancePct(0, 500) = %v, want 0", got)
+	}
+
+	tests := []struct {
+		name string
+		stat FareVarianceStat
+		want bool
+	}{
+		{"within threshold", FareVarianceStat{Rides: 100, MedianPct: 10}, false},
+		{"overcharging", FareVarianceStat{Rides: 100, MedianPct: 20}, true},
+		{"undercharging", FareVarianceStat{Rides: 100, MedianPct: -20}, true},
+		{"sample too small", FareVarianceStat{Rides: FareVarianceMinSampleSize - 1, MedianPct: 50}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stat.ShouldAlert(DefaultFareVarianceAlertPct); got != tt.want {
+				t.Errorf("ShouldAlert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
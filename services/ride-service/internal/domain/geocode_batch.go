@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Batch geocoding settings
+const (
+	BatchGeocodeLimit       = 200                // most addresses in one batch
+	BatchGeocodeConcurrency = 8                  // provider calls in flight per batch
+	BatchGeocodeCacheTTL    = 7 * 24 * time.Hour // addresses rarely move
+)
+
+// GeocodeStatus is the outcome of geocoding one address in a batch
+type GeocodeStatus string
+
+const (
+	GeocodeStatusOK       GeocodeStatus = "OK"
+	GeocodeStatusNotFound GeocodeStatus = "NOT_FOUND"
+	GeocodeStatusInvalid  GeocodeStatus = "INVALID" // blank address
+	GeocodeStatusError    GeocodeStatus = "ERROR"   // provider failed; retry later
+)
+
+// AddressComponents is a geocoded address broken into its parts
+type AddressComponents struct {
+	StreetNumber string `json:"street_number,omitempty"`
+	Street       string `json:"street,omitempty"`
+	Neighborhood string `json:"neighborhood,omitempty"`
+	City         string `json:"city,omitempty"`
+	Region       string `json:"region,omitempty"`
+	PostalCode   string `json:"postal_code,omitempty"`
+	Country      string `json:"country,omitempty"` // ISO 3166-1 alpha-2
+}
+
+// GeocodedAddress is the result for one row of a batch. Confidence runs
+// from 0 to 1; low scores are worth a merchant checking by hand.
+type GeocodedAddress struct {
+	Row              int                `json:"row"`
+	Address          string             `json:"address"`
+	Status           GeocodeStatus      `json:"status"`
+	PlaceID          string             `json:"place_id,omitempty"`
+	FormattedAddress string             `json:"formatted_address,omitempty"`
+	Latitude         float64            `json:"latitude,omitempty"`
+	Longitude        float64            `json:"longitude,omitempty"`
+	Confidence       float64            `json:"confidence"`
+	Components       *AddressComponents `json:"components,omitempty"`
+	Cached           bool               `json:"cached"`
+}
+
+// geocodePrecision is how much each provider location type is trusted
+var geocodePrecision = map[string]float64{
+	"ROOFTOP":            1.0,
+	"RANGE_INTERPOLATED": 0.8,
+	"GEOMETRIC_CENTER":   0.6,
+	"APPROXIMATE":        0.4,
+}
+
+// GeocodeConfidence scores a geocoding match from its location type,
+// lowered when the provider only matched part of the address or found
+// several candidates
+func GeocodeConfidence(locationType string, partialMatch bool, candidates int) float64 {
+	confidence, ok := geocodePrecision[locationType]
+	if !ok {
+		confidence = 0.3
+	}
+	if partialMatch {
+		confidence *= 0.7
+	}
+	if candidates > 1 {
+		confidence *= 0.9
+	}
+	return confidence
+}
+
+// NormalizeAddress folds case and whitespace so repeats of an address share
+// one lookup
+func NormalizeAddress(address string) string {
+	return strings.Join(strings.Fields(strings.ToLower(address)), " ")
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "testing"
+
+func TestGeocodeConfidence(t *testing.T) {
+	exact := GeocodeConfidence("ROOFTOP", false, 1)
+	partial := GeocodeConfidence("ROOFTOP", true, 1)
+	vague := GeocodeConfidence("APPROXIMATE", false, 3)
+
+	if exact != 1 {
+		t.Errorf("exact rooftop match = %v, want 1", exact)
+	}
+	if !(vague < partial && partial < exact) {
+		t.Errorf("confidence should fall with precision: exact %v, partial %v, vague %v", exact, partial, vague)
+	}
+	if GeocodeConfidence("", false, 1) <= 0 {
+		t.Error("unknown location types should still score")
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	if got := NormalizeAddress("  12 Admiralty WAY,\tLekki  "); got != "12 admiralty way, lekki" {
+		t.Errorf("NormalizeAddress = %q", got)
+	}
+}
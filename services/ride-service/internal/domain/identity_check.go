@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdentityCheckStatus is the outcome of a shift-start selfie check
+type IdentityCheckStatus string
+
+const (
+	IdentityCheckPassed     IdentityCheckStatus = "PASSED"
+	IdentityCheckFailed     IdentityCheckStatus = "FAILED"
+	IdentityCheckOverridden IdentityCheckStatus = "OVERRIDDEN" // cleared by manual review after a lockout
+)
+
+const (
+	// FaceMatchThreshold is the minimum provider confidence for a selfie to
+	// match the profile photo
+	FaceMatchThreshold = 0.85
+
+	// MaxIdentityCheckFailures consecutive failures lock the driver out of
+	// going online until support reviews them
+	MaxIdentityCheckFailures = 3
+
+	// IdentityCheckValidity is how long a passed check covers going online,
+	// roughly one shift
+	IdentityCheckValidity = 12 * time.Hour
+)
+
+// IdentityCheck is a selfie submitted by a driver and its face-match result
+// against their profile photo
+type IdentityCheck struct {
+	ID         uuid.UUID           `json:"id"`
+	DriverID   uuid.UUID           `json:"driver_id"`
+	Status     IdentityCheckStatus `json:"status"`
+	Provider   string              `json:"provider,omitempty"`
+	Confidence float64             `json:"confidence"`
+	SelfieURL  string              `json:"selfie_url,omitempty"`
+	Note       string              `json:"note,omitempty"`
+	ReviewedBy *uuid.UUID          `json:"reviewed_by,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// IsPassing reports whether the check verified the driver's identity
+func (c *IdentityCheck) IsPassing() bool {
+	return c.Status == IdentityCheckPassed || c.Status == IdentityCheckOverridden
+}
+
+// IsValidAt reports whether the check still covers going online at the given time
+func (c *IdentityCheck) IsValidAt(now time.Time) bool {
+	return c.IsPassing() && now.Before(c.CreatedAt.Add(IdentityCheckValidity))
+}
+
+// IsIdentityCheckLocked reports whether a driver's most recent checks, newest
+// first, are all failures up to the lockout limit
+func IsIdentityCheckLocked(recent []*IdentityCheck) bool {
+	if len(recent) < MaxIdentityCheckFailures {
+		return false
+	}
+	for _, check := range recent[:MaxIdentityCheckFailures] {
+		if check.Status != IdentityCheckFailed {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsIdentityCheckLocked(t *testing.T) {
+	failed := &IdentityCheck{Status: IdentityCheckFailed}
+	passed := &IdentityCheck{Status: IdentityCheckPassed}
+	overridden := &IdentityCheck{Status: IdentityCheckOverridden}
+
+	tests := []struct {
+		name   string
+		recent []*IdentityCheck
+		want   bool
+	}{
+		{"no checks", nil, false},
+		{"fewer failures than limit", []*IdentityCheck{failed, failed}, false},
+		{"consecutive failures reach limit", []*IdentityCheck{failed, failed, failed}, true},
+		{"pass breaks the streak", []*IdentityCheck{failed, failed, passed, failed}, false},
+		{"override clears the lockout", []*IdentityCheck{overridden, failed, failed, failed}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsIdentityCheckLocked(tt.recent); got != tt.want {
+				t.Errorf("IsIdentityCheckLocked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentityCheckIsValidAt(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC)
+
+	passed := &IdentityCheck{Status: IdentityCheckPassed, CreatedAt: createdAt}
+	if !passed.IsValidAt(createdAt.Add(8 * time.Hour)) {
+		t.Error("expected passed check to be valid within the shift window")
+	}
+	if passed.IsValidAt(createdAt.Add(IdentityCheckValidity)) {
+		t.Error("expected passed check to lapse after the shift window")
+	}
+
+	failed := &IdentityCheck{Status: IdentityCheckFailed, CreatedAt: createdAt}
+	if failed.IsValidAt(createdAt) {
+		t.Error("expected failed check to never be valid")
+	}
+}
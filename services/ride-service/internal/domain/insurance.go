@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultInsuranceClaimWindow is how long after a trip a claim may be filed
+// where the city's bundle does not say
+const DefaultInsuranceClaimWindow = 30 * 24 * time.Hour
+
+// CityInsurance is a market's passenger insurance cover. Every completed
+// ride in an enabled market is attached to the master policy, and the
+// premium is carved out of the fare already charged rather than added to it.
+type CityInsurance struct {
+	Enabled         bool    `json:"enabled"`
+	Provider        string  `json:"provider,omitempty"`
+	MasterPolicy    string  `json:"master_policy,omitempty"`     // the insurer's policy number for the market
+	PremiumPercent  float64 `json:"premium_percent,omitempty"`   // share of the fare paid as premium, e.g. 0.01
+	MinPremium      int64   `json:"min_premium,omitempty"`       // smallest currency unit
+	ClaimWindowDays int     `json:"claim_window_days,omitempty"` // zero is DefaultInsuranceClaimWindow
+}
+
+// Validate checks the insurance cover is usable
+func (c CityInsurance) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	switch {
+	case c.Provider == "" || c.MasterPolicy == "":
+		return fmt.Errorf("insurance needs a provider and master_policy")
+	case c.PremiumPercent < 0 || c.PremiumPercent > 0.2:
+		return fmt.Errorf("insurance premium_percent must be between 0 and 0.2")
+	case c.MinPremium < 0:
+		return fmt.Errorf("insurance min_premium must not be negative")
+	case c.ClaimWindowDays < 0:
+		return fmt.Errorf("insurance claim_window_days must not be negative")
+	}
+	return nil
+}
+
+// Premium returns the premium due on a fare. It is never more than the
+// fare itself.
+func (c CityInsurance) Premium(fare int64) int64 {
+	premium := int64(math.Round(float64(fare) * c.PremiumPercent))
+	if premium < c.MinPremium {
+		premium = c.MinPremium
+	}
+	if premium > fare {
+		premium = fare
+	}
+	return premium
+}
+
+// ClaimWindow returns how long after a trip a claim may be filed
+func (c CityInsurance) ClaimWindow() time.Duration {
+	if c.ClaimWindowDays == 0 {
+		return DefaultInsuranceClaimWindow
+	}
+	return time.Duration(c.ClaimWindowDays) * 24 * time.Hour
+}
+
+// InsurancePolicy is a ride's cover under its market's master policy
+type InsurancePolicy struct {
+	ID            uuid.UUID `json:"id"`
+	RideID        uuid.UUID `json:"ride_id"`
+	City          string    `json:"city"`
+	Provider      string    `json:"provider"`
+	PolicyRef     string    `json:"policy_ref"`
+	Fare          int64     `json:"fare"`
+	Premium       int64     `json:"premium"` // included in the fare
+	Currency      Currency  `json:"currency"`
+	CoverageStart time.Time `json:"coverage_start"`
+	CoverageEnd   time.Time `json:"coverage_end"`
+	ClaimsUntil   time.Time `json:"claims_until"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// InsurancePolicyRef is the reference a ride is known by to the insurer:
+// the master policy number and the ride
+func InsurancePolicyRef(masterPolicy string, rideID uuid.UUID) string {
+	return masterPolicy + "/" + rideID.String()
+}
+
+// Covers reports whether something that happened at t was on the insured trip
+func (p *InsurancePolicy) Covers(t time.Time) bool {
+	return !t.Before(p.CoverageStart) && !t.After(p.CoverageEnd)
+}
+
+// InsuranceClaimType is what a claim is for
+type InsuranceClaimType string
+
+const (
+	InsuranceClaimInjury         InsuranceClaimType = "INJURY"
+	InsuranceClaimPropertyDamage InsuranceClaimType = "PROPERTY_DAMAGE"
+	InsuranceClaimVehicleDamage  InsuranceClaimType = "VEHICLE_DAMAGE"
+	InsuranceClaimOther          InsuranceClaimType = "OTHER"
+)
+
+// IsValid reports whether the claim type is known
+func (t InsuranceClaimType) IsValid() bool {
+	switch t {
+	case InsuranceClaimInjury, InsuranceClaimPropertyDamage, InsuranceClaimVehicleDamage, InsuranceClaimOther:
+		return true
+	}
+	return false
+}
+
+// InsuranceClaimStatus is where a claim is between intake and the insurer's
+// decision
+type InsuranceClaimStatus string
+
+const (
+	InsuranceClaimSubmitted   InsuranceClaimStatus = "SUBMITTED"
+	InsuranceClaimUnderReview InsuranceClaimStatus = "UNDER_REVIEW"
+	InsuranceClaimFiled       InsuranceClaimStatus = "FILED" // lodged with the provider
+	InsuranceClaimSettled     InsuranceClaimStatus = "SETTLED"
+	InsuranceClaimRejected    InsuranceClaimStatus = "REJECTED"
+)
+
+// IsValid reports whether the status is known
+func (s InsuranceClaimStatus) IsValid() bool {
+	switch s {
+	case InsuranceClaimSubmitted, InsuranceClaimUnderReview, InsuranceClaimFiled,
+		InsuranceClaimSettled, InsuranceClaimRejected:
+		return true
+	}
+	return false
+}
+
+// IsClosed reports whether the claim has been decided
+func (s InsuranceClaimStatus) IsClosed() bool {
+	return s == InsuranceClaimSettled || s == InsuranceClaimRejected
+}
+
+// CanTransitionTo reports whether support may move a claim from s to next.
+// Claims are reviewed before they are lodged with the provider, and only
+// the provider settles them; support may reject at any point before that.
+func (s InsuranceClaimStatus) CanTransitionTo(next InsuranceClaimStatus) bool {
+	switch s {
+	case InsuranceClaimSubmitted:
+		return next == InsuranceClaimUnderReview || next == InsuranceClaimRejected
+	case InsuranceClaimUnderReview:
+		return next == InsuranceClaimFiled || next == InsuranceClaimRejected
+	case InsuranceClaimFiled:
+		return next == InsuranceClaimSettled || next == InsuranceClaimRejected
+	}
+	return false
+}
+
+// Who filed a claim
+const (
+	ClaimantRider  = "RIDER"
+	ClaimantDriver = "DRIVER"
+)
+
+// InsuranceClaim is a rider's or driver's claim for something that happened
+// on an insured ride. A claim on a ride with an SOS is linked to the safety
+// incident so ops and the claims desk see each other's side.
+type InsuranceClaim struct {
+	ID               uuid.UUID            `json:"id"`
+	RideID           uuid.UUID            `json:"ride_id"`
+	PolicyID         uuid.UUID            `json:"policy_id"`
+	PolicyRef        string               `json:"policy_ref"`
+	ClaimantID       uuid.UUID            `json:"claimant_id"`
+	ClaimantRole     string               `json:"claimant_role"` // RIDER or DRIVER
+	Type             InsuranceClaimType   `json:"type"`
+	Description      string               `json:"description"`
+	IncidentAt       *time.Time           `json:"incident_at,omitempty"`
+	SafetyIncidentID *uuid.UUID           `json:"safety_incident_id,omitempty"`
+	Status           InsuranceClaimStatus `json:"status"`
+	ProviderClaimRef string               `json:"provider_claim_ref,omitempty"`
+	SettledAmount    int64                `json:"settled_amount,omitempty"`
+	Currency         Currency             `json:"currency"`
+	Note             string               `json:"note,omitempty"` // the latest decision or update from support
+	UpdatedBy        *uuid.UUID           `json:"updated_by,omitempty"`
+	ClosedAt         *time.Time           `json:"closed_at,omitempty"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+}
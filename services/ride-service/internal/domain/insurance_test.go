@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCityInsurancePremium(t *testing.T) {
+	tests := []struct {
+		name  string
+		cover CityInsurance
+		fare  int64
+		want  int64
+	}{
+		{"percent of fare", CityInsurance{PremiumPercent: 0.01}, 250000, 2500},
+		{"rounded", CityInsurance{PremiumPercent: 0.015}, 12345, 185},
+		{"minimum premium", CityInsurance{PremiumPercent: 0.01, MinPremium: 5000}, 250000, 5000},
+		{"never above fare", CityInsurance{MinPremium: 5000}, 3000, 3000},
+		{"free ride", CityInsurance{PremiumPercent: 0.01, MinPremium: 5000}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cover.Premium(tt.fare); got != tt.want {
+				t.Errorf("Premium() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCityInsuranceValidate(t *testing.T) {
+	valid := CityInsurance{Enabled: true, Provider: "Insurer", MasterPolicy: "MP-1", PremiumPercent: 0.01}
+
+	tests := []struct {
+		name    string
+		cover   func(c CityInsurance) CityInsurance
+		wantErr bool
+	}{
+		{"valid", func(c CityInsurance) CityInsurance { return c }, false},
+		{"disabled needs nothing", func(c CityInsurance) CityInsurance { return CityInsurance{} }, false},
+		{"no provider", func(c CityInsurance) CityInsurance { c.Provider = ""; return c }, true},
+		{"no master policy", func(c CityInsurance) CityInsurance { c.MasterPolicy = ""; return c }, true},
+		{"premium too high", func(c CityInsurance) CityInsurance { c.PremiumPercent = 0.5; return c }, true},
+		{"negative window", func(c CityInsurance) CityInsurance { c.ClaimWindowDays = -1; return c }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cover(valid).Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCityInsuranceClaimWindow(t *testing.T) {
+	if got := (CityInsurance{}).ClaimWindow(); got != DefaultInsuranceClaimWindow {
+		t.Errorf("ClaimWindow() = %v, want default %v", got, DefaultInsuranceClaimWindow)
+	}
+	if got := (CityInsurance{ClaimWindowDays: 90}).ClaimWindow(); got != 90*24*time.Hour {
+		t.Errorf("ClaimWindow() = %v, want 90 days", got)
+	}
+}
+
+func TestInsurancePolicyCovers(t *testing.T) {
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	policy := &InsurancePolicy{CoverageStart: start, CoverageEnd: start.Add(30 * time.Minute)}
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{start.Add(-time.Minute), false},
+		{start, true},
+		{start.Add(15 * time.Minute), true},
+		{start.Add(30 * time.Minute), true},
+		{start.Add(31 * time.Minute), false},
+	}
+	for _, tt := range tests {
+		if got := policy.Covers(tt.at); got != tt.want {
+			t.Errorf("Covers(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestInsuranceClaimStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to InsuranceClaimStatus
+		want     bool
+	}{
+		{InsuranceClaimSubmitted, InsuranceClaimUnderReview, true},
+		{InsuranceClaimSubmitted, InsuranceClaimRejected, true},
+		{InsuranceClaimSubmitted, InsuranceClaimFiled, false},
+		{InsuranceClaimUnderReview, InsuranceClaimFiled, true},
+		{InsuranceClaimUnderReview, InsuranceClaimSettled, false},
+		{InsuranceClaimFiled, InsuranceClaimSettled, true},
+		{InsuranceClaimFiled, InsuranceClaimRejected, true},
+		{InsuranceClaimSettled, InsuranceClaimRejected, false},
+		{InsuranceClaimRejected, InsuranceClaimUnderReview, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("CanTransitionTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxKillSwitchDuration is the furthest ahead a timed re-enable can be set
+const MaxKillSwitchDuration = 7 * 24 * time.Hour
+
+// KillSwitchFeature is a feature ops can switch off city by city
+type KillSwitchFeature string
+
+const (
+	KillSwitchRides      KillSwitchFeature = "RIDES"      // new ride requests
+	KillSwitchDeliveries KillSwitchFeature = "DELIVERIES" // new deliveries
+	KillSwitchSurge      KillSwitchFeature = "SURGE"      // demand and weather surge
+)
+
+// IsValid reports whether the feature can be switched off
+func (f KillSwitchFeature) IsValid() bool {
+	switch f {
+	case KillSwitchRides, KillSwitchDeliveries, KillSwitchSurge:
+		return true
+	}
+	return false
+}
+
+// KillSwitch switches a feature off in one city, during outages, protests
+// or severe weather, until ops switch it back on or ReenableAt passes
+type KillSwitch struct {
+	City       string            `json:"city"`
+	CityName   string            `json:"city_name"` // matched against delivery addresses, which carry no city code
+	Feature    KillSwitchFeature `json:"feature"`
+	Reason     string            `json:"reason"`
+	DisabledBy uuid.UUID         `json:"disabled_by"`
+	DisabledAt time.Time         `json:"disabled_at"`
+	ReenableAt *time.Time        `json:"reenable_at,omitempty"`
+}
+
+// Validate checks the switch can be stored
+func (k *KillSwitch) Validate(now time.Time) error {
+	if !k.Feature.IsValid() {
+		return ErrInvalidKillSwitchFeature
+	}
+	if strings.TrimSpace(k.Reason) == "" {
+		return ErrKillSwitchReasonRequired
+	}
+	if k.ReenableAt != nil && (!k.ReenableAt.After(now) || k.ReenableAt.Sub(now) > MaxKillSwitchDuration) {
+		return ErrInvalidKillSwitchExpiry
+	}
+	return nil
+}
+
+// IsActive reports whether the feature is still switched off
+func (k *KillSwitch) IsActive(now time.Time) bool {
+	return k.ReenableAt == nil || now.Before(*k.ReenableAt)
+}
+
+// KillSwitchAction is what happened to a switch, for the audit log
+type KillSwitchAction string
+
+const (
+	KillSwitchDisabled  KillSwitchAction = "DISABLED"
+	KillSwitchEnabled   KillSwitchAction = "ENABLED"
+	KillSwitchReenabled KillSwitchAction = "REENABLED" // the timed re-enable passed
+)
+
+// KillSwitchAudit records one change to a city's switches. ActorID is nil
+// for timed re-enables.
+type KillSwitchAudit struct {
+	ID         uuid.UUID         `json:"id"`
+	City       string            `json:"city"`
+	Feature    KillSwitchFeature `json:"feature"`
+	Action     KillSwitchAction  `json:"action"`
+	Reason     string            `json:"reason,omitempty"`
+	ActorID    *uuid.UUID        `json:"actor_id,omitempty"`
+	ReenableAt *time.Time        `json:"reenable_at,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKillSwitchValidate(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	soon := now.Add(2 * time.Hour)
+	tooLate := now.Add(MaxKillSwitchDuration + time.Hour)
+
+	tests := []struct {
+		name string
+		sw   KillSwitch
+		want error
+	}{
+		{"until switched back on", KillSwitch{Feature: KillSwitchRides, Reason: "outage"}, nil},
+		{"timed", KillSwitch{Feature: KillSwitchSurge, Reason: "flooding", ReenableAt: &soon}, nil},
+		{"unknown feature", KillSwitch{Feature: "PAYMENTS", Reason: "outage"}, ErrInvalidKillSwitchFeature},
+		{"no reason", KillSwitch{Feature: KillSwitchDeliveries, Reason: "  "}, ErrKillSwitchReasonRequired},
+		{"re-enable in the past", KillSwitch{Feature: KillSwitchRides, Reason: "outage", ReenableAt: &past}, ErrInvalidKillSwitchExpiry},
+		{"re-enable too far ahead", KillSwitch{Feature: KillSwitchRides, Reason: "outage", ReenableAt: &tooLate}, ErrInvalidKillSwitchExpiry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sw.Validate(now); got != tt.want {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKillSwitchIsActive(t *testing.T) {
+	now := time.Now()
+	reenable := now.Add(time.Hour)
+	sw := KillSwitch{Feature: KillSwitchRides, Reason: "protest", ReenableAt: &reenable}
+
+	if !sw.IsActive(now) {
+		t.Error("switch should be active before its re-enable time")
+	}
+	if sw.IsActive(reenable) {
+		t.Error("switch should lapse at its re-enable time")
+	}
+	if untimed := (KillSwitch{Feature: KillSwitchRides}); !untimed.IsActive(now.Add(365 * 24 * time.Hour)) {
+		t.Error("untimed switch should stay active")
+	}
+}
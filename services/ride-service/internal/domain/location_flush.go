@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Driver location write coalescing settings. Real-time positions live in
+// Redis; Postgres gets each driver's latest position in batches.
+const (
+	LocationFlushInterval  = 30 * time.Second // a driver's position is written at most this often
+	LocationFlushDistanceM = 300.0            // moving this far from the written position writes at once
+	LocationFlushBatchSize = 500              // most drivers written in one UPDATE
+)
+
+// PersistedLocation is the driver position last written to Postgres
+type PersistedLocation struct {
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	WrittenAt time.Time `json:"written_at"`
+}
+
+// LocationFlushDue returns when a driver's new position should be written:
+// at once for a driver never written or moved a long way since, otherwise
+// one flush interval after the last write
+func LocationFlushDue(persisted *PersistedLocation, loc *DriverLocation, now time.Time) time.Time {
+	if persisted == nil || movedFar(persisted, loc.Location) {
+		return now
+	}
+	due := persisted.WrittenAt.Add(LocationFlushInterval)
+	if due.Before(now) {
+		return now
+	}
+	return due
+}
+
+// movedFar reports whether loc is at least the flush distance from the
+// written position, using an equirectangular approximation
+func movedFar(persisted *PersistedLocation, loc Location) bool {
+	const earthRadiusM = 6371000.0
+	lat := (persisted.Latitude + loc.Latitude) / 2 * math.Pi / 180
+	dx := (loc.Longitude - persisted.Longitude) * math.Pi / 180 * math.Cos(lat)
+	dy := (loc.Latitude - persisted.Latitude) * math.Pi / 180
+	return math.Hypot(dx, dy)*earthRadiusM >= LocationFlushDistanceM
+}
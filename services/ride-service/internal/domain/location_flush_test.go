@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocationFlushDue(t *testing.T) {
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	persisted := &PersistedLocation{Latitude: 6.4281, Longitude: 3.4219, WrittenAt: now.Add(-10 * time.Second)}
+	ping := func(lat, lng float64) *DriverLocation {
+		return &DriverLocation{Location: Location{Latitude: lat, Longitude: lng}, Timestamp: now}
+	}
+
+	if due := LocationFlushDue(nil, ping(6.4281, 3.4219), now); !due.Equal(now) {
+		t.Errorf("a driver never written should be due now, got %v", due)
+	}
+
+	// About 50m from the written position
+	if due := LocationFlushDue(persisted, ping(6.4285, 3.4220), now); !due.Equal(persisted.WrittenAt.Add(LocationFlushInterval)) {
+		t.Errorf("a small move should wait for the flush interval, got %v", due)
+	}
+
+	// About 550m from the written position
+	if due := LocationFlushDue(persisted, ping(6.4331, 3.4219), now); !due.Equal(now) {
+		t.Errorf("a long move should be due now, got %v", due)
+	}
+
+	stale := &PersistedLocation{Latitude: 6.4281, Longitude: 3.4219, WrittenAt: now.Add(-time.Minute)}
+	if due := LocationFlushDue(stale, ping(6.4281, 3.4219), now); !due.Equal(now) {
+		t.Errorf("a position written over an interval ago should be due now, got %v", due)
+	}
+}
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Location history settings
+const (
+	FrequentPlaceResolution = 9   // H3 resolution trip endpoints are bucketed at (roughly a city block)
+	HistoryPlaceLimit       = 50  // most-visited places served per user
+	HistoryPatternLimit     = 100 // most-travelled trip patterns served per user
+	HomeWorkMinTrips        = 3   // trips in a place's typical hours before it is taken as home or work
+)
+
+// FrequentPlace is a place a user's trips start or end at, bucketed by H3
+// cell. The coordinates are the mean of the trip endpoints in the cell; the
+// name and address are the latest ones the user gave.
+type FrequentPlace struct {
+	UserID         uuid.UUID `json:"user_id"`
+	Cell           string    `json:"cell"`
+	Latitude       float64   `json:"latitude"`
+	Longitude      float64   `json:"longitude"`
+	Name           string    `json:"name,omitempty"`
+	Address        string    `json:"address,omitempty"`
+	PlaceID        string    `json:"place_id,omitempty"`
+	Pickups        int64     `json:"pickups"`
+	Dropoffs       int64     `json:"dropoffs"`
+	FirstVisitedAt time.Time `json:"first_visited_at"`
+	LastVisitedAt  time.Time `json:"last_visited_at"`
+}
+
+// Visits is the number of trips that started or ended at the place
+func (p *FrequentPlace) Visits() int64 {
+	return p.Pickups + p.Dropoffs
+}
+
+// TripPattern counts a user's trips between two cells in one weekly slot,
+// in the local time of the pickup city
+type TripPattern struct {
+	UserID          uuid.UUID `json:"user_id"`
+	OriginCell      string    `json:"origin_cell"`
+	DestinationCell string    `json:"destination_cell"`
+	DayOfWeek       int       `json:"day_of_week"` // 0 is Sunday
+	HourOfDay       int       `json:"hour_of_day"`
+	Trips           int64     `json:"trips"`
+	LastTripAt      time.Time `json:"last_trip_at"`
+}
+
+// TripPatternSlot is the weekly slot a trip requested at t falls in, in loc
+func TripPatternSlot(t time.Time, loc *time.Location) (dayOfWeek, hourOfDay int) {
+	local := t.In(loc)
+	return int(local.Weekday()), local.Hour()
+}
+
+// UserLocationHistory is what destination prediction knows about a user:
+// their most-visited places, most-travelled trip patterns and, when their
+// trips make it clear, their home and work
+type UserLocationHistory struct {
+	UserID   uuid.UUID        `json:"user_id"`
+	Places   []*FrequentPlace `json:"places"`
+	Patterns []*TripPattern   `json:"patterns"`
+	Home     *FrequentPlace   `json:"home,omitempty"`
+	Work     *FrequentPlace   `json:"work,omitempty"`
+}
+
+// DetectHomeWork picks the user's home and work from their trip patterns.
+// Home is where evening trips end and early-morning trips start; work is
+// where weekday-morning trips end and weekday-evening trips start. A place
+// needs HomeWorkMinTrips such trips, and work is never the home place.
+// A home or work already set, as SeedHomeWork does from saved places, is
+// kept.
+func (h *UserLocationHistory) DetectHomeWork() {
+	homeScores := make(map[string]int64)
+	workScores := make(map[string]int64)
+	for _, p := range h.Patterns {
+		weekday := p.DayOfWeek >= 1 && p.DayOfWeek <= 5
+		switch {
+		case p.HourOfDay >= 18 || p.HourOfDay < 2:
+			homeScores[p.DestinationCell] += p.Trips
+			if weekday {
+				workScores[p.OriginCell] += p.Trips
+			}
+		case p.HourOfDay >= 5 && p.HourOfDay < 10:
+			homeScores[p.OriginCell] += p.Trips
+			if weekday {
+				workScores[p.DestinationCell] += p.Trips
+			}
+		}
+	}
+
+	if h.Home == nil {
+		h.Home = h.place(topCell(homeScores, ""))
+	}
+	homeCell := ""
+	if h.Home != nil {
+		homeCell = h.Home.Cell
+	}
+	if h.Work == nil {
+		h.Work = h.place(topCell(workScores, homeCell))
+	}
+}
+
+// place finds one of the history's places by cell
+func (h *UserLocationHistory) place(cell string) *FrequentPlace {
+	if cell == "" {
+		return nil
+	}
+	for _, p := range h.Places {
+		if p.Cell == cell {
+			return p
+		}
+	}
+	return nil
+}
+
+// topCell is the highest-scoring cell with at least HomeWorkMinTrips,
+// ignoring exclude. Ties go to the lexically smaller cell so results are
+// stable.
+func topCell(scores map[string]int64, exclude string) string {
+	best, bestScore := "", int64(0)
+	for cell, score := range scores {
+		if cell == exclude || score < HomeWorkMinTrips {
+			continue
+		}
+		if score > bestScore || score == bestScore && cell < best {
+			best, bestScore = cell, score
+		}
+	}
+	return best
+}
+
+// LocationHistoryPreferences are a user's location history settings. Opted
+// out users' trips are not recorded and their history is not used.
+type LocationHistoryPreferences struct {
+	UserID    uuid.UUID `json:"user_id"`
+	OptedOut  bool      `json:"opted_out"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PredictionDataExport is everything stored about a user for prediction, in
+// machine-readable form for data portability
+type PredictionDataExport struct {
+	UserID         uuid.UUID        `json:"user_id"`
+	ExportedAt     time.Time        `json:"exported_at"`
+	OptedOut       bool             `json:"opted_out"`
+	Home           *FrequentPlace   `json:"home,omitempty"`
+	Work           *FrequentPlace   `json:"work,omitempty"`
+	FrequentPlaces []*FrequentPlace `json:"frequent_places"`
+	TripPatterns   []*TripPattern   `json:"trip_patterns"`
+}
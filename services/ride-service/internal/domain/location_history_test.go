@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTripPatternSlot(t *testing.T) {
+	lagos := time.FixedZone("WAT", 3600)
+	// Sunday 23:30 UTC is Monday 00:30 in Lagos
+	at := time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC)
+
+	if day, hour := TripPatternSlot(at, time.UTC); day != 0 || hour != 23 {
+		t.Errorf("UTC slot = %d/%d, want 0/23", day, hour)
+	}
+	if day, hour := TripPatternSlot(at, lagos); day != 1 || hour != 0 {
+		t.Errorf("Lagos slot = %d/%d, want 1/0", day, hour)
+	}
+}
+
+func TestFrequentPlaceVisits(t *testing.T) {
+	p := &FrequentPlace{Pickups: 3, Dropoffs: 4}
+	if p.Visits() != 7 {
+		t.Errorf("Visits() = %d, want 7", p.Visits())
+	}
+}
+
+func TestDetectHomeWork(t *testing.T) {
+	home := &FrequentPlace{Cell: "home"}
+	work := &FrequentPlace{Cell: "work"}
+	gym := &FrequentPlace{Cell: "gym"}
+	h := &UserLocationHistory{
+		Places: []*FrequentPlace{home, work, gym},
+		Patterns: []*TripPattern{
+			{OriginCell: "home", DestinationCell: "work", DayOfWeek: 2, HourOfDay: 8, Trips: 4},
+			{OriginCell: "work", DestinationCell: "home", DayOfWeek: 2, HourOfDay: 18, Trips: 3},
+			{OriginCell: "home", DestinationCell: "gym", DayOfWeek: 6, HourOfDay: 9, Trips: 2},
+		},
+	}
+
+	h.DetectHomeWork()
+	if h.Home != home {
+		t.Errorf("Home = %+v, want home", h.Home)
+	}
+	if h.Work != work {
+		t.Errorf("Work = %+v, want work", h.Work)
+	}
+}
+
+func TestDetectHomeWorkNeedsEnoughTrips(t *testing.T) {
+	h := &UserLocationHistory{
+		Places: []*FrequentPlace{{Cell: "a"}, {Cell: "b"}},
+		Patterns: []*TripPattern{
+			{OriginCell: "a", DestinationCell: "b", DayOfWeek: 3, HourOfDay: 7, Trips: 1},
+			{OriginCell: "b", DestinationCell: "a", DayOfWeek: 3, HourOfDay: 19, Trips: 1},
+		},
+	}
+
+	h.DetectHomeWork()
+	if h.Home != nil || h.Work != nil {
+		t.Errorf("expected no home or work from two trips, got %+v/%+v", h.Home, h.Work)
+	}
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Location history ingestion defaults, used where config leaves them out
+const (
+	DefaultLocationIngestBatchSize     = 5000
+	DefaultLocationIngestFlushInterval = time.Second
+)
+
+// LocationPoint is one GPS fix in a driver's location history
+type LocationPoint struct {
+	DriverID   uuid.UUID `json:"driver_id"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	Heading    float64   `json:"heading"`
+	Speed      float64   `json:"speed"`
+	Accuracy   float64   `json:"accuracy"`
+	H3Cell     string    `json:"h3_cell,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Suggestion ranking weights. Text relevance dominates so a personal place
+// never outranks a search result the rider is clearly typing towards.
+const (
+	SuggestionLimit          = 8
+	SuggestionTextWeight     = 0.6
+	SuggestionPersonalWeight = 0.4
+)
+
+// SuggestionSource labels where a location suggestion came from
+type SuggestionSource string
+
+const (
+	SuggestionSourceSearch   SuggestionSource = "SEARCH"
+	SuggestionSourceHome     SuggestionSource = "HOME"
+	SuggestionSourceWork     SuggestionSource = "WORK"
+	SuggestionSourceFrequent SuggestionSource = "FREQUENT"
+	SuggestionSourcePopular  SuggestionSource = "POPULAR"
+)
+
+// LocationSuggestion is one entry in the autocomplete list
+type LocationSuggestion struct {
+	PlaceID       string           `json:"place_id,omitempty"`
+	MainText      string           `json:"main_text"`
+	SecondaryText string           `json:"secondary_text"`
+	Description   string           `json:"description"`
+	Latitude      float64          `json:"latitude,omitempty"`
+	Longitude     float64          `json:"longitude,omitempty"`
+	Source        SuggestionSource `json:"source"`
+	Score         float64          `json:"-"`
+}
+
+// SuggestionCandidate is a personal or popular place that may be blended
+// into the autocomplete list, with how confident we are the rider wants it
+// (0 to 1)
+type SuggestionCandidate struct {
+	Suggestion LocationSuggestion
+	Confidence float64
+}
+
+// TextMatch scores how well typed input matches text: the share of the
+// input's words that start a word of the text, ignoring case and
+// punctuation
+func TextMatch(input, text string) float64 {
+	inputWords := suggestionWords(input)
+	if len(inputWords) == 0 {
+		return 0
+	}
+	textWords := suggestionWords(text)
+
+	matched := 0
+	for _, iw := range inputWords {
+		for _, tw := range textWords {
+			if strings.HasPrefix(tw, iw) {
+				matched++
+				break
+			}
+		}
+	}
+	return float64(matched) / float64(len(inputWords))
+}
+
+// RankSuggestions merges search results with personal and popular
+// candidates into one list ranked by text relevance plus personal
+// confidence. Search results carry their provider order as relevance;
+// candidates must match the input to be included, and one with the same
+// place as a search result relabels and boosts it instead. Candidates
+// earlier in the list win ties on the same place.
+func RankSuggestions(input string, search []*LocationSuggestion, candidates []*SuggestionCandidate, limit int) []*LocationSuggestion {
+	ranked := make([]*LocationSuggestion, 0, len(search)+len(candidates))
+	byPlace := make(map[string]*LocationSuggestion)
+
+	for i, s := range search {
+		s.Source = SuggestionSourceSearch
+		s.Score = SuggestionTextWeight * (1 - 0.5*float64(i)/float64(len(search)))
+		ranked = append(ranked, s)
+		if s.PlaceID != "" {
+			byPlace[s.PlaceID] = s
+		}
+	}
+
+	boosted := make(map[*LocationSuggestion]bool)
+	for _, c := range candidates {
+		s := c.Suggestion
+		text := TextMatch(input, s.MainText+" "+s.Description)
+		if s.Source == SuggestionSourceHome || s.Source == SuggestionSourceWork {
+			text = max(text, TextMatch(input, string(s.Source)))
+		}
+		if text == 0 {
+			continue
+		}
+		personal := SuggestionPersonalWeight * c.Confidence
+
+		if existing, ok := byPlace[s.PlaceID]; ok && s.PlaceID != "" {
+			if boosted[existing] {
+				continue
+			}
+			boosted[existing] = true
+			if existing.Source == SuggestionSourceSearch {
+				existing.Source = s.Source
+				existing.Latitude, existing.Longitude = s.Latitude, s.Longitude
+			}
+			existing.Score += personal
+			continue
+		}
+
+		s.Score = SuggestionTextWeight*text + personal
+		ranked = append(ranked, &s)
+		boosted[&s] = true
+		if s.PlaceID != "" {
+			byPlace[s.PlaceID] = &s
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// suggestionWords lower-cases text and splits it into words
+func suggestionWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "testing"
+
+func TestTextMatch(t *testing.T) {
+	tests := []struct {
+		input, text string
+		want        float64
+	}{
+		{"ikeja city", "Ikeja City Mall, Obafemi Awolowo Way", 1},
+		{"ike mall", "Ikeja City Mall", 1},
+		{"ikeja airport", "Ikeja City Mall", 0.5},
+		{"lekki", "Ikeja City Mall", 0},
+		{"", "Ikeja City Mall", 0},
+	}
+
+	for _, tt := range tests {
+		if got := TextMatch(tt.input, tt.text); got != tt.want {
+			t.Errorf("TextMatch(%q, %q) = %v, want %v", tt.input, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRankSuggestions(t *testing.T) {
+	search := []*LocationSuggestion{
+		{PlaceID: "p1", MainText: "Ikeja Shopping Plaza", Description: "Ikeja Shopping Plaza, Lagos"},
+		{PlaceID: "p2", MainText: "Ikeja City Mall", Description: "Ikeja City Mall, Lagos"},
+	}
+	candidates := []*SuggestionCandidate{
+		{Suggestion: LocationSuggestion{Source: SuggestionSourceHome, MainText: "12 Allen Avenue", Description: "12 Allen Avenue, Ikeja"}, Confidence: 1},
+		{Suggestion: LocationSuggestion{Source: SuggestionSourceFrequent, PlaceID: "p2", MainText: "Ikeja City Mall", Latitude: 6.61, Longitude: 3.35}, Confidence: 0.8},
+		{Suggestion: LocationSuggestion{Source: SuggestionSourcePopular, PlaceID: "p9", MainText: "Murtala Muhammed Airport"}, Confidence: 0.3},
+	}
+
+	ranked := RankSuggestions("ikeja", search, candidates, SuggestionLimit)
+
+	if len(ranked) != 3 {
+		t.Fatalf("got %d suggestions, want 3 (the airport does not match)", len(ranked))
+	}
+	if ranked[0].Source != SuggestionSourceHome {
+		t.Errorf("first suggestion = %s, want HOME", ranked[0].Source)
+	}
+	if ranked[1].PlaceID != "p2" || ranked[1].Source != SuggestionSourceFrequent || ranked[1].Latitude != 6.61 {
+		t.Errorf("second suggestion = %+v, want the frequent mall merged into its search result", ranked[1])
+	}
+	if ranked[2].PlaceID != "p1" || ranked[2].Source != SuggestionSourceSearch {
+		t.Errorf("third suggestion = %+v, want the plain search result", ranked[2])
+	}
+}
+
+func TestRankSuggestionsHomeByLabel(t *testing.T) {
+	candidates := []*SuggestionCandidate{
+		{Suggestion: LocationSuggestion{Source: SuggestionSourceHome, MainText: "12 Allen Avenue"}, Confidence: 1},
+	}
+
+	ranked := RankSuggestions("hom", nil, candidates, SuggestionLimit)
+	if len(ranked) != 1 || ranked[0].Source != SuggestionSourceHome {
+		t.Errorf("typing hom should suggest home, got %+v", ranked)
+	}
+}
@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LostItemStatus is where a lost item case stands
+type LostItemStatus string
+
+const (
+	LostItemStatusReported        LostItemStatus = "REPORTED"         // waiting for the driver to look
+	LostItemStatusFound           LostItemStatus = "FOUND"            // the driver has the item
+	LostItemStatusNotFound        LostItemStatus = "NOT_FOUND"        // the driver looked and it is not there
+	LostItemStatusReturnScheduled LostItemStatus = "RETURN_SCHEDULED" // a handover time and place are agreed
+	LostItemStatusReturned        LostItemStatus = "RETURNED"
+	LostItemStatusClosed          LostItemStatus = "CLOSED" // withdrawn by the rider or closed by support
+)
+
+// IsTerminal reports whether a case in the status is finished
+func (s LostItemStatus) IsTerminal() bool {
+	return s == LostItemStatusNotFound || s == LostItemStatusReturned || s == LostItemStatusClosed
+}
+
+// LostItemCategory is what kind of item was left behind
+type LostItemCategory string
+
+const (
+	LostItemCategoryPhone      LostItemCategory = "PHONE"
+	LostItemCategoryWallet     LostItemCategory = "WALLET"
+	LostItemCategoryBag        LostItemCategory = "BAG"
+	LostItemCategoryKeys       LostItemCategory = "KEYS"
+	LostItemCategoryDocuments  LostItemCategory = "DOCUMENTS"
+	LostItemCategoryClothing   LostItemCategory = "CLOTHING"
+	LostItemCategoryElectronic LostItemCategory = "ELECTRONICS"
+	LostItemCategoryOther      LostItemCategory = "OTHER"
+)
+
+// IsValid reports whether the category is known
+func (c LostItemCategory) IsValid() bool {
+	switch c {
+	case LostItemCategoryPhone, LostItemCategoryWallet, LostItemCategoryBag, LostItemCategoryKeys,
+		LostItemCategoryDocuments, LostItemCategoryClothing, LostItemCategoryElectronic, LostItemCategoryOther:
+		return true
+	}
+	return false
+}
+
+const (
+	// LostItemWindow is how long after a ride ends its rider may report a
+	// lost item
+	LostItemWindow = 30 * 24 * time.Hour
+
+	// MaxLostItemDescription is the longest item description accepted
+	MaxLostItemDescription = 500
+)
+
+// lostItemTransitions are the statuses each party may move a case to from
+// each status
+var lostItemTransitions = map[RideParty]map[LostItemStatus][]LostItemStatus{
+	RidePartyDriver: {
+		LostItemStatusReported:        {LostItemStatusFound, LostItemStatusNotFound},
+		LostItemStatusFound:           {LostItemStatusReturnScheduled, LostItemStatusReturned},
+		LostItemStatusReturnScheduled: {LostItemStatusReturnScheduled, LostItemStatusReturned},
+	},
+	RidePartyRider: {
+		LostItemStatusReported:        {LostItemStatusClosed},
+		LostItemStatusFound:           {LostItemStatusReturnScheduled, LostItemStatusReturned, LostItemStatusClosed},
+		LostItemStatusReturnScheduled: {LostItemStatusReturnScheduled, LostItemStatusReturned, LostItemStatusClosed},
+	},
+	RidePartySupport: {
+		LostItemStatusReported:        {LostItemStatusFound, LostItemStatusNotFound, LostItemStatusClosed},
+		LostItemStatusFound:           {LostItemStatusReturnScheduled, LostItemStatusReturned, LostItemStatusClosed},
+		LostItemStatusReturnScheduled: {LostItemStatusReturnScheduled, LostItemStatusReturned, LostItemStatusClosed},
+	},
+}
+
+// CanMoveLostItem reports whether a party may move a case between two
+// statuses. A scheduled return may be rescheduled.
+func CanMoveLostItem(party RideParty, from, to LostItemStatus) bool {
+	for _, allowed := range lostItemTransitions[party][from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// LostItemReturn is the agreed handover of a found item
+type LostItemReturn struct {
+	At       time.Time `json:"at"`
+	Location Location  `json:"location"`
+}
+
+// LostItemCase is a rider's report of something left in a driver's vehicle,
+// tracked until the item is returned or the case closes. While it is open
+// the rider and driver reach each other over a masked channel of its own,
+// as the ride's channel closes when the ride ends.
+type LostItemCase struct {
+	ID          uuid.UUID        `json:"id"`
+	RideID      uuid.UUID        `json:"ride_id"`
+	RiderID     uuid.UUID        `json:"rider_id"`
+	DriverID    uuid.UUID        `json:"driver_id"`
+	Category    LostItemCategory `json:"category"`
+	Description string           `json:"description"`
+	Status      LostItemStatus   `json:"status"`
+	Return      *LostItemReturn  `json:"return,omitempty"`
+	Note        string           `json:"note,omitempty"` // latest note from whoever moved the case
+	UpdatedBy   RideParty        `json:"updated_by,omitempty"`
+	Contact     *ContactChannel  `json:"contact,omitempty"` // set on views while the case is open
+	ResolvedAt  *time.Time       `json:"resolved_at,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// NewLostItemCase opens a case for an item left on a ride
+func NewLostItemCase(ride *Ride, category LostItemCategory, description string, now time.Time) (*LostItemCase, error) {
+	description = strings.TrimSpace(description)
+	if !category.IsValid() || description == "" || len(description) > MaxLostItemDescription {
+		return nil, ErrInvalidLostItem
+	}
+	if ride.Status != RideStatusCompleted || ride.DriverID == nil {
+		return nil, ErrLostItemNotReportable
+	}
+	endedAt := ride.UpdatedAt
+	if ride.CompletedAt != nil {
+		endedAt = *ride.CompletedAt
+	}
+	if now.Sub(endedAt) > LostItemWindow {
+		return nil, ErrLostItemWindowClosed
+	}
+
+	return &LostItemCase{
+		ID:          uuid.New(),
+		RideID:      ride.ID,
+		RiderID:     ride.RiderID,
+		DriverID:    *ride.DriverID,
+		Category:    category,
+		Description: description,
+		Status:      LostItemStatusReported,
+		UpdatedBy:   RidePartyRider,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// PartyOf returns how a user relates to the case
+func (c *LostItemCase) PartyOf(userID uuid.UUID, role string) RideParty {
+	switch {
+	case userID != uuid.Nil && c.RiderID == userID:
+		return RidePartyRider
+	case userID != uuid.Nil && c.DriverID == userID:
+		return RidePartyDriver
+	case IsSupportRole(role):
+		return RidePartySupport
+	}
+	return RidePartyNone
+}
+
+// Move moves the case to a status on a party's behalf. A scheduled return
+// needs its handover.
+func (c *LostItemCase) Move(party RideParty, to LostItemStatus, ret *LostItemReturn, note string, now time.Time) error {
+	if !CanMoveLostItem(party, c.Status, to) {
+		return ErrInvalidLostItemUpdate
+	}
+	if to == LostItemStatusReturnScheduled {
+		if ret == nil || ret.At.IsZero() {
+			return ErrInvalidLostItem
+		}
+		c.Return = ret
+	}
+
+	c.Status = to
+	c.Note = strings.TrimSpace(note)
+	c.UpdatedBy = party
+	c.UpdatedAt = now
+	if to.IsTerminal() {
+		c.ResolvedAt = &now
+	}
+	return nil
+}
+
+// ConversationID is the messaging conversation of the case
+func (c *LostItemCase) ConversationID() string {
+	return "lost-item:" + c.ID.String()
+}
+
+// View returns the case with its masked channel while it is open
+func (c *LostItemCase) View() *LostItemCase {
+	view := *c
+	if !c.Status.IsTerminal() {
+		view.Contact = &ContactChannel{Service: MaskedChannelService, ConversationID: c.ConversationID()}
+	}
+	return &view
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewLostItemCase(t *testing.T) {
+	completed := time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+	driverID := uuid.New()
+	completedRide := func() *Ride {
+		return &Ride{ID: uuid.New(), RiderID: uuid.New(), DriverID: &driverID, Status: RideStatusCompleted, CompletedAt: &completed}
+	}
+
+	tests := []struct {
+		name        string
+		ride        *Ride
+		category    LostItemCategory
+		description string
+		now         time.Time
+		want        error
+	}{
+		{"reported", completedRide(), LostItemCategoryPhone, "Black phone in a blue case", completed.Add(time.Hour), nil},
+		{"last day of window", completedRide(), LostItemCategoryBag, "Backpack", completed.Add(LostItemWindow), nil},
+		{"window closed", completedRide(), LostItemCategoryBag, "Backpack", completed.Add(LostItemWindow + time.Minute), ErrLostItemWindowClosed},
+		{"unknown category", completedRide(), "UMBRELLA", "Red umbrella", completed, ErrInvalidLostItem},
+		{"blank description", completedRide(), LostItemCategoryKeys, "   ", completed, ErrInvalidLostItem},
+		{"long description", completedRide(), LostItemCategoryKeys, strings.Repeat("k", MaxLostItemDescription+1), completed, ErrInvalidLostItem},
+		{"ride in progress", &Ride{Status: RideStatusInProgress, DriverID: &driverID}, LostItemCategoryWallet, "Wallet", completed, ErrLostItemNotReportable},
+		{"no driver", &Ride{Status: RideStatusCompleted, CompletedAt: &completed}, LostItemCategoryWallet, "Wallet", completed, ErrLostItemNotReportable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lost, err := NewLostItemCase(tt.ride, tt.category, tt.description, tt.now)
+			if err != tt.want {
+				t.Fatalf("NewLostItemCase() error = %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				return
+			}
+			if lost.Status != LostItemStatusReported || lost.DriverID != driverID || lost.RiderID != tt.ride.RiderID {
+				t.Errorf("NewLostItemCase() = %+v", lost)
+			}
+		})
+	}
+}
+
+func TestCanMoveLostItem(t *testing.T) {
+	tests := []struct {
+		party RideParty
+		from  LostItemStatus
+		to    LostItemStatus
+		want  bool
+	}{
+		{RidePartyDriver, LostItemStatusReported, LostItemStatusFound, true},
+		{RidePartyDriver, LostItemStatusReported, LostItemStatusNotFound, true},
+		{RidePartyDriver, LostItemStatusReported, LostItemStatusClosed, false},
+		{RidePartyRider, LostItemStatusReported, LostItemStatusFound, false},
+		{RidePartyRider, LostItemStatusReported, LostItemStatusClosed, true},
+		{RidePartyRider, LostItemStatusFound, LostItemStatusReturnScheduled, true},
+		{RidePartyDriver, LostItemStatusReturnScheduled, LostItemStatusReturnScheduled, true},
+		{RidePartyDriver, LostItemStatusReturnScheduled, LostItemStatusReturned, true},
+		{RidePartySupport, LostItemStatusReported, LostItemStatusClosed, true},
+		{RidePartySupport, LostItemStatusReturned, LostItemStatusClosed, false},
+		{RidePartyNone, LostItemStatusReported, LostItemStatusFound, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanMoveLostItem(tt.party, tt.from, tt.to); got != tt.want {
+			t.Errorf("CanMoveLostItem(%s, %s, %s) = %v, want %v", tt.party, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestLostItemCaseMove(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	lost := &LostItemCase{ID: uuid.New(), Status: LostItemStatusFound}
+
+	if err := lost.Move(RidePartyDriver, LostItemStatusReturnScheduled, nil, "", now); err != ErrInvalidLostItem {
+		t.Fatalf("Move() without a return error = %v, want %v", err, ErrInvalidLostItem)
+	}
+
+	ret := &LostItemReturn{At: now.Add(24 * time.Hour)}
+	if err := lost.Move(RidePartyDriver, LostItemStatusReturnScheduled, ret, " At the office ", now); err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+	if lost.Return != ret || lost.Note != "At the office" || lost.UpdatedBy != RidePartyDriver || lost.ResolvedAt != nil {
+		t.Errorf("after scheduling = %+v", lost)
+	}
+	if view := lost.View(); view.Contact == nil || view.Contact.ConversationID != "lost-item:"+lost.ID.String() {
+		t.Errorf("open case View().Contact = %+v", view.Contact)
+	}
+
+	if err := lost.Move(RidePartyRider, LostItemStatusReturned, nil, "", now.Add(time.Hour)); err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+	if lost.ResolvedAt == nil || !lost.ResolvedAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("ResolvedAt = %v", lost.ResolvedAt)
+	}
+	if view := lost.View(); view.Contact != nil {
+		t.Error("resolved case View() keeps the contact channel")
+	}
+	if err := lost.Move(RidePartyDriver, LostItemStatusFound, nil, "", now); err != ErrInvalidLostItemUpdate {
+		t.Errorf("Move() from RETURNED error = %v, want %v", err, ErrInvalidLostItemUpdate)
+	}
+}
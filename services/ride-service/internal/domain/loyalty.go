@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Riders earn points on what they spend on completed rides and redeem them
+// against later fares. Points are valued in US dollars at the reference
+// rates, so they keep their worth when a rider rides in another market.
+const (
+	// LoyaltyPointsPerUSD are the points earned per US dollar spent
+	LoyaltyPointsPerUSD = 10
+	// LoyaltyPointValueCents is what one point takes off a fare, in US cents
+	LoyaltyPointValueCents = 1
+	// LoyaltyMinRedemption is the fewest points a rider may redeem at once
+	LoyaltyMinRedemption = 100
+)
+
+// LoyaltyPointsMetadataKey is the ride metadata key holding the points
+// redeemed against its fare, so a cancelled ride gives them back
+const LoyaltyPointsMetadataKey = "loyalty_points_redeemed"
+
+// LoyaltyEntryKind is a kind of change to a rider's points balance
+type LoyaltyEntryKind string
+
+const (
+	// LoyaltyEntryEarn credits the points a completed ride earned
+	LoyaltyEntryEarn LoyaltyEntryKind = "EARN"
+	// LoyaltyEntryRedeem debits the points spent on a ride's fare
+	LoyaltyEntryRedeem LoyaltyEntryKind = "REDEEM"
+	// LoyaltyEntryRelease credits back points redeemed on a ride that was
+	// cancelled or expired
+	LoyaltyEntryRelease LoyaltyEntryKind = "RELEASE"
+)
+
+// LoyaltyEntry is one change to a rider's points balance. A ride has at
+// most one entry of each kind.
+type LoyaltyEntry struct {
+	ID        uuid.UUID        `json:"id"`
+	RiderID   uuid.UUID        `json:"rider_id"`
+	RideID    uuid.UUID        `json:"ride_id"`
+	Kind      LoyaltyEntryKind `json:"kind"`
+	Points    int64            `json:"points"` // negative for redemptions
+	Amount    int64            `json:"amount"` // fare spent, or discount given, in minor units
+	Currency  Currency         `json:"currency"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// LoyaltyBalance is a rider's points and what they take off a fare in the
+// rider's currency
+type LoyaltyBalance struct {
+	RiderID       uuid.UUID       `json:"rider_id"`
+	Points        int64           `json:"points"`
+	Value         int64           `json:"value"`
+	Currency      Currency        `json:"currency"`
+	MinRedemption int64           `json:"min_redemption"`
+	Entries       []*LoyaltyEntry `json:"entries"`
+}
+
+// LoyaltyPointsEarned returns the points earned spending amount minor units
+// of currency, or 0 in a currency with no reference rate
+func LoyaltyPointsEarned(amount int64, currency Currency) int64 {
+	cents, ok := ConvertAmount(amount, currency, CurrencyUSD)
+	if !ok || cents <= 0 {
+		return 0
+	}
+	return cents * LoyaltyPointsPerUSD / 100
+}
+
+// LoyaltyPointsValue returns what points take off a fare in currency, in
+// minor units. It reports false in a currency with no reference rate.
+func LoyaltyPointsValue(points int64, currency Currency) (int64, bool) {
+	return ConvertAmount(points*LoyaltyPointValueCents, CurrencyUSD, currency)
+}
+
+// LoyaltyPointsFor returns the points a discount of amount minor units of
+// currency costs, rounded up, and at most limit. A minimum fare can absorb
+// part of a redemption, and the rider only spends what reached the fare.
+func LoyaltyPointsFor(amount int64, currency Currency, limit int64) int64 {
+	rate, ok := ReferenceRatesPerUSD[currency]
+	if !ok || amount <= 0 {
+		return 0
+	}
+	points := int64(math.Ceil(float64(amount) / rate / LoyaltyPointValueCents))
+	if points > limit {
+		return limit
+	}
+	return points
+}
+
+// CheckLoyaltyRedemption checks a rider may redeem points from balance
+func CheckLoyaltyRedemption(points, balance int64) error {
+	if points < LoyaltyMinRedemption {
+		return ErrLoyaltyRedemptionTooSmall
+	}
+	if points > balance {
+		return ErrInsufficientLoyaltyPoints
+	}
+	return nil
+}
+
+// EarnsLoyaltyPoints reports whether the ride earns its rider points: it
+// completed with a fare the rider paid themselves, rather than their
+// business account
+func (r *Ride) EarnsLoyaltyPoints() bool {
+	return r.Status == RideStatusCompleted && r.Price != nil && r.Price.Total > 0 &&
+		r.PaymentMethod != PaymentMethodBusinessInvoice
+}
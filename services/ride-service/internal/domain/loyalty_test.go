@@ -0,0 +1,107 @@
+package domain
+
+import "testing"
+
+func TestLoyaltyPointsEarned(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		currency Currency
+		want     int64
+	}{
+		{"ten dollars", 1000, CurrencyUSD, 100},
+		{"part of a dollar", 1050, CurrencyUSD, 105},
+		{"naira at reference rate", 1550000, CurrencyNGN, 100},
+		{"nothing spent", 0, CurrencyUSD, 0},
+		{"no reference rate", 1000, Currency("XXX"), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LoyaltyPointsEarned(tt.amount, tt.currency); got != tt.want {
+				t.Errorf("LoyaltyPointsEarned() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoyaltyPointsValue(t *testing.T) {
+	if got, ok := LoyaltyPointsValue(500, CurrencyUSD); !ok || got != 500 {
+		t.Errorf("LoyaltyPointsValue(500, USD) = %d, %v, want 500", got, ok)
+	}
+	if got, ok := LoyaltyPointsValue(100, CurrencyKES); !ok || got != 12900 {
+		t.Errorf("LoyaltyPointsValue(100, KES) = %d, %v, want 12900", got, ok)
+	}
+	if _, ok := LoyaltyPointsValue(100, Currency("XXX")); ok {
+		t.Error("LoyaltyPointsValue() in an unknown currency should not be ok")
+	}
+}
+
+func TestLoyaltyPointsFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		currency Currency
+		limit    int64
+		want     int64
+	}{
+		{"whole redemption", 500, CurrencyUSD, 500, 500},
+		{"minimum fare absorbed some", 320, CurrencyUSD, 500, 320},
+		{"rounded up", 12950, CurrencyKES, 500, 101},
+		{"capped at points offered", 12950, CurrencyKES, 100, 100},
+		{"no discount", 0, CurrencyUSD, 500, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LoyaltyPointsFor(tt.amount, tt.currency, tt.limit); got != tt.want {
+				t.Errorf("LoyaltyPointsFor() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLoyaltyRedemption(t *testing.T) {
+	tests := []struct {
+		name    string
+		points  int64
+		balance int64
+		want    error
+	}{
+		{"within balance", 200, 500, nil},
+		{"whole balance", 500, 500, nil},
+		{"below minimum", LoyaltyMinRedemption - 1, 500, ErrLoyaltyRedemptionTooSmall},
+		{"over balance", 600, 500, ErrInsufficientLoyaltyPoints},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckLoyaltyRedemption(tt.points, tt.balance); err != tt.want {
+				t.Errorf("CheckLoyaltyRedemption() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRideEarnsLoyaltyPoints(t *testing.T) {
+	price := &PriceBreakdown{Total: 1000, Currency: CurrencyUSD}
+
+	tests := []struct {
+		name string
+		ride Ride
+		want bool
+	}{
+		{"completed", Ride{Status: RideStatusCompleted, Price: price, PaymentMethod: PaymentMethodCard}, true},
+		{"cancelled", Ride{Status: RideStatusCancelled, Price: price, PaymentMethod: PaymentMethodCard}, false},
+		{"unpriced", Ride{Status: RideStatusCompleted, PaymentMethod: PaymentMethodCard}, false},
+		{"business invoiced", Ride{Status: RideStatusCompleted, Price: price, PaymentMethod: PaymentMethodBusinessInvoice}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ride.EarnsLoyaltyPoints(); got != tt.want {
+				t.Errorf("EarnsLoyaltyPoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
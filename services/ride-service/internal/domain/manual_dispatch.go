@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ManualDispatchLock is how long ops hold a driver while handing them a
+// ride, so matching cannot offer them another in the meantime
+const ManualDispatchLock = 30 * time.Second
+
+// CheckManualDispatch checks ops can hand the driver a ride of rideType:
+// they are online, not on a ride, and drive a vehicle serving the type
+func (d *Driver) CheckManualDispatch(rideType RideType) error {
+	switch {
+	case d.Status.IsRestricted():
+		return ErrDriverRestricted
+	case d.Status != DriverStatusOnline:
+		return ErrDriverNotAvailable
+	case d.CurrentRideID != nil:
+		return ErrDriverBusy
+	case !d.CanAcceptRideType(rideType):
+		return ErrRideTypeNotSupported
+	}
+	return nil
+}
+
+// DispatchManually assigns the ride to a driver ops chose instead of the
+// one matching would find. The ride must be waiting for a driver now and
+// goes through the same transitions as a matched ride. A MANUAL_DISPATCH
+// event records who chose the driver and why.
+func (r *Ride) DispatchManually(driverID, vehicleID, adminID uuid.UUID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrDispatchReasonRequired
+	}
+	if r.DriverID != nil {
+		return ErrRideAlreadyAssigned
+	}
+
+	from := r.Status
+	switch r.Status {
+	case RideStatusPending:
+		// Scheduled rides are dispatched, to any driver who claimed them,
+		// as pickup approaches
+		if r.ScheduledFor != nil {
+			return ErrRideNotDispatchable
+		}
+		if err := r.UpdateStatus(RideStatusSearching); err != nil {
+			return err
+		}
+	case RideStatusSearching, RideStatusMatched:
+	default:
+		return ErrRideNotDispatchable
+	}
+
+	if err := r.AssignDriver(driverID, vehicleID); err != nil {
+		return err
+	}
+
+	event := NewRideEvent(r.ID, RideEventManualDispatch).
+		WithActor(adminID).
+		WithData("driver_id", driverID).
+		WithData("reason", reason)
+	event.FromStatus = from
+	event.ToStatus = r.Status
+	r.RecordEvent(event)
+	return nil
+}
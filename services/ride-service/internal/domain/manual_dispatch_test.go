@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDriverCheckManualDispatch(t *testing.T) {
+	rideID := uuid.New()
+	vehicle := &Vehicle{SupportedTypes: []RideType{RideTypeStandard}}
+
+	tests := []struct {
+		name     string
+		driver   Driver
+		rideType RideType
+		want     error
+	}{
+		{"online and free", Driver{Status: DriverStatusOnline, Vehicle: vehicle}, RideTypeStandard, nil},
+		{"suspended", Driver{Status: DriverStatusSuspended, Vehicle: vehicle}, RideTypeStandard, ErrDriverRestricted},
+		{"offline", Driver{Status: DriverStatusOffline, Vehicle: vehicle}, RideTypeStandard, ErrDriverNotAvailable},
+		{"on a ride", Driver{Status: DriverStatusOnline, CurrentRideID: &rideID, Vehicle: vehicle}, RideTypeStandard, ErrDriverBusy},
+		{"wrong vehicle", Driver{Status: DriverStatusOnline, Vehicle: vehicle}, RideTypeXL, ErrRideTypeNotSupported},
+		{"no vehicle", Driver{Status: DriverStatusOnline}, RideTypeStandard, ErrRideTypeNotSupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.driver.CheckManualDispatch(tt.rideType); err != tt.want {
+				t.Errorf("CheckManualDispatch() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRideDispatchManually(t *testing.T) {
+	driverID, vehicleID, adminID := uuid.New(), uuid.New(), uuid.New()
+	later := time.Now().Add(2 * time.Hour)
+
+	tests := []struct {
+		name   string
+		ride   Ride
+		reason string
+		want   error
+	}{
+		{name: "searching", ride: Ride{Status: RideStatusSearching}, reason: "VIP pickup"},
+		{name: "matched", ride: Ride{Status: RideStatusMatched}, reason: "VIP pickup"},
+		{name: "pending", ride: Ride{Status: RideStatusPending}, reason: "stuck in matching"},
+		{name: "no reason", ride: Ride{Status: RideStatusSearching}, reason: " ", want: ErrDispatchReasonRequired},
+		{name: "scheduled", ride: Ride{Status: RideStatusPending, ScheduledFor: &later}, reason: "VIP pickup", want: ErrRideNotDispatchable},
+		{name: "already assigned", ride: Ride{Status: RideStatusMatched, DriverID: &driverID}, reason: "VIP pickup", want: ErrRideAlreadyAssigned},
+		{name: "in progress", ride: Ride{Status: RideStatusInProgress}, reason: "VIP pickup", want: ErrRideNotDispatchable},
+		{name: "cancelled", ride: Ride{Status: RideStatusCancelled}, reason: "VIP pickup", want: ErrRideNotDispatchable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ride := tt.ride
+			ride.ID = uuid.New()
+			from := ride.Status
+			err := ride.DispatchManually(driverID, vehicleID, adminID, tt.reason)
+			if err != tt.want {
+				t.Fatalf("DispatchManually() error = %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				if len(ride.PendingEvents()) != 0 {
+					t.Errorf("PendingEvents() = %d, want none after a refused dispatch", len(ride.PendingEvents()))
+				}
+				return
+			}
+
+			if ride.Status != RideStatusAccepted || ride.DriverID == nil || *ride.DriverID != driverID {
+				t.Errorf("ride = %s assigned to %v, want ACCEPTED by %s", ride.Status, ride.DriverID, driverID)
+			}
+			events := ride.PendingEvents()
+			last := events[len(events)-1]
+			if last.Type != RideEventManualDispatch || last.ActorID == nil || *last.ActorID != adminID {
+				t.Fatalf("last event = %+v, want MANUAL_DISPATCH by the admin", last)
+			}
+			if last.FromStatus != from || last.ToStatus != RideStatusAccepted {
+				t.Errorf("event statuses = %s -> %s, want %s -> %s", last.FromStatus, last.ToStatus, from, RideStatusAccepted)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// ExchangeRates are units of each currency per US dollar on one day
+type ExchangeRates struct {
+	PerUSD map[Currency]float64 `json:"per_usd"`
+	AsOf   time.Time            `json:"as_of"`
+	Source string               `json:"source"` // provider name, or "reference" for the built-in rates
+}
+
+// DisplayAmount is an amount converted for a rider to read in a currency
+// they know, such as their home currency while riding abroad. It is never
+// charged: the ride settles in its own currency.
+type DisplayAmount struct {
+	Amount    int64     `json:"amount"`
+	Currency  Currency  `json:"currency"`
+	Formatted string    `json:"formatted"`
+	Rate      float64   `json:"rate"` // display units per unit of the settlement currency
+	RatesAsOf time.Time `json:"rates_as_of"`
+}
+
+// SettlementCurrency returns the currency the ride is charged, refunded and
+// paid out in: that of the city it was priced in. It is "" before the ride
+// is priced.
+func (r *Ride) SettlementCurrency() Currency {
+	if r.Price == nil {
+		return ""
+	}
+	return r.Price.Currency
+}
+
+// CheckSettlementCurrency returns ErrSettlementCurrency unless currency is
+// the ride's settlement currency. Money moves for a ride only in its local
+// currency, whatever the rider was shown.
+func (r *Ride) CheckSettlementCurrency(currency Currency) error {
+	if settlement := r.SettlementCurrency(); settlement == "" || currency != settlement {
+		return ErrSettlementCurrency
+	}
+	return nil
+}
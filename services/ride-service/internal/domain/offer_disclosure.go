@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// Default trip length bands, in metres
+const (
+	DefaultShortTripMeters = 5000.0
+	DefaultLongTripMeters  = 20000.0
+)
+
+// Disclosure is how much of a trip's destination drivers see before
+// accepting
+type Disclosure string
+
+const (
+	DisclosureExact  Disclosure = "EXACT"  // dropoff location and address
+	DisclosureArea   Disclosure = "AREA"   // dropoff area to about a kilometre, without the address
+	DisclosureHidden Disclosure = "HIDDEN" // no dropoff until the trip starts
+)
+
+// TripLength is the band a trip's distance falls in, shown instead of its
+// exact distance and duration
+type TripLength string
+
+const (
+	TripLengthShort  TripLength = "SHORT"
+	TripLengthMedium TripLength = "MEDIUM"
+	TripLengthLong   TripLength = "LONG"
+)
+
+// OfferDisclosure is a market's rules for what trip details drivers see in
+// ride offers before accepting. The zero value shows everything.
+//
+// Ride types in ProtectedRideTypes are programs where drivers must keep a
+// high acceptance rate. Their offers never show more than the destination
+// area, so drivers cannot meet the requirement by cherry-picking long or
+// well-paid trips.
+type OfferDisclosure struct {
+	Destination        Disclosure `json:"destination,omitempty"` // empty is EXACT
+	TripLengthBands    bool       `json:"trip_length_bands"`
+	ShortTripMeters    float64    `json:"short_trip_meters,omitempty"`
+	LongTripMeters     float64    `json:"long_trip_meters,omitempty"`
+	HidePaymentMethod  bool       `json:"hide_payment_method"`
+	ProtectedRideTypes []RideType `json:"protected_ride_types,omitempty"`
+}
+
+// Validate checks the disclosure rules are usable
+func (d OfferDisclosure) Validate() error {
+	switch d.Destination {
+	case "", DisclosureExact, DisclosureArea, DisclosureHidden:
+	default:
+		return fmt.Errorf("offer destination disclosure must be EXACT, AREA or HIDDEN")
+	}
+
+	short, long := d.bands()
+	if d.ShortTripMeters < 0 || d.LongTripMeters < 0 || short >= long {
+		return fmt.Errorf("short_trip_meters must be below long_trip_meters")
+	}
+	return nil
+}
+
+// Apply withholds what the rules hide from a driver's offer. Hiding the
+// destination also bands the trip length, since an exact distance would
+// give it away.
+func (d OfferDisclosure) Apply(offer *RideOffer) {
+	destination := d.Destination
+	if destination == "" {
+		destination = DisclosureExact
+	}
+	if destination == DisclosureExact && d.protected(offer.RideType) {
+		destination = DisclosureArea
+	}
+
+	offer.Destination = destination
+	switch destination {
+	case DisclosureArea:
+		if offer.DropoffLocation != nil {
+			offer.DropoffLocation = &Location{
+				Latitude:  math.Round(offer.DropoffLocation.Latitude*100) / 100,
+				Longitude: math.Round(offer.DropoffLocation.Longitude*100) / 100,
+			}
+		}
+	case DisclosureHidden:
+		offer.DropoffLocation = nil
+	}
+
+	if d.TripLengthBands || destination == DisclosureHidden {
+		offer.TripLengthBand = d.band(offer.TripDistanceMeters)
+		offer.TripDistanceMeters = 0
+		offer.TripDurationSeconds = 0
+	}
+
+	if d.HidePaymentMethod {
+		offer.PaymentMethod = ""
+	}
+}
+
+// band is the trip length band of a distance
+func (d OfferDisclosure) band(distanceM float64) TripLength {
+	short, long := d.bands()
+	switch {
+	case distanceM < short:
+		return TripLengthShort
+	case distanceM < long:
+		return TripLengthMedium
+	default:
+		return TripLengthLong
+	}
+}
+
+// bands returns the band limits, defaulted where unset
+func (d OfferDisclosure) bands() (short, long float64) {
+	short, long = d.ShortTripMeters, d.LongTripMeters
+	if short == 0 {
+		short = DefaultShortTripMeters
+	}
+	if long == 0 {
+		long = DefaultLongTripMeters
+	}
+	return short, long
+}
+
+// protected reports whether a ride type's drivers get gaming protection
+func (d OfferDisclosure) protected(rideType RideType) bool {
+	for _, t := range d.ProtectedRideTypes {
+		if t == rideType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func testOffer(rideType RideType) *RideOffer {
+	ride := &Ride{
+		Type:            rideType,
+		PaymentMethod:   PaymentMethodCash,
+		DropoffLocation: Location{Latitude: 6.4281, Longitude: 3.4219, Address: "12 Admiralty Way, Lekki"},
+	}
+	return NewRideOffer(ride, 800, 180, 24000, 2400, nil, 30*time.Second)
+}
+
+func TestOfferDisclosureZeroValueShowsEverything(t *testing.T) {
+	offer := testOffer(RideTypeStandard)
+	OfferDisclosure{}.Apply(offer)
+
+	if offer.Destination != DisclosureExact || offer.DropoffLocation.Address == "" {
+		t.Errorf("destination = %s %+v, want exact", offer.Destination, offer.DropoffLocation)
+	}
+	if offer.TripDistanceMeters != 24000 || offer.TripLengthBand != "" || offer.PaymentMethod != PaymentMethodCash {
+		t.Errorf("offer = %+v, want trip and payment shown", offer)
+	}
+}
+
+func TestOfferDisclosureArea(t *testing.T) {
+	offer := testOffer(RideTypeStandard)
+	OfferDisclosure{Destination: DisclosureArea, HidePaymentMethod: true}.Apply(offer)
+
+	if offer.DropoffLocation.Address != "" || offer.DropoffLocation.Latitude != 6.43 || offer.DropoffLocation.Longitude != 3.42 {
+		t.Errorf("dropoff = %+v, want the area without the address", offer.DropoffLocation)
+	}
+	if offer.PaymentMethod != "" {
+		t.Errorf("payment method = %s, want hidden", offer.PaymentMethod)
+	}
+}
+
+func TestOfferDisclosureHiddenBandsTripLength(t *testing.T) {
+	offer := testOffer(RideTypeStandard)
+	OfferDisclosure{Destination: DisclosureHidden}.Apply(offer)
+
+	if offer.DropoffLocation != nil || offer.TripDistanceMeters != 0 || offer.TripDurationSeconds != 0 {
+		t.Errorf("offer = %+v, want no dropoff and no exact trip length", offer)
+	}
+	if offer.TripLengthBand != TripLengthLong {
+		t.Errorf("band = %s, want LONG", offer.TripLengthBand)
+	}
+}
+
+func TestOfferDisclosureProtectsRideTypes(t *testing.T) {
+	rules := OfferDisclosure{ProtectedRideTypes: []RideType{RideTypePremium}}
+
+	premium := testOffer(RideTypePremium)
+	rules.Apply(premium)
+	if premium.Destination != DisclosureArea {
+		t.Errorf("protected destination = %s, want AREA", premium.Destination)
+	}
+
+	standard := testOffer(RideTypeStandard)
+	rules.Apply(standard)
+	if standard.Destination != DisclosureExact {
+		t.Errorf("unprotected destination = %s, want EXACT", standard.Destination)
+	}
+}
+
+func TestOfferDisclosureValidate(t *testing.T) {
+	if err := (OfferDisclosure{Destination: "VAGUE"}).Validate(); err == nil {
+		t.Error("unknown destination disclosure should be rejected")
+	}
+	if err := (OfferDisclosure{ShortTripMeters: 30000}).Validate(); err == nil {
+		t.Error("short band above the long band should be rejected")
+	}
+	if err := (OfferDisclosure{}).Validate(); err != nil {
+		t.Errorf("zero value should be valid, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// Pickup ETA preview settings
+const (
+	PickupETAResolution   = 9                // H3 resolution previews are cached at
+	PickupETASearchRadius = 5000.0           // metres searched for available drivers
+	PickupETADriverLimit  = 3                // nearest drivers per ride type routed to the pickup
+	PickupETACacheTTL     = 30 * time.Second // how long a cell's preview is served
+)
+
+// AllRideTypes are the ride types previewed outside configured cities
+var AllRideTypes = []RideType{RideTypeStandard, RideTypePremium, RideTypeXL, RideTypeBoda, RideTypeTricycle}
+
+// PickupETA is how soon the nearest available driver of a ride type could
+// reach a pickup
+type PickupETA struct {
+	RideType      RideType `json:"ride_type"`
+	Available     bool     `json:"available"`
+	ETASeconds    int64    `json:"eta_seconds,omitempty"`
+	ETAMinutes    int      `json:"eta_minutes,omitempty"` // rounded up, for "4 min away"
+	DriversNearby int      `json:"drivers_nearby"`
+}
+
+// NewPickupETA builds a ride type's pickup ETA from the fastest driver's
+// travel time, unavailable when no driver is nearby
+func NewPickupETA(rideType RideType, etaSeconds int64, driversNearby int) *PickupETA {
+	eta := &PickupETA{RideType: rideType, DriversNearby: driversNearby}
+	if driversNearby == 0 {
+		return eta
+	}
+
+	eta.Available = true
+	eta.ETASeconds = etaSeconds
+	eta.ETAMinutes = int((etaSeconds + 59) / 60)
+	if eta.ETAMinutes < 1 {
+		eta.ETAMinutes = 1
+	}
+	return eta
+}
+
+// PickupETAPreview is the pickup ETA of each ride type offered at a
+// location, shown before the rider requests
+type PickupETAPreview struct {
+	Cell       string       `json:"cell"`
+	ETAs       []*PickupETA `json:"etas"`
+	ComputedAt time.Time    `json:"computed_at"`
+}
+
+// ForRideType is the preview's ETA for one ride type, if it is offered
+func (p *PickupETAPreview) ForRideType(rideType RideType) (*PickupETA, bool) {
+	for _, eta := range p.ETAs {
+		if eta.RideType == rideType {
+			return eta, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "testing"
+
+func TestNewPickupETA(t *testing.T) {
+	tests := []struct {
+		name        string
+		seconds     int64
+		drivers     int
+		wantMinutes int
+		available   bool
+	}{
+		{"rounds up", 200, 2, 4, true},
+		{"whole minutes", 240, 1, 4, true},
+		{"at least a minute", 10, 1, 1, true},
+		{"no drivers", 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eta := NewPickupETA(RideTypeStandard, tt.seconds, tt.drivers)
+			if eta.Available != tt.available || eta.ETAMinutes != tt.wantMinutes {
+				t.Errorf("got available=%v minutes=%d, want available=%v minutes=%d",
+					eta.Available, eta.ETAMinutes, tt.available, tt.wantMinutes)
+			}
+		})
+	}
+}
+
+func TestPickupETAPreviewForRideType(t *testing.T) {
+	preview := &PickupETAPreview{ETAs: []*PickupETA{
+		NewPickupETA(RideTypeStandard, 240, 3),
+		NewPickupETA(RideTypeBoda, 0, 0),
+	}}
+
+	if eta, ok := preview.ForRideType(RideTypeBoda); !ok || eta.Available {
+		t.Errorf("BODA = %+v, %v; want offered but unavailable", eta, ok)
+	}
+	if _, ok := preview.ForRideType(RideTypeXL); ok {
+		t.Error("XL is not offered here")
+	}
+}
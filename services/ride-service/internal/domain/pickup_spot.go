@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// Pickup spot learning rules
+const (
+	PickupSpotResolution  = 12              // H3 resolution actual pickup points are bucketed at (a few metres)
+	PickupSpotMaxDistance = 300.0           // metres from the requested pickup beyond which a fix is not the same pickup
+	PickupSpotMinPickups  = 5               // pickups at a spot before it is suggested
+	PickupSuggestionLimit = 3               // most spots suggested per location
+	PickupArrivalMaxAge   = 2 * time.Minute // oldest driver fix that counts as their arrival position
+)
+
+// PickupSpot is where trips requested from a cell or place really started,
+// learned from where drivers were when they arrived
+type PickupSpot struct {
+	RequestKey   string    `json:"request_key"` // requested pickup's H3 cell, or its place key for a known place
+	SpotCell     string    `json:"spot_cell"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	Pickups      int64     `json:"pickups"`
+	LastPickupAt time.Time `json:"last_pickup_at"`
+}
+
+// PickupSuggestion is a spot riders near a location are commonly picked up at
+type PickupSuggestion struct {
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	Pickups        int64   `json:"pickups"`
+	Share          float64 `json:"share"` // of learned pickups for the cell or place
+	DistanceMeters float64 `json:"distance_meters"`
+}
+
+// PickupSuggestions are the suggested pickup spots for a requested location
+type PickupSuggestions struct {
+	Latitude    float64             `json:"latitude"`
+	Longitude   float64             `json:"longitude"`
+	RequestKey  string              `json:"request_key"`
+	Suggestions []*PickupSuggestion `json:"suggestions"`
+}
+
+// PickupPlaceKey is the pickup spot key for trips requested from a known
+// place, such as a mall or station
+func PickupPlaceKey(placeID string) string {
+	return "place:" + placeID
+}
+
+// ActualPickupPoint is where a trip really started: the driver's position
+// on arrival, or else the first GPS fix of the trip
+func ActualPickupPoint(arrival *TrailPoint, trail []TrailPoint) (TrailPoint, bool) {
+	switch {
+	case arrival != nil:
+		return *arrival, true
+	case len(trail) > 0:
+		return trail[0], true
+	default:
+		return TrailPoint{}, false
+	}
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActualPickupPoint(t *testing.T) {
+	now := time.Now()
+	arrival := &TrailPoint{Latitude: 6.4301, Longitude: 3.4201, RecordedAt: now}
+	trail := []TrailPoint{
+		{Latitude: 6.4305, Longitude: 3.4205, RecordedAt: now.Add(time.Minute)},
+		{Latitude: 6.4400, Longitude: 3.4300, RecordedAt: now.Add(2 * time.Minute)},
+	}
+
+	if p, ok := ActualPickupPoint(arrival, trail); !ok || p != *arrival {
+		t.Errorf("with arrival fix got %+v/%v, want the arrival fix", p, ok)
+	}
+	if p, ok := ActualPickupPoint(nil, trail); !ok || p != trail[0] {
+		t.Errorf("without arrival fix got %+v/%v, want the first trail point", p, ok)
+	}
+	if _, ok := ActualPickupPoint(nil, nil); ok {
+		t.Error("expected no pickup point without GPS")
+	}
+}
+
+func TestPickupPlaceKey(t *testing.T) {
+	if key := PickupPlaceKey("ChIJ123"); key != "place:ChIJ123" {
+		t.Errorf("PickupPlaceKey = %q, want place:ChIJ123", key)
+	}
+}
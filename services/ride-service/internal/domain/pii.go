@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Riders and drivers see each other only through the ride: a first name and
+// surname initial, never a phone number, with calls and chat relayed over the
+// ride's masked channel. A driver sees a rider's exact pickup, stops and
+// dropoff only while serving the ride; before it is theirs and after it ends
+// they are coarse (~100m), so drivers do not keep riders' home addresses.
+
+// MaskedChannelService relays masked calls and chat between a ride's parties
+const MaskedChannelService = "messaging"
+
+// ContactChannel references the masked channel a party uses in place of the
+// other party's phone number
+type ContactChannel struct {
+	Service        string `json:"service"`
+	ConversationID string `json:"conversation_id"`
+}
+
+// RideConversationID is the messaging conversation of a ride
+func RideConversationID(rideID uuid.UUID) string {
+	return "ride:" + rideID.String()
+}
+
+// RideContactChannel is the masked channel between a ride's rider and driver
+func RideContactChannel(rideID uuid.UUID) *ContactChannel {
+	return &ContactChannel{
+		Service:        MaskedChannelService,
+		ConversationID: RideConversationID(rideID),
+	}
+}
+
+// SurnameInitial shortens a surname to its initial, e.g. "Okafor" to "O."
+func SurnameInitial(surname string) string {
+	initial, _ := utf8.DecodeRuneInString(strings.TrimSpace(surname))
+	if initial == utf8.RuneError {
+		return ""
+	}
+	return string(unicode.ToUpper(initial)) + "."
+}
+
+// CrossPartyView is a driver as riders may see them: no phone number and
+// only the initial of their surname
+func (d *Driver) CrossPartyView() *Driver {
+	view := *d
+	view.Phone = ""
+	view.LastName = SurnameInitial(d.LastName)
+	return &view
+}
+
+// RideParty is how a user relates to a ride
+type RideParty string
+
+const (
+	RidePartyNone    RideParty = ""
+	RidePartyRider   RideParty = "RIDER"
+	RidePartyDriver  RideParty = "DRIVER"
+	RidePartySupport RideParty = "SUPPORT"
+)
+
+// PartyOf returns how a user relates to the ride
+func (r *Ride) PartyOf(userID uuid.UUID, role string) RideParty {
+	switch {
+	case userID != uuid.Nil && r.RiderID == userID:
+		return RidePartyRider
+	case userID != uuid.Nil && r.DriverID != nil && *r.DriverID == userID:
+		return RidePartyDriver
+	case IsSupportRole(role):
+		return RidePartySupport
+	}
+	return RidePartyNone
+}
+
+// ViewFor returns the ride as the given party may see it, or nil if they
+// may not see it at all. Riders see their whole ride; drivers do not see the
+// rider's promo code or internal metadata, and once the ride ends its
+// locations are coarsened and the route polyline dropped. Both parties get
+// the masked contact channel while the ride is active.
+func (r *Ride) ViewFor(party RideParty) *Ride {
+	switch party {
+	case RidePartySupport:
+		return r
+	case RidePartyRider, RidePartyDriver:
+	default:
+		return nil
+	}
+
+	view := *r
+	if r.IsActive() && r.DriverID != nil {
+		view.Contact = RideContactChannel(r.ID)
+	}
+	if party == RidePartyRider {
+		return &view
+	}
+
+	view.PromoCode = ""
+	view.Metadata = nil
+	if !r.IsActive() {
+		view.PickupLocation = coarseLocation(r.PickupLocation)
+		view.DropoffLocation = coarseLocation(r.DropoffLocation)
+		view.CurrentLocation = nil
+		if len(r.Stops) > 0 {
+			view.Stops = make([]Location, len(r.Stops))
+			for i, stop := range r.Stops {
+				view.Stops[i] = coarseLocation(stop)
+			}
+		}
+		if r.Route != nil {
+			route := *r.Route
+			route.Polyline = ""
+			view.Route = &route
+		}
+	}
+	return &view
+}
+
+// CrossPartyView is a scheduled ride listing as drivers browsing the
+// marketplace may see it, with coarse pickup and dropoff until one of them
+// is assigned the ride
+func (l *ScheduledRideListing) CrossPartyView() *ScheduledRideListing {
+	view := *l
+	view.PickupLocation = coarseLocation(l.PickupLocation)
+	view.DropoffLocation = coarseLocation(l.DropoffLocation)
+	return &view
+}
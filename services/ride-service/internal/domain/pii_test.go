@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSurnameInitial(t *testing.T) {
+	tests := []struct {
+		surname string
+		want    string
+	}{
+		{"Okafor", "O."},
+		{"  mensah", "M."},
+		{"Ṣóbọ̀lá", "Ṣ."},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := SurnameInitial(tt.surname); got != tt.want {
+			t.Errorf("SurnameInitial(%q) = %q, want %q", tt.surname, got, tt.want)
+		}
+	}
+}
+
+func TestDriverCrossPartyView(t *testing.T) {
+	driver := &Driver{FirstName: "Amara", LastName: "Okafor", Phone: "+2348012345678"}
+	view := driver.CrossPartyView()
+	if view.Phone != "" || view.LastName != "O." || view.FirstName != "Amara" {
+		t.Errorf("view = %+v, want no phone and a surname initial", view)
+	}
+	if driver.Phone == "" || driver.LastName != "Okafor" {
+		t.Error("view modified the driver")
+	}
+}
+
+func TestRideViewFor(t *testing.T) {
+	riderID, driverID := uuid.New(), uuid.New()
+	home := Location{Latitude: 6.524379, Longitude: 3.379206, Address: "12 Adeola Odeku St"}
+	newRide := func(status RideStatus) *Ride {
+		return &Ride{
+			ID:              uuid.New(),
+			RiderID:         riderID,
+			DriverID:        &driverID,
+			Status:          status,
+			PickupLocation:  home,
+			DropoffLocation: home,
+			Route:           &RouteInfo{Polyline: "abc"},
+			PromoCode:       "WELCOME",
+			Metadata:        map[string]any{RiderPriorityMetadataKey: RiderPriorityLow},
+		}
+	}
+
+	active := newRide(RideStatusInProgress)
+	if p := active.PartyOf(driverID, ""); p != RidePartyDriver {
+		t.Fatalf("PartyOf(driver) = %q", p)
+	}
+	if p := active.PartyOf(uuid.New(), RoleSupport); p != RidePartySupport {
+		t.Fatalf("PartyOf(support) = %q", p)
+	}
+	if active.ViewFor(active.PartyOf(uuid.New(), "")) != nil {
+		t.Error("a stranger was given a view of the ride")
+	}
+
+	view := active.ViewFor(RidePartyDriver)
+	if view.PickupLocation.Address != home.Address {
+		t.Error("driver lost the exact pickup during the ride")
+	}
+	if view.PromoCode != "" || view.Metadata != nil {
+		t.Error("driver sees the rider's promo code or metadata")
+	}
+	if view.Contact == nil || view.Contact.ConversationID != RideConversationID(active.ID) {
+		t.Errorf("contact = %+v, want the ride's masked channel", view.Contact)
+	}
+
+	completed := newRide(RideStatusCompleted)
+	view = completed.ViewFor(RidePartyDriver)
+	if view.PickupLocation.Address != "" || view.PickupLocation.Latitude != 6.524 {
+		t.Errorf("pickup = %+v, want coarse after the ride", view.PickupLocation)
+	}
+	if view.Route.Polyline != "" || completed.Route.Polyline == "" {
+		t.Error("polyline kept for the driver or dropped from the ride")
+	}
+	if view.Contact != nil {
+		t.Error("contact channel offered after the ride")
+	}
+
+	if view := completed.ViewFor(RidePartyRider); view.PickupLocation.Latitude != home.Latitude || view.PromoCode == "" {
+		t.Error("rider's own view was redacted")
+	}
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Pool limits
+const (
+	// MaxPoolRiders is how many pool rides one car carries at once
+	MaxPoolRiders = 3
+
+	// MaxPoolDetour is how much longer than their direct trip a pool rider's
+	// time in the car may get as co-riders are picked up and dropped off
+	MaxPoolDetour = 1.5
+
+	// MaxPoolPickupMeters is how far the driver may drive before picking up
+	// a rider joining the trip
+	MaxPoolPickupMeters = 5000.0
+
+	// MaxPoolHeadingDiff is how far apart in degrees the directions of two
+	// rides may be for them to share a car
+	MaxPoolHeadingDiff = 45.0
+)
+
+// PoolStopType is what happens at a pool stop
+type PoolStopType string
+
+const (
+	PoolStopPickup  PoolStopType = "PICKUP"
+	PoolStopDropoff PoolStopType = "DROPOFF"
+)
+
+// PoolStop is one pickup or dropoff on a pool trip
+type PoolStop struct {
+	RideID   uuid.UUID    `json:"ride_id"`
+	Type     PoolStopType `json:"type"`
+	Location Location     `json:"location"`
+}
+
+// PoolRider is a ride sharing a pool trip
+type PoolRider struct {
+	RideID         uuid.UUID `json:"ride_id"`
+	DirectMeters   float64   `json:"direct_meters"` // pickup to dropoff on their own
+	HeadingDegrees float64   `json:"heading_degrees"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
+
+// PoolTrip is a driver's run carrying pool rides, with the stops still to
+// make in order
+type PoolTrip struct {
+	ID        uuid.UUID    `json:"id"`
+	DriverID  uuid.UUID    `json:"driver_id"`
+	Riders    []*PoolRider `json:"riders"`
+	Stops     []PoolStop   `json:"stops"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// HasSeat reports whether another rider fits in the car
+func (t *PoolTrip) HasSeat() bool {
+	return len(t.Riders) < MaxPoolRiders
+}
+
+// Rider returns the trip's rider for a ride, or nil
+func (t *PoolTrip) Rider(rideID uuid.UUID) *PoolRider {
+	for _, r := range t.Riders {
+		if r.RideID == rideID {
+			return r
+		}
+	}
+	return nil
+}
+
+// CompleteStop removes a stop the driver has made. A rider leaves the trip
+// at their dropoff.
+func (t *PoolTrip) CompleteStop(rideID uuid.UUID, stopType PoolStopType) {
+	t.removeStops(rideID, stopType)
+	if stopType == PoolStopDropoff {
+		t.removeRider(rideID)
+	}
+}
+
+// RemoveRide takes a cancelled ride off the trip
+func (t *PoolTrip) RemoveRide(rideID uuid.UUID) {
+	t.removeStops(rideID, PoolStopPickup)
+	t.removeStops(rideID, PoolStopDropoff)
+	t.removeRider(rideID)
+}
+
+// IsEmpty reports whether the trip has no riders left
+func (t *PoolTrip) IsEmpty() bool {
+	return len(t.Riders) == 0
+}
+
+func (t *PoolTrip) removeStops(rideID uuid.UUID, stopType PoolStopType) {
+	stops := t.Stops[:0]
+	for _, stop := range t.Stops {
+		if stop.RideID != rideID || stop.Type != stopType {
+			stops = append(stops, stop)
+		}
+	}
+	t.Stops = stops
+	t.UpdatedAt = time.Now().UTC()
+}
+
+func (t *PoolTrip) removeRider(rideID uuid.UUID) {
+	riders := t.Riders[:0]
+	for _, r := range t.Riders {
+		if r.RideID != rideID {
+			riders = append(riders, r)
+		}
+	}
+	t.Riders = riders
+	t.UpdatedAt = time.Now().UTC()
+}
+
+// PoolFareShare is one rider's part in splitting a pool trip's fare
+type PoolFareShare struct {
+	RideID       uuid.UUID       `json:"ride_id"`
+	DirectMeters float64         `json:"direct_meters"`
+	Quoted       *PriceBreakdown `json:"quoted"` // the pool fare the rider was quoted alone
+}
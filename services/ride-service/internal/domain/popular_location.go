@@ -0,0 +1,84 @@
+package domain
+
+import "time"
+
+// Popular location rules
+const (
+	PopularLocationWindow     = 30 * 24 * time.Hour // completed rides the ranking looks back over
+	PopularLocationMinRiders  = 10                  // distinct riders before a destination is shown to anyone
+	PopularLocationLimit      = 20                  // destinations kept per city
+	PopularLocationRefreshTTL = 48 * time.Hour      // how long a computed ranking is served if refreshes stop
+)
+
+// PlaceCategory is a coarse category for a destination
+type PlaceCategory string
+
+const (
+	PlaceCategoryMall       PlaceCategory = "MALL"
+	PlaceCategoryTransit    PlaceCategory = "TRANSIT"
+	PlaceCategoryAirport    PlaceCategory = "AIRPORT"
+	PlaceCategoryRestaurant PlaceCategory = "RESTAURANT"
+	PlaceCategoryHotel      PlaceCategory = "HOTEL"
+	PlaceCategoryHealth     PlaceCategory = "HEALTH"
+	PlaceCategoryEducation  PlaceCategory = "EDUCATION"
+	PlaceCategoryWorship    PlaceCategory = "WORSHIP"
+	PlaceCategoryOther      PlaceCategory = "OTHER"
+)
+
+// placeTypeCategories maps map-provider place types to categories, most
+// specific first
+var placeTypeCategories = []struct {
+	placeType string
+	category  PlaceCategory
+}{
+	{"airport", PlaceCategoryAirport},
+	{"shopping_mall", PlaceCategoryMall},
+	{"department_store", PlaceCategoryMall},
+	{"bus_station", PlaceCategoryTransit},
+	{"train_station", PlaceCategoryTransit},
+	{"transit_station", PlaceCategoryTransit},
+	{"subway_station", PlaceCategoryTransit},
+	{"light_rail_station", PlaceCategoryTransit},
+	{"restaurant", PlaceCategoryRestaurant},
+	{"cafe", PlaceCategoryRestaurant},
+	{"bar", PlaceCategoryRestaurant},
+	{"meal_takeaway", PlaceCategoryRestaurant},
+	{"lodging", PlaceCategoryHotel},
+	{"hospital", PlaceCategoryHealth},
+	{"pharmacy", PlaceCategoryHealth},
+	{"university", PlaceCategoryEducation},
+	{"school", PlaceCategoryEducation},
+	{"church", PlaceCategoryWorship},
+	{"mosque", PlaceCategoryWorship},
+	{"place_of_worship", PlaceCategoryWorship},
+}
+
+// PlaceCategoryFromTypes categorizes a place by its map-provider types
+func PlaceCategoryFromTypes(types []string) PlaceCategory {
+	for _, m := range placeTypeCategories {
+		for _, t := range types {
+			if t == m.placeType {
+				return m.category
+			}
+		}
+	}
+	return PlaceCategoryOther
+}
+
+// PopularLocation is a destination many different riders in a city travel to
+type PopularLocation struct {
+	Latitude  float64       `json:"latitude"`
+	Longitude float64       `json:"longitude"`
+	Name      string        `json:"name,omitempty"`
+	Address   string        `json:"address,omitempty"`
+	PlaceID   string        `json:"place_id,omitempty"`
+	Category  PlaceCategory `json:"category"`
+	Trips     int64         `json:"trips"`
+	Riders    int64         `json:"riders"`
+}
+
+// PopularLocations are each city's top destinations, most travelled first
+type PopularLocations struct {
+	ComputedAt time.Time                     `json:"computed_at"`
+	Cities     map[string][]*PopularLocation `json:"cities"`
+}
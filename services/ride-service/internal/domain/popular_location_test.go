@@ -0,0 +1,22 @@
+package domain
+
+import "testing"
+
+func TestPlaceCategoryFromTypes(t *testing.T) {
+	tests := []struct {
+		types []string
+		want  PlaceCategory
+	}{
+		{[]string{"shopping_mall", "point_of_interest", "establishment"}, PlaceCategoryMall},
+		{[]string{"restaurant", "food", "airport"}, PlaceCategoryAirport},
+		{[]string{"transit_station", "bus_station"}, PlaceCategoryTransit},
+		{[]string{"point_of_interest"}, PlaceCategoryOther},
+		{nil, PlaceCategoryOther},
+	}
+
+	for _, tt := range tests {
+		if got := PlaceCategoryFromTypes(tt.types); got != tt.want {
+			t.Errorf("PlaceCategoryFromTypes(%v) = %s, want %s", tt.types, got, tt.want)
+		}
+	}
+}
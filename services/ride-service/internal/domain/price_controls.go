@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PriceControls are a regulator's limits on fares in a market. Amounts are in
+// the smallest currency unit; zero means no limit.
+type PriceControls struct {
+	MaxSurgeMultiplier float64 `json:"max_surge_multiplier,omitempty"`
+	MaxPerKmRate       int64   `json:"max_per_km_rate,omitempty"` // distance charge per km, surge included
+	MinPerKmRate       int64   `json:"min_per_km_rate,omitempty"`
+	MinFare            int64   `json:"min_fare,omitempty"`
+}
+
+// Validate checks the limits are consistent with each other
+func (c PriceControls) Validate() error {
+	switch {
+	case c.MaxSurgeMultiplier != 0 && c.MaxSurgeMultiplier < 1:
+		return fmt.Errorf("max_surge_multiplier must be at least 1")
+	case c.MaxPerKmRate < 0 || c.MinPerKmRate < 0 || c.MinFare < 0:
+		return fmt.Errorf("price controls must not be negative")
+	case c.MaxPerKmRate > 0 && c.MinPerKmRate > c.MaxPerKmRate:
+		return fmt.Errorf("min_per_km_rate must not exceed max_per_km_rate")
+	}
+	return nil
+}
+
+// IsZero reports whether no limit is set
+func (c PriceControls) IsZero() bool {
+	return c == PriceControls{}
+}
+
+// Strictest combines two sets of limits, keeping the lower maximum and the
+// higher minimum of each
+func (c PriceControls) Strictest(other PriceControls) PriceControls {
+	lowerMax := func(a, b int64) int64 {
+		if a == 0 || (b != 0 && b < a) {
+			return b
+		}
+		return a
+	}
+	higherMin := func(a, b int64) int64 {
+		if b > a {
+			return b
+		}
+		return a
+	}
+
+	result := PriceControls{
+		MaxSurgeMultiplier: c.MaxSurgeMultiplier,
+		MaxPerKmRate:       lowerMax(c.MaxPerKmRate, other.MaxPerKmRate),
+		MinPerKmRate:       higherMin(c.MinPerKmRate, other.MinPerKmRate),
+		MinFare:            higherMin(c.MinFare, other.MinFare),
+	}
+	if result.MaxSurgeMultiplier == 0 || (other.MaxSurgeMultiplier != 0 && other.MaxSurgeMultiplier < result.MaxSurgeMultiplier) {
+		result.MaxSurgeMultiplier = other.MaxSurgeMultiplier
+	}
+	return result
+}
+
+// PriceControl identifies one regulatory limit
+type PriceControl string
+
+const (
+	PriceControlMaxSurge     PriceControl = "MAX_SURGE"
+	PriceControlMaxPerKmRate PriceControl = "MAX_PER_KM_RATE"
+	PriceControlMinPerKmRate PriceControl = "MIN_PER_KM_RATE"
+	PriceControlMinFare      PriceControl = "MIN_FARE"
+)
+
+// PriceControlViolation records a price the engine computed outside a
+// market's limits, and the value it was clamped to
+type PriceControlViolation struct {
+	ID        uuid.UUID    `json:"id"`
+	Market    string       `json:"market"` // city code, or currency for markets without a bundle
+	Currency  Currency     `json:"currency"`
+	RideType  RideType     `json:"ride_type"`
+	Control   PriceControl `json:"control"`
+	Limit     float64      `json:"limit"`
+	Computed  float64      `json:"computed"`
+	Applied   float64      `json:"applied"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// PriceControlReportRow summarises violations of one control in one market
+type PriceControlReportRow struct {
+	Market      string       `json:"market"`
+	Currency    Currency     `json:"currency"`
+	Control     PriceControl `json:"control"`
+	Limit       float64      `json:"limit"`
+	Violations  int64        `json:"violations"`
+	WorstCase   float64      `json:"worst_case"` // computed value furthest past the limit
+	FirstSeenAt time.Time    `json:"first_seen_at"`
+	LastSeenAt  time.Time    `json:"last_seen_at"`
+}
+
+// PriceControlReport is the compliance report for a period
+type PriceControlReport struct {
+	From time.Time                `json:"from"`
+	To   time.Time                `json:"to"`
+	Rows []*PriceControlReportRow `json:"rows"`
+}
@@ -0,0 +1,338 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PromoCampaignStatus is the lifecycle state of a promo campaign
+type PromoCampaignStatus string
+
+const (
+	PromoCampaignActive    PromoCampaignStatus = "ACTIVE"
+	PromoCampaignPaused    PromoCampaignStatus = "PAUSED"
+	PromoCampaignExhausted PromoCampaignStatus = "EXHAUSTED" // paused automatically when the budget runs out
+)
+
+// PromoDiscountType is how a campaign's discount is computed
+type PromoDiscountType string
+
+const (
+	PromoDiscountPercent PromoDiscountType = "PERCENT"
+	PromoDiscountFlat    PromoDiscountType = "FLAT"
+)
+
+// PromoIncrementalWindowDays is how many days before and after a rider's
+// first redemption are compared to estimate incremental trips
+const PromoIncrementalWindowDays = 28
+
+// MaxPromoCodeLength is the longest promo code a campaign can use
+const MaxPromoCodeLength = 50
+
+// PromoTargeting limits which riders and trips a campaign applies to. Empty
+// fields do not restrict; NewRiders and LapsedDays together admit either group.
+type PromoTargeting struct {
+	NewRiders  bool        `json:"new_riders,omitempty"`  // riders with no completed rides
+	LapsedDays int         `json:"lapsed_days,omitempty"` // riders whose last completed ride is at least this old
+	Cities     []string    `json:"cities,omitempty"`
+	Zones      []string    `json:"zones,omitempty"` // H3 pickup cells at the city's pricing resolution
+	Hours      []int       `json:"hours,omitempty"` // local pickup hours, 0-23
+	RideTypes  []RideType  `json:"ride_types,omitempty"`
+	Riders     []uuid.UUID `json:"riders,omitempty"` // for vouchers issued to specific riders
+}
+
+// PromoCampaign groups promo codes under a shared budget and targeting
+type PromoCampaign struct {
+	ID              uuid.UUID           `json:"id"`
+	Name            string              `json:"name"`
+	Codes           []string            `json:"codes"`
+	Status          PromoCampaignStatus `json:"status"`
+	Currency        Currency            `json:"currency"`
+	DiscountType    PromoDiscountType   `json:"discount_type"`
+	DiscountPercent float64             `json:"discount_percent,omitempty"` // for PERCENT campaigns
+	DiscountAmount  int64               `json:"discount_amount,omitempty"`  // for FLAT campaigns, in minor units
+	MaxDiscount     int64               `json:"max_discount,omitempty"`     // per ride cap for PERCENT campaigns; 0 for none
+	Budget          int64               `json:"budget"`
+	Spent           int64               `json:"spent"`
+	PerUserLimit    int                 `json:"per_user_limit"` // redemptions per rider; 0 for unlimited
+	Targeting       PromoTargeting      `json:"targeting"`
+	StartsAt        time.Time           `json:"starts_at"`
+	EndsAt          *time.Time          `json:"ends_at,omitempty"`
+	CreatedBy       uuid.UUID           `json:"created_by"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+// NormalizePromoCode canonicalises a code as riders may type it
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate checks a campaign is well formed, normalising its codes
+func (c *PromoCampaign) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidPromoCampaign)
+	}
+	if len(c.Codes) == 0 {
+		return fmt.Errorf("%w: at least one code is required", ErrInvalidPromoCampaign)
+	}
+	if err := NormalizePromoCodes(c.Codes); err != nil {
+		return err
+	}
+	if len(c.Currency) != 3 {
+		return fmt.Errorf("%w: currency must be a 3-letter code", ErrInvalidPromoCampaign)
+	}
+
+	switch c.DiscountType {
+	case PromoDiscountPercent:
+		if c.DiscountPercent <= 0 || c.DiscountPercent > 100 {
+			return fmt.Errorf("%w: discount_percent must be in (0, 100]", ErrInvalidPromoCampaign)
+		}
+	case PromoDiscountFlat:
+		if c.DiscountAmount <= 0 {
+			return fmt.Errorf("%w: discount_amount must be positive", ErrInvalidPromoCampaign)
+		}
+	default:
+		return fmt.Errorf("%w: discount_type must be PERCENT or FLAT", ErrInvalidPromoCampaign)
+	}
+
+	switch {
+	case c.MaxDiscount < 0:
+		return fmt.Errorf("%w: max_discount cannot be negative", ErrInvalidPromoCampaign)
+	case c.Budget <= 0:
+		return fmt.Errorf("%w: budget must be positive", ErrInvalidPromoCampaign)
+	case c.PerUserLimit < 0:
+		return fmt.Errorf("%w: per_user_limit cannot be negative", ErrInvalidPromoCampaign)
+	case c.Targeting.LapsedDays < 0:
+		return fmt.Errorf("%w: lapsed_days cannot be negative", ErrInvalidPromoCampaign)
+	case c.EndsAt != nil && !c.EndsAt.After(c.StartsAt):
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidPromoCampaign)
+	}
+	for _, hour := range c.Targeting.Hours {
+		if hour < 0 || hour > 23 {
+			return fmt.Errorf("%w: hours must be between 0 and 23", ErrInvalidPromoCampaign)
+		}
+	}
+	for _, rideType := range c.Targeting.RideTypes {
+		if !containsRideType(RideTypes, rideType) {
+			return fmt.Errorf("%w: unknown ride type %q", ErrInvalidPromoCampaign, rideType)
+		}
+	}
+
+	return nil
+}
+
+// NormalizePromoCodes normalises codes in place, checking each is set, fits
+// and appears once
+func NormalizePromoCodes(codes []string) error {
+	seen := make(map[string]bool, len(codes))
+	for i, code := range codes {
+		code = NormalizePromoCode(code)
+		if code == "" || len(code) > MaxPromoCodeLength || seen[code] {
+			return fmt.Errorf("%w: codes must be unique and 1 to %d characters", ErrInvalidPromoCampaign, MaxPromoCodeLength)
+		}
+		seen[code] = true
+		codes[i] = code
+	}
+	return nil
+}
+
+// Update replaces what may change once a campaign is running: its name,
+// budget, caps, targeting and end. A budget cannot drop below what is
+// already spent; raising an exhausted campaign's budget reactivates it, and
+// a budget set to what is spent exhausts it.
+func (c *PromoCampaign) Update(u *PromoCampaign) error {
+	updated := *c
+	updated.Name = u.Name
+	updated.Budget = u.Budget
+	updated.MaxDiscount = u.MaxDiscount
+	updated.PerUserLimit = u.PerUserLimit
+	updated.Targeting = u.Targeting
+	updated.EndsAt = u.EndsAt
+	if err := updated.Validate(); err != nil {
+		return err
+	}
+	if updated.Budget < updated.Spent {
+		return fmt.Errorf("%w: budget cannot be less than the %d already spent", ErrInvalidPromoCampaign, updated.Spent)
+	}
+
+	switch {
+	case updated.Status == PromoCampaignExhausted && updated.Remaining() > 0:
+		updated.Status = PromoCampaignActive
+	case updated.Status == PromoCampaignActive && updated.Remaining() == 0:
+		updated.Status = PromoCampaignExhausted
+	}
+
+	*c = updated
+	return nil
+}
+
+// Remaining is the budget left to spend
+func (c *PromoCampaign) Remaining() int64 {
+	if c.Spent >= c.Budget {
+		return 0
+	}
+	return c.Budget - c.Spent
+}
+
+// PromoTrip describes the rider and trip a promo code is being applied to
+type PromoTrip struct {
+	RiderID         uuid.UUID
+	Now             time.Time
+	LocalHour       int
+	City            string
+	Zone            string
+	RideType        RideType // empty when previewing a code across ride types
+	Currency        Currency
+	CompletedRides  int64
+	LastCompletedAt *time.Time
+	Redemptions     int64 // the rider's unreleased redemptions of this campaign
+}
+
+// CheckEligibility reports why the campaign cannot be applied to a trip, or
+// nil if it can
+func (c *PromoCampaign) CheckEligibility(trip PromoTrip) error {
+	if c.Status != PromoCampaignActive || c.Remaining() == 0 {
+		return ErrInvalidPromoCode
+	}
+	if trip.Now.Before(c.StartsAt) || (c.EndsAt != nil && !trip.Now.Before(*c.EndsAt)) {
+		return ErrInvalidPromoCode
+	}
+	if trip.Currency != c.Currency {
+		return ErrPromoNotEligible
+	}
+	if c.PerUserLimit > 0 && trip.Redemptions >= int64(c.PerUserLimit) {
+		return ErrPromoCodeAlreadyUsed
+	}
+
+	t := c.Targeting
+	if t.NewRiders || t.LapsedDays > 0 {
+		isNew := trip.CompletedRides == 0
+		isLapsed := t.LapsedDays > 0 && trip.LastCompletedAt != nil &&
+			trip.Now.Sub(*trip.LastCompletedAt) >= time.Duration(t.LapsedDays)*24*time.Hour
+		if !(t.NewRiders && isNew) && !isLapsed {
+			return ErrPromoNotEligible
+		}
+	}
+	if len(t.Riders) > 0 {
+		targeted := false
+		for _, riderID := range t.Riders {
+			if riderID == trip.RiderID {
+				targeted = true
+				break
+			}
+		}
+		if !targeted {
+			return ErrPromoNotEligible
+		}
+	}
+	if len(t.Cities) > 0 && !containsString(t.Cities, trip.City) {
+		return ErrPromoNotEligible
+	}
+	if len(t.Zones) > 0 && !containsString(t.Zones, trip.Zone) {
+		return ErrPromoNotEligible
+	}
+	if trip.RideType != "" && !c.AppliesToRideType(trip.RideType) {
+		return ErrPromoNotEligible
+	}
+	if len(t.Hours) > 0 {
+		inHours := false
+		for _, hour := range t.Hours {
+			if hour == trip.LocalHour {
+				inHours = true
+				break
+			}
+		}
+		if !inHours {
+			return ErrPromoNotEligible
+		}
+	}
+
+	return nil
+}
+
+// AppliesToRideType reports whether the campaign's codes can be used on a
+// ride type
+func (c *PromoCampaign) AppliesToRideType(rideType RideType) bool {
+	return len(c.Targeting.RideTypes) == 0 || containsRideType(c.Targeting.RideTypes, rideType)
+}
+
+// DiscountFor is the discount the campaign gives on a fare, capped by the
+// fare and by the budget left
+func (c *PromoCampaign) DiscountFor(fare int64) int64 {
+	var discount int64
+	switch c.DiscountType {
+	case PromoDiscountPercent:
+		discount = int64(math.Round(float64(fare) * c.DiscountPercent / 100))
+		if c.MaxDiscount > 0 && discount > c.MaxDiscount {
+			discount = c.MaxDiscount
+		}
+	case PromoDiscountFlat:
+		discount = c.DiscountAmount
+	}
+
+	if discount > fare {
+		discount = fare
+	}
+	if remaining := c.Remaining(); discount > remaining {
+		discount = remaining
+	}
+	if discount < 0 {
+		return 0
+	}
+	return discount
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsRideType(rideTypes []RideType, rideType RideType) bool {
+	for _, t := range rideTypes {
+		if t == rideType {
+			return true
+		}
+	}
+	return false
+}
+
+// PromoRedemption is a campaign discount reserved against a ride. Cancelled
+// rides release their redemption and its spend back to the budget.
+type PromoRedemption struct {
+	ID         uuid.UUID  `json:"id"`
+	CampaignID uuid.UUID  `json:"campaign_id"`
+	Code       string     `json:"code"`
+	RideID     uuid.UUID  `json:"ride_id"`
+	RiderID    uuid.UUID  `json:"rider_id"`
+	Discount   int64      `json:"discount"`
+	Currency   Currency   `json:"currency"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+}
+
+// PromoCampaignStats is how a campaign has performed. Incremental trips
+// compare the redeeming riders' completed trips in the window after their
+// first redemption with the same window before it.
+type PromoCampaignStats struct {
+	CampaignID       uuid.UUID           `json:"campaign_id"`
+	Status           PromoCampaignStatus `json:"status"`
+	Budget           int64               `json:"budget"`
+	Spent            int64               `json:"spent"`
+	Redemptions      int64               `json:"redemptions"`
+	Released         int64               `json:"released"`
+	UniqueRiders     int64               `json:"unique_riders"`
+	CompletedTrips   int64               `json:"completed_trips"` // redeemed rides that completed
+	WindowDays       int                 `json:"window_days"`
+	TripsBefore      int64               `json:"trips_before"`
+	TripsAfter       int64               `json:"trips_after"`
+	IncrementalTrips int64               `json:"incremental_trips"`
+}
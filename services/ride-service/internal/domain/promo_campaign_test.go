@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testCampaign() *PromoCampaign {
+	return &PromoCampaign{
+		Name:            "Lapsed riders, evening",
+		Codes:           []string{" comeback "},
+		Status:          PromoCampaignActive,
+		Currency:        CurrencyKES,
+		DiscountType:    PromoDiscountPercent,
+		DiscountPercent: 20,
+		MaxDiscount:     15000,
+		Budget:          100000,
+		PerUserLimit:    1,
+		Targeting: PromoTargeting{
+			NewRiders:  true,
+			LapsedDays: 30,
+			Cities:     []string{"nairobi"},
+			Hours:      []int{18, 19, 20},
+		},
+		StartsAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestPromoCampaignValidateNormalisesCodes(t *testing.T) {
+	c := testCampaign()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if c.Codes[0] != "COMEBACK" {
+		t.Errorf("Codes[0] = %q, want COMEBACK", c.Codes[0])
+	}
+
+	c.Targeting.Hours = []int{24}
+	if err := c.Validate(); !errors.Is(err, ErrInvalidPromoCampaign) {
+		t.Errorf("Validate() error = %v, want ErrInvalidPromoCampaign", err)
+	}
+}
+
+func TestPromoCampaignCheckEligibility(t *testing.T) {
+	now := time.Date(2026, 3, 1, 16, 0, 0, 0, time.UTC)
+	recent := now.Add(-10 * 24 * time.Hour)
+	lapsed := now.Add(-45 * 24 * time.Hour)
+	trip := PromoTrip{Now: now, LocalHour: 19, City: "nairobi", Currency: CurrencyKES}
+
+	tests := []struct {
+		name   string
+		status PromoCampaignStatus
+		modify func(*PromoTrip)
+		want   error
+	}{
+		{name: "new rider", status: PromoCampaignActive, modify: func(*PromoTrip) {}},
+		{name: "lapsed rider", status: PromoCampaignActive, modify: func(p *PromoTrip) { p.CompletedRides, p.LastCompletedAt = 4, &lapsed }},
+		{name: "active rider", status: PromoCampaignActive, modify: func(p *PromoTrip) { p.CompletedRides, p.LastCompletedAt = 4, &recent }, want: ErrPromoNotEligible},
+		{name: "outside hours", status: PromoCampaignActive, modify: func(p *PromoTrip) { p.LocalHour = 9 }, want: ErrPromoNotEligible},
+		{name: "other city", status: PromoCampaignActive, modify: func(p *PromoTrip) { p.City = "kampala" }, want: ErrPromoNotEligible},
+		{name: "limit reached", status: PromoCampaignActive, modify: func(p *PromoTrip) { p.Redemptions = 1 }, want: ErrPromoCodeAlreadyUsed},
+		{name: "exhausted", status: PromoCampaignExhausted, modify: func(*PromoTrip) {}, want: ErrInvalidPromoCode},
+		{name: "targeted ride type", status: PromoCampaignActive, modify: func(p *PromoTrip) { p.RideType = RideTypeStandard }},
+		{name: "other ride type", status: PromoCampaignActive, modify: func(p *PromoTrip) { p.RideType = RideTypePremium }, want: ErrPromoNotEligible},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCampaign()
+			c.Status = tt.status
+			c.Targeting.RideTypes = []RideType{RideTypeStandard}
+			p := trip
+			tt.modify(&p)
+			if err := c.CheckEligibility(p); err != tt.want {
+				t.Errorf("CheckEligibility() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromoCampaignUpdate(t *testing.T) {
+	t.Run("topping up an exhausted campaign reactivates it", func(t *testing.T) {
+		c := testCampaign()
+		c.Status, c.Spent = PromoCampaignExhausted, c.Budget
+		u := testCampaign()
+		u.Name, u.Budget = "Lapsed riders, extended", 150000
+		if err := c.Update(u); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if c.Status != PromoCampaignActive || c.Name != u.Name || c.Remaining() != 50000 {
+			t.Errorf("Update() = %+v, want an active campaign with 50000 left", c)
+		}
+	})
+
+	t.Run("cutting the budget to what is spent exhausts it", func(t *testing.T) {
+		c := testCampaign()
+		c.Spent = 40000
+		u := testCampaign()
+		u.Budget = 40000
+		if err := c.Update(u); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if c.Status != PromoCampaignExhausted {
+			t.Errorf("Status = %s, want %s", c.Status, PromoCampaignExhausted)
+		}
+	})
+
+	t.Run("budget below spend is rejected", func(t *testing.T) {
+		c := testCampaign()
+		c.Spent = 40000
+		u := testCampaign()
+		u.Budget = 30000
+		if err := c.Update(u); !errors.Is(err, ErrInvalidPromoCampaign) {
+			t.Fatalf("Update() error = %v, want ErrInvalidPromoCampaign", err)
+		}
+		if c.Budget != 100000 {
+			t.Errorf("Budget = %d, want the campaign left unchanged", c.Budget)
+		}
+	})
+}
+
+func TestNormalizePromoCodes(t *testing.T) {
+	codes := []string{" ride20 ", "Ride20"}
+	if err := NormalizePromoCodes(codes); !errors.Is(err, ErrInvalidPromoCampaign) {
+		t.Errorf("NormalizePromoCodes() error = %v, want duplicate rejected", err)
+	}
+	if err := NormalizePromoCodes([]string{strings.Repeat("A", MaxPromoCodeLength+1)}); !errors.Is(err, ErrInvalidPromoCampaign) {
+		t.Errorf("NormalizePromoCodes() error = %v, want long code rejected", err)
+	}
+}
+
+func TestPromoCampaignDiscountFor(t *testing.T) {
+	c := testCampaign()
+
+	if got := c.DiscountFor(50000); got != 10000 {
+		t.Errorf("DiscountFor(50000) = %d, want 20%% = 10000", got)
+	}
+	if got := c.DiscountFor(200000); got != 15000 {
+		t.Errorf("DiscountFor(200000) = %d, want max discount 15000", got)
+	}
+
+	c.Spent = 95000
+	if got := c.DiscountFor(200000); got != 5000 {
+		t.Errorf("DiscountFor(200000) = %d, want remaining budget 5000", got)
+	}
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RideReceiptIssuedEvent is the event type published when a receipt is
+// issued, for the notification service to email it to the rider
+const RideReceiptIssuedEvent = "RIDE_RECEIPT_ISSUED"
+
+// RideReceipt is the rider's record of a completed ride and what they paid
+type RideReceipt struct {
+	ID              uuid.UUID       `json:"id"`
+	Number          string          `json:"number"` // quoted to support, e.g. UBI-20261018-1A2B3C4D
+	RideID          uuid.UUID       `json:"ride_id"`
+	RiderID         uuid.UUID       `json:"rider_id"`
+	Type            RideType        `json:"type"`
+	PickupLocation  Location        `json:"pickup_location"`
+	DropoffLocation Location        `json:"dropoff_location"`
+	Stops           []Location      `json:"stops,omitempty"`
+	Polyline        string          `json:"polyline,omitempty"` // for the route map
+	DistanceMeters  int64           `json:"distance_meters"`
+	DurationSeconds int64           `json:"duration_seconds"`
+	StartedAt       *time.Time      `json:"started_at,omitempty"`
+	CompletedAt     time.Time       `json:"completed_at"`
+	PaymentMethod   PaymentMethod   `json:"payment_method"`
+	Fare            *PriceBreakdown `json:"fare"`
+	Total           int64           `json:"total"`
+	Currency        Currency        `json:"currency"`
+	Emissions       *RideEmissions  `json:"emissions,omitempty"` // estimated CO2 of the trip
+	IssuedAt        time.Time       `json:"issued_at"`
+	PublishedAt     *time.Time      `json:"-"` // when the issued event went out
+}
+
+// RideReceiptEvent is published when a receipt is issued
+type RideReceiptEvent struct {
+	Type    string       `json:"type"`
+	Receipt *RideReceipt `json:"receipt"`
+}
+
+// NewRideReceipt issues the receipt of a completed, priced ride. The fare
+// leaves out the driver and platform split, which is not the rider's.
+func NewRideReceipt(ride *Ride, now time.Time) (*RideReceipt, error) {
+	if ride.Status != RideStatusCompleted || ride.Price == nil {
+		return nil, ErrReceiptNotAvailable
+	}
+
+	fare := *ride.Price
+	fare.DriverEarnings = 0
+	fare.PlatformFee = 0
+	fare.CommissionDiscount = 0
+
+	completedAt := ride.UpdatedAt
+	if ride.CompletedAt != nil {
+		completedAt = *ride.CompletedAt
+	}
+
+	receipt := &RideReceipt{
+		ID:              uuid.New(),
+		Number:          ReceiptNumber(ride.ID, completedAt),
+		RideID:          ride.ID,
+		RiderID:         ride.RiderID,
+		Type:            ride.Type,
+		PickupLocation:  ride.PickupLocation,
+		DropoffLocation: ride.DropoffLocation,
+		Stops:           ride.Stops,
+		StartedAt:       ride.StartedAt,
+		CompletedAt:     completedAt,
+		PaymentMethod:   ride.PaymentMethod,
+		Fare:            &fare,
+		Total:           fare.Total,
+		Currency:        fare.Currency,
+		Emissions:       ride.Emissions(),
+		IssuedAt:        now,
+	}
+	if ride.Route != nil {
+		receipt.Polyline = ride.Route.Polyline
+		receipt.DistanceMeters = ride.Route.DistanceMeters
+		receipt.DurationSeconds = ride.Route.DurationSeconds
+	}
+	// The trip as it happened, not as planned
+	if ride.StartedAt != nil && completedAt.After(*ride.StartedAt) {
+		receipt.DurationSeconds = int64(completedAt.Sub(*ride.StartedAt).Seconds())
+	}
+	return receipt, nil
+}
+
+// ReceiptNumber is a ride's receipt number: its completion date and the
+// start of its ID
+func ReceiptNumber(rideID uuid.UUID, completedAt time.Time) string {
+	return "UBI-" + completedAt.UTC().Format("20060102") + "-" + strings.ToUpper(rideID.String()[:8])
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewRideReceipt(t *testing.T) {
+	started := time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+	completed := started.Add(25 * time.Minute)
+	ride := &Ride{
+		ID:          uuid.MustParse("1a2b3c4d-0000-0000-0000-000000000000"),
+		RiderID:     uuid.New(),
+		Status:      RideStatusCompleted,
+		Route:       &RouteInfo{Polyline: "abc", DistanceMeters: 8000, DurationSeconds: 1200},
+		StartedAt:   &started,
+		CompletedAt: &completed,
+		Price: &PriceBreakdown{
+			BaseFare:           50000,
+			Total:              250000,
+			Currency:           CurrencyNGN,
+			DriverEarnings:     200000,
+			PlatformFee:        50000,
+			CommissionDiscount: 0.1,
+		},
+	}
+
+	receipt, err := NewRideReceipt(ride, completed.Add(time.Second))
+	if err != nil {
+		t.Fatalf("NewRideReceipt() error = %v", err)
+	}
+	if receipt.Number != "UBI-20261018-1A2B3C4D" {
+		t.Errorf("Number = %q", receipt.Number)
+	}
+	if receipt.Total != 250000 || receipt.Currency != CurrencyNGN {
+		t.Errorf("Total = %d %s, want 250000 NGN", receipt.Total, receipt.Currency)
+	}
+	if receipt.Fare.DriverEarnings != 0 || receipt.Fare.PlatformFee != 0 || receipt.Fare.CommissionDiscount != 0 {
+		t.Errorf("Fare keeps the driver and platform split: %+v", receipt.Fare)
+	}
+	if ride.Price.DriverEarnings != 200000 {
+		t.Error("NewRideReceipt() changed the ride's price")
+	}
+	if receipt.DurationSeconds != 1500 {
+		t.Errorf("DurationSeconds = %d, want the actual 1500", receipt.DurationSeconds)
+	}
+	if receipt.DistanceMeters != 8000 || receipt.Polyline != "abc" {
+		t.Errorf("route = %d %q", receipt.DistanceMeters, receipt.Polyline)
+	}
+}
+
+func TestNewRideReceiptNotAvailable(t *testing.T) {
+	tests := []struct {
+		name string
+		ride *Ride
+	}{
+		{"in progress", &Ride{Status: RideStatusInProgress, Price: &PriceBreakdown{Total: 1000}}},
+		{"cancelled", &Ride{Status: RideStatusCancelled, Price: &PriceBreakdown{Total: 1000}}},
+		{"unpriced", &Ride{Status: RideStatusCompleted}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRideReceipt(tt.ride, time.Now()); err != ErrReceiptNotAvailable {
+				t.Errorf("NewRideReceipt() error = %v, want ErrReceiptNotAvailable", err)
+			}
+		})
+	}
+}
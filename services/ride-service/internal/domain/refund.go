@@ -0,0 +1,213 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefundType distinguishes money returned to the original payment method
+// from platform-funded goodwill credits
+type RefundType string
+
+const (
+	RefundTypeFull           RefundType = "FULL"
+	RefundTypePartial        RefundType = "PARTIAL"
+	RefundTypeGoodwillCredit RefundType = "GOODWILL_CREDIT"
+)
+
+// RefundStatus represents the lifecycle of a refund
+type RefundStatus string
+
+const (
+	RefundStatusPendingApproval RefundStatus = "PENDING_APPROVAL"
+	RefundStatusProcessing      RefundStatus = "PROCESSING"
+	RefundStatusCompleted       RefundStatus = "COMPLETED"
+	RefundStatusRejected        RefundStatus = "REJECTED"
+	RefundStatusFailed          RefundStatus = "FAILED"
+)
+
+// RefundReason is a support reason code for a refund
+type RefundReason string
+
+const (
+	RefundReasonOvercharged      RefundReason = "OVERCHARGED"
+	RefundReasonRouteDeviation   RefundReason = "ROUTE_DEVIATION"
+	RefundReasonDriverMisconduct RefundReason = "DRIVER_MISCONDUCT"
+	RefundReasonVehicleIssue     RefundReason = "VEHICLE_ISSUE"
+	RefundReasonServiceFailure   RefundReason = "SERVICE_FAILURE"
+	RefundReasonDuplicateCharge  RefundReason = "DUPLICATE_CHARGE"
+	RefundReasonFareDispute      RefundReason = "FARE_DISPUTE"
+	RefundReasonGoodwill         RefundReason = "GOODWILL"
+)
+
+// IsValid reports whether the reason code is known
+func (r RefundReason) IsValid() bool {
+	switch r {
+	case RefundReasonOvercharged, RefundReasonRouteDeviation, RefundReasonDriverMisconduct,
+		RefundReasonVehicleIssue, RefundReasonServiceFailure, RefundReasonDuplicateCharge,
+		RefundReasonFareDispute, RefundReasonGoodwill:
+		return true
+	}
+	return false
+}
+
+// ReversesDriverEarnings reports whether the driver shares the cost of the refund.
+// Driver-attributable and billing errors reverse the split; service failures and
+// goodwill are absorbed by the platform.
+func (r RefundReason) ReversesDriverEarnings() bool {
+	switch r {
+	case RefundReasonOvercharged, RefundReasonRouteDeviation, RefundReasonDriverMisconduct,
+		RefundReasonDuplicateCharge, RefundReasonFareDispute:
+		return true
+	}
+	return false
+}
+
+// RefundApprovalThresholds are the amounts (minor units) at or above which
+// a refund needs a second approver
+var RefundApprovalThresholds = map[Currency]int64{
+	CurrencyNGN: 1000000, // ₦10,000
+	CurrencyKES: 200000,  // KSh 2,000
+	CurrencyGHS: 20000,   // GH₵200
+	CurrencyUGX: 5000000, // USh 50,000
+	CurrencyTZS: 3000000, // TSh 30,000
+	CurrencyRWF: 2000000, // RF 20,000
+	CurrencyZAR: 30000,   // R300
+	CurrencyUSD: 2000,    // $20
+}
+
+// defaultRefundApprovalThreshold applies to currencies without an explicit threshold
+const defaultRefundApprovalThreshold int64 = 2000
+
+// RequiresSecondApproval reports whether a refund needs a second approver.
+// issued is what has already been refunded or credited on the ride, so a
+// large refund split into small ones still needs approval once their sum
+// reaches the threshold.
+func RequiresSecondApproval(issued, amount int64, currency Currency) bool {
+	threshold, ok := RefundApprovalThresholds[currency]
+	if !ok {
+		threshold = defaultRefundApprovalThreshold
+	}
+	return issued+amount >= threshold
+}
+
+// CanApproveRefunds reports whether a role may approve or reject refunds
+func CanApproveRefunds(role string) bool {
+	return role == RoleSupportLead || role == RoleAdmin
+}
+
+// Refund represents a refund or goodwill credit against a completed ride
+type Refund struct {
+	ID            uuid.UUID    `json:"id"`
+	RideID        uuid.UUID    `json:"ride_id"`
+	RiderID       uuid.UUID    `json:"rider_id"`
+	Type          RefundType   `json:"type"`
+	Reason        RefundReason `json:"reason"`
+	Note          string       `json:"note,omitempty"`
+	Amount        int64        `json:"amount"`
+	Currency      Currency     `json:"currency"`
+	Status        RefundStatus `json:"status"`
+	RequestedBy   uuid.UUID    `json:"requested_by"`
+	ApprovedBy    *uuid.UUID   `json:"approved_by,omitempty"`
+	ProviderRef   string       `json:"provider_ref,omitempty"`
+	FailureReason string       `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// Settle fixes a new refund's amount against what is left of the ride's
+// fare and decides whether it needs a second approver. refunded is the fare
+// already refunded on the ride and issued adds goodwill credits, both
+// counting refunds completed or in flight.
+func (r *Refund) Settle(fare, refunded, issued int64) error {
+	refundable := fare - refunded
+	switch r.Type {
+	case RefundTypeFull:
+		r.Amount = refundable
+	case RefundTypePartial:
+		if r.Amount > refundable {
+			return ErrRefundExceedsFare
+		}
+	case RefundTypeGoodwillCredit:
+		// Credits are platform-funded and not bounded by the fare
+	default:
+		return ErrInvalidRequest
+	}
+	if r.Amount <= 0 {
+		if r.Type == RefundTypeFull {
+			return ErrRefundExceedsFare
+		}
+		return ErrRefundInvalidAmount
+	}
+
+	r.Status = RefundStatusProcessing
+	if RequiresSecondApproval(issued, r.Amount, r.Currency) {
+		r.Status = RefundStatusPendingApproval
+	}
+	return nil
+}
+
+// LedgerAccount identifies the party a ledger entry applies to
+type LedgerAccount string
+
+const (
+	LedgerAccountRider    LedgerAccount = "RIDER"
+	LedgerAccountDriver   LedgerAccount = "DRIVER"
+	LedgerAccountPlatform LedgerAccount = "PLATFORM"
+)
+
+// LedgerEntry records a signed money movement (minor units) for a ride.
+// Positive amounts are owed to the account, negative amounts are taken from it.
+type LedgerEntry struct {
+	ID          uuid.UUID     `json:"id"`
+	RideID      uuid.UUID     `json:"ride_id"`
+	RefundID    *uuid.UUID    `json:"refund_id,omitempty"`
+	Account     LedgerAccount `json:"account"`
+	AccountID   *uuid.UUID    `json:"account_id,omitempty"`
+	Amount      int64         `json:"amount"`
+	Currency    Currency      `json:"currency"`
+	Description string        `json:"description"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// RefundLedgerEntries builds the balanced entries for a completed refund.
+// The rider is credited the full amount; when the reason reverses driver
+// earnings the cost is split in the same proportion as the original fare,
+// otherwise the platform absorbs it.
+func RefundLedgerEntries(refund *Refund, ride *Ride) []*LedgerEntry {
+	now := time.Now().UTC()
+	refundID := refund.ID
+
+	entry := func(account LedgerAccount, accountID *uuid.UUID, amount int64, desc string) *LedgerEntry {
+		return &LedgerEntry{
+			ID:          uuid.New(),
+			RideID:      ride.ID,
+			RefundID:    &refundID,
+			Account:     account,
+			AccountID:   accountID,
+			Amount:      amount,
+			Currency:    refund.Currency,
+			Description: desc,
+			CreatedAt:   now,
+		}
+	}
+
+	riderID := ride.RiderID
+	entries := []*LedgerEntry{
+		entry(LedgerAccountRider, &riderID, refund.Amount, string(refund.Type)+" refund: "+string(refund.Reason)),
+	}
+
+	driverShare := int64(0)
+	if refund.Type != RefundTypeGoodwillCredit && refund.Reason.ReversesDriverEarnings() &&
+		ride.Price != nil && ride.Price.Total > 0 && ride.DriverID != nil {
+		driverShare = refund.Amount * ride.Price.DriverEarnings / ride.Price.Total
+	}
+
+	if driverShare > 0 {
+		entries = append(entries, entry(LedgerAccountDriver, ride.DriverID, -driverShare, "Driver earnings reversal"))
+	}
+	entries = append(entries, entry(LedgerAccountPlatform, nil, -(refund.Amount-driverShare), "Platform fee reversal"))
+
+	return entries
+}
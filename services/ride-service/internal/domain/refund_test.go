@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRefundLedgerEntriesBalance(t *testing.T) {
+	driverID := uuid.New()
+	ride := &Ride{
+		ID:       uuid.New(),
+		RiderID:  uuid.New(),
+		DriverID: &driverID,
+		Price:    &PriceBreakdown{Total: 200000, DriverEarnings: 160000, Currency: CurrencyNGN},
+	}
+
+	tests := []struct {
+		name       string
+		refundType RefundType
+		reason     RefundReason
+		wantDriver int64
+	}{
+		{"driver-attributable refund reverses split", RefundTypePartial, RefundReasonRouteDeviation, -40000},
+		{"service failure absorbed by platform", RefundTypePartial, RefundReasonServiceFailure, 0},
+		{"goodwill credit absorbed by platform", RefundTypeGoodwillCredit, RefundReasonFareDispute, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refund := &Refund{ID: uuid.New(), Type: tt.refundType, Reason: tt.reason, Amount: 50000, Currency: CurrencyNGN}
+			entries := RefundLedgerEntries(refund, ride)
+
+			var sum, driver int64
+			for _, e := range entries {
+				sum += e.Amount
+				if e.Account == LedgerAccountDriver {
+					driver += e.Amount
+				}
+			}
+			if sum != 0 {
+				t.Errorf("ledger entries sum = %d, want 0", sum)
+			}
+			if driver != tt.wantDriver {
+				t.Errorf("driver reversal = %d, want %d", driver, tt.wantDriver)
+			}
+		})
+	}
+}
+
+func TestRequiresSecondApproval(t *testing.T) {
+	if RequiresSecondApproval(0, 999999, CurrencyNGN) {
+		t.Error("amount below NGN threshold should not need approval")
+	}
+	if !RequiresSecondApproval(0, 1000000, CurrencyNGN) {
+		t.Error("amount at NGN threshold should need approval")
+	}
+	if !RequiresSecondApproval(600000, 400000, CurrencyNGN) {
+		t.Error("refunds split under the NGN threshold should need approval once their sum reaches it")
+	}
+}
+
+func TestRefundSettle(t *testing.T) {
+	tests := []struct {
+		name       string
+		refund     Refund
+		refunded   int64
+		issued     int64
+		wantAmount int64
+		wantStatus RefundStatus
+		wantErr    error
+	}{
+		{"full refund of what is left", Refund{Type: RefundTypeFull}, 50000, 50000, 100000, RefundStatusProcessing, nil},
+		{"full refund after a credit", Refund{Type: RefundTypeFull}, 0, 60000, 150000, RefundStatusPendingApproval, nil},
+		{"full refund of a refunded fare", Refund{Type: RefundTypeFull}, 150000, 150000, 0, "", ErrRefundExceedsFare},
+		{"partial refund within the fare", Refund{Type: RefundTypePartial, Amount: 40000}, 100000, 100000, 40000, RefundStatusProcessing, nil},
+		{"partial refund over the fare", Refund{Type: RefundTypePartial, Amount: 60000}, 100000, 100000, 0, "", ErrRefundExceedsFare},
+		{"partial refund of nothing", Refund{Type: RefundTypePartial}, 0, 0, 0, "", ErrRefundInvalidAmount},
+		{"credit beyond the fare", Refund{Type: RefundTypeGoodwillCredit, Amount: 50000}, 150000, 150000, 50000, RefundStatusPendingApproval, nil},
+		{"unknown type", Refund{Type: "CASHBACK", Amount: 100}, 0, 0, 0, "", ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refund := tt.refund
+			refund.Currency = CurrencyKES
+			err := refund.Settle(150000, tt.refunded, tt.issued)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Settle() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if refund.Amount != tt.wantAmount || refund.Status != tt.wantStatus {
+				t.Errorf("Settle() = %d %s, want %d %s", refund.Amount, refund.Status, tt.wantAmount, tt.wantStatus)
+			}
+		})
+	}
+}
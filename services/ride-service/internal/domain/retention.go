@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Retention nudge rules
+const (
+	RetentionMinWait          = 2 * time.Minute    // searching this long before a rider is scored
+	RetentionHistoryDays      = 90                 // window of the rider's history the model sees
+	RetentionMessageThreshold = 0.35               // probability above which the rider gets an honest wait update
+	RetentionCreditThreshold  = 0.6                // probability above which the rider is offered a credit
+	RetentionCreditPct        = 10                 // credit as a share of the quoted fare
+	RetentionCreditValidity   = 7 * 24 * time.Hour // how long the credit code can be redeemed
+	RetentionHoldoutPct       = 10                 // share of nudge-eligible rides left un-nudged to measure lift
+)
+
+// RetentionMitigation is what was done for a rider at risk of cancelling
+type RetentionMitigation string
+
+const (
+	RetentionMitigationNone    RetentionMitigation = "NONE"
+	RetentionMitigationMessage RetentionMitigation = "WAIT_MESSAGE"
+	RetentionMitigationCredit  RetentionMitigation = "CREDIT_OFFER"
+)
+
+// ChooseRetentionMitigation picks the mitigation for a cancellation probability
+func ChooseRetentionMitigation(probability float64) RetentionMitigation {
+	switch {
+	case probability >= RetentionCreditThreshold:
+		return RetentionMitigationCredit
+	case probability >= RetentionMessageThreshold:
+		return RetentionMitigationMessage
+	default:
+		return RetentionMitigationNone
+	}
+}
+
+// RetentionCreditAmount is the credit offered on a quoted fare
+func RetentionCreditAmount(fare int64) int64 {
+	return fare * RetentionCreditPct / 100
+}
+
+// InRetentionHoldout reports whether a ride is in the holdout group, which
+// is scored and recorded but never nudged. Assignment is stable per ride.
+func InRetentionHoldout(rideID uuid.UUID) bool {
+	return binary.BigEndian.Uint32(rideID[12:])%100 < RetentionHoldoutPct
+}
+
+// RetentionNudge records a rider's scored cancellation risk during a long
+// matching wait, the mitigation chosen and, once the ride moves on, whether
+// they cancelled
+type RetentionNudge struct {
+	ID           uuid.UUID           `json:"id"`
+	RideID       uuid.UUID           `json:"ride_id"`
+	RiderID      uuid.UUID           `json:"rider_id"`
+	ModelVersion string              `json:"model_version"`
+	Probability  float64             `json:"probability"`
+	Features     map[string]float64  `json:"features"`
+	WaitSeconds  int64               `json:"wait_seconds"`
+	Mitigation   RetentionMitigation `json:"mitigation"`
+	Holdout      bool                `json:"holdout"` // mitigation chosen but withheld
+	CreditCode   string              `json:"credit_code,omitempty"`
+	CreditAmount int64               `json:"credit_amount,omitempty"`
+	Currency     Currency            `json:"currency,omitempty"`
+	Outcome      string              `json:"outcome,omitempty"` // MATCHED, CANCELLED or EXPIRED
+	CreatedAt    time.Time           `json:"created_at"`
+	ResolvedAt   *time.Time          `json:"resolved_at,omitempty"`
+}
+
+// Retention nudge outcomes
+const (
+	RetentionOutcomeMatched   = "MATCHED"
+	RetentionOutcomeCancelled = "CANCELLED"
+	RetentionOutcomeExpired   = "EXPIRED" // no driver found; the rider did not churn by cancelling
+)
+
+// RetentionStat measures one mitigation arm, treated or held out
+type RetentionStat struct {
+	Mitigation     RetentionMitigation `json:"mitigation"`
+	Holdout        bool                `json:"holdout"`
+	Nudges         int64               `json:"nudges"`
+	Resolved       int64               `json:"resolved"`
+	Cancelled      int64               `json:"cancelled"`
+	CancelRate     float64             `json:"cancel_rate"`     // cancelled / resolved
+	AvgProbability float64             `json:"avg_probability"` // mean predicted probability, for calibration
+}
+
+// RetentionReport is the retention nudge measurement report for a period
+type RetentionReport struct {
+	From         time.Time        `json:"from"`
+	To           time.Time        `json:"to"`
+	ModelVersion string           `json:"model_version"`
+	Stats        []*RetentionStat `json:"stats"`
+}
+
+// NudgePreferences are a rider's retention nudge settings
+type NudgePreferences struct {
+	RiderID   uuid.UUID `json:"rider_id"`
+	OptedOut  bool      `json:"opted_out"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestChooseRetentionMitigation(t *testing.T) {
+	tests := []struct {
+		probability float64
+		want        RetentionMitigation
+	}{
+		{0.1, RetentionMitigationNone},
+		{0.35, RetentionMitigationMessage},
+		{0.59, RetentionMitigationMessage},
+		{0.6, RetentionMitigationCredit},
+		{0.95, RetentionMitigationCredit},
+	}
+	for _, tt := range tests {
+		if got := ChooseRetentionMitigation(tt.probability); got != tt.want {
+			t.Errorf("ChooseRetentionMitigation(%v) = %s, want %s", tt.probability, got, tt.want)
+		}
+	}
+
+	if got := RetentionCreditAmount(250000); got != 25000 {
+		t.Errorf("RetentionCreditAmount(250000) = %d, want 25000", got)
+	}
+}
+
+func TestInRetentionHoldout(t *testing.T) {
+	held := 0
+	for i := 0; i < 10000; i++ {
+		if InRetentionHoldout(uuid.New()) {
+			held++
+		}
+	}
+	// ~10% of rides; random UUIDs make this loose
+	if held < 800 || held > 1200 {
+		t.Errorf("held out %d of 10000 rides, want about 1000", held)
+	}
+
+	id := uuid.New()
+	if InRetentionHoldout(id) != InRetentionHoldout(id) {
+		t.Error("holdout assignment should be stable per ride")
+	}
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Return-leg recommendation settings
+const (
+	ReturnLegDemandWindow    = 30 * time.Minute // recent requests that make a zone busy
+	ReturnLegDemandZoneLimit = 200              // busiest zones considered across all cities
+	ReturnLegNearDemand      = 3000.0           // metres from the target zone within which no return leg is needed
+	ReturnLegCorridorWidth   = 1500.0           // metres either side of the way back searched for work
+	ReturnLegMaxDetour       = 3000.0           // metres a return leg may add to the drive back
+	ReturnLegLimit           = 5                // most return legs recommended
+)
+
+// DemandZone is a pickup zone with recent ride requests and how many
+// drivers are available in it now
+type DemandZone struct {
+	Zone      string  `json:"zone"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Requests  int64   `json:"requests"`
+	Supply    int64   `json:"supply"`
+}
+
+// Pressure is the zone's recent requests per available driver, counting at
+// least one driver
+func (z *DemandZone) Pressure() float64 {
+	return float64(z.Requests) / float64(z.Supply+1)
+}
+
+// ReturnLegSource is the kind of work a return leg is
+type ReturnLegSource string
+
+const (
+	ReturnLegSourceRide     ReturnLegSource = "RIDE"
+	ReturnLegSourceDelivery ReturnLegSource = "DELIVERY"
+)
+
+// ReturnLeg is a waiting ride or delivery along a driver's way back toward
+// demand. Fares are in the smallest currency unit.
+type ReturnLeg struct {
+	Source               ReturnLegSource `json:"source"`
+	ID                   string          `json:"id"`
+	PickupLocation       Location        `json:"pickup_location"`
+	DropoffLocation      Location        `json:"dropoff_location"`
+	PickupDistanceMeters float64         `json:"pickup_distance_meters"`
+	DetourMeters         float64         `json:"detour_meters"`   // added to the drive straight back
+	ProgressMeters       float64         `json:"progress_meters"` // how much closer to the zone it ends
+	Fare                 int64           `json:"fare"`
+	Currency             Currency        `json:"currency,omitempty"`
+	Priority             bool            `json:"priority"`
+}
+
+// Gain is what the leg is worth to the drive back: its progress toward
+// demand less its detour
+func (l *ReturnLeg) Gain() float64 {
+	return l.ProgressMeters - l.DetourMeters
+}
+
+// ReturnLegRecommendation is the work recommended to a driver for heading
+// back toward demand after a dropoff. Drivers already near demand get no
+// legs.
+type ReturnLegRecommendation struct {
+	Latitude               float64      `json:"latitude"`
+	Longitude              float64      `json:"longitude"`
+	Zone                   *DemandZone  `json:"zone,omitempty"`
+	DistanceToDemandMeters float64      `json:"distance_to_demand_meters,omitempty"`
+	NearDemand             bool         `json:"near_demand"`
+	Legs                   []*ReturnLeg `json:"legs"`
+	GeneratedAt            time.Time    `json:"generated_at"`
+}
+
+// RankReturnLegs keeps the legs that bring the driver closer to demand
+// within the detour allowance, best gain first, and marks them as priority
+// offers
+func RankReturnLegs(legs []*ReturnLeg, limit int) []*ReturnLeg {
+	ranked := make([]*ReturnLeg, 0, len(legs))
+	for _, l := range legs {
+		if l.ProgressMeters <= 0 || l.DetourMeters > ReturnLegMaxDetour {
+			continue
+		}
+		l.Priority = true
+		ranked = append(ranked, l)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Gain() > ranked[j].Gain() })
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
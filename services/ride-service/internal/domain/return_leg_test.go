@@ -0,0 +1,33 @@
+package domain
+
+import "testing"
+
+func TestDemandZonePressure(t *testing.T) {
+	busy := &DemandZone{Requests: 30, Supply: 2}
+	quiet := &DemandZone{Requests: 30, Supply: 14}
+
+	if busy.Pressure() != 10 || quiet.Pressure() != 2 {
+		t.Errorf("pressure = %v, %v; want 10, 2", busy.Pressure(), quiet.Pressure())
+	}
+	if (&DemandZone{Requests: 5}).Pressure() != 5 {
+		t.Error("a zone with no drivers should count one")
+	}
+}
+
+func TestRankReturnLegs(t *testing.T) {
+	legs := []*ReturnLeg{
+		{ID: "small", ProgressMeters: 2000, DetourMeters: 500},
+		{ID: "best", ProgressMeters: 9000, DetourMeters: 1000},
+		{ID: "away", ProgressMeters: -1500, DetourMeters: 200},
+		{ID: "detour", ProgressMeters: 12000, DetourMeters: 5000},
+	}
+
+	ranked := RankReturnLegs(legs, ReturnLegLimit)
+
+	if len(ranked) != 2 || ranked[0].ID != "best" || ranked[1].ID != "small" {
+		t.Fatalf("ranked = %v, want best then small", ranked)
+	}
+	if !ranked[0].Priority {
+		t.Error("recommended legs should be priority offers")
+	}
+}
@@ -20,8 +20,15 @@ const (
 	RideStatusInProgress RideStatus = "IN_PROGRESS"
 	RideStatusCompleted  RideStatus = "COMPLETED"
 	RideStatusCancelled  RideStatus = "CANCELLED"
+	RideStatusExpired    RideStatus = "EXPIRED" // no driver found within the matching TTL
 )
 
+// RideStatuses lists every ride status
+var RideStatuses = []RideStatus{
+	RideStatusPending, RideStatusSearching, RideStatusMatched, RideStatusAccepted, RideStatusArriving,
+	RideStatusArrived, RideStatusInProgress, RideStatusCompleted, RideStatusCancelled, RideStatusExpired,
+}
+
 // RideType represents the type of ride service
 type RideType string
 
@@ -31,8 +38,12 @@ const (
 	RideTypeXL       RideType = "XL"
 	RideTypeBoda     RideType = "BODA"
 	RideTypeTricycle RideType = "TRICYCLE"
+	RideTypePool     RideType = "POOL" // a car seat shared with riders heading the same way
 )
 
+// RideTypes lists every ride type
+var RideTypes = []RideType{RideTypeStandard, RideTypePremium, RideTypeXL, RideTypeBoda, RideTypeTricycle, RideTypePool}
+
 // PaymentMethod represents the payment method for a ride
 type PaymentMethod string
 
@@ -41,6 +52,7 @@ const (
 	PaymentMethodWallet      PaymentMethod = "WALLET"
 	PaymentMethodMobileMoney PaymentMethod = "MOBILE_MONEY"
 	PaymentMethodCard        PaymentMethod = "CARD"
+	PaymentMethodBusinessInvoice PaymentMethod = "BUSINESS_INVOICE" // billed monthly to the rider's business account
 )
 
 // Currency represents supported currencies
@@ -59,12 +71,17 @@ const (
 
 // Location represents a geographic coordinate with optional metadata
 type Location struct {
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
-	Address   string  `json:"address,omitempty"`
-	Name      string  `json:"name,omitempty"`
-	PlaceID   string  `json:"place_id,omitempty"`
-	H3Cell    string  `json:"h3_cell,omitempty"` // H3 grid cell for indexing
+	Latitude   float64            `json:"latitude"`
+	Longitude  float64            `json:"longitude"`
+	Address    string             `json:"address,omitempty"`
+	Name       string             `json:"name,omitempty"`
+	PlaceID    string             `json:"place_id,omitempty"`
+	H3Cell     string             `json:"h3_cell,omitempty"` // H3 grid cell for indexing
+	PlusCode   string             `json:"plus_code,omitempty"`
+	What3Words string             `json:"w3w,omitempty"`
+	Landmarks  []Landmark         `json:"landmarks,omitempty"`
+	Directions string             `json:"directions,omitempty"` // free text for the driver, e.g. "blue gate, second floor"
+	Components *AddressComponents `json:"components,omitempty"`
 }
 
 // RouteInfo contains route details between pickup and dropoff
@@ -73,22 +90,34 @@ type RouteInfo struct {
 	DurationSeconds  int64   `json:"duration_seconds"`
 	Polyline         string  `json:"polyline,omitempty"`
 	TrafficDuration  int64   `json:"traffic_duration_seconds,omitempty"`
+	Preference       RoutePreference `json:"preference,omitempty"` // the route option the rider picked
+	Summary          string  `json:"summary,omitempty"`
+	Avoid            *RouteAvoidance `json:"avoid,omitempty"` // road classes the route was planned around
+	Legs             []RouteLeg      `json:"legs,omitempty"`  // one per leg between stops, for rides with stops
 }
 
 // PriceBreakdown contains detailed pricing information
 type PriceBreakdown struct {
-	BaseFare         int64   `json:"base_fare"`
-	DistanceFare     int64   `json:"distance_fare"`
-	TimeFare         int64   `json:"time_fare"`
-	SurgeMultiplier  float64 `json:"surge_multiplier"`
-	SurgeAmount      int64   `json:"surge_amount"`
-	BookingFee       int64   `json:"booking_fee"`
-	TollFees         int64   `json:"toll_fees"`
-	PromoDiscount    int64   `json:"promo_discount"`
-	Total            int64   `json:"total"`
-	Currency         Currency `json:"currency"`
-	DriverEarnings   int64   `json:"driver_earnings"`
-	PlatformFee      int64   `json:"platform_fee"`
+	BaseFare               int64    `json:"base_fare"`
+	DistanceFare           int64    `json:"distance_fare"`
+	TimeFare               int64    `json:"time_fare"`
+	SurgeMultiplier        float64  `json:"surge_multiplier"`
+	SurgeAmount            int64    `json:"surge_amount"`
+	ZoneDiscountMultiplier float64  `json:"zone_discount_multiplier"` // below 1 when the pickup cell has a supply glut
+	ZoneDiscount           int64    `json:"zone_discount"`
+	BookingFee             int64    `json:"booking_fee"`
+	TollFees               int64    `json:"toll_fees"`
+	StopFees               int64    `json:"stop_fees,omitempty"` // charged per stop on the way
+	Stops                  int      `json:"stops,omitempty"`
+	WaitTimeFee            int64    `json:"wait_time_fee,omitempty"` // for keeping the driver waiting at pickup
+	WaitMinutes            int      `json:"wait_minutes,omitempty"`  // billed minutes, after the free wait
+	Legs                   []LegFare `json:"legs,omitempty"`     // distance and time fares leg by leg
+	PromoDiscount          int64    `json:"promo_discount"`
+	Total                  int64    `json:"total"`
+	Currency               Currency `json:"currency"`
+	DriverEarnings         int64    `json:"driver_earnings"`
+	PlatformFee            int64    `json:"platform_fee"`
+	CommissionDiscount     float64  `json:"commission_discount,omitempty"` // off the commission for the driver's quality tier
 }
 
 // Ride represents a ride request in the system
@@ -102,6 +131,7 @@ type Ride struct {
 	PickupLocation  Location       `json:"pickup_location"`
 	DropoffLocation Location       `json:"dropoff_location"`
 	Stops           []Location     `json:"stops,omitempty"`
+	StopStates      []StopState    `json:"stop_states,omitempty"` // progress through Stops once the trip starts
 	CurrentLocation *Location      `json:"current_location,omitempty"`
 	
 	// Ride details
@@ -138,9 +168,16 @@ type Ride struct {
 	// Metadata
 	Metadata        map[string]any `json:"metadata,omitempty"`
 	
+	// Masked call and chat channel, set on a party's view (see ViewFor); never stored
+	Contact         *ContactChannel `json:"contact,omitempty"`
+	
 	// Audit
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
+	Version         int64          `json:"version"` // bumped on every write, for optimistic concurrency
+	
+	// Timeline events awaiting persistence (written with the ride, outbox-style)
+	pendingEvents   []*RideEvent
 }
 
 // RideRequest represents a request to create a new ride
@@ -154,6 +191,13 @@ type RideRequest struct {
 	ScheduledFor    *time.Time    `json:"scheduled_for"`
 	PromoCode       string        `json:"promo_code"`
 	Notes           string        `json:"notes"`
+	RouteQuoteID    *uuid.UUID    `json:"route_quote_id"`   // route options quoted at confirmation
+	RoutePreference RoutePreference `json:"route_preference"` // the option picked from the quote
+	Avoid           RouteAvoidance  `json:"avoid"`            // road classes to route around
+	BusinessAccountID *uuid.UUID    `json:"business_account_id"` // ride on a business profile
+	CostCenter      string          `json:"cost_center"`         // charged on the business account
+	RedeemPoints    int64           `json:"redeem_points"`       // loyalty points to take off the fare
+	Preference      RidePreference  `json:"preference"`          // GREEN to be matched with electric and hybrid vehicles first
 }
 
 // DriverOffer represents a driver's offer to fulfill a ride
@@ -177,12 +221,13 @@ type CancellationPolicy struct {
 // NewRide creates a new ride from a request
 func NewRide(req *RideRequest) *Ride {
 	now := time.Now().UTC()
-	return &Ride{
+	ride := &Ride{
 		ID:              uuid.New(),
 		RiderID:         req.RiderID,
 		PickupLocation:  req.PickupLocation,
 		DropoffLocation: req.DropoffLocation,
 		Stops:           req.Stops,
+		StopStates:      newStopStates(len(req.Stops)),
 		Type:            req.Type,
 		Status:          RideStatusPending,
 		PaymentMethod:   req.PaymentMethod,
@@ -193,20 +238,27 @@ func NewRide(req *RideRequest) *Ride {
 		UpdatedAt:       now,
 		Metadata:        make(map[string]any),
 	}
+	
+	ride.recordTransition(RideEventRequested, "", RideStatusPending).
+		WithActor(req.RiderID).
+		WithData("type", req.Type)
+	
+	return ride
 }
 
 // CanTransitionTo checks if a status transition is valid
 func (r *Ride) CanTransitionTo(newStatus RideStatus) bool {
 	validTransitions := map[RideStatus][]RideStatus{
-		RideStatusPending:    {RideStatusSearching, RideStatusCancelled},
-		RideStatusSearching:  {RideStatusMatched, RideStatusCancelled},
-		RideStatusMatched:    {RideStatusAccepted, RideStatusSearching, RideStatusCancelled},
+		RideStatusPending:    {RideStatusSearching, RideStatusCancelled, RideStatusExpired},
+		RideStatusSearching:  {RideStatusMatched, RideStatusCancelled, RideStatusExpired},
+		RideStatusMatched:    {RideStatusAccepted, RideStatusSearching, RideStatusCancelled, RideStatusExpired},
 		RideStatusAccepted:   {RideStatusArriving, RideStatusCancelled},
 		RideStatusArriving:   {RideStatusArrived, RideStatusCancelled},
 		RideStatusArrived:    {RideStatusInProgress, RideStatusCancelled},
 		RideStatusInProgress: {RideStatusCompleted, RideStatusCancelled},
 		RideStatusCompleted:  {},
 		RideStatusCancelled:  {},
+		RideStatusExpired:    {},
 	}
 	
 	allowed, exists := validTransitions[r.Status]
@@ -229,6 +281,7 @@ func (r *Ride) UpdateStatus(newStatus RideStatus) error {
 	}
 	
 	now := time.Now().UTC()
+	r.recordTransition(RideEventStatusChanged, r.Status, newStatus)
 	r.Status = newStatus
 	r.UpdatedAt = now
 	
@@ -253,6 +306,9 @@ func (r *Ride) AssignDriver(driverID, vehicleID uuid.UUID) error {
 	}
 	
 	now := time.Now().UTC()
+	r.recordTransition(RideEventDriverAssigned, r.Status, RideStatusAccepted).
+		WithActor(driverID).
+		WithData("vehicle_id", vehicleID)
 	r.DriverID = &driverID
 	r.VehicleID = &vehicleID
 	r.Status = RideStatusAccepted
@@ -264,11 +320,14 @@ func (r *Ride) AssignDriver(driverID, vehicleID uuid.UUID) error {
 
 // Cancel cancels the ride with a reason
 func (r *Ride) Cancel(cancelledBy uuid.UUID, reason string) error {
-	if r.Status == RideStatusCompleted || r.Status == RideStatusCancelled {
+	if !r.IsActive() {
 		return ErrRideAlreadyEnded
 	}
 	
 	now := time.Now().UTC()
+	r.recordTransition(RideEventCancelled, r.Status, RideStatusCancelled).
+		WithActor(cancelledBy).
+		WithData("reason", reason)
 	r.Status = RideStatusCancelled
 	r.CancelledBy = &cancelledBy
 	r.CancellationReason = reason
@@ -280,7 +339,7 @@ func (r *Ride) Cancel(cancelledBy uuid.UUID, reason string) error {
 
 // IsActive returns true if the ride is in an active state
 func (r *Ride) IsActive() bool {
-	return r.Status != RideStatusCompleted && r.Status != RideStatusCancelled
+	return r.Status != RideStatusCompleted && r.Status != RideStatusCancelled && r.Status != RideStatusExpired
 }
 
 // WaitTimeSeconds returns how long the rider has been waiting
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxRideMessageLength is the longest chat message, in characters
+	MaxRideMessageLength = 1000
+
+	// MaxRideMessages is the most messages a ride's chat keeps
+	MaxRideMessages = 500
+)
+
+// RideMessage is a chat message between a ride's rider and driver
+type RideMessage struct {
+	ID       string    `json:"id"` // orders messages within the ride
+	RideID   uuid.UUID `json:"ride_id"`
+	SenderID uuid.UUID `json:"sender_id"`
+	Sender   RideParty `json:"sender"`
+	Body     string    `json:"body"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+// NewRideMessage creates a message from one of a ride's parties
+func NewRideMessage(rideID, senderID uuid.UUID, sender RideParty, body string, now time.Time) (*RideMessage, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || utf8.RuneCountInString(body) > MaxRideMessageLength {
+		return nil, ErrInvalidRideMessage
+	}
+	return &RideMessage{
+		RideID:   rideID,
+		SenderID: senderID,
+		Sender:   sender,
+		Body:     body,
+		SentAt:   now,
+	}, nil
+}
+
+// RideChat is a ride's messages, live while the chat is open and archived
+// once the ride ends
+type RideChat struct {
+	RideID   uuid.UUID      `json:"ride_id"`
+	Open     bool           `json:"open"`
+	Messages []*RideMessage `json:"messages"`
+}
+
+// ChatOpen reports whether the ride's rider and driver can message each
+// other: from the driver accepting until the ride ends
+func (r *Ride) ChatOpen() bool {
+	if r.DriverID == nil {
+		return false
+	}
+	switch r.Status {
+	case RideStatusAccepted, RideStatusArriving, RideStatusArrived, RideStatusInProgress:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewRideMessage(t *testing.T) {
+	now := time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+		err  error
+	}{
+		{"trimmed", "  I'm at the gate  ", "I'm at the gate", nil},
+		{"blank", "   ", "", ErrInvalidRideMessage},
+		{"longest", strings.Repeat("é", MaxRideMessageLength), strings.Repeat("é", MaxRideMessageLength), nil},
+		{"too long", strings.Repeat("a", MaxRideMessageLength+1), "", ErrInvalidRideMessage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := NewRideMessage(uuid.New(), uuid.New(), RidePartyRider, tt.body, now)
+			if err != tt.err {
+				t.Fatalf("NewRideMessage() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && msg.Body != tt.want {
+				t.Errorf("Body = %q, want %q", msg.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestRideChatOpen(t *testing.T) {
+	driverID := uuid.New()
+
+	tests := []struct {
+		status   RideStatus
+		driverID *uuid.UUID
+		want     bool
+	}{
+		{RideStatusSearching, nil, false},
+		{RideStatusMatched, &driverID, false},
+		{RideStatusAccepted, &driverID, true},
+		{RideStatusArriving, &driverID, true},
+		{RideStatusArrived, &driverID, true},
+		{RideStatusInProgress, &driverID, true},
+		{RideStatusInProgress, nil, false},
+		{RideStatusCompleted, &driverID, false},
+		{RideStatusCancelled, &driverID, false},
+	}
+
+	for _, tt := range tests {
+		ride := &Ride{Status: tt.status, DriverID: tt.driverID}
+		if got := ride.ChatOpen(); got != tt.want {
+			t.Errorf("ChatOpen() for %s with driver %v = %v, want %v", tt.status, tt.driverID != nil, got, tt.want)
+		}
+	}
+}
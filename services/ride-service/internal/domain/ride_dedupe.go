@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Ride request deduplication settings
+const (
+	RideDedupeWindow  = 2 * time.Minute // a repeat request this soon is a double tap
+	RideDedupeRadiusM = 75.0            // pickups and dropoffs this close count as the same place
+)
+
+// IsDuplicateRequest reports whether req repeats this ride: the same rider
+// asking again for near-identical pickup and dropoff, within the dedupe
+// window, before a driver has been matched
+func (r *Ride) IsDuplicateRequest(req *RideRequest, now time.Time) bool {
+	if r.RiderID != req.RiderID {
+		return false
+	}
+	if r.Status != RideStatusPending && r.Status != RideStatusSearching {
+		return false
+	}
+	if now.Sub(r.RequestedAt) > RideDedupeWindow {
+		return false
+	}
+	if (r.ScheduledFor == nil) != (req.ScheduledFor == nil) ||
+		(r.ScheduledFor != nil && !r.ScheduledFor.Equal(*req.ScheduledFor)) {
+		return false
+	}
+	return nearSamePlace(r.PickupLocation, req.PickupLocation) && nearSamePlace(r.DropoffLocation, req.DropoffLocation)
+}
+
+// nearSamePlace reports whether two locations are within the dedupe
+// radius, using an equirectangular approximation that is exact enough at
+// these distances
+func nearSamePlace(a, b Location) bool {
+	const earthRadiusM = 6371000.0
+	lat := (a.Latitude + b.Latitude) / 2 * math.Pi / 180
+	dx := (b.Longitude - a.Longitude) * math.Pi / 180 * math.Cos(lat)
+	dy := (b.Latitude - a.Latitude) * math.Pi / 180
+	return math.Hypot(dx, dy)*earthRadiusM <= RideDedupeRadiusM
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRideIsDuplicateRequest(t *testing.T) {
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	riderID := uuid.New()
+	pickup := Location{Latitude: 6.4281, Longitude: 3.4219}
+	dropoff := Location{Latitude: 6.4550, Longitude: 3.3841}
+
+	existing := func() *Ride {
+		return &Ride{
+			RiderID:         riderID,
+			Status:          RideStatusSearching,
+			PickupLocation:  pickup,
+			DropoffLocation: dropoff,
+			RequestedAt:     now.Add(-30 * time.Second),
+		}
+	}
+	request := func() *RideRequest {
+		return &RideRequest{
+			RiderID:         riderID,
+			PickupLocation:  Location{Latitude: 6.4283, Longitude: 3.4220}, // about 25m away
+			DropoffLocation: dropoff,
+		}
+	}
+
+	if !existing().IsDuplicateRequest(request(), now) {
+		t.Error("double tap within the window should be a duplicate")
+	}
+
+	otherRider := request()
+	otherRider.RiderID = uuid.New()
+	if existing().IsDuplicateRequest(otherRider, now) {
+		t.Error("another rider's request is not a duplicate")
+	}
+
+	elsewhere := request()
+	elsewhere.DropoffLocation = Location{Latitude: 6.4600, Longitude: 3.3841}
+	if existing().IsDuplicateRequest(elsewhere, now) {
+		t.Error("a different dropoff is not a duplicate")
+	}
+
+	if existing().IsDuplicateRequest(request(), now.Add(RideDedupeWindow)) {
+		t.Error("a request after the window is not a duplicate")
+	}
+
+	matched := existing()
+	matched.Status = RideStatusAccepted
+	if matched.IsDuplicateRequest(request(), now) {
+		t.Error("a ride already accepted is not repeated by a new request")
+	}
+
+	later := now.Add(3 * time.Hour)
+	scheduled := request()
+	scheduled.ScheduledFor = &later
+	if existing().IsDuplicateRequest(scheduled, now) {
+		t.Error("a scheduled request does not repeat an immediate ride")
+	}
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RideEventType represents the type of entry in a ride's timeline
+type RideEventType string
+
+const (
+	RideEventRequested       RideEventType = "RIDE_REQUESTED"
+	RideEventStatusChanged   RideEventType = "STATUS_CHANGED"
+	RideEventDriverAssigned  RideEventType = "DRIVER_ASSIGNED"
+	RideEventCancelled       RideEventType = "RIDE_CANCELLED"
+	RideEventMatchingAttempt RideEventType = "MATCHING_ATTEMPT"
+	RideEventOfferSent       RideEventType = "OFFER_SENT"
+	RideEventOfferDeclined   RideEventType = "OFFER_DECLINED"
+	RideEventOfferAccepted   RideEventType = "OFFER_ACCEPTED"
+	RideEventRouteDeviation  RideEventType = "ROUTE_DEVIATION"
+	RideEventFareAdjusted    RideEventType = "FARE_ADJUSTED"
+	RideEventRated           RideEventType = "RIDE_RATED"
+	RideEventFareDisputed    RideEventType = "FARE_DISPUTED"
+	RideEventRetentionNudge  RideEventType = "RETENTION_NUDGE"
+	RideEventRerouteSuggested RideEventType = "REROUTE_SUGGESTED"
+	RideEventRedispatched    RideEventType = "RIDE_REDISPATCHED"
+	RideEventExpired         RideEventType = "RIDE_EXPIRED"
+	RideEventPoolJoined      RideEventType = "POOL_JOINED"
+	RideEventStopArrived     RideEventType = "STOP_ARRIVED"
+	RideEventStopDeparted    RideEventType = "STOP_DEPARTED"
+	RideEventTripShared      RideEventType = "TRIP_SHARED"
+	RideEventSOSRaised       RideEventType = "SOS_RAISED"
+	RideEventInsuranceClaim  RideEventType = "INSURANCE_CLAIM_FILED"
+	RideEventRiderReported   RideEventType = "RIDER_REPORTED"
+	RideEventReceiptIssued   RideEventType = "RECEIPT_ISSUED"
+	RideEventLostItemReported RideEventType = "LOST_ITEM_REPORTED"
+	RideEventLostItemUpdated RideEventType = "LOST_ITEM_UPDATED"
+	RideEventManualDispatch  RideEventType = "MANUAL_DISPATCH"
+)
+
+// RideEvent is a single structured entry in a ride's timeline
+type RideEvent struct {
+	ID         uuid.UUID      `json:"id"`
+	RideID     uuid.UUID      `json:"ride_id"`
+	Type       RideEventType  `json:"type"`
+	FromStatus RideStatus     `json:"from_status,omitempty"`
+	ToStatus   RideStatus     `json:"to_status,omitempty"`
+	ActorID    *uuid.UUID     `json:"actor_id,omitempty"`
+	Data       map[string]any `json:"data,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// NewRideEvent creates a new ride event
+func NewRideEvent(rideID uuid.UUID, eventType RideEventType) *RideEvent {
+	return &RideEvent{
+		ID:        uuid.New(),
+		RideID:    rideID,
+		Type:      eventType,
+		Data:      make(map[string]any),
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// WithActor sets the user or driver responsible for the event
+func (e *RideEvent) WithActor(actorID uuid.UUID) *RideEvent {
+	e.ActorID = &actorID
+	return e
+}
+
+// WithData adds a key/value pair to the event payload
+func (e *RideEvent) WithData(key string, value any) *RideEvent {
+	e.Data[key] = value
+	return e
+}
+
+// RecordEvent queues an event to be persisted with the next ride write
+func (r *Ride) RecordEvent(event *RideEvent) {
+	r.pendingEvents = append(r.pendingEvents, event)
+}
+
+// PendingEvents returns events recorded since the ride was last persisted
+func (r *Ride) PendingEvents() []*RideEvent {
+	return r.pendingEvents
+}
+
+// ClearPendingEvents drops queued events once they have been persisted
+func (r *Ride) ClearPendingEvents() {
+	r.pendingEvents = nil
+}
+
+// recordTransition queues a status change event
+func (r *Ride) recordTransition(eventType RideEventType, from, to RideStatus) *RideEvent {
+	event := NewRideEvent(r.ID, eventType)
+	event.FromStatus = from
+	event.ToStatus = to
+	r.RecordEvent(event)
+	return event
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRideRecordsTimelineEvents(t *testing.T) {
+	riderID := uuid.New()
+	ride := NewRide(&RideRequest{RiderID: riderID, Type: RideTypeStandard})
+
+	if err := ride.UpdateStatus(RideStatusSearching); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	if err := ride.Cancel(riderID, "changed plans"); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+
+	events := ride.PendingEvents()
+	if len(events) != 3 {
+		t.Fatalf("expected 3 pending events, got %d", len(events))
+	}
+
+	wantTypes := []RideEventType{RideEventRequested, RideEventStatusChanged, RideEventCancelled}
+	for i, want := range wantTypes {
+		if events[i].Type != want {
+			t.Errorf("event %d type = %s, want %s", i, events[i].Type, want)
+		}
+		if events[i].RideID != ride.ID {
+			t.Errorf("event %d ride ID = %s, want %s", i, events[i].RideID, ride.ID)
+		}
+	}
+
+	if events[1].FromStatus != RideStatusPending || events[1].ToStatus != RideStatusSearching {
+		t.Errorf("status change = %s -> %s, want PENDING -> SEARCHING", events[1].FromStatus, events[1].ToStatus)
+	}
+	if events[2].ActorID == nil || *events[2].ActorID != riderID {
+		t.Errorf("cancel event actor not set to rider")
+	}
+
+	ride.ClearPendingEvents()
+	if len(ride.PendingEvents()) != 0 {
+		t.Errorf("expected no pending events after clear")
+	}
+}
+
+func TestRideRejectedTransitionRecordsNothing(t *testing.T) {
+	ride := NewRide(&RideRequest{RiderID: uuid.New(), Type: RideTypeStandard})
+	ride.ClearPendingEvents()
+
+	if err := ride.UpdateStatus(RideStatusCompleted); err != ErrInvalidStatusTransition {
+		t.Fatalf("UpdateStatus() error = %v, want ErrInvalidStatusTransition", err)
+	}
+	if len(ride.PendingEvents()) != 0 {
+		t.Errorf("expected no events for rejected transition")
+	}
+}
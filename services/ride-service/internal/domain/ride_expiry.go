@@ -0,0 +1,89 @@
+package domain
+
+import "time"
+
+// DefaultMatchingTTL is how long a ride searches for a driver before it
+// expires
+const DefaultMatchingTTL = 5 * time.Minute
+
+// RideAlternativeAction is something a rider whose ride expired can do
+// instead
+type RideAlternativeAction string
+
+const (
+	RideAlternativeRetry          RideAlternativeAction = "RETRY"
+	RideAlternativeSchedule       RideAlternativeAction = "SCHEDULE"
+	RideAlternativeChangeRideType RideAlternativeAction = "CHANGE_RIDE_TYPE"
+)
+
+// RideAlternative is one option offered to a rider whose ride expired
+type RideAlternative struct {
+	Action   RideAlternativeAction `json:"action"`
+	RideType RideType              `json:"ride_type,omitempty"` // for CHANGE_RIDE_TYPE
+}
+
+// ExpiryAlternatives lists what a rider can do after their ride expired:
+// request again, schedule it for later, or switch to one of the ride types
+// with drivers nearby
+func ExpiryAlternatives(current RideType, available []RideType) []RideAlternative {
+	alternatives := []RideAlternative{
+		{Action: RideAlternativeRetry},
+		{Action: RideAlternativeSchedule},
+	}
+	for _, rideType := range available {
+		if rideType != current {
+			alternatives = append(alternatives, RideAlternative{
+				Action:   RideAlternativeChangeRideType,
+				RideType: rideType,
+			})
+		}
+	}
+	return alternatives
+}
+
+// MatchingStartedAt is when the ride last started looking for a driver:
+// when it was requested, or when it went back to searching after losing
+// its driver
+func (r *Ride) MatchingStartedAt() time.Time {
+	switch t := r.Metadata["matching_started_at"].(type) {
+	case time.Time:
+		return t
+	case string:
+		// Metadata read back from the database holds times as strings
+		if parsed, err := time.Parse(time.RFC3339Nano, t); err == nil {
+			return parsed
+		}
+	}
+	return r.RequestedAt
+}
+
+// MatchingExpired reports whether the ride has been looking for a driver
+// for longer than ttl. Scheduled rides are left to their pickup guarantee.
+func (r *Ride) MatchingExpired(now time.Time, ttl time.Duration) bool {
+	switch r.Status {
+	case RideStatusPending, RideStatusSearching, RideStatusMatched:
+	default:
+		return false
+	}
+	return r.ScheduledFor == nil && !now.Before(r.MatchingStartedAt().Add(ttl))
+}
+
+// Expire ends a ride that found no driver in time, keeping the
+// alternatives offered to the rider with it
+func (r *Ride) Expire(alternatives []RideAlternative) error {
+	if !r.CanTransitionTo(RideStatusExpired) {
+		return ErrInvalidStatusTransition
+	}
+
+	now := time.Now().UTC()
+	r.recordTransition(RideEventExpired, r.Status, RideStatusExpired).
+		WithData("searched_seconds", int64(now.Sub(r.MatchingStartedAt()).Seconds()))
+	if r.Metadata == nil {
+		r.Metadata = make(map[string]any)
+	}
+	r.Metadata["expiry_alternatives"] = alternatives
+	r.Status = RideStatusExpired
+	r.UpdatedAt = now
+
+	return nil
+}
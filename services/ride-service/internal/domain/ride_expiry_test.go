@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRideMatchingExpired(t *testing.T) {
+	requestedAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	scheduledFor := requestedAt.Add(2 * time.Hour)
+
+	tests := []struct {
+		name      string
+		status    RideStatus
+		scheduled *time.Time
+		restarted string
+		at        time.Time
+		want      bool
+	}{
+		{"still searching", RideStatusSearching, nil, "", requestedAt.Add(4 * time.Minute), false},
+		{"searched past ttl", RideStatusSearching, nil, "", requestedAt.Add(5 * time.Minute), true},
+		{"offer out past ttl", RideStatusMatched, nil, "", requestedAt.Add(6 * time.Minute), true},
+		{"driver accepted", RideStatusAccepted, nil, "", requestedAt.Add(time.Hour), false},
+		{"scheduled ride", RideStatusSearching, &scheduledFor, "", requestedAt.Add(time.Hour), false},
+		{"searching again after redispatch", RideStatusSearching, nil, requestedAt.Add(40 * time.Minute).Format(time.RFC3339Nano), requestedAt.Add(42 * time.Minute), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ride := &Ride{Status: tt.status, RequestedAt: requestedAt, ScheduledFor: tt.scheduled, Metadata: map[string]any{}}
+			if tt.restarted != "" {
+				ride.Metadata["matching_started_at"] = tt.restarted
+			}
+			if got := ride.MatchingExpired(tt.at, DefaultMatchingTTL); got != tt.want {
+				t.Errorf("MatchingExpired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRideExpire(t *testing.T) {
+	ride := &Ride{Status: RideStatusSearching, RequestedAt: time.Now().UTC().Add(-6 * time.Minute)}
+	alternatives := ExpiryAlternatives(RideTypeStandard, []RideType{RideTypeStandard, RideTypeBoda})
+
+	if err := ride.Expire(alternatives); err != nil {
+		t.Fatalf("Expire() error = %v", err)
+	}
+	if ride.Status != RideStatusExpired || ride.IsActive() {
+		t.Errorf("status = %s, active = %v, want inactive EXPIRED", ride.Status, ride.IsActive())
+	}
+	if events := ride.PendingEvents(); len(events) != 1 || events[0].Type != RideEventExpired {
+		t.Errorf("events = %+v, want one RIDE_EXPIRED", events)
+	}
+	if err := ride.Cancel(ride.RiderID, "too late"); err != ErrRideAlreadyEnded {
+		t.Errorf("Cancel() after expiry error = %v, want ErrRideAlreadyEnded", err)
+	}
+	if err := ride.Expire(alternatives); err != ErrInvalidStatusTransition {
+		t.Errorf("Expire() twice error = %v, want ErrInvalidStatusTransition", err)
+	}
+}
+
+func TestExpiryAlternatives(t *testing.T) {
+	got := ExpiryAlternatives(RideTypeStandard, []RideType{RideTypeStandard, RideTypeBoda, RideTypeXL})
+	want := []RideAlternative{
+		{Action: RideAlternativeRetry},
+		{Action: RideAlternativeSchedule},
+		{Action: RideAlternativeChangeRideType, RideType: RideTypeBoda},
+		{Action: RideAlternativeChangeRideType, RideType: RideTypeXL},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ExpiryAlternatives() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("alternative %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
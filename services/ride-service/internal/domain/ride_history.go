@@ -0,0 +1,196 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultRideHistoryLimit is the page size when none is asked for
+	DefaultRideHistoryLimit = 20
+	// MaxRideHistoryLimit is the largest page of history served
+	MaxRideHistoryLimit = 100
+	// RideHistoryExportBatch is how many rides an export reads at a time
+	RideHistoryExportBatch = 500
+	// MaxRideHistoryExportRange is the longest range one export may cover;
+	// exports without a start cover this much up to their end
+	MaxRideHistoryExportRange = 366 * 24 * time.Hour
+)
+
+// RideHistoryFilter narrows a rider's or driver's ride history. Rides are
+// listed newest first by request time.
+type RideHistoryFilter struct {
+	From     *time.Time   // requested at or after
+	To       *time.Time   // requested before
+	Statuses []RideStatus // any of these; all when empty
+	Types    []RideType   // any of these; all when empty
+	Cursor   *RideHistoryCursor
+	Limit    int
+}
+
+// Validate checks the filter and defaults its page size
+func (f *RideHistoryFilter) Validate() error {
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidRideHistoryFilter)
+	}
+	for _, status := range f.Statuses {
+		if !containsRideStatus(RideStatuses, status) {
+			return fmt.Errorf("%w: unknown status %q", ErrInvalidRideHistoryFilter, status)
+		}
+	}
+	for _, rideType := range f.Types {
+		if !containsRideType(RideTypes, rideType) {
+			return fmt.Errorf("%w: unknown ride type %q", ErrInvalidRideHistoryFilter, rideType)
+		}
+	}
+	switch {
+	case f.Limit == 0:
+		f.Limit = DefaultRideHistoryLimit
+	case f.Limit < 0 || f.Limit > MaxRideHistoryLimit:
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidRideHistoryFilter, MaxRideHistoryLimit)
+	}
+	return nil
+}
+
+func containsRideStatus(statuses []RideStatus, status RideStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportRange bounds an export's range, ending now when no end is given,
+// and returns ErrInvalidRideHistoryFilter when it is too long
+func (f *RideHistoryFilter) ExportRange(now time.Time) error {
+	if f.To == nil {
+		f.To = &now
+	}
+	if f.From == nil {
+		from := f.To.Add(-MaxRideHistoryExportRange)
+		f.From = &from
+	}
+	if f.To.Sub(*f.From) > MaxRideHistoryExportRange {
+		return fmt.Errorf("%w: exports cover at most %d days", ErrInvalidRideHistoryFilter, int(MaxRideHistoryExportRange.Hours()/24))
+	}
+	return nil
+}
+
+// RideHistoryCursor is where the next page of history starts: the request
+// time and ID of the last ride on the previous page
+type RideHistoryCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// Encode returns the cursor as an opaque token for clients to pass back
+func (c *RideHistoryCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// ParseRideHistoryCursor reads a token from Encode
+func ParseRideHistoryCursor(token string) (*RideHistoryCursor, error) {
+	var c RideHistoryCursor
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(raw, &c)
+	}
+	if err != nil || c.ID == uuid.Nil || c.CreatedAt.IsZero() {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrInvalidRideHistoryFilter)
+	}
+	return &c, nil
+}
+
+// RideHistoryPage is one page of a ride history
+type RideHistoryPage struct {
+	Rides      []*Ride               `json:"rides"`
+	Pagination RideHistoryPagination `json:"pagination"`
+}
+
+// RideHistoryPagination says how to fetch the page after this one
+type RideHistoryPagination struct {
+	Limit      int    `json:"limit"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewRideHistoryPage pages rides read with one more than the limit, the
+// extra ride only telling whether another page follows
+func NewRideHistoryPage(rides []*Ride, limit int) *RideHistoryPage {
+	page := &RideHistoryPage{
+		Rides:      rides,
+		Pagination: RideHistoryPagination{Limit: limit},
+	}
+	if page.Rides == nil {
+		page.Rides = []*Ride{}
+	}
+	if len(rides) > limit {
+		page.Rides = rides[:limit]
+		last := page.Rides[limit-1]
+		page.Pagination.HasMore = true
+		page.Pagination.NextCursor = (&RideHistoryCursor{CreatedAt: last.CreatedAt, ID: last.ID}).Encode()
+	}
+	return page
+}
+
+// RideHistoryCSVHeader are the columns of a ride history export
+var RideHistoryCSVHeader = []string{
+	"ride_id", "requested_at", "completed_at", "cancelled_at", "status", "type",
+	"pickup_address", "dropoff_address", "distance_km", "duration_minutes",
+	"fare", "promo_discount", "currency", "payment_method",
+}
+
+// HistoryCSVRecord returns the ride as a row of a history export. Times are
+// UTC; fares are in major units of the ride's currency.
+func (r *Ride) HistoryCSVRecord() []string {
+	record := []string{
+		r.ID.String(),
+		r.RequestedAt.UTC().Format(time.RFC3339),
+		csvTime(r.CompletedAt),
+		csvTime(r.CancelledAt),
+		string(r.Status),
+		string(r.Type),
+		csvText(r.PickupLocation.Address),
+		csvText(r.DropoffLocation.Address),
+		"", "", "", "", "",
+		string(r.PaymentMethod),
+	}
+	if r.Route != nil {
+		record[8] = strconv.FormatFloat(float64(r.Route.DistanceMeters)/1000, 'f', 2, 64)
+		record[9] = strconv.FormatInt((r.Route.DurationSeconds+59)/60, 10)
+	}
+	if r.Price != nil {
+		record[10] = csvMoney(r.Price.Total)
+		record[11] = csvMoney(r.Price.PromoDiscount)
+		record[12] = string(r.Price.Currency)
+	}
+	return record
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func csvMoney(minor int64) string {
+	return strconv.FormatFloat(float64(minor)/100, 'f', 2, 64)
+}
+
+// csvText keeps free text a rider typed from being read as a formula when
+// the export is opened in a spreadsheet
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRideHistoryFilterValidate(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name      string
+		filter    RideHistoryFilter
+		wantErr   bool
+		wantLimit int
+	}{
+		{name: "empty", filter: RideHistoryFilter{}, wantLimit: DefaultRideHistoryLimit},
+		{name: "range", filter: RideHistoryFilter{From: &from, To: &to, Limit: 50}, wantLimit: 50},
+		{name: "known status and type", filter: RideHistoryFilter{Statuses: []RideStatus{RideStatusCompleted}, Types: []RideType{RideTypePool}}, wantLimit: DefaultRideHistoryLimit},
+		{name: "reversed range", filter: RideHistoryFilter{From: &to, To: &from}, wantErr: true},
+		{name: "unknown status", filter: RideHistoryFilter{Statuses: []RideStatus{"DONE"}}, wantErr: true},
+		{name: "unknown type", filter: RideHistoryFilter{Types: []RideType{"JET"}}, wantErr: true},
+		{name: "limit too large", filter: RideHistoryFilter{Limit: MaxRideHistoryLimit + 1}, wantErr: true},
+		{name: "negative limit", filter: RideHistoryFilter{Limit: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			err := filter.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRideHistoryFilter) {
+					t.Fatalf("Validate() error = %v, want ErrInvalidRideHistoryFilter", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() unexpected error = %v", err)
+			}
+			if filter.Limit != tt.wantLimit {
+				t.Errorf("Limit = %d, want %d", filter.Limit, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestRideHistoryFilterExportRange(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	var open RideHistoryFilter
+	if err := open.ExportRange(now); err != nil {
+		t.Fatalf("ExportRange() unexpected error = %v", err)
+	}
+	if !open.To.Equal(now) || !open.From.Equal(now.Add(-MaxRideHistoryExportRange)) {
+		t.Errorf("ExportRange() = %v to %v, want the year up to now", open.From, open.To)
+	}
+
+	from := now.AddDate(-2, 0, 0)
+	tooLong := RideHistoryFilter{From: &from}
+	if err := tooLong.ExportRange(now); !errors.Is(err, ErrInvalidRideHistoryFilter) {
+		t.Errorf("ExportRange() error = %v, want ErrInvalidRideHistoryFilter", err)
+	}
+}
+
+func TestRideHistoryCursor(t *testing.T) {
+	cursor := &RideHistoryCursor{CreatedAt: time.Date(2025, 3, 4, 5, 6, 7, 123000, time.UTC), ID: uuid.New()}
+
+	got, err := ParseRideHistoryCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("ParseRideHistoryCursor() unexpected error = %v", err)
+	}
+	if !got.CreatedAt.Equal(cursor.CreatedAt) || got.ID != cursor.ID {
+		t.Errorf("ParseRideHistoryCursor() = %+v, want %+v", got, cursor)
+	}
+
+	for _, token := range []string{"", "not base64!", "e30"} {
+		if _, err := ParseRideHistoryCursor(token); !errors.Is(err, ErrInvalidRideHistoryFilter) {
+			t.Errorf("ParseRideHistoryCursor(%q) error = %v, want ErrInvalidRideHistoryFilter", token, err)
+		}
+	}
+}
+
+func TestNewRideHistoryPage(t *testing.T) {
+	rides := make([]*Ride, 3)
+	for i := range rides {
+		rides[i] = &Ride{ID: uuid.New(), CreatedAt: time.Now().Add(-time.Duration(i) * time.Hour)}
+	}
+
+	page := NewRideHistoryPage(rides, 2)
+	if len(page.Rides) != 2 || !page.Pagination.HasMore {
+		t.Fatalf("NewRideHistoryPage() = %d rides, has_more %v, want 2 and true", len(page.Rides), page.Pagination.HasMore)
+	}
+	next, err := ParseRideHistoryCursor(page.Pagination.NextCursor)
+	if err != nil || next.ID != rides[1].ID {
+		t.Errorf("next cursor = %+v, %v, want the second ride", next, err)
+	}
+
+	last := NewRideHistoryPage(rides, 3)
+	if last.Pagination.HasMore || last.Pagination.NextCursor != "" {
+		t.Errorf("NewRideHistoryPage() on the last page = %+v", last.Pagination)
+	}
+	if empty := NewRideHistoryPage(nil, 20); empty.Rides == nil {
+		t.Error("NewRideHistoryPage() with no rides should list none, not null")
+	}
+}
+
+func TestRideHistoryCSVRecord(t *testing.T) {
+	completed := time.Date(2025, 3, 4, 10, 30, 0, 0, time.UTC)
+	ride := &Ride{
+		ID:              uuid.New(),
+		Status:          RideStatusCompleted,
+		Type:            RideTypeStandard,
+		PaymentMethod:   PaymentMethodCard,
+		PickupLocation:  Location{Address: "=HYPERLINK(\"x\")"},
+		DropoffLocation: Location{Address: "Westlands"},
+		RequestedAt:     completed.Add(-30 * time.Minute),
+		CompletedAt:     &completed,
+		Route:           &RouteInfo{DistanceMeters: 12345, DurationSeconds: 1201},
+		Price:           &PriceBreakdown{Total: 152050, PromoDiscount: 10000, Currency: CurrencyKES},
+	}
+
+	record := ride.HistoryCSVRecord()
+	if len(record) != len(RideHistoryCSVHeader) {
+		t.Fatalf("HistoryCSVRecord() has %d columns, header has %d", len(record), len(RideHistoryCSVHeader))
+	}
+	want := map[int]string{
+		2:  "2025-03-04T10:30:00Z",
+		3:  "",
+		6:  "'=HYPERLINK(\"x\")",
+		7:  "Westlands",
+		8:  "12.35",
+		9:  "21",
+		10: "1520.50",
+		11: "100.00",
+		12: "KES",
+	}
+	for i, v := range want {
+		if record[i] != v {
+			t.Errorf("%s = %q, want %q", RideHistoryCSVHeader[i], record[i], v)
+		}
+	}
+}
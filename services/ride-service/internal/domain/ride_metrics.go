@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// RideHourlyMetrics is the ride summary for one market and hour. Requests
+// count in the hour the ride was requested, completions and cancellations
+// in the hour they happened.
+type RideHourlyMetrics struct {
+	Hour               time.Time `json:"hour"`
+	Market             string    `json:"market"` // city code, or currency for rides outside a city bundle
+	Currency           Currency  `json:"currency"`
+	Requested          int64     `json:"requested"`
+	Completed          int64     `json:"completed"`
+	Cancelled          int64     `json:"cancelled"`
+	Revenue            int64     `json:"revenue"` // sum of completed fares
+	FareCount          int64     `json:"fare_count"`
+	DurationSumSeconds int64     `json:"duration_sum_seconds"` // started to completed
+	DurationCount      int64     `json:"duration_count"`
+}
+
+// Add folds another hour's counters into m
+func (m *RideHourlyMetrics) Add(o *RideHourlyMetrics) {
+	m.Requested += o.Requested
+	m.Completed += o.Completed
+	m.Cancelled += o.Cancelled
+	m.Revenue += o.Revenue
+	m.FareCount += o.FareCount
+	m.DurationSumSeconds += o.DurationSumSeconds
+	m.DurationCount += o.DurationCount
+}
+
+// RideMetricsSummary is a market's ride summary over a period
+type RideMetricsSummary struct {
+	Market             string   `json:"market"`
+	Currency           Currency `json:"currency"`
+	Requested          int64    `json:"requested"`
+	Completed          int64    `json:"completed"`
+	Cancelled          int64    `json:"cancelled"`
+	Revenue            int64    `json:"revenue"`
+	AvgFare            float64  `json:"avg_fare"`
+	AvgDurationSeconds float64  `json:"avg_duration_seconds"`
+	CompletionRate     float64  `json:"completion_rate"` // completed per 100 requested
+}
+
+// SummarizeRideMetrics totals hourly metrics by market and currency,
+// busiest market first
+func SummarizeRideMetrics(hours []*RideHourlyMetrics) []*RideMetricsSummary {
+	type key struct {
+		market   string
+		currency Currency
+	}
+	totals := make(map[key]*RideHourlyMetrics)
+	keys := make([]key, 0)
+	for _, h := range hours {
+		k := key{h.Market, h.Currency}
+		if totals[k] == nil {
+			totals[k] = &RideHourlyMetrics{}
+			keys = append(keys, k)
+		}
+		totals[k].Add(h)
+	}
+
+	summaries := make([]*RideMetricsSummary, 0, len(keys))
+	for _, k := range keys {
+		t := totals[k]
+		s := &RideMetricsSummary{
+			Market:    k.market,
+			Currency:  k.currency,
+			Requested: t.Requested,
+			Completed: t.Completed,
+			Cancelled: t.Cancelled,
+			Revenue:   t.Revenue,
+		}
+		if t.FareCount > 0 {
+			s.AvgFare = float64(t.Revenue) / float64(t.FareCount)
+		}
+		if t.DurationCount > 0 {
+			s.AvgDurationSeconds = float64(t.DurationSumSeconds) / float64(t.DurationCount)
+		}
+		if t.Requested > 0 {
+			s.CompletionRate = float64(t.Completed) / float64(t.Requested) * 100
+		}
+		summaries = append(summaries, s)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Requested != summaries[j].Requested {
+			return summaries[i].Requested > summaries[j].Requested
+		}
+		return summaries[i].Market < summaries[j].Market
+	})
+	return summaries
+}
+
+// RideMetricsReport is the per-market ride summary for a period, read from
+// the hourly rollups
+type RideMetricsReport struct {
+	From    time.Time             `json:"from"`
+	To      time.Time             `json:"to"`
+	Markets []*RideMetricsSummary `json:"markets"`
+	Hours   []*RideHourlyMetrics  `json:"hours,omitempty"`
+}
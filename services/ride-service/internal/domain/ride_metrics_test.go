@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeRideMetrics(t *testing.T) {
+	hour := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	hours := []*RideHourlyMetrics{
+		{Hour: hour, Market: "NBO", Currency: CurrencyKES, Requested: 10, Completed: 8, Cancelled: 2,
+			Revenue: 8000, FareCount: 8, DurationSumSeconds: 9600, DurationCount: 8},
+		{Hour: hour.Add(time.Hour), Market: "NBO", Currency: CurrencyKES, Requested: 10, Completed: 7,
+			Revenue: 4000, FareCount: 7, DurationSumSeconds: 8400, DurationCount: 7},
+		{Hour: hour, Market: "LOS", Currency: CurrencyNGN, Requested: 5, Completed: 5,
+			Revenue: 10000, FareCount: 4, DurationSumSeconds: 0, DurationCount: 0},
+	}
+
+	summaries := SummarizeRideMetrics(hours)
+	if len(summaries) != 2 {
+		t.Fatalf("got %d summaries, want 2", len(summaries))
+	}
+
+	nbo := summaries[0]
+	if nbo.Market != "NBO" || nbo.Requested != 20 || nbo.Completed != 15 || nbo.Cancelled != 2 || nbo.Revenue != 12000 {
+		t.Errorf("NBO summary = %+v", nbo)
+	}
+	if nbo.AvgFare != 800 || nbo.AvgDurationSeconds != 1200 || nbo.CompletionRate != 75 {
+		t.Errorf("NBO averages = %v/%v/%v, want 800/1200/75", nbo.AvgFare, nbo.AvgDurationSeconds, nbo.CompletionRate)
+	}
+
+	los := summaries[1]
+	if los.AvgFare != 2500 || los.AvgDurationSeconds != 0 || los.CompletionRate != 100 {
+		t.Errorf("LOS averages = %v/%v/%v, want 2500/0/100", los.AvgFare, los.AvgDurationSeconds, los.CompletionRate)
+	}
+}
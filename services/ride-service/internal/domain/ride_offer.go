@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RideOffer is the payload a driver is offered a ride with: how far away
+// the pickup is, the trip, how it is paid and what they would earn after
+// commission
+type RideOffer struct {
+	RideID               uuid.UUID     `json:"ride_id"`
+	RideType             RideType      `json:"ride_type,omitempty"`
+	PickupLocation       Location      `json:"pickup_location"`
+	PickupDirections     string        `json:"pickup_directions,omitempty"` // landmarks and notes for finding the rider
+	DropoffLocation      *Location     `json:"dropoff_location,omitempty"`
+	Destination          Disclosure    `json:"destination"` // how much of the dropoff is shown
+	PickupDistanceMeters float64       `json:"pickup_distance_meters"`
+	PickupETASeconds     int64         `json:"pickup_eta_seconds"`
+	TripDistanceMeters   float64       `json:"trip_distance_meters,omitempty"`
+	TripDurationSeconds  int64         `json:"trip_duration_seconds,omitempty"`
+	TripLengthBand       TripLength    `json:"trip_length_band,omitempty"`
+	FareEstimate         int64         `json:"fare_estimate"`
+	EstimatedEarnings    int64         `json:"estimated_earnings"`
+	Currency             Currency      `json:"currency,omitempty"`
+	Surge                bool          `json:"surge"`
+	SurgeMultiplier      float64       `json:"surge_multiplier"`
+	PaymentMethod        PaymentMethod `json:"payment_method,omitempty"`
+	Avoid                *RouteAvoidance `json:"avoid,omitempty"` // road classes the rider asked to avoid, for navigation
+	ExpiresInSeconds     int           `json:"expires_in"`
+}
+
+// NewRideOffer builds a ride's offer to one driver. price is the fare
+// priced for the offer and may be nil when the ride could not be priced,
+// in which case the fare fields are left empty.
+func NewRideOffer(
+	ride *Ride,
+	pickupDistanceM float64,
+	pickupETASeconds int64,
+	tripDistanceM float64,
+	tripDurationS int64,
+	price *PriceBreakdown,
+	expiresIn time.Duration,
+) *RideOffer {
+	dropoff := ride.DropoffLocation
+	offer := &RideOffer{
+		RideID:               ride.ID,
+		RideType:             ride.Type,
+		PickupLocation:       ride.PickupLocation,
+		PickupDirections:     ride.PickupLocation.DriverDirections(),
+		DropoffLocation:      &dropoff,
+		Destination:          DisclosureExact,
+		PickupDistanceMeters: pickupDistanceM,
+		PickupETASeconds:     pickupETASeconds,
+		TripDistanceMeters:   tripDistanceM,
+		TripDurationSeconds:  tripDurationS,
+		SurgeMultiplier:      1,
+		PaymentMethod:        ride.PaymentMethod,
+		ExpiresInSeconds:     int(expiresIn.Seconds()),
+	}
+
+	if ride.Route != nil && ride.Route.Avoid != nil {
+		avoid := *ride.Route.Avoid
+		offer.Avoid = &avoid
+	}
+
+	if price != nil {
+		offer.FareEstimate = price.Total
+		offer.EstimatedEarnings = price.DriverEarnings
+		offer.Currency = price.Currency
+		if price.SurgeMultiplier > 1 {
+			offer.Surge = true
+			offer.SurgeMultiplier = price.SurgeMultiplier
+		}
+	}
+	return offer
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewRideOffer(t *testing.T) {
+	ride := &Ride{Type: RideTypeStandard, PaymentMethod: PaymentMethodCash}
+	price := &PriceBreakdown{Total: 250000, DriverEarnings: 200000, Currency: CurrencyNGN, SurgeMultiplier: 1.4}
+
+	offer := NewRideOffer(ride, 1200, 240, 8000, 1500, price, 30*time.Second)
+
+	if offer.EstimatedEarnings != 200000 || offer.FareEstimate != 250000 {
+		t.Errorf("fare = %d, earnings = %d; want 250000, 200000", offer.FareEstimate, offer.EstimatedEarnings)
+	}
+	if !offer.Surge || offer.SurgeMultiplier != 1.4 {
+		t.Errorf("surge = %v x%v, want surging at 1.4", offer.Surge, offer.SurgeMultiplier)
+	}
+	if offer.PaymentMethod != PaymentMethodCash || offer.ExpiresInSeconds != 30 {
+		t.Errorf("payment = %s, expires in %d; want CASH, 30", offer.PaymentMethod, offer.ExpiresInSeconds)
+	}
+}
+
+func TestNewRideOfferUnpriced(t *testing.T) {
+	offer := NewRideOffer(&Ride{Type: RideTypeBoda}, 500, 60, 3000, 600, nil, 30*time.Second)
+
+	if offer.FareEstimate != 0 || offer.EstimatedEarnings != 0 || offer.Surge || offer.SurgeMultiplier != 1 {
+		t.Errorf("unpriced offer = %+v, want no fare and no surge", offer)
+	}
+}
+
+func TestNewRideOfferCarriesAvoidance(t *testing.T) {
+	ride := &Ride{Type: RideTypeStandard, Route: &RouteInfo{Avoid: &RouteAvoidance{Tolls: true}}}
+
+	offer := NewRideOffer(ride, 500, 60, 3000, 600, nil, 30*time.Second)
+
+	if offer.Avoid == nil || !offer.Avoid.Tolls || offer.Avoid.Highways {
+		t.Errorf("avoid = %+v, want tolls only", offer.Avoid)
+	}
+	if NewRideOffer(&Ride{Type: RideTypeStandard}, 500, 60, 3000, 600, nil, 30*time.Second).Avoid != nil {
+		t.Error("offer without a route should not carry avoidance")
+	}
+}
@@ -0,0 +1,106 @@
+package domain
+
+import "time"
+
+// RouteLeg is one leg of a ride's route, between consecutive points of the
+// pickup, its stops and the dropoff
+type RouteLeg struct {
+	DistanceMeters  int64 `json:"distance_meters"`
+	DurationSeconds int64 `json:"duration_seconds"`
+}
+
+// LegFare is what one leg of a multi-stop ride adds to its fare, before
+// surge, discounts and price controls
+type LegFare struct {
+	DistanceMeters  int64 `json:"distance_meters"`
+	DurationSeconds int64 `json:"duration_seconds"`
+	DistanceFare    int64 `json:"distance_fare"`
+	TimeFare        int64 `json:"time_fare"`
+}
+
+// RouteLegTotals adds up the legs' distance and duration
+func RouteLegTotals(legs []RouteLeg) (distanceM float64, durationS int64) {
+	for _, leg := range legs {
+		distanceM += float64(leg.DistanceMeters)
+		durationS += leg.DurationSeconds
+	}
+	return distanceM, durationS
+}
+
+// StopStatus is where the driver is with a stop on the way
+type StopStatus string
+
+const (
+	StopStatusPending  StopStatus = "PENDING"
+	StopStatusArrived  StopStatus = "ARRIVED"
+	StopStatusDeparted StopStatus = "DEPARTED"
+)
+
+// StopState tracks one of a ride's stops, by its index in Stops
+type StopState struct {
+	Index      int        `json:"index"`
+	Status     StopStatus `json:"status"`
+	ArrivedAt  *time.Time `json:"arrived_at,omitempty"`
+	DepartedAt *time.Time `json:"departed_at,omitempty"`
+}
+
+// newStopStates starts every stop pending
+func newStopStates(stops int) []StopState {
+	if stops == 0 {
+		return nil
+	}
+	states := make([]StopState, stops)
+	for i := range states {
+		states[i] = StopState{Index: i, Status: StopStatusPending}
+	}
+	return states
+}
+
+// NextStop is the index of the first stop the driver has not left, or -1
+// when every stop is done
+func (r *Ride) NextStop() int {
+	for _, state := range r.StopStates {
+		if state.Status != StopStatusDeparted {
+			return state.Index
+		}
+	}
+	return -1
+}
+
+// ArriveAtStop records the driver reaching a stop. Stops are made in order
+// once the trip has started.
+func (r *Ride) ArriveAtStop(index int) error {
+	if index < 0 || index >= len(r.StopStates) {
+		return ErrStopNotFound
+	}
+	if r.Status != RideStatusInProgress || r.NextStop() != index || r.StopStates[index].Status != StopStatusPending {
+		return ErrInvalidStopTransition
+	}
+
+	now := time.Now().UTC()
+	r.StopStates[index].Status = StopStatusArrived
+	r.StopStates[index].ArrivedAt = &now
+	r.UpdatedAt = now
+	r.RecordEvent(NewRideEvent(r.ID, RideEventStopArrived).WithData("stop", index))
+	return nil
+}
+
+// DepartStop records the driver leaving a stop they arrived at
+func (r *Ride) DepartStop(index int) error {
+	if index < 0 || index >= len(r.StopStates) {
+		return ErrStopNotFound
+	}
+	state := &r.StopStates[index]
+	if r.Status != RideStatusInProgress || state.Status != StopStatusArrived {
+		return ErrInvalidStopTransition
+	}
+
+	now := time.Now().UTC()
+	state.Status = StopStatusDeparted
+	state.DepartedAt = &now
+	r.UpdatedAt = now
+	r.RecordEvent(NewRideEvent(r.ID, RideEventStopDeparted).
+		WithData("stop", index).
+		WithData("wait_seconds", int64(now.Sub(*state.ArrivedAt).Seconds())))
+	return nil
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRideStopTransitions(t *testing.T) {
+	ride := NewRide(&RideRequest{
+		RiderID: uuid.New(),
+		Stops:   []Location{{Latitude: 6.5, Longitude: 3.3}, {Latitude: 6.6, Longitude: 3.4}},
+		Type:    RideTypeStandard,
+	})
+	if len(ride.StopStates) != 2 || ride.NextStop() != 0 {
+		t.Fatalf("StopStates = %+v, want two pending stops", ride.StopStates)
+	}
+
+	// Stops wait for the trip to start
+	if err := ride.ArriveAtStop(0); !errors.Is(err, ErrInvalidStopTransition) {
+		t.Fatalf("ArriveAtStop() before the trip error = %v, want ErrInvalidStopTransition", err)
+	}
+	ride.Status = RideStatusInProgress
+
+	tests := []struct {
+		name    string
+		change  func() error
+		wantErr error
+	}{
+		{"out of range", func() error { return ride.ArriveAtStop(2) }, ErrStopNotFound},
+		{"out of order", func() error { return ride.ArriveAtStop(1) }, ErrInvalidStopTransition},
+		{"depart before arriving", func() error { return ride.DepartStop(0) }, ErrInvalidStopTransition},
+		{"arrive at the first stop", func() error { return ride.ArriveAtStop(0) }, nil},
+		{"arrive twice", func() error { return ride.ArriveAtStop(0) }, ErrInvalidStopTransition},
+		{"depart the first stop", func() error { return ride.DepartStop(0) }, nil},
+		{"arrive at the second stop", func() error { return ride.ArriveAtStop(1) }, nil},
+		{"depart the second stop", func() error { return ride.DepartStop(1) }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.change(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if ride.NextStop() != -1 {
+		t.Errorf("NextStop() = %d, want -1 once every stop is done", ride.NextStop())
+	}
+	if ride.StopStates[1].ArrivedAt == nil || ride.StopStates[1].DepartedAt == nil {
+		t.Errorf("StopStates[1] = %+v, want arrival and departure times", ride.StopStates[1])
+	}
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RideSweepAction is what the sweeper does with a ride stuck in a state
+type RideSweepAction string
+
+const (
+	RideSweepCancel     RideSweepAction = "CANCEL"
+	RideSweepRedispatch RideSweepAction = "REDISPATCH"
+)
+
+// MaxRideRedispatches is how many times a ride goes back to searching after
+// losing its driver before the sweeper cancels it instead
+const MaxRideRedispatches = 2
+
+// RideSweepTimeouts is how long a ride may sit in each transitional state
+// without being updated before the sweeper acts on it
+type RideSweepTimeouts map[RideStatus]time.Duration
+
+// DefaultRideSweepTimeouts covers every state a ride passes through on its
+// way to pickup. A ride's updated_at moves with each driver location
+// update, so a ride going stale in ACCEPTED or ARRIVING has lost its driver
+// as well as its worker. In-progress trips are never swept: only the
+// driver can end one with a fare.
+var DefaultRideSweepTimeouts = RideSweepTimeouts{
+	RideStatusPending:   5 * time.Minute,
+	RideStatusSearching: 10 * time.Minute,
+	RideStatusMatched:   2 * time.Minute,
+	RideStatusAccepted:  30 * time.Minute,
+	RideStatusArriving:  45 * time.Minute,
+	RideStatusArrived:   30 * time.Minute,
+}
+
+// ParseRideSweepTimeouts overrides the default timeouts from
+// RIDE_SWEEP_TIMEOUTS, a comma separated list of STATUS=DURATION entries
+// such as "SEARCHING=15m,ACCEPTED=20m"
+func ParseRideSweepTimeouts(spec string) (RideSweepTimeouts, error) {
+	timeouts := make(RideSweepTimeouts, len(DefaultRideSweepTimeouts))
+	for status, timeout := range DefaultRideSweepTimeouts {
+		timeouts[status] = timeout
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(entry, "=")
+		status := RideStatus(strings.ToUpper(strings.TrimSpace(key)))
+		_, sweepable := DefaultRideSweepTimeouts[status]
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if !ok || !sweepable || err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid ride sweep timeout %q, want STATUS=DURATION", entry)
+		}
+		timeouts[status] = timeout
+	}
+	return timeouts, nil
+}
+
+// RideSweepReport counts the stuck rides one sweep found and acted on, by
+// the state they were stuck in
+type RideSweepReport struct {
+	Cancelled    map[RideStatus]int `json:"cancelled"`
+	Redispatched map[RideStatus]int `json:"redispatched"`
+	Failed       map[RideStatus]int `json:"failed"`
+}
+
+// NewRideSweepReport creates an empty report
+func NewRideSweepReport() *RideSweepReport {
+	return &RideSweepReport{
+		Cancelled:    make(map[RideStatus]int),
+		Redispatched: make(map[RideStatus]int),
+		Failed:       make(map[RideStatus]int),
+	}
+}
+
+// Total is the number of stuck rides found
+func (r *RideSweepReport) Total() int {
+	total := 0
+	for _, counts := range []map[RideStatus]int{r.Cancelled, r.Redispatched, r.Failed} {
+		for _, n := range counts {
+			total += n
+		}
+	}
+	return total
+}
+
+// SweepAction decides what to do with a ride stuck in its state. A ride that
+// lost its driver on the way to pickup searches again, until it has done so
+// MaxRideRedispatches times; any other stuck ride is cancelled.
+func (r *Ride) SweepAction() RideSweepAction {
+	switch r.Status {
+	case RideStatusMatched, RideStatusAccepted, RideStatusArriving:
+		if r.Redispatches() < MaxRideRedispatches {
+			return RideSweepRedispatch
+		}
+	}
+	return RideSweepCancel
+}
+
+// Redispatches is how many times the ride has gone back to searching
+func (r *Ride) Redispatches() int {
+	// Metadata read back from the database holds numbers as float64
+	switch n := r.Metadata["redispatch_count"].(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+// Redispatch takes the ride off its driver and puts it back to searching
+func (r *Ride) Redispatch(reason string) error {
+	switch r.Status {
+	case RideStatusMatched, RideStatusAccepted, RideStatusArriving:
+	default:
+		return ErrInvalidStatusTransition
+	}
+
+	now := time.Now().UTC()
+	event := r.recordTransition(RideEventRedispatched, r.Status, RideStatusSearching).
+		WithData("reason", reason)
+	if r.DriverID != nil {
+		event.WithData("driver_id", *r.DriverID)
+	}
+
+	if r.Metadata == nil {
+		r.Metadata = make(map[string]any)
+	}
+	r.Metadata["redispatch_count"] = r.Redispatches() + 1
+	r.Metadata["matching_started_at"] = now.Format(time.RFC3339Nano)
+	r.DriverID = nil
+	r.VehicleID = nil
+	r.AcceptedAt = nil
+	r.Status = RideStatusSearching
+	r.UpdatedAt = now
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseRideSweepTimeouts(t *testing.T) {
+	timeouts, err := ParseRideSweepTimeouts("searching=15m, ACCEPTED=20m")
+	if err != nil {
+		t.Fatalf("ParseRideSweepTimeouts() error = %v", err)
+	}
+	if got := timeouts[RideStatusSearching]; got != 15*time.Minute {
+		t.Errorf("SEARCHING timeout = %v, want 15m", got)
+	}
+	if got := timeouts[RideStatusAccepted]; got != 20*time.Minute {
+		t.Errorf("ACCEPTED timeout = %v, want 20m", got)
+	}
+	if got := timeouts[RideStatusArrived]; got != DefaultRideSweepTimeouts[RideStatusArrived] {
+		t.Errorf("ARRIVED timeout = %v, want default", got)
+	}
+	if DefaultRideSweepTimeouts[RideStatusSearching] != 10*time.Minute {
+		t.Error("override changed the defaults")
+	}
+
+	for _, spec := range []string{"SEARCHING", "IN_PROGRESS=1h", "COMPLETED=1h", "SEARCHING=ten", "SEARCHING=0s"} {
+		if _, err := ParseRideSweepTimeouts(spec); err == nil {
+			t.Errorf("ParseRideSweepTimeouts(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestRideSweepAction(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       RideStatus
+		redispatches any
+		want         RideSweepAction
+	}{
+		{"never found a driver", RideStatusSearching, nil, RideSweepCancel},
+		{"pending", RideStatusPending, nil, RideSweepCancel},
+		{"driver vanished after accepting", RideStatusAccepted, nil, RideSweepRedispatch},
+		{"driver vanished on the way", RideStatusArriving, 1, RideSweepRedispatch},
+		{"redispatched too often", RideStatusAccepted, float64(MaxRideRedispatches), RideSweepCancel},
+		{"waiting at pickup", RideStatusArrived, nil, RideSweepCancel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ride := &Ride{Status: tt.status, Metadata: map[string]any{}}
+			if tt.redispatches != nil {
+				ride.Metadata["redispatch_count"] = tt.redispatches
+			}
+			if got := ride.SweepAction(); got != tt.want {
+				t.Errorf("SweepAction() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRideRedispatch(t *testing.T) {
+	driverID, vehicleID := uuid.New(), uuid.New()
+	acceptedAt := time.Now().UTC()
+	ride := &Ride{
+		ID:         uuid.New(),
+		Status:     RideStatusAccepted,
+		DriverID:   &driverID,
+		VehicleID:  &vehicleID,
+		AcceptedAt: &acceptedAt,
+	}
+
+	if err := ride.Redispatch("stuck"); err != nil {
+		t.Fatalf("Redispatch() error = %v", err)
+	}
+	if ride.Status != RideStatusSearching || ride.DriverID != nil || ride.VehicleID != nil || ride.AcceptedAt != nil {
+		t.Errorf("ride after Redispatch() = %s driver %v, want SEARCHING with no driver", ride.Status, ride.DriverID)
+	}
+	if got := ride.Redispatches(); got != 1 {
+		t.Errorf("Redispatches() = %d, want 1", got)
+	}
+	events := ride.PendingEvents()
+	if len(events) != 1 || events[0].Type != RideEventRedispatched || events[0].Data["driver_id"] != driverID {
+		t.Errorf("events = %+v, want one RIDE_REDISPATCHED naming the driver", events)
+	}
+
+	if err := ride.Redispatch("stuck"); err != ErrInvalidStatusTransition {
+		t.Errorf("Redispatch() while searching error = %v, want ErrInvalidStatusTransition", err)
+	}
+}
@@ -0,0 +1,241 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RiderReportType is a driver's report about a rider's behaviour on a ride
+type RiderReportType string
+
+const (
+	RiderReportNoShow      RiderReportType = "NO_SHOW"
+	RiderReportRudeConduct RiderReportType = "RUDE_CONDUCT"
+	RiderReportCashRefused RiderReportType = "CASH_REFUSED"
+)
+
+// IsValid reports whether the report type is known
+func (t RiderReportType) IsValid() bool {
+	switch t {
+	case RiderReportNoShow, RiderReportRudeConduct, RiderReportCashRefused:
+		return true
+	}
+	return false
+}
+
+// RiderReportWindow is how long after a ride ends its driver may report the
+// rider
+const RiderReportWindow = 48 * time.Hour
+
+// RiderReport is a driver's report of a rider's behaviour on a ride.
+// Support voids reports found to be unfounded, and voided reports no longer
+// count against the rider.
+type RiderReport struct {
+	ID        uuid.UUID       `json:"id"`
+	RideID    uuid.UUID       `json:"ride_id"`
+	RiderID   uuid.UUID       `json:"rider_id"`
+	DriverID  uuid.UUID       `json:"driver_id"`
+	Type      RiderReportType `json:"type"`
+	Note      string          `json:"note,omitempty"`
+	VoidedBy  *uuid.UUID      `json:"voided_by,omitempty"`
+	VoidedAt  *time.Time      `json:"voided_at,omitempty"`
+	VoidNote  string          `json:"void_note,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// CheckRiderReport checks a driver may file a report of this type on the
+// ride: no-shows on rides cancelled after the driver arrived, cash refusals
+// on completed cash rides, and rude conduct once the driver has met the
+// rider. Reports close RiderReportWindow after the ride ends.
+func CheckRiderReport(ride *Ride, reportType RiderReportType, now time.Time) error {
+	if !reportType.IsValid() {
+		return ErrInvalidRiderReport
+	}
+
+	var endedAt *time.Time
+	switch ride.Status {
+	case RideStatusCompleted:
+		endedAt = ride.CompletedAt
+	case RideStatusCancelled:
+		endedAt = ride.CancelledAt
+	}
+	if endedAt != nil && now.Sub(*endedAt) > RiderReportWindow {
+		return ErrRiderReportWindowClosed
+	}
+
+	switch reportType {
+	case RiderReportNoShow:
+		if ride.Status != RideStatusCancelled || ride.ArrivedAt == nil || ride.StartedAt != nil {
+			return ErrInvalidRiderReport
+		}
+	case RiderReportCashRefused:
+		if ride.Status != RideStatusCompleted || ride.PaymentMethod != PaymentMethodCash {
+			return ErrInvalidRiderReport
+		}
+	case RiderReportRudeConduct:
+		if ride.ArrivedAt == nil {
+			return ErrInvalidRiderReport
+		}
+	}
+	return nil
+}
+
+// RiderReliabilityWindow is how far back a rider's behaviour counts
+const RiderReliabilityWindow = 90 * 24 * time.Hour
+
+// Points each upheld report takes off a rider's score of 100. Late
+// cancellations cost a point per percent above the allowance, up to the
+// cap, once the rider has enough accepted rides to judge a rate.
+const (
+	RiderNoShowPenalty           = 15
+	RiderRudeConductPenalty      = 20
+	RiderCashRefusalPenalty      = 25
+	RiderCancellationAllowance   = 0.10
+	RiderCancellationPenaltyCap  = 30
+	RiderCancellationMinAccepted = 5
+)
+
+// RiderReliabilityLevel is the band a rider's score falls in, and decides
+// what it costs them
+type RiderReliabilityLevel string
+
+const (
+	RiderReliabilityGood RiderReliabilityLevel = "GOOD"
+	RiderReliabilityFair RiderReliabilityLevel = "FAIR" // lower priority in high demand
+	RiderReliabilityPoor RiderReliabilityLevel = "POOR" // and must prepay
+)
+
+// Scores below which a rider falls to each level
+const (
+	RiderReliabilityFairBelow = 70
+	RiderReliabilityPoorBelow = 40
+)
+
+// RiderHighDemandSurge is the surge at which demand counts as high enough
+// to put reliable riders first
+const RiderHighDemandSurge = 1.5
+
+// Ride metadata marking a ride requested at lower priority
+const (
+	RiderPriorityMetadataKey = "rider_priority"
+	RiderPriorityLow         = "LOW"
+)
+
+// RiderBehaviour is a rider's record over the reliability window
+type RiderBehaviour struct {
+	AcceptedRides     int `json:"accepted_rides"`     // rides a driver accepted
+	LateCancellations int `json:"late_cancellations"` // of those, cancelled by the rider
+	NoShows           int `json:"no_shows"`
+	RudeConduct       int `json:"rude_conduct"`
+	CashRefusals      int `json:"cash_refusals"`
+}
+
+// LateCancellationRate is the share of accepted rides the rider cancelled
+func (b RiderBehaviour) LateCancellationRate() float64 {
+	if b.AcceptedRides == 0 {
+		return 0
+	}
+	return float64(b.LateCancellations) / float64(b.AcceptedRides)
+}
+
+// RiderReliabilityFactor is one behaviour's part in a rider's score, so
+// riders can see why it is what it is
+type RiderReliabilityFactor struct {
+	Factor      string  `json:"factor"` // no_shows, rude_conduct, cash_refusals or late_cancellations
+	Count       int     `json:"count"`
+	Rate        float64 `json:"rate,omitempty"`
+	Points      int     `json:"points"` // taken off the score
+	Explanation string  `json:"explanation"`
+}
+
+// RiderReliability is a rider's reliability score, the factors behind it
+// and what it costs them
+type RiderReliability struct {
+	RiderID            uuid.UUID                `json:"rider_id"`
+	Score              int                      `json:"score"`
+	Level              RiderReliabilityLevel    `json:"level"`
+	Factors            []RiderReliabilityFactor `json:"factors"`
+	Behaviour          RiderBehaviour           `json:"behaviour"`
+	LowerPriority      bool                     `json:"lower_priority_in_high_demand"`
+	PrepaymentRequired bool                     `json:"prepayment_required"`
+	WindowDays         int                      `json:"window_days"`
+	CalculatedAt       time.Time                `json:"calculated_at"`
+}
+
+// ScoreRiderReliability scores a rider's record out of 100
+func ScoreRiderReliability(riderID uuid.UUID, b RiderBehaviour, now time.Time) *RiderReliability {
+	factors := []RiderReliabilityFactor{
+		{
+			Factor:      "no_shows",
+			Count:       b.NoShows,
+			Points:      b.NoShows * RiderNoShowPenalty,
+			Explanation: fmt.Sprintf("Each time a driver arrived and you did not show costs %d points.", RiderNoShowPenalty),
+		},
+		{
+			Factor:      "rude_conduct",
+			Count:       b.RudeConduct,
+			Points:      b.RudeConduct * RiderRudeConductPenalty,
+			Explanation: fmt.Sprintf("Each upheld report of rude conduct towards a driver costs %d points.", RiderRudeConductPenalty),
+		},
+		{
+			Factor:      "cash_refusals",
+			Count:       b.CashRefusals,
+			Points:      b.CashRefusals * RiderCashRefusalPenalty,
+			Explanation: fmt.Sprintf("Each cash trip you did not pay for costs %d points.", RiderCashRefusalPenalty),
+		},
+		lateCancellationFactor(b),
+	}
+
+	score := 100
+	for _, f := range factors {
+		score -= f.Points
+	}
+	if score < 0 {
+		score = 0
+	}
+
+	level := RiderReliabilityGood
+	switch {
+	case score < RiderReliabilityPoorBelow:
+		level = RiderReliabilityPoor
+	case score < RiderReliabilityFairBelow:
+		level = RiderReliabilityFair
+	}
+
+	return &RiderReliability{
+		RiderID:            riderID,
+		Score:              score,
+		Level:              level,
+		Factors:            factors,
+		Behaviour:          b,
+		LowerPriority:      level != RiderReliabilityGood,
+		PrepaymentRequired: level == RiderReliabilityPoor,
+		WindowDays:         int(RiderReliabilityWindow / (24 * time.Hour)),
+		CalculatedAt:       now,
+	}
+}
+
+func lateCancellationFactor(b RiderBehaviour) RiderReliabilityFactor {
+	rate := b.LateCancellationRate()
+	points := 0
+	if b.AcceptedRides >= RiderCancellationMinAccepted && rate > RiderCancellationAllowance {
+		points = int(math.Min(math.Round((rate-RiderCancellationAllowance)*100), RiderCancellationPenaltyCap))
+	}
+	return RiderReliabilityFactor{
+		Factor: "late_cancellations",
+		Count:  b.LateCancellations,
+		Rate:   math.Round(rate*1000) / 1000,
+		Points: points,
+		Explanation: fmt.Sprintf("Cancelling after a driver accepts costs a point per percent above %.0f%% of your rides, up to %d points.",
+			RiderCancellationAllowance*100, RiderCancellationPenaltyCap),
+	}
+}
+
+// Deprioritized reports whether a ride requested at this surge waits
+// behind other riders' rides
+func (r *RiderReliability) Deprioritized(surge float64) bool {
+	return r.LowerPriority && surge >= RiderHighDemandSurge
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestScoreRiderReliability(t *testing.T) {
+	tests := []struct {
+		name      string
+		behaviour RiderBehaviour
+		wantScore int
+		wantLevel RiderReliabilityLevel
+	}{
+		{"clean record", RiderBehaviour{AcceptedRides: 40}, 100, RiderReliabilityGood},
+		{"one no-show", RiderBehaviour{AcceptedRides: 40, NoShows: 1}, 85, RiderReliabilityGood},
+		{"cancellations within allowance", RiderBehaviour{AcceptedRides: 20, LateCancellations: 2}, 100, RiderReliabilityGood},
+		{"cancellations above allowance", RiderBehaviour{AcceptedRides: 20, LateCancellations: 6}, 80, RiderReliabilityGood},
+		{"too few rides to judge a rate", RiderBehaviour{AcceptedRides: 4, LateCancellations: 4}, 100, RiderReliabilityGood},
+		{"cancellation penalty capped", RiderBehaviour{AcceptedRides: 10, LateCancellations: 10}, 70, RiderReliabilityGood},
+		{"fair", RiderBehaviour{AcceptedRides: 30, NoShows: 1, RudeConduct: 1}, 65, RiderReliabilityFair},
+		{"poor", RiderBehaviour{AcceptedRides: 30, CashRefusals: 1, RudeConduct: 2}, 35, RiderReliabilityPoor},
+		{"floored at zero", RiderBehaviour{NoShows: 10}, 0, RiderReliabilityPoor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScoreRiderReliability(uuid.New(), tt.behaviour, time.Now())
+			if got.Score != tt.wantScore || got.Level != tt.wantLevel {
+				t.Errorf("ScoreRiderReliability() = %d %s, want %d %s", got.Score, got.Level, tt.wantScore, tt.wantLevel)
+			}
+			if got.LowerPriority != (tt.wantLevel != RiderReliabilityGood) {
+				t.Errorf("LowerPriority = %v for level %s", got.LowerPriority, got.Level)
+			}
+			if got.PrepaymentRequired != (tt.wantLevel == RiderReliabilityPoor) {
+				t.Errorf("PrepaymentRequired = %v for level %s", got.PrepaymentRequired, got.Level)
+			}
+			if len(got.Factors) != 4 {
+				t.Errorf("got %d factors, want 4", len(got.Factors))
+			}
+		})
+	}
+}
+
+func TestRiderReliabilityDeprioritized(t *testing.T) {
+	fair := ScoreRiderReliability(uuid.New(), RiderBehaviour{NoShows: 3}, time.Now())
+	good := ScoreRiderReliability(uuid.New(), RiderBehaviour{}, time.Now())
+
+	if fair.Deprioritized(1.2) {
+		t.Error("fair rider deprioritized below high-demand surge")
+	}
+	if !fair.Deprioritized(RiderHighDemandSurge) {
+		t.Error("fair rider not deprioritized in high demand")
+	}
+	if good.Deprioritized(3) {
+		t.Error("good rider deprioritized")
+	}
+}
+
+func TestCheckRiderReport(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	arrived := now.Add(-time.Hour)
+	ended := now.Add(-30 * time.Minute)
+	longAgo := now.Add(-RiderReportWindow - time.Hour)
+
+	noShow := &Ride{Status: RideStatusCancelled, ArrivedAt: &arrived, CancelledAt: &ended}
+	cashTrip := &Ride{Status: RideStatusCompleted, PaymentMethod: PaymentMethodCash, ArrivedAt: &arrived, StartedAt: &arrived, CompletedAt: &ended}
+	cardTrip := &Ride{Status: RideStatusCompleted, PaymentMethod: PaymentMethodCard, ArrivedAt: &arrived, StartedAt: &arrived, CompletedAt: &ended}
+	cancelledEarly := &Ride{Status: RideStatusCancelled, CancelledAt: &ended}
+	oldTrip := &Ride{Status: RideStatusCompleted, PaymentMethod: PaymentMethodCash, ArrivedAt: &arrived, CompletedAt: &longAgo}
+
+	tests := []struct {
+		name       string
+		ride       *Ride
+		reportType RiderReportType
+		want       error
+	}{
+		{"no-show after arrival", noShow, RiderReportNoShow, nil},
+		{"no-show on completed trip", cashTrip, RiderReportNoShow, ErrInvalidRiderReport},
+		{"no-show before arrival", cancelledEarly, RiderReportNoShow, ErrInvalidRiderReport},
+		{"cash refused on cash trip", cashTrip, RiderReportCashRefused, nil},
+		{"cash refused on card trip", cardTrip, RiderReportCashRefused, ErrInvalidRiderReport},
+		{"rude conduct on trip", cardTrip, RiderReportRudeConduct, nil},
+		{"rude conduct before meeting", cancelledEarly, RiderReportRudeConduct, ErrInvalidRiderReport},
+		{"unknown type", cashTrip, RiderReportType("LATE"), ErrInvalidRiderReport},
+		{"window closed", oldTrip, RiderReportCashRefused, ErrRiderReportWindowClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckRiderReport(tt.ride, tt.reportType, now); got != tt.want {
+				t.Errorf("CheckRiderReport() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Road report settings
+const (
+	RoadReportH3Resolution = 9                // cells about 350m across
+	RoadReportHalfLife     = 20 * time.Minute // a report counts half as much after this
+	RoadReportIncidentAt   = 2.0              // decayed reports in a cell that make an incident
+	RoadReportTTL          = DefaultDriverIncidentTTL
+	RoadReportPromptBefore = 10 * time.Minute // reporters are asked to confirm this long before expiry
+	RoadReportHistory      = 20               // reports kept per driver
+)
+
+// RoadReportCategory is the kind of road issue a driver reports
+type RoadReportCategory string
+
+const (
+	RoadReportBlocked    RoadReportCategory = "BLOCKED_ROAD"
+	RoadReportCheckpoint RoadReportCategory = "POLICE_CHECKPOINT"
+	RoadReportFlooding   RoadReportCategory = "FLOODING"
+)
+
+// roadReportIncidents is the incident a corroborated report becomes
+var roadReportIncidents = map[RoadReportCategory]IncidentType{
+	RoadReportBlocked:    IncidentRoadClosure,
+	RoadReportCheckpoint: IncidentCheckpoint,
+	RoadReportFlooding:   IncidentFlood,
+}
+
+// IsValid reports whether the category is one drivers can report
+func (c RoadReportCategory) IsValid() bool {
+	_, ok := roadReportIncidents[c]
+	return ok
+}
+
+// IncidentType returns the incident the category becomes once enough
+// drivers report it
+func (c RoadReportCategory) IncidentType() IncidentType {
+	return roadReportIncidents[c]
+}
+
+// RoadReportStatus is where a report is in its confirmation loop
+type RoadReportStatus string
+
+const (
+	RoadReportPending   RoadReportStatus = "PENDING"   // waiting for other drivers to report it too
+	RoadReportConfirmed RoadReportStatus = "CONFIRMED" // corroborated and affecting ETAs and routes
+	RoadReportCleared   RoadReportStatus = "CLEARED"   // the reporter said it is gone
+	RoadReportExpired   RoadReportStatus = "EXPIRED"
+)
+
+// RoadReport is one driver's report of a road issue. Reports in the same
+// H3 cell add up, decaying over time, and become an incident once enough
+// drivers agree.
+type RoadReport struct {
+	ID         uuid.UUID          `json:"id"`
+	DriverID   uuid.UUID          `json:"driver_id"`
+	Category   RoadReportCategory `json:"category"`
+	Latitude   float64            `json:"latitude"`
+	Longitude  float64            `json:"longitude"`
+	H3Cell     string             `json:"h3_cell"`
+	Status     RoadReportStatus   `json:"status"`
+	IncidentID *uuid.UUID         `json:"incident_id,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+	ExpiresAt  time.Time          `json:"expires_at"`
+}
+
+// RoadCellScore is the decayed weight of reports of one category in a cell
+type RoadCellScore struct {
+	Score     float64   `json:"score"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// At returns the score decayed to now
+func (s RoadCellScore) At(now time.Time) float64 {
+	if s.UpdatedAt.IsZero() || !now.After(s.UpdatedAt) {
+		return s.Score
+	}
+	halfLives := now.Sub(s.UpdatedAt).Seconds() / RoadReportHalfLife.Seconds()
+	return s.Score * math.Pow(0.5, halfLives)
+}
+
+// Add decays the score to now and adds weight, which is negative when a
+// reporter says the issue has cleared. The score never drops below zero.
+func (s *RoadCellScore) Add(weight float64, now time.Time) {
+	s.Score = math.Max(0, s.At(now)+weight)
+	s.UpdatedAt = now
+}
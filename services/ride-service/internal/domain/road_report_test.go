@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRoadCellScoreDecays(t *testing.T) {
+	now := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	var score RoadCellScore
+
+	score.Add(1, now)
+	score.Add(1, now.Add(RoadReportHalfLife))
+
+	if got := score.At(now.Add(RoadReportHalfLife)); math.Abs(got-1.5) > 1e-9 {
+		t.Errorf("score = %v, want 1.5 after one half-life", got)
+	}
+	if got := score.At(now.Add(2 * RoadReportHalfLife)); math.Abs(got-0.75) > 1e-9 {
+		t.Errorf("score = %v, want 0.75 a half-life later", got)
+	}
+}
+
+func TestRoadCellScoreNeverNegative(t *testing.T) {
+	now := time.Now()
+	score := RoadCellScore{Score: 1, UpdatedAt: now}
+
+	score.Add(-3, now)
+
+	if score.Score != 0 {
+		t.Errorf("score = %v, want 0", score.Score)
+	}
+}
+
+func TestRoadReportCategoryIncidentType(t *testing.T) {
+	if RoadReportCheckpoint.IncidentType() != IncidentCheckpoint || !RoadReportFlooding.IsValid() {
+		t.Error("categories should map to their incidents")
+	}
+	if RoadReportCategory("POTHOLE").IsValid() {
+		t.Error("POTHOLE should not be a valid category")
+	}
+}
@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Route option settings
+const (
+	MaxRouteOptions          = 3
+	RouteOptionsTTL          = 10 * time.Minute // how long a rider may take to confirm
+	RouteDeviationThresholdM = 250.0            // off the chosen route by more than this is a deviation
+	RouteDeviationCooldown   = 2 * time.Minute  // one deviation event per ride per cooldown
+)
+
+// RoutePreference is the kind of route a rider picked at confirmation
+type RoutePreference string
+
+const (
+	RoutePreferenceFastest    RoutePreference = "FASTEST"
+	RoutePreferenceShortest   RoutePreference = "SHORTEST"
+	RoutePreferenceAvoidTolls RoutePreference = "AVOID_TOLLS"
+)
+
+// IsValid reports whether the preference is one riders can pick
+func (p RoutePreference) IsValid() bool {
+	switch p {
+	case RoutePreferenceFastest, RoutePreferenceShortest, RoutePreferenceAvoidTolls:
+		return true
+	}
+	return false
+}
+
+// RouteAvoidance is the road classes a rider asked their route to avoid.
+// Routing, the fare and the driver's navigation all honor it.
+type RouteAvoidance struct {
+	Tolls    bool `json:"tolls,omitempty"`    // toll roads and express lanes
+	Highways bool `json:"highways,omitempty"` // highways and motorways
+}
+
+// Any reports whether anything is avoided
+func (a RouteAvoidance) Any() bool {
+	return a.Tolls || a.Highways
+}
+
+// RouteCandidate is one route a directions provider returned
+type RouteCandidate struct {
+	Summary         string
+	DistanceMeters  int64
+	DurationSeconds int64
+	Polyline        string
+	HasTolls        bool
+}
+
+// RouteOption is a route offered to a rider, priced for their ride type.
+// FareDelta is the fare against the fastest route.
+type RouteOption struct {
+	Preference      RoutePreference `json:"preference"`
+	Summary         string          `json:"summary,omitempty"`
+	DistanceMeters  int64           `json:"distance_meters"`
+	DurationSeconds int64           `json:"duration_seconds"`
+	Polyline        string          `json:"polyline"`
+	HasTolls        bool            `json:"has_tolls"`
+	Fare            int64           `json:"fare"`
+	FareDelta       int64           `json:"fare_delta"`
+	Currency        Currency        `json:"currency"`
+}
+
+// RouteOptionsQuote holds the routes offered for a trip until the rider
+// confirms one
+type RouteOptionsQuote struct {
+	ID        uuid.UUID      `json:"quote_id"`
+	RiderID   uuid.UUID      `json:"rider_id"`
+	RideType  RideType       `json:"ride_type"`
+	Pickup    Location       `json:"pickup_location"`
+	Dropoff   Location       `json:"dropoff_location"`
+	Avoid     RouteAvoidance `json:"avoid"`
+	Options   []RouteOption  `json:"options"`
+	ExpiresAt time.Time      `json:"expires_at"`
+}
+
+// Option returns the quoted route for a preference
+func (q *RouteOptionsQuote) Option(preference RoutePreference) (*RouteOption, bool) {
+	for i := range q.Options {
+		if q.Options[i].Preference == preference {
+			return &q.Options[i], true
+		}
+	}
+	return nil, false
+}
+
+// SelectRouteOptions picks up to MaxRouteOptions distinct routes: the
+// fastest and shortest of the provider's alternatives, and a toll-free
+// route when the fastest one has tolls. Options that would repeat a route
+// already offered are left out. Fares are filled in by the caller.
+func SelectRouteOptions(alternatives, tollFree []RouteCandidate) []RouteOption {
+	if len(alternatives) == 0 {
+		return nil
+	}
+
+	fastest, shortest := alternatives[0], alternatives[0]
+	for _, route := range alternatives[1:] {
+		if route.DurationSeconds < fastest.DurationSeconds {
+			fastest = route
+		}
+		if route.DistanceMeters < shortest.DistanceMeters {
+			shortest = route
+		}
+	}
+
+	options := []RouteOption{newRouteOption(RoutePreferenceFastest, fastest)}
+	if !sameRoute(shortest, fastest) {
+		options = append(options, newRouteOption(RoutePreferenceShortest, shortest))
+	}
+
+	if fastest.HasTolls {
+		for _, route := range tollFree {
+			if route.HasTolls {
+				continue
+			}
+			duplicate := false
+			for _, option := range options {
+				if option.Polyline == route.Polyline {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				options = append(options, newRouteOption(RoutePreferenceAvoidTolls, route))
+			}
+			break
+		}
+	}
+
+	if len(options) > MaxRouteOptions {
+		options = options[:MaxRouteOptions]
+	}
+	return options
+}
+
+func newRouteOption(preference RoutePreference, route RouteCandidate) RouteOption {
+	return RouteOption{
+		Preference:      preference,
+		Summary:         route.Summary,
+		DistanceMeters:  route.DistanceMeters,
+		DurationSeconds: route.DurationSeconds,
+		Polyline:        route.Polyline,
+		HasTolls:        route.HasTolls,
+	}
+}
+
+func sameRoute(a, b RouteCandidate) bool {
+	if a.Polyline != "" || b.Polyline != "" {
+		return a.Polyline == b.Polyline
+	}
+	return a.DistanceMeters == b.DistanceMeters && a.DurationSeconds == b.DurationSeconds
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "testing"
+
+func TestSelectRouteOptions(t *testing.T) {
+	alternatives := []RouteCandidate{
+		{Summary: "Expressway", DistanceMeters: 12000, DurationSeconds: 900, Polyline: "a", HasTolls: true},
+		{Summary: "Old Road", DistanceMeters: 9500, DurationSeconds: 1300, Polyline: "b"},
+		{Summary: "Ring Road", DistanceMeters: 11000, DurationSeconds: 1100, Polyline: "c"},
+	}
+	tollFree := []RouteCandidate{{Summary: "Ring Road", DistanceMeters: 11000, DurationSeconds: 1100, Polyline: "c"}}
+
+	options := SelectRouteOptions(alternatives, tollFree)
+
+	want := []RoutePreference{RoutePreferenceFastest, RoutePreferenceShortest, RoutePreferenceAvoidTolls}
+	if len(options) != len(want) {
+		t.Fatalf("got %d options, want %d", len(options), len(want))
+	}
+	for i, option := range options {
+		if option.Preference != want[i] {
+			t.Errorf("option %d = %s, want %s", i, option.Preference, want[i])
+		}
+	}
+	if options[0].Polyline != "a" || options[1].Polyline != "b" || options[2].Polyline != "c" {
+		t.Errorf("options picked the wrong routes: %+v", options)
+	}
+}
+
+func TestSelectRouteOptionsSkipsRepeats(t *testing.T) {
+	only := RouteCandidate{DistanceMeters: 5000, DurationSeconds: 600, Polyline: "a"}
+
+	options := SelectRouteOptions([]RouteCandidate{only}, []RouteCandidate{only})
+
+	if len(options) != 1 || options[0].Preference != RoutePreferenceFastest {
+		t.Errorf("options = %+v, want only the fastest route", options)
+	}
+}
+
+func TestRouteOptionsQuoteOption(t *testing.T) {
+	quote := &RouteOptionsQuote{Options: []RouteOption{
+		{Preference: RoutePreferenceFastest, DistanceMeters: 1},
+		{Preference: RoutePreferenceShortest, DistanceMeters: 2},
+	}}
+
+	if option, ok := quote.Option(RoutePreferenceShortest); !ok || option.DistanceMeters != 2 {
+		t.Errorf("Option(SHORTEST) = %+v, %v", option, ok)
+	}
+	if _, ok := quote.Option(RoutePreferenceAvoidTolls); ok {
+		t.Error("Option(AVOID_TOLLS) should not be found")
+	}
+}
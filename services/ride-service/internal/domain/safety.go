@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SafetyIncidentStatus is where ops are with an SOS raised during a ride
+type SafetyIncidentStatus string
+
+const (
+	SafetyIncidentOpen         SafetyIncidentStatus = "OPEN"
+	SafetyIncidentAcknowledged SafetyIncidentStatus = "ACKNOWLEDGED"
+	SafetyIncidentResolved     SafetyIncidentStatus = "RESOLVED"
+)
+
+// IsValid reports whether the status is known
+func (s SafetyIncidentStatus) IsValid() bool {
+	switch s {
+	case SafetyIncidentOpen, SafetyIncidentAcknowledged, SafetyIncidentResolved:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo reports whether ops may move an incident from s to next.
+// An incident is acknowledged before it is resolved, but an agent already
+// on the phone may resolve an open one directly.
+func (s SafetyIncidentStatus) CanTransitionTo(next SafetyIncidentStatus) bool {
+	switch s {
+	case SafetyIncidentOpen:
+		return next == SafetyIncidentAcknowledged || next == SafetyIncidentResolved
+	case SafetyIncidentAcknowledged:
+		return next == SafetyIncidentResolved
+	}
+	return false
+}
+
+// Who raised an SOS
+const (
+	SOSRaisedByRider  = "RIDER"
+	SOSRaisedByDriver = "DRIVER"
+)
+
+// SafetyIncident is an SOS raised by a rider or driver during a ride
+type SafetyIncident struct {
+	ID             uuid.UUID            `json:"id"`
+	RideID         uuid.UUID            `json:"ride_id"`
+	RaisedBy       uuid.UUID            `json:"raised_by"`
+	RaisedByRole   string               `json:"raised_by_role"` // RIDER or DRIVER
+	Status         SafetyIncidentStatus `json:"status"`
+	Location       *Location            `json:"location,omitempty"`
+	Note           string               `json:"note,omitempty"`
+	Snapshot       *SafetySnapshot      `json:"snapshot"`
+	AcknowledgedBy *uuid.UUID           `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time           `json:"acknowledged_at,omitempty"`
+	ResolvedBy     *uuid.UUID           `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time           `json:"resolved_at,omitempty"`
+	Resolution     string               `json:"resolution,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
+
+// SafetySnapshot freezes the ride and driver as they were when the SOS
+// was raised, so ops see what the rider saw even after the ride moves on
+type SafetySnapshot struct {
+	RideStatus      RideStatus `json:"ride_status"`
+	RiderID         uuid.UUID  `json:"rider_id"`
+	PickupLocation  Location   `json:"pickup_location"`
+	DropoffLocation Location   `json:"dropoff_location"`
+	Stops           []Location `json:"stops,omitempty"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	DriverID        *uuid.UUID `json:"driver_id,omitempty"`
+	DriverName      string     `json:"driver_name,omitempty"`
+	DriverPhone     string     `json:"driver_phone,omitempty"`
+	Vehicle         *Vehicle   `json:"vehicle,omitempty"`
+}
+
+// SafetyAlert is the high-priority event ops receive for an SOS. Repeat is
+// set when the SOS was pressed again on an incident already open.
+type SafetyAlert struct {
+	IncidentID uuid.UUID       `json:"incident_id"`
+	RideID     uuid.UUID       `json:"ride_id"`
+	RaisedBy   uuid.UUID       `json:"raised_by"`
+	Role       string          `json:"raised_by_role"`
+	Priority   string          `json:"priority"`
+	Location   *Location       `json:"location,omitempty"`
+	Note       string          `json:"note,omitempty"`
+	Snapshot   *SafetySnapshot `json:"snapshot"`
+	Repeat     bool            `json:"repeat,omitempty"`
+	RaisedAt   time.Time       `json:"raised_at"`
+}
+
+// SafetyAlertPriority marks SOS alerts for ops tooling
+const SafetyAlertPriority = "CRITICAL"
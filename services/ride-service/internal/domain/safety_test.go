@@ -0,0 +1,25 @@
+package domain
+
+import "testing"
+
+func TestSafetyIncidentStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to SafetyIncidentStatus
+		want     bool
+	}{
+		{SafetyIncidentOpen, SafetyIncidentAcknowledged, true},
+		{SafetyIncidentOpen, SafetyIncidentResolved, true},
+		{SafetyIncidentAcknowledged, SafetyIncidentResolved, true},
+		{SafetyIncidentAcknowledged, SafetyIncidentOpen, false},
+		{SafetyIncidentAcknowledged, SafetyIncidentAcknowledged, false},
+		{SafetyIncidentResolved, SafetyIncidentOpen, false},
+		{SafetyIncidentResolved, SafetyIncidentAcknowledged, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("CanTransitionTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
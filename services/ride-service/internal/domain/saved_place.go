@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SavedPlaceKind is what a rider saved a place as
+type SavedPlaceKind string
+
+const (
+	SavedPlaceHome   SavedPlaceKind = "HOME"
+	SavedPlaceWork   SavedPlaceKind = "WORK"
+	SavedPlaceCustom SavedPlaceKind = "CUSTOM" // named by the rider's label
+)
+
+// IsValid reports whether the kind is known
+func (k SavedPlaceKind) IsValid() bool {
+	switch k {
+	case SavedPlaceHome, SavedPlaceWork, SavedPlaceCustom:
+		return true
+	}
+	return false
+}
+
+// Saved place limits. A rider has at most one home and one work.
+const (
+	SavedPlaceLimit      = 20 // places per rider, home and work included
+	SavedPlaceLabelLimit = 50 // characters in a label
+)
+
+// SavedPlace is a place a rider saved to pick from when requesting rides.
+// Cell buckets the place at FrequentPlaceResolution so prediction can match
+// it against the rider's trips.
+type SavedPlace struct {
+	ID        uuid.UUID      `json:"id"`
+	RiderID   uuid.UUID      `json:"rider_id"`
+	Kind      SavedPlaceKind `json:"kind"`
+	Label     string         `json:"label,omitempty"`
+	Location  Location       `json:"location"`
+	Cell      string         `json:"-"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// Validate checks the place has a known kind, a location and, when custom,
+// a label. Home and work are named by their kind, so their label is
+// optional.
+func (p *SavedPlace) Validate() error {
+	p.Label = strings.TrimSpace(p.Label)
+	if !p.Kind.IsValid() || len([]rune(p.Label)) > SavedPlaceLabelLimit {
+		return ErrInvalidSavedPlace
+	}
+	if p.Kind == SavedPlaceCustom && p.Label == "" {
+		return ErrInvalidSavedPlace
+	}
+	if p.Location.Latitude == 0 && p.Location.Longitude == 0 ||
+		p.Location.Latitude < -90 || p.Location.Latitude > 90 ||
+		p.Location.Longitude < -180 || p.Location.Longitude > 180 {
+		return ErrInvalidSavedPlace
+	}
+	return nil
+}
+
+// FrequentPlace is the saved place as a place in the rider's history,
+// named by its label and carrying the visits the rider's trips made to its
+// cell
+func (p *SavedPlace) FrequentPlace(visited *FrequentPlace) *FrequentPlace {
+	place := &FrequentPlace{
+		UserID:    p.RiderID,
+		Cell:      p.Cell,
+		Latitude:  p.Location.Latitude,
+		Longitude: p.Location.Longitude,
+		Name:      p.Label,
+		Address:   p.Location.Address,
+		PlaceID:   p.Location.PlaceID,
+	}
+	if place.Name == "" {
+		place.Name = p.Location.Name
+	}
+	if visited != nil {
+		place.Pickups = visited.Pickups
+		place.Dropoffs = visited.Dropoffs
+		place.FirstVisitedAt = visited.FirstVisitedAt
+		place.LastVisitedAt = visited.LastVisitedAt
+	}
+	return place
+}
+
+// SeedHomeWork sets the history's home and work from the rider's saved
+// places, ahead of DetectHomeWork inferring any the rider has not saved
+func (h *UserLocationHistory) SeedHomeWork(saved []*SavedPlace) {
+	for _, p := range saved {
+		switch p.Kind {
+		case SavedPlaceHome:
+			h.Home = p.FrequentPlace(h.place(p.Cell))
+		case SavedPlaceWork:
+			h.Work = p.FrequentPlace(h.place(p.Cell))
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package domain
+
+import "testing"
+
+func TestSavedPlaceValidate(t *testing.T) {
+	lagos := Location{Latitude: 6.5244, Longitude: 3.3792}
+	tests := []struct {
+		name  string
+		place SavedPlace
+		want  error
+	}{
+		{"home without label", SavedPlace{Kind: SavedPlaceHome, Location: lagos}, nil},
+		{"custom with label", SavedPlace{Kind: SavedPlaceCustom, Label: " Gym ", Location: lagos}, nil},
+		{"custom without label", SavedPlace{Kind: SavedPlaceCustom, Label: "  ", Location: lagos}, ErrInvalidSavedPlace},
+		{"unknown kind", SavedPlace{Kind: "SCHOOL", Label: "School", Location: lagos}, ErrInvalidSavedPlace},
+		{"no location", SavedPlace{Kind: SavedPlaceWork}, ErrInvalidSavedPlace},
+		{"out of range", SavedPlace{Kind: SavedPlaceWork, Location: Location{Latitude: 95, Longitude: 3}}, ErrInvalidSavedPlace},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.place.Validate(); got != tt.want {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeedHomeWork(t *testing.T) {
+	home := &FrequentPlace{Cell: "home", Pickups: 5}
+	work := &FrequentPlace{Cell: "work"}
+	office := &FrequentPlace{Cell: "office", Dropoffs: 2}
+	h := &UserLocationHistory{
+		Places: []*FrequentPlace{home, work, office},
+		Patterns: []*TripPattern{
+			{OriginCell: "home", DestinationCell: "work", DayOfWeek: 2, HourOfDay: 8, Trips: 4},
+			{OriginCell: "work", DestinationCell: "home", DayOfWeek: 2, HourOfDay: 18, Trips: 3},
+		},
+	}
+
+	// The rider saved a new office as work; home is still detected
+	h.SeedHomeWork([]*SavedPlace{
+		{Kind: SavedPlaceWork, Label: "New office", Cell: "office", Location: Location{Address: "1 Marina"}},
+		{Kind: SavedPlaceCustom, Label: "Gym", Cell: "gym"},
+	})
+	h.DetectHomeWork()
+
+	if h.Home != home {
+		t.Errorf("Home = %+v, want detected home", h.Home)
+	}
+	if h.Work == nil || h.Work.Cell != "office" || h.Work.Name != "New office" || h.Work.Dropoffs != 2 {
+		t.Errorf("Work = %+v, want saved office with its visits", h.Work)
+	}
+}
+
+func TestSeedHomeWorkExcludesSavedHomeFromWork(t *testing.T) {
+	h := &UserLocationHistory{
+		Places: []*FrequentPlace{{Cell: "a"}, {Cell: "b"}},
+		Patterns: []*TripPattern{
+			{OriginCell: "b", DestinationCell: "a", DayOfWeek: 2, HourOfDay: 8, Trips: 4},
+		},
+	}
+
+	// Trips suggest a is work, but the rider saved it as home
+	h.SeedHomeWork([]*SavedPlace{{Kind: SavedPlaceHome, Cell: "a"}})
+	h.DetectHomeWork()
+
+	if h.Home == nil || h.Home.Cell != "a" {
+		t.Errorf("Home = %+v, want saved a", h.Home)
+	}
+	if h.Work != nil {
+		t.Errorf("Work = %+v, want none", h.Work)
+	}
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scheduled ride marketplace terms
+const (
+	ScheduledClaimHorizon         = 48 * time.Hour // how far ahead drivers can browse and claim
+	ScheduledClaimFreeRelease     = 2 * time.Hour  // releasing closer to pickup than this is penalised
+	ScheduledClaimMaxActive       = 3              // open claims a driver may hold at once
+	ScheduledClaimReliabilityDays = 30             // trailing window reliability is scored over
+	ScheduledClaimLatePenalty     = 10             // points lost per late release
+	ScheduledClaimNoShowPenalty   = 25             // points lost per claim lapsed by going offline
+	ScheduledClaimMinReliability  = 60             // score below which a driver cannot claim
+)
+
+// ScheduledClaimStatus is the state of a driver's claim on a scheduled ride
+type ScheduledClaimStatus string
+
+const (
+	ScheduledClaimActive    ScheduledClaimStatus = "CLAIMED"
+	ScheduledClaimReleased  ScheduledClaimStatus = "RELEASED"  // released by the driver or freed by a rider cancellation
+	ScheduledClaimFulfilled ScheduledClaimStatus = "FULFILLED" // the driver was assigned the ride at dispatch
+	ScheduledClaimLapsed    ScheduledClaimStatus = "LAPSED"    // the driver was unavailable at dispatch; the ride went to live matching
+)
+
+// ScheduledRideClaim is a driver's pre-claim on a scheduled ride
+type ScheduledRideClaim struct {
+	ID            uuid.UUID            `json:"id"`
+	RideID        uuid.UUID            `json:"ride_id"`
+	DriverID      uuid.UUID            `json:"driver_id"`
+	ScheduledFor  time.Time            `json:"scheduled_for"`
+	Status        ScheduledClaimStatus `json:"status"`
+	Penalty       int                  `json:"penalty"`
+	ReleaseReason string               `json:"release_reason,omitempty"`
+	ClaimedAt     time.Time            `json:"claimed_at"`
+	ClosedAt      *time.Time           `json:"closed_at,omitempty"`
+}
+
+// ReleasePenalty is the reliability penalty for releasing the claim at the
+// given time
+func (c *ScheduledRideClaim) ReleasePenalty(at time.Time) int {
+	if c.ScheduledFor.Sub(at) < ScheduledClaimFreeRelease {
+		return ScheduledClaimLatePenalty
+	}
+	return 0
+}
+
+// ScheduledRideListing is a scheduled ride open for drivers to claim
+type ScheduledRideListing struct {
+	RideID          uuid.UUID `json:"ride_id"`
+	Type            RideType  `json:"type"`
+	PickupLocation  Location  `json:"pickup_location"`
+	DropoffLocation Location  `json:"dropoff_location"`
+	ScheduledFor    time.Time `json:"scheduled_for"`
+	DistanceMeters  int64     `json:"distance_meters"`
+	FareLow         int64     `json:"fare_low"`
+	FareHigh        int64     `json:"fare_high"`
+	Currency        Currency  `json:"currency"`
+}
+
+// ClaimReliability scores how dependably a driver honours scheduled ride
+// claims over the trailing window
+type ClaimReliability struct {
+	DriverID     uuid.UUID `json:"driver_id"`
+	Claims       int       `json:"claims"`
+	Fulfilled    int       `json:"fulfilled"`
+	LateReleases int       `json:"late_releases"`
+	NoShows      int       `json:"no_shows"`
+	Penalty      int       `json:"penalty"`
+	Score        int       `json:"score"` // 0-100
+	ActiveClaims int       `json:"active_claims"`
+	CanClaim     bool      `json:"can_claim"`
+}
+
+// Evaluate computes the score and whether the driver may claim more rides
+func (r *ClaimReliability) Evaluate() {
+	r.Score = 100 - r.Penalty
+	if r.Score < 0 {
+		r.Score = 0
+	}
+	r.CanClaim = r.Score >= ScheduledClaimMinReliability && r.ActiveClaims < ScheduledClaimMaxActive
+}
+
+// DriverScheduledRides is a driver's view of the scheduled ride marketplace:
+// the rides open to claim, their own open claims and their reliability
+type DriverScheduledRides struct {
+	Available   []*ScheduledRideListing `json:"available"`
+	Claims      []*ScheduledRideClaim   `json:"claims"`
+	Reliability *ClaimReliability       `json:"reliability"`
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduledRideClaimReleasePenalty(t *testing.T) {
+	pickup := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	c := &ScheduledRideClaim{ScheduledFor: pickup}
+
+	if got := c.ReleasePenalty(pickup.Add(-3 * time.Hour)); got != 0 {
+		t.Errorf("ReleasePenalty(-3h) = %d, want 0", got)
+	}
+	if got := c.ReleasePenalty(pickup.Add(-time.Hour)); got != ScheduledClaimLatePenalty {
+		t.Errorf("ReleasePenalty(-1h) = %d, want %d", got, ScheduledClaimLatePenalty)
+	}
+}
+
+func TestClaimReliabilityEvaluate(t *testing.T) {
+	r := &ClaimReliability{Penalty: ScheduledClaimLatePenalty + ScheduledClaimNoShowPenalty}
+	r.Evaluate()
+	if r.Score != 65 || !r.CanClaim {
+		t.Errorf("Evaluate() score = %d, can claim = %v, want 65, true", r.Score, r.CanClaim)
+	}
+
+	r.ActiveClaims = ScheduledClaimMaxActive
+	r.Evaluate()
+	if r.CanClaim {
+		t.Error("Evaluate() allowed a claim over the open claim limit")
+	}
+
+	r = &ClaimReliability{Penalty: 5 * ScheduledClaimNoShowPenalty}
+	r.Evaluate()
+	if r.Score != 0 || r.CanClaim {
+		t.Errorf("Evaluate() score = %d, can claim = %v, want 0, false", r.Score, r.CanClaim)
+	}
+}
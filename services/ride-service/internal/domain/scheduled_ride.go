@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scheduled ride timings and guarantee terms
+const (
+	ScheduledRideMinLead        = 30 * time.Minute    // earliest a ride can be booked ahead
+	ScheduledRideMaxLead        = 30 * 24 * time.Hour // latest a ride can be booked ahead
+	ScheduledDispatchLead       = 15 * time.Minute    // when matching starts before pickup
+	ScheduledAssignmentDeadline = 10 * time.Minute    // ops are alarmed if no driver is assigned by then
+	ScheduledFulfillmentGrace   = 10 * time.Minute    // after pickup time, an unassigned ride has failed
+	ScheduledFareBandPct        = 10                  // the locked band is the booking quote +/- this %
+	ScheduledVoucherPct         = 50                  // compensation voucher, as a % of the booking quote
+	ScheduledVoucherValidity    = 30 * 24 * time.Hour
+)
+
+// ScheduledReminder identifies a reminder sent before a scheduled pickup
+type ScheduledReminder string
+
+const (
+	ScheduledReminder24h ScheduledReminder = "24h"
+	ScheduledReminder1h  ScheduledReminder = "1h"
+	ScheduledReminder10m ScheduledReminder = "10m"
+)
+
+// ScheduledReminders are sent in this order, furthest from pickup first
+var ScheduledReminders = []ScheduledReminder{ScheduledReminder24h, ScheduledReminder1h, ScheduledReminder10m}
+
+// Offset is how long before pickup the reminder is sent
+func (r ScheduledReminder) Offset() time.Duration {
+	switch r {
+	case ScheduledReminder24h:
+		return 24 * time.Hour
+	case ScheduledReminder1h:
+		return time.Hour
+	case ScheduledReminder10m:
+		return 10 * time.Minute
+	}
+	return 0
+}
+
+// ValidateScheduledFor checks a requested pickup time is within the booking
+// window
+func ValidateScheduledFor(now, scheduledFor time.Time) error {
+	lead := scheduledFor.Sub(now)
+	if lead < ScheduledRideMinLead || lead > ScheduledRideMaxLead {
+		return ErrInvalidScheduleTime
+	}
+	return nil
+}
+
+// ScheduledRideGuarantee is the fare band locked when a scheduled ride is
+// booked, and the progress of its reminders, alarm and compensation
+type ScheduledRideGuarantee struct {
+	RideID        uuid.UUID           `json:"ride_id"`
+	RiderID       uuid.UUID           `json:"rider_id"`
+	ScheduledFor  time.Time           `json:"scheduled_for"`
+	Currency      Currency            `json:"currency"`
+	QuotedFare    int64               `json:"quoted_fare"`
+	FareLow       int64               `json:"fare_low"`
+	FareHigh      int64               `json:"fare_high"`
+	RemindersSent []ScheduledReminder `json:"reminders_sent"`
+	AlarmRaisedAt *time.Time          `json:"alarm_raised_at,omitempty"`
+	FailedAt      *time.Time          `json:"failed_at,omitempty"`
+	VoucherCode   string              `json:"voucher_code,omitempty"`
+	VoucherAmount int64               `json:"voucher_amount,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+}
+
+// NewScheduledRideGuarantee locks a fare band around a scheduled ride's
+// booking quote
+func NewScheduledRideGuarantee(ride *Ride, now time.Time) *ScheduledRideGuarantee {
+	quote := ride.Price.Total
+	return &ScheduledRideGuarantee{
+		RideID:        ride.ID,
+		RiderID:       ride.RiderID,
+		ScheduledFor:  *ride.ScheduledFor,
+		Currency:      ride.Price.Currency,
+		QuotedFare:    quote,
+		FareLow:       quote * (100 - ScheduledFareBandPct) / 100,
+		FareHigh:      quote * (100 + ScheduledFareBandPct) / 100,
+		RemindersSent: []ScheduledReminder{},
+		CreatedAt:     now,
+	}
+}
+
+// ClampFare keeps a fare priced at dispatch within the locked band
+func (g *ScheduledRideGuarantee) ClampFare(fare int64) int64 {
+	if fare > g.FareHigh {
+		return g.FareHigh
+	}
+	if fare < g.FareLow {
+		return g.FareLow
+	}
+	return fare
+}
+
+// DueReminder returns the reminder to send now, if any. Only the most recent
+// reminder that has come due is sent, and reminders whose time had already
+// passed when the ride was booked are skipped.
+func (g *ScheduledRideGuarantee) DueReminder(now time.Time) (ScheduledReminder, bool) {
+	if !now.Before(g.ScheduledFor) {
+		return "", false
+	}
+
+	var due ScheduledReminder
+	for _, reminder := range ScheduledReminders {
+		sendAt := g.ScheduledFor.Add(-reminder.Offset())
+		if !sendAt.After(now) && sendAt.After(g.CreatedAt) {
+			due = reminder
+		}
+	}
+	if due == "" {
+		return "", false
+	}
+
+	for _, sent := range g.RemindersSent {
+		if sent == due {
+			return "", false
+		}
+	}
+	return due, true
+}
+
+// VoucherValue is the compensation owed if the platform fails to fulfil the
+// ride
+func (g *ScheduledRideGuarantee) VoucherValue() int64 {
+	return g.QuotedFare * ScheduledVoucherPct / 100
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateScheduledFor(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := ValidateScheduledFor(now, now.Add(2*time.Hour)); err != nil {
+		t.Errorf("ValidateScheduledFor(+2h) error = %v", err)
+	}
+	if err := ValidateScheduledFor(now, now.Add(10*time.Minute)); err != ErrInvalidScheduleTime {
+		t.Errorf("ValidateScheduledFor(+10m) error = %v, want ErrInvalidScheduleTime", err)
+	}
+	if err := ValidateScheduledFor(now, now.Add(-time.Hour)); err != ErrInvalidScheduleTime {
+		t.Errorf("ValidateScheduledFor(-1h) error = %v, want ErrInvalidScheduleTime", err)
+	}
+}
+
+func TestScheduledRideGuaranteeClampFare(t *testing.T) {
+	g := &ScheduledRideGuarantee{QuotedFare: 100000, FareLow: 90000, FareHigh: 110000}
+
+	tests := map[int64]int64{150000: 110000, 50000: 90000, 104000: 104000}
+	for fare, want := range tests {
+		if got := g.ClampFare(fare); got != want {
+			t.Errorf("ClampFare(%d) = %d, want %d", fare, got, want)
+		}
+	}
+	if got := g.VoucherValue(); got != 50000 {
+		t.Errorf("VoucherValue() = %d, want 50000", got)
+	}
+}
+
+func TestScheduledRideGuaranteeDueReminder(t *testing.T) {
+	pickup := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	g := &ScheduledRideGuarantee{
+		ScheduledFor:  pickup,
+		CreatedAt:     pickup.Add(-72 * time.Hour),
+		RemindersSent: []ScheduledReminder{},
+	}
+
+	if _, ok := g.DueReminder(pickup.Add(-30 * time.Hour)); ok {
+		t.Error("DueReminder(-30h) returned a reminder, want none")
+	}
+	if r, ok := g.DueReminder(pickup.Add(-23 * time.Hour)); !ok || r != ScheduledReminder24h {
+		t.Errorf("DueReminder(-23h) = %q, %v, want 24h", r, ok)
+	}
+
+	// A missed run only sends the latest reminder
+	if r, ok := g.DueReminder(pickup.Add(-5 * time.Minute)); !ok || r != ScheduledReminder10m {
+		t.Errorf("DueReminder(-5m) = %q, %v, want 10m", r, ok)
+	}
+
+	g.RemindersSent = []ScheduledReminder{ScheduledReminder1h}
+	if _, ok := g.DueReminder(pickup.Add(-30 * time.Minute)); ok {
+		t.Error("DueReminder(-30m) resent the 1h reminder")
+	}
+
+	// Reminders already past at booking are skipped
+	g.CreatedAt = pickup.Add(-2 * time.Hour)
+	g.RemindersSent = []ScheduledReminder{}
+	if r, ok := g.DueReminder(pickup.Add(-90 * time.Minute)); ok {
+		t.Errorf("DueReminder(-90m) = %q, want none for a ride booked 2h ahead", r)
+	}
+}
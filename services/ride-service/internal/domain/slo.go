@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// SLO burn-rate windows. A group alerts when both windows burn faster than
+// SLOFastBurn: at 14.4 times the allowed rate a 30-day error budget loses
+// 2% in an hour, and the short window stops the alert soon after recovery.
+const (
+	SLOShortWindow = 5 * time.Minute
+	SLOLongWindow  = time.Hour
+	SLOFastBurn    = 14.4
+)
+
+// SLOBurnRate is how fast an endpoint group spends its error budget. A
+// request is bad when it fails with a server error or runs past its
+// latency budget; a burn rate of 1 spends the budget exactly over the SLO
+// period.
+type SLOBurnRate struct {
+	Group         string  `json:"group"`
+	Objective     float64 `json:"objective"`
+	ShortRequests int64   `json:"short_requests"`
+	ShortBad      int64   `json:"short_bad"`
+	ShortBurn     float64 `json:"short_burn"`
+	LongRequests  int64   `json:"long_requests"`
+	LongBad       int64   `json:"long_bad"`
+	LongBurn      float64 `json:"long_burn"`
+	Alerting      bool    `json:"alerting"`
+}
+
+// BurnRate is the bad share of requests over the share the objective
+// allows, or 0 with no requests
+func BurnRate(requests, bad int64, objective float64) float64 {
+	if requests == 0 || objective >= 1 {
+		return 0
+	}
+	return float64(bad) / float64(requests) / (1 - objective)
+}
+
+// NewSLOBurnRate works out a group's burn rates from its request counts
+// over the short and long windows
+func NewSLOBurnRate(group string, objective float64, shortRequests, shortBad, longRequests, longBad int64) SLOBurnRate {
+	b := SLOBurnRate{
+		Group:         group,
+		Objective:     objective,
+		ShortRequests: shortRequests,
+		ShortBad:      shortBad,
+		ShortBurn:     BurnRate(shortRequests, shortBad, objective),
+		LongRequests:  longRequests,
+		LongBad:       longBad,
+		LongBurn:      BurnRate(longRequests, longBad, objective),
+	}
+	b.Alerting = b.ShortBurn > SLOFastBurn && b.LongBurn > SLOFastBurn
+	return b
+}
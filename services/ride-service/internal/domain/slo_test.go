@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewSLOBurnRate(t *testing.T) {
+	tests := []struct {
+		name                    string
+		shortRequests, shortBad int64
+		longRequests, longBad   int64
+		wantShort, wantLong     float64
+		wantAlerting            bool
+	}{
+		{"no traffic", 0, 0, 0, 0, 0, 0, false},
+		{"within budget", 100, 1, 1000, 5, 1, 0.5, false},
+		{"fast burn in both windows", 100, 20, 1000, 150, 20, 15, true},
+		{"recovered in the short window", 100, 0, 1000, 150, 0, 15, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewSLOBurnRate("rides", 0.99, tt.shortRequests, tt.shortBad, tt.longRequests, tt.longBad)
+			if math.Abs(got.ShortBurn-tt.wantShort) > 1e-9 || math.Abs(got.LongBurn-tt.wantLong) > 1e-9 {
+				t.Errorf("burn = %v, %v; want %v, %v", got.ShortBurn, got.LongBurn, tt.wantShort, tt.wantLong)
+			}
+			if got.Alerting != tt.wantAlerting {
+				t.Errorf("Alerting = %v, want %v", got.Alerting, tt.wantAlerting)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Support roles, from least to most privileged
+const (
+	RoleSupport     = "SUPPORT"
+	RoleSupportLead = "SUPPORT_LEAD"
+	RoleAdmin       = "ADMIN"
+)
+
+// IsSupportRole reports whether a role may open support cases
+func IsSupportRole(role string) bool {
+	return role == RoleSupport || role == RoleSupportLead || role == RoleAdmin
+}
+
+// SupportCaseContext bundles everything a support agent needs for a ride ticket
+type SupportCaseContext struct {
+	Ride           *Ride                 `json:"ride"`
+	Timeline       []*RideEvent          `json:"timeline"`
+	FareBreakdown  *PriceBreakdown       `json:"fare_breakdown,omitempty"`
+	Rider          *SupportRiderProfile  `json:"rider"`
+	Driver         *Driver               `json:"driver,omitempty"`
+	LocationTrail  *LocationTrailSummary `json:"location_trail"`
+	ChatTranscript *TranscriptPointer    `json:"chat_transcript"`
+	Redacted       bool                  `json:"redacted"`
+}
+
+// SupportRiderProfile is the ride-service view of a rider
+type SupportRiderProfile struct {
+	ID            uuid.UUID   `json:"id"`
+	TotalRides    int64       `json:"total_rides"`
+	RecentRideIDs []uuid.UUID `json:"recent_ride_ids"`
+}
+
+// LocationTrailSummary summarizes where a ride went without the raw GPS trail
+type LocationTrailSummary struct {
+	Pickup           Location  `json:"pickup"`
+	Dropoff          Location  `json:"dropoff"`
+	LastKnown        *Location `json:"last_known,omitempty"`
+	StraightLineM    float64   `json:"straight_line_meters"`
+	PlannedDistanceM int64     `json:"planned_distance_meters,omitempty"`
+	PlannedDurationS int64     `json:"planned_duration_seconds,omitempty"`
+	ActualDurationS  int64     `json:"actual_duration_seconds,omitempty"`
+}
+
+// TranscriptPointer references a conversation stored by the messaging service
+type TranscriptPointer struct {
+	Service        string `json:"service"`
+	ConversationID string `json:"conversation_id"`
+}
+
+// Redact strips personal data that the given role is not cleared to see.
+// Leads and admins see everything; front-line support sees masked phone
+// numbers and coarse (~100m) coordinates without street addresses.
+func (c *SupportCaseContext) Redact(role string) {
+	if role == RoleSupportLead || role == RoleAdmin {
+		return
+	}
+	c.Redacted = true
+
+	if c.Driver != nil {
+		driver := *c.Driver
+		driver.Phone = MaskPhone(driver.Phone)
+		driver.CurrentLocation = coarseLocationPtr(driver.CurrentLocation)
+		c.Driver = &driver
+	}
+
+	if c.Ride != nil {
+		ride := *c.Ride
+		ride.PickupLocation = coarseLocation(ride.PickupLocation)
+		ride.DropoffLocation = coarseLocation(ride.DropoffLocation)
+		ride.CurrentLocation = coarseLocationPtr(ride.CurrentLocation)
+		stops := make([]Location, len(ride.Stops))
+		for i, stop := range ride.Stops {
+			stops[i] = coarseLocation(stop)
+		}
+		ride.Stops = stops
+		// The polyline traces the street-level route; the trail summary
+		// keeps its planned distance and duration
+		ride.Route = nil
+		c.Ride = &ride
+	}
+
+	if c.LocationTrail != nil {
+		trail := *c.LocationTrail
+		trail.Pickup = coarseLocation(trail.Pickup)
+		trail.Dropoff = coarseLocation(trail.Dropoff)
+		trail.LastKnown = coarseLocationPtr(trail.LastKnown)
+		c.LocationTrail = &trail
+	}
+}
+
+// MaskPhone keeps the country prefix and last two digits of a phone number
+func MaskPhone(phone string) string {
+	if len(phone) <= 6 {
+		return strings.Repeat("*", len(phone))
+	}
+	return phone[:4] + strings.Repeat("*", len(phone)-6) + phone[len(phone)-2:]
+}
+
+// coarseLocation rounds coordinates to 3 decimals and drops address details
+func coarseLocation(loc Location) Location {
+	return Location{
+		Latitude:  math.Round(loc.Latitude*1000) / 1000,
+		Longitude: math.Round(loc.Longitude*1000) / 1000,
+		Name:      loc.Name,
+		H3Cell:    loc.H3Cell,
+	}
+}
+
+func coarseLocationPtr(loc *Location) *Location {
+	if loc == nil {
+		return nil
+	}
+	coarse := coarseLocation(*loc)
+	return &coarse
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func newSupportCase() *SupportCaseContext {
+	pickup := Location{Latitude: -1.286389, Longitude: 36.817223, Address: "12 Kenyatta Avenue", Name: "Hilton"}
+	return &SupportCaseContext{
+		Ride: &Ride{
+			PickupLocation:  pickup,
+			DropoffLocation: Location{Latitude: -1.267612, Longitude: 36.810833, Address: "Westlands Road"},
+			Stops:           []Location{{Latitude: -1.2801234, Longitude: 36.8156789, Address: "Moi Avenue"}},
+			Route:           &RouteInfo{DistanceMeters: 4200, DurationSeconds: 900, Polyline: "_p~iF~ps|U_ulLnnqC"},
+		},
+		Driver:        &Driver{Phone: "+254712345678", CurrentLocation: &Location{Latitude: -1.2811111, Longitude: 36.8222222}},
+		LocationTrail: &LocationTrailSummary{Pickup: pickup, PlannedDistanceM: 4200},
+	}
+}
+
+func TestSupportCaseContextRedact(t *testing.T) {
+	c := newSupportCase()
+	ride := c.Ride
+	c.Redact(RoleSupport)
+
+	if !c.Redacted {
+		t.Error("Redacted = false for front-line support")
+	}
+	if c.Ride.Route != nil {
+		t.Errorf("Route = %+v, want the street-level route dropped", c.Ride.Route)
+	}
+	if ride.Route == nil {
+		t.Error("Redact() changed the ride it was given")
+	}
+	for _, loc := range []Location{c.Ride.PickupLocation, c.Ride.DropoffLocation, c.Ride.Stops[0], c.LocationTrail.Pickup, *c.Driver.CurrentLocation} {
+		if loc.Address != "" {
+			t.Errorf("address %q kept", loc.Address)
+		}
+		if loc.Latitude != math.Round(loc.Latitude*1000)/1000 {
+			t.Errorf("latitude %v not coarsened", loc.Latitude)
+		}
+	}
+	if c.Ride.PickupLocation.Name != "Hilton" {
+		t.Errorf("pickup name = %q, want the place name kept", c.Ride.PickupLocation.Name)
+	}
+	if c.Driver.Phone != "+254*******78" {
+		t.Errorf("driver phone = %q, want masked", c.Driver.Phone)
+	}
+	if c.LocationTrail.PlannedDistanceM != 4200 {
+		t.Errorf("planned distance = %d, want kept", c.LocationTrail.PlannedDistanceM)
+	}
+}
+
+func TestSupportCaseContextRedactLeads(t *testing.T) {
+	for _, role := range []string{RoleSupportLead, RoleAdmin} {
+		c := newSupportCase()
+		c.Redact(role)
+		if c.Redacted || c.Ride.Route == nil || c.Driver.Phone != "+254712345678" {
+			t.Errorf("Redact(%s) masked the case, want it in full", role)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Incident settings
+const (
+	DefaultIncidentRadiusM   = 300.0
+	MaxIncidentRadiusM       = 5000.0
+	DefaultIncidentTTL       = 2 * time.Hour
+	DefaultDriverIncidentTTL = 45 * time.Minute // driver reports are unverified, so lapse sooner
+	MaxIncidentTTL           = 72 * time.Hour
+	MaxIncidentDelay         = 3.0 // the most an incident slows travel through it
+)
+
+// IncidentType is the kind of disruption reported on the road
+type IncidentType string
+
+const (
+	IncidentRoadClosure IncidentType = "ROAD_CLOSURE"
+	IncidentFlood       IncidentType = "FLOOD"
+	IncidentProtest     IncidentType = "PROTEST"
+	IncidentAccident    IncidentType = "ACCIDENT"
+	IncidentRoadworks   IncidentType = "ROADWORKS"
+	IncidentCheckpoint  IncidentType = "CHECKPOINT"
+)
+
+// incidentDelays is how much each kind of incident slows travel through it
+// when the reporter gives no figure
+var incidentDelays = map[IncidentType]float64{
+	IncidentRoadClosure: 2.5,
+	IncidentFlood:       2.0,
+	IncidentProtest:     1.8,
+	IncidentAccident:    1.5,
+	IncidentRoadworks:   1.3,
+	IncidentCheckpoint:  1.3,
+}
+
+// IsValid reports whether the type is one that can be reported
+func (t IncidentType) IsValid() bool {
+	_, ok := incidentDelays[t]
+	return ok
+}
+
+// IncidentSource is who reported an incident
+type IncidentSource string
+
+const (
+	IncidentSourceOps    IncidentSource = "OPS"
+	IncidentSourceDriver IncidentSource = "DRIVER"
+	IncidentSourceFeed   IncidentSource = "FEED" // external traffic feeds
+)
+
+// TrafficIncident is a reported disruption covering a circular zone until
+// it expires or is resolved. ETAs through the zone are slowed by
+// DelayMultiplier, and rides routed through it are offered a reroute.
+type TrafficIncident struct {
+	ID              uuid.UUID      `json:"id"`
+	Type            IncidentType   `json:"type"`
+	Source          IncidentSource `json:"source"`
+	ReportedBy      *uuid.UUID     `json:"reported_by,omitempty"`
+	Description     string         `json:"description,omitempty"`
+	Latitude        float64        `json:"latitude"`
+	Longitude       float64        `json:"longitude"`
+	RadiusMeters    float64        `json:"radius_meters"`
+	DelayMultiplier float64        `json:"delay_multiplier"`
+	CreatedAt       time.Time      `json:"created_at"`
+	ExpiresAt       time.Time      `json:"expires_at"`
+}
+
+// Normalize fills in an incident's defaults and checks it is one that can
+// be stored. The caller checks the coordinates.
+func (i *TrafficIncident) Normalize(now time.Time) error {
+	if !i.Type.IsValid() {
+		return ErrInvalidIncidentType
+	}
+	if i.RadiusMeters < 0 || i.RadiusMeters > MaxIncidentRadiusM {
+		return ErrInvalidIncidentRadius
+	}
+	if i.DelayMultiplier != 0 && (i.DelayMultiplier < 1 || i.DelayMultiplier > MaxIncidentDelay) {
+		return ErrInvalidIncidentDelay
+	}
+
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	if i.Source == "" {
+		i.Source = IncidentSourceOps
+	}
+	if i.RadiusMeters == 0 {
+		i.RadiusMeters = DefaultIncidentRadiusM
+	}
+	if i.DelayMultiplier == 0 {
+		i.DelayMultiplier = incidentDelays[i.Type]
+	}
+	if i.CreatedAt.IsZero() {
+		i.CreatedAt = now
+	}
+	if i.ExpiresAt.IsZero() {
+		ttl := DefaultIncidentTTL
+		if i.Source == IncidentSourceDriver {
+			ttl = DefaultDriverIncidentTTL
+		}
+		i.ExpiresAt = now.Add(ttl)
+	}
+	if !i.ExpiresAt.After(now) || i.ExpiresAt.Sub(now) > MaxIncidentTTL {
+		return ErrInvalidIncidentExpiry
+	}
+	return nil
+}
+
+// IsActive reports whether the incident is still in force
+func (i *TrafficIncident) IsActive(now time.Time) bool {
+	return now.Before(i.ExpiresAt)
+}
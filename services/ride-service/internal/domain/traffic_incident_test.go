@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrafficIncidentNormalize(t *testing.T) {
+	now := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	incident := &TrafficIncident{Type: IncidentFlood, Source: IncidentSourceDriver}
+
+	if err := incident.Normalize(now); err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if incident.RadiusMeters != DefaultIncidentRadiusM || incident.DelayMultiplier != 2.0 {
+		t.Errorf("radius = %v, delay = %v; want defaults for a flood", incident.RadiusMeters, incident.DelayMultiplier)
+	}
+	if !incident.ExpiresAt.Equal(now.Add(DefaultDriverIncidentTTL)) {
+		t.Errorf("expires at %v, want the driver report lifetime", incident.ExpiresAt)
+	}
+	if !incident.IsActive(now) || incident.IsActive(incident.ExpiresAt) {
+		t.Error("incident should be active until it expires")
+	}
+}
+
+func TestTrafficIncidentNormalizeRejects(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		incident TrafficIncident
+		want     error
+	}{
+		{"unknown type", TrafficIncident{Type: "METEOR"}, ErrInvalidIncidentType},
+		{"too wide", TrafficIncident{Type: IncidentProtest, RadiusMeters: 9000}, ErrInvalidIncidentRadius},
+		{"speeds up traffic", TrafficIncident{Type: IncidentAccident, DelayMultiplier: 0.5}, ErrInvalidIncidentDelay},
+		{"already expired", TrafficIncident{Type: IncidentRoadworks, ExpiresAt: now.Add(-time.Minute)}, ErrInvalidIncidentExpiry},
+		{"too long", TrafficIncident{Type: IncidentRoadworks, ExpiresAt: now.Add(100 * time.Hour)}, ErrInvalidIncidentExpiry},
+	}
+	for _, tt := range tests {
+		if err := tt.incident.Normalize(now); err != tt.want {
+			t.Errorf("%s: Normalize() = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrainingProduct is a kind of work drivers receive offers for only once
+// they have completed its training
+type TrainingProduct string
+
+const (
+	// TrainingProductIntercity covers rides ending in another city than
+	// they start in
+	TrainingProductIntercity TrainingProduct = "INTERCITY"
+	// TrainingProductPool covers pool rides shared between riders
+	TrainingProductPool TrainingProduct = "POOL"
+	// TrainingProductRegulatedDelivery covers deliveries of regulated goods
+	// such as prescription medicine
+	TrainingProductRegulatedDelivery TrainingProduct = "REGULATED_DELIVERY"
+)
+
+// IsValid reports whether the product is known
+func (p TrainingProduct) IsValid() bool {
+	switch p {
+	case TrainingProductIntercity, TrainingProductPool, TrainingProductRegulatedDelivery:
+		return true
+	}
+	return false
+}
+
+// TrainingPrerequisites are the learning provider's modules a driver must
+// complete before receiving offers for each product. A product with no
+// modules is not gated.
+type TrainingPrerequisites map[TrainingProduct][]string
+
+// ParseTrainingPrerequisites reads TRAINING_PREREQUISITES, a comma
+// separated list of PRODUCT=MODULE|MODULE entries such as
+// "POOL=pool-etiquette,INTERCITY=long-haul-safety|border-crossing"
+func ParseTrainingPrerequisites(spec string) (TrainingPrerequisites, error) {
+	prereqs := make(TrainingPrerequisites)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(entry, "=")
+		product := TrainingProduct(strings.ToUpper(strings.TrimSpace(key)))
+		if !ok || !product.IsValid() {
+			return nil, fmt.Errorf("invalid training prerequisite %q, want PRODUCT=MODULE|MODULE", entry)
+		}
+		for _, module := range strings.Split(value, "|") {
+			module = strings.TrimSpace(module)
+			if module == "" {
+				return nil, fmt.Errorf("invalid training prerequisite %q, want PRODUCT=MODULE|MODULE", entry)
+			}
+			prereqs[product] = append(prereqs[product], module)
+		}
+	}
+	return prereqs, nil
+}
+
+// Modules lists every module any product requires, sorted
+func (p TrainingPrerequisites) Modules() []string {
+	seen := make(map[string]bool)
+	var modules []string
+	for _, required := range p {
+		for _, module := range required {
+			if !seen[module] {
+				seen[module] = true
+				modules = append(modules, module)
+			}
+		}
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// Status reports how far a driver who completed the given modules is
+// through a product's training
+func (p TrainingPrerequisites) Status(product TrainingProduct, completed map[string]bool) *TrainingStatus {
+	status := &TrainingStatus{
+		Product:  product,
+		Required: p[product],
+		Missing:  []string{},
+	}
+	if status.Required == nil {
+		status.Required = []string{}
+	}
+	for _, module := range status.Required {
+		if !completed[module] {
+			status.Missing = append(status.Missing, module)
+		}
+	}
+	status.Cleared = len(status.Missing) == 0
+	return status
+}
+
+// Cleared reports whether a driver who completed the given modules may
+// receive offers for every one of the products
+func (p TrainingPrerequisites) Cleared(products []TrainingProduct, completed map[string]bool) bool {
+	for _, product := range products {
+		if !p.Status(product, completed).Cleared {
+			return false
+		}
+	}
+	return true
+}
+
+// TrainingStatus is a driver's progress through one product's training
+type TrainingStatus struct {
+	Product  TrainingProduct `json:"product"`
+	Required []string        `json:"required"`
+	Missing  []string        `json:"missing"`
+	Cleared  bool            `json:"cleared"`
+}
+
+// RideTrainingProducts lists the gated products a ride falls under. A
+// ride is intercity when its pickup and dropoff are in different cities;
+// cities are given by code, empty when the point is in no known city.
+func RideTrainingProducts(rideType RideType, pickupCity, dropoffCity string) []TrainingProduct {
+	var products []TrainingProduct
+	if rideType == RideTypePool {
+		products = append(products, TrainingProductPool)
+	}
+	if pickupCity != dropoffCity {
+		products = append(products, TrainingProductIntercity)
+	}
+	return products
+}
+
+// TrainingCompletion records that a driver passed a training module
+type TrainingCompletion struct {
+	ID          uuid.UUID `json:"id"`
+	DriverID    uuid.UUID `json:"driver_id"`
+	ModuleID    string    `json:"module_id"`
+	Provider    string    `json:"provider"`
+	ExternalID  string    `json:"external_id"`
+	Score       *float64  `json:"score,omitempty"`
+	CompletedAt time.Time `json:"completed_at"`
+	RecordedAt  time.Time `json:"recorded_at"`
+}
+
+// TrainingWebhook is the payload the learning content provider sends when
+// a driver finishes a module or its quiz
+type TrainingWebhook struct {
+	CompletionID string    `json:"completion_id"`
+	DriverID     uuid.UUID `json:"driver_id"`
+	ModuleID     string    `json:"module_id"`
+	Passed       bool      `json:"passed"`
+	Score        *float64  `json:"score,omitempty"`
+	CompletedAt  time.Time `json:"completed_at"`
+}
+
+// Completion checks the payload and returns the completion it records,
+// or nil for an attempt the driver did not pass
+func (w *TrainingWebhook) Completion(provider string, now time.Time) (*TrainingCompletion, error) {
+	moduleID := strings.TrimSpace(w.ModuleID)
+	switch {
+	case strings.TrimSpace(w.CompletionID) == "":
+		return nil, fmt.Errorf("%w: completion_id is required", ErrInvalidTrainingCompletion)
+	case w.DriverID == uuid.Nil:
+		return nil, fmt.Errorf("%w: driver_id is required", ErrInvalidTrainingCompletion)
+	case moduleID == "":
+		return nil, fmt.Errorf("%w: module_id is required", ErrInvalidTrainingCompletion)
+	case w.CompletedAt.IsZero() || w.CompletedAt.After(now.Add(TrainingClockSkew)):
+		return nil, fmt.Errorf("%w: completed_at must be set and not in the future", ErrInvalidTrainingCompletion)
+	}
+	if !w.Passed {
+		return nil, nil
+	}
+
+	return &TrainingCompletion{
+		ID:          uuid.New(),
+		DriverID:    w.DriverID,
+		ModuleID:    moduleID,
+		Provider:    provider,
+		ExternalID:  strings.TrimSpace(w.CompletionID),
+		Score:       w.Score,
+		CompletedAt: w.CompletedAt.UTC(),
+		RecordedAt:  now,
+	}, nil
+}
+
+// TrainingClockSkew is how far ahead of our clock a provider's completion
+// time may be
+const TrainingClockSkew = 5 * time.Minute
@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseTrainingPrerequisites(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    TrainingPrerequisites
+		wantErr bool
+	}{
+		{name: "empty", spec: "", want: TrainingPrerequisites{}},
+		{
+			name: "several products",
+			spec: "pool=pool-etiquette, INTERCITY=long-haul-safety|border-crossing",
+			want: TrainingPrerequisites{
+				TrainingProductPool:      {"pool-etiquette"},
+				TrainingProductIntercity: {"long-haul-safety", "border-crossing"},
+			},
+		},
+		{name: "unknown product", spec: "XL=big-cars", wantErr: true},
+		{name: "missing modules", spec: "POOL=", wantErr: true},
+		{name: "empty module", spec: "POOL=a||b", wantErr: true},
+		{name: "no separator", spec: "POOL", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTrainingPrerequisites(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrainingPrerequisites() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTrainingPrerequisites() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrainingPrerequisitesCleared(t *testing.T) {
+	prereqs := TrainingPrerequisites{
+		TrainingProductPool:      {"pool-etiquette"},
+		TrainingProductIntercity: {"long-haul-safety", "border-crossing"},
+	}
+
+	tests := []struct {
+		name      string
+		products  []TrainingProduct
+		completed map[string]bool
+		want      bool
+	}{
+		{"nothing gated", nil, nil, true},
+		{"ungated product", []TrainingProduct{TrainingProductRegulatedDelivery}, nil, true},
+		{"pool done", []TrainingProduct{TrainingProductPool}, map[string]bool{"pool-etiquette": true}, true},
+		{"pool missing", []TrainingProduct{TrainingProductPool}, map[string]bool{"long-haul-safety": true}, false},
+		{"intercity partly done", []TrainingProduct{TrainingProductIntercity}, map[string]bool{"long-haul-safety": true}, false},
+		{
+			"pool and intercity done",
+			[]TrainingProduct{TrainingProductPool, TrainingProductIntercity},
+			map[string]bool{"pool-etiquette": true, "long-haul-safety": true, "border-crossing": true},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prereqs.Cleared(tt.products, tt.completed); got != tt.want {
+				t.Errorf("Cleared() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	status := prereqs.Status(TrainingProductIntercity, map[string]bool{"long-haul-safety": true})
+	if status.Cleared || !reflect.DeepEqual(status.Missing, []string{"border-crossing"}) {
+		t.Errorf("Status() = %+v, want border-crossing missing", status)
+	}
+	if got := prereqs.Modules(); !reflect.DeepEqual(got, []string{"border-crossing", "long-haul-safety", "pool-etiquette"}) {
+		t.Errorf("Modules() = %v", got)
+	}
+}
+
+func TestRideTrainingProducts(t *testing.T) {
+	tests := []struct {
+		name     string
+		rideType RideType
+		pickup   string
+		dropoff  string
+		want     []TrainingProduct
+	}{
+		{"standard in town", RideTypeStandard, "LOS", "LOS", nil},
+		{"pool in town", RideTypePool, "LOS", "LOS", []TrainingProduct{TrainingProductPool}},
+		{"standard between cities", RideTypeStandard, "LOS", "IBA", []TrainingProduct{TrainingProductIntercity}},
+		{"leaving known cities", RideTypeStandard, "LOS", "", []TrainingProduct{TrainingProductIntercity}},
+		{"pool between cities", RideTypePool, "NBO", "MBA", []TrainingProduct{TrainingProductPool, TrainingProductIntercity}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RideTrainingProducts(tt.rideType, tt.pickup, tt.dropoff); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RideTrainingProducts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrainingWebhookCompletion(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	valid := TrainingWebhook{
+		CompletionID: "cmp_123",
+		DriverID:     uuid.New(),
+		ModuleID:     "pool-etiquette",
+		Passed:       true,
+		CompletedAt:  now.Add(-time.Hour),
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(w *TrainingWebhook)
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "passed", mutate: func(w *TrainingWebhook) {}},
+		{name: "failed attempt", mutate: func(w *TrainingWebhook) { w.Passed = false }, wantNil: true},
+		{name: "no completion id", mutate: func(w *TrainingWebhook) { w.CompletionID = "" }, wantErr: true},
+		{name: "no driver", mutate: func(w *TrainingWebhook) { w.DriverID = uuid.Nil }, wantErr: true},
+		{name: "no module", mutate: func(w *TrainingWebhook) { w.ModuleID = " " }, wantErr: true},
+		{name: "no completion time", mutate: func(w *TrainingWebhook) { w.CompletedAt = time.Time{} }, wantErr: true},
+		{name: "within clock skew", mutate: func(w *TrainingWebhook) { w.CompletedAt = now.Add(time.Minute) }},
+		{name: "in the future", mutate: func(w *TrainingWebhook) { w.CompletedAt = now.Add(time.Hour) }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := valid
+			tt.mutate(&payload)
+
+			got, err := payload.Completion("acme-learning", now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTrainingCompletion) {
+					t.Fatalf("Completion() error = %v, want ErrInvalidTrainingCompletion", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Completion() unexpected error = %v", err)
+			}
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("Completion() = %v, wantNil %v", got, tt.wantNil)
+			}
+			if got != nil && (got.ExternalID != "cmp_123" || got.Provider != "acme-learning" || got.DriverID != payload.DriverID) {
+				t.Errorf("Completion() = %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// DefaultTripShareTTL is how long a shared trip link works
+const DefaultTripShareTTL = 12 * time.Hour
+
+// Where a shared trip's ETA is to
+const (
+	ShareETAToPickup  = "PICKUP"
+	ShareETAToDropoff = "DROPOFF"
+)
+
+// TripShare is a signed public link a rider sends so others can follow
+// their ride without an account
+type TripShare struct {
+	Token     string    `json:"token"`
+	Path      string    `json:"path"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SharedTrip is what a trip share link shows: enough to follow the ride
+// and recognise the car, and nothing about the rider. Once the ride ends
+// only its status is shown.
+type SharedTrip struct {
+	Status          RideStatus     `json:"status"`
+	DriverFirstName string         `json:"driver_first_name,omitempty"`
+	DriverLocation  *Location      `json:"driver_location,omitempty"`
+	Heading         float64        `json:"heading,omitempty"`
+	LocationAt      *time.Time     `json:"location_updated_at,omitempty"`
+	ETASeconds      *int64         `json:"eta_seconds,omitempty"`
+	ETATo           string         `json:"eta_to,omitempty"` // PICKUP while the driver is on the way, then DROPOFF
+	Vehicle         *SharedVehicle `json:"vehicle,omitempty"`
+	ExpiresAt       time.Time      `json:"expires_at"`
+}
+
+// SharedVehicle describes the car on a shared trip
+type SharedVehicle struct {
+	Make         string `json:"make,omitempty"`
+	Model        string `json:"model,omitempty"`
+	Color        string `json:"color,omitempty"`
+	LicensePlate string `json:"license_plate"`
+}
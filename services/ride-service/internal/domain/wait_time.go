@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// WaitCharge is what a rider pays for keeping the driver waiting at pickup:
+// each full minute past the free minutes at the per-minute rate
+func WaitCharge(waited time.Duration, freeMinutes int, perMinute int64) (minutes int, fee int64) {
+	if perMinute <= 0 {
+		return 0, 0
+	}
+	billable := waited - time.Duration(freeMinutes)*time.Minute
+	if billable < time.Minute {
+		return 0, 0
+	}
+	minutes = int(billable / time.Minute)
+	return minutes, int64(minutes) * perMinute
+}
+
+// PickupWait is how long the driver waited between arriving at the pickup
+// and starting the trip, zero until the trip starts
+func (r *Ride) PickupWait() time.Duration {
+	if r.ArrivedAt == nil || r.StartedAt == nil || r.StartedAt.Before(*r.ArrivedAt) {
+		return 0
+	}
+	return r.StartedAt.Sub(*r.ArrivedAt)
+}
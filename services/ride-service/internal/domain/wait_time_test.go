@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaitCharge(t *testing.T) {
+	tests := []struct {
+		name        string
+		waited      time.Duration
+		freeMinutes int
+		perMinute   int64
+		wantMinutes int
+		wantFee     int64
+	}{
+		{"within free minutes", 3 * time.Minute, 3, 500, 0, 0},
+		{"under a full minute over", 3*time.Minute + 59*time.Second, 3, 500, 0, 0},
+		{"full minutes over", 6*time.Minute + 10*time.Second, 3, 500, 3, 1500},
+		{"no free minutes", 2 * time.Minute, 0, 500, 2, 1000},
+		{"no wait rate", time.Hour, 3, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minutes, fee := WaitCharge(tt.waited, tt.freeMinutes, tt.perMinute)
+			if minutes != tt.wantMinutes || fee != tt.wantFee {
+				t.Errorf("WaitCharge() = %d, %d, want %d, %d", minutes, fee, tt.wantMinutes, tt.wantFee)
+			}
+		})
+	}
+}
+
+func TestRidePickupWait(t *testing.T) {
+	arrived := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	started := arrived.Add(4 * time.Minute)
+
+	if got := (&Ride{ArrivedAt: &arrived}).PickupWait(); got != 0 {
+		t.Errorf("PickupWait() before the trip starts = %v, want 0", got)
+	}
+	if got := (&Ride{StartedAt: &started}).PickupWait(); got != 0 {
+		t.Errorf("PickupWait() without an arrival = %v, want 0", got)
+	}
+	if got := (&Ride{ArrivedAt: &arrived, StartedAt: &started}).PickupWait(); got != 4*time.Minute {
+		t.Errorf("PickupWait() = %v, want 4m", got)
+	}
+}
@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Weather defaults, used where a city's bundle leaves them out
+const (
+	DefaultRainTrafficMultiplier = 1.3
+	DefaultWeatherMaxSurge       = 0.3  // the most weather adds to a surge multiplier
+	DefaultSevereETAPadding      = 0.25 // severe weather ETAs are padded by this fraction
+	WeatherStaleAfter            = 45 * time.Minute
+)
+
+// Rainfall thresholds in mm per hour
+const (
+	heavyRainMMPerHour  = 7.6
+	severeRainMMPerHour = 30.0
+)
+
+// WeatherNoticeSevere is shown to riders requesting in severe weather
+const WeatherNoticeSevere = "Severe weather in your area. Trips may take longer than usual and fares may be higher."
+
+// WeatherSeverity is how much the weather affects driving
+type WeatherSeverity string
+
+const (
+	WeatherClear     WeatherSeverity = "CLEAR"
+	WeatherRain      WeatherSeverity = "RAIN"
+	WeatherHeavyRain WeatherSeverity = "HEAVY_RAIN"
+	WeatherSevere    WeatherSeverity = "SEVERE"
+)
+
+// WeatherCondition is the weather last observed at a city's center
+type WeatherCondition struct {
+	City          string          `json:"city"`
+	Severity      WeatherSeverity `json:"severity"`
+	Summary       string          `json:"summary,omitempty"`
+	RainMMPerHour float64         `json:"rain_mm_per_hour"`
+	ObservedAt    time.Time       `json:"observed_at"`
+}
+
+// IsStale reports whether the condition is too old to act on
+func (c *WeatherCondition) IsStale(now time.Time) bool {
+	return now.Sub(c.ObservedAt) > WeatherStaleAfter
+}
+
+// ClassifyWeather grades an OpenWeather condition code and the last hour's
+// rainfall. Thunderstorms with heavy rain, extreme rain and squalls or
+// tornadoes are severe.
+func ClassifyWeather(code int, rainMMPerHour float64) WeatherSeverity {
+	switch {
+	case rainMMPerHour >= severeRainMMPerHour,
+		code == 202, code == 212, code == 221, code == 232, // heavy and ragged thunderstorms
+		code == 504,              // extreme rain
+		code == 771, code == 781: // squalls, tornado
+		return WeatherSevere
+	case rainMMPerHour >= heavyRainMMPerHour,
+		code == 502, code == 503, code == 522, code == 531, // heavy and very heavy rain
+		code >= 200 && code < 300:
+		return WeatherHeavyRain
+	case rainMMPerHour > 0, code >= 300 && code < 600:
+		return WeatherRain
+	}
+	return WeatherClear
+}
+
+// CityWeather is a city's weather adjustments. Zero values take the
+// platform defaults.
+type CityWeather struct {
+	RainTrafficMultiplier float64 `json:"rain_traffic_multiplier,omitempty"` // traffic slowdown in heavy rain or worse
+	MaxSurge              float64 `json:"max_surge,omitempty"`               // added to surge in severe weather, half in heavy rain
+	SurgeDisabled         bool    `json:"surge_disabled"`                    // where regulators bar weather surge
+	SevereETAPadding      float64 `json:"severe_eta_padding,omitempty"`      // fraction ETAs are padded by in severe weather
+}
+
+// Validate checks the weather adjustments are usable
+func (w CityWeather) Validate() error {
+	if w.RainTrafficMultiplier != 0 && (w.RainTrafficMultiplier < 1 || w.RainTrafficMultiplier > 3) {
+		return fmt.Errorf("rain_traffic_multiplier must be between 1 and 3")
+	}
+	if w.MaxSurge < 0 || w.MaxSurge > 1 {
+		return fmt.Errorf("weather max_surge must be between 0 and 1")
+	}
+	if w.SevereETAPadding < 0 || w.SevereETAPadding > 1 {
+		return fmt.Errorf("severe_eta_padding must be between 0 and 1")
+	}
+	return nil
+}
+
+// TrafficMultiplier returns how much the weather slows traffic
+func (w CityWeather) TrafficMultiplier(severity WeatherSeverity) float64 {
+	if severity != WeatherHeavyRain && severity != WeatherSevere {
+		return 1
+	}
+	if w.RainTrafficMultiplier == 0 {
+		return DefaultRainTrafficMultiplier
+	}
+	return w.RainTrafficMultiplier
+}
+
+// Surge returns what the weather adds to a surge multiplier
+func (w CityWeather) Surge(severity WeatherSeverity) float64 {
+	if w.SurgeDisabled {
+		return 0
+	}
+	max := w.MaxSurge
+	if max == 0 {
+		max = DefaultWeatherMaxSurge
+	}
+	switch severity {
+	case WeatherSevere:
+		return max
+	case WeatherHeavyRain:
+		return max / 2
+	}
+	return 0
+}
+
+// PadETA pads an ETA in seconds for severe weather
+func (w CityWeather) PadETA(seconds int64, severity WeatherSeverity) int64 {
+	if severity != WeatherSevere {
+		return seconds
+	}
+	padding := w.SevereETAPadding
+	if padding == 0 {
+		padding = DefaultSevereETAPadding
+	}
+	return int64(float64(seconds) * (1 + padding))
+}
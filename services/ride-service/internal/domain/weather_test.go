@@ -0,0 +1,51 @@
+package domain
+
+import "testing"
+
+func TestClassifyWeather(t *testing.T) {
+	tests := []struct {
+		code int
+		rain float64
+		want WeatherSeverity
+	}{
+		{800, 0, WeatherClear},
+		{500, 0.8, WeatherRain},
+		{501, 8, WeatherHeavyRain},
+		{502, 0, WeatherHeavyRain},
+		{211, 2, WeatherHeavyRain},
+		{202, 5, WeatherSevere},
+		{501, 35, WeatherSevere},
+	}
+	for _, tt := range tests {
+		if got := ClassifyWeather(tt.code, tt.rain); got != tt.want {
+			t.Errorf("ClassifyWeather(%d, %v) = %s, want %s", tt.code, tt.rain, got, tt.want)
+		}
+	}
+}
+
+func TestCityWeatherAdjustments(t *testing.T) {
+	var defaults CityWeather
+	if got := defaults.TrafficMultiplier(WeatherRain); got != 1 {
+		t.Errorf("light rain traffic multiplier = %v, want 1", got)
+	}
+	if got := defaults.TrafficMultiplier(WeatherHeavyRain); got != DefaultRainTrafficMultiplier {
+		t.Errorf("heavy rain traffic multiplier = %v, want %v", got, DefaultRainTrafficMultiplier)
+	}
+	if got := defaults.Surge(WeatherHeavyRain); got != DefaultWeatherMaxSurge/2 {
+		t.Errorf("heavy rain surge = %v, want half the maximum", got)
+	}
+	if got := defaults.PadETA(600, WeatherSevere); got != 750 {
+		t.Errorf("severe ETA = %d, want 750", got)
+	}
+	if got := defaults.PadETA(600, WeatherHeavyRain); got != 600 {
+		t.Errorf("heavy rain ETA = %d, want unpadded", got)
+	}
+
+	barred := CityWeather{SurgeDisabled: true}
+	if got := barred.Surge(WeatherSevere); got != 0 {
+		t.Errorf("surge with weather surge barred = %v, want 0", got)
+	}
+	if err := (CityWeather{MaxSurge: 2}).Validate(); err == nil {
+		t.Error("max_surge above 1 should not validate")
+	}
+}
@@ -0,0 +1,9 @@
+package domain
+
+// WebhookFailureCount is how many deliveries from a webhook source have
+// been refused for one reason since the replica started
+type WebhookFailureCount struct {
+	Source string `json:"source"`
+	Reason string `json:"reason"`
+	Count  int64  `json:"count"`
+}
@@ -0,0 +1,114 @@
+package domain
+
+import "time"
+
+// ZoneKPIBucket is the granularity of the zone KPI rollups
+const ZoneKPIBucket = 15 * time.Minute
+
+// ZoneKPIWindows are the windows the ops dashboard can select
+var ZoneKPIWindows = map[string]time.Duration{
+	"1h":  time.Hour,
+	"6h":  6 * time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+// DefaultZoneKPIWindow is the window used when none is selected
+const DefaultZoneKPIWindow = "24h"
+
+// ZoneKPIRollup is one zone's pre-aggregated operational counters for a
+// ZoneKPIBucket. Zones are pickup H3 cells at their city's pricing resolution.
+type ZoneKPIRollup struct {
+	Zone          string    `json:"zone"`
+	CityCode      string    `json:"city_code,omitempty"`
+	BucketStart   time.Time `json:"bucket_start"`
+	Requests      int64     `json:"requests"`
+	Completed     int64     `json:"completed"`
+	Cancelled     int64     `json:"cancelled"`
+	Expired       int64     `json:"expired"` // found no driver within the matching TTL
+	ETASumSeconds int64     `json:"eta_sum_seconds"` // accepted to arrived at pickup
+	ETACount      int64     `json:"eta_count"`
+	SurgeSum      float64   `json:"surge_sum"`
+	SurgeCount    int64     `json:"surge_count"`
+	SupplySum     int64     `json:"supply_sum"` // available drivers summed over samples
+	SupplySamples int64     `json:"supply_samples"`
+}
+
+// ZoneKPIs are a zone's operational KPIs over a window
+type ZoneKPIs struct {
+	Zone             string     `json:"zone"`
+	CityCode         string     `json:"city_code,omitempty"`
+	BucketStart      *time.Time `json:"bucket_start,omitempty"` // set on series points
+	Requests         int64      `json:"requests"`
+	Completed        int64      `json:"completed"`
+	Cancelled        int64      `json:"cancelled"`
+	Expired          int64      `json:"expired"`
+	FulfillmentRate  float64    `json:"fulfillment_rate"`  // completed / requests
+	CancellationRate float64    `json:"cancellation_rate"` // cancelled / requests
+	ExpiryRate       float64    `json:"expiry_rate"`       // expired / requests
+	AvgETASeconds    float64    `json:"avg_eta_seconds"`
+	AvgSurge         float64    `json:"avg_surge"`
+	AvgSupply        float64    `json:"avg_supply"`
+	ActiveSupply     int64      `json:"active_supply"` // available drivers now
+	rollup           ZoneKPIRollup
+}
+
+// Add folds a rollup bucket into the KPIs
+func (k *ZoneKPIs) Add(r *ZoneKPIRollup) {
+	k.rollup.Requests += r.Requests
+	k.rollup.Completed += r.Completed
+	k.rollup.Cancelled += r.Cancelled
+	k.rollup.Expired += r.Expired
+	k.rollup.ETASumSeconds += r.ETASumSeconds
+	k.rollup.ETACount += r.ETACount
+	k.rollup.SurgeSum += r.SurgeSum
+	k.rollup.SurgeCount += r.SurgeCount
+	k.rollup.SupplySum += r.SupplySum
+	k.rollup.SupplySamples += r.SupplySamples
+	if k.CityCode == "" {
+		k.CityCode = r.CityCode
+	}
+	k.compute()
+}
+
+func (k *ZoneKPIs) compute() {
+	r := &k.rollup
+	k.Requests = r.Requests
+	k.Completed = r.Completed
+	k.Cancelled = r.Cancelled
+	k.Expired = r.Expired
+	k.FulfillmentRate = kpiRatio(float64(r.Completed), r.Requests)
+	k.CancellationRate = kpiRatio(float64(r.Cancelled), r.Requests)
+	k.ExpiryRate = kpiRatio(float64(r.Expired), r.Requests)
+	k.AvgETASeconds = kpiRatio(float64(r.ETASumSeconds), r.ETACount)
+	k.AvgSurge = kpiRatio(r.SurgeSum, r.SurgeCount)
+	k.AvgSupply = kpiRatio(float64(r.SupplySum), r.SupplySamples)
+}
+
+func kpiRatio(sum float64, count int64) float64 {
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// ZoneKPIReport is the per-zone KPI table for a window
+type ZoneKPIReport struct {
+	Window      string      `json:"window"`
+	From        time.Time   `json:"from"`
+	To          time.Time   `json:"to"`
+	CityCode    string      `json:"city_code,omitempty"`
+	Zones       []*ZoneKPIs `json:"zones"`
+	GeneratedAt time.Time   `json:"generated_at"`
+}
+
+// ZoneKPISeries is one zone's KPIs over a window with a point per bucket,
+// for charting
+type ZoneKPISeries struct {
+	Window      string      `json:"window"`
+	From        time.Time   `json:"from"`
+	To          time.Time   `json:"to"`
+	Totals      *ZoneKPIs   `json:"totals"`
+	Buckets     []*ZoneKPIs `json:"buckets"`
+	GeneratedAt time.Time   `json:"generated_at"`
+}
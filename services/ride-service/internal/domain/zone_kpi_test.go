@@ -0,0 +1,37 @@
+package domain
+
+import "testing"
+
+func TestZoneKPIsAdd(t *testing.T) {
+	var kpis ZoneKPIs
+	kpis.Add(&ZoneKPIRollup{
+		Zone: "9abc", CityCode: "LOS", Requests: 6, Completed: 4, Cancelled: 1, Expired: 1,
+		ETASumSeconds: 1200, ETACount: 4, SurgeSum: 6, SurgeCount: 4, SupplySum: 30, SupplySamples: 3,
+	})
+	kpis.Add(&ZoneKPIRollup{
+		Zone: "9abc", Requests: 4, Completed: 4, Cancelled: 0,
+		ETASumSeconds: 1200, ETACount: 4, SurgeSum: 4, SurgeCount: 4, SupplySum: 0, SupplySamples: 3,
+	})
+
+	if kpis.Requests != 10 || kpis.Completed != 8 || kpis.Cancelled != 1 {
+		t.Fatalf("counts = %d/%d/%d, want 10/8/1", kpis.Requests, kpis.Completed, kpis.Cancelled)
+	}
+	if kpis.CityCode != "LOS" {
+		t.Errorf("CityCode = %q, want LOS", kpis.CityCode)
+	}
+	if kpis.FulfillmentRate != 0.8 || kpis.CancellationRate != 0.1 {
+		t.Errorf("rates = %v/%v, want 0.8/0.1", kpis.FulfillmentRate, kpis.CancellationRate)
+	}
+	if kpis.Expired != 1 || kpis.ExpiryRate != 0.1 {
+		t.Errorf("expired = %d at %v, want 1 at 0.1", kpis.Expired, kpis.ExpiryRate)
+	}
+	if kpis.AvgETASeconds != 300 || kpis.AvgSurge != 1.25 || kpis.AvgSupply != 5 {
+		t.Errorf("averages = %v/%v/%v, want 300/1.25/5", kpis.AvgETASeconds, kpis.AvgSurge, kpis.AvgSupply)
+	}
+
+	var empty ZoneKPIs
+	empty.Add(&ZoneKPIRollup{Zone: "9abc"})
+	if empty.FulfillmentRate != 0 || empty.AvgETASeconds != 0 {
+		t.Errorf("empty zone should have zero rates, got %+v", empty)
+	}
+}
@@ -0,0 +1,288 @@
+package eta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// =============================================================================
+// OSRM REGIONAL DATASETS
+// =============================================================================
+
+// CountryResolver maps a coordinate to its ISO 3166-1 alpha-2 country code
+type CountryResolver func(lat, lng float64) (country string, ok bool)
+
+// OSRMRegion is an OSRM instance serving one country's contracted dataset.
+// The probe point must lie on that country's road network.
+type OSRMRegion struct {
+	Country  string
+	BaseURL  string
+	ProbeLat float64
+	ProbeLng float64
+}
+
+// OSRMRegionHealth is the latest health check result for a regional dataset
+type OSRMRegionHealth struct {
+	Country     string     `json:"country"`
+	BaseURL     string     `json:"base_url"`
+	Healthy     bool       `json:"healthy"`
+	DataVersion string     `json:"data_version,omitempty"`
+	LatencyMS   int64      `json:"latency_ms"`
+	Error       string     `json:"error,omitempty"`
+	CheckedAt   *time.Time `json:"checked_at,omitempty"`
+}
+
+// ParseOSRMRegions parses OSRM_REGIONS, a comma separated list of
+// COUNTRY=URL pairs such as "NG=http://osrm-ng:5000,KE=http://osrm-ke:5000"
+func ParseOSRMRegions(spec string) (map[string]string, error) {
+	regions := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		country, baseURL, ok := strings.Cut(entry, "=")
+		country = strings.ToUpper(strings.TrimSpace(country))
+		baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+		if !ok || len(country) != 2 || baseURL == "" {
+			return nil, fmt.Errorf("invalid OSRM region %q, want COUNTRY=URL", entry)
+		}
+		regions[country] = baseURL
+	}
+	return regions, nil
+}
+
+// SetRegions routes requests to the dataset of the country the origin lies
+// in. Origins outside every region keep using the base URL.
+func (o *OSRMClient) SetRegions(regions []OSRMRegion, countryOf CountryResolver) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.regions = make(map[string]OSRMRegion, len(regions))
+	o.health = make(map[string]*OSRMRegionHealth, len(regions))
+	for _, region := range regions {
+		o.regions[region.Country] = region
+	}
+	o.countryOf = countryOf
+}
+
+// regionFor picks the dataset for a request origin. A region that failed
+// its last health check is reported as an error, so the fallback client
+// moves on to the next provider instead of querying a stale dataset.
+func (o *OSRMClient) regionFor(lat, lng float64) (string, bool, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if o.countryOf == nil {
+		return "", false, nil
+	}
+	country, ok := o.countryOf(lat, lng)
+	if !ok {
+		return "", false, nil
+	}
+	region, ok := o.regions[country]
+	if !ok {
+		return "", false, nil
+	}
+	if health, checked := o.health[country]; checked && !health.Healthy {
+		return "", false, fmt.Errorf("OSRM dataset for %s is unhealthy: %s", country, health.Error)
+	}
+	return region.BaseURL, true, nil
+}
+
+type osrmNearestResponse struct {
+	Code        string `json:"code"`
+	Message     string `json:"message,omitempty"`
+	DataVersion string `json:"data_version,omitempty"`
+}
+
+// CheckHealth probes every regional dataset with a nearest-road lookup at
+// its probe point and records the dataset version it reports
+func (o *OSRMClient) CheckHealth(ctx context.Context) []OSRMRegionHealth {
+	o.mu.RLock()
+	regions := make([]OSRMRegion, 0, len(o.regions))
+	for _, region := range o.regions {
+		regions = append(regions, region)
+	}
+	o.mu.RUnlock()
+
+	results := make([]OSRMRegionHealth, 0, len(regions))
+	for _, region := range regions {
+		health := o.probe(ctx, region)
+
+		o.mu.Lock()
+		previous := o.health[region.Country]
+		o.health[region.Country] = &health
+		o.mu.Unlock()
+
+		event := log.Debug()
+		if previous == nil || previous.Healthy != health.Healthy || previous.DataVersion != health.DataVersion {
+			event = log.Info()
+		}
+		if !health.Healthy {
+			event = log.Warn()
+		}
+		event.
+			Str("country", health.Country).
+			Bool("healthy", health.Healthy).
+			Str("data_version", health.DataVersion).
+			Int64("latency_ms", health.LatencyMS).
+			Str("error", health.Error).
+			Msg("OSRM dataset health checked")
+
+		results = append(results, health)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Country < results[j].Country })
+	return results
+}
+
+func (o *OSRMClient) probe(ctx context.Context, region OSRMRegion) OSRMRegionHealth {
+	checkedAt := time.Now()
+	health := OSRMRegionHealth{
+		Country:   region.Country,
+		BaseURL:   region.BaseURL,
+		CheckedAt: &checkedAt,
+	}
+
+	reqURL := fmt.Sprintf("%s/nearest/v1/%s/%f,%f?number=1",
+		region.BaseURL, ProfileCar.osrmProfile(), region.ProbeLng, region.ProbeLat)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+
+	start := time.Now()
+	resp, err := o.httpClient.Do(httpReq)
+	health.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	defer resp.Body.Close()
+
+	var nearest osrmNearestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&nearest); err != nil {
+		health.Error = fmt.Sprintf("unexpected response (HTTP %d)", resp.StatusCode)
+		return health
+	}
+	if nearest.Code != "Ok" {
+		health.Error = fmt.Sprintf("%s - %s", nearest.Code, nearest.Message)
+		return health
+	}
+
+	health.Healthy = true
+	health.DataVersion = nearest.DataVersion
+	return health
+}
+
+// RegionHealth returns the latest health of every regional dataset,
+// including regions not yet checked
+func (o *OSRMClient) RegionHealth() []OSRMRegionHealth {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	results := make([]OSRMRegionHealth, 0, len(o.regions))
+	for country, region := range o.regions {
+		if health, ok := o.health[country]; ok {
+			results = append(results, *health)
+			continue
+		}
+		results = append(results, OSRMRegionHealth{Country: country, BaseURL: region.BaseURL})
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Country < results[j].Country })
+	return results
+}
+
+// StartHealthChecks checks every region at startup and then every interval
+// until ctx is cancelled
+func (o *OSRMClient) StartHealthChecks(ctx context.Context, interval time.Duration) {
+	o.CheckHealth(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.CheckHealth(ctx)
+		}
+	}
+}
+
+// =============================================================================
+// PROVIDER FAILOVER METRICS
+// =============================================================================
+
+// ProviderStats counts routing calls for one provider in the fallback chain.
+// Failovers counts failed calls that were handed to the next provider.
+type ProviderStats struct {
+	Provider      string     `json:"provider"`
+	Requests      int64      `json:"requests"`
+	Successes     int64      `json:"successes"`
+	Failures      int64      `json:"failures"`
+	Failovers     int64      `json:"failovers"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty"`
+}
+
+type providerCounters struct {
+	mu    sync.Mutex
+	stats ProviderStats
+}
+
+func (c *providerCounters) record(err error, failover bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.Requests++
+	if err == nil {
+		c.stats.Successes++
+		return
+	}
+	c.stats.Failures++
+	c.stats.LastError = err.Error()
+	failedAt := time.Now()
+	c.stats.LastFailureAt = &failedAt
+	if failover {
+		c.stats.Failovers++
+	}
+}
+
+func (c *providerCounters) snapshot() ProviderStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// providerName labels a routing client in metrics and logs
+func providerName(client RoutingClient) string {
+	switch client.(type) {
+	case *GoogleMapsClient:
+		return "google"
+	case *MapboxClient:
+		return "mapbox"
+	case *ValhallaClient:
+		return "valhalla"
+	case *OSRMClient:
+		return "osrm"
+	case *MockRoutingClient:
+		return "estimate"
+	default:
+		return fmt.Sprintf("%T", client)
+	}
+}
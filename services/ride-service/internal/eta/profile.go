@@ -0,0 +1,118 @@
+package eta
+
+import "github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+
+// RoutingProfile is the kind of vehicle a route is planned for. Bikes can
+// cut through streets cars can't use; trucks can't use every street a car
+// can.
+type RoutingProfile string
+
+const (
+	ProfileCar        RoutingProfile = "car"
+	ProfileMotorcycle RoutingProfile = "motorcycle"
+	ProfileBicycle    RoutingProfile = "bicycle"
+	ProfileTruck      RoutingProfile = "truck"
+)
+
+// ProfileForVehicle is the routing profile a vehicle drives by. Tricycles
+// use the roads motorcycles do.
+func ProfileForVehicle(vehicleType domain.VehicleType) RoutingProfile {
+	switch vehicleType {
+	case domain.VehicleTypeBike, domain.VehicleTypeTricycle:
+		return ProfileMotorcycle
+	case domain.VehicleTypeTruck:
+		return ProfileTruck
+	default:
+		return ProfileCar
+	}
+}
+
+// ProfileForRideType is the routing profile of the vehicles that serve a
+// ride type
+func ProfileForRideType(rideType domain.RideType) RoutingProfile {
+	switch rideType {
+	case domain.RideTypeBoda, domain.RideTypeTricycle:
+		return ProfileMotorcycle
+	default:
+		return ProfileCar
+	}
+}
+
+// IsValid reports whether the profile is one routing providers know
+func (p RoutingProfile) IsValid() bool {
+	switch p {
+	case ProfileCar, ProfileMotorcycle, ProfileBicycle, ProfileTruck:
+		return true
+	}
+	return false
+}
+
+// orDefault treats an unset profile as a car, as routes were before
+// profiles
+func (p RoutingProfile) orDefault() RoutingProfile {
+	if p == "" {
+		return ProfileCar
+	}
+	return p
+}
+
+// GoogleMode is the Google Directions travel mode for the profile. Google
+// has no motorcycle or truck routing, so those route as cars.
+func (p RoutingProfile) GoogleMode() string {
+	if p == ProfileBicycle {
+		return "bicycling"
+	}
+	return "driving"
+}
+
+// mapboxProfile is the Mapbox Directions profile. Mapbox has no motorcycle
+// or truck routing, so those route as cars in traffic.
+func (p RoutingProfile) mapboxProfile() string {
+	if p == ProfileBicycle {
+		return "mapbox/cycling"
+	}
+	return "mapbox/driving-traffic"
+}
+
+// osrmProfile is the OSRM profile name. Self-hosted OSRM serves one
+// profile per instance, so each profile may have its own base URL.
+func (p RoutingProfile) osrmProfile() string {
+	switch p.orDefault() {
+	case ProfileMotorcycle:
+		return "motorcycle"
+	case ProfileBicycle:
+		return "cycling"
+	case ProfileTruck:
+		return "truck"
+	default:
+		return "driving"
+	}
+}
+
+// valhallaCosting is the Valhalla costing model, which covers every profile
+// from a single instance
+func (p RoutingProfile) valhallaCosting() string {
+	switch p.orDefault() {
+	case ProfileMotorcycle:
+		return "motorcycle"
+	case ProfileBicycle:
+		return "bicycle"
+	case ProfileTruck:
+		return "truck"
+	default:
+		return "auto"
+	}
+}
+
+// exclusions lists the road classes a request avoids, in the names a
+// provider gives tolls and highways
+func (r *ETARequest) exclusions(tolls, highways string) []string {
+	var exclude []string
+	if r.AvoidTolls {
+		exclude = append(exclude, tolls)
+	}
+	if r.AvoidHighways {
+		exclude = append(exclude, highways)
+	}
+	return exclude
+}
@@ -9,7 +9,11 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
 // =============================================================================
@@ -67,8 +71,14 @@ func (g *GoogleMapsClient) GetRoute(ctx context.Context, req *ETARequest) (*Rout
 	params := url.Values{}
 	params.Set("origin", fmt.Sprintf("%f,%f", req.OriginLat, req.OriginLng))
 	params.Set("destination", fmt.Sprintf("%f,%f", req.DestLat, req.DestLng))
-	params.Set("departure_time", fmt.Sprintf("%d", req.DepartureTime.Unix()))
-	params.Set("traffic_model", "best_guess")
+	params.Set("mode", req.Profile.GoogleMode())
+	if req.Profile.GoogleMode() == "driving" {
+		params.Set("departure_time", fmt.Sprintf("%d", req.DepartureTime.Unix()))
+		params.Set("traffic_model", "best_guess")
+	}
+	if avoid := req.exclusions("tolls", "highways"); len(avoid) > 0 {
+		params.Set("avoid", strings.Join(avoid, "|"))
+	}
 	params.Set("key", g.apiKey)
 
 	reqURL := fmt.Sprintf("%s?%s", g.baseURL, params.Encode())
@@ -141,7 +151,7 @@ func NewMapboxClient(accessToken string) *MapboxClient {
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		baseURL: "https://api.mapbox.com/directions/v5/mapbox/driving-traffic",
+		baseURL: "https://api.mapbox.com/directions/v5",
 	}
 }
 
@@ -172,9 +182,15 @@ func (m *MapboxClient) GetRoute(ctx context.Context, req *ETARequest) (*RouteRes
 	params := url.Values{}
 	params.Set("access_token", m.accessToken)
 	params.Set("geometries", "geojson")
-	params.Set("depart_at", req.DepartureTime.Format(time.RFC3339))
+	profile := req.Profile.mapboxProfile()
+	if profile != "mapbox/cycling" {
+		params.Set("depart_at", req.DepartureTime.Format(time.RFC3339))
+	}
+	if exclude := req.exclusions("toll", "motorway"); len(exclude) > 0 {
+		params.Set("exclude", strings.Join(exclude, ","))
+	}
 
-	reqURL := fmt.Sprintf("%s/%s?%s", m.baseURL, coordinates, params.Encode())
+	reqURL := fmt.Sprintf("%s/%s/%s?%s", m.baseURL, profile, coordinates, params.Encode())
 
 	httpReq, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
 	if err != nil {
@@ -227,13 +243,22 @@ func (m *MapboxClient) GetRoute(ctx context.Context, req *ETARequest) (*RouteRes
 // OSRM ROUTING CLIENT (Self-hosted)
 // =============================================================================
 
-// OSRMClient implements routing using OSRM (Open Source Routing Machine)
+// OSRMClient implements routing using OSRM (Open Source Routing Machine).
+// Per-country datasets set with SetRegions take precedence over the base URL.
 type OSRMClient struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL     string
+	profileURLs map[RoutingProfile]string
+	httpClient  *http.Client
+
+	mu        sync.RWMutex
+	regions   map[string]OSRMRegion
+	health    map[string]*OSRMRegionHealth
+	countryOf CountryResolver
 }
 
-// NewOSRMClient creates a new OSRM routing client
+// NewOSRMClient creates a new OSRM routing client. Instances built for
+// other profiles are read from OSRM_MOTORCYCLE_URL, OSRM_BICYCLE_URL and
+// OSRM_TRUCK_URL; profiles without one use the base URL.
 func NewOSRMClient(baseURL string) *OSRMClient {
 	if baseURL == "" {
 		baseURL = os.Getenv("OSRM_BASE_URL")
@@ -241,8 +266,21 @@ func NewOSRMClient(baseURL string) *OSRMClient {
 			baseURL = "http://router.project-osrm.org" // Public demo server (for testing only)
 		}
 	}
+
+	profileURLs := make(map[RoutingProfile]string)
+	for profile, env := range map[RoutingProfile]string{
+		ProfileMotorcycle: "OSRM_MOTORCYCLE_URL",
+		ProfileBicycle:    "OSRM_BICYCLE_URL",
+		ProfileTruck:      "OSRM_TRUCK_URL",
+	} {
+		if profileURL := os.Getenv(env); profileURL != "" {
+			profileURLs[profile] = profileURL
+		}
+	}
+
 	return &OSRMClient{
-		baseURL: baseURL,
+		baseURL:     baseURL,
+		profileURLs: profileURLs,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
@@ -270,8 +308,25 @@ func (o *OSRMClient) GetRoute(ctx context.Context, req *ETARequest) (*RouteRespo
 	params := url.Values{}
 	params.Set("overview", "full")
 	params.Set("geometries", "polyline")
+	// Needs a dataset built with excludable classes; otherwise OSRM
+	// rejects the request and the next provider is tried
+	if exclude := req.exclusions("toll", "motorway"); len(exclude) > 0 {
+		params.Set("exclude", strings.Join(exclude, ","))
+	}
 
-	reqURL := fmt.Sprintf("%s/route/v1/driving/%s?%s", o.baseURL, coordinates, params.Encode())
+	baseURL := o.baseURL
+	if profileURL, ok := o.profileURLs[req.Profile]; ok {
+		baseURL = profileURL
+	} else if regionURL, ok, err := o.regionFor(req.OriginLat, req.OriginLng); err != nil {
+		return nil, err
+	} else if ok {
+		baseURL = regionURL
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("no OSRM dataset covers %f,%f", req.OriginLat, req.OriginLng)
+	}
+
+	reqURL := fmt.Sprintf("%s/route/v1/%s/%s?%s", baseURL, req.Profile.osrmProfile(), coordinates, params.Encode())
 
 	httpReq, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
 	if err != nil {
@@ -320,7 +375,9 @@ func (o *OSRMClient) GetRoute(ctx context.Context, req *ETARequest) (*RouteRespo
 
 // FallbackRoutingClient tries multiple routing providers in order
 type FallbackRoutingClient struct {
-	clients []RoutingClient
+	clients  []RoutingClient
+	counters []*providerCounters
+	osrm     *OSRMClient
 }
 
 // NewFallbackRoutingClient creates a client that tries providers in order
@@ -334,27 +391,71 @@ func NewFallbackRoutingClient() *FallbackRoutingClient {
 	if os.Getenv("MAPBOX_ACCESS_TOKEN") != "" {
 		clients = append(clients, NewMapboxClient(""))
 	}
-	if os.Getenv("OSRM_BASE_URL") != "" {
-		clients = append(clients, NewOSRMClient(""))
+	if os.Getenv("VALHALLA_URL") != "" {
+		clients = append(clients, NewValhallaClient(""))
+	}
+	var osrm *OSRMClient
+	if os.Getenv("OSRM_BASE_URL") != "" || os.Getenv("OSRM_REGIONS") != "" {
+		osrm = NewOSRMClient("")
+		if os.Getenv("OSRM_BASE_URL") == "" {
+			// Regional datasets only; don't send other origins to the demo server
+			osrm.baseURL = ""
+		}
+		clients = append(clients, osrm)
 	}
 
 	// Always add mock client as last fallback
 	clients = append(clients, &MockRoutingClient{})
 
+	counters := make([]*providerCounters, len(clients))
+	for i, client := range clients {
+		counters[i] = &providerCounters{stats: ProviderStats{Provider: providerName(client)}}
+	}
+
 	return &FallbackRoutingClient{
-		clients: clients,
+		clients:  clients,
+		counters: counters,
+		osrm:     osrm,
+	}
+}
+
+// OSRM returns the OSRM provider, or nil when OSRM is not configured
+func (f *FallbackRoutingClient) OSRM() *OSRMClient {
+	return f.osrm
+}
+
+// Stats returns call and failover counts for each provider in order
+func (f *FallbackRoutingClient) Stats() []ProviderStats {
+	stats := make([]ProviderStats, len(f.counters))
+	for i, counter := range f.counters {
+		stats[i] = counter.snapshot()
 	}
+	return stats
+}
+
+// HasProviders reports whether any real routing provider is configured,
+// rather than only the straight-line mock
+func (f *FallbackRoutingClient) HasProviders() bool {
+	return len(f.clients) > 1
 }
 
 // GetRoute tries each client until one succeeds
 func (f *FallbackRoutingClient) GetRoute(ctx context.Context, req *ETARequest) (*RouteResponse, error) {
 	var lastErr error
 
-	for _, client := range f.clients {
+	for i, client := range f.clients {
 		resp, err := client.GetRoute(ctx, req)
+		failover := err != nil && i < len(f.clients)-1
+		f.counters[i].record(err, failover)
 		if err == nil {
 			return resp, nil
 		}
+		if failover {
+			log.Warn().Err(err).
+				Str("provider", f.counters[i].stats.Provider).
+				Str("next", f.counters[i+1].stats.Provider).
+				Msg("Routing provider failed, failing over")
+		}
 		lastErr = err
 	}
 
@@ -367,6 +468,12 @@ func (f *FallbackRoutingClient) GetRoute(ctx context.Context, req *ETARequest) (
 
 // decodePolyline decodes a polyline5-encoded string
 func decodePolyline(encoded string) []LatLng {
+	return decodePolylinePrecision(encoded, 1e5)
+}
+
+// decodePolylinePrecision decodes a polyline scaled by factor, 1e5 for
+// Google and OSRM or 1e6 for Valhalla
+func decodePolylinePrecision(encoded string, factor float64) []LatLng {
 	var points []LatLng
 	index := 0
 	lat := 0
@@ -410,8 +517,8 @@ func decodePolyline(encoded string) []LatLng {
 		}
 
 		points = append(points, LatLng{
-			Lat: float64(lat) / 1e5,
-			Lng: float64(lng) / 1e5,
+			Lat: float64(lat) / factor,
+			Lng: float64(lng) / factor,
 		})
 	}
 
@@ -5,6 +5,7 @@ import (
 	"crypto/md5"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -29,6 +30,9 @@ type ETARequest struct {
 	DestLng       float64
 	DepartureTime time.Time
 	City          string // Optional: for city-specific traffic patterns
+	Profile       RoutingProfile // Optional: the vehicle routed for; cars when empty
+	AvoidTolls    bool           // Optional: route around toll roads and express lanes
+	AvoidHighways bool           // Optional: route around highways
 }
 
 type ETAResponse struct {
@@ -89,9 +93,12 @@ func (s *ETAService) GetETA(ctx context.Context, req *ETARequest) (*ETAResponse,
 		return nil, fmt.Errorf("routing service error: %w", err)
 	}
 
-	// Apply traffic adjustments using H3-based traffic service
+	// Apply traffic adjustments using H3-based traffic service. Bicycles
+	// filter through jams, so are taken at the router's word.
 	var trafficMultiplier float64
-	if req.City != "" {
+	if req.Profile == ProfileBicycle {
+		trafficMultiplier = 1.0
+	} else if req.City != "" {
 		// Use city-specific traffic patterns
 		trafficMultiplier = s.h3TrafficService.GetCityTrafficMultiplier(req.City, req.DepartureTime)
 	} else if len(route.Polyline) > 0 {
@@ -163,11 +170,12 @@ func (s *ETAService) RecordDriverLocation(lat, lng, speedKmh float64) error {
 
 func (s *ETAService) buildCacheKey(req *ETARequest) string {
 	// Round coordinates to 4 decimals (~11m precision) for better cache hits
-	key := fmt.Sprintf("eta:%.4f,%.4f:%.4f,%.4f:%d:%s",
+	key := fmt.Sprintf("eta:%.4f,%.4f:%.4f,%.4f:%d:%s:%s:%s",
 		req.OriginLat, req.OriginLng,
 		req.DestLat, req.DestLng,
 		req.DepartureTime.Unix()/60, // Round to minute
-		req.City)
+		req.City, req.Profile.orDefault(),
+		strings.Join(req.exclusions("tolls", "highways"), ","))
 	return fmt.Sprintf("%x", md5.Sum([]byte(key)))
 }
 
@@ -251,14 +259,18 @@ func (t *TrafficService) GetMultiplier(lat, lng float64, departureTime time.Time
 type MockRoutingClient struct{}
 
 func (m *MockRoutingClient) GetRoute(ctx context.Context, req *ETARequest) (*RouteResponse, error) {
-	// Simplified: use Haversine distance and assume 30 km/h average speed in city
+	// Simplified: use Haversine distance and assume 30 km/h average speed in
+	// city, 15 km/h by bicycle
 	distance := haversineDistance(req.OriginLat, req.OriginLng, req.DestLat, req.DestLng)
 	
 	// Distance in meters
 	distanceMeters := distance * 1000
 	
-	// Duration assuming 30 km/h average speed
-	durationSeconds := (distance / 30.0) * 3600
+	speedKmh := 30.0
+	if req.Profile == ProfileBicycle {
+		speedKmh = 15.0
+	}
+	durationSeconds := (distance / speedKmh) * 3600
 	duration := time.Duration(durationSeconds) * time.Second
 
 	return &RouteResponse{
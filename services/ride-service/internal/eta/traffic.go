@@ -17,8 +17,32 @@ import (
 
 // H3TrafficService provides real-time traffic data using H3 hexagonal cells
 type H3TrafficService struct {
-	redis *redis.Client
-	ctx   context.Context
+	redis     *redis.Client
+	ctx       context.Context
+	incidents IncidentZones
+	weather   WeatherConditions
+}
+
+// IncidentZones reports how much reported incidents (closures, floods,
+// protests) slow travel at a point; 1 where there are none
+type IncidentZones interface {
+	DelayAt(lat, lng float64) float64
+}
+
+// SetIncidents slows traffic multipliers inside reported incident zones
+func (t *H3TrafficService) SetIncidents(incidents IncidentZones) {
+	t.incidents = incidents
+}
+
+// WeatherConditions reports how much the current weather slows travel at
+// a point; 1 in clear weather
+type WeatherConditions interface {
+	TrafficMultiplierAt(lat, lng float64) float64
+}
+
+// SetWeather slows traffic multipliers in heavy rain and severe weather
+func (t *H3TrafficService) SetWeather(weather WeatherConditions) {
+	t.weather = weather
 }
 
 // NewH3TrafficService creates a new H3-based traffic service
@@ -39,8 +63,21 @@ type TrafficCellData struct {
 	LastUpdated  time.Time `json:"last_updated"`
 }
 
-// GetTrafficMultiplier returns the traffic multiplier for a given location
+// GetTrafficMultiplier returns the traffic multiplier for a given location,
+// including any reported incident and the weather there
 func (t *H3TrafficService) GetTrafficMultiplier(lat, lng float64, departureTime time.Time) float64 {
+	multiplier := t.cellTrafficMultiplier(lat, lng, departureTime)
+	if t.incidents != nil {
+		multiplier *= t.incidents.DelayAt(lat, lng)
+	}
+	if t.weather != nil {
+		multiplier *= t.weather.TrafficMultiplierAt(lat, lng)
+	}
+	return multiplier
+}
+
+// cellTrafficMultiplier returns the multiplier from the cell's measured speeds
+func (t *H3TrafficService) cellTrafficMultiplier(lat, lng float64, departureTime time.Time) float64 {
 	// Get H3 cell at resolution 7 (about 5km average edge length, good for traffic)
 	cell := h3.LatLngToCell(h3.LatLng{Lat: lat, Lng: lng}, 7)
 
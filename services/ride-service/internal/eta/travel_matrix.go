@@ -0,0 +1,284 @@
+package eta
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+	"github.com/uber/h3-go/v4"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// =============================================================================
+// H3 CELL-TO-CELL TRAVEL TIME MATRIX
+// =============================================================================
+
+const (
+	// TravelMatrixResolution is the H3 resolution used for matrix cells
+	// (resolution 8, about 460m edge - fine enough for pickup ETAs while
+	// keeping the pair count per city manageable)
+	TravelMatrixResolution = 8
+
+	// Redis key patterns
+	travelMatrixCellKey = "travel_matrix:%s:cell:%s" // hash per city and origin cell: destination cell -> median seconds
+	travelMatrixCityKey = "travel_matrix:city:%s"    // hash: build stats for a city
+
+	// Matrix entries outlive a couple of missed rebuilds before expiring, so
+	// origin cells a rebuild no longer has trips for drop out
+	travelMatrixTTL = 72 * time.Hour
+
+	// Minimum trips per cell pair before a median is trusted
+	defaultTravelMatrixMinSamples = 3
+
+	// Trips shorter/longer than this are treated as bad data
+	minTripSeconds = 60
+	maxTripSeconds = 4 * 60 * 60
+)
+
+// TravelMatrix serves median cell-to-cell travel times from Redis, per
+// city. It is a cheap approximation of pickup ETAs for ranking matching
+// candidates and quoting riders that avoids calls to external routing APIs.
+// Surge and demand forecasting do not read it: surge is priced on live
+// supply and demand, and forecasting has yet to be built.
+type TravelMatrix struct {
+	redis      *redis.Client
+	cityOf     CityResolver
+	minSamples int
+}
+
+// NewTravelMatrix creates a new travel time matrix backed by Redis. cityOf
+// places trips and lookups in the city whose matrix they belong to.
+func NewTravelMatrix(redisClient *redis.Client, cityOf CityResolver) *TravelMatrix {
+	return &TravelMatrix{
+		redis:      redisClient,
+		cityOf:     cityOf,
+		minSamples: defaultTravelMatrixMinSamples,
+	}
+}
+
+// TravelMatrixStats describes the last build of a city's matrix
+type TravelMatrixStats struct {
+	City        string    `json:"city"`
+	Trips       int       `json:"trips"`
+	OriginCells int       `json:"origin_cells"`
+	CellPairs   int       `json:"cell_pairs"`
+	BuiltAt     time.Time `json:"built_at"`
+}
+
+// TravelMatrixCell converts a coordinate to its matrix cell ID
+func TravelMatrixCell(lat, lng float64) string {
+	return h3.LatLngToCell(h3.LatLng{Lat: lat, Lng: lng}, TravelMatrixResolution).String()
+}
+
+// BuildTravelMatrix computes median trip durations (seconds) between H3 cells
+// from completed car rides; bikes take other streets at other speeds. Pairs
+// with fewer than minSamples trips are dropped.
+func BuildTravelMatrix(rides []*domain.Ride, minSamples int) map[string]map[string]int64 {
+	samples := make(map[string]map[string][]int64)
+
+	for _, ride := range rides {
+		if ride.StartedAt == nil || ride.CompletedAt == nil || ProfileForRideType(ride.Type) != ProfileCar {
+			continue
+		}
+
+		duration := int64(ride.CompletedAt.Sub(*ride.StartedAt).Seconds())
+		if duration < minTripSeconds || duration > maxTripSeconds {
+			continue
+		}
+
+		origin := TravelMatrixCell(ride.PickupLocation.Latitude, ride.PickupLocation.Longitude)
+		dest := TravelMatrixCell(ride.DropoffLocation.Latitude, ride.DropoffLocation.Longitude)
+
+		if samples[origin] == nil {
+			samples[origin] = make(map[string][]int64)
+		}
+		samples[origin][dest] = append(samples[origin][dest], duration)
+	}
+
+	matrix := make(map[string]map[string]int64)
+	for origin, dests := range samples {
+		for dest, durations := range dests {
+			if len(durations) < minSamples {
+				continue
+			}
+			if matrix[origin] == nil {
+				matrix[origin] = make(map[string]int64)
+			}
+			matrix[origin][dest] = median(durations)
+		}
+	}
+
+	return matrix
+}
+
+// Rebuild recomputes a city's matrix from completed rides and stores it.
+// Each origin cell's pairs are replaced, so pairs that no longer have enough
+// trips are dropped rather than served stale.
+func (m *TravelMatrix) Rebuild(ctx context.Context, city string, rides []*domain.Ride) (*TravelMatrixStats, error) {
+	matrix := BuildTravelMatrix(rides, m.minSamples)
+
+	stats := &TravelMatrixStats{
+		City:        city,
+		Trips:       len(rides),
+		OriginCells: len(matrix),
+		BuiltAt:     time.Now().UTC(),
+	}
+
+	pipe := m.redis.TxPipeline()
+	for origin, dests := range matrix {
+		key := fmt.Sprintf(travelMatrixCellKey, city, origin)
+		values := make(map[string]interface{}, len(dests))
+		for dest, seconds := range dests {
+			values[dest] = seconds
+		}
+		stats.CellPairs += len(dests)
+
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, values)
+		pipe.Expire(ctx, key, travelMatrixTTL)
+	}
+
+	cityKey := fmt.Sprintf(travelMatrixCityKey, city)
+	pipe.HSet(ctx, cityKey, map[string]interface{}{
+		"trips":        stats.Trips,
+		"origin_cells": stats.OriginCells,
+		"cell_pairs":   stats.CellPairs,
+		"built_at":     stats.BuiltAt.Unix(),
+	})
+	pipe.Expire(ctx, cityKey, travelMatrixTTL)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to store travel matrix: %w", err)
+	}
+
+	return stats, nil
+}
+
+// Lookup returns the median travel time in seconds between two coordinates,
+// from the matrix of the origin's city. The boolean is false when the origin
+// is outside every city or the matrix has no entry for the cell pair.
+func (m *TravelMatrix) Lookup(ctx context.Context, originLat, originLng, destLat, destLng float64) (int64, bool) {
+	city, ok := m.cityOf(originLat, originLng)
+	if !ok {
+		return 0, false
+	}
+	origin := TravelMatrixCell(originLat, originLng)
+	dest := TravelMatrixCell(destLat, destLng)
+
+	val, err := m.redis.HGet(ctx, fmt.Sprintf(travelMatrixCellKey, city, origin), dest).Result()
+	if err != nil {
+		if err != redis.Nil {
+			log.Warn().Err(err).Str("city", city).Str("origin", origin).Msg("Travel matrix lookup failed")
+		}
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return seconds, true
+}
+
+// GetStats returns build stats for a city's matrix, or nil if never built
+func (m *TravelMatrix) GetStats(ctx context.Context, city string) (*TravelMatrixStats, error) {
+	vals, err := m.redis.HGetAll(ctx, fmt.Sprintf(travelMatrixCityKey, city)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) == 0 {
+		return nil, nil
+	}
+
+	stats := &TravelMatrixStats{City: city}
+	stats.Trips, _ = strconv.Atoi(vals["trips"])
+	stats.OriginCells, _ = strconv.Atoi(vals["origin_cells"])
+	stats.CellPairs, _ = strconv.Atoi(vals["cell_pairs"])
+	if builtAt, err := strconv.ParseInt(vals["built_at"], 10, 64); err == nil {
+		stats.BuiltAt = time.Unix(builtAt, 0).UTC()
+	}
+
+	return stats, nil
+}
+
+// median returns the median of a set of durations
+func median(values []int64) int64 {
+	sorted := make([]int64, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// =============================================================================
+// OFFLINE REBUILD JOB
+// =============================================================================
+
+// CompletedRideSource provides historical trips for matrix builds
+type CompletedRideSource interface {
+	GetCompletedRidesSince(ctx context.Context, since time.Time, limit int) ([]*domain.Ride, error)
+}
+
+// CityResolver maps a coordinate to a city name; ok is false outside service areas
+type CityResolver func(lat, lng float64) (city string, ok bool)
+
+// TravelMatrixJob periodically rebuilds per-city matrices from recent trips
+type TravelMatrixJob struct {
+	matrix   *TravelMatrix
+	source   CompletedRideSource
+	lookback time.Duration
+	maxTrips int
+}
+
+// NewTravelMatrixJob creates a rebuild job over the last 28 days of trips,
+// grouped into cities by the matrix's city resolver
+func NewTravelMatrixJob(matrix *TravelMatrix, source CompletedRideSource) *TravelMatrixJob {
+	return &TravelMatrixJob{
+		matrix:   matrix,
+		source:   source,
+		lookback: 28 * 24 * time.Hour,
+		maxTrips: 200000,
+	}
+}
+
+// Run rebuilds all city matrices once
+func (j *TravelMatrixJob) Run(ctx context.Context) error {
+	rides, err := j.source.GetCompletedRidesSince(ctx, time.Now().Add(-j.lookback), j.maxTrips)
+	if err != nil {
+		return fmt.Errorf("failed to load completed rides: %w", err)
+	}
+
+	byCity := make(map[string][]*domain.Ride)
+	for _, ride := range rides {
+		city, ok := j.matrix.cityOf(ride.PickupLocation.Latitude, ride.PickupLocation.Longitude)
+		if !ok {
+			continue
+		}
+		byCity[city] = append(byCity[city], ride)
+	}
+
+	for city, cityRides := range byCity {
+		stats, err := j.matrix.Rebuild(ctx, city, cityRides)
+		if err != nil {
+			log.Error().Err(err).Str("city", city).Msg("Failed to rebuild travel matrix")
+			continue
+		}
+
+		log.Info().
+			Str("city", city).
+			Int("trips", stats.Trips).
+			Int("cell_pairs", stats.CellPairs).
+			Msg("Travel matrix rebuilt")
+	}
+
+	return nil
+}
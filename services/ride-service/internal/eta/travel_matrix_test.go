@@ -0,0 +1,105 @@
+package eta
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int64
+		want   int64
+	}{
+		{"single", []int64{300}, 300},
+		{"odd", []int64{500, 100, 300}, 300},
+		{"even", []int64{400, 100, 300, 200}, 250},
+		{"repeated", []int64{120, 120, 900}, 120},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := median(tt.values); got != tt.want {
+				t.Errorf("median(%v) = %d, want %d", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMedianLeavesInputUnsorted(t *testing.T) {
+	values := []int64{500, 100, 300}
+	median(values)
+	if values[0] != 500 || values[1] != 100 || values[2] != 300 {
+		t.Errorf("median() reordered its input to %v", values)
+	}
+}
+
+// Two points in Nairobi a few km apart, in different matrix cells
+var (
+	westlands = domain.Location{Latitude: -1.2676, Longitude: 36.8108}
+	cbd       = domain.Location{Latitude: -1.2864, Longitude: 36.8172}
+)
+
+func matrixRide(rideType domain.RideType, from, to domain.Location, duration time.Duration) *domain.Ride {
+	started := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	completed := started.Add(duration)
+	return &domain.Ride{
+		Type:            rideType,
+		PickupLocation:  from,
+		DropoffLocation: to,
+		StartedAt:       &started,
+		CompletedAt:     &completed,
+	}
+}
+
+func TestBuildTravelMatrix(t *testing.T) {
+	origin := TravelMatrixCell(westlands.Latitude, westlands.Longitude)
+	dest := TravelMatrixCell(cbd.Latitude, cbd.Longitude)
+	if origin == dest {
+		t.Fatal("test points share a matrix cell")
+	}
+
+	rides := []*domain.Ride{
+		matrixRide(domain.RideTypeStandard, westlands, cbd, 10*time.Minute),
+		matrixRide(domain.RideTypeStandard, westlands, cbd, 14*time.Minute),
+		matrixRide(domain.RideTypeXL, westlands, cbd, 12*time.Minute),
+		// Bodas take other streets at other speeds
+		matrixRide(domain.RideTypeBoda, westlands, cbd, 4*time.Minute),
+		// Too short or too long to be real trips
+		matrixRide(domain.RideTypeStandard, westlands, cbd, 30*time.Second),
+		matrixRide(domain.RideTypeStandard, westlands, cbd, 5*time.Hour),
+		// Never started
+		{Type: domain.RideTypeStandard, PickupLocation: westlands, DropoffLocation: cbd},
+		// Only two trips the other way
+		matrixRide(domain.RideTypeStandard, cbd, westlands, 20*time.Minute),
+		matrixRide(domain.RideTypeStandard, cbd, westlands, 22*time.Minute),
+	}
+
+	matrix := BuildTravelMatrix(rides, 3)
+
+	if got, ok := matrix[origin][dest]; !ok || got != 720 {
+		t.Errorf("westlands to cbd = %d (present %v), want the 720s median of car trips", got, ok)
+	}
+	if _, ok := matrix[dest][origin]; ok {
+		t.Error("cbd to westlands kept with fewer trips than the minimum")
+	}
+	if len(matrix) != 1 {
+		t.Errorf("origin cells = %d, want 1", len(matrix))
+	}
+
+	if loose := BuildTravelMatrix(rides, 2); loose[dest][origin] != 1260 {
+		t.Errorf("cbd to westlands with 2 samples = %d, want 1260", loose[dest][origin])
+	}
+}
+
+func TestTravelMatrixLookupOutsideCities(t *testing.T) {
+	// Matrices are kept per city, so a lookup from outside every city has
+	// none to read and never reaches Redis
+	matrix := NewTravelMatrix(nil, func(lat, lng float64) (string, bool) { return "", false })
+	if seconds, ok := matrix.Lookup(context.Background(), westlands.Latitude, westlands.Longitude, cbd.Latitude, cbd.Longitude); ok {
+		t.Errorf("Lookup() outside every city = %ds, want no entry", seconds)
+	}
+}
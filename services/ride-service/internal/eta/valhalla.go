@@ -0,0 +1,279 @@
+package eta
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// VALHALLA ROUTING CLIENT (Self-hosted)
+// =============================================================================
+
+// ValhallaClient implements routing and isochrones using Valhalla. Unlike
+// OSRM, one Valhalla instance serves every vehicle profile.
+type ValhallaClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewValhallaClient creates a new Valhalla client, reading VALHALLA_URL
+// when no base URL is given
+func NewValhallaClient(baseURL string) *ValhallaClient {
+	if baseURL == "" {
+		baseURL = os.Getenv("VALHALLA_URL")
+	}
+
+	return &ValhallaClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+type valhallaLocation struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type valhallaDateTime struct {
+	Type  int    `json:"type"` // 1 = depart at
+	Value string `json:"value"`
+}
+
+type valhallaRouteRequest struct {
+	Locations      []valhallaLocation            `json:"locations"`
+	Costing        string                        `json:"costing"`
+	CostingOptions map[string]map[string]float64 `json:"costing_options,omitempty"`
+	DateTime       *valhallaDateTime             `json:"date_time,omitempty"`
+	Units          string                        `json:"units"`
+}
+
+type valhallaRouteResponse struct {
+	Trip struct {
+		Status        int    `json:"status"`
+		StatusMessage string `json:"status_message"`
+		Summary       struct {
+			Length float64 `json:"length"` // kilometers
+			Time   float64 `json:"time"`   // seconds
+		} `json:"summary"`
+		Legs []struct {
+			Shape string `json:"shape"` // polyline6 encoded
+		} `json:"legs"`
+	} `json:"trip"`
+}
+
+type valhallaError struct {
+	ErrorCode int    `json:"error_code"`
+	Error     string `json:"error"`
+}
+
+// GetRoute gets route from Valhalla
+func (v *ValhallaClient) GetRoute(ctx context.Context, req *ETARequest) (*RouteResponse, error) {
+	body := valhallaRouteRequest{
+		Locations: []valhallaLocation{
+			{Lat: req.OriginLat, Lon: req.OriginLng},
+			{Lat: req.DestLat, Lon: req.DestLng},
+		},
+		Costing: req.Profile.valhallaCosting(),
+		Units:   "kilometers",
+	}
+	// Valhalla weighs tolls and highways from 0 (avoid) to 1 (prefer)
+	if req.AvoidTolls || req.AvoidHighways {
+		options := map[string]float64{}
+		if req.AvoidTolls {
+			options["use_tolls"] = 0
+		}
+		if req.AvoidHighways {
+			options["use_highways"] = 0
+		}
+		body.CostingOptions = map[string]map[string]float64{body.Costing: options}
+	}
+	if !req.DepartureTime.IsZero() {
+		body.DateTime = &valhallaDateTime{Type: 1, Value: req.DepartureTime.Format("2006-01-02T15:04")}
+	}
+
+	var routeResp valhallaRouteResponse
+	if err := v.post(ctx, "/route", body, &routeResp); err != nil {
+		return nil, err
+	}
+
+	if routeResp.Trip.Status != 0 {
+		return nil, fmt.Errorf("Valhalla error: %d - %s", routeResp.Trip.Status, routeResp.Trip.StatusMessage)
+	}
+
+	var polyline []LatLng
+	for _, leg := range routeResp.Trip.Legs {
+		polyline = append(polyline, decodePolylinePrecision(leg.Shape, 1e6)...)
+	}
+
+	return &RouteResponse{
+		Duration: time.Duration(routeResp.Trip.Summary.Time) * time.Second,
+		Distance: routeResp.Trip.Summary.Length * 1000,
+		Polyline: polyline,
+	}, nil
+}
+
+// =============================================================================
+// ISOCHRONES
+// =============================================================================
+
+// Isochrone is the area reachable within a travel time. Reverse isochrones
+// hold the points that can reach the center instead.
+type Isochrone struct {
+	Minutes int          `json:"minutes"`
+	Polygon [][2]float64 `json:"polygon"` // [lat, lng] vertices of the outer ring
+}
+
+// Contains reports whether a point lies in the isochrone
+func (i *Isochrone) Contains(lat, lng float64) bool {
+	inside := false
+	j := len(i.Polygon) - 1
+	for k := range i.Polygon {
+		latK, lngK := i.Polygon[k][0], i.Polygon[k][1]
+		latJ, lngJ := i.Polygon[j][0], i.Polygon[j][1]
+		if (latK > lat) != (latJ > lat) &&
+			lng < (lngJ-lngK)*(lat-latK)/(latJ-latK)+lngK {
+			inside = !inside
+		}
+		j = k
+	}
+	return inside
+}
+
+// IsochroneRequest asks for the areas reachable from a point within each
+// of the given travel times
+type IsochroneRequest struct {
+	Lat     float64
+	Lng     float64
+	Minutes []int
+	Profile RoutingProfile
+	Reverse bool // travel towards the point, as drivers heading to a pickup do
+}
+
+type valhallaContour struct {
+	Time int `json:"time"`
+}
+
+type valhallaIsochroneRequest struct {
+	Locations []valhallaLocation `json:"locations"`
+	Costing   string             `json:"costing"`
+	Contours  []valhallaContour  `json:"contours"`
+	Polygons  bool               `json:"polygons"`
+	Reverse   bool               `json:"reverse,omitempty"`
+}
+
+type valhallaIsochroneResponse struct {
+	Features []struct {
+		Properties struct {
+			Contour int `json:"contour"`
+		} `json:"properties"`
+		Geometry struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+	} `json:"features"`
+}
+
+// Isochrones returns one isochrone per requested travel time, smallest
+// first, following the road network for the profile
+func (v *ValhallaClient) Isochrones(ctx context.Context, req *IsochroneRequest) ([]*Isochrone, error) {
+	if len(req.Minutes) == 0 {
+		return nil, fmt.Errorf("at least one contour time is required")
+	}
+
+	body := valhallaIsochroneRequest{
+		Locations: []valhallaLocation{{Lat: req.Lat, Lon: req.Lng}},
+		Costing:   req.Profile.valhallaCosting(),
+		Polygons:  true,
+		Reverse:   req.Reverse,
+	}
+	for _, minutes := range req.Minutes {
+		body.Contours = append(body.Contours, valhallaContour{Time: minutes})
+	}
+
+	var isoResp valhallaIsochroneResponse
+	if err := v.post(ctx, "/isochrone", body, &isoResp); err != nil {
+		return nil, err
+	}
+
+	isochrones := make([]*Isochrone, 0, len(isoResp.Features))
+	for _, feature := range isoResp.Features {
+		if feature.Geometry.Type != "Polygon" {
+			continue
+		}
+
+		// GeoJSON rings are [lng, lat]; the first ring is the outer boundary
+		var rings [][][2]float64
+		if err := json.Unmarshal(feature.Geometry.Coordinates, &rings); err != nil {
+			return nil, fmt.Errorf("failed to parse isochrone polygon: %w", err)
+		}
+		if len(rings) == 0 {
+			continue
+		}
+
+		polygon := make([][2]float64, len(rings[0]))
+		for i, point := range rings[0] {
+			polygon[i] = [2]float64{point[1], point[0]}
+		}
+		isochrones = append(isochrones, &Isochrone{
+			Minutes: feature.Properties.Contour,
+			Polygon: polygon,
+		})
+	}
+
+	if len(isochrones) == 0 {
+		return nil, fmt.Errorf("no isochrones returned")
+	}
+
+	// Valhalla returns the largest contour first
+	sort.Slice(isochrones, func(i, j int) bool { return isochrones[i].Minutes < isochrones[j].Minutes })
+
+	return isochrones, nil
+}
+
+// post sends a JSON request to a Valhalla action and decodes the response
+func (v *ValhallaClient) post(ctx context.Context, action string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", v.baseURL+action, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to call Valhalla: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var valErr valhallaError
+		if json.Unmarshal(respBody, &valErr) == nil && valErr.Error != "" {
+			return fmt.Errorf("Valhalla error: %d - %s", valErr.ErrorCode, valErr.Error)
+		}
+		return fmt.Errorf("Valhalla returned status %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -156,6 +156,9 @@ func EstimateETA(distanceM float64, vehicleType string) int64 {
 		"car":       10.0,  // ~36 km/h (accounting for traffic)
 		"suv":       10.0,
 		"premium":   10.0,
+		"motorcycle": 8.0,  // routing profiles, see eta.RoutingProfile
+		"bicycle":   4.5,   // ~16 km/h
+		"truck":     8.0,
 		"default":   10.0,
 	}
 	
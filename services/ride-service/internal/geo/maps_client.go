@@ -178,6 +178,7 @@ type GeocodeResult struct {
 	Geometry         GeocodeGeometry   `json:"geometry"`
 	AddressComponents []AddressComponent `json:"address_components"`
 	Types            []string          `json:"types"`
+	PartialMatch     bool              `json:"partial_match,omitempty"`
 }
 
 // GeocodeGeometry contains location data
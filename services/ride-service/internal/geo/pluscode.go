@@ -0,0 +1,219 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Open Location Code (plus code) constants. A full code is ten digits in
+// pairs of latitude and longitude, the first eight before a "+", then an
+// optional grid digit; codes are hierarchical, so dropping digits gives
+// the enclosing area.
+const (
+	plusCodeAlphabet   = "23456789CFGHJMPQRVWX"
+	plusCodeSeparator  = "+"
+	plusCodePadding    = '0'
+	plusCodeSepIndex   = 8
+	plusCodePairDigits = 10
+	plusCodeGridRows   = 5
+	plusCodeGridCols   = 4
+
+	// PlusCodeLength is the digits in the plus codes we hand out, about a
+	// 3m by 3m area
+	PlusCodeLength = 11
+)
+
+// PlusCodeArea is the area a plus code covers
+type PlusCodeArea struct {
+	LatLo float64
+	LngLo float64
+	LatHi float64
+	LngHi float64
+}
+
+// Center is the middle of the area, where a plus code is treated as being
+func (a *PlusCodeArea) Center() (float64, float64) {
+	return (a.LatLo + a.LatHi) / 2, (a.LngLo + a.LngHi) / 2
+}
+
+// EncodePlusCode gives the plus code of a location at PlusCodeLength digits
+func EncodePlusCode(lat, lng float64) string {
+	return encodePlusCode(lat, lng, PlusCodeLength)
+}
+
+// encodePlusCode gives the plus code of a location with length digits,
+// which must be even up to ten, or eleven
+func encodePlusCode(lat, lng float64, length int) string {
+	lat = math.Max(-90, math.Min(90, lat))
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+
+	// Work in grid cells so every digit comes from integer division
+	latCells := int64(180 * 8000 * plusCodeGridRows)
+	latVal := int64(math.Floor((lat + 90) * 8000 * plusCodeGridRows))
+	lngVal := int64(math.Floor(lng * 8000 * plusCodeGridCols))
+	if latVal >= latCells {
+		latVal = latCells - 1
+	}
+
+	digits := make([]byte, plusCodePairDigits+1)
+	digits[plusCodePairDigits] = plusCodeAlphabet[(latVal%plusCodeGridRows)*plusCodeGridCols+lngVal%plusCodeGridCols]
+	latVal /= plusCodeGridRows
+	lngVal /= plusCodeGridCols
+	for i := plusCodePairDigits/2 - 1; i >= 0; i-- {
+		digits[2*i] = plusCodeAlphabet[latVal%20]
+		digits[2*i+1] = plusCodeAlphabet[lngVal%20]
+		latVal /= 20
+		lngVal /= 20
+	}
+
+	code := string(digits[:length])
+	if len(code) < plusCodeSepIndex {
+		code += strings.Repeat(string(plusCodePadding), plusCodeSepIndex-len(code))
+	}
+	return code[:plusCodeSepIndex] + plusCodeSeparator + code[plusCodeSepIndex:]
+}
+
+// DecodePlusCode gives the area a full plus code covers
+func DecodePlusCode(code string) (*PlusCodeArea, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !IsFullPlusCode(code) {
+		return nil, fmt.Errorf("%q is not a full plus code", code)
+	}
+
+	digits := strings.TrimRight(strings.Replace(code, plusCodeSeparator, "", 1), string(plusCodePadding))
+	area := &PlusCodeArea{LatLo: -90, LngLo: -180}
+
+	latRes, lngRes := 400.0, 400.0
+	for i := 0; i < len(digits); i++ {
+		value := float64(strings.IndexByte(plusCodeAlphabet, digits[i]))
+		switch {
+		case i < plusCodePairDigits && i%2 == 0:
+			latRes /= 20
+			area.LatLo += value * latRes
+		case i < plusCodePairDigits:
+			lngRes /= 20
+			area.LngLo += value * lngRes
+		default:
+			latRes /= plusCodeGridRows
+			lngRes /= plusCodeGridCols
+			area.LatLo += math.Floor(value/plusCodeGridCols) * latRes
+			area.LngLo += math.Mod(value, plusCodeGridCols) * lngRes
+		}
+	}
+
+	area.LatHi = math.Min(area.LatLo+latRes, 90)
+	area.LngHi = area.LngLo + lngRes
+	return area, nil
+}
+
+// RecoverPlusCode turns a short plus code, such as "PR6C+24", into the
+// full code nearest a reference location. Full codes are returned as-is.
+func RecoverPlusCode(code string, refLat, refLng float64) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if IsFullPlusCode(code) {
+		return code, nil
+	}
+	if !IsShortPlusCode(code) {
+		return "", fmt.Errorf("%q is not a plus code", code)
+	}
+
+	// Borrow the missing leading digits from the reference, then move a
+	// whole cell if that lands further than half a cell away
+	missing := plusCodeSepIndex - strings.Index(code, plusCodeSeparator)
+	resolution := math.Pow(20, float64(2-missing/2))
+	full := encodePlusCode(refLat, refLng, plusCodePairDigits)[:missing] + code
+
+	area, err := DecodePlusCode(full)
+	if err != nil {
+		return "", err
+	}
+	lat, lng := area.Center()
+
+	switch {
+	case refLat+resolution/2 < lat && lat-resolution >= -90:
+		lat -= resolution
+	case refLat-resolution/2 > lat && lat+resolution <= 90:
+		lat += resolution
+	}
+	switch {
+	case refLng+resolution/2 < lng:
+		lng -= resolution
+	case refLng-resolution/2 > lng:
+		lng += resolution
+	}
+
+	return encodePlusCode(lat, lng, len(full)-1), nil
+}
+
+// IsPlusCode reports whether text is a full or short plus code
+func IsPlusCode(code string) bool {
+	return IsFullPlusCode(code) || IsShortPlusCode(code)
+}
+
+// IsFullPlusCode reports whether a code can be decoded on its own
+func IsFullPlusCode(code string) bool {
+	code = strings.ToUpper(code)
+	if !validPlusCode(code) || strings.Index(code, plusCodeSeparator) != plusCodeSepIndex {
+		return false
+	}
+	// The first pair must fall within the world
+	return strings.IndexByte(plusCodeAlphabet, code[0]) < 9 && strings.IndexByte(plusCodeAlphabet, code[1]) < 18
+}
+
+// IsShortPlusCode reports whether a code has had leading digits dropped and
+// needs a reference location to decode
+func IsShortPlusCode(code string) bool {
+	code = strings.ToUpper(code)
+	sep := strings.Index(code, plusCodeSeparator)
+	return validPlusCode(code) && sep >= 0 && sep < plusCodeSepIndex && !strings.ContainsRune(code, plusCodePadding)
+}
+
+// validPlusCode checks a code's shape: one separator at an even position no
+// later than the eighth, padding only as a run of pairs just before it,
+// and never a single digit after it
+func validPlusCode(code string) bool {
+	sep := strings.Index(code, plusCodeSeparator)
+	if sep < 0 || sep != strings.LastIndex(code, plusCodeSeparator) || sep > plusCodeSepIndex || sep%2 == 1 {
+		return false
+	}
+	if len(code)-sep-1 == 1 {
+		return false
+	}
+
+	if pad := strings.IndexRune(code, plusCodePadding); pad >= 0 {
+		if pad == 0 || pad%2 == 1 || sep != plusCodeSepIndex || len(code) != sep+1 {
+			return false
+		}
+		if strings.Trim(code[pad:sep], string(plusCodePadding)) != "" {
+			return false
+		}
+	}
+
+	for i := 0; i < len(code); i++ {
+		if i == sep || (code[i] == plusCodePadding && i < sep) {
+			continue
+		}
+		if strings.IndexByte(plusCodeAlphabet, code[i]) < 0 {
+			return false
+		}
+	}
+	return sep > 0 || len(code) > 1
+}
+
+// FindPlusCode picks a plus code out of written text, returning it with
+// the rest of the text, which for short codes names the locality they are
+// relative to, as in "PR6C+24 Nairobi"
+func FindPlusCode(text string) (code, locality string, ok bool) {
+	fields := strings.Fields(strings.ReplaceAll(text, ",", " "))
+	for i, field := range fields {
+		if IsPlusCode(field) {
+			rest := append(append([]string{}, fields[:i]...), fields[i+1:]...)
+			return strings.ToUpper(field), strings.Join(rest, " "), true
+		}
+	}
+	return "", "", false
+}
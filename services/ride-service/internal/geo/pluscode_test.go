@@ -0,0 +1,70 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEncodePlusCode(t *testing.T) {
+	if got := encodePlusCode(47.365590, 8.524997, 10); got != "8FVC9G8F+6X" {
+		t.Errorf("encodePlusCode = %q, want 8FVC9G8F+6X", got)
+	}
+	if got := encodePlusCode(47.365590, 8.524997, 4); got != "8FVC0000+" {
+		t.Errorf("encodePlusCode at 4 digits = %q, want 8FVC0000+", got)
+	}
+}
+
+func TestDecodePlusCodeRoundTrip(t *testing.T) {
+	points := [][2]float64{{6.4541, 3.3947}, {-1.2921, 36.8219}, {-33.9249, 18.4241}, {90, 179.99999}}
+	for _, p := range points {
+		code := EncodePlusCode(p[0], p[1])
+		area, err := DecodePlusCode(code)
+		if err != nil {
+			t.Fatalf("DecodePlusCode(%q): %v", code, err)
+		}
+		lat, lng := area.Center()
+		if math.Abs(lat-p[0]) > 0.0001 || math.Abs(lng-p[1]) > 0.0001 {
+			t.Errorf("%v encoded to %q which decodes to %v,%v", p, code, lat, lng)
+		}
+	}
+
+	if _, err := DecodePlusCode("PR6C+24"); err == nil {
+		t.Error("short codes should not decode without a reference")
+	}
+}
+
+func TestRecoverPlusCode(t *testing.T) {
+	got, err := RecoverPlusCode("9G8F+6X", 47.4, 8.6)
+	if err != nil || got != "8FVC9G8F+6X" {
+		t.Errorf("RecoverPlusCode = %q, %v; want 8FVC9G8F+6X", got, err)
+	}
+}
+
+func TestPlusCodeValidity(t *testing.T) {
+	cases := map[string]bool{
+		"8FVC9G8F+6X": true,
+		"8fvc9g8f+6x": true,
+		"8FVC0000+":   true,
+		"9G8F+6X":     true,
+		"8FVC9G8F+6":  false, // a single digit after the separator
+		"8FV+C9G8F6X": false,
+		"8FVC00+":     false,
+		"GTBANK":      false,
+		"F2VC9G8F+6X": false, // latitude past the pole
+	}
+	for code, want := range cases {
+		if got := IsPlusCode(code); got != want {
+			t.Errorf("IsPlusCode(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestFindPlusCode(t *testing.T) {
+	code, locality, ok := FindPlusCode("PR6C+24, Nairobi")
+	if !ok || code != "PR6C+24" || locality != "Nairobi" {
+		t.Errorf("FindPlusCode = %q, %q, %v", code, locality, ok)
+	}
+	if _, _, ok := FindPlusCode("12 Allen Avenue, Ikeja"); ok {
+		t.Error("ordinary addresses have no plus code")
+	}
+}
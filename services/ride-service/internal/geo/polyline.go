@@ -0,0 +1,82 @@
+package geo
+
+import "math"
+
+// PolylineDecode decodes a polyline5 string, as PolylineEncode writes and
+// Google Directions returns
+func PolylineDecode(encoded string) []Coordinate {
+	var coords []Coordinate
+	index, lat, lng := 0, 0, 0
+
+	next := func() (int, bool) {
+		result, shift := 0, uint(0)
+		for {
+			if index >= len(encoded) {
+				return 0, false
+			}
+			b := int(encoded[index]) - 63
+			index++
+			result |= (b & 0x1f) << shift
+			shift += 5
+			if b < 0x20 {
+				break
+			}
+		}
+		if result&1 != 0 {
+			return ^(result >> 1), true
+		}
+		return result >> 1, true
+	}
+
+	for index < len(encoded) {
+		dLat, ok := next()
+		if !ok {
+			break
+		}
+		dLng, ok := next()
+		if !ok {
+			break
+		}
+		lat += dLat
+		lng += dLng
+		coords = append(coords, Coordinate{Lat: float64(lat) / 1e5, Lng: float64(lng) / 1e5})
+	}
+
+	return coords
+}
+
+// DistanceToPolyline returns the distance in meters from a point to the
+// nearest segment of a path. Segments are short enough to treat as flat.
+func DistanceToPolyline(lat, lng float64, path []Coordinate) float64 {
+	if len(path) == 0 {
+		return math.Inf(1)
+	}
+	if len(path) == 1 {
+		return HaversineDistance(lat, lng, path[0].Lat, path[0].Lng)
+	}
+
+	// Project onto a local plane in meters around the point
+	const metersPerDegree = 111320.0
+	scaleLng := math.Cos(lat*math.Pi/180) * metersPerDegree
+	project := func(c Coordinate) (float64, float64) {
+		return (c.Lng - lng) * scaleLng, (c.Lat - lat) * metersPerDegree
+	}
+
+	best := math.Inf(1)
+	ax, ay := project(path[0])
+	for _, c := range path[1:] {
+		bx, by := project(c)
+		dx, dy := bx-ax, by-ay
+
+		// Closest point on the segment to the origin
+		t := 0.0
+		if lengthSq := dx*dx + dy*dy; lengthSq > 0 {
+			t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/lengthSq))
+		}
+		if d := math.Hypot(ax+t*dx, ay+t*dy); d < best {
+			best = d
+		}
+		ax, ay = bx, by
+	}
+	return best
+}
@@ -0,0 +1,38 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPolylineDecodeRoundTrip(t *testing.T) {
+	path := []Coordinate{{Lat: 6.52438, Lng: 3.37921}, {Lat: 6.53001, Lng: 3.38512}, {Lat: 6.5201, Lng: 3.39}}
+
+	decoded := PolylineDecode(PolylineEncode(path))
+
+	if len(decoded) != len(path) {
+		t.Fatalf("decoded %d points, want %d", len(decoded), len(path))
+	}
+	for i := range path {
+		if math.Abs(decoded[i].Lat-path[i].Lat) > 1e-5 || math.Abs(decoded[i].Lng-path[i].Lng) > 1e-5 {
+			t.Errorf("point %d = %+v, want %+v", i, decoded[i], path[i])
+		}
+	}
+}
+
+func TestDistanceToPolyline(t *testing.T) {
+	// A road running due east along the equator
+	path := []Coordinate{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 0.01}}
+
+	if d := DistanceToPolyline(0, 0.005, path); d > 1 {
+		t.Errorf("point on the road is %.1fm away", d)
+	}
+	// 0.001 degrees of latitude is about 111m
+	if d := DistanceToPolyline(0.001, 0.005, path); math.Abs(d-111) > 2 {
+		t.Errorf("point beside the road is %.1fm away, want about 111m", d)
+	}
+	// Past the end of the road the distance is to the endpoint
+	if d := DistanceToPolyline(0, 0.011, path); math.Abs(d-111) > 2 {
+		t.Errorf("point past the road is %.1fm away, want about 111m", d)
+	}
+}
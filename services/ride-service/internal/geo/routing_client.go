@@ -29,7 +29,9 @@ func (c *GoogleMapsRoutingClient) GetRoute(ctx context.Context, req *eta.ETARequ
 		DestLat:       req.DestLat,
 		DestLng:       req.DestLng,
 		DepartureTime: req.DepartureTime,
-		Mode:          "driving",
+		Mode:          req.Profile.GoogleMode(),
+		AvoidTolls:    req.AvoidTolls,
+		AvoidHighways: req.AvoidHighways,
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,129 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const defaultWhat3WordsURL = "https://api.what3words.com/v3"
+
+var (
+	// ErrWhat3WordsUnavailable is returned for squares in countries
+	// what3words addressing is not switched on for
+	ErrWhat3WordsUnavailable = errors.New("what3words addressing is not available in this country")
+
+	// ErrWhat3WordsNotFound is returned for words that name no square
+	ErrWhat3WordsNotFound = errors.New("no such what3words address")
+)
+
+// what3wordsPattern matches a three word address, with or without its
+// leading "///"
+var what3wordsPattern = regexp.MustCompile(`^(///)?\p{L}+[.｡。・︒។։။۔።।]\p{L}+[.｡。・︒។։။۔።।]\p{L}+$`)
+
+// IsWhat3Words reports whether text is a three word address, such as
+// "///filled.count.soap"
+func IsWhat3Words(text string) bool {
+	return what3wordsPattern.MatchString(strings.TrimSpace(text))
+}
+
+// What3WordsAddress is a three word address resolved to its 3m square
+type What3WordsAddress struct {
+	Words        string     `json:"words"`
+	Country      string     `json:"country"` // ISO 3166-1 alpha-2
+	NearestPlace string     `json:"nearestPlace"`
+	Coordinates  Coordinate `json:"coordinates"`
+}
+
+// What3WordsClient resolves three word addresses through the what3words
+// API, in the countries it is switched on for
+type What3WordsClient struct {
+	apiKey     string
+	baseURL    string
+	countries  map[string]bool
+	httpClient *http.Client
+}
+
+// What3WordsConfig holds configuration for the what3words client. An empty
+// country list allows every country.
+type What3WordsConfig struct {
+	APIKey    string
+	BaseURL   string
+	Countries []string // ISO 3166-1 alpha-2
+	Timeout   time.Duration
+}
+
+// NewWhat3WordsClient creates a new what3words client
+func NewWhat3WordsClient(config What3WordsConfig) *What3WordsClient {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultWhat3WordsURL
+	}
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	countries := make(map[string]bool)
+	for _, country := range config.Countries {
+		if country = strings.ToUpper(strings.TrimSpace(country)); country != "" {
+			countries[country] = true
+		}
+	}
+
+	return &What3WordsClient{
+		apiKey:     config.APIKey,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		countries:  countries,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Resolve converts a three word address to the centre of its square
+func (c *What3WordsClient) Resolve(ctx context.Context, words string) (*What3WordsAddress, error) {
+	words = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(words), "///"))
+
+	params := url.Values{
+		"words": {words},
+		"key":   {c.apiKey},
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/convert-to-coordinates?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		What3WordsAddress
+		Error *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	if result.Error != nil {
+		if result.Error.Code == "BadWords" {
+			return nil, ErrWhat3WordsNotFound
+		}
+		return nil, fmt.Errorf("API error: %s - %s", result.Error.Code, result.Error.Message)
+	}
+	if len(c.countries) > 0 && !c.countries[strings.ToUpper(result.Country)] {
+		return nil, ErrWhat3WordsUnavailable
+	}
+
+	return &result.What3WordsAddress, nil
+}
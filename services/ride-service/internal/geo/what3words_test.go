@@ -0,0 +1,59 @@
+package geo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWhat3WordsClient_Resolve(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/convert-to-coordinates" {
+			t.Errorf("Expected path /convert-to-coordinates, got %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("words") {
+		case "filled.count.soap":
+			w.Write([]byte(`{"country":"KE","nearestPlace":"Kibera, Nairobi","words":"filled.count.soap","coordinates":{"lat":-1.313,"lng":36.788}}`))
+		case "index.home.raft":
+			w.Write([]byte(`{"country":"GB","nearestPlace":"London","words":"index.home.raft","coordinates":{"lat":51.52,"lng":-0.19}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"BadWords","message":"Invalid or non-existent 3 word address"}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewWhat3WordsClient(What3WordsConfig{APIKey: "test-key", BaseURL: server.URL, Countries: []string{"ke", "ng"}})
+
+	addr, err := client.Resolve(context.Background(), "///Filled.Count.Soap")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if addr.Coordinates.Lat != -1.313 || addr.NearestPlace != "Kibera, Nairobi" {
+		t.Errorf("Unexpected address: %+v", addr)
+	}
+
+	if _, err := client.Resolve(context.Background(), "index.home.raft"); err != ErrWhat3WordsUnavailable {
+		t.Errorf("Expected ErrWhat3WordsUnavailable outside enabled countries, got %v", err)
+	}
+	if _, err := client.Resolve(context.Background(), "not.real.words"); err != ErrWhat3WordsNotFound {
+		t.Errorf("Expected ErrWhat3WordsNotFound, got %v", err)
+	}
+}
+
+func TestIsWhat3Words(t *testing.T) {
+	for text, want := range map[string]bool{
+		"///filled.count.soap": true,
+		"filled.count.soap":    true,
+		"12 Allen Avenue":      false,
+		"www.example.com.ng":   false,
+		"8FVC9G8F+6X":          false,
+	} {
+		if got := IsWhat3Words(text); got != want {
+			t.Errorf("IsWhat3Words(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Package guard protects the service from oversized and slow requests. It
+// rejects request bodies over a per-route size limit, logs requests that
+// run past their latency budget together with the dependency that took the
+// longest, and tracks how fast each endpoint group burns its error budget.
+package guard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/logging"
+)
+
+// DefaultObjective is the share of requests per endpoint group that must
+// succeed within their latency budget
+const DefaultObjective = 0.99
+
+// Route is the size limit and latency budget for requests under a path
+// prefix. Routes sharing a group share an SLO.
+type Route struct {
+	Prefix       string        `json:"prefix"`
+	Group        string        `json:"group"`
+	MaxBodyBytes int64         `json:"max_body_bytes"`
+	Budget       time.Duration `json:"budget"`
+}
+
+// DefaultRoute covers requests no other route matches
+var DefaultRoute = Route{Group: "other", MaxBodyBytes: 64 << 10, Budget: time.Second}
+
+// DefaultRoutes are the service's endpoint groups. Location pings are small
+// and frequent; admin and webhook bodies can carry whole configs.
+var DefaultRoutes = []Route{
+	{Prefix: "/health", Group: "health", MaxBodyBytes: 1 << 10, Budget: 500 * time.Millisecond},
+	{Prefix: "/drivers/location", Group: "location", MaxBodyBytes: 4 << 10, Budget: 300 * time.Millisecond},
+	{Prefix: "/rides", Group: "rides", MaxBodyBytes: 64 << 10, Budget: time.Second},
+	{Prefix: "/drivers", Group: "drivers", MaxBodyBytes: 64 << 10, Budget: 500 * time.Millisecond},
+	{Prefix: "/driver/", Group: "drivers", MaxBodyBytes: 64 << 10, Budget: 500 * time.Millisecond},
+	{Prefix: "/pricing", Group: "pricing", MaxBodyBytes: 16 << 10, Budget: 800 * time.Millisecond},
+	{Prefix: "/predictions", Group: "predictions", MaxBodyBytes: 16 << 10, Budget: 800 * time.Millisecond},
+	{Prefix: "/locations", Group: "locations", MaxBodyBytes: 256 << 10, Budget: 2 * time.Second},
+	{Prefix: "/eta", Group: "eta", MaxBodyBytes: 16 << 10, Budget: 2 * time.Second},
+	{Prefix: "/internal", Group: "internal", MaxBodyBytes: 1 << 20, Budget: 3 * time.Second},
+	{Prefix: "/webhooks", Group: "webhooks", MaxBodyBytes: 1 << 20, Budget: 2 * time.Second},
+	{Prefix: "/track/", Group: "tracking", MaxBodyBytes: 1 << 10, Budget: 500 * time.Millisecond},
+}
+
+// ParseRoutes overrides the default routes' limits by group from
+// REQUEST_BODY_LIMITS, GROUP=BYTES entries such as "rides=131072", and
+// LATENCY_BUDGETS, GROUP=DURATION entries such as "pricing=1s"
+func ParseRoutes(limitSpec, budgetSpec string) ([]Route, error) {
+	routes := append([]Route{DefaultRoute}, DefaultRoutes...)
+
+	limits, err := parseEntries(limitSpec, "request body limit", "GROUP=BYTES", routes, func(value string) (int64, error) {
+		return strconv.ParseInt(value, 10, 64)
+	})
+	if err != nil {
+		return nil, err
+	}
+	budgets, err := parseEntries(budgetSpec, "latency budget", "GROUP=DURATION", routes, func(value string) (int64, error) {
+		d, err := time.ParseDuration(value)
+		return int64(d), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range routes {
+		if limit, ok := limits[routes[i].Group]; ok {
+			routes[i].MaxBodyBytes = limit
+		}
+		if budget, ok := budgets[routes[i].Group]; ok {
+			routes[i].Budget = time.Duration(budget)
+		}
+	}
+	return routes, nil
+}
+
+func parseEntries(spec, what, format string, routes []Route, parse func(string) (int64, error)) (map[string]int64, error) {
+	groups := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		groups[route.Group] = true
+	}
+
+	values := make(map[string]int64)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(entry, "=")
+		group := strings.ToLower(strings.TrimSpace(key))
+		n, err := parse(strings.TrimSpace(value))
+		if !ok || !groups[group] || err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid %s %q, want %s", what, entry, format)
+		}
+		values[group] = n
+	}
+	return values, nil
+}
+
+var guardLog = logging.For("http")
+
+// Guard enforces size limits and latency budgets and keeps burn rates
+type Guard struct {
+	fallback  Route
+	routes    []Route
+	objective float64
+	slo       *sloTracker
+}
+
+// New creates a guard. A route with an empty prefix replaces DefaultRoute;
+// an objective outside (0, 1) means DefaultObjective.
+func New(routes []Route, objective float64) *Guard {
+	if objective <= 0 || objective >= 1 {
+		objective = DefaultObjective
+	}
+	g := &Guard{fallback: DefaultRoute, objective: objective, slo: newSLOTracker()}
+	for _, route := range routes {
+		if route.Prefix == "" {
+			g.fallback = route
+			continue
+		}
+		g.routes = append(g.routes, route)
+	}
+	return g
+}
+
+// Route returns the route a path falls under, by longest prefix
+func (g *Guard) Route(path string) Route {
+	match := g.fallback
+	for _, route := range g.routes {
+		if strings.HasPrefix(path, route.Prefix) && len(route.Prefix) > len(match.Prefix) {
+			match = route
+		}
+	}
+	return match
+}
+
+// Middleware applies the guard to each request. A body declared larger
+// than the route allows is refused before the handler runs; one sent
+// without a length is cut off at the limit, so the handler fails to
+// decode it.
+func (g *Guard) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := g.Route(r.URL.Path)
+		if route.MaxBodyBytes > 0 {
+			if r.ContentLength > route.MaxBodyBytes {
+				writeTooLarge(w, route.MaxBodyBytes)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, route.MaxBodyBytes)
+		}
+
+		spans := &spanRecorder{}
+		r = r.WithContext(withSpans(r.Context(), spans))
+
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+		elapsed := time.Since(start)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		slow := route.Budget > 0 && elapsed > route.Budget
+		g.slo.record(route.Group, start, slow || status >= http.StatusInternalServerError)
+
+		if slow {
+			l := logging.Enrich(r.Context(), guardLog)
+			event := l.Warn().
+				Str("tag", "slow_request").
+				Str("group", route.Group).
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Int("status", status).
+				Dur("duration", elapsed).
+				Dur("budget", route.Budget)
+			if span, ok := spans.slowest(); ok {
+				event = event.
+					Str("dependency", span.Dependency).
+					Dur("dependency_duration", span.Duration).
+					Int("dependency_calls", span.Calls)
+			}
+			event.Msg("Request over latency budget")
+		}
+	})
+}
+
+// BurnRates reports each endpoint group's burn rate over the short and
+// long windows
+func (g *Guard) BurnRates(now time.Time) []domain.SLOBurnRate {
+	return g.slo.burnRates(now, g.objective)
+}
+
+// Report logs each endpoint group's burn rates, alerting on groups burning
+// fast in both windows. Groups with no requests in the long window are
+// left out.
+func (g *Guard) Report(ctx context.Context) error {
+	for _, rate := range g.BurnRates(time.Now()) {
+		if rate.LongRequests == 0 {
+			continue
+		}
+		event := guardLog.Info()
+		if rate.Alerting {
+			event = guardLog.Error().Bool("alert", true)
+		}
+		event.
+			Str("group", rate.Group).
+			Int64("short_requests", rate.ShortRequests).
+			Int64("short_bad", rate.ShortBad).
+			Float64("short_burn", rate.ShortBurn).
+			Int64("long_requests", rate.LongRequests).
+			Int64("long_bad", rate.LongBad).
+			Float64("long_burn", rate.LongBurn).
+			Msg("SLO burn rate")
+	}
+	return nil
+}
+
+func writeTooLarge(w http.ResponseWriter, limit int64) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	json.NewEncoder(w).Encode(map[string]any{
+		"success": false,
+		"error": map[string]string{
+			"code":    domain.ErrCodePayloadTooLarge,
+			"message": fmt.Sprintf("Request body exceeds %d bytes", limit),
+		},
+	})
+}
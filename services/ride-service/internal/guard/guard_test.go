@@ -0,0 +1,112 @@
+package guard
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareBodyLimit(t *testing.T) {
+	g := New([]Route{{Prefix: "/rides", Group: "rides", MaxBodyBytes: 16}}, 0)
+	var read int
+	handler := g.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		read = len(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+
+	tests := []struct {
+		name       string
+		body       io.Reader
+		wantStatus int
+	}{
+		{"within the limit", strings.NewReader(`{"ok":true}`), http.StatusOK},
+		{"declared too large", strings.NewReader(strings.Repeat("x", 32)), http.StatusRequestEntityTooLarge},
+		{"streamed too large", io.MultiReader(strings.NewReader(strings.Repeat("x", 32))), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			read = 0
+			req := httptest.NewRequest(http.MethodPost, "/rides", tt.body)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if read > 16 {
+				t.Errorf("handler read %d bytes past the limit", read)
+			}
+		})
+	}
+}
+
+func TestMiddlewareBurnRate(t *testing.T) {
+	g := New([]Route{{Prefix: "/pricing", Group: "pricing", Budget: 20 * time.Millisecond}}, 0.9)
+	var slowest Span
+	handler := g.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pricing/slow":
+			end := StartSpan(r.Context(), "maps.googleapis.com")
+			time.Sleep(30 * time.Millisecond)
+			end()
+			StartSpan(r.Context(), "redis")()
+			slowest, _ = r.Context().Value(spansKey{}).(*spanRecorder).slowest()
+		case "/pricing/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+
+	for _, path := range []string{"/pricing/ok", "/pricing/ok", "/pricing/slow", "/pricing/fail"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if slowest.Dependency != "maps.googleapis.com" {
+		t.Errorf("slowest dependency = %q, want maps.googleapis.com", slowest.Dependency)
+	}
+	rates := g.BurnRates(time.Now())
+	if len(rates) != 1 || rates[0].Group != "pricing" {
+		t.Fatalf("BurnRates() = %+v, want the pricing group", rates)
+	}
+	// Two bad requests in four against a 10% error budget
+	if got := rates[0]; got.ShortBad != 2 || got.ShortRequests != 4 || math.Abs(got.ShortBurn-5) > 1e-9 {
+		t.Errorf("burn rate = %+v, want 2 of 4 bad burning at 5", got)
+	}
+}
+
+func TestStartSpanOutsideRequest(t *testing.T) {
+	// Background jobs share the instrumented clients
+	StartSpan(context.Background(), "postgres")()
+}
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes("rides=131072, OTHER=1024", "pricing=1s")
+	if err != nil {
+		t.Fatalf("ParseRoutes() error = %v", err)
+	}
+	g := New(routes, 0)
+	if got := g.Route("/rides/123").MaxBodyBytes; got != 131072 {
+		t.Errorf("rides limit = %d, want 131072", got)
+	}
+	if got := g.Route("/unknown").MaxBodyBytes; got != 1024 {
+		t.Errorf("fallback limit = %d, want 1024", got)
+	}
+	if got := g.Route("/pricing/estimate").Budget; got != time.Second {
+		t.Errorf("pricing budget = %v, want 1s", got)
+	}
+	if got := g.Route("/drivers/location").Group; got != "location" {
+		t.Errorf("group = %q, want location", got)
+	}
+
+	for _, spec := range []string{"rides", "nowhere=10", "rides=-1", "rides=big"} {
+		if _, err := ParseRoutes(spec, ""); err == nil {
+			t.Errorf("ParseRoutes(%q) error = nil, want an error", spec)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package guard
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// sloBuckets is one minute bucket per minute of the long window
+var sloBuckets = int(domain.SLOLongWindow / time.Minute)
+
+type sloBucket struct {
+	minute   int64 // Unix minute the counts are for
+	requests int64
+	bad      int64
+}
+
+// sloTracker counts requests and bad requests per endpoint group in minute
+// buckets covering the long window
+type sloTracker struct {
+	mu     sync.Mutex
+	groups map[string][]sloBucket
+}
+
+func newSLOTracker() *sloTracker {
+	return &sloTracker{groups: make(map[string][]sloBucket)}
+}
+
+func (t *sloTracker) record(group string, at time.Time, bad bool) {
+	minute := at.Unix() / 60
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	buckets, ok := t.groups[group]
+	if !ok {
+		buckets = make([]sloBucket, sloBuckets)
+		t.groups[group] = buckets
+	}
+	b := &buckets[minute%int64(sloBuckets)]
+	if b.minute != minute {
+		*b = sloBucket{minute: minute}
+	}
+	b.requests++
+	if bad {
+		b.bad++
+	}
+}
+
+func (t *sloTracker) burnRates(now time.Time, objective float64) []domain.SLOBurnRate {
+	minute := now.Unix() / 60
+	shortFrom := minute - int64(domain.SLOShortWindow/time.Minute) + 1
+	longFrom := minute - int64(sloBuckets) + 1
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rates := make([]domain.SLOBurnRate, 0, len(t.groups))
+	for group, buckets := range t.groups {
+		var shortRequests, shortBad, longRequests, longBad int64
+		for _, b := range buckets {
+			if b.minute < longFrom || b.minute > minute {
+				continue
+			}
+			longRequests += b.requests
+			longBad += b.bad
+			if b.minute >= shortFrom {
+				shortRequests += b.requests
+				shortBad += b.bad
+			}
+		}
+		rates = append(rates, domain.NewSLOBurnRate(group, objective, shortRequests, shortBad, longRequests, longBad))
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Group < rates[j].Group })
+	return rates
+}
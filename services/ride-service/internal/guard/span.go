@@ -0,0 +1,129 @@
+package guard
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v5"
+)
+
+// Span is the time a request spent waiting on one dependency
+type Span struct {
+	Dependency string
+	Duration   time.Duration
+	Calls      int
+}
+
+// spanRecorder totals a request's dependency calls. Handlers may call
+// dependencies from several goroutines.
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []Span
+}
+
+func (r *spanRecorder) add(dependency string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.spans {
+		if r.spans[i].Dependency == dependency {
+			r.spans[i].Duration += d
+			r.spans[i].Calls++
+			return
+		}
+	}
+	r.spans = append(r.spans, Span{Dependency: dependency, Duration: d, Calls: 1})
+}
+
+// slowest is the dependency the request waited on longest
+func (r *spanRecorder) slowest() (Span, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var slowest Span
+	for _, s := range r.spans {
+		if s.Duration > slowest.Duration {
+			slowest = s
+		}
+	}
+	return slowest, slowest.Calls > 0
+}
+
+type spansKey struct{}
+
+func withSpans(ctx context.Context, r *spanRecorder) context.Context {
+	return context.WithValue(ctx, spansKey{}, r)
+}
+
+// StartSpan times a call to a dependency made for the request in ctx; call
+// the returned func when it is done. Outside a request it does nothing.
+func StartSpan(ctx context.Context, dependency string) func() {
+	r, ok := ctx.Value(spansKey{}).(*spanRecorder)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() { r.add(dependency, time.Since(start)) }
+}
+
+type spanEndKey struct{}
+
+// QueryTracer times Postgres queries as "postgres" spans
+type QueryTracer struct{}
+
+// TraceQueryStart implements pgx.QueryTracer
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, spanEndKey{}, StartSpan(ctx, "postgres"))
+}
+
+// TraceQueryEnd implements pgx.QueryTracer
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryEndData) {
+	endSpan(ctx)
+}
+
+// RedisHook times Redis commands and pipelines as "redis" spans
+type RedisHook struct{}
+
+// BeforeProcess implements redis.Hook
+func (RedisHook) BeforeProcess(ctx context.Context, _ redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, spanEndKey{}, StartSpan(ctx, "redis")), nil
+}
+
+// AfterProcess implements redis.Hook
+func (RedisHook) AfterProcess(ctx context.Context, _ redis.Cmder) error {
+	endSpan(ctx)
+	return nil
+}
+
+// BeforeProcessPipeline implements redis.Hook
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, spanEndKey{}, StartSpan(ctx, "redis")), nil
+}
+
+// AfterProcessPipeline implements redis.Hook
+func (RedisHook) AfterProcessPipeline(ctx context.Context, _ []redis.Cmder) error {
+	endSpan(ctx)
+	return nil
+}
+
+func endSpan(ctx context.Context) {
+	if end, ok := ctx.Value(spanEndKey{}).(func()); ok {
+		end()
+	}
+}
+
+// Transport times outbound HTTP calls as spans named for the host called,
+// such as "maps.googleapis.com"
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return roundTripper{base: base}
+}
+
+type roundTripper struct {
+	base http.RoundTripper
+}
+
+func (t roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	defer StartSpan(req.Context(), req.URL.Host)()
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// Largest webhook body accepted from a provider
+const maxWebhookBodyBytes = 1 << 20
+
+// BackgroundCheckService defines the background check service interface
+type BackgroundCheckService interface {
+	HandleWebhook(ctx context.Context, provider string, payload *domain.BackgroundCheckWebhook) (*domain.BackgroundCheck, error)
+	ListChecks(ctx context.Context, driverID uuid.UUID) ([]*domain.BackgroundCheck, error)
+}
+
+// WebhookVerifier defines the signed webhook verification interface
+type WebhookVerifier interface {
+	Verify(ctx context.Context, source string, header http.Header, body []byte) error
+}
+
+// BackgroundCheckHandler receives provider webhooks and serves check history
+type BackgroundCheckHandler struct {
+	checkService BackgroundCheckService
+	verifier     WebhookVerifier
+}
+
+// NewBackgroundCheckHandler creates a new background check handler. Each
+// provider is a webhook source of its own.
+func NewBackgroundCheckHandler(checkService BackgroundCheckService, verifier WebhookVerifier) *BackgroundCheckHandler {
+	return &BackgroundCheckHandler{
+		checkService: checkService,
+		verifier:     verifier,
+	}
+}
+
+// ReceiveWebhook handles POST /webhooks/background-checks/{provider}
+func (h *BackgroundCheckHandler) ReceiveWebhook(w http.ResponseWriter, r *http.Request) {
+	provider := strings.ToLower(chi.URLParam(r, "provider"))
+	if provider == "" {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Missing provider")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	if err := h.verifier.Verify(r.Context(), provider, r.Header, body); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	var payload domain.BackgroundCheckWebhook
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	check, err := h.checkService.HandleWebhook(r.Context(), provider, &payload)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidRequest:
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid background check payload")
+		default:
+			writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to process webhook")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, check)
+}
+
+// ListDriverChecks handles GET /internal/support/drivers/{driverId}/background-checks
+func (h *BackgroundCheckHandler) ListDriverChecks(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	driverID, err := uuid.Parse(chi.URLParam(r, "driverId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid driver ID")
+		return
+	}
+
+	checks, err := h.checkService.ListChecks(r.Context(), driverID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list background checks")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"checks": checks})
+}
+
+// writeWebhookError answers a delivery the verifier refused
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrInvalidWebhookSignature:
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeInvalidSignature, err.Error())
+	case domain.ErrWebhookReplayed:
+		writeError(w, http.StatusConflict, domain.ErrCodeWebhookReplayed, err.Error())
+	default:
+		writeError(w, http.StatusServiceUnavailable, domain.ErrCodeInternal, "Unable to verify webhook")
+	}
+}
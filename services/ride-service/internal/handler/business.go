@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// BusinessService defines the business account service interface
+type BusinessService interface {
+	CreateAccount(ctx context.Context, account *domain.BusinessAccount, adminID uuid.UUID) (*domain.BusinessAccount, error)
+	GetAccount(ctx context.Context, id uuid.UUID) (*domain.BusinessAccount, error)
+	ListAccounts(ctx context.Context) ([]*domain.BusinessAccount, error)
+	UpdateAccount(ctx context.Context, id uuid.UUID, update *domain.BusinessAccount) (*domain.BusinessAccount, error)
+	SaveMember(ctx context.Context, accountID uuid.UUID, member *domain.BusinessMember) (*domain.BusinessMember, error)
+	RemoveMember(ctx context.Context, accountID, riderID uuid.UUID) error
+	ListMembers(ctx context.Context, accountID uuid.UUID) ([]*domain.BusinessMember, error)
+	ListProfiles(ctx context.Context, riderID uuid.UUID) ([]*domain.BusinessProfile, error)
+	GetStatement(ctx context.Context, accountID, userID uuid.UUID, role, month string) (*domain.BusinessStatement, error)
+}
+
+// BusinessHandler serves business accounts: their admin API, riders'
+// business profiles and monthly statements
+type BusinessHandler struct {
+	businessService BusinessService
+}
+
+// NewBusinessHandler creates a new business handler
+func NewBusinessHandler(businessService BusinessService) *BusinessHandler {
+	return &BusinessHandler{businessService: businessService}
+}
+
+// SaveBusinessMemberRequest is the body of a membership change
+type SaveBusinessMemberRequest struct {
+	Role              domain.BusinessMemberRole `json:"role"`
+	DefaultCostCenter string                    `json:"default_cost_center"`
+}
+
+// CreateAccount handles POST /internal/admin/business-accounts
+func (h *BusinessHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+	adminID := getUserIDFromContext(r.Context())
+	if adminID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var account domain.BusinessAccount
+	if err := json.NewDecoder(r.Body).Decode(&account); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	created, err := h.businessService.CreateAccount(r.Context(), &account, adminID)
+	if err != nil {
+		writeBusinessError(w, err, "Failed to create business account")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// ListAccounts handles GET /internal/admin/business-accounts
+func (h *BusinessHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	accounts, err := h.businessService.ListAccounts(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list business accounts")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"accounts": accounts})
+}
+
+// GetAccount handles GET /internal/admin/business-accounts/{accountId}
+func (h *BusinessHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := adminBusinessAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	account, err := h.businessService.GetAccount(r.Context(), accountID)
+	if err != nil {
+		writeBusinessError(w, err, "Failed to get business account")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, account)
+}
+
+// UpdateAccount handles PUT /internal/admin/business-accounts/{accountId}
+func (h *BusinessHandler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := adminBusinessAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	var update domain.BusinessAccount
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	account, err := h.businessService.UpdateAccount(r.Context(), accountID, &update)
+	if err != nil {
+		writeBusinessError(w, err, "Failed to update business account")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, account)
+}
+
+// ListMembers handles GET /internal/admin/business-accounts/{accountId}/members
+func (h *BusinessHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := adminBusinessAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := h.businessService.ListMembers(r.Context(), accountID)
+	if err != nil {
+		writeBusinessError(w, err, "Failed to list business account members")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"members": members})
+}
+
+// SaveMember handles PUT /internal/admin/business-accounts/{accountId}/members/{riderId}
+func (h *BusinessHandler) SaveMember(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := adminBusinessAccountID(w, r)
+	if !ok {
+		return
+	}
+	riderID, err := uuid.Parse(chi.URLParam(r, "riderId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid rider ID")
+		return
+	}
+
+	var req SaveBusinessMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	member, err := h.businessService.SaveMember(r.Context(), accountID, &domain.BusinessMember{
+		RiderID:           riderID,
+		Role:              req.Role,
+		DefaultCostCenter: req.DefaultCostCenter,
+	})
+	if err != nil {
+		writeBusinessError(w, err, "Failed to save business account member")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, member)
+}
+
+// RemoveMember handles DELETE /internal/admin/business-accounts/{accountId}/members/{riderId}
+func (h *BusinessHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := adminBusinessAccountID(w, r)
+	if !ok {
+		return
+	}
+	riderID, err := uuid.Parse(chi.URLParam(r, "riderId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid rider ID")
+		return
+	}
+
+	if err := h.businessService.RemoveMember(r.Context(), accountID, riderID); err != nil {
+		if errors.Is(err, domain.ErrNotBusinessMember) {
+			writeError(w, http.StatusNotFound, domain.ErrCodeNotBusinessMember, err.Error())
+			return
+		}
+		writeBusinessError(w, err, "Failed to remove business account member")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListMyProfiles handles GET /users/me/business-profiles
+func (h *BusinessHandler) ListMyProfiles(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	profiles, err := h.businessService.ListProfiles(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list business profiles")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"profiles": profiles})
+}
+
+// GetStatement handles GET /business-accounts/{accountId}/statement?month=YYYY-MM
+// for the account's admins and platform admins
+func (h *BusinessHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	accountID, err := uuid.Parse(chi.URLParam(r, "accountId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid business account ID")
+		return
+	}
+
+	statement, err := h.businessService.GetStatement(
+		r.Context(), accountID, userID, getUserRoleFromContext(r.Context()), r.URL.Query().Get("month"),
+	)
+	if err != nil {
+		writeBusinessError(w, err, "Failed to get business statement")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, statement)
+}
+
+// adminBusinessAccountID checks the caller is an admin and parses the
+// account in the URL, writing the error if either fails
+func adminBusinessAccountID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return uuid.Nil, false
+	}
+
+	accountID, err := uuid.Parse(chi.URLParam(r, "accountId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid business account ID")
+		return uuid.Nil, false
+	}
+	return accountID, true
+}
+
+// writeBusinessRideError writes the error for a ride request its business
+// profile turned down, reporting whether it was one
+func writeBusinessRideError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrBusinessAccountNotFound),
+		errors.Is(err, domain.ErrNotBusinessMember),
+		errors.Is(err, domain.ErrInvalidCostCenter),
+		errors.Is(err, domain.ErrBusinessRideNotAllowed):
+		writeBusinessError(w, err, "")
+		return true
+	}
+	return false
+}
+
+func writeBusinessError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrBusinessAccountNotFound):
+		writeError(w, http.StatusNotFound, domain.ErrCodeBusinessAccountNotFound, err.Error())
+	case errors.Is(err, domain.ErrNotBusinessMember):
+		writeError(w, http.StatusForbidden, domain.ErrCodeNotBusinessMember, err.Error())
+	case errors.Is(err, domain.ErrForbidden):
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Business account admin access required")
+	case errors.Is(err, domain.ErrInvalidBusinessAccount):
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidBusinessAccount, err.Error())
+	case errors.Is(err, domain.ErrInvalidCostCenter):
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidCostCenter, err.Error())
+	case errors.Is(err, domain.ErrBusinessRideNotAllowed):
+		writeError(w, http.StatusUnprocessableEntity, domain.ErrCodeBusinessRideNotAllowed, err.Error())
+	case errors.Is(err, domain.ErrInvalidStatementMonth):
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidStatementMonth, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, fallback)
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// CityConfigService defines the city configuration service interface
+type CityConfigService interface {
+	ListCities() []*domain.CityConfig
+	GetCity(code string) (*domain.CityConfig, error)
+	SaveCity(ctx context.Context, city *domain.CityConfig, adminID uuid.UUID) (*domain.CityConfig, error)
+}
+
+// CityConfigHandler serves the admin API for city launch bundles
+type CityConfigHandler struct {
+	cityService CityConfigService
+}
+
+// NewCityConfigHandler creates a new city config handler
+func NewCityConfigHandler(cityService CityConfigService) *CityConfigHandler {
+	return &CityConfigHandler{cityService: cityService}
+}
+
+// ListCities handles GET /internal/admin/cities
+func (h *CityConfigHandler) ListCities(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"cities": h.cityService.ListCities()})
+}
+
+// GetCity handles GET /internal/admin/cities/{code}
+func (h *CityConfigHandler) GetCity(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	city, err := h.cityService.GetCity(chi.URLParam(r, "code"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, domain.ErrCodeCityNotFound, "City not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, city)
+}
+
+// SaveCity handles PUT /internal/admin/cities/{code}, creating or replacing
+// the city's bundle
+func (h *CityConfigHandler) SaveCity(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+	adminID := getUserIDFromContext(r.Context())
+	if adminID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var city domain.CityConfig
+	if err := json.NewDecoder(r.Body).Decode(&city); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	code := strings.ToLower(chi.URLParam(r, "code"))
+	if city.Code == "" {
+		city.Code = code
+	}
+	if strings.ToLower(city.Code) != code {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "City code does not match URL")
+		return
+	}
+
+	saved, err := h.cityService.SaveCity(r.Context(), &city, adminID)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCityConfig) {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidCityConfig, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to save city config")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, saved)
+}
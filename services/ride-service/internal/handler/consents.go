@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// ConsentService defines the data consent service interface
+type ConsentService interface {
+	GetConsents(ctx context.Context, userID uuid.UUID) ([]*domain.ConsentStatus, error)
+	UpdateConsents(ctx context.Context, userID uuid.UUID, choices []domain.ConsentChoice) ([]*domain.ConsentStatus, error)
+}
+
+// ConsentHandler lets users see and change what their data may be used for
+type ConsentHandler struct {
+	consentService ConsentService
+}
+
+// NewConsentHandler creates a new consent handler
+func NewConsentHandler(consentService ConsentService) *ConsentHandler {
+	return &ConsentHandler{consentService: consentService}
+}
+
+// UpdateConsentsRequest is a user granting or withdrawing consents
+type UpdateConsentsRequest struct {
+	Consents []domain.ConsentChoice `json:"consents"`
+}
+
+// GetMyConsents handles GET /users/me/consents
+func (h *ConsentHandler) GetMyConsents(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	consents, err := h.consentService.GetConsents(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get consents")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"consents": consents})
+}
+
+// UpdateMyConsents handles PUT /users/me/consents
+func (h *ConsentHandler) UpdateMyConsents(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req UpdateConsentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	consents, err := h.consentService.UpdateConsents(r.Context(), userID, req.Consents)
+	if err != nil {
+		if err == domain.ErrInvalidConsent {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidConsent, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to update consents")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"consents": consents})
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/locale"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/money"
+)
+
+// ExchangeRateSource supplies the rates fares are shown in other currencies
+// at
+type ExchangeRateSource interface {
+	Rates() *domain.ExchangeRates
+}
+
+// displayCurrency picks the currency to show a fare in beside its
+// settlement currency: the one the client asked for, else the rider's
+// home currency. It returns ErrUnsupportedCurrency for an unknown code.
+func displayCurrency(ctx context.Context, requested string) (domain.Currency, error) {
+	if requested != "" {
+		return money.ParseCurrency(requested)
+	}
+	return locale.HomeCurrency(ctx), nil
+}
+
+// displayAmount converts a settlement amount for display. It returns nil
+// without a rate source, when there is nothing to convert, or when there is
+// no rate, since the settlement amount alone is still correct.
+func displayAmount(rates ExchangeRateSource, amount int64, from, to domain.Currency) *domain.DisplayAmount {
+	if rates == nil {
+		return nil
+	}
+	display, err := money.Display(amount, from, to, rates.Rates())
+	if err != nil {
+		log.Warn().Err(err).Str("from", string(from)).Str("to", string(to)).Msg("Failed to convert fare for display")
+		return nil
+	}
+	return display
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/service"
+)
+
+// Default page size for the support dispute queue
+const defaultDisputeQueueLimit = 50
+
+// DisputeService defines the fare dispute service interface
+type DisputeService interface {
+	OpenDispute(ctx context.Context, rideID, riderID uuid.UUID, reason domain.DisputeReason, comment string) (*domain.FareDispute, error)
+	GetRideDispute(ctx context.Context, rideID uuid.UUID) (*domain.FareDispute, error)
+	ListQueue(ctx context.Context, status domain.DisputeStatus, limit, offset int) ([]*domain.FareDispute, int64, error)
+	ResolveDispute(ctx context.Context, disputeID, agentID uuid.UUID, res *service.DisputeResolution) (*domain.FareDispute, error)
+}
+
+// DisputeHandler handles fare dispute requests
+type DisputeHandler struct {
+	disputeService DisputeService
+}
+
+// NewDisputeHandler creates a new dispute handler
+func NewDisputeHandler(disputeService DisputeService) *DisputeHandler {
+	return &DisputeHandler{disputeService: disputeService}
+}
+
+// OpenDisputeRequest is the body of a rider's fare dispute
+type OpenDisputeRequest struct {
+	Reason  string `json:"reason"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// ResolveDisputeRequest is the body of a support agent's dispute decision
+type ResolveDisputeRequest struct {
+	Upheld       bool   `json:"upheld"`
+	RefundAmount int64  `json:"refund_amount,omitempty"`
+	Note         string `json:"note"`
+}
+
+// DisputeQueueItem is a dispute in the support queue with its SLA state
+type DisputeQueueItem struct {
+	*domain.FareDispute
+	Overdue bool `json:"overdue"`
+}
+
+// OpenDispute handles POST /rides/{rideId}/dispute
+func (h *DisputeHandler) OpenDispute(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	var req OpenDisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	dispute, err := h.disputeService.OpenDispute(r.Context(), rideID, userID, domain.DisputeReason(req.Reason), req.Comment)
+	if err != nil {
+		writeDisputeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, dispute)
+}
+
+// GetDispute handles GET /rides/{rideId}/dispute
+func (h *DisputeHandler) GetDispute(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	dispute, err := h.disputeService.GetRideDispute(r.Context(), rideID)
+	if err != nil {
+		writeDisputeError(w, err)
+		return
+	}
+
+	if dispute.RiderID != userID && !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Not allowed to view this dispute")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dispute)
+}
+
+// ListDisputeQueue handles GET /internal/support/disputes
+func (h *DisputeHandler) ListDisputeQueue(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	status := domain.DisputeStatusOpen
+	if s := r.URL.Query().Get("status"); s != "" {
+		status = domain.DisputeStatus(s)
+	}
+
+	limit := defaultDisputeQueueLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	offset := 0
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o > 0 {
+		offset = o
+	}
+
+	disputes, total, err := h.disputeService.ListQueue(r.Context(), status, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list disputes")
+		return
+	}
+
+	now := time.Now().UTC()
+	items := make([]DisputeQueueItem, 0, len(disputes))
+	for _, d := range disputes {
+		items = append(items, DisputeQueueItem{FareDispute: d, Overdue: d.IsOverdue(now)})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"disputes": items,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// ResolveDispute handles POST /internal/support/disputes/{disputeId}/resolve
+func (h *DisputeHandler) ResolveDispute(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+	agentID := getUserIDFromContext(r.Context())
+	if agentID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	disputeID, err := uuid.Parse(chi.URLParam(r, "disputeId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid dispute ID")
+		return
+	}
+
+	var req ResolveDisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	dispute, err := h.disputeService.ResolveDispute(r.Context(), disputeID, agentID, &service.DisputeResolution{
+		Upheld:       req.Upheld,
+		RefundAmount: req.RefundAmount,
+		Note:         req.Note,
+	})
+	if err != nil {
+		writeDisputeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dispute)
+}
+
+func writeDisputeError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrDisputeNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeDisputeNotFound, "Dispute not found")
+	case domain.ErrDisputeAlreadyOpen:
+		writeError(w, http.StatusConflict, domain.ErrCodeDisputeAlreadyOpen, err.Error())
+	case domain.ErrDisputeAlreadyResolved:
+		writeError(w, http.StatusConflict, domain.ErrCodeDisputeAlreadyResolved, err.Error())
+	case domain.ErrDisputeNotOpen:
+		writeError(w, http.StatusConflict, domain.ErrCodeDisputeNotOpen, err.Error())
+	case domain.ErrDisputeWindowClosed:
+		writeError(w, http.StatusUnprocessableEntity, domain.ErrCodeDisputeWindowClosed, err.Error())
+	case domain.ErrRideNotDisputable:
+		writeError(w, http.StatusConflict, domain.ErrCodeRideNotDisputable, err.Error())
+	case domain.ErrDisputeInvalidReason:
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, err.Error())
+	case domain.ErrForbidden:
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Not allowed to dispute this ride")
+	default:
+		// Refund errors can surface when a resolution issues a refund
+		writeRefundError(w, err)
+	}
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// DriverAdminService defines the driver admin service interface
+type DriverAdminService interface {
+	SearchDrivers(ctx context.Context, search *domain.DriverSearch) ([]*domain.Driver, int64, error)
+	GetProfile(ctx context.Context, driverID uuid.UUID) (*domain.DriverProfile, error)
+	UpdateVehicle(ctx context.Context, driverID, adminID uuid.UUID, update *domain.VehicleUpdate, reason string) (*domain.Vehicle, error)
+	ResetStatus(ctx context.Context, driverID, adminID uuid.UUID, reason string) (*domain.Driver, error)
+	ForceOffline(ctx context.Context, driverID, adminID uuid.UUID, reason string) (*domain.Driver, error)
+}
+
+// DriverAdminHandler serves ops' driver search, driver profiles and the
+// audited changes they make to a driver's record
+type DriverAdminHandler struct {
+	driverAdmin DriverAdminService
+}
+
+// NewDriverAdminHandler creates a new driver admin handler
+func NewDriverAdminHandler(driverAdmin DriverAdminService) *DriverAdminHandler {
+	return &DriverAdminHandler{driverAdmin: driverAdmin}
+}
+
+// UpdateVehicleRequest is the body of a vehicle correction
+type UpdateVehicleRequest struct {
+	domain.VehicleUpdate
+	Reason string `json:"reason"`
+}
+
+// DriverAdminActionRequest is the body of a status change
+type DriverAdminActionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SearchDrivers handles GET /internal/admin/drivers?phone=&plate=&name=&status=&city=&limit=&offset=
+func (h *DriverAdminHandler) SearchDrivers(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	query := r.URL.Query()
+	search := &domain.DriverSearch{
+		Phone:  query.Get("phone"),
+		Plate:  query.Get("plate"),
+		Name:   query.Get("name"),
+		Status: domain.DriverStatus(query.Get("status")),
+		City:   query.Get("city"),
+	}
+	for param, target := range map[string]*int{"limit": &search.Limit, "offset": &search.Offset} {
+		v := query.Get(param)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidDriverSearch, param+" must be a number")
+			return
+		}
+		*target = n
+	}
+
+	drivers, total, err := h.driverAdmin.SearchDrivers(r.Context(), search)
+	if err != nil {
+		writeDriverAdminError(w, err, "Failed to search drivers")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"drivers": drivers,
+		"total":   total,
+		"limit":   search.Limit,
+		"offset":  search.Offset,
+	})
+}
+
+// GetProfile handles GET /internal/admin/drivers/{driverId}/profile
+func (h *DriverAdminHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	driverID, _, ok := adminDriverID(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := h.driverAdmin.GetProfile(r.Context(), driverID)
+	if err != nil {
+		writeDriverAdminError(w, err, "Failed to get driver profile")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, profile)
+}
+
+// UpdateVehicle handles PATCH /internal/admin/drivers/{driverId}/vehicle
+func (h *DriverAdminHandler) UpdateVehicle(w http.ResponseWriter, r *http.Request) {
+	driverID, adminID, ok := adminDriverID(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateVehicleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	vehicle, err := h.driverAdmin.UpdateVehicle(r.Context(), driverID, adminID, &req.VehicleUpdate, req.Reason)
+	if err != nil {
+		writeDriverAdminError(w, err, "Failed to update vehicle")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, vehicle)
+}
+
+// ResetStatus handles POST /internal/admin/drivers/{driverId}/reset-status
+func (h *DriverAdminHandler) ResetStatus(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.driverAdmin.ResetStatus, "Failed to reset driver status")
+}
+
+// ForceOffline handles POST /internal/admin/drivers/{driverId}/force-offline
+func (h *DriverAdminHandler) ForceOffline(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.driverAdmin.ForceOffline, "Failed to take driver offline")
+}
+
+func (h *DriverAdminHandler) changeStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	change func(ctx context.Context, driverID, adminID uuid.UUID, reason string) (*domain.Driver, error),
+	fallback string,
+) {
+	driverID, adminID, ok := adminDriverID(w, r)
+	if !ok {
+		return
+	}
+
+	var req DriverAdminActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	driver, err := change(r.Context(), driverID, adminID, req.Reason)
+	if err != nil {
+		writeDriverAdminError(w, err, fallback)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, driver)
+}
+
+// adminDriverID checks the caller is an admin and parses the driver in the
+// URL, writing the error if either fails
+func adminDriverID(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return uuid.Nil, uuid.Nil, false
+	}
+	adminID := getUserIDFromContext(r.Context())
+	if adminID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	driverID, err := uuid.Parse(chi.URLParam(r, "driverId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid driver ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return driverID, adminID, true
+}
+
+func writeDriverAdminError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrDriverNotFound):
+		writeError(w, http.StatusNotFound, domain.ErrCodeDriverNotFound, "Driver not found")
+	case errors.Is(err, domain.ErrVehicleNotFound):
+		writeError(w, http.StatusNotFound, domain.ErrCodeVehicleNotFound, err.Error())
+	case errors.Is(err, domain.ErrInvalidDriverSearch):
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidDriverSearch, err.Error())
+	case errors.Is(err, domain.ErrInvalidVehicleUpdate):
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidVehicleUpdate, err.Error())
+	case errors.Is(err, domain.ErrDriverAdminReasonRequired):
+		writeError(w, http.StatusBadRequest, domain.ErrCodeDriverAdminReasonRequired, err.Error())
+	case errors.Is(err, domain.ErrDriverRestricted):
+		writeError(w, http.StatusConflict, domain.ErrCodeDriverRestricted, err.Error())
+	case errors.Is(err, domain.ErrDriverOnActiveRide):
+		writeError(w, http.StatusConflict, domain.ErrCodeDriverOnActiveRide, err.Error())
+	case errors.Is(err, domain.ErrDriverStatusNotStuck):
+		writeError(w, http.StatusConflict, domain.ErrCodeDriverStatusNotStuck, err.Error())
+	case errors.Is(err, domain.ErrDriverNotOnline):
+		writeError(w, http.StatusConflict, domain.ErrCodeDriverNotOnline, err.Error())
+	case errors.Is(err, domain.ErrDriverStatusChanged):
+		writeError(w, http.StatusConflict, domain.ErrCodeDriverStatusChanged, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, fallback)
+	}
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+const (
+	// Window the expiring-documents report looks ahead when none is given
+	defaultExpiringDocumentDays = 30
+
+	// Longest window the expiring-documents report looks ahead
+	maxExpiringDocumentDays = 90
+)
+
+// DriverDocumentService defines the driver document service interface
+type DriverDocumentService interface {
+	RecordDocument(ctx context.Context, doc *domain.DriverDocument) error
+	ListDocuments(ctx context.Context, driverID uuid.UUID) ([]*domain.ExpiringDocument, error)
+	ExpiringReport(ctx context.Context, country, city string, within time.Duration) ([]*domain.ExpiringDocument, error)
+}
+
+// DriverDocumentHandler records drivers' licences and permits and reports
+// those about to expire
+type DriverDocumentHandler struct {
+	documentService DriverDocumentService
+}
+
+// NewDriverDocumentHandler creates a new driver document handler
+func NewDriverDocumentHandler(documentService DriverDocumentService) *DriverDocumentHandler {
+	return &DriverDocumentHandler{documentService: documentService}
+}
+
+// RecordDocumentRequest is a verified document's details
+type RecordDocumentRequest struct {
+	Country   string    `json:"country"`
+	City      string    `json:"city,omitempty"`
+	Number    string    `json:"number,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RecordDocument handles PUT /internal/support/drivers/{driverId}/documents/{type}
+func (h *DriverDocumentHandler) RecordDocument(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	driverID, err := uuid.Parse(chi.URLParam(r, "driverId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid driver ID")
+		return
+	}
+
+	var req RecordDocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	doc := &domain.DriverDocument{
+		DriverID:  driverID,
+		Type:      domain.DocumentType(strings.ToUpper(chi.URLParam(r, "type"))),
+		Country:   req.Country,
+		City:      req.City,
+		Number:    req.Number,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := h.documentService.RecordDocument(r.Context(), doc); err != nil {
+		switch err {
+		case domain.ErrInvalidDriverDocument:
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Document needs a known type, a country code and an expiry date")
+		default:
+			writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to record document")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// ListDriverDocuments handles GET /internal/support/drivers/{driverId}/documents
+func (h *DriverDocumentHandler) ListDriverDocuments(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	driverID, err := uuid.Parse(chi.URLParam(r, "driverId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid driver ID")
+		return
+	}
+
+	docs, err := h.documentService.ListDocuments(r.Context(), driverID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list documents")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"documents": docs})
+}
+
+// GetExpiringReport handles GET /internal/admin/compliance/expiring-documents?country=&city=&days=
+func (h *DriverDocumentHandler) GetExpiringReport(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	country := strings.ToUpper(r.URL.Query().Get("country"))
+	if len(country) != 2 {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "country must be an ISO country code")
+		return
+	}
+	city := r.URL.Query().Get("city")
+
+	days := defaultExpiringDocumentDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 || parsed > maxExpiringDocumentDays {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "days must be between 0 and 90")
+			return
+		}
+		days = parsed
+	}
+
+	docs, err := h.documentService.ExpiringReport(r.Context(), country, city, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to build expiring documents report")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"country":   country,
+		"city":      strings.ToUpper(city),
+		"days":      days,
+		"documents": docs,
+	})
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// DriverPreferenceService defines the favorite and blocked driver service interface
+type DriverPreferenceService interface {
+	FavoriteDriver(ctx context.Context, rideID, riderID uuid.UUID) (*domain.DriverPreference, error)
+	BlockDriver(ctx context.Context, rideID, riderID uuid.UUID, reason string) (*domain.DriverPreference, error)
+	RemoveDriver(ctx context.Context, riderID, driverID uuid.UUID) error
+	ListDrivers(ctx context.Context, riderID uuid.UUID) ([]*domain.DriverPreference, error)
+}
+
+// DriverPreferenceHandler lets riders favorite and block drivers they rode
+// with
+type DriverPreferenceHandler struct {
+	prefService DriverPreferenceService
+}
+
+// NewDriverPreferenceHandler creates a new driver preference handler
+func NewDriverPreferenceHandler(prefService DriverPreferenceService) *DriverPreferenceHandler {
+	return &DriverPreferenceHandler{prefService: prefService}
+}
+
+// BlockDriverRequest is the body of a rider reporting and blocking a driver
+type BlockDriverRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// FavoriteDriver handles POST /rides/{rideId}/favorite-driver
+func (h *DriverPreferenceHandler) FavoriteDriver(w http.ResponseWriter, r *http.Request) {
+	riderID := getUserIDFromContext(r.Context())
+	if riderID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	pref, err := h.prefService.FavoriteDriver(r.Context(), rideID, riderID)
+	if err != nil {
+		writeDriverPreferenceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pref)
+}
+
+// BlockDriver handles POST /rides/{rideId}/block-driver
+func (h *DriverPreferenceHandler) BlockDriver(w http.ResponseWriter, r *http.Request) {
+	riderID := getUserIDFromContext(r.Context())
+	if riderID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	var req BlockDriverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	pref, err := h.prefService.BlockDriver(r.Context(), rideID, riderID, req.Reason)
+	if err != nil {
+		writeDriverPreferenceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pref)
+}
+
+// ListMyDrivers handles GET /riders/me/drivers
+func (h *DriverPreferenceHandler) ListMyDrivers(w http.ResponseWriter, r *http.Request) {
+	riderID := getUserIDFromContext(r.Context())
+	if riderID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	prefs, err := h.prefService.ListDrivers(r.Context(), riderID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list drivers")
+		return
+	}
+
+	favorites := make([]*domain.DriverPreference, 0)
+	blocked := make([]*domain.DriverPreference, 0)
+	for _, p := range prefs {
+		if p.Kind == domain.DriverPreferenceBlocked {
+			blocked = append(blocked, p)
+		} else {
+			favorites = append(favorites, p)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"favorites": favorites,
+		"blocked":   blocked,
+	})
+}
+
+// RemoveMyDriver handles DELETE /riders/me/drivers/{driverId}
+func (h *DriverPreferenceHandler) RemoveMyDriver(w http.ResponseWriter, r *http.Request) {
+	riderID := getUserIDFromContext(r.Context())
+	if riderID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	driverID, err := uuid.Parse(chi.URLParam(r, "driverId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid driver ID")
+		return
+	}
+
+	if err := h.prefService.RemoveDriver(r.Context(), riderID, driverID); err != nil {
+		writeDriverPreferenceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeDriverPreferenceError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrRideNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, errMsgRideNotFound)
+	case domain.ErrForbidden:
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Not allowed to favorite or block this ride's driver")
+	case domain.ErrInvalidDriverPreference:
+		writeError(w, http.StatusConflict, domain.ErrCodeInvalidDriverPreference, err.Error())
+	case domain.ErrDriverBlocked:
+		writeError(w, http.StatusConflict, domain.ErrCodeDriverBlocked, err.Error())
+	case domain.ErrDriverPreferenceNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeDriverPreferenceNotFound, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to update driver preference")
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// DriverQualityService defines the driver quality program interface
+type DriverQualityService interface {
+	GetDriverQuality(ctx context.Context, driverID uuid.UUID) (*domain.QualityStatus, error)
+}
+
+// DriverQualityHandler shows drivers their quality tier, its benefits and
+// how to improve, and lets support look a driver's up
+type DriverQualityHandler struct {
+	qualityService DriverQualityService
+}
+
+// NewDriverQualityHandler creates a new driver quality handler
+func NewDriverQualityHandler(qualityService DriverQualityService) *DriverQualityHandler {
+	return &DriverQualityHandler{qualityService: qualityService}
+}
+
+// GetMyQuality handles GET /drivers/me/quality
+func (h *DriverQualityHandler) GetMyQuality(w http.ResponseWriter, r *http.Request) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	status, err := h.qualityService.GetDriverQuality(r.Context(), driverID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get driver quality")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// ListTiers handles GET /drivers/quality-tiers
+func (h *DriverQualityHandler) ListTiers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"tiers": domain.QualityTiers,
+		"weights": map[string]float64{
+			"rating":       domain.QualityWeightRating,
+			"cancellation": domain.QualityWeightCancellation,
+			"complaints":   domain.QualityWeightComplaints,
+			"compliance":   domain.QualityWeightCompliance,
+		},
+	})
+}
+
+// GetDriverQuality handles GET /internal/support/drivers/{driverId}/quality
+func (h *DriverQualityHandler) GetDriverQuality(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	driverID, err := uuid.Parse(chi.URLParam(r, "driverId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid driver ID")
+		return
+	}
+
+	status, err := h.qualityService.GetDriverQuality(r.Context(), driverID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get driver quality")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// Default page size for the appeal queue
+const defaultAppealQueueLimit = 50
+
+// DriverStandingService defines the driver sanction and appeal service interface
+type DriverStandingService interface {
+	SanctionDriver(ctx context.Context, driverID, issuedBy uuid.UUID, status domain.DriverStatus, reason domain.SanctionReason, note string, duration time.Duration) (*domain.DriverSanction, error)
+	ReinstateDriver(ctx context.Context, driverID, liftedBy uuid.UUID, note string) error
+	GetStanding(ctx context.Context, driverID uuid.UUID) (*domain.DriverStanding, error)
+	ListSanctions(ctx context.Context, driverID uuid.UUID) ([]*domain.DriverSanction, error)
+	SubmitAppeal(ctx context.Context, driverID uuid.UUID, statement string) (*domain.DriverAppeal, error)
+	ListAppeals(ctx context.Context, status domain.AppealStatus, limit, offset int) ([]*domain.DriverAppeal, error)
+	ReviewAppeal(ctx context.Context, appealID, reviewerID uuid.UUID, upheld bool, note string) (*domain.DriverAppeal, error)
+}
+
+// DriverStandingHandler handles driver sanction and appeal requests
+type DriverStandingHandler struct {
+	standingService DriverStandingService
+}
+
+// NewDriverStandingHandler creates a new driver standing handler
+func NewDriverStandingHandler(standingService DriverStandingService) *DriverStandingHandler {
+	return &DriverStandingHandler{standingService: standingService}
+}
+
+// SanctionDriverRequest is the body of a sanction request
+type SanctionDriverRequest struct {
+	Status        string `json:"status"`
+	Reason        string `json:"reason"`
+	Note          string `json:"note,omitempty"`
+	DurationHours int    `json:"duration_hours,omitempty"` // suspensions only; 0 means indefinite
+}
+
+// ReinstateDriverRequest is the body of a reinstatement request
+type ReinstateDriverRequest struct {
+	Note string `json:"note"`
+}
+
+// SubmitAppealRequest is the body of a driver's appeal
+type SubmitAppealRequest struct {
+	Statement string `json:"statement"`
+}
+
+// ReviewAppealRequest is the body of an appeal decision
+type ReviewAppealRequest struct {
+	Upheld bool   `json:"upheld"`
+	Note   string `json:"note"`
+}
+
+// GetMyStanding handles GET /drivers/me/standing
+func (h *DriverStandingHandler) GetMyStanding(w http.ResponseWriter, r *http.Request) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	standing, err := h.standingService.GetStanding(r.Context(), driverID)
+	if err != nil {
+		writeStandingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, standing)
+}
+
+// SubmitAppeal handles POST /drivers/me/appeals
+func (h *DriverStandingHandler) SubmitAppeal(w http.ResponseWriter, r *http.Request) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req SubmitAppealRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	appeal, err := h.standingService.SubmitAppeal(r.Context(), driverID, req.Statement)
+	if err != nil {
+		writeStandingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, appeal)
+}
+
+// ListDriverSanctions handles GET /internal/support/drivers/{driverId}/sanctions
+func (h *DriverStandingHandler) ListDriverSanctions(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	driverID, err := uuid.Parse(chi.URLParam(r, "driverId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid driver ID")
+		return
+	}
+
+	standing, err := h.standingService.GetStanding(r.Context(), driverID)
+	if err != nil {
+		writeStandingError(w, err)
+		return
+	}
+
+	sanctions, err := h.standingService.ListSanctions(r.Context(), driverID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list sanctions")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"standing":  standing,
+		"sanctions": sanctions,
+	})
+}
+
+// SanctionDriver handles POST /internal/support/drivers/{driverId}/sanctions
+func (h *DriverStandingHandler) SanctionDriver(w http.ResponseWriter, r *http.Request) {
+	if !domain.CanSanctionDrivers(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Sanctioning drivers requires a support lead")
+		return
+	}
+	agentID := getUserIDFromContext(r.Context())
+	if agentID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	driverID, err := uuid.Parse(chi.URLParam(r, "driverId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid driver ID")
+		return
+	}
+
+	var req SanctionDriverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	sanction, err := h.standingService.SanctionDriver(r.Context(), driverID, agentID,
+		domain.DriverStatus(req.Status), domain.SanctionReason(req.Reason), req.Note,
+		time.Duration(req.DurationHours)*time.Hour,
+	)
+	if err != nil {
+		writeStandingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, sanction)
+}
+
+// ReinstateDriver handles POST /internal/support/drivers/{driverId}/reinstate
+func (h *DriverStandingHandler) ReinstateDriver(w http.ResponseWriter, r *http.Request) {
+	if !domain.CanSanctionDrivers(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Reinstating drivers requires a support lead")
+		return
+	}
+	agentID := getUserIDFromContext(r.Context())
+	if agentID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	driverID, err := uuid.Parse(chi.URLParam(r, "driverId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid driver ID")
+		return
+	}
+
+	var req ReinstateDriverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		req.Note = "Reinstated by support"
+	}
+
+	if err := h.standingService.ReinstateDriver(r.Context(), driverID, agentID, req.Note); err != nil {
+		writeStandingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Driver reinstated"})
+}
+
+// ListAppeals handles GET /internal/support/appeals
+func (h *DriverStandingHandler) ListAppeals(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	status := domain.AppealStatusPending
+	if s := r.URL.Query().Get("status"); s != "" {
+		status = domain.AppealStatus(s)
+	}
+
+	limit := defaultAppealQueueLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	offset := 0
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o > 0 {
+		offset = o
+	}
+
+	appeals, err := h.standingService.ListAppeals(r.Context(), status, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list appeals")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"appeals": appeals,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// ReviewAppeal handles POST /internal/support/appeals/{appealId}/review
+func (h *DriverStandingHandler) ReviewAppeal(w http.ResponseWriter, r *http.Request) {
+	if !domain.CanSanctionDrivers(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Reviewing appeals requires a support lead")
+		return
+	}
+	reviewerID := getUserIDFromContext(r.Context())
+	if reviewerID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	appealID, err := uuid.Parse(chi.URLParam(r, "appealId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid appeal ID")
+		return
+	}
+
+	var req ReviewAppealRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	appeal, err := h.standingService.ReviewAppeal(r.Context(), appealID, reviewerID, req.Upheld, req.Note)
+	if err != nil {
+		writeStandingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, appeal)
+}
+
+func writeStandingError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrDriverNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeDriverNotFound, "Driver not found")
+	case domain.ErrDriverNotRestricted:
+		writeError(w, http.StatusConflict, domain.ErrCodeDriverNotRestricted, err.Error())
+	case domain.ErrAppealNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeAppealNotFound, "Appeal not found")
+	case domain.ErrAppealNotAllowed:
+		writeError(w, http.StatusUnprocessableEntity, domain.ErrCodeAppealNotAllowed, err.Error())
+	case domain.ErrAppealAlreadyPending:
+		writeError(w, http.StatusConflict, domain.ErrCodeAppealAlreadyPending, err.Error())
+	case domain.ErrAppealNotPending:
+		writeError(w, http.StatusConflict, domain.ErrCodeAppealNotPending, err.Error())
+	case domain.ErrInvalidSanction, domain.ErrInvalidRequest:
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to process request")
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// EarningsService defines the driver earnings service interface
+type EarningsService interface {
+	GetSummary(ctx context.Context, driverID uuid.UUID, period domain.EarningsPeriod) (*domain.EarningsSummary, error)
+}
+
+// EarningsHandler serves drivers' combined ride and delivery earnings
+type EarningsHandler struct {
+	earningsService EarningsService
+}
+
+// NewEarningsHandler creates a new earnings handler
+func NewEarningsHandler(earningsService EarningsService) *EarningsHandler {
+	return &EarningsHandler{earningsService: earningsService}
+}
+
+// GetMyEarnings handles GET /drivers/me/earnings?period=day|week|month
+func (h *EarningsHandler) GetMyEarnings(w http.ResponseWriter, r *http.Request) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	period := domain.EarningsPeriod(r.URL.Query().Get("period"))
+	if period == "" {
+		period = domain.EarningsPeriodDay
+	}
+
+	summary, err := h.earningsService.GetSummary(r.Context(), driverID, period)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidEarningsPeriod) {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidEarningsPeriod, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to load earnings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// EmissionsService defines the ride emissions service interface
+type EmissionsService interface {
+	GetRiderStats(ctx context.Context, riderID uuid.UUID, from, to *time.Time) (*domain.EmissionsStats, error)
+	GetBusinessStats(ctx context.Context, accountID, userID uuid.UUID, role string, from, to *time.Time) (*domain.EmissionsStats, error)
+}
+
+// EmissionsHandler serves the estimated emissions of riders' and business
+// accounts' completed rides
+type EmissionsHandler struct {
+	emissions EmissionsService
+}
+
+// NewEmissionsHandler creates a new emissions handler
+func NewEmissionsHandler(emissions EmissionsService) *EmissionsHandler {
+	return &EmissionsHandler{emissions: emissions}
+}
+
+// GetMyEmissions handles GET /riders/me/emissions?from=&to=, summing the
+// rider's rides completed in the range, or ever when none is given
+func (h *EmissionsHandler) GetMyEmissions(w http.ResponseWriter, r *http.Request) {
+	riderID := getUserIDFromContext(r.Context())
+	if riderID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	from, to, err := parseEmissionsRange(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	stats, err := h.emissions.GetRiderStats(r.Context(), riderID, from, to)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get emissions")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// GetBusinessEmissions handles GET /business-accounts/{accountId}/emissions?from=&to=
+// for the account's admins and platform admins
+func (h *EmissionsHandler) GetBusinessEmissions(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	accountID, err := uuid.Parse(chi.URLParam(r, "accountId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid business account ID")
+		return
+	}
+	from, to, err := parseEmissionsRange(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	stats, err := h.emissions.GetBusinessStats(
+		r.Context(), accountID, userID, getUserRoleFromContext(r.Context()), from, to,
+	)
+	if err != nil {
+		writeBusinessError(w, err, "Failed to get business account emissions")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// parseEmissionsRange reads an optional from and to, each RFC 3339 or a
+// date. A date for to includes the whole day.
+func parseEmissionsRange(query url.Values) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+	if v := query.Get("from"); v != "" {
+		t, _, err := parseHistoryTime(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid from: %w", err)
+		}
+		from = &t
+	}
+	if v := query.Get("to"); v != "" {
+		t, day, err := parseHistoryTime(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid to: %w", err)
+		}
+		if day {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = &t
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, fmt.Errorf("from must be before to")
+	}
+	return from, to, nil
+}
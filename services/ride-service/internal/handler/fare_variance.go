@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// Period the fare variance report covers when none is given
+const defaultFareVarianceReportPeriod = 7 * 24 * time.Hour
+
+// FareVarianceService defines the fare variance monitoring service interface
+type FareVarianceService interface {
+	GetReport(ctx context.Context, from, to time.Time) (*domain.FareVarianceReport, error)
+}
+
+// FareVarianceHandler serves the quoted-vs-final fare variance report
+type FareVarianceHandler struct {
+	varianceService FareVarianceService
+}
+
+// NewFareVarianceHandler creates a new fare variance handler
+func NewFareVarianceHandler(varianceService FareVarianceService) *FareVarianceHandler {
+	return &FareVarianceHandler{varianceService: varianceService}
+}
+
+// GetReport handles GET /internal/admin/fare-variance?from=&to=
+func (h *FareVarianceHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	to := time.Now().UTC()
+	if v := r.URL.Query().Get("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "to must be an RFC3339 timestamp")
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-defaultFareVarianceReportPeriod)
+	if v := r.URL.Query().Get("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil || !parsed.Before(to) {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "from must be an RFC3339 timestamp before to")
+			return
+		}
+		from = parsed
+	}
+
+	report, err := h.varianceService.GetReport(r.Context(), from, to)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to build fare variance report")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// FareExplainService defines the fare explanation service interface
+type FareExplainService interface {
+	ExplainFare(ctx context.Context, rideID uuid.UUID) (*domain.Ride, *domain.FareExplanation, error)
+}
+
+// FareHandler serves how ride fares were computed
+type FareHandler struct {
+	fareService FareExplainService
+	rates       ExchangeRateSource
+}
+
+// NewFareHandler creates a new fare handler
+func NewFareHandler(fareService FareExplainService) *FareHandler {
+	return &FareHandler{fareService: fareService}
+}
+
+// SetRates shows fare totals converted into the rider's home currency, or
+// the one they ask for, beside the local fare
+func (h *FareHandler) SetRates(rates ExchangeRateSource) {
+	h.rates = rates
+}
+
+// ExplainFare handles GET /rides/{rideId}/fare/explain. The ride's rider and
+// driver and support staff may view it. ?display_currency= converts the
+// total for display; the fare itself stays in the ride's currency.
+func (h *FareHandler) ExplainFare(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	display, err := displayCurrency(r.Context(), r.URL.Query().Get("display_currency"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeUnsupportedCurrency, "Unsupported display currency")
+		return
+	}
+
+	ride, explanation, err := h.fareService.ExplainFare(r.Context(), rideID)
+	if err != nil {
+		if errors.Is(err, domain.ErrRideNotFound) {
+			writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, "Ride not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to explain fare")
+		return
+	}
+
+	isParticipant := ride.RiderID == userID || (ride.DriverID != nil && *ride.DriverID == userID)
+	if !isParticipant && !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Not allowed to view this fare")
+		return
+	}
+
+	if explanation.Price != nil {
+		explanation.Display = displayAmount(h.rates, explanation.Price.Total, explanation.Price.Currency, display)
+	}
+	writeJSON(w, http.StatusOK, explanation)
+}
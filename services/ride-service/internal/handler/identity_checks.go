@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// IdentityCheckService defines the shift-start selfie check service interface
+type IdentityCheckService interface {
+	SubmitSelfie(ctx context.Context, driverID uuid.UUID, selfieURL string) (*domain.IdentityCheck, error)
+	OverrideLockout(ctx context.Context, driverID, reviewerID uuid.UUID, note string) (*domain.IdentityCheck, error)
+	ListChecks(ctx context.Context, driverID uuid.UUID) ([]*domain.IdentityCheck, error)
+}
+
+// IdentityCheckHandler handles driver selfie checks and their manual review
+type IdentityCheckHandler struct {
+	identityService IdentityCheckService
+}
+
+// NewIdentityCheckHandler creates a new identity check handler
+func NewIdentityCheckHandler(identityService IdentityCheckService) *IdentityCheckHandler {
+	return &IdentityCheckHandler{identityService: identityService}
+}
+
+// SubmitSelfieRequest is the body of a driver's selfie check
+type SubmitSelfieRequest struct {
+	SelfieURL string `json:"selfie_url"`
+}
+
+// OverrideLockoutRequest is the body of a manual identity review
+type OverrideLockoutRequest struct {
+	Note string `json:"note"`
+}
+
+// SubmitSelfie handles POST /drivers/me/identity-checks
+func (h *IdentityCheckHandler) SubmitSelfie(w http.ResponseWriter, r *http.Request) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req SubmitSelfieRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	check, err := h.identityService.SubmitSelfie(r.Context(), driverID, req.SelfieURL)
+	if err != nil {
+		writeIdentityCheckError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, check)
+}
+
+// ListDriverChecks handles GET /internal/support/drivers/{driverId}/identity-checks
+func (h *IdentityCheckHandler) ListDriverChecks(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	driverID, err := uuid.Parse(chi.URLParam(r, "driverId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid driver ID")
+		return
+	}
+
+	checks, err := h.identityService.ListChecks(r.Context(), driverID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list identity checks")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"checks": checks})
+}
+
+// OverrideLockout handles POST /internal/support/drivers/{driverId}/identity-override
+func (h *IdentityCheckHandler) OverrideLockout(w http.ResponseWriter, r *http.Request) {
+	if !domain.CanSanctionDrivers(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Overriding identity checks requires a support lead")
+		return
+	}
+	reviewerID := getUserIDFromContext(r.Context())
+	if reviewerID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	driverID, err := uuid.Parse(chi.URLParam(r, "driverId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid driver ID")
+		return
+	}
+
+	var req OverrideLockoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Note == "" {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "A review note is required")
+		return
+	}
+
+	check, err := h.identityService.OverrideLockout(r.Context(), driverID, reviewerID, req.Note)
+	if err != nil {
+		writeIdentityCheckError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, check)
+}
+
+func writeIdentityCheckError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrDriverNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeDriverNotFound, "Driver not found")
+	case domain.ErrIdentityCheckLocked:
+		writeError(w, http.StatusForbidden, domain.ErrCodeIdentityCheckLocked, err.Error())
+	case domain.ErrIdentityCheckNotLocked:
+		writeError(w, http.StatusConflict, domain.ErrCodeIdentityCheckNotLocked, err.Error())
+	case domain.ErrProfilePhotoMissing:
+		writeError(w, http.StatusUnprocessableEntity, domain.ErrCodeProfilePhotoMissing, err.Error())
+	case domain.ErrFaceMatchUnavailable:
+		writeError(w, http.StatusServiceUnavailable, domain.ErrCodeFaceMatchUnavailable, "Selfie check is temporarily unavailable")
+	case domain.ErrInvalidRequest:
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Selfie URL is required")
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to process identity check")
+	}
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// IncidentService defines the traffic incident interface
+type IncidentService interface {
+	Report(ctx context.Context, incident *domain.TrafficIncident) error
+	List(ctx context.Context) ([]*domain.TrafficIncident, error)
+	Resolve(ctx context.Context, incidentID uuid.UUID) error
+}
+
+// IncidentHandler lets ops report, list and resolve road incidents
+type IncidentHandler struct {
+	incidentService IncidentService
+}
+
+// NewIncidentHandler creates a new incident handler
+func NewIncidentHandler(incidentService IncidentService) *IncidentHandler {
+	return &IncidentHandler{incidentService: incidentService}
+}
+
+// ReportIncidentRequest is an ops report of a road incident
+type ReportIncidentRequest struct {
+	Type            string     `json:"type"`
+	Latitude        float64    `json:"latitude"`
+	Longitude       float64    `json:"longitude"`
+	RadiusMeters    float64    `json:"radius_meters,omitempty"`
+	Description     string     `json:"description,omitempty"`
+	DelayMultiplier float64    `json:"delay_multiplier,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+}
+
+// ReportIncident handles POST /internal/admin/incidents
+func (h *IncidentHandler) ReportIncident(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	var req ReportIncidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	incident := &domain.TrafficIncident{
+		Type:            domain.IncidentType(req.Type),
+		Source:          domain.IncidentSourceOps,
+		Description:     req.Description,
+		Latitude:        req.Latitude,
+		Longitude:       req.Longitude,
+		RadiusMeters:    req.RadiusMeters,
+		DelayMultiplier: req.DelayMultiplier,
+	}
+	if req.ExpiresAt != nil {
+		incident.ExpiresAt = req.ExpiresAt.UTC()
+	}
+	if agentID := getUserIDFromContext(r.Context()); agentID != uuid.Nil {
+		incident.ReportedBy = &agentID
+	}
+
+	err := h.incidentService.Report(r.Context(), incident)
+	if errors.Is(err, domain.ErrInvalidLocation) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidLocation, "Invalid incident location")
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidIncidentType) || errors.Is(err, domain.ErrInvalidIncidentRadius) ||
+		errors.Is(err, domain.ErrInvalidIncidentExpiry) || errors.Is(err, domain.ErrInvalidIncidentDelay) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidIncident, err.Error())
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to report incident")
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to report incident")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, incident)
+}
+
+// ListIncidents handles GET /internal/admin/incidents
+func (h *IncidentHandler) ListIncidents(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	incidents, err := h.incidentService.List(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list incidents")
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list incidents")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"incidents": incidents,
+	})
+}
+
+// ResolveIncident handles DELETE /internal/admin/incidents/{incidentId}
+func (h *IncidentHandler) ResolveIncident(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	incidentID, err := uuid.Parse(chi.URLParam(r, "incidentId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid incident ID")
+		return
+	}
+
+	err = h.incidentService.Resolve(r.Context(), incidentID)
+	if errors.Is(err, domain.ErrIncidentNotFound) {
+		writeError(w, http.StatusNotFound, domain.ErrCodeIncidentNotFound, "Incident not found")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to resolve incident")
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to resolve incident")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/service"
+)
+
+const defaultClaimQueueLimit = 50
+
+// InsuranceService defines the ride insurance and claims interface
+type InsuranceService interface {
+	GetRidePolicy(ctx context.Context, rideID, userID uuid.UUID, support bool) (*domain.InsurancePolicy, error)
+	FileClaim(ctx context.Context, req *service.ClaimRequest) (*domain.InsuranceClaim, error)
+	ListRideClaims(ctx context.Context, rideID, userID uuid.UUID, support bool) ([]*domain.InsuranceClaim, error)
+	GetClaim(ctx context.Context, id uuid.UUID) (*domain.InsuranceClaim, error)
+	ListClaims(ctx context.Context, status domain.InsuranceClaimStatus, limit, offset int) ([]*domain.InsuranceClaim, int64, error)
+	ListIncidentClaims(ctx context.Context, incidentID uuid.UUID) ([]*domain.InsuranceClaim, error)
+	UpdateClaim(ctx context.Context, id, agentID uuid.UUID, update *service.ClaimUpdate) (*domain.InsuranceClaim, error)
+}
+
+// InsuranceHandler shows riders and drivers their ride's cover, takes their
+// claims and lets support track the claims with the insurer
+type InsuranceHandler struct {
+	insuranceService InsuranceService
+}
+
+// NewInsuranceHandler creates a new insurance handler
+func NewInsuranceHandler(insuranceService InsuranceService) *InsuranceHandler {
+	return &InsuranceHandler{insuranceService: insuranceService}
+}
+
+// FileClaimRequest is the body of an insurance claim
+type FileClaimRequest struct {
+	Type        string     `json:"type"`
+	Description string     `json:"description"`
+	IncidentAt  *time.Time `json:"incident_at,omitempty"`
+}
+
+// UpdateClaimRequest is support moving a claim on
+type UpdateClaimRequest struct {
+	Status           string `json:"status"`
+	ProviderClaimRef string `json:"provider_claim_ref,omitempty"`
+	SettledAmount    int64  `json:"settled_amount,omitempty"`
+	Note             string `json:"note,omitempty"`
+}
+
+// GetPolicy handles GET /rides/{rideId}/insurance
+func (h *InsuranceHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	support := domain.IsSupportRole(getUserRoleFromContext(r.Context()))
+	policy, err := h.insuranceService.GetRidePolicy(r.Context(), rideID, userID, support)
+	if err != nil {
+		writeInsuranceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, policy)
+}
+
+// FileClaim handles POST /rides/{rideId}/insurance/claims
+func (h *InsuranceHandler) FileClaim(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	var req FileClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	claim, err := h.insuranceService.FileClaim(r.Context(), &service.ClaimRequest{
+		RideID:      rideID,
+		UserID:      userID,
+		Type:        domain.InsuranceClaimType(req.Type),
+		Description: req.Description,
+		IncidentAt:  req.IncidentAt,
+	})
+	if err != nil {
+		writeInsuranceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, claim)
+}
+
+// ListRideClaims handles GET /rides/{rideId}/insurance/claims
+func (h *InsuranceHandler) ListRideClaims(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	support := domain.IsSupportRole(getUserRoleFromContext(r.Context()))
+	claims, err := h.insuranceService.ListRideClaims(r.Context(), rideID, userID, support)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list insurance claims")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"claims": claims,
+	})
+}
+
+// ListClaims handles GET /internal/support/insurance-claims
+func (h *InsuranceHandler) ListClaims(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	status := domain.InsuranceClaimSubmitted
+	if s := r.URL.Query().Get("status"); s != "" {
+		status = domain.InsuranceClaimStatus(s)
+		if !status.IsValid() {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "status must be SUBMITTED, UNDER_REVIEW, FILED, SETTLED or REJECTED")
+			return
+		}
+	}
+
+	limit := defaultClaimQueueLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	offset := 0
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o > 0 {
+		offset = o
+	}
+
+	claims, total, err := h.insuranceService.ListClaims(r.Context(), status, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list insurance claims")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"claims": claims,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// GetClaim handles GET /internal/support/insurance-claims/{claimId}
+func (h *InsuranceHandler) GetClaim(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	claimID, err := uuid.Parse(chi.URLParam(r, "claimId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid claim ID")
+		return
+	}
+
+	claim, err := h.insuranceService.GetClaim(r.Context(), claimID)
+	if err != nil {
+		writeInsuranceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, claim)
+}
+
+// UpdateClaim handles POST /internal/support/insurance-claims/{claimId}/status
+func (h *InsuranceHandler) UpdateClaim(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+	agentID := getUserIDFromContext(r.Context())
+	if agentID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	claimID, err := uuid.Parse(chi.URLParam(r, "claimId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid claim ID")
+		return
+	}
+
+	var req UpdateClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+	status := domain.InsuranceClaimStatus(req.Status)
+	if !status.IsValid() {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "status must be UNDER_REVIEW, FILED, SETTLED or REJECTED")
+		return
+	}
+
+	claim, err := h.insuranceService.UpdateClaim(r.Context(), claimID, agentID, &service.ClaimUpdate{
+		Status:           status,
+		ProviderClaimRef: req.ProviderClaimRef,
+		SettledAmount:    req.SettledAmount,
+		Note:             req.Note,
+	})
+	if err != nil {
+		writeInsuranceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, claim)
+}
+
+// ListIncidentClaims handles GET /internal/support/safety-incidents/{incidentId}/insurance-claims
+func (h *InsuranceHandler) ListIncidentClaims(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	incidentID, err := uuid.Parse(chi.URLParam(r, "incidentId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid incident ID")
+		return
+	}
+
+	claims, err := h.insuranceService.ListIncidentClaims(r.Context(), incidentID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list insurance claims")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"claims": claims,
+	})
+}
+
+func writeInsuranceError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrRideNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, errMsgRideNotFound)
+	case domain.ErrForbidden:
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Only the ride's rider or driver can do this")
+	case domain.ErrRideNotInsured:
+		writeError(w, http.StatusNotFound, domain.ErrCodeRideNotInsured, err.Error())
+	case domain.ErrInsuranceClaimNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeInsuranceClaimNotFound, err.Error())
+	case domain.ErrInsuranceClaimOpen:
+		writeError(w, http.StatusConflict, domain.ErrCodeInsuranceClaimOpen, err.Error())
+	case domain.ErrInsuranceClaimWindowClosed:
+		writeError(w, http.StatusUnprocessableEntity, domain.ErrCodeClaimWindowClosed, err.Error())
+	case domain.ErrInvalidInsuranceClaim:
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidInsuranceClaim, err.Error())
+	case domain.ErrInvalidClaimTransition:
+		writeError(w, http.StatusConflict, domain.ErrCodeInvalidClaimTransition, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to process insurance request")
+	}
+}
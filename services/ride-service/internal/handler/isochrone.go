@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/eta"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+)
+
+// Isochrone contour limits; Valhalla serves at most four contours per call
+const (
+	maxIsochroneContours = 4
+	maxIsochroneMinutes  = 60
+)
+
+// IsochroneProvider computes road-network reachability polygons
+type IsochroneProvider interface {
+	Isochrones(ctx context.Context, req *eta.IsochroneRequest) ([]*eta.Isochrone, error)
+}
+
+// IsochroneHandler serves "reachable within N minutes" polygons for the
+// driver heatmap and delivery zone checks
+type IsochroneHandler struct {
+	provider IsochroneProvider
+}
+
+// NewIsochroneHandler creates a new isochrone handler
+func NewIsochroneHandler(provider IsochroneProvider) *IsochroneHandler {
+	return &IsochroneHandler{provider: provider}
+}
+
+// IsochroneResponse is the response for an isochrone lookup
+type IsochroneResponse struct {
+	Profile    eta.RoutingProfile `json:"profile"`
+	Reverse    bool               `json:"reverse"`
+	Isochrones []*eta.Isochrone   `json:"isochrones"`
+}
+
+// GetIsochrones handles GET /eta/isochrone?lat=...&lng=...&minutes=5,10,15&profile=car&reverse=true
+// Reverse isochrones hold the points that can reach the location, as for
+// drivers heading to a pickup.
+func (h *IsochroneHandler) GetIsochrones(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	lat, latErr := strconv.ParseFloat(query.Get("lat"), 64)
+	lng, lngErr := strconv.ParseFloat(query.Get("lng"), 64)
+	if latErr != nil || lngErr != nil || !geo.IsValidCoordinate(lat, lng) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidLocation, "Invalid location")
+		return
+	}
+
+	req := &eta.IsochroneRequest{
+		Lat:     lat,
+		Lng:     lng,
+		Profile: eta.ProfileCar,
+		Reverse: query.Get("reverse") == "true",
+	}
+	if profile := query.Get("profile"); profile != "" {
+		req.Profile = eta.RoutingProfile(profile)
+		if !req.Profile.IsValid() {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "profile must be car, motorcycle, bicycle or truck")
+			return
+		}
+	}
+
+	minutes := query.Get("minutes")
+	if minutes == "" {
+		minutes = "5,10,15"
+	}
+	for _, value := range strings.Split(minutes, ",") {
+		contour, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || contour < 1 || contour > maxIsochroneMinutes {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "minutes must be between 1 and 60")
+			return
+		}
+		req.Minutes = append(req.Minutes, contour)
+	}
+	if len(req.Minutes) > maxIsochroneContours {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "At most 4 contours are allowed")
+		return
+	}
+	sort.Ints(req.Minutes)
+
+	isochrones, err := h.provider.Isochrones(r.Context(), req)
+	if err != nil {
+		log.Error().Err(err).Float64("lat", lat).Float64("lng", lng).Msg("Failed to compute isochrones")
+		writeError(w, http.StatusBadGateway, domain.ErrCodeRouteNotFound, "Failed to compute isochrones")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, IsochroneResponse{
+		Profile:    req.Profile,
+		Reverse:    req.Reverse,
+		Isochrones: isochrones,
+	})
+}
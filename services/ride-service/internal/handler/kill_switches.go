@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// KillSwitchService defines the city kill switch interface
+type KillSwitchService interface {
+	Disable(ctx context.Context, sw *domain.KillSwitch) error
+	Enable(ctx context.Context, cityCode string, feature domain.KillSwitchFeature, actorID uuid.UUID) error
+	List(ctx context.Context) ([]*domain.KillSwitch, error)
+	Audit(ctx context.Context, cityCode string) ([]*domain.KillSwitchAudit, error)
+}
+
+// KillSwitchChecker reports whether a feature is switched off in a city
+type KillSwitchChecker interface {
+	Check(ctx context.Context, cityCode string, feature domain.KillSwitchFeature) (*domain.KillSwitch, error)
+}
+
+// KillSwitchHandler lets ops switch ride requests, new deliveries or surge
+// off city by city
+type KillSwitchHandler struct {
+	killSwitches KillSwitchService
+}
+
+// NewKillSwitchHandler creates a new kill switch handler
+func NewKillSwitchHandler(killSwitches KillSwitchService) *KillSwitchHandler {
+	return &KillSwitchHandler{killSwitches: killSwitches}
+}
+
+// DisableFeatureRequest switches a feature off. Without reenable_at or
+// duration_minutes it stays off until switched back on.
+type DisableFeatureRequest struct {
+	Reason          string     `json:"reason"`
+	ReenableAt      *time.Time `json:"reenable_at,omitempty"`
+	DurationMinutes int        `json:"duration_minutes,omitempty"`
+}
+
+// DisableFeature handles PUT /internal/admin/kill-switches/{city}/{feature}
+func (h *KillSwitchHandler) DisableFeature(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	var req DisableFeatureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	sw := &domain.KillSwitch{
+		City:       strings.ToUpper(chi.URLParam(r, "city")),
+		Feature:    domain.KillSwitchFeature(strings.ToUpper(chi.URLParam(r, "feature"))),
+		Reason:     strings.TrimSpace(req.Reason),
+		DisabledBy: getUserIDFromContext(r.Context()),
+	}
+	switch {
+	case req.ReenableAt != nil:
+		reenableAt := req.ReenableAt.UTC()
+		sw.ReenableAt = &reenableAt
+	case req.DurationMinutes > 0:
+		reenableAt := time.Now().UTC().Add(time.Duration(req.DurationMinutes) * time.Minute)
+		sw.ReenableAt = &reenableAt
+	}
+
+	err := h.killSwitches.Disable(r.Context(), sw)
+	if errors.Is(err, domain.ErrCityNotFound) {
+		writeError(w, http.StatusNotFound, domain.ErrCodeCityNotFound, "City not found")
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidKillSwitchFeature) || errors.Is(err, domain.ErrKillSwitchReasonRequired) ||
+		errors.Is(err, domain.ErrInvalidKillSwitchExpiry) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidKillSwitch, err.Error())
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to switch feature off")
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to switch feature off")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sw)
+}
+
+// EnableFeature handles DELETE /internal/admin/kill-switches/{city}/{feature}
+func (h *KillSwitchHandler) EnableFeature(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	city := strings.ToUpper(chi.URLParam(r, "city"))
+	feature := domain.KillSwitchFeature(strings.ToUpper(chi.URLParam(r, "feature")))
+
+	err := h.killSwitches.Enable(r.Context(), city, feature, getUserIDFromContext(r.Context()))
+	if errors.Is(err, domain.ErrInvalidKillSwitchFeature) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidKillSwitch, err.Error())
+		return
+	}
+	if errors.Is(err, domain.ErrKillSwitchNotFound) {
+		writeError(w, http.StatusNotFound, domain.ErrCodeKillSwitchNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to switch feature on")
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to switch feature on")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListKillSwitches handles GET /internal/admin/kill-switches
+func (h *KillSwitchHandler) ListKillSwitches(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	switches, err := h.killSwitches.List(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list kill switches")
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list kill switches")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"kill_switches": switches,
+	})
+}
+
+// GetKillSwitchAudit handles GET /internal/admin/kill-switches/audit
+func (h *KillSwitchHandler) GetKillSwitchAudit(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	entries, err := h.killSwitches.Audit(r.Context(), strings.ToUpper(r.URL.Query().Get("city")))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get kill switch audit log")
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get kill switch audit log")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"audit": entries,
+	})
+}
+
+// writeFeatureDisabled tells the client a feature is switched off in its
+// city, why, and when it is due back if ops set a time
+func writeFeatureDisabled(w http.ResponseWriter, sw *domain.KillSwitch) {
+	if sw.ReenableAt != nil {
+		if wait := time.Until(*sw.ReenableAt); wait > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(APIResponse{
+		Success: false,
+		Error: &APIError{
+			Code:    domain.ErrCodeFeatureDisabled,
+			Message: sw.Reason,
+			Details: map[string]interface{}{
+				"city":        sw.City,
+				"feature":     sw.Feature,
+				"reason":      sw.Reason,
+				"reenable_at": sw.ReenableAt,
+			},
+		},
+	})
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/eta"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+)
+
+// LiveETAProvider routes a vehicle from where it is now to a destination,
+// adjusted for current traffic
+type LiveETAProvider interface {
+	GetETA(ctx context.Context, req *eta.ETARequest) (*eta.ETAResponse, error)
+}
+
+// LiveETAHandler serves live ETAs for vehicles on the move, such as
+// couriers heading to a dropoff
+type LiveETAHandler struct {
+	provider LiveETAProvider
+}
+
+// NewLiveETAHandler creates a new live ETA handler
+func NewLiveETAHandler(provider LiveETAProvider) *LiveETAHandler {
+	return &LiveETAHandler{provider: provider}
+}
+
+// LiveETAResponse is the response for a live ETA lookup
+type LiveETAResponse struct {
+	Profile         eta.RoutingProfile `json:"profile"`
+	DurationSeconds int64              `json:"duration_seconds"`
+	DistanceMeters  float64            `json:"distance_meters"`
+	TrafficLevel    string             `json:"traffic_level"`
+	Confidence      float64            `json:"confidence"`
+}
+
+// GetLiveETA handles GET /eta/live?origin_lat=...&origin_lng=...&dest_lat=...&dest_lng=...&profile=motorcycle
+func (h *LiveETAHandler) GetLiveETA(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	coords := make([]float64, 0, 4)
+	for _, param := range []string{"origin_lat", "origin_lng", "dest_lat", "dest_lng"} {
+		value, err := strconv.ParseFloat(query.Get(param), 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid "+param)
+			return
+		}
+		coords = append(coords, value)
+	}
+
+	if !geo.IsValidCoordinate(coords[0], coords[1]) || !geo.IsValidCoordinate(coords[2], coords[3]) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidLocation, "Invalid location")
+		return
+	}
+
+	profile := eta.ProfileCar
+	if value := query.Get("profile"); value != "" {
+		profile = eta.RoutingProfile(value)
+		if !profile.IsValid() {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "profile must be car, motorcycle, bicycle or truck")
+			return
+		}
+	}
+
+	// GetLiveETA always routes for cars, so build the request here to
+	// carry the profile
+	resp, err := h.provider.GetETA(r.Context(), &eta.ETARequest{
+		OriginLat:     coords[0],
+		OriginLng:     coords[1],
+		DestLat:       coords[2],
+		DestLng:       coords[3],
+		DepartureTime: time.Now(),
+		Profile:       profile,
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to get live ETA")
+		writeError(w, http.StatusBadGateway, domain.ErrCodeRouteNotFound, "Failed to get live ETA")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, LiveETAResponse{
+		Profile:         profile,
+		DurationSeconds: int64(resp.Duration.Seconds()),
+		DistanceMeters:  resp.Distance,
+		TrafficLevel:    resp.TrafficLevel,
+		Confidence:      resp.Confidence,
+	})
+}
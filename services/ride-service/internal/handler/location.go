@@ -2,24 +2,74 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/locale"
 )
 
+// SuggestionRanker re-ranks autocomplete results with prediction signals
+type SuggestionRanker interface {
+	Rank(ctx context.Context, userID uuid.UUID, input string, lat, lng float64, search []*domain.LocationSuggestion) []*domain.LocationSuggestion
+}
+
+// BatchGeocoder geocodes lists of addresses
+type BatchGeocoder interface {
+	GeocodeBatch(ctx context.Context, addresses []string, components, language string) []*domain.GeocodedAddress
+}
+
 // LocationHandler handles location-related HTTP requests (Google Maps integration)
 type LocationHandler struct {
 	mapsClient *geo.MapsClient
+	cities     *cityconfig.Registry
+	ranker     SuggestionRanker
+	geocoder   BatchGeocoder
+	what3words What3WordsResolver
 }
 
-// NewLocationHandler creates a new location handler
-func NewLocationHandler(mapsClient *geo.MapsClient) *LocationHandler {
+// NewLocationHandler creates a new location handler. Searches are restricted
+// to the request's country, or else the countries of configured cities,
+// unless the client asks otherwise. ranker may be nil, in which case
+// autocomplete returns search results in the provider's order.
+func NewLocationHandler(mapsClient *geo.MapsClient, cities *cityconfig.Registry, ranker SuggestionRanker, geocoder BatchGeocoder) *LocationHandler {
 	return &LocationHandler{
 		mapsClient: mapsClient,
+		cities:     cities,
+		ranker:     ranker,
+		geocoder:   geocoder,
+	}
+}
+
+// components returns the client's component filter, else the request's
+// country, else the launched countries
+func (h *LocationHandler) components(r *http.Request) string {
+	if components := r.URL.Query().Get("components"); components != "" {
+		return components
+	}
+	if country := locale.Country(r.Context()); country != "" {
+		return "country:" + strings.ToLower(country)
 	}
+	if h.cities != nil {
+		return h.cities.CountryComponents()
+	}
+	return ""
+}
+
+// language returns the client's language parameter or the request's language
+func (h *LocationHandler) language(r *http.Request) string {
+	if language := r.URL.Query().Get("language"); language != "" {
+		return language
+	}
+	return locale.Language(r.Context())
 }
 
 // AutocompleteLocation handles Places Autocomplete requests
@@ -73,8 +123,8 @@ func (h *LocationHandler) AutocompleteLocation(w http.ResponseWriter, r *http.Re
 		Lat:        lat,
 		Lng:        lng,
 		RadiusM:    radius,
-		Language:   r.URL.Query().Get("language"),
-		Components: r.URL.Query().Get("components"),
+		Language:   h.language(r),
+		Components: h.components(r),
 		Types:      r.URL.Query().Get("types"),
 	}
 
@@ -87,23 +137,22 @@ func (h *LocationHandler) AutocompleteLocation(w http.ResponseWriter, r *http.Re
 	}
 
 	// Transform to our response format
-	type Prediction struct {
-		PlaceID       string `json:"place_id"`
-		MainText      string `json:"main_text"`
-		SecondaryText string `json:"secondary_text"`
-		Description   string `json:"description"`
-	}
-
-	predictions := make([]Prediction, 0, len(result.Predictions))
+	predictions := make([]*domain.LocationSuggestion, 0, len(result.Predictions))
 	for _, p := range result.Predictions {
-		predictions = append(predictions, Prediction{
+		predictions = append(predictions, &domain.LocationSuggestion{
 			PlaceID:       p.PlaceID,
 			MainText:      p.MainText,
 			SecondaryText: p.SecondaryText,
 			Description:   p.Description,
+			Source:        domain.SuggestionSourceSearch,
 		})
 	}
 
+	// Blend in the rider's own places and popular destinations
+	if h.ranker != nil {
+		predictions = h.ranker.Rank(ctx, getUserIDFromContext(ctx), input, lat, lng, predictions)
+	}
+
 	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
 		"success":     true,
 		"predictions": predictions,
@@ -128,11 +177,37 @@ func (h *LocationHandler) GeocodeAddress(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if h.what3words != nil && geo.IsWhat3Words(address) {
+		h.geocodeWhat3Words(w, r, address)
+		return
+	}
+
+	// Plus codes decode locally; only a short code's locality may need the
+	// provider
+	if _, _, ok := geo.FindPlusCode(address); ok {
+		code, at, err := h.geocodePlusCode(r, address)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "INVALID_PLUS_CODE", err.Error())
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"location": map[string]interface{}{
+				"formatted_address": code,
+				"plus_code":         code,
+				"lat":               at.Lat,
+				"lng":               at.Lng,
+				"location_type":     "PLUS_CODE",
+			},
+		})
+		return
+	}
+
 	// Build request
 	req := geo.GeocodeRequest{
 		Address:    address,
-		Language:   r.URL.Query().Get("language"),
-		Components: r.URL.Query().Get("components"),
+		Language:   h.language(r),
+		Components: h.components(r),
 	}
 
 	// Call Maps API
@@ -158,10 +233,125 @@ func (h *LocationHandler) GeocodeAddress(w http.ResponseWriter, r *http.Request)
 			"lat":               first.Geometry.Location.Lat,
 			"lng":               first.Geometry.Location.Lng,
 			"location_type":     first.Geometry.LocationType,
+			"plus_code":         geo.EncodePlusCode(first.Geometry.Location.Lat, first.Geometry.Location.Lng),
+		},
+	})
+}
+
+// geocodeWhat3Words answers a geocode request for a three word address
+func (h *LocationHandler) geocodeWhat3Words(w http.ResponseWriter, r *http.Request, words string) {
+	addr, err := h.what3words.Resolve(r.Context(), words)
+	switch {
+	case errors.Is(err, geo.ErrWhat3WordsUnavailable):
+		writeJSONError(w, http.StatusBadRequest, "W3W_UNAVAILABLE", err.Error())
+		return
+	case errors.Is(err, geo.ErrWhat3WordsNotFound):
+		writeJSONError(w, http.StatusNotFound, "NO_RESULTS", "No location found for the given address")
+		return
+	case err != nil:
+		log.Error().Err(err).Str("words", words).Msg("what3words request failed")
+		writeJSONError(w, http.StatusInternalServerError, "W3W_ERROR", "Failed to resolve what3words address")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"location": map[string]interface{}{
+			"formatted_address": addr.NearestPlace,
+			"w3w":               addr.Words,
+			"lat":               addr.Coordinates.Lat,
+			"lng":               addr.Coordinates.Lng,
+			"location_type":     "WHAT3WORDS",
+			"plus_code":         geo.EncodePlusCode(addr.Coordinates.Lat, addr.Coordinates.Lng),
 		},
 	})
 }
 
+// geocodePlusCode resolves a plus code address. Short codes are read near
+// the configured city named after them, else the request's lat and lng,
+// else wherever the provider geocodes the locality to.
+func (h *LocationHandler) geocodePlusCode(r *http.Request, address string) (string, geo.Coordinate, error) {
+	var ref *geo.Coordinate
+	lat, latErr := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	lng, lngErr := strconv.ParseFloat(r.URL.Query().Get("lng"), 64)
+	if latErr == nil && lngErr == nil && geo.IsValidCoordinate(lat, lng) {
+		ref = &geo.Coordinate{Lat: lat, Lng: lng}
+	}
+
+	code, at, err := resolvePlusCode(h.cities, address, ref)
+	if err != errPlusCodeNeedsLocality {
+		return code, at, err
+	}
+
+	_, locality, _ := geo.FindPlusCode(address)
+	if locality == "" {
+		return "", geo.Coordinate{}, err
+	}
+	result, geocodeErr := h.mapsClient.Geocode(r.Context(), geo.GeocodeRequest{
+		Address:    locality,
+		Components: h.components(r),
+		Language:   h.language(r),
+	})
+	if geocodeErr != nil || len(result.Results) == 0 {
+		return "", geo.Coordinate{}, err
+	}
+	return resolvePlusCode(h.cities, address, &result.Results[0].Geometry.Location)
+}
+
+// BatchGeocodeRequest is a list of addresses to geocode, such as a
+// merchant's address book
+type BatchGeocodeRequest struct {
+	Addresses  []string `json:"addresses"`
+	Components string   `json:"components,omitempty"`
+	Language   string   `json:"language,omitempty"`
+}
+
+// BatchGeocodeAddresses geocodes up to 200 addresses, one result per row
+// POST /locations/geocode/batch
+func (h *LocationHandler) BatchGeocodeAddresses(w http.ResponseWriter, r *http.Request) {
+	if !h.mapsClient.IsConfigured() {
+		writeJSONError(w, http.StatusServiceUnavailable, "MAPS_NOT_CONFIGURED", "Location service not available")
+		return
+	}
+
+	var req BatchGeocodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+	if len(req.Addresses) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "INVALID_ADDRESS", "addresses is required")
+		return
+	}
+	if len(req.Addresses) > domain.BatchGeocodeLimit {
+		writeJSONError(w, http.StatusBadRequest, "BATCH_TOO_LARGE",
+			"At most "+strconv.Itoa(domain.BatchGeocodeLimit)+" addresses can be geocoded at once")
+		return
+	}
+
+	components := req.Components
+	if components == "" {
+		components = h.components(r)
+	}
+	language := req.Language
+	if language == "" {
+		language = h.language(r)
+	}
+
+	results := h.geocoder.GeocodeBatch(r.Context(), req.Addresses, components, language)
+
+	summary := make(map[domain.GeocodeStatus]int)
+	for _, result := range results {
+		summary[result.Status]++
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"results": results,
+		"summary": summary,
+	})
+}
+
 // ReverseGeocode converts coordinates to an address
 // GET /locations/reverse?lat=...&lng=...
 func (h *LocationHandler) ReverseGeocode(w http.ResponseWriter, r *http.Request) {
@@ -204,7 +394,7 @@ func (h *LocationHandler) ReverseGeocode(w http.ResponseWriter, r *http.Request)
 	req := geo.ReverseGeocodeRequest{
 		Lat:        lat,
 		Lng:        lng,
-		Language:   r.URL.Query().Get("language"),
+		Language:   h.language(r),
 		ResultType: r.URL.Query().Get("result_type"),
 	}
 
@@ -216,8 +406,16 @@ func (h *LocationHandler) ReverseGeocode(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Places with no street addressing are still reachable by plus code
+	plusCode := geo.EncodePlusCode(lat, lng)
 	if len(result.Results) == 0 {
-		writeJSONError(w, http.StatusNotFound, "NO_RESULTS", "No address found for the given coordinates")
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"address": map[string]interface{}{
+				"formatted_address": plusCode,
+				"plus_code":         plusCode,
+			},
+		})
 		return
 	}
 
@@ -248,6 +446,7 @@ func (h *LocationHandler) ReverseGeocode(w http.ResponseWriter, r *http.Request)
 			"country":           country,
 			"postal_code":       postalCode,
 			"types":             first.Types,
+			"plus_code":         plusCode,
 		},
 	})
 }
@@ -274,7 +473,7 @@ func (h *LocationHandler) GetPlaceDetails(w http.ResponseWriter, r *http.Request
 	req := geo.PlaceDetailsRequest{
 		PlaceID:  placeID,
 		Fields:   r.URL.Query().Get("fields"),
-		Language: r.URL.Query().Get("language"),
+		Language: h.language(r),
 	}
 
 	// Call Maps API
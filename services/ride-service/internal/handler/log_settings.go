@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/logging"
+)
+
+// LogSettingsService defines the runtime log settings interface
+type LogSettingsService interface {
+	Get(ctx context.Context) logging.Settings
+	Update(ctx context.Context, settings logging.Settings, actorID uuid.UUID) (logging.Settings, error)
+	Reset(ctx context.Context, actorID uuid.UUID) (logging.Settings, error)
+}
+
+// LogSettingsHandler lets ops change log levels and sampling rates without
+// a redeploy
+type LogSettingsHandler struct {
+	settings LogSettingsService
+}
+
+// NewLogSettingsHandler creates a new log settings handler
+func NewLogSettingsHandler(settings LogSettingsService) *LogSettingsHandler {
+	return &LogSettingsHandler{settings: settings}
+}
+
+// GetLogSettings handles GET /internal/admin/logging
+func (h *LogSettingsHandler) GetLogSettings(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.settings.Get(r.Context()))
+}
+
+// UpdateLogSettings handles PUT /internal/admin/logging. The body replaces
+// the settings in force, e.g. {"level":"info","levels":{"matching":"debug"}}.
+func (h *LogSettingsHandler) UpdateLogSettings(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	var req logging.Settings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	settings, err := h.settings.Update(r.Context(), req, getUserIDFromContext(r.Context()))
+	if errors.Is(err, logging.ErrInvalidSettings) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to update log settings")
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to update log settings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// ResetLogSettings handles DELETE /internal/admin/logging
+func (h *LogSettingsHandler) ResetLogSettings(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	settings, err := h.settings.Reset(r.Context(), getUserIDFromContext(r.Context()))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to reset log settings")
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to reset log settings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, settings)
+}
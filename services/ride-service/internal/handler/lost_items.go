@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/service"
+)
+
+// LostItemService defines the lost item service interface
+type LostItemService interface {
+	ReportLostItem(ctx context.Context, rideID, riderID uuid.UUID, category domain.LostItemCategory, description string) (*domain.LostItemCase, error)
+	GetRideLostItem(ctx context.Context, rideID, userID uuid.UUID, role string) (*domain.LostItemCase, error)
+	GetLostItem(ctx context.Context, caseID, userID uuid.UUID, role string) (*domain.LostItemCase, error)
+	ListLostItems(ctx context.Context, userID uuid.UUID) ([]*domain.LostItemCase, error)
+	UpdateLostItem(ctx context.Context, caseID, userID uuid.UUID, role string, update *service.LostItemUpdate) (*domain.LostItemCase, error)
+}
+
+// LostItemHandler handles lost item cases for riders, drivers and support
+type LostItemHandler struct {
+	lostItemService LostItemService
+}
+
+// NewLostItemHandler creates a new lost item handler
+func NewLostItemHandler(lostItemService LostItemService) *LostItemHandler {
+	return &LostItemHandler{lostItemService: lostItemService}
+}
+
+// ReportLostItemRequest is the body of a rider's lost item report
+type ReportLostItemRequest struct {
+	Category    string `json:"category"`
+	Description string `json:"description"`
+}
+
+// UpdateLostItemRequest is the body of a lost item case update. A return
+// is required to schedule one.
+type UpdateLostItemRequest struct {
+	Status string                 `json:"status"`
+	Return *domain.LostItemReturn `json:"return,omitempty"`
+	Note   string                 `json:"note,omitempty"`
+}
+
+// ReportLostItem handles POST /rides/{rideId}/lost-item
+func (h *LostItemHandler) ReportLostItem(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	var req ReportLostItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	lost, err := h.lostItemService.ReportLostItem(r.Context(), rideID, userID, domain.LostItemCategory(req.Category), req.Description)
+	if err != nil {
+		writeLostItemError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, lost)
+}
+
+// GetRideLostItem handles GET /rides/{rideId}/lost-item
+func (h *LostItemHandler) GetRideLostItem(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	lost, err := h.lostItemService.GetRideLostItem(r.Context(), rideID, userID, getUserRoleFromContext(r.Context()))
+	if err != nil {
+		writeLostItemError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, lost)
+}
+
+// ListLostItems handles GET /lost-items
+func (h *LostItemHandler) ListLostItems(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	cases, err := h.lostItemService.ListLostItems(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list lost items")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"cases": cases})
+}
+
+// GetLostItem handles GET /lost-items/{caseId}
+func (h *LostItemHandler) GetLostItem(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	caseID, err := uuid.Parse(chi.URLParam(r, "caseId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid case ID")
+		return
+	}
+
+	lost, err := h.lostItemService.GetLostItem(r.Context(), caseID, userID, getUserRoleFromContext(r.Context()))
+	if err != nil {
+		writeLostItemError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, lost)
+}
+
+// UpdateLostItem handles POST /lost-items/{caseId}/status
+func (h *LostItemHandler) UpdateLostItem(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	caseID, err := uuid.Parse(chi.URLParam(r, "caseId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid case ID")
+		return
+	}
+
+	var req UpdateLostItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	lost, err := h.lostItemService.UpdateLostItem(r.Context(), caseID, userID, getUserRoleFromContext(r.Context()), &service.LostItemUpdate{
+		Status: domain.LostItemStatus(req.Status),
+		Return: req.Return,
+		Note:   req.Note,
+	})
+	if err != nil {
+		writeLostItemError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, lost)
+}
+
+func writeLostItemError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrRideNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, errMsgRideNotFound)
+	case domain.ErrLostItemNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeLostItemNotFound, "Lost item case not found")
+	case domain.ErrForbidden:
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Not allowed to access this lost item case")
+	case domain.ErrLostItemAlreadyOpen:
+		writeError(w, http.StatusConflict, domain.ErrCodeLostItemAlreadyOpen, err.Error())
+	case domain.ErrLostItemNotReportable:
+		writeError(w, http.StatusConflict, domain.ErrCodeLostItemNotReportable, err.Error())
+	case domain.ErrLostItemWindowClosed:
+		writeError(w, http.StatusUnprocessableEntity, domain.ErrCodeLostItemWindowClosed, err.Error())
+	case domain.ErrInvalidLostItem:
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidLostItem, err.Error())
+	case domain.ErrInvalidLostItemUpdate:
+		writeError(w, http.StatusConflict, domain.ErrCodeInvalidLostItemUpdate, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to process lost item case")
+	}
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/locale"
+)
+
+// LoyaltyService defines the rider loyalty points service interface
+type LoyaltyService interface {
+	GetBalance(ctx context.Context, riderID uuid.UUID, currency domain.Currency) (*domain.LoyaltyBalance, error)
+}
+
+// LoyaltyHandler serves riders' loyalty points
+type LoyaltyHandler struct {
+	loyalty LoyaltyService
+}
+
+// NewLoyaltyHandler creates a new loyalty handler
+func NewLoyaltyHandler(loyalty LoyaltyService) *LoyaltyHandler {
+	return &LoyaltyHandler{loyalty: loyalty}
+}
+
+// GetMyLoyalty handles GET /riders/me/loyalty. The points are valued in
+// the currency of the market the rider is in.
+func (h *LoyaltyHandler) GetMyLoyalty(w http.ResponseWriter, r *http.Request) {
+	riderID := getUserIDFromContext(r.Context())
+	if riderID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	balance, err := h.loyalty.GetBalance(r.Context(), riderID, locale.Currency(r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get loyalty points")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, balance)
+}
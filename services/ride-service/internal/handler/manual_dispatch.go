@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// ManualDispatchService defines the manual dispatch service interface
+type ManualDispatchService interface {
+	DispatchRide(ctx context.Context, rideID, driverID, adminID uuid.UUID, reason string) (*domain.Ride, error)
+}
+
+// ManualDispatchHandler serves ops' manual dispatch override
+type ManualDispatchHandler struct {
+	dispatch ManualDispatchService
+}
+
+// NewManualDispatchHandler creates a new manual dispatch handler
+func NewManualDispatchHandler(dispatch ManualDispatchService) *ManualDispatchHandler {
+	return &ManualDispatchHandler{dispatch: dispatch}
+}
+
+// ManualDispatchRequest is the body of a manual dispatch
+type ManualDispatchRequest struct {
+	DriverID uuid.UUID `json:"driver_id"`
+	Reason   string    `json:"reason"`
+}
+
+// DispatchRide handles POST /internal/admin/rides/{rideId}/dispatch
+func (h *ManualDispatchHandler) DispatchRide(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+	adminID := getUserIDFromContext(r.Context())
+	if adminID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	var req ManualDispatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+	if req.DriverID == uuid.Nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "driver_id is required")
+		return
+	}
+
+	ride, err := h.dispatch.DispatchRide(r.Context(), rideID, req.DriverID, adminID, req.Reason)
+	if err != nil {
+		writeManualDispatchError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ride)
+}
+
+func writeManualDispatchError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrRideNotFound):
+		writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, err.Error())
+	case errors.Is(err, domain.ErrDriverNotFound):
+		writeError(w, http.StatusNotFound, domain.ErrCodeDriverNotFound, "Driver not found")
+	case errors.Is(err, domain.ErrDispatchReasonRequired):
+		writeError(w, http.StatusBadRequest, domain.ErrCodeDispatchReasonRequired, err.Error())
+	case errors.Is(err, domain.ErrRideNotDispatchable):
+		writeError(w, http.StatusConflict, domain.ErrCodeRideNotDispatchable, err.Error())
+	case errors.Is(err, domain.ErrRideAlreadyAssigned):
+		writeError(w, http.StatusConflict, domain.ErrCodeRideAlreadyAssigned, err.Error())
+	case errors.Is(err, domain.ErrRideTypeNotSupported):
+		writeError(w, http.StatusConflict, domain.ErrCodeRideTypeNotSupported, err.Error())
+	case errors.Is(err, domain.ErrDriverRestricted):
+		writeError(w, http.StatusConflict, domain.ErrCodeDriverRestricted, err.Error())
+	case errors.Is(err, domain.ErrDriverNotAvailable):
+		writeError(w, http.StatusConflict, domain.ErrCodeDriverNotAvailable, err.Error())
+	case errors.Is(err, domain.ErrDriverBusy):
+		writeError(w, http.StatusConflict, domain.ErrCodeDriverBusy, err.Error())
+	case errors.Is(err, domain.ErrTrainingRequired):
+		writeError(w, http.StatusConflict, domain.ErrCodeTrainingRequired, err.Error())
+	case errors.Is(err, domain.ErrBlockedByRider):
+		writeError(w, http.StatusConflict, domain.ErrCodeDriverBlocked, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to dispatch ride")
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+)
+
+// PickupETAService defines the pickup ETA preview service interface
+type PickupETAService interface {
+	Preview(ctx context.Context, lat, lng float64) (*domain.PickupETAPreview, error)
+}
+
+// PickupETAHandler serves pickup ETA previews
+type PickupETAHandler struct {
+	etaService PickupETAService
+}
+
+// NewPickupETAHandler creates a new pickup ETA handler
+func NewPickupETAHandler(etaService PickupETAService) *PickupETAHandler {
+	return &PickupETAHandler{etaService: etaService}
+}
+
+// GetETAPreview handles GET /pricing/eta-preview?pickup_lat=&pickup_lng=&type=
+// Without a type, every ride type offered at the pickup is returned.
+func (h *PickupETAHandler) GetETAPreview(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("pickup_lat"), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid pickup_lat")
+		return
+	}
+	lng, err := strconv.ParseFloat(query.Get("pickup_lng"), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid pickup_lng")
+		return
+	}
+	if !geo.IsValidCoordinate(lat, lng) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidLocation, "Invalid location")
+		return
+	}
+
+	preview, err := h.etaService.Preview(r.Context(), lat, lng)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to estimate pickup ETA")
+		return
+	}
+
+	if rideType := query.Get("type"); rideType != "" {
+		eta, ok := preview.ForRideType(domain.RideType(rideType))
+		if !ok {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeRideTypeUnavailable, "Ride type is not offered here")
+			return
+		}
+		preview.ETAs = []*domain.PickupETA{eta}
+	}
+
+	writeJSON(w, http.StatusOK, preview)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+)
+
+// errPlusCodeNeedsLocality is returned for a short plus code with nothing to
+// read it near
+var errPlusCodeNeedsLocality = errors.New("short plus code needs a city, e.g. \"PR6C+24 Nairobi\", or a full code")
+
+// plusCodeIn returns the plus code a location was given by, in plus_code or
+// as its address, when it came without coordinates
+func (l *LocationInput) plusCodeIn() string {
+	if l.Latitude != 0 || l.Longitude != 0 {
+		return ""
+	}
+	if l.PlusCode != "" {
+		return l.PlusCode
+	}
+	if _, _, ok := geo.FindPlusCode(l.Address); ok {
+		return l.Address
+	}
+	return ""
+}
+
+// resolvePlusCode reads a plus code, alone or followed by a locality as in
+// "PR6C+24 Nairobi", into its full code and centre. Short codes are read
+// near the named city, else near ref; ref may be nil.
+func resolvePlusCode(cities *cityconfig.Registry, text string, ref *geo.Coordinate) (string, geo.Coordinate, error) {
+	code, locality, ok := geo.FindPlusCode(text)
+	if !ok {
+		return "", geo.Coordinate{}, errors.New("no plus code found")
+	}
+
+	if geo.IsShortPlusCode(code) {
+		if cities != nil && locality != "" {
+			if city, ok := cities.FindByName(locality); ok {
+				ref = &geo.Coordinate{Lat: city.ServiceArea.CenterLat, Lng: city.ServiceArea.CenterLng}
+			}
+		}
+		if ref == nil {
+			return "", geo.Coordinate{}, errPlusCodeNeedsLocality
+		}
+		full, err := geo.RecoverPlusCode(code, ref.Lat, ref.Lng)
+		if err != nil {
+			return "", geo.Coordinate{}, err
+		}
+		code = full
+	}
+
+	area, err := geo.DecodePlusCode(code)
+	if err != nil {
+		return "", geo.Coordinate{}, err
+	}
+	lat, lng := area.Center()
+	return code, geo.Coordinate{Lat: lat, Lng: lng}, nil
+}
+
+// resolvePlusCodes fills in coordinates for ride request locations given
+// as plus codes. Short codes without a known city are read near the other
+// end of the trip.
+func (h *RideHandler) resolvePlusCodes(req *RequestRideRequest) error {
+	locations := []*LocationInput{&req.PickupLocation, &req.DropoffLocation}
+	for i := range req.Stops {
+		locations = append(locations, &req.Stops[i])
+	}
+
+	for i, loc := range locations {
+		text := loc.plusCodeIn()
+		if text == "" {
+			continue
+		}
+
+		var ref *geo.Coordinate
+		other := &req.DropoffLocation
+		if i > 0 {
+			other = &req.PickupLocation
+		}
+		if other.plusCodeIn() == "" && (other.Latitude != 0 || other.Longitude != 0) {
+			ref = &geo.Coordinate{Lat: other.Latitude, Lng: other.Longitude}
+		}
+
+		code, at, err := resolvePlusCode(h.cities, text, ref)
+		if err != nil {
+			return err
+		}
+		loc.PlusCode = code
+		loc.Latitude, loc.Longitude = at.Lat, at.Lng
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+)
+
+// PredictionService defines the location prediction service interface
+type PredictionService interface {
+	SuggestPickupSpots(ctx context.Context, lat, lng float64, placeID string) (*domain.PickupSuggestions, error)
+	ExportPredictionData(ctx context.Context, userID uuid.UUID) (*domain.PredictionDataExport, error)
+	DeletePredictionData(ctx context.Context, userID uuid.UUID) error
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*domain.LocationHistoryPreferences, error)
+	SetOptOut(ctx context.Context, userID uuid.UUID, optedOut bool) (*domain.LocationHistoryPreferences, error)
+}
+
+// PopularLocationService defines the city popular destinations service interface
+type PopularLocationService interface {
+	GetPopularLocations(ctx context.Context, lat, lng float64) ([]*domain.PopularLocation, error)
+}
+
+// PredictionHandler serves location predictions learned from past trips
+type PredictionHandler struct {
+	predictionService PredictionService
+	popularService    PopularLocationService
+}
+
+// NewPredictionHandler creates a new prediction handler
+func NewPredictionHandler(predictionService PredictionService, popularService PopularLocationService) *PredictionHandler {
+	return &PredictionHandler{
+		predictionService: predictionService,
+		popularService:    popularService,
+	}
+}
+
+// UpdatePredictionPreferencesRequest represents a user's location history
+// settings update
+type UpdatePredictionPreferencesRequest struct {
+	OptedOut bool `json:"opted_out"`
+}
+
+// SuggestPickupSpots handles GET /predictions/pickup?lat=&lng=&place_id=
+func (h *PredictionHandler) SuggestPickupSpots(w http.ResponseWriter, r *http.Request) {
+	lat, lng, ok := parseCoordinates(w, r)
+	if !ok {
+		return
+	}
+
+	suggestions, err := h.predictionService.SuggestPickupSpots(r.Context(), lat, lng, r.URL.Query().Get("place_id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to suggest pickup spots")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, suggestions)
+}
+
+// GetPopularLocations handles GET /predictions/popular?lat=&lng=
+func (h *PredictionHandler) GetPopularLocations(w http.ResponseWriter, r *http.Request) {
+	lat, lng, ok := parseCoordinates(w, r)
+	if !ok {
+		return
+	}
+
+	locations, err := h.popularService.GetPopularLocations(r.Context(), lat, lng)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get popular locations")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"locations": locations,
+	})
+}
+
+// ExportMyPredictionData handles GET /users/me/prediction-data
+func (h *PredictionHandler) ExportMyPredictionData(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	export, err := h.predictionService.ExportPredictionData(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to export prediction data")
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="prediction-data.json"`)
+	writeJSON(w, http.StatusOK, export)
+}
+
+// DeleteMyPredictionData handles DELETE /users/me/prediction-data
+func (h *PredictionHandler) DeleteMyPredictionData(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.predictionService.DeletePredictionData(r.Context(), userID); err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to delete prediction data")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMyPredictionPreferences handles GET /users/me/prediction-data/preferences
+func (h *PredictionHandler) GetMyPredictionPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	prefs, err := h.predictionService.GetPreferences(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get prediction preferences")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+// UpdateMyPredictionPreferences handles PUT /users/me/prediction-data/preferences
+func (h *PredictionHandler) UpdateMyPredictionPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req UpdatePredictionPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	prefs, err := h.predictionService.SetOptOut(r.Context(), userID, req.OptedOut)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to update prediction preferences")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+// parseCoordinates parses the lat and lng query parameters, writing a 400
+// when they are missing or invalid
+func parseCoordinates(w http.ResponseWriter, r *http.Request) (float64, float64, bool) {
+	lat, err := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid latitude")
+		return 0, 0, false
+	}
+	lng, err := strconv.ParseFloat(r.URL.Query().Get("lng"), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid longitude")
+		return 0, 0, false
+	}
+	if !geo.IsValidCoordinate(lat, lng) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Coordinates out of valid range")
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
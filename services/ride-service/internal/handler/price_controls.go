@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// Period the compliance report covers when none is given
+const defaultComplianceReportPeriod = 7 * 24 * time.Hour
+
+// PriceControlService defines the price control compliance service interface
+type PriceControlService interface {
+	GetReport(ctx context.Context, from, to time.Time) (*domain.PriceControlReport, error)
+}
+
+// PriceControlHandler serves the regulatory price control compliance report
+type PriceControlHandler struct {
+	priceControlService PriceControlService
+}
+
+// NewPriceControlHandler creates a new price control handler
+func NewPriceControlHandler(priceControlService PriceControlService) *PriceControlHandler {
+	return &PriceControlHandler{priceControlService: priceControlService}
+}
+
+// GetComplianceReport handles GET /internal/admin/compliance/price-controls?from=&to=
+func (h *PriceControlHandler) GetComplianceReport(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	to := time.Now().UTC()
+	if v := r.URL.Query().Get("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "to must be an RFC3339 timestamp")
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-defaultComplianceReportPeriod)
+	if v := r.URL.Query().Get("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil || !parsed.Before(to) {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "from must be an RFC3339 timestamp before to")
+			return
+		}
+		from = parsed
+	}
+
+	report, err := h.priceControlService.GetReport(r.Context(), from, to)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to build compliance report")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
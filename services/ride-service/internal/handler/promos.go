@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// PromoService defines the promo campaign service interface
+type PromoService interface {
+	CreateCampaign(ctx context.Context, campaign *domain.PromoCampaign, adminID uuid.UUID) (*domain.PromoCampaign, error)
+	GetCampaign(ctx context.Context, id uuid.UUID) (*domain.PromoCampaign, error)
+	UpdateCampaign(ctx context.Context, id uuid.UUID, update *domain.PromoCampaign) (*domain.PromoCampaign, error)
+	AddCodes(ctx context.Context, id uuid.UUID, codes []string) (*domain.PromoCampaign, error)
+	RemoveCode(ctx context.Context, id uuid.UUID, code string) (*domain.PromoCampaign, error)
+	ListCampaigns(ctx context.Context, status domain.PromoCampaignStatus) ([]*domain.PromoCampaign, error)
+	PauseCampaign(ctx context.Context, id uuid.UUID) (*domain.PromoCampaign, error)
+	ResumeCampaign(ctx context.Context, id uuid.UUID) (*domain.PromoCampaign, error)
+	GetCampaignStats(ctx context.Context, id uuid.UUID) (*domain.PromoCampaignStats, error)
+}
+
+// PromoHandler serves the admin API for promo campaigns
+type PromoHandler struct {
+	promoService PromoService
+}
+
+// NewPromoHandler creates a new promo handler
+func NewPromoHandler(promoService PromoService) *PromoHandler {
+	return &PromoHandler{promoService: promoService}
+}
+
+// CreateCampaign handles POST /internal/admin/promo-campaigns
+func (h *PromoHandler) CreateCampaign(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+	adminID := getUserIDFromContext(r.Context())
+	if adminID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var campaign domain.PromoCampaign
+	if err := json.NewDecoder(r.Body).Decode(&campaign); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	created, err := h.promoService.CreateCampaign(r.Context(), &campaign, adminID)
+	if err != nil {
+		h.writePromoError(w, err, "Failed to create promo campaign")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// ListCampaigns handles GET /internal/admin/promo-campaigns?status=
+func (h *PromoHandler) ListCampaigns(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	status := domain.PromoCampaignStatus(r.URL.Query().Get("status"))
+	campaigns, err := h.promoService.ListCampaigns(r.Context(), status)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list promo campaigns")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"campaigns": campaigns})
+}
+
+// GetCampaign handles GET /internal/admin/promo-campaigns/{campaignId}
+func (h *PromoHandler) GetCampaign(w http.ResponseWriter, r *http.Request) {
+	h.withCampaign(w, r, h.promoService.GetCampaign)
+}
+
+// AddPromoCodesRequest is the body of a request adding codes to a campaign
+type AddPromoCodesRequest struct {
+	Codes []string `json:"codes"`
+}
+
+// UpdateCampaign handles PUT /internal/admin/promo-campaigns/{campaignId}
+func (h *PromoHandler) UpdateCampaign(w http.ResponseWriter, r *http.Request) {
+	var update domain.PromoCampaign
+	h.withCampaignBody(w, r, &update, func(ctx context.Context, id uuid.UUID) (*domain.PromoCampaign, error) {
+		return h.promoService.UpdateCampaign(ctx, id, &update)
+	})
+}
+
+// AddCodes handles POST /internal/admin/promo-campaigns/{campaignId}/codes
+func (h *PromoHandler) AddCodes(w http.ResponseWriter, r *http.Request) {
+	var req AddPromoCodesRequest
+	h.withCampaignBody(w, r, &req, func(ctx context.Context, id uuid.UUID) (*domain.PromoCampaign, error) {
+		return h.promoService.AddCodes(ctx, id, req.Codes)
+	})
+}
+
+// RemoveCode handles DELETE /internal/admin/promo-campaigns/{campaignId}/codes/{code}
+func (h *PromoHandler) RemoveCode(w http.ResponseWriter, r *http.Request) {
+	h.withCampaign(w, r, func(ctx context.Context, id uuid.UUID) (*domain.PromoCampaign, error) {
+		return h.promoService.RemoveCode(ctx, id, chi.URLParam(r, "code"))
+	})
+}
+
+// PauseCampaign handles POST /internal/admin/promo-campaigns/{campaignId}/pause
+func (h *PromoHandler) PauseCampaign(w http.ResponseWriter, r *http.Request) {
+	h.withCampaign(w, r, h.promoService.PauseCampaign)
+}
+
+// ResumeCampaign handles POST /internal/admin/promo-campaigns/{campaignId}/resume
+func (h *PromoHandler) ResumeCampaign(w http.ResponseWriter, r *http.Request) {
+	h.withCampaign(w, r, h.promoService.ResumeCampaign)
+}
+
+// GetCampaignStats handles GET /internal/admin/promo-campaigns/{campaignId}/stats
+func (h *PromoHandler) GetCampaignStats(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	campaignID, err := uuid.Parse(chi.URLParam(r, "campaignId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid campaign ID")
+		return
+	}
+
+	stats, err := h.promoService.GetCampaignStats(r.Context(), campaignID)
+	if err != nil {
+		h.writePromoError(w, err, "Failed to get promo campaign stats")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// withCampaign runs an admin action on the campaign in the URL and writes
+// the resulting campaign
+func (h *PromoHandler) withCampaign(
+	w http.ResponseWriter,
+	r *http.Request,
+	action func(ctx context.Context, id uuid.UUID) (*domain.PromoCampaign, error),
+) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	campaignID, err := uuid.Parse(chi.URLParam(r, "campaignId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid campaign ID")
+		return
+	}
+
+	campaign, err := action(r.Context(), campaignID)
+	if err != nil {
+		h.writePromoError(w, err, "Failed to update promo campaign")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, campaign)
+}
+
+// withCampaignBody decodes the request body into body before running the
+// action like withCampaign
+func (h *PromoHandler) withCampaignBody(
+	w http.ResponseWriter,
+	r *http.Request,
+	body interface{},
+	action func(ctx context.Context, id uuid.UUID) (*domain.PromoCampaign, error),
+) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+	h.withCampaign(w, r, action)
+}
+
+func (h *PromoHandler) writePromoError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrPromoCampaignNotFound):
+		writeError(w, http.StatusNotFound, domain.ErrCodePromoCampaignNotFound, err.Error())
+	case errors.Is(err, domain.ErrPromoCodeNotFound):
+		writeError(w, http.StatusNotFound, domain.ErrCodePromoCodeNotFound, err.Error())
+	case errors.Is(err, domain.ErrInvalidPromoCampaign):
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidPromoCampaign, err.Error())
+	case errors.Is(err, domain.ErrPromoCodeTaken):
+		writeError(w, http.StatusConflict, domain.ErrCodePromoCodeTaken, err.Error())
+	case errors.Is(err, domain.ErrPromoCampaignStatus):
+		writeError(w, http.StatusConflict, domain.ErrCodePromoCampaignStatus, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, fallback)
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// ReceiptService defines the ride receipt service interface
+type ReceiptService interface {
+	GetReceipt(ctx context.Context, rideID, userID uuid.UUID, role string) (*domain.RideReceipt, error)
+}
+
+// ReceiptHandler serves riders' ride receipts
+type ReceiptHandler struct {
+	receiptService ReceiptService
+}
+
+// NewReceiptHandler creates a new receipt handler
+func NewReceiptHandler(receiptService ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{receiptService: receiptService}
+}
+
+// GetReceipt handles GET /rides/{rideId}/receipt
+func (h *ReceiptHandler) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	receipt, err := h.receiptService.GetReceipt(r.Context(), rideID, userID, getUserRoleFromContext(r.Context()))
+	if err != nil {
+		switch err {
+		case domain.ErrRideNotFound:
+			writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, errMsgRideNotFound)
+		case domain.ErrForbidden:
+			writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Only the rider can view this receipt")
+		case domain.ErrReceiptNotAvailable:
+			writeError(w, http.StatusConflict, domain.ErrCodeReceiptNotAvailable, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get receipt")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, receipt)
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/service"
+)
+
+// RefundService defines the refund service interface
+type RefundService interface {
+	RequestRefund(ctx context.Context, req *service.RefundRequest) (*domain.Refund, error)
+	ApproveRefund(ctx context.Context, refundID, approverID uuid.UUID) (*domain.Refund, error)
+	RejectRefund(ctx context.Context, refundID, approverID uuid.UUID, reason string) (*domain.Refund, error)
+	ListRefunds(ctx context.Context, rideID uuid.UUID) ([]*domain.Refund, error)
+	GetLedger(ctx context.Context, rideID uuid.UUID) ([]*domain.LedgerEntry, error)
+}
+
+// RefundHandler handles support refund and goodwill credit requests
+type RefundHandler struct {
+	refundService RefundService
+}
+
+// NewRefundHandler creates a new refund handler
+func NewRefundHandler(refundService RefundService) *RefundHandler {
+	return &RefundHandler{refundService: refundService}
+}
+
+// CreateRefundRequest is the body of a refund request
+type CreateRefundRequest struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+	Amount int64  `json:"amount,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// RejectRefundRequest is the body of a refund rejection
+type RejectRefundRequest struct {
+	Reason string `json:"reason"`
+}
+
+// CreateRefund handles POST /internal/support/rides/{rideId}/refunds
+func (h *RefundHandler) CreateRefund(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+	agentID := getUserIDFromContext(r.Context())
+	if agentID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	var req CreateRefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	refund, err := h.refundService.RequestRefund(r.Context(), &service.RefundRequest{
+		RideID:      rideID,
+		Type:        domain.RefundType(req.Type),
+		Reason:      domain.RefundReason(req.Reason),
+		Note:        req.Note,
+		Amount:      req.Amount,
+		RequestedBy: agentID,
+	})
+	if err != nil && refund == nil {
+		writeRefundError(w, err)
+		return
+	}
+
+	// A refund that failed at the provider is still returned so the agent sees its status
+	status := http.StatusCreated
+	switch refund.Status {
+	case domain.RefundStatusPendingApproval:
+		status = http.StatusAccepted
+	case domain.RefundStatusFailed:
+		status = http.StatusBadGateway
+	}
+	writeJSON(w, status, refund)
+}
+
+// ListRefunds handles GET /internal/support/rides/{rideId}/refunds
+func (h *RefundHandler) ListRefunds(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	refunds, err := h.refundService.ListRefunds(r.Context(), rideID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list refunds")
+		return
+	}
+
+	ledger, err := h.refundService.GetLedger(r.Context(), rideID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get ledger")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"refunds": refunds,
+		"ledger":  ledger,
+	})
+}
+
+// ApproveRefund handles POST /internal/support/refunds/{refundId}/approve
+func (h *RefundHandler) ApproveRefund(w http.ResponseWriter, r *http.Request) {
+	if !domain.CanApproveRefunds(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Refund approval requires a support lead")
+		return
+	}
+	approverID := getUserIDFromContext(r.Context())
+	if approverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	refundID, err := uuid.Parse(chi.URLParam(r, "refundId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid refund ID")
+		return
+	}
+
+	refund, err := h.refundService.ApproveRefund(r.Context(), refundID, approverID)
+	if err != nil && refund == nil {
+		writeRefundError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if refund.Status == domain.RefundStatusFailed {
+		status = http.StatusBadGateway
+	}
+	writeJSON(w, status, refund)
+}
+
+// RejectRefund handles POST /internal/support/refunds/{refundId}/reject
+func (h *RefundHandler) RejectRefund(w http.ResponseWriter, r *http.Request) {
+	if !domain.CanApproveRefunds(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Refund approval requires a support lead")
+		return
+	}
+	approverID := getUserIDFromContext(r.Context())
+	if approverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	refundID, err := uuid.Parse(chi.URLParam(r, "refundId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid refund ID")
+		return
+	}
+
+	var req RejectRefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		req.Reason = "Rejected by approver"
+	}
+
+	refund, err := h.refundService.RejectRefund(r.Context(), refundID, approverID, req.Reason)
+	if err != nil {
+		writeRefundError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, refund)
+}
+
+func writeRefundError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrRideNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, errMsgRideNotFound)
+	case domain.ErrRefundNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeRefundNotFound, "Refund not found")
+	case domain.ErrRideNotRefundable:
+		writeError(w, http.StatusConflict, domain.ErrCodeRideNotRefundable, err.Error())
+	case domain.ErrRefundExceedsFare:
+		writeError(w, http.StatusUnprocessableEntity, domain.ErrCodeRefundExceedsFare, err.Error())
+	case domain.ErrRefundNotPending:
+		writeError(w, http.StatusConflict, domain.ErrCodeRefundNotPending, err.Error())
+	case domain.ErrRefundSelfApproval:
+		writeError(w, http.StatusForbidden, domain.ErrCodeRefundSelfApproval, err.Error())
+	case domain.ErrSettlementCurrency:
+		writeError(w, http.StatusConflict, domain.ErrCodeSettlementCurrency, err.Error())
+	case domain.ErrRefundInvalidReason, domain.ErrRefundInvalidAmount, domain.ErrInvalidRequest:
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to process refund")
+	}
+}
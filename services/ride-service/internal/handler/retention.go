@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// Period the retention nudge report covers when none is given
+const defaultRetentionReportPeriod = 7 * 24 * time.Hour
+
+// RetentionService defines the retention nudge service interface
+type RetentionService interface {
+	GetReport(ctx context.Context, from, to time.Time) (*domain.RetentionReport, error)
+	GetPreferences(ctx context.Context, riderID uuid.UUID) (*domain.NudgePreferences, error)
+	SetOptOut(ctx context.Context, riderID uuid.UUID, optedOut bool) (*domain.NudgePreferences, error)
+}
+
+// RetentionHandler serves riders' nudge preferences and the nudge
+// measurement report
+type RetentionHandler struct {
+	retentionService RetentionService
+}
+
+// NewRetentionHandler creates a new retention handler
+func NewRetentionHandler(retentionService RetentionService) *RetentionHandler {
+	return &RetentionHandler{retentionService: retentionService}
+}
+
+// UpdateNudgePreferencesRequest represents a rider's nudge settings update
+type UpdateNudgePreferencesRequest struct {
+	OptedOut bool `json:"opted_out"`
+}
+
+// GetMyNudgePreferences handles GET /riders/me/nudge-preferences
+func (h *RetentionHandler) GetMyNudgePreferences(w http.ResponseWriter, r *http.Request) {
+	riderID := getUserIDFromContext(r.Context())
+	if riderID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	prefs, err := h.retentionService.GetPreferences(r.Context(), riderID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get nudge preferences")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+// UpdateMyNudgePreferences handles PUT /riders/me/nudge-preferences
+func (h *RetentionHandler) UpdateMyNudgePreferences(w http.ResponseWriter, r *http.Request) {
+	riderID := getUserIDFromContext(r.Context())
+	if riderID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req UpdateNudgePreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	prefs, err := h.retentionService.SetOptOut(r.Context(), riderID, req.OptedOut)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to update nudge preferences")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+// GetReport handles GET /internal/admin/retention-nudges/stats?from=&to=
+func (h *RetentionHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	to := time.Now().UTC()
+	if v := r.URL.Query().Get("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "to must be an RFC3339 timestamp")
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-defaultRetentionReportPeriod)
+	if v := r.URL.Query().Get("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil || !parsed.Before(to) {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "from must be an RFC3339 timestamp before to")
+			return
+		}
+		from = parsed
+	}
+
+	report, err := h.retentionService.GetReport(r.Context(), from, to)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to build retention nudge report")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+)
+
+// ReturnLegService defines the return leg recommendation service interface
+type ReturnLegService interface {
+	Recommend(ctx context.Context, lat, lng float64) (*domain.ReturnLegRecommendation, error)
+}
+
+// ReturnLegHandler serves return-leg recommendations to drivers
+type ReturnLegHandler struct {
+	returnService ReturnLegService
+}
+
+// NewReturnLegHandler creates a new return leg handler
+func NewReturnLegHandler(returnService ReturnLegService) *ReturnLegHandler {
+	return &ReturnLegHandler{returnService: returnService}
+}
+
+// GetMyReturnLegs handles GET /drivers/me/return-legs?lat=&lng=
+// Drivers call it after a dropoff; legs are empty when they are already
+// near demand.
+func (h *ReturnLegHandler) GetMyReturnLegs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid lat")
+		return
+	}
+	lng, err := strconv.ParseFloat(query.Get("lng"), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid lng")
+		return
+	}
+	if !geo.IsValidCoordinate(lat, lng) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidLocation, "Invalid location")
+		return
+	}
+
+	rec, err := h.returnService.Recommend(r.Context(), lat, lng)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to recommend return legs")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rec)
+}
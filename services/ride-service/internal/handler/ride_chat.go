@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// rideChatStreamWindow is how long one stream request follows a chat. It
+// stays under the server's write timeout; clients reconnect and resume
+// from the Last-Event-ID they were sent.
+const rideChatStreamWindow = 10 * time.Second
+
+// RideChatService defines the ride chat service interface
+type RideChatService interface {
+	SendMessage(ctx context.Context, rideID, userID uuid.UUID, role, body string) (*domain.RideMessage, error)
+	GetChat(ctx context.Context, rideID, userID uuid.UUID, role string) (*domain.RideChat, error)
+	WaitForMessages(ctx context.Context, rideID uuid.UUID, after string, block time.Duration) ([]*domain.RideMessage, bool, error)
+}
+
+// RideChatHandler handles messaging between a ride's rider and driver
+type RideChatHandler struct {
+	chatService RideChatService
+}
+
+// NewRideChatHandler creates a new ride chat handler
+func NewRideChatHandler(chatService RideChatService) *RideChatHandler {
+	return &RideChatHandler{chatService: chatService}
+}
+
+// SendRideMessageRequest is the body of a chat message
+type SendRideMessageRequest struct {
+	Body string `json:"body"`
+}
+
+// SendMessage handles POST /rides/{rideId}/messages
+func (h *RideChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	var req SendRideMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	msg, err := h.chatService.SendMessage(r.Context(), rideID, userID, getUserRoleFromContext(r.Context()), req.Body)
+	if err != nil {
+		writeRideChatError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, msg)
+}
+
+// GetMessages handles GET /rides/{rideId}/messages
+func (h *RideChatHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	chat, err := h.chatService.GetChat(r.Context(), rideID, userID, getUserRoleFromContext(r.Context()))
+	if err != nil {
+		writeRideChatError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, chat)
+}
+
+// StreamMessages handles GET /rides/{rideId}/messages/stream, following a
+// ride's chat as server-sent events. Each message is a "message" event
+// whose ID resumes the stream; a "closed" event ends it once the ride does.
+func (h *RideChatHandler) StreamMessages(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	chat, err := h.chatService.GetChat(r.Context(), rideID, userID, getUserRoleFromContext(r.Context()))
+	if err != nil {
+		writeRideChatError(w, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Streaming is not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 1000\n\n")
+
+	// A reconnecting client resumes after the last message it was sent;
+	// a new one is sent the chat so far
+	after := r.Header.Get("Last-Event-ID")
+	if after == "" {
+		after = "0"
+		for _, msg := range chat.Messages {
+			writeRideMessageEvent(w, msg)
+			after = msg.ID
+		}
+	}
+	if !chat.Open {
+		fmt.Fprint(w, "event: closed\ndata: {}\n\n")
+		flusher.Flush()
+		return
+	}
+	flusher.Flush()
+
+	deadline := time.Now().Add(rideChatStreamWindow)
+	for {
+		block := time.Until(deadline)
+		if block < time.Millisecond {
+			return
+		}
+
+		messages, closed, err := h.chatService.WaitForMessages(r.Context(), rideID, after, block)
+		if err != nil {
+			if r.Context().Err() == nil {
+				log.Warn().Err(err).Str("ride_id", rideID.String()).Msg("Failed to follow ride chat")
+			}
+			return
+		}
+		for _, msg := range messages {
+			writeRideMessageEvent(w, msg)
+			after = msg.ID
+		}
+		if closed {
+			fmt.Fprint(w, "event: closed\ndata: {}\n\n")
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func writeRideMessageEvent(w http.ResponseWriter, msg *domain.RideMessage) {
+	data, _ := json.Marshal(msg)
+	fmt.Fprintf(w, "id: %s\nevent: message\ndata: %s\n\n", msg.ID, data)
+}
+
+func writeRideChatError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrRideNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, errMsgRideNotFound)
+	case domain.ErrForbidden:
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Not allowed to access this ride's messages")
+	case domain.ErrRideChatClosed:
+		writeError(w, http.StatusConflict, domain.ErrCodeRideChatClosed, err.Error())
+	case domain.ErrInvalidRideMessage:
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRideMessage, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to process ride messages")
+	}
+}
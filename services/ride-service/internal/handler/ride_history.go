@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// GetRideHistory handles GET /rides/history?from=&to=&status=&type=&cursor=&limit=&as=
+// Riders see the rides they took; drivers pass as=driver for those they
+// drove. Statuses and types may be repeated or comma separated.
+func (h *RideHandler) GetRideHistory(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	asDriver, filter, err := parseRideHistoryQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRideHistoryFilter, err.Error())
+		return
+	}
+
+	page, err := h.rideService.GetRideHistory(r.Context(), userID, asDriver, filter)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRideHistoryFilter) {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRideHistoryFilter, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get ride history")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// ExportRideHistory handles GET /rides/history/export?from=&to=&status=&type=&as=
+// It streams the matching rides as CSV, newest first. Exports cover at most
+// a year, the year up to now when no range is given.
+func (h *RideHandler) ExportRideHistory(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	asDriver, filter, err := parseRideHistoryQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRideHistoryFilter, err.Error())
+		return
+	}
+	filter.Cursor = nil
+
+	// Nothing is written until the first batch is read, so a bad range or a
+	// failing database still gets a JSON error
+	out := csv.NewWriter(w)
+	flusher, _ := w.(http.Flusher)
+	started := false
+	err = h.rideService.ExportRideHistory(r.Context(), userID, asDriver, filter, func(rides []*domain.Ride) error {
+		if !started {
+			started = true
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Disposition",
+				fmt.Sprintf(`attachment; filename="ride-history-%s.csv"`, time.Now().UTC().Format("20060102")))
+			w.WriteHeader(http.StatusOK)
+			if err := out.Write(domain.RideHistoryCSVHeader); err != nil {
+				return err
+			}
+		}
+		for _, ride := range rides {
+			if err := out.Write(ride.HistoryCSVRecord()); err != nil {
+				return err
+			}
+		}
+		out.Flush()
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return out.Error()
+	})
+	if err == nil {
+		return
+	}
+	if started {
+		// The response is under way; the client sees a truncated file
+		log.Error().Err(err).Str("user_id", userID.String()).Msg("Ride history export failed mid-stream")
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidRideHistoryFilter) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRideHistoryFilter, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to export ride history")
+}
+
+// parseRideHistoryQuery reads a history filter and whose history it is
+// from query parameters. Dates may be RFC 3339 times or whole days, a to
+// day included in full.
+func parseRideHistoryQuery(query url.Values) (bool, *domain.RideHistoryFilter, error) {
+	filter := &domain.RideHistoryFilter{}
+
+	var asDriver bool
+	switch as := strings.ToLower(query.Get("as")); as {
+	case "", "rider":
+	case "driver":
+		asDriver = true
+	default:
+		return false, nil, fmt.Errorf("as must be rider or driver")
+	}
+
+	if v := query.Get("from"); v != "" {
+		from, _, err := parseHistoryTime(v)
+		if err != nil {
+			return false, nil, fmt.Errorf("invalid from: %w", err)
+		}
+		filter.From = &from
+	}
+	if v := query.Get("to"); v != "" {
+		to, day, err := parseHistoryTime(v)
+		if err != nil {
+			return false, nil, fmt.Errorf("invalid to: %w", err)
+		}
+		if day {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.To = &to
+	}
+
+	for _, status := range queryList(query, "status") {
+		filter.Statuses = append(filter.Statuses, domain.RideStatus(strings.ToUpper(status)))
+	}
+	for _, rideType := range queryList(query, "type") {
+		filter.Types = append(filter.Types, domain.RideType(strings.ToUpper(rideType)))
+	}
+
+	if v := query.Get("cursor"); v != "" {
+		cursor, err := domain.ParseRideHistoryCursor(v)
+		if err != nil {
+			return false, nil, err
+		}
+		filter.Cursor = cursor
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return false, nil, fmt.Errorf("invalid limit")
+		}
+		filter.Limit = limit
+	}
+
+	return asDriver, filter, nil
+}
+
+// parseHistoryTime reads an RFC 3339 time or a YYYY-MM-DD day, reporting
+// which it was
+func parseHistoryTime(v string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), false, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	return t, true, err
+}
+
+// queryList reads a parameter given more than once or as a comma separated
+// list
+func queryList(query url.Values, key string) []string {
+	var values []string
+	for _, v := range query[key] {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+	return values
+}
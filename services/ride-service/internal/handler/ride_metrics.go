@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// Period the ride metrics report covers when none is given
+const defaultRideMetricsReportPeriod = 7 * 24 * time.Hour
+
+// RideMetricsService defines the hourly ride metrics service interface
+type RideMetricsService interface {
+	GetReport(ctx context.Context, from, to time.Time, market string, hourly bool) (*domain.RideMetricsReport, error)
+}
+
+// RideMetricsHandler serves the per-market ride metrics report
+type RideMetricsHandler struct {
+	metricsService RideMetricsService
+}
+
+// NewRideMetricsHandler creates a new ride metrics handler
+func NewRideMetricsHandler(metricsService RideMetricsService) *RideMetricsHandler {
+	return &RideMetricsHandler{metricsService: metricsService}
+}
+
+// GetReport handles GET /internal/admin/metrics?from=&to=&market=&hourly=
+func (h *RideMetricsHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	to := time.Now().UTC()
+	if v := r.URL.Query().Get("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "to must be an RFC3339 timestamp")
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-defaultRideMetricsReportPeriod)
+	if v := r.URL.Query().Get("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil || !parsed.Before(to) {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "from must be an RFC3339 timestamp before to")
+			return
+		}
+		from = parsed
+	}
+
+	report, err := h.metricsService.GetReport(r.Context(), from, to,
+		r.URL.Query().Get("market"), r.URL.Query().Get("hourly") == "true")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to build ride metrics report")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
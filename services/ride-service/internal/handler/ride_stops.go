@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// RideStopService defines the stop tracking interface for drivers on a
+// multi-stop ride
+type RideStopService interface {
+	ArriveAtStop(ctx context.Context, rideID, driverID uuid.UUID, index int) (*domain.Ride, error)
+	DepartStop(ctx context.Context, rideID, driverID uuid.UUID, index int) (*domain.Ride, error)
+}
+
+// RideStopHandler lets drivers mark arrival at and departure from stops
+type RideStopHandler struct {
+	stopService RideStopService
+}
+
+// NewRideStopHandler creates a new ride stop handler
+func NewRideStopHandler(stopService RideStopService) *RideStopHandler {
+	return &RideStopHandler{stopService: stopService}
+}
+
+// ArriveAtStop handles POST /driver/rides/{rideId}/stops/{index}/arrive
+func (h *RideStopHandler) ArriveAtStop(w http.ResponseWriter, r *http.Request) {
+	h.updateStop(w, r, h.stopService.ArriveAtStop)
+}
+
+// DepartStop handles POST /driver/rides/{rideId}/stops/{index}/depart
+func (h *RideStopHandler) DepartStop(w http.ResponseWriter, r *http.Request) {
+	h.updateStop(w, r, h.stopService.DepartStop)
+}
+
+func (h *RideStopHandler) updateStop(
+	w http.ResponseWriter,
+	r *http.Request,
+	update func(ctx context.Context, rideID, driverID uuid.UUID, index int) (*domain.Ride, error),
+) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	index, err := strconv.Atoi(chi.URLParam(r, "index"))
+	if err != nil || index < 0 {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid stop index")
+		return
+	}
+
+	ride, err := update(r.Context(), rideID, driverID, index)
+	if err != nil {
+		switch err {
+		case domain.ErrRideNotFound:
+			writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, errMsgRideNotFound)
+		case domain.ErrStopNotFound:
+			writeError(w, http.StatusNotFound, domain.ErrCodeStopNotFound, err.Error())
+		case domain.ErrForbidden:
+			writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Ride is assigned to another driver")
+		case domain.ErrInvalidStopTransition:
+			writeError(w, http.StatusConflict, domain.ErrCodeInvalidStopTransition, err.Error())
+		case domain.ErrRideConflict:
+			writeError(w, http.StatusConflict, domain.ErrCodeRideConflict, "Ride was updated concurrently, retry")
+		default:
+			writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to update stop")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ride.ViewFor(domain.RidePartyDriver))
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// RiderReliabilityService defines the rider reliability service interface
+type RiderReliabilityService interface {
+	ReportRider(ctx context.Context, rideID, driverID uuid.UUID, reportType domain.RiderReportType, note string) (*domain.RiderReport, error)
+	GetReliability(ctx context.Context, riderID uuid.UUID) (*domain.RiderReliability, error)
+	ListRiderReports(ctx context.Context, riderID uuid.UUID) ([]*domain.RiderReport, error)
+	VoidReport(ctx context.Context, reportID, agentID uuid.UUID, note string) (*domain.RiderReport, error)
+}
+
+// RiderReliabilityHandler takes drivers' reports about riders and shows
+// riders their reliability score and the factors behind it
+type RiderReliabilityHandler struct {
+	reliabilityService RiderReliabilityService
+}
+
+// NewRiderReliabilityHandler creates a new rider reliability handler
+func NewRiderReliabilityHandler(reliabilityService RiderReliabilityService) *RiderReliabilityHandler {
+	return &RiderReliabilityHandler{reliabilityService: reliabilityService}
+}
+
+// ReportRiderRequest is the body of a driver's report about a rider
+type ReportRiderRequest struct {
+	Type string `json:"type"`
+	Note string `json:"note,omitempty"`
+}
+
+// VoidRiderReportRequest is the body of a support agent voiding a report
+type VoidRiderReportRequest struct {
+	Note string `json:"note"`
+}
+
+// ReportRider handles POST /rides/{rideId}/rider-reports
+func (h *RiderReliabilityHandler) ReportRider(w http.ResponseWriter, r *http.Request) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	var req ReportRiderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	report, err := h.reliabilityService.ReportRider(r.Context(), rideID, driverID, domain.RiderReportType(req.Type), req.Note)
+	if err != nil {
+		writeRiderReportError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, report)
+}
+
+// GetMyReliability handles GET /riders/me/reliability
+func (h *RiderReliabilityHandler) GetMyReliability(w http.ResponseWriter, r *http.Request) {
+	riderID := getUserIDFromContext(r.Context())
+	if riderID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	reliability, err := h.reliabilityService.GetReliability(r.Context(), riderID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get rider reliability")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reliability)
+}
+
+// GetRiderReliability handles GET /internal/support/riders/{riderId}/reliability
+func (h *RiderReliabilityHandler) GetRiderReliability(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	riderID, err := uuid.Parse(chi.URLParam(r, "riderId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid rider ID")
+		return
+	}
+
+	reliability, err := h.reliabilityService.GetReliability(r.Context(), riderID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get rider reliability")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reliability)
+}
+
+// ListRiderReports handles GET /internal/support/riders/{riderId}/rider-reports
+func (h *RiderReliabilityHandler) ListRiderReports(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	riderID, err := uuid.Parse(chi.URLParam(r, "riderId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid rider ID")
+		return
+	}
+
+	reports, err := h.reliabilityService.ListRiderReports(r.Context(), riderID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list rider reports")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"reports": reports,
+	})
+}
+
+// VoidReport handles POST /internal/support/rider-reports/{reportId}/void
+func (h *RiderReliabilityHandler) VoidReport(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+	agentID := getUserIDFromContext(r.Context())
+	if agentID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	reportID, err := uuid.Parse(chi.URLParam(r, "reportId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid report ID")
+		return
+	}
+
+	var req VoidRiderReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	report, err := h.reliabilityService.VoidReport(r.Context(), reportID, agentID, req.Note)
+	if err != nil {
+		writeRiderReportError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+func writeRiderReportError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrRideNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, errMsgRideNotFound)
+	case domain.ErrRiderReportNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeRiderReportNotFound, "Rider report not found")
+	case domain.ErrForbidden:
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Not allowed to report this rider")
+	case domain.ErrInvalidRiderReport:
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRiderReport, err.Error())
+	case domain.ErrRiderReportExists:
+		writeError(w, http.StatusConflict, domain.ErrCodeRiderReportExists, err.Error())
+	case domain.ErrRiderReportVoided:
+		writeError(w, http.StatusConflict, domain.ErrCodeRiderReportVoided, err.Error())
+	case domain.ErrRiderReportWindowClosed:
+		writeError(w, http.StatusUnprocessableEntity, domain.ErrCodeReportWindowClosed, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to process rider report")
+	}
+}
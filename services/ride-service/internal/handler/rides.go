@@ -11,8 +11,11 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/eta"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/locale"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/pricing"
 )
 
@@ -31,7 +34,9 @@ type RideService interface {
 	UpdateRideStatus(ctx context.Context, rideID uuid.UUID, status domain.RideStatus) error
 	RateRide(ctx context.Context, rideID uuid.UUID, rating float32, isRider bool) error
 	GetActiveRide(ctx context.Context, userID uuid.UUID, isRider bool) (*domain.Ride, error)
-	GetRideHistory(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Ride, int64, error)
+	GetRideHistory(ctx context.Context, userID uuid.UUID, asDriver bool, filter *domain.RideHistoryFilter) (*domain.RideHistoryPage, error)
+	ExportRideHistory(ctx context.Context, userID uuid.UUID, asDriver bool, filter *domain.RideHistoryFilter, write func([]*domain.Ride) error) error
+	GetRideEvents(ctx context.Context, rideID uuid.UUID) ([]*domain.RideEvent, error)
 }
 
 // DriverService defines the driver service interface
@@ -40,6 +45,7 @@ type DriverService interface {
 	UpdateLocation(ctx context.Context, driverID uuid.UUID, loc *domain.DriverLocation) error
 	AcceptRide(ctx context.Context, rideID, driverID uuid.UUID) error
 	DeclineRide(ctx context.Context, rideID, driverID uuid.UUID) error
+	SetDriverStatus(ctx context.Context, driverID uuid.UUID, status domain.DriverStatus) error
 }
 
 // MatchingService defines the matching service interface
@@ -54,21 +60,94 @@ type RideHandler struct {
 	driverService  DriverService
 	matchingService MatchingService
 	pricingEngine  *pricing.Engine
+	cities         *cityconfig.Registry
+	what3words     What3WordsResolver
+	router         eta.RoutingClient
+	weather        WeatherReporter
+	killSwitches   KillSwitchChecker
+	rates          ExchangeRateSource
+	promos         PromoPreviewer
 }
 
-// NewRideHandler creates a new ride handler
+// WeatherReporter reports a city's current weather, nil when unknown
+type WeatherReporter interface {
+	Condition(cityCode string) *domain.WeatherCondition
+}
+
+// PromoPreviewer shows what a promo code takes off estimated fares
+type PromoPreviewer interface {
+	PreviewPromo(
+		ctx context.Context,
+		code string,
+		riderID uuid.UUID,
+		city *domain.CityConfig,
+		h3Cell string,
+		distance float64,
+		duration int64,
+		estimates map[domain.RideType]*domain.PriceBreakdown,
+	) (map[domain.RideType]*domain.PriceBreakdown, error)
+}
+
+// NewRideHandler creates a new ride handler. cities may be nil, in which case
+// the built-in service areas and currency defaults apply.
 func NewRideHandler(
 	rideService RideService,
 	driverService DriverService,
 	matchingService MatchingService,
 	pricingEngine *pricing.Engine,
+	cities *cityconfig.Registry,
 ) *RideHandler {
 	return &RideHandler{
 		rideService:     rideService,
 		driverService:   driverService,
 		matchingService: matchingService,
 		pricingEngine:   pricingEngine,
+		cities:          cities,
+	}
+}
+
+// cityAt returns the configured city containing a point, if any
+func (h *RideHandler) cityAt(lat, lng float64) (*domain.CityConfig, bool) {
+	if h.cities == nil {
+		return nil, false
+	}
+	return h.cities.FindByLocation(lat, lng)
+}
+
+// SetRouter prices estimates on the routed distance, honoring the rider's
+// avoidance preferences. Without it, estimates use straight-line distance.
+func (h *RideHandler) SetRouter(router eta.RoutingClient) {
+	h.router = router
+}
+
+// SetWeather pads estimated ETAs and warns riders in severe weather
+func (h *RideHandler) SetWeather(weather WeatherReporter) {
+	h.weather = weather
+}
+
+// SetKillSwitches refuses ride requests in cities where ops have switched
+// them off
+func (h *RideHandler) SetKillSwitches(killSwitches KillSwitchChecker) {
+	h.killSwitches = killSwitches
+}
+
+// SetRates shows estimates converted into the rider's home currency, or
+// the one they ask for, beside the local fare
+func (h *RideHandler) SetRates(rates ExchangeRateSource) {
+	h.rates = rates
+}
+
+// SetPromos shows estimates with the rider's promo code applied
+func (h *RideHandler) SetPromos(promos PromoPreviewer) {
+	h.promos = promos
+}
+
+// h3Resolution returns the indexing resolution for a point
+func (h *RideHandler) h3Resolution(lat, lng float64) int {
+	if h.cities == nil {
+		return geo.H3Resolution
 	}
+	return h.cities.H3Resolution(lat, lng)
 }
 
 // Response helpers
@@ -80,8 +159,9 @@ type APIResponse struct {
 }
 
 type APIError struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
@@ -116,6 +196,14 @@ type RequestRideRequest struct {
 	ScheduledFor    *time.Time    `json:"scheduled_for,omitempty"`
 	PromoCode       string        `json:"promo_code,omitempty"`
 	Notes           string        `json:"notes,omitempty"`
+	RouteQuoteID    string        `json:"route_quote_id,omitempty"`   // from POST /rides/route-options
+	RoutePreference string        `json:"route_preference,omitempty"` // FASTEST, SHORTEST or AVOID_TOLLS; FASTEST when empty
+	AvoidTolls      bool          `json:"avoid_tolls,omitempty"`      // route around toll roads and express lanes
+	AvoidHighways   bool          `json:"avoid_highways,omitempty"`
+	BusinessAccountID string      `json:"business_account_id,omitempty"` // ride on one of GET /users/me/business-profiles
+	CostCenter      string        `json:"cost_center,omitempty"`         // the profile's default when empty
+	RedeemPoints    int64         `json:"redeem_points,omitempty"`       // loyalty points to take off the fare
+	Preference      string        `json:"preference,omitempty"`          // GREEN to be matched with electric and hybrid vehicles first
 }
 
 type LocationInput struct {
@@ -124,6 +212,9 @@ type LocationInput struct {
 	Address   string  `json:"address,omitempty"`
 	Name      string  `json:"name,omitempty"`
 	PlaceID   string  `json:"place_id,omitempty"`
+	Directions string `json:"directions,omitempty"` // free text for the driver
+	PlusCode  string  `json:"plus_code,omitempty"` // in place of coordinates; short codes may name their city
+	W3W       string  `json:"w3w,omitempty"`       // what3words address in place of coordinates, where available
 }
 
 type CancelRideRequest struct {
@@ -143,19 +234,31 @@ type UpdateLocationRequest struct {
 	Accuracy  float64 `json:"accuracy"`
 }
 
+type UpdateDriverStatusRequest struct {
+	Status string `json:"status"` // ONLINE or OFFLINE
+}
+
 type PriceEstimateRequest struct {
 	PickupLatitude   float64 `json:"pickup_latitude"`
 	PickupLongitude  float64 `json:"pickup_longitude"`
 	DropoffLatitude  float64 `json:"dropoff_latitude"`
 	DropoffLongitude float64 `json:"dropoff_longitude"`
 	Currency         string  `json:"currency,omitempty"`
+	DisplayCurrency  string  `json:"display_currency,omitempty"` // shown beside the local fare; the rider's home currency when empty
+	AvoidTolls       bool    `json:"avoid_tolls,omitempty"`
+	AvoidHighways    bool    `json:"avoid_highways,omitempty"`
+	PromoCode        string  `json:"promo_code,omitempty"`
 }
 
 type PriceEstimateResponse struct {
-	Estimates map[string]PriceEstimate `json:"estimates"`
-	Distance  int64                    `json:"distance_meters"`
-	Duration  int64                    `json:"duration_seconds"`
-	Surge     float64                  `json:"surge_multiplier"`
+	Estimates    map[string]PriceEstimate `json:"estimates"`
+	Distance     int64                    `json:"distance_meters"`
+	Duration     int64                    `json:"duration_seconds"`
+	Surge        float64                  `json:"surge_multiplier"`
+	ZoneDiscount float64                  `json:"zone_discount_multiplier"`
+	WeatherNotice string                  `json:"weather_notice,omitempty"`
+	PromoCode    string                   `json:"promo_code,omitempty"`
+	PromoError   string                   `json:"promo_error,omitempty"` // why the code took nothing off
 }
 
 type PriceEstimate struct {
@@ -164,6 +267,10 @@ type PriceEstimate struct {
 	TotalFormatted string `json:"total_formatted"`
 	Currency       string `json:"currency"`
 	ETA            int64  `json:"eta_seconds"`
+	ZoneDiscount   int64  `json:"zone_discount,omitempty"`
+	PromoDiscount  int64  `json:"promo_discount,omitempty"`
+	CO2Grams       int64  `json:"co2_grams"` // estimated for the vehicle the ride type is usually driven in
+	Display        *domain.DisplayAmount `json:"display,omitempty"` // indicative only; the ride settles in Currency
 }
 
 type NearbyDriversResponse struct {
@@ -173,6 +280,7 @@ type NearbyDriversResponse struct {
 type NearbyDriverInfo struct {
 	ID           string  `json:"id"`
 	FirstName    string  `json:"first_name"`
+	LastInitial  string  `json:"last_initial,omitempty"`
 	Rating       float64 `json:"rating"`
 	VehicleType  string  `json:"vehicle_type"`
 	VehicleMake  string  `json:"vehicle_make"`
@@ -201,6 +309,17 @@ func (h *RideHandler) RequestRide(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// Plus codes may stand in for coordinates
+	if err := h.resolvePlusCodes(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidLocation, "Invalid plus code: "+err.Error())
+		return
+	}
+	
+	if err := h.resolveWhat3Words(r.Context(), &req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidLocation, "Invalid w3w address: "+err.Error())
+		return
+	}
+	
 	// Validate locations
 	if !geo.IsValidCoordinate(req.PickupLocation.Latitude, req.PickupLocation.Longitude) {
 		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidLocation, "Invalid pickup location")
@@ -211,12 +330,31 @@ func (h *RideHandler) RequestRide(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Check service area
-	inService, _ := geo.IsInServiceArea(req.PickupLocation.Latitude, req.PickupLocation.Longitude)
+	// Check service area; configured cities take precedence over the built-in areas
+	city, inService := h.cityAt(req.PickupLocation.Latitude, req.PickupLocation.Longitude)
+	if !inService {
+		inService, _ = geo.IsInServiceArea(req.PickupLocation.Latitude, req.PickupLocation.Longitude)
+	}
 	if !inService {
 		writeError(w, http.StatusBadRequest, domain.ErrCodeOutOfService, "Pickup location is outside service area")
 		return
 	}
+	if city != nil && !city.SupportsRideType(domain.RideType(req.Type)) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeRideTypeUnavailable, "Ride type is not offered in "+city.Name)
+		return
+	}
+	if city != nil && h.killSwitches != nil {
+		// Fail open: a Redis outage should not stop rides on its own
+		sw, err := h.killSwitches.Check(r.Context(), city.Code, domain.KillSwitchRides)
+		if err != nil {
+			log.Warn().Err(err).Str("city", city.Code).Msg("Failed to check ride kill switch")
+		}
+		if sw != nil {
+			writeFeatureDisabled(w, sw)
+			return
+		}
+	}
+	resolution := h.h3Resolution(req.PickupLocation.Latitude, req.PickupLocation.Longitude)
 	
 	// Convert to domain request
 	rideReq := &domain.RideRequest{
@@ -227,7 +365,10 @@ func (h *RideHandler) RequestRide(w http.ResponseWriter, r *http.Request) {
 			Address:   req.PickupLocation.Address,
 			Name:      req.PickupLocation.Name,
 			PlaceID:   req.PickupLocation.PlaceID,
-			H3Cell:    geo.H3Cell(req.PickupLocation.Latitude, req.PickupLocation.Longitude, geo.H3Resolution),
+			Directions: req.PickupLocation.Directions,
+			PlusCode:   req.PickupLocation.PlusCode,
+			What3Words: req.PickupLocation.W3W,
+			H3Cell:    geo.H3Cell(req.PickupLocation.Latitude, req.PickupLocation.Longitude, resolution),
 		},
 		DropoffLocation: domain.Location{
 			Latitude:  req.DropoffLocation.Latitude,
@@ -235,13 +376,60 @@ func (h *RideHandler) RequestRide(w http.ResponseWriter, r *http.Request) {
 			Address:   req.DropoffLocation.Address,
 			Name:      req.DropoffLocation.Name,
 			PlaceID:   req.DropoffLocation.PlaceID,
-			H3Cell:    geo.H3Cell(req.DropoffLocation.Latitude, req.DropoffLocation.Longitude, geo.H3Resolution),
+			Directions: req.DropoffLocation.Directions,
+			PlusCode:   req.DropoffLocation.PlusCode,
+			What3Words: req.DropoffLocation.W3W,
+			H3Cell:    geo.H3Cell(req.DropoffLocation.Latitude, req.DropoffLocation.Longitude, resolution),
 		},
 		Type:          domain.RideType(req.Type),
 		PaymentMethod: domain.PaymentMethod(req.PaymentMethod),
 		ScheduledFor:  req.ScheduledFor,
 		PromoCode:     req.PromoCode,
 		Notes:         req.Notes,
+		Avoid:         domain.RouteAvoidance{Tolls: req.AvoidTolls, Highways: req.AvoidHighways},
+		CostCenter:    req.CostCenter,
+		RedeemPoints:  req.RedeemPoints,
+	}
+	
+	if req.RedeemPoints < 0 {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "redeem_points cannot be negative")
+		return
+	}
+	
+	// The business profile the ride is charged to, if any
+	if req.BusinessAccountID != "" {
+		accountID, err := uuid.Parse(req.BusinessAccountID)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid business_account_id")
+			return
+		}
+		rideReq.BusinessAccountID = &accountID
+	}
+	
+	// How the rider asked to be matched, if at all
+	if req.Preference != "" {
+		preference := domain.RidePreference(req.Preference)
+		if preference != domain.RidePreferenceGreen {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "preference must be GREEN")
+			return
+		}
+		rideReq.Preference = preference
+	}
+	
+	// The route the rider picked at confirmation, if any
+	if req.RouteQuoteID != "" {
+		quoteID, err := uuid.Parse(req.RouteQuoteID)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid route_quote_id")
+			return
+		}
+		preference := domain.RoutePreference(req.RoutePreference)
+		if preference != "" && !preference.IsValid() {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "route_preference must be FASTEST, SHORTEST or AVOID_TOLLS")
+			return
+		}
+		rideReq.RouteQuoteID = &quoteID
+		rideReq.RoutePreference = preference
 	}
 	
 	// Convert stops
@@ -252,11 +440,61 @@ func (h *RideHandler) RequestRide(w http.ResponseWriter, r *http.Request) {
 			Address:   stop.Address,
 			Name:      stop.Name,
 			PlaceID:   stop.PlaceID,
+			Directions: stop.Directions,
+			PlusCode:   stop.PlusCode,
+			What3Words: stop.W3W,
 		})
 	}
 	
 	// Create ride
 	ride, err := h.rideService.RequestRide(r.Context(), rideReq)
+	if err == domain.ErrRideTypeUnavailable {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeRideTypeUnavailable, err.Error())
+		return
+	}
+	if err == domain.ErrInvalidScheduleTime {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidScheduleTime, err.Error())
+		return
+	}
+	if err == domain.ErrInvalidPromoCode {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidPromoCode, err.Error())
+		return
+	}
+	if err == domain.ErrPromoNotEligible {
+		writeError(w, http.StatusBadRequest, domain.ErrCodePromoNotEligible, err.Error())
+		return
+	}
+	if err == domain.ErrRouteOptionNotFound {
+		writeError(w, http.StatusConflict, domain.ErrCodeRouteOptionNotFound, err.Error())
+		return
+	}
+	if err == domain.ErrPromoCodeAlreadyUsed {
+		writeError(w, http.StatusConflict, domain.ErrCodePromoCodeAlreadyUsed, err.Error())
+		return
+	}
+	if err == domain.ErrRideRequestInProgress {
+		writeError(w, http.StatusConflict, domain.ErrCodeRideRequestInProgress, err.Error())
+		return
+	}
+	if err == domain.ErrPrepaymentRequired {
+		writeError(w, http.StatusUnprocessableEntity, domain.ErrCodePrepaymentRequired, err.Error())
+		return
+	}
+	if err == domain.ErrInsufficientLoyaltyPoints {
+		writeError(w, http.StatusConflict, domain.ErrCodeInsufficientLoyaltyPoints, err.Error())
+		return
+	}
+	if err == domain.ErrLoyaltyRedemptionTooSmall {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeLoyaltyRedemptionTooSmall, err.Error())
+		return
+	}
+	if err == domain.ErrLoyaltyNotRedeemable {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeLoyaltyNotRedeemable, err.Error())
+		return
+	}
+	if err != nil && writeBusinessRideError(w, err) {
+		return
+	}
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to request ride")
 		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to request ride")
@@ -267,7 +505,48 @@ func (h *RideHandler) RequestRide(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetRide handles GET /rides/{rideId}
+// Riders, drivers and support each get their own view of the ride.
 func (h *RideHandler) GetRide(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+	
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+	
+	ride, err := h.rideService.GetRide(r.Context(), rideID)
+	if err != nil {
+		if err == domain.ErrRideNotFound {
+			writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, errMsgRideNotFound)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get ride")
+		return
+	}
+	
+	view := ride.ViewFor(ride.PartyOf(userID, getUserRoleFromContext(r.Context())))
+	if view == nil {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Forbidden")
+		return
+	}
+	
+	writeJSON(w, http.StatusOK, view)
+}
+
+// GetRideEvents handles GET /rides/{rideId}/events
+// Returns the ride timeline; limited to the ride's rider/driver and support staff.
+func (h *RideHandler) GetRideEvents(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+	
 	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
 	if err != nil {
 		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
@@ -284,7 +563,22 @@ func (h *RideHandler) GetRide(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	writeJSON(w, http.StatusOK, ride)
+	isParticipant := ride.RiderID == userID || (ride.DriverID != nil && *ride.DriverID == userID)
+	if !isParticipant && !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Forbidden")
+		return
+	}
+	
+	events, err := h.rideService.GetRideEvents(r.Context(), rideID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get ride events")
+		return
+	}
+	
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ride_id": rideID,
+		"events":  events,
+	})
 }
 
 // CancelRide handles POST /rides/{rideId}/cancel
@@ -312,6 +606,8 @@ func (h *RideHandler) CancelRide(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, errMsgRideNotFound)
 		case domain.ErrRideAlreadyEnded:
 			writeError(w, http.StatusBadRequest, domain.ErrCodeRideAlreadyEnded, "Ride has already ended")
+		case domain.ErrRideConflict:
+			writeError(w, http.StatusConflict, domain.ErrCodeRideConflict, "Ride was updated at the same time, please try again")
 		default:
 			writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to cancel ride")
 		}
@@ -323,6 +619,12 @@ func (h *RideHandler) CancelRide(w http.ResponseWriter, r *http.Request) {
 
 // TrackRide handles GET /rides/{rideId}/track
 func (h *RideHandler) TrackRide(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+	
 	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
 	if err != nil {
 		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
@@ -339,19 +641,28 @@ func (h *RideHandler) TrackRide(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	view := ride.ViewFor(ride.PartyOf(userID, getUserRoleFromContext(r.Context())))
+	if view == nil {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Forbidden")
+		return
+	}
+	
 	// Return tracking info
 	trackingInfo := map[string]interface{}{
-		"ride_id":          ride.ID,
-		"status":           ride.Status,
-		"current_location": ride.CurrentLocation,
-		"pickup_location":  ride.PickupLocation,
-		"dropoff_location": ride.DropoffLocation,
-		"driver_id":        ride.DriverID,
+		"ride_id":          view.ID,
+		"status":           view.Status,
+		"current_location": view.CurrentLocation,
+		"pickup_location":  view.PickupLocation,
+		"dropoff_location": view.DropoffLocation,
+		"driver_id":        view.DriverID,
+	}
+	if view.Contact != nil {
+		trackingInfo["contact"] = view.Contact
 	}
 	
 	// Add ETA if in progress
-	if ride.Status == domain.RideStatusInProgress && ride.Route != nil {
-		trackingInfo["eta_seconds"] = ride.Route.DurationSeconds
+	if view.Status == domain.RideStatusInProgress && view.Route != nil {
+		trackingInfo["eta_seconds"] = view.Route.DurationSeconds
 	}
 	
 	writeJSON(w, http.StatusOK, trackingInfo)
@@ -392,6 +703,10 @@ func (h *RideHandler) RateRide(w http.ResponseWriter, r *http.Request) {
 	isRider := ride.RiderID == userID
 	
 	if err := h.rideService.RateRide(r.Context(), rideID, req.Rating, isRider); err != nil {
+		if err == domain.ErrRideConflict {
+			writeError(w, http.StatusConflict, domain.ErrCodeRideConflict, "Ride was updated at the same time, please try again")
+			return
+		}
 		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to rate ride")
 		return
 	}
@@ -416,17 +731,66 @@ func (h *RideHandler) GetPriceEstimate(w http.ResponseWriter, r *http.Request) {
 	// Estimate duration
 	duration := geo.EstimateETA(distance, "car")
 	
+	// A routed trip replaces the estimate, so avoiding tolls or highways
+	// shows up in the fare
+	if h.router != nil {
+		route, err := h.router.GetRoute(r.Context(), &eta.ETARequest{
+			OriginLat:     req.PickupLatitude,
+			OriginLng:     req.PickupLongitude,
+			DestLat:       req.DropoffLatitude,
+			DestLng:       req.DropoffLongitude,
+			DepartureTime: time.Now(),
+			AvoidTolls:    req.AvoidTolls,
+			AvoidHighways: req.AvoidHighways,
+		})
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to route price estimate, using straight-line distance")
+		} else {
+			distance = route.Distance
+			duration = int64(route.Duration.Seconds())
+		}
+	}
+	
 	// Get H3 cell for surge
-	h3Cell := geo.H3Cell(req.PickupLatitude, req.PickupLongitude, geo.H3Resolution)
+	h3Cell := geo.H3Cell(req.PickupLatitude, req.PickupLongitude, h.h3Resolution(req.PickupLatitude, req.PickupLongitude))
 	
-	// Default currency
-	currency := domain.CurrencyNGN
+	// Currency of the request's market unless the client asks for one
+	currency := locale.Currency(r.Context())
 	if req.Currency != "" {
 		currency = domain.Currency(req.Currency)
 	}
 	
-	// Get estimates for all ride types
-	estimates, err := h.pricingEngine.GetPriceEstimate(distance, duration, currency, h3Cell)
+	// Estimate the pickup city's ride types with its own fares. Rides settle
+	// in the city's currency, so a different currency asked for there is
+	// only shown beside the fare.
+	var estimates map[domain.RideType]*domain.PriceBreakdown
+	var err error
+	city, inCity := h.cityAt(req.PickupLatitude, req.PickupLongitude)
+	requestedDisplay := req.DisplayCurrency
+	if inCity && requestedDisplay == "" && req.Currency != "" && domain.Currency(req.Currency) != city.Currency {
+		requestedDisplay = req.Currency
+	}
+	display, err := displayCurrency(r.Context(), requestedDisplay)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeUnsupportedCurrency, "Unsupported display currency")
+		return
+	}
+	
+	// Severe weather slows every trip in the city, so pad the ETAs the fares
+	// are estimated on and warn the rider
+	var weather *domain.WeatherCondition
+	if inCity && h.weather != nil {
+		if condition := h.weather.Condition(city.Code); condition != nil && condition.Severity == domain.WeatherSevere {
+			weather = condition
+			duration = city.Weather.PadETA(duration, weather.Severity)
+		}
+	}
+	
+	if inCity {
+		estimates, err = h.pricingEngine.GetCityPriceEstimate(city, distance, duration, h3Cell)
+	} else {
+		estimates, err = h.pricingEngine.GetPriceEstimate(distance, duration, currency, h3Cell)
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, domain.ErrCodePricingFailed, "Failed to calculate price")
 		return
@@ -437,22 +801,88 @@ func (h *RideHandler) GetPriceEstimate(w http.ResponseWriter, r *http.Request) {
 		Estimates: make(map[string]PriceEstimate),
 		Distance:  int64(distance),
 		Duration:  duration,
-		Surge:     h.pricingEngine.GetSurgeMultiplier(h3Cell),
+		Surge:        h.pricingEngine.GetSurgeMultiplier(h3Cell),
+		ZoneDiscount: h.pricingEngine.GetZoneDiscount(h3Cell),
+	}
+	if inCity && h.pricingEngine.SurgeDisabled(city.Code) {
+		response.Surge = 1.0
+	}
+	if weather != nil {
+		response.WeatherNotice = domain.WeatherNoticeSevere
+	}
+	
+	// Show the rider's promo code on the fares it applies to. Nothing is
+	// reserved from its campaign until the ride is requested.
+	if req.PromoCode != "" {
+		var promoCity *domain.CityConfig
+		if inCity {
+			promoCity = city
+		}
+		discounted, code := h.previewPromo(r.Context(), req.PromoCode, promoCity, h3Cell, distance, duration, estimates)
+		for rideType, price := range discounted {
+			estimates[rideType] = price
+		}
+		response.PromoCode = domain.NormalizePromoCode(req.PromoCode)
+		response.PromoError = code
 	}
 	
 	for rideType, price := range estimates {
+		etaSeconds := geo.EstimateETA(distance, string(rideType))
+		if weather != nil {
+			etaSeconds = city.Weather.PadETA(etaSeconds, weather.Severity)
+		}
 		response.Estimates[string(rideType)] = PriceEstimate{
 			Type:           string(rideType),
 			Total:          price.Total,
 			TotalFormatted: pricing.FormatPrice(price.Total, price.Currency),
 			Currency:       string(price.Currency),
-			ETA:            geo.EstimateETA(distance, string(rideType)),
+			ETA:            etaSeconds,
+			ZoneDiscount:   price.ZoneDiscount,
+			PromoDiscount:  price.PromoDiscount,
+			CO2Grams:       domain.EstimateRideCO2Grams(rideType, int64(distance)),
+			Display:        displayAmount(h.rates, price.Total, price.Currency, display),
 		}
 	}
 	
 	writeJSON(w, http.StatusOK, response)
 }
 
+// previewPromo applies a promo code to estimates for the signed-in rider,
+// returning the fares it discounts or the error code saying why it applies
+// to none
+func (h *RideHandler) previewPromo(
+	ctx context.Context,
+	code string,
+	city *domain.CityConfig,
+	h3Cell string,
+	distance float64,
+	duration int64,
+	estimates map[domain.RideType]*domain.PriceBreakdown,
+) (map[domain.RideType]*domain.PriceBreakdown, string) {
+	riderID := getUserIDFromContext(ctx)
+	if riderID == uuid.Nil {
+		return nil, domain.ErrCodeUnauthorized
+	}
+	if h.promos == nil {
+		return nil, domain.ErrCodeInvalidPromoCode
+	}
+	
+	discounted, err := h.promos.PreviewPromo(ctx, code, riderID, city, h3Cell, distance, duration, estimates)
+	switch {
+	case err == nil:
+		return discounted, ""
+	case err == domain.ErrInvalidPromoCode:
+		return nil, domain.ErrCodeInvalidPromoCode
+	case err == domain.ErrPromoNotEligible:
+		return nil, domain.ErrCodePromoNotEligible
+	case err == domain.ErrPromoCodeAlreadyUsed:
+		return nil, domain.ErrCodePromoCodeAlreadyUsed
+	default:
+		log.Warn().Err(err).Msg("Failed to preview promo code on estimate")
+		return nil, domain.ErrCodeInternal
+	}
+}
+
 // GetSurgeMultiplier handles GET /pricing/surge
 func (h *RideHandler) GetSurgeMultiplier(w http.ResponseWriter, r *http.Request) {
 	latStr := r.URL.Query().Get("lat")
@@ -470,12 +900,16 @@ func (h *RideHandler) GetSurgeMultiplier(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	
-	h3Cell := geo.H3Cell(lat, lng, geo.H3Resolution)
+	h3Cell := geo.H3Cell(lat, lng, h.h3Resolution(lat, lng))
 	surge := h.pricingEngine.GetSurgeMultiplier(h3Cell)
+	if city, ok := h.cityAt(lat, lng); ok && h.pricingEngine.SurgeDisabled(city.Code) {
+		surge = 1.0
+	}
 	
 	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"surge_multiplier": surge,
-		"h3_cell":          h3Cell,
+		"surge_multiplier":         surge,
+		"zone_discount_multiplier": h.pricingEngine.GetZoneDiscount(h3Cell),
+		"h3_cell":                  h3Cell,
 	})
 }
 
@@ -503,7 +937,7 @@ func (h *RideHandler) UpdateDriverLocation(w http.ResponseWriter, r *http.Reques
 		Location: domain.Location{
 			Latitude:  req.Latitude,
 			Longitude: req.Longitude,
-			H3Cell:    geo.H3Cell(req.Latitude, req.Longitude, geo.H3Resolution),
+			H3Cell:    geo.H3Cell(req.Latitude, req.Longitude, h.h3Resolution(req.Latitude, req.Longitude)),
 		},
 		Heading:   req.Heading,
 		Speed:     req.Speed,
@@ -512,6 +946,10 @@ func (h *RideHandler) UpdateDriverLocation(w http.ResponseWriter, r *http.Reques
 	}
 	
 	if err := h.driverService.UpdateLocation(r.Context(), driverID, loc); err != nil {
+		if err == domain.ErrDriverRestricted {
+			writeError(w, http.StatusForbidden, domain.ErrCodeDriverRestricted, "Driver account is restricted")
+			return
+		}
 		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to update location")
 		return
 	}
@@ -519,6 +957,49 @@ func (h *RideHandler) UpdateDriverLocation(w http.ResponseWriter, r *http.Reques
 	writeJSON(w, http.StatusOK, map[string]string{"message": "Location updated"})
 }
 
+// UpdateDriverStatus handles PUT /drivers/status
+func (h *RideHandler) UpdateDriverStatus(w http.ResponseWriter, r *http.Request) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+	
+	var req UpdateDriverStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid request body")
+		return
+	}
+	
+	status := domain.DriverStatus(req.Status)
+	if status != domain.DriverStatusOnline && status != domain.DriverStatusOffline {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Status must be ONLINE or OFFLINE")
+		return
+	}
+	
+	if err := h.driverService.SetDriverStatus(r.Context(), driverID, status); err != nil {
+		switch err {
+		case domain.ErrDriverNotFound:
+			writeError(w, http.StatusNotFound, domain.ErrCodeDriverNotFound, "Driver not found")
+		case domain.ErrDriverRestricted:
+			writeError(w, http.StatusForbidden, domain.ErrCodeDriverRestricted, "Driver account is restricted")
+		case domain.ErrBackgroundCheckRequired:
+			writeError(w, http.StatusForbidden, domain.ErrCodeBackgroundCheckRequired, "Background check has not cleared")
+		case domain.ErrDriverDocumentExpired:
+			writeError(w, http.StatusForbidden, domain.ErrCodeDriverDocumentExpired, err.Error())
+		case domain.ErrIdentityCheckRequired:
+			writeError(w, http.StatusForbidden, domain.ErrCodeIdentityCheckRequired, err.Error())
+		case domain.ErrIdentityCheckLocked:
+			writeError(w, http.StatusForbidden, domain.ErrCodeIdentityCheckLocked, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to update status")
+		}
+		return
+	}
+	
+	writeJSON(w, http.StatusOK, map[string]string{"status": string(status)})
+}
+
 // GetNearbyDrivers handles GET /drivers/nearby
 func (h *RideHandler) GetNearbyDrivers(w http.ResponseWriter, r *http.Request) {
 	latStr := r.URL.Query().Get("lat")
@@ -562,25 +1043,27 @@ func (h *RideHandler) GetNearbyDrivers(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	for _, d := range drivers {
+		driver := d.Driver.CrossPartyView()
 		info := NearbyDriverInfo{
-			ID:         d.Driver.ID.String(),
-			FirstName:  d.Driver.FirstName,
-			Rating:     d.Driver.Rating,
-			ETASeconds: d.ETASeconds,
-			DistanceM:  d.DistanceM,
-			Heading:    d.Driver.Heading,
+			ID:          driver.ID.String(),
+			FirstName:   driver.FirstName,
+			LastInitial: driver.LastName,
+			Rating:      driver.Rating,
+			ETASeconds:  d.ETASeconds,
+			DistanceM:   d.DistanceM,
+			Heading:     driver.Heading,
 		}
 		
-		if d.Driver.CurrentLocation != nil {
-			info.Latitude = d.Driver.CurrentLocation.Latitude
-			info.Longitude = d.Driver.CurrentLocation.Longitude
+		if driver.CurrentLocation != nil {
+			info.Latitude = driver.CurrentLocation.Latitude
+			info.Longitude = driver.CurrentLocation.Longitude
 		}
 		
-		if d.Driver.Vehicle != nil {
-			info.VehicleType = string(d.Driver.Vehicle.Type)
-			info.VehicleMake = d.Driver.Vehicle.Make
-			info.VehicleModel = d.Driver.Vehicle.Model
-			info.LicensePlate = d.Driver.Vehicle.LicensePlate
+		if driver.Vehicle != nil {
+			info.VehicleType = string(driver.Vehicle.Type)
+			info.VehicleMake = driver.Vehicle.Make
+			info.VehicleModel = driver.Vehicle.Model
+			info.LicensePlate = driver.Vehicle.LicensePlate
 		}
 		
 		response.Drivers = append(response.Drivers, info)
@@ -609,8 +1092,16 @@ func (h *RideHandler) AcceptRide(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, "Ride not found")
 		case domain.ErrDriverNotAvailable:
 			writeError(w, http.StatusBadRequest, domain.ErrCodeDriverNotAvailable, "Driver not available")
+		case domain.ErrDriverRestricted:
+			writeError(w, http.StatusForbidden, domain.ErrCodeDriverRestricted, "Driver account is restricted")
 		case domain.ErrRideAlreadyAssigned:
 			writeError(w, http.StatusConflict, domain.ErrCodeRideAlreadyAssigned, "Ride already assigned")
+		case domain.ErrBlockedByRider:
+			writeError(w, http.StatusConflict, domain.ErrCodeRideUnavailable, "Ride is not available to this driver")
+		case domain.ErrTrainingRequired:
+			writeError(w, http.StatusForbidden, domain.ErrCodeTrainingRequired, "Complete the required training to accept this ride")
+		case domain.ErrInvalidStatusTransition:
+			writeError(w, http.StatusConflict, domain.ErrCodeInvalidStatusTransition, "Ride can no longer be accepted")
 		default:
 			writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to accept ride")
 		}
@@ -618,9 +1109,13 @@ func (h *RideHandler) AcceptRide(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	// Get updated ride
-	ride, _ := h.rideService.GetRide(r.Context(), rideID)
+	ride, err := h.rideService.GetRide(r.Context(), rideID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get ride")
+		return
+	}
 	
-	writeJSON(w, http.StatusOK, ride)
+	writeJSON(w, http.StatusOK, ride.ViewFor(domain.RidePartyDriver))
 }
 
 // DeclineRide handles POST /driver/rides/{rideId}/decline
@@ -657,3 +1152,11 @@ func getUserIDFromContext(ctx context.Context) uuid.UUID {
 	}
 	return uuid.Nil
 }
+
+// Helper to get user role from context (set by auth middleware)
+func getUserRoleFromContext(ctx context.Context) string {
+	if role, ok := ctx.Value("user_role").(string); ok {
+		return role
+	}
+	return ""
+}
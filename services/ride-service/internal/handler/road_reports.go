@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// RoadReportService defines the driver road report interface
+type RoadReportService interface {
+	Submit(ctx context.Context, driverID uuid.UUID, category domain.RoadReportCategory, lat, lng float64) (*domain.RoadReport, error)
+	List(ctx context.Context, driverID uuid.UUID) ([]*domain.RoadReport, error)
+	Confirm(ctx context.Context, driverID, reportID uuid.UUID, stillThere bool) (*domain.RoadReport, error)
+}
+
+// RoadReportHandler takes drivers' reports of road issues
+type RoadReportHandler struct {
+	reportService RoadReportService
+}
+
+// NewRoadReportHandler creates a new road report handler
+func NewRoadReportHandler(reportService RoadReportService) *RoadReportHandler {
+	return &RoadReportHandler{reportService: reportService}
+}
+
+// SubmitRoadReportRequest is a driver's report of a road issue
+type SubmitRoadReportRequest struct {
+	Category  string  `json:"category"` // BLOCKED_ROAD, POLICE_CHECKPOINT or FLOODING
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// ConfirmRoadReportRequest answers whether a reported issue is still there
+type ConfirmRoadReportRequest struct {
+	StillThere bool `json:"still_there"`
+}
+
+// SubmitRoadReport handles POST /driver/road-reports
+// The report comes back PENDING until enough drivers report the same
+// issue nearby, then CONFIRMED with the time it will lapse unless
+// confirmed again.
+func (h *RoadReportHandler) SubmitRoadReport(w http.ResponseWriter, r *http.Request) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req SubmitRoadReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	report, err := h.reportService.Submit(r.Context(), driverID, domain.RoadReportCategory(req.Category), req.Latitude, req.Longitude)
+	if err != nil {
+		writeRoadReportError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, report)
+}
+
+// ListMyRoadReports handles GET /driver/road-reports
+func (h *RoadReportHandler) ListMyRoadReports(w http.ResponseWriter, r *http.Request) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	reports, err := h.reportService.List(r.Context(), driverID)
+	if err != nil {
+		writeRoadReportError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"reports": reports,
+	})
+}
+
+// ConfirmRoadReport handles POST /driver/road-reports/{reportId}/confirm
+func (h *RoadReportHandler) ConfirmRoadReport(w http.ResponseWriter, r *http.Request) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	reportID, err := uuid.Parse(chi.URLParam(r, "reportId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid report ID")
+		return
+	}
+
+	var req ConfirmRoadReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	report, err := h.reportService.Confirm(r.Context(), driverID, reportID, req.StillThere)
+	if err != nil {
+		writeRoadReportError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+func writeRoadReportError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidRoadReport):
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRoadReport, err.Error())
+	case errors.Is(err, domain.ErrInvalidLocation):
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidLocation, "Invalid report location")
+	case errors.Is(err, domain.ErrRoadReportNotFound):
+		writeError(w, http.StatusNotFound, domain.ErrCodeRoadReportNotFound, "Road report not found")
+	case errors.Is(err, domain.ErrRoadReportClosed):
+		writeError(w, http.StatusConflict, domain.ErrCodeRoadReportClosed, err.Error())
+	default:
+		log.Error().Err(err).Msg("Road report request failed")
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to process road report")
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+)
+
+// RouteOptionService defines the route option quoting interface
+type RouteOptionService interface {
+	Quote(ctx context.Context, riderID uuid.UUID, pickup, dropoff domain.Location, rideType domain.RideType, avoid domain.RouteAvoidance) (*domain.RouteOptionsQuote, error)
+}
+
+// RouteOptionHandler serves the route choices shown at ride confirmation
+type RouteOptionHandler struct {
+	routeService RouteOptionService
+}
+
+// NewRouteOptionHandler creates a new route option handler
+func NewRouteOptionHandler(routeService RouteOptionService) *RouteOptionHandler {
+	return &RouteOptionHandler{routeService: routeService}
+}
+
+// RouteOptionsRequest is the trip to quote routes for
+type RouteOptionsRequest struct {
+	PickupLocation  LocationInput `json:"pickup_location"`
+	DropoffLocation LocationInput `json:"dropoff_location"`
+	Type            string        `json:"type"`
+	AvoidTolls      bool          `json:"avoid_tolls,omitempty"`
+	AvoidHighways   bool          `json:"avoid_highways,omitempty"`
+}
+
+// GetRouteOptions handles POST /rides/route-options
+// Returns up to three routes with their fares. The rider confirms one by
+// sending its quote_id and preference with the ride request, along with
+// the same avoid_tolls and avoid_highways the routes were quoted for.
+func (h *RouteOptionHandler) GetRouteOptions(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req RouteOptionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	if !geo.IsValidCoordinate(req.PickupLocation.Latitude, req.PickupLocation.Longitude) ||
+		!geo.IsValidCoordinate(req.DropoffLocation.Latitude, req.DropoffLocation.Longitude) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidLocation, "Invalid pickup or dropoff location")
+		return
+	}
+	if req.Type == "" {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "type is required")
+		return
+	}
+
+	pickup := domain.Location{
+		Latitude:  req.PickupLocation.Latitude,
+		Longitude: req.PickupLocation.Longitude,
+		Address:   req.PickupLocation.Address,
+	}
+	dropoff := domain.Location{
+		Latitude:  req.DropoffLocation.Latitude,
+		Longitude: req.DropoffLocation.Longitude,
+		Address:   req.DropoffLocation.Address,
+	}
+
+	quote, err := h.routeService.Quote(r.Context(), userID, pickup, dropoff, domain.RideType(req.Type),
+		domain.RouteAvoidance{Tolls: req.AvoidTolls, Highways: req.AvoidHighways})
+	if errors.Is(err, domain.ErrRouteNotFound) {
+		writeError(w, http.StatusNotFound, domain.ErrCodeRouteNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, domain.ErrRideTypeUnavailable) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeRideTypeUnavailable, err.Error())
+		return
+	}
+	if errors.Is(err, domain.ErrPricingFailed) {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodePricingFailed, "Failed to calculate price")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to quote route options")
+		writeError(w, http.StatusBadGateway, domain.ErrCodeRouteNotFound, "Failed to get route options")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, quote)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/eta"
+)
+
+// RoutingHealthHandler reports routing provider failover counts and the
+// health of regional OSRM datasets
+type RoutingHealthHandler struct {
+	router *eta.FallbackRoutingClient
+}
+
+// NewRoutingHealthHandler creates a new routing health handler
+func NewRoutingHealthHandler(router *eta.FallbackRoutingClient) *RoutingHealthHandler {
+	return &RoutingHealthHandler{router: router}
+}
+
+// RoutingHealthResponse is the response for the routing health report
+type RoutingHealthResponse struct {
+	Providers   []eta.ProviderStats    `json:"providers"`
+	OSRMRegions []eta.OSRMRegionHealth `json:"osrm_regions"`
+}
+
+// GetHealth handles GET /internal/admin/routing/health
+func (h *RoutingHealthHandler) GetHealth(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	response := RoutingHealthResponse{
+		Providers:   h.router.Stats(),
+		OSRMRegions: []eta.OSRMRegionHealth{},
+	}
+	if osrm := h.router.OSRM(); osrm != nil {
+		response.OSRMRegions = osrm.RegionHealth()
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/service"
+)
+
+const defaultSafetyQueueLimit = 50
+
+// SafetyService defines the SOS and safety incident interface
+type SafetyService interface {
+	RaiseSOS(ctx context.Context, req *service.SOSRequest) (*domain.SafetyIncident, error)
+	GetIncident(ctx context.Context, id uuid.UUID) (*domain.SafetyIncident, error)
+	ListIncidents(ctx context.Context, status domain.SafetyIncidentStatus, limit, offset int) ([]*domain.SafetyIncident, int64, error)
+	Acknowledge(ctx context.Context, id, agentID uuid.UUID) (*domain.SafetyIncident, error)
+	Resolve(ctx context.Context, id, agentID uuid.UUID, resolution string) (*domain.SafetyIncident, error)
+}
+
+// SafetyHandler takes SOS presses from riders and drivers and lets ops
+// work the resulting incidents
+type SafetyHandler struct {
+	safetyService SafetyService
+}
+
+// NewSafetyHandler creates a new safety handler
+func NewSafetyHandler(safetyService SafetyService) *SafetyHandler {
+	return &SafetyHandler{safetyService: safetyService}
+}
+
+// SOSRequest is the body of an SOS; every field is optional so a panicked
+// press with no body still gets through
+type SOSRequest struct {
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+	Note      string  `json:"note,omitempty"`
+}
+
+// ResolveSafetyIncidentRequest is an agent's resolution of an incident
+type ResolveSafetyIncidentRequest struct {
+	Resolution string `json:"resolution"`
+}
+
+// RaiseSOS handles POST /rides/{rideId}/sos
+func (h *SafetyHandler) RaiseSOS(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	var req SOSRequest
+	if r.ContentLength != 0 {
+		// A malformed body must not stop the alert; fall back to the
+		// driver's position
+		_ = json.NewDecoder(r.Body).Decode(&req)
+	}
+
+	incident, err := h.safetyService.RaiseSOS(r.Context(), &service.SOSRequest{
+		RideID:    rideID,
+		UserID:    userID,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Note:      req.Note,
+	})
+	switch err {
+	case nil:
+		writeJSON(w, http.StatusAccepted, incident)
+	case domain.ErrRideNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, errMsgRideNotFound)
+	case domain.ErrSOSNotAllowed:
+		writeError(w, http.StatusForbidden, domain.ErrCodeSOSNotAllowed, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to raise SOS")
+	}
+}
+
+// ListIncidents handles GET /internal/support/safety-incidents
+func (h *SafetyHandler) ListIncidents(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	status := domain.SafetyIncidentOpen
+	if s := r.URL.Query().Get("status"); s != "" {
+		status = domain.SafetyIncidentStatus(s)
+		if !status.IsValid() {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "status must be OPEN, ACKNOWLEDGED or RESOLVED")
+			return
+		}
+	}
+
+	limit := defaultSafetyQueueLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	offset := 0
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o > 0 {
+		offset = o
+	}
+
+	incidents, total, err := h.safetyService.ListIncidents(r.Context(), status, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list safety incidents")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"incidents": incidents,
+		"total":     total,
+		"limit":     limit,
+		"offset":    offset,
+	})
+}
+
+// GetIncident handles GET /internal/support/safety-incidents/{incidentId}
+func (h *SafetyHandler) GetIncident(w http.ResponseWriter, r *http.Request) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	incidentID, err := uuid.Parse(chi.URLParam(r, "incidentId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid incident ID")
+		return
+	}
+
+	incident, err := h.safetyService.GetIncident(r.Context(), incidentID)
+	if err != nil {
+		writeSafetyError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, incident)
+}
+
+// AcknowledgeIncident handles POST /internal/support/safety-incidents/{incidentId}/acknowledge
+func (h *SafetyHandler) AcknowledgeIncident(w http.ResponseWriter, r *http.Request) {
+	h.updateIncident(w, r, func(ctx context.Context, id, agentID uuid.UUID) (*domain.SafetyIncident, error) {
+		return h.safetyService.Acknowledge(ctx, id, agentID)
+	})
+}
+
+// ResolveIncident handles POST /internal/support/safety-incidents/{incidentId}/resolve
+func (h *SafetyHandler) ResolveIncident(w http.ResponseWriter, r *http.Request) {
+	var req ResolveSafetyIncidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	h.updateIncident(w, r, func(ctx context.Context, id, agentID uuid.UUID) (*domain.SafetyIncident, error) {
+		return h.safetyService.Resolve(ctx, id, agentID, req.Resolution)
+	})
+}
+
+func (h *SafetyHandler) updateIncident(
+	w http.ResponseWriter,
+	r *http.Request,
+	update func(ctx context.Context, id, agentID uuid.UUID) (*domain.SafetyIncident, error),
+) {
+	if !domain.IsSupportRole(getUserRoleFromContext(r.Context())) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+	agentID := getUserIDFromContext(r.Context())
+	if agentID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	incidentID, err := uuid.Parse(chi.URLParam(r, "incidentId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid incident ID")
+		return
+	}
+
+	incident, err := update(r.Context(), incidentID, agentID)
+	if err != nil {
+		writeSafetyError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, incident)
+}
+
+func writeSafetyError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrSafetyIncidentNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeSafetyIncidentNotFound, err.Error())
+	case domain.ErrInvalidSafetyTransition:
+		writeError(w, http.StatusConflict, domain.ErrCodeInvalidSafetyTransition, err.Error())
+	case domain.ErrInvalidRequest:
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "A resolution is required")
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to update safety incident")
+	}
+}
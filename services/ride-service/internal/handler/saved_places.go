@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// SavedPlaceService defines the saved place service interface
+type SavedPlaceService interface {
+	ListPlaces(ctx context.Context, riderID uuid.UUID) ([]*domain.SavedPlace, error)
+	CreatePlace(ctx context.Context, riderID uuid.UUID, kind domain.SavedPlaceKind, label string, location domain.Location) (*domain.SavedPlace, error)
+	UpdatePlace(ctx context.Context, riderID, placeID uuid.UUID, kind domain.SavedPlaceKind, label string, location domain.Location) (*domain.SavedPlace, error)
+	DeletePlace(ctx context.Context, riderID, placeID uuid.UUID) error
+}
+
+// SavedPlaceHandler handles riders' saved places
+type SavedPlaceHandler struct {
+	placeService SavedPlaceService
+}
+
+// NewSavedPlaceHandler creates a new saved place handler
+func NewSavedPlaceHandler(placeService SavedPlaceService) *SavedPlaceHandler {
+	return &SavedPlaceHandler{placeService: placeService}
+}
+
+// SavedPlaceRequest is the body of a saved place to create or replace
+type SavedPlaceRequest struct {
+	Kind     string `json:"kind"`            // HOME, WORK or CUSTOM
+	Label    string `json:"label,omitempty"` // required for CUSTOM
+	Location struct {
+		Latitude   float64 `json:"latitude"`
+		Longitude  float64 `json:"longitude"`
+		Address    string  `json:"address,omitempty"`
+		Name       string  `json:"name,omitempty"`
+		PlaceID    string  `json:"place_id,omitempty"`
+		Directions string  `json:"directions,omitempty"`
+	} `json:"location"`
+}
+
+func (req *SavedPlaceRequest) location() domain.Location {
+	return domain.Location{
+		Latitude:   req.Location.Latitude,
+		Longitude:  req.Location.Longitude,
+		Address:    req.Location.Address,
+		Name:       req.Location.Name,
+		PlaceID:    req.Location.PlaceID,
+		Directions: req.Location.Directions,
+	}
+}
+
+// ListPlaces handles GET /riders/places
+func (h *SavedPlaceHandler) ListPlaces(w http.ResponseWriter, r *http.Request) {
+	riderID := getUserIDFromContext(r.Context())
+	if riderID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	places, err := h.placeService.ListPlaces(r.Context(), riderID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list saved places")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"places": places,
+	})
+}
+
+// CreatePlace handles POST /riders/places
+func (h *SavedPlaceHandler) CreatePlace(w http.ResponseWriter, r *http.Request) {
+	riderID := getUserIDFromContext(r.Context())
+	if riderID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req SavedPlaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	place, err := h.placeService.CreatePlace(r.Context(), riderID, domain.SavedPlaceKind(req.Kind), req.Label, req.location())
+	if err != nil {
+		writeSavedPlaceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, place)
+}
+
+// UpdatePlace handles PUT /riders/places/{placeId}
+func (h *SavedPlaceHandler) UpdatePlace(w http.ResponseWriter, r *http.Request) {
+	riderID := getUserIDFromContext(r.Context())
+	if riderID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	placeID, err := uuid.Parse(chi.URLParam(r, "placeId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid place ID")
+		return
+	}
+
+	var req SavedPlaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	place, err := h.placeService.UpdatePlace(r.Context(), riderID, placeID, domain.SavedPlaceKind(req.Kind), req.Label, req.location())
+	if err != nil {
+		writeSavedPlaceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, place)
+}
+
+// DeletePlace handles DELETE /riders/places/{placeId}
+func (h *SavedPlaceHandler) DeletePlace(w http.ResponseWriter, r *http.Request) {
+	riderID := getUserIDFromContext(r.Context())
+	if riderID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	placeID, err := uuid.Parse(chi.URLParam(r, "placeId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid place ID")
+		return
+	}
+
+	if err := h.placeService.DeletePlace(r.Context(), riderID, placeID); err != nil {
+		writeSavedPlaceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeSavedPlaceError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrSavedPlaceNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeSavedPlaceNotFound, "Saved place not found")
+	case domain.ErrInvalidSavedPlace:
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidSavedPlace, err.Error())
+	case domain.ErrSavedPlaceExists:
+		writeError(w, http.StatusConflict, domain.ErrCodeSavedPlaceExists, err.Error())
+	case domain.ErrSavedPlaceLimit:
+		writeError(w, http.StatusConflict, domain.ErrCodeSavedPlaceLimit, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to save place")
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// ScheduledRideService defines the driver scheduled ride marketplace interface
+type ScheduledRideService interface {
+	GetDriverScheduledRides(ctx context.Context, driverID uuid.UUID) (*domain.DriverScheduledRides, error)
+	ClaimScheduledRide(ctx context.Context, rideID, driverID uuid.UUID) (*domain.ScheduledRideClaim, error)
+	ReleaseScheduledClaim(ctx context.Context, rideID, driverID uuid.UUID) (*domain.ScheduledRideClaim, error)
+}
+
+// ScheduledRideHandler lets drivers browse and pre-claim scheduled rides
+type ScheduledRideHandler struct {
+	scheduledService ScheduledRideService
+}
+
+// NewScheduledRideHandler creates a new scheduled ride handler
+func NewScheduledRideHandler(scheduledService ScheduledRideService) *ScheduledRideHandler {
+	return &ScheduledRideHandler{scheduledService: scheduledService}
+}
+
+// ListScheduledRides handles GET /driver/scheduled-rides
+func (h *ScheduledRideHandler) ListScheduledRides(w http.ResponseWriter, r *http.Request) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rides, err := h.scheduledService.GetDriverScheduledRides(r.Context(), driverID)
+	if err != nil {
+		writeScheduledClaimError(w, err)
+		return
+	}
+	for i, listing := range rides.Available {
+		rides.Available[i] = listing.CrossPartyView()
+	}
+
+	writeJSON(w, http.StatusOK, rides)
+}
+
+// ClaimScheduledRide handles POST /driver/scheduled-rides/{rideId}/claim
+func (h *ScheduledRideHandler) ClaimScheduledRide(w http.ResponseWriter, r *http.Request) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	claim, err := h.scheduledService.ClaimScheduledRide(r.Context(), rideID, driverID)
+	if err != nil {
+		writeScheduledClaimError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, claim)
+}
+
+// ReleaseScheduledRide handles POST /driver/scheduled-rides/{rideId}/release
+func (h *ScheduledRideHandler) ReleaseScheduledRide(w http.ResponseWriter, r *http.Request) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	claim, err := h.scheduledService.ReleaseScheduledClaim(r.Context(), rideID, driverID)
+	if err != nil {
+		writeScheduledClaimError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, claim)
+}
+
+func writeScheduledClaimError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrRideNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, errMsgRideNotFound)
+	case domain.ErrDriverNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeDriverNotFound, "Driver not found")
+	case domain.ErrScheduledClaimNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeScheduledClaimNotFound, err.Error())
+	case domain.ErrScheduledRideNotClaimable:
+		writeError(w, http.StatusUnprocessableEntity, domain.ErrCodeScheduledRideNotClaimable, err.Error())
+	case domain.ErrScheduledRideClaimed:
+		writeError(w, http.StatusConflict, domain.ErrCodeScheduledRideClaimed, err.Error())
+	case domain.ErrScheduledClaimLimit:
+		writeError(w, http.StatusConflict, domain.ErrCodeScheduledClaimLimit, err.Error())
+	case domain.ErrClaimReliabilityLow:
+		writeError(w, http.StatusForbidden, domain.ErrCodeClaimReliabilityLow, err.Error())
+	case domain.ErrDriverRestricted:
+		writeError(w, http.StatusForbidden, domain.ErrCodeDriverRestricted, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to process request")
+	}
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// BurnRateReporter defines the request guard interface for SLO burn rates
+type BurnRateReporter interface {
+	BurnRates(now time.Time) []domain.SLOBurnRate
+}
+
+// SLOHandler serves per-endpoint-group SLO burn rates
+type SLOHandler struct {
+	guard BurnRateReporter
+}
+
+// NewSLOHandler creates a new SLO handler
+func NewSLOHandler(guard BurnRateReporter) *SLOHandler {
+	return &SLOHandler{guard: guard}
+}
+
+// GetBurnRates handles GET /internal/admin/slo. Each replica reports the
+// requests it served.
+func (h *SLOHandler) GetBurnRates(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.guard.BurnRates(time.Now()))
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// SupportService defines the support case service interface
+type SupportService interface {
+	GetRideCaseContext(ctx context.Context, rideID uuid.UUID, role string) (*domain.SupportCaseContext, error)
+}
+
+// SupportHandler handles internal support tooling requests
+type SupportHandler struct {
+	supportService SupportService
+}
+
+// NewSupportHandler creates a new support handler
+func NewSupportHandler(supportService SupportService) *SupportHandler {
+	return &SupportHandler{supportService: supportService}
+}
+
+// GetRideCaseContext handles GET /internal/support/rides/{rideId}/context
+func (h *SupportHandler) GetRideCaseContext(w http.ResponseWriter, r *http.Request) {
+	role := getUserRoleFromContext(r.Context())
+	if !domain.IsSupportRole(role) {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Support access required")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	caseCtx, err := h.supportService.GetRideCaseContext(r.Context(), rideID, role)
+	if err != nil {
+		if err == domain.ErrRideNotFound {
+			writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, errMsgRideNotFound)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to build case context")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, caseCtx)
+}
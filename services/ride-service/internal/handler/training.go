@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// TrainingService defines the driver training service interface
+type TrainingService interface {
+	HandleWebhook(ctx context.Context, provider string, payload *domain.TrainingWebhook) (*domain.TrainingCompletion, error)
+	GetStatus(ctx context.Context, driverID uuid.UUID) ([]*domain.TrainingStatus, error)
+	GetProductStatus(ctx context.Context, driverID uuid.UUID, product domain.TrainingProduct) (*domain.TrainingStatus, error)
+	ListCompletions(ctx context.Context, driverID uuid.UUID) ([]*domain.TrainingCompletion, error)
+}
+
+// TrainingHandler receives learning provider webhooks and serves drivers'
+// training progress
+type TrainingHandler struct {
+	training TrainingService
+	verifier WebhookVerifier
+}
+
+// NewTrainingHandler creates a new training handler. Each learning provider
+// is a webhook source of its own.
+func NewTrainingHandler(training TrainingService, verifier WebhookVerifier) *TrainingHandler {
+	return &TrainingHandler{
+		training: training,
+		verifier: verifier,
+	}
+}
+
+// ReceiveWebhook handles POST /webhooks/training/{provider}
+func (h *TrainingHandler) ReceiveWebhook(w http.ResponseWriter, r *http.Request) {
+	provider := strings.ToLower(chi.URLParam(r, "provider"))
+	if provider == "" {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Missing provider")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	if err := h.verifier.Verify(r.Context(), provider, r.Header, body); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	var payload domain.TrainingWebhook
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRequestBody)
+		return
+	}
+
+	completion, err := h.training.HandleWebhook(r.Context(), provider, &payload)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidTrainingCompletion) {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidTrainingCompletion, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to process webhook")
+		return
+	}
+
+	// Failed attempts and redeliveries are acknowledged without a completion
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"recorded":   completion != nil,
+		"completion": completion,
+	})
+}
+
+// GetMyTraining handles GET /drivers/me/training
+func (h *TrainingHandler) GetMyTraining(w http.ResponseWriter, r *http.Request) {
+	driverID := getUserIDFromContext(r.Context())
+	if driverID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	statuses, err := h.training.GetStatus(r.Context(), driverID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get training status")
+		return
+	}
+	completions, err := h.training.ListCompletions(r.Context(), driverID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to list training completions")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"products":    statuses,
+		"completions": completions,
+	})
+}
+
+// GetDriverTraining handles GET /internal/drivers/{driverId}/training/{product}
+// for services that gate their own offers, such as regulated deliveries
+func (h *TrainingHandler) GetDriverTraining(w http.ResponseWriter, r *http.Request) {
+	driverID, err := uuid.Parse(chi.URLParam(r, "driverId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid driver ID")
+		return
+	}
+	product := domain.TrainingProduct(strings.ToUpper(chi.URLParam(r, "product")))
+
+	status, err := h.training.GetProductStatus(r.Context(), driverID, product)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRequest) {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Unknown training product")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get training status")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
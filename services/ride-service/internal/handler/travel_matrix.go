@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/eta"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+)
+
+// TravelMatrixHandler serves precomputed H3 cell-to-cell travel times
+type TravelMatrixHandler struct {
+	matrix *eta.TravelMatrix
+}
+
+// NewTravelMatrixHandler creates a new travel matrix handler
+func NewTravelMatrixHandler(matrix *eta.TravelMatrix) *TravelMatrixHandler {
+	return &TravelMatrixHandler{matrix: matrix}
+}
+
+// TravelTimeResponse is the response for a travel time lookup
+type TravelTimeResponse struct {
+	OriginCell      string `json:"origin_cell"`
+	DestinationCell string `json:"destination_cell"`
+	DurationSeconds int64  `json:"duration_seconds"`
+	Source          string `json:"source"` // "matrix" or "estimate"
+}
+
+// GetTravelTime handles GET /eta/matrix?origin_lat=...&origin_lng=...&dest_lat=...&dest_lng=...
+// Falls back to a straight-line estimate when the matrix has no entry.
+func (h *TravelMatrixHandler) GetTravelTime(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	coords := make([]float64, 0, 4)
+	for _, param := range []string{"origin_lat", "origin_lng", "dest_lat", "dest_lng"} {
+		value, err := strconv.ParseFloat(query.Get(param), 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "Invalid "+param)
+			return
+		}
+		coords = append(coords, value)
+	}
+
+	if !geo.IsValidCoordinate(coords[0], coords[1]) || !geo.IsValidCoordinate(coords[2], coords[3]) {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidLocation, "Invalid location")
+		return
+	}
+
+	response := TravelTimeResponse{
+		OriginCell:      eta.TravelMatrixCell(coords[0], coords[1]),
+		DestinationCell: eta.TravelMatrixCell(coords[2], coords[3]),
+		Source:          "matrix",
+	}
+
+	seconds, ok := h.matrix.Lookup(r.Context(), coords[0], coords[1], coords[2], coords[3])
+	if !ok {
+		distance := geo.HaversineDistance(coords[0], coords[1], coords[2], coords[3])
+		seconds = geo.EstimateETA(distance, "car")
+		response.Source = "estimate"
+	}
+	response.DurationSeconds = seconds
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// GetCityStats handles GET /eta/matrix/cities/{city}
+func (h *TravelMatrixHandler) GetCityStats(w http.ResponseWriter, r *http.Request) {
+	city := chi.URLParam(r, "city")
+
+	stats, err := h.matrix.GetStats(r.Context(), city)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to get travel matrix stats")
+		return
+	}
+	if stats == nil {
+		writeError(w, http.StatusNotFound, domain.ErrCodeNotFound, "No travel matrix built for city")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
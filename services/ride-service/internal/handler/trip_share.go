@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// TripShareService defines the trip sharing interface
+type TripShareService interface {
+	Share(ctx context.Context, rideID, riderID uuid.UUID) (*domain.TripShare, error)
+	Track(ctx context.Context, token string) (*domain.SharedTrip, error)
+}
+
+// TripShareHandler lets riders share a live view of their trip
+type TripShareHandler struct {
+	shareService TripShareService
+}
+
+// NewTripShareHandler creates a new trip share handler
+func NewTripShareHandler(shareService TripShareService) *TripShareHandler {
+	return &TripShareHandler{shareService: shareService}
+}
+
+// ShareRide handles POST /rides/{rideId}/share
+func (h *TripShareHandler) ShareRide(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, domain.ErrCodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	rideID, err := uuid.Parse(chi.URLParam(r, "rideId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, errMsgInvalidRideID)
+		return
+	}
+
+	share, err := h.shareService.Share(r.Context(), rideID, userID)
+	switch err {
+	case nil:
+		writeJSON(w, http.StatusCreated, share)
+	case domain.ErrRideNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeRideNotFound, errMsgRideNotFound)
+	case domain.ErrForbidden:
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Only the rider can share this trip")
+	case domain.ErrRideAlreadyEnded:
+		writeError(w, http.StatusConflict, domain.ErrCodeRideAlreadyEnded, "Only active rides can be shared")
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to share ride")
+	}
+}
+
+// TrackSharedTrip handles GET /track/{token}. It needs no account: the
+// signed token is the permission.
+func (h *TripShareHandler) TrackSharedTrip(w http.ResponseWriter, r *http.Request) {
+	trip, err := h.shareService.Track(r.Context(), chi.URLParam(r, "token"))
+	switch err {
+	case nil:
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, trip)
+	case domain.ErrInvalidShareToken, domain.ErrRideNotFound:
+		writeError(w, http.StatusNotFound, domain.ErrCodeInvalidShareToken, "Trip not found")
+	case domain.ErrShareLinkExpired:
+		writeError(w, http.StatusGone, domain.ErrCodeShareLinkExpired, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to load trip")
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// WebhookFailureReporter defines the webhook verifier's failure counts
+type WebhookFailureReporter interface {
+	Failures() []domain.WebhookFailureCount
+}
+
+// WebhookHandler serves webhook verification failure counts
+type WebhookHandler struct {
+	verifier WebhookFailureReporter
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(verifier WebhookFailureReporter) *WebhookHandler {
+	return &WebhookHandler{verifier: verifier}
+}
+
+// GetFailures handles GET /internal/admin/webhooks/failures. Each replica
+// reports the deliveries it refused.
+func (h *WebhookHandler) GetFailures(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"failures": h.verifier.Failures()})
+}
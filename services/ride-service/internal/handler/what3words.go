@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+)
+
+// What3WordsResolver resolves three word addresses, such as
+// "filled.count.soap", in the countries they are switched on for
+type What3WordsResolver interface {
+	Resolve(ctx context.Context, words string) (*geo.What3WordsAddress, error)
+}
+
+// SetWhat3Words lets ride requests give locations as three word addresses.
+// Without it, w3w locations are rejected.
+func (h *RideHandler) SetWhat3Words(resolver What3WordsResolver) {
+	h.what3words = resolver
+}
+
+// SetWhat3Words lets the geocode endpoint resolve three word addresses.
+// Without it, they are geocoded like any other text.
+func (h *LocationHandler) SetWhat3Words(resolver What3WordsResolver) {
+	h.what3words = resolver
+}
+
+// resolveWhat3Words fills in coordinates for ride request locations given
+// as three word addresses
+func (h *RideHandler) resolveWhat3Words(ctx context.Context, req *RequestRideRequest) error {
+	locations := []*LocationInput{&req.PickupLocation, &req.DropoffLocation}
+	for i := range req.Stops {
+		locations = append(locations, &req.Stops[i])
+	}
+
+	for _, loc := range locations {
+		if loc.W3W == "" || loc.Latitude != 0 || loc.Longitude != 0 {
+			continue
+		}
+		if h.what3words == nil {
+			return geo.ErrWhat3WordsUnavailable
+		}
+
+		addr, err := h.what3words.Resolve(ctx, loc.W3W)
+		if err != nil {
+			return err
+		}
+		loc.W3W = addr.Words
+		loc.Latitude, loc.Longitude = addr.Coordinates.Lat, addr.Coordinates.Lng
+		if loc.Address == "" {
+			loc.Address = addr.NearestPlace
+		}
+	}
+	return nil
+}
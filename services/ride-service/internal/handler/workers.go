@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/worker"
+)
+
+// WorkerScheduler defines the background job scheduler interface
+type WorkerScheduler interface {
+	Status() *worker.Status
+}
+
+// WorkerHandler serves background job leadership and run stats
+type WorkerHandler struct {
+	workers WorkerScheduler
+}
+
+// NewWorkerHandler creates a new worker handler
+func NewWorkerHandler(workers WorkerScheduler) *WorkerHandler {
+	return &WorkerHandler{workers: workers}
+}
+
+// GetStatus handles GET /internal/admin/workers. Each replica reports its
+// own view, so follower stats show skipped rather than completed runs.
+func (h *WorkerHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.workers.Status())
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// ZoneKPIService defines the zone operational KPI service interface
+type ZoneKPIService interface {
+	GetReport(ctx context.Context, window, cityCode string) (*domain.ZoneKPIReport, error)
+	GetZoneSeries(ctx context.Context, zone, window string) (*domain.ZoneKPISeries, error)
+}
+
+// ZoneKPIHandler serves per-zone operational KPIs to the ops dashboard
+type ZoneKPIHandler struct {
+	kpiService ZoneKPIService
+}
+
+// NewZoneKPIHandler creates a new zone KPI handler
+func NewZoneKPIHandler(kpiService ZoneKPIService) *ZoneKPIHandler {
+	return &ZoneKPIHandler{kpiService: kpiService}
+}
+
+// GetReport handles GET /internal/admin/zones/kpis?window=&city=
+func (h *ZoneKPIHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	window, ok := parseZoneKPIWindow(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.kpiService.GetReport(r.Context(), window, r.URL.Query().Get("city"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to build zone KPI report")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// GetZoneSeries handles GET /internal/admin/zones/{zone}/kpis?window=
+func (h *ZoneKPIHandler) GetZoneSeries(w http.ResponseWriter, r *http.Request) {
+	if getUserRoleFromContext(r.Context()) != domain.RoleAdmin {
+		writeError(w, http.StatusForbidden, domain.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	window, ok := parseZoneKPIWindow(w, r)
+	if !ok {
+		return
+	}
+
+	series, err := h.kpiService.GetZoneSeries(r.Context(), chi.URLParam(r, "zone"), window)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, domain.ErrCodeInternal, "Failed to build zone KPI series")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, series)
+}
+
+func parseZoneKPIWindow(w http.ResponseWriter, r *http.Request) (string, bool) {
+	window := r.URL.Query().Get("window")
+	if window == "" {
+		window = domain.DefaultZoneKPIWindow
+	}
+	if _, ok := domain.ZoneKPIWindows[window]; !ok {
+		writeError(w, http.StatusBadRequest, domain.ErrCodeInvalidRequest, "window must be one of 1h, 6h, 24h or 7d")
+		return "", false
+	}
+	return window, true
+}
@@ -0,0 +1,254 @@
+// Package ingest consumes event streams into the ride service's stores
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/logging"
+)
+
+// locationLog is sampled: it logs on every batch of driver pings
+var locationLog = logging.For("location")
+
+// LocationTopic is the topic the location service publishes driver pings to
+const LocationTopic = "driver-locations"
+
+// retryBackoff is how long the consumer waits after a failed fetch or
+// write before trying again
+const retryBackoff = time.Second
+
+// PointWriter bulk writes location points
+type PointWriter interface {
+	CopyPoints(ctx context.Context, points []*domain.LocationPoint) (int64, error)
+}
+
+// ConsentChecker reports which users consented to a use of their data
+type ConsentChecker interface {
+	AllowedUsers(ctx context.Context, purpose domain.ConsentPurpose, userIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+}
+
+// messageSource is the part of a Kafka reader the consumer uses
+type messageSource interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// LocationConsumerConfig configures the location history consumer. Zero
+// batch size and flush interval take the domain defaults.
+type LocationConsumerConfig struct {
+	Brokers       []string
+	GroupID       string
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
+// LocationConsumer writes the driver pings published by the location
+// service to the location history in batches. A batch is written once it
+// is full or has waited a flush interval, and offsets are committed only
+// after it is written, so a crash replays pings rather than losing them.
+type LocationConsumer struct {
+	source        messageSource
+	reader        *kafka.Reader
+	writer        PointWriter
+	consents      ConsentChecker
+	batchSize     int
+	flushInterval time.Duration
+}
+
+// NewLocationConsumer creates a consumer reading the location topic
+func NewLocationConsumer(cfg LocationConsumerConfig, writer PointWriter) *LocationConsumer {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  cfg.Brokers,
+		GroupID:  cfg.GroupID,
+		Topic:    LocationTopic,
+		MinBytes: 1 << 10,
+		MaxBytes: 10 << 20,
+		MaxWait:  cfg.FlushInterval,
+	})
+	c := newLocationConsumer(reader, writer, cfg.BatchSize, cfg.FlushInterval)
+	c.reader = reader
+	return c
+}
+
+func newLocationConsumer(source messageSource, writer PointWriter, batchSize int, flushInterval time.Duration) *LocationConsumer {
+	if batchSize <= 0 {
+		batchSize = domain.DefaultLocationIngestBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = domain.DefaultLocationIngestFlushInterval
+	}
+	return &LocationConsumer{
+		source:        source,
+		writer:        writer,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+	}
+}
+
+// SetConsents keeps the pings of drivers who withdrew precise location
+// consent out of the location history
+func (c *LocationConsumer) SetConsents(consents ConsentChecker) {
+	c.consents = consents
+}
+
+// Start consumes pings until ctx is cancelled, writing what it has
+// batched before returning
+func (c *LocationConsumer) Start(ctx context.Context) {
+	points := make([]*domain.LocationPoint, 0, c.batchSize)
+	var last *kafka.Message
+	flushAt := time.Now().Add(c.flushInterval)
+
+	for {
+		fetchCtx, cancel := context.WithDeadline(ctx, flushAt)
+		msg, err := c.source.FetchMessage(fetchCtx)
+		cancel()
+
+		switch {
+		case err == nil:
+			if point, err := decodeLocationPoint(msg.Value); err == nil {
+				points = append(points, point)
+			} else {
+				locationLog.Warn().Err(err).Int64("offset", msg.Offset).Msg("Skipping malformed location ping")
+			}
+			last = &msg
+			if len(points) < c.batchSize {
+				continue
+			}
+		case ctx.Err() != nil:
+			// Shutting down; write what we have on a fresh context
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			c.flush(flushCtx, points, last)
+			cancel()
+			return
+		case !errors.Is(err, context.DeadlineExceeded):
+			locationLog.Error().Err(err).Msg("Failed to fetch location ping")
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryBackoff):
+			}
+			continue
+		}
+
+		if !c.flush(ctx, points, last) {
+			return
+		}
+		points = points[:0]
+		last = nil
+		flushAt = time.Now().Add(c.flushInterval)
+	}
+}
+
+// Close closes the Kafka reader
+func (c *LocationConsumer) Close() error {
+	if c.reader == nil {
+		return nil
+	}
+	return c.reader.Close()
+}
+
+// flush writes the batch, retrying until it is written or ctx is done,
+// then commits up to the last message read. It returns false when ctx
+// ends first.
+func (c *LocationConsumer) flush(ctx context.Context, points []*domain.LocationPoint, last *kafka.Message) bool {
+	if last == nil {
+		return true
+	}
+
+	for len(points) > 0 {
+		start := time.Now()
+		kept, err := c.consented(ctx, points)
+		var n int64
+		if err == nil {
+			n, err = c.writer.CopyPoints(ctx, kept)
+		}
+		if err == nil {
+			locationLog.Debug().Int64("points", n).Dur("took", time.Since(start)).Msg("Wrote location history batch")
+			break
+		}
+		locationLog.Error().Err(err).Int("points", len(points)).Msg("Failed to write location history batch")
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(retryBackoff):
+		}
+	}
+
+	if err := c.source.CommitMessages(ctx, *last); err != nil {
+		locationLog.Error().Err(err).Int64("offset", last.Offset).Msg("Failed to commit location ping offsets")
+	}
+	return true
+}
+
+// consented drops the points of drivers without precise location consent
+func (c *LocationConsumer) consented(ctx context.Context, points []*domain.LocationPoint) ([]*domain.LocationPoint, error) {
+	if c.consents == nil {
+		return points, nil
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	drivers := make([]uuid.UUID, 0)
+	for _, p := range points {
+		if !seen[p.DriverID] {
+			seen[p.DriverID] = true
+			drivers = append(drivers, p.DriverID)
+		}
+	}
+	allowed, err := c.consents.AllowedUsers(ctx, domain.ConsentPreciseLocation, drivers)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make([]*domain.LocationPoint, 0, len(points))
+	for _, p := range points {
+		if allowed[p.DriverID] {
+			kept = append(kept, p)
+		}
+	}
+	return kept, nil
+}
+
+// locationMessage is a ping as the location service publishes it
+type locationMessage struct {
+	DriverID  string    `json:"driver_id"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Heading   float64   `json:"heading"`
+	Speed     float64   `json:"speed"`
+	Accuracy  float64   `json:"accuracy"`
+	Timestamp time.Time `json:"timestamp"`
+	H3Index   string    `json:"h3_index"`
+}
+
+// decodeLocationPoint turns a published ping into a location point
+func decodeLocationPoint(value []byte) (*domain.LocationPoint, error) {
+	var msg locationMessage
+	if err := json.Unmarshal(value, &msg); err != nil {
+		return nil, err
+	}
+	driverID, err := uuid.Parse(strings.TrimSpace(msg.DriverID))
+	if err != nil {
+		return nil, err
+	}
+	if msg.Timestamp.IsZero() {
+		return nil, errors.New("location ping has no timestamp")
+	}
+
+	return &domain.LocationPoint{
+		DriverID:   driverID,
+		Latitude:   msg.Latitude,
+		Longitude:  msg.Longitude,
+		Heading:    msg.Heading,
+		Speed:      msg.Speed,
+		Accuracy:   msg.Accuracy,
+		H3Cell:     msg.H3Index,
+		RecordedAt: msg.Timestamp.UTC(),
+	}, nil
+}
@@ -0,0 +1,216 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/segmentio/kafka-go"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// fakeSource serves pre-encoded pings, then blocks until ctx ends
+type fakeSource struct {
+	mu        sync.Mutex
+	msgs      []kafka.Message
+	next      int
+	committed int64
+}
+
+func newFakeSource(n int) *fakeSource {
+	drivers := make([]uuid.UUID, 64)
+	for i := range drivers {
+		drivers[i] = uuid.New()
+	}
+	base := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	src := &fakeSource{msgs: make([]kafka.Message, n), committed: -1}
+	for i := range src.msgs {
+		value := fmt.Sprintf(
+			`{"driver_id":%q,"latitude":%f,"longitude":%f,"heading":90,"speed":11.5,"accuracy":8,"timestamp":%q,"h3_index":"88754e6499fffff","vehicle_type":"car","is_available":true}`,
+			drivers[i%len(drivers)], 6.4281+float64(i%1000)*1e-5, 3.4219, base.Add(time.Duration(i)*time.Millisecond).Format(time.RFC3339Nano),
+		)
+		src.msgs[i] = kafka.Message{Offset: int64(i), Value: []byte(value)}
+	}
+	return src
+}
+
+func (s *fakeSource) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	s.mu.Lock()
+	if s.next < len(s.msgs) {
+		msg := s.msgs[s.next]
+		s.next++
+		s.mu.Unlock()
+		return msg, nil
+	}
+	s.mu.Unlock()
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (s *fakeSource) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, msg := range msgs {
+		s.committed = msg.Offset
+	}
+	return nil
+}
+
+// countingWriter counts points and cancels once it has seen want of them
+type countingWriter struct {
+	next    PointWriter
+	want    int64
+	written int64
+	batches int
+	cancel  context.CancelFunc
+}
+
+func (w *countingWriter) CopyPoints(ctx context.Context, points []*domain.LocationPoint) (int64, error) {
+	n := int64(len(points))
+	if w.next != nil {
+		var err error
+		if n, err = w.next.CopyPoints(ctx, points); err != nil {
+			return 0, err
+		}
+	}
+	w.written += n
+	w.batches++
+	if w.written >= w.want {
+		w.cancel()
+	}
+	return n, nil
+}
+
+func TestLocationConsumerBatchesAndCommits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	src := newFakeSource(250)
+	writer := &countingWriter{want: 250, cancel: cancel}
+	newLocationConsumer(src, writer, 100, 50*time.Millisecond).Start(ctx)
+
+	if writer.written != 250 {
+		t.Fatalf("wrote %d points, want 250", writer.written)
+	}
+	// Two full batches, then the rest once the flush interval passes
+	if writer.batches != 3 {
+		t.Errorf("wrote %d batches, want 3", writer.batches)
+	}
+	if src.committed != 249 {
+		t.Errorf("committed offset %d, want 249", src.committed)
+	}
+}
+
+// denyConsents withholds consent from a set of drivers
+type denyConsents map[uuid.UUID]bool
+
+func (d denyConsents) AllowedUsers(ctx context.Context, purpose domain.ConsentPurpose, userIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	allowed := make(map[uuid.UUID]bool, len(userIDs))
+	for _, id := range userIDs {
+		allowed[id] = !d[id]
+	}
+	return allowed, nil
+}
+
+func TestLocationConsumerDropsPointsWithoutConsent(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The fake source cycles through 64 drivers, so the first one sent
+	// pings 0 and 64
+	src := newFakeSource(100)
+	first, err := decodeLocationPoint(src.msgs[0].Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := &countingWriter{want: 98, cancel: cancel}
+	consumer := newLocationConsumer(src, writer, 50, 50*time.Millisecond)
+	consumer.SetConsents(denyConsents{first.DriverID: true})
+	consumer.Start(ctx)
+
+	if writer.written != 98 {
+		t.Fatalf("wrote %d points, want 98", writer.written)
+	}
+	if src.committed != 99 {
+		t.Errorf("committed offset %d, want 99", src.committed)
+	}
+}
+
+func TestDecodeLocationPointRejectsBadPings(t *testing.T) {
+	for _, value := range []string{
+		`not json`,
+		`{"driver_id":"driver-1","latitude":6.4,"longitude":3.4,"timestamp":"2026-05-01T08:00:00Z"}`,
+		`{"driver_id":"` + uuid.NewString() + `","latitude":6.4,"longitude":3.4}`,
+	} {
+		if _, err := decodeLocationPoint([]byte(value)); err == nil {
+			t.Errorf("decodeLocationPoint(%s) should fail", value)
+		}
+	}
+}
+
+// runConsumerBenchmark feeds b.N pings through the consumer into writer
+// and reports sustained points per second
+func runConsumerBenchmark(b *testing.B, writer PointWriter, batchSize int) {
+	src := newFakeSource(b.N)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	counter := &countingWriter{next: writer, want: int64(b.N), cancel: cancel}
+	consumer := newLocationConsumer(src, counter, batchSize, 10*time.Millisecond)
+
+	b.ResetTimer()
+	start := time.Now()
+	consumer.Start(ctx)
+	elapsed := time.Since(start)
+	b.StopTimer()
+
+	if counter.written != int64(b.N) {
+		b.Fatalf("wrote %d points, want %d", counter.written, b.N)
+	}
+	b.ReportMetric(float64(b.N)/elapsed.Seconds(), "points/s")
+}
+
+// BenchmarkLocationConsumer measures decoding and batching alone
+func BenchmarkLocationConsumer(b *testing.B) {
+	runConsumerBenchmark(b, nil, domain.DefaultLocationIngestBatchSize)
+}
+
+// BenchmarkLocationConsumerCopy measures ingestion into Postgres with COPY.
+// It runs only when LOCATION_INGEST_BENCH_DATABASE_URL points at a
+// scratch database, e.g.
+//
+//	LOCATION_INGEST_BENCH_DATABASE_URL=postgres://localhost/ride_bench \
+//	  go test ./internal/ingest -run '^$' -bench Copy -benchtime 200000x
+func BenchmarkLocationConsumerCopy(b *testing.B) {
+	dsn := os.Getenv("LOCATION_INGEST_BENCH_DATABASE_URL")
+	if dsn == "" {
+		b.Skip("LOCATION_INGEST_BENCH_DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer pool.Close()
+
+	repo := repository.NewLocationPointRepository(pool)
+	if err := repo.CreateLocationPointTables(ctx); err != nil {
+		b.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, `TRUNCATE driver_location_points`); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, batchSize := range []int{1000, domain.DefaultLocationIngestBatchSize} {
+		b.Run(fmt.Sprintf("batch=%d", batchSize), func(b *testing.B) {
+			runConsumerBenchmark(b, repo, batchSize)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Package locale resolves the country, city and language a request is made
+// from and carries them in the request context, so pricing and geocoding do
+// not have to assume a market.
+package locale
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// Gateway headers the resolver reads
+const (
+	HeaderUserCountry  = "X-User-Country"  // ISO country from the user's profile
+	HeaderUserLocation = "X-User-Location" // device position as "lat,lng"
+	HeaderUserLanguage = "X-User-Language" // preferred language from the profile
+)
+
+// Fallbacks when nothing about the request identifies a market
+const (
+	DefaultCurrency = domain.CurrencyNGN
+	DefaultLanguage = "en"
+)
+
+// countryCurrencies maps markets to their currency for countries without a
+// city bundle
+var countryCurrencies = map[string]domain.Currency{
+	"NG": domain.CurrencyNGN,
+	"KE": domain.CurrencyKES,
+	"GH": domain.CurrencyGHS,
+	"UG": domain.CurrencyUGX,
+	"TZ": domain.CurrencyTZS,
+	"RW": domain.CurrencyRWF,
+	"ZA": domain.CurrencyZAR,
+}
+
+// Locale is the market and language a request is made in
+type Locale struct {
+	Country  string          `json:"country,omitempty"` // ISO 3166-1 alpha-2
+	City     string          `json:"city,omitempty"`    // city bundle code
+	Currency domain.Currency `json:"currency,omitempty"`
+	Language string          `json:"language"`
+	Timezone string          `json:"timezone,omitempty"`
+
+	// HomeCurrency is the currency of the profile country, which differs
+	// from Currency while the user is abroad
+	HomeCurrency domain.Currency `json:"home_currency,omitempty"`
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the locale
+func NewContext(ctx context.Context, l *Locale) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the request's locale, or nil if none was resolved
+func FromContext(ctx context.Context) *Locale {
+	l, _ := ctx.Value(contextKey{}).(*Locale)
+	return l
+}
+
+// Country returns the request's country, or "" if unknown
+func Country(ctx context.Context) string {
+	if l := FromContext(ctx); l != nil {
+		return l.Country
+	}
+	return ""
+}
+
+// City returns the request's city bundle code, or "" if unknown
+func City(ctx context.Context) string {
+	if l := FromContext(ctx); l != nil {
+		return l.City
+	}
+	return ""
+}
+
+// Currency returns the request's currency, falling back to DefaultCurrency
+func Currency(ctx context.Context) domain.Currency {
+	if l := FromContext(ctx); l != nil && l.Currency != "" {
+		return l.Currency
+	}
+	return DefaultCurrency
+}
+
+// HomeCurrency returns the currency of the user's profile country, or ""
+// if unknown
+func HomeCurrency(ctx context.Context) domain.Currency {
+	if l := FromContext(ctx); l != nil {
+		return l.HomeCurrency
+	}
+	return ""
+}
+
+// Language returns the request's language, falling back to DefaultLanguage
+func Language(ctx context.Context) string {
+	if l := FromContext(ctx); l != nil && l.Language != "" {
+		return l.Language
+	}
+	return DefaultLanguage
+}
+
+func (l *Locale) setCity(city *domain.CityConfig) {
+	l.City = city.Code
+	l.Country = city.Country
+	l.Currency = city.Currency
+	l.Timezone = city.Timezone
+}
+
+// Resolver builds a request's locale from gateway headers and coordinates
+type Resolver struct {
+	cities *cityconfig.Registry
+}
+
+// NewResolver creates a resolver. cities may be nil, in which case only the
+// country headers are used.
+func NewResolver(cities *cityconfig.Registry) *Resolver {
+	return &Resolver{cities: cities}
+}
+
+// Resolve determines a request's locale. The device position wins over the
+// profile country, so a traveller is priced in the market they ride in.
+func (res *Resolver) Resolve(r *http.Request) *Locale {
+	l := &Locale{
+		Country:  strings.ToUpper(strings.TrimSpace(r.Header.Get(HeaderUserCountry))),
+		Language: resolveLanguage(r),
+	}
+	l.HomeCurrency = countryCurrencies[l.Country]
+
+	if lat, lng, ok := requestPosition(r); ok && res.cities != nil {
+		if city, found := res.cities.FindByLocation(lat, lng); found {
+			l.setCity(city)
+		}
+	}
+
+	if l.Currency == "" {
+		l.Currency = countryCurrencies[l.Country]
+	}
+
+	return l
+}
+
+// Middleware stores the resolved locale in the request context
+func (res *Resolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), res.Resolve(r))))
+	})
+}
+
+// requestPosition reads the device position header, then lat/lng query parameters
+func requestPosition(r *http.Request) (float64, float64, bool) {
+	latStr, lngStr := r.URL.Query().Get("lat"), r.URL.Query().Get("lng")
+	if header := r.Header.Get(HeaderUserLocation); header != "" {
+		if parts := strings.SplitN(header, ",", 2); len(parts) == 2 {
+			latStr, lngStr = parts[0], parts[1]
+		}
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(lngStr), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+// resolveLanguage prefers the profile language, then the first Accept-Language tag
+func resolveLanguage(r *http.Request) string {
+	if lang := strings.TrimSpace(r.Header.Get(HeaderUserLanguage)); lang != "" {
+		return lang
+	}
+
+	accept := r.Header.Get("Accept-Language")
+	if tag, _, _ := strings.Cut(accept, ","); tag != "" {
+		tag, _, _ = strings.Cut(tag, ";")
+		if tag = strings.TrimSpace(tag); tag != "" && tag != "*" {
+			return tag
+		}
+	}
+
+	return DefaultLanguage
+}
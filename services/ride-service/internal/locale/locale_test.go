@@ -0,0 +1,94 @@
+package locale
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+func TestResolve(t *testing.T) {
+	cities := cityconfig.NewRegistry()
+	cities.Put(&domain.CityConfig{
+		Code:        "nairobi",
+		Country:     "KE",
+		Currency:    domain.CurrencyKES,
+		Timezone:    "Africa/Nairobi",
+		Enabled:     true,
+		ServiceArea: domain.CityServiceArea{CenterLat: -1.2921, CenterLng: 36.8219, RadiusM: 40000},
+	})
+	resolver := NewResolver(cities)
+
+	tests := []struct {
+		name         string
+		target       string
+		headers      map[string]string
+		wantCountry  string
+		wantCity     string
+		wantCurrency domain.Currency
+		wantLanguage string
+	}{
+		{
+			name:         "profile country only",
+			target:       "/pricing/surge",
+			headers:      map[string]string{HeaderUserCountry: "gh"},
+			wantCountry:  "GH",
+			wantCurrency: domain.CurrencyGHS,
+			wantLanguage: DefaultLanguage,
+		},
+		{
+			name:         "position overrides profile country",
+			target:       "/pricing/surge?lat=-1.28&lng=36.82",
+			headers:      map[string]string{HeaderUserCountry: "NG", "Accept-Language": "sw-KE,sw;q=0.9"},
+			wantCountry:  "KE",
+			wantCity:     "nairobi",
+			wantCurrency: domain.CurrencyKES,
+			wantLanguage: "sw-KE",
+		},
+		{
+			name:         "location header",
+			target:       "/rides",
+			headers:      map[string]string{HeaderUserLocation: "-1.30, 36.80", HeaderUserLanguage: "en"},
+			wantCountry:  "KE",
+			wantCity:     "nairobi",
+			wantCurrency: domain.CurrencyKES,
+			wantLanguage: "en",
+		},
+		{
+			name:         "nothing known",
+			target:       "/rides",
+			wantLanguage: DefaultLanguage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			l := resolver.Resolve(req)
+			if l.Country != tt.wantCountry || l.City != tt.wantCity || l.Currency != tt.wantCurrency || l.Language != tt.wantLanguage {
+				t.Errorf("Resolve() = %+v, want country=%s city=%s currency=%s language=%s",
+					l, tt.wantCountry, tt.wantCity, tt.wantCurrency, tt.wantLanguage)
+			}
+		})
+	}
+}
+
+func TestAccessorsFallBackWithoutLocale(t *testing.T) {
+	ctx := context.Background()
+
+	if got := Currency(ctx); got != DefaultCurrency {
+		t.Errorf("Currency() = %s, want %s", got, DefaultCurrency)
+	}
+	if got := Language(ctx); got != DefaultLanguage {
+		t.Errorf("Language() = %s, want %s", got, DefaultLanguage)
+	}
+	if got := Country(ctx); got != "" {
+		t.Errorf("Country() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/locale"
+)
+
+type rideIDKey struct{}
+type driverIDKey struct{}
+
+// WithRideID returns a copy of ctx whose logs carry the ride's ID
+func WithRideID(ctx context.Context, rideID uuid.UUID) context.Context {
+	return context.WithValue(ctx, rideIDKey{}, rideID.String())
+}
+
+// WithDriverID returns a copy of ctx whose logs carry the driver's ID
+func WithDriverID(ctx context.Context, driverID uuid.UUID) context.Context {
+	return context.WithValue(ctx, driverIDKey{}, driverID.String())
+}
+
+// Ctx returns the default logger with the request, ride, driver and city
+// fields found in ctx
+func Ctx(ctx context.Context) *zerolog.Logger {
+	l := Enrich(ctx, log.Logger)
+	return &l
+}
+
+// Enrich adds the request, ride, driver and city fields found in ctx to l.
+// Ride and driver IDs come from WithRideID and WithDriverID, or else from
+// the {rideId} and {driverId} route parameters.
+func Enrich(ctx context.Context, l zerolog.Logger) zerolog.Logger {
+	c := l.With()
+	if requestID := middleware.GetReqID(ctx); requestID != "" {
+		c = c.Str("request_id", requestID)
+	}
+	if rideID := contextID(ctx, rideIDKey{}, "rideId"); rideID != "" {
+		c = c.Str("ride_id", rideID)
+	}
+	if driverID := contextID(ctx, driverIDKey{}, "driverId"); driverID != "" {
+		c = c.Str("driver_id", driverID)
+	}
+	if city := locale.City(ctx); city != "" {
+		c = c.Str("city", city)
+	}
+	return c.Logger()
+}
+
+func contextID(ctx context.Context, key any, param string) string {
+	if id, ok := ctx.Value(key).(string); ok {
+		return id
+	}
+	if rctx := chi.RouteContext(ctx); rctx != nil {
+		return rctx.URLParam(param)
+	}
+	return ""
+}
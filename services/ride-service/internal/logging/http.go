@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog"
+)
+
+// RequestLogger logs each request once it is served, under the component
+// of the longest path prefix it matches in routes, or "http". Server
+// errors log at error level and client errors at warn, so sampling only
+// ever drops requests that succeeded.
+func RequestLogger(routes map[string]string) func(http.Handler) http.Handler {
+	loggers := map[string]zerolog.Logger{"": For("http")}
+	for _, component := range routes {
+		if _, ok := loggers[component]; !ok {
+			loggers[component] = For(component)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			l := Enrich(r.Context(), loggers[routeComponent(routes, r.URL.Path)])
+			event := l.Info()
+			switch {
+			case status >= http.StatusInternalServerError:
+				event = l.Error()
+			case status >= http.StatusBadRequest:
+				event = l.Warn()
+			}
+			event.
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Int("status", status).
+				Int("bytes", ww.BytesWritten()).
+				Dur("duration", time.Since(start)).
+				Msg("Request served")
+		})
+	}
+}
+
+func routeComponent(routes map[string]string, path string) string {
+	match, component := "", ""
+	for prefix, c := range routes {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(match) {
+			match, component = prefix, c
+		}
+	}
+	return component
+}
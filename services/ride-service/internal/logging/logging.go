@@ -0,0 +1,238 @@
+// Package logging configures the service's zerolog output. Components log
+// through their own logger, whose level and sampling rate can be changed
+// at runtime; loggers pick up request, ride, driver and city fields from
+// the context, and phone numbers are redacted before anything is written.
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrInvalidSettings is returned for levels or sampling rates that cannot
+// be applied
+var ErrInvalidSettings = errors.New("invalid log settings")
+
+// DefaultSampleRates keep one in N debug and info events from the noisiest
+// components. Warnings and errors are never sampled.
+var DefaultSampleRates = map[string]uint32{
+	"location": 20,
+	"matching": 5,
+}
+
+// Settings are the levels and sampling rates in force
+type Settings struct {
+	Level       string            `json:"level"`
+	Levels      map[string]string `json:"levels,omitempty"`       // per-component overrides of Level
+	SampleRates map[string]uint32 `json:"sample_rates,omitempty"` // keep 1 in N debug and info events
+}
+
+type state struct {
+	level  zerolog.Level
+	levels map[string]zerolog.Level
+	rates  map[string]uint32
+}
+
+var (
+	output   = &switchWriter{w: NewRedactor(os.Stderr)}
+	base     = zerolog.New(output).With().Timestamp().Logger()
+	current  atomic.Pointer[state]
+	counters sync.Map // component -> *atomic.Uint64
+)
+
+func init() {
+	current.Store(&state{level: zerolog.InfoLevel, rates: DefaultSampleRates})
+	log.Logger = base.Hook(componentHook{})
+}
+
+// Setup points all logging at w, redacting phone numbers on the way, and
+// applies the initial settings
+func Setup(w io.Writer, settings Settings) error {
+	if err := Apply(settings); err != nil {
+		return err
+	}
+	output.set(NewRedactor(w))
+	return nil
+}
+
+// For returns the logger for a component. Its events carry the component
+// name and follow the component's level and sampling rate.
+func For(component string) zerolog.Logger {
+	return base.With().Str("component", component).Logger().Hook(componentHook{component: component})
+}
+
+// Apply replaces the levels and sampling rates in force. Components without
+// a sampling rate of their own keep their default one. Invalid settings
+// change nothing.
+func Apply(settings Settings) error {
+	next := &state{
+		level:  zerolog.InfoLevel,
+		levels: make(map[string]zerolog.Level, len(settings.Levels)),
+		rates:  make(map[string]uint32, len(DefaultSampleRates)+len(settings.SampleRates)),
+	}
+	if settings.Level != "" {
+		level, err := parseLevel(settings.Level)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSettings, err)
+		}
+		next.level = level
+	}
+	for component, name := range settings.Levels {
+		level, err := parseLevel(name)
+		if err != nil {
+			return fmt.Errorf("%w: component %s: %v", ErrInvalidSettings, component, err)
+		}
+		next.levels[component] = level
+	}
+	for component, rate := range DefaultSampleRates {
+		next.rates[component] = rate
+	}
+	for component, rate := range settings.SampleRates {
+		if rate == 0 {
+			return fmt.Errorf("%w: component %s: sample rate must be at least 1", ErrInvalidSettings, component)
+		}
+		next.rates[component] = rate
+	}
+
+	// Events below the global level are dropped before any hook runs, so it
+	// must admit the most verbose level any component wants
+	lowest := next.level
+	for _, level := range next.levels {
+		if level < lowest {
+			lowest = level
+		}
+	}
+	zerolog.SetGlobalLevel(lowest)
+	current.Store(next)
+	return nil
+}
+
+// Current returns the levels and sampling rates in force
+func Current() Settings {
+	s := current.Load()
+	settings := Settings{
+		Level:       s.level.String(),
+		Levels:      make(map[string]string, len(s.levels)),
+		SampleRates: make(map[string]uint32, len(s.rates)),
+	}
+	for component, level := range s.levels {
+		settings.Levels[component] = level.String()
+	}
+	for component, rate := range s.rates {
+		settings.SampleRates[component] = rate
+	}
+	return settings
+}
+
+// ParseLevels parses per-component levels from LOG_LEVELS, a comma
+// separated list of COMPONENT=LEVEL entries such as "matching=debug"
+func ParseLevels(spec string) (map[string]string, error) {
+	levels := make(map[string]string)
+	err := parseList(spec, func(component, value string) error {
+		if _, err := parseLevel(value); err != nil {
+			return err
+		}
+		levels[component] = value
+		return nil
+	})
+	return levels, err
+}
+
+// ParseSampleRates parses per-component sampling rates from LOG_SAMPLING,
+// a comma separated list of COMPONENT=N entries such as "location=50"
+func ParseSampleRates(spec string) (map[string]uint32, error) {
+	rates := make(map[string]uint32)
+	err := parseList(spec, func(component, value string) error {
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || n == 0 {
+			return fmt.Errorf("sample rate %q must be a whole number of at least 1", value)
+		}
+		rates[component] = uint32(n)
+		return nil
+	})
+	return rates, err
+}
+
+func parseList(spec string, add func(component, value string) error) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		component, value, ok := strings.Cut(entry, "=")
+		component, value = strings.TrimSpace(component), strings.TrimSpace(value)
+		if !ok || component == "" {
+			return fmt.Errorf("invalid entry %q, want COMPONENT=VALUE", entry)
+		}
+		if err := add(component, value); err != nil {
+			return fmt.Errorf("invalid entry %q: %w", entry, err)
+		}
+	}
+	return nil
+}
+
+func parseLevel(name string) (zerolog.Level, error) {
+	level, err := zerolog.ParseLevel(strings.ToLower(strings.TrimSpace(name)))
+	if err != nil || level == zerolog.NoLevel {
+		return zerolog.NoLevel, fmt.Errorf("unknown log level %q", name)
+	}
+	return level, nil
+}
+
+// componentHook drops events below the component's level and samples its
+// debug and info events. The zero value applies the default level to
+// logs written without a component.
+type componentHook struct {
+	component string
+}
+
+func (h componentHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if !allow(h.component, level) {
+		e.Discard()
+	}
+}
+
+func allow(component string, level zerolog.Level) bool {
+	s := current.Load()
+	min, ok := s.levels[component]
+	if !ok {
+		min = s.level
+	}
+	if level < min {
+		return false
+	}
+
+	rate := s.rates[component]
+	if rate <= 1 || level >= zerolog.WarnLevel {
+		return true
+	}
+	counter, _ := counters.LoadOrStore(component, new(atomic.Uint64))
+	return counter.(*atomic.Uint64).Add(1)%uint64(rate) == 1
+}
+
+// switchWriter lets Setup redirect loggers created before it ran
+type switchWriter struct {
+	mu sync.RWMutex
+	w  io.Writer
+}
+
+func (s *switchWriter) set(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w = w
+}
+
+func (s *switchWriter) Write(p []byte) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.w.Write(p)
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestComponentLevelsAndSampling(t *testing.T) {
+	var buf bytes.Buffer
+	err := Setup(&buf, Settings{
+		Level:       "info",
+		Levels:      map[string]string{"matching": "debug"},
+		SampleRates: map[string]uint32{"location": 10},
+	})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	t.Cleanup(func() { _ = Apply(Settings{}) })
+
+	count := func(component, level string) int {
+		return strings.Count(buf.String(), `"level":"`+level+`","component":"`+component+`"`)
+	}
+
+	matching := For("matching")
+	location := For("location")
+	for i := 0; i < 100; i++ {
+		matching.Debug().Msg("offer")
+		location.Info().Msg("update")
+		location.Warn().Msg("stale")
+		location.Debug().Msg("fix")
+	}
+
+	if got := count("matching", "debug"); got != 20 {
+		t.Errorf("matching debug events = %d, want 20 (debug enabled, default 1 in 5)", got)
+	}
+	if got := count("location", "info"); got != 10 {
+		t.Errorf("location info events = %d, want 10 (1 in 10)", got)
+	}
+	if got := count("location", "warn"); got != 100 {
+		t.Errorf("location warn events = %d, want all 100", got)
+	}
+	if got := count("location", "debug"); got != 0 {
+		t.Errorf("location debug events = %d, want 0 below info", got)
+	}
+}
+
+func TestApplyRejectsInvalidSettings(t *testing.T) {
+	before := Current()
+	for _, settings := range []Settings{
+		{Level: "loud"},
+		{Levels: map[string]string{"matching": "verbose"}},
+		{SampleRates: map[string]uint32{"location": 0}},
+	} {
+		if err := Apply(settings); err == nil {
+			t.Errorf("Apply(%+v) error = nil, want error", settings)
+		}
+	}
+	if after := Current(); after.Level != before.Level || len(after.Levels) != len(before.Levels) {
+		t.Errorf("Current() = %+v after rejected settings, want %+v", after, before)
+	}
+}
+
+func TestParseSampleRates(t *testing.T) {
+	rates, err := ParseSampleRates("location=50, matching=1")
+	if err != nil {
+		t.Fatalf("ParseSampleRates() error = %v", err)
+	}
+	if rates["location"] != 50 || rates["matching"] != 1 {
+		t.Errorf("ParseSampleRates() = %v", rates)
+	}
+	for _, spec := range []string{"location", "location=0", "=5", "location=many"} {
+		if _, err := ParseSampleRates(spec); err == nil {
+			t.Errorf("ParseSampleRates(%q) error = nil, want error", spec)
+		}
+	}
+}
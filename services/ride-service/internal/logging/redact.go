@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+)
+
+// Redacted replaces phone numbers in log output
+const Redacted = "[REDACTED]"
+
+var (
+	// phoneFieldPattern matches string fields that hold a phone number
+	// whatever their value looks like
+	phoneFieldPattern = regexp.MustCompile(`"((?:[a-z_]*_)?(?:phone|phone_number|msisdn|mobile))":"[^"]*"`)
+
+	// phonePattern matches phone numbers in international format, or in the
+	// local and country-code formats of the markets we operate in, standing
+	// on their own so IDs and timestamps that contain long digit runs are
+	// left alone
+	phonePattern = regexp.MustCompile(`(?:^|[^\w+-])(\+\d{1,3}(?:[ -]?\d){7,12}|(?:234|254|233|256|255|250|27|0)\d{9,10})(?:$|[^\w-])`)
+)
+
+// Redact removes phone numbers from a log line
+func Redact(p []byte) []byte {
+	p = phoneFieldPattern.ReplaceAll(p, []byte(`"$1":"`+Redacted+`"`))
+	if !phonePattern.Match(p) {
+		return p
+	}
+
+	// Neighbouring numbers share the delimiter between them, so each pass
+	// only finds every other one
+	for {
+		matches := phonePattern.FindAllSubmatchIndex(p, -1)
+		if matches == nil {
+			return p
+		}
+		redacted := make([]byte, 0, len(p))
+		last := 0
+		for _, m := range matches {
+			redacted = append(redacted, p[last:m[2]]...)
+			// A number logged as a JSON number needs quoting once redacted
+			if isJSONNumber(p, m[2], m[3]) {
+				redacted = append(redacted, '"')
+				redacted = append(redacted, Redacted...)
+				redacted = append(redacted, '"')
+			} else {
+				redacted = append(redacted, Redacted...)
+			}
+			last = m[3]
+		}
+		p = append(redacted, p[last:]...)
+	}
+}
+
+// isJSONNumber reports whether p[start:end] is a whole JSON value rather
+// than part of a string
+func isJSONNumber(p []byte, start, end int) bool {
+	return start > 0 && end < len(p) &&
+		bytes.IndexByte([]byte(":,["), p[start-1]) >= 0 &&
+		bytes.IndexByte([]byte(",}]"), p[end]) >= 0
+}
+
+// redactor removes phone numbers from everything written through it
+type redactor struct {
+	w io.Writer
+}
+
+// NewRedactor returns a writer that removes phone numbers from each log
+// line before writing it to w
+func NewRedactor(w io.Writer) io.Writer {
+	return redactor{w: w}
+}
+
+func (r redactor) Write(p []byte) (int, error) {
+	if _, err := r.w.Write(Redact(p)); err != nil {
+		return 0, err
+	}
+	// zerolog treats a short write as an error, so report the original length
+	return len(p), nil
+}
@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{
+			name: "phone field",
+			line: `{"rider_phone":"0801 234 5678","message":"Sent"}`,
+			want: `{"rider_phone":"[REDACTED]","message":"Sent"}`,
+		},
+		{
+			name: "international number in message",
+			line: `{"message":"SMS to +234 801 234 5678 failed"}`,
+			want: `{"message":"SMS to [REDACTED] failed"}`,
+		},
+		{
+			name: "local and country code numbers side by side",
+			line: `{"message":"08012345678,254712345678"}`,
+			want: `{"message":"[REDACTED],[REDACTED]"}`,
+		},
+		{
+			name: "number logged as a JSON number",
+			line: `{"to":2348012345678,"level":"info"}`,
+			want: `{"to":"[REDACTED]","level":"info"}`,
+		},
+		{
+			name: "ids and timestamps untouched",
+			line: `{"ride_id":"0a5f2c1e-2345-4e6f-8a9b-023456789012","time":1760774400,"amount":25000000}`,
+			want: `{"ride_id":"0a5f2c1e-2345-4e6f-8a9b-023456789012","time":1760774400,"amount":25000000}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(Redact([]byte(tt.line)))
+			if got != tt.want {
+				t.Errorf("Redact() = %s, want %s", got, tt.want)
+			}
+			if !json.Valid([]byte(got)) {
+				t.Errorf("Redact() = %s, not valid JSON", got)
+			}
+		})
+	}
+}
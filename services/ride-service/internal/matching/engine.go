@@ -3,16 +3,22 @@ package matching
 
 import (
 	"context"
+	"math"
 	"sort"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/eta"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/logging"
 )
 
+// matchingLog is sampled: each ride logs every attempt and offer
+var matchingLog = logging.For("matching")
+
 // Config holds matching engine configuration
 type Config struct {
 	// Maximum search radius in meters
@@ -35,6 +41,17 @@ type Config struct {
 	
 	// Time between matching attempts
 	MatchingInterval time.Duration
+	
+	// How long a low-priority rider's ride waits before matching starts,
+	// leaving nearby drivers to other riders in high demand
+	LowPriorityHold time.Duration
+	
+	// Points added to the score of a driver the rider favorited
+	FavoriteDriverBoost float64
+	
+	// Points added to the score of an electric or hybrid vehicle's driver
+	// on a green ride
+	GreenVehicleBoost float64
 }
 
 // DefaultConfig returns default matching configuration
@@ -47,6 +64,9 @@ func DefaultConfig() *Config {
 		OfferTimeout:         30 * time.Second,
 		MaxMatchingAttempts:  5,
 		MatchingInterval:     15 * time.Second,
+		LowPriorityHold:      20 * time.Second,
+		FavoriteDriverBoost:  10,
+		GreenVehicleBoost:    25,
 	}
 }
 
@@ -71,14 +91,75 @@ type DriverPool interface {
 // OfferSender sends ride offers to drivers
 type OfferSender interface {
 	// SendOffer sends a ride offer to a driver
-	SendOffer(ctx context.Context, driverID uuid.UUID, ride *domain.Ride, etaSeconds int64) error
+	SendOffer(ctx context.Context, driverID uuid.UUID, offer *domain.RideOffer) error
+}
+
+// FareEstimator reprices a ride's quoted fare for the trip, keeping the
+// surge and discounts it was quoted with
+type FareEstimator interface {
+	RecalculateCityPrice(cityCode string, original *domain.PriceBreakdown, rideType domain.RideType, distanceM float64, durationS int64) *domain.PriceBreakdown
+}
+
+// CityResolver returns the city containing a location
+type CityResolver func(lat, lng float64) (*domain.CityConfig, bool)
+
+// TravelTimeEstimator provides precomputed travel times between points
+type TravelTimeEstimator interface {
+	// Lookup returns travel time in seconds, ok is false when unknown
+	Lookup(ctx context.Context, originLat, originLng, destLat, destLng float64) (int64, bool)
+}
+
+// ReachabilityProvider computes road-network isochrones around a point
+type ReachabilityProvider interface {
+	Isochrones(ctx context.Context, req *eta.IsochroneRequest) ([]*eta.Isochrone, error)
+}
+
+// Average city driving speed used to turn a search radius into the travel
+// time bounding drivers that can actually reach the pickup (20 km/h)
+const reachableMetersPerMinute = 333.0
+
+// QualityBoosts provides the points drivers' quality tiers add to their
+// matching score
+type QualityBoosts interface {
+	MatchingBoost(driverID uuid.UUID) float64
+}
+
+// DriverPreferences provides the drivers a rider favorited and blocked
+type DriverPreferences interface {
+	DriverPreferences(ctx context.Context, riderID uuid.UUID) (*domain.DriverPreferenceSet, error)
+}
+
+// TrainingGate reports which drivers have completed the training a ride
+// requires, such as for pool or intercity rides
+type TrainingGate interface {
+	ClearedForRide(ctx context.Context, ride *domain.Ride, driverIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+}
+
+// VehicleLookup provides drivers' active vehicles, keyed by driver
+type VehicleLookup interface {
+	GetActiveVehicles(ctx context.Context, driverIDs []uuid.UUID) (map[uuid.UUID]*domain.Vehicle, error)
+}
+
+// EventRecorder persists ride timeline events produced during matching
+type EventRecorder interface {
+	AppendEvents(ctx context.Context, events ...*domain.RideEvent) error
 }
 
 // Engine is the main matching engine
 type Engine struct {
-	config     *Config
-	driverPool DriverPool
-	sender     OfferSender
+	config      *Config
+	driverPool  DriverPool
+	sender      OfferSender
+	travelTimes TravelTimeEstimator
+	events      EventRecorder
+	fares       FareEstimator
+	cityOf      CityResolver
+	reach       ReachabilityProvider
+	pooler      *Pooler
+	quality     QualityBoosts
+	prefs       DriverPreferences
+	training    TrainingGate
+	vehicles    VehicleLookup
 	
 	// Active matching sessions
 	sessions   map[uuid.UUID]*MatchingSession
@@ -92,6 +173,7 @@ type MatchingSession struct {
 	Attempt        int
 	CurrentRadius  float64
 	OfferedDrivers map[uuid.UUID]time.Time
+	Preferences    *domain.DriverPreferenceSet // the rider's favorite and blocked drivers
 	Status         MatchingStatus
 	ResultCh       chan *MatchResult
 	CancelFunc     context.CancelFunc
@@ -130,6 +212,79 @@ func NewEngine(config *Config, pool DriverPool, sender OfferSender) *Engine {
 	}
 }
 
+// SetTravelTimeEstimator ranks and offers candidates on matrix-based pickup
+// ETAs instead of straight-line estimates
+func (e *Engine) SetTravelTimeEstimator(estimator TravelTimeEstimator) {
+	e.travelTimes = estimator
+}
+
+// SetFareEstimator enables offer fares and driver earnings priced at offer
+// time
+func (e *Engine) SetFareEstimator(estimator FareEstimator) {
+	e.fares = estimator
+}
+
+// SetCityResolver enables pricing offers with the pickup city's bundle and
+// applying its offer disclosure rules
+func (e *Engine) SetCityResolver(cityOf CityResolver) {
+	e.cityOf = cityOf
+}
+
+// SetReachabilityProvider limits candidates to drivers whose road route
+// reaches the pickup in the time the search radius allows, dropping those
+// across rivers or highways with no nearby crossing
+func (e *Engine) SetReachabilityProvider(provider ReachabilityProvider) {
+	e.reach = provider
+}
+
+// SetPooler enables matching pool rides onto trips drivers are already
+// running with other pool riders
+func (e *Engine) SetPooler(pooler *Pooler) {
+	e.pooler = pooler
+}
+
+// SetQualityBoosts ranks drivers in higher quality tiers ahead of otherwise
+// equal candidates
+func (e *Engine) SetQualityBoosts(boosts QualityBoosts) {
+	e.quality = boosts
+}
+
+// SetDriverPreferences ranks riders' favorite drivers ahead and never
+// offers their rides to drivers they blocked
+func (e *Engine) SetDriverPreferences(prefs DriverPreferences) {
+	e.prefs = prefs
+}
+
+// SetTrainingGate offers pool and intercity rides only to drivers who have
+// completed their training
+func (e *Engine) SetTrainingGate(gate TrainingGate) {
+	e.training = gate
+}
+
+// SetVehicleLookup ranks the drivers of electric and hybrid vehicles ahead
+// on green rides
+func (e *Engine) SetVehicleLookup(vehicles VehicleLookup) {
+	e.vehicles = vehicles
+}
+
+// SetEventRecorder enables writing matching attempts and offers to the ride timeline
+func (e *Engine) SetEventRecorder(recorder EventRecorder) {
+	e.events = recorder
+}
+
+// recordEvent writes a timeline event, logging rather than failing on error
+func (e *Engine) recordEvent(ctx context.Context, event *domain.RideEvent) {
+	if e.events == nil {
+		return
+	}
+	if err := e.events.AppendEvents(ctx, event); err != nil {
+		matchingLog.Warn().Err(err).
+			Str("ride_id", event.RideID.String()).
+			Str("type", string(event.Type)).
+			Msg("Failed to record ride event")
+	}
+}
+
 // StartMatching begins the matching process for a ride
 func (e *Engine) StartMatching(ctx context.Context, ride *domain.Ride) (<-chan *MatchResult, error) {
 	// Validate ride
@@ -214,20 +369,38 @@ func (e *Engine) AcceptRide(ctx context.Context, rideID, driverID uuid.UUID) (*M
 		return nil, err
 	}
 	
-	if !driver.IsAvailable() {
+	// A driver running a pool trip is busy but can take another pool rider
+	pool := session.Ride.Type == domain.RideTypePool && e.pooler != nil
+	if !driver.IsAvailable() && !(pool && e.pooler.IsRunningTrip(driverID)) {
 		e.sessionsMu.Unlock()
 		return nil, domain.ErrDriverNotAvailable
 	}
 	
-	// Calculate ETA
-	eta := e.calculateETA(session.Ride.PickupLocation, *driver.CurrentLocation, driver.Vehicle.Type)
+	var joined *domain.RideEvent
+	if pool {
+		trip, insertion, err := e.pooler.Join(ctx, session.Ride, driverID)
+		if err != nil {
+			e.sessionsMu.Unlock()
+			return nil, err
+		}
+		joined = domain.NewRideEvent(rideID, domain.RideEventPoolJoined).
+			WithActor(driverID).
+			WithData("trip_id", trip.ID).
+			WithData("riders", len(trip.Riders))
+		if insertion != nil {
+			joined.WithData("added_meters", int64(insertion.AddedMeters))
+		}
+	}
 	
-	// Create result
+	// Create result, with the ETA when the driver's position and vehicle
+	// are known
 	result := &MatchResult{
-		Success:   true,
-		DriverID:  driverID,
-		VehicleID: driver.Vehicle.ID,
-		ETA:       eta,
+		Success:  true,
+		DriverID: driverID,
+	}
+	if driver.Vehicle != nil && driver.CurrentLocation != nil {
+		result.VehicleID = driver.Vehicle.ID
+		result.ETA = e.calculateETA(ctx, session.Ride.PickupLocation, *driver.CurrentLocation, driver.Vehicle.Type)
 	}
 	
 	session.Status = MatchingStatusMatched
@@ -242,6 +415,13 @@ func (e *Engine) AcceptRide(ctx context.Context, rideID, driverID uuid.UUID) (*M
 	delete(e.sessions, rideID)
 	e.sessionsMu.Unlock()
 	
+	e.recordEvent(ctx, domain.NewRideEvent(rideID, domain.RideEventOfferAccepted).
+		WithActor(driverID).
+		WithData("eta_seconds", result.ETA))
+	if joined != nil {
+		e.recordEvent(ctx, joined)
+	}
+	
 	return result, nil
 }
 
@@ -263,19 +443,45 @@ func (e *Engine) DeclineRide(rideID, driverID uuid.UUID) error {
 	// Unlock driver
 	_ = e.driverPool.UnlockDriver(context.Background(), driverID)
 	
+	e.recordEvent(context.Background(), domain.NewRideEvent(rideID, domain.RideEventOfferDeclined).
+		WithActor(driverID))
+	
 	return nil
 }
 
 // runMatching runs the matching algorithm
 func (e *Engine) runMatching(ctx context.Context, session *MatchingSession) {
+	// The result channel is closed under the lock AcceptRide sends on it
+	// under, once the session can no longer be found to accept
 	defer func() {
-		close(session.ResultCh)
 		e.sessionsMu.Lock()
-		delete(e.sessions, session.Ride.ID)
+		close(session.ResultCh)
+		if e.sessions[session.Ride.ID] == session {
+			delete(e.sessions, session.Ride.ID)
+		}
 		e.sessionsMu.Unlock()
 	}()
 	
 	ride := session.Ride
+	logger := logging.Enrich(logging.WithRideID(ctx, ride.ID), matchingLog)
+	
+	if e.prefs != nil {
+		prefs, err := e.prefs.DriverPreferences(ctx, ride.RiderID)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to load rider driver preferences")
+		}
+		session.Preferences = prefs
+	}
+	
+	if ride.Metadata[domain.RiderPriorityMetadataKey] == domain.RiderPriorityLow && e.config.LowPriorityHold > 0 {
+		logger.Info().Dur("hold", e.config.LowPriorityHold).Msg("Holding low-priority ride before matching")
+		select {
+		case <-ctx.Done():
+			session.Status = MatchingStatusCancelled
+			return
+		case <-time.After(e.config.LowPriorityHold):
+		}
+	}
 	
 	for session.Attempt < e.config.MaxMatchingAttempts {
 		select {
@@ -286,12 +492,21 @@ func (e *Engine) runMatching(ctx context.Context, session *MatchingSession) {
 		}
 		
 		session.Attempt++
-		log.Info().
-			Str("ride_id", ride.ID.String()).
+		logger.Info().
 			Int("attempt", session.Attempt).
 			Float64("radius", session.CurrentRadius).
 			Msg("Starting matching attempt")
 		
+		e.recordEvent(ctx, domain.NewRideEvent(ride.ID, domain.RideEventMatchingAttempt).
+			WithData("attempt", session.Attempt).
+			WithData("radius_m", session.CurrentRadius))
+		
+		// Pool rides are offered to a trip heading their way as well as to
+		// free drivers; whoever accepts first takes the ride
+		if ride.Type == domain.RideTypePool && e.pooler != nil {
+			e.offerPoolTrip(ctx, session, logger)
+		}
+		
 		// Find nearby drivers
 		drivers, err := e.driverPool.GetNearbyDrivers(
 			ctx,
@@ -302,15 +517,19 @@ func (e *Engine) runMatching(ctx context.Context, session *MatchingSession) {
 		)
 		
 		if err != nil {
-			log.Error().Err(err).Msg("Failed to get nearby drivers")
+			logger.Error().Err(err).Msg("Failed to get nearby drivers")
 			continue
 		}
 		
 		// Filter out already offered/declined drivers
 		candidates := e.filterCandidates(session, drivers)
+		candidates = e.filterTrained(ctx, ride, candidates, logger)
+		candidates = e.filterReachable(ctx, ride, session.CurrentRadius, candidates)
+		e.attachVehicles(ctx, ride, candidates, logger)
+		e.estimateETAs(ctx, ride, candidates)
 		
 		if len(candidates) == 0 {
-			log.Info().Msg("No candidates found, expanding radius")
+			logger.Debug().Msg("No candidates found, expanding radius")
 			session.CurrentRadius = min(
 				session.CurrentRadius+e.config.RadiusExpansionStep,
 				e.config.MaxSearchRadius,
@@ -320,7 +539,7 @@ func (e *Engine) runMatching(ctx context.Context, session *MatchingSession) {
 		}
 		
 		// Rank candidates
-		ranked := e.rankCandidates(candidates, ride)
+		ranked := e.rankCandidates(candidates, ride, session.Preferences)
 		
 		// Send offers to top candidates
 		for i, candidate := range ranked {
@@ -334,21 +553,26 @@ func (e *Engine) runMatching(ctx context.Context, session *MatchingSession) {
 			}
 			
 			// Record offer
-			session.OfferedDrivers[candidate.Driver.ID] = time.Now()
+			e.markOffered(session, candidate.Driver.ID)
 			
 			// Send offer
-			if err := e.sender.SendOffer(ctx, candidate.Driver.ID, ride, candidate.ETASeconds); err != nil {
-				log.Error().Err(err).
+			if err := e.sender.SendOffer(ctx, candidate.Driver.ID, e.buildOffer(ride, candidate)); err != nil {
+				logger.Error().Err(err).
 					Str("driver_id", candidate.Driver.ID.String()).
 					Msg("Failed to send offer")
 				_ = e.driverPool.UnlockDriver(ctx, candidate.Driver.ID)
 				continue
 			}
 			
-			log.Info().
+			logger.Debug().
 				Str("driver_id", candidate.Driver.ID.String()).
 				Int64("eta", candidate.ETASeconds).
 				Msg("Sent ride offer to driver")
+			
+			e.recordEvent(ctx, domain.NewRideEvent(ride.ID, domain.RideEventOfferSent).
+				WithActor(candidate.Driver.ID).
+				WithData("eta_seconds", candidate.ETASeconds).
+				WithData("distance_m", candidate.DistanceM))
 		}
 		
 		// Wait for responses
@@ -359,7 +583,7 @@ func (e *Engine) runMatching(ctx context.Context, session *MatchingSession) {
 			return
 		case <-timer.C:
 			// Timeout - unlock all offered drivers and try again
-			for driverID, offerTime := range session.OfferedDrivers {
+			for driverID, offerTime := range e.offered(session) {
 				if !offerTime.IsZero() && time.Since(offerTime) > e.config.OfferTimeout {
 					_ = e.driverPool.UnlockDriver(ctx, driverID)
 				}
@@ -381,13 +605,80 @@ func (e *Engine) runMatching(ctx context.Context, session *MatchingSession) {
 	}
 }
 
-// filterCandidates removes drivers that have already been offered or declined
+// offerPoolTrip offers a pool ride to the driver of the trip it adds least
+// distance to
+func (e *Engine) offerPoolTrip(ctx context.Context, session *MatchingSession, logger zerolog.Logger) {
+	ride := session.Ride
+	match, ok := e.pooler.FindTrip(ctx, ride, e.offered(session))
+	if !ok || session.Preferences.IsBlocked(match.DriverID) {
+		return
+	}
+	if len(e.filterTrained(ctx, ride, []*domain.NearbyDriver{{Driver: &domain.Driver{ID: match.DriverID}}}, logger)) == 0 {
+		return
+	}
+	if err := e.driverPool.LockDriver(ctx, match.DriverID, e.config.OfferTimeout); err != nil {
+		return
+	}
+	e.markOffered(session, match.DriverID)
+	
+	candidate := &domain.NearbyDriver{
+		Driver:     &domain.Driver{ID: match.DriverID},
+		DistanceM:  match.PickupDistanceM,
+		ETASeconds: geo.EstimateETA(match.PickupDistanceM, string(eta.ProfileCar)),
+	}
+	if err := e.sender.SendOffer(ctx, match.DriverID, e.buildOffer(ride, candidate)); err != nil {
+		logger.Error().Err(err).Str("driver_id", match.DriverID.String()).Msg("Failed to send pool offer")
+		_ = e.driverPool.UnlockDriver(ctx, match.DriverID)
+		return
+	}
+	
+	logger.Debug().
+		Str("driver_id", match.DriverID.String()).
+		Str("trip_id", match.TripID.String()).
+		Float64("added_meters", match.Insertion.AddedMeters).
+		Msg("Sent pool offer to driver on trip")
+	
+	e.recordEvent(ctx, domain.NewRideEvent(ride.ID, domain.RideEventOfferSent).
+		WithActor(match.DriverID).
+		WithData("eta_seconds", candidate.ETASeconds).
+		WithData("distance_m", candidate.DistanceM).
+		WithData("pool_trip_id", match.TripID))
+}
+
+// offered copies the drivers a session has offered its ride to, which
+// drivers' answers change while matching runs
+func (e *Engine) offered(session *MatchingSession) map[uuid.UUID]time.Time {
+	e.sessionsMu.RLock()
+	defer e.sessionsMu.RUnlock()
+	
+	offered := make(map[uuid.UUID]time.Time, len(session.OfferedDrivers))
+	for driverID, at := range session.OfferedDrivers {
+		offered[driverID] = at
+	}
+	return offered
+}
+
+// markOffered records an offer sent to a driver
+func (e *Engine) markOffered(session *MatchingSession, driverID uuid.UUID) {
+	e.sessionsMu.Lock()
+	session.OfferedDrivers[driverID] = time.Now()
+	e.sessionsMu.Unlock()
+}
+
+// filterCandidates removes drivers that have already been offered or
+// declined, and drivers the rider blocked
 func (e *Engine) filterCandidates(session *MatchingSession, drivers []*domain.NearbyDriver) []*domain.NearbyDriver {
 	var candidates []*domain.NearbyDriver
+	offered := e.offered(session)
 	
 	for _, d := range drivers {
 		// Skip if already offered
-		if _, offered := session.OfferedDrivers[d.Driver.ID]; offered {
+		if _, ok := offered[d.Driver.ID]; ok {
+			continue
+		}
+		
+		// Skip if the rider blocked them
+		if session.Preferences.IsBlocked(d.Driver.ID) {
 			continue
 		}
 		
@@ -402,8 +693,122 @@ func (e *Engine) filterCandidates(session *MatchingSession, drivers []*domain.Ne
 	return candidates
 }
 
-// rankCandidates scores and ranks driver candidates
-func (e *Engine) rankCandidates(candidates []*domain.NearbyDriver, ride *domain.Ride) []*domain.NearbyDriver {
+// filterTrained drops candidates who have not completed the training the
+// ride requires. When training cannot be checked no candidate is kept,
+// since untrained drivers must not be offered gated rides.
+func (e *Engine) filterTrained(ctx context.Context, ride *domain.Ride, candidates []*domain.NearbyDriver, logger zerolog.Logger) []*domain.NearbyDriver {
+	if e.training == nil || len(candidates) == 0 {
+		return candidates
+	}
+	
+	driverIDs := make([]uuid.UUID, len(candidates))
+	for i, c := range candidates {
+		driverIDs[i] = c.Driver.ID
+	}
+	cleared, err := e.training.ClearedForRide(ctx, ride, driverIDs)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to check driver training")
+		return nil
+	}
+	
+	trained := candidates[:0]
+	for _, c := range candidates {
+		if cleared[c.Driver.ID] {
+			trained = append(trained, c)
+		}
+	}
+	return trained
+}
+
+// filterReachable drops candidates outside the reverse isochrone of the
+// pickup for the search radius. Candidates are kept as they are when no
+// isochrone can be computed.
+func (e *Engine) filterReachable(ctx context.Context, ride *domain.Ride, radiusM float64, candidates []*domain.NearbyDriver) []*domain.NearbyDriver {
+	if e.reach == nil || len(candidates) == 0 {
+		return candidates
+	}
+	
+	minutes := int(math.Ceil(radiusM / reachableMetersPerMinute))
+	if minutes > 60 {
+		minutes = 60
+	}
+	isochrones, err := e.reach.Isochrones(ctx, &eta.IsochroneRequest{
+		Lat:     ride.PickupLocation.Latitude,
+		Lng:     ride.PickupLocation.Longitude,
+		Minutes: []int{minutes},
+		Profile: eta.ProfileForRideType(ride.Type),
+		Reverse: true,
+	})
+	if err != nil {
+		matchingLog.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to compute pickup isochrone")
+		return candidates
+	}
+	
+	reachable := candidates[:0]
+	for _, c := range candidates {
+		loc := c.Driver.CurrentLocation
+		if loc == nil || isochrones[0].Contains(loc.Latitude, loc.Longitude) {
+			reachable = append(reachable, c)
+		}
+	}
+	return reachable
+}
+
+// attachVehicles sets the active vehicle of candidates found without one,
+// for ranking green rides. Candidates are ranked as they are when their
+// vehicles cannot be read.
+func (e *Engine) attachVehicles(ctx context.Context, ride *domain.Ride, candidates []*domain.NearbyDriver, logger zerolog.Logger) {
+	if e.vehicles == nil || !ride.PrefersGreen() {
+		return
+	}
+	
+	var driverIDs []uuid.UUID
+	for _, c := range candidates {
+		if c.Driver.Vehicle == nil {
+			driverIDs = append(driverIDs, c.Driver.ID)
+		}
+	}
+	if len(driverIDs) == 0 {
+		return
+	}
+	vehicles, err := e.vehicles.GetActiveVehicles(ctx, driverIDs)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to get candidates' vehicles")
+		return
+	}
+	for _, c := range candidates {
+		if v, ok := vehicles[c.Driver.ID]; ok && c.Driver.Vehicle == nil {
+			c.Driver.Vehicle = v
+		}
+	}
+}
+
+// estimateETAs replaces candidates' straight-line pickup ETAs with the
+// travel times learned from past trips, where there are any. The matrix is
+// built from car trips, so rides served by bikes keep the estimates.
+func (e *Engine) estimateETAs(ctx context.Context, ride *domain.Ride, candidates []*domain.NearbyDriver) {
+	if e.travelTimes == nil || eta.ProfileForRideType(ride.Type) != eta.ProfileCar {
+		return
+	}
+	
+	for _, c := range candidates {
+		loc := c.Driver.CurrentLocation
+		if loc == nil {
+			continue
+		}
+		if seconds, ok := e.travelTimes.Lookup(ctx,
+			loc.Latitude, loc.Longitude,
+			ride.PickupLocation.Latitude, ride.PickupLocation.Longitude,
+		); ok {
+			c.ETASeconds = seconds
+		}
+	}
+}
+
+// rankCandidates scores and ranks driver candidates, boosting drivers the
+// rider favorited and, on green rides, drivers of electric and hybrid
+// vehicles. prefs may be nil.
+func (e *Engine) rankCandidates(candidates []*domain.NearbyDriver, ride *domain.Ride, prefs *domain.DriverPreferenceSet) []*domain.NearbyDriver {
 	// Score each candidate
 	type scoredDriver struct {
 		driver *domain.NearbyDriver
@@ -414,6 +819,12 @@ func (e *Engine) rankCandidates(candidates []*domain.NearbyDriver, ride *domain.
 	
 	for i, c := range candidates {
 		score := e.calculateScore(c, ride)
+		if prefs.IsFavorite(c.Driver.ID) {
+			score += e.config.FavoriteDriverBoost
+		}
+		if ride.PrefersGreen() && c.Driver.Vehicle != nil && c.Driver.Vehicle.Powertrain.LowEmission() {
+			score += e.config.GreenVehicleBoost
+		}
 		scored[i] = scoredDriver{driver: c, score: score}
 	}
 	
@@ -438,6 +849,7 @@ func (e *Engine) calculateScore(candidate *domain.NearbyDriver, ride *domain.Rid
 	// - Rating (higher is better) - 30%
 	// - Acceptance rate (higher is better) - 20%
 	// - ETA (shorter is better) - 10%
+	// plus the driver's quality tier boost, when one is set
 	
 	// Distance score (max 40 points)
 	// Score decreases linearly with distance
@@ -462,11 +874,66 @@ func (e *Engine) calculateScore(candidate *domain.NearbyDriver, ride *domain.Rid
 		etaScore = 0
 	}
 	
-	return distanceScore + ratingScore + acceptanceScore + etaScore
+	score := distanceScore + ratingScore + acceptanceScore + etaScore
+	if e.quality != nil {
+		score += e.quality.MatchingBoost(candidate.Driver.ID)
+	}
+	return score
+}
+
+// buildOffer builds a ride's offer to a candidate. The trip is the ride's
+// route, or a straight-line estimate when it has none; earnings need the
+// ride's quoted fare and a fare estimator. What the pickup city's rules
+// hide is withheld before the offer is sent.
+func (e *Engine) buildOffer(ride *domain.Ride, candidate *domain.NearbyDriver) *domain.RideOffer {
+	var tripDistance float64
+	var tripDuration int64
+	if ride.Route != nil {
+		tripDistance = float64(ride.Route.DistanceMeters)
+		tripDuration = ride.Route.DurationSeconds
+	} else {
+		tripDistance = geo.HaversineDistance(
+			ride.PickupLocation.Latitude, ride.PickupLocation.Longitude,
+			ride.DropoffLocation.Latitude, ride.DropoffLocation.Longitude,
+		)
+		tripDuration = geo.EstimateETA(tripDistance, string(eta.ProfileForRideType(ride.Type)))
+	}
+	
+	var city *domain.CityConfig
+	if e.cityOf != nil {
+		city, _ = e.cityOf(ride.PickupLocation.Latitude, ride.PickupLocation.Longitude)
+	}
+	
+	price := ride.Price
+	if e.fares != nil && ride.Price != nil {
+		cityCode := ""
+		if city != nil {
+			cityCode = city.Code
+		}
+		price = e.fares.RecalculateCityPrice(cityCode, ride.Price, ride.Type, tripDistance, tripDuration)
+	}
+	
+	offer := domain.NewRideOffer(ride, candidate.DistanceM, candidate.ETASeconds,
+		tripDistance, tripDuration, price, e.config.OfferTimeout)
+	if city != nil {
+		city.Offers.Apply(offer)
+	}
+	return offer
 }
 
 // calculateETA calculates ETA from driver to pickup
-func (e *Engine) calculateETA(pickup domain.Location, driverLoc domain.Location, vehicleType domain.VehicleType) int64 {
+func (e *Engine) calculateETA(ctx context.Context, pickup domain.Location, driverLoc domain.Location, vehicleType domain.VehicleType) int64 {
+	// Prefer historical cell-to-cell travel times when available. They are
+	// learned from car trips, so bikes take the speed estimate.
+	if e.travelTimes != nil && eta.ProfileForVehicle(vehicleType) == eta.ProfileCar {
+		if seconds, ok := e.travelTimes.Lookup(ctx,
+			driverLoc.Latitude, driverLoc.Longitude,
+			pickup.Latitude, pickup.Longitude,
+		); ok {
+			return seconds
+		}
+	}
+	
 	distance := geo.HaversineDistance(
 		driverLoc.Latitude, driverLoc.Longitude,
 		pickup.Latitude, pickup.Longitude,
@@ -0,0 +1,103 @@
+package matching
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+const (
+	// OfferStreamGroup is the consumer group the realtime gateway reads
+	// driver offer streams as
+	OfferStreamGroup = "gateway"
+
+	// offerStreamRetention is how long offers stay in a driver's stream.
+	// Entries are trimmed and idle streams deleted after it; offers expire
+	// well before, so the gateway never replays them.
+	offerStreamRetention = 10 * time.Minute
+)
+
+// OfferStreamKey is the Redis stream holding a driver's undelivered offers
+func OfferStreamKey(driverID string) string {
+	return fmt.Sprintf("driver:%s:offers", driverID)
+}
+
+// OfferNotifyChannel is the channel that wakes the gateway holding a
+// driver's socket to read their offer stream
+func OfferNotifyChannel(driverID string) string {
+	return fmt.Sprintf("offers:%s", driverID)
+}
+
+// OfferStream delivers dispatch messages to drivers through a Redis stream
+// per driver. The realtime gateway reads each stream as OfferStreamGroup
+// and acknowledges an entry once the driver app confirms it, so an offer
+// sent while the driver's socket is reconnecting is replayed on reconnect
+// instead of lost as a pub/sub message would be.
+type OfferStream struct {
+	redis *redis.Client
+}
+
+// NewOfferStream creates a new offer stream
+func NewOfferStream(redisClient *redis.Client) *OfferStream {
+	return &OfferStream{redis: redisClient}
+}
+
+// Send appends a message to the driver's stream and wakes the gateway. The
+// gateway drops the message unread once ttl has passed. It returns the
+// stream entry ID the driver app acknowledges.
+func (s *OfferStream) Send(ctx context.Context, driverID string, message []byte, ttl time.Duration) (string, error) {
+	key := OfferStreamKey(driverID)
+	now := time.Now()
+
+	// The group goes with the stream when an idle stream expires, so it is
+	// recreated on every send
+	err := s.redis.XGroupCreateMkStream(ctx, key, OfferStreamGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return "", fmt.Errorf("create offer stream group: %w", err)
+	}
+
+	id, err := s.redis.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MinID:  fmt.Sprintf("%d", now.Add(-offerStreamRetention).UnixMilli()),
+		Approx: true,
+		Values: map[string]interface{}{
+			"message":    message,
+			"expires_at": now.Add(ttl).UnixMilli(),
+		},
+	}).Result()
+	if err != nil {
+		return "", fmt.Errorf("add offer to stream: %w", err)
+	}
+
+	pipe := s.redis.Pipeline()
+	pipe.PExpire(ctx, key, offerStreamRetention)
+	pipe.Publish(ctx, OfferNotifyChannel(driverID), id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		// The offer is stored; the gateway finds it on the next wake-up
+		// or reconnect
+		return id, fmt.Errorf("notify gateway of offer: %w", err)
+	}
+	return id, nil
+}
+
+// SendOffer queues a matching engine's ride offer on the driver's stream,
+// to be dropped unread once the offer expires. It goes out as a dispatch
+// request, which driver apps already answer through the ride's accept and
+// decline endpoints.
+func (s *OfferStream) SendOffer(ctx context.Context, driverID uuid.UUID, offer *domain.RideOffer) error {
+	message, err := json.Marshal(map[string]interface{}{
+		"type":    "dispatch_request",
+		"payload": offer,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.Send(ctx, driverID.String(), message, time.Duration(offer.ExpiresInSeconds)*time.Second)
+	return err
+}
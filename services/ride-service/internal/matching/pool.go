@@ -0,0 +1,325 @@
+package matching
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+)
+
+// poolSearchRadius is how far a pool trip's driver may be from a new
+// rider's pickup for the ride to join the trip
+const poolSearchRadius = 3000.0 // meters
+
+// Insertion places a pool ride's pickup and dropoff among a trip's
+// remaining stops
+type Insertion struct {
+	PickupIndex  int               `json:"pickup_index"`
+	DropoffIndex int               `json:"dropoff_index"`
+	AddedMeters  float64           `json:"added_meters"` // how much longer the driver's route gets
+	Stops        []domain.PoolStop `json:"stops"`
+}
+
+// PoolMatch is a pool trip a ride can join
+type PoolMatch struct {
+	TripID          uuid.UUID
+	DriverID        uuid.UUID
+	PickupDistanceM float64
+	Insertion       *Insertion
+}
+
+// BestInsertion finds where a ride's pickup and dropoff add least to a
+// trip's route from the driver's position. Places that would stretch any
+// rider's time in the car past domain.MaxPoolDetour times their direct
+// trip, or leave the new rider waiting past domain.MaxPoolPickupMeters of
+// driving, are ruled out; ok is false when no place is left.
+func BestInsertion(start domain.Location, trip *domain.PoolTrip, rider *domain.PoolRider, pickup, dropoff domain.Location) (*Insertion, bool) {
+	limits := make(map[uuid.UUID]float64, len(trip.Riders)+1)
+	for _, r := range trip.Riders {
+		limits[r.RideID] = r.DirectMeters * domain.MaxPoolDetour
+	}
+	limits[rider.RideID] = rider.DirectMeters * domain.MaxPoolDetour
+
+	base := routeMeters(start, trip.Stops)
+	pickupStop := domain.PoolStop{RideID: rider.RideID, Type: domain.PoolStopPickup, Location: pickup}
+	dropoffStop := domain.PoolStop{RideID: rider.RideID, Type: domain.PoolStopDropoff, Location: dropoff}
+
+	var best *Insertion
+	n := len(trip.Stops)
+	for i := 0; i <= n; i++ {
+		for j := i + 1; j <= n+1; j++ {
+			stops := make([]domain.PoolStop, 0, n+2)
+			stops = append(stops, trip.Stops[:i]...)
+			stops = append(stops, pickupStop)
+			stops = append(stops, trip.Stops[i:j-1]...)
+			stops = append(stops, dropoffStop)
+			stops = append(stops, trip.Stops[j-1:]...)
+
+			if !withinDetour(start, stops, limits, rider.RideID) {
+				continue
+			}
+			added := routeMeters(start, stops) - base
+			if best == nil || added < best.AddedMeters {
+				best = &Insertion{PickupIndex: i, DropoffIndex: j, AddedMeters: added, Stops: stops}
+			}
+		}
+	}
+	return best, best != nil
+}
+
+// routeMeters is the straight-line length of driving through the stops
+func routeMeters(start domain.Location, stops []domain.PoolStop) float64 {
+	total := 0.0
+	prev := start
+	for _, stop := range stops {
+		total += geo.HaversineDistance(prev.Latitude, prev.Longitude, stop.Location.Latitude, stop.Location.Longitude)
+		prev = stop.Location
+	}
+	return total
+}
+
+// withinDetour reports whether every rider reaches their dropoff within
+// their limit and the joining rider is picked up soon enough. Riders
+// already in the car count from the driver's position.
+func withinDetour(start domain.Location, stops []domain.PoolStop, limits map[uuid.UUID]float64, joining uuid.UUID) bool {
+	boarded := make(map[uuid.UUID]float64, len(limits))
+	travelled := 0.0
+	prev := start
+	for _, stop := range stops {
+		travelled += geo.HaversineDistance(prev.Latitude, prev.Longitude, stop.Location.Latitude, stop.Location.Longitude)
+		prev = stop.Location
+
+		switch stop.Type {
+		case domain.PoolStopPickup:
+			if stop.RideID == joining && travelled > domain.MaxPoolPickupMeters {
+				return false
+			}
+			boarded[stop.RideID] = travelled
+		case domain.PoolStopDropoff:
+			if limit, ok := limits[stop.RideID]; ok && travelled-boarded[stop.RideID] > limit {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// headingDiff is the angle in degrees between two headings
+func headingDiff(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
+
+// newPoolRider describes a pool ride for matching against trips
+func newPoolRider(ride *domain.Ride) *domain.PoolRider {
+	pickup, dropoff := ride.PickupLocation, ride.DropoffLocation
+	return &domain.PoolRider{
+		RideID:         ride.ID,
+		DirectMeters:   geo.HaversineDistance(pickup.Latitude, pickup.Longitude, dropoff.Latitude, dropoff.Longitude),
+		HeadingDegrees: geo.Bearing(pickup.Latitude, pickup.Longitude, dropoff.Latitude, dropoff.Longitude),
+		JoinedAt:       time.Now().UTC(),
+	}
+}
+
+// Pooler keeps the pool trips drivers are running and matches new pool
+// rides onto them
+type Pooler struct {
+	driverPool DriverPool
+
+	mu       sync.Mutex
+	trips    map[uuid.UUID]*domain.PoolTrip // trip ID -> trip
+	byDriver map[uuid.UUID]uuid.UUID        // driver ID -> trip ID
+	byRide   map[uuid.UUID]uuid.UUID        // ride ID -> trip ID
+}
+
+// NewPooler creates a new pooler
+func NewPooler(driverPool DriverPool) *Pooler {
+	return &Pooler{
+		driverPool: driverPool,
+		trips:      make(map[uuid.UUID]*domain.PoolTrip),
+		byDriver:   make(map[uuid.UUID]uuid.UUID),
+		byRide:     make(map[uuid.UUID]uuid.UUID),
+	}
+}
+
+// FindTrip finds the pool trip a ride adds least distance to, among trips
+// with a seat free, heading the ride's way and with their driver near the
+// pickup. Drivers in skip are left out.
+func (p *Pooler) FindTrip(ctx context.Context, ride *domain.Ride, skip map[uuid.UUID]time.Time) (*PoolMatch, bool) {
+	rider := newPoolRider(ride)
+
+	p.mu.Lock()
+	candidates := make([]*domain.PoolTrip, 0, len(p.trips))
+	for _, trip := range p.trips {
+		if _, skipped := skip[trip.DriverID]; skipped || !trip.HasSeat() || !sameDirection(trip, rider) {
+			continue
+		}
+		candidates = append(candidates, copyTrip(trip))
+	}
+	p.mu.Unlock()
+
+	var best *PoolMatch
+	for _, trip := range candidates {
+		driver, err := p.driverPool.GetDriver(ctx, trip.DriverID)
+		if err != nil || driver == nil || driver.CurrentLocation == nil {
+			continue
+		}
+		start := *driver.CurrentLocation
+		pickupDistance := geo.HaversineDistance(start.Latitude, start.Longitude,
+			ride.PickupLocation.Latitude, ride.PickupLocation.Longitude)
+		if pickupDistance > poolSearchRadius {
+			continue
+		}
+
+		insertion, ok := BestInsertion(start, trip, rider, ride.PickupLocation, ride.DropoffLocation)
+		if !ok {
+			continue
+		}
+		if best == nil || insertion.AddedMeters < best.Insertion.AddedMeters {
+			best = &PoolMatch{
+				TripID:          trip.ID,
+				DriverID:        trip.DriverID,
+				PickupDistanceM: pickupDistance,
+				Insertion:       insertion,
+			}
+		}
+	}
+	return best, best != nil
+}
+
+// IsRunningTrip reports whether the driver has a pool trip with riders
+func (p *Pooler) IsRunningTrip(driverID uuid.UUID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.byDriver[driverID]
+	return ok
+}
+
+// Join adds a ride to the driver's pool trip, or starts a trip when the
+// driver has none. The insertion is worked out again against the trip's
+// stops as they are now; domain.ErrPoolTripFull means the ride no longer
+// fits.
+func (p *Pooler) Join(ctx context.Context, ride *domain.Ride, driverID uuid.UUID) (*domain.PoolTrip, *Insertion, error) {
+	rider := newPoolRider(ride)
+
+	// The driver's position is read before locking; it is only used to
+	// order the stops
+	start := ride.PickupLocation
+	if driver, err := p.driverPool.GetDriver(ctx, driverID); err == nil && driver != nil && driver.CurrentLocation != nil {
+		start = *driver.CurrentLocation
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if tripID, ok := p.byRide[ride.ID]; ok {
+		trip := p.trips[tripID]
+		return copyTrip(trip), nil, nil
+	}
+
+	now := time.Now().UTC()
+	trip := p.trips[p.byDriver[driverID]]
+	if trip == nil {
+		trip = &domain.PoolTrip{
+			ID:        uuid.New(),
+			DriverID:  driverID,
+			CreatedAt: now,
+		}
+	}
+	if !trip.HasSeat() {
+		return nil, nil, domain.ErrPoolTripFull
+	}
+	insertion, ok := BestInsertion(start, trip, rider, ride.PickupLocation, ride.DropoffLocation)
+	if !ok {
+		return nil, nil, domain.ErrPoolTripFull
+	}
+
+	trip.Riders = append(trip.Riders, rider)
+	trip.Stops = insertion.Stops
+	trip.UpdatedAt = now
+	p.trips[trip.ID] = trip
+	p.byDriver[driverID] = trip.ID
+	p.byRide[ride.ID] = trip.ID
+	return copyTrip(trip), insertion, nil
+}
+
+// CompleteStop records the driver making a ride's pickup or dropoff. The
+// trip ends with its last dropoff.
+func (p *Pooler) CompleteStop(rideID uuid.UUID, stopType domain.PoolStopType) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	trip := p.trips[p.byRide[rideID]]
+	if trip == nil {
+		return
+	}
+	trip.CompleteStop(rideID, stopType)
+	if stopType == domain.PoolStopDropoff {
+		delete(p.byRide, rideID)
+	}
+	p.endIfEmpty(trip)
+}
+
+// Leave takes a cancelled ride off its pool trip
+func (p *Pooler) Leave(rideID uuid.UUID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	trip := p.trips[p.byRide[rideID]]
+	if trip == nil {
+		return
+	}
+	trip.RemoveRide(rideID)
+	delete(p.byRide, rideID)
+	p.endIfEmpty(trip)
+}
+
+// Trip returns the pool trip a ride is on, or nil
+func (p *Pooler) Trip(rideID uuid.UUID) *domain.PoolTrip {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	trip := p.trips[p.byRide[rideID]]
+	if trip == nil {
+		return nil
+	}
+	return copyTrip(trip)
+}
+
+func (p *Pooler) endIfEmpty(trip *domain.PoolTrip) {
+	if !trip.IsEmpty() {
+		return
+	}
+	delete(p.trips, trip.ID)
+	delete(p.byDriver, trip.DriverID)
+}
+
+// sameDirection reports whether a rider heads the way of every rider on
+// the trip
+func sameDirection(trip *domain.PoolTrip, rider *domain.PoolRider) bool {
+	for _, r := range trip.Riders {
+		if headingDiff(r.HeadingDegrees, rider.HeadingDegrees) > domain.MaxPoolHeadingDiff {
+			return false
+		}
+	}
+	return true
+}
+
+// copyTrip copies a trip so callers can read it without holding the lock
+func copyTrip(trip *domain.PoolTrip) *domain.PoolTrip {
+	c := *trip
+	c.Riders = make([]*domain.PoolRider, len(trip.Riders))
+	for i, r := range trip.Riders {
+		rider := *r
+		c.Riders[i] = &rider
+	}
+	c.Stops = append([]domain.PoolStop(nil), trip.Stops...)
+	return &c
+}
@@ -0,0 +1,132 @@
+package matching
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// Points along a road heading east; 0.01° of longitude is about 1.1 km
+func east(km float64) domain.Location {
+	return domain.Location{Latitude: 6.5, Longitude: 3.3 + km*0.009}
+}
+
+type fakeDriverPool struct {
+	drivers map[uuid.UUID]*domain.Driver
+}
+
+func (p *fakeDriverPool) GetNearbyDrivers(context.Context, float64, float64, float64, domain.RideType) ([]*domain.NearbyDriver, error) {
+	return nil, nil
+}
+
+func (p *fakeDriverPool) GetDriver(_ context.Context, driverID uuid.UUID) (*domain.Driver, error) {
+	if d, ok := p.drivers[driverID]; ok {
+		return d, nil
+	}
+	return nil, domain.ErrDriverNotFound
+}
+
+func (p *fakeDriverPool) LockDriver(context.Context, uuid.UUID, time.Duration) error { return nil }
+func (p *fakeDriverPool) UnlockDriver(context.Context, uuid.UUID) error              { return nil }
+func (p *fakeDriverPool) IsDriverLocked(context.Context, uuid.UUID) bool             { return false }
+
+func poolRide(pickup, dropoff domain.Location) *domain.Ride {
+	return &domain.Ride{ID: uuid.New(), Type: domain.RideTypePool, PickupLocation: pickup, DropoffLocation: dropoff}
+}
+
+func TestBestInsertion(t *testing.T) {
+	first := poolRide(east(1), east(10))
+	pickup := domain.PoolStop{RideID: first.ID, Type: domain.PoolStopPickup, Location: first.PickupLocation}
+	dropoff := domain.PoolStop{RideID: first.ID, Type: domain.PoolStopDropoff, Location: first.DropoffLocation}
+
+	tests := []struct {
+		name        string
+		boarded     bool // the first rider is already in the car
+		ride        *domain.Ride
+		wantOK      bool
+		wantPickup  int
+		wantDropoff int
+	}{
+		{"on the way", false, poolRide(east(3), east(8)), true, 1, 2},
+		{"past the first dropoff", false, poolRide(east(4), east(12)), true, 1, 3},
+		{"back past the start with a rider on board", true, poolRide(east(-6), east(2)), false, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trip := &domain.PoolTrip{
+				Riders: []*domain.PoolRider{newPoolRider(first)},
+				Stops:  []domain.PoolStop{pickup, dropoff},
+			}
+			if tt.boarded {
+				trip.Stops = []domain.PoolStop{dropoff}
+			}
+			insertion, ok := BestInsertion(east(0), trip, newPoolRider(tt.ride), tt.ride.PickupLocation, tt.ride.DropoffLocation)
+			if ok != tt.wantOK {
+				t.Fatalf("BestInsertion() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if insertion.PickupIndex != tt.wantPickup || insertion.DropoffIndex != tt.wantDropoff {
+				t.Errorf("insertion at %d, %d; want %d, %d", insertion.PickupIndex, insertion.DropoffIndex, tt.wantPickup, tt.wantDropoff)
+			}
+			if len(insertion.Stops) != len(trip.Stops)+2 {
+				t.Errorf("stops = %d, want %d", len(insertion.Stops), len(trip.Stops)+2)
+			}
+		})
+	}
+}
+
+func TestPoolerJoinAndLeave(t *testing.T) {
+	driverID := uuid.New()
+	start := east(0)
+	pooler := NewPooler(&fakeDriverPool{drivers: map[uuid.UUID]*domain.Driver{
+		driverID: {ID: driverID, CurrentLocation: &start},
+	}})
+	ctx := context.Background()
+
+	first := poolRide(east(1), east(10))
+	if _, _, err := pooler.Join(ctx, first, driverID); err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+
+	// A rider heading the other way is not offered the trip
+	if _, ok := pooler.FindTrip(ctx, poolRide(east(2), east(-5)), nil); ok {
+		t.Error("FindTrip() matched a ride heading the opposite way")
+	}
+
+	second := poolRide(east(2), east(9))
+	match, ok := pooler.FindTrip(ctx, second, nil)
+	if !ok || match.DriverID != driverID {
+		t.Fatalf("FindTrip() = %+v, %v; want the driver's trip", match, ok)
+	}
+	trip, _, err := pooler.Join(ctx, second, driverID)
+	if err != nil || len(trip.Riders) != 2 || len(trip.Stops) != 4 {
+		t.Fatalf("Join() = %+v, %v; want two riders and four stops", trip, err)
+	}
+
+	third := poolRide(east(3), east(8))
+	if _, _, err := pooler.Join(ctx, third, driverID); err != nil {
+		t.Fatalf("Join() third rider error = %v", err)
+	}
+	if _, _, err := pooler.Join(ctx, poolRide(east(4), east(9)), driverID); !errors.Is(err, domain.ErrPoolTripFull) {
+		t.Errorf("Join() fourth rider error = %v, want ErrPoolTripFull", err)
+	}
+
+	pooler.Leave(second.ID)
+	pooler.CompleteStop(first.ID, domain.PoolStopPickup)
+	pooler.CompleteStop(first.ID, domain.PoolStopDropoff)
+	if trip := pooler.Trip(third.ID); trip == nil || len(trip.Riders) != 1 || len(trip.Stops) != 2 {
+		t.Fatalf("Trip() = %+v, want only the third rider's stops", trip)
+	}
+
+	pooler.CompleteStop(third.ID, domain.PoolStopDropoff)
+	if pooler.IsRunningTrip(driverID) {
+		t.Error("IsRunningTrip() = true after the last dropoff")
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"sort"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/eta"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
 )
 
 const (
@@ -33,6 +36,10 @@ type RideRequest struct {
 	PickupAddress string    `json:"pickup_address"`
 	DropoffAddress string   `json:"dropoff_address"`
 	FareEstimate  float64   `json:"fare_estimate"`
+	DriverEarnings float64  `json:"driver_earnings"` // fare estimate after commission, from the quoted price
+	SurgeMultiplier float64 `json:"surge_multiplier"`
+	PaymentMethod string    `json:"payment_method"`
+	RideType      string    `json:"ride_type"`
 	Currency      string    `json:"currency"`
 	RequestedAt   time.Time `json:"requested_at"`
 	MaxWaitTime   time.Duration `json:"max_wait_time"`
@@ -83,6 +90,7 @@ type RoutingServiceClient interface {
 
 type MatchingService struct {
 	redis           *redis.Client
+	offers          *OfferStream
 	kafka           *kafka.Writer
 	locationClient  LocationServiceClient
 	routingClient   RoutingServiceClient
@@ -103,6 +111,7 @@ func NewMatchingService(
 
 	return &MatchingService{
 		redis:          redisClient,
+		offers:         NewOfferStream(redisClient),
 		kafka:          kafkaWriter,
 		locationClient: locationClient,
 		routingClient:  routingClient,
@@ -112,7 +121,8 @@ func NewMatchingService(
 
 // FindMatch finds and dispatches to the best available driver
 func (s *MatchingService) FindMatch(ctx context.Context, request *RideRequest) (*MatchResult, error) {
-	log.Printf("[Matching] Starting match for request %s", request.RequestID)
+	logger := matchingLog.With().Str("ride_id", request.RequestID).Logger()
+	logger.Info().Msg("Starting match")
 
 	// Store ride request
 	if err := s.storeRideRequest(ctx, request); err != nil {
@@ -123,7 +133,7 @@ func (s *MatchingService) FindMatch(ctx context.Context, request *RideRequest) (
 	radius := InitialRadius
 
 	for radius <= MaxSearchRadius {
-		log.Printf("[Matching] Searching with radius %.1f km", radius)
+		logger.Debug().Float64("radius_km", radius).Msg("Searching for drivers")
 
 		// Find available drivers
 		drivers, err := s.locationClient.FindNearbyDrivers(
@@ -137,7 +147,7 @@ func (s *MatchingService) FindMatch(ctx context.Context, request *RideRequest) (
 			return nil, fmt.Errorf("failed to find nearby drivers: %w", err)
 		}
 
-		log.Printf("[Matching] Found %d drivers within %.1f km", len(drivers), radius)
+		logger.Debug().Int("drivers", len(drivers)).Float64("radius_km", radius).Msg("Found nearby drivers")
 
 		if len(drivers) == 0 {
 			radius += RadiusIncrement
@@ -152,12 +162,15 @@ func (s *MatchingService) FindMatch(ctx context.Context, request *RideRequest) (
 
 		// Try to dispatch to best drivers
 		for _, scored := range scoredDrivers {
-			log.Printf("[Matching] Attempting dispatch to driver %s (score: %.2f, distance: %.2f km)",
-				scored.Driver.DriverID, scored.Score, scored.Distance)
+			driverLog := logger.With().Str("driver_id", scored.Driver.DriverID).Logger()
+			driverLog.Debug().
+				Float64("score", scored.Score).
+				Float64("distance_km", scored.Distance).
+				Msg("Attempting dispatch")
 
-			accepted, err := s.dispatchToDriver(ctx, request, scored.Driver)
+			accepted, err := s.dispatchToDriver(ctx, request, scored)
 			if err != nil {
-				log.Printf("[Matching] Dispatch error for driver %s: %v", scored.Driver.DriverID, err)
+				driverLog.Warn().Err(err).Msg("Dispatch failed")
 				continue
 			}
 
@@ -173,20 +186,19 @@ func (s *MatchingService) FindMatch(ctx context.Context, request *RideRequest) (
 				// Publish match event
 				s.publishMatchEvent(ctx, result)
 
-				log.Printf("[Matching] Successfully matched request %s to driver %s",
-					request.RequestID, scored.Driver.DriverID)
+				driverLog.Info().Msg("Matched driver")
 
 				return result, nil
 			}
 
-			log.Printf("[Matching] Driver %s rejected request", scored.Driver.DriverID)
+			driverLog.Debug().Msg("Driver rejected request")
 		}
 
 		// No drivers accepted, expand search
 		radius += RadiusIncrement
 	}
 
-	log.Printf("[Matching] No drivers found for request %s within %.1f km", request.RequestID, MaxSearchRadius)
+	logger.Info().Float64("radius_km", MaxSearchRadius).Msg("No drivers found")
 	return nil, ErrNoDriversAvailable
 }
 
@@ -206,7 +218,7 @@ func (s *MatchingService) scoreDrivers(
 			request.PickupLat, request.PickupLng,
 		)
 		if err != nil {
-			log.Printf("Failed to get ETA for driver %s: %v", driver.DriverID, err)
+			matchingLog.Warn().Err(err).Str("driver_id", driver.DriverID).Msg("Failed to get ETA for driver")
 			continue
 		}
 
@@ -262,8 +274,10 @@ func (s *MatchingService) calculateScore(
 func (s *MatchingService) dispatchToDriver(
 	ctx context.Context,
 	request *RideRequest,
-	driver *DriverLocation,
+	scored ScoredDriver,
 ) (bool, error) {
+	driver := scored.Driver
+
 	// Generate dispatch ID
 	dispatchID := fmt.Sprintf("dispatch_%d", time.Now().UnixNano())
 
@@ -283,7 +297,7 @@ func (s *MatchingService) dispatchToDriver(
 	}
 
 	// Send to driver via WebSocket (through real-time gateway)
-	if err := s.sendDispatchToDriver(ctx, dispatch, request); err != nil {
+	if err := s.sendDispatchToDriver(ctx, dispatch, request, scored); err != nil {
 		return false, fmt.Errorf("failed to send dispatch: %w", err)
 	}
 
@@ -329,26 +343,62 @@ func (s *MatchingService) sendDispatchToDriver(
 	ctx context.Context,
 	dispatch *Dispatch,
 	request *RideRequest,
+	scored ScoredDriver,
 ) error {
-	// This would call the real-time gateway's broadcast endpoint
+	// This would call the real-time gateway's broadcast endpoint. The
+	// payload is the standard driver offer, with the legacy dispatch fields
+	// kept for older driver apps.
 	message := map[string]interface{}{
 		"type": "dispatch_request",
-		"payload": map[string]interface{}{
-			"dispatch_id":      dispatch.ID,
-			"request_id":       request.RequestID,
-			"pickup_address":   request.PickupAddress,
-			"pickup_lat":       request.PickupLat,
-			"pickup_lng":       request.PickupLng,
-			"dropoff_address":  request.DropoffAddress,
-			"fare_estimate":    request.FareEstimate,
-			"currency":         request.Currency,
-			"expires_in":       int(DispatchTimeout.Seconds()),
+		"payload": struct {
+			DispatchID     string  `json:"dispatch_id"`
+			RequestID      string  `json:"request_id"`
+			PickupAddress  string  `json:"pickup_address"`
+			PickupLat      float64 `json:"pickup_lat"`
+			PickupLng      float64 `json:"pickup_lng"`
+			DropoffAddress string  `json:"dropoff_address"`
+			*domain.RideOffer
+		}{
+			DispatchID:     dispatch.ID,
+			RequestID:      request.RequestID,
+			PickupAddress:  request.PickupAddress,
+			PickupLat:      request.PickupLat,
+			PickupLng:      request.PickupLng,
+			DropoffAddress: request.DropoffAddress,
+			RideOffer:      request.offer(scored),
 		},
 	}
 
-	// Publish to Redis channel for real-time gateway
+	// Queue on the driver's offer stream for the real-time gateway, which
+	// replays it if the driver's socket is reconnecting
 	data, _ := json.Marshal(message)
-	return s.redis.Publish(ctx, fmt.Sprintf("user:%s", dispatch.DriverID), data).Err()
+	_, err := s.offers.Send(ctx, dispatch.DriverID, data, time.Until(dispatch.ExpiresAt))
+	return err
+}
+
+// offer builds the standard driver offer for a legacy request. The legacy
+// path has no pricing engine, so the fare and earnings are those quoted
+// with the request.
+func (r *RideRequest) offer(scored ScoredDriver) *domain.RideOffer {
+	rideID, _ := uuid.Parse(r.RequestID)
+	tripDistance := geo.HaversineDistance(r.PickupLat, r.PickupLng, r.DropoffLat, r.DropoffLng)
+
+	ride := &domain.Ride{
+		ID:              rideID,
+		Type:            domain.RideType(r.RideType),
+		PaymentMethod:   domain.PaymentMethod(r.PaymentMethod),
+		PickupLocation:  domain.Location{Latitude: r.PickupLat, Longitude: r.PickupLng, Address: r.PickupAddress},
+		DropoffLocation: domain.Location{Latitude: r.DropoffLat, Longitude: r.DropoffLng, Address: r.DropoffAddress},
+	}
+	price := &domain.PriceBreakdown{
+		Total:           int64(r.FareEstimate),
+		DriverEarnings:  int64(r.DriverEarnings),
+		Currency:        domain.Currency(r.Currency),
+		SurgeMultiplier: r.SurgeMultiplier,
+	}
+
+	return domain.NewRideOffer(ride, scored.Distance*1000, int64(scored.ETA.Seconds()),
+		tripDistance, geo.EstimateETA(tripDistance, string(eta.ProfileForRideType(ride.Type))), price, DispatchTimeout)
 }
 
 type DispatchResponse struct {
@@ -426,7 +476,7 @@ func (s *MatchingService) calculateHeadingBonus(driver *DriverLocation, request
 func (s *MatchingService) publishMatchEvent(ctx context.Context, result *MatchResult) {
 	data, err := json.Marshal(result)
 	if err != nil {
-		log.Printf("Failed to marshal match event: %v", err)
+		matchingLog.Error().Err(err).Str("ride_id", result.RequestID).Msg("Failed to marshal match event")
 		return
 	}
 
@@ -435,7 +485,7 @@ func (s *MatchingService) publishMatchEvent(ctx context.Context, result *MatchRe
 		Value: data,
 	})
 	if err != nil {
-		log.Printf("Failed to publish match event to Kafka: %v", err)
+		matchingLog.Error().Err(err).Str("ride_id", result.RequestID).Msg("Failed to publish match event to Kafka")
 	}
 }
 
@@ -0,0 +1,204 @@
+package money
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/logging"
+)
+
+const defaultRatesURL = "https://openexchangerates.org/api"
+
+// RateProvider fetches the latest exchange rates
+type RateProvider interface {
+	Latest(ctx context.Context) (*domain.ExchangeRates, error)
+}
+
+// HTTPProvider reads rates from an Open Exchange Rates compatible API
+type HTTPProvider struct {
+	baseURL    string
+	appID      string
+	httpClient *http.Client
+}
+
+// ProviderConfig holds configuration for the rate provider
+type ProviderConfig struct {
+	BaseURL string // defaults to the public Open Exchange Rates API
+	AppID   string
+	Timeout time.Duration
+}
+
+// NewHTTPProvider creates a new rate provider
+func NewHTTPProvider(config ProviderConfig) *HTTPProvider {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultRatesURL
+	}
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &HTTPProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		appID:      config.AppID,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+type latestResponse struct {
+	Timestamp int64              `json:"timestamp"`
+	Base      string             `json:"base"`
+	Rates     map[string]float64 `json:"rates"`
+}
+
+// Latest gets the provider's current USD rates for the currencies we
+// operate in
+func (p *HTTPProvider) Latest(ctx context.Context) (*domain.ExchangeRates, error) {
+	symbols := make([]string, 0, len(domain.ReferenceRatesPerUSD))
+	for currency := range domain.ReferenceRatesPerUSD {
+		symbols = append(symbols, string(currency))
+	}
+
+	params := url.Values{}
+	params.Set("app_id", p.appID)
+	params.Set("base", string(domain.CurrencyUSD))
+	params.Set("symbols", strings.Join(symbols, ","))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/latest.json?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("exchange rate request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchange rate provider returned status %d", resp.StatusCode)
+	}
+
+	var body latestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode exchange rates: %w", err)
+	}
+	if body.Base != "" && body.Base != string(domain.CurrencyUSD) {
+		return nil, fmt.Errorf("exchange rates are based on %s, want USD", body.Base)
+	}
+
+	rates := &domain.ExchangeRates{
+		PerUSD: map[domain.Currency]float64{domain.CurrencyUSD: 1},
+		AsOf:   time.Unix(body.Timestamp, 0).UTC(),
+		Source: "openexchangerates",
+	}
+	for code, rate := range body.Rates {
+		currency := domain.Currency(code)
+		if _, ok := domain.ReferenceRatesPerUSD[currency]; ok && rate > 0 {
+			rates.PerUSD[currency] = rate
+		}
+	}
+	return rates, nil
+}
+
+var fxLog = logging.For("fx")
+
+// ratesKeyPrefix prefixes the Redis key holding a day's rates, so every
+// replica converts at the same rates and the provider is asked once a day
+const ratesKeyPrefix = "fx:rates:"
+
+// RateCache holds the day's exchange rates. Conversions read whatever it
+// holds; Refresh replaces it once a new day's rates are available. Until
+// the first refresh succeeds it serves the reference rates.
+type RateCache struct {
+	provider RateProvider
+	redis    *goredis.Client
+	now      func() time.Time
+
+	mu    sync.RWMutex
+	rates *domain.ExchangeRates
+	day   string
+}
+
+// NewRateCache creates a rate cache. provider and redis may be nil, in
+// which case it keeps serving the reference rates or only this replica's
+// fetches respectively.
+func NewRateCache(provider RateProvider, redis *goredis.Client) *RateCache {
+	return &RateCache{
+		provider: provider,
+		redis:    redis,
+		now:      time.Now,
+		rates:    ReferenceRates(),
+	}
+}
+
+// Rates returns the rates currently held
+func (c *RateCache) Rates() *domain.ExchangeRates {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.rates
+}
+
+// Refresh loads today's rates unless they are already held, from Redis if
+// another replica fetched them and from the provider otherwise. On failure
+// the rates held are kept, however old.
+func (c *RateCache) Refresh(ctx context.Context) error {
+	day := c.now().UTC().Format("2006-01-02")
+
+	c.mu.RLock()
+	current := c.day == day
+	c.mu.RUnlock()
+	if current || c.provider == nil {
+		return nil
+	}
+
+	key := ratesKeyPrefix + day
+	if c.redis != nil {
+		data, err := c.redis.Get(ctx, key).Bytes()
+		if err == nil {
+			var rates domain.ExchangeRates
+			if err := json.Unmarshal(data, &rates); err == nil {
+				c.set(day, &rates)
+				return nil
+			}
+		} else if err != goredis.Nil {
+			fxLog.Warn().Err(err).Msg("Failed to read cached exchange rates")
+		}
+	}
+
+	rates, err := c.provider.Latest(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch exchange rates: %w", err)
+	}
+	c.set(day, rates)
+
+	if c.redis != nil {
+		data, _ := json.Marshal(rates)
+		if err := c.redis.Set(ctx, key, data, 48*time.Hour).Err(); err != nil {
+			fxLog.Warn().Err(err).Msg("Failed to cache exchange rates")
+		}
+	}
+
+	fxLog.Info().
+		Str("day", day).
+		Str("source", rates.Source).
+		Time("as_of", rates.AsOf).
+		Msg("Exchange rates refreshed")
+	return nil
+}
+
+func (c *RateCache) set(day string, rates *domain.ExchangeRates) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rates = rates
+	c.day = day
+}
@@ -0,0 +1,85 @@
+// Package money converts amounts between currencies so riders can read a
+// fare in a currency they know. Rides are priced, charged, refunded and
+// paid out in the currency of the city they happen in; a converted amount
+// is only ever shown, never settled.
+package money
+
+import (
+	"math"
+	"strings"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/pricing"
+)
+
+// SourceReference marks the built-in indicative rates
+const SourceReference = "reference"
+
+// ReferenceRates returns the built-in indicative rates, for when no rate
+// provider has answered
+func ReferenceRates() *domain.ExchangeRates {
+	perUSD := make(map[domain.Currency]float64, len(domain.ReferenceRatesPerUSD))
+	for currency, rate := range domain.ReferenceRatesPerUSD {
+		perUSD[currency] = rate
+	}
+	return &domain.ExchangeRates{PerUSD: perUSD, Source: SourceReference}
+}
+
+// ParseCurrency reads a client-supplied currency code, returning
+// ErrUnsupportedCurrency for currencies we hold no rate for
+func ParseCurrency(code string) (domain.Currency, error) {
+	currency := domain.Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if _, ok := domain.ReferenceRatesPerUSD[currency]; !ok {
+		return "", domain.ErrUnsupportedCurrency
+	}
+	return currency, nil
+}
+
+// Rate returns units of to per unit of from
+func Rate(from, to domain.Currency, rates *domain.ExchangeRates) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	if rates == nil {
+		return 0, domain.ErrUnsupportedCurrency
+	}
+	fromRate, ok := rates.PerUSD[from]
+	if !ok || fromRate <= 0 {
+		return 0, domain.ErrUnsupportedCurrency
+	}
+	toRate, ok := rates.PerUSD[to]
+	if !ok || toRate <= 0 {
+		return 0, domain.ErrUnsupportedCurrency
+	}
+	return toRate / fromRate, nil
+}
+
+// Convert converts minor units between currencies, rounding to the
+// nearest unit
+func Convert(amount int64, from, to domain.Currency, rates *domain.ExchangeRates) (int64, error) {
+	rate, err := Rate(from, to, rates)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Round(float64(amount) * rate)), nil
+}
+
+// Display converts a settlement amount for display. It returns nil when
+// the two currencies are the same, since there is nothing to convert.
+func Display(amount int64, from, to domain.Currency, rates *domain.ExchangeRates) (*domain.DisplayAmount, error) {
+	if from == to || to == "" {
+		return nil, nil
+	}
+	rate, err := Rate(from, to, rates)
+	if err != nil {
+		return nil, err
+	}
+	converted := int64(math.Round(float64(amount) * rate))
+	return &domain.DisplayAmount{
+		Amount:    converted,
+		Currency:  to,
+		Formatted: pricing.FormatPrice(converted, to),
+		Rate:      rate,
+		RatesAsOf: rates.AsOf,
+	}, nil
+}
@@ -0,0 +1,112 @@
+package money
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+var testRates = &domain.ExchangeRates{
+	PerUSD: map[domain.Currency]float64{
+		domain.CurrencyUSD: 1,
+		domain.CurrencyNGN: 1500,
+		domain.CurrencyGHS: 15,
+	},
+	AsOf: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		from, to domain.Currency
+		want     int64
+		wantErr  error
+	}{
+		{"same currency", 150000, domain.CurrencyNGN, domain.CurrencyNGN, 150000, nil},
+		{"naira to cedi", 1500000, domain.CurrencyNGN, domain.CurrencyGHS, 15000, nil},
+		{"cedi to naira", 2550, domain.CurrencyGHS, domain.CurrencyNGN, 255000, nil},
+		{"rounds to the nearest unit", 1, domain.CurrencyNGN, domain.CurrencyUSD, 0, nil},
+		{"no rate", 1000, domain.CurrencyNGN, domain.CurrencyKES, 0, domain.ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.amount, tt.from, tt.to, testRates)
+			if err != tt.wantErr {
+				t.Fatalf("Convert() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Convert() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	display, err := Display(1500000, domain.CurrencyNGN, domain.CurrencyGHS, testRates)
+	if err != nil {
+		t.Fatalf("Display() error = %v", err)
+	}
+	if display.Amount != 15000 || display.Currency != domain.CurrencyGHS || display.Formatted != "GH₵150.00" || !display.RatesAsOf.Equal(testRates.AsOf) {
+		t.Errorf("Display() = %+v", display)
+	}
+
+	if display, _ := Display(1500000, domain.CurrencyNGN, domain.CurrencyNGN, testRates); display != nil {
+		t.Errorf("Display() in the settlement currency = %+v, want nil", display)
+	}
+}
+
+func TestParseCurrency(t *testing.T) {
+	if got, err := ParseCurrency(" ghs "); err != nil || got != domain.CurrencyGHS {
+		t.Errorf("ParseCurrency(\" ghs \") = %s, %v", got, err)
+	}
+	if _, err := ParseCurrency("EUR"); err != domain.ErrUnsupportedCurrency {
+		t.Errorf("ParseCurrency(\"EUR\") error = %v, want ErrUnsupportedCurrency", err)
+	}
+}
+
+type stubProvider struct {
+	rates *domain.ExchangeRates
+	err   error
+	calls int
+}
+
+func (p *stubProvider) Latest(ctx context.Context) (*domain.ExchangeRates, error) {
+	p.calls++
+	return p.rates, p.err
+}
+
+func TestRateCacheRefresh(t *testing.T) {
+	provider := &stubProvider{err: errors.New("provider down")}
+	cache := NewRateCache(provider, nil)
+	day := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return day }
+
+	// A failed fetch keeps serving the reference rates
+	if err := cache.Refresh(context.Background()); err == nil {
+		t.Fatal("Refresh() succeeded with the provider down")
+	}
+	if cache.Rates().Source != SourceReference {
+		t.Errorf("Rates().Source = %q, want reference", cache.Rates().Source)
+	}
+
+	provider.rates, provider.err = testRates, nil
+	if err := cache.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if cache.Rates() != testRates {
+		t.Errorf("Rates() = %+v, want the provider's", cache.Rates())
+	}
+
+	// Rates are fetched once a day
+	cache.Refresh(context.Background())
+	day = day.Add(24 * time.Hour)
+	cache.Refresh(context.Background())
+	if provider.calls != 3 {
+		t.Errorf("provider called %d times, want 3", provider.calls)
+	}
+}
@@ -0,0 +1,98 @@
+// Package notification provides a client for the notification service.
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Push priorities accepted by the notification service
+const (
+	PriorityNormal = "NORMAL"
+	PriorityHigh   = "HIGH"
+)
+
+// Client calls the notification service's internal API
+type Client struct {
+	baseURL    string
+	serviceKey string
+	httpClient *http.Client
+}
+
+// ClientConfig holds configuration for the notification client
+type ClientConfig struct {
+	BaseURL    string
+	ServiceKey string
+	Timeout    time.Duration
+}
+
+// NewClient creates a new notification service client
+func NewClient(config ClientConfig) *Client {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		serviceKey: config.ServiceKey,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+type apiResponse struct {
+	Success bool `json:"success"`
+	Error   *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// SendPush sends a push notification to all of a user's devices. data["type"]
+// is logged by the notification service as the notification type.
+func (c *Client) SendPush(ctx context.Context, userID uuid.UUID, title, body, priority string, data map[string]string) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"userId":   userID.String(),
+		"title":    title,
+		"body":     body,
+		"priority": priority,
+		"data":     data,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/push/send", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Service-Key", c.serviceKey)
+	req.Header.Set("X-Service-Name", "ride-service")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("notification service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("failed to decode notification service response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 || !apiResp.Success {
+		if apiResp.Error != nil {
+			return fmt.Errorf("notification service error %s: %s", apiResp.Error.Code, apiResp.Error.Message)
+		}
+		return fmt.Errorf("notification service returned status %d", resp.StatusCode)
+	}
+	return nil
+}
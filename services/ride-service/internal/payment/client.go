@@ -0,0 +1,176 @@
+// Package payment provides a client for the payment service.
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// Client calls the payment service's internal API
+type Client struct {
+	baseURL    string
+	serviceKey string
+	httpClient *http.Client
+}
+
+// ClientConfig holds configuration for the payment client
+type ClientConfig struct {
+	BaseURL    string
+	ServiceKey string
+	Timeout    time.Duration
+}
+
+// NewClient creates a new payment service client
+func NewClient(config ClientConfig) *Client {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 15 * time.Second
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		serviceKey: config.ServiceKey,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// RefundResult is the payment service's response to a refund or credit
+type RefundResult struct {
+	RefundID string `json:"refundId"`
+	Status   string `json:"status"`
+}
+
+// Payment is a payment captured by the payment service
+type Payment struct {
+	ID          string `json:"id"`
+	ReferenceID string `json:"referenceId"`
+	Status      string `json:"status"`
+	Method      string `json:"method"`
+}
+
+// ErrNotFound is returned when the payment service has no such resource
+var ErrNotFound = errors.New("payment service resource not found")
+
+type apiResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// RefundPayment refunds part or all of a captured payment.
+// Amounts are minor units; the payment service API takes major units.
+func (c *Client) RefundPayment(ctx context.Context, paymentID string, amount int64, currency domain.Currency, reason string) (*RefundResult, error) {
+	body := map[string]interface{}{
+		"amount": toMajorUnits(amount),
+		"reason": reason,
+	}
+
+	var result RefundResult
+	if err := c.post(ctx, fmt.Sprintf("/payments/%s/refund", paymentID), body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// IssueCredit credits a rider's wallet with a platform-funded goodwill amount
+func (c *Client) IssueCredit(ctx context.Context, userID string, amount int64, currency domain.Currency, reference, reason string) (*RefundResult, error) {
+	body := map[string]interface{}{
+		"userId":      userID,
+		"amount":      toMajorUnits(amount),
+		"currency":    currency,
+		"reference":   reference,
+		"description": reason,
+	}
+
+	var result RefundResult
+	if err := c.post(ctx, "/wallets/credits", body, &result); err != nil {
+		return nil, err
+	}
+	if result.RefundID == "" {
+		result.RefundID = reference
+	}
+	return &result, nil
+}
+
+// CapturedPayment gets the payment captured for a ride, nil when the ride
+// was not paid through the payment service (e.g. cash)
+func (c *Client) CapturedPayment(ctx context.Context, referenceID string) (*Payment, error) {
+	var result Payment
+	err := c.get(ctx, "/payments/by-reference/"+url.PathEscape(referenceID), &result)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	return c.do(req, out)
+}
+
+func (c *Client) post(ctx context.Context, path string, body interface{}, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, out)
+}
+
+func (c *Client) do(req *http.Request, out interface{}) error {
+	req.Header.Set("X-Service-Key", c.serviceKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("payment service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("failed to decode payment service response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 || !apiResp.Success {
+		if apiResp.Error != nil {
+			return fmt.Errorf("payment service error %s: %s", apiResp.Error.Code, apiResp.Error.Message)
+		}
+		return fmt.Errorf("payment service returned status %d", resp.StatusCode)
+	}
+
+	if out != nil && len(apiResp.Data) > 0 {
+		return json.Unmarshal(apiResp.Data, out)
+	}
+	return nil
+}
+
+func toMajorUnits(amount int64) float64 {
+	return float64(amount) / 100
+}
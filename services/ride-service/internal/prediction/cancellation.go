@@ -0,0 +1,51 @@
+package prediction
+
+import (
+	"math"
+	"time"
+)
+
+// Rider cancellation model features
+const (
+	FeatureWaitMinutes     = "wait_minutes"      // time spent searching for a driver
+	FeatureSurgeExcess     = "surge_excess"      // surge multiplier above 1x
+	FeatureRiderCancelRate = "rider_cancel_rate" // share of the rider's recent requests they cancelled
+	FeatureRiderNew        = "rider_new"         // 1 when the rider has too few recent requests to judge
+)
+
+// riderHistoryMinRides is the recent requests below which a rider's cancel
+// rate is too noisy to use
+const riderHistoryMinRides = 3
+
+// NewRiderCancellationModel returns the model estimating the chance a rider
+// cancels while waiting to be matched. The coefficients are a hand-tuned
+// baseline: a rider with no history and no surge is at about 10% after two
+// minutes and about 50% after eight.
+func NewRiderCancellationModel() *LogisticModel {
+	return &LogisticModel{
+		Name:      "rider_cancellation",
+		Version:   "baseline-1",
+		Intercept: -3.2,
+		Weights: map[string]float64{
+			FeatureWaitMinutes:     0.366,
+			FeatureSurgeExcess:     1.2,
+			FeatureRiderCancelRate: 2.5,
+			FeatureRiderNew:        0.3,
+		},
+	}
+}
+
+// RiderCancellationFeatures builds the cancellation model's features from a
+// ride's wait and surge and the rider's recent requests and cancellations
+func RiderCancellationFeatures(wait time.Duration, surge float64, recentRides, recentCancels int) Features {
+	f := Features{
+		FeatureWaitMinutes: wait.Minutes(),
+		FeatureSurgeExcess: math.Max(surge-1, 0),
+	}
+	if recentRides < riderHistoryMinRides {
+		f[FeatureRiderNew] = 1
+	} else {
+		f[FeatureRiderCancelRate] = float64(recentCancels) / float64(recentRides)
+	}
+	return f
+}
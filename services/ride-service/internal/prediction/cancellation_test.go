@@ -0,0 +1,35 @@
+package prediction
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRiderCancellationModel(t *testing.T) {
+	model := NewRiderCancellationModel()
+
+	newRider := func(wait time.Duration) float64 {
+		return model.Predict(RiderCancellationFeatures(wait, 1, 0, 0))
+	}
+	if p := newRider(2 * time.Minute); math.Abs(p-0.1) > 0.02 {
+		t.Errorf("new rider after 2m = %.3f, want about 0.10", p)
+	}
+	if p := newRider(8 * time.Minute); math.Abs(p-0.5) > 0.05 {
+		t.Errorf("new rider after 8m = %.3f, want about 0.50", p)
+	}
+
+	base := model.Predict(RiderCancellationFeatures(5*time.Minute, 1, 20, 2))
+	if surged := model.Predict(RiderCancellationFeatures(5*time.Minute, 1.8, 20, 2)); surged <= base {
+		t.Errorf("surge should raise the probability: %.3f <= %.3f", surged, base)
+	}
+	if habitual := model.Predict(RiderCancellationFeatures(5*time.Minute, 1, 20, 12)); habitual <= base {
+		t.Errorf("a history of cancelling should raise the probability: %.3f <= %.3f", habitual, base)
+	}
+
+	// Too little history to judge falls back to the new-rider feature
+	f := RiderCancellationFeatures(time.Minute, 1, 2, 2)
+	if f[FeatureRiderCancelRate] != 0 || f[FeatureRiderNew] != 1 {
+		t.Errorf("features with 2 recent rides = %v, want new rider", f)
+	}
+}
@@ -0,0 +1,36 @@
+// Package prediction scores outcomes with small, explainable models that run
+// in-process on features the service already has.
+package prediction
+
+import "math"
+
+// Features are a model's named inputs
+type Features map[string]float64
+
+// LogisticModel is a logistic regression over named features. Features
+// without a weight are ignored; weights without a feature count as zero.
+type LogisticModel struct {
+	Name      string             `json:"name"`
+	Version   string             `json:"version"`
+	Intercept float64            `json:"intercept"`
+	Weights   map[string]float64 `json:"weights"`
+}
+
+// Predict returns the modelled probability for the features
+func (m *LogisticModel) Predict(f Features) float64 {
+	z := m.Intercept
+	for _, contribution := range m.Contributions(f) {
+		z += contribution
+	}
+	return 1 / (1 + math.Exp(-z))
+}
+
+// Contributions returns each weighted feature's contribution to the
+// log-odds, for explaining a prediction
+func (m *LogisticModel) Contributions(f Features) map[string]float64 {
+	contributions := make(map[string]float64, len(m.Weights))
+	for name, weight := range m.Weights {
+		contributions[name] = weight * f[name]
+	}
+	return contributions
+}
@@ -2,9 +2,15 @@
 package pricing
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"math"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
 )
 
@@ -21,10 +27,18 @@ type PricingConfig struct {
 
 	// Minimum fare by ride type
 	MinFares map[domain.RideType]int64
+	
+	// Per minute rate for waiting at pickup by ride type, and the minutes
+	// the driver waits free; ride types without a wait rate are not billed
+	WaitPerMinuteRates map[domain.RideType]int64
+	FreeWaitMinutes    map[domain.RideType]int
 
 	// Booking fee (platform fee)
 	BookingFee int64
 
+	// Fee per stop on the way to the dropoff
+	StopFee int64
+
 	// Commission percentage (platform takes)
 	CommissionPercent float64
 
@@ -50,17 +64,63 @@ type SurgeConfig struct {
 	DecayRatePerMinute float64
 }
 
+// GlutDiscountConfig holds configuration for discounting cells where idle
+// drivers far outnumber requests. It is kept apart from SurgeConfig so the
+// discount can be tuned or switched off without touching surge.
+type GlutDiscountConfig struct {
+	// Idle drivers in cell before a discount kicks in
+	MinIdleDrivers int
+
+	// Demand/supply ratio at or below which the cell counts as a glut
+	DemandSupplyThreshold float64
+
+	// Discount added per idle driver above the threshold
+	DiscountStep float64
+
+	// Lowest multiplier a discount can reach, e.g. 0.85 for at most 15% off.
+	// 1 or more disables the discount.
+	FloorMultiplier float64
+
+	// Largest change to a cell's discount per update
+	MaxStepChange float64
+}
+
 // Engine is the main pricing engine
 type Engine struct {
 	configs      map[domain.Currency]*PricingConfig
+	versions     map[domain.Currency]string // currency -> hash of its default config
 	surgeConfig  *SurgeConfig
+	glutConfig   *GlutDiscountConfig
 	surgeCache   map[string]*SurgeData // H3 cell -> surge data
+	
+	cityMu       sync.RWMutex
+	cityConfigs  map[string]*cityPricing // city code -> pricing from its bundle
+	weatherSurge map[string]float64      // city code -> surge added for the weather
+	surgeOff     map[string]bool         // city codes with surge switched off
+	marketControls map[domain.Currency]domain.PriceControls // currency -> strictest controls of its cities
+	
+	violations   ViolationRecorder
+}
+
+// cityPricing is a city's fares plus its regulatory price controls
+type cityPricing struct {
+	config   *PricingConfig
+	controls domain.PriceControls
+	enabled  bool
+	version  string
+}
+
+// ViolationRecorder receives fares the engine clamped to a market's price
+// controls, for the compliance report. It must not block.
+type ViolationRecorder interface {
+	RecordPriceControlViolation(v *domain.PriceControlViolation)
 }
 
 // SurgeData holds surge pricing data for a cell
 type SurgeData struct {
 	Cell            string
 	Multiplier      float64
+	Discount        float64 // glut discount multiplier, 1 when the cell is not discounted
 	ActiveDrivers   int
 	PendingRequests int
 	LastUpdated     time.Time
@@ -68,11 +128,254 @@ type SurgeData struct {
 
 // NewEngine creates a new pricing engine with default configurations
 func NewEngine() *Engine {
+	configs := getDefaultConfigs()
+	versions := make(map[domain.Currency]string, len(configs))
+	for currency, config := range configs {
+		versions[currency] = configVersion(config)
+	}
+	
 	return &Engine{
-		configs:     getDefaultConfigs(),
+		configs:     configs,
+		versions:    versions,
 		surgeConfig: getDefaultSurgeConfig(),
+		glutConfig:  DefaultGlutDiscountConfig(),
 		surgeCache:  make(map[string]*SurgeData),
+		cityConfigs: make(map[string]*cityPricing),
+		weatherSurge: make(map[string]float64),
+		surgeOff:     make(map[string]bool),
+		marketControls: make(map[domain.Currency]domain.PriceControls),
+	}
+}
+
+// SetViolationRecorder sets where clamped fares are reported. Call before
+// serving traffic.
+func (e *Engine) SetViolationRecorder(recorder ViolationRecorder) {
+	e.violations = recorder
+}
+
+// SetGlutDiscountConfig replaces the glut discount settings. Call before
+// serving traffic.
+func (e *Engine) SetGlutDiscountConfig(config *GlutDiscountConfig) {
+	e.glutConfig = config
+}
+
+// ConfigFromCity converts a city bundle's fares into a pricing config
+func ConfigFromCity(city *domain.CityConfig) *PricingConfig {
+	config := &PricingConfig{
+		BaseFares:         make(map[domain.RideType]int64),
+		PerKmRates:        make(map[domain.RideType]int64),
+		PerMinuteRates:    make(map[domain.RideType]int64),
+		MinFares:          make(map[domain.RideType]int64),
+		WaitPerMinuteRates: make(map[domain.RideType]int64),
+		FreeWaitMinutes:   make(map[domain.RideType]int),
+		BookingFee:        city.Pricing.BookingFee,
+		StopFee:           city.Pricing.StopFee,
+		CommissionPercent: city.Pricing.CommissionPercent,
+		Currency:          city.Currency,
+	}
+	
+	for rideType, fares := range city.Pricing.RideTypes {
+		config.BaseFares[rideType] = fares.BaseFare
+		config.PerKmRates[rideType] = fares.PerKmRate
+		config.PerMinuteRates[rideType] = fares.PerMinuteRate
+		config.MinFares[rideType] = fares.MinFare
+		config.WaitPerMinuteRates[rideType] = fares.WaitPerMinuteRate
+		config.FreeWaitMinutes[rideType] = fares.FreeWaitMinutes
+	}
+	
+	return config
+}
+
+// SetCityConfig registers pricing from a city bundle, replacing any previous
+// pricing for that city. Rides priced by currency alone get the strictest
+// controls of the enabled cities using that currency.
+func (e *Engine) SetCityConfig(city *domain.CityConfig) {
+	e.cityMu.Lock()
+	defer e.cityMu.Unlock()
+	
+	config := ConfigFromCity(city)
+	e.cityConfigs[city.Code] = &cityPricing{
+		config:   config,
+		controls: city.Regulatory.PriceControls,
+		enabled:  city.Enabled,
+		version:  configVersion(struct {
+			Config   *PricingConfig
+			Controls domain.PriceControls
+		}{config, city.Regulatory.PriceControls}),
+	}
+	
+	var controls domain.PriceControls
+	for _, c := range e.cityConfigs {
+		if c.enabled && c.config.Currency == city.Currency {
+			controls = controls.Strictest(c.controls)
+		}
+	}
+	e.marketControls[city.Currency] = controls
+}
+
+// SetWeatherSurge sets what the city's weather adds to its surge
+// multipliers, 0 to clear it. Like demand surge it applies only to fares
+// priced in a cell.
+func (e *Engine) SetWeatherSurge(cityCode string, add float64) {
+	e.cityMu.Lock()
+	defer e.cityMu.Unlock()
+	
+	if add <= 0 {
+		delete(e.weatherSurge, cityCode)
+		return
+	}
+	e.weatherSurge[cityCode] = add
+}
+
+// SetSurgeDisabled switches demand and weather surge off or back on in a
+// city. Fares there are priced at a multiplier of 1 while it is off.
+func (e *Engine) SetSurgeDisabled(cityCode string, disabled bool) {
+	e.cityMu.Lock()
+	defer e.cityMu.Unlock()
+	
+	if !disabled {
+		delete(e.surgeOff, cityCode)
+		return
+	}
+	e.surgeOff[cityCode] = true
+}
+
+// SurgeDisabled reports whether surge is switched off in a city
+func (e *Engine) SurgeDisabled(cityCode string) bool {
+	e.cityMu.RLock()
+	defer e.cityMu.RUnlock()
+	return e.surgeOff[cityCode]
+}
+
+// CalculateCityPrice prices a ride with a city's bundle and price controls.
+// Rides with stops pass their route legs, which replace the distance and
+// duration (see CalculatePrice).
+func (e *Engine) CalculateCityPrice(
+	cityCode string,
+	rideType domain.RideType,
+	distanceM float64,
+	durationS int64,
+	currency domain.Currency,
+	h3Cell string,
+	promoDiscount int64,
+	legs ...domain.RouteLeg,
+) (*domain.PriceBreakdown, error) {
+	price, _, err := e.CalculateCityPriceWithInputs(cityCode, rideType, distanceM, durationS, currency, h3Cell, promoDiscount, legs...)
+	return price, err
+}
+
+// CalculateCityPriceWithInputs prices a ride like CalculateCityPrice and also
+// returns the config, rates, controls and surge it used, for the fare snapshot
+func (e *Engine) CalculateCityPriceWithInputs(
+	cityCode string,
+	rideType domain.RideType,
+	distanceM float64,
+	durationS int64,
+	currency domain.Currency,
+	h3Cell string,
+	promoDiscount int64,
+	legs ...domain.RouteLeg,
+) (*domain.PriceBreakdown, *domain.FareInputs, error) {
+	if len(legs) > 0 {
+		distanceM, durationS = domain.RouteLegTotals(legs)
+	}
+	
+	e.cityMu.RLock()
+	city, exists := e.cityConfigs[cityCode]
+	weatherSurge := e.weatherSurge[cityCode]
+	surgeOff := e.surgeOff[cityCode]
+	e.cityMu.RUnlock()
+	
+	var config *PricingConfig
+	var mkt *market
+	inputs := &domain.FareInputs{RideType: rideType, SurgeCell: h3Cell}
+	
+	if exists {
+		config = city.config
+		mkt = &market{name: cityCode, controls: city.controls, report: true}
+		inputs.ConfigSource = domain.FareConfigCityBundle
+		inputs.ConfigVersion = city.version
+		inputs.City = cityCode
+	} else {
+		config, exists = e.configs[currency]
+		if !exists {
+			// Default to NGN
+			config = e.configs[domain.CurrencyNGN]
+		}
+		mkt = e.currencyMarket(config.Currency, true)
+		inputs.ConfigSource = domain.FareConfigCurrencyDefault
+		inputs.ConfigVersion = e.versions[config.Currency]
+	}
+	
+	surgeMultiplier := e.GetSurgeMultiplier(h3Cell)
+	if data, ok := e.surgeCache[h3Cell]; ok && (surgeMultiplier != 1.0 || e.GetZoneDiscount(h3Cell) != 1.0) {
+		updatedAt := data.LastUpdated
+		inputs.SurgeUpdatedAt = &updatedAt
+	}
+	if weatherSurge > 0 && h3Cell != "" {
+		// Weather adds to demand surge but never past the surge cap; the
+		// city's price controls still clamp the total
+		surgeMultiplier = math.Min(surgeMultiplier+weatherSurge, e.surgeConfig.MaxSurgeMultiplier)
+		inputs.WeatherSurge = weatherSurge
+	}
+	if surgeOff {
+		surgeMultiplier = 1.0
+		inputs.WeatherSurge = 0
+		inputs.SurgeDisabled = true
+	}
+	
+	zoneDiscount := e.GetZoneDiscount(h3Cell)
+	price, clamped := e.calculate(config, mkt, rideType, distanceM, durationS, legs, stopCount(legs), surgeMultiplier, zoneDiscount, promoDiscount)
+	
+	inputs.Currency = config.Currency
+	inputs.Rates = domain.RideTypeFares{
+		BaseFare:          config.BaseFares[rideType],
+		PerKmRate:         config.PerKmRates[rideType],
+		PerMinuteRate:     config.PerMinuteRates[rideType],
+		MinFare:           config.MinFares[rideType],
+		WaitPerMinuteRate: config.WaitPerMinuteRates[rideType],
+		FreeWaitMinutes:   config.FreeWaitMinutes[rideType],
+	}
+	inputs.BookingFee = config.BookingFee
+	inputs.StopFee = config.StopFee
+	inputs.Commission = config.CommissionPercent
+	inputs.PriceControls = mkt.controls
+	inputs.SurgeObserved = surgeMultiplier
+	inputs.ZoneDiscountObserved = zoneDiscount
+	inputs.ClampedControls = clamped
+	
+	return price, inputs, nil
+}
+
+// configVersion identifies a pricing config by a short hash of its content
+func configVersion(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// GetCityPriceEstimate returns price estimates for the ride types offered in a city
+func (e *Engine) GetCityPriceEstimate(
+	city *domain.CityConfig,
+	distanceM float64,
+	durationS int64,
+	h3Cell string,
+) (map[domain.RideType]*domain.PriceBreakdown, error) {
+	
+	estimates := make(map[domain.RideType]*domain.PriceBreakdown)
+	
+	for _, rideType := range city.RideTypes {
+		price, err := e.CalculateCityPrice(city.Code, rideType, distanceM, durationS, city.Currency, h3Cell, 0)
+		if err != nil {
+			continue
+		}
+		estimates[rideType] = price
 	}
+	
+	return estimates, nil
 }
 
 // getDefaultConfigs returns default pricing configs for supported currencies
@@ -85,6 +388,7 @@ func getDefaultConfigs() map[domain.Currency]*PricingConfig {
 				domain.RideTypeXL:       60000,   // ₦600
 				domain.RideTypeBoda:     15000,   // ₦150
 				domain.RideTypeTricycle: 20000,   // ₦200
+				domain.RideTypePool:     22500,   // ₦225
 			},
 			PerKmRates: map[domain.RideType]int64{
 				domain.RideTypeStandard: 15000,   // ₦150/km
@@ -92,6 +396,7 @@ func getDefaultConfigs() map[domain.Currency]*PricingConfig {
 				domain.RideTypeXL:       30000,   // ₦300/km
 				domain.RideTypeBoda:     8000,    // ₦80/km
 				domain.RideTypeTricycle: 10000,   // ₦100/km
+				domain.RideTypePool:     11200,   // ₦112/km
 			},
 			PerMinuteRates: map[domain.RideType]int64{
 				domain.RideTypeStandard: 2000,    // ₦20/min
@@ -99,6 +404,7 @@ func getDefaultConfigs() map[domain.Currency]*PricingConfig {
 				domain.RideTypeXL:       4000,    // ₦40/min
 				domain.RideTypeBoda:     1000,    // ₦10/min
 				domain.RideTypeTricycle: 1500,    // ₦15/min
+				domain.RideTypePool:     1500,    // ₦15/min
 			},
 			MinFares: map[domain.RideType]int64{
 				domain.RideTypeStandard: 50000,   // ₦500 minimum
@@ -106,8 +412,26 @@ func getDefaultConfigs() map[domain.Currency]*PricingConfig {
 				domain.RideTypeXL:       100000,  // ₦1000 minimum
 				domain.RideTypeBoda:     30000,   // ₦300 minimum
 				domain.RideTypeTricycle: 35000,   // ₦350 minimum
+				domain.RideTypePool:     37500,   // ₦375 minimum
+			},
+			WaitPerMinuteRates: map[domain.RideType]int64{
+				domain.RideTypeStandard: 2000,    // ₦20/min after 3 free minutes
+				domain.RideTypePremium:  3500,    // ₦35/min after 5
+				domain.RideTypeXL:       4000,    // ₦40/min after 5
+				domain.RideTypeBoda:     1000,    // ₦10/min after 2
+				domain.RideTypeTricycle: 1500,    // ₦15/min after 2
+				domain.RideTypePool:     1500,    // ₦15/min after 2
+			},
+			FreeWaitMinutes: map[domain.RideType]int{
+				domain.RideTypeStandard: 3,
+				domain.RideTypePremium:  5,
+				domain.RideTypeXL:       5,
+				domain.RideTypeBoda:     2,
+				domain.RideTypeTricycle: 2,
+				domain.RideTypePool:     2,
 			},
 			BookingFee:        10000, // ₦100
+			StopFee:           15000, // ₦150 per stop
 			CommissionPercent: 0.20,  // 20%
 			Currency:          domain.CurrencyNGN,
 		},
@@ -118,6 +442,7 @@ func getDefaultConfigs() map[domain.Currency]*PricingConfig {
 				domain.RideTypeXL:       30000,   // KES 300
 				domain.RideTypeBoda:     8000,    // KES 80
 				domain.RideTypeTricycle: 10000,   // KES 100
+				domain.RideTypePool:     11200,   // KES 112
 			},
 			PerKmRates: map[domain.RideType]int64{
 				domain.RideTypeStandard: 4000,    // KES 40/km
@@ -125,6 +450,7 @@ func getDefaultConfigs() map[domain.Currency]*PricingConfig {
 				domain.RideTypeXL:       8500,    // KES 85/km
 				domain.RideTypeBoda:     2500,    // KES 25/km
 				domain.RideTypeTricycle: 3000,    // KES 30/km
+				domain.RideTypePool:     3000,    // KES 30/km
 			},
 			PerMinuteRates: map[domain.RideType]int64{
 				domain.RideTypeStandard: 400,     // KES 4/min
@@ -132,6 +458,7 @@ func getDefaultConfigs() map[domain.Currency]*PricingConfig {
 				domain.RideTypeXL:       850,     // KES 8.5/min
 				domain.RideTypeBoda:     200,     // KES 2/min
 				domain.RideTypeTricycle: 300,     // KES 3/min
+				domain.RideTypePool:     300,     // KES 3/min
 			},
 			MinFares: map[domain.RideType]int64{
 				domain.RideTypeStandard: 20000,   // KES 200 minimum
@@ -139,8 +466,26 @@ func getDefaultConfigs() map[domain.Currency]*PricingConfig {
 				domain.RideTypeXL:       45000,   // KES 450 minimum
 				domain.RideTypeBoda:     10000,   // KES 100 minimum
 				domain.RideTypeTricycle: 15000,   // KES 150 minimum
+				domain.RideTypePool:     15000,   // KES 150 minimum
+			},
+			WaitPerMinuteRates: map[domain.RideType]int64{
+				domain.RideTypeStandard: 400,     // KES 4/min after 3 free minutes
+				domain.RideTypePremium:  700,     // KES 7/min after 5
+				domain.RideTypeXL:       850,     // KES 8.5/min after 5
+				domain.RideTypeBoda:     200,     // KES 2/min after 2
+				domain.RideTypeTricycle: 300,     // KES 3/min after 2
+				domain.RideTypePool:     300,     // KES 3/min after 2
+			},
+			FreeWaitMinutes: map[domain.RideType]int{
+				domain.RideTypeStandard: 3,
+				domain.RideTypePremium:  5,
+				domain.RideTypeXL:       5,
+				domain.RideTypeBoda:     2,
+				domain.RideTypeTricycle: 2,
+				domain.RideTypePool:     2,
 			},
 			BookingFee:        5000,  // KES 50
+			StopFee:           3000,  // KES 30 per stop
 			CommissionPercent: 0.20,
 			Currency:          domain.CurrencyKES,
 		},
@@ -151,6 +496,7 @@ func getDefaultConfigs() map[domain.Currency]*PricingConfig {
 				domain.RideTypeXL:       1200,    // GHS 12
 				domain.RideTypeBoda:     250,     // GHS 2.50
 				domain.RideTypeTricycle: 350,     // GHS 3.50
+				domain.RideTypePool:     375,     // GHS 3.75
 			},
 			PerKmRates: map[domain.RideType]int64{
 				domain.RideTypeStandard: 250,     // GHS 2.50/km
@@ -158,6 +504,7 @@ func getDefaultConfigs() map[domain.Currency]*PricingConfig {
 				domain.RideTypeXL:       550,     // GHS 5.50/km
 				domain.RideTypeBoda:     150,     // GHS 1.50/km
 				domain.RideTypeTricycle: 180,     // GHS 1.80/km
+				domain.RideTypePool:     190,     // GHS 1.90/km
 			},
 			PerMinuteRates: map[domain.RideType]int64{
 				domain.RideTypeStandard: 30,      // GHS 0.30/min
@@ -165,6 +512,7 @@ func getDefaultConfigs() map[domain.Currency]*PricingConfig {
 				domain.RideTypeXL:       60,      // GHS 0.60/min
 				domain.RideTypeBoda:     15,      // GHS 0.15/min
 				domain.RideTypeTricycle: 20,      // GHS 0.20/min
+				domain.RideTypePool:     22,      // GHS 0.22/min
 			},
 			MinFares: map[domain.RideType]int64{
 				domain.RideTypeStandard: 800,     // GHS 8 minimum
@@ -172,14 +520,42 @@ func getDefaultConfigs() map[domain.Currency]*PricingConfig {
 				domain.RideTypeXL:       2000,    // GHS 20 minimum
 				domain.RideTypeBoda:     400,     // GHS 4 minimum
 				domain.RideTypeTricycle: 500,     // GHS 5 minimum
+				domain.RideTypePool:     600,     // GHS 6 minimum
+			},
+			WaitPerMinuteRates: map[domain.RideType]int64{
+				domain.RideTypeStandard: 30,      // GHS 0.30/min after 3 free minutes
+				domain.RideTypePremium:  50,      // GHS 0.50/min after 5
+				domain.RideTypeXL:       60,      // GHS 0.60/min after 5
+				domain.RideTypeBoda:     15,      // GHS 0.15/min after 2
+				domain.RideTypeTricycle: 20,      // GHS 0.20/min after 2
+				domain.RideTypePool:     22,      // GHS 0.22/min after 2
+			},
+			FreeWaitMinutes: map[domain.RideType]int{
+				domain.RideTypeStandard: 3,
+				domain.RideTypePremium:  5,
+				domain.RideTypeXL:       5,
+				domain.RideTypeBoda:     2,
+				domain.RideTypeTricycle: 2,
+				domain.RideTypePool:     2,
 			},
 			BookingFee:        100,   // GHS 1
+			StopFee:           150,   // GHS 1.50 per stop
 			CommissionPercent: 0.20,
 			Currency:          domain.CurrencyGHS,
 		},
 	}
 }
 
+func DefaultGlutDiscountConfig() *GlutDiscountConfig {
+	return &GlutDiscountConfig{
+		MinIdleDrivers:        8,
+		DemandSupplyThreshold: 0.25,
+		DiscountStep:          0.02,
+		FloorMultiplier:       0.85,
+		MaxStepChange:         0.05,
+	}
+}
+
 func getDefaultSurgeConfig() *SurgeConfig {
 	return &SurgeConfig{
 		MinDriversThreshold:   3,
@@ -190,7 +566,9 @@ func getDefaultSurgeConfig() *SurgeConfig {
 	}
 }
 
-// CalculatePrice calculates the price for a ride
+// CalculatePrice calculates the price for a ride. A ride with stops is
+// priced leg by leg from its route legs, whose totals replace distanceM and
+// durationS, and pays the stop fee for each stop between the legs.
 func (e *Engine) CalculatePrice(
 	rideType domain.RideType,
 	distanceM float64,
@@ -198,13 +576,169 @@ func (e *Engine) CalculatePrice(
 	currency domain.Currency,
 	h3Cell string,
 	promoDiscount int64,
+	legs ...domain.RouteLeg,
 ) (*domain.PriceBreakdown, error) {
+	if len(legs) > 0 {
+		distanceM, durationS = domain.RouteLegTotals(legs)
+	}
 	
 	config, exists := e.configs[currency]
 	if !exists {
 		// Default to NGN
 		config = e.configs[domain.CurrencyNGN]
-		currency = domain.CurrencyNGN
+	}
+	
+	price, _ := e.calculate(config, e.currencyMarket(config.Currency, true), rideType, distanceM, durationS, legs, stopCount(legs), e.GetSurgeMultiplier(h3Cell), e.GetZoneDiscount(h3Cell), promoDiscount)
+	return price, nil
+}
+
+// RecalculatePrice reprices a completed ride for a different distance and
+// duration, keeping the surge, zone discount, stop fees and promo discount
+// originally applied
+func (e *Engine) RecalculatePrice(
+	original *domain.PriceBreakdown,
+	rideType domain.RideType,
+	distanceM float64,
+	durationS int64,
+) *domain.PriceBreakdown {
+	config, exists := e.configs[original.Currency]
+	if !exists {
+		config = e.configs[domain.CurrencyNGN]
+	}
+	
+	surgeMultiplier := original.SurgeMultiplier
+	if surgeMultiplier < 1 {
+		surgeMultiplier = 1
+	}
+	
+	price, _ := e.calculate(config, e.currencyMarket(config.Currency, false), rideType, distanceM, durationS, nil, original.Stops, surgeMultiplier, zoneDiscountOf(original), original.PromoDiscount)
+	price = price.WithCommissionDiscount(original.CommissionDiscount)
+	return addWaitCharge(price, original.WaitMinutes, original.WaitTimeFee, config.CommissionPercent)
+}
+
+// RecalculateCityPrice reprices a completed ride like RecalculatePrice, with
+// the bundle of the city it was quoted in
+func (e *Engine) RecalculateCityPrice(
+	cityCode string,
+	original *domain.PriceBreakdown,
+	rideType domain.RideType,
+	distanceM float64,
+	durationS int64,
+) *domain.PriceBreakdown {
+	e.cityMu.RLock()
+	city, exists := e.cityConfigs[cityCode]
+	e.cityMu.RUnlock()
+	
+	if !exists {
+		return e.RecalculatePrice(original, rideType, distanceM, durationS)
+	}
+	
+	surgeMultiplier := original.SurgeMultiplier
+	if surgeMultiplier < 1 {
+		surgeMultiplier = 1
+	}
+	
+	mkt := &market{name: cityCode, controls: city.controls}
+	price, _ := e.calculate(city.config, mkt, rideType, distanceM, durationS, nil, original.Stops, surgeMultiplier, zoneDiscountOf(original), original.PromoDiscount)
+	price = price.WithCommissionDiscount(original.CommissionDiscount)
+	return addWaitCharge(price, original.WaitMinutes, original.WaitTimeFee, city.config.CommissionPercent)
+}
+
+// ApplyWaitTime adds the charge for keeping the driver waiting at pickup to
+// a ride's fare, with the rates of the city it was quoted in. The charge is
+// its own line item, outside surge and discounts, and commission is taken
+// on it like the rest of the fare.
+func (e *Engine) ApplyWaitTime(
+	cityCode string,
+	price *domain.PriceBreakdown,
+	rideType domain.RideType,
+	waited time.Duration,
+) *domain.PriceBreakdown {
+	e.cityMu.RLock()
+	city, exists := e.cityConfigs[cityCode]
+	e.cityMu.RUnlock()
+	
+	var config *PricingConfig
+	if exists {
+		config = city.config
+	} else if config, exists = e.configs[price.Currency]; !exists {
+		config = e.configs[domain.CurrencyNGN]
+	}
+	
+	minutes, fee := domain.WaitCharge(waited, config.FreeWaitMinutes[rideType], config.WaitPerMinuteRates[rideType])
+	if fee == 0 {
+		return price
+	}
+	return addWaitCharge(price, minutes, fee, config.CommissionPercent)
+}
+
+// addWaitCharge returns a copy of a fare with a wait charge added
+func addWaitCharge(price *domain.PriceBreakdown, minutes int, fee int64, commissionPercent float64) *domain.PriceBreakdown {
+	if fee <= 0 {
+		return price
+	}
+	
+	charged := *price
+	charged.WaitMinutes = minutes
+	charged.WaitTimeFee = fee
+	charged.Total += fee
+	charged.PlatformFee = int64(float64(charged.Total)*commissionPercent) - int64(float64(charged.Total)*charged.CommissionDiscount)
+	charged.DriverEarnings = charged.Total - charged.PlatformFee
+	return &charged
+}
+
+// zoneDiscountOf is the zone discount multiplier a fare was priced at
+func zoneDiscountOf(price *domain.PriceBreakdown) float64 {
+	if price.ZoneDiscountMultiplier <= 0 || price.ZoneDiscountMultiplier > 1 {
+		return 1
+	}
+	return price.ZoneDiscountMultiplier
+}
+
+// stopCount is how many stops lie between a route's legs
+func stopCount(legs []domain.RouteLeg) int {
+	if len(legs) < 2 {
+		return 0
+	}
+	return len(legs) - 1
+}
+
+// market is where a fare is priced, for applying and reporting price controls
+type market struct {
+	name     string
+	controls domain.PriceControls
+	report   bool // false for reprices no rider is charged, which are clamped but not reported
+}
+
+// currencyMarket returns the market for rides priced by currency alone
+func (e *Engine) currencyMarket(currency domain.Currency, report bool) *market {
+	e.cityMu.RLock()
+	defer e.cityMu.RUnlock()
+	
+	return &market{name: string(currency), controls: e.marketControls[currency], report: report}
+}
+
+// calculate applies a pricing config to a trip, clamps the result to the
+// market's price controls and returns the controls that were applied. With
+// legs the distance and time fares are summed leg by leg; stops is how many
+// stop fees are charged.
+func (e *Engine) calculate(
+	config *PricingConfig,
+	market *market,
+	rideType domain.RideType,
+	distanceM float64,
+	durationS int64,
+	legs []domain.RouteLeg,
+	stops int,
+	surgeMultiplier float64,
+	zoneDiscount float64,
+	promoDiscount int64,
+) (*domain.PriceBreakdown, []domain.PriceControl) {
+	controls := market.controls
+	var clamped []domain.PriceControl
+	violation := func(control domain.PriceControl, limit, computed, applied float64) {
+		clamped = append(clamped, control)
+		e.recordViolation(market, config.Currency, rideType, control, limit, computed, applied)
 	}
 	
 	// Get base rates for ride type
@@ -213,6 +747,12 @@ func (e *Engine) CalculatePrice(
 	perMinRate := config.PerMinuteRates[rideType]
 	minFare := config.MinFares[rideType]
 	
+	// Cap surge at the regulatory limit
+	if controls.MaxSurgeMultiplier > 0 && surgeMultiplier > controls.MaxSurgeMultiplier {
+		violation(domain.PriceControlMaxSurge, controls.MaxSurgeMultiplier, surgeMultiplier, controls.MaxSurgeMultiplier)
+		surgeMultiplier = controls.MaxSurgeMultiplier
+	}
+	
 	// Calculate distance and time fares
 	distanceKm := distanceM / 1000.0
 	durationMin := float64(durationS) / 60.0
@@ -220,18 +760,78 @@ func (e *Engine) CalculatePrice(
 	distanceFare := int64(distanceKm * float64(perKmRate))
 	timeFare := int64(durationMin * float64(perMinRate))
 	
-	// Get surge multiplier
-	surgeMultiplier := e.GetSurgeMultiplier(h3Cell)
+	var legFares []domain.LegFare
+	if len(legs) > 0 {
+		distanceFare, timeFare = 0, 0
+		legFares = make([]domain.LegFare, len(legs))
+		for i, leg := range legs {
+			legFares[i] = domain.LegFare{
+				DistanceMeters:  leg.DistanceMeters,
+				DurationSeconds: leg.DurationSeconds,
+				DistanceFare:    int64(float64(leg.DistanceMeters) / 1000.0 * float64(perKmRate)),
+				TimeFare:        int64(float64(leg.DurationSeconds) / 60.0 * float64(perMinRate)),
+			}
+			distanceFare += legFares[i].DistanceFare
+			timeFare += legFares[i].TimeFare
+		}
+	}
+	
+	// Raise the distance fare to the mandatory per-km minimum
+	if controls.MinPerKmRate > 0 && distanceKm > 0 {
+		floor := int64(distanceKm * float64(controls.MinPerKmRate))
+		if distanceFare < floor {
+			violation(domain.PriceControlMinPerKmRate, float64(controls.MinPerKmRate), float64(perKmRate), float64(controls.MinPerKmRate))
+			distanceFare = floor
+		}
+	}
 	
 	// Calculate subtotal before surge
 	subtotal := baseFare + distanceFare + timeFare
 	
 	// Apply surge
 	surgeAmount := int64(float64(subtotal) * (surgeMultiplier - 1))
+	
+	// Keep the distance charge, surge included, within the per-km maximum.
+	// Surge on distance is given up first, then the distance fare itself.
+	if controls.MaxPerKmRate > 0 && distanceKm > 0 {
+		ceiling := int64(distanceKm * float64(controls.MaxPerKmRate))
+		surgedDistance := int64(float64(distanceFare) * surgeMultiplier)
+		if surgedDistance > ceiling {
+			violation(domain.PriceControlMaxPerKmRate, float64(controls.MaxPerKmRate), float64(surgedDistance)/distanceKm, float64(controls.MaxPerKmRate))
+			excess := surgedDistance - ceiling
+			cut := excess
+			if cut > surgeAmount {
+				cut = surgeAmount
+			}
+			surgeAmount -= cut
+			distanceFare -= excess - cut
+			subtotal -= excess - cut
+		}
+	}
 	subtotalWithSurge := subtotal + surgeAmount
 	
-	// Add booking fee
-	totalBeforeDiscount := subtotalWithSurge + config.BookingFee
+	// Take the glut discount off cells with idle supply. It never applies
+	// with surge, and stops short of the per-km minimum.
+	var zoneDiscountAmount int64
+	if zoneDiscount < 1 && surgeAmount == 0 {
+		zoneDiscountAmount = int64(float64(subtotal) * (1 - zoneDiscount))
+		if controls.MinPerKmRate > 0 && distanceKm > 0 {
+			headroom := subtotal - int64(distanceKm*float64(controls.MinPerKmRate))
+			if headroom < 0 {
+				headroom = 0
+			}
+			if zoneDiscountAmount > headroom {
+				zoneDiscountAmount = headroom
+			}
+		}
+		subtotalWithSurge -= zoneDiscountAmount
+	} else {
+		zoneDiscount = 1
+	}
+	
+	// Add booking fee, and stop fees for multi-stop rides. Neither is surged.
+	stopFees := config.StopFee * int64(stops)
+	totalBeforeDiscount := subtotalWithSurge + config.BookingFee + stopFees
 	
 	// Apply promo discount
 	total := totalBeforeDiscount - promoDiscount
@@ -244,24 +844,72 @@ func (e *Engine) CalculatePrice(
 		total = minFare
 	}
 	
+	// The regulatory minimum is applied last so no discount can undercut it
+	if controls.MinFare > 0 && total < controls.MinFare {
+		violation(domain.PriceControlMinFare, float64(controls.MinFare), float64(total), float64(controls.MinFare))
+		total = controls.MinFare
+	}
+	
 	// Calculate driver earnings and platform fee
 	platformFee := int64(float64(total) * config.CommissionPercent)
 	driverEarnings := total - platformFee
 	
 	return &domain.PriceBreakdown{
-		BaseFare:        baseFare,
-		DistanceFare:    distanceFare,
-		TimeFare:        timeFare,
-		SurgeMultiplier: surgeMultiplier,
-		SurgeAmount:     surgeAmount,
-		BookingFee:      config.BookingFee,
-		TollFees:        0, // NOTE: Toll fees calculated via routing service integration
-		PromoDiscount:   promoDiscount,
-		Total:           total,
-		Currency:        currency,
-		DriverEarnings:  driverEarnings,
-		PlatformFee:     platformFee,
-	}, nil
+		BaseFare:               baseFare,
+		DistanceFare:           distanceFare,
+		TimeFare:               timeFare,
+		SurgeMultiplier:        surgeMultiplier,
+		SurgeAmount:            surgeAmount,
+		ZoneDiscountMultiplier: zoneDiscount,
+		ZoneDiscount:           zoneDiscountAmount,
+		BookingFee:             config.BookingFee,
+		TollFees:               0, // NOTE: Toll fees calculated via routing service integration
+		StopFees:               stopFees,
+		Stops:                  stops,
+		Legs:                   legFares,
+		PromoDiscount:          promoDiscount,
+		Total:                  total,
+		Currency:               config.Currency,
+		DriverEarnings:         driverEarnings,
+		PlatformFee:            platformFee,
+	}, clamped
+}
+
+// recordViolation logs a fare clamped to a price control and reports it for
+// the compliance report
+func (e *Engine) recordViolation(
+	market *market,
+	currency domain.Currency,
+	rideType domain.RideType,
+	control domain.PriceControl,
+	limit, computed, applied float64,
+) {
+	if !market.report {
+		return
+	}
+	
+	log.Warn().
+		Str("market", market.name).
+		Str("ride_type", string(rideType)).
+		Str("control", string(control)).
+		Float64("limit", limit).
+		Float64("computed", computed).
+		Msg("Fare clamped to price control")
+	
+	if e.violations == nil {
+		return
+	}
+	e.violations.RecordPriceControlViolation(&domain.PriceControlViolation{
+		ID:        uuid.New(),
+		Market:    market.name,
+		Currency:  currency,
+		RideType:  rideType,
+		Control:   control,
+		Limit:     limit,
+		Computed:  computed,
+		Applied:   applied,
+		CreatedAt: time.Now().UTC(),
+	})
 }
 
 // GetSurgeMultiplier returns the current surge multiplier for an H3 cell
@@ -279,6 +927,22 @@ func (e *Engine) GetSurgeMultiplier(h3Cell string) float64 {
 	return data.Multiplier
 }
 
+// GetZoneDiscount returns the current glut discount multiplier for an H3
+// cell, 1 when the cell is not discounted
+func (e *Engine) GetZoneDiscount(h3Cell string) float64 {
+	data, exists := e.surgeCache[h3Cell]
+	if !exists || data.Discount <= 0 {
+		return 1.0
+	}
+	
+	// Stale data gives no discount, like stale surge
+	if time.Since(data.LastUpdated) > 5*time.Minute {
+		return 1.0
+	}
+	
+	return data.Discount
+}
+
 // UpdateSurge updates surge pricing data for an H3 cell
 func (e *Engine) UpdateSurge(h3Cell string, activeDrivers, pendingRequests int) float64 {
 	now := time.Now()
@@ -322,10 +986,23 @@ func (e *Engine) UpdateSurge(h3Cell string, activeDrivers, pendingRequests int)
 		}
 	}
 	
+	// Discount cells with plenty of idle drivers and little demand
+	discount := 1.0
+	if multiplier <= 1.0 {
+		discount = e.glutDiscount(activeDrivers, ratio)
+		if existing, exists := e.surgeCache[h3Cell]; exists && existing.Discount > 0 && e.glutConfig.MaxStepChange > 0 {
+			diff := discount - existing.Discount
+			if math.Abs(diff) > e.glutConfig.MaxStepChange {
+				discount = existing.Discount + math.Copysign(e.glutConfig.MaxStepChange, diff)
+			}
+		}
+	}
+	
 	// Update cache
 	e.surgeCache[h3Cell] = &SurgeData{
 		Cell:            h3Cell,
 		Multiplier:      multiplier,
+		Discount:        discount,
 		ActiveDrivers:   activeDrivers,
 		PendingRequests: pendingRequests,
 		LastUpdated:     now,
@@ -334,6 +1011,18 @@ func (e *Engine) UpdateSurge(h3Cell string, activeDrivers, pendingRequests int)
 	return multiplier
 }
 
+// glutDiscount is the discount multiplier for a cell's supply and demand,
+// no lower than the configured floor
+func (e *Engine) glutDiscount(activeDrivers int, ratio float64) float64 {
+	cfg := e.glutConfig
+	if cfg == nil || cfg.FloorMultiplier >= 1 || activeDrivers < cfg.MinIdleDrivers || ratio > cfg.DemandSupplyThreshold {
+		return 1.0
+	}
+	
+	discount := 1.0 - float64(activeDrivers-cfg.MinIdleDrivers+1)*cfg.DiscountStep
+	return math.Max(discount, cfg.FloorMultiplier)
+}
+
 // GetPriceEstimate returns price estimates for all ride types
 func (e *Engine) GetPriceEstimate(
 	distanceM float64,
@@ -0,0 +1,201 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+type recordedViolations []*domain.PriceControlViolation
+
+func (r *recordedViolations) RecordPriceControlViolation(v *domain.PriceControlViolation) {
+	*r = append(*r, v)
+}
+
+func testCity(controls domain.PriceControls) *domain.CityConfig {
+	return &domain.CityConfig{
+		Code:      "testcity",
+		Currency:  domain.CurrencyKES,
+		Enabled:   true,
+		RideTypes: []domain.RideType{domain.RideTypeStandard},
+		Pricing: domain.CityPricing{
+			RideTypes: map[domain.RideType]domain.RideTypeFares{
+				domain.RideTypeStandard: {BaseFare: 10000, PerKmRate: 5000, PerMinuteRate: 0, MinFare: 20000},
+			},
+		},
+		Regulatory: domain.CityRegulatory{PriceControls: controls},
+	}
+}
+
+func TestCalculateCityPriceClampsToPriceControls(t *testing.T) {
+	engine := NewEngine()
+	var violations recordedViolations
+	engine.SetViolationRecorder(&violations)
+	engine.SetCityConfig(testCity(domain.PriceControls{
+		MaxSurgeMultiplier: 2.0,
+		MaxPerKmRate:       8000,
+		MinFare:            25000,
+	}))
+	engine.surgeCache["cell"] = &SurgeData{Cell: "cell", Multiplier: 2.5, LastUpdated: time.Now()}
+
+	// 10 km: distance fare 50,000, surged to 100,000 at 2.0x; ceiling is 80,000
+	price, err := engine.CalculateCityPrice("testcity", domain.RideTypeStandard, 10000, 0, domain.CurrencyKES, "cell", 0)
+	if err != nil {
+		t.Fatalf("CalculateCityPrice() error = %v", err)
+	}
+	if price.SurgeMultiplier != 2.0 {
+		t.Errorf("SurgeMultiplier = %v, want 2.0", price.SurgeMultiplier)
+	}
+	if price.SurgeAmount != 60000-20000 || price.DistanceFare != 50000 {
+		t.Errorf("SurgeAmount = %d, DistanceFare = %d; want 40000, 50000", price.SurgeAmount, price.DistanceFare)
+	}
+	if len(violations) != 2 || violations[0].Control != domain.PriceControlMaxSurge || violations[1].Control != domain.PriceControlMaxPerKmRate {
+		t.Errorf("violations = %+v, want max surge then max per-km", violations)
+	}
+
+	// A promo cannot take the fare below the regulatory minimum
+	violations = nil
+	price, _ = engine.CalculateCityPrice("testcity", domain.RideTypeStandard, 1000, 0, domain.CurrencyKES, "", 14000)
+	if price.Total != 25000 {
+		t.Errorf("Total = %d, want regulatory minimum 25000", price.Total)
+	}
+	if len(violations) != 1 || violations[0].Control != domain.PriceControlMinFare {
+		t.Errorf("violations = %+v, want min fare", violations)
+	}
+}
+
+func TestCalculatePriceUsesStrictestCityControlsForCurrency(t *testing.T) {
+	engine := NewEngine()
+	engine.SetCityConfig(testCity(domain.PriceControls{MinPerKmRate: 9000}))
+
+	price, err := engine.CalculatePrice(domain.RideTypeStandard, 5000, 0, domain.CurrencyKES, "", 0)
+	if err != nil {
+		t.Fatalf("CalculatePrice() error = %v", err)
+	}
+	if price.DistanceFare != 45000 {
+		t.Errorf("DistanceFare = %d, want per-km minimum 45000", price.DistanceFare)
+	}
+}
+
+func TestCalculateCityPriceWithInputs(t *testing.T) {
+	engine := NewEngine()
+	engine.SetCityConfig(testCity(domain.PriceControls{MinFare: 25000}))
+
+	_, inputs, err := engine.CalculateCityPriceWithInputs("testcity", domain.RideTypeStandard, 1000, 0, domain.CurrencyKES, "", 0)
+	if err != nil {
+		t.Fatalf("CalculateCityPriceWithInputs() error = %v", err)
+	}
+	if inputs.ConfigSource != domain.FareConfigCityBundle || inputs.City != "testcity" || inputs.ConfigVersion == "" {
+		t.Errorf("inputs = %+v, want city bundle source with a version", inputs)
+	}
+	if inputs.Rates.PerKmRate != 5000 || len(inputs.ClampedControls) != 1 || inputs.ClampedControls[0] != domain.PriceControlMinFare {
+		t.Errorf("inputs = %+v, want city rates and min fare clamp", inputs)
+	}
+
+	_, inputs, _ = engine.CalculateCityPriceWithInputs("", domain.RideTypeStandard, 1000, 0, domain.CurrencyNGN, "", 0)
+	if inputs.ConfigSource != domain.FareConfigCurrencyDefault || inputs.Currency != domain.CurrencyNGN {
+		t.Errorf("inputs = %+v, want NGN currency defaults", inputs)
+	}
+}
+
+func TestZoneDiscountInSupplyGlut(t *testing.T) {
+	engine := NewEngine()
+	engine.SetCityConfig(testCity(domain.PriceControls{}))
+
+	// 20 idle drivers, 1 request: 26% off by step, limited to the 0.85 floor
+	if got := engine.UpdateSurge("cell", 20, 1); got != 1.0 {
+		t.Fatalf("UpdateSurge() surge = %v, want 1.0", got)
+	}
+	if got := engine.GetZoneDiscount("cell"); got != 0.85 {
+		t.Fatalf("GetZoneDiscount() = %v, want floor 0.85", got)
+	}
+
+	price, err := engine.CalculateCityPrice("testcity", domain.RideTypeStandard, 10000, 0, domain.CurrencyKES, "cell", 0)
+	if err != nil {
+		t.Fatalf("CalculateCityPrice() error = %v", err)
+	}
+	if price.ZoneDiscountMultiplier != 0.85 || price.ZoneDiscount != 9000 || price.Total != 51000 {
+		t.Errorf("price = %+v, want 9000 zone discount off 60000", price)
+	}
+
+	// The discount eases off gradually as the glut clears
+	engine.UpdateSurge("cell", 8, 0)
+	if got := engine.GetZoneDiscount("cell"); got < 0.899 || got > 0.901 {
+		t.Errorf("GetZoneDiscount() = %v, want 0.90 after one step", got)
+	}
+
+	// Cells short of drivers surge instead
+	engine.UpdateSurge("busy", 1, 5)
+	if got := engine.GetZoneDiscount("busy"); got != 1.0 {
+		t.Errorf("GetZoneDiscount() = %v, want 1.0 in a surging cell", got)
+	}
+}
+
+func TestCalculateCityPricePerLeg(t *testing.T) {
+	engine := NewEngine()
+	city := testCity(domain.PriceControls{})
+	city.Pricing.StopFee = 2000
+	engine.SetCityConfig(city)
+
+	// Two stops split the trip into three legs; the distance argument is
+	// replaced by the legs' total
+	legs := []domain.RouteLeg{
+		{DistanceMeters: 3000, DurationSeconds: 300},
+		{DistanceMeters: 2000, DurationSeconds: 240},
+		{DistanceMeters: 5000, DurationSeconds: 600},
+	}
+	price, err := engine.CalculateCityPrice("testcity", domain.RideTypeStandard, 1000, 60, domain.CurrencyKES, "", 0, legs...)
+	if err != nil {
+		t.Fatalf("CalculateCityPrice() error = %v", err)
+	}
+	if len(price.Legs) != 3 || price.Legs[0].DistanceFare != 15000 || price.Legs[2].DistanceFare != 25000 {
+		t.Errorf("Legs = %+v, want per-leg distance fares 15000, 10000, 25000", price.Legs)
+	}
+	if price.DistanceFare != 50000 {
+		t.Errorf("DistanceFare = %d, want 50000", price.DistanceFare)
+	}
+	if price.Stops != 2 || price.StopFees != 4000 {
+		t.Errorf("Stops = %d, StopFees = %d; want 2, 4000", price.Stops, price.StopFees)
+	}
+	if want := price.BaseFare + price.DistanceFare + price.TimeFare + price.BookingFee + price.StopFees; price.Total != want {
+		t.Errorf("Total = %d, want %d", price.Total, want)
+	}
+}
+
+func TestApplyWaitTime(t *testing.T) {
+	engine := NewEngine()
+	city := testCity(domain.PriceControls{})
+	city.Pricing.CommissionPercent = 0.2
+	fares := city.Pricing.RideTypes[domain.RideTypeStandard]
+	fares.WaitPerMinuteRate = 500
+	fares.FreeWaitMinutes = 3
+	city.Pricing.RideTypes[domain.RideTypeStandard] = fares
+	engine.SetCityConfig(city)
+
+	quoted, err := engine.CalculateCityPrice("testcity", domain.RideTypeStandard, 5000, 600, domain.CurrencyKES, "", 0)
+	if err != nil {
+		t.Fatalf("CalculateCityPrice() error = %v", err)
+	}
+
+	if got := engine.ApplyWaitTime("testcity", quoted, domain.RideTypeStandard, 2*time.Minute); got != quoted {
+		t.Errorf("ApplyWaitTime() within free minutes = %+v, want the quoted fare", got)
+	}
+
+	charged := engine.ApplyWaitTime("testcity", quoted, domain.RideTypeStandard, 7*time.Minute+30*time.Second)
+	if charged.WaitMinutes != 4 || charged.WaitTimeFee != 2000 {
+		t.Errorf("wait = %d min for %d, want 4 min for 2000", charged.WaitMinutes, charged.WaitTimeFee)
+	}
+	if charged.Total != quoted.Total+2000 || quoted.WaitTimeFee != 0 {
+		t.Errorf("Total = %d, want %d with the quote untouched", charged.Total, quoted.Total+2000)
+	}
+	if charged.PlatformFee+charged.DriverEarnings != charged.Total || charged.PlatformFee != charged.Total/5 {
+		t.Errorf("fee split = %d + %d, want 20%% commission of %d", charged.PlatformFee, charged.DriverEarnings, charged.Total)
+	}
+
+	// Repricing the completed ride keeps the wait charge
+	repriced := engine.RecalculateCityPrice("testcity", charged, domain.RideTypeStandard, 5000, 600)
+	if repriced.WaitTimeFee != 2000 || repriced.Total != charged.Total {
+		t.Errorf("repriced = %+v, want the wait charge kept", repriced)
+	}
+}
@@ -0,0 +1,85 @@
+package pricing
+
+import (
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// SplitPoolFare divides a pool trip's fare among its riders. The driver's
+// whole route is priced at pool rates, with the surge the first rider was
+// quoted, and each rider pays a part in proportion to the distance they
+// would have travelled alone, less their own promo discount. No rider pays
+// more than the pool fare they were quoted alone.
+func (e *Engine) SplitPoolFare(
+	cityCode string,
+	routeMeters float64,
+	routeSeconds int64,
+	shares []domain.PoolFareShare,
+) map[uuid.UUID]*domain.PriceBreakdown {
+	split := make(map[uuid.UUID]*domain.PriceBreakdown, len(shares))
+	if len(shares) == 0 {
+		return split
+	}
+
+	totalDirect := 0.0
+	for _, share := range shares {
+		totalDirect += share.DirectMeters
+	}
+
+	// Promos belong to riders, not the trip
+	reference := *shares[0].Quoted
+	reference.PromoDiscount = 0
+	trip := e.RecalculateCityPrice(cityCode, &reference, domain.RideTypePool, routeMeters, routeSeconds)
+
+	for _, share := range shares {
+		fraction := 1.0 / float64(len(shares))
+		if totalDirect > 0 {
+			fraction = share.DirectMeters / totalDirect
+		}
+
+		part := scaleFare(trip, fraction)
+		part.PromoDiscount = share.Quoted.PromoDiscount
+		part.Total -= part.PromoDiscount
+		if part.Total < 0 {
+			part.Total = 0
+		}
+		if part.Total >= share.Quoted.Total {
+			quoted := *share.Quoted
+			split[share.RideID] = &quoted
+			continue
+		}
+		part.PlatformFee = int64(float64(part.Total) * commissionOf(trip))
+		part.DriverEarnings = part.Total - part.PlatformFee
+		split[share.RideID] = part
+	}
+	return split
+}
+
+// scaleFare is a fraction of a fare, component by component
+func scaleFare(price *domain.PriceBreakdown, fraction float64) *domain.PriceBreakdown {
+	scale := func(amount int64) int64 {
+		return int64(float64(amount) * fraction)
+	}
+	return &domain.PriceBreakdown{
+		BaseFare:               scale(price.BaseFare),
+		DistanceFare:           scale(price.DistanceFare),
+		TimeFare:               scale(price.TimeFare),
+		SurgeMultiplier:        price.SurgeMultiplier,
+		SurgeAmount:            scale(price.SurgeAmount),
+		ZoneDiscountMultiplier: price.ZoneDiscountMultiplier,
+		ZoneDiscount:           scale(price.ZoneDiscount),
+		BookingFee:             scale(price.BookingFee),
+		TollFees:               scale(price.TollFees),
+		StopFees:               scale(price.StopFees),
+		Total:                  scale(price.Total),
+		Currency:               price.Currency,
+	}
+}
+
+// commissionOf is the share of a fare the platform kept
+func commissionOf(price *domain.PriceBreakdown) float64 {
+	if price.Total <= 0 {
+		return 0
+	}
+	return float64(price.PlatformFee) / float64(price.Total)
+}
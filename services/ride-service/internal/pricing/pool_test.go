@@ -0,0 +1,63 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+func TestSplitPoolFare(t *testing.T) {
+	engine := NewEngine()
+	city := testCity(domain.PriceControls{})
+	city.RideTypes = append(city.RideTypes, domain.RideTypePool)
+	city.Pricing.RideTypes[domain.RideTypePool] = domain.RideTypeFares{BaseFare: 10000, PerKmRate: 4000, MinFare: 10000}
+	city.Pricing.CommissionPercent = 0.20
+	engine.SetCityConfig(city)
+
+	quote := func(distanceM float64, promo int64) *domain.PriceBreakdown {
+		price, err := engine.CalculateCityPrice("testcity", domain.RideTypePool, distanceM, 0, domain.CurrencyKES, "", promo)
+		if err != nil {
+			t.Fatalf("CalculateCityPrice() error = %v", err)
+		}
+		return price
+	}
+
+	long, short, capped := uuid.New(), uuid.New(), uuid.New()
+	cappedQuote := quote(2000, 0) // 18,000 alone
+	split := engine.SplitPoolFare("testcity", 14000, 0, []domain.PoolFareShare{
+		{RideID: long, DirectMeters: 6000, Quoted: quote(6000, 0)},
+		{RideID: short, DirectMeters: 4000, Quoted: quote(4000, 5000)},
+		{RideID: capped, DirectMeters: 2000, Quoted: cappedQuote},
+	})
+
+	// The 14 km route costs 66,000, shared 6:4:2 as 33,000, 22,000 and 11,000
+	tests := []struct {
+		name   string
+		rideID uuid.UUID
+		total  int64
+	}{
+		{"long trip pays the most", long, 33000},
+		{"promo comes off the rider's own share", short, 17000},
+		{"short trip pays the least", capped, 11000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := split[tt.rideID]
+			if got == nil || got.Total != tt.total {
+				t.Fatalf("split = %+v, want total %d", got, tt.total)
+			}
+			if got.PlatformFee+got.DriverEarnings != got.Total {
+				t.Errorf("platform fee %d + earnings %d != total %d", got.PlatformFee, got.DriverEarnings, got.Total)
+			}
+		})
+	}
+
+	// A rider whose share would exceed their solo quote pays the quote
+	split = engine.SplitPoolFare("testcity", 30000, 0, []domain.PoolFareShare{
+		{RideID: capped, DirectMeters: 2000, Quoted: cappedQuote},
+	})
+	if got := split[capped]; got == nil || got.Total != cappedQuote.Total {
+		t.Errorf("split = %+v, want the solo quote %d", got, cappedQuote.Total)
+	}
+}
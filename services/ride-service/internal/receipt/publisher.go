@@ -0,0 +1,55 @@
+// Package receipt publishes issued ride receipts for the notification
+// service, which emails them to riders.
+package receipt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// Topic is the Kafka topic receipts are published to, keyed by ride
+const Topic = "ride-receipts"
+
+// Publisher sends receipt events to Kafka
+type Publisher struct {
+	kafka *kafka.Writer
+}
+
+// NewPublisher creates a publisher writing to the given brokers
+func NewPublisher(brokers []string) *Publisher {
+	return &Publisher{
+		kafka: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        Topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			WriteTimeout: 5 * time.Second,
+		},
+	}
+}
+
+// PublishReceipt sends a receipt issued event
+func (p *Publisher) PublishReceipt(ctx context.Context, receipt *domain.RideReceipt) error {
+	data, err := json.Marshal(&domain.RideReceiptEvent{
+		Type:    domain.RideReceiptIssuedEvent,
+		Receipt: receipt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal receipt event: %w", err)
+	}
+
+	return p.kafka.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(receipt.RideID.String()),
+		Value: data,
+	})
+}
+
+// Close closes the Kafka writer
+func (p *Publisher) Close() error {
+	return p.kafka.Close()
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/logging"
 )
 
 const (
@@ -20,11 +21,34 @@ const (
 	driverLocationKey    = "driver:location:"
 	driverStatusKey      = "driver:status:"
 	driverLockKey        = "driver:lock:"
+	driverRestrictionKey = "driver:restriction:"
 	rideCacheKey         = "ride:"
 	h3CellDriversKey     = "h3:drivers:"
 	surgeDataKey         = "surge:"
 	activeDriversKey     = "drivers:active"
 	rideMatchingKey      = "matching:ride:"
+	zoneKPIReportKey     = "zone_kpis:"
+	locationHistoryKey   = "prediction:history:"
+	popularLocationsKey  = "prediction:popular_locations"
+	pickupETAPreviewKey  = "pickup_eta:"
+	geocodeKey           = "geocode:"
+	routeOptionsKey      = "route_options:"
+	routeDeviationKey    = "route_deviation:"
+	incidentsKey         = "traffic:incidents"
+	incidentReportsKey   = "traffic:incident_reports"
+	rerouteKey           = "reroute:"
+	roadReportKey        = "road_report:"
+	roadReportsKey       = "road_reports:driver:"
+	roadCellKey          = "road_reports:cell:"
+	roadReporterKey      = "road_reports:reporter:"
+	roadIncidentKey      = "road_reports:incident:"
+	roadPromptsKey       = "road_reports:prompts"
+	killSwitchesKey      = "killswitch:active" // also read by the delivery service
+	rideRequestLockKey   = "ride_request:lock:"
+	locationFlushKey     = "driver:location:flush"
+	persistedLocationKey = "driver:location:persisted"
+	logSettingsKey       = "logging:settings"
+	rideChatKey          = "ride:chat:"
 	
 	// TTLs
 	locationTTL          = 5 * time.Minute
@@ -32,6 +56,11 @@ const (
 	rideCacheTTL         = 30 * time.Minute
 	surgeTTL             = 5 * time.Minute
 	matchingLockTTL      = 60 * time.Second
+	rideRequestLockTTL   = 10 * time.Second
+	zoneKPIReportTTL     = 15 * time.Minute
+	locationHistoryTTL   = 1 * time.Hour
+	rideChatTTL          = 24 * time.Hour   // outlives any ride, in case it is never closed
+	rideChatClosedTTL    = 10 * time.Minute // lets readers see the chat close
 )
 
 // DriverPool manages driver locations and availability in Redis
@@ -129,6 +158,114 @@ func (p *DriverPool) GetDriverLocation(ctx context.Context, driverID uuid.UUID)
 	return &loc, nil
 }
 
+// GetDriverLocations gets the current location of each of the given
+// drivers, keyed by driver. Drivers without a live location are left out.
+func (p *DriverPool) GetDriverLocations(ctx context.Context, driverIDs []uuid.UUID) (map[uuid.UUID]*DriverLocationData, error) {
+	locations := make(map[uuid.UUID]*DriverLocationData, len(driverIDs))
+	if len(driverIDs) == 0 {
+		return locations, nil
+	}
+	
+	keys := make([]string, len(driverIDs))
+	for i, id := range driverIDs {
+		keys[i] = driverLocationKey + id.String()
+	}
+	values, err := p.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var loc DriverLocationData
+		if err := json.Unmarshal([]byte(data), &loc); err == nil {
+			locations[driverIDs[i]] = &loc
+		}
+	}
+	return locations, nil
+}
+
+// Location flush helpers
+
+// QueueLocationFlush queues a driver's position to be written to Postgres
+// at due. A driver already queued keeps the earlier of the two times.
+func (p *DriverPool) QueueLocationFlush(ctx context.Context, driverID uuid.UUID, due time.Time) error {
+	return p.client.ZAddArgs(ctx, locationFlushKey, redis.ZAddArgs{
+		LT:      true,
+		Members: []redis.Z{{Score: float64(due.Unix()), Member: driverID.String()}},
+	}).Err()
+}
+
+// ClaimDueLocationFlushes takes up to limit drivers whose positions are
+// due to be written. Each driver is handed to one caller only.
+func (p *DriverPool) ClaimDueLocationFlushes(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	members, err := p.client.ZRangeByScore(ctx, locationFlushKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.Unix(), 10),
+		Count: int64(limit),
+	}).Result()
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+	
+	pipe := p.client.Pipeline()
+	removed := make([]*redis.IntCmd, len(members))
+	for i, member := range members {
+		removed[i] = pipe.ZRem(ctx, locationFlushKey, member)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	
+	due := make([]uuid.UUID, 0, len(members))
+	for i, member := range members {
+		if removed[i].Val() == 0 {
+			continue
+		}
+		if driverID, err := uuid.Parse(member); err == nil {
+			due = append(due, driverID)
+		}
+	}
+	return due, nil
+}
+
+// GetPersistedLocation gets the position last written to Postgres for a
+// driver, or nil when none has been written through the flush
+func (p *DriverPool) GetPersistedLocation(ctx context.Context, driverID uuid.UUID) (*domain.PersistedLocation, error) {
+	data, err := p.client.HGet(ctx, persistedLocationKey, driverID.String()).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	var persisted domain.PersistedLocation
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		return nil, nil
+	}
+	return &persisted, nil
+}
+
+// SetPersistedLocations records the positions just written to Postgres
+func (p *DriverPool) SetPersistedLocations(ctx context.Context, persisted map[uuid.UUID]*domain.PersistedLocation) error {
+	if len(persisted) == 0 {
+		return nil
+	}
+	
+	values := make([]interface{}, 0, 2*len(persisted))
+	for driverID, loc := range persisted {
+		data, err := json.Marshal(loc)
+		if err != nil {
+			return err
+		}
+		values = append(values, driverID.String(), data)
+	}
+	return p.client.HSet(ctx, persistedLocationKey, values...).Err()
+}
+
 // GetNearbyDrivers finds drivers near a location using Redis GEO
 func (p *DriverPool) GetNearbyDrivers(ctx context.Context, lat, lng, radiusM float64, rideType domain.RideType) ([]*domain.NearbyDriver, error) {
 	// Use GEORADIUS to find nearby drivers
@@ -238,8 +375,17 @@ func (p *DriverPool) SetDriverStatus(ctx context.Context, driverID uuid.UUID, st
 	return nil
 }
 
-// GetDriverStatus gets a driver's status
+// GetDriverStatus gets a driver's status. An active restriction takes
+// precedence over the operational status.
 func (p *DriverPool) GetDriverStatus(ctx context.Context, driverID uuid.UUID) (domain.DriverStatus, error) {
+	restriction, err := p.client.Get(ctx, driverRestrictionKey+driverID.String()).Result()
+	if err == nil {
+		return domain.DriverStatus(restriction), nil
+	}
+	if err != redis.Nil {
+		return "", err
+	}
+	
 	status, err := p.client.Get(ctx, driverStatusKey+driverID.String()).Result()
 	if err != nil {
 		if err == redis.Nil {
@@ -250,6 +396,21 @@ func (p *DriverPool) GetDriverStatus(ctx context.Context, driverID uuid.UUID) (d
 	return domain.DriverStatus(status), nil
 }
 
+// SetDriverRestriction marks a driver as suspended, deactivated or under
+// review and removes them from matching. The restriction has no TTL and
+// persists until cleared.
+func (p *DriverPool) SetDriverRestriction(ctx context.Context, driverID uuid.UUID, status domain.DriverStatus) error {
+	if err := p.client.Set(ctx, driverRestrictionKey+driverID.String(), string(status), 0).Err(); err != nil {
+		return err
+	}
+	return p.RemoveDriver(ctx, driverID)
+}
+
+// ClearDriverRestriction lifts a driver's restriction, leaving them offline
+func (p *DriverPool) ClearDriverRestriction(ctx context.Context, driverID uuid.UUID) error {
+	return p.client.Del(ctx, driverRestrictionKey+driverID.String()).Err()
+}
+
 // LockDriver temporarily locks a driver for matching
 func (p *DriverPool) LockDriver(ctx context.Context, driverID uuid.UUID, duration time.Duration) error {
 	ok, err := p.client.SetNX(ctx, driverLockKey+driverID.String(), "1", duration).Result()
@@ -368,6 +529,461 @@ func (p *DriverPool) InvalidateRideCache(ctx context.Context, rideID uuid.UUID)
 	return p.client.Del(ctx, rideCacheKey+rideID.String()).Err()
 }
 
+// Zone KPI caching
+
+// CacheZoneKPIReport caches the all-cities zone KPI report for its window
+func (p *DriverPool) CacheZoneKPIReport(ctx context.Context, report *domain.ZoneKPIReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return p.client.Set(ctx, zoneKPIReportKey+report.Window, data, zoneKPIReportTTL).Err()
+}
+
+// GetCachedZoneKPIReport gets the cached all-cities zone KPI report for a window
+func (p *DriverPool) GetCachedZoneKPIReport(ctx context.Context, window string) (*domain.ZoneKPIReport, error) {
+	data, err := p.client.Get(ctx, zoneKPIReportKey+window).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	var report domain.ZoneKPIReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	
+	return &report, nil
+}
+
+// Location history caching
+
+// CacheUserLocationHistory caches a user's frequent places and trip patterns
+func (p *DriverPool) CacheUserLocationHistory(ctx context.Context, history *domain.UserLocationHistory) error {
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	return p.client.Set(ctx, locationHistoryKey+history.UserID.String(), data, locationHistoryTTL).Err()
+}
+
+// GetCachedUserLocationHistory gets a user's cached location history
+func (p *DriverPool) GetCachedUserLocationHistory(ctx context.Context, userID uuid.UUID) (*domain.UserLocationHistory, error) {
+	data, err := p.client.Get(ctx, locationHistoryKey+userID.String()).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	var history domain.UserLocationHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, err
+	}
+	
+	return &history, nil
+}
+
+// InvalidateUserLocationHistory removes a user's cached location history
+func (p *DriverPool) InvalidateUserLocationHistory(ctx context.Context, userID uuid.UUID) error {
+	return p.client.Del(ctx, locationHistoryKey+userID.String()).Err()
+}
+
+// SetPopularLocations stores each city's popular destinations until ttl
+func (p *DriverPool) SetPopularLocations(ctx context.Context, locations *domain.PopularLocations, ttl time.Duration) error {
+	data, err := json.Marshal(locations)
+	if err != nil {
+		return err
+	}
+	return p.client.Set(ctx, popularLocationsKey, data, ttl).Err()
+}
+
+// GetPopularLocations gets each city's popular destinations, or nil when
+// they have not been computed
+func (p *DriverPool) GetPopularLocations(ctx context.Context) (*domain.PopularLocations, error) {
+	data, err := p.client.Get(ctx, popularLocationsKey).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	var locations domain.PopularLocations
+	if err := json.Unmarshal(data, &locations); err != nil {
+		return nil, err
+	}
+	
+	return &locations, nil
+}
+
+// Pickup ETA preview caching
+
+// CachePickupETAPreview caches a cell's pickup ETA preview briefly, so
+// riders browsing nearby share one lookup
+func (p *DriverPool) CachePickupETAPreview(ctx context.Context, preview *domain.PickupETAPreview) error {
+	data, err := json.Marshal(preview)
+	if err != nil {
+		return err
+	}
+	return p.client.Set(ctx, pickupETAPreviewKey+preview.Cell, data, domain.PickupETACacheTTL).Err()
+}
+
+// GetCachedPickupETAPreview gets a cell's cached pickup ETA preview
+func (p *DriverPool) GetCachedPickupETAPreview(ctx context.Context, cell string) (*domain.PickupETAPreview, error) {
+	data, err := p.client.Get(ctx, pickupETAPreviewKey+cell).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	var preview domain.PickupETAPreview
+	if err := json.Unmarshal(data, &preview); err != nil {
+		return nil, err
+	}
+	
+	return &preview, nil
+}
+
+// Geocode caching
+
+// CacheGeocode caches an address's geocoding result under a lookup key
+func (p *DriverPool) CacheGeocode(ctx context.Context, key string, result *domain.GeocodedAddress) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	
+	return p.client.Set(ctx, geocodeKey+key, data, domain.BatchGeocodeCacheTTL).Err()
+}
+
+// GetCachedGeocode gets an address's cached geocoding result
+func (p *DriverPool) GetCachedGeocode(ctx context.Context, key string) (*domain.GeocodedAddress, error) {
+	data, err := p.client.Get(ctx, geocodeKey+key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	var result domain.GeocodedAddress
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	
+	return &result, nil
+}
+
+// CacheRouteOptions caches the route options quoted to a rider until they
+// confirm the ride
+func (p *DriverPool) CacheRouteOptions(ctx context.Context, quote *domain.RouteOptionsQuote) error {
+	data, err := json.Marshal(quote)
+	if err != nil {
+		return err
+	}
+	
+	return p.client.Set(ctx, routeOptionsKey+quote.ID.String(), data, domain.RouteOptionsTTL).Err()
+}
+
+// GetRouteOptions gets a route options quote, or nil once it has expired
+func (p *DriverPool) GetRouteOptions(ctx context.Context, quoteID uuid.UUID) (*domain.RouteOptionsQuote, error) {
+	data, err := p.client.Get(ctx, routeOptionsKey+quoteID.String()).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	var quote domain.RouteOptionsQuote
+	if err := json.Unmarshal(data, &quote); err != nil {
+		return nil, err
+	}
+	
+	return &quote, nil
+}
+
+// MarkRouteDeviation reports whether a ride's deviation should be recorded,
+// allowing one per ride per cooldown
+func (p *DriverPool) MarkRouteDeviation(ctx context.Context, rideID uuid.UUID) (bool, error) {
+	return p.client.SetNX(ctx, routeDeviationKey+rideID.String(), "1", domain.RouteDeviationCooldown).Result()
+}
+
+// Traffic incident helpers
+
+// SaveIncident stores an incident for every instance to see until it
+// expires or is resolved
+func (p *DriverPool) SaveIncident(ctx context.Context, incident *domain.TrafficIncident) error {
+	data, err := json.Marshal(incident)
+	if err != nil {
+		return err
+	}
+	
+	return p.client.HSet(ctx, incidentsKey, incident.ID.String(), data).Err()
+}
+
+// ListIncidents gets the incidents in force, dropping any that have expired
+func (p *DriverPool) ListIncidents(ctx context.Context) ([]*domain.TrafficIncident, error) {
+	entries, err := p.client.HGetAll(ctx, incidentsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	
+	now := time.Now()
+	incidents := make([]*domain.TrafficIncident, 0, len(entries))
+	var expired []string
+	for id, data := range entries {
+		var incident domain.TrafficIncident
+		if err := json.Unmarshal([]byte(data), &incident); err != nil || !incident.IsActive(now) {
+			expired = append(expired, id)
+			continue
+		}
+		incidents = append(incidents, &incident)
+	}
+	
+	if len(expired) > 0 {
+		p.client.HDel(ctx, incidentsKey, expired...)
+	}
+	return incidents, nil
+}
+
+// GetIncident gets an incident in force, or nil once it has expired or
+// been resolved
+func (p *DriverPool) GetIncident(ctx context.Context, incidentID uuid.UUID) (*domain.TrafficIncident, error) {
+	data, err := p.client.HGet(ctx, incidentsKey, incidentID.String()).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	var incident domain.TrafficIncident
+	if err := json.Unmarshal(data, &incident); err != nil {
+		return nil, err
+	}
+	if !incident.IsActive(time.Now()) {
+		return nil, nil
+	}
+	return &incident, nil
+}
+
+// DeleteIncident removes a resolved incident, reporting whether it was
+// still stored
+func (p *DriverPool) DeleteIncident(ctx context.Context, incidentID uuid.UUID) (bool, error) {
+	removed, err := p.client.HDel(ctx, incidentsKey, incidentID.String()).Result()
+	return removed > 0, err
+}
+
+// PopIncidentReport waits up to timeout for the next incident report
+// queued by an external feed, returning nil when none arrives
+func (p *DriverPool) PopIncidentReport(ctx context.Context, timeout time.Duration) ([]byte, error) {
+	result, err := p.client.BRPop(ctx, timeout, incidentReportsKey).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	// BRPOP returns the key and the value
+	return []byte(result[1]), nil
+}
+
+// MarkRerouteSuggested reports whether a ride should be offered a way
+// around an incident, allowing one suggestion per ride per incident
+func (p *DriverPool) MarkRerouteSuggested(ctx context.Context, rideID, incidentID uuid.UUID, ttl time.Duration) (bool, error) {
+	return p.client.SetNX(ctx, rerouteKey+rideID.String()+":"+incidentID.String(), "1", ttl).Result()
+}
+
+// Road report helpers
+
+// SaveRoadReport stores a driver's road report, kept an hour past its
+// expiry so the driver can see how it ended
+func (p *DriverPool) SaveRoadReport(ctx context.Context, report *domain.RoadReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	
+	ttl := time.Until(report.ExpiresAt) + time.Hour
+	listKey := roadReportsKey + report.DriverID.String()
+	
+	pipe := p.client.Pipeline()
+	pipe.Set(ctx, roadReportKey+report.ID.String(), data, ttl)
+	pipe.LRem(ctx, listKey, 0, report.ID.String())
+	pipe.LPush(ctx, listKey, report.ID.String())
+	pipe.LTrim(ctx, listKey, 0, domain.RoadReportHistory-1)
+	pipe.Expire(ctx, listKey, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetRoadReport gets a road report, or nil once it is no longer kept
+func (p *DriverPool) GetRoadReport(ctx context.Context, reportID uuid.UUID) (*domain.RoadReport, error) {
+	data, err := p.client.Get(ctx, roadReportKey+reportID.String()).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	var report domain.RoadReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ListRoadReports gets a driver's kept road reports, newest first
+func (p *DriverPool) ListRoadReports(ctx context.Context, driverID uuid.UUID) ([]*domain.RoadReport, error) {
+	ids, err := p.client.LRange(ctx, roadReportsKey+driverID.String(), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	
+	reports := make([]*domain.RoadReport, 0, len(ids))
+	if len(ids) == 0 {
+		return reports, nil
+	}
+	
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = roadReportKey + id
+	}
+	values, err := p.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var report domain.RoadReport
+		if err := json.Unmarshal([]byte(data), &report); err == nil {
+			reports = append(reports, &report)
+		}
+	}
+	return reports, nil
+}
+
+// MarkRoadReporter reports whether a driver's report should count toward
+// a cell's score, allowing one per driver per cell and category each
+// half-life so no driver can make an incident alone
+func (p *DriverPool) MarkRoadReporter(ctx context.Context, cell string, category domain.RoadReportCategory, driverID uuid.UUID) (bool, error) {
+	key := roadReporterKey + cell + ":" + string(category) + ":" + driverID.String()
+	return p.client.SetNX(ctx, key, "1", domain.RoadReportHalfLife).Result()
+}
+
+// AddRoadCellScore decays a cell's score for a category and adds weight,
+// returning the new score
+func (p *DriverPool) AddRoadCellScore(ctx context.Context, cell string, category domain.RoadReportCategory, weight float64) (float64, error) {
+	key := roadCellKey + cell + ":" + string(category)
+	var score domain.RoadCellScore
+	
+	err := p.client.Watch(ctx, func(tx *redis.Tx) error {
+		score = domain.RoadCellScore{}
+		data, err := tx.Get(ctx, key).Bytes()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if err == nil {
+			if err := json.Unmarshal(data, &score); err != nil {
+				return err
+			}
+		}
+		
+		score.Add(weight, time.Now())
+		updated, err := json.Marshal(score)
+		if err != nil {
+			return err
+		}
+		
+		// A score is negligible after six half-lives
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, updated, 6*domain.RoadReportHalfLife)
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil {
+		return 0, err
+	}
+	return score.Score, nil
+}
+
+// SetRoadIncident records the incident reports in a cell became, until it
+// expires
+func (p *DriverPool) SetRoadIncident(ctx context.Context, cell string, category domain.RoadReportCategory, incidentID uuid.UUID, ttl time.Duration) error {
+	return p.client.Set(ctx, roadIncidentKey+cell+":"+string(category), incidentID.String(), ttl).Err()
+}
+
+// GetRoadIncident gets the incident reports in a cell became, or nil
+func (p *DriverPool) GetRoadIncident(ctx context.Context, cell string, category domain.RoadReportCategory) (*uuid.UUID, error) {
+	value, err := p.client.Get(ctx, roadIncidentKey+cell+":"+string(category)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	incidentID, err := uuid.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &incidentID, nil
+}
+
+// ClearRoadIncident forgets the incident reports in a cell became
+func (p *DriverPool) ClearRoadIncident(ctx context.Context, cell string, category domain.RoadReportCategory) error {
+	return p.client.Del(ctx, roadIncidentKey+cell+":"+string(category)).Err()
+}
+
+// ScheduleRoadReportPrompt queues a reporter to be asked whether their
+// report still stands
+func (p *DriverPool) ScheduleRoadReportPrompt(ctx context.Context, reportID uuid.UUID, at time.Time) error {
+	return p.client.ZAdd(ctx, roadPromptsKey, &redis.Z{
+		Score:  float64(at.Unix()),
+		Member: reportID.String(),
+	}).Err()
+}
+
+// ClaimDueRoadReportPrompts takes the prompts due by now off the queue.
+// Each is claimed by one instance only.
+func (p *DriverPool) ClaimDueRoadReportPrompts(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	members, err := p.client.ZRangeByScore(ctx, roadPromptsKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	
+	var due []uuid.UUID
+	for _, member := range members {
+		removed, err := p.client.ZRem(ctx, roadPromptsKey, member).Result()
+		if err != nil {
+			return due, err
+		}
+		if removed == 0 {
+			continue
+		}
+		if reportID, err := uuid.Parse(member); err == nil {
+			due = append(due, reportID)
+		}
+	}
+	return due, nil
+}
+
 // Matching helpers
 
 // SetMatchingLock sets a lock for ride matching
@@ -380,6 +996,17 @@ func (p *DriverPool) ReleaseMatchingLock(ctx context.Context, rideID uuid.UUID)
 	return p.client.Del(ctx, rideMatchingKey+rideID.String()).Err()
 }
 
+// SetRideRequestLock serializes a rider's ride requests so a double tap
+// sees the ride the first tap created
+func (p *DriverPool) SetRideRequestLock(ctx context.Context, riderID uuid.UUID) (bool, error) {
+	return p.client.SetNX(ctx, rideRequestLockKey+riderID.String(), "1", rideRequestLockTTL).Result()
+}
+
+// ReleaseRideRequestLock releases a rider's ride request lock
+func (p *DriverPool) ReleaseRideRequestLock(ctx context.Context, riderID uuid.UUID) error {
+	return p.client.Del(ctx, rideRequestLockKey+riderID.String()).Err()
+}
+
 // Analytics helpers
 
 // IncrementMetric increments a metric counter
@@ -407,3 +1034,211 @@ func (p *DriverPool) GetMetric(ctx context.Context, metric string, date time.Tim
 func (p *DriverPool) Ping(ctx context.Context) error {
 	return p.client.Ping(ctx).Err()
 }
+
+// killSwitchField is a switch's field in the kill switch hash, e.g. "LAG:RIDES"
+func killSwitchField(city string, feature domain.KillSwitchFeature) string {
+	return city + ":" + string(feature)
+}
+
+// SaveKillSwitch switches a feature off in a city, replacing any switch
+// already set for it
+func (p *DriverPool) SaveKillSwitch(ctx context.Context, sw *domain.KillSwitch) error {
+	data, err := json.Marshal(sw)
+	if err != nil {
+		return err
+	}
+	
+	return p.client.HSet(ctx, killSwitchesKey, killSwitchField(sw.City, sw.Feature), data).Err()
+}
+
+// GetKillSwitch gets the switch on a city's feature, or nil when the
+// feature is on. Switches past their re-enable time are treated as off
+// until the sweep removes them.
+func (p *DriverPool) GetKillSwitch(ctx context.Context, city string, feature domain.KillSwitchFeature) (*domain.KillSwitch, error) {
+	data, err := p.client.HGet(ctx, killSwitchesKey, killSwitchField(city, feature)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	var sw domain.KillSwitch
+	if err := json.Unmarshal(data, &sw); err != nil {
+		return nil, err
+	}
+	if !sw.IsActive(time.Now()) {
+		return nil, nil
+	}
+	return &sw, nil
+}
+
+// ListKillSwitches gets every stored switch, including any past their
+// re-enable time
+func (p *DriverPool) ListKillSwitches(ctx context.Context) ([]*domain.KillSwitch, error) {
+	entries, err := p.client.HGetAll(ctx, killSwitchesKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	
+	switches := make([]*domain.KillSwitch, 0, len(entries))
+	for field, data := range entries {
+		var sw domain.KillSwitch
+		if err := json.Unmarshal([]byte(data), &sw); err != nil {
+			p.client.HDel(ctx, killSwitchesKey, field)
+			continue
+		}
+		switches = append(switches, &sw)
+	}
+	return switches, nil
+}
+
+// DeleteKillSwitch switches a city's feature back on, reporting whether it
+// was still off. Only one caller sees true, so re-enables are audited once
+// across instances.
+func (p *DriverPool) DeleteKillSwitch(ctx context.Context, city string, feature domain.KillSwitchFeature) (bool, error) {
+	removed, err := p.client.HDel(ctx, killSwitchesKey, killSwitchField(city, feature)).Result()
+	return removed > 0, err
+}
+
+// SaveLogSettings stores the log levels and sampling rates every instance
+// should run with
+func (p *DriverPool) SaveLogSettings(ctx context.Context, settings logging.Settings) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	
+	return p.client.Set(ctx, logSettingsKey, data, 0).Err()
+}
+
+// GetLogSettings gets the stored log settings, or nil when none are set
+func (p *DriverPool) GetLogSettings(ctx context.Context) (*logging.Settings, error) {
+	data, err := p.client.Get(ctx, logSettingsKey).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	var settings logging.Settings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// DeleteLogSettings removes the stored log settings so every instance goes
+// back to the settings it started with
+func (p *DriverPool) DeleteLogSettings(ctx context.Context) error {
+	return p.client.Del(ctx, logSettingsKey).Err()
+}
+
+// Ride chat helpers
+
+// rideChatClosedField marks the entry that closes a ride's chat stream
+const rideChatClosedField = "closed"
+
+// AppendRideMessage adds a message to its ride's chat stream and sets the
+// ID it was stored under
+func (p *DriverPool) AppendRideMessage(ctx context.Context, msg *domain.RideMessage) error {
+	key := rideChatKey + msg.RideID.String()
+	
+	id, err := p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: domain.MaxRideMessages,
+		Approx: true,
+		Values: map[string]interface{}{
+			"sender_id": msg.SenderID.String(),
+			"sender":    string(msg.Sender),
+			"body":      msg.Body,
+			"sent_at":   msg.SentAt.Format(time.RFC3339Nano),
+		},
+	}).Result()
+	if err != nil {
+		return err
+	}
+	p.client.Expire(ctx, key, rideChatTTL)
+	
+	msg.ID = id
+	return nil
+}
+
+// ListRideMessages gets the messages in a ride's chat stream, reporting
+// whether the stream has been closed
+func (p *DriverPool) ListRideMessages(ctx context.Context, rideID uuid.UUID) ([]*domain.RideMessage, bool, error) {
+	entries, err := p.client.XRange(ctx, rideChatKey+rideID.String(), "-", "+").Result()
+	if err != nil {
+		return nil, false, err
+	}
+	messages, closed := parseRideMessages(rideID, entries)
+	return messages, closed, nil
+}
+
+// WaitForRideMessages waits up to block for messages after the given ID,
+// reporting whether the stream has been closed. It returns nothing when
+// none arrive in time.
+func (p *DriverPool) WaitForRideMessages(ctx context.Context, rideID uuid.UUID, after string, block time.Duration) ([]*domain.RideMessage, bool, error) {
+	if after == "" {
+		after = "0"
+	}
+	streams, err := p.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{rideChatKey + rideID.String(), after},
+		Block:   block,
+	}).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	
+	var messages []*domain.RideMessage
+	closed := false
+	for _, stream := range streams {
+		batch, end := parseRideMessages(rideID, stream.Messages)
+		messages = append(messages, batch...)
+		closed = closed || end
+	}
+	return messages, closed, nil
+}
+
+// CloseRideChat marks a ride's chat stream closed, so waiting readers
+// stop, and lets it expire shortly after
+func (p *DriverPool) CloseRideChat(ctx context.Context, rideID uuid.UUID) error {
+	key := rideChatKey + rideID.String()
+	
+	pipe := p.client.Pipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		Values: map[string]interface{}{rideChatClosedField: "1"},
+	})
+	pipe.Expire(ctx, key, rideChatClosedTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// parseRideMessages decodes chat stream entries, reporting whether they
+// include the closing entry
+func parseRideMessages(rideID uuid.UUID, entries []redis.XMessage) ([]*domain.RideMessage, bool) {
+	messages := make([]*domain.RideMessage, 0, len(entries))
+	closed := false
+	for _, entry := range entries {
+		if _, ok := entry.Values[rideChatClosedField]; ok {
+			closed = true
+			continue
+		}
+		
+		msg := &domain.RideMessage{ID: entry.ID, RideID: rideID}
+		senderID, _ := entry.Values["sender_id"].(string)
+		sender, _ := entry.Values["sender"].(string)
+		sentAt, _ := entry.Values["sent_at"].(string)
+		msg.Body, _ = entry.Values["body"].(string)
+		msg.SenderID, _ = uuid.Parse(senderID)
+		msg.Sender = domain.RideParty(sender)
+		msg.SentAt, _ = time.Parse(time.RFC3339Nano, sentAt)
+		messages = append(messages, msg)
+	}
+	return messages, closed
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// BackgroundCheckRepository handles driver background check data access
+type BackgroundCheckRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewBackgroundCheckRepository creates a new background check repository
+func NewBackgroundCheckRepository(pool *pgxpool.Pool) *BackgroundCheckRepository {
+	return &BackgroundCheckRepository{pool: pool}
+}
+
+const backgroundCheckColumns = `
+	id, driver_id, provider, external_id, country, status, detail,
+	completed_at, expires_at, created_at, updated_at`
+
+// Save inserts a check or, if the provider's check ID is already known,
+// updates its result. Providers retry webhooks, so this must be idempotent.
+func (r *BackgroundCheckRepository) Save(ctx context.Context, check *domain.BackgroundCheck) error {
+	query := `
+		INSERT INTO driver_background_checks (` + backgroundCheckColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (provider, external_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			detail = EXCLUDED.detail,
+			completed_at = EXCLUDED.completed_at,
+			expires_at = EXCLUDED.expires_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at`
+
+	return r.pool.QueryRow(ctx, query,
+		check.ID, check.DriverID, check.Provider, check.ExternalID, check.Country,
+		check.Status, check.Detail, check.CompletedAt, check.ExpiresAt,
+		check.CreatedAt, check.UpdatedAt,
+	).Scan(&check.ID, &check.CreatedAt)
+}
+
+// GetByExternalID gets a check by the provider's reference
+func (r *BackgroundCheckRepository) GetByExternalID(ctx context.Context, provider, externalID string) (*domain.BackgroundCheck, error) {
+	query := `
+		SELECT ` + backgroundCheckColumns + `
+		FROM driver_background_checks
+		WHERE provider = $1 AND external_id = $2`
+
+	check, err := r.scanCheck(r.pool.QueryRow(ctx, query, provider, externalID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrBackgroundCheckNotFound
+	}
+	return check, err
+}
+
+// GetLatestCompleted gets a driver's most recent finished check. A pending
+// re-check does not replace the result it is renewing.
+func (r *BackgroundCheckRepository) GetLatestCompleted(ctx context.Context, driverID uuid.UUID) (*domain.BackgroundCheck, error) {
+	query := `
+		SELECT ` + backgroundCheckColumns + `
+		FROM driver_background_checks
+		WHERE driver_id = $1 AND status <> 'PENDING'
+		ORDER BY completed_at DESC
+		LIMIT 1`
+
+	check, err := r.scanCheck(r.pool.QueryRow(ctx, query, driverID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrBackgroundCheckNotFound
+	}
+	return check, err
+}
+
+// ListByDriver lists a driver's checks, newest first
+func (r *BackgroundCheckRepository) ListByDriver(ctx context.Context, driverID uuid.UUID) ([]*domain.BackgroundCheck, error) {
+	query := `
+		SELECT ` + backgroundCheckColumns + `
+		FROM driver_background_checks
+		WHERE driver_id = $1
+		ORDER BY created_at DESC`
+
+	return r.queryChecks(ctx, query, driverID)
+}
+
+// ListDueForRecheck lists clear checks expiring before the given time for
+// which no newer check has been started
+func (r *BackgroundCheckRepository) ListDueForRecheck(ctx context.Context, before time.Time, limit int) ([]*domain.BackgroundCheck, error) {
+	query := `
+		SELECT ` + backgroundCheckColumns + `
+		FROM driver_background_checks c
+		WHERE c.status = 'CLEAR' AND c.expires_at <= $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM driver_background_checks n
+			WHERE n.driver_id = c.driver_id AND n.created_at > c.created_at
+		  )
+		ORDER BY c.expires_at ASC
+		LIMIT $2`
+
+	return r.queryChecks(ctx, query, before, limit)
+}
+
+// ExpireLapsed marks clear checks past their expiry as expired and returns
+// the affected drivers
+func (r *BackgroundCheckRepository) ExpireLapsed(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	query := `
+		UPDATE driver_background_checks
+		SET status = 'EXPIRED', updated_at = $1
+		WHERE status = 'CLEAR' AND expires_at <= $1
+		RETURNING driver_id`
+
+	rows, err := r.pool.Query(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	driverIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var driverID uuid.UUID
+		if err := rows.Scan(&driverID); err != nil {
+			return nil, err
+		}
+		driverIDs = append(driverIDs, driverID)
+	}
+
+	return driverIDs, rows.Err()
+}
+
+func (r *BackgroundCheckRepository) queryChecks(ctx context.Context, query string, args ...interface{}) ([]*domain.BackgroundCheck, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := make([]*domain.BackgroundCheck, 0)
+	for rows.Next() {
+		check, err := r.scanCheck(rows)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, rows.Err()
+}
+
+func (r *BackgroundCheckRepository) scanCheck(row pgx.Row) (*domain.BackgroundCheck, error) {
+	var check domain.BackgroundCheck
+	var detail sql.NullString
+
+	err := row.Scan(
+		&check.ID, &check.DriverID, &check.Provider, &check.ExternalID, &check.Country,
+		&check.Status, &detail, &check.CompletedAt, &check.ExpiresAt,
+		&check.CreatedAt, &check.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	check.Detail = detail.String
+
+	return &check, nil
+}
+
+// CreateBackgroundCheckTables creates the background check table (for testing/migrations)
+func (r *BackgroundCheckRepository) CreateBackgroundCheckTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS driver_background_checks (
+			id UUID PRIMARY KEY,
+			driver_id UUID NOT NULL,
+			provider VARCHAR(50) NOT NULL,
+			external_id VARCHAR(100) NOT NULL,
+			country CHAR(2) NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			detail TEXT,
+			completed_at TIMESTAMPTZ,
+			expires_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			UNIQUE (provider, external_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_background_checks_driver ON driver_background_checks(driver_id, created_at);
+		CREATE INDEX IF NOT EXISTS idx_background_checks_expiry ON driver_background_checks(expires_at) WHERE status = 'CLEAR';
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
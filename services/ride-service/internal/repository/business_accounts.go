@@ -0,0 +1,328 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// BusinessRepository stores business accounts, their members and the rides
+// taken on them
+type BusinessRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewBusinessRepository creates a new business repository
+func NewBusinessRepository(pool *pgxpool.Pool) *BusinessRepository {
+	return &BusinessRepository{pool: pool}
+}
+
+const businessAccountColumns = `
+	a.id, a.name, a.cost_centers, a.monthly_invoicing, a.policy,
+	a.active, a.created_by, a.created_at, a.updated_at`
+
+// CreateAccount stores a new business account
+func (r *BusinessRepository) CreateAccount(ctx context.Context, account *domain.BusinessAccount) error {
+	policyJSON, err := json.Marshal(account.Policy)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO business_accounts (
+			id, name, cost_centers, monthly_invoicing, policy,
+			active, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		account.ID, account.Name, account.CostCenters, account.MonthlyInvoicing, policyJSON,
+		account.Active, account.CreatedBy, account.CreatedAt, account.UpdatedAt,
+	)
+	return err
+}
+
+// GetAccount gets a business account by ID
+func (r *BusinessRepository) GetAccount(ctx context.Context, id uuid.UUID) (*domain.BusinessAccount, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+businessAccountColumns+` FROM business_accounts a WHERE a.id = $1`, id)
+	return scanBusinessAccount(row)
+}
+
+// ListAccounts lists business accounts by name
+func (r *BusinessRepository) ListAccounts(ctx context.Context) ([]*domain.BusinessAccount, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+businessAccountColumns+` FROM business_accounts a ORDER BY a.name, a.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := make([]*domain.BusinessAccount, 0)
+	for rows.Next() {
+		account, err := scanBusinessAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// UpdateAccount saves an account's name, cost centers, invoicing, policy and
+// status. Members' default cost centers the account no longer has are
+// cleared.
+func (r *BusinessRepository) UpdateAccount(ctx context.Context, account *domain.BusinessAccount) error {
+	policyJSON, err := json.Marshal(account.Policy)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE business_accounts SET
+			name = $2, cost_centers = $3, monthly_invoicing = $4, policy = $5,
+			active = $6, updated_at = $7
+		WHERE id = $1`,
+		account.ID, account.Name, account.CostCenters, account.MonthlyInvoicing, policyJSON,
+		account.Active, account.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrBusinessAccountNotFound
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE business_members SET default_cost_center = ''
+		WHERE account_id = $1 AND default_cost_center <> '' AND NOT default_cost_center = ANY($2)`,
+		account.ID, account.CostCenters,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SaveMember adds a rider to an account, or updates their membership
+func (r *BusinessRepository) SaveMember(ctx context.Context, member *domain.BusinessMember) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO business_members (account_id, rider_id, role, default_cost_center, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (account_id, rider_id) DO UPDATE SET
+			role = EXCLUDED.role,
+			default_cost_center = EXCLUDED.default_cost_center`,
+		member.AccountID, member.RiderID, member.Role, member.DefaultCostCenter, member.CreatedAt,
+	)
+	return err
+}
+
+// RemoveMember removes a rider from an account
+func (r *BusinessRepository) RemoveMember(ctx context.Context, accountID, riderID uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, `
+		DELETE FROM business_members WHERE account_id = $1 AND rider_id = $2`,
+		accountID, riderID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotBusinessMember
+	}
+	return nil
+}
+
+// GetMember gets a rider's membership of an account
+func (r *BusinessRepository) GetMember(ctx context.Context, accountID, riderID uuid.UUID) (*domain.BusinessMember, error) {
+	m := &domain.BusinessMember{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT account_id, rider_id, role, default_cost_center, created_at
+		FROM business_members
+		WHERE account_id = $1 AND rider_id = $2`,
+		accountID, riderID,
+	).Scan(&m.AccountID, &m.RiderID, &m.Role, &m.DefaultCostCenter, &m.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotBusinessMember
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+// ListMembers lists an account's members, oldest first
+func (r *BusinessRepository) ListMembers(ctx context.Context, accountID uuid.UUID) ([]*domain.BusinessMember, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT account_id, rider_id, role, default_cost_center, created_at
+		FROM business_members
+		WHERE account_id = $1
+		ORDER BY created_at, rider_id`,
+		accountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make([]*domain.BusinessMember, 0)
+	for rows.Next() {
+		m := &domain.BusinessMember{}
+		if err := rows.Scan(&m.AccountID, &m.RiderID, &m.Role, &m.DefaultCostCenter, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// ListProfiles lists the business accounts a rider can ride on
+func (r *BusinessRepository) ListProfiles(ctx context.Context, riderID uuid.UUID) ([]*domain.BusinessProfile, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+businessAccountColumns+`, m.role, m.default_cost_center
+		FROM business_members m
+		JOIN business_accounts a ON a.id = m.account_id
+		WHERE m.rider_id = $1
+		ORDER BY a.name, a.id`,
+		riderID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := make([]*domain.BusinessProfile, 0)
+	for rows.Next() {
+		a := &domain.BusinessAccount{}
+		p := &domain.BusinessProfile{Account: a}
+		var policyJSON []byte
+		err := rows.Scan(
+			&a.ID, &a.Name, &a.CostCenters, &a.MonthlyInvoicing, &policyJSON,
+			&a.Active, &a.CreatedBy, &a.CreatedAt, &a.UpdatedAt,
+			&p.Role, &p.DefaultCostCenter,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(policyJSON, &a.Policy); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+
+	return profiles, rows.Err()
+}
+
+// RecordRide records a ride taken on a business account
+func (r *BusinessRepository) RecordRide(ctx context.Context, ride *domain.BusinessRide) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO business_rides (ride_id, account_id, rider_id, cost_center, monthly_invoicing, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (ride_id) DO NOTHING`,
+		ride.RideID, ride.AccountID, ride.RiderID, ride.CostCenter, ride.MonthlyInvoicing, ride.CreatedAt,
+	)
+	return err
+}
+
+// ListStatementLines lists an account's rides completed in [from, to), in
+// the order they completed
+func (r *BusinessRepository) ListStatementLines(ctx context.Context, accountID uuid.UUID, from, to time.Time) ([]domain.BusinessStatementLine, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT b.ride_id, b.rider_id, b.cost_center, r.type, r.completed_at,
+			COALESCE((r.price->>'total')::BIGINT, 0), COALESCE(r.price->>'currency', ''),
+			b.monthly_invoicing
+		FROM business_rides b
+		JOIN rides r ON r.id = b.ride_id
+		WHERE b.account_id = $1
+			AND r.status = 'COMPLETED'
+			AND r.completed_at >= $2 AND r.completed_at < $3
+		ORDER BY r.completed_at, b.ride_id`,
+		accountID, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := make([]domain.BusinessStatementLine, 0)
+	for rows.Next() {
+		var l domain.BusinessStatementLine
+		err := rows.Scan(&l.RideID, &l.RiderID, &l.CostCenter, &l.Type, &l.CompletedAt, &l.Total, &l.Currency, &l.Invoiced)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+
+	return lines, rows.Err()
+}
+
+func scanBusinessAccount(row pgx.Row) (*domain.BusinessAccount, error) {
+	a := &domain.BusinessAccount{}
+	var policyJSON []byte
+	err := row.Scan(
+		&a.ID, &a.Name, &a.CostCenters, &a.MonthlyInvoicing, &policyJSON,
+		&a.Active, &a.CreatedBy, &a.CreatedAt, &a.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBusinessAccountNotFound
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(policyJSON, &a.Policy); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// CreateBusinessTables creates the business account tables (for testing/migrations)
+func (r *BusinessRepository) CreateBusinessTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS business_accounts (
+			id UUID PRIMARY KEY,
+			name VARCHAR(200) NOT NULL,
+			cost_centers TEXT[] NOT NULL DEFAULT '{}',
+			monthly_invoicing BOOLEAN NOT NULL DEFAULT FALSE,
+			policy JSONB NOT NULL DEFAULT '{}'::jsonb,
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_by UUID NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS business_members (
+			account_id UUID NOT NULL REFERENCES business_accounts(id) ON DELETE CASCADE,
+			rider_id UUID NOT NULL,
+			role VARCHAR(20) NOT NULL,
+			default_cost_center VARCHAR(100) NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (account_id, rider_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_business_members_rider ON business_members(rider_id);
+
+		CREATE TABLE IF NOT EXISTS business_rides (
+			ride_id UUID PRIMARY KEY REFERENCES rides(id),
+			account_id UUID NOT NULL REFERENCES business_accounts(id),
+			rider_id UUID NOT NULL,
+			cost_center VARCHAR(100) NOT NULL DEFAULT '',
+			monthly_invoicing BOOLEAN NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_business_rides_account ON business_rides(account_id, created_at);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// CityConfigRepository stores city bundles edited through the admin API
+type CityConfigRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewCityConfigRepository creates a new city config repository
+func NewCityConfigRepository(pool *pgxpool.Pool) *CityConfigRepository {
+	return &CityConfigRepository{pool: pool}
+}
+
+// Upsert saves a city bundle, replacing any stored version
+func (r *CityConfigRepository) Upsert(ctx context.Context, city *domain.CityConfig, updatedBy uuid.UUID) error {
+	configJSON, err := json.Marshal(city)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO city_configs (code, config, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (code) DO UPDATE SET
+			config = EXCLUDED.config,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at`
+
+	_, err = r.pool.Exec(ctx, query, city.Code, configJSON, updatedBy, city.UpdatedAt)
+	return err
+}
+
+// List returns all stored city bundles
+func (r *CityConfigRepository) List(ctx context.Context) ([]*domain.CityConfig, error) {
+	rows, err := r.pool.Query(ctx, `SELECT config FROM city_configs ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cities := make([]*domain.CityConfig, 0)
+	for rows.Next() {
+		var configJSON []byte
+		if err := rows.Scan(&configJSON); err != nil {
+			return nil, err
+		}
+
+		var city domain.CityConfig
+		if err := json.Unmarshal(configJSON, &city); err != nil {
+			return nil, err
+		}
+		cities = append(cities, &city)
+	}
+
+	return cities, rows.Err()
+}
+
+// CreateCityConfigTables creates the city config table (for testing/migrations)
+func (r *CityConfigRepository) CreateCityConfigTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS city_configs (
+			code VARCHAR(50) PRIMARY KEY,
+			config JSONB NOT NULL,
+			updated_by UUID,
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
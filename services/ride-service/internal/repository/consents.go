@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// ConsentRepository handles users' data consents. The current choice per
+// purpose is kept alongside an append-only log of every change.
+type ConsentRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewConsentRepository creates a new consent repository
+func NewConsentRepository(pool *pgxpool.Pool) *ConsentRepository {
+	return &ConsentRepository{pool: pool}
+}
+
+// Save stores a user's choices and logs each one, in one transaction
+func (r *ConsentRepository) Save(ctx context.Context, consents []*domain.Consent) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, c := range consents {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO user_consents (user_id, purpose, granted, version, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id, purpose) DO UPDATE SET
+				granted = EXCLUDED.granted,
+				version = EXCLUDED.version,
+				updated_at = EXCLUDED.updated_at`,
+			c.UserID, c.Purpose, c.Granted, c.Version, c.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO user_consent_log (user_id, purpose, granted, version, recorded_at)
+			VALUES ($1, $2, $3, $4, $5)`,
+			c.UserID, c.Purpose, c.Granted, c.Version, c.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListByUser lists a user's current choices
+func (r *ConsentRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Consent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT user_id, purpose, granted, version, updated_at
+		FROM user_consents
+		WHERE user_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	consents := make([]*domain.Consent, 0)
+	for rows.Next() {
+		var c domain.Consent
+		if err := rows.Scan(&c.UserID, &c.Purpose, &c.Granted, &c.Version, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		consents = append(consents, &c)
+	}
+
+	return consents, rows.Err()
+}
+
+// ListByPurpose gets the choices the given users made for one purpose, by
+// user; users who never chose are missing
+func (r *ConsentRepository) ListByPurpose(ctx context.Context, purpose domain.ConsentPurpose, userIDs []uuid.UUID) (map[uuid.UUID]*domain.Consent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT user_id, purpose, granted, version, updated_at
+		FROM user_consents
+		WHERE purpose = $1 AND user_id = ANY($2)`,
+		purpose, userIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	consents := make(map[uuid.UUID]*domain.Consent)
+	for rows.Next() {
+		var c domain.Consent
+		if err := rows.Scan(&c.UserID, &c.Purpose, &c.Granted, &c.Version, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		consents[c.UserID] = &c
+	}
+
+	return consents, rows.Err()
+}
+
+// CreateConsentTables creates the consent tables (for testing/migrations)
+func (r *ConsentRepository) CreateConsentTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS user_consents (
+			user_id UUID NOT NULL,
+			purpose VARCHAR(40) NOT NULL,
+			granted BOOLEAN NOT NULL,
+			version INTEGER NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (user_id, purpose)
+		);
+
+		CREATE TABLE IF NOT EXISTS user_consent_log (
+			id BIGSERIAL PRIMARY KEY,
+			user_id UUID NOT NULL,
+			purpose VARCHAR(40) NOT NULL,
+			granted BOOLEAN NOT NULL,
+			version INTEGER NOT NULL,
+			recorded_at TIMESTAMPTZ NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_user_consent_log_user ON user_consent_log(user_id, recorded_at);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
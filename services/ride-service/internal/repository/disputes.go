@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// DisputeRepository handles fare dispute data access
+type DisputeRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewDisputeRepository creates a new dispute repository
+func NewDisputeRepository(pool *pgxpool.Pool) *DisputeRepository {
+	return &DisputeRepository{pool: pool}
+}
+
+const disputeColumns = `
+	id, ride_id, rider_id, reason, comment, status, evidence, resolution, refund_id,
+	sla_due_at, resolved_by, resolved_at, created_at, updated_at`
+
+// Create inserts a new dispute
+func (r *DisputeRepository) Create(ctx context.Context, dispute *domain.FareDispute) error {
+	evidenceJSON, _ := json.Marshal(dispute.Evidence)
+
+	query := `
+		INSERT INTO ride_disputes (` + disputeColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	_, err := r.pool.Exec(ctx, query,
+		dispute.ID, dispute.RideID, dispute.RiderID, dispute.Reason, dispute.Comment, dispute.Status,
+		evidenceJSON, dispute.Resolution, dispute.RefundID,
+		dispute.SLADueAt, dispute.ResolvedBy, dispute.ResolvedAt, dispute.CreatedAt, dispute.UpdatedAt,
+	)
+	if err != nil && isUniqueViolation(err) {
+		return domain.ErrDisputeAlreadyOpen
+	}
+	return err
+}
+
+// GetByID retrieves a dispute by ID
+func (r *DisputeRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.FareDispute, error) {
+	query := `SELECT ` + disputeColumns + ` FROM ride_disputes WHERE id = $1`
+	return r.scanDispute(r.pool.QueryRow(ctx, query, id))
+}
+
+// GetLatestByRide retrieves the most recent dispute for a ride
+func (r *DisputeRepository) GetLatestByRide(ctx context.Context, rideID uuid.UUID) (*domain.FareDispute, error) {
+	query := `SELECT ` + disputeColumns + ` FROM ride_disputes WHERE ride_id = $1 ORDER BY created_at DESC LIMIT 1`
+	return r.scanDispute(r.pool.QueryRow(ctx, query, rideID))
+}
+
+// LinkRefund records the refund issued for a dispute still open
+func (r *DisputeRepository) LinkRefund(ctx context.Context, dispute *domain.FareDispute) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE ride_disputes SET refund_id = $2, updated_at = $3
+		WHERE id = $1 AND status = 'OPEN'`,
+		dispute.ID, dispute.RefundID, time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrDisputeNotOpen
+	}
+	return nil
+}
+
+// HasResolved reports whether a ride has a dispute that was upheld, by
+// support or automatically
+func (r *DisputeRepository) HasResolved(ctx context.Context, rideID uuid.UUID) (bool, error) {
+	var resolved bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM ride_disputes
+			WHERE ride_id = $1 AND status IN ('RESOLVED', 'AUTO_RESOLVED')
+		)`, rideID).Scan(&resolved)
+	return resolved, err
+}
+
+// ListByStatus lists disputes in a status, earliest SLA deadline first
+func (r *DisputeRepository) ListByStatus(ctx context.Context, status domain.DisputeStatus, limit, offset int) ([]*domain.FareDispute, int64, error) {
+	var total int64
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM ride_disputes WHERE status = $1`, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + disputeColumns + `
+		FROM ride_disputes
+		WHERE status = $1
+		ORDER BY sla_due_at ASC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.pool.Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	disputes := make([]*domain.FareDispute, 0)
+	for rows.Next() {
+		dispute, err := r.scanDispute(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		disputes = append(disputes, dispute)
+	}
+
+	return disputes, total, rows.Err()
+}
+
+// Resolve records the outcome of an open dispute
+func (r *DisputeRepository) Resolve(ctx context.Context, dispute *domain.FareDispute) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE ride_disputes SET
+			status = $2,
+			resolution = $3,
+			refund_id = $4,
+			resolved_by = $5,
+			resolved_at = $6,
+			updated_at = $7
+		WHERE id = $1 AND status = 'OPEN'`,
+		dispute.ID, dispute.Status, dispute.Resolution, dispute.RefundID,
+		dispute.ResolvedBy, dispute.ResolvedAt, time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrDisputeNotOpen
+	}
+	return nil
+}
+
+func (r *DisputeRepository) scanDispute(row pgx.Row) (*domain.FareDispute, error) {
+	var dispute domain.FareDispute
+	var comment, resolution sql.NullString
+	var evidenceJSON []byte
+
+	err := row.Scan(
+		&dispute.ID, &dispute.RideID, &dispute.RiderID, &dispute.Reason, &comment, &dispute.Status,
+		&evidenceJSON, &resolution, &dispute.RefundID,
+		&dispute.SLADueAt, &dispute.ResolvedBy, &dispute.ResolvedAt, &dispute.CreatedAt, &dispute.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDisputeNotFound
+		}
+		return nil, err
+	}
+
+	dispute.Comment = comment.String
+	dispute.Resolution = resolution.String
+	if len(evidenceJSON) > 0 {
+		var evidence domain.DisputeEvidence
+		if err := json.Unmarshal(evidenceJSON, &evidence); err == nil {
+			dispute.Evidence = &evidence
+		}
+	}
+
+	return &dispute, nil
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// CreateDisputeTables creates the dispute table (for testing/migrations)
+func (r *DisputeRepository) CreateDisputeTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS ride_disputes (
+			id UUID PRIMARY KEY,
+			ride_id UUID NOT NULL REFERENCES rides(id),
+			rider_id UUID NOT NULL,
+			reason VARCHAR(50) NOT NULL,
+			comment TEXT,
+			status VARCHAR(30) NOT NULL,
+			evidence JSONB,
+			resolution TEXT,
+			refund_id UUID,
+			sla_due_at TIMESTAMPTZ NOT NULL,
+			resolved_by UUID,
+			resolved_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		-- At most one open dispute per ride
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_ride_disputes_open ON ride_disputes(ride_id) WHERE status = 'OPEN';
+		CREATE INDEX IF NOT EXISTS idx_ride_disputes_queue ON ride_disputes(status, sla_due_at);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+const searchDriversSQL = `
+	SELECT
+		d.id, d.user_id, d.status,
+		u.first_name, u.last_name, u.phone, u.profile_photo,
+		d.current_location, d.h3_cell, d.last_location_at,
+		d.heading, d.speed,
+		d.rating, d.total_rides, d.acceptance_rate,
+		d.current_ride_id, d.online_since,
+		d.created_at, d.updated_at,
+		v.id as vehicle_id, v.type as vehicle_type,
+		v.make, v.model, v.year, v.color, v.license_plate,
+		v.capacity, v.supported_types, v.powertrain
+	FROM drivers d
+	JOIN users u ON u.id = d.user_id
+	LEFT JOIN vehicles v ON v.driver_id = d.id AND v.is_active = true`
+
+// likeEscaper escapes the LIKE wildcards in a search term
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Search finds drivers matching every given criterion, by name, along with
+// how many match in all. Phones match on their trailing digits and plates
+// on their leading letters and digits. Drivers carry no city of their own,
+// so a driver is in a city when they hold a document issued for it, such
+// as its operating permit.
+func (r *DriverRepository) Search(ctx context.Context, search *domain.DriverSearch) ([]*domain.Driver, int64, error) {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if search.Phone != "" {
+		where(`regexp_replace(u.phone, '[^0-9]', '', 'g') LIKE '%' || ?`, search.Phone)
+	}
+	if search.Plate != "" {
+		where(`regexp_replace(UPPER(v.license_plate), '[^A-Z0-9]', '', 'g') LIKE ? || '%'`, search.Plate)
+	}
+	for _, word := range strings.Fields(search.Name) {
+		where(`(u.first_name || ' ' || u.last_name) ILIKE '%' || ? || '%'`, likeEscaper.Replace(word))
+	}
+	if search.Status != "" {
+		where(`d.status = ?`, search.Status)
+	}
+	if search.City != "" {
+		where(`EXISTS (SELECT 1 FROM driver_documents dd WHERE dd.driver_id = d.id AND LOWER(dd.city) = LOWER(?))`, search.City)
+	}
+
+	query := searchDriversSQL
+	if len(conditions) > 0 {
+		query += "\n\tWHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM (` + query + `) matches`
+	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count drivers: %w", err)
+	}
+
+	query += fmt.Sprintf("\n\tORDER BY u.first_name, u.last_name, d.id\n\tLIMIT %d OFFSET %d", search.Limit, search.Offset)
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search drivers: %w", err)
+	}
+	defer rows.Close()
+
+	drivers := make([]*domain.Driver, 0)
+	for rows.Next() {
+		driver, err := r.scanDriver(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		drivers = append(drivers, driver)
+	}
+
+	return drivers, total, rows.Err()
+}
+
+// UpdateVehicle saves an admin's correction to a driver's active vehicle
+// and its audit entry, in one transaction
+func (r *DriverRepository) UpdateVehicle(ctx context.Context, vehicle *domain.Vehicle, audit *domain.DriverAdminAudit) error {
+	supportedJSON, _ := json.Marshal(vehicle.SupportedTypes)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE vehicles SET
+			type = $3, make = $4, model = $5, year = $6, color = $7,
+			license_plate = $8, capacity = $9, supported_types = $10, powertrain = $11, updated_at = $12
+		WHERE id = $1 AND driver_id = $2 AND is_active = true`,
+		vehicle.ID, vehicle.DriverID, vehicle.Type, vehicle.Make, vehicle.Model, vehicle.Year, vehicle.Color,
+		vehicle.LicensePlate, vehicle.Capacity, supportedJSON, vehicle.Powertrain, vehicle.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: licence plate is registered to another vehicle", domain.ErrInvalidVehicleUpdate)
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrVehicleNotFound
+	}
+
+	if err := insertDriverAdminAudit(ctx, tx, audit); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// TakeOffline sets a driver offline and off any ride, provided their status
+// is still the one the admin saw, and stores the audit entry in the same
+// transaction
+func (r *DriverRepository) TakeOffline(ctx context.Context, driverID uuid.UUID, from domain.DriverStatus, audit *domain.DriverAdminAudit) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE drivers SET
+			status = 'OFFLINE',
+			current_ride_id = NULL,
+			online_since = NULL,
+			updated_at = $3
+		WHERE id = $1 AND status = $2`,
+		driverID, from, time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrDriverStatusChanged
+	}
+
+	if err := insertDriverAdminAudit(ctx, tx, audit); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func insertDriverAdminAudit(ctx context.Context, tx pgx.Tx, audit *domain.DriverAdminAudit) error {
+	changesJSON, _ := json.Marshal(audit.Changes)
+	_, err := tx.Exec(ctx, `
+		INSERT INTO driver_admin_actions (id, driver_id, action, reason, changes, admin_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		audit.ID, audit.DriverID, audit.Action, audit.Reason, changesJSON, audit.AdminID, audit.CreatedAt,
+	)
+	return err
+}
+
+// ListAdminActions gets the most recent admin changes to a driver's record,
+// newest first
+func (r *DriverRepository) ListAdminActions(ctx context.Context, driverID uuid.UUID, limit int) ([]*domain.DriverAdminAudit, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, driver_id, action, reason, changes, admin_id, created_at
+		FROM driver_admin_actions
+		WHERE driver_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`,
+		driverID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.DriverAdminAudit, 0)
+	for rows.Next() {
+		var audit domain.DriverAdminAudit
+		var changesJSON []byte
+		if err := rows.Scan(
+			&audit.ID, &audit.DriverID, &audit.Action, &audit.Reason, &changesJSON, &audit.AdminID, &audit.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changesJSON, &audit.Changes); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &audit)
+	}
+
+	return entries, rows.Err()
+}
+
+// CreateDriverAdminTables creates the driver admin audit table (for testing/migrations)
+func (r *DriverRepository) CreateDriverAdminTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS driver_admin_actions (
+			id UUID PRIMARY KEY,
+			driver_id UUID NOT NULL,
+			action VARCHAR(20) NOT NULL,
+			reason TEXT NOT NULL,
+			changes JSONB NOT NULL DEFAULT '[]'::jsonb,
+			admin_id UUID NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_driver_admin_actions_driver ON driver_admin_actions(driver_id, created_at DESC);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
+
+// ListRecentByDriver gets the rides most recently assigned to a driver,
+// newest first
+func (r *RideRepository) ListRecentByDriver(ctx context.Context, driverID uuid.UUID, limit int) ([]*domain.Ride, error) {
+	query := `
+		SELECT
+			id, rider_id, driver_id, vehicle_id,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
+			type, status, payment_method,
+			route, price,
+			scheduled_for, requested_at, accepted_at, arrived_at,
+			started_at, completed_at, cancelled_at,
+			cancellation_reason, cancelled_by,
+			rider_rating, driver_rating,
+			promo_code, metadata,
+			created_at, updated_at, version
+		FROM rides
+		WHERE driver_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, driverID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rides := make([]*domain.Ride, 0)
+	for rows.Next() {
+		ride, err := r.scanRideFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		rides = append(rides, ride)
+	}
+
+	return rides, rows.Err()
+}
+
+// GetDriverTripStats counts the rides assigned to a driver since a time,
+// and how many they completed and cancelled themselves
+func (r *RideRepository) GetDriverTripStats(ctx context.Context, driverID uuid.UUID, since time.Time) (*domain.DriverTripStats, error) {
+	stats := &domain.DriverTripStats{Since: since}
+	err := r.pool.QueryRow(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE r.status = 'COMPLETED'),
+			COUNT(*) FILTER (WHERE r.status = 'CANCELLED' AND r.cancelled_by = d.user_id)
+		FROM rides r
+		JOIN drivers d ON d.id = r.driver_id
+		WHERE r.driver_id = $1 AND r.created_at >= $2`,
+		driverID, since,
+	).Scan(&stats.Assigned, &stats.Completed, &stats.DriverCancelled)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.Assigned > 0 {
+		stats.CompletionRate = float64(stats.Completed) / float64(stats.Assigned)
+	}
+	return stats, nil
+}
+
+// ListByDriver gets the most recent SOS incidents on rides a driver was
+// assigned, newest first
+func (r *SafetyIncidentRepository) ListByDriver(ctx context.Context, driverID uuid.UUID, limit int) ([]*domain.SafetyIncident, error) {
+	query := `
+		SELECT ` + safetyIncidentColumns + `
+		FROM safety_incidents
+		WHERE ride_id IN (SELECT id FROM rides WHERE driver_id = $1)
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, driverID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	incidents := make([]*domain.SafetyIncident, 0)
+	for rows.Next() {
+		incident, err := r.scanIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+		incidents = append(incidents, incident)
+	}
+
+	return incidents, rows.Err()
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// DriverDocumentRepository handles driver document data access
+type DriverDocumentRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewDriverDocumentRepository creates a new driver document repository
+func NewDriverDocumentRepository(pool *pgxpool.Pool) *DriverDocumentRepository {
+	return &DriverDocumentRepository{pool: pool}
+}
+
+const driverDocumentColumns = `
+	id, driver_id, type, country, city, number, expires_at,
+	last_reminder_days, created_at, updated_at`
+
+// Save records a driver's current document of a type, replacing the one it
+// renews. Reminders start over when the expiry date changes.
+func (r *DriverDocumentRepository) Save(ctx context.Context, doc *domain.DriverDocument) error {
+	query := `
+		INSERT INTO driver_documents (` + driverDocumentColumns + `)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, 0, $8, $9)
+		ON CONFLICT (driver_id, type) DO UPDATE SET
+			country = EXCLUDED.country,
+			city = EXCLUDED.city,
+			number = EXCLUDED.number,
+			last_reminder_days = CASE
+				WHEN driver_documents.expires_at = EXCLUDED.expires_at THEN driver_documents.last_reminder_days
+				ELSE 0
+			END,
+			expires_at = EXCLUDED.expires_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, last_reminder_days, created_at`
+
+	return r.pool.QueryRow(ctx, query,
+		doc.ID, doc.DriverID, doc.Type, doc.Country, doc.City, doc.Number, doc.ExpiresAt,
+		doc.CreatedAt, doc.UpdatedAt,
+	).Scan(&doc.ID, &doc.LastReminderDays, &doc.CreatedAt)
+}
+
+// ListByDriver lists a driver's documents, soonest to expire first
+func (r *DriverDocumentRepository) ListByDriver(ctx context.Context, driverID uuid.UUID) ([]*domain.DriverDocument, error) {
+	query := `
+		SELECT ` + driverDocumentColumns + `
+		FROM driver_documents
+		WHERE driver_id = $1
+		ORDER BY expires_at ASC`
+
+	return r.queryDocuments(ctx, query, driverID)
+}
+
+// ListExpiringBetween lists documents expiring in [from, to), soonest first
+func (r *DriverDocumentRepository) ListExpiringBetween(ctx context.Context, from, to time.Time, limit int) ([]*domain.DriverDocument, error) {
+	query := `
+		SELECT ` + driverDocumentColumns + `
+		FROM driver_documents
+		WHERE expires_at >= $1 AND expires_at < $2
+		ORDER BY expires_at ASC
+		LIMIT $3`
+
+	return r.queryDocuments(ctx, query, from, to, limit)
+}
+
+// ListExpiringBefore lists documents in a market expiring before a time,
+// including those already expired, soonest first. An empty city covers
+// the whole market.
+func (r *DriverDocumentRepository) ListExpiringBefore(ctx context.Context, country, city string, before time.Time, limit int) ([]*domain.DriverDocument, error) {
+	query := `
+		SELECT ` + driverDocumentColumns + `
+		FROM driver_documents
+		WHERE country = $1 AND ($2 = '' OR city = $2) AND expires_at < $3
+		ORDER BY expires_at ASC
+		LIMIT $4`
+
+	return r.queryDocuments(ctx, query, country, city, before, limit)
+}
+
+// MarkReminded records the reminder sent for a document's current expiry
+func (r *DriverDocumentRepository) MarkReminded(ctx context.Context, id uuid.UUID, days int) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE driver_documents SET last_reminder_days = $2 WHERE id = $1`,
+		id, days,
+	)
+	return err
+}
+
+func (r *DriverDocumentRepository) queryDocuments(ctx context.Context, query string, args ...interface{}) ([]*domain.DriverDocument, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := make([]*domain.DriverDocument, 0)
+	for rows.Next() {
+		doc, err := r.scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, rows.Err()
+}
+
+func (r *DriverDocumentRepository) scanDocument(row pgx.Row) (*domain.DriverDocument, error) {
+	var doc domain.DriverDocument
+	var city, number sql.NullString
+
+	err := row.Scan(
+		&doc.ID, &doc.DriverID, &doc.Type, &doc.Country, &city, &number, &doc.ExpiresAt,
+		&doc.LastReminderDays, &doc.CreatedAt, &doc.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	doc.City = city.String
+	doc.Number = number.String
+
+	return &doc, nil
+}
+
+// CreateDriverDocumentTables creates the driver document table (for testing/migrations)
+func (r *DriverDocumentRepository) CreateDriverDocumentTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS driver_documents (
+			id UUID PRIMARY KEY,
+			driver_id UUID NOT NULL,
+			type VARCHAR(30) NOT NULL,
+			country CHAR(2) NOT NULL,
+			city VARCHAR(50),
+			number VARCHAR(50),
+			expires_at TIMESTAMPTZ NOT NULL,
+			last_reminder_days INT NOT NULL DEFAULT 0,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			UNIQUE (driver_id, type)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_driver_documents_expiry ON driver_documents(expires_at);
+		CREATE INDEX IF NOT EXISTS idx_driver_documents_market ON driver_documents(country, city, expires_at);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
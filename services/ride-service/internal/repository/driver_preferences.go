@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// DriverPreferenceRepository handles riders' favorite and blocked drivers
+type DriverPreferenceRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewDriverPreferenceRepository creates a new driver preference repository
+func NewDriverPreferenceRepository(pool *pgxpool.Pool) *DriverPreferenceRepository {
+	return &DriverPreferenceRepository{pool: pool}
+}
+
+// Save stores a rider's preference for a driver. A block replaces a
+// favorite; a favorite returns ErrDriverBlocked if the driver is blocked.
+func (r *DriverPreferenceRepository) Save(ctx context.Context, pref *domain.DriverPreference) error {
+	result, err := r.pool.Exec(ctx, `
+		INSERT INTO rider_driver_preferences (rider_id, driver_id, kind, ride_id, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (rider_id, driver_id) DO UPDATE SET
+			kind = EXCLUDED.kind,
+			ride_id = EXCLUDED.ride_id,
+			reason = EXCLUDED.reason,
+			created_at = EXCLUDED.created_at
+		WHERE EXCLUDED.kind = 'BLOCKED' OR rider_driver_preferences.kind <> 'BLOCKED'`,
+		pref.RiderID, pref.DriverID, pref.Kind, pref.RideID, pref.Reason, pref.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrDriverBlocked
+	}
+	return nil
+}
+
+// Delete removes a rider's favorite or block of a driver
+func (r *DriverPreferenceRepository) Delete(ctx context.Context, riderID, driverID uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, `
+		DELETE FROM rider_driver_preferences WHERE rider_id = $1 AND driver_id = $2`,
+		riderID, driverID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrDriverPreferenceNotFound
+	}
+	return nil
+}
+
+// ListByRider lists a rider's favorite and blocked drivers, newest first
+func (r *DriverPreferenceRepository) ListByRider(ctx context.Context, riderID uuid.UUID) ([]*domain.DriverPreference, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT rider_id, driver_id, kind, ride_id, reason, created_at
+		FROM rider_driver_preferences
+		WHERE rider_id = $1
+		ORDER BY created_at DESC`,
+		riderID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prefs := make([]*domain.DriverPreference, 0)
+	for rows.Next() {
+		var pref domain.DriverPreference
+		var reason sql.NullString
+		if err := rows.Scan(&pref.RiderID, &pref.DriverID, &pref.Kind, &pref.RideID, &reason, &pref.CreatedAt); err != nil {
+			return nil, err
+		}
+		pref.Reason = reason.String
+		prefs = append(prefs, &pref)
+	}
+
+	return prefs, rows.Err()
+}
+
+// IsBlocked reports whether a rider blocked a driver
+func (r *DriverPreferenceRepository) IsBlocked(ctx context.Context, riderID, driverID uuid.UUID) (bool, error) {
+	var blocked bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM rider_driver_preferences
+			WHERE rider_id = $1 AND driver_id = $2 AND kind = 'BLOCKED'
+		)`,
+		riderID, driverID,
+	).Scan(&blocked)
+	return blocked, err
+}
+
+// CreateDriverPreferenceTables creates the driver preference table (for testing/migrations)
+func (r *DriverPreferenceRepository) CreateDriverPreferenceTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS rider_driver_preferences (
+			rider_id UUID NOT NULL,
+			driver_id UUID NOT NULL,
+			kind VARCHAR(10) NOT NULL,
+			ride_id UUID NOT NULL REFERENCES rides(id),
+			reason TEXT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (rider_id, driver_id)
+		);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
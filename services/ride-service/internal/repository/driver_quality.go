@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// DriverQualityRepository handles driver quality program data access
+type DriverQualityRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewDriverQualityRepository creates a new driver quality repository
+func NewDriverQualityRepository(pool *pgxpool.Pool) *DriverQualityRepository {
+	return &DriverQualityRepository{pool: pool}
+}
+
+const driverQualityColumns = `
+	driver_id, period, metrics, scores, composite, tier, previous_tier, evaluated_at`
+
+// Save stores a driver's evaluation, replacing any earlier run's for the
+// same month
+func (r *DriverQualityRepository) Save(ctx context.Context, quality *domain.DriverQuality) error {
+	metricsJSON, _ := json.Marshal(quality.Metrics)
+	scoresJSON, _ := json.Marshal(quality.Scores)
+
+	query := `
+		INSERT INTO driver_quality_evaluations (` + driverQualityColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (driver_id, period) DO UPDATE SET
+			metrics = EXCLUDED.metrics,
+			scores = EXCLUDED.scores,
+			composite = EXCLUDED.composite,
+			tier = EXCLUDED.tier,
+			previous_tier = EXCLUDED.previous_tier,
+			evaluated_at = EXCLUDED.evaluated_at`
+
+	var previous *domain.QualityTier
+	if quality.PreviousTier != "" {
+		previous = &quality.PreviousTier
+	}
+
+	_, err := r.pool.Exec(ctx, query,
+		quality.DriverID, quality.Period, metricsJSON, scoresJSON, quality.Scores.Composite,
+		quality.Tier, previous, quality.EvaluatedAt,
+	)
+	return err
+}
+
+// GetLatest retrieves a driver's most recent evaluation
+func (r *DriverQualityRepository) GetLatest(ctx context.Context, driverID uuid.UUID) (*domain.DriverQuality, error) {
+	query := `
+		SELECT ` + driverQualityColumns + `
+		FROM driver_quality_evaluations
+		WHERE driver_id = $1
+		ORDER BY period DESC
+		LIMIT 1`
+
+	var quality domain.DriverQuality
+	var metricsJSON, scoresJSON []byte
+	var composite float64
+	var previous *string
+
+	err := r.pool.QueryRow(ctx, query, driverID).Scan(
+		&quality.DriverID, &quality.Period, &metricsJSON, &scoresJSON, &composite,
+		&quality.Tier, &previous, &quality.EvaluatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDriverQualityNotFound
+		}
+		return nil, err
+	}
+
+	json.Unmarshal(metricsJSON, &quality.Metrics)
+	json.Unmarshal(scoresJSON, &quality.Scores)
+	if previous != nil {
+		quality.PreviousTier = domain.QualityTier(*previous)
+	}
+	quality.Benefits = quality.Tier.Benefits()
+	return &quality, nil
+}
+
+// ListTiers returns each driver's tier from their latest evaluation before
+// a period, or their latest of all when before is empty, for the drivers
+// holding a tier above Standard
+func (r *DriverQualityRepository) ListTiers(ctx context.Context, before string) (map[uuid.UUID]domain.QualityTier, error) {
+	query := `
+		SELECT driver_id, tier FROM (
+			SELECT DISTINCT ON (driver_id) driver_id, tier
+			FROM driver_quality_evaluations
+			WHERE $1 = '' OR period < $1
+			ORDER BY driver_id, period DESC
+		) latest
+		WHERE tier <> 'STANDARD'`
+
+	rows, err := r.pool.Query(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tiers := make(map[uuid.UUID]domain.QualityTier)
+	for rows.Next() {
+		var driverID uuid.UUID
+		var tier domain.QualityTier
+		if err := rows.Scan(&driverID, &tier); err != nil {
+			return nil, err
+		}
+		tiers[driverID] = tier
+	}
+
+	return tiers, rows.Err()
+}
+
+// GatherMetrics collects drivers' quality records over [from, to), for one
+// driver or, when driverID is nil, every driver with a trip, complaint or
+// compliance issue in the period. Complaints are upheld disputes about the
+// driving and sanctions for rider complaints; compliance issues are
+// documents expired by the period's end and every other sanction.
+func (r *DriverQualityRepository) GatherMetrics(ctx context.Context, from, to time.Time, driverID *uuid.UUID) (map[uuid.UUID]*domain.QualityMetrics, error) {
+	metrics := make(map[uuid.UUID]*domain.QualityMetrics)
+	metricsFor := func(id uuid.UUID) *domain.QualityMetrics {
+		m, ok := metrics[id]
+		if !ok {
+			m = &domain.QualityMetrics{}
+			metrics[id] = m
+		}
+		return m
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT driver_id,
+			COUNT(*) FILTER (WHERE status = 'COMPLETED'),
+			COUNT(*) FILTER (WHERE status = 'CANCELLED' AND cancelled_by = driver_id),
+			COUNT(driver_rating) FILTER (WHERE status = 'COMPLETED'),
+			COALESCE(AVG(driver_rating) FILTER (WHERE status = 'COMPLETED'), 0)
+		FROM rides
+		WHERE driver_id IS NOT NULL
+			AND ($3::uuid IS NULL OR driver_id = $3)
+			AND ((status = 'COMPLETED' AND completed_at >= $1 AND completed_at < $2)
+				OR (status = 'CANCELLED' AND cancelled_at >= $1 AND cancelled_at < $2))
+		GROUP BY driver_id`,
+		from, to, driverID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var id uuid.UUID
+		var completed, cancelled, rated int
+		var rating float64
+		if err := rows.Scan(&id, &completed, &cancelled, &rated, &rating); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		m := metricsFor(id)
+		m.CompletedTrips = completed
+		m.Cancellations = cancelled
+		m.RatedTrips = rated
+		m.AverageRating = rating
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	complaints, err := r.countByDriver(ctx, `
+		SELECT driver_id, SUM(n)::int FROM (
+			SELECT r.driver_id, COUNT(*) AS n
+			FROM ride_disputes d
+			JOIN rides r ON r.id = d.ride_id
+			WHERE d.status = 'RESOLVED'
+				AND d.reason IN ('LONG_ROUTE', 'WRONG_DROPOFF', 'TRIP_NOT_TAKEN')
+				AND d.resolved_at >= $1 AND d.resolved_at < $2
+				AND r.driver_id IS NOT NULL
+				AND ($3::uuid IS NULL OR r.driver_id = $3)
+			GROUP BY r.driver_id
+			UNION ALL
+			SELECT driver_id, COUNT(*)
+			FROM driver_sanctions
+			WHERE reason = 'RIDER_COMPLAINTS'
+				AND created_at >= $1 AND created_at < $2
+				AND ($3::uuid IS NULL OR driver_id = $3)
+			GROUP BY driver_id
+		) c
+		GROUP BY driver_id`,
+		from, to, driverID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for id, n := range complaints {
+		metricsFor(id).Complaints = n
+	}
+
+	issues, err := r.countByDriver(ctx, `
+		SELECT driver_id, SUM(n)::int FROM (
+			SELECT driver_id, COUNT(*) AS n
+			FROM driver_documents
+			WHERE expires_at < $2
+				AND ($3::uuid IS NULL OR driver_id = $3)
+			GROUP BY driver_id
+			UNION ALL
+			SELECT driver_id, COUNT(*)
+			FROM driver_sanctions
+			WHERE reason <> 'RIDER_COMPLAINTS'
+				AND created_at >= $1 AND created_at < $2
+				AND ($3::uuid IS NULL OR driver_id = $3)
+			GROUP BY driver_id
+		) c
+		GROUP BY driver_id`,
+		from, to, driverID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for id, n := range issues {
+		metricsFor(id).ComplianceIssues = n
+	}
+
+	return metrics, nil
+}
+
+func (r *DriverQualityRepository) countByDriver(ctx context.Context, query string, args ...interface{}) (map[uuid.UUID]int, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var id uuid.UUID
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		counts[id] = n
+	}
+
+	return counts, rows.Err()
+}
+
+// CreateDriverQualityTables creates the driver quality table (for testing/migrations)
+func (r *DriverQualityRepository) CreateDriverQualityTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS driver_quality_evaluations (
+			driver_id UUID NOT NULL,
+			period CHAR(7) NOT NULL,
+			metrics JSONB NOT NULL,
+			scores JSONB NOT NULL,
+			composite DECIMAL(4,1) NOT NULL,
+			tier VARCHAR(20) NOT NULL,
+			previous_tier VARCHAR(20),
+			evaluated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (driver_id, period)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_driver_quality_period_tier ON driver_quality_evaluations(period, tier);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// GetDriverEarnings totals a driver's completed-ride earnings and driver
+// ledger adjustments in [from, to), one line per currency
+func (r *RideRepository) GetDriverEarnings(ctx context.Context, driverID uuid.UUID, from, to time.Time) ([]*domain.EarningsLine, error) {
+	lines := make(map[domain.Currency]*domain.EarningsLine)
+	line := func(currency domain.Currency) *domain.EarningsLine {
+		if l, ok := lines[currency]; ok {
+			return l
+		}
+		l := &domain.EarningsLine{Source: domain.EarningsSourceRides, Currency: currency}
+		lines[currency] = l
+		return l
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT price->>'currency', COUNT(*), COALESCE(SUM((price->>'driver_earnings')::bigint), 0)
+		FROM rides
+		WHERE driver_id = $1 AND status = 'COMPLETED'
+			AND completed_at >= $2 AND completed_at < $3
+			AND price IS NOT NULL
+		GROUP BY price->>'currency'`,
+		driverID, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var currency domain.Currency
+		var trips int
+		var fares int64
+		if err := rows.Scan(&currency, &trips, &fares); err != nil {
+			return nil, err
+		}
+		l := line(currency)
+		l.Trips, l.Fares = trips, fares
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	adjRows, err := r.pool.Query(ctx, `
+		SELECT currency, COALESCE(SUM(amount), 0)
+		FROM ride_ledger_entries
+		WHERE account = $1 AND account_id = $2
+			AND created_at >= $3 AND created_at < $4
+		GROUP BY currency`,
+		domain.LedgerAccountDriver, driverID, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer adjRows.Close()
+
+	for adjRows.Next() {
+		var currency domain.Currency
+		var amount int64
+		if err := adjRows.Scan(&currency, &amount); err != nil {
+			return nil, err
+		}
+		line(currency).Adjustments = amount
+	}
+	if err := adjRows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.EarningsLine, 0, len(lines))
+	for _, l := range lines {
+		result = append(result, l)
+	}
+	return result, nil
+}
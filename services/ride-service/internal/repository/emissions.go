@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// emissionsStatsSQL sums the emissions recorded on completed rides. The
+// caller adds the join and condition picking whose rides are summed, as
+// $1, with the range in $2 and $3.
+const emissionsStatsSQL = `
+	SELECT
+		COUNT(*),
+		COALESCE(SUM((r.metadata->'emissions'->>'distance_meters')::BIGINT), 0),
+		COALESCE(SUM((r.metadata->'emissions'->>'co2_grams')::BIGINT), 0),
+		COALESCE(SUM((r.metadata->'emissions'->>'saved_grams')::BIGINT), 0),
+		COUNT(*) FILTER (WHERE r.metadata->'emissions'->>'powertrain' IN ('ELECTRIC', 'HYBRID')),
+		COUNT(*) FILTER (WHERE r.metadata->>'ride_preference' = 'GREEN')
+	FROM rides r
+	%s
+	WHERE %s
+		AND r.status = 'COMPLETED'
+		AND r.metadata->'emissions' IS NOT NULL
+		AND ($2::TIMESTAMPTZ IS NULL OR r.completed_at >= $2)
+		AND ($3::TIMESTAMPTZ IS NULL OR r.completed_at < $3)`
+
+// EmissionsRepository sums the estimated emissions of completed rides
+type EmissionsRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewEmissionsRepository creates a new emissions repository
+func NewEmissionsRepository(pool *pgxpool.Pool) *EmissionsRepository {
+	return &EmissionsRepository{pool: pool}
+}
+
+// GetRiderStats sums the emissions of a rider's rides completed in
+// [from, to). Either end may be nil for an open range.
+func (r *EmissionsRepository) GetRiderStats(ctx context.Context, riderID uuid.UUID, from, to *time.Time) (*domain.EmissionsStats, error) {
+	return r.getStats(ctx, emissionsStats("", "r.rider_id = $1"), riderID, from, to)
+}
+
+// GetBusinessStats sums the emissions of rides taken on a business account
+// and completed in [from, to). Either end may be nil for an open range.
+func (r *EmissionsRepository) GetBusinessStats(ctx context.Context, accountID uuid.UUID, from, to *time.Time) (*domain.EmissionsStats, error) {
+	return r.getStats(ctx, emissionsStats("JOIN business_rides b ON b.ride_id = r.id", "b.account_id = $1"), accountID, from, to)
+}
+
+func emissionsStats(join, condition string) string {
+	return fmt.Sprintf(emissionsStatsSQL, join, condition)
+}
+
+func (r *EmissionsRepository) getStats(ctx context.Context, query string, id uuid.UUID, from, to *time.Time) (*domain.EmissionsStats, error) {
+	stats := &domain.EmissionsStats{From: from, To: to}
+	err := r.pool.QueryRow(ctx, query, id, from, to).Scan(
+		&stats.Rides, &stats.DistanceMeters, &stats.CO2Grams, &stats.SavedGrams,
+		&stats.LowEmissionRides, &stats.GreenRequested,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// CreateEmissionsTables adds vehicles' powertrain, which green rides are
+// matched on and emissions estimated with (for testing/migrations)
+func (r *EmissionsRepository) CreateEmissionsTables(ctx context.Context) error {
+	query := `
+		ALTER TABLE vehicles ADD COLUMN IF NOT EXISTS powertrain VARCHAR(20) NOT NULL DEFAULT 'ICE';
+		CREATE INDEX IF NOT EXISTS idx_rides_rider_completed ON rides(rider_id, completed_at) WHERE status = 'COMPLETED';
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// SaveFareSnapshot stores the inputs behind a ride's price
+func (r *RideRepository) SaveFareSnapshot(ctx context.Context, snapshot *domain.FareSnapshot) error {
+	snapshotJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO ride_fare_snapshots (id, ride_id, reason, snapshot, created_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		snapshot.ID, snapshot.RideID, snapshot.Reason, snapshotJSON, snapshot.CreatedAt,
+	)
+	return err
+}
+
+// ListFareSnapshots lists a ride's fare snapshots, oldest first
+func (r *RideRepository) ListFareSnapshots(ctx context.Context, rideID uuid.UUID) ([]*domain.FareSnapshot, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT snapshot FROM ride_fare_snapshots
+		WHERE ride_id = $1
+		ORDER BY created_at ASC`,
+		rideID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := make([]*domain.FareSnapshot, 0)
+	for rows.Next() {
+		var snapshotJSON []byte
+		if err := rows.Scan(&snapshotJSON); err != nil {
+			return nil, err
+		}
+
+		var snapshot domain.FareSnapshot
+		if err := json.Unmarshal(snapshotJSON, &snapshot); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
+
+// CreateFareSnapshotTables creates the fare snapshot table (for testing/migrations)
+func (r *RideRepository) CreateFareSnapshotTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS ride_fare_snapshots (
+			id UUID PRIMARY KEY,
+			ride_id UUID NOT NULL REFERENCES rides(id),
+			reason VARCHAR(30) NOT NULL,
+			snapshot JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_ride_fare_snapshots_ride_id ON ride_fare_snapshots(ride_id, created_at);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
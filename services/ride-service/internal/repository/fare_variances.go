@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// GetUnmeasuredCompletedRides gets rides completed since the given time that
+// have no fare variance recorded yet, oldest first
+func (r *RideRepository) GetUnmeasuredCompletedRides(ctx context.Context, since time.Time, limit int) ([]*domain.Ride, error) {
+	query := `
+		SELECT
+			id, rider_id, driver_id, vehicle_id,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
+			type, status, payment_method,
+			route, price,
+			scheduled_for, requested_at, accepted_at, arrived_at,
+			started_at, completed_at, cancelled_at,
+			cancellation_reason, cancelled_by,
+			rider_rating, driver_rating,
+			promo_code, metadata,
+			created_at, updated_at, version
+		FROM rides r
+		WHERE status = 'COMPLETED'
+			AND started_at IS NOT NULL
+			AND price IS NOT NULL
+			AND completed_at >= $1
+			AND NOT EXISTS (SELECT 1 FROM ride_fare_variances v WHERE v.ride_id = r.id)
+		ORDER BY completed_at ASC
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rides := make([]*domain.Ride, 0)
+	for rows.Next() {
+		ride, err := r.scanRideFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		rides = append(rides, ride)
+	}
+
+	return rides, rows.Err()
+}
+
+// InsertFareVariances stores a batch of fare variances
+func (r *RideRepository) InsertFareVariances(ctx context.Context, variances []*domain.FareVariance) error {
+	batch := &pgx.Batch{}
+	for _, v := range variances {
+		batch.Queue(`
+			INSERT INTO ride_fare_variances (
+				ride_id, market, ride_type, currency, measured,
+				quoted_fare, final_fare, variance_pct,
+				quoted_distance_m, actual_distance_m, completed_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (ride_id) DO NOTHING`,
+			v.RideID, v.Market, v.RideType, v.Currency, v.Measured,
+			v.QuotedFare, v.FinalFare, v.VariancePct,
+			v.QuotedDistanceM, v.ActualDistanceM, v.CompletedAt,
+		)
+	}
+
+	return r.pool.SendBatch(ctx, batch).Close()
+}
+
+// GetFareVarianceStats summarises measured fare variances for rides
+// completed in [from, to), by market and ride type
+func (r *RideRepository) GetFareVarianceStats(ctx context.Context, from, to time.Time) ([]*domain.FareVarianceStat, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT market, ride_type, COUNT(*),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY variance_pct),
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY ABS(variance_pct)),
+			AVG(quoted_fare), AVG(final_fare)
+		FROM ride_fare_variances
+		WHERE measured AND completed_at >= $1 AND completed_at < $2
+		GROUP BY market, ride_type
+		ORDER BY market, ride_type`,
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]*domain.FareVarianceStat, 0)
+	for rows.Next() {
+		var stat domain.FareVarianceStat
+		if err := rows.Scan(
+			&stat.Market, &stat.RideType, &stat.Rides,
+			&stat.MedianPct, &stat.P90AbsPct, &stat.MeanQuoted, &stat.MeanFinal,
+		); err != nil {
+			return nil, err
+		}
+		stats = append(stats, &stat)
+	}
+
+	return stats, rows.Err()
+}
+
+// CreateFareVarianceTables creates the fare variance table (for testing/migrations)
+func (r *RideRepository) CreateFareVarianceTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS ride_fare_variances (
+			ride_id UUID PRIMARY KEY REFERENCES rides(id),
+			market VARCHAR(50) NOT NULL,
+			ride_type VARCHAR(20) NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			measured BOOLEAN NOT NULL,
+			quoted_fare BIGINT NOT NULL,
+			final_fare BIGINT NOT NULL,
+			variance_pct DOUBLE PRECISION NOT NULL,
+			quoted_distance_m DOUBLE PRECISION NOT NULL,
+			actual_distance_m DOUBLE PRECISION NOT NULL,
+			completed_at TIMESTAMPTZ NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_ride_fare_variances_completed_at ON ride_fare_variances(completed_at) WHERE measured;
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// IdentityCheckRepository handles driver selfie identity check data access
+type IdentityCheckRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewIdentityCheckRepository creates a new identity check repository
+func NewIdentityCheckRepository(pool *pgxpool.Pool) *IdentityCheckRepository {
+	return &IdentityCheckRepository{pool: pool}
+}
+
+const identityCheckColumns = `
+	id, driver_id, status, provider, confidence, selfie_url, note,
+	reviewed_by, created_at`
+
+// Create inserts an identity check
+func (r *IdentityCheckRepository) Create(ctx context.Context, check *domain.IdentityCheck) error {
+	query := `
+		INSERT INTO driver_identity_checks (` + identityCheckColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err := r.pool.Exec(ctx, query,
+		check.ID, check.DriverID, check.Status, check.Provider, check.Confidence,
+		check.SelfieURL, check.Note, check.ReviewedBy, check.CreatedAt,
+	)
+	return err
+}
+
+// ListRecent lists a driver's most recent checks, newest first
+func (r *IdentityCheckRepository) ListRecent(ctx context.Context, driverID uuid.UUID, limit int) ([]*domain.IdentityCheck, error) {
+	query := `
+		SELECT ` + identityCheckColumns + `
+		FROM driver_identity_checks
+		WHERE driver_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, driverID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := make([]*domain.IdentityCheck, 0)
+	for rows.Next() {
+		check, err := r.scanCheck(rows)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, rows.Err()
+}
+
+func (r *IdentityCheckRepository) scanCheck(row pgx.Row) (*domain.IdentityCheck, error) {
+	var check domain.IdentityCheck
+	var provider, selfieURL, note sql.NullString
+
+	err := row.Scan(
+		&check.ID, &check.DriverID, &check.Status, &provider, &check.Confidence,
+		&selfieURL, &note, &check.ReviewedBy, &check.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	check.Provider = provider.String
+	check.SelfieURL = selfieURL.String
+	check.Note = note.String
+
+	return &check, nil
+}
+
+// CreateIdentityCheckTables creates the identity check table (for testing/migrations)
+func (r *IdentityCheckRepository) CreateIdentityCheckTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS driver_identity_checks (
+			id UUID PRIMARY KEY,
+			driver_id UUID NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			provider VARCHAR(50),
+			confidence DOUBLE PRECISION NOT NULL DEFAULT 0,
+			selfie_url TEXT,
+			note TEXT,
+			reviewed_by UUID,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_identity_checks_driver ON driver_identity_checks(driver_id, created_at DESC);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
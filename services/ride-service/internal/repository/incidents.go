@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// GetRidesUnderway gets rides with a driver on the way to the pickup or
+// carrying the rider, to check their routes against reported incidents
+func (r *RideRepository) GetRidesUnderway(ctx context.Context, limit int) ([]*domain.Ride, error) {
+	query := `
+		SELECT
+			id, rider_id, driver_id, vehicle_id,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
+			type, status, payment_method,
+			route, price,
+			scheduled_for, requested_at, accepted_at, arrived_at,
+			started_at, completed_at, cancelled_at,
+			cancellation_reason, cancelled_by,
+			rider_rating, driver_rating,
+			promo_code, metadata,
+			created_at, updated_at, version
+		FROM rides
+		WHERE status IN ('ACCEPTED', 'ARRIVING', 'ARRIVED', 'IN_PROGRESS')
+			AND driver_id IS NOT NULL
+		ORDER BY accepted_at ASC
+		LIMIT $1`
+
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rides := make([]*domain.Ride, 0)
+	for rows.Next() {
+		ride, err := r.scanRideFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		rides = append(rides, ride)
+	}
+
+	return rides, rows.Err()
+}
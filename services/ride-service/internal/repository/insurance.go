@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// InsuranceRepository handles ride insurance policy and claim data access
+type InsuranceRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewInsuranceRepository creates a new insurance repository
+func NewInsuranceRepository(pool *pgxpool.Pool) *InsuranceRepository {
+	return &InsuranceRepository{pool: pool}
+}
+
+const insurancePolicyColumns = `
+	id, ride_id, city, provider, policy_ref, fare, premium, currency,
+	coverage_start, coverage_end, claims_until, created_at`
+
+const insuranceClaimColumns = `
+	id, ride_id, policy_id, policy_ref, claimant_id, claimant_role, type, description, incident_at,
+	safety_incident_id, status, provider_claim_ref, settled_amount, currency, note, updated_by,
+	closed_at, created_at, updated_at`
+
+// CreatePolicy attaches a policy to a ride. A ride is only covered once, so
+// attaching again is a no-op that reports false.
+func (r *InsuranceRepository) CreatePolicy(ctx context.Context, policy *domain.InsurancePolicy) (bool, error) {
+	query := `
+		INSERT INTO ride_insurance_policies (` + insurancePolicyColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (ride_id) DO NOTHING`
+
+	result, err := r.pool.Exec(ctx, query,
+		policy.ID, policy.RideID, policy.City, policy.Provider, policy.PolicyRef,
+		policy.Fare, policy.Premium, policy.Currency,
+		policy.CoverageStart, policy.CoverageEnd, policy.ClaimsUntil, policy.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// GetPolicyByRide retrieves a ride's policy
+func (r *InsuranceRepository) GetPolicyByRide(ctx context.Context, rideID uuid.UUID) (*domain.InsurancePolicy, error) {
+	query := `SELECT ` + insurancePolicyColumns + ` FROM ride_insurance_policies WHERE ride_id = $1`
+
+	var policy domain.InsurancePolicy
+	err := r.pool.QueryRow(ctx, query, rideID).Scan(
+		&policy.ID, &policy.RideID, &policy.City, &policy.Provider, &policy.PolicyRef,
+		&policy.Fare, &policy.Premium, &policy.Currency,
+		&policy.CoverageStart, &policy.CoverageEnd, &policy.ClaimsUntil, &policy.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrRideNotInsured
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// CreateClaim inserts a new claim, returning ErrInsuranceClaimOpen if the
+// claimant already has one open on the ride
+func (r *InsuranceRepository) CreateClaim(ctx context.Context, claim *domain.InsuranceClaim) error {
+	query := `
+		INSERT INTO insurance_claims (` + insuranceClaimColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`
+
+	_, err := r.pool.Exec(ctx, query,
+		claim.ID, claim.RideID, claim.PolicyID, claim.PolicyRef, claim.ClaimantID, claim.ClaimantRole,
+		claim.Type, claim.Description, claim.IncidentAt,
+		claim.SafetyIncidentID, claim.Status, claim.ProviderClaimRef, claim.SettledAmount, claim.Currency,
+		claim.Note, claim.UpdatedBy, claim.ClosedAt, claim.CreatedAt, claim.UpdatedAt,
+	)
+	if err != nil && isUniqueViolation(err) {
+		return domain.ErrInsuranceClaimOpen
+	}
+	return err
+}
+
+// GetClaim retrieves a claim by ID
+func (r *InsuranceRepository) GetClaim(ctx context.Context, id uuid.UUID) (*domain.InsuranceClaim, error) {
+	query := `SELECT ` + insuranceClaimColumns + ` FROM insurance_claims WHERE id = $1`
+	return r.scanClaim(r.pool.QueryRow(ctx, query, id))
+}
+
+// ListClaimsByRide lists a ride's claims, oldest first
+func (r *InsuranceRepository) ListClaimsByRide(ctx context.Context, rideID uuid.UUID) ([]*domain.InsuranceClaim, error) {
+	query := `SELECT ` + insuranceClaimColumns + ` FROM insurance_claims WHERE ride_id = $1 ORDER BY created_at ASC`
+	return r.queryClaims(ctx, query, rideID)
+}
+
+// ListClaimsBySafetyIncident lists the claims linked to an SOS incident
+func (r *InsuranceRepository) ListClaimsBySafetyIncident(ctx context.Context, incidentID uuid.UUID) ([]*domain.InsuranceClaim, error) {
+	query := `SELECT ` + insuranceClaimColumns + ` FROM insurance_claims WHERE safety_incident_id = $1 ORDER BY created_at ASC`
+	return r.queryClaims(ctx, query, incidentID)
+}
+
+// ListClaimsByStatus lists claims in a status, longest waiting first
+func (r *InsuranceRepository) ListClaimsByStatus(ctx context.Context, status domain.InsuranceClaimStatus, limit, offset int) ([]*domain.InsuranceClaim, int64, error) {
+	var total int64
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM insurance_claims WHERE status = $1`, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + insuranceClaimColumns + `
+		FROM insurance_claims
+		WHERE status = $1
+		ORDER BY updated_at ASC
+		LIMIT $2 OFFSET $3`
+
+	claims, err := r.queryClaims(ctx, query, status, limit, offset)
+	return claims, total, err
+}
+
+// UpdateClaimStatus moves a claim on from the status it was read in,
+// returning ErrInvalidClaimTransition if another agent moved it first
+func (r *InsuranceRepository) UpdateClaimStatus(ctx context.Context, claim *domain.InsuranceClaim, from domain.InsuranceClaimStatus) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE insurance_claims SET
+			status = $2,
+			provider_claim_ref = $3,
+			settled_amount = $4,
+			note = $5,
+			updated_by = $6,
+			closed_at = $7,
+			updated_at = $8
+		WHERE id = $1 AND status = $9`,
+		claim.ID, claim.Status, claim.ProviderClaimRef, claim.SettledAmount, claim.Note,
+		claim.UpdatedBy, claim.ClosedAt, claim.UpdatedAt, from,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrInvalidClaimTransition
+	}
+	return nil
+}
+
+func (r *InsuranceRepository) queryClaims(ctx context.Context, query string, args ...interface{}) ([]*domain.InsuranceClaim, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	claims := make([]*domain.InsuranceClaim, 0)
+	for rows.Next() {
+		claim, err := r.scanClaim(rows)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, claim)
+	}
+
+	return claims, rows.Err()
+}
+
+func (r *InsuranceRepository) scanClaim(row pgx.Row) (*domain.InsuranceClaim, error) {
+	var claim domain.InsuranceClaim
+	var providerRef, note sql.NullString
+
+	err := row.Scan(
+		&claim.ID, &claim.RideID, &claim.PolicyID, &claim.PolicyRef, &claim.ClaimantID, &claim.ClaimantRole,
+		&claim.Type, &claim.Description, &claim.IncidentAt,
+		&claim.SafetyIncidentID, &claim.Status, &providerRef, &claim.SettledAmount, &claim.Currency,
+		&note, &claim.UpdatedBy, &claim.ClosedAt, &claim.CreatedAt, &claim.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrInsuranceClaimNotFound
+		}
+		return nil, err
+	}
+
+	claim.ProviderClaimRef = providerRef.String
+	claim.Note = note.String
+	return &claim, nil
+}
+
+// CreateInsuranceTables creates the ride insurance tables (for testing/migrations)
+func (r *InsuranceRepository) CreateInsuranceTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS ride_insurance_policies (
+			id UUID PRIMARY KEY,
+			ride_id UUID NOT NULL UNIQUE REFERENCES rides(id),
+			city VARCHAR(50) NOT NULL,
+			provider VARCHAR(100) NOT NULL,
+			policy_ref VARCHAR(150) NOT NULL,
+			fare BIGINT NOT NULL,
+			premium BIGINT NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			coverage_start TIMESTAMPTZ NOT NULL,
+			coverage_end TIMESTAMPTZ NOT NULL,
+			claims_until TIMESTAMPTZ NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		-- Premium bordereaux for the insurers are run by city and month
+		CREATE INDEX IF NOT EXISTS idx_ride_insurance_policies_city ON ride_insurance_policies(city, coverage_end);
+
+		CREATE TABLE IF NOT EXISTS insurance_claims (
+			id UUID PRIMARY KEY,
+			ride_id UUID NOT NULL REFERENCES rides(id),
+			policy_id UUID NOT NULL REFERENCES ride_insurance_policies(id),
+			policy_ref VARCHAR(150) NOT NULL,
+			claimant_id UUID NOT NULL,
+			claimant_role VARCHAR(20) NOT NULL,
+			type VARCHAR(30) NOT NULL,
+			description TEXT NOT NULL,
+			incident_at TIMESTAMPTZ,
+			safety_incident_id UUID REFERENCES safety_incidents(id),
+			status VARCHAR(20) NOT NULL,
+			provider_claim_ref VARCHAR(100),
+			settled_amount BIGINT NOT NULL DEFAULT 0,
+			currency VARCHAR(3) NOT NULL,
+			note TEXT,
+			updated_by UUID,
+			closed_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		-- One open claim per claimant per ride
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_insurance_claims_open ON insurance_claims(ride_id, claimant_id) WHERE status NOT IN ('SETTLED', 'REJECTED');
+		CREATE INDEX IF NOT EXISTS idx_insurance_claims_queue ON insurance_claims(status, updated_at);
+		CREATE INDEX IF NOT EXISTS idx_insurance_claims_safety ON insurance_claims(safety_incident_id) WHERE safety_incident_id IS NOT NULL;
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// KillSwitchRepository stores the audit log of city kill switches. The
+// switches themselves live in Redis.
+type KillSwitchRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewKillSwitchRepository creates a new kill switch repository
+func NewKillSwitchRepository(pool *pgxpool.Pool) *KillSwitchRepository {
+	return &KillSwitchRepository{pool: pool}
+}
+
+// InsertAudit records a change to a city's switches
+func (r *KillSwitchRepository) InsertAudit(ctx context.Context, audit *domain.KillSwitchAudit) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO kill_switch_audit (id, city, feature, action, reason, actor_id, reenable_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		audit.ID, audit.City, audit.Feature, audit.Action, audit.Reason, audit.ActorID, audit.ReenableAt, audit.CreatedAt,
+	)
+	return err
+}
+
+// ListAudit gets the most recent changes, newest first, for one city or
+// for all cities when city is empty
+func (r *KillSwitchRepository) ListAudit(ctx context.Context, city string, limit int) ([]*domain.KillSwitchAudit, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, city, feature, action, reason, actor_id, reenable_at, created_at
+		FROM kill_switch_audit
+		WHERE $1 = '' OR city = $1
+		ORDER BY created_at DESC
+		LIMIT $2`,
+		city, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.KillSwitchAudit, 0)
+	for rows.Next() {
+		var audit domain.KillSwitchAudit
+		if err := rows.Scan(
+			&audit.ID, &audit.City, &audit.Feature, &audit.Action, &audit.Reason,
+			&audit.ActorID, &audit.ReenableAt, &audit.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &audit)
+	}
+
+	return entries, rows.Err()
+}
+
+// CreateKillSwitchTables creates the audit table (for testing/migrations)
+func (r *KillSwitchRepository) CreateKillSwitchTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS kill_switch_audit (
+			id UUID PRIMARY KEY,
+			city VARCHAR(50) NOT NULL,
+			feature VARCHAR(20) NOT NULL,
+			action VARCHAR(20) NOT NULL,
+			reason TEXT NOT NULL DEFAULT '',
+			actor_id UUID,
+			reenable_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_kill_switch_audit_city ON kill_switch_audit(city, created_at DESC);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,397 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// GetUnrecordedCompletedRides gets rides completed since the given time
+// whose trip has not been added to the rider's location history, oldest
+// first
+func (r *RideRepository) GetUnrecordedCompletedRides(ctx context.Context, since time.Time, limit int) ([]*domain.Ride, error) {
+	query := `
+		SELECT
+			id, rider_id, driver_id, vehicle_id,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
+			type, status, payment_method,
+			route, price,
+			scheduled_for, requested_at, accepted_at, arrived_at,
+			started_at, completed_at, cancelled_at,
+			cancellation_reason, cancelled_by,
+			rider_rating, driver_rating,
+			promo_code, metadata,
+			created_at, updated_at, version
+		FROM rides r
+		WHERE status = 'COMPLETED'
+			AND completed_at >= $1
+			AND NOT EXISTS (SELECT 1 FROM location_history_trips t WHERE t.ride_id = r.id)
+		ORDER BY completed_at ASC
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rides := make([]*domain.Ride, 0)
+	for rows.Next() {
+		ride, err := r.scanRideFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		rides = append(rides, ride)
+	}
+
+	return rides, rows.Err()
+}
+
+// RecordUserTrip adds a trip's endpoints and pattern to a user's location
+// history, and where it really started to the learned pickup spots when
+// known, in one transaction. Counters are added to the stored ones; a nil
+// pattern records the ride as seen without touching the user's history. It
+// returns false when the ride was already recorded.
+func (r *RideRepository) RecordUserTrip(
+	ctx context.Context,
+	rideID uuid.UUID,
+	userID uuid.UUID,
+	places []*domain.FrequentPlace,
+	pattern *domain.TripPattern,
+	spots []*domain.PickupSpot,
+) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO location_history_trips (ride_id, user_id, recorded_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (ride_id) DO NOTHING`,
+		rideID, userID,
+	)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	for _, p := range places {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO frequent_places (
+				user_id, cell, latitude, longitude, name, address, place_id,
+				pickups, dropoffs, first_visited_at, last_visited_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (user_id, cell) DO UPDATE SET
+				latitude = (frequent_places.latitude * (frequent_places.pickups + frequent_places.dropoffs)
+					+ EXCLUDED.latitude * (EXCLUDED.pickups + EXCLUDED.dropoffs))
+					/ (frequent_places.pickups + frequent_places.dropoffs + EXCLUDED.pickups + EXCLUDED.dropoffs),
+				longitude = (frequent_places.longitude * (frequent_places.pickups + frequent_places.dropoffs)
+					+ EXCLUDED.longitude * (EXCLUDED.pickups + EXCLUDED.dropoffs))
+					/ (frequent_places.pickups + frequent_places.dropoffs + EXCLUDED.pickups + EXCLUDED.dropoffs),
+				name = COALESCE(NULLIF(EXCLUDED.name, ''), frequent_places.name),
+				address = COALESCE(NULLIF(EXCLUDED.address, ''), frequent_places.address),
+				place_id = COALESCE(NULLIF(EXCLUDED.place_id, ''), frequent_places.place_id),
+				pickups = frequent_places.pickups + EXCLUDED.pickups,
+				dropoffs = frequent_places.dropoffs + EXCLUDED.dropoffs,
+				first_visited_at = LEAST(frequent_places.first_visited_at, EXCLUDED.first_visited_at),
+				last_visited_at = GREATEST(frequent_places.last_visited_at, EXCLUDED.last_visited_at)`,
+			p.UserID, p.Cell, p.Latitude, p.Longitude, p.Name, p.Address, p.PlaceID,
+			p.Pickups, p.Dropoffs, p.FirstVisitedAt, p.LastVisitedAt,
+		)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if pattern != nil {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO trip_patterns (
+				user_id, origin_cell, destination_cell, day_of_week, hour_of_day, trips, last_trip_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (user_id, origin_cell, destination_cell, day_of_week, hour_of_day) DO UPDATE SET
+				trips = trip_patterns.trips + EXCLUDED.trips,
+				last_trip_at = GREATEST(trip_patterns.last_trip_at, EXCLUDED.last_trip_at)`,
+			pattern.UserID, pattern.OriginCell, pattern.DestinationCell,
+			pattern.DayOfWeek, pattern.HourOfDay, pattern.Trips, pattern.LastTripAt,
+		)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	for _, spot := range spots {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO pickup_spots (
+				request_key, spot_cell, latitude, longitude, pickups, last_pickup_at
+			) VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (request_key, spot_cell) DO UPDATE SET
+				latitude = (pickup_spots.latitude * pickup_spots.pickups + EXCLUDED.latitude * EXCLUDED.pickups)
+					/ (pickup_spots.pickups + EXCLUDED.pickups),
+				longitude = (pickup_spots.longitude * pickup_spots.pickups + EXCLUDED.longitude * EXCLUDED.pickups)
+					/ (pickup_spots.pickups + EXCLUDED.pickups),
+				pickups = pickup_spots.pickups + EXCLUDED.pickups,
+				last_pickup_at = GREATEST(pickup_spots.last_pickup_at, EXCLUDED.last_pickup_at)`,
+			spot.RequestKey, spot.SpotCell, spot.Latitude, spot.Longitude, spot.Pickups, spot.LastPickupAt,
+		)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// RecordArrivalLocation stores where the driver was when they marked a ride
+// arrived
+func (r *RideRepository) RecordArrivalLocation(ctx context.Context, rideID uuid.UUID, point domain.TrailPoint) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO ride_arrival_locations (ride_id, latitude, longitude, recorded_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (ride_id) DO UPDATE SET
+			latitude = EXCLUDED.latitude,
+			longitude = EXCLUDED.longitude,
+			recorded_at = EXCLUDED.recorded_at`,
+		rideID, point.Latitude, point.Longitude, point.RecordedAt,
+	)
+	return err
+}
+
+// GetArrivalLocation gets where the driver was when they marked a ride
+// arrived, or nil when it was not recorded
+func (r *RideRepository) GetArrivalLocation(ctx context.Context, rideID uuid.UUID) (*domain.TrailPoint, error) {
+	var p domain.TrailPoint
+	err := r.pool.QueryRow(ctx, `
+		SELECT latitude, longitude, recorded_at FROM ride_arrival_locations WHERE ride_id = $1`,
+		rideID,
+	).Scan(&p.Latitude, &p.Longitude, &p.RecordedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetPickupSpots gets the learned pickup spots for trips requested from a
+// cell or place with at least minPickups pickups, most used first, along
+// with its total learned pickups
+func (r *RideRepository) GetPickupSpots(ctx context.Context, requestKey string, minPickups int64, limit int) ([]*domain.PickupSpot, int64, error) {
+	var total int64
+	if err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(SUM(pickups), 0)::BIGINT FROM pickup_spots WHERE request_key = $1`,
+		requestKey,
+	).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT request_key, spot_cell, latitude, longitude, pickups, last_pickup_at
+		FROM pickup_spots
+		WHERE request_key = $1 AND pickups >= $2
+		ORDER BY pickups DESC, last_pickup_at DESC
+		LIMIT $3`,
+		requestKey, minPickups, limit,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	spots := make([]*domain.PickupSpot, 0)
+	for rows.Next() {
+		var s domain.PickupSpot
+		if err := rows.Scan(&s.RequestKey, &s.SpotCell, &s.Latitude, &s.Longitude, &s.Pickups, &s.LastPickupAt); err != nil {
+			return nil, 0, err
+		}
+		spots = append(spots, &s)
+	}
+
+	return spots, total, rows.Err()
+}
+
+// GetUserLocationHistory gets a user's most-visited places and
+// most-travelled trip patterns. A limit of 0 gets them all.
+func (r *RideRepository) GetUserLocationHistory(ctx context.Context, userID uuid.UUID, placeLimit, patternLimit int) (*domain.UserLocationHistory, error) {
+	history := &domain.UserLocationHistory{
+		UserID:   userID,
+		Places:   make([]*domain.FrequentPlace, 0),
+		Patterns: make([]*domain.TripPattern, 0),
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT cell, latitude, longitude, name, address, place_id,
+			pickups, dropoffs, first_visited_at, last_visited_at
+		FROM frequent_places
+		WHERE user_id = $1
+		ORDER BY pickups + dropoffs DESC, last_visited_at DESC
+		LIMIT NULLIF($2, 0)`,
+		userID, placeLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := &domain.FrequentPlace{UserID: userID}
+		if err := rows.Scan(
+			&p.Cell, &p.Latitude, &p.Longitude, &p.Name, &p.Address, &p.PlaceID,
+			&p.Pickups, &p.Dropoffs, &p.FirstVisitedAt, &p.LastVisitedAt,
+		); err != nil {
+			return nil, err
+		}
+		history.Places = append(history.Places, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.pool.Query(ctx, `
+		SELECT origin_cell, destination_cell, day_of_week, hour_of_day, trips, last_trip_at
+		FROM trip_patterns
+		WHERE user_id = $1
+		ORDER BY trips DESC, last_trip_at DESC
+		LIMIT NULLIF($2, 0)`,
+		userID, patternLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := &domain.TripPattern{UserID: userID}
+		if err := rows.Scan(
+			&p.OriginCell, &p.DestinationCell, &p.DayOfWeek, &p.HourOfDay, &p.Trips, &p.LastTripAt,
+		); err != nil {
+			return nil, err
+		}
+		history.Patterns = append(history.Patterns, p)
+	}
+
+	return history, rows.Err()
+}
+
+// DeleteUserLocationHistory deletes a user's frequent places and trip
+// patterns. Their rides stay marked as recorded so they are not added back.
+func (r *RideRepository) DeleteUserLocationHistory(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM frequent_places WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM trip_patterns WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetLocationHistoryPreferences gets a user's location history settings,
+// defaulting to opted in
+func (r *RideRepository) GetLocationHistoryPreferences(ctx context.Context, userID uuid.UUID) (*domain.LocationHistoryPreferences, error) {
+	prefs := &domain.LocationHistoryPreferences{UserID: userID}
+	err := r.pool.QueryRow(ctx, `
+		SELECT opted_out, updated_at FROM location_history_preferences WHERE user_id = $1`,
+		userID,
+	).Scan(&prefs.OptedOut, &prefs.UpdatedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// SaveLocationHistoryPreferences stores a user's location history settings
+func (r *RideRepository) SaveLocationHistoryPreferences(ctx context.Context, prefs *domain.LocationHistoryPreferences) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO location_history_preferences (user_id, opted_out, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			opted_out = EXCLUDED.opted_out,
+			updated_at = EXCLUDED.updated_at`,
+		prefs.UserID, prefs.OptedOut, prefs.UpdatedAt,
+	)
+	return err
+}
+
+// CreateLocationHistoryTables creates the location history tables (for testing/migrations)
+func (r *RideRepository) CreateLocationHistoryTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS frequent_places (
+			user_id UUID NOT NULL,
+			cell VARCHAR(32) NOT NULL,
+			latitude DOUBLE PRECISION NOT NULL,
+			longitude DOUBLE PRECISION NOT NULL,
+			name VARCHAR(255) NOT NULL DEFAULT '',
+			address TEXT NOT NULL DEFAULT '',
+			place_id VARCHAR(255) NOT NULL DEFAULT '',
+			pickups BIGINT NOT NULL DEFAULT 0,
+			dropoffs BIGINT NOT NULL DEFAULT 0,
+			first_visited_at TIMESTAMPTZ NOT NULL,
+			last_visited_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (user_id, cell)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_frequent_places_cell ON frequent_places(cell);
+
+		CREATE TABLE IF NOT EXISTS trip_patterns (
+			user_id UUID NOT NULL,
+			origin_cell VARCHAR(32) NOT NULL,
+			destination_cell VARCHAR(32) NOT NULL,
+			day_of_week SMALLINT NOT NULL,
+			hour_of_day SMALLINT NOT NULL,
+			trips BIGINT NOT NULL DEFAULT 0,
+			last_trip_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (user_id, origin_cell, destination_cell, day_of_week, hour_of_day)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_trip_patterns_origin ON trip_patterns(origin_cell, day_of_week, hour_of_day);
+
+		CREATE TABLE IF NOT EXISTS location_history_trips (
+			ride_id UUID PRIMARY KEY REFERENCES rides(id),
+			user_id UUID NOT NULL,
+			recorded_at TIMESTAMPTZ NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_location_history_trips_user ON location_history_trips(user_id);
+
+		CREATE TABLE IF NOT EXISTS location_history_preferences (
+			user_id UUID PRIMARY KEY,
+			opted_out BOOLEAN NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS pickup_spots (
+			request_key VARCHAR(255) NOT NULL,
+			spot_cell VARCHAR(32) NOT NULL,
+			latitude DOUBLE PRECISION NOT NULL,
+			longitude DOUBLE PRECISION NOT NULL,
+			pickups BIGINT NOT NULL DEFAULT 0,
+			last_pickup_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (request_key, spot_cell)
+		);
+
+		CREATE TABLE IF NOT EXISTS ride_arrival_locations (
+			ride_id UUID PRIMARY KEY REFERENCES rides(id),
+			latitude DOUBLE PRECISION NOT NULL,
+			longitude DOUBLE PRECISION NOT NULL,
+			recorded_at TIMESTAMPTZ NOT NULL
+		);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
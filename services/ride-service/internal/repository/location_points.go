@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// locationPointColumns are the columns a location point is copied into
+var locationPointColumns = []string{
+	"driver_id", "latitude", "longitude", "heading", "speed", "accuracy", "h3_cell", "recorded_at",
+}
+
+// LocationPointRepository stores drivers' GPS history
+type LocationPointRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewLocationPointRepository creates a new location point repository
+func NewLocationPointRepository(pool *pgxpool.Pool) *LocationPointRepository {
+	return &LocationPointRepository{pool: pool}
+}
+
+// CopyPoints bulk inserts points with COPY, returning how many were
+// written. The batch is written whole or not at all.
+func (r *LocationPointRepository) CopyPoints(ctx context.Context, points []*domain.LocationPoint) (int64, error) {
+	if len(points) == 0 {
+		return 0, nil
+	}
+
+	n, err := r.pool.CopyFrom(ctx,
+		pgx.Identifier{"driver_location_points"},
+		locationPointColumns,
+		pgx.CopyFromSlice(len(points), func(i int) ([]interface{}, error) {
+			p := points[i]
+			return []interface{}{
+				p.DriverID, p.Latitude, p.Longitude, p.Heading, p.Speed, p.Accuracy, p.H3Cell, p.RecordedAt,
+			}, nil
+		}),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy location points: %w", err)
+	}
+	return n, nil
+}
+
+// CreateLocationPointTables creates the location history table (for
+// testing/migrations). It has no primary key so COPY stays cheap.
+func (r *LocationPointRepository) CreateLocationPointTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS driver_location_points (
+			driver_id UUID NOT NULL,
+			latitude DOUBLE PRECISION NOT NULL,
+			longitude DOUBLE PRECISION NOT NULL,
+			heading DOUBLE PRECISION NOT NULL DEFAULT 0,
+			speed DOUBLE PRECISION NOT NULL DEFAULT 0,
+			accuracy DOUBLE PRECISION NOT NULL DEFAULT 0,
+			h3_cell VARCHAR(20) NOT NULL DEFAULT '',
+			recorded_at TIMESTAMPTZ NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_driver_location_points_driver ON driver_location_points(driver_id, recorded_at);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
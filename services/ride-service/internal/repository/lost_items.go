@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// LostItemRepository handles lost item cases
+type LostItemRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewLostItemRepository creates a new lost item repository
+func NewLostItemRepository(pool *pgxpool.Pool) *LostItemRepository {
+	return &LostItemRepository{pool: pool}
+}
+
+const lostItemColumns = `
+	id, ride_id, rider_id, driver_id, category, description, status, return_details,
+	note, updated_by, resolved_at, created_at, updated_at`
+
+// Create inserts a new case
+func (r *LostItemRepository) Create(ctx context.Context, c *domain.LostItemCase) error {
+	returnJSON, _ := json.Marshal(c.Return)
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO lost_item_cases (`+lostItemColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		c.ID, c.RideID, c.RiderID, c.DriverID, c.Category, c.Description, c.Status, returnJSON,
+		c.Note, c.UpdatedBy, c.ResolvedAt, c.CreatedAt, c.UpdatedAt,
+	)
+	if err != nil && isUniqueViolation(err) {
+		return domain.ErrLostItemAlreadyOpen
+	}
+	return err
+}
+
+// GetByID retrieves a case by ID
+func (r *LostItemRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.LostItemCase, error) {
+	return r.scanCase(r.pool.QueryRow(ctx, `SELECT `+lostItemColumns+` FROM lost_item_cases WHERE id = $1`, id))
+}
+
+// GetLatestByRide retrieves the most recent case for a ride
+func (r *LostItemRepository) GetLatestByRide(ctx context.Context, rideID uuid.UUID) (*domain.LostItemCase, error) {
+	return r.scanCase(r.pool.QueryRow(ctx, `
+		SELECT `+lostItemColumns+` FROM lost_item_cases
+		WHERE ride_id = $1
+		ORDER BY created_at DESC LIMIT 1`,
+		rideID,
+	))
+}
+
+// ListByUser lists the cases a user is the rider or driver of, most
+// recently updated first
+func (r *LostItemRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*domain.LostItemCase, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+lostItemColumns+` FROM lost_item_cases
+		WHERE rider_id = $1 OR driver_id = $1
+		ORDER BY updated_at DESC
+		LIMIT $2`,
+		userID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cases := make([]*domain.LostItemCase, 0)
+	for rows.Next() {
+		c, err := r.scanCase(rows)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, c)
+	}
+
+	return cases, rows.Err()
+}
+
+// Update stores a case's new status, provided it is still in status from
+func (r *LostItemRepository) Update(ctx context.Context, c *domain.LostItemCase, from domain.LostItemStatus) error {
+	returnJSON, _ := json.Marshal(c.Return)
+
+	result, err := r.pool.Exec(ctx, `
+		UPDATE lost_item_cases SET
+			status = $3,
+			return_details = $4,
+			note = $5,
+			updated_by = $6,
+			resolved_at = $7,
+			updated_at = $8
+		WHERE id = $1 AND status = $2`,
+		c.ID, from, c.Status, returnJSON, c.Note, c.UpdatedBy, c.ResolvedAt, c.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrInvalidLostItemUpdate
+	}
+	return nil
+}
+
+func (r *LostItemRepository) scanCase(row pgx.Row) (*domain.LostItemCase, error) {
+	var c domain.LostItemCase
+	var returnJSON []byte
+	var note, updatedBy sql.NullString
+
+	err := row.Scan(
+		&c.ID, &c.RideID, &c.RiderID, &c.DriverID, &c.Category, &c.Description, &c.Status, &returnJSON,
+		&note, &updatedBy, &c.ResolvedAt, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrLostItemNotFound
+		}
+		return nil, err
+	}
+
+	c.Note = note.String
+	c.UpdatedBy = domain.RideParty(updatedBy.String)
+	if len(returnJSON) > 0 {
+		var ret domain.LostItemReturn
+		if err := json.Unmarshal(returnJSON, &ret); err == nil && !ret.At.IsZero() {
+			c.Return = &ret
+		}
+	}
+
+	return &c, nil
+}
+
+// CreateLostItemTables creates the lost item case table (for testing/migrations)
+func (r *LostItemRepository) CreateLostItemTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS lost_item_cases (
+			id UUID PRIMARY KEY,
+			ride_id UUID NOT NULL REFERENCES rides(id),
+			rider_id UUID NOT NULL,
+			driver_id UUID NOT NULL,
+			category VARCHAR(30) NOT NULL,
+			description TEXT NOT NULL,
+			status VARCHAR(30) NOT NULL,
+			return_details JSONB,
+			note TEXT,
+			updated_by VARCHAR(20),
+			resolved_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		-- At most one open case per ride
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_lost_item_cases_open ON lost_item_cases(ride_id)
+			WHERE status IN ('REPORTED', 'FOUND', 'RETURN_SCHEDULED');
+		CREATE INDEX IF NOT EXISTS idx_lost_item_cases_rider ON lost_item_cases(rider_id, updated_at);
+		CREATE INDEX IF NOT EXISTS idx_lost_item_cases_driver ON lost_item_cases(driver_id, updated_at);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
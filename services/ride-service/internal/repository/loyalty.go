@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// LoyaltyRepository handles riders' loyalty points balances and ledger
+type LoyaltyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewLoyaltyRepository creates a new loyalty repository
+func NewLoyaltyRepository(pool *pgxpool.Pool) *LoyaltyRepository {
+	return &LoyaltyRepository{pool: pool}
+}
+
+// RecordEntry adds an entry to the ledger and applies it to the rider's
+// balance, reporting false when the ride already has an entry of its kind.
+// Entries taking points off fail with ErrInsufficientLoyaltyPoints rather
+// than leave the balance negative.
+func (r *LoyaltyRepository) RecordEntry(ctx context.Context, e *domain.LoyaltyEntry) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO rider_loyalty_ledger (id, rider_id, ride_id, kind, points, amount, currency, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (ride_id, kind) DO NOTHING`,
+		e.ID, e.RiderID, e.RideID, e.Kind, e.Points, e.Amount, e.Currency, e.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if e.Points < 0 {
+		result, err = tx.Exec(ctx, `
+			UPDATE rider_loyalty_accounts SET balance = balance + $2, updated_at = $3
+			WHERE rider_id = $1 AND balance + $2 >= 0`,
+			e.RiderID, e.Points, e.CreatedAt,
+		)
+		if err != nil {
+			return false, err
+		}
+		if result.RowsAffected() == 0 {
+			return false, domain.ErrInsufficientLoyaltyPoints
+		}
+	} else {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO rider_loyalty_accounts (rider_id, balance, updated_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (rider_id) DO UPDATE SET
+				balance = rider_loyalty_accounts.balance + EXCLUDED.balance,
+				updated_at = EXCLUDED.updated_at`,
+			e.RiderID, e.Points, e.CreatedAt,
+		)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// GetEntry gets a ride's ledger entry of a kind, or nil if it has none
+func (r *LoyaltyRepository) GetEntry(ctx context.Context, rideID uuid.UUID, kind domain.LoyaltyEntryKind) (*domain.LoyaltyEntry, error) {
+	var e domain.LoyaltyEntry
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, rider_id, ride_id, kind, points, amount, currency, created_at
+		FROM rider_loyalty_ledger
+		WHERE ride_id = $1 AND kind = $2`,
+		rideID, kind,
+	).Scan(&e.ID, &e.RiderID, &e.RideID, &e.Kind, &e.Points, &e.Amount, &e.Currency, &e.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &e, nil
+}
+
+// GetBalance gets a rider's points balance, 0 for riders yet to earn any
+func (r *LoyaltyRepository) GetBalance(ctx context.Context, riderID uuid.UUID) (int64, error) {
+	var balance int64
+	err := r.pool.QueryRow(ctx, `
+		SELECT balance FROM rider_loyalty_accounts WHERE rider_id = $1`,
+		riderID,
+	).Scan(&balance)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return balance, err
+}
+
+// ListEntries gets a rider's most recent ledger entries, newest first
+func (r *LoyaltyRepository) ListEntries(ctx context.Context, riderID uuid.UUID, limit int) ([]*domain.LoyaltyEntry, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, rider_id, ride_id, kind, points, amount, currency, created_at
+		FROM rider_loyalty_ledger
+		WHERE rider_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`,
+		riderID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.LoyaltyEntry, 0)
+	for rows.Next() {
+		var e domain.LoyaltyEntry
+		if err := rows.Scan(&e.ID, &e.RiderID, &e.RideID, &e.Kind, &e.Points, &e.Amount, &e.Currency, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
+
+// CreateLoyaltyTables creates the loyalty balance and ledger tables (for testing/migrations)
+func (r *LoyaltyRepository) CreateLoyaltyTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS rider_loyalty_accounts (
+			rider_id UUID PRIMARY KEY,
+			balance BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS rider_loyalty_ledger (
+			id UUID PRIMARY KEY,
+			rider_id UUID NOT NULL,
+			ride_id UUID NOT NULL,
+			kind VARCHAR(20) NOT NULL,
+			points BIGINT NOT NULL,
+			amount BIGINT NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			UNIQUE (ride_id, kind)
+		);
+		CREATE INDEX IF NOT EXISTS idx_rider_loyalty_ledger_rider ON rider_loyalty_ledger(rider_id, created_at DESC);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
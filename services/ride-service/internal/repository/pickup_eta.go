@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// GetActiveVehicles gets the active vehicle of each of the given drivers,
+// keyed by driver. Drivers without an active vehicle are left out.
+func (r *DriverRepository) GetActiveVehicles(ctx context.Context, driverIDs []uuid.UUID) (map[uuid.UUID]*domain.Vehicle, error) {
+	vehicles := make(map[uuid.UUID]*domain.Vehicle, len(driverIDs))
+	if len(driverIDs) == 0 {
+		return vehicles, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, driver_id, type, capacity, supported_types, powertrain
+		FROM vehicles
+		WHERE driver_id = ANY($1) AND is_active = true`,
+		driverIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active vehicles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		v := &domain.Vehicle{IsActive: true}
+		var supportedTypes []byte
+		if err := rows.Scan(&v.ID, &v.DriverID, &v.Type, &v.Capacity, &supportedTypes, &v.Powertrain); err != nil {
+			return nil, fmt.Errorf("failed to scan vehicle: %w", err)
+		}
+		if len(supportedTypes) > 0 {
+			_ = json.Unmarshal(supportedTypes, &v.SupportedTypes)
+		}
+		vehicles[v.DriverID] = v
+	}
+	return vehicles, rows.Err()
+}
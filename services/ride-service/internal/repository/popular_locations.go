@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// AggregatePopularDestinations ranks the dropoffs of rides completed since
+// the given time by trips, grouped by place where the rider picked one and
+// by a roughly 100 m grid square otherwise. Destinations fewer than
+// minRiders distinct riders went to are left out.
+func (r *RideRepository) AggregatePopularDestinations(ctx context.Context, since time.Time, minRiders int, limit int) ([]*domain.PopularLocation, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT
+			AVG((dropoff_location->>'latitude')::DOUBLE PRECISION),
+			AVG((dropoff_location->>'longitude')::DOUBLE PRECISION),
+			COALESCE(mode() WITHIN GROUP (ORDER BY NULLIF(dropoff_location->>'name', '')), ''),
+			COALESCE(mode() WITHIN GROUP (ORDER BY NULLIF(dropoff_location->>'address', '')), ''),
+			COALESCE(mode() WITHIN GROUP (ORDER BY NULLIF(dropoff_location->>'place_id', '')), ''),
+			COUNT(*),
+			COUNT(DISTINCT rider_id)
+		FROM rides
+		WHERE status = 'COMPLETED' AND completed_at >= $1
+		GROUP BY COALESCE(
+			NULLIF(dropoff_location->>'place_id', ''),
+			round((dropoff_location->>'latitude')::NUMERIC, 3) || ',' || round((dropoff_location->>'longitude')::NUMERIC, 3)
+		)
+		HAVING COUNT(DISTINCT rider_id) >= $2
+		ORDER BY COUNT(*) DESC
+		LIMIT $3`,
+		since, minRiders, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := make([]*domain.PopularLocation, 0)
+	for rows.Next() {
+		var l domain.PopularLocation
+		if err := rows.Scan(
+			&l.Latitude, &l.Longitude, &l.Name, &l.Address, &l.PlaceID, &l.Trips, &l.Riders,
+		); err != nil {
+			return nil, err
+		}
+		locations = append(locations, &l)
+	}
+
+	return locations, rows.Err()
+}
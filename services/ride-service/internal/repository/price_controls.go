@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// PriceControlRepository stores fares clamped to regulatory price controls
+type PriceControlRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPriceControlRepository creates a new price control repository
+func NewPriceControlRepository(pool *pgxpool.Pool) *PriceControlRepository {
+	return &PriceControlRepository{pool: pool}
+}
+
+// InsertViolations stores a batch of violations
+func (r *PriceControlRepository) InsertViolations(ctx context.Context, violations []*domain.PriceControlViolation) error {
+	batch := &pgx.Batch{}
+	for _, v := range violations {
+		batch.Queue(`
+			INSERT INTO price_control_violations (id, market, currency, ride_type, control, limit_value, computed, applied, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			v.ID, v.Market, v.Currency, v.RideType, v.Control, v.Limit, v.Computed, v.Applied, v.CreatedAt,
+		)
+	}
+
+	return r.pool.SendBatch(ctx, batch).Close()
+}
+
+// Report summarises violations in [from, to) by market, control and limit
+func (r *PriceControlRepository) Report(ctx context.Context, from, to time.Time) ([]*domain.PriceControlReportRow, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT market, currency, control, limit_value, COUNT(*),
+			CASE WHEN control LIKE 'MAX_%' THEN MAX(computed) ELSE MIN(computed) END,
+			MIN(created_at), MAX(created_at)
+		FROM price_control_violations
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY market, currency, control, limit_value
+		ORDER BY COUNT(*) DESC, market, control`,
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := make([]*domain.PriceControlReportRow, 0)
+	for rows.Next() {
+		var row domain.PriceControlReportRow
+		if err := rows.Scan(
+			&row.Market, &row.Currency, &row.Control, &row.Limit, &row.Violations,
+			&row.WorstCase, &row.FirstSeenAt, &row.LastSeenAt,
+		); err != nil {
+			return nil, err
+		}
+		report = append(report, &row)
+	}
+
+	return report, rows.Err()
+}
+
+// CreatePriceControlTables creates the violation table (for testing/migrations)
+func (r *PriceControlRepository) CreatePriceControlTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS price_control_violations (
+			id UUID PRIMARY KEY,
+			market VARCHAR(50) NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			ride_type VARCHAR(20) NOT NULL,
+			control VARCHAR(30) NOT NULL,
+			limit_value DOUBLE PRECISION NOT NULL,
+			computed DOUBLE PRECISION NOT NULL,
+			applied DOUBLE PRECISION NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_price_control_violations_created_at ON price_control_violations(created_at);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,502 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// PromoRepository stores promo campaigns, their codes and redemptions
+type PromoRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPromoRepository creates a new promo repository
+func NewPromoRepository(pool *pgxpool.Pool) *PromoRepository {
+	return &PromoRepository{pool: pool}
+}
+
+const promoCampaignColumns = `
+	c.id, c.name, c.status, c.currency,
+	c.discount_type, c.discount_percent, c.discount_amount, c.max_discount,
+	c.budget, c.spent, c.per_user_limit, c.targeting,
+	c.starts_at, c.ends_at, c.created_by, c.created_at, c.updated_at,
+	ARRAY(SELECT code FROM promo_codes pc WHERE pc.campaign_id = c.id ORDER BY code)`
+
+// CreateCampaign stores a campaign and claims its codes
+func (r *PromoRepository) CreateCampaign(ctx context.Context, campaign *domain.PromoCampaign) error {
+	targetingJSON, err := json.Marshal(campaign.Targeting)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO promo_campaigns (
+			id, name, status, currency,
+			discount_type, discount_percent, discount_amount, max_discount,
+			budget, spent, per_user_limit, targeting,
+			starts_at, ends_at, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`,
+		campaign.ID, campaign.Name, campaign.Status, campaign.Currency,
+		campaign.DiscountType, campaign.DiscountPercent, campaign.DiscountAmount, campaign.MaxDiscount,
+		campaign.Budget, campaign.Spent, campaign.PerUserLimit, targetingJSON,
+		campaign.StartsAt, campaign.EndsAt, campaign.CreatedBy, campaign.CreatedAt, campaign.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, code := range campaign.Codes {
+		_, err := tx.Exec(ctx, `INSERT INTO promo_codes (code, campaign_id) VALUES ($1, $2)`, code, campaign.ID)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return domain.ErrPromoCodeTaken
+			}
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetCampaign gets a campaign by ID
+func (r *PromoRepository) GetCampaign(ctx context.Context, id uuid.UUID) (*domain.PromoCampaign, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+promoCampaignColumns+` FROM promo_campaigns c WHERE c.id = $1`, id)
+	return scanPromoCampaign(row)
+}
+
+// GetCampaignByCode gets the campaign a promo code belongs to
+func (r *PromoRepository) GetCampaignByCode(ctx context.Context, code string) (*domain.PromoCampaign, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+promoCampaignColumns+`
+		FROM promo_campaigns c
+		JOIN promo_codes p ON p.campaign_id = c.id
+		WHERE p.code = $1`,
+		code,
+	)
+	return scanPromoCampaign(row)
+}
+
+// ListCampaigns lists campaigns, newest first, optionally by status
+func (r *PromoRepository) ListCampaigns(ctx context.Context, status domain.PromoCampaignStatus) ([]*domain.PromoCampaign, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+promoCampaignColumns+`
+		FROM promo_campaigns c
+		WHERE $1 = '' OR c.status = $1
+		ORDER BY c.created_at DESC`,
+		string(status),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	campaigns := make([]*domain.PromoCampaign, 0)
+	for rows.Next() {
+		campaign, err := scanPromoCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, campaign)
+	}
+
+	return campaigns, rows.Err()
+}
+
+// UpdateCampaignStatus moves a campaign from one status to another
+func (r *PromoRepository) UpdateCampaignStatus(ctx context.Context, id uuid.UUID, from, to domain.PromoCampaignStatus) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE promo_campaigns SET status = $3, updated_at = $4
+		WHERE id = $1 AND status = $2`,
+		id, from, to, time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrPromoCampaignStatus
+	}
+	return nil
+}
+
+// UpdateCampaign applies a change to a campaign under a row lock, so the
+// change sees the budget spent as of now, and stores the result
+func (r *PromoRepository) UpdateCampaign(ctx context.Context, id uuid.UUID, apply func(*domain.PromoCampaign) error) (*domain.PromoCampaign, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	campaign, err := scanPromoCampaign(tx.QueryRow(ctx, `SELECT `+promoCampaignColumns+` FROM promo_campaigns c WHERE c.id = $1 FOR UPDATE`, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := apply(campaign); err != nil {
+		return nil, err
+	}
+	campaign.UpdatedAt = time.Now().UTC()
+
+	targetingJSON, err := json.Marshal(campaign.Targeting)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE promo_campaigns SET
+			name = $2, status = $3, max_discount = $4, budget = $5,
+			per_user_limit = $6, targeting = $7, ends_at = $8, updated_at = $9
+		WHERE id = $1`,
+		campaign.ID, campaign.Name, campaign.Status, campaign.MaxDiscount, campaign.Budget,
+		campaign.PerUserLimit, targetingJSON, campaign.EndsAt, campaign.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return campaign, tx.Commit(ctx)
+}
+
+// AddCodes claims more codes for a campaign
+func (r *PromoRepository) AddCodes(ctx context.Context, campaignID uuid.UUID, codes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockPromoCampaign(ctx, tx, campaignID); err != nil {
+		return err
+	}
+	for _, code := range codes {
+		_, err := tx.Exec(ctx, `INSERT INTO promo_codes (code, campaign_id) VALUES ($1, $2)`, code, campaignID)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return domain.ErrPromoCodeTaken
+			}
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RemoveCode retires one of a campaign's codes. Rides already redeemed with
+// it keep their redemption; a campaign keeps at least one code.
+func (r *PromoRepository) RemoveCode(ctx context.Context, campaignID uuid.UUID, code string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockPromoCampaign(ctx, tx, campaignID); err != nil {
+		return err
+	}
+
+	var owned bool
+	var count int
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(bool_or(code = $2), false), COUNT(*) FROM promo_codes WHERE campaign_id = $1`,
+		campaignID, code,
+	).Scan(&owned, &count)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return domain.ErrPromoCodeNotFound
+	}
+	if count == 1 {
+		return fmt.Errorf("%w: a campaign keeps at least one code", domain.ErrInvalidPromoCampaign)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM promo_codes WHERE code = $1 AND campaign_id = $2`, code, campaignID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// lockPromoCampaign locks a campaign's row for the rest of the transaction
+func lockPromoCampaign(ctx context.Context, tx pgx.Tx, campaignID uuid.UUID) error {
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, `SELECT id FROM promo_campaigns WHERE id = $1 FOR UPDATE`, campaignID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrPromoCampaignNotFound
+	}
+	return err
+}
+
+// GetRiderHistory gets how many rides a rider has completed and when they
+// last completed one
+func (r *PromoRepository) GetRiderHistory(ctx context.Context, riderID uuid.UUID) (int64, *time.Time, error) {
+	var count int64
+	var last *time.Time
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*), MAX(completed_at) FROM rides
+		WHERE rider_id = $1 AND status = 'COMPLETED'`,
+		riderID,
+	).Scan(&count, &last)
+	return count, last, err
+}
+
+// CountRiderRedemptions counts a rider's unreleased redemptions of a campaign
+func (r *PromoRepository) CountRiderRedemptions(ctx context.Context, campaignID, riderID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM promo_redemptions
+		WHERE campaign_id = $1 AND rider_id = $2 AND released_at IS NULL`,
+		campaignID, riderID,
+	).Scan(&count)
+	return count, err
+}
+
+// Redeem reserves a redemption's discount against its campaign's budget,
+// re-checking status, budget and the per-rider limit under a row lock. The
+// campaign is marked EXHAUSTED when the redemption uses up its budget, which
+// is reported back.
+func (r *PromoRepository) Redeem(ctx context.Context, redemption *domain.PromoRedemption) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var status domain.PromoCampaignStatus
+	var budget, spent int64
+	var perUserLimit int
+	err = tx.QueryRow(ctx, `
+		SELECT status, budget, spent, per_user_limit FROM promo_campaigns
+		WHERE id = $1 FOR UPDATE`,
+		redemption.CampaignID,
+	).Scan(&status, &budget, &spent, &perUserLimit)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, domain.ErrInvalidPromoCode
+		}
+		return false, err
+	}
+	if status != domain.PromoCampaignActive || spent+redemption.Discount > budget {
+		return false, domain.ErrInvalidPromoCode
+	}
+
+	if perUserLimit > 0 {
+		var used int
+		err := tx.QueryRow(ctx, `
+			SELECT COUNT(*) FROM promo_redemptions
+			WHERE campaign_id = $1 AND rider_id = $2 AND released_at IS NULL`,
+			redemption.CampaignID, redemption.RiderID,
+		).Scan(&used)
+		if err != nil {
+			return false, err
+		}
+		if used >= perUserLimit {
+			return false, domain.ErrPromoCodeAlreadyUsed
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO promo_redemptions (id, campaign_id, code, ride_id, rider_id, discount, currency, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		redemption.ID, redemption.CampaignID, redemption.Code, redemption.RideID,
+		redemption.RiderID, redemption.Discount, redemption.Currency, redemption.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	spent += redemption.Discount
+	exhausted := spent >= budget
+	if exhausted {
+		status = domain.PromoCampaignExhausted
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE promo_campaigns SET spent = $2, status = $3, updated_at = $4
+		WHERE id = $1`,
+		redemption.CampaignID, spent, status, time.Now().UTC(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return exhausted, tx.Commit(ctx)
+}
+
+// Release returns a ride's redemption to its campaign's budget, reactivating
+// a campaign that had been exhausted. Rides without a redemption are a no-op.
+func (r *PromoRepository) Release(ctx context.Context, rideID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now().UTC()
+	var campaignID uuid.UUID
+	var discount int64
+	err = tx.QueryRow(ctx, `
+		UPDATE promo_redemptions SET released_at = $2
+		WHERE ride_id = $1 AND released_at IS NULL
+		RETURNING campaign_id, discount`,
+		rideID, now,
+	).Scan(&campaignID, &discount)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE promo_campaigns SET
+			spent = spent - $2,
+			status = CASE WHEN status = 'EXHAUSTED' AND spent - $2 < budget THEN 'ACTIVE' ELSE status END,
+			updated_at = $3
+		WHERE id = $1`,
+		campaignID, discount, now,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetCampaignStats gets a campaign's redemption counts from its redemptions
+// and its riders' completed trips from ride events
+func (r *PromoRepository) GetCampaignStats(ctx context.Context, campaign *domain.PromoCampaign, windowDays int) (*domain.PromoCampaignStats, error) {
+	stats := &domain.PromoCampaignStats{
+		CampaignID: campaign.ID,
+		Status:     campaign.Status,
+		Budget:     campaign.Budget,
+		Spent:      campaign.Spent,
+		WindowDays: windowDays,
+	}
+
+	err := r.pool.QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE p.released_at IS NULL),
+			COUNT(*) FILTER (WHERE p.released_at IS NOT NULL),
+			COUNT(DISTINCT p.rider_id) FILTER (WHERE p.released_at IS NULL),
+			COUNT(*) FILTER (WHERE p.released_at IS NULL AND EXISTS (
+				SELECT 1 FROM ride_events e
+				WHERE e.ride_id = p.ride_id AND e.type = 'STATUS_CHANGED' AND e.to_status = 'COMPLETED'
+			))
+		FROM promo_redemptions p
+		WHERE p.campaign_id = $1`,
+		campaign.ID,
+	).Scan(&stats.Redemptions, &stats.Released, &stats.UniqueRiders, &stats.CompletedTrips)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.pool.QueryRow(ctx, `
+		WITH redeemers AS (
+			SELECT rider_id, MIN(created_at) AS first_at
+			FROM promo_redemptions
+			WHERE campaign_id = $1 AND released_at IS NULL
+			GROUP BY rider_id
+		)
+		SELECT
+			COUNT(*) FILTER (WHERE e.created_at >= d.first_at - $2 * INTERVAL '1 day' AND e.created_at < d.first_at),
+			COUNT(*) FILTER (WHERE e.created_at >= d.first_at AND e.created_at < d.first_at + $2 * INTERVAL '1 day')
+		FROM redeemers d
+		JOIN rides r ON r.rider_id = d.rider_id
+		JOIN ride_events e ON e.ride_id = r.id
+		WHERE e.type = 'STATUS_CHANGED' AND e.to_status = 'COMPLETED'`,
+		campaign.ID, windowDays,
+	).Scan(&stats.TripsBefore, &stats.TripsAfter)
+	if err != nil {
+		return nil, err
+	}
+	stats.IncrementalTrips = stats.TripsAfter - stats.TripsBefore
+
+	return stats, nil
+}
+
+func scanPromoCampaign(row pgx.Row) (*domain.PromoCampaign, error) {
+	var campaign domain.PromoCampaign
+	var targetingJSON []byte
+	err := row.Scan(
+		&campaign.ID, &campaign.Name, &campaign.Status, &campaign.Currency,
+		&campaign.DiscountType, &campaign.DiscountPercent, &campaign.DiscountAmount, &campaign.MaxDiscount,
+		&campaign.Budget, &campaign.Spent, &campaign.PerUserLimit, &targetingJSON,
+		&campaign.StartsAt, &campaign.EndsAt, &campaign.CreatedBy, &campaign.CreatedAt, &campaign.UpdatedAt,
+		&campaign.Codes,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrPromoCampaignNotFound
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(targetingJSON, &campaign.Targeting); err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// CreatePromoTables creates the promo campaign tables (for testing/migrations)
+func (r *PromoRepository) CreatePromoTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS promo_campaigns (
+			id UUID PRIMARY KEY,
+			name VARCHAR(200) NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			discount_type VARCHAR(20) NOT NULL,
+			discount_percent DOUBLE PRECISION NOT NULL DEFAULT 0,
+			discount_amount BIGINT NOT NULL DEFAULT 0,
+			max_discount BIGINT NOT NULL DEFAULT 0,
+			budget BIGINT NOT NULL,
+			spent BIGINT NOT NULL DEFAULT 0,
+			per_user_limit INT NOT NULL DEFAULT 0,
+			targeting JSONB NOT NULL DEFAULT '{}'::jsonb,
+			starts_at TIMESTAMPTZ NOT NULL,
+			ends_at TIMESTAMPTZ,
+			created_by UUID NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS promo_codes (
+			code VARCHAR(50) PRIMARY KEY,
+			campaign_id UUID NOT NULL REFERENCES promo_campaigns(id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_promo_codes_campaign_id ON promo_codes(campaign_id);
+
+		CREATE TABLE IF NOT EXISTS promo_redemptions (
+			id UUID PRIMARY KEY,
+			campaign_id UUID NOT NULL REFERENCES promo_campaigns(id),
+			code VARCHAR(50) NOT NULL,
+			ride_id UUID NOT NULL,
+			rider_id UUID NOT NULL,
+			discount BIGINT NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			released_at TIMESTAMPTZ
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_promo_redemptions_campaign_rider ON promo_redemptions(campaign_id, rider_id);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_promo_redemptions_ride_id ON promo_redemptions(ride_id) WHERE released_at IS NULL;
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
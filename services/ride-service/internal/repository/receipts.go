@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// ReceiptRepository handles ride receipts, one per completed ride. A
+// receipt's issued event is pending until published_at is set, so events
+// that fail to publish are retried.
+type ReceiptRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewReceiptRepository creates a new receipt repository
+func NewReceiptRepository(pool *pgxpool.Pool) *ReceiptRepository {
+	return &ReceiptRepository{pool: pool}
+}
+
+// Create stores a receipt, returning false when the ride already has one
+func (r *ReceiptRepository) Create(ctx context.Context, receipt *domain.RideReceipt) (bool, error) {
+	body, err := json.Marshal(receipt)
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO ride_receipts (id, ride_id, rider_id, number, body, issued_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (ride_id) DO NOTHING`,
+		receipt.ID, receipt.RideID, receipt.RiderID, receipt.Number, body, receipt.IssuedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// GetByRide gets a ride's receipt
+func (r *ReceiptRepository) GetByRide(ctx context.Context, rideID uuid.UUID) (*domain.RideReceipt, error) {
+	receipt, err := r.scanReceipt(r.pool.QueryRow(ctx, `
+		SELECT body, published_at FROM ride_receipts WHERE ride_id = $1`,
+		rideID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrReceiptNotFound
+	}
+	return receipt, err
+}
+
+// ListUnpublished lists receipts whose issued event has not gone out,
+// oldest first
+func (r *ReceiptRepository) ListUnpublished(ctx context.Context, limit int) ([]*domain.RideReceipt, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT body, published_at FROM ride_receipts
+		WHERE published_at IS NULL
+		ORDER BY issued_at
+		LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipts := make([]*domain.RideReceipt, 0)
+	for rows.Next() {
+		receipt, err := r.scanReceipt(rows)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	return receipts, rows.Err()
+}
+
+// MarkPublished records that a receipt's issued event went out
+func (r *ReceiptRepository) MarkPublished(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE ride_receipts SET published_at = $2 WHERE id = $1`, id, at)
+	return err
+}
+
+func (r *ReceiptRepository) scanReceipt(row pgx.Row) (*domain.RideReceipt, error) {
+	var body []byte
+	var receipt domain.RideReceipt
+	if err := row.Scan(&body, &receipt.PublishedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &receipt); err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// CreateReceiptTables creates the receipt table (for testing/migrations)
+func (r *ReceiptRepository) CreateReceiptTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS ride_receipts (
+			id UUID PRIMARY KEY,
+			ride_id UUID NOT NULL UNIQUE REFERENCES rides(id),
+			rider_id UUID NOT NULL,
+			number VARCHAR(32) NOT NULL,
+			body JSONB NOT NULL,
+			issued_at TIMESTAMPTZ NOT NULL,
+			published_at TIMESTAMPTZ
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_ride_receipts_unpublished ON ride_receipts(issued_at) WHERE published_at IS NULL;
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// RefundRepository handles refund and ledger data access
+type RefundRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRefundRepository creates a new refund repository
+func NewRefundRepository(pool *pgxpool.Pool) *RefundRepository {
+	return &RefundRepository{pool: pool}
+}
+
+const refundColumns = `
+	id, ride_id, rider_id, type, reason, note, amount, currency, status,
+	requested_by, approved_by, provider_ref, failure_reason, created_at, updated_at`
+
+// Create settles a new refund against the ride's fare and inserts it. The
+// ride is locked while what was already refunded and issued on it is summed,
+// so concurrent refunds cannot together exceed the fare or slip under the
+// approval threshold.
+func (r *RefundRepository) Create(ctx context.Context, refund *domain.Refund, fare int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var rideID uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT id FROM rides WHERE id = $1 FOR UPDATE`, refund.RideID).Scan(&rideID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrRideNotFound
+		}
+		return err
+	}
+
+	var refunded, issued int64
+	err = tx.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(amount) FILTER (WHERE type != 'GOODWILL_CREDIT'), 0),
+			COALESCE(SUM(amount), 0)
+		FROM ride_refunds
+		WHERE ride_id = $1
+			AND status IN ('PENDING_APPROVAL', 'PROCESSING', 'COMPLETED')
+	`, refund.RideID).Scan(&refunded, &issued)
+	if err != nil {
+		return err
+	}
+	if err := refund.Settle(fare, refunded, issued); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO ride_refunds (`+refundColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`,
+		refund.ID, refund.RideID, refund.RiderID, refund.Type, refund.Reason, refund.Note,
+		refund.Amount, refund.Currency, refund.Status,
+		refund.RequestedBy, refund.ApprovedBy, refund.ProviderRef, refund.FailureReason,
+		refund.CreatedAt, refund.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetByID retrieves a refund by ID
+func (r *RefundRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM ride_refunds WHERE id = $1`
+	return r.scanRefund(r.pool.QueryRow(ctx, query, id))
+}
+
+// ListByRide lists refunds for a ride, newest first
+func (r *RefundRepository) ListByRide(ctx context.Context, rideID uuid.UUID) ([]*domain.Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM ride_refunds WHERE ride_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, rideID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := make([]*domain.Refund, 0)
+	for rows.Next() {
+		refund, err := r.scanRefund(rows)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+
+	return refunds, rows.Err()
+}
+
+// TotalRefunded sums refunds against a ride that are completed or in flight
+// (goodwill credits are excluded as they do not return the fare)
+func (r *RefundRepository) TotalRefunded(ctx context.Context, rideID uuid.UUID) (int64, error) {
+	var total int64
+	err := r.pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM ride_refunds
+		WHERE ride_id = $1
+			AND type != 'GOODWILL_CREDIT'
+			AND status IN ('PENDING_APPROVAL', 'PROCESSING', 'COMPLETED')
+	`, rideID).Scan(&total)
+	return total, err
+}
+
+// UpdateStatus moves a refund to a new status if it is currently in fromStatus
+func (r *RefundRepository) UpdateStatus(ctx context.Context, refund *domain.Refund, fromStatus domain.RefundStatus) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE ride_refunds SET
+			status = $3,
+			approved_by = $4,
+			provider_ref = $5,
+			failure_reason = $6,
+			updated_at = $7
+		WHERE id = $1 AND status = $2`,
+		refund.ID, fromStatus, refund.Status, refund.ApprovedBy,
+		refund.ProviderRef, refund.FailureReason, time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrRefundNotPending
+	}
+	return nil
+}
+
+// Complete marks a refund completed and writes its ledger entries atomically
+func (r *RefundRepository) Complete(ctx context.Context, refund *domain.Refund, entries []*domain.LedgerEntry) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE ride_refunds SET status = $2, provider_ref = $3, updated_at = $4
+		WHERE id = $1`,
+		refund.ID, domain.RefundStatusCompleted, refund.ProviderRef, time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO ride_ledger_entries (id, ride_id, refund_id, account, account_id, amount, currency, description, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			entry.ID, entry.RideID, entry.RefundID, entry.Account, entry.AccountID,
+			entry.Amount, entry.Currency, entry.Description, entry.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetLedgerEntries lists ledger entries for a ride
+func (r *RefundRepository) GetLedgerEntries(ctx context.Context, rideID uuid.UUID) ([]*domain.LedgerEntry, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, ride_id, refund_id, account, account_id, amount, currency, description, created_at
+		FROM ride_ledger_entries
+		WHERE ride_id = $1
+		ORDER BY created_at ASC`,
+		rideID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.LedgerEntry, 0)
+	for rows.Next() {
+		var entry domain.LedgerEntry
+		if err := rows.Scan(
+			&entry.ID, &entry.RideID, &entry.RefundID, &entry.Account, &entry.AccountID,
+			&entry.Amount, &entry.Currency, &entry.Description, &entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *RefundRepository) scanRefund(row pgx.Row) (*domain.Refund, error) {
+	var refund domain.Refund
+	var note, providerRef, failureReason sql.NullString
+
+	err := row.Scan(
+		&refund.ID, &refund.RideID, &refund.RiderID, &refund.Type, &refund.Reason, &note,
+		&refund.Amount, &refund.Currency, &refund.Status,
+		&refund.RequestedBy, &refund.ApprovedBy, &providerRef, &failureReason,
+		&refund.CreatedAt, &refund.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrRefundNotFound
+		}
+		return nil, err
+	}
+
+	refund.Note = note.String
+	refund.ProviderRef = providerRef.String
+	refund.FailureReason = failureReason.String
+
+	return &refund, nil
+}
+
+// CreateRefundTables creates the refund and ledger tables (for testing/migrations)
+func (r *RefundRepository) CreateRefundTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS ride_refunds (
+			id UUID PRIMARY KEY,
+			ride_id UUID NOT NULL REFERENCES rides(id),
+			rider_id UUID NOT NULL,
+			type VARCHAR(30) NOT NULL,
+			reason VARCHAR(50) NOT NULL,
+			note TEXT,
+			amount BIGINT NOT NULL CHECK (amount > 0),
+			currency VARCHAR(3) NOT NULL,
+			status VARCHAR(30) NOT NULL,
+			requested_by UUID NOT NULL,
+			approved_by UUID,
+			provider_ref VARCHAR(100),
+			failure_reason TEXT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_ride_refunds_ride_id ON ride_refunds(ride_id);
+		CREATE INDEX IF NOT EXISTS idx_ride_refunds_pending ON ride_refunds(created_at) WHERE status = 'PENDING_APPROVAL';
+
+		CREATE TABLE IF NOT EXISTS ride_ledger_entries (
+			id UUID PRIMARY KEY,
+			ride_id UUID NOT NULL REFERENCES rides(id),
+			refund_id UUID REFERENCES ride_refunds(id),
+			account VARCHAR(20) NOT NULL,
+			account_id UUID,
+			amount BIGINT NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			description TEXT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_ride_ledger_ride_id ON ride_ledger_entries(ride_id);
+		CREATE INDEX IF NOT EXISTS idx_ride_ledger_account ON ride_ledger_entries(account, account_id);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -31,6 +32,7 @@ func (r *RideRepository) Create(ctx context.Context, ride *domain.Ride) error {
 	pickupJSON, _ := json.Marshal(ride.PickupLocation)
 	dropoffJSON, _ := json.Marshal(ride.DropoffLocation)
 	stopsJSON, _ := json.Marshal(ride.Stops)
+	stopStatesJSON, _ := json.Marshal(ride.StopStates)
 	
 	var routeJSON, priceJSON []byte
 	if ride.Route != nil {
@@ -45,7 +47,7 @@ func (r *RideRepository) Create(ctx context.Context, ride *domain.Ride) error {
 	query := `
 		INSERT INTO rides (
 			id, rider_id, driver_id, vehicle_id,
-			pickup_location, dropoff_location, stops, current_location,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
 			type, status, payment_method,
 			route, price,
 			scheduled_for, requested_at, accepted_at, arrived_at,
@@ -53,23 +55,29 @@ func (r *RideRepository) Create(ctx context.Context, ride *domain.Ride) error {
 			cancellation_reason, cancelled_by,
 			rider_rating, driver_rating,
 			promo_code, metadata,
-			created_at, updated_at
+			created_at, updated_at, version
 		) VALUES (
 			$1, $2, $3, $4,
-			$5, $6, $7, $8,
-			$9, $10, $11,
-			$12, $13,
-			$14, $15, $16, $17,
-			$18, $19, $20,
-			$21, $22,
-			$23, $24,
-			$25, $26,
-			$27, $28
+			$5, $6, $7, $8, $9,
+			$10, $11, $12,
+			$13, $14,
+			$15, $16, $17, $18,
+			$19, $20, $21,
+			$22, $23,
+			$24, $25,
+			$26, $27,
+			$28, $29, 1
 		)`
 	
-	_, err := r.pool.Exec(ctx, query,
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	
+	_, err = tx.Exec(ctx, query,
 		ride.ID, ride.RiderID, ride.DriverID, ride.VehicleID,
-		pickupJSON, dropoffJSON, stopsJSON, nil,
+		pickupJSON, dropoffJSON, stopsJSON, stopStatesJSON, nil,
 		ride.Type, ride.Status, ride.PaymentMethod,
 		routeJSON, priceJSON,
 		ride.ScheduledFor, ride.RequestedAt, ride.AcceptedAt, ride.ArrivedAt,
@@ -79,12 +87,99 @@ func (r *RideRepository) Create(ctx context.Context, ride *domain.Ride) error {
 		ride.PromoCode, metadataJSON,
 		ride.CreatedAt, ride.UpdatedAt,
 	)
+	if err != nil {
+		return err
+	}
 	
-	return err
+	if err := r.insertEvents(ctx, tx, ride.PendingEvents()); err != nil {
+		return err
+	}
+	
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	
+	ride.Version = 1
+	ride.ClearPendingEvents()
+	return nil
 }
 
-// Update updates an existing ride
+// Update writes an existing ride if it is still at the version it was read
+// at, returning ErrRideConflict when a concurrent update got there first.
+// The ride's version is bumped on success.
 func (r *RideRepository) Update(ctx context.Context, ride *domain.Ride) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	
+	if err := r.updateRide(ctx, tx, ride); err != nil {
+		return err
+	}
+	if err := r.insertEvents(ctx, tx, ride.PendingEvents()); err != nil {
+		return err
+	}
+	
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	
+	ride.Version++
+	ride.ClearPendingEvents()
+	return nil
+}
+
+// AssignDriverToRide writes a ride the driver has just been assigned to
+// (see Ride.AssignDriver), marks the driver ON_RIDE and records the ride's
+// events in one transaction, so a failure part way never leaves a driver
+// on a ride that has no driver or the reverse. It returns
+// ErrDriverNotAvailable if the driver is no longer free and ErrRideConflict
+// if the ride changed since it was read.
+func (r *RideRepository) AssignDriverToRide(ctx context.Context, ride *domain.Ride) error {
+	if ride.DriverID == nil {
+		return domain.ErrInvalidRequest
+	}
+	
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	
+	result, err := tx.Exec(ctx, `
+		UPDATE drivers SET
+			status = 'ON_RIDE',
+			current_ride_id = $2,
+			updated_at = $3
+		WHERE id = $1 AND status = 'ONLINE' AND current_ride_id IS NULL`,
+		*ride.DriverID, ride.ID, time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrDriverNotAvailable
+	}
+	
+	if err := r.updateRide(ctx, tx, ride); err != nil {
+		return err
+	}
+	if err := r.insertEvents(ctx, tx, ride.PendingEvents()); err != nil {
+		return err
+	}
+	
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	
+	ride.Version++
+	ride.ClearPendingEvents()
+	return nil
+}
+
+// updateRide writes a ride's mutable columns within tx, checking its version
+func (r *RideRepository) updateRide(ctx context.Context, tx pgx.Tx, ride *domain.Ride) error {
 	// Serialize locations
 	var currentLocJSON []byte
 	if ride.CurrentLocation != nil {
@@ -99,6 +194,7 @@ func (r *RideRepository) Update(ctx context.Context, ride *domain.Ride) error {
 		priceJSON, _ = json.Marshal(ride.Price)
 	}
 	
+	stopStatesJSON, _ := json.Marshal(ride.StopStates)
 	metadataJSON, _ := json.Marshal(ride.Metadata)
 	
 	query := `
@@ -119,10 +215,12 @@ func (r *RideRepository) Update(ctx context.Context, ride *domain.Ride) error {
 			rider_rating = $15,
 			driver_rating = $16,
 			metadata = $17,
-			updated_at = $18
-		WHERE id = $1`
+			updated_at = $18,
+			stop_states = $20,
+			version = version + 1
+		WHERE id = $1 AND version = $19`
 	
-	_, err := r.pool.Exec(ctx, query,
+	tag, err := tx.Exec(ctx, query,
 		ride.ID,
 		ride.DriverID,
 		ride.VehicleID,
@@ -141,63 +239,118 @@ func (r *RideRepository) Update(ctx context.Context, ride *domain.Ride) error {
 		ride.DriverRating,
 		metadataJSON,
 		time.Now().UTC(),
+		ride.Version,
+		stopStatesJSON,
 	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRideConflict
+	}
+	return nil
+}
+
+// AppendEvents records timeline events that happen outside a ride write
+// (matching attempts, offers, deviations)
+func (r *RideRepository) AppendEvents(ctx context.Context, events ...*domain.RideEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
 	
-	return err
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	
+	if err := r.insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+	
+	return tx.Commit(ctx)
 }
 
-// GetByID retrieves a ride by ID
-func (r *RideRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Ride, error) {
+// GetEvents retrieves a ride's timeline in chronological order
+func (r *RideRepository) GetEvents(ctx context.Context, rideID uuid.UUID) ([]*domain.RideEvent, error) {
 	query := `
-		SELECT
-			id, rider_id, driver_id, vehicle_id,
-			pickup_location, dropoff_location, stops, current_location,
-			type, status, payment_method,
-			route, price,
-			scheduled_for, requested_at, accepted_at, arrived_at,
-			started_at, completed_at, cancelled_at,
-			cancellation_reason, cancelled_by,
-			rider_rating, driver_rating,
-			promo_code, metadata,
-			created_at, updated_at
-		FROM rides WHERE id = $1`
+		SELECT id, ride_id, type, from_status, to_status, actor_id, data, created_at
+		FROM ride_events
+		WHERE ride_id = $1
+		ORDER BY created_at ASC, id ASC`
 	
-	return r.scanRide(r.pool.QueryRow(ctx, query, id))
+	rows, err := r.pool.Query(ctx, query, rideID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	events := make([]*domain.RideEvent, 0)
+	for rows.Next() {
+		var event domain.RideEvent
+		var fromStatus, toStatus sql.NullString
+		var dataJSON []byte
+		
+		if err := rows.Scan(
+			&event.ID, &event.RideID, &event.Type,
+			&fromStatus, &toStatus, &event.ActorID,
+			&dataJSON, &event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		
+		event.FromStatus = domain.RideStatus(fromStatus.String)
+		event.ToStatus = domain.RideStatus(toStatus.String)
+		if len(dataJSON) > 0 {
+			json.Unmarshal(dataJSON, &event.Data)
+		}
+		
+		events = append(events, &event)
+	}
+	
+	return events, rows.Err()
+}
+
+// insertEvents writes timeline events within an existing transaction
+func (r *RideRepository) insertEvents(ctx context.Context, tx pgx.Tx, events []*domain.RideEvent) error {
+	for _, event := range events {
+		dataJSON, _ := json.Marshal(event.Data)
+		
+		_, err := tx.Exec(ctx, `
+			INSERT INTO ride_events (id, ride_id, type, from_status, to_status, actor_id, data, created_at)
+			VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8)`,
+			event.ID, event.RideID, event.Type,
+			string(event.FromStatus), string(event.ToStatus), event.ActorID,
+			dataJSON, event.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert ride event: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetByID retrieves a ride by ID
+func (r *RideRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Ride, error) {
+	return r.scanRide(r.pool.QueryRow(ctx, getRideByIDSQL, id))
 }
 
 // GetActiveByRider gets the active ride for a rider
 func (r *RideRepository) GetActiveByRider(ctx context.Context, riderID uuid.UUID) (*domain.Ride, error) {
-	query := `
-		SELECT
-			id, rider_id, driver_id, vehicle_id,
-			pickup_location, dropoff_location, stops, current_location,
-			type, status, payment_method,
-			route, price,
-			scheduled_for, requested_at, accepted_at, arrived_at,
-			started_at, completed_at, cancelled_at,
-			cancellation_reason, cancelled_by,
-			rider_rating, driver_rating,
-			promo_code, metadata,
-			created_at, updated_at
-		FROM rides
-		WHERE rider_id = $1
-			AND status NOT IN ('COMPLETED', 'CANCELLED')
-		ORDER BY created_at DESC
-		LIMIT 1`
-	
-	ride, err := r.scanRide(r.pool.QueryRow(ctx, query, riderID))
+	ride, err := r.scanRide(r.pool.QueryRow(ctx, getActiveRideByRiderSQL, riderID))
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
 	return ride, err
 }
 
-// GetActiveByDriver gets the active ride for a driver
-func (r *RideRepository) GetActiveByDriver(ctx context.Context, driverID uuid.UUID) (*domain.Ride, error) {
+// GetRecentRequestsByRider gets the rider's rides requested since the
+// given time that are still waiting for a driver, newest first
+func (r *RideRepository) GetRecentRequestsByRider(ctx context.Context, riderID uuid.UUID, since time.Time) ([]*domain.Ride, error) {
 	query := `
 		SELECT
 			id, rider_id, driver_id, vehicle_id,
-			pickup_location, dropoff_location, stops, current_location,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
 			type, status, payment_method,
 			route, price,
 			scheduled_for, requested_at, accepted_at, arrived_at,
@@ -205,14 +358,34 @@ func (r *RideRepository) GetActiveByDriver(ctx context.Context, driverID uuid.UU
 			cancellation_reason, cancelled_by,
 			rider_rating, driver_rating,
 			promo_code, metadata,
-			created_at, updated_at
+			created_at, updated_at, version
 		FROM rides
-		WHERE driver_id = $1
-			AND status NOT IN ('COMPLETED', 'CANCELLED')
-		ORDER BY created_at DESC
-		LIMIT 1`
+		WHERE rider_id = $1
+			AND status IN ('PENDING', 'SEARCHING')
+			AND requested_at >= $2
+		ORDER BY requested_at DESC`
 	
-	ride, err := r.scanRide(r.pool.QueryRow(ctx, query, driverID))
+	rows, err := r.pool.Query(ctx, query, riderID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	rides := make([]*domain.Ride, 0)
+	for rows.Next() {
+		ride, err := r.scanRideFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		rides = append(rides, ride)
+	}
+	
+	return rides, rows.Err()
+}
+
+// GetActiveByDriver gets the active ride for a driver
+func (r *RideRepository) GetActiveByDriver(ctx context.Context, driverID uuid.UUID) (*domain.Ride, error) {
+	ride, err := r.scanRide(r.pool.QueryRow(ctx, getActiveRideByDriverSQL, driverID))
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -231,7 +404,7 @@ func (r *RideRepository) GetRiderHistory(ctx context.Context, riderID uuid.UUID,
 	query := `
 		SELECT
 			id, rider_id, driver_id, vehicle_id,
-			pickup_location, dropoff_location, stops, current_location,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
 			type, status, payment_method,
 			route, price,
 			scheduled_for, requested_at, accepted_at, arrived_at,
@@ -239,7 +412,7 @@ func (r *RideRepository) GetRiderHistory(ctx context.Context, riderID uuid.UUID,
 			cancellation_reason, cancelled_by,
 			rider_rating, driver_rating,
 			promo_code, metadata,
-			created_at, updated_at
+			created_at, updated_at, version
 		FROM rides
 		WHERE rider_id = $1
 		ORDER BY created_at DESC
@@ -263,9 +436,86 @@ func (r *RideRepository) GetRiderHistory(ctx context.Context, riderID uuid.UUID,
 	return rides, total, nil
 }
 
-// UpdateStatus updates just the ride status
+// ListHistory gets a page of a rider's or driver's rides matching the
+// filter, newest first. One ride more than the limit is read so the caller
+// can tell whether another page follows.
+func (r *RideRepository) ListHistory(ctx context.Context, userID uuid.UUID, asDriver bool, filter *domain.RideHistoryFilter) ([]*domain.Ride, error) {
+	column := "rider_id"
+	if asDriver {
+		column = "driver_id"
+	}
+	conditions := []string{column + " = $1"}
+	args := []interface{}{userID}
+	
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		args = append(args, statuses)
+		conditions = append(conditions, fmt.Sprintf("status = ANY($%d)", len(args)))
+	}
+	if len(filter.Types) > 0 {
+		types := make([]string, len(filter.Types))
+		for i, rideType := range filter.Types {
+			types[i] = string(rideType)
+		}
+		args = append(args, types)
+		conditions = append(conditions, fmt.Sprintf("type = ANY($%d)", len(args)))
+	}
+	if filter.Cursor != nil {
+		args = append(args, filter.Cursor.CreatedAt, filter.Cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	args = append(args, filter.Limit+1)
+	
+	query := `
+		SELECT
+			id, rider_id, driver_id, vehicle_id,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
+			type, status, payment_method,
+			route, price,
+			scheduled_for, requested_at, accepted_at, arrived_at,
+			started_at, completed_at, cancelled_at,
+			cancellation_reason, cancelled_by,
+			rider_rating, driver_rating,
+			promo_code, metadata,
+			created_at, updated_at, version
+		FROM rides
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ` + fmt.Sprintf("$%d", len(args))
+	
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	rides := make([]*domain.Ride, 0, filter.Limit+1)
+	for rows.Next() {
+		ride, err := r.scanRideFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		rides = append(rides, ride)
+	}
+	
+	return rides, rows.Err()
+}
+
+// UpdateStatus updates just the ride status, bumping the version so
+// full updates read before it conflict
 func (r *RideRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.RideStatus) error {
-	query := `UPDATE rides SET status = $2, updated_at = $3 WHERE id = $1`
+	query := `UPDATE rides SET status = $2, updated_at = $3, version = version + 1 WHERE id = $1`
 	_, err := r.pool.Exec(ctx, query, id, status, time.Now().UTC())
 	return err
 }
@@ -278,17 +528,43 @@ func (r *RideRepository) UpdateLocation(ctx context.Context, id uuid.UUID, locat
 	return err
 }
 
+// GetLocationTrail gets the GPS points recorded while a ride was in progress
+func (r *RideRepository) GetLocationTrail(ctx context.Context, rideID uuid.UUID) ([]domain.TrailPoint, error) {
+	query := `
+		SELECT latitude, longitude, COALESCE(speed, 0), recorded_at
+		FROM ride_locations
+		WHERE ride_id = $1
+		ORDER BY recorded_at ASC`
+	
+	rows, err := r.pool.Query(ctx, query, rideID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	trail := make([]domain.TrailPoint, 0)
+	for rows.Next() {
+		var p domain.TrailPoint
+		if err := rows.Scan(&p.Latitude, &p.Longitude, &p.Speed, &p.RecordedAt); err != nil {
+			return nil, err
+		}
+		trail = append(trail, p)
+	}
+	
+	return trail, rows.Err()
+}
+
 // scanRide scans a single ride from a row
 func (r *RideRepository) scanRide(row pgx.Row) (*domain.Ride, error) {
 	var ride domain.Ride
 	var driverID, vehicleID, cancelledBy sql.NullString
-	var pickupJSON, dropoffJSON, stopsJSON, currentLocJSON, routeJSON, priceJSON, metadataJSON []byte
+	var pickupJSON, dropoffJSON, stopsJSON, stopStatesJSON, currentLocJSON, routeJSON, priceJSON, metadataJSON []byte
 	var scheduledFor, acceptedAt, arrivedAt, startedAt, completedAt, cancelledAt sql.NullTime
 	var riderRating, driverRating sql.NullFloat64
 	
 	err := row.Scan(
 		&ride.ID, &ride.RiderID, &driverID, &vehicleID,
-		&pickupJSON, &dropoffJSON, &stopsJSON, &currentLocJSON,
+		&pickupJSON, &dropoffJSON, &stopsJSON, &stopStatesJSON, &currentLocJSON,
 		&ride.Type, &ride.Status, &ride.PaymentMethod,
 		&routeJSON, &priceJSON,
 		&scheduledFor, &ride.RequestedAt, &acceptedAt, &arrivedAt,
@@ -296,7 +572,7 @@ func (r *RideRepository) scanRide(row pgx.Row) (*domain.Ride, error) {
 		&ride.CancellationReason, &cancelledBy,
 		&riderRating, &driverRating,
 		&ride.PromoCode, &metadataJSON,
-		&ride.CreatedAt, &ride.UpdatedAt,
+		&ride.CreatedAt, &ride.UpdatedAt, &ride.Version,
 	)
 	
 	if err != nil {
@@ -354,6 +630,7 @@ func (r *RideRepository) scanRide(row pgx.Row) (*domain.Ride, error) {
 	_ = json.Unmarshal(pickupJSON, &ride.PickupLocation)
 	_ = json.Unmarshal(dropoffJSON, &ride.DropoffLocation)
 	_ = json.Unmarshal(stopsJSON, &ride.Stops)
+	_ = json.Unmarshal(stopStatesJSON, &ride.StopStates)
 	if len(currentLocJSON) > 0 {
 		var loc domain.Location
 		if json.Unmarshal(currentLocJSON, &loc) == nil {
@@ -384,13 +661,13 @@ func (r *RideRepository) scanRide(row pgx.Row) (*domain.Ride, error) {
 func (r *RideRepository) scanRideFromRows(rows pgx.Rows) (*domain.Ride, error) {
 	var ride domain.Ride
 	var driverID, vehicleID, cancelledBy sql.NullString
-	var pickupJSON, dropoffJSON, stopsJSON, currentLocJSON, routeJSON, priceJSON, metadataJSON []byte
+	var pickupJSON, dropoffJSON, stopsJSON, stopStatesJSON, currentLocJSON, routeJSON, priceJSON, metadataJSON []byte
 	var scheduledFor, acceptedAt, arrivedAt, startedAt, completedAt, cancelledAt sql.NullTime
 	var riderRating, driverRating sql.NullFloat64
 	
 	err := rows.Scan(
 		&ride.ID, &ride.RiderID, &driverID, &vehicleID,
-		&pickupJSON, &dropoffJSON, &stopsJSON, &currentLocJSON,
+		&pickupJSON, &dropoffJSON, &stopsJSON, &stopStatesJSON, &currentLocJSON,
 		&ride.Type, &ride.Status, &ride.PaymentMethod,
 		&routeJSON, &priceJSON,
 		&scheduledFor, &ride.RequestedAt, &acceptedAt, &arrivedAt,
@@ -398,7 +675,7 @@ func (r *RideRepository) scanRideFromRows(rows pgx.Rows) (*domain.Ride, error) {
 		&ride.CancellationReason, &cancelledBy,
 		&riderRating, &driverRating,
 		&ride.PromoCode, &metadataJSON,
-		&ride.CreatedAt, &ride.UpdatedAt,
+		&ride.CreatedAt, &ride.UpdatedAt, &ride.Version,
 	)
 	
 	if err != nil {
@@ -453,6 +730,7 @@ func (r *RideRepository) scanRideFromRows(rows pgx.Rows) (*domain.Ride, error) {
 	_ = json.Unmarshal(pickupJSON, &ride.PickupLocation)
 	_ = json.Unmarshal(dropoffJSON, &ride.DropoffLocation)
 	_ = json.Unmarshal(stopsJSON, &ride.Stops)
+	_ = json.Unmarshal(stopStatesJSON, &ride.StopStates)
 	if len(currentLocJSON) > 0 {
 		var loc domain.Location
 		if json.Unmarshal(currentLocJSON, &loc) == nil {
@@ -484,7 +762,7 @@ func (r *RideRepository) GetPendingScheduledRides(ctx context.Context, beforeTim
 	query := `
 		SELECT
 			id, rider_id, driver_id, vehicle_id,
-			pickup_location, dropoff_location, stops, current_location,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
 			type, status, payment_method,
 			route, price,
 			scheduled_for, requested_at, accepted_at, arrived_at,
@@ -492,7 +770,7 @@ func (r *RideRepository) GetPendingScheduledRides(ctx context.Context, beforeTim
 			cancellation_reason, cancelled_by,
 			rider_rating, driver_rating,
 			promo_code, metadata,
-			created_at, updated_at
+			created_at, updated_at, version
 		FROM rides
 		WHERE status = 'PENDING'
 			AND scheduled_for IS NOT NULL
@@ -518,47 +796,153 @@ func (r *RideRepository) GetPendingScheduledRides(ctx context.Context, beforeTim
 	return rides, nil
 }
 
-// GetMetrics gets ride metrics for analytics
+// GetCompletedRidesSince gets completed rides for offline aggregation jobs
+func (r *RideRepository) GetCompletedRidesSince(ctx context.Context, since time.Time, limit int) ([]*domain.Ride, error) {
+	query := `
+		SELECT
+			id, rider_id, driver_id, vehicle_id,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
+			type, status, payment_method,
+			route, price,
+			scheduled_for, requested_at, accepted_at, arrived_at,
+			started_at, completed_at, cancelled_at,
+			cancellation_reason, cancelled_by,
+			rider_rating, driver_rating,
+			promo_code, metadata,
+			created_at, updated_at, version
+		FROM rides
+		WHERE status = 'COMPLETED'
+			AND started_at IS NOT NULL
+			AND completed_at >= $1
+		ORDER BY completed_at DESC
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rides []*domain.Ride
+	for rows.Next() {
+		ride, err := r.scanRideFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		rides = append(rides, ride)
+	}
+
+	return rides, nil
+}
+
+// GetStuckRides gets rides in a status that have not been updated since the
+// given time, oldest first. Scheduled rides waiting for their pickup time
+// are not stuck and are left out.
+func (r *RideRepository) GetStuckRides(ctx context.Context, status domain.RideStatus, updatedBefore time.Time, limit int) ([]*domain.Ride, error) {
+	query := `
+		SELECT
+			id, rider_id, driver_id, vehicle_id,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
+			type, status, payment_method,
+			route, price,
+			scheduled_for, requested_at, accepted_at, arrived_at,
+			started_at, completed_at, cancelled_at,
+			cancellation_reason, cancelled_by,
+			rider_rating, driver_rating,
+			promo_code, metadata,
+			created_at, updated_at, version
+		FROM rides
+		WHERE status = $1
+			AND updated_at < $2
+			AND NOT (status = 'PENDING' AND scheduled_for IS NOT NULL)
+		ORDER BY updated_at ASC
+		LIMIT $3`
+
+	rows, err := r.pool.Query(ctx, query, status, updatedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rides []*domain.Ride
+	for rows.Next() {
+		ride, err := r.scanRideFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		rides = append(rides, ride)
+	}
+
+	return rides, rows.Err()
+}
+
+// GetMatchingExpiredRides gets rides still looking for a driver that started
+// looking before the given time, oldest first. Scheduled rides are left to
+// their pickup guarantee.
+func (r *RideRepository) GetMatchingExpiredRides(ctx context.Context, startedBefore time.Time, limit int) ([]*domain.Ride, error) {
+	query := `
+		SELECT
+			id, rider_id, driver_id, vehicle_id,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
+			type, status, payment_method,
+			route, price,
+			scheduled_for, requested_at, accepted_at, arrived_at,
+			started_at, completed_at, cancelled_at,
+			cancellation_reason, cancelled_by,
+			rider_rating, driver_rating,
+			promo_code, metadata,
+			created_at, updated_at, version
+		FROM rides
+		WHERE status IN ('PENDING', 'SEARCHING', 'MATCHED')
+			AND scheduled_for IS NULL
+			AND COALESCE((metadata->>'matching_started_at')::TIMESTAMPTZ, requested_at) < $1
+		ORDER BY requested_at ASC
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, startedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rides []*domain.Ride
+	for rows.Next() {
+		ride, err := r.scanRideFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		rides = append(rides, ride)
+	}
+
+	return rides, rows.Err()
+}
+
+// GetMetrics gets ride metrics for analytics from the hourly rollups, so
+// the period is widened to whole hours
 func (r *RideRepository) GetMetrics(ctx context.Context, startTime, endTime time.Time) (map[string]any, error) {
 	metrics := make(map[string]any)
 	
-	// Total rides
-	var totalRides int64
-	r.pool.QueryRow(ctx, `
-		SELECT COUNT(*) FROM rides
-		WHERE created_at >= $1 AND created_at < $2
-	`, startTime, endTime).Scan(&totalRides)
+	var totalRides, completedRides, cancelledRides, revenue, fareCount int64
+	err := r.pool.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(requested), 0)::BIGINT,
+			COALESCE(SUM(completed), 0)::BIGINT,
+			COALESCE(SUM(cancelled), 0)::BIGINT,
+			COALESCE(SUM(revenue), 0)::BIGINT,
+			COALESCE(SUM(fare_count), 0)::BIGINT
+		FROM ride_hourly_metrics
+		WHERE hour >= date_trunc('hour', $1::TIMESTAMPTZ) AND hour < $2
+	`, startTime, endTime).Scan(&totalRides, &completedRides, &cancelledRides, &revenue, &fareCount)
+	if err != nil {
+		return nil, err
+	}
 	metrics["total_rides"] = totalRides
-	
-	// Completed rides
-	var completedRides int64
-	r.pool.QueryRow(ctx, `
-		SELECT COUNT(*) FROM rides
-		WHERE status = 'COMPLETED'
-			AND completed_at >= $1 AND completed_at < $2
-	`, startTime, endTime).Scan(&completedRides)
 	metrics["completed_rides"] = completedRides
-	
-	// Cancelled rides
-	var cancelledRides int64
-	r.pool.QueryRow(ctx, `
-		SELECT COUNT(*) FROM rides
-		WHERE status = 'CANCELLED'
-			AND cancelled_at >= $1 AND cancelled_at < $2
-	`, startTime, endTime).Scan(&cancelledRides)
 	metrics["cancelled_rides"] = cancelledRides
 	
 	// Average ride value
-	var avgValue sql.NullFloat64
-	r.pool.QueryRow(ctx, `
-		SELECT AVG((price->>'total')::numeric)
-		FROM rides
-		WHERE status = 'COMPLETED'
-			AND completed_at >= $1 AND completed_at < $2
-			AND price IS NOT NULL
-	`, startTime, endTime).Scan(&avgValue)
-	if avgValue.Valid {
-		metrics["average_ride_value"] = avgValue.Float64
+	if fareCount > 0 {
+		metrics["average_ride_value"] = float64(revenue) / float64(fareCount)
 	}
 	
 	// Completion rate
@@ -580,6 +964,7 @@ func (r *RideRepository) CreateRidesTable(ctx context.Context) error {
 			pickup_location JSONB NOT NULL,
 			dropoff_location JSONB NOT NULL,
 			stops JSONB DEFAULT '[]'::jsonb,
+			stop_states JSONB DEFAULT '[]'::jsonb,
 			current_location JSONB,
 			type VARCHAR(50) NOT NULL,
 			status VARCHAR(50) NOT NULL DEFAULT 'PENDING',
@@ -600,14 +985,46 @@ func (r *RideRepository) CreateRidesTable(ctx context.Context) error {
 			promo_code VARCHAR(50),
 			metadata JSONB DEFAULT '{}'::jsonb,
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			version BIGINT NOT NULL DEFAULT 1
 		);
 		
+		ALTER TABLE rides ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+		ALTER TABLE rides ADD COLUMN IF NOT EXISTS stop_states JSONB DEFAULT '[]'::jsonb;
+		
 		CREATE INDEX IF NOT EXISTS idx_rides_rider_id ON rides(rider_id);
 		CREATE INDEX IF NOT EXISTS idx_rides_driver_id ON rides(driver_id);
 		CREATE INDEX IF NOT EXISTS idx_rides_status ON rides(status);
 		CREATE INDEX IF NOT EXISTS idx_rides_scheduled_for ON rides(scheduled_for) WHERE scheduled_for IS NOT NULL;
 		CREATE INDEX IF NOT EXISTS idx_rides_created_at ON rides(created_at);
+		CREATE INDEX IF NOT EXISTS idx_rides_rider_history ON rides(rider_id, created_at DESC, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_rides_driver_history ON rides(driver_id, created_at DESC, id DESC) WHERE driver_id IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_rides_completed_at ON rides(completed_at) WHERE status = 'COMPLETED';
+		
+		CREATE TABLE IF NOT EXISTS ride_events (
+			id UUID PRIMARY KEY,
+			ride_id UUID NOT NULL REFERENCES rides(id) ON DELETE CASCADE,
+			type VARCHAR(50) NOT NULL,
+			from_status VARCHAR(50),
+			to_status VARCHAR(50),
+			actor_id UUID,
+			data JSONB DEFAULT '{}'::jsonb,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		
+		CREATE INDEX IF NOT EXISTS idx_ride_events_ride_id ON ride_events(ride_id, created_at);
+		
+		CREATE TABLE IF NOT EXISTS ride_locations (
+			id BIGSERIAL PRIMARY KEY,
+			ride_id UUID NOT NULL REFERENCES rides(id) ON DELETE CASCADE,
+			driver_id UUID NOT NULL,
+			latitude DOUBLE PRECISION NOT NULL,
+			longitude DOUBLE PRECISION NOT NULL,
+			speed DOUBLE PRECISION,
+			recorded_at TIMESTAMPTZ NOT NULL
+		);
+		
+		CREATE INDEX IF NOT EXISTS idx_ride_locations_ride_id ON ride_locations(ride_id, recorded_at);
 	`
 	
 	_, err := r.pool.Exec(ctx, query)
@@ -637,24 +1054,7 @@ func NewDriverRepository(pool *pgxpool.Pool) *DriverRepository {
 
 // GetByID gets a driver by ID
 func (r *DriverRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Driver, error) {
-	query := `
-		SELECT
-			d.id, d.user_id, d.status,
-			u.first_name, u.last_name, u.phone, u.profile_photo,
-			d.current_location, d.h3_cell, d.last_location_at,
-			d.heading, d.speed,
-			d.rating, d.total_rides, d.acceptance_rate,
-			d.current_ride_id, d.online_since,
-			d.created_at, d.updated_at,
-			v.id as vehicle_id, v.type as vehicle_type,
-			v.make, v.model, v.year, v.color, v.license_plate,
-			v.capacity, v.supported_types
-		FROM drivers d
-		JOIN users u ON u.id = d.user_id
-		LEFT JOIN vehicles v ON v.driver_id = d.id AND v.is_active = true
-		WHERE d.id = $1`
-	
-	return r.scanDriver(r.pool.QueryRow(ctx, query, id))
+	return r.scanDriver(r.pool.QueryRow(ctx, getDriverByIDSQL, id))
 }
 
 // GetNearby gets drivers near a location
@@ -671,7 +1071,7 @@ func (r *DriverRepository) GetNearby(ctx context.Context, lat, lng, radiusM floa
 			d.created_at, d.updated_at,
 			v.id as vehicle_id, v.type as vehicle_type,
 			v.make, v.model, v.year, v.color, v.license_plate,
-			v.capacity, v.supported_types,
+			v.capacity, v.supported_types, v.powertrain,
 			ST_Distance(
 				d.location_point::geography,
 				ST_SetSRID(ST_MakePoint($2, $1), 4326)::geography
@@ -747,6 +1147,90 @@ func (r *DriverRepository) UpdateLocation(ctx context.Context, driverID uuid.UUI
 	return err
 }
 
+// UpdateLocations writes many drivers' locations in one statement. A
+// location older than the one already stored is skipped, so a late batch
+// never moves a driver back.
+func (r *DriverRepository) UpdateLocations(ctx context.Context, locs []*domain.DriverLocation) error {
+	if len(locs) == 0 {
+		return nil
+	}
+	
+	ids := make([]uuid.UUID, len(locs))
+	lats := make([]float64, len(locs))
+	lngs := make([]float64, len(locs))
+	cells := make([]string, len(locs))
+	headings := make([]float64, len(locs))
+	speeds := make([]float64, len(locs))
+	timestamps := make([]time.Time, len(locs))
+	for i, loc := range locs {
+		ids[i] = loc.DriverID
+		lats[i] = loc.Location.Latitude
+		lngs[i] = loc.Location.Longitude
+		cells[i] = loc.Location.H3Cell
+		headings[i] = loc.Heading
+		speeds[i] = loc.Speed
+		timestamps[i] = loc.Timestamp
+	}
+	
+	query := `
+		UPDATE drivers d SET
+			current_location = jsonb_build_object('latitude', v.lat, 'longitude', v.lng, 'h3_cell', v.h3_cell),
+			location_point = ST_SetSRID(ST_MakePoint(v.lng, v.lat), 4326),
+			h3_cell = v.h3_cell,
+			heading = v.heading,
+			speed = v.speed,
+			last_location_at = v.at,
+			updated_at = v.at
+		FROM unnest($1::uuid[], $2::float8[], $3::float8[], $4::text[], $5::float8[], $6::float8[], $7::timestamptz[])
+			AS v(id, lat, lng, h3_cell, heading, speed, at)
+		WHERE d.id = v.id AND (d.last_location_at IS NULL OR d.last_location_at < v.at)`
+	
+	_, err := r.pool.Exec(ctx, query, ids, lats, lngs, cells, headings, speeds, timestamps)
+	if err != nil {
+		return fmt.Errorf("failed to update driver locations: %w", err)
+	}
+	return nil
+}
+
+// RecordRideLocation appends a location to the trail of the driver's
+// in-progress ride, if any
+func (r *DriverRepository) RecordRideLocation(ctx context.Context, driverID uuid.UUID, loc *domain.DriverLocation) error {
+	query := `
+		INSERT INTO ride_locations (ride_id, driver_id, latitude, longitude, speed, recorded_at)
+		SELECT rd.id, $1, $2, $3, $4, $5
+		FROM drivers d
+		JOIN rides rd ON rd.id = d.current_ride_id
+		WHERE d.id = $1 AND rd.status = 'IN_PROGRESS'`
+	
+	_, err := r.pool.Exec(ctx, query,
+		driverID, loc.Location.Latitude, loc.Location.Longitude, loc.Speed, loc.Timestamp,
+	)
+	return err
+}
+
+// GetInProgressRoute gets the ID and planned route of the driver's
+// in-progress ride; the ID is nil when the driver has none
+func (r *DriverRepository) GetInProgressRoute(ctx context.Context, driverID uuid.UUID) (uuid.UUID, *domain.RouteInfo, error) {
+	var rideID uuid.UUID
+	var routeJSON []byte
+	err := r.pool.QueryRow(ctx, getInProgressRouteSQL, driverID).Scan(&rideID, &routeJSON)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, nil, nil
+	}
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	
+	var route *domain.RouteInfo
+	if len(routeJSON) > 0 {
+		route = &domain.RouteInfo{}
+		if err := json.Unmarshal(routeJSON, route); err != nil {
+			return uuid.Nil, nil, err
+		}
+	}
+	return rideID, route, nil
+}
+
 // UpdateStatus updates a driver's status
 func (r *DriverRepository) UpdateStatus(ctx context.Context, driverID uuid.UUID, status domain.DriverStatus) error {
 	now := time.Now().UTC()
@@ -792,7 +1276,7 @@ func (r *DriverRepository) AssignRide(ctx context.Context, driverID, rideID uuid
 func (r *DriverRepository) CompleteRide(ctx context.Context, driverID uuid.UUID) error {
 	query := `
 		UPDATE drivers SET
-			status = 'ONLINE',
+			status = CASE WHEN status IN ('SUSPENDED', 'DEACTIVATED', 'UNDER_REVIEW') THEN status ELSE 'ONLINE' END,
 			current_ride_id = NULL,
 			total_rides = total_rides + 1,
 			updated_at = $2
@@ -802,13 +1286,27 @@ func (r *DriverRepository) CompleteRide(ctx context.Context, driverID uuid.UUID)
 	return err
 }
 
+// ReleaseRide frees a driver still assigned to a ride that ended without
+// completing, without counting it towards their total
+func (r *DriverRepository) ReleaseRide(ctx context.Context, driverID, rideID uuid.UUID) error {
+	query := `
+		UPDATE drivers SET
+			status = CASE WHEN status = 'ON_RIDE' THEN 'ONLINE' ELSE status END,
+			current_ride_id = NULL,
+			updated_at = $3
+		WHERE id = $1 AND current_ride_id = $2`
+	
+	_, err := r.pool.Exec(ctx, query, driverID, rideID, time.Now().UTC())
+	return err
+}
+
 func (r *DriverRepository) scanDriver(row pgx.Row) (*domain.Driver, error) {
 	var driver domain.Driver
 	var currentLocJSON []byte
 	var lastLocAt, onlineSince sql.NullTime
 	var currentRideID, vehicleID sql.NullString
 	var vehicleType sql.NullString
-	var make, model, color, licensePlate sql.NullString
+	var make, model, color, licensePlate, powertrain sql.NullString
 	var year, capacity sql.NullInt32
 	var supportedTypes []byte
 	
@@ -822,7 +1320,7 @@ func (r *DriverRepository) scanDriver(row pgx.Row) (*domain.Driver, error) {
 		&driver.CreatedAt, &driver.UpdatedAt,
 		&vehicleID, &vehicleType,
 		&make, &model, &year, &color, &licensePlate,
-		&capacity, &supportedTypes,
+		&capacity, &supportedTypes, &powertrain,
 	)
 	
 	if err != nil {
@@ -885,6 +1383,9 @@ func (r *DriverRepository) scanDriver(row pgx.Row) (*domain.Driver, error) {
 		if len(supportedTypes) > 0 {
 			_ = json.Unmarshal(supportedTypes, &vehicle.SupportedTypes)
 		}
+		if powertrain.Valid {
+			vehicle.Powertrain = domain.Powertrain(powertrain.String)
+		}
 		
 		vehicle.IsActive = true
 		driver.Vehicle = vehicle
@@ -899,7 +1400,7 @@ func (r *DriverRepository) scanNearbyDriver(rows pgx.Rows) (*domain.NearbyDriver
 	var lastLocAt, onlineSince sql.NullTime
 	var currentRideID, vehicleID sql.NullString
 	var vehicleType sql.NullString
-	var make, model, color, licensePlate sql.NullString
+	var make, model, color, licensePlate, powertrain sql.NullString
 	var year, capacity sql.NullInt32
 	var supportedTypes []byte
 	var distanceMeters float64
@@ -914,7 +1415,7 @@ func (r *DriverRepository) scanNearbyDriver(rows pgx.Rows) (*domain.NearbyDriver
 		&driver.CreatedAt, &driver.UpdatedAt,
 		&vehicleID, &vehicleType,
 		&make, &model, &year, &color, &licensePlate,
-		&capacity, &supportedTypes,
+		&capacity, &supportedTypes, &powertrain,
 		&distanceMeters,
 	)
 	
@@ -969,6 +1470,9 @@ func (r *DriverRepository) scanNearbyDriver(rows pgx.Rows) (*domain.NearbyDriver
 		if len(supportedTypes) > 0 {
 			_ = json.Unmarshal(supportedTypes, &vehicle.SupportedTypes)
 		}
+		if powertrain.Valid {
+			vehicle.Powertrain = domain.Powertrain(powertrain.String)
+		}
 		
 		vehicle.IsActive = true
 		driver.Vehicle = vehicle
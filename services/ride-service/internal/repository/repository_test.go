@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// newTestPool connects to a scratch database for the repository tests. They
+// run only when RIDE_TEST_DATABASE_URL points at one:
+//
+//	RIDE_TEST_DATABASE_URL=postgres://localhost/ride_test \
+//	  go test ./internal/repository
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv("RIDE_TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("RIDE_TEST_DATABASE_URL not set")
+	}
+	pool, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	if err := NewRideRepository(pool).CreateRidesTable(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return pool
+}
+
+// createTestRide stores a ride that is searching for a driver
+func createTestRide(t *testing.T, repo *RideRepository) *domain.Ride {
+	t.Helper()
+	ride := domain.NewRide(&domain.RideRequest{
+		RiderID:         uuid.New(),
+		PickupLocation:  domain.Location{Latitude: 6.4281, Longitude: 3.4219},
+		DropoffLocation: domain.Location{Latitude: 6.455, Longitude: 3.3841},
+		Type:            domain.RideTypeStandard,
+		PaymentMethod:   domain.PaymentMethodCash,
+	})
+	if err := ride.UpdateStatus(domain.RideStatusSearching); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create(context.Background(), ride); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return ride
+}
+
+func TestRideRepositoryUpdateChecksVersion(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRideRepository(newTestPool(t))
+	ride := createTestRide(t, repo)
+
+	// Two requests read the ride at the same version
+	first, err := repo.GetByID(ctx, ride.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := repo.GetByID(ctx, ride.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first.Metadata["first"] = true
+	if err := repo.Update(ctx, first); err != nil {
+		t.Fatalf("first Update() error = %v", err)
+	}
+	if first.Version != ride.Version+1 {
+		t.Errorf("version after update = %d, want %d", first.Version, ride.Version+1)
+	}
+
+	second.Metadata["second"] = true
+	if err := repo.Update(ctx, second); !errors.Is(err, domain.ErrRideConflict) {
+		t.Fatalf("stale Update() error = %v, want ErrRideConflict", err)
+	}
+
+	stored, err := repo.GetByID(ctx, ride.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Version != first.Version || stored.Metadata["first"] != true || stored.Metadata["second"] != nil {
+		t.Errorf("stored = version %d, metadata %v, want the first update only", stored.Version, stored.Metadata)
+	}
+
+	// Reloaded at the new version, the second request's change goes through
+	stored.Metadata["second"] = true
+	if err := repo.Update(ctx, stored); err != nil {
+		t.Fatalf("Update() after reload error = %v", err)
+	}
+}
+
+// createTestDriver stores a driver in the given status, on currentRide if
+// set. Only the columns driver assignment reads are kept in the scratch
+// database's drivers table.
+func createTestDriver(t *testing.T, pool *pgxpool.Pool, status domain.DriverStatus, currentRide *uuid.UUID) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS drivers (
+			id UUID PRIMARY KEY,
+			status VARCHAR(20) NOT NULL,
+			current_ride_id UUID,
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	driverID := uuid.New()
+	_, err = pool.Exec(ctx, `INSERT INTO drivers (id, status, current_ride_id) VALUES ($1, $2, $3)`,
+		driverID, status, currentRide)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return driverID
+}
+
+// driverState reads back a driver's status and current ride
+func driverState(t *testing.T, pool *pgxpool.Pool, driverID uuid.UUID) (domain.DriverStatus, *uuid.UUID) {
+	t.Helper()
+	var status domain.DriverStatus
+	var currentRide *uuid.UUID
+	err := pool.QueryRow(context.Background(), `SELECT status, current_ride_id FROM drivers WHERE id = $1`, driverID).
+		Scan(&status, &currentRide)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return status, currentRide
+}
+
+func TestRideRepositoryAssignDriverToRide(t *testing.T) {
+	ctx := context.Background()
+	pool := newTestPool(t)
+	repo := NewRideRepository(pool)
+
+	assign := func(t *testing.T, ride *domain.Ride, driverID uuid.UUID) error {
+		t.Helper()
+		if err := ride.AssignDriver(driverID, uuid.New()); err != nil {
+			t.Fatal(err)
+		}
+		return repo.AssignDriverToRide(ctx, ride)
+	}
+
+	// unassigned checks a refused assignment left the ride searching
+	unassigned := func(t *testing.T, rideID uuid.UUID) {
+		t.Helper()
+		stored, err := repo.GetByID(ctx, rideID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Status != domain.RideStatusSearching || stored.DriverID != nil {
+			t.Errorf("stored ride = %s with driver %v, want still searching", stored.Status, stored.DriverID)
+		}
+	}
+
+	t.Run("online driver", func(t *testing.T) {
+		ride := createTestRide(t, repo)
+		driverID := createTestDriver(t, pool, domain.DriverStatusOnline, nil)
+		version := ride.Version
+
+		if err := assign(t, ride, driverID); err != nil {
+			t.Fatalf("AssignDriverToRide() error = %v", err)
+		}
+		if ride.Version != version+1 {
+			t.Errorf("version = %d, want %d", ride.Version, version+1)
+		}
+		status, currentRide := driverState(t, pool, driverID)
+		if status != domain.DriverStatusOnRide || currentRide == nil || *currentRide != ride.ID {
+			t.Errorf("driver = %s on %v, want ON_RIDE on the ride", status, currentRide)
+		}
+		stored, err := repo.GetByID(ctx, ride.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Status != domain.RideStatusAccepted || stored.DriverID == nil || *stored.DriverID != driverID {
+			t.Errorf("stored ride = %s with driver %v, want accepted by the driver", stored.Status, stored.DriverID)
+		}
+	})
+
+	t.Run("driver offline", func(t *testing.T) {
+		ride := createTestRide(t, repo)
+		driverID := createTestDriver(t, pool, domain.DriverStatusOffline, nil)
+
+		if err := assign(t, ride, driverID); !errors.Is(err, domain.ErrDriverNotAvailable) {
+			t.Fatalf("AssignDriverToRide() error = %v, want ErrDriverNotAvailable", err)
+		}
+		unassigned(t, ride.ID)
+	})
+
+	t.Run("driver already on a ride", func(t *testing.T) {
+		ride := createTestRide(t, repo)
+		other := uuid.New()
+		driverID := createTestDriver(t, pool, domain.DriverStatusOnline, &other)
+
+		if err := assign(t, ride, driverID); !errors.Is(err, domain.ErrDriverNotAvailable) {
+			t.Fatalf("AssignDriverToRide() error = %v, want ErrDriverNotAvailable", err)
+		}
+		if _, currentRide := driverState(t, pool, driverID); currentRide == nil || *currentRide != other {
+			t.Errorf("driver's current ride = %v, want the ride they were on", currentRide)
+		}
+		unassigned(t, ride.ID)
+	})
+
+	t.Run("stale ride", func(t *testing.T) {
+		// The ride changed since it was read, so the driver update made in
+		// the same transaction is rolled back
+		ride := createTestRide(t, repo)
+		driverID := createTestDriver(t, pool, domain.DriverStatusOnline, nil)
+		concurrent, err := repo.GetByID(ctx, ride.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		concurrent.Metadata["rider_note"] = "at the gate"
+		if err := repo.Update(ctx, concurrent); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := assign(t, ride, driverID); !errors.Is(err, domain.ErrRideConflict) {
+			t.Fatalf("AssignDriverToRide() error = %v, want ErrRideConflict", err)
+		}
+		status, currentRide := driverState(t, pool, driverID)
+		if status != domain.DriverStatusOnline || currentRide != nil {
+			t.Errorf("driver = %s on %v, want still ONLINE without a ride", status, currentRide)
+		}
+		unassigned(t, ride.ID)
+	})
+}
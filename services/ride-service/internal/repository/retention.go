@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// GetRidesAwaitingNudge gets on-demand rides still searching for a driver
+// since before the given time that have not been scored, excluding riders
+// who opted out of nudges, longest wait first
+func (r *RideRepository) GetRidesAwaitingNudge(ctx context.Context, requestedBefore time.Time, limit int) ([]*domain.Ride, error) {
+	query := `
+		SELECT
+			id, rider_id, driver_id, vehicle_id,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
+			type, status, payment_method,
+			route, price,
+			scheduled_for, requested_at, accepted_at, arrived_at,
+			started_at, completed_at, cancelled_at,
+			cancellation_reason, cancelled_by,
+			rider_rating, driver_rating,
+			promo_code, metadata,
+			created_at, updated_at, version
+		FROM rides r
+		WHERE status = 'SEARCHING'
+			AND scheduled_for IS NULL
+			AND requested_at <= $1
+			AND NOT EXISTS (SELECT 1 FROM retention_nudges n WHERE n.ride_id = r.id)
+			AND NOT EXISTS (
+				SELECT 1 FROM rider_nudge_preferences p
+				WHERE p.rider_id = r.rider_id AND p.opted_out
+			)
+		ORDER BY requested_at ASC
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, requestedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rides := make([]*domain.Ride, 0)
+	for rows.Next() {
+		ride, err := r.scanRideFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		rides = append(rides, ride)
+	}
+
+	return rides, rows.Err()
+}
+
+// GetRiderCancellationHistory counts a rider's ride requests in [since,
+// before) and how many of those they cancelled themselves
+func (r *RideRepository) GetRiderCancellationHistory(ctx context.Context, riderID uuid.UUID, since, before time.Time) (int, int, error) {
+	var rides, cancels int
+	err := r.pool.QueryRow(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'CANCELLED' AND cancelled_by = rider_id)
+		FROM rides
+		WHERE rider_id = $1 AND requested_at >= $2 AND requested_at < $3`,
+		riderID, since, before,
+	).Scan(&rides, &cancels)
+	return rides, cancels, err
+}
+
+// CreateRetentionNudge records a scored ride. It returns false when the ride
+// was already scored.
+func (r *RideRepository) CreateRetentionNudge(ctx context.Context, nudge *domain.RetentionNudge) (bool, error) {
+	featuresJSON, err := json.Marshal(nudge.Features)
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO retention_nudges (
+			id, ride_id, rider_id, model_version, probability, features, wait_seconds,
+			mitigation, holdout, credit_code, credit_amount, currency, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11, NULLIF($12, ''), $13)
+		ON CONFLICT (ride_id) DO NOTHING`,
+		nudge.ID, nudge.RideID, nudge.RiderID, nudge.ModelVersion, nudge.Probability, featuresJSON, nudge.WaitSeconds,
+		nudge.Mitigation, nudge.Holdout, nudge.CreditCode, nudge.CreditAmount, string(nudge.Currency), nudge.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ResolveRetentionNudges records the outcome of scored rides that have been
+// matched, cancelled or expired since
+func (r *RideRepository) ResolveRetentionNudges(ctx context.Context) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE retention_nudges n SET
+			outcome = CASE WHEN r.status IN ('CANCELLED', 'EXPIRED') THEN r.status ELSE 'MATCHED' END,
+			resolved_at = NOW()
+		FROM rides r
+		WHERE r.id = n.ride_id
+			AND n.resolved_at IS NULL
+			AND r.status NOT IN ('PENDING', 'SEARCHING')`,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetRetentionStats measures each mitigation arm for rides scored in
+// [from, to)
+func (r *RideRepository) GetRetentionStats(ctx context.Context, from, to time.Time) ([]*domain.RetentionStat, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT mitigation, holdout, COUNT(*),
+			COUNT(*) FILTER (WHERE resolved_at IS NOT NULL),
+			COUNT(*) FILTER (WHERE outcome = 'CANCELLED'),
+			AVG(probability)
+		FROM retention_nudges
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY mitigation, holdout
+		ORDER BY mitigation, holdout`,
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]*domain.RetentionStat, 0)
+	for rows.Next() {
+		var stat domain.RetentionStat
+		if err := rows.Scan(
+			&stat.Mitigation, &stat.Holdout, &stat.Nudges,
+			&stat.Resolved, &stat.Cancelled, &stat.AvgProbability,
+		); err != nil {
+			return nil, err
+		}
+		if stat.Resolved > 0 {
+			stat.CancelRate = float64(stat.Cancelled) / float64(stat.Resolved)
+		}
+		stats = append(stats, &stat)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetNudgePreferences gets a rider's nudge settings, defaulting to opted in
+func (r *RideRepository) GetNudgePreferences(ctx context.Context, riderID uuid.UUID) (*domain.NudgePreferences, error) {
+	prefs := &domain.NudgePreferences{RiderID: riderID}
+	err := r.pool.QueryRow(ctx, `
+		SELECT opted_out, updated_at FROM rider_nudge_preferences WHERE rider_id = $1`,
+		riderID,
+	).Scan(&prefs.OptedOut, &prefs.UpdatedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// SaveNudgePreferences stores a rider's nudge settings
+func (r *RideRepository) SaveNudgePreferences(ctx context.Context, prefs *domain.NudgePreferences) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO rider_nudge_preferences (rider_id, opted_out, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (rider_id) DO UPDATE SET
+			opted_out = EXCLUDED.opted_out,
+			updated_at = EXCLUDED.updated_at`,
+		prefs.RiderID, prefs.OptedOut, prefs.UpdatedAt,
+	)
+	return err
+}
+
+// CreateRetentionTables creates the retention nudge tables (for testing/migrations)
+func (r *RideRepository) CreateRetentionTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS retention_nudges (
+			id UUID PRIMARY KEY,
+			ride_id UUID NOT NULL UNIQUE REFERENCES rides(id),
+			rider_id UUID NOT NULL,
+			model_version VARCHAR(50) NOT NULL,
+			probability DOUBLE PRECISION NOT NULL,
+			features JSONB NOT NULL,
+			wait_seconds BIGINT NOT NULL,
+			mitigation VARCHAR(20) NOT NULL,
+			holdout BOOLEAN NOT NULL,
+			credit_code VARCHAR(50),
+			credit_amount BIGINT NOT NULL DEFAULT 0,
+			currency VARCHAR(3),
+			outcome VARCHAR(20),
+			created_at TIMESTAMPTZ NOT NULL,
+			resolved_at TIMESTAMPTZ
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_retention_nudges_created_at ON retention_nudges(created_at);
+		CREATE INDEX IF NOT EXISTS idx_retention_nudges_unresolved ON retention_nudges(ride_id) WHERE resolved_at IS NULL;
+
+		CREATE TABLE IF NOT EXISTS rider_nudge_preferences (
+			rider_id UUID PRIMARY KEY,
+			opted_out BOOLEAN NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// GetDemandZones gets the pickup zones with the most ride requests since a
+// time, with their mean pickup point. Supply is left for the caller.
+func (r *RideRepository) GetDemandZones(ctx context.Context, since time.Time, limit int) ([]*domain.DemandZone, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT
+			pickup_location->>'h3_cell' AS zone,
+			AVG((pickup_location->>'latitude')::DOUBLE PRECISION),
+			AVG((pickup_location->>'longitude')::DOUBLE PRECISION),
+			COUNT(*) AS requests
+		FROM rides
+		WHERE requested_at >= $1
+			AND COALESCE(pickup_location->>'h3_cell', '') != ''
+		GROUP BY zone
+		ORDER BY requests DESC
+		LIMIT $2`,
+		since, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	zones := make([]*domain.DemandZone, 0)
+	for rows.Next() {
+		var z domain.DemandZone
+		if err := rows.Scan(&z.Zone, &z.Latitude, &z.Longitude, &z.Requests); err != nil {
+			return nil, err
+		}
+		zones = append(zones, &z)
+	}
+
+	return zones, rows.Err()
+}
+
+// GetSearchingRidesInBounds gets rides still searching for a driver whose
+// pickup lies in a bounding box, oldest first
+func (r *RideRepository) GetSearchingRidesInBounds(ctx context.Context, minLat, minLng, maxLat, maxLng float64, limit int) ([]*domain.Ride, error) {
+	query := `
+		SELECT
+			id, rider_id, driver_id, vehicle_id,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
+			type, status, payment_method,
+			route, price,
+			scheduled_for, requested_at, accepted_at, arrived_at,
+			started_at, completed_at, cancelled_at,
+			cancellation_reason, cancelled_by,
+			rider_rating, driver_rating,
+			promo_code, metadata,
+			created_at, updated_at, version
+		FROM rides
+		WHERE status = 'SEARCHING'
+			AND (pickup_location->>'latitude')::DOUBLE PRECISION BETWEEN $1 AND $3
+			AND (pickup_location->>'longitude')::DOUBLE PRECISION BETWEEN $2 AND $4
+		ORDER BY requested_at
+		LIMIT $5`
+
+	rows, err := r.pool.Query(ctx, query, minLat, minLng, maxLat, maxLng, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rides []*domain.Ride
+	for rows.Next() {
+		ride, err := r.scanRideFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		rides = append(rides, ride)
+	}
+
+	return rides, rows.Err()
+}
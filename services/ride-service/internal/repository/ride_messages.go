@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// RideMessageRepository archives ride chats once their rides end. Live
+// chats are kept in Redis.
+type RideMessageRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRideMessageRepository creates a new ride message repository
+func NewRideMessageRepository(pool *pgxpool.Pool) *RideMessageRepository {
+	return &RideMessageRepository{pool: pool}
+}
+
+// Archive stores a ride's messages. Messages already archived are skipped,
+// so a chat can be archived again.
+func (r *RideMessageRepository) Archive(ctx context.Context, messages []*domain.RideMessage) error {
+	batch := &pgx.Batch{}
+	for _, m := range messages {
+		batch.Queue(`
+			INSERT INTO ride_messages (ride_id, message_id, sender_id, sender, body, sent_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (ride_id, message_id) DO NOTHING`,
+			m.RideID, m.ID, m.SenderID, m.Sender, m.Body, m.SentAt,
+		)
+	}
+
+	return r.pool.SendBatch(ctx, batch).Close()
+}
+
+// ListByRide lists a ride's archived messages in the order they were sent
+func (r *RideMessageRepository) ListByRide(ctx context.Context, rideID uuid.UUID) ([]*domain.RideMessage, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT message_id, sender_id, sender, body, sent_at
+		FROM ride_messages
+		WHERE ride_id = $1
+		ORDER BY sent_at, message_id`,
+		rideID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]*domain.RideMessage, 0)
+	for rows.Next() {
+		m := &domain.RideMessage{RideID: rideID}
+		if err := rows.Scan(&m.ID, &m.SenderID, &m.Sender, &m.Body, &m.SentAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, rows.Err()
+}
+
+// CreateRideMessageTables creates the ride message archive table (for testing/migrations)
+func (r *RideMessageRepository) CreateRideMessageTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS ride_messages (
+			ride_id UUID NOT NULL REFERENCES rides(id),
+			message_id VARCHAR(40) NOT NULL,
+			sender_id UUID NOT NULL,
+			sender VARCHAR(20) NOT NULL,
+			body TEXT NOT NULL,
+			sent_at TIMESTAMPTZ NOT NULL,
+			archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (ride_id, message_id)
+		);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// RideHourAggregate is the hourly ride counters for one pickup cell and
+// currency, with a representative pickup point for resolving its market
+type RideHourAggregate struct {
+	domain.RideHourlyMetrics
+	Latitude  float64
+	Longitude float64
+}
+
+// AggregateRideHours aggregates ride requests, completions and
+// cancellations that happened in [from, to) by hour, pickup cell (or a
+// coarse grid square for rides without one) and currency
+func (r *RideRepository) AggregateRideHours(ctx context.Context, from, to time.Time) ([]*RideHourAggregate, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT
+			date_trunc('hour', e.at) AS hour,
+			COALESCE(
+				NULLIF(r.pickup_location->>'h3_cell', ''),
+				round((r.pickup_location->>'latitude')::NUMERIC, 1) || ',' || round((r.pickup_location->>'longitude')::NUMERIC, 1)
+			) AS cell,
+			COALESCE(r.price->>'currency', '') AS currency,
+			COUNT(*) FILTER (WHERE e.kind = 'requested'),
+			COUNT(*) FILTER (WHERE e.kind = 'completed'),
+			COUNT(*) FILTER (WHERE e.kind = 'cancelled'),
+			COALESCE(SUM((r.price->>'total')::BIGINT) FILTER (WHERE e.kind = 'completed'), 0)::BIGINT,
+			COUNT(*) FILTER (WHERE e.kind = 'completed' AND r.price ? 'total'),
+			COALESCE(SUM(extract(epoch FROM r.completed_at - r.started_at)) FILTER (WHERE e.kind = 'completed' AND r.started_at IS NOT NULL), 0)::BIGINT,
+			COUNT(*) FILTER (WHERE e.kind = 'completed' AND r.started_at IS NOT NULL),
+			AVG((r.pickup_location->>'latitude')::DOUBLE PRECISION),
+			AVG((r.pickup_location->>'longitude')::DOUBLE PRECISION)
+		FROM rides r
+		CROSS JOIN LATERAL (VALUES
+			('requested', r.requested_at),
+			('completed', CASE WHEN r.status = 'COMPLETED' THEN r.completed_at END),
+			('cancelled', CASE WHEN r.status = 'CANCELLED' THEN r.cancelled_at END)
+		) AS e(kind, at)
+		WHERE (r.requested_at >= $1 AND r.requested_at < $2
+				OR r.status = 'COMPLETED' AND r.completed_at >= $1 AND r.completed_at < $2
+				OR r.status = 'CANCELLED' AND r.cancelled_at >= $1 AND r.cancelled_at < $2)
+			AND e.at >= $1 AND e.at < $2
+		GROUP BY hour, cell, currency`,
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aggregates := make([]*RideHourAggregate, 0)
+	for rows.Next() {
+		var a RideHourAggregate
+		var cell string
+		if err := rows.Scan(
+			&a.Hour, &cell, &a.Currency,
+			&a.Requested, &a.Completed, &a.Cancelled,
+			&a.Revenue, &a.FareCount,
+			&a.DurationSumSeconds, &a.DurationCount,
+			&a.Latitude, &a.Longitude,
+		); err != nil {
+			return nil, err
+		}
+		aggregates = append(aggregates, &a)
+	}
+
+	return aggregates, rows.Err()
+}
+
+// ReplaceRideHourlyMetrics replaces the rollups for hours in [from, to)
+func (r *RideRepository) ReplaceRideHourlyMetrics(ctx context.Context, from, to time.Time, metrics []*domain.RideHourlyMetrics) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM ride_hourly_metrics WHERE hour >= $1 AND hour < $2`,
+		from, to,
+	); err != nil {
+		return err
+	}
+
+	for _, m := range metrics {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO ride_hourly_metrics (
+				hour, market, currency, requested, completed, cancelled,
+				revenue, fare_count, duration_sum_seconds, duration_count
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			m.Hour, m.Market, m.Currency, m.Requested, m.Completed, m.Cancelled,
+			m.Revenue, m.FareCount, m.DurationSumSeconds, m.DurationCount,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetRideMetricsWatermark gets the latest rolled-up hour, or nil when
+// nothing has been rolled up yet
+func (r *RideRepository) GetRideMetricsWatermark(ctx context.Context) (*time.Time, error) {
+	var hour *time.Time
+	err := r.pool.QueryRow(ctx, `SELECT MAX(hour) FROM ride_hourly_metrics`).Scan(&hour)
+	return hour, err
+}
+
+// GetRideHourlyMetrics gets the rollups for hours in [from, to), optionally
+// for one market, oldest first
+func (r *RideRepository) GetRideHourlyMetrics(ctx context.Context, from, to time.Time, market string) ([]*domain.RideHourlyMetrics, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT
+			hour, market, currency, requested, completed, cancelled,
+			revenue, fare_count, duration_sum_seconds, duration_count
+		FROM ride_hourly_metrics
+		WHERE hour >= $1 AND hour < $2
+			AND ($3 = '' OR market = $3)
+		ORDER BY hour, market, currency`,
+		from, to, market,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	metrics := make([]*domain.RideHourlyMetrics, 0)
+	for rows.Next() {
+		var m domain.RideHourlyMetrics
+		if err := rows.Scan(
+			&m.Hour, &m.Market, &m.Currency, &m.Requested, &m.Completed, &m.Cancelled,
+			&m.Revenue, &m.FareCount, &m.DurationSumSeconds, &m.DurationCount,
+		); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, &m)
+	}
+
+	return metrics, rows.Err()
+}
+
+// CreateRideMetricsTables creates the hourly ride metrics table (for testing/migrations)
+func (r *RideRepository) CreateRideMetricsTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS ride_hourly_metrics (
+			hour TIMESTAMPTZ NOT NULL,
+			market VARCHAR(50) NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			requested BIGINT NOT NULL DEFAULT 0,
+			completed BIGINT NOT NULL DEFAULT 0,
+			cancelled BIGINT NOT NULL DEFAULT 0,
+			revenue BIGINT NOT NULL DEFAULT 0,
+			fare_count BIGINT NOT NULL DEFAULT 0,
+			duration_sum_seconds BIGINT NOT NULL DEFAULT 0,
+			duration_count BIGINT NOT NULL DEFAULT 0,
+			PRIMARY KEY (hour, market, currency)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_rides_requested_at ON rides(requested_at);
+		CREATE INDEX IF NOT EXISTS idx_rides_cancelled_at ON rides(cancelled_at) WHERE status = 'CANCELLED';
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
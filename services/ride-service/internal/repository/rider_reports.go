@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// RiderReportRepository handles driver reports about riders and the rider
+// reliability record built from them
+type RiderReportRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRiderReportRepository creates a new rider report repository
+func NewRiderReportRepository(pool *pgxpool.Pool) *RiderReportRepository {
+	return &RiderReportRepository{pool: pool}
+}
+
+const riderReportColumns = `
+	id, ride_id, rider_id, driver_id, type, note, voided_by, voided_at, void_note, created_at`
+
+// Create inserts a new report, returning ErrRiderReportExists if the ride
+// already has one of the same type
+func (r *RiderReportRepository) Create(ctx context.Context, report *domain.RiderReport) error {
+	query := `
+		INSERT INTO rider_reports (` + riderReportColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := r.pool.Exec(ctx, query,
+		report.ID, report.RideID, report.RiderID, report.DriverID, report.Type, report.Note,
+		report.VoidedBy, report.VoidedAt, report.VoidNote, report.CreatedAt,
+	)
+	if err != nil && isUniqueViolation(err) {
+		return domain.ErrRiderReportExists
+	}
+	return err
+}
+
+// GetByID retrieves a report by ID
+func (r *RiderReportRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.RiderReport, error) {
+	query := `SELECT ` + riderReportColumns + ` FROM rider_reports WHERE id = $1`
+	return r.scanReport(r.pool.QueryRow(ctx, query, id))
+}
+
+// ListByRider lists the reports about a rider since a time, newest first,
+// voided ones included
+func (r *RiderReportRepository) ListByRider(ctx context.Context, riderID uuid.UUID, since time.Time) ([]*domain.RiderReport, error) {
+	query := `
+		SELECT ` + riderReportColumns + `
+		FROM rider_reports
+		WHERE rider_id = $1 AND created_at >= $2
+		ORDER BY created_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, riderID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := make([]*domain.RiderReport, 0)
+	for rows.Next() {
+		report, err := r.scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+// Void voids a report, returning ErrRiderReportVoided if it already was
+func (r *RiderReportRepository) Void(ctx context.Context, report *domain.RiderReport) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE rider_reports SET
+			voided_by = $2,
+			voided_at = $3,
+			void_note = $4
+		WHERE id = $1 AND voided_at IS NULL`,
+		report.ID, report.VoidedBy, report.VoidedAt, report.VoidNote,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrRiderReportVoided
+	}
+	return nil
+}
+
+// GetBehaviour gathers a rider's record since a time: the rides drivers
+// accepted, those the rider cancelled, and the reports about them that
+// still stand
+func (r *RiderReportRepository) GetBehaviour(ctx context.Context, riderID uuid.UUID, since time.Time) (*domain.RiderBehaviour, error) {
+	var b domain.RiderBehaviour
+
+	err := r.pool.QueryRow(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'CANCELLED' AND cancelled_by = rider_id)
+		FROM rides
+		WHERE rider_id = $1 AND accepted_at IS NOT NULL AND accepted_at >= $2`,
+		riderID, since,
+	).Scan(&b.AcceptedRides, &b.LateCancellations)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.pool.QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE type = 'NO_SHOW'),
+			COUNT(*) FILTER (WHERE type = 'RUDE_CONDUCT'),
+			COUNT(*) FILTER (WHERE type = 'CASH_REFUSED')
+		FROM rider_reports
+		WHERE rider_id = $1 AND created_at >= $2 AND voided_at IS NULL`,
+		riderID, since,
+	).Scan(&b.NoShows, &b.RudeConduct, &b.CashRefusals)
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+func (r *RiderReportRepository) scanReport(row pgx.Row) (*domain.RiderReport, error) {
+	var report domain.RiderReport
+	var note, voidNote sql.NullString
+
+	err := row.Scan(
+		&report.ID, &report.RideID, &report.RiderID, &report.DriverID, &report.Type, &note,
+		&report.VoidedBy, &report.VoidedAt, &voidNote, &report.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrRiderReportNotFound
+		}
+		return nil, err
+	}
+
+	report.Note = note.String
+	report.VoidNote = voidNote.String
+	return &report, nil
+}
+
+// CreateRiderReportTables creates the rider report table (for testing/migrations)
+func (r *RiderReportRepository) CreateRiderReportTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS rider_reports (
+			id UUID PRIMARY KEY,
+			ride_id UUID NOT NULL REFERENCES rides(id),
+			rider_id UUID NOT NULL,
+			driver_id UUID NOT NULL,
+			type VARCHAR(20) NOT NULL,
+			note TEXT,
+			voided_by UUID,
+			voided_at TIMESTAMPTZ,
+			void_note TEXT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			UNIQUE (ride_id, type)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_rider_reports_rider ON rider_reports(rider_id, created_at);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
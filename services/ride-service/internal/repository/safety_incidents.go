@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// SafetyIncidentRepository handles SOS incident data access
+type SafetyIncidentRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSafetyIncidentRepository creates a new safety incident repository
+func NewSafetyIncidentRepository(pool *pgxpool.Pool) *SafetyIncidentRepository {
+	return &SafetyIncidentRepository{pool: pool}
+}
+
+const safetyIncidentColumns = `
+	id, ride_id, raised_by, raised_by_role, status, location, note, snapshot,
+	acknowledged_by, acknowledged_at, resolved_by, resolved_at, resolution, created_at, updated_at`
+
+// Create inserts a new incident, returning ErrSafetyIncidentOpen if the
+// ride already has one unresolved
+func (r *SafetyIncidentRepository) Create(ctx context.Context, incident *domain.SafetyIncident) error {
+	locationJSON, _ := json.Marshal(incident.Location)
+	snapshotJSON, _ := json.Marshal(incident.Snapshot)
+
+	query := `
+		INSERT INTO safety_incidents (` + safetyIncidentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+	_, err := r.pool.Exec(ctx, query,
+		incident.ID, incident.RideID, incident.RaisedBy, incident.RaisedByRole, incident.Status,
+		locationJSON, incident.Note, snapshotJSON,
+		incident.AcknowledgedBy, incident.AcknowledgedAt, incident.ResolvedBy, incident.ResolvedAt,
+		incident.Resolution, incident.CreatedAt, incident.UpdatedAt,
+	)
+	if err != nil && isUniqueViolation(err) {
+		return domain.ErrSafetyIncidentOpen
+	}
+	return err
+}
+
+// GetByID retrieves an incident by ID
+func (r *SafetyIncidentRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.SafetyIncident, error) {
+	query := `SELECT ` + safetyIncidentColumns + ` FROM safety_incidents WHERE id = $1`
+	return r.scanIncident(r.pool.QueryRow(ctx, query, id))
+}
+
+// GetUnresolvedByRide retrieves a ride's unresolved incident
+func (r *SafetyIncidentRepository) GetUnresolvedByRide(ctx context.Context, rideID uuid.UUID) (*domain.SafetyIncident, error) {
+	query := `SELECT ` + safetyIncidentColumns + ` FROM safety_incidents WHERE ride_id = $1 AND status <> 'RESOLVED'`
+	return r.scanIncident(r.pool.QueryRow(ctx, query, rideID))
+}
+
+// GetLatestByRide retrieves a ride's most recent incident, resolved or not
+func (r *SafetyIncidentRepository) GetLatestByRide(ctx context.Context, rideID uuid.UUID) (*domain.SafetyIncident, error) {
+	query := `SELECT ` + safetyIncidentColumns + ` FROM safety_incidents WHERE ride_id = $1 ORDER BY created_at DESC LIMIT 1`
+	return r.scanIncident(r.pool.QueryRow(ctx, query, rideID))
+}
+
+// ListByStatus lists incidents in a status, longest waiting first
+func (r *SafetyIncidentRepository) ListByStatus(ctx context.Context, status domain.SafetyIncidentStatus, limit, offset int) ([]*domain.SafetyIncident, int64, error) {
+	var total int64
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM safety_incidents WHERE status = $1`, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + safetyIncidentColumns + `
+		FROM safety_incidents
+		WHERE status = $1
+		ORDER BY created_at ASC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.pool.Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	incidents := make([]*domain.SafetyIncident, 0)
+	for rows.Next() {
+		incident, err := r.scanIncident(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		incidents = append(incidents, incident)
+	}
+
+	return incidents, total, rows.Err()
+}
+
+// UpdateLocation records where the SOS was last pressed from
+func (r *SafetyIncidentRepository) UpdateLocation(ctx context.Context, id uuid.UUID, location *domain.Location) error {
+	locationJSON, _ := json.Marshal(location)
+	_, err := r.pool.Exec(ctx,
+		`UPDATE safety_incidents SET location = $2, updated_at = $3 WHERE id = $1`,
+		id, locationJSON, time.Now().UTC(),
+	)
+	return err
+}
+
+// UpdateStatus moves an incident on from the status it was read in,
+// returning ErrInvalidSafetyTransition if another agent moved it first
+func (r *SafetyIncidentRepository) UpdateStatus(ctx context.Context, incident *domain.SafetyIncident, from domain.SafetyIncidentStatus) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE safety_incidents SET
+			status = $2,
+			acknowledged_by = $3,
+			acknowledged_at = $4,
+			resolved_by = $5,
+			resolved_at = $6,
+			resolution = $7,
+			updated_at = $8
+		WHERE id = $1 AND status = $9`,
+		incident.ID, incident.Status, incident.AcknowledgedBy, incident.AcknowledgedAt,
+		incident.ResolvedBy, incident.ResolvedAt, incident.Resolution, incident.UpdatedAt, from,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrInvalidSafetyTransition
+	}
+	return nil
+}
+
+func (r *SafetyIncidentRepository) scanIncident(row pgx.Row) (*domain.SafetyIncident, error) {
+	var incident domain.SafetyIncident
+	var note, resolution sql.NullString
+	var locationJSON, snapshotJSON []byte
+
+	err := row.Scan(
+		&incident.ID, &incident.RideID, &incident.RaisedBy, &incident.RaisedByRole, &incident.Status,
+		&locationJSON, &note, &snapshotJSON,
+		&incident.AcknowledgedBy, &incident.AcknowledgedAt, &incident.ResolvedBy, &incident.ResolvedAt,
+		&resolution, &incident.CreatedAt, &incident.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSafetyIncidentNotFound
+		}
+		return nil, err
+	}
+
+	incident.Note = note.String
+	incident.Resolution = resolution.String
+	if len(locationJSON) > 0 {
+		json.Unmarshal(locationJSON, &incident.Location)
+	}
+	if len(snapshotJSON) > 0 {
+		json.Unmarshal(snapshotJSON, &incident.Snapshot)
+	}
+
+	return &incident, nil
+}
+
+// CreateSafetyIncidentTables creates the safety incident table (for testing/migrations)
+func (r *SafetyIncidentRepository) CreateSafetyIncidentTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS safety_incidents (
+			id UUID PRIMARY KEY,
+			ride_id UUID NOT NULL REFERENCES rides(id),
+			raised_by UUID NOT NULL,
+			raised_by_role VARCHAR(20) NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			location JSONB,
+			note TEXT,
+			snapshot JSONB NOT NULL,
+			acknowledged_by UUID,
+			acknowledged_at TIMESTAMPTZ,
+			resolved_by UUID,
+			resolved_at TIMESTAMPTZ,
+			resolution TEXT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		-- Pressing SOS again adds to the ride's unresolved incident
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_safety_incidents_unresolved ON safety_incidents(ride_id) WHERE status <> 'RESOLVED';
+		CREATE INDEX IF NOT EXISTS idx_safety_incidents_queue ON safety_incidents(status, created_at);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
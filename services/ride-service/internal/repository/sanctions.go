@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// SanctionRepository handles driver sanction and appeal data access
+type SanctionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSanctionRepository creates a new sanction repository
+func NewSanctionRepository(pool *pgxpool.Pool) *SanctionRepository {
+	return &SanctionRepository{pool: pool}
+}
+
+const sanctionColumns = `
+	id, driver_id, status, reason, note, issued_by, starts_at, ends_at,
+	lifted_at, lifted_by, lift_note, created_at`
+
+const appealColumns = `
+	id, sanction_id, driver_id, statement, status, reviewed_by, review_note, created_at, reviewed_at`
+
+// Create records a sanction and applies its status to the driver atomically.
+// Any sanction already in force is superseded.
+func (r *SanctionRepository) Create(ctx context.Context, sanction *domain.DriverSanction) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE driver_sanctions SET lifted_at = $2, lifted_by = $3, lift_note = 'Superseded'
+		WHERE driver_id = $1 AND lifted_at IS NULL`,
+		sanction.DriverID, sanction.StartsAt, sanction.IssuedBy,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO driver_sanctions (`+sanctionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		sanction.ID, sanction.DriverID, sanction.Status, sanction.Reason, sanction.Note,
+		sanction.IssuedBy, sanction.StartsAt, sanction.EndsAt,
+		sanction.LiftedAt, sanction.LiftedBy, sanction.LiftNote, sanction.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE drivers SET status = $2, online_since = NULL, updated_at = $3
+		WHERE id = $1`,
+		sanction.DriverID, sanction.Status, sanction.StartsAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetActive gets the sanction currently in force for a driver
+func (r *SanctionRepository) GetActive(ctx context.Context, driverID uuid.UUID) (*domain.DriverSanction, error) {
+	query := `
+		SELECT ` + sanctionColumns + `
+		FROM driver_sanctions
+		WHERE driver_id = $1 AND lifted_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	sanction, err := r.scanSanction(r.pool.QueryRow(ctx, query, driverID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrDriverNotRestricted
+	}
+	return sanction, err
+}
+
+// GetByID gets a sanction by ID
+func (r *SanctionRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.DriverSanction, error) {
+	query := `SELECT ` + sanctionColumns + ` FROM driver_sanctions WHERE id = $1`
+
+	sanction, err := r.scanSanction(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrDriverNotRestricted
+	}
+	return sanction, err
+}
+
+// ListByDriver lists a driver's sanction history, newest first
+func (r *SanctionRepository) ListByDriver(ctx context.Context, driverID uuid.UUID) ([]*domain.DriverSanction, error) {
+	query := `SELECT ` + sanctionColumns + ` FROM driver_sanctions WHERE driver_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, driverID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sanctions := make([]*domain.DriverSanction, 0)
+	for rows.Next() {
+		sanction, err := r.scanSanction(rows)
+		if err != nil {
+			return nil, err
+		}
+		sanctions = append(sanctions, sanction)
+	}
+
+	return sanctions, rows.Err()
+}
+
+// ListExpired lists time-boxed sanctions whose end has passed but which have
+// not yet been lifted
+func (r *SanctionRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*domain.DriverSanction, error) {
+	query := `
+		SELECT ` + sanctionColumns + `
+		FROM driver_sanctions
+		WHERE lifted_at IS NULL AND ends_at IS NOT NULL AND ends_at <= $1
+		ORDER BY ends_at ASC
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sanctions := make([]*domain.DriverSanction, 0)
+	for rows.Next() {
+		sanction, err := r.scanSanction(rows)
+		if err != nil {
+			return nil, err
+		}
+		sanctions = append(sanctions, sanction)
+	}
+
+	return sanctions, rows.Err()
+}
+
+// Lift ends a sanction and returns the driver to OFFLINE atomically.
+// A nil liftedBy records an automatic reinstatement.
+func (r *SanctionRepository) Lift(ctx context.Context, sanctionID uuid.UUID, liftedBy *uuid.UUID, note string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now().UTC()
+
+	var driverID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE driver_sanctions SET lifted_at = $2, lifted_by = $3, lift_note = $4
+		WHERE id = $1 AND lifted_at IS NULL
+		RETURNING driver_id`,
+		sanctionID, now, liftedBy, note,
+	).Scan(&driverID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrDriverNotRestricted
+		}
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE drivers SET status = 'OFFLINE', updated_at = $2
+		WHERE id = $1 AND status IN ('SUSPENDED', 'DEACTIVATED', 'UNDER_REVIEW')`,
+		driverID, now,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CreateAppeal inserts a new appeal
+func (r *SanctionRepository) CreateAppeal(ctx context.Context, appeal *domain.DriverAppeal) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO driver_appeals (`+appealColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		appeal.ID, appeal.SanctionID, appeal.DriverID, appeal.Statement, appeal.Status,
+		appeal.ReviewedBy, appeal.ReviewNote, appeal.CreatedAt, appeal.ReviewedAt,
+	)
+	if err != nil && isUniqueViolation(err) {
+		return domain.ErrAppealAlreadyPending
+	}
+	return err
+}
+
+// GetAppeal gets an appeal by ID
+func (r *SanctionRepository) GetAppeal(ctx context.Context, id uuid.UUID) (*domain.DriverAppeal, error) {
+	query := `SELECT ` + appealColumns + ` FROM driver_appeals WHERE id = $1`
+	return r.scanAppeal(r.pool.QueryRow(ctx, query, id))
+}
+
+// GetPendingAppeal gets the pending appeal against a sanction, if any
+func (r *SanctionRepository) GetPendingAppeal(ctx context.Context, sanctionID uuid.UUID) (*domain.DriverAppeal, error) {
+	query := `SELECT ` + appealColumns + ` FROM driver_appeals WHERE sanction_id = $1 AND status = 'PENDING'`
+	return r.scanAppeal(r.pool.QueryRow(ctx, query, sanctionID))
+}
+
+// ListAppeals lists appeals in a status, oldest first
+func (r *SanctionRepository) ListAppeals(ctx context.Context, status domain.AppealStatus, limit, offset int) ([]*domain.DriverAppeal, error) {
+	query := `
+		SELECT ` + appealColumns + `
+		FROM driver_appeals
+		WHERE status = $1
+		ORDER BY created_at ASC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.pool.Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	appeals := make([]*domain.DriverAppeal, 0)
+	for rows.Next() {
+		appeal, err := r.scanAppeal(rows)
+		if err != nil {
+			return nil, err
+		}
+		appeals = append(appeals, appeal)
+	}
+
+	return appeals, rows.Err()
+}
+
+// ReviewAppeal records the decision on a pending appeal
+func (r *SanctionRepository) ReviewAppeal(ctx context.Context, appeal *domain.DriverAppeal) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE driver_appeals SET status = $2, reviewed_by = $3, review_note = $4, reviewed_at = $5
+		WHERE id = $1 AND status = 'PENDING'`,
+		appeal.ID, appeal.Status, appeal.ReviewedBy, appeal.ReviewNote, appeal.ReviewedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrAppealNotPending
+	}
+	return nil
+}
+
+func (r *SanctionRepository) scanSanction(row pgx.Row) (*domain.DriverSanction, error) {
+	var sanction domain.DriverSanction
+	var note, liftNote sql.NullString
+
+	err := row.Scan(
+		&sanction.ID, &sanction.DriverID, &sanction.Status, &sanction.Reason, &note,
+		&sanction.IssuedBy, &sanction.StartsAt, &sanction.EndsAt,
+		&sanction.LiftedAt, &sanction.LiftedBy, &liftNote, &sanction.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sanction.Note = note.String
+	sanction.LiftNote = liftNote.String
+
+	return &sanction, nil
+}
+
+func (r *SanctionRepository) scanAppeal(row pgx.Row) (*domain.DriverAppeal, error) {
+	var appeal domain.DriverAppeal
+	var reviewNote sql.NullString
+
+	err := row.Scan(
+		&appeal.ID, &appeal.SanctionID, &appeal.DriverID, &appeal.Statement, &appeal.Status,
+		&appeal.ReviewedBy, &reviewNote, &appeal.CreatedAt, &appeal.ReviewedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAppealNotFound
+		}
+		return nil, err
+	}
+
+	appeal.ReviewNote = reviewNote.String
+
+	return &appeal, nil
+}
+
+// CreateSanctionTables creates the sanction and appeal tables (for testing/migrations)
+func (r *SanctionRepository) CreateSanctionTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS driver_sanctions (
+			id UUID PRIMARY KEY,
+			driver_id UUID NOT NULL,
+			status VARCHAR(30) NOT NULL,
+			reason VARCHAR(50) NOT NULL,
+			note TEXT,
+			issued_by UUID NOT NULL,
+			starts_at TIMESTAMPTZ NOT NULL,
+			ends_at TIMESTAMPTZ,
+			lifted_at TIMESTAMPTZ,
+			lifted_by UUID,
+			lift_note TEXT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_driver_sanctions_driver_id ON driver_sanctions(driver_id, created_at);
+		CREATE INDEX IF NOT EXISTS idx_driver_sanctions_expiry ON driver_sanctions(ends_at) WHERE lifted_at IS NULL AND ends_at IS NOT NULL;
+
+		CREATE TABLE IF NOT EXISTS driver_appeals (
+			id UUID PRIMARY KEY,
+			sanction_id UUID NOT NULL REFERENCES driver_sanctions(id),
+			driver_id UUID NOT NULL,
+			statement TEXT NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			reviewed_by UUID,
+			review_note TEXT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			reviewed_at TIMESTAMPTZ
+		);
+
+		-- At most one pending appeal per sanction
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_driver_appeals_pending ON driver_appeals(sanction_id) WHERE status = 'PENDING';
+		CREATE INDEX IF NOT EXISTS idx_driver_appeals_status ON driver_appeals(status, created_at);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// SavedPlaceRepository handles riders' saved places
+type SavedPlaceRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSavedPlaceRepository creates a new saved place repository
+func NewSavedPlaceRepository(pool *pgxpool.Pool) *SavedPlaceRepository {
+	return &SavedPlaceRepository{pool: pool}
+}
+
+const savedPlaceColumns = `id, rider_id, kind, label, location, cell, created_at, updated_at`
+
+// Create inserts a new saved place, returning ErrSavedPlaceExists if the
+// rider already has a home or work and this is another
+func (r *SavedPlaceRepository) Create(ctx context.Context, place *domain.SavedPlace) error {
+	locationJSON, _ := json.Marshal(place.Location)
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO saved_places (`+savedPlaceColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		place.ID, place.RiderID, place.Kind, place.Label, locationJSON, place.Cell,
+		place.CreatedAt, place.UpdatedAt,
+	)
+	if err != nil && isUniqueViolation(err) {
+		return domain.ErrSavedPlaceExists
+	}
+	return err
+}
+
+// GetByID retrieves one of a rider's saved places
+func (r *SavedPlaceRepository) GetByID(ctx context.Context, riderID, id uuid.UUID) (*domain.SavedPlace, error) {
+	query := `SELECT ` + savedPlaceColumns + ` FROM saved_places WHERE id = $1 AND rider_id = $2`
+	return r.scanPlace(r.pool.QueryRow(ctx, query, id, riderID))
+}
+
+// ListByRider lists a rider's saved places, home and work first
+func (r *SavedPlaceRepository) ListByRider(ctx context.Context, riderID uuid.UUID) ([]*domain.SavedPlace, error) {
+	query := `
+		SELECT ` + savedPlaceColumns + `
+		FROM saved_places
+		WHERE rider_id = $1
+		ORDER BY CASE kind WHEN 'HOME' THEN 0 WHEN 'WORK' THEN 1 ELSE 2 END, created_at`
+
+	rows, err := r.pool.Query(ctx, query, riderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	places := make([]*domain.SavedPlace, 0)
+	for rows.Next() {
+		place, err := r.scanPlace(rows)
+		if err != nil {
+			return nil, err
+		}
+		places = append(places, place)
+	}
+
+	return places, rows.Err()
+}
+
+// CountByRider counts a rider's saved places
+func (r *SavedPlaceRepository) CountByRider(ctx context.Context, riderID uuid.UUID) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM saved_places WHERE rider_id = $1`, riderID).Scan(&count)
+	return count, err
+}
+
+// Update saves a place's kind, label and location
+func (r *SavedPlaceRepository) Update(ctx context.Context, place *domain.SavedPlace) error {
+	locationJSON, _ := json.Marshal(place.Location)
+
+	result, err := r.pool.Exec(ctx, `
+		UPDATE saved_places SET
+			kind = $3,
+			label = $4,
+			location = $5,
+			cell = $6,
+			updated_at = $7
+		WHERE id = $1 AND rider_id = $2`,
+		place.ID, place.RiderID, place.Kind, place.Label, locationJSON, place.Cell, place.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrSavedPlaceExists
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrSavedPlaceNotFound
+	}
+	return nil
+}
+
+// Delete deletes one of a rider's saved places
+func (r *SavedPlaceRepository) Delete(ctx context.Context, riderID, id uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM saved_places WHERE id = $1 AND rider_id = $2`, id, riderID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrSavedPlaceNotFound
+	}
+	return nil
+}
+
+func (r *SavedPlaceRepository) scanPlace(row pgx.Row) (*domain.SavedPlace, error) {
+	var place domain.SavedPlace
+	var locationJSON []byte
+
+	err := row.Scan(
+		&place.ID, &place.RiderID, &place.Kind, &place.Label, &locationJSON, &place.Cell,
+		&place.CreatedAt, &place.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSavedPlaceNotFound
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(locationJSON, &place.Location); err != nil {
+		return nil, err
+	}
+	return &place, nil
+}
+
+// CreateSavedPlaceTables creates the saved place table (for testing/migrations)
+func (r *SavedPlaceRepository) CreateSavedPlaceTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS saved_places (
+			id UUID PRIMARY KEY,
+			rider_id UUID NOT NULL,
+			kind VARCHAR(10) NOT NULL,
+			label VARCHAR(50) NOT NULL DEFAULT '',
+			location JSONB NOT NULL,
+			cell VARCHAR(20) NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_saved_places_rider ON saved_places(rider_id);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_places_home_work
+			ON saved_places(rider_id, kind) WHERE kind IN ('HOME', 'WORK');
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+const scheduledClaimColumns = `
+	c.id, c.ride_id, c.driver_id, c.scheduled_for, c.status,
+	c.penalty, c.release_reason, c.claimed_at, c.closed_at`
+
+// ListClaimableScheduledRides gets unclaimed scheduled rides of the given
+// types picking up in (from, to], soonest first
+func (r *RideRepository) ListClaimableScheduledRides(ctx context.Context, from, to time.Time, rideTypes []string) ([]*domain.ScheduledRideListing, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT
+			r.id, r.type, r.pickup_location, r.dropoff_location, r.scheduled_for,
+			COALESCE((r.route->>'distance_meters')::BIGINT, 0),
+			g.fare_low, g.fare_high, g.currency
+		FROM rides r
+		JOIN scheduled_ride_guarantees g ON g.ride_id = r.id
+		WHERE r.status = 'PENDING'
+			AND r.scheduled_for > $1 AND r.scheduled_for <= $2
+			AND ($3::TEXT[] IS NULL OR r.type = ANY($3))
+			AND NOT EXISTS (
+				SELECT 1 FROM scheduled_ride_claims c
+				WHERE c.ride_id = r.id AND c.status = 'CLAIMED'
+			)
+		ORDER BY r.scheduled_for
+		LIMIT 100`,
+		from, to, rideTypes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	listings := make([]*domain.ScheduledRideListing, 0)
+	for rows.Next() {
+		var l domain.ScheduledRideListing
+		var pickupJSON, dropoffJSON []byte
+		err := rows.Scan(
+			&l.RideID, &l.Type, &pickupJSON, &dropoffJSON, &l.ScheduledFor,
+			&l.DistanceMeters, &l.FareLow, &l.FareHigh, &l.Currency,
+		)
+		if err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal(pickupJSON, &l.PickupLocation)
+		_ = json.Unmarshal(dropoffJSON, &l.DropoffLocation)
+		listings = append(listings, &l)
+	}
+
+	return listings, rows.Err()
+}
+
+// CreateScheduledClaim stores a driver's claim on a scheduled ride, provided
+// the ride is still pending. A ride holds at most one open claim.
+func (r *RideRepository) CreateScheduledClaim(ctx context.Context, claim *domain.ScheduledRideClaim) error {
+	result, err := r.pool.Exec(ctx, `
+		INSERT INTO scheduled_ride_claims (id, ride_id, driver_id, scheduled_for, status, claimed_at)
+		SELECT $1, $2, $3, $4, $5, $6
+		WHERE EXISTS (SELECT 1 FROM rides WHERE id = $2 AND status = 'PENDING')`,
+		claim.ID, claim.RideID, claim.DriverID, claim.ScheduledFor, claim.Status, claim.ClaimedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrScheduledRideClaimed
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrScheduledRideNotClaimable
+	}
+	return nil
+}
+
+// GetActiveScheduledClaim gets a ride's open claim, or nil if it has none
+func (r *RideRepository) GetActiveScheduledClaim(ctx context.Context, rideID uuid.UUID) (*domain.ScheduledRideClaim, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+scheduledClaimColumns+`
+		FROM scheduled_ride_claims c
+		WHERE c.ride_id = $1 AND c.status = 'CLAIMED'`,
+		rideID,
+	)
+	claim, err := scanScheduledClaim(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return claim, err
+}
+
+// ListDriverScheduledClaims gets a driver's open claims, soonest first
+func (r *RideRepository) ListDriverScheduledClaims(ctx context.Context, driverID uuid.UUID) ([]*domain.ScheduledRideClaim, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+scheduledClaimColumns+`
+		FROM scheduled_ride_claims c
+		WHERE c.driver_id = $1 AND c.status = 'CLAIMED'
+		ORDER BY c.scheduled_for`,
+		driverID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	claims := make([]*domain.ScheduledRideClaim, 0)
+	for rows.Next() {
+		claim, err := scanScheduledClaim(rows)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, claim)
+	}
+
+	return claims, rows.Err()
+}
+
+// CloseScheduledClaim moves an open claim to its final status
+func (r *RideRepository) CloseScheduledClaim(ctx context.Context, claim *domain.ScheduledRideClaim) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE scheduled_ride_claims SET
+			status = $2, penalty = $3, release_reason = $4, closed_at = $5
+		WHERE id = $1 AND status = 'CLAIMED'`,
+		claim.ID, claim.Status, claim.Penalty, claim.ReleaseReason, claim.ClosedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrScheduledClaimNotFound
+	}
+	return nil
+}
+
+// GetClaimReliability tallies a driver's claims made since the given time
+// and counts their open claims
+func (r *RideRepository) GetClaimReliability(ctx context.Context, driverID uuid.UUID, since time.Time) (*domain.ClaimReliability, error) {
+	rel := &domain.ClaimReliability{DriverID: driverID}
+	err := r.pool.QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE claimed_at >= $2),
+			COUNT(*) FILTER (WHERE claimed_at >= $2 AND status = 'FULFILLED'),
+			COUNT(*) FILTER (WHERE claimed_at >= $2 AND status = 'RELEASED' AND penalty > 0),
+			COUNT(*) FILTER (WHERE claimed_at >= $2 AND status = 'LAPSED' AND penalty > 0),
+			COALESCE(SUM(penalty) FILTER (WHERE claimed_at >= $2), 0),
+			COUNT(*) FILTER (WHERE status = 'CLAIMED')
+		FROM scheduled_ride_claims
+		WHERE driver_id = $1`,
+		driverID, since,
+	).Scan(&rel.Claims, &rel.Fulfilled, &rel.LateReleases, &rel.NoShows, &rel.Penalty, &rel.ActiveClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	rel.Evaluate()
+	return rel, nil
+}
+
+func scanScheduledClaim(row pgx.Row) (*domain.ScheduledRideClaim, error) {
+	var c domain.ScheduledRideClaim
+	var reason *string
+	err := row.Scan(
+		&c.ID, &c.RideID, &c.DriverID, &c.ScheduledFor, &c.Status,
+		&c.Penalty, &reason, &c.ClaimedAt, &c.ClosedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if reason != nil {
+		c.ReleaseReason = *reason
+	}
+	return &c, nil
+}
+
+// CreateScheduledClaimTables creates the scheduled ride claim table (for testing/migrations)
+func (r *RideRepository) CreateScheduledClaimTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS scheduled_ride_claims (
+			id UUID PRIMARY KEY,
+			ride_id UUID NOT NULL REFERENCES rides(id),
+			driver_id UUID NOT NULL,
+			scheduled_for TIMESTAMPTZ NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			penalty INT NOT NULL DEFAULT 0,
+			release_reason VARCHAR(255),
+			claimed_at TIMESTAMPTZ NOT NULL,
+			closed_at TIMESTAMPTZ
+		);
+
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_scheduled_ride_claims_open ON scheduled_ride_claims(ride_id) WHERE status = 'CLAIMED';
+		CREATE INDEX IF NOT EXISTS idx_scheduled_ride_claims_driver ON scheduled_ride_claims(driver_id, claimed_at);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+const scheduledGuaranteeColumns = `
+	g.ride_id, g.rider_id, g.scheduled_for, g.currency,
+	g.quoted_fare, g.fare_low, g.fare_high, g.reminders_sent,
+	g.alarm_raised_at, g.failed_at, g.voucher_code, g.voucher_amount, g.created_at`
+
+// SaveScheduledGuarantee stores the fare band locked for a scheduled ride
+func (r *RideRepository) SaveScheduledGuarantee(ctx context.Context, g *domain.ScheduledRideGuarantee) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO scheduled_ride_guarantees (
+			ride_id, rider_id, scheduled_for, currency,
+			quoted_fare, fare_low, fare_high, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		g.RideID, g.RiderID, g.ScheduledFor, g.Currency,
+		g.QuotedFare, g.FareLow, g.FareHigh, g.CreatedAt,
+	)
+	return err
+}
+
+// GetScheduledGuarantee gets a scheduled ride's guarantee, or nil if the
+// ride has none
+func (r *RideRepository) GetScheduledGuarantee(ctx context.Context, rideID uuid.UUID) (*domain.ScheduledRideGuarantee, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+scheduledGuaranteeColumns+` FROM scheduled_ride_guarantees g WHERE g.ride_id = $1`, rideID)
+	g, err := scanScheduledGuarantee(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return g, err
+}
+
+// ListUpcomingScheduledGuarantees gets guarantees of live rides picking up
+// in (from, to]
+func (r *RideRepository) ListUpcomingScheduledGuarantees(ctx context.Context, from, to time.Time) ([]*domain.ScheduledRideGuarantee, error) {
+	return r.queryScheduledGuarantees(ctx, `
+		SELECT `+scheduledGuaranteeColumns+`
+		FROM scheduled_ride_guarantees g
+		JOIN rides r ON r.id = g.ride_id
+		WHERE g.scheduled_for > $1 AND g.scheduled_for <= $2
+			AND r.status NOT IN ('COMPLETED', 'CANCELLED')
+		ORDER BY g.scheduled_for`,
+		from, to,
+	)
+}
+
+// MarkScheduledReminderSent records a reminder as sent
+func (r *RideRepository) MarkScheduledReminderSent(ctx context.Context, rideID uuid.UUID, reminder domain.ScheduledReminder) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE scheduled_ride_guarantees SET reminders_sent = array_append(reminders_sent, $2)
+		WHERE ride_id = $1 AND NOT ($2 = ANY(reminders_sent))`,
+		rideID, string(reminder),
+	)
+	return err
+}
+
+// ListScheduledAwaitingDriver gets scheduled rides picking up by the given
+// time that still have no driver and have not yet raised an alarm
+func (r *RideRepository) ListScheduledAwaitingDriver(ctx context.Context, before time.Time) ([]*domain.ScheduledRideGuarantee, error) {
+	return r.queryScheduledGuarantees(ctx, `
+		SELECT `+scheduledGuaranteeColumns+`
+		FROM scheduled_ride_guarantees g
+		JOIN rides r ON r.id = g.ride_id
+		WHERE g.scheduled_for <= $1
+			AND g.alarm_raised_at IS NULL
+			AND r.driver_id IS NULL
+			AND r.status IN ('PENDING', 'SEARCHING', 'MATCHED')
+		ORDER BY g.scheduled_for`,
+		before,
+	)
+}
+
+// MarkScheduledAlarmRaised records that ops were alarmed about a ride
+func (r *RideRepository) MarkScheduledAlarmRaised(ctx context.Context, rideID uuid.UUID, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE scheduled_ride_guarantees SET alarm_raised_at = $2 WHERE ride_id = $1`, rideID, at)
+	return err
+}
+
+// ListUnfulfilledScheduledRides gets scheduled rides picking up by the given
+// time that the platform failed to serve and has not yet compensated: rides
+// still without a driver, and rides cancelled by anyone but the rider
+func (r *RideRepository) ListUnfulfilledScheduledRides(ctx context.Context, before time.Time) ([]*domain.ScheduledRideGuarantee, error) {
+	return r.queryScheduledGuarantees(ctx, `
+		SELECT `+scheduledGuaranteeColumns+`
+		FROM scheduled_ride_guarantees g
+		JOIN rides r ON r.id = g.ride_id
+		WHERE g.scheduled_for <= $1
+			AND g.failed_at IS NULL
+			AND (
+				(r.driver_id IS NULL AND r.status IN ('PENDING', 'SEARCHING', 'MATCHED'))
+				OR (r.status = 'CANCELLED' AND r.cancelled_by IS DISTINCT FROM r.rider_id)
+			)
+		ORDER BY g.scheduled_for`,
+		before,
+	)
+}
+
+// MarkScheduledRideFailed records a failed scheduled ride and the voucher
+// issued for it
+func (r *RideRepository) MarkScheduledRideFailed(ctx context.Context, rideID uuid.UUID, at time.Time, voucherCode string, voucherAmount int64) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE scheduled_ride_guarantees SET failed_at = $2, voucher_code = $3, voucher_amount = $4
+		WHERE ride_id = $1`,
+		rideID, at, voucherCode, voucherAmount,
+	)
+	return err
+}
+
+func (r *RideRepository) queryScheduledGuarantees(ctx context.Context, query string, args ...interface{}) ([]*domain.ScheduledRideGuarantee, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	guarantees := make([]*domain.ScheduledRideGuarantee, 0)
+	for rows.Next() {
+		g, err := scanScheduledGuarantee(rows)
+		if err != nil {
+			return nil, err
+		}
+		guarantees = append(guarantees, g)
+	}
+
+	return guarantees, rows.Err()
+}
+
+func scanScheduledGuarantee(row pgx.Row) (*domain.ScheduledRideGuarantee, error) {
+	var g domain.ScheduledRideGuarantee
+	var reminders []string
+	var voucherCode *string
+	err := row.Scan(
+		&g.RideID, &g.RiderID, &g.ScheduledFor, &g.Currency,
+		&g.QuotedFare, &g.FareLow, &g.FareHigh, &reminders,
+		&g.AlarmRaisedAt, &g.FailedAt, &voucherCode, &g.VoucherAmount, &g.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	g.RemindersSent = make([]domain.ScheduledReminder, 0, len(reminders))
+	for _, reminder := range reminders {
+		g.RemindersSent = append(g.RemindersSent, domain.ScheduledReminder(reminder))
+	}
+	if voucherCode != nil {
+		g.VoucherCode = *voucherCode
+	}
+	return &g, nil
+}
+
+// CreateScheduledRideTables creates the scheduled ride guarantee table (for testing/migrations)
+func (r *RideRepository) CreateScheduledRideTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS scheduled_ride_guarantees (
+			ride_id UUID PRIMARY KEY REFERENCES rides(id),
+			rider_id UUID NOT NULL,
+			scheduled_for TIMESTAMPTZ NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			quoted_fare BIGINT NOT NULL,
+			fare_low BIGINT NOT NULL,
+			fare_high BIGINT NOT NULL,
+			reminders_sent TEXT[] NOT NULL DEFAULT '{}',
+			alarm_raised_at TIMESTAMPTZ,
+			failed_at TIMESTAMPTZ,
+			voucher_code VARCHAR(50),
+			voucher_amount BIGINT NOT NULL DEFAULT 0,
+			created_at TIMESTAMPTZ NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_scheduled_ride_guarantees_scheduled_for ON scheduled_ride_guarantees(scheduled_for) WHERE failed_at IS NULL;
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// rideColumns is the column list scanRide reads
+const rideColumns = `
+			id, rider_id, driver_id, vehicle_id,
+			pickup_location, dropoff_location, stops, stop_states, current_location,
+			type, status, payment_method,
+			route, price,
+			scheduled_for, requested_at, accepted_at, arrived_at,
+			started_at, completed_at, cancelled_at,
+			cancellation_reason, cancelled_by,
+			rider_rating, driver_rating,
+			promo_code, metadata,
+			created_at, updated_at, version`
+
+// Hot queries, run on every ride poll or driver ping. They are prepared
+// on each connection under their own text, so calls skip the parse and
+// plan round trip and send only the statement's parameters.
+const (
+	getRideByIDSQL = `
+		SELECT` + rideColumns + `
+		FROM rides WHERE id = $1`
+
+	getActiveRideByRiderSQL = `
+		SELECT` + rideColumns + `
+		FROM rides
+		WHERE rider_id = $1
+			AND status NOT IN ('COMPLETED', 'CANCELLED', 'EXPIRED')
+			AND NOT (status = 'PENDING' AND scheduled_for IS NOT NULL)
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	getActiveRideByDriverSQL = `
+		SELECT` + rideColumns + `
+		FROM rides
+		WHERE driver_id = $1
+			AND status NOT IN ('COMPLETED', 'CANCELLED', 'EXPIRED')
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	getDriverByIDSQL = `
+		SELECT
+			d.id, d.user_id, d.status,
+			u.first_name, u.last_name, u.phone, u.profile_photo,
+			d.current_location, d.h3_cell, d.last_location_at,
+			d.heading, d.speed,
+			d.rating, d.total_rides, d.acceptance_rate,
+			d.current_ride_id, d.online_since,
+			d.created_at, d.updated_at,
+			v.id as vehicle_id, v.type as vehicle_type,
+			v.make, v.model, v.year, v.color, v.license_plate,
+			v.capacity, v.supported_types, v.powertrain
+		FROM drivers d
+		JOIN users u ON u.id = d.user_id
+		LEFT JOIN vehicles v ON v.driver_id = d.id AND v.is_active = true
+		WHERE d.id = $1`
+
+	getInProgressRouteSQL = `
+		SELECT rd.id, rd.route
+		FROM drivers d
+		JOIN rides rd ON rd.id = d.current_ride_id
+		WHERE d.id = $1 AND rd.status = 'IN_PROGRESS'`
+)
+
+// hotStatements are prepared on every new connection
+var hotStatements = []string{
+	getRideByIDSQL,
+	getActiveRideByRiderSQL,
+	getActiveRideByDriverSQL,
+	getDriverByIDSQL,
+	getInProgressRouteSQL,
+}
+
+// PrepareHotStatements prepares the hot queries on a new connection; use
+// it as the pool's AfterConnect hook. Statements are named by their text,
+// so repository calls pick them up without knowing they were prepared,
+// and run as ordinary queries when the hook is not set. A statement that
+// fails to prepare, say before its tables are migrated, is logged rather
+// than failing the connection.
+func PrepareHotStatements(ctx context.Context, conn *pgx.Conn) error {
+	for _, sql := range hotStatements {
+		if err := prepareStatements(ctx, conn, sql); err != nil {
+			log.Warn().Err(err).Msg("Hot statement not prepared")
+		}
+	}
+	return nil
+}
+
+// prepareStatements prepares each statement under its own text
+func prepareStatements(ctx context.Context, conn *pgx.Conn, statements ...string) error {
+	for _, sql := range statements {
+		if _, err := conn.Prepare(ctx, sql, sql); err != nil {
+			return fmt.Errorf("failed to prepare statement: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// BenchmarkGetRideByID compares the ways pgx can run the ride lookup:
+//
+//   - simple_protocol sends the query text and parses it on every call
+//   - exec parses and plans the text on every call over the extended protocol
+//   - cache_describe caches the description but still sends the text
+//   - cache_statement prepares on first use and sends only parameters after
+//   - prepared prepares up front, as PrepareHotStatements does on connect
+//
+// It runs only when RIDE_BENCH_DATABASE_URL points at a scratch database:
+//
+//	RIDE_BENCH_DATABASE_URL=postgres://localhost/ride_bench \
+//	  go test ./internal/repository -run '^$' -bench GetRideByID -benchmem
+func BenchmarkGetRideByID(b *testing.B) {
+	dsn := os.Getenv("RIDE_BENCH_DATABASE_URL")
+	if dsn == "" {
+		b.Skip("RIDE_BENCH_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	rideID := seedBenchRide(b, ctx, dsn)
+
+	modes := []struct {
+		name     string
+		mode     pgx.QueryExecMode
+		prepared bool
+	}{
+		{"simple_protocol", pgx.QueryExecModeSimpleProtocol, false},
+		{"exec", pgx.QueryExecModeExec, false},
+		{"cache_describe", pgx.QueryExecModeCacheDescribe, false},
+		{"cache_statement", pgx.QueryExecModeCacheStatement, false},
+		{"prepared", pgx.QueryExecModeCacheStatement, true},
+	}
+	for _, m := range modes {
+		b.Run(m.name, func(b *testing.B) {
+			cfg, err := pgxpool.ParseConfig(dsn)
+			if err != nil {
+				b.Fatal(err)
+			}
+			cfg.MaxConns = 1
+			cfg.ConnConfig.DefaultQueryExecMode = m.mode
+			if m.prepared {
+				cfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+					return prepareStatements(ctx, conn, getRideByIDSQL)
+				}
+			}
+			pool, err := pgxpool.NewWithConfig(ctx, cfg)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer pool.Close()
+
+			repo := NewRideRepository(pool)
+			if _, err := repo.GetByID(ctx, rideID); err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.GetByID(ctx, rideID); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// seedBenchRide creates the rides table and a ride to look up
+func seedBenchRide(b *testing.B, ctx context.Context, dsn string) uuid.UUID {
+	b.Helper()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer pool.Close()
+
+	if err := NewRideRepository(pool).CreateRidesTable(ctx); err != nil {
+		b.Fatal(err)
+	}
+	rideID := uuid.New()
+	_, err = pool.Exec(ctx, `
+		INSERT INTO rides (id, rider_id, pickup_location, dropoff_location, type, status, payment_method, requested_at)
+		VALUES ($1, $2, '{"latitude": 6.4281, "longitude": 3.4219}', '{"latitude": 6.455, "longitude": 3.3841}',
+			'STANDARD', 'SEARCHING', 'CASH', NOW())`,
+		rideID, uuid.New(),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return rideID
+}
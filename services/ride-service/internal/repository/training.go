@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// TrainingRepository handles the driver training completion registry
+type TrainingRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewTrainingRepository creates a new training repository
+func NewTrainingRepository(pool *pgxpool.Pool) *TrainingRepository {
+	return &TrainingRepository{pool: pool}
+}
+
+// RecordCompletion stores a completion, reporting false when the provider
+// already reported it. Providers retry webhooks, so this must be idempotent.
+func (r *TrainingRepository) RecordCompletion(ctx context.Context, c *domain.TrainingCompletion) (bool, error) {
+	result, err := r.pool.Exec(ctx, `
+		INSERT INTO driver_training_completions (
+			id, driver_id, module_id, provider, external_id, score, completed_at, recorded_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (provider, external_id) DO NOTHING`,
+		c.ID, c.DriverID, c.ModuleID, c.Provider, c.ExternalID, c.Score, c.CompletedAt, c.RecordedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// ListCompletions gets a driver's completions, newest first
+func (r *TrainingRepository) ListCompletions(ctx context.Context, driverID uuid.UUID) ([]*domain.TrainingCompletion, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, driver_id, module_id, provider, external_id, score, completed_at, recorded_at
+		FROM driver_training_completions
+		WHERE driver_id = $1
+		ORDER BY completed_at DESC`,
+		driverID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	completions := make([]*domain.TrainingCompletion, 0)
+	for rows.Next() {
+		var c domain.TrainingCompletion
+		if err := rows.Scan(
+			&c.ID, &c.DriverID, &c.ModuleID, &c.Provider, &c.ExternalID, &c.Score, &c.CompletedAt, &c.RecordedAt,
+		); err != nil {
+			return nil, err
+		}
+		completions = append(completions, &c)
+	}
+
+	return completions, rows.Err()
+}
+
+// CompletedModules gets which of the given modules each driver has
+// completed. Drivers with none are left out.
+func (r *TrainingRepository) CompletedModules(ctx context.Context, driverIDs []uuid.UUID, modules []string) (map[uuid.UUID]map[string]bool, error) {
+	completed := make(map[uuid.UUID]map[string]bool)
+	if len(driverIDs) == 0 || len(modules) == 0 {
+		return completed, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT DISTINCT driver_id, module_id
+		FROM driver_training_completions
+		WHERE driver_id = ANY($1) AND module_id = ANY($2)`,
+		driverIDs, modules,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var driverID uuid.UUID
+		var module string
+		if err := rows.Scan(&driverID, &module); err != nil {
+			return nil, err
+		}
+		if completed[driverID] == nil {
+			completed[driverID] = make(map[string]bool)
+		}
+		completed[driverID][module] = true
+	}
+
+	return completed, rows.Err()
+}
+
+// CreateTrainingTables creates the training completion table (for testing/migrations)
+func (r *TrainingRepository) CreateTrainingTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS driver_training_completions (
+			id UUID PRIMARY KEY,
+			driver_id UUID NOT NULL,
+			module_id VARCHAR(100) NOT NULL,
+			provider VARCHAR(50) NOT NULL,
+			external_id VARCHAR(255) NOT NULL,
+			score DOUBLE PRECISION,
+			completed_at TIMESTAMPTZ NOT NULL,
+			recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			UNIQUE (provider, external_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_driver_training_driver ON driver_training_completions(driver_id, module_id);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// ZoneRideAggregate is a zone's ride counters for one bucket, with a
+// representative pickup point for resolving its city
+type ZoneRideAggregate struct {
+	domain.ZoneKPIRollup
+	Latitude  float64
+	Longitude float64
+}
+
+// AggregateZoneRides aggregates rides requested in [from, to) into
+// ZoneKPIBucket buckets by pickup cell
+func (r *RideRepository) AggregateZoneRides(ctx context.Context, from, to time.Time) ([]*ZoneRideAggregate, error) {
+	bucketSeconds := int64(domain.ZoneKPIBucket / time.Second)
+	rows, err := r.pool.Query(ctx, `
+		SELECT
+			pickup_location->>'h3_cell' AS zone,
+			to_timestamp(floor(extract(epoch FROM requested_at) / $3) * $3) AS bucket,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'COMPLETED'),
+			COUNT(*) FILTER (WHERE status = 'CANCELLED'),
+			COUNT(*) FILTER (WHERE status = 'EXPIRED'),
+			COALESCE(SUM(extract(epoch FROM arrived_at - accepted_at)) FILTER (WHERE arrived_at > accepted_at), 0)::BIGINT,
+			COUNT(*) FILTER (WHERE arrived_at > accepted_at),
+			COALESCE(SUM((price->>'surge_multiplier')::DOUBLE PRECISION) FILTER (WHERE price ? 'surge_multiplier'), 0),
+			COUNT(*) FILTER (WHERE price ? 'surge_multiplier'),
+			AVG((pickup_location->>'latitude')::DOUBLE PRECISION),
+			AVG((pickup_location->>'longitude')::DOUBLE PRECISION)
+		FROM rides
+		WHERE requested_at >= $1 AND requested_at < $2
+			AND COALESCE(pickup_location->>'h3_cell', '') != ''
+		GROUP BY zone, bucket`,
+		from, to, bucketSeconds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aggregates := make([]*ZoneRideAggregate, 0)
+	for rows.Next() {
+		var a ZoneRideAggregate
+		if err := rows.Scan(
+			&a.Zone, &a.BucketStart,
+			&a.Requests, &a.Completed, &a.Cancelled, &a.Expired,
+			&a.ETASumSeconds, &a.ETACount,
+			&a.SurgeSum, &a.SurgeCount,
+			&a.Latitude, &a.Longitude,
+		); err != nil {
+			return nil, err
+		}
+		aggregates = append(aggregates, &a)
+	}
+
+	return aggregates, rows.Err()
+}
+
+// UpsertZoneRideRollups stores the ride counters of a batch of rollups,
+// replacing any previously rolled up for the same zone and bucket. Supply
+// samples are left as they are.
+func (r *RideRepository) UpsertZoneRideRollups(ctx context.Context, rollups []*domain.ZoneKPIRollup) error {
+	batch := &pgx.Batch{}
+	for _, z := range rollups {
+		batch.Queue(`
+			INSERT INTO zone_kpi_rollups (
+				zone, bucket_start, city_code, requests, completed, cancelled, expired,
+				eta_sum_seconds, eta_count, surge_sum, surge_count
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (zone, bucket_start) DO UPDATE SET
+				city_code = EXCLUDED.city_code,
+				requests = EXCLUDED.requests,
+				completed = EXCLUDED.completed,
+				cancelled = EXCLUDED.cancelled,
+				expired = EXCLUDED.expired,
+				eta_sum_seconds = EXCLUDED.eta_sum_seconds,
+				eta_count = EXCLUDED.eta_count,
+				surge_sum = EXCLUDED.surge_sum,
+				surge_count = EXCLUDED.surge_count,
+				updated_at = NOW()`,
+			z.Zone, z.BucketStart, z.CityCode, z.Requests, z.Completed, z.Cancelled, z.Expired,
+			z.ETASumSeconds, z.ETACount, z.SurgeSum, z.SurgeCount,
+		)
+	}
+
+	return r.pool.SendBatch(ctx, batch).Close()
+}
+
+// AddZoneSupplySamples adds one available-driver sample per zone to the
+// rollups' supply counters
+func (r *RideRepository) AddZoneSupplySamples(ctx context.Context, samples []*domain.ZoneKPIRollup) error {
+	batch := &pgx.Batch{}
+	for _, z := range samples {
+		batch.Queue(`
+			INSERT INTO zone_kpi_rollups (zone, bucket_start, city_code, supply_sum, supply_samples)
+			VALUES ($1, $2, $3, $4, 1)
+			ON CONFLICT (zone, bucket_start) DO UPDATE SET
+				supply_sum = zone_kpi_rollups.supply_sum + EXCLUDED.supply_sum,
+				supply_samples = zone_kpi_rollups.supply_samples + 1,
+				updated_at = NOW()`,
+			z.Zone, z.BucketStart, z.CityCode, z.SupplySum,
+		)
+	}
+
+	return r.pool.SendBatch(ctx, batch).Close()
+}
+
+// ListRecentZones gets the zones with rollups since the given time
+func (r *RideRepository) ListRecentZones(ctx context.Context, since time.Time) ([]*domain.ZoneKPIRollup, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT DISTINCT ON (zone) zone, city_code
+		FROM zone_kpi_rollups
+		WHERE bucket_start >= $1 AND requests > 0
+		ORDER BY zone, bucket_start DESC`,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	zones := make([]*domain.ZoneKPIRollup, 0)
+	for rows.Next() {
+		var z domain.ZoneKPIRollup
+		if err := rows.Scan(&z.Zone, &z.CityCode); err != nil {
+			return nil, err
+		}
+		zones = append(zones, &z)
+	}
+
+	return zones, rows.Err()
+}
+
+// GetZoneKPIRollups gets rollups with buckets starting in [from, to),
+// optionally for one city or zone, oldest first
+func (r *RideRepository) GetZoneKPIRollups(ctx context.Context, from, to time.Time, cityCode, zone string) ([]*domain.ZoneKPIRollup, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT
+			zone, city_code, bucket_start, requests, completed, cancelled, expired,
+			eta_sum_seconds, eta_count, surge_sum, surge_count, supply_sum, supply_samples
+		FROM zone_kpi_rollups
+		WHERE bucket_start >= $1 AND bucket_start < $2
+			AND ($3 = '' OR city_code = $3)
+			AND ($4 = '' OR zone = $4)
+		ORDER BY bucket_start, zone`,
+		from, to, cityCode, zone,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rollups := make([]*domain.ZoneKPIRollup, 0)
+	for rows.Next() {
+		var z domain.ZoneKPIRollup
+		if err := rows.Scan(
+			&z.Zone, &z.CityCode, &z.BucketStart, &z.Requests, &z.Completed, &z.Cancelled, &z.Expired,
+			&z.ETASumSeconds, &z.ETACount, &z.SurgeSum, &z.SurgeCount, &z.SupplySum, &z.SupplySamples,
+		); err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, &z)
+	}
+
+	return rollups, rows.Err()
+}
+
+// CreateZoneKPITables creates the zone KPI rollup table (for testing/migrations)
+func (r *RideRepository) CreateZoneKPITables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS zone_kpi_rollups (
+			zone VARCHAR(32) NOT NULL,
+			bucket_start TIMESTAMPTZ NOT NULL,
+			city_code VARCHAR(50) NOT NULL DEFAULT '',
+			requests BIGINT NOT NULL DEFAULT 0,
+			completed BIGINT NOT NULL DEFAULT 0,
+			cancelled BIGINT NOT NULL DEFAULT 0,
+			expired BIGINT NOT NULL DEFAULT 0,
+			eta_sum_seconds BIGINT NOT NULL DEFAULT 0,
+			eta_count BIGINT NOT NULL DEFAULT 0,
+			surge_sum DOUBLE PRECISION NOT NULL DEFAULT 0,
+			surge_count BIGINT NOT NULL DEFAULT 0,
+			supply_sum BIGINT NOT NULL DEFAULT 0,
+			supply_samples BIGINT NOT NULL DEFAULT 0,
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (zone, bucket_start)
+		);
+
+		ALTER TABLE zone_kpi_rollups ADD COLUMN IF NOT EXISTS expired BIGINT NOT NULL DEFAULT 0;
+
+		CREATE INDEX IF NOT EXISTS idx_zone_kpi_rollups_bucket ON zone_kpi_rollups(bucket_start);
+		CREATE INDEX IF NOT EXISTS idx_rides_requested_at ON rides(requested_at);
+	`
+
+	_, err := r.pool.Exec(ctx, query)
+	return err
+}
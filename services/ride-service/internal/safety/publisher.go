@@ -0,0 +1,109 @@
+// Package safety delivers SOS alerts to the ops team. Alerts go to a Kafka
+// topic for the incident tooling and to a Redis stream and channel for the
+// live ops dashboard, so either path alone is enough to reach a person.
+package safety
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/segmentio/kafka-go"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/logging"
+)
+
+// Where alerts are published
+const (
+	AlertTopic   = "safety-alerts"     // Kafka topic, keyed by ride
+	AlertStream  = "ops:safety-alerts" // Redis stream ops read and acknowledge from
+	AlertChannel = "ops:safety-alerts" // Redis channel for dashboards already open
+)
+
+// streamMaxLen bounds the Redis stream; older alerts live on in Kafka and
+// the incident table
+const streamMaxLen = 10000
+
+var safetyLog = logging.For("safety")
+
+// Publisher sends alerts to whichever of Kafka and Redis are configured
+type Publisher struct {
+	kafka *kafka.Writer
+	redis *goredis.Client
+}
+
+// NewPublisher creates a publisher. brokers may be empty and redis nil, but
+// with neither every alert fails.
+func NewPublisher(brokers []string, redis *goredis.Client) *Publisher {
+	p := &Publisher{redis: redis}
+	if len(brokers) > 0 {
+		p.kafka = &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        AlertTopic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			WriteTimeout: 5 * time.Second,
+		}
+	}
+	return p
+}
+
+// Publish sends an alert, failing only if no configured path took it
+func (p *Publisher) Publish(ctx context.Context, alert *domain.SafetyAlert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal safety alert: %w", err)
+	}
+
+	var errs []error
+	delivered := false
+
+	if p.kafka != nil {
+		err := p.kafka.WriteMessages(ctx, kafka.Message{
+			Key:   []byte(alert.RideID.String()),
+			Value: data,
+		})
+		if err != nil {
+			safetyLog.Error().Err(err).Str("incident_id", alert.IncidentID.String()).Msg("Failed to publish safety alert to Kafka")
+			errs = append(errs, err)
+		} else {
+			delivered = true
+		}
+	}
+
+	if p.redis != nil {
+		pipe := p.redis.TxPipeline()
+		pipe.XAdd(ctx, &goredis.XAddArgs{
+			Stream: AlertStream,
+			MaxLen: streamMaxLen,
+			Approx: true,
+			Values: map[string]interface{}{"alert": data},
+		})
+		pipe.Publish(ctx, AlertChannel, data)
+		if _, err := pipe.Exec(ctx); err != nil {
+			safetyLog.Error().Err(err).Str("incident_id", alert.IncidentID.String()).Msg("Failed to publish safety alert to Redis")
+			errs = append(errs, err)
+		} else {
+			delivered = true
+		}
+	}
+
+	if !delivered {
+		if len(errs) == 0 {
+			return errors.New("no safety alert destination configured")
+		}
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// Close flushes and closes the Kafka writer
+func (p *Publisher) Close() error {
+	if p.kafka == nil {
+		return nil
+	}
+	return p.kafka.Close()
+}
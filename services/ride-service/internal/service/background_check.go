@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// Maximum re-checks requested per scheduling run
+const recheckBatchSize = 100
+
+// CheckProvider starts background checks with a third-party provider
+type CheckProvider interface {
+	RequestCheck(ctx context.Context, provider string, driverID uuid.UUID, country string) (string, error)
+}
+
+// BackgroundCheckService tracks driver background checks and enforces that
+// only cleared drivers go online
+type BackgroundCheckService struct {
+	checkRepo  *repository.BackgroundCheckRepository
+	driverRepo *repository.DriverRepository
+	driverPool *redis.DriverPool
+	standing   *DriverStandingService
+	provider   CheckProvider
+}
+
+// NewBackgroundCheckService creates a new background check service.
+// provider may be nil, in which case re-checks are not requested and lapsed
+// checks simply expire.
+func NewBackgroundCheckService(
+	checkRepo *repository.BackgroundCheckRepository,
+	driverRepo *repository.DriverRepository,
+	driverPool *redis.DriverPool,
+	standing *DriverStandingService,
+	provider CheckProvider,
+) *BackgroundCheckService {
+	return &BackgroundCheckService{
+		checkRepo:  checkRepo,
+		driverRepo: driverRepo,
+		driverPool: driverPool,
+		standing:   standing,
+		provider:   provider,
+	}
+}
+
+// HandleWebhook records a provider's status update for a check. Checks the
+// provider started outside this service (e.g. during onboarding) are created
+// on first sight.
+func (s *BackgroundCheckService) HandleWebhook(ctx context.Context, provider string, payload *domain.BackgroundCheckWebhook) (*domain.BackgroundCheck, error) {
+	status, ok := domain.ParseBackgroundCheckStatus(payload.Status)
+	if !ok || payload.CheckID == "" || payload.DriverID == uuid.Nil {
+		return nil, domain.ErrInvalidRequest
+	}
+
+	check, err := s.checkRepo.GetByExternalID(ctx, provider, payload.CheckID)
+	if err == domain.ErrBackgroundCheckNotFound {
+		country := strings.ToUpper(strings.TrimSpace(payload.Country))
+		if len(country) != 2 {
+			return nil, domain.ErrInvalidRequest
+		}
+		now := time.Now().UTC()
+		check = &domain.BackgroundCheck{
+			ID:         uuid.New(),
+			DriverID:   payload.DriverID,
+			Provider:   provider,
+			ExternalID: payload.CheckID,
+			Country:    country,
+			Status:     domain.BackgroundCheckPending,
+			CreatedAt:  now,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Deliveries can arrive out of order; a late "pending" must not undo a result
+	if check.Status.IsFinal() && !status.IsFinal() {
+		return check, nil
+	}
+
+	completedAt := payload.CompletedAt
+	if completedAt.IsZero() {
+		completedAt = time.Now().UTC()
+	}
+	check.ApplyResult(status, payload.Detail, completedAt)
+
+	if err := s.checkRepo.Save(ctx, check); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("driver_id", check.DriverID.String()).
+		Str("provider", provider).
+		Str("status", string(check.Status)).
+		Msg("Background check updated")
+
+	if status == domain.BackgroundCheckConsider || status == domain.BackgroundCheckFailed {
+		s.placeUnderReview(ctx, check)
+	}
+
+	return check, nil
+}
+
+// IsCleared reports whether a driver's latest finished check permits them to work
+func (s *BackgroundCheckService) IsCleared(ctx context.Context, driverID uuid.UUID) (bool, error) {
+	check, err := s.checkRepo.GetLatestCompleted(ctx, driverID)
+	if err == domain.ErrBackgroundCheckNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return check.IsCleared(time.Now().UTC()), nil
+}
+
+// ListChecks lists a driver's background checks
+func (s *BackgroundCheckService) ListChecks(ctx context.Context, driverID uuid.UUID) ([]*domain.BackgroundCheck, error) {
+	return s.checkRepo.ListByDriver(ctx, driverID)
+}
+
+// ScheduleRechecks requests renewals for checks nearing expiry, then expires
+// lapsed checks and takes those drivers offline
+func (s *BackgroundCheckService) ScheduleRechecks(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	requested := 0
+
+	if s.provider != nil {
+		due, err := s.checkRepo.ListDueForRecheck(ctx, now.Add(domain.RecheckLeadTime), recheckBatchSize)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, prev := range due {
+			externalID, err := s.provider.RequestCheck(ctx, prev.Provider, prev.DriverID, prev.Country)
+			if err != nil {
+				log.Error().Err(err).Str("driver_id", prev.DriverID.String()).Msg("Failed to request background re-check")
+				continue
+			}
+
+			recheck := &domain.BackgroundCheck{
+				ID:         uuid.New(),
+				DriverID:   prev.DriverID,
+				Provider:   prev.Provider,
+				ExternalID: externalID,
+				Country:    prev.Country,
+				Status:     domain.BackgroundCheckPending,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			}
+			if err := s.checkRepo.Save(ctx, recheck); err != nil {
+				log.Error().Err(err).Str("driver_id", prev.DriverID.String()).Msg("Failed to save background re-check")
+				continue
+			}
+			requested++
+		}
+	}
+
+	lapsed, err := s.checkRepo.ExpireLapsed(ctx, now)
+	if err != nil {
+		return requested, err
+	}
+	for _, driverID := range lapsed {
+		if cleared, err := s.IsCleared(ctx, driverID); err == nil && !cleared {
+			s.takeOffline(ctx, driverID)
+		}
+	}
+
+	return requested, nil
+}
+
+// placeUnderReview restricts a driver whose check came back adverse, unless
+// they already carry a sanction
+func (s *BackgroundCheckService) placeUnderReview(ctx context.Context, check *domain.BackgroundCheck) {
+	if s.standing == nil {
+		s.takeOffline(ctx, check.DriverID)
+		return
+	}
+
+	standing, err := s.standing.GetStanding(ctx, check.DriverID)
+	if err != nil || standing.ActiveSanction != nil {
+		return
+	}
+
+	_, err = s.standing.SanctionDriver(ctx, check.DriverID, uuid.Nil,
+		domain.DriverStatusUnderReview, domain.SanctionReasonBackgroundCheck,
+		"Background check returned "+string(check.Status), 0,
+	)
+	if err != nil {
+		log.Error().Err(err).Str("driver_id", check.DriverID.String()).Msg("Failed to place driver under review")
+	}
+}
+
+// takeOffline removes an online driver from matching. Drivers mid-ride are
+// left to finish and are blocked the next time they try to go online.
+func (s *BackgroundCheckService) takeOffline(ctx context.Context, driverID uuid.UUID) {
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil || driver.Status != domain.DriverStatusOnline {
+		return
+	}
+
+	if err := s.driverRepo.UpdateStatus(ctx, driverID, domain.DriverStatusOffline); err != nil {
+		log.Error().Err(err).Str("driver_id", driverID.String()).Msg("Failed to take driver offline")
+		return
+	}
+	if s.driverPool != nil {
+		if err := s.driverPool.RemoveDriver(ctx, driverID); err != nil {
+			log.Error().Err(err).Str("driver_id", driverID.String()).Msg("Failed to remove driver from pool")
+		}
+	}
+
+	log.Info().Str("driver_id", driverID.String()).Msg("Driver taken offline: background check not cleared")
+}
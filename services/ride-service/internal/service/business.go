@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// BusinessService manages business accounts and checks the rides their
+// members request on them against the account's policy
+type BusinessService struct {
+	businessRepo *repository.BusinessRepository
+}
+
+// NewBusinessService creates a new business service
+func NewBusinessService(businessRepo *repository.BusinessRepository) *BusinessService {
+	return &BusinessService{businessRepo: businessRepo}
+}
+
+// CreateAccount validates and stores a new business account
+func (s *BusinessService) CreateAccount(ctx context.Context, account *domain.BusinessAccount, adminID uuid.UUID) (*domain.BusinessAccount, error) {
+	if account.CostCenters == nil {
+		account.CostCenters = []string{}
+	}
+	if err := account.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	account.ID = uuid.New()
+	account.Active = true
+	account.CreatedBy = adminID
+	account.CreatedAt = now
+	account.UpdatedAt = now
+
+	if err := s.businessRepo.CreateAccount(ctx, account); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("account_id", account.ID.String()).
+		Str("name", account.Name).
+		Bool("monthly_invoicing", account.MonthlyInvoicing).
+		Str("created_by", adminID.String()).
+		Msg("Business account created")
+
+	return account, nil
+}
+
+// GetAccount gets a business account by ID
+func (s *BusinessService) GetAccount(ctx context.Context, id uuid.UUID) (*domain.BusinessAccount, error) {
+	return s.businessRepo.GetAccount(ctx, id)
+}
+
+// ListAccounts lists business accounts
+func (s *BusinessService) ListAccounts(ctx context.Context) ([]*domain.BusinessAccount, error) {
+	return s.businessRepo.ListAccounts(ctx)
+}
+
+// UpdateAccount replaces an account's name, cost centers, invoicing,
+// policy and status
+func (s *BusinessService) UpdateAccount(ctx context.Context, id uuid.UUID, update *domain.BusinessAccount) (*domain.BusinessAccount, error) {
+	account, err := s.businessRepo.GetAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	account.Name = update.Name
+	account.CostCenters = update.CostCenters
+	if account.CostCenters == nil {
+		account.CostCenters = []string{}
+	}
+	account.MonthlyInvoicing = update.MonthlyInvoicing
+	account.Policy = update.Policy
+	account.Active = update.Active
+	if err := account.Validate(); err != nil {
+		return nil, err
+	}
+	account.UpdatedAt = time.Now().UTC()
+
+	if err := s.businessRepo.UpdateAccount(ctx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// SaveMember adds a rider to an account, or changes their role or default
+// cost center
+func (s *BusinessService) SaveMember(ctx context.Context, accountID uuid.UUID, member *domain.BusinessMember) (*domain.BusinessMember, error) {
+	account, err := s.businessRepo.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == "" {
+		member.Role = domain.BusinessRoleMember
+	}
+	if err := member.Validate(account); err != nil {
+		return nil, err
+	}
+	member.AccountID = account.ID
+	member.CreatedAt = time.Now().UTC()
+
+	if err := s.businessRepo.SaveMember(ctx, member); err != nil {
+		return nil, err
+	}
+	return s.businessRepo.GetMember(ctx, account.ID, member.RiderID)
+}
+
+// RemoveMember removes a rider from an account. Rides they already took on
+// it stay on its statements.
+func (s *BusinessService) RemoveMember(ctx context.Context, accountID, riderID uuid.UUID) error {
+	return s.businessRepo.RemoveMember(ctx, accountID, riderID)
+}
+
+// ListMembers lists an account's members
+func (s *BusinessService) ListMembers(ctx context.Context, accountID uuid.UUID) ([]*domain.BusinessMember, error) {
+	if _, err := s.businessRepo.GetAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	return s.businessRepo.ListMembers(ctx, accountID)
+}
+
+// ListProfiles lists the business profiles a rider can request rides on
+func (s *BusinessService) ListProfiles(ctx context.Context, riderID uuid.UUID) ([]*domain.BusinessProfile, error) {
+	return s.businessRepo.ListProfiles(ctx, riderID)
+}
+
+// GetStatement gets an account's completed rides for a month, for platform
+// admins and the account's own admins
+func (s *BusinessService) GetStatement(ctx context.Context, accountID, userID uuid.UUID, role, month string) (*domain.BusinessStatement, error) {
+	from, to, err := domain.ParseStatementMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBusinessAdmin(ctx, s.businessRepo, accountID, userID, role); err != nil {
+		return nil, err
+	}
+
+	lines, err := s.businessRepo.ListStatementLines(ctx, accountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewBusinessStatement(accountID, month, lines), nil
+}
+
+// checkBusinessAdmin checks the account exists and the user is a platform
+// admin or one of the account's own admins
+func checkBusinessAdmin(ctx context.Context, repo *repository.BusinessRepository, accountID, userID uuid.UUID, role string) error {
+	if _, err := repo.GetAccount(ctx, accountID); err != nil {
+		return err
+	}
+	if role == domain.RoleAdmin {
+		return nil
+	}
+	member, err := repo.GetMember(ctx, accountID, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role != domain.BusinessRoleAdmin {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeRide checks a rider may take a ride on the business profile
+// they requested it on, at its local pickup time, and resolves the cost
+// center it is charged to
+func (s *BusinessService) AuthorizeRide(ctx context.Context, req *domain.RideRequest, local time.Time) (*domain.BusinessRide, error) {
+	account, err := s.businessRepo.GetAccount(ctx, *req.BusinessAccountID)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.businessRepo.GetMember(ctx, account.ID, req.RiderID)
+	if err != nil {
+		return nil, err
+	}
+
+	costCenter := strings.TrimSpace(req.CostCenter)
+	if costCenter == "" {
+		costCenter = member.DefaultCostCenter
+	}
+	if err := account.CheckRide(req.Type, costCenter, local); err != nil {
+		return nil, err
+	}
+
+	return &domain.BusinessRide{
+		AccountID:        account.ID,
+		RiderID:          req.RiderID,
+		CostCenter:       costCenter,
+		MonthlyInvoicing: account.MonthlyInvoicing,
+	}, nil
+}
+
+// RecordRide records a ride taken on a business account for its statements
+func (s *BusinessService) RecordRide(ctx context.Context, ride *domain.BusinessRide) error {
+	return s.businessRepo.RecordRide(ctx, ride)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/pricing"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// CityConfigService loads city bundles and applies admin edits to the
+// registry and pricing engine without a deploy
+type CityConfigService struct {
+	cityRepo      *repository.CityConfigRepository
+	registry      *cityconfig.Registry
+	pricingEngine *pricing.Engine
+}
+
+// NewCityConfigService creates a new city config service. cityRepo may be
+// nil, in which case only bundle files are loaded and edits are rejected.
+func NewCityConfigService(
+	cityRepo *repository.CityConfigRepository,
+	registry *cityconfig.Registry,
+	pricingEngine *pricing.Engine,
+) *CityConfigService {
+	return &CityConfigService{
+		cityRepo:      cityRepo,
+		registry:      registry,
+		pricingEngine: pricingEngine,
+	}
+}
+
+// Load fills the registry from the compiled-in bundles, then bundle files in
+// dir (if set), then bundles saved through the admin API. Later sources
+// override earlier ones city by city.
+func (s *CityConfigService) Load(ctx context.Context, dir string) error {
+	cities, err := cityconfig.DefaultBundles()
+	if err != nil {
+		return fmt.Errorf("default city bundles: %w", err)
+	}
+
+	if dir != "" {
+		fromDir, err := cityconfig.LoadBundles(os.DirFS(dir))
+		if err != nil {
+			return fmt.Errorf("city bundles in %s: %w", dir, err)
+		}
+		cities = append(cities, fromDir...)
+	}
+
+	if s.cityRepo != nil {
+		stored, err := s.cityRepo.List(ctx)
+		if err != nil {
+			return fmt.Errorf("stored city configs: %w", err)
+		}
+		for _, city := range stored {
+			// Skip rather than fail startup on a bundle saved under older rules
+			if err := city.Validate(); err != nil {
+				log.Error().Err(err).Str("city", city.Code).Msg("Skipping invalid stored city config")
+				continue
+			}
+			cities = append(cities, city)
+		}
+	}
+
+	for _, city := range cities {
+		s.apply(city)
+	}
+
+	log.Info().Int("cities", len(s.registry.List())).Msg("City configs loaded")
+	return nil
+}
+
+// ListCities lists all city bundles
+func (s *CityConfigService) ListCities() []*domain.CityConfig {
+	return s.registry.List()
+}
+
+// GetCity gets a city bundle by code
+func (s *CityConfigService) GetCity(code string) (*domain.CityConfig, error) {
+	city, ok := s.registry.Get(code)
+	if !ok {
+		return nil, domain.ErrCityNotFound
+	}
+	return city, nil
+}
+
+// SaveCity validates and stores a city bundle and applies it immediately
+func (s *CityConfigService) SaveCity(ctx context.Context, city *domain.CityConfig, adminID uuid.UUID) (*domain.CityConfig, error) {
+	if s.cityRepo == nil {
+		return nil, domain.ErrInternal
+	}
+	if err := city.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidCityConfig, err)
+	}
+
+	city.UpdatedAt = time.Now().UTC()
+	if err := s.cityRepo.Upsert(ctx, city, adminID); err != nil {
+		return nil, err
+	}
+	s.apply(city)
+
+	log.Info().
+		Str("city", city.Code).
+		Bool("enabled", city.Enabled).
+		Str("admin_id", adminID.String()).
+		Msg("City config saved")
+
+	return city, nil
+}
+
+// apply makes a bundle live for lookups and pricing
+func (s *CityConfigService) apply(city *domain.CityConfig) {
+	s.registry.Put(city)
+	if s.pricingEngine != nil {
+		s.pricingEngine.SetCityConfig(city)
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// ConsentService keeps users' consents to precise location storage,
+// prediction personalization and marketing, and answers whether their data
+// may be used for each
+type ConsentService struct {
+	consentRepo *repository.ConsentRepository
+	history     *LocationHistoryService
+}
+
+// NewConsentService creates a new consent service
+func NewConsentService(consentRepo *repository.ConsentRepository) *ConsentService {
+	return &ConsentService{consentRepo: consentRepo}
+}
+
+// SetLocationHistory drops users' cached location history when their
+// location or personalization consent changes
+func (s *ConsentService) SetLocationHistory(history *LocationHistoryService) {
+	s.history = history
+}
+
+// GetConsents gets a user's consent status for every purpose
+func (s *ConsentService) GetConsents(ctx context.Context, userID uuid.UUID) ([]*domain.ConsentStatus, error) {
+	set, err := s.Consents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return set.Statuses(), nil
+}
+
+// UpdateConsents records a user granting or withdrawing consents
+func (s *ConsentService) UpdateConsents(ctx context.Context, userID uuid.UUID, choices []domain.ConsentChoice) ([]*domain.ConsentStatus, error) {
+	now := time.Now().UTC()
+	consents := make([]*domain.Consent, 0, len(choices))
+	for _, choice := range choices {
+		c, err := domain.NewConsent(userID, choice, now)
+		if err != nil {
+			return nil, err
+		}
+		consents = append(consents, c)
+	}
+	if len(consents) == 0 {
+		return nil, domain.ErrInvalidConsent
+	}
+
+	if err := s.consentRepo.Save(ctx, consents); err != nil {
+		return nil, err
+	}
+	historyChanged := false
+	for _, c := range consents {
+		log.Info().
+			Str("user_id", userID.String()).
+			Str("purpose", string(c.Purpose)).
+			Bool("granted", c.Granted).
+			Int("version", c.Version).
+			Msg("Consent recorded")
+		historyChanged = historyChanged || c.Purpose != domain.ConsentMarketing
+	}
+	if historyChanged && s.history != nil {
+		s.history.invalidate(ctx, userID)
+	}
+
+	return s.GetConsents(ctx, userID)
+}
+
+// Consents gets a user's recorded consents
+func (s *ConsentService) Consents(ctx context.Context, userID uuid.UUID) (*domain.ConsentSet, error) {
+	consents, err := s.consentRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewConsentSet(userID, consents), nil
+}
+
+// Allows reports whether a user's data may be used for the purpose
+func (s *ConsentService) Allows(ctx context.Context, userID uuid.UUID, purpose domain.ConsentPurpose) (bool, error) {
+	set, err := s.Consents(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return set.Allows(purpose), nil
+}
+
+// AllowedUsers reports, for each of the given users, whether their data may
+// be used for the purpose
+func (s *ConsentService) AllowedUsers(ctx context.Context, purpose domain.ConsentPurpose, userIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	consents, err := s.consentRepo.ListByPurpose(ctx, purpose, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[uuid.UUID]bool, len(userIDs))
+	for _, id := range userIDs {
+		var set *domain.ConsentSet
+		if c, ok := consents[id]; ok {
+			set = domain.NewConsentSet(id, []*domain.Consent{c})
+		}
+		allowed[id] = set.Allows(purpose)
+	}
+	return allowed, nil
+}
+
+// Notifier wraps a notifier so pushes needing consent, such as marketing,
+// reach only users who gave it
+func (s *ConsentService) Notifier(next Notifier) Notifier {
+	return &consentNotifier{next: next, consents: s}
+}
+
+// consentNotifier drops pushes the recipient has not consented to
+type consentNotifier struct {
+	next     Notifier
+	consents *ConsentService
+}
+
+func (n *consentNotifier) SendPush(ctx context.Context, userID uuid.UUID, title, body, priority string, data map[string]string) error {
+	if purpose, ok := domain.NotificationConsent(data); ok {
+		allowed, err := n.consents.Allows(ctx, userID, purpose)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			log.Debug().
+				Str("user_id", userID.String()).
+				Str("type", data["type"]).
+				Msg("Push skipped without consent")
+			return nil
+		}
+	}
+	return n.next.SendPush(ctx, userID, title, body, priority, data)
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/pricing"
+)
+
+// DisputeResolution is a support agent's decision on a queued dispute
+type DisputeResolution struct {
+	Upheld       bool
+	RefundAmount int64 // partial refund issued when the dispute is upheld
+	Note         string
+}
+
+// DisputeRides reads disputed rides and records their dispute events
+type DisputeRides interface {
+	GetRide(ctx context.Context, rideID uuid.UUID) (*domain.Ride, error)
+	RecordEvent(ctx context.Context, event *domain.RideEvent)
+}
+
+// DisputeTrails provides the GPS trails disputed fares are checked against
+type DisputeTrails interface {
+	GetLocationTrail(ctx context.Context, rideID uuid.UUID) ([]domain.TrailPoint, error)
+}
+
+// DisputeStore stores fare disputes
+type DisputeStore interface {
+	Create(ctx context.Context, dispute *domain.FareDispute) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.FareDispute, error)
+	GetLatestByRide(ctx context.Context, rideID uuid.UUID) (*domain.FareDispute, error)
+	LinkRefund(ctx context.Context, dispute *domain.FareDispute) error
+	HasResolved(ctx context.Context, rideID uuid.UUID) (bool, error)
+	ListByStatus(ctx context.Context, status domain.DisputeStatus, limit, offset int) ([]*domain.FareDispute, int64, error)
+	Resolve(ctx context.Context, dispute *domain.FareDispute) error
+}
+
+// DisputeService handles rider fare disputes
+type DisputeService struct {
+	rideService   DisputeRides
+	trails        DisputeTrails
+	disputeRepo   DisputeStore
+	refundService *RefundService
+	pricingEngine *pricing.Engine
+	prefs         *DriverPreferenceService
+}
+
+// NewDisputeService creates a new dispute service
+func NewDisputeService(
+	rideService DisputeRides,
+	trails DisputeTrails,
+	disputeRepo DisputeStore,
+	refundService *RefundService,
+	pricingEngine *pricing.Engine,
+) *DisputeService {
+	return &DisputeService{
+		rideService:   rideService,
+		trails:        trails,
+		disputeRepo:   disputeRepo,
+		refundService: refundService,
+		pricingEngine: pricingEngine,
+	}
+}
+
+// SetDriverPreferences blocks drivers for riders who dispute how they
+// handled the trip
+func (s *DisputeService) SetDriverPreferences(prefs *DriverPreferenceService) {
+	s.prefs = prefs
+}
+
+// OpenDispute records a rider's fare dispute with system-gathered evidence.
+// Clear-cut overbilling is refunded immediately; everything else is queued
+// for support with an SLA deadline. A ride whose dispute was upheld cannot
+// be disputed again, nor one with a dispute still open.
+func (s *DisputeService) OpenDispute(ctx context.Context, rideID, riderID uuid.UUID, reason domain.DisputeReason, comment string) (*domain.FareDispute, error) {
+	if !reason.IsValid() {
+		return nil, domain.ErrDisputeInvalidReason
+	}
+
+	ride, err := s.rideService.GetRide(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	if ride.RiderID != riderID {
+		return nil, domain.ErrForbidden
+	}
+	if ride.Status != domain.RideStatusCompleted || ride.Price == nil {
+		return nil, domain.ErrRideNotDisputable
+	}
+
+	now := time.Now().UTC()
+	if ride.CompletedAt != nil && now.Sub(*ride.CompletedAt) > domain.DisputeWindow {
+		return nil, domain.ErrDisputeWindowClosed
+	}
+
+	// An upheld dispute has already been refunded; another would refund the
+	// same difference again
+	resolved, err := s.disputeRepo.HasResolved(ctx, ride.ID)
+	if err != nil {
+		return nil, err
+	}
+	if resolved {
+		return nil, domain.ErrDisputeAlreadyResolved
+	}
+
+	// One dispute per ride is open at a time; the unique index on open
+	// disputes refuses a concurrent second one as it is stored
+	latest, err := s.disputeRepo.GetLatestByRide(ctx, ride.ID)
+	if err != nil && !errors.Is(err, domain.ErrDisputeNotFound) {
+		return nil, err
+	}
+	if latest != nil && latest.Status == domain.DisputeStatusOpen {
+		return nil, domain.ErrDisputeAlreadyOpen
+	}
+
+	dispute := &domain.FareDispute{
+		ID:        uuid.New(),
+		RideID:    ride.ID,
+		RiderID:   riderID,
+		Reason:    reason,
+		Comment:   comment,
+		Status:    domain.DisputeStatusOpen,
+		Evidence:  s.gatherEvidence(ctx, ride),
+		SLADueAt:  now.Add(domain.DisputeSLA),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.disputeRepo.Create(ctx, dispute); err != nil {
+		return nil, err
+	}
+
+	s.rideService.RecordEvent(ctx, domain.NewRideEvent(ride.ID, domain.RideEventFareDisputed).
+		WithActor(riderID).
+		WithData("dispute_id", dispute.ID).
+		WithData("reason", reason))
+
+	if reason.ReportsDriver() {
+		blockReported(ctx, s.prefs, ride, "Fare dispute: "+string(reason))
+	}
+
+	if dispute.Evidence.IsClearOverbilling() {
+		s.autoResolve(ctx, dispute)
+	}
+
+	log.Info().
+		Str("dispute_id", dispute.ID.String()).
+		Str("ride_id", ride.ID.String()).
+		Str("status", string(dispute.Status)).
+		Msg("Fare dispute opened")
+
+	return dispute, nil
+}
+
+// GetRideDispute gets the latest dispute for a ride
+func (s *DisputeService) GetRideDispute(ctx context.Context, rideID uuid.UUID) (*domain.FareDispute, error) {
+	return s.disputeRepo.GetLatestByRide(ctx, rideID)
+}
+
+// ListQueue lists disputes in a status, most urgent first
+func (s *DisputeService) ListQueue(ctx context.Context, status domain.DisputeStatus, limit, offset int) ([]*domain.FareDispute, int64, error) {
+	return s.disputeRepo.ListByStatus(ctx, status, limit, offset)
+}
+
+// ResolveDispute records a support agent's decision, refunding the rider
+// when the dispute is upheld with an amount. Until the refund completes the
+// dispute stays open with it linked; a refund the provider failed is
+// reported as the error.
+func (s *DisputeService) ResolveDispute(ctx context.Context, disputeID, agentID uuid.UUID, res *DisputeResolution) (*domain.FareDispute, error) {
+	dispute, err := s.disputeRepo.GetByID(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Status != domain.DisputeStatusOpen {
+		return nil, domain.ErrDisputeNotOpen
+	}
+
+	status := domain.DisputeStatusRejected
+	if res.Upheld {
+		status = domain.DisputeStatusResolved
+
+		// A dispute already linked to a refund is closed on it rather than
+		// refunded twice
+		if res.RefundAmount > 0 && dispute.RefundID == nil {
+			refund, err := s.refundService.RequestRefund(ctx, &RefundRequest{
+				RideID:      dispute.RideID,
+				Type:        domain.RefundTypePartial,
+				Reason:      domain.RefundReasonFareDispute,
+				Note:        "Dispute " + dispute.ID.String(),
+				Amount:      res.RefundAmount,
+				RequestedBy: agentID,
+			})
+			if refund == nil {
+				return nil, err
+			}
+			dispute.RefundID = &refund.ID
+			if !s.refundSettled(ctx, dispute, refund, err) {
+				if refund.Status == domain.RefundStatusFailed {
+					return nil, err
+				}
+				return dispute, nil
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	dispute.Status = status
+	dispute.Resolution = res.Note
+	dispute.ResolvedBy = &agentID
+	dispute.ResolvedAt = &now
+
+	if err := s.disputeRepo.Resolve(ctx, dispute); err != nil {
+		return nil, err
+	}
+
+	return dispute, nil
+}
+
+// autoResolve refunds the fare difference for clear-cut overbilling. Unless
+// the refund completes, e.g. it waits for a second approver or the payment
+// provider failed it, the dispute stays queued for support with the refund
+// linked.
+func (s *DisputeService) autoResolve(ctx context.Context, dispute *domain.FareDispute) {
+	if s.refundService == nil {
+		return
+	}
+
+	evidence := dispute.Evidence
+	refund, err := s.refundService.RequestRefund(ctx, &RefundRequest{
+		RideID:      dispute.RideID,
+		Type:        domain.RefundTypePartial,
+		Reason:      domain.RefundReasonRouteDeviation,
+		Note:        "Auto-resolved dispute " + dispute.ID.String(),
+		Amount:      evidence.FareDifference,
+		RequestedBy: uuid.Nil, // system
+	})
+	if refund == nil {
+		log.Warn().Err(err).Str("dispute_id", dispute.ID.String()).Msg("Dispute auto-resolution refund failed; leaving for support")
+		return
+	}
+
+	dispute.RefundID = &refund.ID
+	if !s.refundSettled(ctx, dispute, refund, err) {
+		return
+	}
+
+	now := time.Now().UTC()
+	dispute.Status = domain.DisputeStatusAutoResolved
+	dispute.ResolvedAt = &now
+	dispute.Resolution = fmt.Sprintf(
+		"Billed distance %.0fm exceeded GPS distance %.0fm by %.0f%%; refunded fare difference",
+		evidence.BilledDistanceM, evidence.ActualDistanceM, evidence.DistanceOverPct,
+	)
+
+	if err := s.disputeRepo.Resolve(ctx, dispute); err != nil {
+		log.Error().Err(err).Str("dispute_id", dispute.ID.String()).Msg("Failed to record dispute auto-resolution")
+	}
+}
+
+// refundSettled reports whether a dispute's refund completed. A refund
+// waiting for a second approver, or failed by the payment provider, is
+// linked to the dispute, which stays open for support.
+func (s *DisputeService) refundSettled(ctx context.Context, dispute *domain.FareDispute, refund *domain.Refund, err error) bool {
+	if refund.Status == domain.RefundStatusCompleted {
+		return true
+	}
+
+	log.Warn().Err(err).
+		Str("dispute_id", dispute.ID.String()).
+		Str("refund_id", refund.ID.String()).
+		Str("refund_status", string(refund.Status)).
+		Msg("Dispute refund not completed; leaving for support")
+	if err := s.disputeRepo.LinkRefund(ctx, dispute); err != nil {
+		log.Error().Err(err).Str("dispute_id", dispute.ID.String()).Msg("Failed to link refund to dispute")
+	}
+	return false
+}
+
+// gatherEvidence compares what was billed, less any refunds, against the
+// recorded GPS trail and reprices the trip using the distance and time
+// actually driven
+func (s *DisputeService) gatherEvidence(ctx context.Context, ride *domain.Ride) *domain.DisputeEvidence {
+	evidence := &domain.DisputeEvidence{
+		Trail:          []domain.TrailPoint{},
+		BilledFare:     ride.Price.Total,
+		RecomputedFare: ride.Price.Total,
+		Currency:       ride.Price.Currency,
+		StraightLineM: geo.HaversineDistance(
+			ride.PickupLocation.Latitude, ride.PickupLocation.Longitude,
+			ride.DropoffLocation.Latitude, ride.DropoffLocation.Longitude,
+		),
+	}
+
+	if ride.Route != nil {
+		evidence.BilledDistanceM = float64(ride.Route.DistanceMeters)
+		evidence.BilledDurationS = ride.Route.DurationSeconds
+	}
+	if ride.StartedAt != nil && ride.CompletedAt != nil {
+		evidence.ActualDurationS = int64(ride.CompletedAt.Sub(*ride.StartedAt).Seconds())
+	}
+
+	if s.refundService != nil {
+		refunded, err := s.refundService.TotalRefunded(ctx, ride.ID)
+		if err != nil {
+			log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to load refunds for dispute")
+		}
+		evidence.RefundedFare = refunded
+	}
+
+	if s.trails != nil {
+		trail, err := s.trails.GetLocationTrail(ctx, ride.ID)
+		if err != nil {
+			log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to load GPS trail for dispute")
+		} else {
+			evidence.Trail = trail
+		}
+	}
+
+	for i := 1; i < len(evidence.Trail); i++ {
+		prev, cur := evidence.Trail[i-1], evidence.Trail[i]
+		evidence.ActualDistanceM += geo.HaversineDistance(prev.Latitude, prev.Longitude, cur.Latitude, cur.Longitude)
+	}
+
+	// Only reprice when the trail is dense enough to trust
+	if len(evidence.Trail) >= domain.DisputeMinTrailPoints && evidence.ActualDistanceM > 0 {
+		if evidence.BilledDistanceM > 0 {
+			evidence.DistanceOverPct = (evidence.BilledDistanceM/evidence.ActualDistanceM - 1) * 100
+		}
+
+		durationS := evidence.ActualDurationS
+		if durationS == 0 {
+			durationS = evidence.BilledDurationS
+		}
+
+		recomputed := s.pricingEngine.RecalculatePrice(ride.Price, ride.Type, evidence.ActualDistanceM, durationS)
+		evidence.RecomputedFare = recomputed.Total
+		// What was already refunded is not overbilled any more
+		if diff := evidence.BilledFare - evidence.RefundedFare - recomputed.Total; diff > 0 {
+			evidence.FareDifference = diff
+		}
+	}
+
+	return evidence
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/payment"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/pricing"
+)
+
+// fakeDisputeStore keeps disputes in memory, refusing a second open dispute
+// on a ride as the unique index does
+type fakeDisputeStore struct {
+	disputes map[uuid.UUID]*domain.FareDispute
+}
+
+func newFakeDisputeStore() *fakeDisputeStore {
+	return &fakeDisputeStore{disputes: make(map[uuid.UUID]*domain.FareDispute)}
+}
+
+func (f *fakeDisputeStore) Create(ctx context.Context, dispute *domain.FareDispute) error {
+	for _, d := range f.disputes {
+		if d.RideID == dispute.RideID && d.Status == domain.DisputeStatusOpen {
+			return domain.ErrDisputeAlreadyOpen
+		}
+	}
+	stored := *dispute
+	f.disputes[dispute.ID] = &stored
+	return nil
+}
+
+func (f *fakeDisputeStore) GetByID(ctx context.Context, id uuid.UUID) (*domain.FareDispute, error) {
+	dispute, ok := f.disputes[id]
+	if !ok {
+		return nil, domain.ErrDisputeNotFound
+	}
+	stored := *dispute
+	return &stored, nil
+}
+
+func (f *fakeDisputeStore) GetLatestByRide(ctx context.Context, rideID uuid.UUID) (*domain.FareDispute, error) {
+	var latest *domain.FareDispute
+	for _, d := range f.disputes {
+		if d.RideID == rideID && (latest == nil || d.CreatedAt.After(latest.CreatedAt)) {
+			latest = d
+		}
+	}
+	if latest == nil {
+		return nil, domain.ErrDisputeNotFound
+	}
+	stored := *latest
+	return &stored, nil
+}
+
+func (f *fakeDisputeStore) LinkRefund(ctx context.Context, dispute *domain.FareDispute) error {
+	stored, ok := f.disputes[dispute.ID]
+	if !ok || stored.Status != domain.DisputeStatusOpen {
+		return domain.ErrDisputeNotOpen
+	}
+	stored.RefundID = dispute.RefundID
+	return nil
+}
+
+func (f *fakeDisputeStore) HasResolved(ctx context.Context, rideID uuid.UUID) (bool, error) {
+	for _, d := range f.disputes {
+		if d.RideID == rideID && (d.Status == domain.DisputeStatusResolved || d.Status == domain.DisputeStatusAutoResolved) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeDisputeStore) ListByStatus(ctx context.Context, status domain.DisputeStatus, limit, offset int) ([]*domain.FareDispute, int64, error) {
+	var disputes []*domain.FareDispute
+	for _, d := range f.disputes {
+		if d.Status == status {
+			disputes = append(disputes, d)
+		}
+	}
+	return disputes, int64(len(disputes)), nil
+}
+
+func (f *fakeDisputeStore) Resolve(ctx context.Context, dispute *domain.FareDispute) error {
+	stored, ok := f.disputes[dispute.ID]
+	if !ok || stored.Status != domain.DisputeStatusOpen {
+		return domain.ErrDisputeNotOpen
+	}
+	*stored = *dispute
+	return nil
+}
+
+// fakeTrails serves the same GPS trail for every ride
+type fakeTrails []domain.TrailPoint
+
+func (f fakeTrails) GetLocationTrail(ctx context.Context, rideID uuid.UUID) ([]domain.TrailPoint, error) {
+	return f, nil
+}
+
+// drivenTrail is a trail of evenly spaced fixes covering distanceM heading north
+func drivenTrail(distanceM float64) fakeTrails {
+	trail := make(fakeTrails, domain.DisputeMinTrailPoints)
+	start := time.Now().Add(-time.Hour)
+	for i := range trail {
+		trail[i] = domain.TrailPoint{
+			Latitude:   -1.2921 + distanceM/111320*float64(i)/float64(len(trail)-1),
+			Longitude:  36.8219,
+			RecordedAt: start.Add(time.Duration(i) * 2 * time.Minute),
+		}
+	}
+	return trail
+}
+
+// disputeTest is a completed ride billed for 12km with the services that
+// handle its disputes
+type disputeTest struct {
+	ride     *domain.Ride
+	refunds  *fakeRefundStore
+	payments *fakePayments
+	disputes *fakeDisputeStore
+	service  *DisputeService
+}
+
+func newDisputeTest(t *testing.T, drivenM float64) *disputeTest {
+	t.Helper()
+	ride := newRefundTestRide(150000)
+	ride.Type = domain.RideTypeStandard
+	ride.Route = &domain.RouteInfo{DistanceMeters: 12000, DurationSeconds: 1200}
+	startedAt, completedAt := time.Now().Add(-80*time.Minute), time.Now().Add(-time.Hour)
+	ride.StartedAt, ride.CompletedAt = &startedAt, &completedAt
+
+	rides := &fakeRefundRides{ride: ride}
+	refunds := newFakeRefundStore()
+	payments := newFakePayments()
+	payments.captured[ride.ID.String()] = &payment.Payment{ID: "pay_1", ReferenceID: ride.ID.String(), Status: "COMPLETED"}
+	disputes := newFakeDisputeStore()
+	return &disputeTest{
+		ride:     ride,
+		refunds:  refunds,
+		payments: payments,
+		disputes: disputes,
+		service: NewDisputeService(rides, drivenTrail(drivenM), disputes,
+			NewRefundService(rides, refunds, payments), pricing.NewEngine()),
+	}
+}
+
+// refund stores a refund already issued on the ride
+func (d *disputeTest) refund(refundType domain.RefundType, amount int64) {
+	d.refunds.refunds[uuid.New()] = &domain.Refund{
+		RideID: d.ride.ID,
+		Type:   refundType,
+		Amount: amount,
+		Status: domain.RefundStatusCompleted,
+	}
+}
+
+func (d *disputeTest) open(t *testing.T) *domain.FareDispute {
+	t.Helper()
+	dispute, err := d.service.OpenDispute(context.Background(), d.ride.ID, d.ride.RiderID, domain.DisputeReasonLongRoute, "")
+	if err != nil {
+		t.Fatalf("OpenDispute() error = %v", err)
+	}
+	return dispute
+}
+
+func TestOpenDisputeNetsOutRefunds(t *testing.T) {
+	d := newDisputeTest(t, 5000)
+	d.refund(domain.RefundTypePartial, 20000)
+	d.refund(domain.RefundTypeGoodwillCredit, 10000)
+
+	evidence := d.open(t).Evidence
+	if evidence.RefundedFare != 20000 {
+		t.Errorf("refunded fare = %d, want 20000 without the goodwill credit", evidence.RefundedFare)
+	}
+	if want := evidence.BilledFare - evidence.RefundedFare - evidence.RecomputedFare; evidence.FareDifference != want || want <= 0 {
+		t.Errorf("fare difference = %d, want %d (billed %d less refunded %d and recomputed %d)",
+			evidence.FareDifference, want, evidence.BilledFare, evidence.RefundedFare, evidence.RecomputedFare)
+	}
+}
+
+func TestOpenDisputeWithFareRefunded(t *testing.T) {
+	// Nothing is left to refund, so the dispute waits for support
+	d := newDisputeTest(t, 5000)
+	d.refund(domain.RefundTypePartial, 150000)
+
+	dispute := d.open(t)
+	if dispute.Evidence.FareDifference != 0 || dispute.Status != domain.DisputeStatusOpen || dispute.RefundID != nil {
+		t.Errorf("dispute = %s with difference %d, want open without a refund", dispute.Status, dispute.Evidence.FareDifference)
+	}
+}
+
+func TestOpenDisputeAutoResolves(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(d *disputeTest)
+		wantStatus domain.DisputeStatus
+		wantRefund domain.RefundStatus
+	}{
+		{"refund completed", func(d *disputeTest) {}, domain.DisputeStatusAutoResolved, domain.RefundStatusCompleted},
+		{"refund needs approval", func(d *disputeTest) {
+			// Earlier credits push the refund over the approval threshold
+			d.refund(domain.RefundTypeGoodwillCredit, 190000)
+		}, domain.DisputeStatusOpen, domain.RefundStatusPendingApproval},
+		{"refund failed", func(d *disputeTest) {
+			delete(d.payments.captured, d.ride.ID.String())
+		}, domain.DisputeStatusOpen, domain.RefundStatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDisputeTest(t, 5000)
+			tt.setup(d)
+
+			dispute := d.open(t)
+			if dispute.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", dispute.Status, tt.wantStatus)
+			}
+			if dispute.RefundID == nil {
+				t.Fatal("no refund linked to the dispute")
+			}
+			if refund := d.refunds.refunds[*dispute.RefundID]; refund.Status != tt.wantRefund {
+				t.Errorf("refund status = %s, want %s", refund.Status, tt.wantRefund)
+			}
+			stored := d.disputes.disputes[dispute.ID]
+			if stored.Status != tt.wantStatus || stored.RefundID == nil || *stored.RefundID != *dispute.RefundID {
+				t.Errorf("stored dispute = %s with refund %v, want %s with the refund linked", stored.Status, stored.RefundID, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestOpenDisputeOnePerRide(t *testing.T) {
+	// Driven as billed, so the first dispute is queued for support
+	d := newDisputeTest(t, 12000)
+	first := d.open(t)
+	if first.Status != domain.DisputeStatusOpen {
+		t.Fatalf("status = %s, want OPEN", first.Status)
+	}
+
+	_, err := d.service.OpenDispute(context.Background(), d.ride.ID, d.ride.RiderID, domain.DisputeReasonLongRoute, "")
+	if !errors.Is(err, domain.ErrDisputeAlreadyOpen) {
+		t.Errorf("second OpenDispute() error = %v, want ErrDisputeAlreadyOpen", err)
+	}
+
+	if _, err := d.service.ResolveDispute(context.Background(), first.ID, uuid.New(), &DisputeResolution{Upheld: true}); err != nil {
+		t.Fatalf("ResolveDispute() error = %v", err)
+	}
+	_, err = d.service.OpenDispute(context.Background(), d.ride.ID, d.ride.RiderID, domain.DisputeReasonLongRoute, "")
+	if !errors.Is(err, domain.ErrDisputeAlreadyResolved) {
+		t.Errorf("OpenDispute() after resolution error = %v, want ErrDisputeAlreadyResolved", err)
+	}
+}
+
+func TestResolveDispute(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(d *disputeTest)
+		wantErr    error
+		wantStatus domain.DisputeStatus
+		wantRefund domain.RefundStatus
+	}{
+		{"refund completed", func(d *disputeTest) {}, nil, domain.DisputeStatusResolved, domain.RefundStatusCompleted},
+		{"refund needs approval", func(d *disputeTest) {
+			d.refund(domain.RefundTypeGoodwillCredit, 190000)
+		}, nil, domain.DisputeStatusOpen, domain.RefundStatusPendingApproval},
+		{"refund failed", func(d *disputeTest) {
+			delete(d.payments.captured, d.ride.ID.String())
+		}, domain.ErrRidePaymentNotFound, domain.DisputeStatusOpen, domain.RefundStatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDisputeTest(t, 12000)
+			tt.setup(d)
+			opened := d.open(t)
+
+			resolved, err := d.service.ResolveDispute(context.Background(), opened.ID, uuid.New(), &DisputeResolution{
+				Upheld:       true,
+				RefundAmount: 30000,
+				Note:         "Driver took a longer route",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResolveDispute() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && resolved.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", resolved.Status, tt.wantStatus)
+			}
+
+			stored := d.disputes.disputes[opened.ID]
+			if stored.Status != tt.wantStatus {
+				t.Errorf("stored status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+			if stored.RefundID == nil {
+				t.Fatal("no refund linked to the dispute")
+			}
+			if refund := d.refunds.refunds[*stored.RefundID]; refund.Status != tt.wantRefund || refund.Amount != 30000 {
+				t.Errorf("refund = %d %s, want 30000 %s", refund.Amount, refund.Status, tt.wantRefund)
+			}
+		})
+	}
+}
+
+func TestResolveDisputeWithRefundLinked(t *testing.T) {
+	// Once the pending refund is approved the agent closes the dispute on
+	// it without refunding again
+	d := newDisputeTest(t, 12000)
+	d.refund(domain.RefundTypeGoodwillCredit, 190000)
+	opened := d.open(t)
+	resolution := &DisputeResolution{Upheld: true, RefundAmount: 30000}
+	if _, err := d.service.ResolveDispute(context.Background(), opened.ID, uuid.New(), resolution); err != nil {
+		t.Fatalf("ResolveDispute() error = %v", err)
+	}
+
+	resolved, err := d.service.ResolveDispute(context.Background(), opened.ID, uuid.New(), resolution)
+	if err != nil {
+		t.Fatalf("second ResolveDispute() error = %v", err)
+	}
+	if resolved.Status != domain.DisputeStatusResolved {
+		t.Errorf("status = %s, want RESOLVED", resolved.Status)
+	}
+	disputeRefunds := 0
+	for _, refund := range d.refunds.refunds {
+		if refund.Type == domain.RefundTypePartial {
+			disputeRefunds++
+		}
+	}
+	if disputeRefunds != 1 {
+		t.Errorf("dispute refunds = %d, want 1", disputeRefunds)
+	}
+}
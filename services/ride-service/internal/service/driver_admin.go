@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+const (
+	// driverProfileTrips, driverProfileIncidents and driverProfileActions
+	// are how many of each a driver profile shows
+	driverProfileTrips     = 10
+	driverProfileIncidents = 10
+	driverProfileActions   = 20
+
+	// driverStatsWindow is how far back a driver profile's trip stats go
+	driverStatsWindow = 30 * 24 * time.Hour
+)
+
+// DriverAdminService lets ops find drivers, see everything about one in a
+// single profile, and correct their record. Every correction is audited.
+type DriverAdminService struct {
+	driverRepo *repository.DriverRepository
+	rideRepo   *repository.RideRepository
+	safetyRepo *repository.SafetyIncidentRepository
+	driverPool *redis.DriverPool
+
+	// Optional profile sections, left out when their feature is off
+	standing  *DriverStandingService
+	quality   *DriverQualityService
+	documents *DriverDocumentService
+}
+
+// NewDriverAdminService creates a new driver admin service. The standing,
+// quality and document services may be nil.
+func NewDriverAdminService(
+	driverRepo *repository.DriverRepository,
+	rideRepo *repository.RideRepository,
+	safetyRepo *repository.SafetyIncidentRepository,
+	driverPool *redis.DriverPool,
+	standing *DriverStandingService,
+	quality *DriverQualityService,
+	documents *DriverDocumentService,
+) *DriverAdminService {
+	return &DriverAdminService{
+		driverRepo: driverRepo,
+		rideRepo:   rideRepo,
+		safetyRepo: safetyRepo,
+		driverPool: driverPool,
+		standing:   standing,
+		quality:    quality,
+		documents:  documents,
+	}
+}
+
+// SearchDrivers finds drivers by phone, plate, name, status or city
+func (s *DriverAdminService) SearchDrivers(ctx context.Context, search *domain.DriverSearch) ([]*domain.Driver, int64, error) {
+	if err := search.Normalize(); err != nil {
+		return nil, 0, err
+	}
+	return s.driverRepo.Search(ctx, search)
+}
+
+// GetProfile gets a driver's consolidated profile: their record and
+// vehicle, standing, quality tier, documents, recent trips and stats, SOS
+// incidents on their rides and the admin changes made to their record
+func (s *DriverAdminService) GetProfile(ctx context.Context, driverID uuid.UUID) (*domain.DriverProfile, error) {
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	profile := &domain.DriverProfile{Driver: driver}
+
+	if s.standing != nil {
+		if profile.Standing, err = s.standing.GetStanding(ctx, driverID); err != nil {
+			return nil, err
+		}
+	}
+	if s.quality != nil {
+		if profile.Quality, err = s.quality.GetDriverQuality(ctx, driverID); err != nil {
+			return nil, err
+		}
+	}
+	if s.documents != nil {
+		if profile.Documents, err = s.documents.ListDocuments(ctx, driverID); err != nil {
+			return nil, err
+		}
+	}
+
+	if profile.Stats, err = s.rideRepo.GetDriverTripStats(ctx, driverID, time.Now().UTC().Add(-driverStatsWindow)); err != nil {
+		return nil, err
+	}
+	if profile.RecentTrips, err = s.rideRepo.ListRecentByDriver(ctx, driverID, driverProfileTrips); err != nil {
+		return nil, err
+	}
+	if profile.Incidents, err = s.safetyRepo.ListByDriver(ctx, driverID, driverProfileIncidents); err != nil {
+		return nil, err
+	}
+	if profile.AdminActions, err = s.driverRepo.ListAdminActions(ctx, driverID, driverProfileActions); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// UpdateVehicle corrects a driver's active vehicle. A changed vehicle type
+// changes the ride types the driver is matched to.
+func (s *DriverAdminService) UpdateVehicle(ctx context.Context, driverID, adminID uuid.UUID, update *domain.VehicleUpdate, reason string) (*domain.Vehicle, error) {
+	audit, err := domain.NewDriverAdminAudit(driverID, adminID, domain.DriverAdminUpdateVehicle, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if driver.Vehicle == nil {
+		return nil, domain.ErrVehicleNotFound
+	}
+
+	vehicle := driver.Vehicle
+	if audit.Changes, err = update.Apply(vehicle, audit.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := s.driverRepo.UpdateVehicle(ctx, vehicle, audit); err != nil {
+		return nil, err
+	}
+
+	s.logAction(audit)
+	return vehicle, nil
+}
+
+// ResetStatus takes a driver left on a ride that has ended off it and
+// offline, so they can go online again
+func (s *DriverAdminService) ResetStatus(ctx context.Context, driverID, adminID uuid.UUID, reason string) (*domain.Driver, error) {
+	return s.takeOffline(ctx, driverID, adminID, domain.DriverAdminResetStatus, reason, (*domain.Driver).CheckStatusReset)
+}
+
+// ForceOffline takes an online driver who is not on a ride out of matching
+func (s *DriverAdminService) ForceOffline(ctx context.Context, driverID, adminID uuid.UUID, reason string) (*domain.Driver, error) {
+	return s.takeOffline(ctx, driverID, adminID, domain.DriverAdminForceOffline, reason, (*domain.Driver).CheckForceOffline)
+}
+
+// takeOffline sets a driver offline once check allows it, in the database
+// and then in the matching pool
+func (s *DriverAdminService) takeOffline(
+	ctx context.Context,
+	driverID, adminID uuid.UUID,
+	action domain.DriverAdminAction,
+	reason string,
+	check func(*domain.Driver, bool) error,
+) (*domain.Driver, error) {
+	audit, err := domain.NewDriverAdminAudit(driverID, adminID, action, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	active, err := s.rideRepo.GetActiveByDriver(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(driver, active != nil); err != nil {
+		return nil, err
+	}
+
+	audit.Changes = driver.OfflineChanges()
+	if err := s.driverRepo.TakeOffline(ctx, driverID, driver.Status, audit); err != nil {
+		return nil, err
+	}
+
+	// Drop the driver's position, status and any matching lock so they are
+	// offered no more rides
+	if s.driverPool != nil {
+		if err := s.driverPool.RemoveDriver(ctx, driverID); err != nil {
+			log.Error().Err(err).Str("driver_id", driverID.String()).Msg("Failed to remove driver from Redis pool")
+		}
+	}
+
+	s.logAction(audit)
+	driver.SetOffline()
+	return driver, nil
+}
+
+func (s *DriverAdminService) logAction(audit *domain.DriverAdminAudit) {
+	log.Info().
+		Str("driver_id", audit.DriverID.String()).
+		Str("action", string(audit.Action)).
+		Str("admin_id", audit.AdminID.String()).
+		Str("reason", audit.Reason).
+		Int("changes", len(audit.Changes)).
+		Msg("Driver record changed by admin")
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/notification"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+const (
+	// Maximum documents reminded or enforced per expiry run
+	documentExpiryBatchSize = 500
+
+	// Maximum rows in the expiring-documents report
+	expiringDocumentReportLimit = 1000
+
+	// documentEnforcementLookback is how long after their grace ends
+	// lapsed documents keep being enforced, covering runs the job missed
+	documentEnforcementLookback = 2 * 24 * time.Hour
+)
+
+var documentNames = map[domain.DocumentType]string{
+	domain.DocumentDriversLicense:      "driver's licence",
+	domain.DocumentVehicleInsurance:    "vehicle insurance",
+	domain.DocumentVehicleRegistration: "vehicle registration",
+	domain.DocumentRoadworthiness:      "roadworthiness certificate",
+	domain.DocumentOperatorPermit:      "operator permit",
+}
+
+// DriverDocumentService tracks the expiry of drivers' licences and permits,
+// reminds drivers to renew them and keeps drivers with lapsed documents
+// offline
+type DriverDocumentService struct {
+	docRepo    *repository.DriverDocumentRepository
+	driverRepo *repository.DriverRepository
+	driverPool *redis.DriverPool
+	notifier   Notifier
+	grace      domain.DocumentGracePeriods
+}
+
+// NewDriverDocumentService creates a new driver document service. notifier
+// may be nil, in which case no reminders are sent.
+func NewDriverDocumentService(
+	docRepo *repository.DriverDocumentRepository,
+	driverRepo *repository.DriverRepository,
+	driverPool *redis.DriverPool,
+	notifier Notifier,
+	grace domain.DocumentGracePeriods,
+) *DriverDocumentService {
+	if grace == nil {
+		grace = domain.DefaultDocumentGracePeriods
+	}
+	return &DriverDocumentService{
+		docRepo:    docRepo,
+		driverRepo: driverRepo,
+		driverPool: driverPool,
+		notifier:   notifier,
+		grace:      grace,
+	}
+}
+
+// RecordDocument records a driver's verified document, replacing the one
+// of the same type it renews
+func (s *DriverDocumentService) RecordDocument(ctx context.Context, doc *domain.DriverDocument) error {
+	doc.Country = strings.ToUpper(strings.TrimSpace(doc.Country))
+	doc.City = strings.ToUpper(strings.TrimSpace(doc.City))
+	if doc.DriverID == uuid.Nil || !doc.Type.IsValid() || len(doc.Country) != 2 || doc.ExpiresAt.IsZero() {
+		return domain.ErrInvalidDriverDocument
+	}
+
+	now := time.Now().UTC()
+	doc.ID = uuid.New()
+	doc.CreatedAt = now
+	doc.UpdatedAt = now
+	return s.docRepo.Save(ctx, doc)
+}
+
+// ListDocuments lists a driver's documents with where each stands
+func (s *DriverDocumentService) ListDocuments(ctx context.Context, driverID uuid.UUID) ([]*domain.ExpiringDocument, error) {
+	docs, err := s.docRepo.ListByDriver(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withStatus(docs, time.Now().UTC()), nil
+}
+
+// IsCompliant reports whether none of a driver's documents has lapsed
+// past its grace period
+func (s *DriverDocumentService) IsCompliant(ctx context.Context, driverID uuid.UUID) (bool, error) {
+	docs, err := s.docRepo.ListByDriver(ctx, driverID)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now().UTC()
+	for _, doc := range docs {
+		if !doc.PermitsWork(now, s.grace.GracePeriod(doc.Country, doc.Type)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ExpiringReport lists a market's documents expiring within the given
+// time, and those already expired, for city ops to chase renewals. An
+// empty city covers the whole market.
+func (s *DriverDocumentService) ExpiringReport(ctx context.Context, country, city string, within time.Duration) ([]*domain.ExpiringDocument, error) {
+	now := time.Now().UTC()
+	docs, err := s.docRepo.ListExpiringBefore(ctx,
+		strings.ToUpper(country), strings.ToUpper(city), now.Add(within), expiringDocumentReportLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s.withStatus(docs, now), nil
+}
+
+func (s *DriverDocumentService) withStatus(docs []*domain.DriverDocument, now time.Time) []*domain.ExpiringDocument {
+	rows := make([]*domain.ExpiringDocument, 0, len(docs))
+	for _, doc := range docs {
+		grace := s.grace.GracePeriod(doc.Country, doc.Type)
+		graceEndsAt := doc.ExpiresAt.Add(grace)
+		daysLeft := int(graceEndsAt.Sub(now).Hours() / 24)
+		if daysLeft < 0 {
+			daysLeft = 0
+		}
+		rows = append(rows, &domain.ExpiringDocument{
+			DriverDocument: *doc,
+			Status:         doc.Status(now, grace),
+			GraceEndsAt:    graceEndsAt,
+			DaysLeft:       daysLeft,
+		})
+	}
+	return rows
+}
+
+// SendExpiryReminders reminds drivers whose documents expire within a
+// reminder's reach to renew them
+func (s *DriverDocumentService) SendExpiryReminders(ctx context.Context) (int, error) {
+	if s.notifier == nil {
+		return 0, nil
+	}
+
+	now := time.Now().UTC()
+	docs, err := s.docRepo.ListExpiringBetween(ctx, now, now.AddDate(0, 0, domain.DocumentExpiryReminders[0]), documentExpiryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, doc := range docs {
+		days, due := doc.DueReminder(now)
+		if !due {
+			continue
+		}
+
+		driver, err := s.driverRepo.GetByID(ctx, doc.DriverID)
+		if err != nil {
+			log.Error().Err(err).Str("driver_id", doc.DriverID.String()).Msg("Failed to load driver for document reminder")
+			continue
+		}
+
+		name := documentNames[doc.Type]
+		body := fmt.Sprintf("Your %s expires in %d days. Upload the renewed document to keep driving.", name, days)
+		priority := notification.PriorityNormal
+		if days == 1 {
+			body = fmt.Sprintf("Your %s expires tomorrow. Upload the renewed document to keep driving.", name)
+			priority = notification.PriorityHigh
+		}
+		if grace := s.grace.GracePeriod(doc.Country, doc.Type); grace > 0 {
+			body += fmt.Sprintf(" You have %d days' grace after it expires.", int(grace.Hours()/24))
+		}
+
+		err = s.notifier.SendPush(ctx, driver.UserID, "Document expiring", body, priority, map[string]string{
+			"type":          "driver_document_expiry",
+			"document_type": string(doc.Type),
+			"expires_at":    doc.ExpiresAt.Format(time.RFC3339),
+			"days_left":     fmt.Sprint(days),
+		})
+		if err != nil {
+			log.Error().Err(err).Str("driver_id", doc.DriverID.String()).Msg("Failed to send document expiry reminder")
+			continue
+		}
+		if err := s.docRepo.MarkReminded(ctx, doc.ID, days); err != nil {
+			log.Error().Err(err).Str("driver_id", doc.DriverID.String()).Msg("Failed to record document expiry reminder")
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// EnforceExpiry takes drivers offline once a document's grace period has
+// run out. Drivers mid-ride are left to finish and are blocked the next
+// time they try to go online.
+func (s *DriverDocumentService) EnforceExpiry(ctx context.Context) error {
+	now := time.Now().UTC()
+	var longestGrace time.Duration
+	for _, byType := range s.grace {
+		for _, grace := range byType {
+			if grace > longestGrace {
+				longestGrace = grace
+			}
+		}
+	}
+
+	docs, err := s.docRepo.ListExpiringBetween(ctx, now.Add(-longestGrace-documentEnforcementLookback), now, documentExpiryBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range docs {
+		if doc.PermitsWork(now, s.grace.GracePeriod(doc.Country, doc.Type)) {
+			continue
+		}
+		driver, err := s.driverRepo.GetByID(ctx, doc.DriverID)
+		if err != nil || driver.Status != domain.DriverStatusOnline {
+			continue
+		}
+
+		if err := s.driverRepo.UpdateStatus(ctx, doc.DriverID, domain.DriverStatusOffline); err != nil {
+			log.Error().Err(err).Str("driver_id", doc.DriverID.String()).Msg("Failed to take driver offline")
+			continue
+		}
+		if s.driverPool != nil {
+			if err := s.driverPool.RemoveDriver(ctx, doc.DriverID); err != nil {
+				log.Error().Err(err).Str("driver_id", doc.DriverID.String()).Msg("Failed to remove driver from pool")
+			}
+		}
+		log.Info().
+			Str("driver_id", doc.DriverID.String()).
+			Str("document_type", string(doc.Type)).
+			Msg("Driver taken offline: document expired")
+	}
+	return nil
+}
+
+// RunExpiry sends due expiry reminders and takes drivers whose documents
+// have lapsed offline
+func (s *DriverDocumentService) RunExpiry(ctx context.Context) error {
+	n, err := s.SendExpiryReminders(ctx)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Info().Int("sent", n).Msg("Sent document expiry reminders")
+	}
+	return s.EnforceExpiry(ctx)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// DriverPreferenceService keeps riders' favorite drivers, ranked ahead in
+// matching, and blocked drivers, who are never matched with them again.
+// Drivers a rider reports are blocked for them.
+type DriverPreferenceService struct {
+	rideService *RideService
+	prefRepo    *repository.DriverPreferenceRepository
+}
+
+// NewDriverPreferenceService creates a new driver preference service
+func NewDriverPreferenceService(rideService *RideService, prefRepo *repository.DriverPreferenceRepository) *DriverPreferenceService {
+	return &DriverPreferenceService{
+		rideService: rideService,
+		prefRepo:    prefRepo,
+	}
+}
+
+// FavoriteDriver favorites the driver of a rider's completed ride
+func (s *DriverPreferenceService) FavoriteDriver(ctx context.Context, rideID, riderID uuid.UUID) (*domain.DriverPreference, error) {
+	return s.mark(ctx, rideID, riderID, domain.DriverPreferenceFavorite, "")
+}
+
+// BlockDriver reports and blocks the driver of one of a rider's rides
+func (s *DriverPreferenceService) BlockDriver(ctx context.Context, rideID, riderID uuid.UUID, reason string) (*domain.DriverPreference, error) {
+	return s.mark(ctx, rideID, riderID, domain.DriverPreferenceBlocked, reason)
+}
+
+func (s *DriverPreferenceService) mark(ctx context.Context, rideID, riderID uuid.UUID, kind domain.DriverPreferenceKind, reason string) (*domain.DriverPreference, error) {
+	ride, err := s.rideService.GetRide(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	if ride.RiderID != riderID {
+		return nil, domain.ErrForbidden
+	}
+
+	pref, err := domain.NewDriverPreference(ride, kind, reason, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.prefRepo.Save(ctx, pref); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("rider_id", riderID.String()).
+		Str("driver_id", pref.DriverID.String()).
+		Str("kind", string(kind)).
+		Msg("Rider driver preference saved")
+
+	return pref, nil
+}
+
+// RemoveDriver unfavorites or unblocks a driver
+func (s *DriverPreferenceService) RemoveDriver(ctx context.Context, riderID, driverID uuid.UUID) error {
+	return s.prefRepo.Delete(ctx, riderID, driverID)
+}
+
+// ListDrivers lists a rider's favorite and blocked drivers, newest first
+func (s *DriverPreferenceService) ListDrivers(ctx context.Context, riderID uuid.UUID) ([]*domain.DriverPreference, error) {
+	return s.prefRepo.ListByRider(ctx, riderID)
+}
+
+// DriverPreferences gets the drivers a rider favorited and blocked, for
+// ranking and filtering matching candidates
+func (s *DriverPreferenceService) DriverPreferences(ctx context.Context, riderID uuid.UUID) (*domain.DriverPreferenceSet, error) {
+	prefs, err := s.prefRepo.ListByRider(ctx, riderID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewDriverPreferenceSet(prefs), nil
+}
+
+// IsBlocked reports whether a rider blocked a driver
+func (s *DriverPreferenceService) IsBlocked(ctx context.Context, riderID, driverID uuid.UUID) (bool, error) {
+	return s.prefRepo.IsBlocked(ctx, riderID, driverID)
+}
+
+// blockReported blocks a ride's driver for its rider after the rider
+// reported them, logging rather than failing on error. prefs may be nil.
+func blockReported(ctx context.Context, prefs *DriverPreferenceService, ride *domain.Ride, reason string) {
+	if prefs == nil || ride.DriverID == nil {
+		return
+	}
+	pref, err := domain.NewDriverPreference(ride, domain.DriverPreferenceBlocked, reason, time.Now().UTC())
+	if err == nil {
+		err = prefs.prefRepo.Save(ctx, pref)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to block reported driver")
+	}
+}
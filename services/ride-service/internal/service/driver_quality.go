@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// DriverQualityService runs the driver quality program. Each month's
+// evaluation scores drivers on the month before and places them in a tier;
+// the tiers are kept in memory on every replica so assigning a ride and
+// ranking drivers can apply the benefits without a query.
+type DriverQualityService struct {
+	qualityRepo *repository.DriverQualityRepository
+
+	mu    sync.RWMutex
+	tiers map[uuid.UUID]domain.QualityTier
+}
+
+// NewDriverQualityService creates a new driver quality service
+func NewDriverQualityService(qualityRepo *repository.DriverQualityRepository) *DriverQualityService {
+	return &DriverQualityService{
+		qualityRepo: qualityRepo,
+		tiers:       make(map[uuid.UUID]domain.QualityTier),
+	}
+}
+
+// Run evaluates the month that has just ended
+func (s *DriverQualityService) Run(ctx context.Context) error {
+	thisMonth, _ := domain.QualityPeriod(time.Now())
+	n, err := s.Evaluate(ctx, thisMonth.AddDate(0, -1, 0))
+	if n > 0 {
+		log.Info().Int("drivers", n).Msg("Evaluated driver quality")
+	}
+	return err
+}
+
+// Evaluate scores every driver active in the month containing period and
+// stores their tier. Drivers holding a tier who were not active are
+// evaluated too, so an idle month drops them back to Standard. Running a
+// month again replaces its evaluations.
+func (s *DriverQualityService) Evaluate(ctx context.Context, period time.Time) (int, error) {
+	from, to := domain.QualityPeriod(period)
+
+	metrics, err := s.qualityRepo.GatherMetrics(ctx, from, to, nil)
+	if err != nil {
+		return 0, err
+	}
+	previous, err := s.qualityRepo.ListTiers(ctx, from.Format(domain.QualityPeriodLayout))
+	if err != nil {
+		return 0, err
+	}
+	for driverID := range previous {
+		if _, ok := metrics[driverID]; !ok {
+			metrics[driverID] = &domain.QualityMetrics{}
+		}
+	}
+
+	now := time.Now().UTC()
+	evaluated := 0
+	for driverID, m := range metrics {
+		prev, ok := previous[driverID]
+		if !ok {
+			prev = domain.QualityTierStandard
+		}
+		quality := domain.EvaluateQuality(driverID, from, *m, prev, now)
+		if err := s.qualityRepo.Save(ctx, quality); err != nil {
+			return evaluated, err
+		}
+		evaluated++
+
+		if quality.Tier != prev {
+			log.Info().
+				Str("driver_id", driverID.String()).
+				Str("period", quality.Period).
+				Str("from", string(prev)).
+				Str("to", string(quality.Tier)).
+				Float64("score", quality.Scores.Composite).
+				Msg("Driver quality tier changed")
+		}
+	}
+
+	return evaluated, s.Refresh(ctx)
+}
+
+// Refresh reloads drivers' tiers into memory
+func (s *DriverQualityService) Refresh(ctx context.Context) error {
+	tiers, err := s.qualityRepo.ListTiers(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.tiers = tiers
+	s.mu.Unlock()
+	return nil
+}
+
+// Tier returns a driver's current tier
+func (s *DriverQualityService) Tier(driverID uuid.UUID) domain.QualityTier {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if tier, ok := s.tiers[driverID]; ok {
+		return tier
+	}
+	return domain.QualityTierStandard
+}
+
+// MatchingBoost returns the points a driver's tier adds to their matching
+// score
+func (s *DriverQualityService) MatchingBoost(driverID uuid.UUID) float64 {
+	return s.Tier(driverID).Benefits().MatchingBoost
+}
+
+// GetDriverQuality gets a driver's tier, the evaluation behind it and this
+// month's progress towards the next evaluation, with tips on improving it
+func (s *DriverQualityService) GetDriverQuality(ctx context.Context, driverID uuid.UUID) (*domain.QualityStatus, error) {
+	status := &domain.QualityStatus{Tier: domain.QualityTierStandard}
+
+	latest, err := s.qualityRepo.GetLatest(ctx, driverID)
+	switch err {
+	case nil:
+		status.Tier = latest.Tier
+		status.Evaluation = latest
+	case domain.ErrDriverQualityNotFound:
+	default:
+		return nil, err
+	}
+	status.Benefits = status.Tier.Benefits()
+
+	now := time.Now().UTC()
+	from, _ := domain.QualityPeriod(now)
+	metrics, err := s.qualityRepo.GatherMetrics(ctx, from, now, &driverID)
+	if err != nil {
+		return nil, err
+	}
+	m := domain.QualityMetrics{}
+	if found, ok := metrics[driverID]; ok {
+		m = *found
+	}
+
+	status.MonthToDate = domain.EvaluateQuality(driverID, from, m, status.Tier, now)
+	status.Tips = status.MonthToDate.Tips()
+	return status, nil
+}
+
+// discountCommission gives a ride's fare split the commission discount its
+// newly assigned driver's tier earns. quality may be nil.
+func discountCommission(ride *domain.Ride, quality *DriverQualityService) {
+	if quality == nil || ride.Price == nil || ride.DriverID == nil {
+		return
+	}
+	ride.Price = ride.Price.WithCommissionDiscount(quality.Tier(*ride.DriverID).Benefits().CommissionDiscount)
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// Maximum expired suspensions lifted per reinstatement run
+const reinstatementBatchSize = 100
+
+// DriverStandingService manages driver suspensions, deactivations, reviews
+// and appeals
+type DriverStandingService struct {
+	driverRepo   *repository.DriverRepository
+	sanctionRepo *repository.SanctionRepository
+	driverPool   *redis.DriverPool
+}
+
+// NewDriverStandingService creates a new driver standing service
+func NewDriverStandingService(
+	driverRepo *repository.DriverRepository,
+	sanctionRepo *repository.SanctionRepository,
+	driverPool *redis.DriverPool,
+) *DriverStandingService {
+	return &DriverStandingService{
+		driverRepo:   driverRepo,
+		sanctionRepo: sanctionRepo,
+		driverPool:   driverPool,
+	}
+}
+
+// SanctionDriver suspends, deactivates or places a driver under review.
+// A positive duration makes a suspension time-boxed.
+func (s *DriverStandingService) SanctionDriver(
+	ctx context.Context,
+	driverID, issuedBy uuid.UUID,
+	status domain.DriverStatus,
+	reason domain.SanctionReason,
+	note string,
+	duration time.Duration,
+) (*domain.DriverSanction, error) {
+	if _, err := s.driverRepo.GetByID(ctx, driverID); err != nil {
+		return nil, err
+	}
+
+	sanction, err := domain.NewDriverSanction(driverID, issuedBy, status, reason, note, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sanctionRepo.Create(ctx, sanction); err != nil {
+		return nil, err
+	}
+
+	// Pull the driver out of matching immediately
+	if s.driverPool != nil {
+		if err := s.driverPool.SetDriverRestriction(ctx, driverID, status); err != nil {
+			log.Error().Err(err).Str("driver_id", driverID.String()).Msg("Failed to set driver restriction in Redis")
+		}
+	}
+
+	log.Info().
+		Str("driver_id", driverID.String()).
+		Str("status", string(status)).
+		Str("reason", string(reason)).
+		Dur("duration", duration).
+		Msg("Driver sanctioned")
+
+	return sanction, nil
+}
+
+// ReinstateDriver lifts a driver's active sanction
+func (s *DriverStandingService) ReinstateDriver(ctx context.Context, driverID, liftedBy uuid.UUID, note string) error {
+	sanction, err := s.sanctionRepo.GetActive(ctx, driverID)
+	if err != nil {
+		return err
+	}
+
+	return s.lift(ctx, sanction, &liftedBy, note)
+}
+
+// GetStanding reports whether a driver may go online, with the sanction and
+// appeal that apply if not
+func (s *DriverStandingService) GetStanding(ctx context.Context, driverID uuid.UUID) (*domain.DriverStanding, error) {
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	standing := &domain.DriverStanding{
+		DriverID:    driverID,
+		Status:      driver.Status,
+		CanGoOnline: !driver.Status.IsRestricted(),
+	}
+
+	sanction, err := s.sanctionRepo.GetActive(ctx, driverID)
+	if err == domain.ErrDriverNotRestricted {
+		return standing, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	standing.ActiveSanction = sanction
+	standing.CanGoOnline = false
+
+	if appeal, err := s.sanctionRepo.GetPendingAppeal(ctx, sanction.ID); err == nil {
+		standing.PendingAppeal = appeal
+	}
+
+	return standing, nil
+}
+
+// ListSanctions lists a driver's sanction history
+func (s *DriverStandingService) ListSanctions(ctx context.Context, driverID uuid.UUID) ([]*domain.DriverSanction, error) {
+	return s.sanctionRepo.ListByDriver(ctx, driverID)
+}
+
+// SubmitAppeal lets a driver appeal their active sanction
+func (s *DriverStandingService) SubmitAppeal(ctx context.Context, driverID uuid.UUID, statement string) (*domain.DriverAppeal, error) {
+	statement = strings.TrimSpace(statement)
+	if statement == "" {
+		return nil, domain.ErrInvalidRequest
+	}
+
+	sanction, err := s.sanctionRepo.GetActive(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if !sanction.CanAppeal() {
+		return nil, domain.ErrAppealNotAllowed
+	}
+
+	appeal := &domain.DriverAppeal{
+		ID:         uuid.New(),
+		SanctionID: sanction.ID,
+		DriverID:   driverID,
+		Statement:  statement,
+		Status:     domain.AppealStatusPending,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	if err := s.sanctionRepo.CreateAppeal(ctx, appeal); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("driver_id", driverID.String()).
+		Str("appeal_id", appeal.ID.String()).
+		Msg("Driver appeal submitted")
+
+	return appeal, nil
+}
+
+// ListAppeals lists appeals in a status
+func (s *DriverStandingService) ListAppeals(ctx context.Context, status domain.AppealStatus, limit, offset int) ([]*domain.DriverAppeal, error) {
+	return s.sanctionRepo.ListAppeals(ctx, status, limit, offset)
+}
+
+// ReviewAppeal decides a pending appeal; upholding it reinstates the driver
+func (s *DriverStandingService) ReviewAppeal(ctx context.Context, appealID, reviewerID uuid.UUID, upheld bool, note string) (*domain.DriverAppeal, error) {
+	appeal, err := s.sanctionRepo.GetAppeal(ctx, appealID)
+	if err != nil {
+		return nil, err
+	}
+	if appeal.Status != domain.AppealStatusPending {
+		return nil, domain.ErrAppealNotPending
+	}
+
+	now := time.Now().UTC()
+	appeal.Status = domain.AppealStatusDenied
+	if upheld {
+		appeal.Status = domain.AppealStatusUpheld
+	}
+	appeal.ReviewedBy = &reviewerID
+	appeal.ReviewNote = note
+	appeal.ReviewedAt = &now
+
+	if err := s.sanctionRepo.ReviewAppeal(ctx, appeal); err != nil {
+		return nil, err
+	}
+
+	if upheld {
+		sanction, err := s.sanctionRepo.GetByID(ctx, appeal.SanctionID)
+		if err != nil {
+			return nil, err
+		}
+		if sanction.LiftedAt == nil {
+			if err := s.lift(ctx, sanction, &reviewerID, "Appeal upheld: "+note); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return appeal, nil
+}
+
+// ReinstateExpired lifts time-boxed suspensions whose period has ended
+func (s *DriverStandingService) ReinstateExpired(ctx context.Context) (int, error) {
+	expired, err := s.sanctionRepo.ListExpired(ctx, time.Now().UTC(), reinstatementBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	reinstated := 0
+	for _, sanction := range expired {
+		if err := s.lift(ctx, sanction, nil, "Suspension period ended"); err != nil {
+			log.Error().Err(err).Str("sanction_id", sanction.ID.String()).Msg("Failed to reinstate driver")
+			continue
+		}
+		reinstated++
+	}
+
+	return reinstated, nil
+}
+
+func (s *DriverStandingService) lift(ctx context.Context, sanction *domain.DriverSanction, liftedBy *uuid.UUID, note string) error {
+	if err := s.sanctionRepo.Lift(ctx, sanction.ID, liftedBy, note); err != nil {
+		return err
+	}
+
+	if s.driverPool != nil {
+		if err := s.driverPool.ClearDriverRestriction(ctx, sanction.DriverID); err != nil {
+			log.Error().Err(err).Str("driver_id", sanction.DriverID.String()).Msg("Failed to clear driver restriction in Redis")
+		}
+	}
+
+	log.Info().
+		Str("driver_id", sanction.DriverID.String()).
+		Str("sanction_id", sanction.ID.String()).
+		Msg("Driver reinstated")
+
+	return nil
+}
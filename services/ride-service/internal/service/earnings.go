@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/locale"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// DeliveryEarningsSource reports a driver's earnings from deliveries
+type DeliveryEarningsSource interface {
+	GetDriverEarnings(ctx context.Context, driverID uuid.UUID, from, to time.Time) ([]*domain.EarningsLine, error)
+}
+
+// EarningsService combines a driver's ride and delivery earnings into one
+// summary for the driver app home screen
+type EarningsService struct {
+	rideRepo   *repository.RideRepository
+	deliveries DeliveryEarningsSource
+}
+
+// NewEarningsService creates a new earnings service. deliveries may be nil,
+// in which case summaries cover rides only.
+func NewEarningsService(rideRepo *repository.RideRepository, deliveries DeliveryEarningsSource) *EarningsService {
+	return &EarningsService{
+		rideRepo:   rideRepo,
+		deliveries: deliveries,
+	}
+}
+
+// GetSummary gets a driver's earnings for the current day, week or month in
+// the request's timezone. Totals are shown in the request's currency, or the
+// currency the driver earned most in if the market is unknown. If the
+// delivery service is unreachable the summary covers rides only and says so.
+func (s *EarningsService) GetSummary(ctx context.Context, driverID uuid.UUID, period domain.EarningsPeriod) (*domain.EarningsSummary, error) {
+	loc := time.UTC
+	if l := locale.FromContext(ctx); l != nil && l.Timezone != "" {
+		if tz, err := time.LoadLocation(l.Timezone); err == nil {
+			loc = tz
+		}
+	}
+
+	from, to, err := period.Bounds(time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.rideRepo.GetDriverEarnings(ctx, driverID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &domain.EarningsSummary{
+		DriverID: driverID,
+		Period:   period,
+		From:     from,
+		To:       to,
+	}
+
+	if s.deliveries != nil {
+		deliveryLines, err := s.deliveries.GetDriverEarnings(ctx, driverID, from, to)
+		if err != nil {
+			log.Warn().Err(err).Str("driver_id", driverID.String()).Msg("Delivery earnings unavailable")
+			summary.DeliveriesUnavailable = true
+		} else {
+			lines = append(lines, deliveryLines...)
+		}
+	}
+	summary.Lines = lines
+
+	if l := locale.FromContext(ctx); l != nil && l.Currency != "" {
+		summary.Currency = l.Currency
+	} else if primary := domain.PrimaryEarningsCurrency(lines); primary != "" {
+		summary.Currency = primary
+	} else {
+		summary.Currency = locale.DefaultCurrency
+	}
+
+	summary.Summarize()
+	return summary, nil
+}
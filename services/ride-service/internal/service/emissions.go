@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// EmissionsService estimates the CO2 of completed rides from the vehicle
+// they were driven in, and sums it for riders and business accounts
+type EmissionsService struct {
+	repo         *repository.EmissionsRepository
+	driverRepo   *repository.DriverRepository
+	businessRepo *repository.BusinessRepository
+}
+
+// NewEmissionsService creates a new emissions service
+func NewEmissionsService(
+	repo *repository.EmissionsRepository,
+	driverRepo *repository.DriverRepository,
+	businessRepo *repository.BusinessRepository,
+) *EmissionsService {
+	return &EmissionsService{repo: repo, driverRepo: driverRepo, businessRepo: businessRepo}
+}
+
+// Estimate estimates a ride's emissions in its driver's active vehicle.
+// When the vehicle cannot be read, the ride type's usual vehicle is
+// assumed rather than leave the ride without an estimate.
+func (s *EmissionsService) Estimate(ctx context.Context, ride *domain.Ride) *domain.RideEmissions {
+	var vehicle *domain.Vehicle
+	if ride.DriverID != nil {
+		vehicles, err := s.driverRepo.GetActiveVehicles(ctx, []uuid.UUID{*ride.DriverID})
+		if err != nil {
+			log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to get vehicle for ride emissions")
+		}
+		vehicle = vehicles[*ride.DriverID]
+	}
+	return domain.NewRideEmissions(ride, vehicle)
+}
+
+// GetRiderStats sums the emissions of a rider's rides completed in
+// [from, to)
+func (s *EmissionsService) GetRiderStats(ctx context.Context, riderID uuid.UUID, from, to *time.Time) (*domain.EmissionsStats, error) {
+	return s.repo.GetRiderStats(ctx, riderID, from, to)
+}
+
+// GetBusinessStats sums the emissions of rides taken on a business account
+// and completed in [from, to), for platform admins and the account's own
+// admins
+func (s *EmissionsService) GetBusinessStats(ctx context.Context, accountID, userID uuid.UUID, role string, from, to *time.Time) (*domain.EmissionsStats, error) {
+	if err := checkBusinessAdmin(ctx, s.businessRepo, accountID, userID, role); err != nil {
+		return nil, err
+	}
+	return s.repo.GetBusinessStats(ctx, accountID, from, to)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/pricing"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// Fare variance job settings
+const (
+	fareVarianceLookback  = 48 * time.Hour // how far back to look for rides not yet measured
+	fareVarianceBatchSize = 500
+	fareVarianceWindow    = 24 * time.Hour // trailing window the metrics and alerts cover
+)
+
+// FareVarianceService compares quoted fares with fares metered on the trips
+// actually driven, to catch routing and pricing bugs
+type FareVarianceService struct {
+	rideRepo      *repository.RideRepository
+	pricingEngine *pricing.Engine
+	cities        *cityconfig.Registry
+	thresholdPct  float64
+}
+
+// NewFareVarianceService creates a new fare variance service. A zero
+// threshold uses domain.DefaultFareVarianceAlertPct.
+func NewFareVarianceService(
+	rideRepo *repository.RideRepository,
+	pricingEngine *pricing.Engine,
+	cities *cityconfig.Registry,
+	thresholdPct float64,
+) *FareVarianceService {
+	if thresholdPct <= 0 {
+		thresholdPct = domain.DefaultFareVarianceAlertPct
+	}
+
+	return &FareVarianceService{
+		rideRepo:      rideRepo,
+		pricingEngine: pricingEngine,
+		cities:        cities,
+		thresholdPct:  thresholdPct,
+	}
+}
+
+// Run measures recently completed rides, then emits variance metrics for the
+// trailing window and alerts on markets whose median variance is too high
+func (s *FareVarianceService) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	rides, err := s.rideRepo.GetUnmeasuredCompletedRides(ctx, now.Add(-fareVarianceLookback), fareVarianceBatchSize)
+	if err != nil {
+		return err
+	}
+
+	if len(rides) > 0 {
+		variances := make([]*domain.FareVariance, 0, len(rides))
+		for _, ride := range rides {
+			variances = append(variances, s.measure(ctx, ride))
+		}
+		if err := s.rideRepo.InsertFareVariances(ctx, variances); err != nil {
+			return err
+		}
+	}
+
+	report, err := s.GetReport(ctx, now.Add(-fareVarianceWindow), now)
+	if err != nil {
+		return err
+	}
+
+	for _, stat := range report.Stats {
+		log.Info().
+			Str("metric", "fare_variance").
+			Str("market", stat.Market).
+			Str("ride_type", string(stat.RideType)).
+			Int64("rides", stat.Rides).
+			Float64("median_pct", stat.MedianPct).
+			Float64("p90_abs_pct", stat.P90AbsPct).
+			Msg("Fare variance")
+
+		if stat.Alerting {
+			log.Error().
+				Bool("alert", true).
+				Str("market", stat.Market).
+				Str("ride_type", string(stat.RideType)).
+				Int64("rides", stat.Rides).
+				Float64("median_pct", stat.MedianPct).
+				Float64("threshold_pct", s.thresholdPct).
+				Msg("Median fare variance exceeds threshold - check routing and surge")
+		}
+	}
+
+	return nil
+}
+
+// GetReport gets variance stats for rides completed in [from, to)
+func (s *FareVarianceService) GetReport(ctx context.Context, from, to time.Time) (*domain.FareVarianceReport, error) {
+	stats, err := s.rideRepo.GetFareVarianceStats(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stat := range stats {
+		stat.Alerting = stat.ShouldAlert(s.thresholdPct)
+	}
+
+	return &domain.FareVarianceReport{
+		From:         from,
+		To:           to,
+		ThresholdPct: s.thresholdPct,
+		Stats:        stats,
+	}, nil
+}
+
+// measure meters a ride on its GPS trail and actual duration, with the
+// pricing of the city it was quoted in
+func (s *FareVarianceService) measure(ctx context.Context, ride *domain.Ride) *domain.FareVariance {
+	variance := &domain.FareVariance{
+		RideID:     ride.ID,
+		Market:     string(ride.Price.Currency),
+		RideType:   ride.Type,
+		Currency:   ride.Price.Currency,
+		QuotedFare: ride.Price.Total,
+		FinalFare:  ride.Price.Total,
+	}
+	if ride.CompletedAt != nil {
+		variance.CompletedAt = *ride.CompletedAt
+	}
+	if ride.Route != nil {
+		variance.QuotedDistanceM = float64(ride.Route.DistanceMeters)
+	}
+
+	cityCode := ""
+	if s.cities != nil {
+		if city, ok := s.cities.FindByLocation(ride.PickupLocation.Latitude, ride.PickupLocation.Longitude); ok {
+			cityCode = city.Code
+			variance.Market = city.Code
+		}
+	}
+
+	trail, err := s.rideRepo.GetLocationTrail(ctx, ride.ID)
+	if err != nil {
+		log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to load GPS trail for fare variance")
+		return variance
+	}
+	for i := 1; i < len(trail); i++ {
+		variance.ActualDistanceM += geo.HaversineDistance(trail[i-1].Latitude, trail[i-1].Longitude, trail[i].Latitude, trail[i].Longitude)
+	}
+
+	// Sparse trails under-measure the trip, so leave those rides out of the stats
+	if len(trail) < domain.FareVarianceMinTrailPoints || variance.ActualDistanceM <= 0 || ride.StartedAt == nil || ride.CompletedAt == nil {
+		return variance
+	}
+
+	durationS := int64(ride.CompletedAt.Sub(*ride.StartedAt).Seconds())
+	final := s.pricingEngine.RecalculateCityPrice(cityCode, ride.Price, ride.Type, variance.ActualDistanceM, durationS)
+
+	variance.Measured = true
+	variance.FinalFare = final.Total
+	variance.VariancePct = domain.FareVariancePct(variance.QuotedFare, variance.FinalFare)
+	return variance
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+)
+
+// BatchGeocodeService geocodes merchants' address lists, sharing lookups
+// between repeated addresses and caching results
+type BatchGeocodeService struct {
+	mapsClient *geo.MapsClient
+	driverPool *redis.DriverPool
+}
+
+// NewBatchGeocodeService creates a new batch geocoding service. driverPool
+// may be nil, in which case results are not cached.
+func NewBatchGeocodeService(mapsClient *geo.MapsClient, driverPool *redis.DriverPool) *BatchGeocodeService {
+	return &BatchGeocodeService{
+		mapsClient: mapsClient,
+		driverPool: driverPool,
+	}
+}
+
+// GeocodeBatch geocodes each address, returning one result per row in
+// order. A row that fails does not fail the batch.
+func (s *BatchGeocodeService) GeocodeBatch(ctx context.Context, addresses []string, components, language string) []*domain.GeocodedAddress {
+	results := make([]*domain.GeocodedAddress, len(addresses))
+
+	// Rows with the same address share one lookup
+	rows := make(map[string][]int)
+	for i, address := range addresses {
+		normalized := domain.NormalizeAddress(address)
+		if normalized == "" {
+			results[i] = &domain.GeocodedAddress{Row: i, Address: address, Status: domain.GeocodeStatusInvalid}
+			continue
+		}
+		rows[normalized] = append(rows[normalized], i)
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, domain.BatchGeocodeConcurrency)
+	)
+	for normalized, indexes := range rows {
+		wg.Add(1)
+		go func(normalized string, indexes []int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result := s.geocode(ctx, strings.TrimSpace(addresses[indexes[0]]), normalized, components, language)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, i := range indexes {
+				row := *result
+				row.Row = i
+				row.Address = addresses[i]
+				results[i] = &row
+			}
+		}(normalized, indexes)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// geocode looks up one address, from the cache where possible
+func (s *BatchGeocodeService) geocode(ctx context.Context, address, normalized, components, language string) *domain.GeocodedAddress {
+	key := components + "|" + language + "|" + normalized
+	if s.driverPool != nil {
+		if cached, err := s.driverPool.GetCachedGeocode(ctx, key); err == nil && cached != nil {
+			cached.Cached = true
+			return cached
+		}
+	}
+
+	resp, err := s.mapsClient.Geocode(ctx, geo.GeocodeRequest{
+		Address:    address,
+		Components: components,
+		Language:   language,
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("address", address).Msg("Batch geocode lookup failed")
+		return &domain.GeocodedAddress{Status: domain.GeocodeStatusError}
+	}
+
+	result := &domain.GeocodedAddress{Status: domain.GeocodeStatusNotFound}
+	if len(resp.Results) > 0 {
+		first := resp.Results[0]
+		result = &domain.GeocodedAddress{
+			Status:           domain.GeocodeStatusOK,
+			PlaceID:          first.PlaceID,
+			FormattedAddress: first.FormattedAddress,
+			Latitude:         first.Geometry.Location.Lat,
+			Longitude:        first.Geometry.Location.Lng,
+			Confidence:       domain.GeocodeConfidence(first.Geometry.LocationType, first.PartialMatch, len(resp.Results)),
+			Components:       addressComponents(first.AddressComponents),
+		}
+	}
+
+	// Not-found addresses are cached too, so a bad list is not retried
+	// against the provider on every import
+	if s.driverPool != nil {
+		if err := s.driverPool.CacheGeocode(ctx, key, result); err != nil {
+			log.Warn().Err(err).Msg("Failed to cache geocode result")
+		}
+	}
+
+	return result
+}
+
+// addressComponents picks the parts of an address out of the provider's
+// typed components
+func addressComponents(parts []geo.AddressComponent) *domain.AddressComponents {
+	c := &domain.AddressComponents{}
+	for _, part := range parts {
+		for _, t := range part.Types {
+			switch t {
+			case "street_number":
+				c.StreetNumber = part.LongName
+			case "route":
+				c.Street = part.LongName
+			case "neighborhood", "sublocality", "sublocality_level_1":
+				if c.Neighborhood == "" {
+					c.Neighborhood = part.LongName
+				}
+			case "locality":
+				c.City = part.LongName
+			case "administrative_area_level_1":
+				c.Region = part.LongName
+			case "postal_code":
+				c.PostalCode = part.LongName
+			case "country":
+				c.Country = part.ShortName
+			}
+		}
+	}
+	return c
+}
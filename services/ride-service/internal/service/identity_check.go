@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// Checks shown to support when reviewing a driver
+const identityCheckHistoryLimit = 50
+
+// FaceMatcher compares a selfie against a reference photo and returns the
+// provider's match confidence between 0 and 1
+type FaceMatcher interface {
+	Name() string
+	MatchFace(ctx context.Context, referenceURL, selfieURL string) (float64, error)
+}
+
+// IdentityCheckService verifies drivers by selfie at the start of a shift
+type IdentityCheckService struct {
+	checkRepo  *repository.IdentityCheckRepository
+	driverRepo *repository.DriverRepository
+	matcher    FaceMatcher
+}
+
+// NewIdentityCheckService creates a new identity check service
+func NewIdentityCheckService(
+	checkRepo *repository.IdentityCheckRepository,
+	driverRepo *repository.DriverRepository,
+	matcher FaceMatcher,
+) *IdentityCheckService {
+	return &IdentityCheckService{
+		checkRepo:  checkRepo,
+		driverRepo: driverRepo,
+		matcher:    matcher,
+	}
+}
+
+// SubmitSelfie matches a driver's selfie against their profile photo and
+// records the result. Drivers locked out by repeated failures must wait for
+// manual review.
+func (s *IdentityCheckService) SubmitSelfie(ctx context.Context, driverID uuid.UUID, selfieURL string) (*domain.IdentityCheck, error) {
+	selfieURL = strings.TrimSpace(selfieURL)
+	if selfieURL == "" {
+		return nil, domain.ErrInvalidRequest
+	}
+
+	recent, err := s.checkRepo.ListRecent(ctx, driverID, domain.MaxIdentityCheckFailures)
+	if err != nil {
+		return nil, err
+	}
+	if domain.IsIdentityCheckLocked(recent) {
+		return nil, domain.ErrIdentityCheckLocked
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if driver.ProfilePhoto == "" {
+		return nil, domain.ErrProfilePhotoMissing
+	}
+
+	// A provider outage is not the driver's failure, so nothing is recorded
+	confidence, err := s.matcher.MatchFace(ctx, driver.ProfilePhoto, selfieURL)
+	if err != nil {
+		log.Error().Err(err).Str("driver_id", driverID.String()).Msg("Face match request failed")
+		return nil, domain.ErrFaceMatchUnavailable
+	}
+
+	check := &domain.IdentityCheck{
+		ID:         uuid.New(),
+		DriverID:   driverID,
+		Status:     domain.IdentityCheckFailed,
+		Provider:   s.matcher.Name(),
+		Confidence: confidence,
+		SelfieURL:  selfieURL,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if confidence >= domain.FaceMatchThreshold {
+		check.Status = domain.IdentityCheckPassed
+	}
+
+	if err := s.checkRepo.Create(ctx, check); err != nil {
+		return nil, err
+	}
+
+	event := log.Info()
+	if !check.IsPassing() {
+		event = log.Warn()
+		if domain.IsIdentityCheckLocked(append([]*domain.IdentityCheck{check}, recent...)) {
+			event = event.Bool("locked", true)
+		}
+	}
+	event.
+		Str("driver_id", driverID.String()).
+		Str("status", string(check.Status)).
+		Float64("confidence", confidence).
+		Msg("Driver identity check recorded")
+
+	return check, nil
+}
+
+// IsVerified reports whether a driver has a passing check covering the
+// current shift
+func (s *IdentityCheckService) IsVerified(ctx context.Context, driverID uuid.UUID) (bool, error) {
+	recent, err := s.checkRepo.ListRecent(ctx, driverID, domain.MaxIdentityCheckFailures)
+	if err != nil {
+		return false, err
+	}
+	if domain.IsIdentityCheckLocked(recent) {
+		return false, domain.ErrIdentityCheckLocked
+	}
+	if len(recent) == 0 {
+		return false, nil
+	}
+	return recent[0].IsValidAt(time.Now().UTC()), nil
+}
+
+// OverrideLockout clears a lockout after support has reviewed the driver's
+// identity manually. The override covers the current shift.
+func (s *IdentityCheckService) OverrideLockout(ctx context.Context, driverID, reviewerID uuid.UUID, note string) (*domain.IdentityCheck, error) {
+	recent, err := s.checkRepo.ListRecent(ctx, driverID, domain.MaxIdentityCheckFailures)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.IsIdentityCheckLocked(recent) {
+		return nil, domain.ErrIdentityCheckNotLocked
+	}
+
+	check := &domain.IdentityCheck{
+		ID:         uuid.New(),
+		DriverID:   driverID,
+		Status:     domain.IdentityCheckOverridden,
+		Note:       note,
+		ReviewedBy: &reviewerID,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := s.checkRepo.Create(ctx, check); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("driver_id", driverID.String()).
+		Str("reviewed_by", reviewerID.String()).
+		Msg("Driver identity lockout overridden")
+
+	return check, nil
+}
+
+// ListChecks lists a driver's recent identity checks, newest first
+func (s *IdentityCheckService) ListChecks(ctx context.Context, driverID uuid.UUID) ([]*domain.IdentityCheck, error) {
+	return s.checkRepo.ListRecent(ctx, driverID, identityCheckHistoryLimit)
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/eta"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/notification"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// incidentRideBatchSize is the most rides underway checked per sweep
+const incidentRideBatchSize = 1000
+
+// IncidentService ingests road incidents reported by ops, traffic feeds
+// and corroborated driver road reports, slows ETAs through them and offers drivers whose route
+// runs through one a way around it
+type IncidentService struct {
+	driverPool *redis.DriverPool
+	rideRepo   *repository.RideRepository
+	driverRepo *repository.DriverRepository
+	mapsClient *geo.MapsClient
+	notifier   Notifier
+
+	mu     sync.RWMutex
+	active []*domain.TrafficIncident
+}
+
+// NewIncidentService creates a new incident service. mapsClient may be
+// nil, in which case drivers are warned of incidents without a route
+// around them.
+func NewIncidentService(
+	driverPool *redis.DriverPool,
+	rideRepo *repository.RideRepository,
+	driverRepo *repository.DriverRepository,
+	mapsClient *geo.MapsClient,
+	notifier Notifier,
+) *IncidentService {
+	return &IncidentService{
+		driverPool: driverPool,
+		rideRepo:   rideRepo,
+		driverRepo: driverRepo,
+		mapsClient: mapsClient,
+		notifier:   notifier,
+	}
+}
+
+// Report stores a new incident and offers reroutes to rides already
+// heading through it
+func (s *IncidentService) Report(ctx context.Context, incident *domain.TrafficIncident) error {
+	if !geo.IsValidCoordinate(incident.Latitude, incident.Longitude) {
+		return domain.ErrInvalidLocation
+	}
+	if err := incident.Normalize(time.Now().UTC()); err != nil {
+		return err
+	}
+	if err := s.driverPool.SaveIncident(ctx, incident); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	replaced := false
+	for i := range s.active {
+		if s.active[i].ID == incident.ID {
+			s.active[i], replaced = incident, true
+		}
+	}
+	if !replaced {
+		s.active = append(s.active, incident)
+	}
+	s.mu.Unlock()
+
+	log.Info().
+		Str("incident_id", incident.ID.String()).
+		Str("type", string(incident.Type)).
+		Str("source", string(incident.Source)).
+		Msg("Traffic incident reported")
+
+	return s.suggestReroutes(ctx, []*domain.TrafficIncident{incident})
+}
+
+// List gets the incidents in force
+func (s *IncidentService) List(ctx context.Context) ([]*domain.TrafficIncident, error) {
+	return s.driverPool.ListIncidents(ctx)
+}
+
+// Get gets an incident in force, or nil once it has expired or been resolved
+func (s *IncidentService) Get(ctx context.Context, incidentID uuid.UUID) (*domain.TrafficIncident, error) {
+	return s.driverPool.GetIncident(ctx, incidentID)
+}
+
+// Resolve removes an incident before it expires
+func (s *IncidentService) Resolve(ctx context.Context, incidentID uuid.UUID) error {
+	removed, err := s.driverPool.DeleteIncident(ctx, incidentID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return domain.ErrIncidentNotFound
+	}
+
+	s.mu.Lock()
+	for i, incident := range s.active {
+		if incident.ID == incidentID {
+			s.active = append(s.active[:i:i], s.active[i+1:]...)
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// DelayAt returns how much the worst incident covering a point slows
+// travel there, or 1 when none does
+func (s *IncidentService) DelayAt(lat, lng float64) float64 {
+	now := time.Now()
+	delay := 1.0
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, incident := range s.active {
+		if incident.IsActive(now) && incident.DelayMultiplier > delay &&
+			geo.HaversineDistance(lat, lng, incident.Latitude, incident.Longitude) <= incident.RadiusMeters {
+			delay = incident.DelayMultiplier
+		}
+	}
+	return delay
+}
+
+// Sweep reloads the incidents in force, including those reported to other
+// instances, and offers reroutes to rides that have since headed through
+// one. Each ride is offered one reroute per incident.
+func (s *IncidentService) Sweep(ctx context.Context) error {
+	incidents, err := s.driverPool.ListIncidents(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.active = incidents
+	s.mu.Unlock()
+
+	if len(incidents) == 0 {
+		return nil
+	}
+	return s.suggestReroutes(ctx, incidents)
+}
+
+// StartConsumer ingests incident reports that traffic feeds queue in Redis
+// until ctx is cancelled. Reports that fail to parse or validate are
+// logged and dropped.
+func (s *IncidentService) StartConsumer(ctx context.Context) {
+	for ctx.Err() == nil {
+		data, err := s.driverPool.PopIncidentReport(ctx, 5*time.Second)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error().Err(err).Msg("Failed to read incident report")
+				time.Sleep(time.Second)
+			}
+			continue
+		}
+		if data == nil {
+			continue
+		}
+
+		var incident domain.TrafficIncident
+		if err := json.Unmarshal(data, &incident); err != nil {
+			log.Warn().Err(err).Msg("Dropping malformed incident report")
+			continue
+		}
+		if incident.Source == "" {
+			incident.Source = domain.IncidentSourceFeed
+		}
+		if err := s.Report(ctx, &incident); err != nil {
+			log.Warn().Err(err).Msg("Dropping incident report")
+		}
+	}
+}
+
+// suggestReroutes offers a reroute to each ride underway whose remaining
+// route runs through one of the incidents
+func (s *IncidentService) suggestReroutes(ctx context.Context, incidents []*domain.TrafficIncident) error {
+	rides, err := s.rideRepo.GetRidesUnderway(ctx, incidentRideBatchSize)
+	if err != nil {
+		return err
+	}
+
+	suggested := 0
+	for _, ride := range rides {
+		path := remainingPath(ride)
+		for _, incident := range incidents {
+			if geo.DistanceToPolyline(incident.Latitude, incident.Longitude, path) > incident.RadiusMeters {
+				continue
+			}
+			first, err := s.driverPool.MarkRerouteSuggested(ctx, ride.ID, incident.ID, time.Until(incident.ExpiresAt))
+			if err != nil || !first {
+				continue
+			}
+			s.suggestReroute(ctx, ride, incident)
+			suggested++
+		}
+	}
+
+	if suggested > 0 {
+		log.Info().Int("rides", suggested).Msg("Reroutes suggested around incidents")
+	}
+	return nil
+}
+
+// suggestReroute tells a ride's driver about an incident on their route,
+// with a route around it when one can be found, and records it on the
+// ride's timeline
+func (s *IncidentService) suggestReroute(ctx context.Context, ride *domain.Ride, incident *domain.TrafficIncident) {
+	around := s.routeAround(ctx, ride)
+
+	event := domain.NewRideEvent(ride.ID, domain.RideEventRerouteSuggested).
+		WithData("incident_id", incident.ID.String()).
+		WithData("incident_type", string(incident.Type))
+	if around != nil {
+		event = event.
+			WithData("distance_meters", around.DistanceMeters).
+			WithData("duration_seconds", around.DurationSeconds)
+	}
+	if err := s.rideRepo.AppendEvents(ctx, event); err != nil {
+		log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to record reroute suggestion")
+	}
+
+	if s.notifier == nil || s.driverRepo == nil {
+		return
+	}
+	driver, err := s.driverRepo.GetByID(ctx, *ride.DriverID)
+	if err != nil {
+		log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to get driver for reroute suggestion")
+		return
+	}
+
+	kind := strings.ToLower(strings.ReplaceAll(string(incident.Type), "_", " "))
+	body := fmt.Sprintf("A %s is reported on your route. Consider another way.", kind)
+	data := map[string]string{
+		"type":          "REROUTE_SUGGESTED",
+		"ride_id":       ride.ID.String(),
+		"incident_id":   incident.ID.String(),
+		"incident_type": string(incident.Type),
+	}
+	if around != nil {
+		body = fmt.Sprintf("A %s is reported on your route. A way around it via %s is in your app.", kind, around.Summary)
+		data["polyline"] = around.Polyline
+		data["distance_meters"] = fmt.Sprintf("%d", around.DistanceMeters)
+		data["duration_seconds"] = fmt.Sprintf("%d", around.DurationSeconds)
+	}
+	if err := s.notifier.SendPush(ctx, driver.UserID, "Incident on your route", body, notification.PriorityHigh, data); err != nil {
+		log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to send reroute suggestion")
+	}
+}
+
+// routeAround finds the first alternative from where the ride is to its
+// dropoff that clears every incident in force, keeping to the rider's
+// avoidance preferences
+func (s *IncidentService) routeAround(ctx context.Context, ride *domain.Ride) *domain.RouteCandidate {
+	if s.mapsClient == nil {
+		return nil
+	}
+
+	origin := ride.PickupLocation
+	if ride.Status == domain.RideStatusInProgress && ride.CurrentLocation != nil {
+		origin = *ride.CurrentLocation
+	}
+	request := geo.DirectionsRequest{
+		OriginLat:     origin.Latitude,
+		OriginLng:     origin.Longitude,
+		DestLat:       ride.DropoffLocation.Latitude,
+		DestLng:       ride.DropoffLocation.Longitude,
+		DepartureTime: time.Now(),
+		Mode:          eta.ProfileForRideType(ride.Type).GoogleMode(),
+		Alternatives:  true,
+	}
+	if ride.Route != nil && ride.Route.Avoid != nil {
+		request.AvoidTolls = ride.Route.Avoid.Tolls
+		request.AvoidHighways = ride.Route.Avoid.Highways
+	}
+
+	directions, err := s.mapsClient.GetDirections(ctx, request)
+	if err != nil {
+		log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to get routes around incident")
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, candidate := range routeCandidates(directions) {
+		path := geo.PolylineDecode(candidate.Polyline)
+		clear := true
+		for _, incident := range s.active {
+			if geo.DistanceToPolyline(incident.Latitude, incident.Longitude, path) <= incident.RadiusMeters {
+				clear = false
+				break
+			}
+		}
+		if clear {
+			return &candidate
+		}
+	}
+	return nil
+}
+
+// remainingPath is the part of a ride's planned route still ahead: the
+// routed polyline where there is one, otherwise the straight legs through
+// its stops. Once the rider is aboard, the route already driven is dropped.
+func remainingPath(ride *domain.Ride) []geo.Coordinate {
+	var path []geo.Coordinate
+	if ride.Route != nil && ride.Route.Polyline != "" {
+		path = geo.PolylineDecode(ride.Route.Polyline)
+	} else {
+		path = append(path, geo.Coordinate{Lat: ride.PickupLocation.Latitude, Lng: ride.PickupLocation.Longitude})
+		for _, stop := range ride.Stops {
+			path = append(path, geo.Coordinate{Lat: stop.Latitude, Lng: stop.Longitude})
+		}
+		path = append(path, geo.Coordinate{Lat: ride.DropoffLocation.Latitude, Lng: ride.DropoffLocation.Longitude})
+	}
+
+	if ride.Status != domain.RideStatusInProgress || ride.CurrentLocation == nil || len(path) < 2 {
+		return path
+	}
+	nearest, best := 0, geo.HaversineDistance(ride.CurrentLocation.Latitude, ride.CurrentLocation.Longitude, path[0].Lat, path[0].Lng)
+	for i, point := range path[1:] {
+		if d := geo.HaversineDistance(ride.CurrentLocation.Latitude, ride.CurrentLocation.Longitude, point.Lat, point.Lng); d < best {
+			nearest, best = i+1, d
+		}
+	}
+	remaining := []geo.Coordinate{{Lat: ride.CurrentLocation.Latitude, Lng: ride.CurrentLocation.Longitude}}
+	return append(remaining, path[nearest:]...)
+}
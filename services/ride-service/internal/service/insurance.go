@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// ClaimRequest is a rider's or driver's claim on an insured ride
+type ClaimRequest struct {
+	RideID      uuid.UUID
+	UserID      uuid.UUID
+	Type        domain.InsuranceClaimType
+	Description string
+	IncidentAt  *time.Time
+}
+
+// ClaimUpdate is support moving a claim on. Lodging a claim with the
+// provider needs the provider's claim reference, and a rejection needs a
+// reason.
+type ClaimUpdate struct {
+	Status           domain.InsuranceClaimStatus
+	ProviderClaimRef string
+	SettledAmount    int64
+	Note             string
+}
+
+// InsuranceService attaches passenger insurance to completed rides in
+// insured markets and takes claims against it. Claims on rides with an SOS
+// are linked to the safety incident.
+type InsuranceService struct {
+	rideService   *RideService
+	insuranceRepo *repository.InsuranceRepository
+	safetyRepo    *repository.SafetyIncidentRepository
+	cities        *cityconfig.Registry
+}
+
+// NewInsuranceService creates a new insurance service. safetyRepo may be
+// nil, in which case claims are not linked to SOS incidents.
+func NewInsuranceService(
+	rideService *RideService,
+	insuranceRepo *repository.InsuranceRepository,
+	safetyRepo *repository.SafetyIncidentRepository,
+	cities *cityconfig.Registry,
+) *InsuranceService {
+	return &InsuranceService{
+		rideService:   rideService,
+		insuranceRepo: insuranceRepo,
+		safetyRepo:    safetyRepo,
+		cities:        cities,
+	}
+}
+
+// AttachPolicy covers a completed ride under its city's master policy.
+// Rides in markets without cover get no policy and no error.
+func (s *InsuranceService) AttachPolicy(ctx context.Context, ride *domain.Ride) (*domain.InsurancePolicy, error) {
+	if ride.Status != domain.RideStatusCompleted || ride.Price == nil || s.cities == nil {
+		return nil, nil
+	}
+	city, ok := s.cities.FindByLocation(ride.PickupLocation.Latitude, ride.PickupLocation.Longitude)
+	if !ok || !city.Insurance.Enabled {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	end := now
+	if ride.CompletedAt != nil {
+		end = *ride.CompletedAt
+	}
+	start := end
+	if ride.StartedAt != nil {
+		start = *ride.StartedAt
+	}
+
+	policy := &domain.InsurancePolicy{
+		ID:            uuid.New(),
+		RideID:        ride.ID,
+		City:          city.Code,
+		Provider:      city.Insurance.Provider,
+		PolicyRef:     domain.InsurancePolicyRef(city.Insurance.MasterPolicy, ride.ID),
+		Fare:          ride.Price.Total,
+		Premium:       city.Insurance.Premium(ride.Price.Total),
+		Currency:      ride.Price.Currency,
+		CoverageStart: start,
+		CoverageEnd:   end,
+		ClaimsUntil:   end.Add(city.Insurance.ClaimWindow()),
+		CreatedAt:     now,
+	}
+
+	attached, err := s.insuranceRepo.CreatePolicy(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	if !attached {
+		return s.insuranceRepo.GetPolicyByRide(ctx, ride.ID)
+	}
+
+	log.Info().
+		Str("ride_id", ride.ID.String()).
+		Str("policy_ref", policy.PolicyRef).
+		Int64("premium", policy.Premium).
+		Msg("Ride insurance attached")
+
+	return policy, nil
+}
+
+// GetRidePolicy gets a ride's policy for its rider or driver, or support
+func (s *InsuranceService) GetRidePolicy(ctx context.Context, rideID, userID uuid.UUID, support bool) (*domain.InsurancePolicy, error) {
+	if !support {
+		ride, err := s.rideService.GetRide(ctx, rideID)
+		if err != nil {
+			return nil, err
+		}
+		if claimantRole(ride, userID) == "" {
+			return nil, domain.ErrForbidden
+		}
+	}
+	return s.insuranceRepo.GetPolicyByRide(ctx, rideID)
+}
+
+// FileClaim takes a claim from the ride's rider or driver while the claim
+// window is open
+func (s *InsuranceService) FileClaim(ctx context.Context, req *ClaimRequest) (*domain.InsuranceClaim, error) {
+	description := strings.TrimSpace(req.Description)
+	if !req.Type.IsValid() || description == "" {
+		return nil, domain.ErrInvalidInsuranceClaim
+	}
+
+	ride, err := s.rideService.GetRide(ctx, req.RideID)
+	if err != nil {
+		return nil, err
+	}
+	role := claimantRole(ride, req.UserID)
+	if role == "" {
+		return nil, domain.ErrForbidden
+	}
+
+	policy, err := s.insuranceRepo.GetPolicyByRide(ctx, ride.ID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if now.After(policy.ClaimsUntil) {
+		return nil, domain.ErrInsuranceClaimWindowClosed
+	}
+	if req.IncidentAt != nil && !policy.Covers(*req.IncidentAt) {
+		return nil, domain.ErrInvalidInsuranceClaim
+	}
+
+	claim := &domain.InsuranceClaim{
+		ID:           uuid.New(),
+		RideID:       ride.ID,
+		PolicyID:     policy.ID,
+		PolicyRef:    policy.PolicyRef,
+		ClaimantID:   req.UserID,
+		ClaimantRole: role,
+		Type:         req.Type,
+		Description:  description,
+		IncidentAt:   req.IncidentAt,
+		Status:       domain.InsuranceClaimSubmitted,
+		Currency:     policy.Currency,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if incident := s.safetyIncident(ctx, ride.ID); incident != nil {
+		claim.SafetyIncidentID = &incident.ID
+	}
+
+	if err := s.insuranceRepo.CreateClaim(ctx, claim); err != nil {
+		return nil, err
+	}
+
+	event := domain.NewRideEvent(ride.ID, domain.RideEventInsuranceClaim).
+		WithActor(req.UserID).
+		WithData("claim_id", claim.ID).
+		WithData("type", claim.Type)
+	if claim.SafetyIncidentID != nil {
+		event = event.WithData("safety_incident_id", *claim.SafetyIncidentID)
+	}
+	s.rideService.RecordEvent(ctx, event)
+
+	log.Info().
+		Str("claim_id", claim.ID.String()).
+		Str("ride_id", ride.ID.String()).
+		Str("type", string(claim.Type)).
+		Bool("sos", claim.SafetyIncidentID != nil).
+		Msg("Insurance claim filed")
+
+	return claim, nil
+}
+
+// safetyIncident finds the SOS raised on a ride, if any
+func (s *InsuranceService) safetyIncident(ctx context.Context, rideID uuid.UUID) *domain.SafetyIncident {
+	if s.safetyRepo == nil {
+		return nil
+	}
+	incident, err := s.safetyRepo.GetLatestByRide(ctx, rideID)
+	if err != nil {
+		if err != domain.ErrSafetyIncidentNotFound {
+			log.Warn().Err(err).Str("ride_id", rideID.String()).Msg("Failed to look up SOS for insurance claim")
+		}
+		return nil
+	}
+	return incident
+}
+
+// ListRideClaims lists a ride's claims. Riders and drivers see only their
+// own; support sees all of them.
+func (s *InsuranceService) ListRideClaims(ctx context.Context, rideID, userID uuid.UUID, support bool) ([]*domain.InsuranceClaim, error) {
+	claims, err := s.insuranceRepo.ListClaimsByRide(ctx, rideID)
+	if err != nil || support {
+		return claims, err
+	}
+
+	own := make([]*domain.InsuranceClaim, 0, len(claims))
+	for _, claim := range claims {
+		if claim.ClaimantID == userID {
+			own = append(own, claim)
+		}
+	}
+	return own, nil
+}
+
+// GetClaim gets a claim
+func (s *InsuranceService) GetClaim(ctx context.Context, id uuid.UUID) (*domain.InsuranceClaim, error) {
+	return s.insuranceRepo.GetClaim(ctx, id)
+}
+
+// ListClaims lists claims in a status, longest waiting first
+func (s *InsuranceService) ListClaims(ctx context.Context, status domain.InsuranceClaimStatus, limit, offset int) ([]*domain.InsuranceClaim, int64, error) {
+	return s.insuranceRepo.ListClaimsByStatus(ctx, status, limit, offset)
+}
+
+// ListIncidentClaims lists the claims linked to an SOS incident
+func (s *InsuranceService) ListIncidentClaims(ctx context.Context, incidentID uuid.UUID) ([]*domain.InsuranceClaim, error) {
+	return s.insuranceRepo.ListClaimsBySafetyIncident(ctx, incidentID)
+}
+
+// UpdateClaim moves a claim on through review, the provider and settlement
+func (s *InsuranceService) UpdateClaim(ctx context.Context, id, agentID uuid.UUID, update *ClaimUpdate) (*domain.InsuranceClaim, error) {
+	switch {
+	case update.Status == domain.InsuranceClaimFiled && update.ProviderClaimRef == "",
+		update.Status == domain.InsuranceClaimRejected && update.Note == "",
+		update.SettledAmount < 0:
+		return nil, domain.ErrInvalidInsuranceClaim
+	}
+
+	claim, err := s.insuranceRepo.GetClaim(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	from := claim.Status
+	if !from.CanTransitionTo(update.Status) {
+		return nil, domain.ErrInvalidClaimTransition
+	}
+
+	now := time.Now().UTC()
+	claim.Status = update.Status
+	claim.UpdatedBy = &agentID
+	claim.UpdatedAt = now
+	if update.ProviderClaimRef != "" {
+		claim.ProviderClaimRef = update.ProviderClaimRef
+	}
+	if update.Note != "" {
+		claim.Note = update.Note
+	}
+	if update.Status == domain.InsuranceClaimSettled {
+		claim.SettledAmount = update.SettledAmount
+	}
+	if update.Status.IsClosed() {
+		claim.ClosedAt = &now
+	}
+
+	if err := s.insuranceRepo.UpdateClaimStatus(ctx, claim, from); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("claim_id", claim.ID.String()).
+		Str("agent_id", agentID.String()).
+		Str("status", string(claim.Status)).
+		Msg("Insurance claim updated")
+
+	return claim, nil
+}
+
+// claimantRole returns whether the user rode or drove the ride, or "" for
+// neither
+func claimantRole(ride *domain.Ride, userID uuid.UUID) string {
+	switch {
+	case ride.RiderID == userID:
+		return domain.ClaimantRider
+	case ride.DriverID != nil && *ride.DriverID == userID:
+		return domain.ClaimantDriver
+	}
+	return ""
+}
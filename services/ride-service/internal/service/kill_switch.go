@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/pricing"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// killSwitchAuditLimit is the most audit entries returned at once
+const killSwitchAuditLimit = 200
+
+// KillSwitchService switches ride requests, new deliveries or surge off
+// city by city. Switches are stored in Redis so every instance, and the
+// delivery service, sees them at once; each change is audit logged.
+type KillSwitchService struct {
+	driverPool    *redis.DriverPool
+	auditRepo     *repository.KillSwitchRepository
+	cities        *cityconfig.Registry
+	pricingEngine *pricing.Engine
+}
+
+// NewKillSwitchService creates a new kill switch service. auditRepo may be
+// nil, in which case changes are audited to the log only.
+func NewKillSwitchService(
+	driverPool *redis.DriverPool,
+	auditRepo *repository.KillSwitchRepository,
+	cities *cityconfig.Registry,
+	pricingEngine *pricing.Engine,
+) *KillSwitchService {
+	return &KillSwitchService{
+		driverPool:    driverPool,
+		auditRepo:     auditRepo,
+		cities:        cities,
+		pricingEngine: pricingEngine,
+	}
+}
+
+// Disable switches a feature off in a city until it is switched back on
+// or its re-enable time passes
+func (s *KillSwitchService) Disable(ctx context.Context, sw *domain.KillSwitch) error {
+	city, ok := s.cities.Get(sw.City)
+	if !ok {
+		return domain.ErrCityNotFound
+	}
+	now := time.Now().UTC()
+	if err := sw.Validate(now); err != nil {
+		return err
+	}
+
+	sw.CityName = city.Name
+	sw.DisabledAt = now
+	if err := s.driverPool.SaveKillSwitch(ctx, sw); err != nil {
+		return err
+	}
+	if sw.Feature == domain.KillSwitchSurge {
+		s.pricingEngine.SetSurgeDisabled(sw.City, true)
+	}
+
+	actorID := sw.DisabledBy
+	s.audit(ctx, &domain.KillSwitchAudit{
+		City:       sw.City,
+		Feature:    sw.Feature,
+		Action:     domain.KillSwitchDisabled,
+		Reason:     sw.Reason,
+		ActorID:    &actorID,
+		ReenableAt: sw.ReenableAt,
+	})
+	return nil
+}
+
+// Enable switches a city's feature back on
+func (s *KillSwitchService) Enable(ctx context.Context, cityCode string, feature domain.KillSwitchFeature, actorID uuid.UUID) error {
+	if !feature.IsValid() {
+		return domain.ErrInvalidKillSwitchFeature
+	}
+	removed, err := s.driverPool.DeleteKillSwitch(ctx, cityCode, feature)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return domain.ErrKillSwitchNotFound
+	}
+	if feature == domain.KillSwitchSurge {
+		s.pricingEngine.SetSurgeDisabled(cityCode, false)
+	}
+
+	s.audit(ctx, &domain.KillSwitchAudit{
+		City:    cityCode,
+		Feature: feature,
+		Action:  domain.KillSwitchEnabled,
+		ActorID: &actorID,
+	})
+	return nil
+}
+
+// Check gets the switch on a city's feature, or nil when the feature is on
+func (s *KillSwitchService) Check(ctx context.Context, cityCode string, feature domain.KillSwitchFeature) (*domain.KillSwitch, error) {
+	return s.driverPool.GetKillSwitch(ctx, cityCode, feature)
+}
+
+// List gets the switches in force
+func (s *KillSwitchService) List(ctx context.Context) ([]*domain.KillSwitch, error) {
+	switches, err := s.driverPool.ListKillSwitches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	active := make([]*domain.KillSwitch, 0, len(switches))
+	for _, sw := range switches {
+		if sw.IsActive(now) {
+			active = append(active, sw)
+		}
+	}
+	return active, nil
+}
+
+// Audit gets recent switch changes for a city, or all cities when
+// cityCode is empty
+func (s *KillSwitchService) Audit(ctx context.Context, cityCode string) ([]*domain.KillSwitchAudit, error) {
+	if s.auditRepo == nil {
+		return []*domain.KillSwitchAudit{}, nil
+	}
+	return s.auditRepo.ListAudit(ctx, cityCode, killSwitchAuditLimit)
+}
+
+// Sweep switches features back on once their re-enable time passes and
+// brings this instance's surge switches in line with Redis, for switches
+// changed through other instances
+func (s *KillSwitchService) Sweep(ctx context.Context) error {
+	switches, err := s.driverPool.ListKillSwitches(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	surgeOff := make(map[string]bool)
+	for _, sw := range switches {
+		if sw.IsActive(now) {
+			if sw.Feature == domain.KillSwitchSurge {
+				surgeOff[sw.City] = true
+			}
+			continue
+		}
+
+		removed, err := s.driverPool.DeleteKillSwitch(ctx, sw.City, sw.Feature)
+		if err != nil {
+			log.Error().Err(err).Str("city", sw.City).Str("feature", string(sw.Feature)).Msg("Failed to re-enable feature")
+			continue
+		}
+		if removed {
+			s.audit(ctx, &domain.KillSwitchAudit{
+				City:       sw.City,
+				Feature:    sw.Feature,
+				Action:     domain.KillSwitchReenabled,
+				Reason:     sw.Reason,
+				ReenableAt: sw.ReenableAt,
+			})
+		}
+	}
+
+	for _, city := range s.cities.List() {
+		s.pricingEngine.SetSurgeDisabled(city.Code, surgeOff[city.Code])
+	}
+	return nil
+}
+
+// audit logs a switch change and stores it for the audit trail. A failed
+// write is logged rather than undoing the change, which ops need to take
+// effect regardless.
+func (s *KillSwitchService) audit(ctx context.Context, entry *domain.KillSwitchAudit) {
+	entry.ID = uuid.New()
+	entry.CreatedAt = time.Now().UTC()
+
+	event := log.Info().
+		Str("city", entry.City).
+		Str("feature", string(entry.Feature)).
+		Str("action", string(entry.Action)).
+		Str("reason", entry.Reason)
+	if entry.ActorID != nil {
+		event = event.Str("actor_id", entry.ActorID.String())
+	}
+	if entry.ReenableAt != nil {
+		event = event.Time("reenable_at", *entry.ReenableAt)
+	}
+	event.Msg("City kill switch changed")
+
+	if s.auditRepo == nil {
+		return
+	}
+	if err := s.auditRepo.InsertAudit(ctx, entry); err != nil {
+		log.Error().Err(err).Str("city", entry.City).Msg("Failed to store kill switch audit entry")
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/logging"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// locationLog is sampled: it logs on every batch of driver pings
+var locationLog = logging.For("location")
+
+// LocationFlushService coalesces drivers' GPS pings into batched Postgres
+// writes. Pings only update Redis; each driver's latest position is then
+// written at most once per flush interval, or at once after a long move.
+// The queue lives in Redis, so whichever instance flushes writes the
+// newest position.
+type LocationFlushService struct {
+	driverPool *redis.DriverPool
+	driverRepo *repository.DriverRepository
+}
+
+// NewLocationFlushService creates a new location flush service
+func NewLocationFlushService(driverPool *redis.DriverPool, driverRepo *repository.DriverRepository) *LocationFlushService {
+	return &LocationFlushService{
+		driverPool: driverPool,
+		driverRepo: driverRepo,
+	}
+}
+
+// Track queues a driver's new position, already in Redis, to be written
+func (s *LocationFlushService) Track(ctx context.Context, loc *domain.DriverLocation) error {
+	persisted, err := s.driverPool.GetPersistedLocation(ctx, loc.DriverID)
+	if err != nil {
+		return err
+	}
+	return s.driverPool.QueueLocationFlush(ctx, loc.DriverID, domain.LocationFlushDue(persisted, loc, time.Now()))
+}
+
+// Flush writes the positions of every driver that is due, in batches
+func (s *LocationFlushService) Flush(ctx context.Context) error {
+	for {
+		now := time.Now()
+		driverIDs, err := s.driverPool.ClaimDueLocationFlushes(ctx, now, domain.LocationFlushBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(driverIDs) == 0 {
+			return nil
+		}
+
+		if err := s.flushBatch(ctx, driverIDs, now); err != nil {
+			// Put the batch back so the next run retries it
+			for _, driverID := range driverIDs {
+				_ = s.driverPool.QueueLocationFlush(ctx, driverID, now)
+			}
+			return err
+		}
+		if len(driverIDs) < domain.LocationFlushBatchSize {
+			return nil
+		}
+	}
+}
+
+// flushBatch writes the latest Redis position of each driver. Drivers
+// whose position has expired from Redis have gone offline and are skipped.
+func (s *LocationFlushService) flushBatch(ctx context.Context, driverIDs []uuid.UUID, now time.Time) error {
+	current, err := s.driverPool.GetDriverLocations(ctx, driverIDs)
+	if err != nil {
+		return err
+	}
+
+	locs := make([]*domain.DriverLocation, 0, len(current))
+	persisted := make(map[uuid.UUID]*domain.PersistedLocation, len(current))
+	for driverID, data := range current {
+		locs = append(locs, &domain.DriverLocation{
+			DriverID: driverID,
+			Location: domain.Location{
+				Latitude:  data.Latitude,
+				Longitude: data.Longitude,
+				H3Cell:    data.H3Cell,
+			},
+			Heading:   data.Heading,
+			Speed:     data.Speed,
+			Timestamp: data.UpdatedAt,
+		})
+		persisted[driverID] = &domain.PersistedLocation{
+			Latitude:  data.Latitude,
+			Longitude: data.Longitude,
+			WrittenAt: now,
+		}
+	}
+
+	if err := s.driverRepo.UpdateLocations(ctx, locs); err != nil {
+		return err
+	}
+	if err := s.driverPool.SetPersistedLocations(ctx, persisted); err != nil {
+		locationLog.Warn().Err(err).Msg("Failed to record flushed driver locations")
+	}
+
+	locationLog.Debug().Int("drivers", len(locs)).Msg("Flushed driver locations")
+	return nil
+}
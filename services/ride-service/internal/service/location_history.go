@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// Location history recording settings
+const (
+	locationHistoryBackfill  = 90 * 24 * time.Hour // completed rides picked up on the first run
+	locationHistoryBatchSize = 500                 // most rides recorded per run
+)
+
+// LocationHistoryService keeps riders' frequent places and trip patterns in
+// Postgres, fed from completed rides, with a Redis cache for prediction
+// lookups. It also learns where pickups really happen to suggest better
+// pickup spots.
+type LocationHistoryService struct {
+	rideRepo    *repository.RideRepository
+	driverPool  *redis.DriverPool
+	cities      *cityconfig.Registry
+	savedPlaces *repository.SavedPlaceRepository
+	consents    *ConsentService
+}
+
+// NewLocationHistoryService creates a new location history service. cities
+// may be nil, in which case trip patterns are bucketed in UTC.
+func NewLocationHistoryService(
+	rideRepo *repository.RideRepository,
+	driverPool *redis.DriverPool,
+	cities *cityconfig.Registry,
+) *LocationHistoryService {
+	return &LocationHistoryService{
+		rideRepo:   rideRepo,
+		driverPool: driverPool,
+		cities:     cities,
+	}
+}
+
+// SetSavedPlaces takes riders' saved home and work over the ones their
+// trips suggest
+func (s *LocationHistoryService) SetSavedPlaces(savedPlaces *repository.SavedPlaceRepository) {
+	s.savedPlaces = savedPlaces
+}
+
+// SetConsents stores and personalizes from riders' trips only as far as
+// they consented
+func (s *LocationHistoryService) SetConsents(consents *ConsentService) {
+	s.consents = consents
+}
+
+// Run records the trips of rides completed since the last run
+func (s *LocationHistoryService) Run(ctx context.Context) error {
+	since := time.Now().UTC().Add(-locationHistoryBackfill)
+	rides, err := s.rideRepo.GetUnrecordedCompletedRides(ctx, since, locationHistoryBatchSize)
+	if err != nil {
+		return err
+	}
+
+	recorded := 0
+	for _, ride := range rides {
+		ok, err := s.RecordTrip(ctx, ride)
+		if err != nil {
+			log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to record trip in location history")
+			continue
+		}
+		if ok {
+			recorded++
+		}
+	}
+
+	if recorded > 0 {
+		log.Info().Int("recorded", recorded).Msg("Location history updated")
+	}
+	return nil
+}
+
+// RecordTrip adds a completed ride's pickup, dropoff and trip pattern to the
+// rider's location history unless they opted out. Places need the rider's
+// precise location consent and the pattern their personalization consent.
+// Recording is idempotent per ride; it returns false when the ride was
+// already recorded.
+func (s *LocationHistoryService) RecordTrip(ctx context.Context, ride *domain.Ride) (bool, error) {
+	at := ride.RequestedAt
+	if ride.CompletedAt != nil {
+		at = *ride.CompletedAt
+	}
+
+	pickup := s.place(ride.RiderID, &ride.PickupLocation, at)
+	pickup.Pickups = 1
+	dropoff := s.place(ride.RiderID, &ride.DropoffLocation, at)
+	dropoff.Dropoffs = 1
+
+	day, hour := domain.TripPatternSlot(ride.RequestedAt, s.timezone(&ride.PickupLocation))
+	pattern := &domain.TripPattern{
+		UserID:          ride.RiderID,
+		OriginCell:      pickup.Cell,
+		DestinationCell: dropoff.Cell,
+		DayOfWeek:       day,
+		HourOfDay:       hour,
+		Trips:           1,
+		LastTripAt:      at,
+	}
+
+	spots, err := s.pickupSpots(ctx, ride, at)
+	if err != nil {
+		return false, err
+	}
+
+	// Opted-out riders' trips still teach pickup spots, which hold no rider
+	places := []*domain.FrequentPlace{pickup, dropoff}
+	prefs, err := s.rideRepo.GetLocationHistoryPreferences(ctx, ride.RiderID)
+	if err != nil {
+		return false, err
+	}
+	consents, err := s.consentSet(ctx, ride.RiderID)
+	if err != nil {
+		return false, err
+	}
+	if prefs.OptedOut || !consents.Allows(domain.ConsentPreciseLocation) {
+		places = nil
+	}
+	if prefs.OptedOut || !consents.Allows(domain.ConsentPersonalization) {
+		pattern = nil
+	}
+
+	recorded, err := s.rideRepo.RecordUserTrip(ctx, ride.ID, ride.RiderID, places, pattern, spots)
+	if err != nil || !recorded {
+		return false, err
+	}
+
+	s.invalidate(ctx, ride.RiderID)
+	return true, nil
+}
+
+// GetUserLocationHistory gets a user's most-visited places, most-travelled
+// trip patterns and detected home and work, from the cache when present.
+// Users who opted out or withdrew personalization consent get an empty
+// history.
+func (s *LocationHistoryService) GetUserLocationHistory(ctx context.Context, userID uuid.UUID) (*domain.UserLocationHistory, error) {
+	if s.driverPool != nil {
+		if history, err := s.driverPool.GetCachedUserLocationHistory(ctx, userID); err == nil && history != nil {
+			return history, nil
+		}
+	}
+
+	prefs, err := s.rideRepo.GetLocationHistoryPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	consents, err := s.consentSet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	history := &domain.UserLocationHistory{
+		UserID:   userID,
+		Places:   make([]*domain.FrequentPlace, 0),
+		Patterns: make([]*domain.TripPattern, 0),
+	}
+	if !prefs.OptedOut && consents.Allows(domain.ConsentPersonalization) {
+		history, err = s.rideRepo.GetUserLocationHistory(ctx, userID, domain.HistoryPlaceLimit, domain.HistoryPatternLimit)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.seedHomeWork(ctx, history); err != nil {
+			return nil, err
+		}
+		history.DetectHomeWork()
+	}
+
+	if s.driverPool != nil {
+		_ = s.driverPool.CacheUserLocationHistory(ctx, history)
+	}
+	return history, nil
+}
+
+// ExportPredictionData gets everything stored about a user for prediction
+func (s *LocationHistoryService) ExportPredictionData(ctx context.Context, userID uuid.UUID) (*domain.PredictionDataExport, error) {
+	prefs, err := s.rideRepo.GetLocationHistoryPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.rideRepo.GetUserLocationHistory(ctx, userID, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.seedHomeWork(ctx, history); err != nil {
+		return nil, err
+	}
+	history.DetectHomeWork()
+
+	return &domain.PredictionDataExport{
+		UserID:         userID,
+		ExportedAt:     time.Now().UTC(),
+		OptedOut:       prefs.OptedOut,
+		Home:           history.Home,
+		Work:           history.Work,
+		FrequentPlaces: history.Places,
+		TripPatterns:   history.Patterns,
+	}, nil
+}
+
+// DeletePredictionData deletes a user's stored places and trip patterns
+func (s *LocationHistoryService) DeletePredictionData(ctx context.Context, userID uuid.UUID) error {
+	if err := s.rideRepo.DeleteUserLocationHistory(ctx, userID); err != nil {
+		return err
+	}
+	s.invalidate(ctx, userID)
+
+	log.Info().Str("user_id", userID.String()).Msg("Prediction data deleted")
+	return nil
+}
+
+// GetPreferences gets a user's location history settings
+func (s *LocationHistoryService) GetPreferences(ctx context.Context, userID uuid.UUID) (*domain.LocationHistoryPreferences, error) {
+	return s.rideRepo.GetLocationHistoryPreferences(ctx, userID)
+}
+
+// SetOptOut opts a user out of (or back into) location history. Opting out
+// stops recording and personalisation but keeps stored data until deleted.
+func (s *LocationHistoryService) SetOptOut(ctx context.Context, userID uuid.UUID, optedOut bool) (*domain.LocationHistoryPreferences, error) {
+	prefs := &domain.LocationHistoryPreferences{
+		UserID:    userID,
+		OptedOut:  optedOut,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := s.rideRepo.SaveLocationHistoryPreferences(ctx, prefs); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, userID)
+	return prefs, nil
+}
+
+// SuggestPickupSpots suggests the spots trips requested near a location, or
+// from a known place when placeID is given, commonly really start from.
+// Places without enough learned pickups fall back to the location's cell.
+func (s *LocationHistoryService) SuggestPickupSpots(ctx context.Context, lat, lng float64, placeID string) (*domain.PickupSuggestions, error) {
+	keys := []string{geo.H3Cell(lat, lng, domain.FrequentPlaceResolution)}
+	if placeID != "" {
+		keys = append([]string{domain.PickupPlaceKey(placeID)}, keys...)
+	}
+
+	suggestions := &domain.PickupSuggestions{
+		Latitude:    lat,
+		Longitude:   lng,
+		Suggestions: make([]*domain.PickupSuggestion, 0),
+	}
+	for _, key := range keys {
+		spots, total, err := s.rideRepo.GetPickupSpots(ctx, key, domain.PickupSpotMinPickups, domain.PickupSuggestionLimit)
+		if err != nil {
+			return nil, err
+		}
+		suggestions.RequestKey = key
+		if len(spots) == 0 {
+			continue
+		}
+
+		for _, spot := range spots {
+			suggestions.Suggestions = append(suggestions.Suggestions, &domain.PickupSuggestion{
+				Latitude:       spot.Latitude,
+				Longitude:      spot.Longitude,
+				Pickups:        spot.Pickups,
+				Share:          float64(spot.Pickups) / float64(total),
+				DistanceMeters: geo.HaversineDistance(lat, lng, spot.Latitude, spot.Longitude),
+			})
+		}
+		break
+	}
+	return suggestions, nil
+}
+
+// pickupSpots is where a completed ride really started, learned under the
+// cell it was requested from and under the requested place when known, or
+// nil when there is no usable GPS fix
+func (s *LocationHistoryService) pickupSpots(ctx context.Context, ride *domain.Ride, at time.Time) ([]*domain.PickupSpot, error) {
+	arrival, err := s.rideRepo.GetArrivalLocation(ctx, ride.ID)
+	if err != nil {
+		return nil, err
+	}
+	var trail []domain.TrailPoint
+	if arrival == nil {
+		if trail, err = s.rideRepo.GetLocationTrail(ctx, ride.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	point, ok := domain.ActualPickupPoint(arrival, trail)
+	if !ok {
+		return nil, nil
+	}
+	requested := &ride.PickupLocation
+	// A fix far from the requested pickup is a changed pickup or bad GPS
+	if geo.HaversineDistance(requested.Latitude, requested.Longitude, point.Latitude, point.Longitude) > domain.PickupSpotMaxDistance {
+		return nil, nil
+	}
+
+	keys := []string{geo.H3Cell(requested.Latitude, requested.Longitude, domain.FrequentPlaceResolution)}
+	if requested.PlaceID != "" {
+		keys = append(keys, domain.PickupPlaceKey(requested.PlaceID))
+	}
+	spots := make([]*domain.PickupSpot, 0, len(keys))
+	for _, key := range keys {
+		spots = append(spots, &domain.PickupSpot{
+			RequestKey:   key,
+			SpotCell:     geo.H3Cell(point.Latitude, point.Longitude, domain.PickupSpotResolution),
+			Latitude:     point.Latitude,
+			Longitude:    point.Longitude,
+			Pickups:      1,
+			LastPickupAt: at,
+		})
+	}
+	return spots, nil
+}
+
+// seedHomeWork sets the history's home and work from the user's saved
+// places, when saved places are enabled
+func (s *LocationHistoryService) seedHomeWork(ctx context.Context, history *domain.UserLocationHistory) error {
+	if s.savedPlaces == nil {
+		return nil
+	}
+	saved, err := s.savedPlaces.ListByRider(ctx, history.UserID)
+	if err != nil {
+		return err
+	}
+	history.SeedHomeWork(saved)
+	return nil
+}
+
+// consentSet gets a user's consents, or nil, for the defaults, when consent
+// management is disabled
+func (s *LocationHistoryService) consentSet(ctx context.Context, userID uuid.UUID) (*domain.ConsentSet, error) {
+	if s.consents == nil {
+		return nil, nil
+	}
+	return s.consents.Consents(ctx, userID)
+}
+
+// invalidate drops a user's cached location history
+func (s *LocationHistoryService) invalidate(ctx context.Context, userID uuid.UUID) {
+	if s.driverPool == nil {
+		return
+	}
+	if err := s.driverPool.InvalidateUserLocationHistory(ctx, userID); err != nil {
+		log.Warn().Err(err).Str("user_id", userID.String()).Msg("Failed to invalidate cached location history")
+	}
+}
+
+// place buckets a trip endpoint into its frequent place cell
+func (s *LocationHistoryService) place(userID uuid.UUID, loc *domain.Location, at time.Time) *domain.FrequentPlace {
+	return &domain.FrequentPlace{
+		UserID:         userID,
+		Cell:           geo.H3Cell(loc.Latitude, loc.Longitude, domain.FrequentPlaceResolution),
+		Latitude:       loc.Latitude,
+		Longitude:      loc.Longitude,
+		Name:           loc.Name,
+		Address:        loc.Address,
+		PlaceID:        loc.PlaceID,
+		FirstVisitedAt: at,
+		LastVisitedAt:  at,
+	}
+}
+
+// timezone is the pickup city's time zone, or UTC outside a city bundle
+func (s *LocationHistoryService) timezone(loc *domain.Location) *time.Location {
+	if s.cities != nil {
+		if city, ok := s.cities.FindByLocation(loc.Latitude, loc.Longitude); ok {
+			if tz, err := time.LoadLocation(city.Timezone); err == nil {
+				return tz
+			}
+		}
+	}
+	return time.UTC
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// Confidence given to each kind of non-search suggestion. Frequent places
+// scale with visits and popular places with trips, relative to the most
+// visited or travelled.
+const (
+	homeWorkSuggestionConfidence = 1.0
+	frequentSuggestionConfidence = 0.8
+	popularSuggestionConfidence  = 0.3
+)
+
+// LocationSuggestionService re-ranks autocomplete results with what
+// prediction knows: the rider's home, work and frequent places and their
+// city's popular destinations
+type LocationSuggestionService struct {
+	history *LocationHistoryService
+	popular *PopularLocationService
+}
+
+// NewLocationSuggestionService creates a new location suggestion service
+func NewLocationSuggestionService(history *LocationHistoryService, popular *PopularLocationService) *LocationSuggestionService {
+	return &LocationSuggestionService{
+		history: history,
+		popular: popular,
+	}
+}
+
+// Rank merges search results for input with the rider's personal places
+// and the popular destinations around lat/lng. Anonymous requests skip
+// personal places and requests without a location skip popular ones.
+// Prediction signals only ever improve the list, so failures to load them
+// are logged and the search results are still returned.
+func (s *LocationSuggestionService) Rank(ctx context.Context, userID uuid.UUID, input string, lat, lng float64, search []*domain.LocationSuggestion) []*domain.LocationSuggestion {
+	var candidates []*domain.SuggestionCandidate
+
+	if userID != uuid.Nil {
+		history, err := s.history.GetUserLocationHistory(ctx, userID)
+		if err != nil {
+			log.Warn().Err(err).Str("user_id", userID.String()).Msg("Failed to load location history for suggestions")
+		} else {
+			candidates = append(candidates, personalSuggestions(history)...)
+		}
+	}
+
+	if lat != 0 || lng != 0 {
+		popular, err := s.popular.GetPopularLocations(ctx, lat, lng)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to load popular locations for suggestions")
+		} else {
+			candidates = append(candidates, popularSuggestions(popular)...)
+		}
+	}
+
+	return domain.RankSuggestions(input, search, candidates, domain.SuggestionLimit)
+}
+
+// personalSuggestions turns a rider's home, work and frequent places into
+// suggestion candidates, home and work first
+func personalSuggestions(history *domain.UserLocationHistory) []*domain.SuggestionCandidate {
+	candidates := make([]*domain.SuggestionCandidate, 0, len(history.Places))
+	seen := make(map[string]bool)
+
+	add := func(p *domain.FrequentPlace, source domain.SuggestionSource, confidence float64) {
+		if p == nil || seen[p.Cell] || p.Name == "" && p.Address == "" {
+			return
+		}
+		seen[p.Cell] = true
+		candidates = append(candidates, &domain.SuggestionCandidate{
+			Suggestion: placeSuggestion(p, source),
+			Confidence: confidence,
+		})
+	}
+
+	add(history.Home, domain.SuggestionSourceHome, homeWorkSuggestionConfidence)
+	add(history.Work, domain.SuggestionSourceWork, homeWorkSuggestionConfidence)
+
+	var maxVisits int64
+	for _, p := range history.Places {
+		maxVisits = max(maxVisits, p.Visits())
+	}
+	for _, p := range history.Places {
+		add(p, domain.SuggestionSourceFrequent,
+			frequentSuggestionConfidence*float64(p.Visits())/float64(maxVisits))
+	}
+	return candidates
+}
+
+// popularSuggestions turns a city's popular destinations into suggestion
+// candidates
+func popularSuggestions(popular []*domain.PopularLocation) []*domain.SuggestionCandidate {
+	var maxTrips int64
+	for _, l := range popular {
+		maxTrips = max(maxTrips, l.Trips)
+	}
+
+	candidates := make([]*domain.SuggestionCandidate, 0, len(popular))
+	for _, l := range popular {
+		candidates = append(candidates, &domain.SuggestionCandidate{
+			Suggestion: domain.LocationSuggestion{
+				PlaceID:       l.PlaceID,
+				MainText:      l.Name,
+				SecondaryText: l.Address,
+				Description:   l.Address,
+				Latitude:      l.Latitude,
+				Longitude:     l.Longitude,
+				Source:        domain.SuggestionSourcePopular,
+			},
+			Confidence: popularSuggestionConfidence * float64(l.Trips) / float64(maxTrips),
+		})
+	}
+	return candidates
+}
+
+// placeSuggestion is the suggestion for one of a rider's places, named by
+// what they called it or else its address
+func placeSuggestion(p *domain.FrequentPlace, source domain.SuggestionSource) domain.LocationSuggestion {
+	s := domain.LocationSuggestion{
+		PlaceID:     p.PlaceID,
+		MainText:    p.Name,
+		Description: p.Address,
+		Latitude:    p.Latitude,
+		Longitude:   p.Longitude,
+		Source:      source,
+	}
+	if s.MainText == "" {
+		s.MainText = p.Address
+	} else {
+		s.SecondaryText = p.Address
+	}
+	return s
+}
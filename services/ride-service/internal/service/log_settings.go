@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/logging"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+)
+
+// LogSettingsService changes log levels and sampling rates at runtime.
+// Changes are stored in Redis and every instance picks them up on its next
+// sync, so turning on debug logging for a component covers the whole fleet.
+type LogSettingsService struct {
+	driverPool *redis.DriverPool
+	defaults   logging.Settings
+}
+
+// NewLogSettingsService creates a new log settings service. defaults are
+// the settings the instance started with, which a reset goes back to.
+func NewLogSettingsService(driverPool *redis.DriverPool, defaults logging.Settings) *LogSettingsService {
+	return &LogSettingsService{
+		driverPool: driverPool,
+		defaults:   defaults,
+	}
+}
+
+// Get returns the settings in force on this instance
+func (s *LogSettingsService) Get(ctx context.Context) logging.Settings {
+	return logging.Current()
+}
+
+// Update applies new settings here and stores them for the other instances
+func (s *LogSettingsService) Update(ctx context.Context, settings logging.Settings, actorID uuid.UUID) (logging.Settings, error) {
+	if err := logging.Apply(settings); err != nil {
+		return logging.Settings{}, err
+	}
+	if err := s.driverPool.SaveLogSettings(ctx, settings); err != nil {
+		return logging.Settings{}, err
+	}
+
+	current := logging.Current()
+	log.Info().
+		Str("actor_id", actorID.String()).
+		Interface("settings", current).
+		Msg("Log settings changed")
+	return current, nil
+}
+
+// Reset puts every instance back on the settings it started with
+func (s *LogSettingsService) Reset(ctx context.Context, actorID uuid.UUID) (logging.Settings, error) {
+	if err := s.driverPool.DeleteLogSettings(ctx); err != nil {
+		return logging.Settings{}, err
+	}
+	if err := logging.Apply(s.defaults); err != nil {
+		return logging.Settings{}, err
+	}
+
+	log.Info().Str("actor_id", actorID.String()).Msg("Log settings reset")
+	return logging.Current(), nil
+}
+
+// Sync brings this instance's settings in line with Redis, for changes
+// made through other instances
+func (s *LogSettingsService) Sync(ctx context.Context) error {
+	stored, err := s.driverPool.GetLogSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	settings := s.defaults
+	if stored != nil {
+		settings = *stored
+	}
+	// Compare what Apply would produce so an unchanged store is a no-op
+	before := logging.Current()
+	if err := logging.Apply(settings); err != nil {
+		return err
+	}
+	if after := logging.Current(); !reflect.DeepEqual(before, after) {
+		log.Info().Interface("settings", after).Msg("Log settings synced")
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/notification"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// Most lost item cases listed for a user
+const lostItemListLimit = 50
+
+// LostItemUpdate is a party's move of a lost item case
+type LostItemUpdate struct {
+	Status domain.LostItemStatus
+	Return *domain.LostItemReturn
+	Note   string
+}
+
+// LostItemService tracks items riders leave in drivers' vehicles, from the
+// report to the handover. The rider and driver talk over the case's masked
+// channel and each is pushed the other's updates.
+type LostItemService struct {
+	rideService  *RideService
+	lostItemRepo *repository.LostItemRepository
+	notifier     Notifier
+}
+
+// NewLostItemService creates a new lost item service
+func NewLostItemService(rideService *RideService, lostItemRepo *repository.LostItemRepository, notifier Notifier) *LostItemService {
+	return &LostItemService{
+		rideService:  rideService,
+		lostItemRepo: lostItemRepo,
+		notifier:     notifier,
+	}
+}
+
+// ReportLostItem opens a case for an item the rider left on a completed
+// ride and asks the driver to look for it
+func (s *LostItemService) ReportLostItem(ctx context.Context, rideID, riderID uuid.UUID, category domain.LostItemCategory, description string) (*domain.LostItemCase, error) {
+	ride, err := s.rideService.GetRide(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	if ride.RiderID != riderID {
+		return nil, domain.ErrForbidden
+	}
+
+	lost, err := domain.NewLostItemCase(ride, category, description, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.lostItemRepo.Create(ctx, lost); err != nil {
+		return nil, err
+	}
+
+	s.rideService.RecordEvent(ctx, domain.NewRideEvent(ride.ID, domain.RideEventLostItemReported).
+		WithActor(riderID).
+		WithData("case_id", lost.ID).
+		WithData("category", lost.Category))
+
+	s.notify(ctx, lost, lost.DriverID, "Lost item reported",
+		"A rider left "+itemLabel(lost.Category)+" in your vehicle. Please check and let them know.")
+
+	log.Info().
+		Str("case_id", lost.ID.String()).
+		Str("ride_id", ride.ID.String()).
+		Msg("Lost item reported")
+
+	return lost.View(), nil
+}
+
+// GetRideLostItem gets a ride's latest case for its rider, driver or support
+func (s *LostItemService) GetRideLostItem(ctx context.Context, rideID, userID uuid.UUID, role string) (*domain.LostItemCase, error) {
+	lost, err := s.lostItemRepo.GetLatestByRide(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	if lost.PartyOf(userID, role) == domain.RidePartyNone {
+		return nil, domain.ErrForbidden
+	}
+	return lost.View(), nil
+}
+
+// GetLostItem gets a case for its rider, driver or support
+func (s *LostItemService) GetLostItem(ctx context.Context, caseID, userID uuid.UUID, role string) (*domain.LostItemCase, error) {
+	lost, err := s.lostItemRepo.GetByID(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if lost.PartyOf(userID, role) == domain.RidePartyNone {
+		return nil, domain.ErrForbidden
+	}
+	return lost.View(), nil
+}
+
+// ListLostItems lists the cases a user is the rider or driver of
+func (s *LostItemService) ListLostItems(ctx context.Context, userID uuid.UUID) ([]*domain.LostItemCase, error) {
+	cases, err := s.lostItemRepo.ListByUser(ctx, userID, lostItemListLimit)
+	if err != nil {
+		return nil, err
+	}
+	for i, lost := range cases {
+		cases[i] = lost.View()
+	}
+	return cases, nil
+}
+
+// UpdateLostItem moves a case on behalf of its rider, driver or support and
+// tells the other party
+func (s *LostItemService) UpdateLostItem(ctx context.Context, caseID, userID uuid.UUID, role string, update *LostItemUpdate) (*domain.LostItemCase, error) {
+	lost, err := s.lostItemRepo.GetByID(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	party := lost.PartyOf(userID, role)
+	if party == domain.RidePartyNone {
+		return nil, domain.ErrForbidden
+	}
+
+	from := lost.Status
+	if err := lost.Move(party, update.Status, update.Return, update.Note, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	if err := s.lostItemRepo.Update(ctx, lost, from); err != nil {
+		return nil, err
+	}
+
+	s.rideService.RecordEvent(ctx, domain.NewRideEvent(lost.RideID, domain.RideEventLostItemUpdated).
+		WithActor(userID).
+		WithData("case_id", lost.ID).
+		WithData("from", from).
+		WithData("to", lost.Status))
+
+	title, body := lostItemMessage(lost)
+	if party != domain.RidePartyRider {
+		s.notify(ctx, lost, lost.RiderID, title, body)
+	}
+	if party != domain.RidePartyDriver {
+		s.notify(ctx, lost, lost.DriverID, title, body)
+	}
+
+	return lost.View(), nil
+}
+
+// notify pushes a case update to one of its parties
+func (s *LostItemService) notify(ctx context.Context, lost *domain.LostItemCase, userID uuid.UUID, title, body string) {
+	if s.notifier == nil {
+		return
+	}
+	err := s.notifier.SendPush(ctx, userID, title, body, notification.PriorityHigh, map[string]string{
+		"type":            "LOST_ITEM",
+		"case_id":         lost.ID.String(),
+		"ride_id":         lost.RideID.String(),
+		"status":          string(lost.Status),
+		"conversation_id": lost.ConversationID(),
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("case_id", lost.ID.String()).Msg("Failed to send lost item notification")
+	}
+}
+
+// lostItemMessage is the push for a case's new status
+func lostItemMessage(lost *domain.LostItemCase) (string, string) {
+	item := itemLabel(lost.Category)
+	switch lost.Status {
+	case domain.LostItemStatusFound:
+		return "Item found", "Your driver found " + item + ". Message them to arrange the return."
+	case domain.LostItemStatusNotFound:
+		return "Item not found", "Your driver checked and could not find " + item + "."
+	case domain.LostItemStatusReturnScheduled:
+		return "Return arranged", "The return of " + item + " is set for " + lost.Return.At.Format("Mon 2 Jan 15:04") + " UTC."
+	case domain.LostItemStatusReturned:
+		return "Item returned", "The return of " + item + " is complete."
+	default:
+		return "Lost item case closed", "The case for " + item + " was closed."
+	}
+}
+
+// itemLabel names an item category in a sentence, e.g. "a phone"
+func itemLabel(category domain.LostItemCategory) string {
+	switch category {
+	case domain.LostItemCategoryKeys, domain.LostItemCategoryDocuments, domain.LostItemCategoryClothing, domain.LostItemCategoryElectronic:
+		return "some " + strings.ToLower(string(category))
+	case domain.LostItemCategoryOther:
+		return "an item"
+	}
+	return "a " + strings.ToLower(string(category))
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// loyaltyEntriesShown is how many recent ledger entries a balance lists
+const loyaltyEntriesShown = 20
+
+// LoyaltyService accrues riders' loyalty points on completed rides and
+// keeps the ledger of points redeemed against fares
+type LoyaltyService struct {
+	repo *repository.LoyaltyRepository
+}
+
+// NewLoyaltyService creates a new loyalty service
+func NewLoyaltyService(repo *repository.LoyaltyRepository) *LoyaltyService {
+	return &LoyaltyService{repo: repo}
+}
+
+// Accrue credits the rider with the points a completed ride earned. A ride
+// earns once, however often it is reported complete.
+func (s *LoyaltyService) Accrue(ctx context.Context, ride *domain.Ride) (*domain.LoyaltyEntry, error) {
+	if !ride.EarnsLoyaltyPoints() {
+		return nil, nil
+	}
+	points := domain.LoyaltyPointsEarned(ride.Price.Total, ride.Price.Currency)
+	if points <= 0 {
+		return nil, nil
+	}
+
+	entry := &domain.LoyaltyEntry{
+		ID:        uuid.New(),
+		RiderID:   ride.RiderID,
+		RideID:    ride.ID,
+		Kind:      domain.LoyaltyEntryEarn,
+		Points:    points,
+		Amount:    ride.Price.Total,
+		Currency:  ride.Price.Currency,
+		CreatedAt: time.Now().UTC(),
+	}
+	recorded, err := s.repo.RecordEntry(ctx, entry)
+	if err != nil || !recorded {
+		return nil, err
+	}
+
+	log.Info().
+		Str("ride_id", ride.ID.String()).
+		Str("rider_id", ride.RiderID.String()).
+		Int64("points", points).
+		Msg("Loyalty points earned")
+
+	return entry, nil
+}
+
+// CheckRedemption checks the rider has the points to redeem before the
+// fare is repriced with them
+func (s *LoyaltyService) CheckRedemption(ctx context.Context, riderID uuid.UUID, points int64) error {
+	balance, err := s.repo.GetBalance(ctx, riderID)
+	if err != nil {
+		return err
+	}
+	return domain.CheckLoyaltyRedemption(points, balance)
+}
+
+// Redeem debits the points spent on a ride's fare
+func (s *LoyaltyService) Redeem(ctx context.Context, entry *domain.LoyaltyEntry) error {
+	recorded, err := s.repo.RecordEntry(ctx, entry)
+	if err != nil {
+		return err
+	}
+	if !recorded {
+		return domain.ErrLoyaltyNotRedeemable
+	}
+	return nil
+}
+
+// Release credits back the points redeemed on a ride that will not be
+// charged. Rides without a redemption are a no-op.
+func (s *LoyaltyService) Release(ctx context.Context, rideID uuid.UUID) error {
+	redeemed, err := s.repo.GetEntry(ctx, rideID, domain.LoyaltyEntryRedeem)
+	if err != nil || redeemed == nil {
+		return err
+	}
+
+	_, err = s.repo.RecordEntry(ctx, &domain.LoyaltyEntry{
+		ID:        uuid.New(),
+		RiderID:   redeemed.RiderID,
+		RideID:    rideID,
+		Kind:      domain.LoyaltyEntryRelease,
+		Points:    -redeemed.Points,
+		Amount:    redeemed.Amount,
+		Currency:  redeemed.Currency,
+		CreatedAt: time.Now().UTC(),
+	})
+	return err
+}
+
+// GetBalance gets a rider's points, what they take off a fare in currency,
+// and their recent ledger entries
+func (s *LoyaltyService) GetBalance(ctx context.Context, riderID uuid.UUID, currency domain.Currency) (*domain.LoyaltyBalance, error) {
+	points, err := s.repo.GetBalance(ctx, riderID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.ListEntries(ctx, riderID, loyaltyEntriesShown)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &domain.LoyaltyBalance{
+		RiderID:       riderID,
+		Points:        points,
+		MinRedemption: domain.LoyaltyMinRedemption,
+		Entries:       entries,
+	}
+	if value, ok := domain.LoyaltyPointsValue(points, currency); ok {
+		balance.Value = value
+		balance.Currency = currency
+	}
+	return balance, nil
+}
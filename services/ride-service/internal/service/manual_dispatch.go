@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/notification"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// ManualDispatchService lets ops hand a waiting ride to a driver they
+// choose, for VIP trips or rides matching cannot place. Scoring is skipped
+// but the driver's lock, availability and the ride's transitions are not.
+type ManualDispatchService struct {
+	rideRepo   *repository.RideRepository
+	driverRepo *repository.DriverRepository
+	driverPool *redis.DriverPool
+	notifier   Notifier
+	quality    *DriverQualityService
+	prefs      *DriverPreferenceService
+	training   *TrainingService
+}
+
+// NewManualDispatchService creates a new manual dispatch service. driverPool
+// may be nil, in which case only the database status guards the driver.
+func NewManualDispatchService(
+	rideRepo *repository.RideRepository,
+	driverRepo *repository.DriverRepository,
+	driverPool *redis.DriverPool,
+	notifier Notifier,
+) *ManualDispatchService {
+	return &ManualDispatchService{
+		rideRepo:   rideRepo,
+		driverRepo: driverRepo,
+		driverPool: driverPool,
+		notifier:   notifier,
+	}
+}
+
+// SetQualityProgram gives drivers dispatched a ride the commission discount
+// their quality tier earns
+func (s *ManualDispatchService) SetQualityProgram(quality *DriverQualityService) {
+	s.quality = quality
+}
+
+// SetDriverPreferences keeps ops from dispatching a ride to a driver its
+// rider blocked
+func (s *ManualDispatchService) SetDriverPreferences(prefs *DriverPreferenceService) {
+	s.prefs = prefs
+}
+
+// SetTraining keeps ops from dispatching pool and intercity rides to
+// drivers who have not completed their training
+func (s *ManualDispatchService) SetTraining(training *TrainingService) {
+	s.training = training
+}
+
+// DispatchRide assigns a waiting ride to an online driver ops chose
+func (s *ManualDispatchService) DispatchRide(ctx context.Context, rideID, driverID, adminID uuid.UUID, reason string) (*domain.Ride, error) {
+	ride, err := s.rideRepo.GetByID(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The pool has the driver's live status and any active restriction
+	if s.driverPool != nil {
+		if driver.Status, err = s.driverPool.GetDriverStatus(ctx, driverID); err != nil {
+			return nil, err
+		}
+	}
+	if err := driver.CheckManualDispatch(ride.Type); err != nil {
+		return nil, err
+	}
+	if s.prefs != nil {
+		blocked, err := s.prefs.IsBlocked(ctx, ride.RiderID, driverID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, domain.ErrBlockedByRider
+		}
+	}
+	if s.training != nil {
+		if err := s.training.CheckRide(ctx, driverID, ride); err != nil {
+			return nil, err
+		}
+	}
+
+	// Hold the driver as matching does while they weigh an offer. A driver
+	// already held is looking at another ride.
+	if s.driverPool != nil {
+		if err := s.driverPool.LockDriver(ctx, driverID, domain.ManualDispatchLock); err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = s.driverPool.UnlockDriver(ctx, driverID)
+		}()
+	}
+
+	if err := ride.DispatchManually(driverID, driver.Vehicle.ID, adminID, reason); err != nil {
+		return nil, err
+	}
+	discountCommission(ride, s.quality)
+	if err := s.rideRepo.AssignDriverToRide(ctx, ride); err != nil {
+		// The ride changed since it was read; it was cancelled or matched
+		if errors.Is(err, domain.ErrRideConflict) {
+			return nil, domain.ErrRideAlreadyAssigned
+		}
+		return nil, err
+	}
+
+	if s.driverPool != nil {
+		_ = s.driverPool.SetDriverStatus(ctx, driverID, domain.DriverStatusOnRide)
+		_ = s.driverPool.CacheRide(ctx, ride)
+	}
+	s.notifyDriver(ctx, driver, ride)
+
+	log.Info().
+		Str("ride_id", rideID.String()).
+		Str("driver_id", driverID.String()).
+		Str("admin_id", adminID.String()).
+		Msg("Ride dispatched manually")
+
+	return ride, nil
+}
+
+// notifyDriver tells the driver they have been given a ride, since they
+// did not accept an offer for it
+func (s *ManualDispatchService) notifyDriver(ctx context.Context, driver *domain.Driver, ride *domain.Ride) {
+	if s.notifier == nil {
+		return
+	}
+	err := s.notifier.SendPush(ctx, driver.UserID, "New trip assigned",
+		"Ops have assigned you a trip. Head to the pickup point.", notification.PriorityHigh,
+		map[string]string{
+			"type":    "manual_dispatch",
+			"ride_id": ride.ID.String(),
+		})
+	if err != nil {
+		log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to notify manually dispatched driver")
+	}
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/matching"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// matchingPool is the pool the matching engine dispatches from: nearby
+// drivers and offer locks from Redis, and drivers' records from the
+// database
+type matchingPool struct {
+	*redis.DriverPool
+	drivers *repository.DriverRepository
+}
+
+// NewMatchingPool creates the matching engine's driver pool
+func NewMatchingPool(driverPool *redis.DriverPool, driverRepo *repository.DriverRepository) matching.DriverPool {
+	return &matchingPool{DriverPool: driverPool, drivers: driverRepo}
+}
+
+// GetDriver returns a driver's record, with their status and active vehicle
+func (p *matchingPool) GetDriver(ctx context.Context, driverID uuid.UUID) (*domain.Driver, error) {
+	return p.drivers.GetByID(ctx, driverID)
+}
@@ -0,0 +1,646 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/eta"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/matching"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/pricing"
+)
+
+// fakeMatchingPool serves a fixed set of nearby drivers, as the Redis pool
+// finds them, and keeps offer locks in memory
+type fakeMatchingPool struct {
+	mu     sync.Mutex
+	nearby []*domain.NearbyDriver
+	locked map[uuid.UUID]bool
+}
+
+func newFakeMatchingPool(nearby ...*domain.NearbyDriver) *fakeMatchingPool {
+	return &fakeMatchingPool{nearby: nearby, locked: make(map[uuid.UUID]bool)}
+}
+
+func (p *fakeMatchingPool) GetNearbyDrivers(ctx context.Context, lat, lng, radiusM float64, rideType domain.RideType) ([]*domain.NearbyDriver, error) {
+	// Each search finds fresh copies, which matching is free to annotate
+	drivers := make([]*domain.NearbyDriver, len(p.nearby))
+	for i, d := range p.nearby {
+		found := *d
+		driver := *d.Driver
+		found.Driver = &driver
+		drivers[i] = &found
+	}
+	return drivers, nil
+}
+
+func (p *fakeMatchingPool) GetDriver(ctx context.Context, driverID uuid.UUID) (*domain.Driver, error) {
+	for _, d := range p.nearby {
+		if d.Driver.ID == driverID {
+			driver := *d.Driver
+			driver.Vehicle = &domain.Vehicle{ID: uuid.New(), DriverID: driverID, Type: domain.VehicleTypeCar}
+			return &driver, nil
+		}
+	}
+	return nil, domain.ErrDriverNotFound
+}
+
+func (p *fakeMatchingPool) LockDriver(ctx context.Context, driverID uuid.UUID, duration time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.locked[driverID] {
+		return domain.ErrDriverBusy
+	}
+	p.locked[driverID] = true
+	return nil
+}
+
+func (p *fakeMatchingPool) UnlockDriver(ctx context.Context, driverID uuid.UUID) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.locked, driverID)
+	return nil
+}
+
+func (p *fakeMatchingPool) IsDriverLocked(ctx context.Context, driverID uuid.UUID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.locked[driverID]
+}
+
+type sentOffer struct {
+	driverID uuid.UUID
+	offer    *domain.RideOffer
+}
+
+type fakeOfferSender struct {
+	offers chan sentOffer
+}
+
+func newFakeOfferSender() *fakeOfferSender {
+	return &fakeOfferSender{offers: make(chan sentOffer, 16)}
+}
+
+func (s *fakeOfferSender) SendOffer(ctx context.Context, driverID uuid.UUID, offer *domain.RideOffer) error {
+	s.offers <- sentOffer{driverID: driverID, offer: offer}
+	return nil
+}
+
+// await returns the next n offers sent, in the order they went out
+func (s *fakeOfferSender) await(t *testing.T, n int) []sentOffer {
+	t.Helper()
+	var offers []sentOffer
+	timeout := time.After(2 * time.Second)
+	for len(offers) < n {
+		select {
+		case offer := <-s.offers:
+			offers = append(offers, offer)
+		case <-timeout:
+			t.Fatalf("offers sent = %d, want %d", len(offers), n)
+		}
+	}
+	return offers
+}
+
+// fakeTravelTimes serves learned travel times by the origin's latitude
+type fakeTravelTimes map[float64]int64
+
+func (f fakeTravelTimes) Lookup(ctx context.Context, originLat, originLng, destLat, destLng float64) (int64, bool) {
+	seconds, ok := f[originLat]
+	return seconds, ok
+}
+
+// fakeReachability reaches a square around the pickup, as if a river
+// bounded it, and keeps the requests it was asked
+type fakeReachability struct {
+	halfSide float64
+	requests []*eta.IsochroneRequest
+}
+
+func (f *fakeReachability) Isochrones(ctx context.Context, req *eta.IsochroneRequest) ([]*eta.Isochrone, error) {
+	f.requests = append(f.requests, req)
+	return []*eta.Isochrone{{
+		Minutes: req.Minutes[0],
+		Polygon: [][2]float64{
+			{req.Lat - f.halfSide, req.Lng - f.halfSide},
+			{req.Lat - f.halfSide, req.Lng + f.halfSide},
+			{req.Lat + f.halfSide, req.Lng + f.halfSide},
+			{req.Lat + f.halfSide, req.Lng - f.halfSide},
+		},
+	}}, nil
+}
+
+type fakeRideEvents struct {
+	mu     sync.Mutex
+	events []*domain.RideEvent
+}
+
+func (f *fakeRideEvents) AppendEvents(ctx context.Context, events ...*domain.RideEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, events...)
+	return nil
+}
+
+// types returns the types of the events recorded so far
+func (f *fakeRideEvents) types() []domain.RideEventType {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	types := make([]domain.RideEventType, len(f.events))
+	for i, event := range f.events {
+		types[i] = event.Type
+	}
+	return types
+}
+
+// await waits for the timeline to hold want's count of each event type
+func (f *fakeRideEvents) await(t *testing.T, want map[domain.RideEventType]int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got := make(map[domain.RideEventType]int)
+		for _, eventType := range f.types() {
+			got[eventType]++
+		}
+		missing := false
+		for eventType, n := range want {
+			if got[eventType] != n {
+				missing = true
+			}
+		}
+		if !missing {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+var matchingPickup = domain.Location{Latitude: -1.2864, Longitude: 36.8172}
+
+// nearbyDriver places an online driver distanceM north of the pickup
+func nearbyDriver(distanceM float64, etaSeconds int64) *domain.NearbyDriver {
+	return &domain.NearbyDriver{
+		Driver: &domain.Driver{
+			ID:     uuid.New(),
+			Status: domain.DriverStatusOnline,
+			CurrentLocation: &domain.Location{
+				Latitude:  matchingPickup.Latitude + distanceM/111320,
+				Longitude: matchingPickup.Longitude,
+			},
+			Rating:         4.8,
+			AcceptanceRate: 0.9,
+		},
+		DistanceM:  distanceM,
+		ETASeconds: etaSeconds,
+	}
+}
+
+func newMatchingRide(rideType domain.RideType) *domain.Ride {
+	return &domain.Ride{
+		ID:              uuid.New(),
+		RiderID:         uuid.New(),
+		Type:            rideType,
+		Status:          domain.RideStatusSearching,
+		PickupLocation:  matchingPickup,
+		DropoffLocation: domain.Location{Latitude: -1.2676, Longitude: 36.8108},
+		Metadata:        map[string]any{},
+	}
+}
+
+// newTestEngine builds a matching engine that makes one attempt, sending
+// its offers straight away
+func newTestEngine(pool matching.DriverPool, sender matching.OfferSender) *matching.Engine {
+	config := matching.DefaultConfig()
+	config.OfferTimeout = time.Second
+	config.MatchingInterval = 10 * time.Millisecond
+	config.MaxMatchingAttempts = 1
+	config.LowPriorityHold = 0
+	return matching.NewEngine(config, pool, sender)
+}
+
+// startTestMatching starts matching a ride through the ride service, as a
+// ride request does, and stops it when the test ends
+func startTestMatching(t *testing.T, engine *matching.Engine, ride *domain.Ride) *RideService {
+	t.Helper()
+	rides := &RideService{}
+	rides.SetMatcher(engine)
+	rides.startMatching(ride)
+	t.Cleanup(func() { _ = engine.CancelMatching(ride.ID) })
+	return rides
+}
+
+func TestMatchingRanksOnLearnedPickupETAs(t *testing.T) {
+	near := nearbyDriver(500, 60)
+	far := nearbyDriver(1500, 180)
+
+	t.Run("straight-line estimates", func(t *testing.T) {
+		sender := newFakeOfferSender()
+		engine := newTestEngine(newFakeMatchingPool(near, far), sender)
+		startTestMatching(t, engine, newMatchingRide(domain.RideTypeStandard))
+
+		offers := sender.await(t, 2)
+		if offers[0].driverID != near.Driver.ID {
+			t.Error("nearer driver not offered the ride first")
+		}
+	})
+
+	t.Run("learned travel times", func(t *testing.T) {
+		sender := newFakeOfferSender()
+		engine := newTestEngine(newFakeMatchingPool(near, far), sender)
+		// Traffic makes the nearer driver the slower one to the pickup
+		engine.SetTravelTimeEstimator(fakeTravelTimes{
+			near.Driver.CurrentLocation.Latitude: 1500,
+			far.Driver.CurrentLocation.Latitude:  120,
+		})
+		startTestMatching(t, engine, newMatchingRide(domain.RideTypeStandard))
+
+		offers := sender.await(t, 2)
+		if offers[0].driverID != far.Driver.ID {
+			t.Error("quicker driver not offered the ride first")
+		}
+		if offers[0].offer.PickupETASeconds != 120 {
+			t.Errorf("offered pickup ETA = %ds, want the learned 120s", offers[0].offer.PickupETASeconds)
+		}
+	})
+
+	t.Run("bodas on car travel times", func(t *testing.T) {
+		sender := newFakeOfferSender()
+		engine := newTestEngine(newFakeMatchingPool(near, far), sender)
+		// The matrix is learned from car trips, which say nothing of how
+		// quickly a boda gets through traffic
+		engine.SetTravelTimeEstimator(fakeTravelTimes{
+			near.Driver.CurrentLocation.Latitude: 1500,
+			far.Driver.CurrentLocation.Latitude:  120,
+		})
+		startTestMatching(t, engine, newMatchingRide(domain.RideTypeBoda))
+
+		offers := sender.await(t, 2)
+		if offers[0].driverID != near.Driver.ID {
+			t.Error("boda ride ranked on car travel times")
+		}
+	})
+}
+
+func TestAcceptRideThroughMatching(t *testing.T) {
+	ctx := context.Background()
+	offered := nearbyDriver(500, 60)
+	sender := newFakeOfferSender()
+	engine := newTestEngine(newFakeMatchingPool(offered), sender)
+	ride := newMatchingRide(domain.RideTypeStandard)
+	startTestMatching(t, engine, ride)
+	sender.await(t, 1)
+
+	drivers := &DriverService{}
+	drivers.SetMatcher(engine)
+
+	if err := drivers.AcceptRide(ctx, ride.ID, uuid.New()); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("AcceptRide() by a driver never offered the ride error = %v, want ErrUnauthorized", err)
+	}
+	if err := drivers.AcceptRide(ctx, ride.ID, offered.Driver.ID); err != nil {
+		t.Fatalf("AcceptRide() by the offered driver error = %v", err)
+	}
+	if err := engine.CancelMatching(ride.ID); !errors.Is(err, domain.ErrRideNotFound) {
+		t.Errorf("ride still matching after it was accepted")
+	}
+}
+
+func TestDeclineRideThroughMatching(t *testing.T) {
+	ctx := context.Background()
+	offered := nearbyDriver(500, 60)
+	pool := newFakeMatchingPool(offered)
+	sender := newFakeOfferSender()
+	engine := newTestEngine(pool, sender)
+	ride := newMatchingRide(domain.RideTypeStandard)
+	startTestMatching(t, engine, ride)
+	sender.await(t, 1)
+
+	drivers := &DriverService{}
+	drivers.SetMatcher(engine)
+
+	if err := drivers.DeclineRide(ctx, ride.ID, offered.Driver.ID); err != nil {
+		t.Fatalf("DeclineRide() error = %v", err)
+	}
+	if pool.IsDriverLocked(ctx, offered.Driver.ID) {
+		t.Error("declining driver still locked")
+	}
+	if err := drivers.AcceptRide(ctx, ride.ID, offered.Driver.ID); err == nil {
+		t.Error("AcceptRide() after declining succeeded")
+	}
+}
+
+func TestEndedRideStopsMatching(t *testing.T) {
+	sender := newFakeOfferSender()
+	engine := newTestEngine(newFakeMatchingPool(nearbyDriver(500, 60)), sender)
+	ride := newMatchingRide(domain.RideTypeStandard)
+	rides := startTestMatching(t, engine, ride)
+	sender.await(t, 1)
+
+	rides.releaseEndedRide(context.Background(), ride)
+	if err := engine.CancelMatching(ride.ID); !errors.Is(err, domain.ErrRideNotFound) {
+		t.Error("cancelled ride still matching")
+	}
+}
+
+func TestMatchingRecordsOffersOnTheTimeline(t *testing.T) {
+	ctx := context.Background()
+	taker := nearbyDriver(500, 60)
+	decliner := nearbyDriver(900, 120)
+	sender := newFakeOfferSender()
+	engine := newTestEngine(newFakeMatchingPool(taker, decliner), sender)
+	events := &fakeRideEvents{}
+	engine.SetEventRecorder(events)
+	ride := newMatchingRide(domain.RideTypeStandard)
+	startTestMatching(t, engine, ride)
+	sender.await(t, 2)
+
+	drivers := &DriverService{}
+	drivers.SetMatcher(engine)
+	if err := drivers.DeclineRide(ctx, ride.ID, decliner.Driver.ID); err != nil {
+		t.Fatalf("DeclineRide() error = %v", err)
+	}
+	if err := drivers.AcceptRide(ctx, ride.ID, taker.Driver.ID); err != nil {
+		t.Fatalf("AcceptRide() error = %v", err)
+	}
+
+	// Offers are recorded once sent, racing the drivers' answers, so only
+	// the counts are certain
+	events.await(t, map[domain.RideEventType]int{
+		domain.RideEventMatchingAttempt: 1,
+		domain.RideEventOfferSent:       2,
+		domain.RideEventOfferDeclined:   1,
+		domain.RideEventOfferAccepted:   1,
+	})
+}
+
+func TestMatchingSkipsUnreachableDrivers(t *testing.T) {
+	near := nearbyDriver(500, 60)
+	// Closer to the pickup than the search radius but across the river
+	across := nearbyDriver(2500, 300)
+	sender := newFakeOfferSender()
+	engine := newTestEngine(newFakeMatchingPool(near, across), sender)
+	reach := &fakeReachability{halfSide: 0.01}
+	engine.SetReachabilityProvider(reach)
+	startTestMatching(t, engine, newMatchingRide(domain.RideTypeBoda))
+
+	offers := sender.await(t, 1)
+	if offers[0].driverID != near.Driver.ID {
+		t.Error("reachable driver not offered the ride")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if len(sender.offers) != 0 {
+		t.Error("driver with no road to the pickup offered the ride")
+	}
+
+	if len(reach.requests) != 1 {
+		t.Fatalf("isochrone requests = %d, want 1", len(reach.requests))
+	}
+	req := reach.requests[0]
+	if req.Profile != eta.ProfileMotorcycle || !req.Reverse {
+		t.Errorf("isochrone request = %+v, want a reverse motorcycle isochrone for a boda", req)
+	}
+	if req.Lat != matchingPickup.Latitude || req.Lng != matchingPickup.Longitude {
+		t.Errorf("isochrone around (%v, %v), want the pickup", req.Lat, req.Lng)
+	}
+}
+
+func TestMatchingAppliesTheCitysOfferRules(t *testing.T) {
+	sender := newFakeOfferSender()
+	engine := newTestEngine(newFakeMatchingPool(nearbyDriver(500, 60)), sender)
+	engine.SetCityResolver(func(lat, lng float64) (*domain.CityConfig, bool) {
+		return &domain.CityConfig{
+			Code:   "NBO",
+			Offers: domain.OfferDisclosure{Destination: domain.DisclosureHidden},
+		}, true
+	})
+	startTestMatching(t, engine, newMatchingRide(domain.RideTypeStandard))
+
+	offer := sender.await(t, 1)[0].offer
+	if offer.Destination != domain.DisclosureHidden || offer.DropoffLocation != nil {
+		t.Errorf("offer destination = %s at %v, want hidden", offer.Destination, offer.DropoffLocation)
+	}
+}
+
+func TestRequestRideRefusesPool(t *testing.T) {
+	// Dispatched today, a pool ride would go to a free driver as a solo
+	// ride at the pool fare
+	rides := &RideService{}
+	_, err := rides.RequestRide(context.Background(), &domain.RideRequest{
+		RiderID:         uuid.New(),
+		Type:            domain.RideTypePool,
+		PickupLocation:  matchingPickup,
+		DropoffLocation: domain.Location{Latitude: -1.2676, Longitude: 36.8108},
+	})
+	if !errors.Is(err, domain.ErrRideTypeUnavailable) {
+		t.Errorf("RequestRide(POOL) error = %v, want ErrRideTypeUnavailable", err)
+	}
+}
+
+func TestMatchingRanksHigherQualityTiersAhead(t *testing.T) {
+	near := nearbyDriver(500, 60)
+	gold := nearbyDriver(1000, 120)
+	sender := newFakeOfferSender()
+	engine := newTestEngine(newFakeMatchingPool(near, gold), sender)
+	engine.SetQualityBoosts(&DriverQualityService{
+		tiers: map[uuid.UUID]domain.QualityTier{gold.Driver.ID: domain.QualityTierGold},
+	})
+	startTestMatching(t, engine, newMatchingRide(domain.RideTypeStandard))
+
+	offers := sender.await(t, 2)
+	if offers[0].driverID != gold.Driver.ID {
+		t.Error("gold driver not offered the ride ahead of a slightly nearer standard one")
+	}
+}
+
+func TestMatchingHoldsLowPriorityRiders(t *testing.T) {
+	const hold = 300 * time.Millisecond
+	newHoldingEngine := func(sender *fakeOfferSender) *matching.Engine {
+		config := matching.DefaultConfig()
+		config.OfferTimeout = time.Second
+		config.MatchingInterval = 10 * time.Millisecond
+		config.MaxMatchingAttempts = 1
+		config.LowPriorityHold = hold
+		return matching.NewEngine(config, newFakeMatchingPool(nearbyDriver(500, 60)), sender)
+	}
+
+	t.Run("rider in good standing", func(t *testing.T) {
+		sender := newFakeOfferSender()
+		start := time.Now()
+		startTestMatching(t, newHoldingEngine(sender), newMatchingRide(domain.RideTypeStandard))
+
+		sender.await(t, 1)
+		if waited := time.Since(start); waited >= hold {
+			t.Errorf("offer sent after %v, want before the %v hold", waited, hold)
+		}
+	})
+
+	t.Run("deprioritized rider at high demand", func(t *testing.T) {
+		pricingEngine := pricing.NewEngine()
+		pricingEngine.UpdateSurge(geo.H3Cell(matchingPickup.Latitude, matchingPickup.Longitude, geo.H3Resolution), 0, 20)
+		sender := newFakeOfferSender()
+		engine := newHoldingEngine(sender)
+		rides := &RideService{pricingEngine: pricingEngine}
+		rides.SetRiderReliability(fakeReliability{LowerPriority: true})
+		rides.SetMatcher(engine)
+
+		start := time.Now()
+		ride, err := rides.RequestRide(context.Background(), &domain.RideRequest{
+			RiderID:         uuid.New(),
+			Type:            domain.RideTypeStandard,
+			PickupLocation:  matchingPickup,
+			DropoffLocation: domain.Location{Latitude: -1.2676, Longitude: 36.8108},
+			PaymentMethod:   domain.PaymentMethodCard,
+		})
+		if err != nil {
+			t.Fatalf("RequestRide() error = %v", err)
+		}
+		t.Cleanup(func() { _ = engine.CancelMatching(ride.ID) })
+		if ride.Metadata[domain.RiderPriorityMetadataKey] != domain.RiderPriorityLow {
+			t.Fatalf("ride priority = %v, want %s", ride.Metadata[domain.RiderPriorityMetadataKey], domain.RiderPriorityLow)
+		}
+
+		sender.await(t, 1)
+		if waited := time.Since(start); waited < hold {
+			t.Errorf("offer sent after %v, want after the %v hold", waited, hold)
+		}
+	})
+}
+
+// fakeReliability reports every rider with the same record
+type fakeReliability domain.RiderReliability
+
+func (f fakeReliability) GetReliability(ctx context.Context, riderID uuid.UUID) (*domain.RiderReliability, error) {
+	reliability := domain.RiderReliability(f)
+	return &reliability, nil
+}
+
+// fakeDriverPreferences gives every rider the same favorite and blocked
+// drivers
+type fakeDriverPreferences struct {
+	favorite, blocked uuid.UUID
+}
+
+func (f fakeDriverPreferences) DriverPreferences(ctx context.Context, riderID uuid.UUID) (*domain.DriverPreferenceSet, error) {
+	return domain.NewDriverPreferenceSet([]*domain.DriverPreference{
+		{RiderID: riderID, DriverID: f.favorite, Kind: domain.DriverPreferenceFavorite},
+		{RiderID: riderID, DriverID: f.blocked, Kind: domain.DriverPreferenceBlocked},
+	}), nil
+}
+
+func TestMatchingAppliesRiderDriverPreferences(t *testing.T) {
+	blocked := nearbyDriver(300, 40)
+	near := nearbyDriver(500, 60)
+	favorite := nearbyDriver(1500, 180)
+	sender := newFakeOfferSender()
+	engine := newTestEngine(newFakeMatchingPool(blocked, near, favorite), sender)
+	engine.SetDriverPreferences(fakeDriverPreferences{favorite: favorite.Driver.ID, blocked: blocked.Driver.ID})
+	startTestMatching(t, engine, newMatchingRide(domain.RideTypeStandard))
+
+	offers := sender.await(t, 2)
+	if offers[0].driverID != favorite.Driver.ID {
+		t.Error("favorite driver not offered the ride ahead of nearer ones")
+	}
+	for _, o := range offers {
+		if o.driverID == blocked.Driver.ID {
+			t.Error("blocked driver offered the ride")
+		}
+	}
+	select {
+	case o := <-sender.offers:
+		t.Errorf("unexpected offer to %s", o.driverID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// fakeVehicles serves drivers' active vehicles with the given powertrains
+type fakeVehicles map[uuid.UUID]domain.Powertrain
+
+func (f fakeVehicles) GetActiveVehicles(ctx context.Context, driverIDs []uuid.UUID) (map[uuid.UUID]*domain.Vehicle, error) {
+	vehicles := make(map[uuid.UUID]*domain.Vehicle)
+	for _, id := range driverIDs {
+		if powertrain, ok := f[id]; ok {
+			vehicles[id] = &domain.Vehicle{ID: uuid.New(), DriverID: id, Type: domain.VehicleTypeCar, Powertrain: powertrain}
+		}
+	}
+	return vehicles, nil
+}
+
+func TestMatchingOffersGreenRidesToElectricVehiclesFirst(t *testing.T) {
+	near := nearbyDriver(500, 60)
+	electric := nearbyDriver(1500, 180)
+
+	for _, tt := range []struct {
+		name       string
+		preference domain.RidePreference
+		wantFirst  uuid.UUID
+	}{
+		{"no preference", "", near.Driver.ID},
+		{"green", domain.RidePreferenceGreen, electric.Driver.ID},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := newFakeOfferSender()
+			engine := newTestEngine(newFakeMatchingPool(near, electric), sender)
+			engine.SetVehicleLookup(fakeVehicles{
+				near.Driver.ID:     domain.PowertrainICE,
+				electric.Driver.ID: domain.PowertrainElectric,
+			})
+			rides := &RideService{pricingEngine: pricing.NewEngine()}
+			rides.SetMatcher(engine)
+
+			ride, err := rides.RequestRide(context.Background(), &domain.RideRequest{
+				RiderID:         uuid.New(),
+				Type:            domain.RideTypeStandard,
+				PickupLocation:  matchingPickup,
+				DropoffLocation: domain.Location{Latitude: -1.2676, Longitude: 36.8108},
+				PaymentMethod:   domain.PaymentMethodCard,
+				Preference:      tt.preference,
+			})
+			if err != nil {
+				t.Fatalf("RequestRide() error = %v", err)
+			}
+			t.Cleanup(func() { _ = engine.CancelMatching(ride.ID) })
+
+			offers := sender.await(t, 2)
+			if offers[0].driverID != tt.wantFirst {
+				t.Errorf("first offer to %s, want %s", offers[0].driverID, tt.wantFirst)
+			}
+		})
+	}
+}
+
+// fakeTrainingGate clears only the given drivers for every ride
+type fakeTrainingGate map[uuid.UUID]bool
+
+func (f fakeTrainingGate) ClearedForRide(ctx context.Context, ride *domain.Ride, driverIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	cleared := make(map[uuid.UUID]bool)
+	for _, id := range driverIDs {
+		cleared[id] = f[id]
+	}
+	return cleared, nil
+}
+
+func TestMatchingOffersOnlyTrainedDrivers(t *testing.T) {
+	untrained := nearbyDriver(500, 60)
+	trained := nearbyDriver(1500, 180)
+	sender := newFakeOfferSender()
+	engine := newTestEngine(newFakeMatchingPool(untrained, trained), sender)
+	engine.SetTrainingGate(fakeTrainingGate{trained.Driver.ID: true})
+	startTestMatching(t, engine, newMatchingRide(domain.RideTypeStandard))
+
+	offers := sender.await(t, 1)
+	if offers[0].driverID != trained.Driver.ID {
+		t.Error("ride offered to a driver without the training it requires")
+	}
+	select {
+	case o := <-sender.offers:
+		t.Errorf("unexpected offer to %s", o.driverID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/eta"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// PickupETAService previews how soon a driver of each ride type could reach
+// a pickup, before the rider requests
+type PickupETAService struct {
+	driverPool   *redis.DriverPool
+	driverRepo   *repository.DriverRepository
+	travelMatrix *eta.TravelMatrix
+	traffic      *eta.H3TrafficService
+	cities       *cityconfig.Registry
+}
+
+// NewPickupETAService creates a new pickup ETA service. Drivers' travel
+// times come from the travel matrix where it has the cell pair, else a
+// straight-line estimate adjusted for current traffic.
+func NewPickupETAService(
+	driverPool *redis.DriverPool,
+	driverRepo *repository.DriverRepository,
+	travelMatrix *eta.TravelMatrix,
+	traffic *eta.H3TrafficService,
+	cities *cityconfig.Registry,
+) *PickupETAService {
+	return &PickupETAService{
+		driverPool:   driverPool,
+		driverRepo:   driverRepo,
+		travelMatrix: travelMatrix,
+		traffic:      traffic,
+		cities:       cities,
+	}
+}
+
+// Preview gets the pickup ETA of each ride type offered at a location,
+// cached briefly per cell
+func (s *PickupETAService) Preview(ctx context.Context, lat, lng float64) (*domain.PickupETAPreview, error) {
+	cell := geo.H3Cell(lat, lng, domain.PickupETAResolution)
+	if cached, err := s.driverPool.GetCachedPickupETAPreview(ctx, cell); err == nil && cached != nil {
+		return cached, nil
+	}
+
+	drivers, err := s.driverPool.GetNearbyDrivers(ctx, lat, lng, domain.PickupETASearchRadius, "")
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(drivers))
+	for _, d := range drivers {
+		ids = append(ids, d.Driver.ID)
+	}
+	vehicles, err := s.driverRepo.GetActiveVehicles(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range drivers {
+		d.Driver.Vehicle = vehicles[d.Driver.ID]
+	}
+
+	now := time.Now().UTC()
+	rideTypes := domain.AllRideTypes
+	if city, ok := s.cities.FindByLocation(lat, lng); ok {
+		rideTypes = city.RideTypes
+	}
+
+	preview := &domain.PickupETAPreview{Cell: cell, ComputedAt: now}
+	for _, rideType := range rideTypes {
+		// Drivers come nearest first, so only the first few need routing
+		var best int64
+		nearby := 0
+		for _, d := range drivers {
+			if !d.Driver.CanAcceptRideType(rideType) {
+				continue
+			}
+			nearby++
+			if nearby > domain.PickupETADriverLimit {
+				continue
+			}
+			if seconds := s.travelTime(ctx, d, lat, lng, now); best == 0 || seconds < best {
+				best = seconds
+			}
+		}
+		preview.ETAs = append(preview.ETAs, domain.NewPickupETA(rideType, best, nearby))
+	}
+
+	// A failed cache write only costs the next rider a fresh lookup
+	_ = s.driverPool.CachePickupETAPreview(ctx, preview)
+	return preview, nil
+}
+
+// travelTime estimates a driver's travel time to the pickup in seconds
+func (s *PickupETAService) travelTime(ctx context.Context, d *domain.NearbyDriver, lat, lng float64, now time.Time) int64 {
+	loc := d.Driver.CurrentLocation
+	// The matrix is learned from car trips, so bikes take the speed estimate
+	if s.travelMatrix != nil && eta.ProfileForVehicle(d.Driver.Vehicle.Type) == eta.ProfileCar {
+		if seconds, ok := s.travelMatrix.Lookup(ctx, loc.Latitude, loc.Longitude, lat, lng); ok {
+			return seconds
+		}
+	}
+
+	vType := "car"
+	switch d.Driver.Vehicle.Type {
+	case domain.VehicleTypeBike:
+		vType = "bike"
+	case domain.VehicleTypeTricycle:
+		vType = "tricycle"
+	}
+	seconds := geo.EstimateETA(d.DistanceM, vType)
+	if s.traffic != nil {
+		seconds = int64(float64(seconds) * s.traffic.GetTrafficMultiplier(lat, lng, now))
+	}
+	return seconds
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// popularCandidateLimit is the most destinations ranked across all cities
+// before they are split by city
+const popularCandidateLimit = 2000
+
+// PopularLocationService ranks each city's most travelled destinations from
+// completed rides, for suggesting destinations to riders with no history
+type PopularLocationService struct {
+	rideRepo   *repository.RideRepository
+	driverPool *redis.DriverPool
+	cities     *cityconfig.Registry
+	maps       *geo.MapsClient
+}
+
+// NewPopularLocationService creates a new popular location service. maps
+// may be nil or unconfigured, in which case destinations are not
+// categorized.
+func NewPopularLocationService(
+	rideRepo *repository.RideRepository,
+	driverPool *redis.DriverPool,
+	cities *cityconfig.Registry,
+	maps *geo.MapsClient,
+) *PopularLocationService {
+	return &PopularLocationService{
+		rideRepo:   rideRepo,
+		driverPool: driverPool,
+		cities:     cities,
+		maps:       maps,
+	}
+}
+
+// Run recomputes every city's popular destinations and stores them for the
+// prediction lookups
+func (s *PopularLocationService) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+	candidates, err := s.rideRepo.AggregatePopularDestinations(ctx,
+		now.Add(-domain.PopularLocationWindow), domain.PopularLocationMinRiders, popularCandidateLimit)
+	if err != nil {
+		return err
+	}
+
+	popular := &domain.PopularLocations{
+		ComputedAt: now,
+		Cities:     make(map[string][]*domain.PopularLocation),
+	}
+	for _, l := range candidates {
+		city, ok := s.cities.FindByLocation(l.Latitude, l.Longitude)
+		if !ok || len(popular.Cities[city.Code]) >= domain.PopularLocationLimit {
+			continue
+		}
+		l.Category = s.categorize(ctx, l.PlaceID)
+		popular.Cities[city.Code] = append(popular.Cities[city.Code], l)
+	}
+
+	if err := s.driverPool.SetPopularLocations(ctx, popular, domain.PopularLocationRefreshTTL); err != nil {
+		return err
+	}
+
+	log.Info().Int("cities", len(popular.Cities)).Int("candidates", len(candidates)).Msg("Popular locations refreshed")
+	return nil
+}
+
+// GetPopularLocations gets the popular destinations of the city containing
+// a location, empty outside a city or before the first refresh
+func (s *PopularLocationService) GetPopularLocations(ctx context.Context, lat, lng float64) ([]*domain.PopularLocation, error) {
+	city, ok := s.cities.FindByLocation(lat, lng)
+	if !ok {
+		return []*domain.PopularLocation{}, nil
+	}
+
+	popular, err := s.driverPool.GetPopularLocations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if popular == nil || popular.Cities[city.Code] == nil {
+		return []*domain.PopularLocation{}, nil
+	}
+	return popular.Cities[city.Code], nil
+}
+
+// categorize looks up a place's category from the map provider's place
+// types
+func (s *PopularLocationService) categorize(ctx context.Context, placeID string) domain.PlaceCategory {
+	if placeID == "" || s.maps == nil || !s.maps.IsConfigured() {
+		return domain.PlaceCategoryOther
+	}
+
+	details, err := s.maps.GetPlaceDetails(ctx, geo.PlaceDetailsRequest{PlaceID: placeID, Fields: "types"})
+	if err != nil {
+		log.Warn().Err(err).Str("place_id", placeID).Msg("Failed to categorize popular location")
+		return domain.PlaceCategoryOther
+	}
+	return domain.PlaceCategoryFromTypes(details.Result.Types)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// Violations buffered between flushes; beyond this they are dropped rather
+// than slow down pricing
+const priceControlQueueSize = 1000
+
+// PriceControlService collects fares clamped to regulatory price controls and
+// builds the compliance report from them
+type PriceControlService struct {
+	repo  *repository.PriceControlRepository
+	queue chan *domain.PriceControlViolation
+}
+
+// NewPriceControlService creates a new price control service
+func NewPriceControlService(repo *repository.PriceControlRepository) *PriceControlService {
+	return &PriceControlService{
+		repo:  repo,
+		queue: make(chan *domain.PriceControlViolation, priceControlQueueSize),
+	}
+}
+
+// RecordPriceControlViolation queues a violation for storage without blocking
+// the pricing path
+func (s *PriceControlService) RecordPriceControlViolation(v *domain.PriceControlViolation) {
+	select {
+	case s.queue <- v:
+	default:
+		log.Warn().Str("market", v.Market).Str("control", string(v.Control)).Msg("Price control violation queue full, dropping")
+	}
+}
+
+// StartFlushJob stores queued violations every interval until ctx is cancelled
+func (s *PriceControlService) StartFlushJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Store what is left with a fresh context so shutdown does not lose it
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			s.flush(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+func (s *PriceControlService) flush(ctx context.Context) {
+	var batch []*domain.PriceControlViolation
+drain:
+	for {
+		select {
+		case v := <-s.queue:
+			batch = append(batch, v)
+		default:
+			break drain
+		}
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	if err := s.repo.InsertViolations(ctx, batch); err != nil {
+		log.Error().Err(err).Int("violations", len(batch)).Msg("Failed to store price control violations")
+	}
+}
+
+// GetReport gets the compliance report for [from, to)
+func (s *PriceControlService) GetReport(ctx context.Context, from, to time.Time) (*domain.PriceControlReport, error) {
+	rows, err := s.repo.Report(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PriceControlReport{From: from, To: to, Rows: rows}, nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// PromoService manages promo campaigns and applies their codes to rides
+type PromoService struct {
+	promoRepo *repository.PromoRepository
+}
+
+// NewPromoService creates a new promo service
+func NewPromoService(promoRepo *repository.PromoRepository) *PromoService {
+	return &PromoService{promoRepo: promoRepo}
+}
+
+// CreateCampaign validates and stores a new campaign
+func (s *PromoService) CreateCampaign(ctx context.Context, campaign *domain.PromoCampaign, adminID uuid.UUID) (*domain.PromoCampaign, error) {
+	now := time.Now().UTC()
+	if campaign.StartsAt.IsZero() {
+		campaign.StartsAt = now
+	}
+	if err := campaign.Validate(); err != nil {
+		return nil, err
+	}
+
+	campaign.ID = uuid.New()
+	campaign.Status = domain.PromoCampaignActive
+	campaign.Spent = 0
+	campaign.CreatedBy = adminID
+	campaign.CreatedAt = now
+	campaign.UpdatedAt = now
+
+	if err := s.promoRepo.CreateCampaign(ctx, campaign); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("campaign_id", campaign.ID.String()).
+		Str("name", campaign.Name).
+		Strs("codes", campaign.Codes).
+		Int64("budget", campaign.Budget).
+		Str("created_by", adminID.String()).
+		Msg("Promo campaign created")
+
+	return campaign, nil
+}
+
+// GetCampaign gets a campaign by ID
+func (s *PromoService) GetCampaign(ctx context.Context, id uuid.UUID) (*domain.PromoCampaign, error) {
+	return s.promoRepo.GetCampaign(ctx, id)
+}
+
+// ListCampaigns lists campaigns, optionally by status
+func (s *PromoService) ListCampaigns(ctx context.Context, status domain.PromoCampaignStatus) ([]*domain.PromoCampaign, error) {
+	return s.promoRepo.ListCampaigns(ctx, status)
+}
+
+// PauseCampaign stops an active campaign's codes from being redeemed
+func (s *PromoService) PauseCampaign(ctx context.Context, id uuid.UUID) (*domain.PromoCampaign, error) {
+	if err := s.promoRepo.UpdateCampaignStatus(ctx, id, domain.PromoCampaignActive, domain.PromoCampaignPaused); err != nil {
+		return nil, err
+	}
+	return s.promoRepo.GetCampaign(ctx, id)
+}
+
+// ResumeCampaign reactivates a paused campaign that still has budget
+func (s *PromoService) ResumeCampaign(ctx context.Context, id uuid.UUID) (*domain.PromoCampaign, error) {
+	campaign, err := s.promoRepo.GetCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Remaining() == 0 {
+		return nil, domain.ErrPromoCampaignStatus
+	}
+
+	if err := s.promoRepo.UpdateCampaignStatus(ctx, id, domain.PromoCampaignPaused, domain.PromoCampaignActive); err != nil {
+		return nil, err
+	}
+	return s.promoRepo.GetCampaign(ctx, id)
+}
+
+// UpdateCampaign changes a campaign's name, budget, caps, targeting or end
+func (s *PromoService) UpdateCampaign(ctx context.Context, id uuid.UUID, update *domain.PromoCampaign) (*domain.PromoCampaign, error) {
+	campaign, err := s.promoRepo.UpdateCampaign(ctx, id, func(campaign *domain.PromoCampaign) error {
+		return campaign.Update(update)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("campaign_id", campaign.ID.String()).
+		Str("status", string(campaign.Status)).
+		Int64("budget", campaign.Budget).
+		Int64("spent", campaign.Spent).
+		Msg("Promo campaign updated")
+
+	return campaign, nil
+}
+
+// AddCodes adds codes to a campaign, sharing its budget and targeting
+func (s *PromoService) AddCodes(ctx context.Context, id uuid.UUID, codes []string) (*domain.PromoCampaign, error) {
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("%w: at least one code is required", domain.ErrInvalidPromoCampaign)
+	}
+	if err := domain.NormalizePromoCodes(codes); err != nil {
+		return nil, err
+	}
+	if err := s.promoRepo.AddCodes(ctx, id, codes); err != nil {
+		return nil, err
+	}
+	return s.promoRepo.GetCampaign(ctx, id)
+}
+
+// RemoveCode stops one of a campaign's codes from being redeemed
+func (s *PromoService) RemoveCode(ctx context.Context, id uuid.UUID, code string) (*domain.PromoCampaign, error) {
+	if err := s.promoRepo.RemoveCode(ctx, id, domain.NormalizePromoCode(code)); err != nil {
+		return nil, err
+	}
+	return s.promoRepo.GetCampaign(ctx, id)
+}
+
+// GetCampaignStats gets a campaign's redemptions and incremental trips
+func (s *PromoService) GetCampaignStats(ctx context.Context, id uuid.UUID) (*domain.PromoCampaignStats, error) {
+	campaign, err := s.promoRepo.GetCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.promoRepo.GetCampaignStats(ctx, campaign, domain.PromoIncrementalWindowDays)
+}
+
+// IssueVoucher creates a single-use code worth a flat amount that only the
+// given rider can redeem, valid for the given period
+func (s *PromoService) IssueVoucher(
+	ctx context.Context,
+	riderID uuid.UUID,
+	currency domain.Currency,
+	amount int64,
+	validity time.Duration,
+	name string,
+) (*domain.PromoCampaign, error) {
+	now := time.Now().UTC()
+	endsAt := now.Add(validity)
+	code := "UBI-" + strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", "")[:10])
+
+	return s.CreateCampaign(ctx, &domain.PromoCampaign{
+		Name:           name,
+		Codes:          []string{code},
+		Currency:       currency,
+		DiscountType:   domain.PromoDiscountFlat,
+		DiscountAmount: amount,
+		Budget:         amount,
+		PerUserLimit:   1,
+		Targeting:      domain.PromoTargeting{Riders: []uuid.UUID{riderID}},
+		StartsAt:       now,
+		EndsAt:         &endsAt,
+	}, uuid.Nil)
+}
+
+// Evaluate finds the campaign behind a code and checks it applies to the
+// rider and trip. trip's rider history and redemptions are filled in here.
+func (s *PromoService) Evaluate(ctx context.Context, code string, riderID uuid.UUID, trip domain.PromoTrip) (*domain.PromoCampaign, error) {
+	campaign, err := s.promoRepo.GetCampaignByCode(ctx, domain.NormalizePromoCode(code))
+	if err == domain.ErrPromoCampaignNotFound {
+		return nil, domain.ErrInvalidPromoCode
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	trip.RiderID = riderID
+	trip.CompletedRides, trip.LastCompletedAt, err = s.promoRepo.GetRiderHistory(ctx, riderID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.PerUserLimit > 0 {
+		trip.Redemptions, err = s.promoRepo.CountRiderRedemptions(ctx, campaign.ID, riderID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := campaign.CheckEligibility(trip); err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+// Redeem reserves a redemption against its campaign's budget, pausing the
+// campaign automatically once the budget is used up
+func (s *PromoService) Redeem(ctx context.Context, redemption *domain.PromoRedemption) error {
+	exhausted, err := s.promoRepo.Redeem(ctx, redemption)
+	if err != nil {
+		return err
+	}
+
+	if exhausted {
+		log.Info().
+			Str("campaign_id", redemption.CampaignID.String()).
+			Msg("Promo campaign budget exhausted - campaign paused")
+	}
+	return nil
+}
+
+// Release returns a cancelled ride's redemption to its campaign's budget
+func (s *PromoService) Release(ctx context.Context, rideID uuid.UUID) error {
+	return s.promoRepo.Release(ctx, rideID)
+}
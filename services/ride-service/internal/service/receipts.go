@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// receiptPublishBatchSize is the most pending receipt events sent per run
+const receiptPublishBatchSize = 200
+
+// ReceiptPublisher sends receipt issued events to the notification service
+type ReceiptPublisher interface {
+	PublishReceipt(ctx context.Context, receipt *domain.RideReceipt) error
+}
+
+// ReceiptService issues a receipt for each completed ride and publishes it
+// for the notification service to email. Events that fail to publish are
+// retried by Run.
+type ReceiptService struct {
+	rideService *RideService
+	receiptRepo *repository.ReceiptRepository
+	publisher   ReceiptPublisher
+}
+
+// NewReceiptService creates a new receipt service. publisher may be nil, in
+// which case receipts are issued but not emailed.
+func NewReceiptService(rideService *RideService, receiptRepo *repository.ReceiptRepository, publisher ReceiptPublisher) *ReceiptService {
+	return &ReceiptService{
+		rideService: rideService,
+		receiptRepo: receiptRepo,
+		publisher:   publisher,
+	}
+}
+
+// IssueReceipt issues a completed ride's receipt, or returns the one it
+// already has
+func (s *ReceiptService) IssueReceipt(ctx context.Context, ride *domain.Ride) (*domain.RideReceipt, error) {
+	receipt, err := domain.NewRideReceipt(ride, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.receiptRepo.Create(ctx, receipt)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return s.receiptRepo.GetByRide(ctx, ride.ID)
+	}
+
+	s.rideService.RecordEvent(ctx, domain.NewRideEvent(ride.ID, domain.RideEventReceiptIssued).
+		WithData("receipt_id", receipt.ID).
+		WithData("number", receipt.Number))
+	s.publish(ctx, receipt)
+
+	log.Info().
+		Str("ride_id", ride.ID.String()).
+		Str("receipt", receipt.Number).
+		Msg("Ride receipt issued")
+
+	return receipt, nil
+}
+
+// GetReceipt gets a ride's receipt for its rider or support staff, issuing
+// it if completion did not
+func (s *ReceiptService) GetReceipt(ctx context.Context, rideID, userID uuid.UUID, role string) (*domain.RideReceipt, error) {
+	ride, err := s.rideService.GetRide(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	switch ride.PartyOf(userID, role) {
+	case domain.RidePartyRider, domain.RidePartySupport:
+	default:
+		return nil, domain.ErrForbidden
+	}
+
+	receipt, err := s.receiptRepo.GetByRide(ctx, rideID)
+	if err == domain.ErrReceiptNotFound {
+		return s.IssueReceipt(ctx, ride)
+	}
+	return receipt, err
+}
+
+// Run publishes the events of receipts that failed to go out
+func (s *ReceiptService) Run(ctx context.Context) error {
+	if s.publisher == nil {
+		return nil
+	}
+
+	receipts, err := s.receiptRepo.ListUnpublished(ctx, receiptPublishBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, receipt := range receipts {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.publish(ctx, receipt)
+	}
+	return nil
+}
+
+// publish sends a receipt's issued event and marks it sent, logging rather
+// than failing so Run can retry
+func (s *ReceiptService) publish(ctx context.Context, receipt *domain.RideReceipt) {
+	if s.publisher == nil {
+		return
+	}
+	err := s.publisher.PublishReceipt(ctx, receipt)
+	if err == nil {
+		err = s.receiptRepo.MarkPublished(ctx, receipt.ID, time.Now().UTC())
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("ride_id", receipt.RideID.String()).Msg("Failed to publish ride receipt")
+	}
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/payment"
+)
+
+// PaymentGateway executes refunds and credits with the payment provider
+type PaymentGateway interface {
+	CapturedPayment(ctx context.Context, referenceID string) (*payment.Payment, error)
+	RefundPayment(ctx context.Context, paymentID string, amount int64, currency domain.Currency, reason string) (*payment.RefundResult, error)
+	IssueCredit(ctx context.Context, userID string, amount int64, currency domain.Currency, reference, reason string) (*payment.RefundResult, error)
+}
+
+// RefundStore stores refunds and their ledger entries
+type RefundStore interface {
+	// Create settles a new refund against the ride's fare and what was
+	// already issued on it, and stores it, atomically per ride
+	Create(ctx context.Context, refund *domain.Refund, fare int64) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Refund, error)
+	ListByRide(ctx context.Context, rideID uuid.UUID) ([]*domain.Refund, error)
+	TotalRefunded(ctx context.Context, rideID uuid.UUID) (int64, error)
+	UpdateStatus(ctx context.Context, refund *domain.Refund, fromStatus domain.RefundStatus) error
+	Complete(ctx context.Context, refund *domain.Refund, entries []*domain.LedgerEntry) error
+	GetLedgerEntries(ctx context.Context, rideID uuid.UUID) ([]*domain.LedgerEntry, error)
+}
+
+// RefundRides reads the rides refunds are made against and records their
+// fare adjustments
+type RefundRides interface {
+	GetRide(ctx context.Context, rideID uuid.UUID) (*domain.Ride, error)
+	RecordEvent(ctx context.Context, event *domain.RideEvent)
+}
+
+// RefundRequest is a support agent's request to refund or credit a ride
+type RefundRequest struct {
+	RideID      uuid.UUID
+	Type        domain.RefundType
+	Reason      domain.RefundReason
+	Note        string
+	Amount      int64 // ignored for FULL refunds
+	RequestedBy uuid.UUID
+}
+
+// RefundService handles refunds, goodwill credits and their ledger entries
+type RefundService struct {
+	rideService RefundRides
+	refundRepo  RefundStore
+	payments    PaymentGateway
+}
+
+// NewRefundService creates a new refund service
+func NewRefundService(
+	rideService RefundRides,
+	refundRepo RefundStore,
+	payments PaymentGateway,
+) *RefundService {
+	return &RefundService{
+		rideService: rideService,
+		refundRepo:  refundRepo,
+		payments:    payments,
+	}
+}
+
+// RequestRefund creates a refund. Refunds keeping what was issued on the
+// ride under the approval threshold are executed immediately; the rest wait
+// for a second approver.
+func (s *RefundService) RequestRefund(ctx context.Context, req *RefundRequest) (*domain.Refund, error) {
+	if !req.Reason.IsValid() {
+		return nil, domain.ErrRefundInvalidReason
+	}
+
+	ride, err := s.rideService.GetRide(ctx, req.RideID)
+	if err != nil {
+		return nil, err
+	}
+	if ride.Status != domain.RideStatusCompleted || ride.Price == nil {
+		return nil, domain.ErrRideNotRefundable
+	}
+
+	// The amount and approval are settled as the refund is stored, under a
+	// lock on the ride, so concurrent refunds cannot together exceed its fare
+	now := time.Now().UTC()
+	refund := &domain.Refund{
+		ID:          uuid.New(),
+		RideID:      ride.ID,
+		RiderID:     ride.RiderID,
+		Type:        req.Type,
+		Reason:      req.Reason,
+		Note:        req.Note,
+		Amount:      req.Amount,
+		Currency:    ride.Price.Currency,
+		RequestedBy: req.RequestedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.refundRepo.Create(ctx, refund, ride.Price.Total); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("refund_id", refund.ID.String()).
+		Str("ride_id", ride.ID.String()).
+		Str("type", string(refund.Type)).
+		Int64("amount", refund.Amount).
+		Str("status", string(refund.Status)).
+		Msg("Refund requested")
+
+	if refund.Status == domain.RefundStatusPendingApproval {
+		return refund, nil
+	}
+
+	return s.execute(ctx, refund, ride)
+}
+
+// ApproveRefund approves a pending refund and executes it
+func (s *RefundService) ApproveRefund(ctx context.Context, refundID, approverID uuid.UUID) (*domain.Refund, error) {
+	refund, err := s.refundRepo.GetByID(ctx, refundID)
+	if err != nil {
+		return nil, err
+	}
+	if refund.Status != domain.RefundStatusPendingApproval {
+		return nil, domain.ErrRefundNotPending
+	}
+	if refund.RequestedBy == approverID {
+		return nil, domain.ErrRefundSelfApproval
+	}
+
+	ride, err := s.rideService.GetRide(ctx, refund.RideID)
+	if err != nil {
+		return nil, err
+	}
+
+	refund.Status = domain.RefundStatusProcessing
+	refund.ApprovedBy = &approverID
+	if err := s.refundRepo.UpdateStatus(ctx, refund, domain.RefundStatusPendingApproval); err != nil {
+		return nil, err
+	}
+
+	return s.execute(ctx, refund, ride)
+}
+
+// RejectRefund rejects a pending refund
+func (s *RefundService) RejectRefund(ctx context.Context, refundID, approverID uuid.UUID, reason string) (*domain.Refund, error) {
+	refund, err := s.refundRepo.GetByID(ctx, refundID)
+	if err != nil {
+		return nil, err
+	}
+	if refund.Status != domain.RefundStatusPendingApproval {
+		return nil, domain.ErrRefundNotPending
+	}
+
+	refund.Status = domain.RefundStatusRejected
+	refund.ApprovedBy = &approverID
+	refund.FailureReason = reason
+	if err := s.refundRepo.UpdateStatus(ctx, refund, domain.RefundStatusPendingApproval); err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// ListRefunds lists refunds for a ride
+func (s *RefundService) ListRefunds(ctx context.Context, rideID uuid.UUID) ([]*domain.Refund, error) {
+	return s.refundRepo.ListByRide(ctx, rideID)
+}
+
+// TotalRefunded sums the fare refunded, or being refunded, on a ride
+func (s *RefundService) TotalRefunded(ctx context.Context, rideID uuid.UUID) (int64, error) {
+	return s.refundRepo.TotalRefunded(ctx, rideID)
+}
+
+// GetLedger lists ledger entries for a ride
+func (s *RefundService) GetLedger(ctx context.Context, rideID uuid.UUID) ([]*domain.LedgerEntry, error) {
+	return s.refundRepo.GetLedgerEntries(ctx, rideID)
+}
+
+// execute sends the refund to the payment provider and records ledger entries
+func (s *RefundService) execute(ctx context.Context, refund *domain.Refund, ride *domain.Ride) (*domain.Refund, error) {
+	var result *payment.RefundResult
+	var err error
+
+	switch {
+	case ride.CheckSettlementCurrency(refund.Currency) != nil:
+		// Money only moves in the ride's own currency
+		err = domain.ErrSettlementCurrency
+	case refund.Type == domain.RefundTypeGoodwillCredit:
+		result, err = s.payments.IssueCredit(ctx, ride.RiderID.String(), refund.Amount, refund.Currency,
+			refund.ID.String(), string(refund.Reason))
+	default:
+		var paymentID string
+		paymentID, err = ridePaymentID(ctx, s.payments, ride.ID)
+		if err == nil {
+			result, err = s.payments.RefundPayment(ctx, paymentID, refund.Amount, refund.Currency, string(refund.Reason))
+		}
+	}
+
+	if err != nil {
+		log.Error().Err(err).Str("refund_id", refund.ID.String()).Msg("Refund execution failed")
+		refund.Status = domain.RefundStatusFailed
+		refund.FailureReason = err.Error()
+		if updateErr := s.refundRepo.UpdateStatus(ctx, refund, domain.RefundStatusProcessing); updateErr != nil {
+			log.Error().Err(updateErr).Str("refund_id", refund.ID.String()).Msg("Failed to mark refund failed")
+		}
+		return refund, err
+	}
+
+	refund.ProviderRef = result.RefundID
+	refund.Status = domain.RefundStatusCompleted
+	if err := s.refundRepo.Complete(ctx, refund, domain.RefundLedgerEntries(refund, ride)); err != nil {
+		// Money has moved; surface loudly so the ledger can be reconciled
+		log.Error().Err(err).
+			Str("refund_id", refund.ID.String()).
+			Str("provider_ref", refund.ProviderRef).
+			Msg("Refund executed but ledger write failed")
+		return refund, err
+	}
+
+	s.rideService.RecordEvent(ctx, domain.NewRideEvent(ride.ID, domain.RideEventFareAdjusted).
+		WithActor(refund.RequestedBy).
+		WithData("refund_id", refund.ID).
+		WithData("type", refund.Type).
+		WithData("reason", refund.Reason).
+		WithData("amount", refund.Amount))
+
+	log.Info().
+		Str("refund_id", refund.ID.String()).
+		Str("provider_ref", refund.ProviderRef).
+		Msg("Refund completed")
+
+	return refund, nil
+}
+
+// ridePaymentID looks up the payment captured for a ride, which its fare is
+// refunded against
+func ridePaymentID(ctx context.Context, payments PaymentGateway, rideID uuid.UUID) (string, error) {
+	captured, err := payments.CapturedPayment(ctx, rideID.String())
+	if err != nil {
+		return "", err
+	}
+	if captured == nil {
+		return "", domain.ErrRidePaymentNotFound
+	}
+	return captured.ID, nil
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/payment"
+)
+
+type fakeRefundStore struct {
+	refunds map[uuid.UUID]*domain.Refund
+	ledger  []*domain.LedgerEntry
+}
+
+func newFakeRefundStore() *fakeRefundStore {
+	return &fakeRefundStore{refunds: make(map[uuid.UUID]*domain.Refund)}
+}
+
+func (f *fakeRefundStore) Create(ctx context.Context, refund *domain.Refund, fare int64) error {
+	refunded, _ := f.TotalRefunded(ctx, refund.RideID)
+	issued := f.totalIssued(refund.RideID)
+	if err := refund.Settle(fare, refunded, issued); err != nil {
+		return err
+	}
+	stored := *refund
+	f.refunds[refund.ID] = &stored
+	return nil
+}
+
+func (f *fakeRefundStore) GetByID(ctx context.Context, id uuid.UUID) (*domain.Refund, error) {
+	refund, ok := f.refunds[id]
+	if !ok {
+		return nil, domain.ErrRefundNotFound
+	}
+	stored := *refund
+	return &stored, nil
+}
+
+func (f *fakeRefundStore) ListByRide(ctx context.Context, rideID uuid.UUID) ([]*domain.Refund, error) {
+	var refunds []*domain.Refund
+	for _, refund := range f.refunds {
+		if refund.RideID == rideID {
+			refunds = append(refunds, refund)
+		}
+	}
+	return refunds, nil
+}
+
+func (f *fakeRefundStore) TotalRefunded(ctx context.Context, rideID uuid.UUID) (int64, error) {
+	var total int64
+	for _, refund := range f.refunds {
+		if refund.RideID != rideID || refund.Type == domain.RefundTypeGoodwillCredit {
+			continue
+		}
+		switch refund.Status {
+		case domain.RefundStatusPendingApproval, domain.RefundStatusProcessing, domain.RefundStatusCompleted:
+			total += refund.Amount
+		}
+	}
+	return total, nil
+}
+
+// totalIssued adds goodwill credits to the fare refunded
+func (f *fakeRefundStore) totalIssued(rideID uuid.UUID) int64 {
+	var total int64
+	for _, refund := range f.refunds {
+		if refund.RideID != rideID {
+			continue
+		}
+		switch refund.Status {
+		case domain.RefundStatusPendingApproval, domain.RefundStatusProcessing, domain.RefundStatusCompleted:
+			total += refund.Amount
+		}
+	}
+	return total
+}
+
+func (f *fakeRefundStore) UpdateStatus(ctx context.Context, refund *domain.Refund, fromStatus domain.RefundStatus) error {
+	stored, ok := f.refunds[refund.ID]
+	if !ok || stored.Status != fromStatus {
+		return domain.ErrRefundNotPending
+	}
+	*stored = *refund
+	return nil
+}
+
+func (f *fakeRefundStore) Complete(ctx context.Context, refund *domain.Refund, entries []*domain.LedgerEntry) error {
+	stored := *refund
+	f.refunds[refund.ID] = &stored
+	f.ledger = append(f.ledger, entries...)
+	return nil
+}
+
+func (f *fakeRefundStore) GetLedgerEntries(ctx context.Context, rideID uuid.UUID) ([]*domain.LedgerEntry, error) {
+	return f.ledger, nil
+}
+
+type fakeRefundRides struct {
+	ride   *domain.Ride
+	events []*domain.RideEvent
+}
+
+func (f *fakeRefundRides) GetRide(ctx context.Context, rideID uuid.UUID) (*domain.Ride, error) {
+	if f.ride == nil || f.ride.ID != rideID {
+		return nil, domain.ErrRideNotFound
+	}
+	return f.ride, nil
+}
+
+func (f *fakeRefundRides) RecordEvent(ctx context.Context, event *domain.RideEvent) {
+	f.events = append(f.events, event)
+}
+
+type fakePayments struct {
+	captured map[string]*payment.Payment // by reference
+	refunded map[string]int64            // by payment ID
+	credited map[string]int64            // by user ID
+}
+
+func newFakePayments() *fakePayments {
+	return &fakePayments{
+		captured: make(map[string]*payment.Payment),
+		refunded: make(map[string]int64),
+		credited: make(map[string]int64),
+	}
+}
+
+func (f *fakePayments) CapturedPayment(ctx context.Context, referenceID string) (*payment.Payment, error) {
+	return f.captured[referenceID], nil
+}
+
+func (f *fakePayments) RefundPayment(ctx context.Context, paymentID string, amount int64, currency domain.Currency, reason string) (*payment.RefundResult, error) {
+	f.refunded[paymentID] += amount
+	return &payment.RefundResult{RefundID: "ref_" + paymentID, Status: "processed"}, nil
+}
+
+func (f *fakePayments) IssueCredit(ctx context.Context, userID string, amount int64, currency domain.Currency, reference, reason string) (*payment.RefundResult, error) {
+	f.credited[userID] += amount
+	return &payment.RefundResult{RefundID: "txn_" + reference, Status: "completed"}, nil
+}
+
+func newRefundTestRide(total int64) *domain.Ride {
+	driverID := uuid.New()
+	return &domain.Ride{
+		ID:            uuid.New(),
+		RiderID:       uuid.New(),
+		DriverID:      &driverID,
+		Status:        domain.RideStatusCompleted,
+		PaymentMethod: domain.PaymentMethodCard,
+		Price: &domain.PriceBreakdown{
+			Total:          total,
+			DriverEarnings: total * 80 / 100,
+			Currency:       domain.CurrencyKES,
+		},
+	}
+}
+
+func TestRequestRefundExecutesAgainstCapturedPayment(t *testing.T) {
+	ride := newRefundTestRide(150000)
+	store := newFakeRefundStore()
+	rides := &fakeRefundRides{ride: ride}
+	payments := newFakePayments()
+	payments.captured[ride.ID.String()] = &payment.Payment{ID: "pay_1", ReferenceID: ride.ID.String(), Status: "COMPLETED"}
+
+	svc := NewRefundService(rides, store, payments)
+	refund, err := svc.RequestRefund(context.Background(), &RefundRequest{
+		RideID:      ride.ID,
+		Type:        domain.RefundTypeFull,
+		Reason:      domain.RefundReasonOvercharged,
+		RequestedBy: uuid.New(),
+	})
+	if err != nil {
+		t.Fatalf("RequestRefund() error = %v", err)
+	}
+
+	if refund.Status != domain.RefundStatusCompleted {
+		t.Errorf("status = %s, want COMPLETED", refund.Status)
+	}
+	if refund.ProviderRef != "ref_pay_1" {
+		t.Errorf("provider ref = %q, want ref_pay_1", refund.ProviderRef)
+	}
+	if got := payments.refunded["pay_1"]; got != 150000 {
+		t.Errorf("refunded against pay_1 = %d, want 150000", got)
+	}
+	if stored := store.refunds[refund.ID]; stored.Status != domain.RefundStatusCompleted {
+		t.Errorf("stored status = %s, want COMPLETED", stored.Status)
+	}
+	if len(store.ledger) == 0 {
+		t.Error("no ledger entries written")
+	}
+	if len(rides.events) != 1 || rides.events[0].Type != domain.RideEventFareAdjusted {
+		t.Errorf("events = %v, want one fare adjustment", rides.events)
+	}
+}
+
+func TestRequestRefundWithoutCapturedPayment(t *testing.T) {
+	ride := newRefundTestRide(150000)
+	store := newFakeRefundStore()
+
+	svc := NewRefundService(&fakeRefundRides{ride: ride}, store, newFakePayments())
+	refund, err := svc.RequestRefund(context.Background(), &RefundRequest{
+		RideID:      ride.ID,
+		Type:        domain.RefundTypePartial,
+		Reason:      domain.RefundReasonOvercharged,
+		Amount:      50000,
+		RequestedBy: uuid.New(),
+	})
+	if !errors.Is(err, domain.ErrRidePaymentNotFound) {
+		t.Fatalf("RequestRefund() error = %v, want ErrRidePaymentNotFound", err)
+	}
+	if refund.Status != domain.RefundStatusFailed {
+		t.Errorf("status = %s, want FAILED", refund.Status)
+	}
+}
+
+func TestRequestRefundGoodwillCredit(t *testing.T) {
+	ride := newRefundTestRide(150000)
+	payments := newFakePayments()
+
+	svc := NewRefundService(&fakeRefundRides{ride: ride}, newFakeRefundStore(), payments)
+	refund, err := svc.RequestRefund(context.Background(), &RefundRequest{
+		RideID:      ride.ID,
+		Type:        domain.RefundTypeGoodwillCredit,
+		Reason:      domain.RefundReasonOvercharged,
+		Amount:      20000,
+		RequestedBy: uuid.New(),
+	})
+	if err != nil {
+		t.Fatalf("RequestRefund() error = %v", err)
+	}
+	if refund.Status != domain.RefundStatusCompleted {
+		t.Errorf("status = %s, want COMPLETED", refund.Status)
+	}
+	if got := payments.credited[ride.RiderID.String()]; got != 20000 {
+		t.Errorf("credited = %d, want 20000", got)
+	}
+}
+
+func TestRequestRefundSplitNeedsApproval(t *testing.T) {
+	// KES refunds of 2,000 or more need a second approver
+	ride := newRefundTestRide(300000)
+	payments := newFakePayments()
+	payments.captured[ride.ID.String()] = &payment.Payment{ID: "pay_1", ReferenceID: ride.ID.String(), Status: "COMPLETED"}
+	svc := NewRefundService(&fakeRefundRides{ride: ride}, newFakeRefundStore(), payments)
+
+	agent := uuid.New()
+	request := func(amount int64) *domain.Refund {
+		refund, err := svc.RequestRefund(context.Background(), &RefundRequest{
+			RideID:      ride.ID,
+			Type:        domain.RefundTypePartial,
+			Reason:      domain.RefundReasonOvercharged,
+			Amount:      amount,
+			RequestedBy: agent,
+		})
+		if err != nil {
+			t.Fatalf("RequestRefund(%d) error = %v", amount, err)
+		}
+		return refund
+	}
+
+	if first := request(150000); first.Status != domain.RefundStatusCompleted {
+		t.Errorf("first refund status = %s, want COMPLETED", first.Status)
+	}
+	if second := request(100000); second.Status != domain.RefundStatusPendingApproval {
+		t.Errorf("second refund status = %s, want PENDING_APPROVAL", second.Status)
+	}
+	if got := payments.refunded["pay_1"]; got != 150000 {
+		t.Errorf("refunded = %d, want only the first 150000", got)
+	}
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/notification"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/prediction"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// retentionBatchSize is the most waiting rides scored per run
+const retentionBatchSize = 200
+
+// RetentionService scores riders' chance of cancelling during long matching
+// waits and nudges those at risk with an honest wait update or a credit
+type RetentionService struct {
+	rideService *RideService
+	rideRepo    *repository.RideRepository
+	model       *prediction.LogisticModel
+	promos      *PromoService
+	notifier    Notifier
+}
+
+// NewRetentionService creates a new retention service. promos may be nil,
+// in which case riders due a credit get the wait update instead.
+func NewRetentionService(
+	rideService *RideService,
+	rideRepo *repository.RideRepository,
+	promos *PromoService,
+	notifier Notifier,
+) *RetentionService {
+	return &RetentionService{
+		rideService: rideService,
+		rideRepo:    rideRepo,
+		model:       prediction.NewRiderCancellationModel(),
+		promos:      promos,
+		notifier:    notifier,
+	}
+}
+
+// Run scores rides that have been searching for a driver past the minimum
+// wait, then records the outcome of rides scored on earlier runs
+func (s *RetentionService) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	rides, err := s.rideRepo.GetRidesAwaitingNudge(ctx, now.Add(-domain.RetentionMinWait), retentionBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, ride := range rides {
+		if err := s.score(ctx, ride, now); err != nil {
+			log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to score ride for retention nudge")
+		}
+	}
+
+	resolved, err := s.rideRepo.ResolveRetentionNudges(ctx)
+	if err != nil {
+		return err
+	}
+	if len(rides) > 0 || resolved > 0 {
+		log.Info().Int("scored", len(rides)).Int64("resolved", resolved).Msg("Retention nudge run complete")
+	}
+	return nil
+}
+
+// GetReport measures each mitigation against its holdout for rides scored
+// in [from, to)
+func (s *RetentionService) GetReport(ctx context.Context, from, to time.Time) (*domain.RetentionReport, error) {
+	stats, err := s.rideRepo.GetRetentionStats(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.RetentionReport{
+		From:         from,
+		To:           to,
+		ModelVersion: s.model.Name + "/" + s.model.Version,
+		Stats:        stats,
+	}, nil
+}
+
+// GetPreferences gets a rider's nudge settings
+func (s *RetentionService) GetPreferences(ctx context.Context, riderID uuid.UUID) (*domain.NudgePreferences, error) {
+	return s.rideRepo.GetNudgePreferences(ctx, riderID)
+}
+
+// SetOptOut opts a rider out of (or back into) retention nudges
+func (s *RetentionService) SetOptOut(ctx context.Context, riderID uuid.UUID, optedOut bool) (*domain.NudgePreferences, error) {
+	prefs := &domain.NudgePreferences{
+		RiderID:   riderID,
+		OptedOut:  optedOut,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := s.rideRepo.SaveNudgePreferences(ctx, prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// score predicts a waiting ride's cancellation probability, records it with
+// the chosen mitigation and, unless the ride is held out, nudges the rider
+func (s *RetentionService) score(ctx context.Context, ride *domain.Ride, now time.Time) error {
+	wait := now.Sub(ride.RequestedAt)
+
+	recentRides, recentCancels, err := s.rideRepo.GetRiderCancellationHistory(ctx, ride.RiderID,
+		now.AddDate(0, 0, -domain.RetentionHistoryDays), ride.RequestedAt)
+	if err != nil {
+		return err
+	}
+
+	surge := 1.0
+	if ride.Price != nil && ride.Price.SurgeMultiplier > 0 {
+		surge = ride.Price.SurgeMultiplier
+	}
+	features := prediction.RiderCancellationFeatures(wait, surge, recentRides, recentCancels)
+	probability := s.model.Predict(features)
+
+	nudge := &domain.RetentionNudge{
+		ID:           uuid.New(),
+		RideID:       ride.ID,
+		RiderID:      ride.RiderID,
+		ModelVersion: s.model.Name + "/" + s.model.Version,
+		Probability:  probability,
+		Features:     features,
+		WaitSeconds:  int64(wait.Seconds()),
+		Mitigation:   domain.ChooseRetentionMitigation(probability),
+		Holdout:      domain.InRetentionHoldout(ride.ID),
+		CreatedAt:    now,
+	}
+
+	if nudge.Mitigation == domain.RetentionMitigationCredit && !nudge.Holdout {
+		s.issueCredit(ctx, ride, nudge)
+	}
+
+	created, err := s.rideRepo.CreateRetentionNudge(ctx, nudge)
+	if err != nil || !created {
+		// Scored by a concurrent run
+		return err
+	}
+
+	s.rideService.RecordEvent(ctx, domain.NewRideEvent(ride.ID, domain.RideEventRetentionNudge).
+		WithData("model_version", nudge.ModelVersion).
+		WithData("probability", probability).
+		WithData("mitigation", nudge.Mitigation).
+		WithData("holdout", nudge.Holdout))
+
+	if nudge.Mitigation != domain.RetentionMitigationNone && !nudge.Holdout {
+		s.notify(ctx, nudge)
+	}
+	return nil
+}
+
+// issueCredit issues the rider a voucher worth a share of the quoted fare,
+// falling back to the wait update when there is no fare or it fails
+func (s *RetentionService) issueCredit(ctx context.Context, ride *domain.Ride, nudge *domain.RetentionNudge) {
+	nudge.Mitigation = domain.RetentionMitigationMessage
+	if s.promos == nil || ride.Price == nil {
+		return
+	}
+	amount := domain.RetentionCreditAmount(ride.Price.Total)
+	if amount <= 0 {
+		return
+	}
+
+	voucher, err := s.promos.IssueVoucher(ctx, ride.RiderID, ride.Price.Currency, amount, domain.RetentionCreditValidity,
+		fmt.Sprintf("Retention credit %s", ride.ID))
+	if err != nil {
+		log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to issue retention credit")
+		return
+	}
+
+	nudge.Mitigation = domain.RetentionMitigationCredit
+	nudge.CreditCode = voucher.Codes[0]
+	nudge.CreditAmount = voucher.DiscountAmount
+	nudge.Currency = ride.Price.Currency
+}
+
+// notify pushes the nudge to the rider. The wait update makes no promise
+// about when a driver will be found since matching has no reliable ETA yet.
+// Credit offers are marketing, sent only with the rider's consent.
+func (s *RetentionService) notify(ctx context.Context, nudge *domain.RetentionNudge) {
+	if s.notifier == nil {
+		return
+	}
+
+	title := "Still looking for your driver"
+	body := "It's taking longer than usual to find a driver near you. We're still searching and will let you know as soon as one accepts."
+	data := map[string]string{
+		"type":       "RETENTION_NUDGE",
+		"ride_id":    nudge.RideID.String(),
+		"mitigation": string(nudge.Mitigation),
+	}
+	if nudge.Mitigation == domain.RetentionMitigationCredit {
+		title = "Thanks for waiting"
+		body = fmt.Sprintf("%s Here's code %s for %d %s off a ride.", body, nudge.CreditCode, nudge.CreditAmount, nudge.Currency)
+		data["voucher_code"] = nudge.CreditCode
+		data["category"] = domain.NotificationCategoryMarketing
+	}
+
+	if err := s.notifier.SendPush(ctx, nudge.RiderID, title, body, notification.PriorityNormal, data); err != nil {
+		log.Warn().Err(err).Str("ride_id", nudge.RideID.String()).Msg("Failed to send retention nudge")
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/delivery"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// metresPerDegree is the length of a degree of latitude, used to size the
+// search box around the way back
+const metresPerDegree = 111320.0
+
+// ReturnLegService recommends rides and deliveries that take a driver back
+// toward demand after a dropoff far from it
+type ReturnLegService struct {
+	rideRepo   *repository.RideRepository
+	driverPool *redis.DriverPool
+	deliveries *delivery.Client
+	cities     *cityconfig.Registry
+}
+
+// NewReturnLegService creates a new return leg service
+func NewReturnLegService(
+	rideRepo *repository.RideRepository,
+	driverPool *redis.DriverPool,
+	deliveries *delivery.Client,
+	cities *cityconfig.Registry,
+) *ReturnLegService {
+	return &ReturnLegService{
+		rideRepo:   rideRepo,
+		driverPool: driverPool,
+		deliveries: deliveries,
+		cities:     cities,
+	}
+}
+
+// Recommend finds the zone in the driver's city with the most requests per
+// available driver and, unless the driver is already near it, the waiting
+// rides and deliveries that bring them closer to it
+func (s *ReturnLegService) Recommend(ctx context.Context, lat, lng float64) (*domain.ReturnLegRecommendation, error) {
+	rec := &domain.ReturnLegRecommendation{
+		Latitude:    lat,
+		Longitude:   lng,
+		Legs:        []*domain.ReturnLeg{},
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	zone, err := s.busiestZone(ctx, lat, lng)
+	if err != nil {
+		return nil, err
+	}
+	if zone == nil {
+		return rec, nil
+	}
+
+	rec.Zone = zone
+	rec.DistanceToDemandMeters = geo.HaversineDistance(lat, lng, zone.Latitude, zone.Longitude)
+	if rec.DistanceToDemandMeters <= domain.ReturnLegNearDemand {
+		rec.NearDemand = true
+		return rec, nil
+	}
+
+	legs, err := s.waitingRides(ctx, lat, lng, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deliveries are a bonus; the delivery service being down should not
+	// hide the rides
+	deliveries, err := s.deliveries.GetCorridorDeliveries(ctx, lat, lng, zone.Latitude, zone.Longitude, domain.ReturnLegCorridorWidth)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to get corridor deliveries")
+	}
+	legs = append(legs, deliveries...)
+
+	for _, leg := range legs {
+		measureReturnLeg(leg, lat, lng, zone)
+	}
+	rec.Legs = domain.RankReturnLegs(legs, domain.ReturnLegLimit)
+
+	return rec, nil
+}
+
+// busiestZone gets the zone in the same city as a location with the highest
+// demand pressure, or nil if it has had no requests lately
+func (s *ReturnLegService) busiestZone(ctx context.Context, lat, lng float64) (*domain.DemandZone, error) {
+	zones, err := s.rideRepo.GetDemandZones(ctx, time.Now().Add(-domain.ReturnLegDemandWindow), domain.ReturnLegDemandZoneLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	city, inCity := s.cities.FindByLocation(lat, lng)
+
+	var busiest *domain.DemandZone
+	for _, zone := range zones {
+		if inCity {
+			if zoneCity, ok := s.cities.FindByLocation(zone.Latitude, zone.Longitude); !ok || zoneCity.Code != city.Code {
+				continue
+			}
+		}
+
+		supply, err := s.driverPool.CountDriversInCell(ctx, zone.Zone)
+		if err != nil {
+			return nil, err
+		}
+		zone.Supply = supply
+
+		if busiest == nil || zone.Pressure() > busiest.Pressure() {
+			busiest = zone
+		}
+	}
+
+	return busiest, nil
+}
+
+// waitingRides gets the rides still searching for a driver whose pickup
+// lies between the driver and the zone, padded by the corridor width
+func (s *ReturnLegService) waitingRides(ctx context.Context, lat, lng float64, zone *domain.DemandZone) ([]*domain.ReturnLeg, error) {
+	padLat := domain.ReturnLegCorridorWidth / metresPerDegree
+	padLng := padLat / math.Max(math.Cos(lat*math.Pi/180), 0.01)
+
+	rides, err := s.rideRepo.GetSearchingRidesInBounds(ctx,
+		math.Min(lat, zone.Latitude)-padLat, math.Min(lng, zone.Longitude)-padLng,
+		math.Max(lat, zone.Latitude)+padLat, math.Max(lng, zone.Longitude)+padLng,
+		domain.ReturnLegLimit*4,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	legs := make([]*domain.ReturnLeg, 0, len(rides))
+	for _, ride := range rides {
+		leg := &domain.ReturnLeg{
+			Source:          domain.ReturnLegSourceRide,
+			ID:              ride.ID.String(),
+			PickupLocation:  ride.PickupLocation,
+			DropoffLocation: ride.DropoffLocation,
+		}
+		if ride.Price != nil {
+			leg.Fare = ride.Price.Total
+			leg.Currency = ride.Price.Currency
+		}
+		legs = append(legs, leg)
+	}
+	return legs, nil
+}
+
+// measureReturnLeg sets how far a leg's pickup is from the driver, how much
+// it adds to driving straight back to the zone and how much closer to the
+// zone it ends
+func measureReturnLeg(leg *domain.ReturnLeg, lat, lng float64, zone *domain.DemandZone) {
+	pickup, dropoff := leg.PickupLocation, leg.DropoffLocation
+
+	direct := geo.HaversineDistance(lat, lng, zone.Latitude, zone.Longitude)
+	leg.PickupDistanceMeters = geo.HaversineDistance(lat, lng, pickup.Latitude, pickup.Longitude)
+	remaining := geo.HaversineDistance(dropoff.Latitude, dropoff.Longitude, zone.Latitude, zone.Longitude)
+
+	leg.DetourMeters = leg.PickupDistanceMeters +
+		geo.HaversineDistance(pickup.Latitude, pickup.Longitude, dropoff.Latitude, dropoff.Longitude) +
+		remaining - direct
+	leg.ProgressMeters = direct - remaining
+}
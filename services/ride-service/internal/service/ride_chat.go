@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/notification"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// rideMessagePreview is how much of a message its push shows, in characters
+const rideMessagePreview = 100
+
+// RideChatService lets a ride's rider and driver message each other while
+// the driver is on the ride. Messages are kept in a Redis stream per ride,
+// which readers follow, and archived to the database as the ride ends.
+type RideChatService struct {
+	rideService *RideService
+	driverPool  *redis.DriverPool
+	messageRepo *repository.RideMessageRepository
+	notifier    Notifier
+}
+
+// NewRideChatService creates a new ride chat service
+func NewRideChatService(rideService *RideService, driverPool *redis.DriverPool, messageRepo *repository.RideMessageRepository, notifier Notifier) *RideChatService {
+	return &RideChatService{
+		rideService: rideService,
+		driverPool:  driverPool,
+		messageRepo: messageRepo,
+		notifier:    notifier,
+	}
+}
+
+// SendMessage sends a message from the ride's rider or driver to the other
+func (s *RideChatService) SendMessage(ctx context.Context, rideID, userID uuid.UUID, role, body string) (*domain.RideMessage, error) {
+	ride, err := s.rideService.GetRide(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	party := ride.PartyOf(userID, role)
+	if party != domain.RidePartyRider && party != domain.RidePartyDriver {
+		return nil, domain.ErrForbidden
+	}
+	if !ride.ChatOpen() {
+		return nil, domain.ErrRideChatClosed
+	}
+
+	msg, err := domain.NewRideMessage(ride.ID, userID, party, body, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.driverPool.AppendRideMessage(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	recipient, title := *ride.DriverID, "Message from your rider"
+	if party == domain.RidePartyDriver {
+		recipient, title = ride.RiderID, "Message from your driver"
+	}
+	s.notify(ctx, recipient, title, msg)
+
+	return msg, nil
+}
+
+// GetChat gets a ride's chat for its rider, driver or support: live while
+// it is open, archived after
+func (s *RideChatService) GetChat(ctx context.Context, rideID, userID uuid.UUID, role string) (*domain.RideChat, error) {
+	ride, err := s.rideService.GetRide(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	if ride.PartyOf(userID, role) == domain.RidePartyNone {
+		return nil, domain.ErrForbidden
+	}
+
+	chat := &domain.RideChat{RideID: ride.ID, Open: ride.ChatOpen()}
+	if !chat.Open {
+		chat.Messages, err = s.messageRepo.ListByRide(ctx, ride.ID)
+		if err != nil || len(chat.Messages) > 0 {
+			return chat, err
+		}
+		// Not archived yet, or the archive failed; the stream has it
+	}
+
+	messages, closed, err := s.driverPool.ListRideMessages(ctx, ride.ID)
+	if err != nil {
+		return nil, err
+	}
+	chat.Messages = messages
+	chat.Open = chat.Open && !closed
+	return chat, nil
+}
+
+// WaitForMessages waits up to block for messages after the given one,
+// reporting whether the chat has closed. Callers check access with GetChat
+// first.
+func (s *RideChatService) WaitForMessages(ctx context.Context, rideID uuid.UUID, after string, block time.Duration) ([]*domain.RideMessage, bool, error) {
+	return s.driverPool.WaitForRideMessages(ctx, rideID, after, block)
+}
+
+// CloseChat archives an ended ride's chat and closes its stream, so readers
+// stop following it. A chat that fails to archive is left open to expire,
+// and is still read from the stream until it does.
+func (s *RideChatService) CloseChat(ctx context.Context, ride *domain.Ride) {
+	messages, closed, err := s.driverPool.ListRideMessages(ctx, ride.ID)
+	if err != nil {
+		log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to read ride chat for archiving")
+		return
+	}
+	if closed {
+		return
+	}
+
+	if len(messages) > 0 {
+		if err := s.messageRepo.Archive(ctx, messages); err != nil {
+			log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to archive ride chat")
+			return
+		}
+	}
+	if err := s.driverPool.CloseRideChat(ctx, ride.ID); err != nil {
+		log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to close ride chat stream")
+	}
+
+	log.Debug().
+		Str("ride_id", ride.ID.String()).
+		Int("messages", len(messages)).
+		Msg("Ride chat archived")
+}
+
+// notify pushes a message to the party it was sent to
+func (s *RideChatService) notify(ctx context.Context, userID uuid.UUID, title string, msg *domain.RideMessage) {
+	if s.notifier == nil {
+		return
+	}
+	preview := msg.Body
+	if utf8.RuneCountInString(preview) > rideMessagePreview {
+		preview = string([]rune(preview)[:rideMessagePreview]) + "…"
+	}
+	err := s.notifier.SendPush(ctx, userID, title, preview, notification.PriorityNormal, map[string]string{
+		"type":       "RIDE_MESSAGE",
+		"ride_id":    msg.RideID.String(),
+		"message_id": msg.ID,
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("ride_id", msg.RideID.String()).Msg("Failed to send ride message notification")
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/notification"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// rideExpiryBatch bounds the rides expired in one run; the rest are picked
+// up on the next
+const rideExpiryBatch = 500
+
+// RideExpiryService ends matching for rides that found no driver within the
+// matching TTL and offers their riders something else to try
+type RideExpiryService struct {
+	rideService *RideService
+	rideRepo    *repository.RideRepository
+	payments    PaymentGateway
+	notifier    Notifier
+	ttl         time.Duration
+}
+
+// NewRideExpiryService creates a new ride expiry service. A ttl of zero
+// uses domain.DefaultMatchingTTL. payments and notifier may be nil, in
+// which case payments taken up front are not refunded and riders are not
+// notified.
+func NewRideExpiryService(
+	rideService *RideService,
+	rideRepo *repository.RideRepository,
+	payments PaymentGateway,
+	notifier Notifier,
+	ttl time.Duration,
+) *RideExpiryService {
+	if ttl <= 0 {
+		ttl = domain.DefaultMatchingTTL
+	}
+	return &RideExpiryService{
+		rideService: rideService,
+		rideRepo:    rideRepo,
+		payments:    payments,
+		notifier:    notifier,
+		ttl:         ttl,
+	}
+}
+
+// Run expires rides that have been looking for a driver for longer than the
+// matching TTL
+func (s *RideExpiryService) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+	rides, err := s.rideRepo.GetMatchingExpiredRides(ctx, now.Add(-s.ttl), rideExpiryBatch)
+	if err != nil {
+		return err
+	}
+
+	expired := make(map[domain.RideType]int)
+	for _, ride := range rides {
+		err := s.expire(ctx, ride, now)
+		switch {
+		case errors.Is(err, errRideMovedOn):
+		case err != nil:
+			log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to expire ride")
+		default:
+			expired[ride.Type]++
+		}
+	}
+
+	if len(expired) > 0 {
+		log.Info().Interface("expired", expired).Dur("ttl", s.ttl).Msg("Expired rides that found no driver")
+	}
+	return nil
+}
+
+// expire ends one ride's matching, releases what it held and tells the
+// rider what they can do instead
+func (s *RideExpiryService) expire(ctx context.Context, ride *domain.Ride, now time.Time) error {
+	alternatives := domain.ExpiryAlternatives(ride.Type, s.availableRideTypes(ctx, ride))
+
+	ride, err := s.rideService.updateRide(ctx, ride, func(ride *domain.Ride) error {
+		// A driver accepted, or the ride went back to searching, meanwhile
+		if !ride.MatchingExpired(now, s.ttl) {
+			return errRideMovedOn
+		}
+		return ride.Expire(alternatives)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.rideService.releaseEndedRide(ctx, ride)
+	refundUpfrontPayment(ctx, s.payments, s.rideService, ride)
+
+	log.Info().
+		Str("ride_id", ride.ID.String()).
+		Str("ride_type", string(ride.Type)).
+		Str("h3_cell", ride.PickupLocation.H3Cell).
+		Msg("Ride expired without a driver")
+
+	s.notify(ctx, ride, alternatives)
+	return nil
+}
+
+// availableRideTypes lists the ride types with drivers near the pickup
+func (s *RideExpiryService) availableRideTypes(ctx context.Context, ride *domain.Ride) []domain.RideType {
+	pool := s.rideService.driverPool
+	if pool == nil {
+		return nil
+	}
+
+	var available []domain.RideType
+	for _, rideType := range domain.RideTypes {
+		drivers, err := pool.GetNearbyDrivers(ctx, ride.PickupLocation.Latitude, ride.PickupLocation.Longitude,
+			domain.PickupETASearchRadius, rideType)
+		if err != nil {
+			log.Warn().Err(err).Str("ride_type", string(rideType)).Msg("Failed to find drivers for expired ride alternatives")
+			continue
+		}
+		if len(drivers) > 0 {
+			available = append(available, rideType)
+		}
+	}
+	return available
+}
+
+func (s *RideExpiryService) notify(ctx context.Context, ride *domain.Ride, alternatives []domain.RideAlternative) {
+	if s.notifier == nil {
+		return
+	}
+
+	alternativesJSON, _ := json.Marshal(alternatives)
+	err := s.notifier.SendPush(ctx, ride.RiderID, "No drivers available",
+		"We couldn't find you a driver this time. Try again, schedule your ride or pick another ride type.",
+		notification.PriorityHigh, map[string]string{
+			"type":         "RIDE_EXPIRED",
+			"ride_id":      ride.ID.String(),
+			"alternatives": string(alternativesJSON),
+		})
+	if err != nil {
+		log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to notify rider of expired ride")
+	}
+}
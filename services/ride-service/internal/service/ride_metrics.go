@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// Ride metrics rollup settings
+const (
+	rideMetricsReroll   = 3 * time.Hour      // trailing hours re-rolled each run to pick up late completions
+	rideMetricsBackfill = 7 * 24 * time.Hour // history rolled up on the first run
+)
+
+// RideMetricsService rolls rides up into hourly per-market summaries and
+// serves analytics and admin reports from them
+type RideMetricsService struct {
+	rideRepo *repository.RideRepository
+	cities   *cityconfig.Registry
+}
+
+// NewRideMetricsService creates a new ride metrics service
+func NewRideMetricsService(rideRepo *repository.RideRepository, cities *cityconfig.Registry) *RideMetricsService {
+	return &RideMetricsService{
+		rideRepo: rideRepo,
+		cities:   cities,
+	}
+}
+
+// Run rolls up every hour from the re-roll window (or the last rolled-up
+// hour, if the worker has fallen further behind) through the current hour
+func (s *RideMetricsService) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+	current := now.Truncate(time.Hour)
+
+	from := current.Add(-rideMetricsReroll)
+	watermark, err := s.rideRepo.GetRideMetricsWatermark(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case watermark == nil:
+		from = current.Add(-rideMetricsBackfill)
+	case watermark.Before(from):
+		from = watermark.UTC()
+	}
+
+	// One hour per query keeps each scan on the rides table small
+	for hour := from; !hour.After(current); hour = hour.Add(time.Hour) {
+		if err := s.rollUpHour(ctx, hour); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetReport gets per-market ride summaries for hours in [from, to),
+// optionally for one market and with the hourly breakdown
+func (s *RideMetricsService) GetReport(ctx context.Context, from, to time.Time, market string, hourly bool) (*domain.RideMetricsReport, error) {
+	from = from.UTC().Truncate(time.Hour)
+	hours, err := s.rideRepo.GetRideHourlyMetrics(ctx, from, to, market)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.RideMetricsReport{
+		From:    from,
+		To:      to,
+		Markets: domain.SummarizeRideMetrics(hours),
+	}
+	if hourly {
+		report.Hours = hours
+	}
+	return report, nil
+}
+
+// rollUpHour recomputes one hour's per-market summaries
+func (s *RideMetricsService) rollUpHour(ctx context.Context, hour time.Time) error {
+	end := hour.Add(time.Hour)
+	aggregates, err := s.rideRepo.AggregateRideHours(ctx, hour, end)
+	if err != nil {
+		return err
+	}
+
+	type key struct {
+		market   string
+		currency domain.Currency
+	}
+	byMarket := make(map[key]*domain.RideHourlyMetrics)
+	metrics := make([]*domain.RideHourlyMetrics, 0)
+	for _, a := range aggregates {
+		k := key{s.market(a), a.Currency}
+		m, ok := byMarket[k]
+		if !ok {
+			m = &domain.RideHourlyMetrics{Hour: hour, Market: k.market, Currency: k.currency}
+			byMarket[k] = m
+			metrics = append(metrics, m)
+		}
+		m.Add(&a.RideHourlyMetrics)
+	}
+
+	return s.rideRepo.ReplaceRideHourlyMetrics(ctx, hour, end, metrics)
+}
+
+// market is the pickup city's code, or the fare currency outside a city
+// bundle, matching the fare variance markets
+func (s *RideMetricsService) market(a *repository.RideHourAggregate) string {
+	if s.cities != nil {
+		if city, ok := s.cities.FindByLocation(a.Latitude, a.Longitude); ok {
+			return city.Code
+		}
+	}
+	return string(a.Currency)
+}
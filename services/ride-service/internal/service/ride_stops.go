@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/eta"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+)
+
+// routeLegs measures each leg of a ride with stops, from the pickup through
+// the stops to the dropoff. Legs the router cannot route fall back to the
+// straight-line distance and an estimated duration; routed reports whether
+// every leg was routed.
+func (s *RideService) routeLegs(ctx context.Context, req *domain.RideRequest, profile eta.RoutingProfile) ([]domain.RouteLeg, bool) {
+	points := make([]domain.Location, 0, len(req.Stops)+2)
+	points = append(points, req.PickupLocation)
+	points = append(points, req.Stops...)
+	points = append(points, req.DropoffLocation)
+
+	legs := make([]domain.RouteLeg, 0, len(points)-1)
+	routed := s.router != nil
+	for i := 1; i < len(points); i++ {
+		from, to := points[i-1], points[i]
+
+		if s.router != nil {
+			route, err := s.router.GetRoute(ctx, &eta.ETARequest{
+				OriginLat:     from.Latitude,
+				OriginLng:     from.Longitude,
+				DestLat:       to.Latitude,
+				DestLng:       to.Longitude,
+				DepartureTime: time.Now(),
+				Profile:       profile,
+				AvoidTolls:    req.Avoid.Tolls,
+				AvoidHighways: req.Avoid.Highways,
+			})
+			if err == nil {
+				legs = append(legs, domain.RouteLeg{
+					DistanceMeters:  int64(route.Distance),
+					DurationSeconds: int64(route.Duration.Seconds()),
+				})
+				continue
+			}
+			log.Warn().Err(err).Int("leg", i-1).Str("profile", string(profile)).Msg("Failed to route ride leg, using straight-line distance")
+			routed = false
+		}
+
+		distance := geo.HaversineDistance(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+		duration := geo.EstimateETA(distance, string(profile))
+		legs = append(legs, domain.RouteLeg{
+			DistanceMeters:  int64(distance),
+			DurationSeconds: geo.EstimateETAWithTraffic(duration, time.Now().Hour()),
+		})
+	}
+	return legs, routed
+}
+
+// ArriveAtStop records the ride's driver reaching one of its stops
+func (s *RideService) ArriveAtStop(ctx context.Context, rideID, driverID uuid.UUID, index int) (*domain.Ride, error) {
+	return s.updateStop(ctx, rideID, driverID, index, (*domain.Ride).ArriveAtStop)
+}
+
+// DepartStop records the ride's driver leaving one of its stops
+func (s *RideService) DepartStop(ctx context.Context, rideID, driverID uuid.UUID, index int) (*domain.Ride, error) {
+	return s.updateStop(ctx, rideID, driverID, index, (*domain.Ride).DepartStop)
+}
+
+func (s *RideService) updateStop(
+	ctx context.Context,
+	rideID, driverID uuid.UUID,
+	index int,
+	change func(*domain.Ride, int) error,
+) (*domain.Ride, error) {
+	ride, err := s.GetRide(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	if ride.DriverID == nil || *ride.DriverID != driverID {
+		return nil, domain.ErrForbidden
+	}
+
+	ride, err = s.updateRide(ctx, ride, func(ride *domain.Ride) error {
+		return change(ride, index)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.driverPool != nil {
+		_ = s.driverPool.CacheRide(ctx, ride)
+	}
+
+	state := ride.StopStates[index]
+	log.Info().
+		Str("ride_id", rideID.String()).
+		Int("stop", index).
+		Str("status", string(state.Status)).
+		Msg("Ride stop updated")
+
+	return ride, nil
+}
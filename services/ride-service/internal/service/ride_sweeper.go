@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// rideSweepBatch bounds the rides swept per state in one run; the rest are
+// picked up on the next
+const rideSweepBatch = 200
+
+// errRideMovedOn marks a stuck ride that was updated while being swept
+var errRideMovedOn = errors.New("ride updated while being swept")
+
+// RideSweeper finds rides stuck in a transitional state, usually because
+// the worker or driver app handling them died, and cancels or re-dispatches
+// them so riders are not left waiting on a ride that will never move
+type RideSweeper struct {
+	rideService *RideService
+	rideRepo    *repository.RideRepository
+	driverRepo  *repository.DriverRepository
+	payments    PaymentGateway
+	timeouts    domain.RideSweepTimeouts
+}
+
+// NewRideSweeper creates a new ride sweeper. payments may be nil, in which
+// case rides paid for up front are cancelled without refunding the payment.
+func NewRideSweeper(
+	rideService *RideService,
+	rideRepo *repository.RideRepository,
+	driverRepo *repository.DriverRepository,
+	payments PaymentGateway,
+	timeouts domain.RideSweepTimeouts,
+) *RideSweeper {
+	if timeouts == nil {
+		timeouts = domain.DefaultRideSweepTimeouts
+	}
+	return &RideSweeper{
+		rideService: rideService,
+		rideRepo:    rideRepo,
+		driverRepo:  driverRepo,
+		payments:    payments,
+		timeouts:    timeouts,
+	}
+}
+
+// Run sweeps every transitional state once and alerts with the counts per
+// state when any ride was stuck
+func (s *RideSweeper) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+	report := domain.NewRideSweepReport()
+
+	statuses := make([]domain.RideStatus, 0, len(s.timeouts))
+	for status := range s.timeouts {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i] < statuses[j] })
+
+	for _, status := range statuses {
+		timeout := s.timeouts[status]
+		rides, err := s.rideRepo.GetStuckRides(ctx, status, now.Add(-timeout), rideSweepBatch)
+		if err != nil {
+			return err
+		}
+
+		for _, ride := range rides {
+			action, err := s.sweep(ctx, ride, timeout)
+			switch {
+			case errors.Is(err, errRideMovedOn):
+			case err != nil:
+				report.Failed[status]++
+				log.Error().Err(err).Str("ride_id", ride.ID.String()).Str("status", string(status)).Msg("Failed to sweep stuck ride")
+			case action == domain.RideSweepRedispatch:
+				report.Redispatched[status]++
+			default:
+				report.Cancelled[status]++
+			}
+		}
+	}
+
+	if report.Total() > 0 {
+		log.Error().
+			Bool("alert", true).
+			Int("stuck_rides", report.Total()).
+			Interface("cancelled", report.Cancelled).
+			Interface("redispatched", report.Redispatched).
+			Interface("failed", report.Failed).
+			Msg("Swept rides stuck in transitional states")
+	}
+	return nil
+}
+
+// sweep cancels or re-dispatches one stuck ride and releases what it held
+func (s *RideSweeper) sweep(ctx context.Context, ride *domain.Ride, timeout time.Duration) (domain.RideSweepAction, error) {
+	stuckIn := ride.Status
+	version := ride.Version
+	driverID := ride.DriverID
+	action := ride.SweepAction()
+	reason := fmt.Sprintf("stuck in %s for over %s", stuckIn, timeout)
+
+	ride, err := s.rideService.updateRide(ctx, ride, func(ride *domain.Ride) error {
+		// Any other write since the ride was listed means it is moving again
+		if ride.Version != version {
+			return errRideMovedOn
+		}
+		if action == domain.RideSweepRedispatch {
+			return ride.Redispatch(reason)
+		}
+		return ride.Cancel(uuid.Nil, reason)
+	})
+	if err != nil {
+		return action, err
+	}
+
+	if driverID != nil {
+		s.releaseDriver(ctx, *driverID, ride.ID)
+	}
+	if action == domain.RideSweepRedispatch {
+		if s.rideService.driverPool != nil {
+			_ = s.rideService.driverPool.InvalidateRideCache(ctx, ride.ID)
+		}
+	} else {
+		s.rideService.releaseEndedRide(ctx, ride)
+		refundUpfrontPayment(ctx, s.payments, s.rideService, ride)
+	}
+
+	log.Info().
+		Str("ride_id", ride.ID.String()).
+		Str("stuck_in", string(stuckIn)).
+		Str("action", string(action)).
+		Msg("Swept stuck ride")
+	return action, nil
+}
+
+// releaseDriver frees the driver a stuck ride was assigned to so they can
+// be matched again
+func (s *RideSweeper) releaseDriver(ctx context.Context, driverID, rideID uuid.UUID) {
+	if s.driverRepo != nil {
+		if err := s.driverRepo.ReleaseRide(ctx, driverID, rideID); err != nil {
+			log.Error().Err(err).Str("driver_id", driverID.String()).Msg("Failed to release driver from stuck ride")
+		}
+	}
+	if pool := s.rideService.driverPool; pool != nil {
+		_ = pool.UnlockDriver(ctx, driverID)
+		_ = pool.SetDriverStatus(ctx, driverID, domain.DriverStatusOnline)
+	}
+}
+
+// refundUpfrontPayment returns a payment taken up front for a ride that
+// ended before it started
+func refundUpfrontPayment(ctx context.Context, payments PaymentGateway, rideService *RideService, ride *domain.Ride) {
+	if ride.PaymentMethod == domain.PaymentMethodCash || ride.Price == nil || payments == nil {
+		return
+	}
+
+	paymentID, err := ridePaymentID(ctx, payments, ride.ID)
+	if errors.Is(err, domain.ErrRidePaymentNotFound) {
+		// Nothing was taken up front
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).
+			Bool("alert", true).
+			Str("ride_id", ride.ID.String()).
+			Msg("Failed to look up payment for unfulfilled ride")
+		return
+	}
+
+	result, err := payments.RefundPayment(ctx, paymentID, ride.Price.Total, ride.Price.Currency, "ride_not_fulfilled")
+	if err != nil {
+		// The ride has ended, so nothing retries this; it needs reconciling
+		// by hand
+		log.Error().Err(err).
+			Bool("alert", true).
+			Str("ride_id", ride.ID.String()).
+			Str("payment_id", paymentID).
+			Msg("Failed to refund payment for unfulfilled ride")
+		return
+	}
+
+	rideService.RecordEvent(ctx, domain.NewRideEvent(ride.ID, domain.RideEventFareAdjusted).
+		WithData("reason", "ride_not_fulfilled").
+		WithData("refund_ref", result.RefundID).
+		WithData("amount", ride.Price.Total))
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// fakeVersionedRides stores one ride with a version check, as the ride
+// repository does. rivals is how many more concurrent writes land just
+// before each of ours, each moving the stored ride on with rival.
+type fakeVersionedRides struct {
+	stored  domain.Ride
+	rivals  int
+	rival   func(*domain.Ride)
+	updates int
+	reloads int
+}
+
+func (f *fakeVersionedRides) Update(ctx context.Context, ride *domain.Ride) error {
+	f.updates++
+	if f.rivals > 0 {
+		f.rivals--
+		f.rival(&f.stored)
+		f.stored.Version++
+	}
+	if ride.Version != f.stored.Version {
+		return domain.ErrRideConflict
+	}
+	f.stored = copyRide(ride)
+	f.stored.Version++
+	ride.Version++
+	return nil
+}
+
+func (f *fakeVersionedRides) GetByID(ctx context.Context, id uuid.UUID) (*domain.Ride, error) {
+	f.reloads++
+	ride := copyRide(&f.stored)
+	return &ride, nil
+}
+
+// copyRide copies a ride with its own metadata, as a database round trip does
+func copyRide(ride *domain.Ride) domain.Ride {
+	copied := *ride
+	copied.Metadata = make(map[string]any, len(ride.Metadata))
+	for k, v := range ride.Metadata {
+		copied.Metadata[k] = v
+	}
+	return copied
+}
+
+func newVersionedRide(status domain.RideStatus) *domain.Ride {
+	return &domain.Ride{ID: uuid.New(), Status: status, Version: 1, Metadata: map[string]any{}}
+}
+
+func TestRetryRideUpdate(t *testing.T) {
+	tagged := func(ride *domain.Ride) error {
+		ride.Metadata["tagged"] = true
+		return nil
+	}
+
+	t.Run("no conflict", func(t *testing.T) {
+		ride := newVersionedRide(domain.RideStatusInProgress)
+		rides := &fakeVersionedRides{stored: copyRide(ride)}
+		updated, err := retryRideUpdate(context.Background(), rides, ride, tagged, func(uuid.UUID) {
+			t.Error("ride reported stale without a conflict")
+		})
+		if err != nil {
+			t.Fatalf("retryRideUpdate() error = %v", err)
+		}
+		if updated.Version != 2 || rides.stored.Version != 2 || rides.updates != 1 {
+			t.Errorf("version = %d, stored %d after %d updates, want 2 after 1", updated.Version, rides.stored.Version, rides.updates)
+		}
+	})
+
+	t.Run("conflict then retry", func(t *testing.T) {
+		// The driver's location update lands first; the tag is applied to
+		// the ride as it now stands, keeping both
+		ride := newVersionedRide(domain.RideStatusInProgress)
+		rides := &fakeVersionedRides{stored: copyRide(ride), rivals: 1, rival: func(r *domain.Ride) {
+			r.CurrentLocation = &domain.Location{Latitude: -1.2921, Longitude: 36.8219}
+		}}
+		var stale []uuid.UUID
+		updated, err := retryRideUpdate(context.Background(), rides, ride, tagged, func(id uuid.UUID) {
+			stale = append(stale, id)
+		})
+		if err != nil {
+			t.Fatalf("retryRideUpdate() error = %v", err)
+		}
+		if rides.updates != 2 || rides.reloads != 1 || len(stale) != 1 || stale[0] != ride.ID {
+			t.Errorf("updates = %d, reloads = %d, stale = %v, want 2, 1 and the ride", rides.updates, rides.reloads, stale)
+		}
+		if updated.Version != 3 || updated.CurrentLocation == nil || updated.Metadata["tagged"] != true {
+			t.Errorf("updated = version %d, location %v, metadata %v, want version 3 with both changes", updated.Version, updated.CurrentLocation, updated.Metadata)
+		}
+		if rides.stored.CurrentLocation == nil || rides.stored.Metadata["tagged"] != true {
+			t.Error("stored ride lost one of the changes")
+		}
+	})
+
+	t.Run("change no longer allowed", func(t *testing.T) {
+		// The driver completes the ride as the rider cancels it
+		ride := newVersionedRide(domain.RideStatusInProgress)
+		rides := &fakeVersionedRides{stored: copyRide(ride), rivals: 1, rival: func(r *domain.Ride) {
+			r.Status = domain.RideStatusCompleted
+		}}
+		_, err := retryRideUpdate(context.Background(), rides, ride, func(r *domain.Ride) error {
+			return r.Cancel(uuid.New(), "changed my mind")
+		}, func(uuid.UUID) {})
+		if !errors.Is(err, domain.ErrRideAlreadyEnded) {
+			t.Fatalf("retryRideUpdate() error = %v, want ErrRideAlreadyEnded", err)
+		}
+		if rides.stored.Status != domain.RideStatusCompleted {
+			t.Errorf("stored status = %s, want the completion kept", rides.stored.Status)
+		}
+	})
+
+	t.Run("out of retries", func(t *testing.T) {
+		ride := newVersionedRide(domain.RideStatusInProgress)
+		rides := &fakeVersionedRides{stored: copyRide(ride), rivals: rideUpdateAttempts, rival: func(r *domain.Ride) {}}
+		changes := 0
+		_, err := retryRideUpdate(context.Background(), rides, ride, func(r *domain.Ride) error {
+			changes++
+			return tagged(r)
+		}, func(uuid.UUID) {})
+		if !errors.Is(err, domain.ErrRideConflict) {
+			t.Fatalf("retryRideUpdate() error = %v, want ErrRideConflict", err)
+		}
+		if changes != rideUpdateAttempts || rides.updates != rideUpdateAttempts {
+			t.Errorf("changes = %d, updates = %d, want %d of each", changes, rides.updates, rideUpdateAttempts)
+		}
+		if rides.stored.Metadata["tagged"] == true {
+			t.Error("change written despite every attempt conflicting")
+		}
+	})
+}
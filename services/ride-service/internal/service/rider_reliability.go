@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// RiderReliabilityService takes drivers' reports about riders and scores
+// riders' reliability from them and their late cancellations
+type RiderReliabilityService struct {
+	rideService *RideService
+	reportRepo  *repository.RiderReportRepository
+}
+
+// NewRiderReliabilityService creates a new rider reliability service
+func NewRiderReliabilityService(rideService *RideService, reportRepo *repository.RiderReportRepository) *RiderReliabilityService {
+	return &RiderReliabilityService{
+		rideService: rideService,
+		reportRepo:  reportRepo,
+	}
+}
+
+// ReportRider records the ride's driver's report about its rider
+func (s *RiderReliabilityService) ReportRider(ctx context.Context, rideID, driverID uuid.UUID, reportType domain.RiderReportType, note string) (*domain.RiderReport, error) {
+	ride, err := s.rideService.GetRide(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	if ride.DriverID == nil || *ride.DriverID != driverID {
+		return nil, domain.ErrForbidden
+	}
+
+	now := time.Now().UTC()
+	if err := domain.CheckRiderReport(ride, reportType, now); err != nil {
+		return nil, err
+	}
+
+	report := &domain.RiderReport{
+		ID:        uuid.New(),
+		RideID:    ride.ID,
+		RiderID:   ride.RiderID,
+		DriverID:  driverID,
+		Type:      reportType,
+		Note:      note,
+		CreatedAt: now,
+	}
+	if err := s.reportRepo.Create(ctx, report); err != nil {
+		return nil, err
+	}
+
+	s.rideService.RecordEvent(ctx, domain.NewRideEvent(ride.ID, domain.RideEventRiderReported).
+		WithActor(driverID).
+		WithData("report_id", report.ID).
+		WithData("type", reportType))
+
+	log.Info().
+		Str("report_id", report.ID.String()).
+		Str("ride_id", ride.ID.String()).
+		Str("type", string(reportType)).
+		Msg("Rider reported by driver")
+
+	return report, nil
+}
+
+// GetReliability scores a rider on their record over the reliability window
+func (s *RiderReliabilityService) GetReliability(ctx context.Context, riderID uuid.UUID) (*domain.RiderReliability, error) {
+	now := time.Now().UTC()
+	behaviour, err := s.reportRepo.GetBehaviour(ctx, riderID, now.Add(-domain.RiderReliabilityWindow))
+	if err != nil {
+		return nil, err
+	}
+	return domain.ScoreRiderReliability(riderID, *behaviour, now), nil
+}
+
+// ListRiderReports lists the reports about a rider over the reliability
+// window, voided ones included
+func (s *RiderReliabilityService) ListRiderReports(ctx context.Context, riderID uuid.UUID) ([]*domain.RiderReport, error) {
+	return s.reportRepo.ListByRider(ctx, riderID, time.Now().UTC().Add(-domain.RiderReliabilityWindow))
+}
+
+// VoidReport voids a report support found unfounded, so it no longer counts
+// against the rider
+func (s *RiderReliabilityService) VoidReport(ctx context.Context, reportID, agentID uuid.UUID, note string) (*domain.RiderReport, error) {
+	report, err := s.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.VoidedAt != nil {
+		return nil, domain.ErrRiderReportVoided
+	}
+
+	now := time.Now().UTC()
+	report.VoidedBy = &agentID
+	report.VoidedAt = &now
+	report.VoidNote = note
+	if err := s.reportRepo.Void(ctx, report); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("report_id", report.ID.String()).
+		Str("agent_id", agentID.String()).
+		Msg("Rider report voided")
+
+	return report, nil
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/uber/h3-go/v4"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/notification"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+)
+
+// roadReportRadiusM is the zone an incident raised from reports covers,
+// about one report cell
+const roadReportRadiusM = 200.0
+
+// RoadReportService collects drivers' reports of blocked roads, police
+// checkpoints and flooding. Reports add up per H3 cell, decaying over
+// time, and raise an incident once enough drivers agree. Reporters are
+// asked before their report expires whether it still stands.
+type RoadReportService struct {
+	driverPool *redis.DriverPool
+	incidents  *IncidentService
+	notifier   Notifier
+}
+
+// NewRoadReportService creates a new road report service
+func NewRoadReportService(driverPool *redis.DriverPool, incidents *IncidentService, notifier Notifier) *RoadReportService {
+	return &RoadReportService{
+		driverPool: driverPool,
+		incidents:  incidents,
+		notifier:   notifier,
+	}
+}
+
+// Submit records a driver's road report and counts it toward its cell
+func (s *RoadReportService) Submit(ctx context.Context, driverID uuid.UUID, category domain.RoadReportCategory, lat, lng float64) (*domain.RoadReport, error) {
+	if !category.IsValid() {
+		return nil, domain.ErrInvalidRoadReport
+	}
+	if !geo.IsValidCoordinate(lat, lng) {
+		return nil, domain.ErrInvalidLocation
+	}
+
+	now := time.Now().UTC()
+	report := &domain.RoadReport{
+		ID:        uuid.New(),
+		DriverID:  driverID,
+		Category:  category,
+		Latitude:  lat,
+		Longitude: lng,
+		H3Cell:    h3.LatLngToCell(h3.LatLng{Lat: lat, Lng: lng}, domain.RoadReportH3Resolution).String(),
+		Status:    domain.RoadReportPending,
+		CreatedAt: now,
+		ExpiresAt: now.Add(domain.RoadReportTTL),
+	}
+
+	if err := s.count(ctx, report); err != nil {
+		return nil, err
+	}
+	if err := s.driverPool.SaveRoadReport(ctx, report); err != nil {
+		return nil, err
+	}
+	if err := s.driverPool.ScheduleRoadReportPrompt(ctx, report.ID, report.ExpiresAt.Add(-domain.RoadReportPromptBefore)); err != nil {
+		log.Warn().Err(err).Str("report_id", report.ID.String()).Msg("Failed to schedule road report prompt")
+	}
+	return report, nil
+}
+
+// List gets a driver's recent reports with where each stands now
+func (s *RoadReportService) List(ctx context.Context, driverID uuid.UUID) ([]*domain.RoadReport, error) {
+	reports, err := s.driverPool.ListRoadReports(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, report := range reports {
+		if report.Status == domain.RoadReportCleared {
+			continue
+		}
+		// Another driver's report may have raised or extended the incident
+		// since this one was saved
+		incidentID, err := s.driverPool.GetRoadIncident(ctx, report.H3Cell, report.Category)
+		if err != nil {
+			return nil, err
+		}
+		if incidentID != nil {
+			if incident, err := s.incidents.Get(ctx, *incidentID); err == nil && incident != nil {
+				report.Status = domain.RoadReportConfirmed
+				report.IncidentID = incidentID
+				report.ExpiresAt = incident.ExpiresAt
+			}
+		}
+		if !now.Before(report.ExpiresAt) {
+			report.Status = domain.RoadReportExpired
+		}
+	}
+	return reports, nil
+}
+
+// Confirm answers whether a reported issue is still there. Confirming
+// counts the report again and extends it; clearing takes it off the cell
+// and resolves the incident once too few reports remain.
+func (s *RoadReportService) Confirm(ctx context.Context, driverID, reportID uuid.UUID, stillThere bool) (*domain.RoadReport, error) {
+	report, err := s.driverPool.GetRoadReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil || report.DriverID != driverID {
+		return nil, domain.ErrRoadReportNotFound
+	}
+
+	now := time.Now().UTC()
+	if report.Status == domain.RoadReportCleared || !now.Before(report.ExpiresAt) {
+		return nil, domain.ErrRoadReportClosed
+	}
+
+	if stillThere {
+		report.Status = domain.RoadReportPending
+		report.ExpiresAt = now.Add(domain.RoadReportTTL)
+		if err := s.count(ctx, report); err != nil {
+			return nil, err
+		}
+		if err := s.driverPool.ScheduleRoadReportPrompt(ctx, report.ID, report.ExpiresAt.Add(-domain.RoadReportPromptBefore)); err != nil {
+			log.Warn().Err(err).Str("report_id", report.ID.String()).Msg("Failed to schedule road report prompt")
+		}
+	} else {
+		if err := s.tally(ctx, report, -1); err != nil {
+			return nil, err
+		}
+		report.Status = domain.RoadReportCleared
+		report.ExpiresAt = now
+	}
+
+	if err := s.driverPool.SaveRoadReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// SendPrompts asks reporters whose reports are about to expire whether
+// the issue is still there
+func (s *RoadReportService) SendPrompts(ctx context.Context) error {
+	due, err := s.driverPool.ClaimDueRoadReportPrompts(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, reportID := range due {
+		report, err := s.driverPool.GetRoadReport(ctx, reportID)
+		if err != nil {
+			log.Error().Err(err).Str("report_id", reportID.String()).Msg("Failed to get road report")
+			continue
+		}
+		if report == nil || report.Status == domain.RoadReportCleared || s.notifier == nil {
+			continue
+		}
+
+		body := fmt.Sprintf("Is the %s you reported still there? Let us know so other drivers get the right warning.", categoryLabel(report.Category))
+		err = s.notifier.SendPush(ctx, report.DriverID, "Still there?", body, notification.PriorityNormal, map[string]string{
+			"type":       "ROAD_REPORT_CONFIRM",
+			"report_id":  report.ID.String(),
+			"category":   string(report.Category),
+			"expires_at": report.ExpiresAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			log.Warn().Err(err).Str("report_id", reportID.String()).Msg("Failed to send road report prompt")
+		}
+	}
+	return nil
+}
+
+// count adds a report to its cell, once per driver per half-life
+func (s *RoadReportService) count(ctx context.Context, report *domain.RoadReport) error {
+	counted, err := s.driverPool.MarkRoadReporter(ctx, report.H3Cell, report.Category, report.DriverID)
+	if err != nil {
+		return err
+	}
+	weight := 0.0
+	if counted {
+		weight = 1
+	}
+	return s.tally(ctx, report, weight)
+}
+
+// tally adds weight to the report's cell and raises, extends or resolves
+// the cell's incident for the new score
+func (s *RoadReportService) tally(ctx context.Context, report *domain.RoadReport, weight float64) error {
+	score, err := s.driverPool.AddRoadCellScore(ctx, report.H3Cell, report.Category, weight)
+	if err != nil {
+		return err
+	}
+	incidentID, err := s.driverPool.GetRoadIncident(ctx, report.H3Cell, report.Category)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case score >= domain.RoadReportIncidentAt:
+		incident, err := s.raiseIncident(ctx, report, incidentID)
+		if err != nil {
+			return err
+		}
+		report.Status = domain.RoadReportConfirmed
+		report.IncidentID = &incident.ID
+		report.ExpiresAt = incident.ExpiresAt
+
+	case incidentID != nil && score < domain.RoadReportIncidentAt/2:
+		// Enough reporters have said it cleared
+		if err := s.incidents.Resolve(ctx, *incidentID); err != nil && !errors.Is(err, domain.ErrIncidentNotFound) {
+			return err
+		}
+		if err := s.driverPool.ClearRoadIncident(ctx, report.H3Cell, report.Category); err != nil {
+			return err
+		}
+
+	case incidentID != nil:
+		report.Status = domain.RoadReportConfirmed
+		report.IncidentID = incidentID
+	}
+	return nil
+}
+
+// raiseIncident extends the cell's incident, or raises one at the cell's
+// center when there is none in force
+func (s *RoadReportService) raiseIncident(ctx context.Context, report *domain.RoadReport, incidentID *uuid.UUID) (*domain.TrafficIncident, error) {
+	var incident *domain.TrafficIncident
+	if incidentID != nil {
+		existing, err := s.incidents.Get(ctx, *incidentID)
+		if err != nil {
+			return nil, err
+		}
+		incident = existing
+	}
+	if incident == nil {
+		center := h3.CellToLatLng(h3.Cell(h3.IndexFromString(report.H3Cell)))
+		incident = &domain.TrafficIncident{
+			Type:         report.Category.IncidentType(),
+			Source:       domain.IncidentSourceDriver,
+			Description:  "Reported by drivers: " + categoryLabel(report.Category),
+			Latitude:     center.Lat,
+			Longitude:    center.Lng,
+			RadiusMeters: roadReportRadiusM,
+		}
+	}
+	incident.ExpiresAt = time.Now().UTC().Add(domain.RoadReportTTL)
+
+	if err := s.incidents.Report(ctx, incident); err != nil {
+		return nil, err
+	}
+	if err := s.driverPool.SetRoadIncident(ctx, report.H3Cell, report.Category, incident.ID, domain.RoadReportTTL); err != nil {
+		return nil, err
+	}
+	return incident, nil
+}
+
+// categoryLabel names a category for drivers, e.g. "police checkpoint"
+func categoryLabel(category domain.RoadReportCategory) string {
+	return strings.ToLower(strings.ReplaceAll(string(category), "_", " "))
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/eta"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/locale"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/pricing"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+)
+
+// routeQuoteMatchM is how far a ride's pickup or dropoff may be from the
+// quoted trip's for the quote to apply
+const routeQuoteMatchM = 100.0
+
+// RouteOptionService quotes alternative routes for a trip at ride
+// confirmation, each priced for the rider's ride type
+type RouteOptionService struct {
+	mapsClient    *geo.MapsClient
+	pricingEngine *pricing.Engine
+	cities        *cityconfig.Registry
+	driverPool    *redis.DriverPool
+}
+
+// NewRouteOptionService creates a new route option service
+func NewRouteOptionService(
+	mapsClient *geo.MapsClient,
+	pricingEngine *pricing.Engine,
+	cities *cityconfig.Registry,
+	driverPool *redis.DriverPool,
+) *RouteOptionService {
+	return &RouteOptionService{
+		mapsClient:    mapsClient,
+		pricingEngine: pricingEngine,
+		cities:        cities,
+		driverPool:    driverPool,
+	}
+}
+
+// Quote fetches the fastest, shortest and toll-free routes for a trip,
+// prices each and caches them until the rider confirms one. Every route
+// keeps to the rider's avoidance preferences.
+func (s *RouteOptionService) Quote(ctx context.Context, riderID uuid.UUID, pickup, dropoff domain.Location, rideType domain.RideType, avoid domain.RouteAvoidance) (*domain.RouteOptionsQuote, error) {
+	request := geo.DirectionsRequest{
+		OriginLat:     pickup.Latitude,
+		OriginLng:     pickup.Longitude,
+		DestLat:       dropoff.Latitude,
+		DestLng:       dropoff.Longitude,
+		DepartureTime: time.Now(),
+		Mode:          eta.ProfileForRideType(rideType).GoogleMode(),
+		AvoidTolls:    avoid.Tolls,
+		AvoidHighways: avoid.Highways,
+	}
+
+	request.Alternatives = true
+	directions, err := s.mapsClient.GetDirections(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	alternatives := routeCandidates(directions)
+	if len(alternatives) == 0 {
+		return nil, domain.ErrRouteNotFound
+	}
+
+	// Toll-free routes are only worth offering when the fastest has tolls
+	var tollFree []domain.RouteCandidate
+	for _, route := range alternatives {
+		if !route.HasTolls {
+			continue
+		}
+		request.Alternatives = false
+		request.AvoidTolls = true
+		if directions, err := s.mapsClient.GetDirections(ctx, request); err != nil {
+			log.Warn().Err(err).Msg("Failed to get toll-free route")
+		} else {
+			tollFree = routeCandidates(directions)
+		}
+		break
+	}
+
+	quote := &domain.RouteOptionsQuote{
+		ID:        uuid.New(),
+		RiderID:   riderID,
+		RideType:  rideType,
+		Pickup:    pickup,
+		Dropoff:   dropoff,
+		Avoid:     avoid,
+		Options:   domain.SelectRouteOptions(alternatives, tollFree),
+		ExpiresAt: time.Now().UTC().Add(domain.RouteOptionsTTL),
+	}
+	if err := s.priceOptions(ctx, quote); err != nil {
+		return nil, err
+	}
+
+	if s.driverPool != nil {
+		if err := s.driverPool.CacheRouteOptions(ctx, quote); err != nil {
+			return nil, err
+		}
+	}
+
+	return quote, nil
+}
+
+// priceOptions prices each option like the ride request would, with the
+// pickup city's bundle and surge
+func (s *RouteOptionService) priceOptions(ctx context.Context, quote *domain.RouteOptionsQuote) error {
+	currency := locale.Currency(ctx)
+	cityCode := ""
+	resolution := geo.H3Resolution
+	if s.cities != nil {
+		if city, ok := s.cities.FindByLocation(quote.Pickup.Latitude, quote.Pickup.Longitude); ok {
+			if !city.SupportsRideType(quote.RideType) {
+				return domain.ErrRideTypeUnavailable
+			}
+			currency = city.Currency
+			cityCode = city.Code
+			if city.H3Resolution > 0 {
+				resolution = city.H3Resolution
+			}
+		}
+	}
+	h3Cell := geo.H3Cell(quote.Pickup.Latitude, quote.Pickup.Longitude, resolution)
+
+	for i := range quote.Options {
+		option := &quote.Options[i]
+		price, err := s.pricingEngine.CalculateCityPrice(
+			cityCode, quote.RideType,
+			float64(option.DistanceMeters), option.DurationSeconds,
+			currency, h3Cell, 0,
+		)
+		if err != nil {
+			return domain.ErrPricingFailed
+		}
+		option.Fare = price.Total
+		option.Currency = price.Currency
+		option.FareDelta = option.Fare - quote.Options[0].Fare
+	}
+	return nil
+}
+
+// routeCandidates reads each returned route's totals across its legs,
+// preferring travel time in traffic
+func routeCandidates(directions *geo.DirectionsResponse) []domain.RouteCandidate {
+	var candidates []domain.RouteCandidate
+	for _, route := range directions.Routes {
+		if len(route.Legs) == 0 {
+			continue
+		}
+
+		candidate := domain.RouteCandidate{
+			Summary:  route.Summary,
+			Polyline: route.OverviewPolyline.Points,
+		}
+		for _, leg := range route.Legs {
+			candidate.DistanceMeters += int64(leg.Distance.Value)
+			if leg.DurationInTraffic.Value > 0 {
+				candidate.DurationSeconds += int64(leg.DurationInTraffic.Value)
+			} else {
+				candidate.DurationSeconds += int64(leg.Duration.Value)
+			}
+		}
+
+		// Google flags tolls only in the route's warnings
+		for _, warning := range route.Warnings {
+			if strings.Contains(strings.ToLower(warning), "toll") {
+				candidate.HasTolls = true
+				break
+			}
+		}
+
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// quotedRoute returns the route a rider picked from a quote for their ride
+// request. The quote must be theirs, for the same ride type, trip and
+// avoidance preferences.
+func quotedRoute(ctx context.Context, pool *redis.DriverPool, req *domain.RideRequest) (*domain.RouteOption, error) {
+	if pool == nil || len(req.Stops) > 0 {
+		return nil, domain.ErrRouteOptionNotFound
+	}
+
+	quote, err := pool.GetRouteOptions(ctx, *req.RouteQuoteID)
+	if err != nil {
+		return nil, err
+	}
+	if quote == nil || quote.RiderID != req.RiderID || quote.RideType != req.Type || quote.Avoid != req.Avoid {
+		return nil, domain.ErrRouteOptionNotFound
+	}
+	if geo.HaversineDistance(quote.Pickup.Latitude, quote.Pickup.Longitude, req.PickupLocation.Latitude, req.PickupLocation.Longitude) > routeQuoteMatchM ||
+		geo.HaversineDistance(quote.Dropoff.Latitude, quote.Dropoff.Longitude, req.DropoffLocation.Latitude, req.DropoffLocation.Longitude) > routeQuoteMatchM {
+		return nil, domain.ErrRouteOptionNotFound
+	}
+
+	preference := req.RoutePreference
+	if preference == "" {
+		preference = domain.RoutePreferenceFastest
+	}
+	option, ok := quote.Option(preference)
+	if !ok {
+		return nil, domain.ErrRouteOptionNotFound
+	}
+	return option, nil
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// SafetyAlertPublisher delivers SOS alerts to the ops team
+type SafetyAlertPublisher interface {
+	Publish(ctx context.Context, alert *domain.SafetyAlert) error
+}
+
+// SOSRequest is an SOS pressed in the app, with the device's position when
+// it has one
+type SOSRequest struct {
+	RideID    uuid.UUID
+	UserID    uuid.UUID
+	Latitude  float64
+	Longitude float64
+	Note      string
+}
+
+// SafetyService records SOS incidents raised during rides, alerts ops and
+// tracks the incidents through acknowledgement to resolution
+type SafetyService struct {
+	rideService  *RideService
+	incidentRepo *repository.SafetyIncidentRepository
+	driverRepo   *repository.DriverRepository
+	driverPool   *redis.DriverPool
+	publisher    SafetyAlertPublisher
+}
+
+// NewSafetyService creates a new safety service. driverPool may be nil, in
+// which case an SOS without a device position falls back to the ride's
+// last known location.
+func NewSafetyService(
+	rideService *RideService,
+	incidentRepo *repository.SafetyIncidentRepository,
+	driverRepo *repository.DriverRepository,
+	driverPool *redis.DriverPool,
+	publisher SafetyAlertPublisher,
+) *SafetyService {
+	return &SafetyService{
+		rideService:  rideService,
+		incidentRepo: incidentRepo,
+		driverRepo:   driverRepo,
+		driverPool:   driverPool,
+		publisher:    publisher,
+	}
+}
+
+// RaiseSOS records an SOS from the ride's rider or driver and alerts ops.
+// Pressing it again while the ride's incident is unresolved updates that
+// incident's location and alerts again rather than opening another.
+func (s *SafetyService) RaiseSOS(ctx context.Context, req *SOSRequest) (*domain.SafetyIncident, error) {
+	ride, err := s.rideService.GetRide(ctx, req.RideID)
+	if err != nil {
+		return nil, err
+	}
+
+	var role string
+	switch {
+	case ride.RiderID == req.UserID:
+		role = domain.SOSRaisedByRider
+	case ride.DriverID != nil && *ride.DriverID == req.UserID:
+		role = domain.SOSRaisedByDriver
+	default:
+		return nil, domain.ErrSOSNotAllowed
+	}
+	if !ride.IsActive() && !recentlyEnded(ride, time.Now()) {
+		return nil, domain.ErrSOSNotAllowed
+	}
+
+	location := s.sosLocation(ctx, ride, req)
+	now := time.Now().UTC()
+
+	incident, err := s.incidentRepo.GetUnresolvedByRide(ctx, ride.ID)
+	if err != nil && err != domain.ErrSafetyIncidentNotFound {
+		return nil, err
+	}
+	repeat := incident != nil
+	if repeat {
+		if location != nil {
+			if err := s.incidentRepo.UpdateLocation(ctx, incident.ID, location); err != nil {
+				log.Error().Err(err).Str("incident_id", incident.ID.String()).Msg("Failed to update SOS location")
+			}
+			incident.Location = location
+		}
+	} else {
+		incident = &domain.SafetyIncident{
+			ID:           uuid.New(),
+			RideID:       ride.ID,
+			RaisedBy:     req.UserID,
+			RaisedByRole: role,
+			Status:       domain.SafetyIncidentOpen,
+			Location:     location,
+			Note:         req.Note,
+			Snapshot:     s.snapshot(ctx, ride),
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if err := s.incidentRepo.Create(ctx, incident); err != nil {
+			if err != domain.ErrSafetyIncidentOpen {
+				return nil, err
+			}
+			// Pressed twice at once; the other press opened it
+			if incident, err = s.incidentRepo.GetUnresolvedByRide(ctx, ride.ID); err != nil {
+				return nil, err
+			}
+			repeat = true
+		}
+	}
+
+	alert := &domain.SafetyAlert{
+		IncidentID: incident.ID,
+		RideID:     ride.ID,
+		RaisedBy:   req.UserID,
+		Role:       role,
+		Priority:   domain.SafetyAlertPriority,
+		Location:   location,
+		Note:       req.Note,
+		Snapshot:   incident.Snapshot,
+		Repeat:     repeat,
+		RaisedAt:   now,
+	}
+	if err := s.publisher.Publish(ctx, alert); err != nil {
+		// The incident is recorded and shows in the ops queue, but nobody has
+		// been paged
+		log.Error().Err(err).
+			Bool("alert", true).
+			Str("incident_id", incident.ID.String()).
+			Str("ride_id", ride.ID.String()).
+			Msg("Failed to publish SOS alert")
+	}
+
+	if !repeat {
+		s.rideService.RecordEvent(ctx, domain.NewRideEvent(ride.ID, domain.RideEventSOSRaised).
+			WithActor(req.UserID).
+			WithData("incident_id", incident.ID).
+			WithData("role", role))
+	}
+
+	log.Warn().
+		Str("incident_id", incident.ID.String()).
+		Str("ride_id", ride.ID.String()).
+		Str("role", role).
+		Bool("repeat", repeat).
+		Msg("SOS raised")
+
+	return incident, nil
+}
+
+// sosGracePeriod lets an SOS be raised just after a ride ends, when the
+// rider may still be with the driver
+const sosGracePeriod = 30 * time.Minute
+
+func recentlyEnded(ride *domain.Ride, now time.Time) bool {
+	ended := ride.CompletedAt
+	if ended == nil {
+		ended = ride.CancelledAt
+	}
+	return ended != nil && now.Sub(*ended) < sosGracePeriod
+}
+
+// sosLocation prefers the device's position, then the driver's live
+// position, then the ride's last known location
+func (s *SafetyService) sosLocation(ctx context.Context, ride *domain.Ride, req *SOSRequest) *domain.Location {
+	if (req.Latitude != 0 || req.Longitude != 0) && geo.IsValidCoordinate(req.Latitude, req.Longitude) {
+		return &domain.Location{Latitude: req.Latitude, Longitude: req.Longitude}
+	}
+	if s.driverPool != nil && ride.DriverID != nil {
+		loc, err := s.driverPool.GetDriverLocation(ctx, *ride.DriverID)
+		if err != nil {
+			log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to load driver location for SOS")
+		}
+		if loc != nil {
+			return &domain.Location{Latitude: loc.Latitude, Longitude: loc.Longitude, H3Cell: loc.H3Cell}
+		}
+	}
+	return ride.CurrentLocation
+}
+
+// snapshot freezes the ride and its driver for the incident
+func (s *SafetyService) snapshot(ctx context.Context, ride *domain.Ride) *domain.SafetySnapshot {
+	snapshot := &domain.SafetySnapshot{
+		RideStatus:      ride.Status,
+		RiderID:         ride.RiderID,
+		PickupLocation:  ride.PickupLocation,
+		DropoffLocation: ride.DropoffLocation,
+		Stops:           ride.Stops,
+		StartedAt:       ride.StartedAt,
+		DriverID:        ride.DriverID,
+	}
+	if ride.DriverID == nil || s.driverRepo == nil {
+		return snapshot
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, *ride.DriverID)
+	if err != nil {
+		log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to load driver for SOS snapshot")
+		return snapshot
+	}
+	snapshot.DriverName = driver.FirstName + " " + driver.LastName
+	snapshot.DriverPhone = driver.Phone
+	snapshot.Vehicle = driver.Vehicle
+	return snapshot
+}
+
+// GetIncident gets an incident
+func (s *SafetyService) GetIncident(ctx context.Context, id uuid.UUID) (*domain.SafetyIncident, error) {
+	return s.incidentRepo.GetByID(ctx, id)
+}
+
+// ListIncidents lists incidents in a status, longest waiting first
+func (s *SafetyService) ListIncidents(ctx context.Context, status domain.SafetyIncidentStatus, limit, offset int) ([]*domain.SafetyIncident, int64, error) {
+	return s.incidentRepo.ListByStatus(ctx, status, limit, offset)
+}
+
+// Acknowledge records that an agent has picked up an incident
+func (s *SafetyService) Acknowledge(ctx context.Context, id, agentID uuid.UUID) (*domain.SafetyIncident, error) {
+	return s.transition(ctx, id, agentID, domain.SafetyIncidentAcknowledged, "")
+}
+
+// Resolve closes an incident with what was done about it
+func (s *SafetyService) Resolve(ctx context.Context, id, agentID uuid.UUID, resolution string) (*domain.SafetyIncident, error) {
+	if resolution == "" {
+		return nil, domain.ErrInvalidRequest
+	}
+	return s.transition(ctx, id, agentID, domain.SafetyIncidentResolved, resolution)
+}
+
+func (s *SafetyService) transition(ctx context.Context, id, agentID uuid.UUID, next domain.SafetyIncidentStatus, resolution string) (*domain.SafetyIncident, error) {
+	incident, err := s.incidentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	from := incident.Status
+	if !from.CanTransitionTo(next) {
+		return nil, domain.ErrInvalidSafetyTransition
+	}
+
+	now := time.Now().UTC()
+	incident.Status = next
+	incident.UpdatedAt = now
+	switch next {
+	case domain.SafetyIncidentAcknowledged:
+		incident.AcknowledgedBy = &agentID
+		incident.AcknowledgedAt = &now
+	case domain.SafetyIncidentResolved:
+		if incident.AcknowledgedAt == nil {
+			incident.AcknowledgedBy = &agentID
+			incident.AcknowledgedAt = &now
+		}
+		incident.ResolvedBy = &agentID
+		incident.ResolvedAt = &now
+		incident.Resolution = resolution
+	}
+
+	if err := s.incidentRepo.UpdateStatus(ctx, incident, from); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("incident_id", incident.ID.String()).
+		Str("agent_id", agentID.String()).
+		Str("status", string(next)).
+		Msg("Safety incident updated")
+
+	return incident, nil
+}
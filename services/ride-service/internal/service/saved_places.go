@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// SavedPlaceService manages riders' saved places. Saved home and work feed
+// destination prediction, so changing them drops the rider's cached
+// location history.
+type SavedPlaceService struct {
+	placeRepo *repository.SavedPlaceRepository
+	history   *LocationHistoryService
+}
+
+// NewSavedPlaceService creates a new saved place service. history may be
+// nil, in which case saved places are not used for prediction.
+func NewSavedPlaceService(placeRepo *repository.SavedPlaceRepository, history *LocationHistoryService) *SavedPlaceService {
+	return &SavedPlaceService{
+		placeRepo: placeRepo,
+		history:   history,
+	}
+}
+
+// ListPlaces lists a rider's saved places, home and work first
+func (s *SavedPlaceService) ListPlaces(ctx context.Context, riderID uuid.UUID) ([]*domain.SavedPlace, error) {
+	return s.placeRepo.ListByRider(ctx, riderID)
+}
+
+// CreatePlace saves a new place for a rider
+func (s *SavedPlaceService) CreatePlace(ctx context.Context, riderID uuid.UUID, kind domain.SavedPlaceKind, label string, location domain.Location) (*domain.SavedPlace, error) {
+	now := time.Now().UTC()
+	place := &domain.SavedPlace{
+		ID:        uuid.New(),
+		RiderID:   riderID,
+		Kind:      kind,
+		Label:     label,
+		Location:  location,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.prepare(place); err != nil {
+		return nil, err
+	}
+
+	count, err := s.placeRepo.CountByRider(ctx, riderID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= domain.SavedPlaceLimit {
+		return nil, domain.ErrSavedPlaceLimit
+	}
+
+	if err := s.placeRepo.Create(ctx, place); err != nil {
+		return nil, err
+	}
+	s.changed(ctx, place)
+	return place, nil
+}
+
+// UpdatePlace replaces a saved place's kind, label and location
+func (s *SavedPlaceService) UpdatePlace(ctx context.Context, riderID, placeID uuid.UUID, kind domain.SavedPlaceKind, label string, location domain.Location) (*domain.SavedPlace, error) {
+	place, err := s.placeRepo.GetByID(ctx, riderID, placeID)
+	if err != nil {
+		return nil, err
+	}
+
+	place.Kind = kind
+	place.Label = label
+	place.Location = location
+	place.UpdatedAt = time.Now().UTC()
+	if err := s.prepare(place); err != nil {
+		return nil, err
+	}
+
+	if err := s.placeRepo.Update(ctx, place); err != nil {
+		return nil, err
+	}
+	s.changed(ctx, place)
+	return place, nil
+}
+
+// DeletePlace deletes one of a rider's saved places
+func (s *SavedPlaceService) DeletePlace(ctx context.Context, riderID, placeID uuid.UUID) error {
+	place, err := s.placeRepo.GetByID(ctx, riderID, placeID)
+	if err != nil {
+		return err
+	}
+	if err := s.placeRepo.Delete(ctx, riderID, placeID); err != nil {
+		return err
+	}
+	s.changed(ctx, place)
+	return nil
+}
+
+// prepare validates a place and buckets it into its frequent place cell
+func (s *SavedPlaceService) prepare(place *domain.SavedPlace) error {
+	if err := place.Validate(); err != nil {
+		return err
+	}
+	domain.NormalizeLocation(&place.Location)
+	place.Cell = geo.H3Cell(place.Location.Latitude, place.Location.Longitude, domain.FrequentPlaceResolution)
+	return nil
+}
+
+// changed drops the rider's cached location history so prediction picks up
+// the change
+func (s *SavedPlaceService) changed(ctx context.Context, place *domain.SavedPlace) {
+	if s.history != nil {
+		s.history.invalidate(ctx, place.RiderID)
+	}
+}
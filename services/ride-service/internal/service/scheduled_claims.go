@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+// GetDriverScheduledRides lists the scheduled rides a driver can claim over
+// the next ScheduledClaimHorizon, with their open claims and reliability
+func (s *ScheduledRideService) GetDriverScheduledRides(ctx context.Context, driverID uuid.UUID) (*domain.DriverScheduledRides, error) {
+	now := time.Now().UTC()
+
+	reliability, err := s.getReliability(ctx, driverID, now)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := s.rideRepo.ListDriverScheduledClaims(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only offer rides the driver's vehicle can serve
+	var rideTypes []string
+	if s.driverRepo != nil {
+		driver, err := s.driverRepo.GetByID(ctx, driverID)
+		if err != nil {
+			return nil, err
+		}
+		if driver.Vehicle != nil {
+			rideTypes = make([]string, 0, len(driver.Vehicle.SupportedTypes))
+			for _, t := range driver.Vehicle.SupportedTypes {
+				rideTypes = append(rideTypes, string(t))
+			}
+		}
+	}
+
+	available, err := s.rideRepo.ListClaimableScheduledRides(ctx,
+		now.Add(domain.ScheduledDispatchLead), now.Add(domain.ScheduledClaimHorizon), rideTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.DriverScheduledRides{
+		Available:   available,
+		Claims:      claims,
+		Reliability: reliability,
+	}, nil
+}
+
+// ClaimScheduledRide reserves a scheduled ride for a driver, who is assigned
+// it at dispatch if they are online
+func (s *ScheduledRideService) ClaimScheduledRide(ctx context.Context, rideID, driverID uuid.UUID) (*domain.ScheduledRideClaim, error) {
+	now := time.Now().UTC()
+
+	if pool := s.rideService.driverPool; pool != nil {
+		status, err := pool.GetDriverStatus(ctx, driverID)
+		if err != nil {
+			return nil, err
+		}
+		if status.IsRestricted() {
+			return nil, domain.ErrDriverRestricted
+		}
+	}
+
+	reliability, err := s.getReliability(ctx, driverID, now)
+	if err != nil {
+		return nil, err
+	}
+	if reliability.Score < domain.ScheduledClaimMinReliability {
+		return nil, domain.ErrClaimReliabilityLow
+	}
+	if reliability.ActiveClaims >= domain.ScheduledClaimMaxActive {
+		return nil, domain.ErrScheduledClaimLimit
+	}
+
+	ride, err := s.rideRepo.GetByID(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	if ride.Status != domain.RideStatusPending || ride.ScheduledFor == nil {
+		return nil, domain.ErrScheduledRideNotClaimable
+	}
+	lead := ride.ScheduledFor.Sub(now)
+	if lead <= domain.ScheduledDispatchLead || lead > domain.ScheduledClaimHorizon {
+		return nil, domain.ErrScheduledRideNotClaimable
+	}
+	if s.prefs != nil {
+		blocked, err := s.prefs.IsBlocked(ctx, ride.RiderID, driverID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, domain.ErrScheduledRideNotClaimable
+		}
+	}
+
+	if s.driverRepo != nil {
+		driver, err := s.driverRepo.GetByID(ctx, driverID)
+		if err != nil {
+			return nil, err
+		}
+		if driver.Vehicle == nil || !supportsRideType(driver.Vehicle, ride.Type) {
+			return nil, domain.ErrScheduledRideNotClaimable
+		}
+	}
+
+	claim := &domain.ScheduledRideClaim{
+		ID:           uuid.New(),
+		RideID:       ride.ID,
+		DriverID:     driverID,
+		ScheduledFor: *ride.ScheduledFor,
+		Status:       domain.ScheduledClaimActive,
+		ClaimedAt:    now,
+	}
+	if err := s.rideRepo.CreateScheduledClaim(ctx, claim); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("ride_id", ride.ID.String()).
+		Str("driver_id", driverID.String()).
+		Time("scheduled_for", claim.ScheduledFor).
+		Msg("Scheduled ride claimed")
+
+	return claim, nil
+}
+
+// ReleaseScheduledClaim gives up a driver's claim, penalising their
+// reliability if pickup is near
+func (s *ScheduledRideService) ReleaseScheduledClaim(ctx context.Context, rideID, driverID uuid.UUID) (*domain.ScheduledRideClaim, error) {
+	claim, err := s.rideRepo.GetActiveScheduledClaim(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	if claim == nil || claim.DriverID != driverID {
+		return nil, domain.ErrScheduledClaimNotFound
+	}
+
+	now := time.Now().UTC()
+	claim.Penalty = claim.ReleasePenalty(now)
+	if err := s.closeClaim(ctx, claim, domain.ScheduledClaimReleased, "released by driver", now); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("ride_id", rideID.String()).
+		Str("driver_id", driverID.String()).
+		Int("penalty", claim.Penalty).
+		Msg("Scheduled ride claim released")
+
+	return claim, nil
+}
+
+// assignClaimant assigns a dispatching ride to the driver who claimed it. If
+// the claimant is unavailable the claim lapses, penalised if they went
+// offline, and the ride is left to live matching. ride must be SEARCHING.
+func (s *ScheduledRideService) assignClaimant(ctx context.Context, ride *domain.Ride, claim *domain.ScheduledRideClaim, now time.Time) *domain.ScheduledRideClaim {
+	if s.driverRepo == nil {
+		return nil
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, claim.DriverID)
+	if err != nil {
+		log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to load scheduled ride claimant")
+		return nil
+	}
+
+	status := driver.Status
+	if pool := s.rideService.driverPool; pool != nil {
+		if status, err = pool.GetDriverStatus(ctx, claim.DriverID); err != nil {
+			log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to get scheduled ride claimant status")
+			return nil
+		}
+	}
+
+	if status != domain.DriverStatusOnline || driver.Vehicle == nil {
+		s.lapseClaim(ctx, claim, status, now)
+		return nil
+	}
+	if err := s.driverRepo.AssignRide(ctx, driver.ID, ride.ID); err != nil {
+		s.lapseClaim(ctx, claim, domain.DriverStatusBusy, now)
+		return nil
+	}
+	if err := ride.AssignDriver(driver.ID, driver.Vehicle.ID); err != nil {
+		log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to assign scheduled ride claimant")
+		return nil
+	}
+	discountCommission(ride, s.quality)
+
+	return claim
+}
+
+// lapseClaim hands a claimed ride back to live matching
+func (s *ScheduledRideService) lapseClaim(ctx context.Context, claim *domain.ScheduledRideClaim, status domain.DriverStatus, now time.Time) {
+	if status == domain.DriverStatusOffline || status.IsRestricted() {
+		claim.Penalty = domain.ScheduledClaimNoShowPenalty
+	}
+	if err := s.closeClaim(ctx, claim, domain.ScheduledClaimLapsed, "claimant "+string(status)+" at dispatch", now); err != nil {
+		log.Error().Err(err).Str("ride_id", claim.RideID.String()).Msg("Failed to lapse scheduled ride claim")
+	}
+
+	log.Warn().
+		Str("ride_id", claim.RideID.String()).
+		Str("driver_id", claim.DriverID.String()).
+		Str("driver_status", string(status)).
+		Int("penalty", claim.Penalty).
+		Msg("Scheduled ride claimant unavailable - falling back to live matching")
+}
+
+func (s *ScheduledRideService) closeClaim(ctx context.Context, claim *domain.ScheduledRideClaim, status domain.ScheduledClaimStatus, reason string, now time.Time) error {
+	claim.Status = status
+	claim.ReleaseReason = reason
+	claim.ClosedAt = &now
+	return s.rideRepo.CloseScheduledClaim(ctx, claim)
+}
+
+func (s *ScheduledRideService) getReliability(ctx context.Context, driverID uuid.UUID, now time.Time) (*domain.ClaimReliability, error) {
+	since := now.AddDate(0, 0, -domain.ScheduledClaimReliabilityDays)
+	return s.rideRepo.GetClaimReliability(ctx, driverID, since)
+}
+
+func supportsRideType(vehicle *domain.Vehicle, rideType domain.RideType) bool {
+	for _, t := range vehicle.SupportedTypes {
+		if t == rideType {
+			return true
+		}
+	}
+	return false
+}
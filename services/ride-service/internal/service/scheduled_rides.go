@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/notification"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/pricing"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// Notifier sends push notifications to riders
+type Notifier interface {
+	SendPush(ctx context.Context, userID uuid.UUID, title, body, priority string, data map[string]string) error
+}
+
+// ScheduledRideService dispatches scheduled rides at their locked fare, and
+// reminds riders, alarms ops and compensates riders as pickup approaches
+type ScheduledRideService struct {
+	rideService   *RideService
+	rideRepo      *repository.RideRepository
+	driverRepo    *repository.DriverRepository
+	pricingEngine *pricing.Engine
+	cities        *cityconfig.Registry
+	promos        *PromoService
+	notifier      Notifier
+	quality       *DriverQualityService
+	prefs         *DriverPreferenceService
+}
+
+// NewScheduledRideService creates a new scheduled ride service. cities may
+// be nil, in which case rides are repriced with the currency defaults.
+func NewScheduledRideService(
+	rideService *RideService,
+	rideRepo *repository.RideRepository,
+	driverRepo *repository.DriverRepository,
+	pricingEngine *pricing.Engine,
+	cities *cityconfig.Registry,
+	promos *PromoService,
+	notifier Notifier,
+) *ScheduledRideService {
+	return &ScheduledRideService{
+		rideService:   rideService,
+		rideRepo:      rideRepo,
+		driverRepo:    driverRepo,
+		pricingEngine: pricingEngine,
+		cities:        cities,
+		promos:        promos,
+		notifier:      notifier,
+	}
+}
+
+// SetQualityProgram gives claimants the commission discount their quality
+// tier earns when their ride is dispatched
+func (s *ScheduledRideService) SetQualityProgram(quality *DriverQualityService) {
+	s.quality = quality
+}
+
+// SetDriverPreferences keeps drivers a rider blocked from claiming their
+// scheduled rides
+func (s *ScheduledRideService) SetDriverPreferences(prefs *DriverPreferenceService) {
+	s.prefs = prefs
+}
+
+// Run dispatches rides coming up for pickup, sends due reminders, alarms ops
+// about rides still without a driver and compensates riders whose rides the
+// platform failed to fulfil
+func (s *ScheduledRideService) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	if err := s.dispatch(ctx, now); err != nil {
+		return err
+	}
+	if err := s.remind(ctx, now); err != nil {
+		return err
+	}
+	if err := s.alarm(ctx, now); err != nil {
+		return err
+	}
+	return s.compensate(ctx, now)
+}
+
+// dispatch reprices rides due for matching at the current surge, keeps the
+// fare within the band locked at booking, and assigns each ride to the
+// driver who claimed it or starts searching for one
+func (s *ScheduledRideService) dispatch(ctx context.Context, now time.Time) error {
+	rides, err := s.rideRepo.GetPendingScheduledRides(ctx, now.Add(domain.ScheduledDispatchLead))
+	if err != nil {
+		return err
+	}
+
+	for _, ride := range rides {
+		g, err := s.rideRepo.GetScheduledGuarantee(ctx, ride.ID)
+		if err != nil {
+			log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to load scheduled ride guarantee")
+			continue
+		}
+
+		var snapshot *domain.FareSnapshot
+		if g != nil && ride.Price != nil && ride.Route != nil {
+			snapshot = s.reprice(ride, g, now)
+		}
+
+		if err := ride.UpdateStatus(domain.RideStatusSearching); err != nil {
+			continue
+		}
+
+		claim, err := s.rideRepo.GetActiveScheduledClaim(ctx, ride.ID)
+		if err != nil {
+			log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to load scheduled ride claim")
+		} else if claim != nil {
+			claim = s.assignClaimant(ctx, ride, claim, now)
+		}
+
+		if err := s.rideRepo.Update(ctx, ride); err != nil {
+			log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to dispatch scheduled ride")
+			continue
+		}
+		if claim != nil {
+			if err := s.closeClaim(ctx, claim, domain.ScheduledClaimFulfilled, "", now); err != nil {
+				log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to fulfil scheduled ride claim")
+			}
+			if s.rideService.driverPool != nil {
+				_ = s.rideService.driverPool.SetDriverStatus(ctx, claim.DriverID, domain.DriverStatusOnRide)
+			}
+		}
+		if snapshot != nil {
+			if err := s.rideRepo.SaveFareSnapshot(ctx, snapshot); err != nil {
+				log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to store fare snapshot")
+			}
+		}
+		if s.rideService.driverPool != nil {
+			_ = s.rideService.driverPool.CacheRide(ctx, ride)
+		}
+
+		log.Info().
+			Str("ride_id", ride.ID.String()).
+			Time("scheduled_for", *ride.ScheduledFor).
+			Bool("claimed", claim != nil).
+			Msg("Scheduled ride dispatched")
+	}
+
+	return nil
+}
+
+// reprice prices a ride at dispatch with the pickup cell's current surge and
+// clamps the fare to the ride's locked band, returning the snapshot to store
+func (s *ScheduledRideService) reprice(ride *domain.Ride, g *domain.ScheduledRideGuarantee, now time.Time) *domain.FareSnapshot {
+	cityCode := ""
+	resolution := geo.H3Resolution
+	if s.cities != nil {
+		if city, ok := s.cities.FindByLocation(ride.PickupLocation.Latitude, ride.PickupLocation.Longitude); ok {
+			cityCode = city.Code
+			if city.H3Resolution > 0 {
+				resolution = city.H3Resolution
+			}
+		}
+	}
+	h3Cell := ride.PickupLocation.H3Cell
+	if h3Cell == "" {
+		h3Cell = geo.H3Cell(ride.PickupLocation.Latitude, ride.PickupLocation.Longitude, resolution)
+	}
+
+	price, inputs, err := s.pricingEngine.CalculateCityPriceWithInputs(
+		cityCode,
+		ride.Type,
+		float64(ride.Route.DistanceMeters),
+		ride.Route.DurationSeconds,
+		g.Currency,
+		h3Cell,
+		ride.Price.PromoDiscount,
+		ride.Route.Legs...,
+	)
+	if err != nil || price.Currency != g.Currency {
+		// Keep the booking quote rather than charge in another currency
+		return nil
+	}
+
+	if clamped := g.ClampFare(price.Total); clamped != price.Total {
+		ride.RecordEvent(domain.NewRideEvent(ride.ID, domain.RideEventFareAdjusted).
+			WithData("reason", "scheduled_fare_band").
+			WithData("priced", price.Total).
+			WithData("charged", clamped))
+		price.Total = clamped
+		price.PlatformFee = int64(float64(clamped) * inputs.Commission)
+		price.DriverEarnings = clamped - price.PlatformFee
+	}
+	ride.Price = price
+
+	return &domain.FareSnapshot{
+		ID:     uuid.New(),
+		RideID: ride.ID,
+		Reason: domain.FareSnapshotScheduledDispatch,
+		Inputs: *inputs,
+		Route: domain.FareRouteInputs{
+			DistanceMeters:  ride.Route.DistanceMeters,
+			DurationSeconds: ride.Route.DurationSeconds,
+			DistanceSource:  domain.RouteDistanceHaversine,
+			DurationSource:  domain.RouteDurationEstimate,
+			Stops:           len(ride.Stops),
+		},
+		Promo: domain.FarePromoInputs{
+			Code:      ride.PromoCode,
+			Evaluated: ride.PromoCode != "" && s.promos != nil,
+			Discount:  price.PromoDiscount,
+			Note:      fmt.Sprintf("fare held within the band locked at booking (%d-%d)", g.FareLow, g.FareHigh),
+		},
+		Tax: domain.FareTaxInputs{
+			Rules: []string{},
+			Note:  "no tax rules are applied to ride fares",
+		},
+		Price:     price,
+		CreatedAt: now,
+	}
+}
+
+// remind sends riders the most recent reminder that has come due
+func (s *ScheduledRideService) remind(ctx context.Context, now time.Time) error {
+	if s.notifier == nil {
+		return nil
+	}
+
+	guarantees, err := s.rideRepo.ListUpcomingScheduledGuarantees(ctx, now, now.Add(domain.ScheduledReminder24h.Offset()))
+	if err != nil {
+		return err
+	}
+
+	for _, g := range guarantees {
+		reminder, ok := g.DueReminder(now)
+		if !ok {
+			continue
+		}
+
+		body := fmt.Sprintf("Your ride is booked for %s UTC. Your fare will be between %d and %d %s.",
+			g.ScheduledFor.Format("Mon 2 Jan 15:04"), g.FareLow, g.FareHigh, g.Currency)
+		err := s.notifier.SendPush(ctx, g.RiderID, "Upcoming ride", body, notification.PriorityNormal, map[string]string{
+			"type":     "SCHEDULED_RIDE_REMINDER",
+			"ride_id":  g.RideID.String(),
+			"reminder": string(reminder),
+		})
+		if err != nil {
+			// Later reminders still go out; a missed one is not retried
+			log.Warn().Err(err).Str("ride_id", g.RideID.String()).Str("reminder", string(reminder)).Msg("Failed to send scheduled ride reminder")
+		}
+
+		if err := s.rideRepo.MarkScheduledReminderSent(ctx, g.RideID, reminder); err != nil {
+			log.Error().Err(err).Str("ride_id", g.RideID.String()).Msg("Failed to record scheduled ride reminder")
+		}
+	}
+
+	return nil
+}
+
+// alarm alerts ops to scheduled rides close to pickup that have no driver
+func (s *ScheduledRideService) alarm(ctx context.Context, now time.Time) error {
+	guarantees, err := s.rideRepo.ListScheduledAwaitingDriver(ctx, now.Add(domain.ScheduledAssignmentDeadline))
+	if err != nil {
+		return err
+	}
+
+	for _, g := range guarantees {
+		log.Error().
+			Bool("alert", true).
+			Str("ride_id", g.RideID.String()).
+			Str("rider_id", g.RiderID.String()).
+			Time("scheduled_for", g.ScheduledFor).
+			Dur("until_pickup", g.ScheduledFor.Sub(now)).
+			Msg("Scheduled ride has no driver close to pickup time")
+
+		if err := s.rideRepo.MarkScheduledAlarmRaised(ctx, g.RideID, now); err != nil {
+			log.Error().Err(err).Str("ride_id", g.RideID.String()).Msg("Failed to record scheduled ride alarm")
+		}
+	}
+
+	return nil
+}
+
+// compensate cancels scheduled rides still unassigned after pickup time and
+// issues a voucher to riders of rides the platform failed to fulfil
+func (s *ScheduledRideService) compensate(ctx context.Context, now time.Time) error {
+	if s.promos == nil {
+		return nil
+	}
+
+	guarantees, err := s.rideRepo.ListUnfulfilledScheduledRides(ctx, now.Add(-domain.ScheduledFulfillmentGrace))
+	if err != nil {
+		return err
+	}
+
+	for _, g := range guarantees {
+		ride, err := s.rideRepo.GetByID(ctx, g.RideID)
+		if err != nil {
+			log.Error().Err(err).Str("ride_id", g.RideID.String()).Msg("Failed to load unfulfilled scheduled ride")
+			continue
+		}
+
+		if ride.IsActive() {
+			if err := s.cancelUnfulfilled(ctx, ride); err != nil {
+				log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to cancel unfulfilled scheduled ride")
+				continue
+			}
+		}
+
+		voucher, err := s.promos.IssueVoucher(ctx, g.RiderID, g.Currency, g.VoucherValue(), domain.ScheduledVoucherValidity,
+			fmt.Sprintf("Scheduled ride compensation %s", g.RideID))
+		if err != nil {
+			// Left unmarked so the next run retries
+			log.Error().Err(err).Str("ride_id", g.RideID.String()).Msg("Failed to issue scheduled ride voucher")
+			continue
+		}
+		code := voucher.Codes[0]
+
+		if err := s.rideRepo.MarkScheduledRideFailed(ctx, g.RideID, now, code, voucher.DiscountAmount); err != nil {
+			log.Error().Err(err).Str("ride_id", g.RideID.String()).Msg("Failed to record scheduled ride compensation")
+			continue
+		}
+
+		log.Info().
+			Str("ride_id", g.RideID.String()).
+			Str("rider_id", g.RiderID.String()).
+			Str("voucher_code", code).
+			Int64("voucher_amount", voucher.DiscountAmount).
+			Msg("Scheduled ride not fulfilled - rider compensated")
+
+		if s.notifier != nil {
+			body := fmt.Sprintf("We couldn't find a driver for your scheduled ride. Use code %s for %d %s off your next ride.",
+				code, voucher.DiscountAmount, g.Currency)
+			err := s.notifier.SendPush(ctx, g.RiderID, "Sorry about your ride", body, notification.PriorityHigh, map[string]string{
+				"type":         "SCHEDULED_RIDE_COMPENSATION",
+				"ride_id":      g.RideID.String(),
+				"voucher_code": code,
+			})
+			if err != nil {
+				log.Warn().Err(err).Str("ride_id", g.RideID.String()).Msg("Failed to notify rider of compensation voucher")
+			}
+		}
+	}
+
+	return nil
+}
+
+// cancelUnfulfilled cancels a scheduled ride on the platform's behalf
+func (s *ScheduledRideService) cancelUnfulfilled(ctx context.Context, ride *domain.Ride) error {
+	if err := ride.Cancel(uuid.Nil, "no driver assigned for scheduled ride"); err != nil {
+		return err
+	}
+	if err := s.rideRepo.Update(ctx, ride); err != nil {
+		return err
+	}
+
+	if s.rideService.driverPool != nil {
+		_ = s.rideService.driverPool.InvalidateRideCache(ctx, ride.ID)
+	}
+	if ride.PromoCode != "" {
+		if err := s.promos.Release(ctx, ride.ID); err != nil {
+			log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to release promo redemption")
+		}
+	}
+	return nil
+}
@@ -3,12 +3,18 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/eta"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/locale"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/matching"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/pricing"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
 	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
@@ -19,25 +25,195 @@ type RideService struct {
 	rideRepo      *repository.RideRepository
 	driverPool    *redis.DriverPool
 	pricingEngine *pricing.Engine
+	cities        *cityconfig.Registry
+	promos        *PromoService
+	router        eta.RoutingClient
+	weather       WeatherReporter
+	insurer       RideInsurer
+	reliability   RiderReliabilityChecker
+	receipts      RideReceiptIssuer
+	chats         RideChatCloser
+	business      BusinessRideAuthorizer
+	loyalty       RiderLoyalty
+	emissions     RideEmissionsEstimator
+	matcher       RideMatcher
 }
 
-// NewRideService creates a new ride service
+// WeatherReporter reports a city's current weather, nil when unknown
+type WeatherReporter interface {
+	Condition(cityCode string) *domain.WeatherCondition
+}
+
+// RideInsurer attaches passenger insurance to completed rides
+type RideInsurer interface {
+	AttachPolicy(ctx context.Context, ride *domain.Ride) (*domain.InsurancePolicy, error)
+}
+
+// RiderReliabilityChecker scores a rider's reliability
+type RiderReliabilityChecker interface {
+	GetReliability(ctx context.Context, riderID uuid.UUID) (*domain.RiderReliability, error)
+}
+
+// RideReceiptIssuer issues receipts for completed rides
+type RideReceiptIssuer interface {
+	IssueReceipt(ctx context.Context, ride *domain.Ride) (*domain.RideReceipt, error)
+}
+
+// RideChatCloser closes and archives a ride's chat once the ride ends
+type RideChatCloser interface {
+	CloseChat(ctx context.Context, ride *domain.Ride)
+}
+
+// BusinessRideAuthorizer checks rides requested on business profiles
+// against their account's policy and records those it allows
+type BusinessRideAuthorizer interface {
+	AuthorizeRide(ctx context.Context, req *domain.RideRequest, local time.Time) (*domain.BusinessRide, error)
+	RecordRide(ctx context.Context, ride *domain.BusinessRide) error
+}
+
+// RiderLoyalty accrues riders' points on completed rides and redeems them
+// against fares
+type RiderLoyalty interface {
+	Accrue(ctx context.Context, ride *domain.Ride) (*domain.LoyaltyEntry, error)
+	CheckRedemption(ctx context.Context, riderID uuid.UUID, points int64) error
+	Redeem(ctx context.Context, entry *domain.LoyaltyEntry) error
+	Release(ctx context.Context, rideID uuid.UUID) error
+}
+
+// RideEmissionsEstimator estimates the CO2 of rides as they complete
+type RideEmissionsEstimator interface {
+	Estimate(ctx context.Context, ride *domain.Ride) *domain.RideEmissions
+}
+
+// RideMatcher offers rides to nearby drivers and settles the offers they
+// answer
+type RideMatcher interface {
+	StartMatching(ctx context.Context, ride *domain.Ride) (<-chan *matching.MatchResult, error)
+	AcceptRide(ctx context.Context, rideID, driverID uuid.UUID) (*matching.MatchResult, error)
+	DeclineRide(rideID, driverID uuid.UUID) error
+	CancelMatching(rideID uuid.UUID) error
+}
+
+// NewRideService creates a new ride service. cities may be nil, in which case
+// rides are priced with the currency defaults; promos may be nil, in which
+// case promo codes are stored on the ride but not applied.
 func NewRideService(
 	rideRepo *repository.RideRepository,
 	driverPool *redis.DriverPool,
 	pricingEngine *pricing.Engine,
+	cities *cityconfig.Registry,
+	promos *PromoService,
 ) *RideService {
 	return &RideService{
 		rideRepo:      rideRepo,
 		driverPool:    driverPool,
 		pricingEngine: pricingEngine,
+		cities:        cities,
+		promos:        promos,
 	}
 }
 
+// SetRouter prices rides on the routed distance for the ride type's
+// vehicles instead of the straight-line distance
+func (s *RideService) SetRouter(router eta.RoutingClient) {
+	s.router = router
+}
+
+// SetWeather pads ETAs and warns riders requesting in severe weather
+func (s *RideService) SetWeather(weather WeatherReporter) {
+	s.weather = weather
+}
+
+// SetInsurer attaches insurance to rides as they complete in insured markets
+func (s *RideService) SetInsurer(insurer RideInsurer) {
+	s.insurer = insurer
+}
+
+// SetRiderReliability makes unreliable riders prepay and wait behind others
+// in high demand
+func (s *RideService) SetRiderReliability(reliability RiderReliabilityChecker) {
+	s.reliability = reliability
+}
+
+// SetReceipts issues riders a receipt as their rides complete
+func (s *RideService) SetReceipts(receipts RideReceiptIssuer) {
+	s.receipts = receipts
+}
+
+// SetChats closes riders' and drivers' chat as their rides end
+func (s *RideService) SetChats(chats RideChatCloser) {
+	s.chats = chats
+}
+
+// SetBusiness lets riders request rides on their business profiles
+func (s *RideService) SetBusiness(business BusinessRideAuthorizer) {
+	s.business = business
+}
+
+// SetLoyalty accrues riders' points as their rides complete and lets them
+// redeem points against fares
+func (s *RideService) SetLoyalty(loyalty RiderLoyalty) {
+	s.loyalty = loyalty
+}
+
+// SetEmissions records the estimated CO2 of rides as they complete, for
+// their receipts and riders' and business accounts' emissions stats
+func (s *RideService) SetEmissions(emissions RideEmissionsEstimator) {
+	s.emissions = emissions
+}
+
+// SetMatcher offers rides to nearby drivers as they are requested. Without
+// it rides wait for ops to dispatch them until the matching TTL.
+func (s *RideService) SetMatcher(matcher RideMatcher) {
+	s.matcher = matcher
+}
+
 // RequestRide creates a new ride request
 func (s *RideService) RequestRide(ctx context.Context, req *domain.RideRequest) (*domain.Ride, error) {
-	// Check if rider already has an active ride
+	// Pool trips can be matched and their fares split, but a driver on a
+	// ride cannot yet be assigned a second rider, so no city offers pool
+	// rides until they can be dispatched
+	if req.Type == domain.RideTypePool {
+		return nil, domain.ErrRideTypeUnavailable
+	}
+	if req.ScheduledFor != nil {
+		if err := domain.ValidateScheduledFor(time.Now().UTC(), *req.ScheduledFor); err != nil {
+			return nil, err
+		}
+	}
+	if req.BusinessAccountID == nil && req.PaymentMethod == domain.PaymentMethodBusinessInvoice {
+		return nil, fmt.Errorf("%w: invoiced rides must be requested on a business profile", domain.ErrBusinessRideNotAllowed)
+	}
+	if req.BusinessAccountID != nil && s.business == nil {
+		return nil, domain.ErrBusinessAccountNotFound
+	}
+	
+	// A double-tapped request gets back the ride the first tap created.
+	// Requests are serialized per rider so both taps cannot miss each other.
+	if s.driverPool != nil {
+		release, err := s.lockRideRequest(ctx, req.RiderID)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
 	if s.rideRepo != nil {
+		existing, err := s.findDuplicateRequest(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			log.Info().
+				Str("ride_id", existing.ID.String()).
+				Str("rider_id", req.RiderID.String()).
+				Msg("Duplicate ride request, returning existing ride")
+			return existing, nil
+		}
+	}
+	
+	// Check if rider already has an active ride; scheduled rides can be
+	// booked alongside one
+	if s.rideRepo != nil && req.ScheduledFor == nil {
 		activeRide, err := s.rideRepo.GetActiveByRider(ctx, req.RiderID)
 		if err != nil {
 			return nil, err
@@ -47,53 +223,179 @@ func (s *RideService) RequestRide(ctx context.Context, req *domain.RideRequest)
 		}
 	}
 	
+	// Riders with a poor record must pay up front rather than in cash; a
+	// failed lookup lets the request through rather than blocking riders
+	var reliability *domain.RiderReliability
+	if s.reliability != nil {
+		r, err := s.reliability.GetReliability(ctx, req.RiderID)
+		if err != nil {
+			log.Warn().Err(err).Str("rider_id", req.RiderID.String()).Msg("Failed to get rider reliability")
+		} else {
+			reliability = r
+		}
+	}
+	if reliability != nil && reliability.PrepaymentRequired && req.PaymentMethod == domain.PaymentMethodCash {
+		return nil, domain.ErrPrepaymentRequired
+	}
+	
+	// Keep the landmarks written into addresses for the driver
+	domain.NormalizeLocation(&req.PickupLocation)
+	domain.NormalizeLocation(&req.DropoffLocation)
+	for i := range req.Stops {
+		domain.NormalizeLocation(&req.Stops[i])
+	}
+	
 	// Calculate route and pricing
 	distance := geo.HaversineDistance(
 		req.PickupLocation.Latitude, req.PickupLocation.Longitude,
 		req.DropoffLocation.Latitude, req.DropoffLocation.Longitude,
 	)
 	
-	// Add stops to distance
-	if len(req.Stops) > 0 {
-		prevLat := req.PickupLocation.Latitude
-		prevLng := req.PickupLocation.Longitude
-		
-		for _, stop := range req.Stops {
-			distance += geo.HaversineDistance(prevLat, prevLng, stop.Latitude, stop.Longitude)
-			prevLat = stop.Latitude
-			prevLng = stop.Longitude
-		}
-		
-		// Add final leg to dropoff
-		distance += geo.HaversineDistance(prevLat, prevLng, req.DropoffLocation.Latitude, req.DropoffLocation.Longitude)
-	}
-	
 	// Estimate duration
-	duration := geo.EstimateETA(distance, string(req.Type))
+	profile := eta.ProfileForRideType(req.Type)
+	duration := geo.EstimateETA(distance, string(profile))
 	duration = geo.EstimateETAWithTraffic(duration, time.Now().Hour())
 	
+	// A route the rider picked at confirmation replaces the estimate, as
+	// does a routed trip; bikes and cars take different streets, so the
+	// route is planned for the ride type's vehicles. Rides with stops are
+	// routed and priced leg by leg.
+	var chosen *domain.RouteOption
+	var legs []domain.RouteLeg
+	routed := false
+	if req.RouteQuoteID != nil {
+		option, err := quotedRoute(ctx, s.driverPool, req)
+		if err != nil {
+			return nil, err
+		}
+		chosen = option
+		distance = float64(option.DistanceMeters)
+		duration = option.DurationSeconds
+		routed = true
+	} else if s.router != nil && len(req.Stops) == 0 {
+		route, err := s.router.GetRoute(ctx, &eta.ETARequest{
+			OriginLat:     req.PickupLocation.Latitude,
+			OriginLng:     req.PickupLocation.Longitude,
+			DestLat:       req.DropoffLocation.Latitude,
+			DestLng:       req.DropoffLocation.Longitude,
+			DepartureTime: time.Now(),
+			Profile:       profile,
+			AvoidTolls:    req.Avoid.Tolls,
+			AvoidHighways: req.Avoid.Highways,
+		})
+		if err != nil {
+			log.Warn().Err(err).Str("profile", string(profile)).Msg("Failed to route ride, using straight-line distance")
+		} else {
+			distance = route.Distance
+			duration = int64(route.Duration.Seconds())
+			routed = true
+		}
+	} else if len(req.Stops) > 0 {
+		legs, routed = s.routeLegs(ctx, req, profile)
+		distance, duration = domain.RouteLegTotals(legs)
+	}
+	
 	// Create ride
 	ride := domain.NewRide(req)
 	
-	// Set route info
+	// Set route info, keeping the chosen route to check the driver follows it
 	ride.Route = &domain.RouteInfo{
 		DistanceMeters:  int64(distance),
 		DurationSeconds: duration,
+		Legs:            legs,
+	}
+	if chosen != nil {
+		ride.Route.Polyline = chosen.Polyline
+		ride.Route.Preference = chosen.Preference
+		ride.Route.Summary = chosen.Summary
+	}
+	if req.Avoid.Any() {
+		avoid := req.Avoid
+		ride.Route.Avoid = &avoid
+	}
+	
+	// Price with the pickup city's bundle where one is configured, otherwise
+	// in the request's market currency
+	currency := locale.Currency(ctx)
+	cityCode := ""
+	timezone := ""
+	if l := locale.FromContext(ctx); l != nil {
+		timezone = l.Timezone
+	}
+	resolution := geo.H3Resolution
+	if s.cities != nil {
+		if city, ok := s.cities.FindByLocation(req.PickupLocation.Latitude, req.PickupLocation.Longitude); ok {
+			if !city.SupportsRideType(req.Type) {
+				return nil, domain.ErrRideTypeUnavailable
+			}
+			currency = city.Currency
+			cityCode = city.Code
+			timezone = city.Timezone
+			if city.H3Resolution > 0 {
+				resolution = city.H3Resolution
+			}
+			if s.weather != nil && req.ScheduledFor == nil {
+				if condition := s.weather.Condition(city.Code); condition != nil && condition.Severity == domain.WeatherSevere {
+					duration = city.Weather.PadETA(duration, condition.Severity)
+					for i := range legs {
+						legs[i].DurationSeconds = city.Weather.PadETA(legs[i].DurationSeconds, condition.Severity)
+					}
+					if len(legs) > 0 {
+						_, duration = domain.RouteLegTotals(legs)
+					}
+					ride.Route.DurationSeconds = duration
+					ride.Metadata["weather_notice"] = domain.WeatherNoticeSevere
+				}
+			}
+		}
+	}
+	
+	// Rides on a business profile must fit the account's policy at the
+	// local pickup time; invoiced accounts are billed instead of the rider
+	var business *domain.BusinessRide
+	if req.BusinessAccountID != nil {
+		authorized, err := s.authorizeBusinessRide(ctx, req, timezone)
+		if err != nil {
+			return nil, err
+		}
+		business = authorized
+		if business.MonthlyInvoicing {
+			ride.PaymentMethod = domain.PaymentMethodBusinessInvoice
+		} else if ride.PaymentMethod == domain.PaymentMethodBusinessInvoice {
+			return nil, fmt.Errorf("%w: the account is not invoiced monthly", domain.ErrBusinessRideNotAllowed)
+		}
+		ride.Metadata[domain.BusinessAccountMetadataKey] = business.AccountID.String()
+		if business.CostCenter != "" {
+			ride.Metadata[domain.CostCenterMetadataKey] = business.CostCenter
+		}
+	}
+	
+	// Green rides are matched with electric and hybrid vehicles first
+	if req.Preference != "" {
+		ride.Metadata[domain.RidePreferenceMetadataKey] = string(req.Preference)
 	}
 	
-	// Calculate price
 	h3Cell := req.PickupLocation.H3Cell
 	if h3Cell == "" {
-		h3Cell = geo.H3Cell(req.PickupLocation.Latitude, req.PickupLocation.Longitude, geo.H3Resolution)
+		h3Cell = geo.H3Cell(req.PickupLocation.Latitude, req.PickupLocation.Longitude, resolution)
 	}
 	
-	price, err := s.pricingEngine.CalculatePrice(
+	// Today's surge says nothing about a future pickup, so scheduled rides
+	// are quoted without it and repriced within their band at dispatch
+	surgeCell := h3Cell
+	if req.ScheduledFor != nil {
+		surgeCell = ""
+	}
+	
+	price, inputs, err := s.pricingEngine.CalculateCityPriceWithInputs(
+		cityCode,
 		req.Type,
 		distance,
 		duration,
-		domain.CurrencyNGN, // Default - should be based on location
-		h3Cell,
-		0, // NOTE: Promo discount lookup handled by promotion service
+		currency,
+		surgeCell,
+		0, // promo campaigns are applied below, once the undiscounted fare is known
+		legs...,
 	)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to calculate price")
@@ -101,14 +403,119 @@ func (s *RideService) RequestRide(ctx context.Context, req *domain.RideRequest)
 		ride.Price = price
 	}
 	
-	// Set status to searching
-	ride.Status = domain.RideStatusSearching
+	// Apply the rider's promo code, reserving the discount from its campaign
+	promo := domain.FarePromoInputs{
+		Code: req.PromoCode,
+		Note: "promo codes are not applied without a promo campaign store",
+	}
+	var redemption *domain.PromoRedemption
+	if req.PromoCode != "" && s.promos != nil && ride.Price != nil {
+		redemption, err = s.applyPromo(ctx, ride, cityCode, timezone, h3Cell, distance, duration)
+		if err != nil {
+			return nil, err
+		}
+		promo = domain.FarePromoInputs{
+			Code:      redemption.Code,
+			Evaluated: true,
+			Discount:  redemption.Discount,
+		}
+	}
 	
-	// Persist ride
+	// Then the rider's loyalty points, which take off what the promo left
+	var redeemed *domain.LoyaltyEntry
+	if req.RedeemPoints > 0 {
+		redeemed, err = s.redeemPoints(ctx, ride, req.RedeemPoints, cityCode, distance, duration)
+		if err != nil {
+			if redemption != nil {
+				if releaseErr := s.promos.Release(ctx, ride.ID); releaseErr != nil {
+					log.Error().Err(releaseErr).Str("ride_id", ride.ID.String()).Msg("Failed to release promo redemption")
+				}
+			}
+			return nil, err
+		}
+		promo.Points = -redeemed.Points
+		promo.PointsDiscount = redeemed.Amount
+	}
+	
+	// Scheduled rides wait with a locked fare band until dispatch; others
+	// start searching straight away
+	var guarantee *domain.ScheduledRideGuarantee
+	if ride.ScheduledFor != nil {
+		if ride.Price == nil {
+			return nil, domain.ErrPricingFailed
+		}
+		guarantee = domain.NewScheduledRideGuarantee(ride, time.Now().UTC())
+		ride.Metadata["fare_band"] = map[string]any{
+			"low":      guarantee.FareLow,
+			"high":     guarantee.FareHigh,
+			"currency": guarantee.Currency,
+		}
+	} else {
+		_ = ride.UpdateStatus(domain.RideStatusSearching)
+		if reliability != nil && ride.Price != nil && reliability.Deprioritized(ride.Price.SurgeMultiplier) {
+			ride.Metadata[domain.RiderPriorityMetadataKey] = domain.RiderPriorityLow
+		}
+	}
+	
+	// Persist ride, with the inputs behind its price for fare explanations
 	if s.rideRepo != nil {
 		if err := s.rideRepo.Create(ctx, ride); err != nil {
+			if redemption != nil {
+				if releaseErr := s.promos.Release(ctx, ride.ID); releaseErr != nil {
+					log.Error().Err(releaseErr).Str("ride_id", ride.ID.String()).Msg("Failed to release promo redemption")
+				}
+			}
+			if redeemed != nil {
+				if releaseErr := s.loyalty.Release(ctx, ride.ID); releaseErr != nil {
+					log.Error().Err(releaseErr).Str("ride_id", ride.ID.String()).Msg("Failed to release loyalty points")
+				}
+			}
 			return nil, err
 		}
+		
+		if business != nil {
+			business.RideID = ride.ID
+			business.CreatedAt = ride.RequestedAt
+			if err := s.business.RecordRide(ctx, business); err != nil {
+				log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to record business ride")
+			}
+		}
+		
+		if ride.Price != nil {
+			distanceSource, durationSource := domain.RouteDistanceHaversine, domain.RouteDurationEstimate
+			if routed {
+				distanceSource, durationSource = domain.RouteDistanceRouted, domain.RouteDurationRouted
+			}
+			snapshot := &domain.FareSnapshot{
+				ID:     uuid.New(),
+				RideID: ride.ID,
+				Reason: domain.FareSnapshotRideRequest,
+				Inputs: *inputs,
+				Route: domain.FareRouteInputs{
+					DistanceMeters:  ride.Route.DistanceMeters,
+					DurationSeconds: ride.Route.DurationSeconds,
+					DistanceSource:  distanceSource,
+					DurationSource:  durationSource,
+					Stops:           len(req.Stops),
+				},
+				Promo: promo,
+				Tax: domain.FareTaxInputs{
+					Rules: []string{},
+					Note:  "no tax rules are applied to ride fares",
+				},
+				Price:     ride.Price,
+				CreatedAt: time.Now().UTC(),
+			}
+			if err := s.rideRepo.SaveFareSnapshot(ctx, snapshot); err != nil {
+				log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to store fare snapshot")
+			}
+		}
+		
+		if guarantee != nil {
+			if err := s.rideRepo.SaveScheduledGuarantee(ctx, guarantee); err != nil {
+				return nil, err
+			}
+		}
 	}
 	
 	// Cache ride
@@ -123,11 +530,278 @@ func (s *RideService) RequestRide(ctx context.Context, req *domain.RideRequest)
 		Float64("distance", distance).
 		Msg("Ride request created")
 	
-	// NOTE: Matching process is started via the matching engine
+	// Scheduled rides are dispatched when they are due
+	if ride.ScheduledFor == nil {
+		s.startMatching(ride)
+	}
 	
 	return ride, nil
 }
 
+// startMatching offers a ride to drivers in the background, outliving the
+// request that created it. A ride no driver takes is left to expire.
+func (s *RideService) startMatching(ride *domain.Ride) {
+	if s.matcher == nil {
+		return
+	}
+	
+	results, err := s.matcher.StartMatching(context.Background(), ride)
+	if err != nil {
+		log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to start matching")
+		return
+	}
+	go func() {
+		for result := range results {
+			if !result.Success {
+				log.Info().Err(result.Error).Str("ride_id", ride.ID.String()).Msg("Matching found no driver")
+			}
+		}
+	}()
+}
+
+// authorizeBusinessRide checks a ride requested on a business profile at
+// its local pickup time, now or when it is scheduled for
+func (s *RideService) authorizeBusinessRide(ctx context.Context, req *domain.RideRequest, timezone string) (*domain.BusinessRide, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	pickup := time.Now()
+	if req.ScheduledFor != nil {
+		pickup = *req.ScheduledFor
+	}
+	return s.business.AuthorizeRide(ctx, req, pickup.In(loc))
+}
+
+// rideRequestLockWait is how long a request waits for the rider's previous
+// request to finish before giving up
+const rideRequestLockWait = 3 * time.Second
+
+// lockRideRequest takes the rider's ride request lock, waiting briefly for
+// a request already in flight. It fails open if Redis is unavailable.
+func (s *RideService) lockRideRequest(ctx context.Context, riderID uuid.UUID) (func(), error) {
+	deadline := time.Now().Add(rideRequestLockWait)
+	for {
+		locked, err := s.driverPool.SetRideRequestLock(ctx, riderID)
+		if err != nil {
+			log.Warn().Err(err).Str("rider_id", riderID.String()).Msg("Failed to lock ride request, skipping dedupe lock")
+			return func() {}, nil
+		}
+		if locked {
+			return func() {
+				if err := s.driverPool.ReleaseRideRequestLock(context.Background(), riderID); err != nil {
+					log.Warn().Err(err).Str("rider_id", riderID.String()).Msg("Failed to release ride request lock")
+				}
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, domain.ErrRideRequestInProgress
+		}
+		
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// findDuplicateRequest gets a ride the rider requested moments ago for the
+// same trip, or nil
+func (s *RideService) findDuplicateRequest(ctx context.Context, req *domain.RideRequest) (*domain.Ride, error) {
+	now := time.Now().UTC()
+	recent, err := s.rideRepo.GetRecentRequestsByRider(ctx, req.RiderID, now.Add(-domain.RideDedupeWindow))
+	if err != nil {
+		return nil, err
+	}
+	for _, ride := range recent {
+		if ride.IsDuplicateRequest(req, now) {
+			return ride, nil
+		}
+	}
+	return nil, nil
+}
+
+// applyPromo checks a ride's promo code against its campaign, discounts the
+// ride's fare and reserves the discount against the campaign's budget
+func (s *RideService) applyPromo(
+	ctx context.Context,
+	ride *domain.Ride,
+	cityCode, timezone, h3Cell string,
+	distance float64,
+	duration int64,
+) (*domain.PromoRedemption, error) {
+	now := time.Now().UTC()
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	
+	campaign, err := s.promos.Evaluate(ctx, ride.PromoCode, ride.RiderID, domain.PromoTrip{
+		Now:       now,
+		LocalHour: now.In(loc).Hour(),
+		City:      cityCode,
+		Zone:      h3Cell,
+		RideType:  ride.Type,
+		Currency:  ride.Price.Currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	// Reprice with the discount at the surge already quoted. Minimum fares
+	// can absorb part of it, so only what reaches the rider is spent.
+	withDiscount := *ride.Price
+	withDiscount.PromoDiscount = campaign.DiscountFor(ride.Price.Total)
+	discounted := s.pricingEngine.RecalculateCityPrice(cityCode, &withDiscount, ride.Type, distance, duration)
+	discount := ride.Price.Total - discounted.Total
+	if discount <= 0 {
+		return nil, domain.ErrPromoNotEligible
+	}
+	discounted.PromoDiscount = discount
+	discounted.Legs = ride.Price.Legs
+	
+	redemption := &domain.PromoRedemption{
+		ID:         uuid.New(),
+		CampaignID: campaign.ID,
+		Code:       domain.NormalizePromoCode(ride.PromoCode),
+		RideID:     ride.ID,
+		RiderID:    ride.RiderID,
+		Discount:   discount,
+		Currency:   discounted.Currency,
+		CreatedAt:  now,
+	}
+	if err := s.promos.Redeem(ctx, redemption); err != nil {
+		return nil, err
+	}
+	
+	ride.PromoCode = redemption.Code
+	ride.Price = discounted
+	return redemption, nil
+}
+
+// redeemPoints takes the rider's loyalty points off a ride's fare, repriced
+// as a promo discount so minimum fares still hold, and debits the points
+// that reached the fare
+func (s *RideService) redeemPoints(
+	ctx context.Context,
+	ride *domain.Ride,
+	points int64,
+	cityCode string,
+	distance float64,
+	duration int64,
+) (*domain.LoyaltyEntry, error) {
+	if s.loyalty == nil || ride.Price == nil || ride.PaymentMethod == domain.PaymentMethodBusinessInvoice {
+		return nil, domain.ErrLoyaltyNotRedeemable
+	}
+	if err := s.loyalty.CheckRedemption(ctx, ride.RiderID, points); err != nil {
+		return nil, err
+	}
+	value, ok := domain.LoyaltyPointsValue(points, ride.Price.Currency)
+	if !ok || value <= 0 {
+		return nil, domain.ErrLoyaltyNotRedeemable
+	}
+	
+	withDiscount := *ride.Price
+	withDiscount.PromoDiscount += value
+	discounted := s.pricingEngine.RecalculateCityPrice(cityCode, &withDiscount, ride.Type, distance, duration)
+	discount := ride.Price.Total - discounted.Total
+	if discount <= 0 {
+		return nil, domain.ErrLoyaltyNotRedeemable
+	}
+	discounted.PromoDiscount = ride.Price.PromoDiscount + discount
+	discounted.Legs = ride.Price.Legs
+	
+	spent := domain.LoyaltyPointsFor(discount, ride.Price.Currency, points)
+	entry := &domain.LoyaltyEntry{
+		ID:        uuid.New(),
+		RiderID:   ride.RiderID,
+		RideID:    ride.ID,
+		Kind:      domain.LoyaltyEntryRedeem,
+		Points:    -spent,
+		Amount:    discount,
+		Currency:  discounted.Currency,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.loyalty.Redeem(ctx, entry); err != nil {
+		return nil, err
+	}
+	
+	ride.Price = discounted
+	ride.Metadata[domain.LoyaltyPointsMetadataKey] = spent
+	return entry, nil
+}
+
+// PreviewPromo shows what a promo code would take off each estimated fare,
+// without reserving anything from its campaign. Only the ride types the
+// code applies to are returned, repriced as a ride request would be; city
+// is nil outside configured cities.
+func (s *RideService) PreviewPromo(
+	ctx context.Context,
+	code string,
+	riderID uuid.UUID,
+	city *domain.CityConfig,
+	h3Cell string,
+	distance float64,
+	duration int64,
+	estimates map[domain.RideType]*domain.PriceBreakdown,
+) (map[domain.RideType]*domain.PriceBreakdown, error) {
+	if s.promos == nil {
+		return nil, domain.ErrInvalidPromoCode
+	}
+	
+	cityCode := ""
+	timezone := ""
+	if l := locale.FromContext(ctx); l != nil {
+		timezone = l.Timezone
+	}
+	if city != nil {
+		cityCode = city.Code
+		timezone = city.Timezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	
+	// Estimates share one currency, so any of them stands for the trip
+	var currency domain.Currency
+	for _, price := range estimates {
+		currency = price.Currency
+		break
+	}
+	
+	now := time.Now().UTC()
+	campaign, err := s.promos.Evaluate(ctx, code, riderID, domain.PromoTrip{
+		Now:       now,
+		LocalHour: now.In(loc).Hour(),
+		City:      cityCode,
+		Zone:      h3Cell,
+		Currency:  currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	discounted := make(map[domain.RideType]*domain.PriceBreakdown)
+	for rideType, price := range estimates {
+		if !campaign.AppliesToRideType(rideType) {
+			continue
+		}
+		withDiscount := *price
+		withDiscount.PromoDiscount = campaign.DiscountFor(price.Total)
+		repriced := s.pricingEngine.RecalculateCityPrice(cityCode, &withDiscount, rideType, distance, duration)
+		if discount := price.Total - repriced.Total; discount > 0 {
+			repriced.PromoDiscount = discount
+			discounted[rideType] = repriced
+		}
+	}
+	if len(discounted) == 0 {
+		return nil, domain.ErrPromoNotEligible
+	}
+	return discounted, nil
+}
+
 // GetRide retrieves a ride by ID
 func (s *RideService) GetRide(ctx context.Context, rideID uuid.UUID) (*domain.Ride, error) {
 	// Check cache first
@@ -155,6 +829,29 @@ func (s *RideService) GetRide(ctx context.Context, rideID uuid.UUID) (*domain.Ri
 	return nil, domain.ErrRideNotFound
 }
 
+// ExplainFare returns a ride's price with the stored snapshots of how it was
+// computed
+func (s *RideService) ExplainFare(ctx context.Context, rideID uuid.UUID) (*domain.Ride, *domain.FareExplanation, error) {
+	ride, err := s.GetRide(ctx, rideID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.rideRepo == nil {
+		return nil, nil, domain.ErrInternal
+	}
+	
+	snapshots, err := s.rideRepo.ListFareSnapshots(ctx, rideID)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	return ride, &domain.FareExplanation{
+		RideID:    ride.ID,
+		Price:     ride.Price,
+		Snapshots: snapshots,
+	}, nil
+}
+
 // CancelRide cancels a ride
 func (s *RideService) CancelRide(ctx context.Context, rideID, userID uuid.UUID, reason string) error {
 	ride, err := s.GetRide(ctx, rideID)
@@ -167,21 +864,54 @@ func (s *RideService) CancelRide(ctx context.Context, rideID, userID uuid.UUID,
 		return domain.ErrForbidden
 	}
 	
-	// Cancel the ride
-	if err := ride.Cancel(userID, reason); err != nil {
+	// Cancel the ride, unless a concurrent update has since moved it on
+	ride, err = s.updateRide(ctx, ride, func(ride *domain.Ride) error {
+		return ride.Cancel(userID, reason)
+	})
+	if err != nil {
 		return err
 	}
+	s.releaseEndedRide(ctx, ride)
 	
-	// Update database
-	if s.rideRepo != nil {
-		if err := s.rideRepo.Update(ctx, ride); err != nil {
-			return err
-		}
-	}
+	log.Info().
+		Str("ride_id", rideID.String()).
+		Str("cancelled_by", userID.String()).
+		Str("reason", reason).
+		Msg("Ride cancelled")
 	
+	return nil
+}
+
+// releaseEndedRide gives back what a ride held once it is cancelled or
+// expires
+func (s *RideService) releaseEndedRide(ctx context.Context, ride *domain.Ride) {
 	// Invalidate cache
 	if s.driverPool != nil {
-		_ = s.driverPool.InvalidateRideCache(ctx, rideID)
+		_ = s.driverPool.InvalidateRideCache(ctx, ride.ID)
+	}
+	
+	// Stop offering the ride to drivers
+	if s.matcher != nil {
+		_ = s.matcher.CancelMatching(ride.ID)
+	}
+	
+	// Return any promo discount to its campaign's budget
+	if ride.PromoCode != "" && s.promos != nil {
+		if err := s.promos.Release(ctx, ride.ID); err != nil {
+			log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to release promo redemption")
+		}
+	}
+	
+	// Give back any loyalty points redeemed on the fare
+	if _, ok := ride.Metadata[domain.LoyaltyPointsMetadataKey]; ok && s.loyalty != nil {
+		if err := s.loyalty.Release(ctx, ride.ID); err != nil {
+			log.Error().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to release loyalty points")
+		}
+	}
+	
+	// Free a driver's claim on a scheduled ride, without penalty
+	if ride.ScheduledFor != nil && s.rideRepo != nil {
+		s.releaseScheduledClaim(ctx, ride.ID)
 	}
 	
 	// If driver was assigned, free them
@@ -189,13 +919,81 @@ func (s *RideService) CancelRide(ctx context.Context, rideID, userID uuid.UUID,
 		_ = s.driverPool.SetDriverStatus(ctx, *ride.DriverID, domain.DriverStatusOnline)
 	}
 	
-	log.Info().
-		Str("ride_id", rideID.String()).
-		Str("cancelled_by", userID.String()).
-		Str("reason", reason).
-		Msg("Ride cancelled")
+	// Close the rider and driver's chat
+	if ride.DriverID != nil && s.chats != nil {
+		s.chats.CloseChat(ctx, ride)
+	}
+}
+
+// rideUpdateAttempts is how many times a ride change is tried against
+// concurrent updates before giving up with ErrRideConflict
+const rideUpdateAttempts = 3
+
+// updateRide applies change to the ride and writes it with a version check.
+// When a concurrent update wins, the ride is reloaded and change applied
+// again, so a change the new state no longer allows (a rider cancelling a
+// ride the driver just completed) fails with its own error rather than
+// overwriting the other update.
+func (s *RideService) updateRide(ctx context.Context, ride *domain.Ride, change func(*domain.Ride) error) (*domain.Ride, error) {
+	if s.rideRepo == nil {
+		if err := change(ride); err != nil {
+			return nil, err
+		}
+		return ride, nil
+	}
 	
-	return nil
+	return retryRideUpdate(ctx, s.rideRepo, ride, change, func(rideID uuid.UUID) {
+		// The cached copy may be the stale one, so reload from the database
+		if s.driverPool != nil {
+			_ = s.driverPool.InvalidateRideCache(ctx, rideID)
+		}
+	})
+}
+
+// versionedRides writes rides with a version check and reloads them when
+// the check fails
+type versionedRides interface {
+	Update(ctx context.Context, ride *domain.Ride) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Ride, error)
+}
+
+// retryRideUpdate is updateRide's retry loop, calling stale before each
+// reload
+func retryRideUpdate(ctx context.Context, rides versionedRides, ride *domain.Ride, change func(*domain.Ride) error, stale func(rideID uuid.UUID)) (*domain.Ride, error) {
+	for attempt := 1; ; attempt++ {
+		if err := change(ride); err != nil {
+			return nil, err
+		}
+		
+		err := rides.Update(ctx, ride)
+		if err == nil {
+			return ride, nil
+		}
+		if !errors.Is(err, domain.ErrRideConflict) || attempt == rideUpdateAttempts {
+			return nil, err
+		}
+		
+		stale(ride.ID)
+		ride, err = rides.GetByID(ctx, ride.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// releaseScheduledClaim closes any open claim on a cancelled scheduled ride
+func (s *RideService) releaseScheduledClaim(ctx context.Context, rideID uuid.UUID) {
+	claim, err := s.rideRepo.GetActiveScheduledClaim(ctx, rideID)
+	if err == nil && claim != nil {
+		now := time.Now().UTC()
+		claim.Status = domain.ScheduledClaimReleased
+		claim.ReleaseReason = "ride cancelled"
+		claim.ClosedAt = &now
+		err = s.rideRepo.CloseScheduledClaim(ctx, claim)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("ride_id", rideID.String()).Msg("Failed to release scheduled ride claim")
+	}
 }
 
 // UpdateRideStatus updates the status of a ride
@@ -205,15 +1003,25 @@ func (s *RideService) UpdateRideStatus(ctx context.Context, rideID uuid.UUID, st
 		return err
 	}
 	
-	if err := ride.UpdateStatus(status); err != nil {
-		return err
+	var emissions *domain.RideEmissions
+	if status == domain.RideStatusCompleted && s.emissions != nil {
+		emissions = s.emissions.Estimate(ctx, ride)
 	}
 	
-	// Update database
-	if s.rideRepo != nil {
-		if err := s.rideRepo.Update(ctx, ride); err != nil {
+	ride, err = s.updateRide(ctx, ride, func(ride *domain.Ride) error {
+		if err := ride.UpdateStatus(status); err != nil {
 			return err
 		}
+		if status == domain.RideStatusInProgress {
+			s.chargeWaitTime(ride)
+		}
+		if emissions != nil {
+			ride.SetEmissions(emissions)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 	
 	// Update cache
@@ -222,12 +1030,36 @@ func (s *RideService) UpdateRideStatus(ctx context.Context, rideID uuid.UUID, st
 	}
 	
 	// Handle status-specific actions
+	if status == domain.RideStatusArrived && ride.DriverID != nil {
+		s.recordArrivalLocation(ctx, ride)
+	}
 	if status == domain.RideStatusCompleted && ride.DriverID != nil {
 		// Free up driver
 		if s.driverPool != nil {
 			_ = s.driverPool.SetDriverStatus(ctx, *ride.DriverID, domain.DriverStatusOnline)
 		}
 	}
+	if status == domain.RideStatusCompleted && s.insurer != nil {
+		if _, err := s.insurer.AttachPolicy(ctx, ride); err != nil {
+			// Completion must not fail on insurance, but the ride cannot be
+			// claimed on without a policy
+			log.Error().Err(err).Str("ride_id", rideID.String()).Msg("Failed to attach ride insurance")
+		}
+	}
+	if status == domain.RideStatusCompleted && s.receipts != nil {
+		// A missed receipt is issued when the rider first asks for it
+		if _, err := s.receipts.IssueReceipt(ctx, ride); err != nil {
+			log.Error().Err(err).Str("ride_id", rideID.String()).Msg("Failed to issue ride receipt")
+		}
+	}
+	if status == domain.RideStatusCompleted && s.chats != nil {
+		s.chats.CloseChat(ctx, ride)
+	}
+	if status == domain.RideStatusCompleted && s.loyalty != nil {
+		if _, err := s.loyalty.Accrue(ctx, ride); err != nil {
+			log.Error().Err(err).Str("ride_id", rideID.String()).Msg("Failed to accrue loyalty points")
+		}
+	}
 	
 	log.Info().
 		Str("ride_id", rideID.String()).
@@ -237,30 +1069,75 @@ func (s *RideService) UpdateRideStatus(ctx context.Context, rideID uuid.UUID, st
 	return nil
 }
 
-// RateRide adds a rating to a completed ride
-func (s *RideService) RateRide(ctx context.Context, rideID uuid.UUID, rating float32, isRider bool) error {
-	ride, err := s.GetRide(ctx, rideID)
-	if err != nil {
-		return err
+// chargeWaitTime adds the charge for keeping the driver waiting at pickup
+// to the fare as the trip starts
+func (s *RideService) chargeWaitTime(ride *domain.Ride) {
+	if ride.Price == nil || s.pricingEngine == nil {
+		return
 	}
 	
-	if ride.Status != domain.RideStatusCompleted {
-		return domain.ErrRideNotActive
+	cityCode := ""
+	if s.cities != nil {
+		if city, ok := s.cities.FindByLocation(ride.PickupLocation.Latitude, ride.PickupLocation.Longitude); ok {
+			cityCode = city.Code
+		}
 	}
 	
-	if isRider {
-		ride.DriverRating = &rating
-	} else {
-		ride.RiderRating = &rating
+	charged := s.pricingEngine.ApplyWaitTime(cityCode, ride.Price, ride.Type, ride.PickupWait())
+	if charged.WaitTimeFee == ride.Price.WaitTimeFee {
+		return
+	}
+	ride.Price = charged
+	ride.RecordEvent(domain.NewRideEvent(ride.ID, domain.RideEventFareAdjusted).
+		WithData("reason", "wait_time").
+		WithData("wait_minutes", charged.WaitMinutes).
+		WithData("wait_time_fee", charged.WaitTimeFee))
+}
+
+// recordArrivalLocation stores the driver's GPS position on arrival, which
+// is where the pickup really happens, for learning pickup spots
+func (s *RideService) recordArrivalLocation(ctx context.Context, ride *domain.Ride) {
+	if s.rideRepo == nil || s.driverPool == nil {
+		return
 	}
 	
-	ride.UpdatedAt = time.Now().UTC()
+	loc, err := s.driverPool.GetDriverLocation(ctx, *ride.DriverID)
+	if err != nil || loc == nil || time.Since(loc.UpdatedAt) > domain.PickupArrivalMaxAge {
+		return
+	}
 	
-	// Update database
-	if s.rideRepo != nil {
-		if err := s.rideRepo.Update(ctx, ride); err != nil {
-			return err
+	point := domain.TrailPoint{Latitude: loc.Latitude, Longitude: loc.Longitude, RecordedAt: loc.UpdatedAt}
+	if err := s.rideRepo.RecordArrivalLocation(ctx, ride.ID, point); err != nil {
+		log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to record arrival location")
+	}
+}
+
+// RateRide adds a rating to a completed ride
+func (s *RideService) RateRide(ctx context.Context, rideID uuid.UUID, rating float32, isRider bool) error {
+	ride, err := s.GetRide(ctx, rideID)
+	if err != nil {
+		return err
+	}
+	
+	_, err = s.updateRide(ctx, ride, func(ride *domain.Ride) error {
+		if ride.Status != domain.RideStatusCompleted {
+			return domain.ErrRideNotActive
+		}
+		
+		if isRider {
+			ride.DriverRating = &rating
+		} else {
+			ride.RiderRating = &rating
 		}
+		
+		ride.UpdatedAt = time.Now().UTC()
+		ride.RecordEvent(domain.NewRideEvent(rideID, domain.RideEventRated).
+			WithData("rating", rating).
+			WithData("by_rider", isRider))
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 	
 	// Invalidate cache
@@ -283,32 +1160,170 @@ func (s *RideService) GetActiveRide(ctx context.Context, userID uuid.UUID, isRid
 	return s.rideRepo.GetActiveByDriver(ctx, userID)
 }
 
-// GetRideHistory gets ride history for a user
-func (s *RideService) GetRideHistory(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Ride, int64, error) {
+// GetRideHistory gets a page of the rides a user took, or drove when
+// asDriver is set, matching the filter
+func (s *RideService) GetRideHistory(ctx context.Context, userID uuid.UUID, asDriver bool, filter *domain.RideHistoryFilter) (*domain.RideHistoryPage, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
 	if s.rideRepo == nil {
-		return nil, 0, nil
+		return domain.NewRideHistoryPage(nil, filter.Limit), nil
 	}
 	
-	return s.rideRepo.GetRiderHistory(ctx, userID, limit, offset)
+	rides, err := s.rideRepo.ListHistory(ctx, userID, asDriver, filter)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewRideHistoryPage(rides, filter.Limit), nil
+}
+
+// ExportRideHistory passes every ride matching the filter to write, a batch
+// at a time and newest first, so an export streams however many rides it
+// covers
+func (s *RideService) ExportRideHistory(
+	ctx context.Context,
+	userID uuid.UUID,
+	asDriver bool,
+	filter *domain.RideHistoryFilter,
+	write func([]*domain.Ride) error,
+) error {
+	if err := filter.ExportRange(time.Now().UTC()); err != nil {
+		return err
+	}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	filter.Limit = domain.RideHistoryExportBatch
+	if s.rideRepo == nil {
+		return nil
+	}
+	
+	for {
+		rides, err := s.rideRepo.ListHistory(ctx, userID, asDriver, filter)
+		if err != nil {
+			return err
+		}
+		page := domain.NewRideHistoryPage(rides, filter.Limit)
+		if err := write(page.Rides); err != nil {
+			return err
+		}
+		if !page.Pagination.HasMore {
+			return nil
+		}
+		last := page.Rides[len(page.Rides)-1]
+		filter.Cursor = &domain.RideHistoryCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// GetRideEvents gets the structured event timeline for a ride
+func (s *RideService) GetRideEvents(ctx context.Context, rideID uuid.UUID) ([]*domain.RideEvent, error) {
+	if s.rideRepo == nil {
+		return []*domain.RideEvent{}, nil
+	}
+	
+	return s.rideRepo.GetEvents(ctx, rideID)
+}
+
+// RecordEvent appends an out-of-band event to a ride's timeline. Failures are
+// logged rather than returned since the timeline is informational.
+func (s *RideService) RecordEvent(ctx context.Context, event *domain.RideEvent) {
+	if s.rideRepo == nil {
+		return
+	}
+	
+	if err := s.rideRepo.AppendEvents(ctx, event); err != nil {
+		log.Error().Err(err).
+			Str("ride_id", event.RideID.String()).
+			Str("event_type", string(event.Type)).
+			Msg("Failed to record ride event")
+	}
 }
 
 // DriverService handles driver-related business logic
 type DriverService struct {
 	driverRepo *repository.DriverRepository
 	driverPool *redis.DriverPool
+	checks     *BackgroundCheckService
+	identity   *IdentityCheckService
+	documents  *DriverDocumentService
+	events     RideEventRecorder
+	rideRepo   *repository.RideRepository
+	flusher    *LocationFlushService
+	quality    *DriverQualityService
+	prefs      *DriverPreferenceService
+	training   *TrainingService
+	matcher    RideMatcher
 }
 
-// NewDriverService creates a new driver service
+// RideEventRecorder persists ride timeline events
+type RideEventRecorder interface {
+	AppendEvents(ctx context.Context, events ...*domain.RideEvent) error
+}
+
+// NewDriverService creates a new driver service. checks and identity may be
+// nil, in which case going online skips the background check or the
+// shift-start selfie check respectively.
 func NewDriverService(
 	driverRepo *repository.DriverRepository,
 	driverPool *redis.DriverPool,
+	checks *BackgroundCheckService,
+	identity *IdentityCheckService,
 ) *DriverService {
 	return &DriverService{
 		driverRepo: driverRepo,
 		driverPool: driverPool,
+		checks:     checks,
+		identity:   identity,
 	}
 }
 
+// SetEventRecorder enables recording drivers leaving the rider's chosen
+// route on the ride timeline
+func (s *DriverService) SetEventRecorder(recorder RideEventRecorder) {
+	s.events = recorder
+}
+
+// SetDocumentService keeps drivers whose licences or permits have lapsed
+// past their grace period from going online
+func (s *DriverService) SetDocumentService(documents *DriverDocumentService) {
+	s.documents = documents
+}
+
+// SetRideRepository makes accepting a ride assign the driver and the ride
+// together. Without it only the driver's side is written.
+func (s *DriverService) SetRideRepository(rideRepo *repository.RideRepository) {
+	s.rideRepo = rideRepo
+}
+
+// SetLocationFlusher batches drivers' position writes to the database
+// instead of writing on every ping
+func (s *DriverService) SetLocationFlusher(flusher *LocationFlushService) {
+	s.flusher = flusher
+}
+
+// SetQualityProgram gives drivers accepting a ride the commission discount
+// their quality tier earns
+func (s *DriverService) SetQualityProgram(quality *DriverQualityService) {
+	s.quality = quality
+}
+
+// SetDriverPreferences keeps drivers a rider blocked from accepting their
+// rides
+func (s *DriverService) SetDriverPreferences(prefs *DriverPreferenceService) {
+	s.prefs = prefs
+}
+
+// SetTraining keeps drivers from accepting pool and intercity rides before
+// completing their training
+func (s *DriverService) SetTraining(training *TrainingService) {
+	s.training = training
+}
+
+// SetMatcher settles drivers' answers to the ride offers matching sent them
+func (s *DriverService) SetMatcher(matcher RideMatcher) {
+	s.matcher = matcher
+}
+
 // GetNearbyDrivers finds drivers near a location
 func (s *DriverService) GetNearbyDrivers(ctx context.Context, lat, lng, radius float64, rideType domain.RideType) ([]*domain.NearbyDriver, error) {
 	// Use Redis for real-time location data
@@ -326,23 +1341,81 @@ func (s *DriverService) GetNearbyDrivers(ctx context.Context, lat, lng, radius f
 
 // UpdateLocation updates a driver's location
 func (s *DriverService) UpdateLocation(ctx context.Context, driverID uuid.UUID, loc *domain.DriverLocation) error {
+	queued := false
+	
 	// Update in Redis for real-time access
 	if s.driverPool != nil {
+		if status, err := s.driverPool.GetDriverStatus(ctx, driverID); err == nil && status.IsRestricted() {
+			return domain.ErrDriverRestricted
+		}
+		
 		if err := s.driverPool.UpdateLocation(ctx, loc); err != nil {
 			log.Error().Err(err).Msg("Failed to update driver location in Redis")
+		} else if s.flusher != nil {
+			if err := s.flusher.Track(ctx, loc); err == nil {
+				queued = true
+			} else {
+				log.Error().Err(err).Msg("Failed to queue driver location write")
+			}
 		}
 	}
 	
-	// Persist to database (less frequently in production)
 	if s.driverRepo != nil {
-		if err := s.driverRepo.UpdateLocation(ctx, driverID, loc); err != nil {
-			log.Error().Err(err).Msg("Failed to persist driver location")
+		// Write straight through when the position could not be queued
+		if !queued {
+			if err := s.driverRepo.UpdateLocation(ctx, driverID, loc); err != nil {
+				log.Error().Err(err).Msg("Failed to persist driver location")
+			}
 		}
+		
+		// Keep the GPS trail of any in-progress ride for fare disputes
+		if err := s.driverRepo.RecordRideLocation(ctx, driverID, loc); err != nil {
+			log.Error().Err(err).Msg("Failed to record ride location")
+		}
+		
+		s.checkRouteDeviation(ctx, driverID, loc)
 	}
 	
 	return nil
 }
 
+// checkRouteDeviation records a deviation when the driver of an in-progress
+// ride is off the route the rider chose, at most once per cooldown
+func (s *DriverService) checkRouteDeviation(ctx context.Context, driverID uuid.UUID, loc *domain.DriverLocation) {
+	if s.events == nil || s.driverPool == nil {
+		return
+	}
+	
+	rideID, route, err := s.driverRepo.GetInProgressRoute(ctx, driverID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get in-progress route")
+		return
+	}
+	if rideID == uuid.Nil || route == nil || route.Polyline == "" {
+		return
+	}
+	
+	offRoute := geo.DistanceToPolyline(loc.Location.Latitude, loc.Location.Longitude, geo.PolylineDecode(route.Polyline))
+	if offRoute <= domain.RouteDeviationThresholdM {
+		return
+	}
+	if first, err := s.driverPool.MarkRouteDeviation(ctx, rideID); err != nil || !first {
+		return
+	}
+	
+	event := domain.NewRideEvent(rideID, domain.RideEventRouteDeviation).
+		WithActor(driverID).
+		WithData("distance_off_route_m", int64(offRoute)).
+		WithData("latitude", loc.Location.Latitude).
+		WithData("longitude", loc.Location.Longitude)
+	if route.Preference != "" {
+		event = event.WithData("route_preference", string(route.Preference))
+	}
+	if err := s.events.AppendEvents(ctx, event); err != nil {
+		log.Error().Err(err).Str("ride_id", rideID.String()).Msg("Failed to record route deviation")
+	}
+}
+
 // AcceptRide handles a driver accepting a ride
 func (s *DriverService) AcceptRide(ctx context.Context, rideID, driverID uuid.UUID) error {
 	// Check if driver is available
@@ -351,18 +1424,39 @@ func (s *DriverService) AcceptRide(ctx context.Context, rideID, driverID uuid.UU
 		if err != nil {
 			return err
 		}
+		if status.IsRestricted() {
+			return domain.ErrDriverRestricted
+		}
 		if status != domain.DriverStatusOnline {
 			return domain.ErrDriverNotAvailable
 		}
-		
-		// Check if driver is locked
-		if s.driverPool.IsDriverLocked(ctx, driverID) {
-			return domain.ErrDriverBusy
+	}
+	
+	// While a ride is being matched only the drivers it was offered to can
+	// take it, under the lock their offer holds. Rides matching elsewhere,
+	// or not at all, go straight to assignment.
+	offered := false
+	if s.matcher != nil {
+		_, err := s.matcher.AcceptRide(ctx, rideID, driverID)
+		switch {
+		case err == nil:
+			offered = true
+		case !errors.Is(err, domain.ErrRideNotFound):
+			return err
 		}
 	}
 	
-	// Assign driver to ride in database
-	if s.driverRepo != nil {
+	// Check if driver is locked
+	if !offered && s.driverPool != nil && s.driverPool.IsDriverLocked(ctx, driverID) {
+		return domain.ErrDriverBusy
+	}
+	
+	// Assign driver and ride to each other in database
+	if s.rideRepo != nil && s.driverRepo != nil {
+		if err := s.assignDriverToRide(ctx, rideID, driverID); err != nil {
+			return err
+		}
+	} else if s.driverRepo != nil {
 		if err := s.driverRepo.AssignRide(ctx, driverID, rideID); err != nil {
 			return err
 		}
@@ -381,8 +1475,65 @@ func (s *DriverService) AcceptRide(ctx context.Context, rideID, driverID uuid.UU
 	return nil
 }
 
+// assignDriverToRide assigns the driver's vehicle to the ride and the ride
+// to the driver in one transaction
+func (s *DriverService) assignDriverToRide(ctx context.Context, rideID, driverID uuid.UUID) error {
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return err
+	}
+	if driver.Vehicle == nil {
+		return domain.ErrDriverNotAvailable
+	}
+	
+	ride, err := s.rideRepo.GetByID(ctx, rideID)
+	if err != nil {
+		return err
+	}
+	if ride.DriverID != nil {
+		return domain.ErrRideAlreadyAssigned
+	}
+	if s.prefs != nil {
+		blocked, err := s.prefs.IsBlocked(ctx, ride.RiderID, driverID)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return domain.ErrBlockedByRider
+		}
+	}
+	if s.training != nil {
+		if err := s.training.CheckRide(ctx, driverID, ride); err != nil {
+			return err
+		}
+	}
+	if err := ride.AssignDriver(driverID, driver.Vehicle.ID); err != nil {
+		return err
+	}
+	discountCommission(ride, s.quality)
+	if err := s.rideRepo.AssignDriverToRide(ctx, ride); err != nil {
+		// The ride changed since it was read; another driver got there first
+		if errors.Is(err, domain.ErrRideConflict) {
+			return domain.ErrRideAlreadyAssigned
+		}
+		return err
+	}
+	
+	if s.driverPool != nil {
+		_ = s.driverPool.CacheRide(ctx, ride)
+	}
+	return nil
+}
+
 // DeclineRide handles a driver declining a ride
 func (s *DriverService) DeclineRide(ctx context.Context, rideID, driverID uuid.UUID) error {
+	// Stop offering the ride to the driver
+	if s.matcher != nil {
+		if err := s.matcher.DeclineRide(rideID, driverID); err != nil && !errors.Is(err, domain.ErrRideNotFound) {
+			return err
+		}
+	}
+	
 	// Unlock driver if locked
 	if s.driverPool != nil {
 		_ = s.driverPool.UnlockDriver(ctx, driverID)
@@ -398,6 +1549,53 @@ func (s *DriverService) DeclineRide(ctx context.Context, rideID, driverID uuid.U
 
 // SetDriverStatus sets a driver's operational status
 func (s *DriverService) SetDriverStatus(ctx context.Context, driverID uuid.UUID, status domain.DriverStatus) error {
+	// Restricted states are managed by DriverStandingService only
+	if status.IsRestricted() {
+		return domain.ErrInvalidRequest
+	}
+	if s.driverRepo != nil {
+		driver, err := s.driverRepo.GetByID(ctx, driverID)
+		if err != nil {
+			return err
+		}
+		if driver.Status.IsRestricted() {
+			return domain.ErrDriverRestricted
+		}
+	}
+	
+	// Activation requires a cleared background check
+	if status == domain.DriverStatusOnline && s.checks != nil {
+		cleared, err := s.checks.IsCleared(ctx, driverID)
+		if err != nil {
+			return err
+		}
+		if !cleared {
+			return domain.ErrBackgroundCheckRequired
+		}
+	}
+	
+	// Licences and permits must be current, or within their grace period
+	if status == domain.DriverStatusOnline && s.documents != nil {
+		compliant, err := s.documents.IsCompliant(ctx, driverID)
+		if err != nil {
+			return err
+		}
+		if !compliant {
+			return domain.ErrDriverDocumentExpired
+		}
+	}
+	
+	// Each shift starts with a selfie check
+	if status == domain.DriverStatusOnline && s.identity != nil {
+		verified, err := s.identity.IsVerified(ctx, driverID)
+		if err != nil {
+			return err
+		}
+		if !verified {
+			return domain.ErrIdentityCheckRequired
+		}
+	}
+	
 	// Update in Redis
 	if s.driverPool != nil {
 		if err := s.driverPool.SetDriverStatus(ctx, driverID, status); err != nil {
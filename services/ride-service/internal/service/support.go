@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// Number of recent rides included in a rider's support profile
+const supportRecentRides = 5
+
+// SupportService assembles case context for support tooling
+type SupportService struct {
+	rideService *RideService
+	rideRepo    *repository.RideRepository
+	driverRepo  *repository.DriverRepository
+}
+
+// NewSupportService creates a new support service
+func NewSupportService(
+	rideService *RideService,
+	rideRepo *repository.RideRepository,
+	driverRepo *repository.DriverRepository,
+) *SupportService {
+	return &SupportService{
+		rideService: rideService,
+		rideRepo:    rideRepo,
+		driverRepo:  driverRepo,
+	}
+}
+
+// GetRideCaseContext gathers timeline, fare, profiles and trail for a ride,
+// redacted according to the requesting agent's role
+func (s *SupportService) GetRideCaseContext(ctx context.Context, rideID uuid.UUID, role string) (*domain.SupportCaseContext, error) {
+	ride, err := s.rideService.GetRide(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+
+	caseCtx := &domain.SupportCaseContext{
+		Ride:          ride,
+		FareBreakdown: ride.Price,
+		Rider:         &domain.SupportRiderProfile{ID: ride.RiderID, RecentRideIDs: []uuid.UUID{}},
+		LocationTrail: buildLocationTrail(ride),
+		ChatTranscript: &domain.TranscriptPointer{
+			Service:        domain.MaskedChannelService,
+			ConversationID: domain.RideConversationID(ride.ID),
+		},
+	}
+
+	caseCtx.Timeline, err = s.rideService.GetRideEvents(ctx, rideID)
+	if err != nil {
+		log.Warn().Err(err).Str("ride_id", rideID.String()).Msg("Failed to load ride timeline for support case")
+		caseCtx.Timeline = []*domain.RideEvent{}
+	}
+
+	if s.rideRepo != nil {
+		history, total, err := s.rideRepo.GetRiderHistory(ctx, ride.RiderID, supportRecentRides, 0)
+		if err == nil {
+			caseCtx.Rider.TotalRides = total
+			for _, past := range history {
+				caseCtx.Rider.RecentRideIDs = append(caseCtx.Rider.RecentRideIDs, past.ID)
+			}
+		}
+	}
+
+	if ride.DriverID != nil && s.driverRepo != nil {
+		driver, err := s.driverRepo.GetByID(ctx, *ride.DriverID)
+		if err != nil {
+			log.Warn().Err(err).Str("driver_id", ride.DriverID.String()).Msg("Failed to load driver for support case")
+		} else {
+			caseCtx.Driver = driver
+		}
+	}
+
+	caseCtx.Redact(role)
+
+	return caseCtx, nil
+}
+
+// buildLocationTrail summarizes planned vs. actual movement for a ride
+func buildLocationTrail(ride *domain.Ride) *domain.LocationTrailSummary {
+	trail := &domain.LocationTrailSummary{
+		Pickup:    ride.PickupLocation,
+		Dropoff:   ride.DropoffLocation,
+		LastKnown: ride.CurrentLocation,
+		StraightLineM: geo.HaversineDistance(
+			ride.PickupLocation.Latitude, ride.PickupLocation.Longitude,
+			ride.DropoffLocation.Latitude, ride.DropoffLocation.Longitude,
+		),
+	}
+
+	if ride.Route != nil {
+		trail.PlannedDistanceM = ride.Route.DistanceMeters
+		trail.PlannedDurationS = ride.Route.DurationSeconds
+	}
+
+	if ride.StartedAt != nil && ride.CompletedAt != nil {
+		trail.ActualDurationS = int64(ride.CompletedAt.Sub(*ride.StartedAt).Seconds())
+	}
+
+	return trail
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// trainingProducts are the gated products, in the order drivers see them
+var trainingProducts = []domain.TrainingProduct{
+	domain.TrainingProductIntercity,
+	domain.TrainingProductPool,
+	domain.TrainingProductRegulatedDelivery,
+}
+
+// TrainingService keeps the registry of training modules drivers have
+// completed and gates intercity, pool and regulated delivery work on the
+// modules each product requires
+type TrainingService struct {
+	repo    *repository.TrainingRepository
+	prereqs domain.TrainingPrerequisites
+	cities  *cityconfig.Registry
+}
+
+// NewTrainingService creates a new training service. cities may be nil, in
+// which case no ride counts as intercity.
+func NewTrainingService(repo *repository.TrainingRepository, prereqs domain.TrainingPrerequisites, cities *cityconfig.Registry) *TrainingService {
+	return &TrainingService{
+		repo:    repo,
+		prereqs: prereqs,
+		cities:  cities,
+	}
+}
+
+// HandleWebhook records a module completion reported by the learning
+// content provider. Attempts the driver did not pass, and completions
+// already reported, record nothing and return nil.
+func (s *TrainingService) HandleWebhook(ctx context.Context, provider string, payload *domain.TrainingWebhook) (*domain.TrainingCompletion, error) {
+	completion, err := payload.Completion(provider, time.Now().UTC())
+	if err != nil || completion == nil {
+		return nil, err
+	}
+
+	recorded, err := s.repo.RecordCompletion(ctx, completion)
+	if err != nil || !recorded {
+		return nil, err
+	}
+
+	log.Info().
+		Str("driver_id", completion.DriverID.String()).
+		Str("module_id", completion.ModuleID).
+		Str("provider", provider).
+		Msg("Driver training module completed")
+
+	return completion, nil
+}
+
+// GetStatus gets a driver's progress through every product's training
+func (s *TrainingService) GetStatus(ctx context.Context, driverID uuid.UUID) ([]*domain.TrainingStatus, error) {
+	completed, err := s.completedModules(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*domain.TrainingStatus, 0, len(trainingProducts))
+	for _, product := range trainingProducts {
+		statuses = append(statuses, s.prereqs.Status(product, completed))
+	}
+	return statuses, nil
+}
+
+// GetProductStatus gets a driver's progress through one product's training
+func (s *TrainingService) GetProductStatus(ctx context.Context, driverID uuid.UUID, product domain.TrainingProduct) (*domain.TrainingStatus, error) {
+	if !product.IsValid() {
+		return nil, domain.ErrInvalidRequest
+	}
+	completed, err := s.completedModules(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	return s.prereqs.Status(product, completed), nil
+}
+
+// ListCompletions gets the modules a driver has completed
+func (s *TrainingService) ListCompletions(ctx context.Context, driverID uuid.UUID) ([]*domain.TrainingCompletion, error) {
+	return s.repo.ListCompletions(ctx, driverID)
+}
+
+// CheckRide returns ErrTrainingRequired when the driver has not completed
+// the training the ride's products require
+func (s *TrainingService) CheckRide(ctx context.Context, driverID uuid.UUID, ride *domain.Ride) error {
+	cleared, err := s.ClearedForRide(ctx, ride, []uuid.UUID{driverID})
+	if err != nil {
+		return err
+	}
+	if !cleared[driverID] {
+		return domain.ErrTrainingRequired
+	}
+	return nil
+}
+
+// ClearedForRide reports which of the drivers have completed the training
+// the ride's products require
+func (s *TrainingService) ClearedForRide(ctx context.Context, ride *domain.Ride, driverIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	products := domain.RideTrainingProducts(ride.Type, s.cityCode(ride.PickupLocation), s.cityCode(ride.DropoffLocation))
+
+	var modules []string
+	for _, product := range products {
+		modules = append(modules, s.prereqs[product]...)
+	}
+	completed, err := s.repo.CompletedModules(ctx, driverIDs, modules)
+	if err != nil {
+		return nil, err
+	}
+
+	cleared := make(map[uuid.UUID]bool, len(driverIDs))
+	for _, driverID := range driverIDs {
+		cleared[driverID] = s.prereqs.Cleared(products, completed[driverID])
+	}
+	return cleared, nil
+}
+
+func (s *TrainingService) completedModules(ctx context.Context, driverID uuid.UUID) (map[string]bool, error) {
+	completed, err := s.repo.CompletedModules(ctx, []uuid.UUID{driverID}, s.prereqs.Modules())
+	if err != nil {
+		return nil, err
+	}
+	return completed[driverID], nil
+}
+
+// cityCode returns the code of the city containing loc, or "" when it is
+// in none
+func (s *TrainingService) cityCode(loc domain.Location) string {
+	if s.cities == nil {
+		return ""
+	}
+	city, ok := s.cities.FindByLocation(loc.Latitude, loc.Longitude)
+	if !ok {
+		return ""
+	}
+	return city.Code
+}
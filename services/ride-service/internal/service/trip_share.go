@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/geo"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// TripShareService issues signed links to follow a ride and serves the
+// read-only view behind them. Links carry the ride and their expiry, so
+// nothing is stored and any replica can check them.
+type TripShareService struct {
+	rideService *RideService
+	driverRepo  *repository.DriverRepository
+	driverPool  *redis.DriverPool
+	secret      []byte
+	ttl         time.Duration
+	now         func() time.Time
+}
+
+// NewTripShareService creates a new trip share service. driverRepo and
+// driverPool may be nil, in which case shared trips show no driver, car
+// or position.
+func NewTripShareService(
+	rideService *RideService,
+	driverRepo *repository.DriverRepository,
+	driverPool *redis.DriverPool,
+	secret string,
+	ttl time.Duration,
+) *TripShareService {
+	if ttl <= 0 {
+		ttl = domain.DefaultTripShareTTL
+	}
+	return &TripShareService{
+		rideService: rideService,
+		driverRepo:  driverRepo,
+		driverPool:  driverPool,
+		secret:      []byte(secret),
+		ttl:         ttl,
+		now:         time.Now,
+	}
+}
+
+// Share issues a link to follow a ride. Only the ride's rider may share it,
+// and only while it is active. Links are not stored, so one cannot be
+// revoked before it expires: rotating the share secret invalidates every
+// link, and once the ride ends a link shows only its status.
+func (s *TripShareService) Share(ctx context.Context, rideID, riderID uuid.UUID) (*domain.TripShare, error) {
+	ride, err := s.rideService.GetRide(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	if ride.RiderID != riderID {
+		return nil, domain.ErrForbidden
+	}
+	if !ride.IsActive() {
+		return nil, domain.ErrRideAlreadyEnded
+	}
+
+	expiresAt := s.now().Add(s.ttl).UTC().Truncate(time.Second)
+	token := s.sign(rideID, expiresAt)
+
+	s.rideService.RecordEvent(ctx, domain.NewRideEvent(ride.ID, domain.RideEventTripShared).
+		WithActor(riderID).
+		WithData("expires_at", expiresAt))
+
+	return &domain.TripShare{
+		Token:     token,
+		Path:      "/track/" + token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Track returns what a share link shows of its ride
+func (s *TripShareService) Track(ctx context.Context, token string) (*domain.SharedTrip, error) {
+	rideID, expiresAt, err := s.verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	ride, err := s.rideService.GetRide(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+
+	trip := &domain.SharedTrip{Status: ride.Status, ExpiresAt: expiresAt}
+	if !ride.IsActive() || ride.DriverID == nil {
+		return trip, nil
+	}
+
+	var vehicleType string
+	if s.driverRepo != nil {
+		driver, err := s.driverRepo.GetByID(ctx, *ride.DriverID)
+		if err != nil {
+			log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to load driver for shared trip")
+		} else {
+			trip.DriverFirstName = driver.FirstName
+			if v := driver.Vehicle; v != nil {
+				trip.Vehicle = &domain.SharedVehicle{
+					Make:         v.Make,
+					Model:        v.Model,
+					Color:        v.Color,
+					LicensePlate: v.LicensePlate,
+				}
+				vehicleType = strings.ToLower(string(v.Type))
+			}
+		}
+	}
+
+	if s.driverPool != nil {
+		loc, err := s.driverPool.GetDriverLocation(ctx, *ride.DriverID)
+		if err != nil {
+			log.Warn().Err(err).Str("ride_id", ride.ID.String()).Msg("Failed to load driver location for shared trip")
+		}
+		if loc != nil {
+			updatedAt := loc.UpdatedAt
+			trip.DriverLocation = &domain.Location{Latitude: loc.Latitude, Longitude: loc.Longitude}
+			trip.Heading = loc.Heading
+			trip.LocationAt = &updatedAt
+
+			// Time to the pickup until the rider is aboard, then to the dropoff
+			target, to := ride.DropoffLocation, domain.ShareETAToDropoff
+			if ride.Status != domain.RideStatusInProgress {
+				target, to = ride.PickupLocation, domain.ShareETAToPickup
+			}
+			distance := geo.HaversineDistance(loc.Latitude, loc.Longitude, target.Latitude, target.Longitude)
+			eta := geo.EstimateETAWithTraffic(geo.EstimateETA(distance, vehicleType), s.now().Hour())
+			trip.ETASeconds = &eta
+			trip.ETATo = to
+		}
+	}
+
+	return trip, nil
+}
+
+// sign returns a token naming the ride and when it stops working:
+// base64url(ride ID, expiry) "." base64url(HMAC-SHA256 of the first part)
+func (s *TripShareService) sign(rideID uuid.UUID, expiresAt time.Time) string {
+	payload := make([]byte, 24)
+	copy(payload, rideID[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(expiresAt.Unix()))
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded))
+}
+
+// verify checks a token's signature and expiry
+func (s *TripShareService) verify(token string) (uuid.UUID, time.Time, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, time.Time{}, domain.ErrInvalidShareToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.mac(encoded)) {
+		return uuid.Nil, time.Time{}, domain.ErrInvalidShareToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(payload) != 24 {
+		return uuid.Nil, time.Time{}, domain.ErrInvalidShareToken
+	}
+
+	rideID, _ := uuid.FromBytes(payload[:16])
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0).UTC()
+	if !s.now().Before(expiresAt) {
+		return uuid.Nil, time.Time{}, domain.ErrShareLinkExpired
+	}
+	return rideID, expiresAt, nil
+}
+
+func (s *TripShareService) mac(data string) []byte {
+	m := hmac.New(sha256.New, s.secret)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
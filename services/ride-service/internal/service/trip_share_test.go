@@ -0,0 +1,94 @@
+package service
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+func newTestTripShare(secret string, now time.Time) *TripShareService {
+	return &TripShareService{
+		secret: []byte(secret),
+		ttl:    domain.DefaultTripShareTTL,
+		now:    func() time.Time { return now },
+	}
+}
+
+func TestTripShareTokenVerify(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	rideID := uuid.New()
+	expiresAt := now.Add(time.Hour)
+	s := newTestTripShare("share-secret", now)
+	token := s.sign(rideID, expiresAt)
+
+	encoded, signature, _ := strings.Cut(token, ".")
+	otherRide := s.sign(uuid.New(), expiresAt)
+	otherEncoded, _, _ := strings.Cut(otherRide, ".")
+
+	flipped := []byte(signature)
+	if flipped[0] == 'A' {
+		flipped[0] = 'B'
+	} else {
+		flipped[0] = 'A'
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		now     time.Time
+		secret  string
+		wantErr error
+	}{
+		{name: "valid", token: token, now: now, secret: "share-secret"},
+		{name: "valid until just before expiry", token: token, now: expiresAt.Add(-time.Second), secret: "share-secret"},
+		{name: "ride ID swapped under the signature", token: otherEncoded + "." + signature, now: now, secret: "share-secret", wantErr: domain.ErrInvalidShareToken},
+		{name: "tampered signature", token: encoded + "." + string(flipped), now: now, secret: "share-secret", wantErr: domain.ErrInvalidShareToken},
+		{name: "signed with another secret", token: token, now: now, secret: "rotated-secret", wantErr: domain.ErrInvalidShareToken},
+		{name: "no signature", token: encoded, now: now, secret: "share-secret", wantErr: domain.ErrInvalidShareToken},
+		{name: "signature not base64", token: encoded + ".!!", now: now, secret: "share-secret", wantErr: domain.ErrInvalidShareToken},
+		{name: "expired", token: token, now: expiresAt, secret: "share-secret", wantErr: domain.ErrShareLinkExpired},
+		{name: "long expired", token: token, now: expiresAt.Add(24 * time.Hour), secret: "share-secret", wantErr: domain.ErrShareLinkExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotRide, gotExpiry, err := newTestTripShare(tt.secret, tt.now).verify(tt.token)
+			if err != tt.wantErr {
+				t.Fatalf("verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if gotRide != rideID {
+				t.Errorf("verify() ride = %s, want %s", gotRide, rideID)
+			}
+			if !gotExpiry.Equal(expiresAt) {
+				t.Errorf("verify() expires at = %s, want %s", gotExpiry, expiresAt)
+			}
+		})
+	}
+}
+
+func TestTripShareTokenRejectsForgedPayload(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestTripShare("share-secret", now)
+	token := s.sign(uuid.New(), now.Add(time.Minute))
+	_, signature, _ := strings.Cut(token, ".")
+
+	// Pushing the expiry out without the secret breaks the signature
+	payload := make([]byte, 24)
+	forged := base64.RawURLEncoding.EncodeToString(payload)
+	if _, _, err := s.verify(forged + "." + signature); err != domain.ErrInvalidShareToken {
+		t.Fatalf("verify() error = %v, want %v", err, domain.ErrInvalidShareToken)
+	}
+
+	// A correctly signed payload of the wrong length is still refused
+	short := base64.RawURLEncoding.EncodeToString(payload[:16])
+	if _, _, err := s.verify(short + "." + base64.RawURLEncoding.EncodeToString(s.mac(short))); err != domain.ErrInvalidShareToken {
+		t.Fatalf("verify() error = %v, want %v", err, domain.ErrInvalidShareToken)
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/pricing"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/weather"
+)
+
+// WeatherService polls the weather at each enabled city's center. Heavy
+// rain slows the city's traffic model and adds a bounded amount to its
+// surge; severe weather also pads ETAs and warns riders as they request.
+type WeatherService struct {
+	client  *weather.Client
+	cities  *cityconfig.Registry
+	pricing *pricing.Engine
+
+	mu         sync.RWMutex
+	conditions map[string]*domain.WeatherCondition // city code -> last observed
+}
+
+// NewWeatherService creates a new weather service
+func NewWeatherService(client *weather.Client, cities *cityconfig.Registry, pricingEngine *pricing.Engine) *WeatherService {
+	return &WeatherService{
+		client:     client,
+		cities:     cities,
+		pricing:    pricingEngine,
+		conditions: make(map[string]*domain.WeatherCondition),
+	}
+}
+
+// Poll reads the current weather for every enabled city and updates its
+// weather surge. A city whose poll fails keeps its last condition until
+// that goes stale.
+func (s *WeatherService) Poll(ctx context.Context) error {
+	for _, city := range s.cities.List() {
+		if !city.Enabled {
+			continue
+		}
+
+		condition, err := s.client.Current(ctx, city.ServiceArea.CenterLat, city.ServiceArea.CenterLng)
+		if err != nil {
+			log.Warn().Err(err).Str("city", city.Code).Msg("Failed to poll city weather")
+			if last := s.Condition(city.Code); last == nil {
+				s.pricing.SetWeatherSurge(city.Code, 0)
+			}
+			continue
+		}
+		condition.City = city.Code
+
+		s.mu.Lock()
+		s.conditions[city.Code] = condition
+		s.mu.Unlock()
+
+		s.pricing.SetWeatherSurge(city.Code, city.Weather.Surge(condition.Severity))
+		if condition.Severity == domain.WeatherHeavyRain || condition.Severity == domain.WeatherSevere {
+			log.Info().
+				Str("city", city.Code).
+				Str("severity", string(condition.Severity)).
+				Float64("rain_mm_per_hour", condition.RainMMPerHour).
+				Msg("Weather is affecting traffic")
+		}
+	}
+	return nil
+}
+
+// Condition returns the city's current weather, or nil when it has not
+// been observed recently
+func (s *WeatherService) Condition(cityCode string) *domain.WeatherCondition {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	condition := s.conditions[cityCode]
+	if condition == nil || condition.IsStale(time.Now()) {
+		return nil
+	}
+	return condition
+}
+
+// TrafficMultiplierAt returns how much the weather slows travel at a
+// point; 1 outside any city or in clear weather
+func (s *WeatherService) TrafficMultiplierAt(lat, lng float64) float64 {
+	city, ok := s.cities.FindByLocation(lat, lng)
+	if !ok {
+		return 1
+	}
+	condition := s.Condition(city.Code)
+	if condition == nil {
+		return 1
+	}
+	return city.Weather.TrafficMultiplier(condition.Severity)
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/cityconfig"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/redis"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/repository"
+)
+
+// Zone KPI rollup settings
+const (
+	zoneKPIReroll       = 3 * time.Hour  // ride outcomes settle within this, so its buckets are re-rolled each run
+	zoneKPISupplyLookup = 24 * time.Hour // zones with requests this recently get supply sampled
+)
+
+// ZoneKPIService rolls rides and driver supply up into per-zone KPI buckets
+// and serves the ops dashboard from the rollups, so dashboards never scan
+// the rides table
+type ZoneKPIService struct {
+	rideRepo   *repository.RideRepository
+	driverPool *redis.DriverPool
+	cities     *cityconfig.Registry
+}
+
+// NewZoneKPIService creates a new zone KPI service. Without a driver pool
+// supply is not sampled and reports are not cached.
+func NewZoneKPIService(
+	rideRepo *repository.RideRepository,
+	driverPool *redis.DriverPool,
+	cities *cityconfig.Registry,
+) *ZoneKPIService {
+	return &ZoneKPIService{
+		rideRepo:   rideRepo,
+		driverPool: driverPool,
+		cities:     cities,
+	}
+}
+
+// Run re-rolls the recent ride buckets, samples available supply in active
+// zones and refreshes the cached report for each dashboard window
+func (s *ZoneKPIService) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	aggregates, err := s.rideRepo.AggregateZoneRides(ctx, now.Add(-zoneKPIReroll).Truncate(domain.ZoneKPIBucket), now)
+	if err != nil {
+		return err
+	}
+	if len(aggregates) > 0 {
+		rollups := make([]*domain.ZoneKPIRollup, 0, len(aggregates))
+		for _, a := range aggregates {
+			rollup := a.ZoneKPIRollup
+			if s.cities != nil {
+				if city, ok := s.cities.FindByLocation(a.Latitude, a.Longitude); ok {
+					rollup.CityCode = city.Code
+				}
+			}
+			rollups = append(rollups, &rollup)
+		}
+		if err := s.rideRepo.UpsertZoneRideRollups(ctx, rollups); err != nil {
+			return err
+		}
+	}
+
+	if s.driverPool == nil {
+		return nil
+	}
+
+	if err := s.sampleSupply(ctx, now); err != nil {
+		return err
+	}
+
+	for window := range domain.ZoneKPIWindows {
+		report, err := s.buildReport(ctx, window, now)
+		if err != nil {
+			return err
+		}
+		if err := s.driverPool.CacheZoneKPIReport(ctx, report); err != nil {
+			log.Warn().Err(err).Str("window", window).Msg("Failed to cache zone KPI report")
+		}
+	}
+
+	return nil
+}
+
+// GetReport gets per-zone KPIs over a dashboard window, optionally for one
+// city, from the cached report where there is one
+func (s *ZoneKPIService) GetReport(ctx context.Context, window, cityCode string) (*domain.ZoneKPIReport, error) {
+	var report *domain.ZoneKPIReport
+	if s.driverPool != nil {
+		cached, err := s.driverPool.GetCachedZoneKPIReport(ctx, window)
+		if err != nil {
+			log.Warn().Err(err).Str("window", window).Msg("Failed to read cached zone KPI report")
+		}
+		report = cached
+	}
+	if report == nil {
+		built, err := s.buildReport(ctx, window, time.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		report = built
+	}
+
+	if cityCode != "" {
+		zones := make([]*domain.ZoneKPIs, 0)
+		for _, z := range report.Zones {
+			if z.CityCode == cityCode {
+				zones = append(zones, z)
+			}
+		}
+		report.Zones = zones
+		report.CityCode = cityCode
+	}
+
+	return report, nil
+}
+
+// GetZoneSeries gets one zone's KPIs over a dashboard window with a point
+// per rollup bucket
+func (s *ZoneKPIService) GetZoneSeries(ctx context.Context, zone, window string) (*domain.ZoneKPISeries, error) {
+	now := time.Now().UTC()
+	from := now.Add(-domain.ZoneKPIWindows[window]).Truncate(domain.ZoneKPIBucket)
+
+	rollups, err := s.rideRepo.GetZoneKPIRollups(ctx, from, now, "", zone)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := &domain.ZoneKPIs{Zone: zone}
+	buckets := make([]*domain.ZoneKPIs, 0, len(rollups))
+	for _, r := range rollups {
+		totals.Add(r)
+
+		bucketStart := r.BucketStart
+		point := &domain.ZoneKPIs{Zone: zone, BucketStart: &bucketStart}
+		point.Add(r)
+		buckets = append(buckets, point)
+	}
+	totals.ActiveSupply = s.activeSupply(ctx, zone)
+
+	return &domain.ZoneKPISeries{
+		Window:      window,
+		From:        from,
+		To:          now,
+		Totals:      totals,
+		Buckets:     buckets,
+		GeneratedAt: now,
+	}, nil
+}
+
+// buildReport folds a window's rollups into per-zone KPIs, busiest first
+func (s *ZoneKPIService) buildReport(ctx context.Context, window string, now time.Time) (*domain.ZoneKPIReport, error) {
+	from := now.Add(-domain.ZoneKPIWindows[window]).Truncate(domain.ZoneKPIBucket)
+
+	rollups, err := s.rideRepo.GetZoneKPIRollups(ctx, from, now, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	byZone := make(map[string]*domain.ZoneKPIs)
+	zones := make([]*domain.ZoneKPIs, 0)
+	for _, r := range rollups {
+		kpis, ok := byZone[r.Zone]
+		if !ok {
+			kpis = &domain.ZoneKPIs{Zone: r.Zone}
+			byZone[r.Zone] = kpis
+			zones = append(zones, kpis)
+		}
+		kpis.Add(r)
+	}
+	for _, kpis := range zones {
+		kpis.ActiveSupply = s.activeSupply(ctx, kpis.Zone)
+	}
+
+	sort.Slice(zones, func(i, j int) bool {
+		if zones[i].Requests != zones[j].Requests {
+			return zones[i].Requests > zones[j].Requests
+		}
+		return zones[i].Zone < zones[j].Zone
+	})
+
+	return &domain.ZoneKPIReport{
+		Window:      window,
+		From:        from,
+		To:          now,
+		Zones:       zones,
+		GeneratedAt: now,
+	}, nil
+}
+
+// sampleSupply records the available drivers in each recently active zone
+// against the current bucket
+func (s *ZoneKPIService) sampleSupply(ctx context.Context, now time.Time) error {
+	zones, err := s.rideRepo.ListRecentZones(ctx, now.Add(-zoneKPISupplyLookup))
+	if err != nil {
+		return err
+	}
+	if len(zones) == 0 {
+		return nil
+	}
+
+	bucket := now.Truncate(domain.ZoneKPIBucket)
+	for _, z := range zones {
+		z.BucketStart = bucket
+		z.SupplySum = s.activeSupply(ctx, z.Zone)
+	}
+
+	return s.rideRepo.AddZoneSupplySamples(ctx, zones)
+}
+
+func (s *ZoneKPIService) activeSupply(ctx context.Context, zone string) int64 {
+	if s.driverPool == nil {
+		return 0
+	}
+	count, err := s.driverPool.CountDriversInCell(ctx, zone)
+	if err != nil {
+		return 0
+	}
+	return count
+}
@@ -0,0 +1,160 @@
+// Package verification provides clients for third-party background-check and
+// face-match providers.
+package verification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Client requests background checks through the provider gateway. Results
+// arrive asynchronously on the background-check webhook.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// ClientConfig holds configuration for the verification client
+type ClientConfig struct {
+	BaseURL string
+	APIKey  string
+	Timeout time.Duration
+}
+
+// NewClient creates a new verification client
+func NewClient(config ClientConfig) *Client {
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 15 * time.Second
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		apiKey:     config.APIKey,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+type checkRequest struct {
+	Provider  string    `json:"provider"`
+	DriverID  uuid.UUID `json:"driver_id"`
+	Country   string    `json:"country"`
+	Reference string    `json:"reference"`
+}
+
+type checkResponse struct {
+	CheckID string `json:"check_id"`
+	Status  string `json:"status"`
+}
+
+// RequestCheck starts a background check with a provider and returns the
+// provider's check ID
+func (c *Client) RequestCheck(ctx context.Context, provider string, driverID uuid.UUID, country string) (string, error) {
+	payload, err := json.Marshal(checkRequest{
+		Provider:  provider,
+		DriverID:  driverID,
+		Country:   country,
+		Reference: uuid.New().String(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/checks", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("background check request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("background check provider returned status %d", resp.StatusCode)
+	}
+
+	var result checkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode background check response: %w", err)
+	}
+	if result.CheckID == "" {
+		return "", fmt.Errorf("background check provider returned no check ID")
+	}
+
+	return result.CheckID, nil
+}
+
+// FaceMatchClient compares driver selfies against profile photos through a
+// face-match provider
+type FaceMatchClient struct {
+	*Client
+	provider string
+}
+
+// NewFaceMatchClient creates a face-match client. provider names the vendor
+// behind the endpoint and is recorded with each result.
+func NewFaceMatchClient(config ClientConfig, provider string) *FaceMatchClient {
+	return &FaceMatchClient{Client: NewClient(config), provider: provider}
+}
+
+type faceMatchRequest struct {
+	ReferenceURL string `json:"reference_url"`
+	SelfieURL    string `json:"selfie_url"`
+}
+
+type faceMatchResponse struct {
+	Confidence *float64 `json:"confidence"`
+}
+
+// Name returns the provider name
+func (c *FaceMatchClient) Name() string {
+	return c.provider
+}
+
+// MatchFace returns the provider's confidence, between 0 and 1, that the
+// selfie shows the person in the reference photo
+func (c *FaceMatchClient) MatchFace(ctx context.Context, referenceURL, selfieURL string) (float64, error) {
+	payload, err := json.Marshal(faceMatchRequest{ReferenceURL: referenceURL, SelfieURL: selfieURL})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/face-match", bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("face match request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("face match provider returned status %d", resp.StatusCode)
+	}
+
+	var result faceMatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode face match response: %w", err)
+	}
+	if result.Confidence == nil {
+		return 0, fmt.Errorf("face match provider returned no confidence")
+	}
+
+	return *result.Confidence, nil
+}
@@ -0,0 +1,117 @@
+// Package weather provides a client for the OpenWeather current weather API.
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+const defaultBaseURL = "https://api.openweathermap.org/data/2.5"
+
+// Client reads current conditions from OpenWeather
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// ClientConfig holds configuration for the weather client
+type ClientConfig struct {
+	BaseURL string // defaults to the public OpenWeather API
+	APIKey  string
+	Timeout time.Duration
+}
+
+// NewClient creates a new weather client
+func NewClient(config ClientConfig) *Client {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     config.APIKey,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+type currentResponse struct {
+	Weather []struct {
+		ID          int    `json:"id"`
+		Description string `json:"description"`
+	} `json:"weather"`
+	Rain struct {
+		OneHour float64 `json:"1h"`
+	} `json:"rain"`
+	Dt int64 `json:"dt"`
+}
+
+// Current gets the weather at a point, graded by how much it affects
+// driving
+func (c *Client) Current(ctx context.Context, lat, lng float64) (*domain.WeatherCondition, error) {
+	params := url.Values{}
+	params.Set("lat", strconv.FormatFloat(lat, 'f', 4, 64))
+	params.Set("lon", strconv.FormatFloat(lng, 'f', 4, 64))
+	params.Set("units", "metric")
+	params.Set("appid", c.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/weather?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("weather request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("weather provider returned status %d", resp.StatusCode)
+	}
+
+	var result currentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode weather response: %w", err)
+	}
+
+	condition := &domain.WeatherCondition{
+		RainMMPerHour: result.Rain.OneHour,
+		ObservedAt:    time.Unix(result.Dt, 0).UTC(),
+	}
+	if result.Dt == 0 {
+		condition.ObservedAt = time.Now().UTC()
+	}
+
+	// The first condition is the primary one; grade on the worst
+	condition.Severity = domain.ClassifyWeather(0, result.Rain.OneHour)
+	for i, w := range result.Weather {
+		if i == 0 {
+			condition.Summary = w.Description
+		}
+		if severity := domain.ClassifyWeather(w.ID, result.Rain.OneHour); weatherRank[severity] > weatherRank[condition.Severity] {
+			condition.Severity = severity
+		}
+	}
+	return condition, nil
+}
+
+var weatherRank = map[domain.WeatherSeverity]int{
+	domain.WeatherClear:     0,
+	domain.WeatherRain:      1,
+	domain.WeatherHeavyRain: 2,
+	domain.WeatherSevere:    3,
+}
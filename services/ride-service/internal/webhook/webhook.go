@@ -0,0 +1,256 @@
+// Package webhook verifies signed webhook deliveries. A sender signs the
+// delivery's timestamp, a one-off nonce and the body with the secret it
+// shares with us; deliveries signed too long ago, or whose nonce has been
+// seen before, are refused so a captured request cannot be replayed.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/logging"
+)
+
+// Headers a signed delivery carries. The signature is the hex
+// HMAC-SHA256 of "<timestamp>.<nonce>.<body>", optionally prefixed
+// "sha256=", with the timestamp in Unix seconds.
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderNonce     = "X-Webhook-Nonce"
+)
+
+// DefaultTolerance is how far a delivery's timestamp may be from our clock
+const DefaultTolerance = 5 * time.Minute
+
+// Failures from one source within a minute that raise an alert
+const alertThreshold = 10
+
+// Reasons a delivery fails verification
+const (
+	ReasonUnknownSource = "unknown_source"
+	ReasonMissingHeader = "missing_header"
+	ReasonStale         = "stale_timestamp"
+	ReasonBadSignature  = "bad_signature"
+	ReasonReplayed      = "replayed_nonce"
+	ReasonNonceStore    = "nonce_store_error"
+)
+
+// NonceStore remembers nonces already used. Claim reports false when the
+// key was claimed before and has not yet expired.
+type NonceStore interface {
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// ParseSecrets reads SOURCE=SECRET entries such as "checkr=abc,onfido=def".
+// Source names are case-insensitive.
+func ParseSecrets(spec string) (map[string]string, error) {
+	secrets := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		source, secret, ok := strings.Cut(entry, "=")
+		source = strings.ToLower(strings.TrimSpace(source))
+		secret = strings.TrimSpace(secret)
+		if !ok || source == "" || secret == "" {
+			return nil, fmt.Errorf("invalid webhook secret entry for %q, want SOURCE=SECRET", source)
+		}
+		secrets[source] = secret
+	}
+	return secrets, nil
+}
+
+// Sign returns the signature header value for a delivery
+func Sign(secret string, timestamp int64, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "." + nonce + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+var webhookLog = logging.For("webhook")
+
+// Verifier checks deliveries against per-source secrets and counts the
+// ones it refuses
+type Verifier struct {
+	secrets       map[string]string
+	defaultSecret string
+	tolerance     time.Duration
+	nonces        NonceStore
+	now           func() time.Time
+
+	mu       sync.Mutex
+	failures map[string]map[string]int64
+	minute   map[string]int64
+	minuteAt time.Time
+}
+
+// NewVerifier creates a verifier. defaultSecret, when set, covers sources
+// with no secret of their own; a tolerance of zero means DefaultTolerance.
+func NewVerifier(secrets map[string]string, defaultSecret string, tolerance time.Duration, nonces NonceStore) *Verifier {
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	return &Verifier{
+		secrets:       secrets,
+		defaultSecret: defaultSecret,
+		tolerance:     tolerance,
+		nonces:        nonces,
+		now:           time.Now,
+		failures:      make(map[string]map[string]int64),
+		minute:        make(map[string]int64),
+	}
+}
+
+// Verify checks a delivery from source, returning
+// ErrInvalidWebhookSignature or ErrWebhookReplayed when it is refused. A
+// source with no secret configured has every delivery refused.
+func (v *Verifier) Verify(ctx context.Context, source string, header http.Header, body []byte) error {
+	source = strings.ToLower(source)
+	if reason, err := v.verify(ctx, source, header, body); err != nil {
+		v.fail(source, reason)
+		return err
+	}
+	return nil
+}
+
+func (v *Verifier) verify(ctx context.Context, source string, header http.Header, body []byte) (string, error) {
+	secret := v.secrets[source]
+	if secret == "" {
+		secret = v.defaultSecret
+	}
+	if secret == "" {
+		return ReasonUnknownSource, domain.ErrInvalidWebhookSignature
+	}
+
+	signature := header.Get(HeaderSignature)
+	nonce := header.Get(HeaderNonce)
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if signature == "" || nonce == "" || err != nil {
+		return ReasonMissingHeader, domain.ErrInvalidWebhookSignature
+	}
+
+	skew := v.now().Sub(time.Unix(timestamp, 0))
+	if skew > v.tolerance || skew < -v.tolerance {
+		return ReasonStale, domain.ErrInvalidWebhookSignature
+	}
+
+	if !hmac.Equal([]byte(Sign(secret, timestamp, nonce, body)), []byte("sha256="+strings.TrimPrefix(signature, "sha256="))) {
+		return ReasonBadSignature, domain.ErrInvalidWebhookSignature
+	}
+
+	// A nonce only needs remembering while its timestamp is still accepted
+	fresh, err := v.nonces.Claim(ctx, "webhook:nonce:"+source+":"+nonce, 2*v.tolerance)
+	if err != nil {
+		return ReasonNonceStore, err
+	}
+	if !fresh {
+		return ReasonReplayed, domain.ErrWebhookReplayed
+	}
+	return "", nil
+}
+
+// fail counts a refused delivery and alerts once a source's failures in
+// the current minute reach the threshold
+func (v *Verifier) fail(source, reason string) {
+	now := v.now()
+
+	v.mu.Lock()
+	if v.failures[source] == nil {
+		v.failures[source] = make(map[string]int64)
+	}
+	v.failures[source][reason]++
+	if minute := now.Truncate(time.Minute); !minute.Equal(v.minuteAt) {
+		v.minuteAt = minute
+		v.minute = make(map[string]int64)
+	}
+	v.minute[source]++
+	inMinute := v.minute[source]
+	v.mu.Unlock()
+
+	webhookLog.Warn().
+		Str("tag", "webhook_verification_failed").
+		Str("source", source).
+		Str("reason", reason).
+		Msg("Webhook delivery refused")
+
+	if inMinute == alertThreshold {
+		webhookLog.Error().
+			Bool("alert", true).
+			Str("source", source).
+			Int64("failures", inMinute).
+			Msg("Webhook verification failures spiking")
+	}
+}
+
+// Failures returns the deliveries refused since start, by source and reason
+func (v *Verifier) Failures() []domain.WebhookFailureCount {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var counts []domain.WebhookFailureCount
+	for source, reasons := range v.failures {
+		for reason, count := range reasons {
+			counts = append(counts, domain.WebhookFailureCount{Source: source, Reason: reason, Count: count})
+		}
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Source != counts[j].Source {
+			return counts[i].Source < counts[j].Source
+		}
+		return counts[i].Reason < counts[j].Reason
+	})
+	return counts
+}
+
+// RedisNonces keeps nonces in Redis so every replica sees them
+type RedisNonces struct {
+	Client *goredis.Client
+}
+
+// Claim sets the key if it is not already set
+func (n RedisNonces) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return n.Client.SetNX(ctx, key, 1, ttl).Result()
+}
+
+// MemoryNonces keeps nonces in this replica, for running without Redis
+type MemoryNonces struct {
+	mu     sync.Mutex
+	expiry map[string]time.Time
+}
+
+// NewMemoryNonces creates an in-memory nonce store
+func NewMemoryNonces() *MemoryNonces {
+	return &MemoryNonces{expiry: make(map[string]time.Time)}
+}
+
+// Claim sets the key if it is not already set, dropping expired keys
+func (n *MemoryNonces) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for k, expires := range n.expiry {
+		if now.After(expires) {
+			delete(n.expiry, k)
+		}
+	}
+	if _, ok := n.expiry[key]; ok {
+		return false, nil
+	}
+	n.expiry[key] = now.Add(ttl)
+	return true, nil
+}
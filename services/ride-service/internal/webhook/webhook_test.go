@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ubi-africa/ubi-monorepo/services/ride-service/internal/domain"
+)
+
+func signedHeader(secret string, at time.Time, nonce string, body []byte) http.Header {
+	header := http.Header{}
+	header.Set(HeaderTimestamp, strconv.FormatInt(at.Unix(), 10))
+	header.Set(HeaderNonce, nonce)
+	header.Set(HeaderSignature, Sign(secret, at.Unix(), nonce, body))
+	return header
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"status":"clear"}`)
+
+	tests := []struct {
+		name   string
+		source string
+		header http.Header
+		body   []byte
+		want   error
+		reason string
+	}{
+		{"signed with the source's secret", "checkr", signedHeader("checkr-secret", now, "n1", body), body, nil, ""},
+		{"signed with the default secret", "onfido", signedHeader("default-secret", now, "n2", body), body, nil, ""},
+		{"wrong secret", "checkr", signedHeader("default-secret", now, "n3", body), body, domain.ErrInvalidWebhookSignature, ReasonBadSignature},
+		{"body changed", "checkr", signedHeader("checkr-secret", now, "n4", body), []byte(`{"status":"consider"}`), domain.ErrInvalidWebhookSignature, ReasonBadSignature},
+		{"signed too long ago", "checkr", signedHeader("checkr-secret", now.Add(-6*time.Minute), "n5", body), body, domain.ErrInvalidWebhookSignature, ReasonStale},
+		{"no nonce", "checkr", signedHeader("checkr-secret", now, "", body), body, domain.ErrInvalidWebhookSignature, ReasonMissingHeader},
+		{"replayed", "checkr", signedHeader("checkr-secret", now, "n1", body), body, domain.ErrWebhookReplayed, ReasonReplayed},
+	}
+
+	v := NewVerifier(map[string]string{"checkr": "checkr-secret"}, "default-secret", 0, NewMemoryNonces())
+	v.now = func() time.Time { return now }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := failureCount(v, tt.source, tt.reason)
+			if err := v.Verify(context.Background(), tt.source, tt.header, tt.body); err != tt.want {
+				t.Fatalf("Verify() = %v, want %v", err, tt.want)
+			}
+			if tt.reason != "" && failureCount(v, tt.source, tt.reason) != before+1 {
+				t.Errorf("failure %s/%s not counted", tt.source, tt.reason)
+			}
+		})
+	}
+}
+
+func TestVerifyUnknownSource(t *testing.T) {
+	v := NewVerifier(map[string]string{"checkr": "checkr-secret"}, "", 0, NewMemoryNonces())
+	header := signedHeader("checkr-secret", time.Now(), "n1", nil)
+	if err := v.Verify(context.Background(), "onfido", header, nil); err != domain.ErrInvalidWebhookSignature {
+		t.Fatalf("Verify() = %v, want ErrInvalidWebhookSignature", err)
+	}
+	if failureCount(v, "onfido", ReasonUnknownSource) != 1 {
+		t.Errorf("Failures() = %+v, want one unknown_source", v.Failures())
+	}
+}
+
+func TestParseSecrets(t *testing.T) {
+	secrets, err := ParseSecrets(" Checkr=abc, onfido=d=ef ")
+	if err != nil {
+		t.Fatalf("ParseSecrets() error = %v", err)
+	}
+	if secrets["checkr"] != "abc" || secrets["onfido"] != "d=ef" {
+		t.Errorf("ParseSecrets() = %v", secrets)
+	}
+	if _, err := ParseSecrets("checkr"); err == nil {
+		t.Error("ParseSecrets(\"checkr\") succeeded, want error")
+	}
+}
+
+func failureCount(v *Verifier, source, reason string) int64 {
+	for _, f := range v.Failures() {
+		if f.Source == source && f.Reason == reason {
+			return f.Count
+		}
+	}
+	return 0
+}
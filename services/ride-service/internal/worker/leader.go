@@ -0,0 +1,142 @@
+package worker
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Elector elects the one replica that runs singleton jobs. TryLead is
+// called every few seconds and must both take a free lease and renew the
+// one this replica already holds.
+type Elector interface {
+	TryLead(ctx context.Context) (bool, error)
+	Resign(ctx context.Context) error
+}
+
+// standalone leads unconditionally
+type standalone struct{}
+
+// Standalone returns an elector for a service running a single replica,
+// which always leads
+func Standalone() Elector {
+	return standalone{}
+}
+
+func (standalone) TryLead(context.Context) (bool, error) { return true, nil }
+func (standalone) Resign(context.Context) error          { return nil }
+
+// renewLease extends the lease when this replica holds it and takes it when
+// nobody does
+var renewLease = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 1
+end
+return 0`)
+
+// releaseLease deletes the lease only when this replica holds it
+var releaseLease = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// RedisElector holds leadership as a Redis key that expires unless the
+// leader renews it, so a crashed leader is replaced within one TTL
+type RedisElector struct {
+	client *redis.Client
+	key    string
+	token  string
+	ttl    time.Duration
+}
+
+// NewRedisElector creates an elector leasing key for ttl at a time. token
+// identifies this replica and must differ between replicas.
+func NewRedisElector(client *redis.Client, key, token string, ttl time.Duration) *RedisElector {
+	return &RedisElector{client: client, key: key, token: token, ttl: ttl}
+}
+
+// TryLead takes or renews the lease
+func (e *RedisElector) TryLead(ctx context.Context) (bool, error) {
+	n, err := renewLease.Run(ctx, e.client, []string{e.key}, e.token, e.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// Resign gives up the lease so another replica can lead at once
+func (e *RedisElector) Resign(ctx context.Context) error {
+	return releaseLease.Run(ctx, e.client, []string{e.key}, e.token).Err()
+}
+
+// PostgresElector holds leadership as a session advisory lock on a
+// connection taken out of the pool. Postgres releases the lock when the
+// session ends, so a crashed leader is replaced as soon as its connection
+// drops.
+type PostgresElector struct {
+	pool *pgxpool.Pool
+	key  int64
+	conn *pgxpool.Conn
+}
+
+// NewPostgresElector creates an elector locking on a key derived from name
+func NewPostgresElector(pool *pgxpool.Pool, name string) *PostgresElector {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return &PostgresElector{pool: pool, key: int64(h.Sum64())}
+}
+
+// TryLead takes the lock, or checks the session holding it is still alive
+func (e *PostgresElector) TryLead(ctx context.Context) (bool, error) {
+	if e.conn != nil {
+		if _, err := e.conn.Exec(ctx, "SELECT 1"); err != nil {
+			e.drop(ctx)
+			return false, err
+		}
+		return true, nil
+	}
+
+	conn, err := e.pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	var locked bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", e.key).Scan(&locked); err != nil {
+		conn.Release()
+		return false, err
+	}
+	if !locked {
+		conn.Release()
+		return false, nil
+	}
+	e.conn = conn
+	return true, nil
+}
+
+// Resign releases the lock
+func (e *PostgresElector) Resign(ctx context.Context) error {
+	if e.conn == nil {
+		return nil
+	}
+	if _, err := e.conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", e.key); err != nil {
+		e.drop(ctx)
+		return err
+	}
+	e.conn.Release()
+	e.conn = nil
+	return nil
+}
+
+// drop closes the leader's connection rather than return it to the pool,
+// where it could still be holding the lock
+func (e *PostgresElector) drop(ctx context.Context) {
+	e.conn.Hijack().Close(ctx)
+	e.conn = nil
+}
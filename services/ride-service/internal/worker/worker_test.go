@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fixedElector bool
+
+func (e fixedElector) TryLead(context.Context) (bool, error) { return bool(e), nil }
+func (fixedElector) Resign(context.Context) error            { return nil }
+
+func TestSchedulerIsolatesPanicsAndFollowsLeadership(t *testing.T) {
+	tests := []struct {
+		name        string
+		leader      bool
+		wantRuns    int64
+		wantSkipped int64
+	}{
+		{"leader runs singleton jobs", true, 1, 0},
+		{"follower skips singleton jobs", false, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New("test", "replica-1", fixedElector(tt.leader))
+			s.Register(Job{
+				Name: "panics", Schedule: Every(time.Hour), EveryReplica: true, RunOnStart: true,
+				Run: func(context.Context) error { panic("boom") },
+			})
+			s.Register(Job{
+				Name: "fails", Schedule: Every(time.Hour), EveryReplica: true, RunOnStart: true,
+				Run: func(context.Context) error { return errors.New("failed") },
+			})
+			s.Register(Job{
+				Name: "singleton", Schedule: Every(time.Hour), RunOnStart: true,
+				Run: func(context.Context) error { return nil },
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				s.Start(ctx)
+				close(done)
+			}()
+
+			deadline := time.Now().Add(2 * time.Second)
+			for {
+				stats := byName(s.Status())
+				if stats["panics"].Runs == 1 && stats["fails"].Runs == 1 &&
+					stats["singleton"].Runs+stats["singleton"].Skipped == 1 {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("jobs did not run on start")
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			cancel()
+			<-done
+
+			stats := byName(s.Status())
+			if p := stats["panics"]; p.Panics != 1 || p.Failures != 1 || p.LastError != "panic: boom" {
+				t.Errorf("panicking job stats = %+v", p)
+			}
+			if f := stats["fails"]; f.Panics != 0 || f.Failures != 1 || f.LastSucceededAt != nil {
+				t.Errorf("failing job stats = %+v", f)
+			}
+			if sj := stats["singleton"]; sj.Runs != tt.wantRuns || sj.Skipped != tt.wantSkipped {
+				t.Errorf("singleton runs = %d, skipped = %d, want %d, %d", sj.Runs, sj.Skipped, tt.wantRuns, tt.wantSkipped)
+			}
+			if s.IsLeader() {
+				t.Error("scheduler still leads after shutdown")
+			}
+		})
+	}
+}
+
+func byName(status *Status) map[string]*JobStats {
+	stats := make(map[string]*JobStats, len(status.Jobs))
+	for _, j := range status.Jobs {
+		stats[j.Name] = j
+	}
+	return stats
+}